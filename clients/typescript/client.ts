@@ -0,0 +1,3834 @@
+// Code generated by go run ./cmd/genclient; DO NOT EDIT.
+
+/** 生成客户端使用的接口文档版本 */
+export const API_VERSION = "1.0";
+
+/** 服务端返回的错误代码 */
+export const ERROR_CODES = [
+  "VALIDATION_ERROR",
+  "NOT_FOUND",
+  "UNAUTHORIZED",
+  "FORBIDDEN",
+  "CONFLICT",
+  "RATE_LIMIT_EXCEEDED",
+  "INTERNAL_ERROR",
+  "DATABASE_ERROR",
+  "CACHE_ERROR",
+  "SERVICE_UNAVAILABLE",
+  "TIMEOUT",
+  "INVALID_TOKEN",
+  "TOKEN_BLACKLISTED",
+  "BUSINESS_LOGIC_ERROR",
+  "QUOTA_EXCEEDED",
+  "MAINTENANCE_MODE",
+  "THIRD_PARTY_SERVICE_ERROR",
+  "CONFIGURATION_ERROR",
+  "DEPENDENCY_ERROR",
+  "SECURITY_ERROR",
+  "DATA_INTEGRITY_ERROR",
+  "PAYLOAD_TOO_LARGE",
+  "PRECONDITION_FAILED",
+  "ENTITLEMENT_REQUIRED",
+] as const;
+
+export type ErrorCode = (typeof ERROR_CODES)[number];
+
+/** 服务端返回的错误，code 对应服务端的 AppError 错误代码 */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly code: ErrorCode | string;
+  readonly userMessage?: string;
+  readonly details?: Record<string, unknown>;
+  readonly correlationId?: string;
+  readonly requestId?: string;
+
+  constructor(init: {
+    status: number;
+    code: ErrorCode | string;
+    message: string;
+    userMessage?: string;
+    details?: Record<string, unknown>;
+    correlationId?: string;
+    requestId?: string;
+  }) {
+    super(init.message);
+    this.name = "ApiError";
+    this.status = init.status;
+    this.code = init.code;
+    this.userMessage = init.userMessage;
+    this.details = init.details;
+    this.correlationId = init.correlationId;
+    this.requestId = init.requestId;
+  }
+
+  /** 判断错误代码 */
+  is(code: ErrorCode): boolean {
+    return this.code === code;
+  }
+}
+
+/** 条件请求的资源未修改（304） */
+export class NotModifiedError extends Error {
+  constructor() {
+    super("not modified");
+    this.name = "NotModifiedError";
+  }
+}
+
+/** 无法解析错误响应时按状态码推断错误代码 */
+function codeForStatus(status: number): ErrorCode | string {
+  switch (status) {
+    case 400:
+      return "VALIDATION_ERROR";
+    case 401:
+      return "UNAUTHORIZED";
+    case 403:
+      return "FORBIDDEN";
+    case 404:
+      return "NOT_FOUND";
+    case 409:
+      return "CONFLICT";
+    case 412:
+      return "PRECONDITION_FAILED";
+    case 413:
+      return "PAYLOAD_TOO_LARGE";
+    case 429:
+      return "RATE_LIMIT_EXCEEDED";
+    case 503:
+      return "SERVICE_UNAVAILABLE";
+    case 504:
+      return "TIMEOUT";
+  }
+  return status >= 500 ? "INTERNAL_ERROR" : "UNKNOWN";
+}
+
+/** 解析信封格式（{"error": {...}}）或 RFC 7807 格式的错误响应 */
+async function parseError(response: Response): Promise<ApiError> {
+  const init = {
+    status: response.status,
+    code: codeForStatus(response.status),
+    message: response.statusText || "HTTP " + response.status,
+    correlationId: response.headers.get("X-Correlation-ID") ?? undefined,
+    requestId: response.headers.get("X-Request-ID") ?? undefined,
+  } as ConstructorParameters<typeof ApiError>[0];
+  try {
+    const body = (await response.json()) as Record<string, any>;
+    const error = body.error && typeof body.error === "object" ? body.error : body;
+    if (typeof error.code === "string" && error.code !== "") init.code = error.code;
+    const message = error.message ?? error.detail ?? body.message;
+    if (typeof message === "string" && message !== "") init.message = message;
+    init.userMessage = error.user_message ?? (typeof body.detail === "string" ? body.detail : undefined);
+    init.details = error.details;
+    if (typeof body.correlation_id === "string") init.correlationId = body.correlation_id;
+  } catch {
+    // 响应体不是 JSON 时只使用状态码
+  }
+  return new ApiError(init);
+}
+
+/** 提供访问令牌，invalidate 在服务端返回 401 后调用以便下次重新获取 */
+export interface TokenProvider {
+  getToken(): Promise<string | undefined>;
+  invalidate?(token: string): void;
+}
+
+/** 读取 JWT 的过期时间（毫秒），不校验签名 */
+function tokenExpiry(token: string): number | undefined {
+  const payload = token.split(".")[1];
+  if (!payload) return undefined;
+  try {
+    const claims = JSON.parse(atob(payload.replace(/-/g, "+").replace(/_/g, "/")));
+    return typeof claims.exp === "number" ? claims.exp * 1000 : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** 缓存令牌，在过期前 leewayMs 或令牌失效后重新获取；并发请求共用一次获取 */
+export class RefreshingTokenProvider implements TokenProvider {
+  private token?: string;
+  private expiry?: number;
+  private pending?: Promise<string>;
+
+  constructor(
+    private readonly fetchToken: () => Promise<string>,
+    private readonly leewayMs = 30_000,
+  ) {}
+
+  async getToken(): Promise<string> {
+    if (this.token && (this.expiry === undefined || Date.now() < this.expiry - this.leewayMs)) {
+      return this.token;
+    }
+    if (!this.pending) {
+      this.pending = this.fetchToken()
+        .then((token) => {
+          this.token = token;
+          this.expiry = tokenExpiry(token);
+          return token;
+        })
+        .finally(() => {
+          this.pending = undefined;
+        });
+    }
+    return this.pending;
+  }
+
+  invalidate(token: string): void {
+    if (this.token === token) {
+      this.token = undefined;
+      this.expiry = undefined;
+    }
+  }
+}
+
+/** 使用邮箱和密码登录获取令牌，令牌过期或失效后自动重新登录 */
+export function loginTokenProvider(client: ApiClient, email: string, password: string): RefreshingTokenProvider {
+  return new RefreshingTokenProvider(async () => (await client.authLogin({ email, password })).token);
+}
+
+export interface ClientOptions {
+  /** 服务地址，如 http://localhost:8080 */
+  baseUrl: string;
+  /** 固定的访问令牌或令牌提供者 */
+  token?: string | TokenProvider;
+  /** 默认使用全局 fetch */
+  fetch?: typeof fetch;
+  /** 每个请求都携带的请求头 */
+  headers?: Record<string, string>;
+}
+
+export interface RequestOptions {
+  headers?: Record<string, string>;
+  signal?: AbortSignal;
+  /** 收到成功响应后调用，用于读取 ETag 等响应头 */
+  onResponse?: (response: Response) => void;
+}
+
+type QueryValue = string | number | boolean | undefined | null;
+
+interface RequestSpec {
+  method: string;
+  path: string;
+  query?: Record<string, QueryValue>;
+  headers?: Record<string, QueryValue>;
+  body?: unknown;
+  form?: FormData;
+  auth?: boolean;
+  envelope?: boolean;
+  text?: boolean;
+}
+
+/** 从 page（默认第 1 页）开始逐页获取并逐条返回数据 */
+async function* paginate<T>(page: number | undefined, fetchPage: (page: number) => Promise<Page<T>>): AsyncGenerator<T> {
+  for (let current = page && page > 0 ? page : 1; ; current++) {
+    const result = await fetchPage(current);
+    const items = result.data ?? [];
+    yield* items;
+    if (items.length === 0 || current >= (result.pagination?.total_pages ?? 0)) return;
+  }
+}
+
+/** 一页数据 */
+export interface Page<T> {
+  data: T[];
+  pagination: Pagination;
+}
+
+/** 依赖组件的状态 */
+export interface AdminOverviewComponent {
+  /** 驱动名称 */
+  driver?: string;
+  /** 不健康时的错误信息 */
+  error?: string;
+  /** 组件返回的统计信息 */
+  stats?: Record<string, unknown>;
+  /** 组件状态 */
+  status?: string;
+}
+
+/** 管理后台系统概览，汇总用户、限流、缓存、数据库和构建信息 */
+export interface AdminOverviewResponse {
+  /** 版本和构建信息 */
+  build?: Info;
+  /** 缓存状态和统计 */
+  cache?: AdminOverviewComponent;
+  /** 数据库连接池健康状态 */
+  database?: AdminOverviewComponent;
+  /** 概览生成时间，缓存期内的请求返回相同的时间 */
+  generated_at?: string;
+  /** 请求和限流统计，未启用限流时省略 */
+  rate_limit?: RateLimitStats;
+  /** 进程运行时长 */
+  uptime?: AdminOverviewUptime;
+  /** 用户数量 */
+  users?: AdminOverviewUsers;
+}
+
+/** 进程运行时长 */
+export interface AdminOverviewUptime {
+  /** 可读的运行时长 */
+  human?: string;
+  /** 运行秒数 */
+  seconds?: number;
+  /** 进程启动时间 */
+  started_at?: string;
+}
+
+/** 用户数量统计 */
+export interface AdminOverviewUsers {
+  /** 已激活用户数 */
+  active?: number;
+  /** 管理员数 */
+  admins?: number;
+  /** 统计失败时的错误信息 */
+  error?: string;
+  /** 已停用用户数 */
+  inactive?: number;
+  /** 用户总数（含已停用） */
+  total?: number;
+}
+
+/** 管理员发布的系统公告，在 starts_at 和 ends_at 之间对匹配角色和租户的用户可见 */
+export interface Announcement {
+  /** 正文 */
+  body?: string;
+  /** 创建时间 */
+  created_at?: string;
+  /** 创建者ID */
+  created_by?: string;
+  /** 过期时间，为空时一直有效 */
+  ends_at?: string | null;
+  /** 公告ID */
+  id?: string;
+  /** 级别（info、warning、critical） */
+  level?: string;
+  /** 可见的角色，为空时对所有用户（包括未登录用户）可见 */
+  roles?: Array<string>;
+  /** 生效时间，为空时立即生效 */
+  starts_at?: string | null;
+  /** 可见的租户ID，为空时对所有租户可见 */
+  tenant_id?: string | null;
+  /** 标题 */
+  title?: string;
+  /** 更新时间 */
+  updated_at?: string;
+}
+
+/** 公告管理列表响应 */
+export interface AnnouncementListResponse {
+  /** 公告，按创建时间倒序 */
+  announcements?: Array<Announcement>;
+  /** 分页信息 */
+  pagination?: Pagination;
+}
+
+/** 创建或修改公告请求，修改时替换公告的完整定义 */
+export interface AnnouncementRequest {
+  /** 正文 */
+  body: string;
+  /** 过期时间，为空时一直有效，须晚于生效时间 */
+  ends_at?: string | null;
+  /** 级别，默认 info */
+  level?: "info" | "warning" | "critical";
+  /** 可见的角色，为空时对所有用户可见 */
+  roles?: Array<"user" | "admin">;
+  /** 生效时间，为空时立即生效 */
+  starts_at?: string | null;
+  /** 可见的租户ID，为空时对所有租户可见 */
+  tenant_id?: string | null;
+  /** 标题 */
+  title: string;
+}
+
+/** 分配用户角色请求，角色列表为用户的完整角色集合 */
+export interface AssignRolesRequest {
+  /** 角色列表 */
+  roles: Array<"user" | "admin">;
+}
+
+/** 数据库的逻辑备份（pg_dump 自定义格式），加密后保存在对象存储中 */
+export interface Backup {
+  /** 备份文件（加密前）的 SHA-256，完成后填写 */
+  checksum?: string;
+  /** 完成或失败时间 */
+  completed_at?: string | null;
+  /** 创建时间 */
+  created_at?: string;
+  /** 创建者ID，定期备份为空 */
+  created_by?: string | null;
+  /** 失败原因 */
+  error?: string;
+  /** 备份删除时间，完成后填写 */
+  expires_at?: string | null;
+  /** 备份ID */
+  id?: string;
+  /** 备份文件大小（字节，加密前），完成后填写 */
+  size?: number;
+  /** 状态（pending、running、completed、failed） */
+  status?: string;
+  /** 触发方式（manual、scheduled） */
+  trigger?: string;
+  /** 更新时间 */
+  updated_at?: string;
+}
+
+/** 备份列表响应 */
+export interface BackupListResponse {
+  /** 备份，按创建时间倒序 */
+  backups?: Array<Backup>;
+  /** 分页信息 */
+  pagination?: Pagination;
+}
+
+/** 封禁记录 */
+export interface Ban {
+  /** 封禁时间 */
+  created_at?: string;
+  /** 到期时间，到期后自动解除 */
+  expires_at?: string;
+  /** IP 地址或用户ID */
+  identifier?: string;
+  /** 封禁原因 */
+  reason?: string;
+  /** 封禁对象类型：ip 或 user */
+  type?: string;
+  /** 封禁时统计窗口内的违规比例（百分比） */
+  violation_rate?: number;
+  /** 封禁时统计窗口内的违规次数 */
+  violations?: number;
+}
+
+/** 缓存键列表响应 */
+export interface CacheKeysResponse {
+  /** 获取下一页时传入的游标，遍历结束时为空 */
+  cursor?: string;
+  /** 本页匹配的键（不含应用键前缀），页内按字典序排列 */
+  keys?: Array<string>;
+  /** 匹配模式 */
+  pattern?: string;
+  /** 是否还有下一页 */
+  truncated?: boolean;
+}
+
+/** 缓存统计响应 */
+export interface CacheStatsResponse {
+  /** 驱动支持的可选能力 */
+  capabilities?: Array<string>;
+  /** 缓存驱动 */
+  driver?: string;
+  /** 驱动返回的统计信息 */
+  stats?: Record<string, unknown>;
+}
+
+/** 变更邮箱请求，新邮箱验证后才生效 */
+export interface ChangeEmailRequest {
+  /** 新邮箱地址 */
+  new_email: string;
+  /** 当前密码 */
+  password: string;
+}
+
+/** 修改密码请求 */
+export interface ChangePasswordRequest {
+  /** 新密码 */
+  new_password: string;
+  /** 旧密码 */
+  old_password: string;
+}
+
+/** 单个检查项的结果 */
+export interface CheckResult {
+  /** 是否为关键依赖 */
+  critical?: boolean;
+  /** 检查耗时（毫秒） */
+  duration_ms?: number;
+  /** 错误信息 */
+  error?: string;
+  /** 检查状态 */
+  status?: "healthy" | "degraded" | "unhealthy";
+}
+
+/** 确认邮箱变更请求 */
+export interface ConfirmEmailChangeRequest {
+  /** 发送到新邮箱的验证令牌 */
+  token: string;
+}
+
+/** represents the traffic of clients located in one country */
+export interface CountryStats {
+  client_errors?: number;
+  country?: string;
+  requests?: number;
+  response_bytes?: number;
+  server_errors?: number;
+}
+
+/** 创建功能开关请求 */
+export interface CreateFeatureFlagRequest {
+  /** 说明 */
+  description?: string;
+  /** 总开关 */
+  enabled?: boolean;
+  /** 开关键，小写字母、数字、下划线、点和连字符 */
+  key: string;
+  /** 按用户灰度的比例（0-100） */
+  percentage?: number;
+  /** 始终开启的用户ID */
+  users: Array<string>;
+}
+
+/** 注销账户请求 */
+export interface DeleteAccountRequest {
+  /** 当前密码 */
+  password: string;
+}
+
+/** 设备授权请求的响应（RFC 8628 第 3.2 节），设备显示 user_code 和 verification_uri 并轮询令牌接口 */
+export interface DeviceCodeResponse {
+  /** 设备码，只用于轮询令牌接口，不要展示给用户 */
+  device_code?: string;
+  /** 设备码和用户码的有效期（秒） */
+  expires_in?: number;
+  /** 轮询间隔（秒） */
+  interval?: number;
+  /** 用户码，用户在验证页面输入 */
+  user_code?: string;
+  /** 验证页面地址 */
+  verification_uri?: string;
+  /** 带有用户码的验证页面地址，可显示为二维码 */
+  verification_uri_complete?: string;
+}
+
+/** 设备轮询令牌的请求 */
+export interface DeviceTokenRequest {
+  /** 设备码 */
+  device_code: string;
+}
+
+/** 已登录用户在验证页面对设备登录做出的决定 */
+export interface DeviceVerifyRequest {
+  /** 是否允许设备登录 */
+  approve?: boolean;
+  /** 设备上显示的用户码，不区分大小写，可省略连字符 */
+  user_code: string;
+}
+
+/** 排空进度 */
+export interface DrainStatus {
+  /** 完成时间 */
+  completed_at?: string | null;
+  /** 处理中的请求数，不含排空接口本身 */
+  in_flight?: number;
+  /** 开始时间 */
+  started_at?: string | null;
+  /** idle、draining 或 drained */
+  state?: string;
+  /** 各步骤的进度 */
+  steps?: Array<Step>;
+}
+
+/** 邮箱变更申请响应 */
+export interface EmailChangeResponse {
+  /** 验证令牌过期时间 */
+  expires_at?: string;
+  /** 待验证的新邮箱 */
+  new_email?: string;
+}
+
+/** 开启维护请求，已在维护中时更新说明和预计结束时间 */
+export interface EnableMaintenanceRequest {
+  /** 预计时长（秒），0 表示未知 */
+  duration_seconds?: number;
+  /** 展示给用户的说明 */
+  message?: string;
+}
+
+/** 抹除用户个人数据的结果 */
+export interface ErasureReport {
+  /** 是否删除了头像文件 */
+  avatar_deleted?: boolean;
+  /** 抹除时间 */
+  erased_at?: string;
+  /** 删除的站内信数 */
+  notifications_deleted?: number;
+  /** 是否吊销了已签发的令牌 */
+  tokens_revoked?: boolean;
+  /** 被抹除的用户ID */
+  user_id?: string;
+}
+
+/** 错误代码的机器可读说明 */
+export interface ErrorCodeInfo {
+  /** 错误代码 */
+  code?: ErrorCode;
+  /** 错误说明 */
+  description?: string;
+  /** 文档链接，同时作为 RFC 7807 的问题类型URI */
+  docs_url?: string;
+  /** 对应的HTTP状态码 */
+  http_status?: number;
+  /** 客户端是否可以原样重试 */
+  retryable?: boolean;
+  /** 简短标题 */
+  title?: string;
+}
+
+/** Stripe 事件，data.object 按事件类型解析 */
+export interface Event {
+  /** 事件创建时间（Unix 秒） */
+  created?: number;
+  /** 事件数据 */
+  data?: EventData;
+  /** 事件ID */
+  id?: string;
+  /** 事件类型 */
+  type?: string;
+}
+
+/** 事件携带的对象 */
+export interface EventData {
+  /** 事件发生后的对象 */
+  object?: unknown;
+}
+
+/** 后台生成的用户数据导出文件，记录任务进度和存储位置 */
+export interface Export {
+  /** 完成或失败时间 */
+  completed_at?: string | null;
+  /** 创建时间 */
+  created_at?: string;
+  /** 创建者ID */
+  created_by?: string;
+  /** 带签名的下载地址，完成后每次查询重新签发 */
+  download_url?: string;
+  /** 失败原因 */
+  error?: string;
+  /** 文件删除时间，完成后填写 */
+  expires_at?: string | null;
+  /** 筛选条件 */
+  filter?: ExportFilter;
+  /** 文件格式（csv、json、xlsx） */
+  format?: string;
+  /** 导出任务ID */
+  id?: string;
+  /** 已写入的用户数 */
+  processed_rows?: number;
+  /** 文件大小（字节），完成后填写 */
+  size?: number;
+  /** 状态（pending、running、completed、failed） */
+  status?: string;
+  /** 所属租户ID，未启用多租户时为空 */
+  tenant_id?: string | null;
+  /** 开始时匹配的用户数 */
+  total_rows?: number;
+  /** 更新时间 */
+  updated_at?: string;
+}
+
+/** 导出用户的筛选条件，与管理员用户列表的筛选条件相同 */
+export interface ExportFilter {
+  /** 按激活状态筛选 */
+  is_active?: boolean | null;
+  /** 按管理员角色筛选 */
+  is_admin?: boolean | null;
+  /** 按用户名、邮箱或姓名模糊匹配 */
+  query?: string;
+}
+
+/** 创建用户数据导出的请求，筛选条件与管理员用户列表相同 */
+export interface ExportRequest {
+  /** 文件格式 */
+  format: "csv" | "json" | "xlsx";
+  /** 按激活状态筛选 */
+  is_active?: boolean | null;
+  /** 按管理员角色筛选 */
+  is_admin?: boolean | null;
+  /** 按用户名、邮箱或姓名模糊匹配 */
+  query?: string;
+}
+
+/** 功能开关定义 */
+export interface Flag {
+  /** 说明 */
+  description?: string;
+  /** 总开关，关闭时对所有用户关闭 */
+  enabled?: boolean;
+  /** 开关键 */
+  key?: string;
+  /** 按用户灰度的比例（0-100），100 表示对所有请求开启 */
+  percentage?: number;
+  /** 最后修改时间 */
+  updated_at?: string;
+  /** 始终开启的用户ID */
+  users?: Array<string>;
+}
+
+/** represents the statistics of one method/route/status series */
+export interface HTTPRouteStats {
+  /** 纳秒 */
+  avg_duration?: number;
+  latency?: HistogramSnapshot;
+  method?: string;
+  /** panics recovered by the recovery middleware */
+  panics?: number;
+  request_bytes?: number;
+  requests?: number;
+  response_bytes?: number;
+  route?: string;
+  status?: number;
+}
+
+/** represents aggregated HTTP server statistics */
+export interface HTTPStats {
+  in_flight?: number;
+  peak_in_flight?: number;
+  routes?: Array<HTTPRouteStats>;
+  total_panics?: number;
+  total_requests?: number;
+}
+
+/** 一组检查的汇总结果 */
+export interface HealthReport {
+  /** 各检查项结果 */
+  checks?: Record<string, CheckResult>;
+  /** 维护倒计时，仅在维护期间出现 */
+  maintenance?: Maintenance;
+  /** 整体状态 */
+  status?: "healthy" | "degraded" | "unhealthy";
+  /** 检查时间 */
+  timestamp?: string;
+}
+
+/** 健康检查响应 */
+export interface HealthResponse {
+  /** 维护倒计时，仅在维护期间出现 */
+  maintenance?: Maintenance;
+  /** 服务状态，数据库和缓存为包含 status 的对象 */
+  services?: Record<string, unknown>;
+  /** 状态 */
+  status?: string;
+  /** 运行时信息 */
+  system?: Record<string, unknown>;
+  /** 时间戳 */
+  timestamp?: string;
+  /** 版本号 */
+  version?: string;
+}
+
+/** represents a single cumulative histogram bucket */
+export interface HistogramBucket {
+  count?: number;
+  le?: string;
+}
+
+/** is a point-in-time view of a latency histogram */
+export interface HistogramSnapshot {
+  buckets?: Array<HistogramBucket>;
+  count?: number;
+  /** 纳秒 */
+  mean?: number;
+  /** 纳秒 */
+  sum?: number;
+}
+
+/** 模拟登录响应 */
+export interface ImpersonationResponse {
+  /** 令牌过期时间 */
+  expires_at?: string;
+  /** 管理员用户ID */
+  impersonator_id?: string;
+  /** 以目标用户身份签发的令牌 */
+  token?: string;
+  /** 目标用户 */
+  user?: SafeUser;
+}
+
+/** 批量导入用户的结果，试运行时只校验不写入 */
+export interface ImportReport {
+  /** 已创建的用户数，试运行时为 0 */
+  created?: number;
+  /** 是否为试运行 */
+  dry_run?: boolean;
+  /** 各行的错误，按行号排序 */
+  errors?: Array<ImportRowError>;
+  /** 文件中的数据行数 */
+  total_rows?: number;
+  /** 通过校验且没有重复的行数 */
+  valid_rows?: number;
+}
+
+/** 导入文件中一行的错误，同一行可以有多个字段错误 */
+export interface ImportRowError {
+  /** 错误代码 */
+  code?: string;
+  /** 出错的字段，整行错误时为空 */
+  field?: string;
+  /** 错误描述 */
+  message?: string;
+  /** 数据行号，从 1 开始，不含 CSV 表头 */
+  row?: number;
+}
+
+/** 版本和构建信息 */
+export interface Info {
+  build_time?: string;
+  git_commit?: string;
+  go_version?: string;
+  /** 构建时工作区有未提交的修改 */
+  modified?: boolean;
+  version?: string;
+}
+
+/** JSON Web Key（RFC 7517），仅包含公钥参数 */
+export interface JWK {
+  /** 签名算法 */
+  alg?: string;
+  /** EC 曲线 */
+  crv?: string;
+  /** RSA 公开指数 */
+  e?: string;
+  /** 密钥ID */
+  kid?: string;
+  /** 密钥类型：RSA 或 EC */
+  kty?: string;
+  /** RSA 模数 */
+  n?: string;
+  /** 用途：sig */
+  use?: string;
+  /** EC 公钥 X 坐标 */
+  x?: string;
+  /** EC 公钥 Y 坐标 */
+  y?: string;
+}
+
+/** JSON Web Key Set */
+export interface JWKS {
+  keys?: Array<JWK>;
+}
+
+/** 当前生效的全局日志级别和按模块覆盖的日志级别 */
+export interface LevelSettings {
+  level?: string;
+  modules?: Record<string, string>;
+}
+
+/** 登录请求 */
+export interface LoginRequest {
+  /** 邮箱地址 */
+  email: string;
+  /** 密码 */
+  password: string;
+}
+
+/** 登录响应 */
+export interface LoginResponse {
+  /** 访问令牌有效期（秒） */
+  expires_in?: number;
+  /** 刷新令牌，用于在访问令牌过期后换取新的令牌 */
+  refresh_token?: string;
+  /** JWT令牌 */
+  token?: string;
+  /** 安全用户信息 */
+  user?: SafeUser;
+}
+
+/** 登出请求，请求体可以省略 */
+export interface LogoutRequest {
+  /** 同时吊销的刷新令牌 */
+  refresh_token?: string;
+}
+
+/** 登出响应 */
+export interface LogoutResponse {
+  /** 邮箱地址 */
+  email?: string;
+  /** 用户ID */
+  user_id?: string;
+  /** 用户名 */
+  username?: string;
+}
+
+/** 申请登录链接的请求 */
+export interface MagicLinkRequest {
+  /** 账号邮箱 */
+  email: string;
+}
+
+/** 申请登录链接的响应，邮箱不存在时同样返回，不泄露账号是否存在 */
+export interface MagicLinkResponse {
+  /** 登录链接的过期时间 */
+  expires_at?: string;
+}
+
+/** 使用登录链接中的令牌登录 */
+export interface MagicLinkVerifyRequest {
+  /** 登录链接中的令牌 */
+  token: string;
+}
+
+/** 维护倒计时，维护期间附加在检查结果中，不影响整体状态 */
+export interface Maintenance {
+  /** 预计结束时间，为空表示未知 */
+  ends_at?: string | null;
+  /** 维护说明 */
+  message?: string;
+  /** 距预计结束的秒数 */
+  remaining_seconds?: number;
+  /** 开始时间 */
+  started_at?: string | null;
+}
+
+/** 标记全部通知已读响应 */
+export interface MarkNotificationsReadResponse {
+  /** 本次标记为已读的通知数 */
+  marked?: number;
+}
+
+/** 一个时间桶内所有实例的指标汇总 */
+export interface MetricsHistoryPoint {
+  /** 用户缓存命中率 */
+  cache_hit_rate?: number;
+  /** 用户缓存命中数 */
+  cache_hits?: number;
+  /** 未命中后从数据库加载的次数 */
+  cache_loads?: number;
+  /** 用户缓存未命中数 */
+  cache_misses?: number;
+  /** 平均请求耗时（毫秒） */
+  http_avg_latency_ms?: number;
+  /** 各实例处理中请求数的最大值之和 */
+  http_in_flight?: number;
+  /** HTTP 请求数 */
+  http_requests?: number;
+  /** 状态码 5xx 的请求数 */
+  http_server_errors?: number;
+  /** 有快照的实例数 */
+  instances?: number;
+  /** 限流检查的请求数 */
+  rate_limit_requests?: number;
+  /** 被限流的请求数 */
+  rate_limit_throttled?: number;
+  /** 时间桶开始时间 */
+  time?: string;
+}
+
+/** 指标历史，按时间桶汇总所有实例的快照，供管理后台绘制趋势图 */
+export interface MetricsHistoryResponse {
+  /** 查询范围开始时间 */
+  from?: string;
+  /** 按时间排序的数据点，没有快照的时间桶省略 */
+  points?: Array<MetricsHistoryPoint>;
+  /** 时间桶长度（秒），查询范围超过原始快照保留时长时使用汇总快照 */
+  resolution?: number;
+  /** 查询范围结束时间 */
+  to?: string;
+}
+
+/** 站内信，由通知服务的站内渠道写入，用户在通知收件箱中查看 */
+export interface Notification {
+  /** 正文 */
+  body?: string;
+  /** 创建时间 */
+  created_at?: string;
+  /** 通知ID */
+  id?: string;
+  /** 已读时间，未读时为 null */
+  read_at?: string | null;
+  /** 标题 */
+  subject?: string;
+  /** 通知类型 */
+  type?: string;
+}
+
+/** 通知收件箱响应 */
+export interface NotificationListResponse {
+  /** 通知，按创建时间倒序 */
+  notifications?: Array<Notification>;
+  /** 分页信息 */
+  pagination?: Pagination;
+  /** 全部未读通知数 */
+  unread_count?: number;
+}
+
+/** 用户对一种通知类型在一个渠道上的投递偏好，没有记录时使用通知类型的默认渠道 */
+export interface NotificationPreference {
+  /** 渠道（email、sms、push、in_app） */
+  channel?: string;
+  /** 是否投递 */
+  enabled?: boolean;
+  /** 通知类型 */
+  type?: string;
+}
+
+/** 一种通知类型在一个渠道上的投递偏好 */
+export interface NotificationPreferenceRequest {
+  /** 渠道 */
+  channel: "email" | "sms" | "push" | "in_app";
+  /** 是否投递 */
+  enabled?: boolean;
+  /** 通知类型 */
+  type: string;
+}
+
+/** 在本服务注册的 OpenID Connect 客户端（依赖方） */
+export interface OAuthClient {
+  /** 是否为机密客户端 */
+  confidential?: boolean;
+  /** 创建时间 */
+  created_at?: string;
+  /** 创建者ID */
+  created_by?: string;
+  /** 客户端ID（client_id） */
+  id?: string;
+  /** 名称，显示在授权同意页面 */
+  name?: string;
+  /** 允许的回调地址，须完全匹配 */
+  redirect_uris?: Array<string>;
+  /** 更新时间 */
+  updated_at?: string;
+}
+
+/** 注册客户端的响应，客户端密钥只在此时返回一次 */
+export interface OAuthClientCreatedResponse {
+  /** 客户端 */
+  client?: OAuthClient;
+  /** 客户端密钥，公开客户端为空 */
+  client_secret?: string;
+}
+
+/** 注册 OpenID Connect 客户端的请求 */
+export interface OAuthClientRequest {
+  /** 是否为机密客户端，机密客户端会签发客户端密钥 */
+  confidential?: boolean;
+  /** 名称 */
+  name: string;
+  /** 允许的回调地址 */
+  redirect_uris: Array<string>;
+}
+
+/** 用户在授权同意页面做出的决定，附带原始授权请求 */
+export interface OAuthConsentRequest {
+  /** 是否同意授权 */
+  approve?: boolean;
+  /** 客户端ID */
+  client_id: string;
+  /** PKCE 挑战值 */
+  code_challenge: string;
+  /** PKCE 挑战方法，只支持 S256 */
+  code_challenge_method: string;
+  /** 写入 ID 令牌的随机数 */
+  nonce?: string;
+  /** 回调地址，须已注册 */
+  redirect_uri: string;
+  /** 响应类型，只支持 code */
+  response_type: string;
+  /** 以空格分隔的范围，须包含 openid */
+  scope: string;
+  /** 原样返回给客户端的状态 */
+  state?: string;
+}
+
+/** 授权同意页面需要展示的信息 */
+export interface OAuthConsentResponse {
+  /** 客户端ID */
+  client_id?: string;
+  /** 客户端名称 */
+  client_name?: string;
+  /** 客户端请求的范围 */
+  scopes?: Array<string>;
+}
+
+/** OAuth 2.0 错误响应（RFC 6749 第 5.2 节） */
+export interface OAuthErrorResponse {
+  /** 错误码 */
+  error?: string;
+  /** 错误描述 */
+  error_description?: string;
+}
+
+/** 授权决定的结果，客户端应将浏览器重定向到 redirect_to */
+export interface OAuthRedirectResponse {
+  /** 带有授权码或错误的回调地址 */
+  redirect_to?: string;
+}
+
+/** 令牌接口的响应（RFC 6749 第 5.1 节） */
+export interface OAuthTokenResponse {
+  /** 访问令牌，与登录签发的令牌相同 */
+  access_token?: string;
+  /** 访问令牌的有效期（秒） */
+  expires_in?: number;
+  /** ID 令牌 */
+  id_token?: string;
+  /** 授予的范围 */
+  scope?: string;
+  /** 令牌类型 */
+  token_type?: string;
+}
+
+/** OpenID Connect 发现文档 */
+export interface OIDCDiscovery {
+  /** 授权端点（授权同意页面） */
+  authorization_endpoint?: string;
+  /** 支持的声明 */
+  claims_supported?: Array<string>;
+  /** 支持的 PKCE 挑战方法 */
+  code_challenge_methods_supported?: Array<string>;
+  /** 支持的授权类型 */
+  grant_types_supported?: Array<string>;
+  /** ID 令牌的签名算法 */
+  id_token_signing_alg_values_supported?: Array<string>;
+  /** 签发者 */
+  issuer?: string;
+  /** 公钥集合 */
+  jwks_uri?: string;
+  /** 支持的响应类型 */
+  response_types_supported?: Array<string>;
+  /** 支持的范围 */
+  scopes_supported?: Array<string>;
+  /** 支持的主体标识类型 */
+  subject_types_supported?: Array<string>;
+  /** 令牌端点 */
+  token_endpoint?: string;
+  /** 令牌端点的客户端认证方式 */
+  token_endpoint_auth_methods_supported?: Array<string>;
+  /** 用户信息端点 */
+  userinfo_endpoint?: string;
+}
+
+/** 用户信息端点的响应 */
+export interface OIDCUserInfo {
+  /** 邮箱地址 */
+  email?: string;
+  /** 姓 */
+  family_name?: string;
+  /** 名 */
+  given_name?: string;
+  /** 全名 */
+  name?: string;
+  /** 用户名 */
+  preferred_username?: string;
+  /** 用户ID */
+  sub?: string;
+}
+
+/** 分页信息 */
+export interface Pagination {
+  /** 每页数量 */
+  limit?: number;
+  /** 当前页码 */
+  page?: number;
+  /** 总记录数 */
+  total?: number;
+  /** 总页数 */
+  total_pages?: number;
+}
+
+/** 管理员重置密码响应 */
+export interface PasswordResetResponse {
+  /** 临时密码，只返回一次 */
+  temporary_password?: string;
+  /** 用户ID */
+  user_id?: string;
+}
+
+/** 用户个人数据的完整导出，包含资料、站内信和通知偏好 */
+export interface PersonalDataArchive {
+  /** 生成时间 */
+  generated_at?: string;
+  /** 各通知类型在各渠道上的投递偏好 */
+  notification_preferences?: Array<NotificationPreference>;
+  /** 站内信，按创建时间倒序 */
+  notifications?: Array<Notification>;
+  /** 用户资料 */
+  user?: SafeUser;
+  /** 格式版本 */
+  version?: number;
+}
+
+/** 订阅套餐，由配置 billing.plans 定义 */
+export interface Plan {
+  /** 套餐包含的权益 */
+  entitlements?: Array<string>;
+  /** 套餐标识 */
+  id?: string;
+  /** 套餐名称 */
+  name?: string;
+  /** 套餐的用量配额，覆盖 quota.limits 中的同名配额 */
+  quotas?: Array<PlanQuota>;
+  /** 限流窗口内的请求数，0 表示使用租户或全局配置 */
+  rate_limit?: number;
+}
+
+/** 套餐的每月用量配额 */
+export interface PlanQuota {
+  /** 硬限制，0 表示不限制 */
+  hard?: number;
+  /** 配额名称 */
+  name?: string;
+  /** 软限制，0 表示没有软限制 */
+  soft?: number;
+}
+
+/** 一张表的保留策略 */
+export interface PolicyStatus {
+  retention?: string;
+  table?: string;
+}
+
+/** aggregates the flagged requests of one route */
+export interface QueryOffender {
+  flagged_requests?: number;
+  last_correlation_id?: string;
+  last_queries?: number;
+  last_seen?: string;
+  max_queries?: number;
+  method?: string;
+  n_plus_one_requests?: number;
+  route?: string;
+  stack?: Array<string>;
+  top_repetitions?: number;
+  top_statement?: string;
+}
+
+/** represents the current rate limiting configuration */
+export interface RateLimitConfig {
+  algorithm?: string;
+  burst?: number;
+  enabled?: boolean;
+  mode?: string;
+  requests_per_minute?: number;
+  /** 纳秒 */
+  window_size?: number;
+}
+
+/** 管理员在运行时设置的限制，优先于配置文件；零值字段沿用配置文件中的值 */
+export interface RateLimitOverrides {
+  /** 匿名用户每个时间窗口的请求数 */
+  anonymous?: number;
+  /** 认证用户每个时间窗口的请求数 */
+  authenticated?: number;
+  /** enforce、warn 或 shadow */
+  mode?: string;
+  /** 按路由模板（如 /api/v1/users/:id）单独计数的限制 */
+  routes?: Record<string, RateLimitTier>;
+  /** 最后修改时间 */
+  updated_at?: string;
+  /** 最后修改的管理员ID */
+  updated_by?: string;
+  /** 时间窗口，如 30s、1m */
+  window?: string;
+}
+
+/** represents a single rate limit request/check */
+export interface RateLimitRequest {
+  allowed?: boolean;
+  current_count?: number;
+  /** 纳秒 */
+  duration?: number;
+  endpoint?: string;
+  ip?: string;
+  limit?: number;
+  reason?: string;
+  timestamp?: string;
+  user_id?: string;
+  /** 纳秒 */
+  window_size?: number;
+}
+
+/** 单个路由的速率限制，0 表示该类用户沿用全局限制 */
+export interface RateLimitRouteLimit {
+  /** 匿名用户每个时间窗口的请求数 */
+  anonymous?: number;
+  /** 认证用户每个时间窗口的请求数 */
+  authenticated?: number;
+}
+
+/** 速率限制当前生效的参数和管理员设置的覆盖 */
+export interface RateLimitState {
+  /** 是否启用 */
+  enabled?: boolean;
+  /** 生效的全局限制 */
+  limits?: RateLimitTier;
+  /** 生效的模式 */
+  mode?: string;
+  /** 管理员设置的覆盖，为空表示完全使用配置文件 */
+  overrides?: RateLimitOverrides;
+  /** 覆盖是否保存在 Redis 中并同步到所有实例 */
+  persisted?: boolean;
+  /** 生效的按路由限制 */
+  routes?: Record<string, RateLimitTier>;
+  /** 生效的时间窗口 */
+  window?: string;
+}
+
+/** represents aggregated rate limit statistics */
+export interface RateLimitStats {
+  allow_rate?: number;
+  allowed_requests?: number;
+  /** 纳秒 */
+  avg_check_duration?: number;
+  configuration?: RateLimitConfig;
+  /** Effectiveness score */
+  effective_rate?: number;
+  /** 纳秒 */
+  max_check_duration?: number;
+  /** 纳秒 */
+  min_check_duration?: number;
+  recent_requests?: Array<RateLimitRequest>;
+  throttle_rate?: number;
+  throttled_requests?: number;
+  top_violating_ips?: Array<ViolationTracker>;
+  top_violating_users?: Array<ViolationTracker>;
+  total_requests?: number;
+}
+
+/** 匿名和认证用户的请求限制，0 表示沿用上一级的限制 */
+export interface RateLimitTier {
+  /** 匿名用户每个时间窗口的请求数 */
+  anonymous?: number;
+  /** 认证用户每个时间窗口的请求数 */
+  authenticated?: number;
+}
+
+/** aggregates the rate limit checks of one interval */
+export interface RateLimitTimeSeriesPoint {
+  /** 纳秒 */
+  avg_check_duration?: number;
+  /** 纳秒 */
+  max_check_duration?: number;
+  /** 纳秒 */
+  p95_check_duration?: number;
+  requests?: number;
+  start?: string;
+  /** percentage */
+  throttle_rate?: number;
+  throttled?: number;
+}
+
+/** 刷新令牌请求 */
+export interface RefreshTokenRequest {
+  /** 登录或上次刷新时返回的刷新令牌 */
+  refresh_token: string;
+}
+
+/** 刷新令牌响应，原刷新令牌随即失效 */
+export interface RefreshTokenResponse {
+  /** 访问令牌有效期（秒） */
+  expires_in?: number;
+  /** 新的刷新令牌 */
+  refresh_token?: string;
+  /** 新的访问令牌 */
+  token?: string;
+}
+
+/** 注册请求 */
+export interface RegisterRequest {
+  /** 邮箱地址 */
+  email: string;
+  /** 名 */
+  first_name?: string;
+  /** 姓 */
+  last_name?: string;
+  /** 密码 */
+  password: string;
+  /** 用户名 */
+  username: string;
+}
+
+/** represents the thresholds, totals and worst offending routes */
+export interface RequestQueryStats {
+  flagged_requests?: number;
+  max_queries?: number;
+  offenders?: Array<QueryOffender>;
+  repeat_threshold?: number;
+  requests?: number;
+}
+
+/** 恢复备份的请求，confirm 须与路径中的备份ID相同，避免误操作覆盖数据库 */
+export interface RestoreBackupRequest {
+  /** 要恢复的备份ID */
+  confirm: string;
+}
+
+/** 本实例最近一次恢复的状态 */
+export interface RestoreStatus {
+  /** 恢复的备份ID */
+  backup_id?: string;
+  /** 失败原因 */
+  error?: string;
+  /** 结束时间 */
+  finished_at?: string | null;
+  /** 发起恢复的管理员ID */
+  requested_by?: string;
+  /** 开始时间 */
+  started_at?: string | null;
+  /** 状态（idle、running、completed、failed） */
+  state?: string;
+}
+
+/** 一张表的清理结果 */
+export interface Result {
+  /** 早于该时间的行被删除 */
+  cutoff?: string;
+  /** 清理失败的原因，失败前已删除的行数计入 Rows */
+  error?: string;
+  /** 保留期限，如 720h0m0s */
+  retention?: string;
+  /** 删除的行数，试运行时为可删除的行数 */
+  rows?: number;
+  table?: string;
+}
+
+/** 一次清理的报告 */
+export interface RetentionReport {
+  /** 手动触发清理的管理员ID */
+  actor_id?: string;
+  dry_run?: boolean;
+  finished_at?: string;
+  results?: Array<Result>;
+  started_at?: string;
+}
+
+/** 保留策略和最近一次清理的报告 */
+export interface RetentionStatus {
+  /** 定期清理是否为试运行 */
+  dry_run?: boolean;
+  /** 定期清理的间隔，如 1h0m0s */
+  interval?: string;
+  /** 最近一次清理（定期或手动）的报告，本实例尚未清理时为空 */
+  last_report?: RetentionReport;
+  policies?: Array<PolicyStatus>;
+}
+
+/** 不包含敏感信息的用户对象 */
+export interface SafeUser {
+  /** 头像URL */
+  avatar?: string;
+  /** 创建时间 */
+  created_at?: string;
+  /** 邮箱地址 */
+  email?: string;
+  /** 名 */
+  first_name?: string;
+  /** 用户ID */
+  id?: string;
+  /** 是否激活 */
+  is_active?: boolean;
+  /** 是否为管理员 */
+  is_admin?: boolean;
+  /** 最后登录时间 */
+  last_login?: string | null;
+  /** 姓 */
+  last_name?: string;
+  /** 所属租户ID */
+  tenant_id?: string | null;
+  /** 更新时间 */
+  updated_at?: string;
+  /** 用户名 */
+  username?: string;
+}
+
+/** 维护状态 */
+export interface State {
+  /** 是否处于维护中 */
+  enabled?: boolean;
+  /** 预计结束时间，为空表示未知 */
+  ends_at?: string | null;
+  /** 展示给用户的说明 */
+  message?: string;
+  /** admin 由管理员开启；config 由配置开启 */
+  source?: string;
+  /** 开始时间 */
+  started_at?: string | null;
+  /** 最后修改状态的管理员ID */
+  updated_by?: string;
+}
+
+/** 排空步骤的进度 */
+export interface Step {
+  /** 结束时间 */
+  completed_at?: string | null;
+  /** 失败原因 */
+  error?: string;
+  /** stop_accepting、in_flight_requests 或 background_jobs */
+  name?: string;
+  /** 开始时间 */
+  started_at?: string | null;
+  /** pending、running、done 或 failed */
+  status?: string;
+}
+
+/** Stripe webhook 的处理结果 */
+export interface StripeWebhookResponse {
+  /** 事件ID */
+  event_id?: string;
+  /** applied 已同步；ignored 不处理的事件类型或无法关联到用户；stale 比已同步的事件更旧 */
+  outcome?: string;
+}
+
+/** 用户的订阅，由 Stripe webhook 同步，每个用户最多一条 */
+export interface Subscription {
+  /** 是否在周期结束时取消 */
+  cancel_at_period_end?: boolean;
+  /** 创建时间 */
+  created_at?: string;
+  /** 当前计费周期的结束时间 */
+  current_period_end?: string | null;
+  /** 订阅ID */
+  id?: string;
+  /** 套餐标识 */
+  plan_id?: string;
+  /** 订阅状态 */
+  status?: string;
+  /** 更新时间 */
+  updated_at?: string;
+  /** 用户ID */
+  user_id?: string;
+}
+
+/** 当前用户的套餐和订阅 */
+export interface SubscriptionResponse {
+  /** 当前生效的套餐，没有有效订阅时为默认套餐 */
+  plan?: Plan;
+  /** 订阅，从未订阅时为空 */
+  subscription?: Subscription;
+}
+
+/** 修改功能开关请求，替换开关的完整定义 */
+export interface UpdateFeatureFlagRequest {
+  /** 说明 */
+  description?: string;
+  /** 总开关 */
+  enabled?: boolean;
+  /** 按用户灰度的比例（0-100） */
+  percentage?: number;
+  /** 始终开启的用户ID */
+  users: Array<string>;
+}
+
+/** 修改日志级别请求，module 为空时修改全局级别，level 为空时移除模块的级别覆盖 */
+export interface UpdateLogLevelRequest {
+  /** 日志级别 */
+  level?: "debug" | "info" | "warn" | "error";
+  /** 模块名称 */
+  module?: string;
+}
+
+/** 修改通知偏好请求，只修改列出的通知类型和渠道 */
+export interface UpdateNotificationPreferencesRequest {
+  /** 偏好 */
+  preferences: Array<NotificationPreferenceRequest>;
+}
+
+/** 修改运行时速率限制请求，替换管理员设置的全部覆盖；字段为 0 或空时沿用配置文件中的值 */
+export interface UpdateRateLimitsRequest {
+  /** 匿名用户每个时间窗口的请求数 */
+  anonymous?: number;
+  /** 认证用户每个时间窗口的请求数 */
+  authenticated?: number;
+  /** enforce、warn 或 shadow */
+  mode?: "enforce" | "warn" | "shadow";
+  /** 按路由模板（如 /api/v1/users/:id）单独计数的限制 */
+  routes?: Record<string, RateLimitRouteLimit>;
+  /** 时间窗口，如 30s、1m */
+  window?: string;
+}
+
+/** 更新用户请求 */
+export interface UpdateUserRequest {
+  /** 头像URL */
+  avatar?: string;
+  /** 名 */
+  first_name?: string;
+  /** 姓 */
+  last_name?: string;
+  /** 用户名 */
+  username?: string;
+}
+
+/** 主体在当前周期内的用量 */
+export interface Usage {
+  /** 硬限制，0 表示不限制 */
+  hard?: number;
+  /** 本周期开始时间 */
+  period_start?: string;
+  /** 配额名称 */
+  quota?: string;
+  /** 剩余用量，不限制时为 -1 */
+  remaining?: number;
+  /** 用量重置时间，即下一周期开始时间 */
+  reset_at?: string;
+  /** 软限制，0 表示没有软限制 */
+  soft?: number;
+  /** 是否已达到软限制 */
+  soft_reached?: boolean;
+  /** 本周期已用量 */
+  used?: number;
+}
+
+/** tracks rate limit violations for a specific identifier */
+export interface ViolationTracker {
+  /** Autonomous system of an IP, when GeoIP lookup is enabled */
+  asn?: number;
+  /** Client country of an IP, when GeoIP lookup is enabled */
+  country?: string;
+  first_violation?: string;
+  /** IP or user ID */
+  identifier?: string;
+  last_violation?: string;
+  total_violations?: number;
+  violation_history?: Array<string>;
+}
+
+/** 缓存预热请求，datasets 为空时预热全部数据集 */
+export interface WarmCacheRequest {
+  /** 数据集名称 */
+  datasets: Array<string>;
+}
+
+/** 单个数据集的预热进度 */
+export interface WarmDatasetStatus {
+  /** 已预热的条目数 */
+  done?: number;
+  /** 耗时（毫秒） */
+  duration_ms?: number;
+  /** 失败原因 */
+  error?: string;
+  /** 数据集名称 */
+  name?: string;
+  /** 状态 */
+  state?: "pending" | "running" | "completed" | "failed";
+  /** 条目总数，未知时为 0 */
+  total?: number;
+}
+
+/** 一次预热任务的进度 */
+export interface WarmStatus {
+  /** 各数据集的进度 */
+  datasets?: Array<WarmDatasetStatus>;
+  /** 结束时间 */
+  finished_at?: string | null;
+  /** 任务编号，每次预热递增 */
+  id?: number;
+  /** 开始时间 */
+  started_at?: string;
+  /** 状态 */
+  state?: "pending" | "running" | "completed" | "failed";
+  /** 触发方式：startup 或 api */
+  trigger?: string;
+}
+
+/** announcementAdminListAnnouncements 的查询参数和请求头 */
+export interface AnnouncementAdminListAnnouncementsParams {
+  /** 页码 */
+  page?: number;
+  /** 每页项目数量 */
+  limit?: number;
+}
+
+/** backupListBackups 的查询参数和请求头 */
+export interface BackupListBackupsParams {
+  /** 页码 */
+  page?: number;
+  /** 每页项目数量 */
+  limit?: number;
+}
+
+/** cacheFlush 的查询参数和请求头 */
+export interface CacheFlushParams {
+  /** Confirm flushing the whole cache server when the driver cannot scope the flush */
+  force?: boolean;
+}
+
+/** cacheListKeys 的查询参数和请求头 */
+export interface CacheListKeysParams {
+  /** Glob pattern */
+  pattern?: string;
+  /** Approximate number of keys per page (1-1000) */
+  limit?: number;
+  /** Cursor returned by the previous page */
+  cursor?: string;
+}
+
+/** importImportUsers 的查询参数和请求头 */
+export interface ImportImportUsersParams {
+  /** 文件格式（csv、json），默认按文件扩展名判断 */
+  format?: string;
+  /** 只校验不写入 */
+  dry_run?: boolean;
+}
+
+/** metricsHistory 的查询参数和请求头 */
+export interface MetricsHistoryParams {
+  /** 查询最近多少小时，默认 24 */
+  hours?: number;
+}
+
+/** metricsHTTPMetrics 的查询参数和请求头 */
+export interface MetricsHTTPMetricsParams {
+  /** 只返回该路由模板的统计，如 /api/v1/users/:id */
+  route?: string;
+  /** 只返回该方法的统计，如 GET */
+  method?: string;
+}
+
+/** metricsRateLimitTimeSeries 的查询参数和请求头 */
+export interface MetricsRateLimitTimeSeriesParams {
+  /** 查询最近多长时间，Go 时长格式，最大 24h */
+  window?: string;
+  /** 每个数据点的时长，Go 时长格式，不超过 window */
+  resolution?: string;
+}
+
+/** adminOverviewOverview 的查询参数和请求头 */
+export interface AdminOverviewOverviewParams {
+  /** 跳过缓存重新采集 */
+  refresh?: boolean;
+}
+
+/** retentionRunRetention 的查询参数和请求头 */
+export interface RetentionRunRetentionParams {
+  /** 是否只统计可删除的行数 */
+  dry_run?: boolean;
+}
+
+/** adminUserListUsers 的查询参数和请求头 */
+export interface AdminUserListUsersParams {
+  /** 搜索关键字 */
+  q?: string;
+  /** 是否激活 */
+  active?: boolean;
+  /** 角色 */
+  role?: "user" | "admin";
+  /** 页码 */
+  page?: number;
+  /** 每页项目数量 */
+  limit?: number;
+}
+
+/** adminUserStreamUsers 的查询参数和请求头 */
+export interface AdminUserStreamUsersParams {
+  /** 搜索关键字 */
+  q?: string;
+  /** 是否激活 */
+  active?: boolean;
+  /** 角色 */
+  role?: "user" | "admin";
+}
+
+/** billingStripeWebhook 的查询参数和请求头 */
+export interface BillingStripeWebhookParams {
+  /** Stripe 签名，如 t=1492774577,v1=5257a869... */
+  "Stripe-Signature": string;
+}
+
+/** oIDCAuthorize 的查询参数和请求头 */
+export interface OIDCAuthorizeParams {
+  /** 响应类型 */
+  response_type: "code";
+  /** 客户端ID */
+  client_id: string;
+  /** 回调地址 */
+  redirect_uri: string;
+  /** 以空格分隔的范围 */
+  scope: string;
+  /** 原样返回给客户端的状态 */
+  state?: string;
+  /** 写入 ID 令牌的随机数 */
+  nonce?: string;
+  /** PKCE 挑战值 */
+  code_challenge: string;
+  /** PKCE 挑战方法 */
+  code_challenge_method: "S256";
+}
+
+/** userGetUsers 的查询参数和请求头 */
+export interface UserGetUsersParams {
+  /** 页码 */
+  page?: number;
+  /** 每页项目数量 */
+  limit?: number;
+}
+
+/** profileGetProfile 的查询参数和请求头 */
+export interface ProfileGetProfileParams {
+  /** 上次获取时的 ETag */
+  "If-None-Match"?: string;
+}
+
+/** profileUpdateProfile 的查询参数和请求头 */
+export interface ProfileUpdateProfileParams {
+  /** 获取资料时的 ETag */
+  "If-Match"?: string;
+}
+
+/** notificationListNotifications 的查询参数和请求头 */
+export interface NotificationListNotificationsParams {
+  /** 只返回未读通知 */
+  unread?: boolean;
+  /** 页码 */
+  page?: number;
+  /** 每页项目数量 */
+  limit?: number;
+}
+
+/** userUpdateUser 的查询参数和请求头 */
+export interface UserUpdateUserParams {
+  /** ETag returned when the user was fetched */
+  "If-Match"?: string;
+}
+
+/** 接口客户端，方法名与 operationId 一致 */
+export class ApiClient {
+  private readonly baseUrl: string;
+  private readonly tokenProvider?: TokenProvider;
+  private readonly fetchImpl: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.tokenProvider =
+      typeof options.token === "string" ? { getToken: async () => options.token as string } : options.token;
+    this.fetchImpl = options.fetch ?? fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+  /** 发送请求并解析响应，需要认证的请求收到 401 后使令牌失效并重试一次 */
+  private async request<T>(spec: RequestSpec, options?: RequestOptions, retried = false): Promise<T> {
+    const url = new URL(this.baseUrl + spec.path);
+    for (const [key, value] of Object.entries(spec.query ?? {})) {
+      if (value !== undefined && value !== null && value !== "") url.searchParams.set(key, String(value));
+    }
+    const headers = new Headers(this.headers);
+    headers.set("Accept", spec.text ? "text/plain" : "application/json");
+    for (const [key, value] of Object.entries(spec.headers ?? {})) {
+      if (value !== undefined && value !== null && value !== "") headers.set(key, String(value));
+    }
+    for (const [key, value] of Object.entries(options?.headers ?? {})) headers.set(key, value);
+    let token: string | undefined;
+    if (spec.auth && this.tokenProvider) {
+      token = await this.tokenProvider.getToken();
+      if (token) headers.set("Authorization", "Bearer " + token);
+    }
+    let body: BodyInit | undefined;
+    if (spec.form) {
+      body = spec.form;
+    } else if (spec.body !== undefined) {
+      headers.set("Content-Type", "application/json");
+      body = JSON.stringify(spec.body);
+    }
+
+    const response = await this.fetchImpl(url, { method: spec.method, headers, body, signal: options?.signal });
+    if (response.status === 401 && token && !retried && this.tokenProvider?.invalidate) {
+      this.tokenProvider.invalidate(token);
+      return this.request<T>(spec, options, true);
+    }
+    if (response.status === 304) throw new NotModifiedError();
+    if (!response.ok) throw await parseError(response);
+    options?.onResponse?.(response);
+
+    if (spec.text) return (await response.text()) as T;
+    const text = await response.text();
+    if (text === "") return undefined as T;
+    const data = JSON.parse(text);
+    return (spec.envelope ? data.data : data) as T;
+  }
+
+  /**
+   * JSON Web Key Set
+   *
+   * Publish the public keys used to sign access tokens so that other services can verify them. Keys are identified by the kid token header; HMAC keys are never published.
+   *
+   * GET /.well-known/jwks.json
+   */
+  async authJWKS(options?: RequestOptions): Promise<JWKS> {
+    return this.request<JWKS>(
+      {
+        method: "GET",
+        path: "/.well-known/jwks.json",
+      },
+      options,
+    );
+  }
+
+  /**
+   * OpenID Connect 发现文档
+   *
+   * 返回 OpenID Connect 提供方的元数据，依赖方据此找到授权、令牌、用户信息端点和公钥集合。authorization_endpoint 为前端的授权同意页面
+   *
+   * GET /.well-known/openid-configuration
+   */
+  async oIDCDiscovery(options?: RequestOptions): Promise<OIDCDiscovery> {
+    return this.request<OIDCDiscovery>(
+      {
+        method: "GET",
+        path: "/.well-known/openid-configuration",
+      },
+      options,
+    );
+  }
+
+  /**
+   * 列出公告
+   *
+   * 分页列出全部公告，包括尚未生效和已过期的公告，按创建时间倒序（仅管理员）
+   *
+   * GET /api/v1/admin/announcements
+   */
+  async announcementAdminListAnnouncements(params?: AnnouncementAdminListAnnouncementsParams, options?: RequestOptions): Promise<AnnouncementListResponse> {
+    return this.request<AnnouncementListResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/announcements",
+        query: { page: params?.page, limit: params?.limit },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 发布公告
+   *
+   * 创建公告（仅管理员）。roles 为空时对所有用户可见，tenant_id 为空时对所有租户可见；starts_at 为空时立即生效，ends_at 为空时一直有效。本实例的订阅者立即收到推送
+   *
+   * POST /api/v1/admin/announcements
+   */
+  async announcementCreateAnnouncement(body: AnnouncementRequest, options?: RequestOptions): Promise<Announcement> {
+    return this.request<Announcement>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/announcements",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取公告
+   *
+   * 获取指定公告（仅管理员）
+   *
+   * GET /api/v1/admin/announcements/{id}
+   */
+  async announcementAdminGetAnnouncement(id: string, options?: RequestOptions): Promise<Announcement> {
+    return this.request<Announcement>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/announcements/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 修改公告
+   *
+   * 替换公告的完整定义（仅管理员），本实例的订阅者立即收到推送，其他实例在缓存失效后的下一次检查时推送
+   *
+   * PUT /api/v1/admin/announcements/{id}
+   */
+  async announcementUpdateAnnouncement(id: string, body: AnnouncementRequest, options?: RequestOptions): Promise<Announcement> {
+    return this.request<Announcement>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/announcements/" + encodeURIComponent(id),
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 删除公告
+   *
+   * 删除公告（仅管理员），要提前结束公告也可以把 ends_at 修改为当前时间
+   *
+   * DELETE /api/v1/admin/announcements/{id}
+   */
+  async announcementDeleteAnnouncement(id: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/announcements/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 列出数据库备份
+   *
+   * 分页列出手动和自动创建的备份，包括执行中和失败的备份，按创建时间倒序（仅管理员）。过期的备份由定期清理删除
+   *
+   * GET /api/v1/admin/backups
+   */
+  async backupListBackups(params?: BackupListBackupsParams, options?: RequestOptions): Promise<BackupListResponse> {
+    return this.request<BackupListResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/backups",
+        query: { page: params?.page, limit: params?.limit },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 创建数据库备份
+   *
+   * 在后台执行 pg_dump 并把加密后的备份文件保存到对象存储（仅管理员）。轮询 GET /api/v1/admin/backups/{id} 查看进度；每个实例同一时间只执行一个备份或恢复
+   *
+   * POST /api/v1/admin/backups
+   */
+  async backupCreateBackup(options?: RequestOptions): Promise<Backup> {
+    return this.request<Backup>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/backups",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 查询数据库备份
+   *
+   * 返回备份的状态、大小和 SHA-256 校验和（仅管理员），校验和针对解密后的备份文件
+   *
+   * GET /api/v1/admin/backups/{id}
+   */
+  async backupGetBackup(id: string, options?: RequestOptions): Promise<Backup> {
+    return this.request<Backup>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/backups/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 从备份恢复数据库
+   *
+   * 在后台用已完成的备份替换数据库的内容（仅管理员），confirm 须与路径中的备份ID相同。恢复在单个事务中执行，失败时数据库保持恢复前的状态；备份列表不受恢复影响。恢复期间其他实例仍在处理请求，应先开启维护模式。轮询 GET /api/v1/admin/restore 查看进度
+   *
+   * POST /api/v1/admin/backups/{id}/restore
+   */
+  async backupRestoreBackup(id: string, body: RestoreBackupRequest, options?: RequestOptions): Promise<RestoreStatus> {
+    return this.request<RestoreStatus>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/backups/" + encodeURIComponent(id) + "/restore",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 列出封禁
+   *
+   * 列出因限流违规被自动封禁的 IP 和用户，按到期时间排序（仅管理员）
+   *
+   * GET /api/v1/admin/bans
+   */
+  async banListListBans(options?: RequestOptions): Promise<Array<Ban>> {
+    return this.request<Array<Ban>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/bans",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 解除封禁
+   *
+   * 提前解除 IP 或用户的封禁（仅管理员），解除后的一个统计窗口内不会因之前的违规再次自动封禁
+   *
+   * DELETE /api/v1/admin/bans/{type}/{identifier}
+   */
+  async banListUnban(type: string, identifier: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/bans/" + encodeURIComponent(type) + "/" + encodeURIComponent(identifier),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Flush cache
+   *
+   * Delete every key under the application key prefix (admin only). Drivers that cannot scope the flush to the prefix (memcached) wipe the whole cache server, so the request must then be confirmed with force=true.
+   *
+   * POST /api/v1/admin/cache/flush
+   */
+  async cacheFlush(params?: CacheFlushParams, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/cache/flush",
+        query: { force: params?.force },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * List cache keys
+   *
+   * List the cache keys matching a glob pattern, without the application key prefix (admin only). Keys are read incrementally with SCAN, so listing never blocks the cache server; each page holds about limit keys, sorted within the page. Pass the returned cursor to fetch the next page; an empty cursor means the scan is complete. Keys changed during the scan may be returned twice or not at all. Not supported by the memcached driver.
+   *
+   * GET /api/v1/admin/cache/keys
+   */
+  async cacheListKeys(params?: CacheListKeysParams, options?: RequestOptions): Promise<CacheKeysResponse> {
+    return this.request<CacheKeysResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/cache/keys",
+        query: { pattern: params?.pattern, limit: params?.limit, cursor: params?.cursor },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Delete cache key
+   *
+   * Delete a single cache key, given without the application key prefix (admin only). The next read reloads the value from the database.
+   *
+   * DELETE /api/v1/admin/cache/keys/{key}
+   */
+  async cacheDeleteKey(key: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/cache/keys/" + encodeURIComponent(key),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Get cache statistics
+   *
+   * Get the statistics reported by the cache driver, together with the optional capabilities it supports (admin only)
+   *
+   * GET /api/v1/admin/cache/stats
+   */
+  async cacheGetStats(options?: RequestOptions): Promise<CacheStatsResponse> {
+    return this.request<CacheStatsResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/cache/stats",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Get cache warm-up progress
+   *
+   * Get the progress of the running or most recent cache warm-up, including the startup warm-up (admin only)
+   *
+   * GET /api/v1/admin/cache/warm
+   */
+  async cacheWarmStatus(options?: RequestOptions): Promise<WarmStatus> {
+    return this.request<WarmStatus>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/cache/warm",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Warm cache
+   *
+   * Start warming the cache in the background (admin only). Without datasets every registered dataset is warmed; otherwise only the named ones. Only one warm-up runs at a time. Poll GET /api/v1/admin/cache/warm for progress.
+   *
+   * POST /api/v1/admin/cache/warm
+   */
+  async cacheWarm(body: WarmCacheRequest, options?: RequestOptions): Promise<WarmStatus> {
+    return this.request<WarmStatus>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/cache/warm",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取排空进度
+   *
+   * 返回当前实例的排空进度和处理中的请求数（仅管理员）。state 为 drained 时进程可以安全退出
+   *
+   * GET /api/v1/admin/drain
+   */
+  async drainGetDrain(options?: RequestOptions): Promise<DrainStatus> {
+    return this.request<DrainStatus>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/drain",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 排空实例
+   *
+   * 在后台排空处理请求的实例（仅管理员）：先将就绪检查标记为失败并等待 server.shutdown.pre_stop_delay 秒让负载均衡器摘除流量， 再等待处理中的请求完成（最多 server.shutdown.drain_timeout 秒），最后停止后台任务和事件消费者。排空期间端口保持打开，可通过 GET 轮询进度。 排空完成后 server.shutdown.exit_after_drain 为 true 时进程自动退出，否则等待 SIGTERM 并跳过已完成的关闭阶段。排空只执行一次，重复调用返回 200 和当前进度
+   *
+   * POST /api/v1/admin/drain
+   */
+  async drainStartDrain(options?: RequestOptions): Promise<DrainStatus> {
+    return this.request<DrainStatus>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/drain",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 导出用户数据
+   *
+   * 创建导出任务并在后台生成 CSV、JSON 或 XLSX 文件（仅管理员），筛选条件与用户列表相同。轮询 GET /api/v1/admin/exports/{id} 查看进度，完成后响应中的 download_url 为限时有效的下载链接。XLSX 最多导出 1048575 行
+   *
+   * POST /api/v1/admin/exports
+   */
+  async exportCreateExport(body: ExportRequest, options?: RequestOptions): Promise<Export> {
+    return this.request<Export>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/exports",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 查询导出任务
+   *
+   * 返回导出任务的状态和进度（仅管理员）。任务完成后 download_url 为新签发的下载链接，链接在 exports.url_ttl 后失效，过期后重新查询即可获得新链接；文件在 expires_at 后删除
+   *
+   * GET /api/v1/admin/exports/{id}
+   */
+  async exportGetExport(id: string, options?: RequestOptions): Promise<Export> {
+    return this.request<Export>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/exports/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 列出功能开关
+   *
+   * 列出所有功能开关的定义，按键排序（仅管理员）
+   *
+   * GET /api/v1/admin/feature-flags
+   */
+  async featureFlagListFeatureFlags(options?: RequestOptions): Promise<Array<Flag>> {
+    return this.request<Array<Flag>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/feature-flags",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 创建功能开关
+   *
+   * 创建功能开关（仅管理员）。percentage 为按用户ID哈希分桶开启的比例，100 表示对所有请求开启；users 中的用户始终开启；enabled 为 false 时对所有用户关闭
+   *
+   * POST /api/v1/admin/feature-flags
+   */
+  async featureFlagCreateFeatureFlag(body: CreateFeatureFlagRequest, options?: RequestOptions): Promise<Flag> {
+    return this.request<Flag>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/feature-flags",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取功能开关
+   *
+   * 获取指定功能开关的定义（仅管理员）
+   *
+   * GET /api/v1/admin/feature-flags/{key}
+   */
+  async featureFlagGetFeatureFlag(key: string, options?: RequestOptions): Promise<Flag> {
+    return this.request<Flag>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/feature-flags/" + encodeURIComponent(key),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 修改功能开关
+   *
+   * 替换功能开关的完整定义（仅管理员），所有实例在收到变更通知或本地缓存过期后生效
+   *
+   * PUT /api/v1/admin/feature-flags/{key}
+   */
+  async featureFlagUpdateFeatureFlag(key: string, body: UpdateFeatureFlagRequest, options?: RequestOptions): Promise<Flag> {
+    return this.request<Flag>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/feature-flags/" + encodeURIComponent(key),
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 删除功能开关
+   *
+   * 删除功能开关（仅管理员），删除后的开关对所有用户视为关闭
+   *
+   * DELETE /api/v1/admin/feature-flags/{key}
+   */
+  async featureFlagDeleteFeatureFlag(key: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/feature-flags/" + encodeURIComponent(key),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 结束模拟登录
+   *
+   * 使用模拟登录令牌调用，吊销该令牌并记录审计事件，之后该令牌不能再使用。普通令牌不能调用此接口。
+   *
+   * POST /api/v1/admin/impersonation/stop
+   */
+  async adminUserStopImpersonation(options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/impersonation/stop",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 批量导入用户
+   *
+   * 以 multipart/form-data 上传 CSV 或 JSON 文件批量创建用户（仅管理员）。CSV 须包含 username、email、password 列，可包含 first_name、last_name 列；JSON 为对象数组，字段与注册请求相同。每行按注册请求的规则校验，邮箱（不区分大小写）或用户名与文件中之前的行或现有用户重复的行被跳过；有效的行分批写入，每批一个事务。dry_run=true 时只校验并返回错误报告。导入的用户为已激活的普通用户，不发送欢迎通知
+   *
+   * POST /api/v1/admin/imports
+   */
+  async importImportUsers(file: Blob, fileFilename?: string, params?: ImportImportUsersParams, options?: RequestOptions): Promise<ImportReport> {
+    const form = new FormData();
+    form.append("file", file, fileFilename ?? "file");
+    return this.request<ImportReport>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/imports",
+        query: { format: params?.format, dry_run: params?.dry_run },
+        form,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Get log levels
+   *
+   * Get the global log level and the per-module level overrides currently in effect (admin only)
+   *
+   * GET /api/v1/admin/logging/level
+   */
+  async loggingGetLevels(options?: RequestOptions): Promise<LevelSettings> {
+    return this.request<LevelSettings>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/logging/level",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Update log level
+   *
+   * Change the log level at runtime without a restart (admin only). Without a module the global level is changed and is restored from configuration on the next reload; with a module the level overrides the global level for that module, and an empty level removes the override.
+   *
+   * PUT /api/v1/admin/logging/level
+   */
+  async loggingUpdateLevel(body: UpdateLogLevelRequest, options?: RequestOptions): Promise<LevelSettings> {
+    return this.request<LevelSettings>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/logging/level",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取维护状态
+   *
+   * 返回当前生效的维护状态，source 为 config 表示由配置开启（仅管理员）
+   *
+   * GET /api/v1/admin/maintenance
+   */
+  async maintenanceGetMaintenance(options?: RequestOptions): Promise<State> {
+    return this.request<State>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/maintenance",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 开启维护
+   *
+   * 开启维护模式（仅管理员）。维护期间除放行路径外的请求返回 503 MAINTENANCE_MODE，携带 X-Maintenance-Bypass 旁路令牌的请求不受影响。 已在维护中时更新说明和预计结束时间，开始时间不变；缓存是 Redis 时其他实例在 maintenance.refresh_interval 内生效
+   *
+   * PUT /api/v1/admin/maintenance
+   */
+  async maintenanceEnableMaintenance(body: EnableMaintenanceRequest, options?: RequestOptions): Promise<State> {
+    return this.request<State>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/maintenance",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 结束维护
+   *
+   * 结束管理员开启的维护（仅管理员）。由配置开启的维护需要修改 maintenance.active 才能结束，此时返回 409
+   *
+   * DELETE /api/v1/admin/maintenance
+   */
+  async maintenanceDisableMaintenance(options?: RequestOptions): Promise<State> {
+    return this.request<State>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/maintenance",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 按国家统计流量
+   *
+   * 按客户端 IP 所在国家返回请求数、4xx 和 5xx 数以及响应字节数（仅管理员），需要启用 GeoIP 查询。无法定位的请求计入 unknown。结果按请求数从多到少排序。同样的请求数以 Prometheus 格式在 /api/v1/metrics/prometheus 的 http_requests_by_country_total 导出。
+   *
+   * GET /api/v1/admin/metrics/countries
+   */
+  async metricsCountries(options?: RequestOptions): Promise<Array<CountryStats>> {
+    return this.request<Array<CountryStats>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/metrics/countries",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 指标历史
+   *
+   * 返回最近 hours 小时内按时间桶汇总的请求数、5xx 数、平均耗时、限流和用户缓存统计，供管理后台绘制趋势图（仅管理员）。数据来自定期写入数据库的指标快照，进程重启后仍然保留。查询范围不超过原始快照保留时长时按采集间隔返回，否则按汇总间隔返回，尚未结束的汇总时间桶不包含在内。
+   *
+   * GET /api/v1/admin/metrics/history
+   */
+  async metricsHistory(params?: MetricsHistoryParams, options?: RequestOptions): Promise<MetricsHistoryResponse> {
+    return this.request<MetricsHistoryResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/metrics/history",
+        query: { hours: params?.hours },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * HTTP 服务指标
+   *
+   * 按方法、路由模板和状态码返回请求数、请求和响应字节数以及耗时直方图，并返回当前和峰值处理中的请求数（仅管理员）。未匹配任何路由的请求计入 unmatched 路由。结果按请求数从多到少排序，可按路由模板和方法过滤。同样的指标以 Prometheus 格式在 /api/v1/metrics/prometheus 导出。
+   *
+   * GET /api/v1/admin/metrics/http
+   */
+  async metricsHTTPMetrics(params?: MetricsHTTPMetricsParams, options?: RequestOptions): Promise<HTTPStats> {
+    return this.request<HTTPStats>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/metrics/http",
+        query: { route: params?.route, method: params?.method },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 请求查询最多的路由
+   *
+   * 返回本实例查询过多或出现 N+1 查询的路由（仅管理员），需要启用 database.request_queries。单个请求的查询数超过 max_queries，或同一语句（忽略参数）执行达到 repeat_threshold 次时，请求计入所属路由，并记录查询数最多的请求的重复语句和调用栈。结果按单个请求的最大查询数从多到少排序，数据在进程内保存，重启后清零。
+   *
+   * GET /api/v1/admin/metrics/queries
+   */
+  async metricsQueryOffenders(options?: RequestOptions): Promise<RequestQueryStats> {
+    return this.request<RequestQueryStats>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/metrics/queries",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 限流时间序列
+   *
+   * 返回本实例最近 window 时长内按 resolution 汇总的限流检查数、拒绝数、拒绝率和检查耗时（平均、最大、P95），供管理后台绘制限流趋势（仅管理员）。数据来自进程内按分钟汇总的统计，保留 24 小时，进程重启后清零；resolution 向上取整到分钟，数据点按 resolution 的整数倍对齐，没有请求的时间段返回 0。
+   *
+   * GET /api/v1/admin/metrics/rate-limit
+   */
+  async metricsRateLimitTimeSeries(params?: MetricsRateLimitTimeSeriesParams, options?: RequestOptions): Promise<Array<RateLimitTimeSeriesPoint>> {
+    return this.request<Array<RateLimitTimeSeriesPoint>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/metrics/rate-limit",
+        query: { window: params?.window, resolution: params?.resolution },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 列出 OpenID Connect 客户端
+   *
+   * 列出已注册的客户端，按创建时间倒序（仅管理员）
+   *
+   * GET /api/v1/admin/oauth-clients
+   */
+  async oIDCListClients(options?: RequestOptions): Promise<Array<OAuthClient>> {
+    return this.request<Array<OAuthClient>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/oauth-clients",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 注册 OpenID Connect 客户端
+   *
+   * 注册依赖方（仅管理员）。回调地址须为不带片段的 http 或 https 绝对地址，授权请求中的 redirect_uri 须与其中之一完全一致。机密客户端的客户端密钥只在响应中返回一次
+   *
+   * POST /api/v1/admin/oauth-clients
+   */
+  async oIDCCreateClient(body: OAuthClientRequest, options?: RequestOptions): Promise<OAuthClientCreatedResponse> {
+    return this.request<OAuthClientCreatedResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/oauth-clients",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 删除 OpenID Connect 客户端
+   *
+   * 删除客户端（仅管理员），之后该客户端不能再发起授权或兑换令牌，已签发的令牌在过期前仍然有效
+   *
+   * DELETE /api/v1/admin/oauth-clients/{id}
+   */
+  async oIDCDeleteClient(id: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/admin/oauth-clients/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 系统概览
+   *
+   * 汇总用户数量、请求和限流统计、缓存统计、数据库连接池健康状态、运行时长和版本信息，供管理后台仪表盘使用（仅管理员）。结果在进程内缓存 5 秒，refresh=true 时重新采集。某一部分采集失败时在该部分的 error 字段中说明，接口仍返回 200。
+   *
+   * GET /api/v1/admin/overview
+   */
+  async adminOverviewOverview(params?: AdminOverviewOverviewParams, options?: RequestOptions): Promise<AdminOverviewResponse> {
+    return this.request<AdminOverviewResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/overview",
+        query: { refresh: params?.refresh },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取速率限制
+   *
+   * 返回当前生效的速率限制和管理员设置的覆盖（仅管理员）
+   *
+   * GET /api/v1/admin/rate-limits
+   */
+  async rateLimitGetRateLimits(options?: RequestOptions): Promise<RateLimitState> {
+    return this.request<RateLimitState>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/rate-limits",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 修改速率限制
+   *
+   * 替换管理员设置的速率限制覆盖（仅管理员），覆盖优先于配置文件，字段为 0 或空时沿用配置文件中的值，提交空对象恢复配置文件中的限制。 设置了路由限制的路由单独计数，不占用全局限制。缓存是 Redis 时覆盖保存在 Redis 中，重启后仍然有效，并通过发布订阅同步到所有实例
+   *
+   * PUT /api/v1/admin/rate-limits
+   */
+  async rateLimitUpdateRateLimits(body: UpdateRateLimitsRequest, options?: RequestOptions): Promise<RateLimitState> {
+    return this.request<RateLimitState>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/rate-limits",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 查询恢复状态
+   *
+   * 返回本实例最近一次从备份恢复的状态（仅管理员），本实例没有执行过恢复时 state 为 idle。恢复由接收请求的实例执行，多实例部署时须在同一实例上查询
+   *
+   * GET /api/v1/admin/restore
+   */
+  async backupGetRestoreStatus(options?: RequestOptions): Promise<RestoreStatus> {
+    return this.request<RestoreStatus>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/restore",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取数据保留策略
+   *
+   * 返回各表的保留策略、定期清理的间隔和本实例最近一次清理的报告（仅管理员）
+   *
+   * GET /api/v1/admin/retention
+   */
+  async retentionGetRetention(options?: RequestOptions): Promise<RetentionStatus> {
+    return this.request<RetentionStatus>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/retention",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 执行数据保留策略
+   *
+   * 立即按所有保留策略清理一次过期数据并返回报告（仅管理员）。默认为试运行，只统计各表可删除的行数； dry_run=false 时分批删除数据，每张表删除的行数记录一条审计事件。客户端断开不会中断清理
+   *
+   * POST /api/v1/admin/retention/run
+   */
+  async retentionRunRetention(params?: RetentionRunRetentionParams, options?: RequestOptions): Promise<RetentionReport> {
+    return this.request<RetentionReport>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/retention/run",
+        query: { dry_run: params?.dry_run },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 按条件列出用户
+   *
+   * 分页列出用户，包含已停用的用户（仅管理员）。q 按用户名、邮箱和姓名模糊匹配；role=admin 只返回管理员，role=user 只返回普通用户。结果不经过缓存。
+   *
+   * GET /api/v1/admin/users
+   */
+  async adminUserListUsers(params?: AdminUserListUsersParams, options?: RequestOptions): Promise<Page<SafeUser>> {
+    return this.request<Page<SafeUser>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/users",
+        query: { q: params?.q, active: params?.active, role: params?.role, page: params?.page, limit: params?.limit },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /** 从 params.page（默认第 1 页）开始逐页调用 adminUserListUsers，逐条返回数据 */
+  async *adminUserListUsersIter(params?: AdminUserListUsersParams, options?: RequestOptions): AsyncGenerator<SafeUser> {
+    yield* paginate(params?.page, (page) => this.adminUserListUsers({ ...params, page }, options));
+  }
+
+  /**
+   * 流式列出全部用户
+   *
+   * 不分页地列出匹配条件的全部用户，包含已停用的用户（仅管理员），按用户ID排序。用户逐行从数据库读取并逐个写入响应，内存占用与用户数量无关，适合导出大量用户；需要异步生成文件时使用数据导出接口。响应开始后读取失败时响应体被截断，不是合法的 JSON。结果不经过缓存。
+   *
+   * GET /api/v1/admin/users/stream
+   */
+  async adminUserStreamUsers(params?: AdminUserStreamUsersParams, options?: RequestOptions): Promise<Array<SafeUser>> {
+    return this.request<Array<SafeUser>>(
+      {
+        method: "GET",
+        path: "/api/v1/admin/users/stream",
+        query: { q: params?.q, active: params?.active, role: params?.role },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 激活用户
+   *
+   * 重新激活已停用的用户（仅管理员）
+   *
+   * POST /api/v1/admin/users/{id}/activate
+   */
+  async adminUserActivateUser(id: string, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/activate",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 停用用户
+   *
+   * 停用用户并吊销其已签发的全部令牌，停用的用户无法登录（仅管理员）。管理员不能停用自己。
+   *
+   * POST /api/v1/admin/users/{id}/deactivate
+   */
+  async adminUserDeactivateUser(id: string, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/deactivate",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 抹除用户的账户
+   *
+   * 代用户抹除其个人数据（仅管理员），用于处理通过其他渠道提出的删除请求，已注销的账户也可以抹除。处理与用户自行抹除相同，操作不可撤销。管理员不能通过该接口抹除自己
+   *
+   * POST /api/v1/admin/users/{id}/erasure
+   */
+  async privacyEraseUser(id: string, options?: RequestOptions): Promise<ErasureReport> {
+    return this.request<ErasureReport>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/erasure",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 模拟登录用户
+   *
+   * 以目标用户身份签发短期令牌，令牌的 act 声明记录管理员ID，imp_banner 声明为模拟登录横幅（仅管理员）。不能模拟自己或其他管理员，模拟登录期间不能再次模拟。令牌有效期 15 分钟。
+   *
+   * POST /api/v1/admin/users/{id}/impersonate
+   */
+  async adminUserImpersonate(id: string, options?: RequestOptions): Promise<ImpersonationResponse> {
+    return this.request<ImpersonationResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/impersonate",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 强制重置用户密码
+   *
+   * 将用户密码重置为随机临时密码并吊销其已签发的全部令牌（仅管理员）。临时密码只在本次响应中返回，需要通过安全渠道转交用户，用户登录后应立即修改密码。
+   *
+   * POST /api/v1/admin/users/{id}/password-reset
+   */
+  async adminUserResetPassword(id: string, options?: RequestOptions): Promise<PasswordResetResponse> {
+    return this.request<PasswordResetResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/password-reset",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 分配用户角色
+   *
+   * 设置用户的完整角色集合（仅管理员）。包含 admin 时授予管理员权限，否则撤销；管理员不能撤销自己的管理员角色。
+   *
+   * PUT /api/v1/admin/users/{id}/roles
+   */
+  async adminUserAssignRoles(id: string, body: AssignRolesRequest, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "PUT",
+        path: "/api/v1/admin/users/" + encodeURIComponent(id) + "/roles",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取生效中的公告
+   *
+   * 返回当前对调用者生效的公告，按创建时间倒序。未登录时只返回不限角色的公告；租户取自用户所属租户或请求的租户。公告列表来自共享缓存，管理员修改后立即失效
+   *
+   * GET /api/v1/announcements
+   */
+  async announcementListAnnouncements(options?: RequestOptions): Promise<Array<Announcement>> {
+    return this.request<Array<Announcement>>(
+      {
+        method: "GET",
+        path: "/api/v1/announcements",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Change user password
+   *
+   * Change the password of the currently authenticated user
+   *
+   * POST /api/v1/auth/change-password
+   */
+  async authChangePassword(body: ChangePasswordRequest, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/change-password",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 申请设备码
+   *
+   * 设备（电视、命令行工具）申请设备码和用户码（RFC 8628）。设备显示 user_code 和 verification_uri（或把 verification_uri_complete 显示为二维码），然后按 interval 轮询令牌接口
+   *
+   * POST /api/v1/auth/device/code
+   */
+  async passwordlessStartDevice(options?: RequestOptions): Promise<DeviceCodeResponse> {
+    return this.request<DeviceCodeResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/device/code",
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 设备轮询令牌
+   *
+   * 设备用设备码轮询，用户允许后返回与密码登录相同的访问令牌，设备码只能兑换一次。用户尚未决定时返回 400，error_code 为 authorization_pending；轮询过快为 slow_down，设备应把间隔增加 5 秒；用户拒绝为 access_denied；设备码无效或过期为 expired_token
+   *
+   * POST /api/v1/auth/device/token
+   */
+  async passwordlessDeviceToken(body: DeviceTokenRequest, options?: RequestOptions): Promise<LoginResponse> {
+    return this.request<LoginResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/device/token",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 允许或拒绝设备登录
+   *
+   * 已登录用户在验证页面输入设备上显示的用户码，允许后设备以该用户身份登录。用户码不区分大小写，只能使用一次
+   *
+   * POST /api/v1/auth/device/verify
+   */
+  async passwordlessVerifyDevice(body: DeviceVerifyRequest, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/device/verify",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Login user
+   *
+   * Authenticate a user with email and password and return an access token together with a refresh token. Use the refresh token with POST /api/v1/auth/refresh to obtain a new pair before the access token expires. Error messages are localized according to the Accept-Language header (en, zh).
+   *
+   * POST /api/v1/auth/login
+   */
+  async authLogin(body: LoginRequest, options?: RequestOptions): Promise<LoginResponse> {
+    return this.request<LoginResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/login",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Logout user
+   *
+   * Logout the current user by blacklisting their access token. Pass the refresh token in the optional request body to revoke it as well; otherwise it stays valid until it expires. Error messages are localized according to the Accept-Language header (en, zh).
+   *
+   * POST /api/v1/auth/logout
+   */
+  async authLogout(body: LogoutRequest, options?: RequestOptions): Promise<LogoutResponse> {
+    return this.request<LogoutResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/logout",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 申请登录链接
+   *
+   * 向账号邮箱发送登录链接，链接在 passwordless.magic_link_ttl 内有效且只能使用一次。邮箱不存在或账号已停用时同样返回 202，不泄露账号是否存在
+   *
+   * POST /api/v1/auth/magic-link
+   */
+  async passwordlessRequestMagicLink(body: MagicLinkRequest, options?: RequestOptions): Promise<MagicLinkResponse> {
+    return this.request<MagicLinkResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/magic-link",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 使用登录链接登录
+   *
+   * 用登录链接中的令牌换取与密码登录相同的访问令牌，令牌只能使用一次
+   *
+   * POST /api/v1/auth/magic-link/verify
+   */
+  async passwordlessRedeemMagicLink(body: MagicLinkVerifyRequest, options?: RequestOptions): Promise<LoginResponse> {
+    return this.request<LoginResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/magic-link/verify",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Get current user profile
+   *
+   * Get the profile of the currently authenticated user. This endpoint serves frequently accessed user profile data from Redis cache with 5-minute TTL. If Redis is unavailable, data is served directly from PostgreSQL database. Cache status is provided in response headers.
+   *
+   * GET /api/v1/auth/me
+   */
+  async authMe(options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "GET",
+        path: "/api/v1/auth/me",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Refresh access token
+   *
+   * Exchange a refresh token for a new access token and refresh token. The refresh token is rotated: the submitted token is revoked and cannot be used again. Refresh tokens are rejected after logout, after the user changes their password and once the account is deleted or deactivated. Error messages are localized according to the Accept-Language header (en, zh).
+   *
+   * POST /api/v1/auth/refresh
+   */
+  async authRefresh(body: RefreshTokenRequest, options?: RequestOptions): Promise<RefreshTokenResponse> {
+    return this.request<RefreshTokenResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/refresh",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Register new user
+   *
+   * Register a new user account. The username may contain letters, digits, underscores, dots and hyphens; the password must satisfy the password policy. Error messages are localized according to the Accept-Language header (en, zh).
+   *
+   * POST /api/v1/auth/register
+   */
+  async authRegister(body: RegisterRequest, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "POST",
+        path: "/api/v1/auth/register",
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取订阅套餐
+   *
+   * 返回所有订阅套餐及其包含的权益、限流和用量配额，按展示顺序排列
+   *
+   * GET /api/v1/billing/plans
+   */
+  async billingListPlans(options?: RequestOptions): Promise<Array<Plan>> {
+    return this.request<Array<Plan>>(
+      {
+        method: "GET",
+        path: "/api/v1/billing/plans",
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 接收 Stripe webhook
+   *
+   * 校验 Stripe-Signature 签名后同步订阅的创建、更新和删除，其他事件直接确认。乱序到达的旧事件不会覆盖较新的状态。 订阅的价格没有对应的套餐时返回 400，Stripe 会稍后重试，可在配置中补充 stripe_price_ids 后等待重试
+   *
+   * POST /api/v1/billing/webhooks/stripe
+   */
+  async billingStripeWebhook(body: Event, params?: BillingStripeWebhookParams, options?: RequestOptions): Promise<StripeWebhookResponse> {
+    return this.request<StripeWebhookResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/billing/webhooks/stripe",
+        headers: { "Stripe-Signature": params?.["Stripe-Signature"] },
+        body,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Enhanced health check endpoint
+   *
+   * Comprehensive health check including database connection pool metrics, Redis cache statistics, and system information. This endpoint provides detailed monitoring data including connection pool utilization, query performance, cache hit rates, memory usage, and latency metrics.
+   *
+   * GET /api/v1/health
+   */
+  async healthHealth(options?: RequestOptions): Promise<HealthResponse> {
+    return this.request<HealthResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/health",
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Liveness probe
+   *
+   * Kubernetes liveness probe. Runs only the liveness checks registered in the health registry (process-level state, never external dependencies), so a failing database does not cause the pod to be restarted. Returns 200 when alive and 503 otherwise.
+   *
+   * GET /api/v1/live
+   */
+  async healthHealthz2(options?: RequestOptions): Promise<HealthReport> {
+    return this.request<HealthReport>(
+      {
+        method: "GET",
+        path: "/api/v1/live",
+      },
+      options,
+    );
+  }
+
+  /**
+   * List error codes
+   *
+   * Get the machine-readable catalog of every error code the API can return, with its HTTP status, whether the request can be retried as-is, and a documentation link. The documentation link is also the RFC 7807 problem type URI.
+   *
+   * GET /api/v1/meta/errors
+   */
+  async metaListErrorCodes(options?: RequestOptions): Promise<Array<ErrorCodeInfo>> {
+    return this.request<Array<ErrorCodeInfo>>(
+      {
+        method: "GET",
+        path: "/api/v1/meta/errors",
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Database metrics endpoint
+   *
+   * Returns database connection pool statistics (open, idle, in-use connections and wait count), query performance statistics and per-operation query latency histograms collected by the query monitor plugin. The cache section reports hits, misses, loads, load latency and the per-key-prefix breakdown of each instrumented cache, keyed by cache name. The jwt_blacklist section reports the number of revoked tokens (read from the blacklist index, without scanning the keyspace) and, when the bloom filter is enabled, its skip and false-positive counters under jwt_blacklist.filter.
+   *
+   * GET /api/v1/metrics
+   */
+  async healthMetrics(options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "GET",
+        path: "/api/v1/metrics",
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Prometheus metrics endpoint
+   *
+   * Returns the registered metrics in the Prometheus text exposition format: HTTP request counts, latency histograms, request/response sizes and in-flight requests by method, route and status, and cache hits, misses, loads, load latency histograms and per-key-prefix counters labelled by cache name.
+   *
+   * GET /api/v1/metrics/prometheus
+   */
+  async healthPrometheus(options?: RequestOptions): Promise<string> {
+    return this.request<string>(
+      {
+        method: "GET",
+        path: "/api/v1/metrics/prometheus",
+        text: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 读取授权请求
+   *
+   * 授权同意页面携带用户的访问令牌调用，校验依赖方的授权请求（参数与授权端点的查询参数相同）并返回需要展示的客户端名称和范围。只支持 code 响应类型和 S256 PKCE，scope 须包含 openid，不支持的范围被忽略
+   *
+   * GET /api/v1/oauth/authorize
+   */
+  async oIDCAuthorize(params?: OIDCAuthorizeParams, options?: RequestOptions): Promise<OAuthConsentResponse> {
+    return this.request<OAuthConsentResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/oauth/authorize",
+        query: { response_type: params?.response_type, client_id: params?.client_id, redirect_uri: params?.redirect_uri, scope: params?.scope, state: params?.state, nonce: params?.nonce, code_challenge: params?.code_challenge, code_challenge_method: params?.code_challenge_method },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 提交授权决定
+   *
+   * 授权同意页面提交用户的决定和原始授权请求。同意时签发一次性授权码，拒绝时返回 access_denied 错误，客户端应将浏览器重定向到响应中的 redirect_to
+   *
+   * POST /api/v1/oauth/authorize
+   */
+  async oIDCApprove(body: OAuthConsentRequest, options?: RequestOptions): Promise<OAuthRedirectResponse> {
+    return this.request<OAuthRedirectResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/oauth/authorize",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 用户信息
+   *
+   * 返回访问令牌所属用户的标准声明（OpenID Connect Core 第 5.3 节）
+   *
+   * GET /api/v1/oauth/userinfo
+   */
+  async oIDCUserInfo(options?: RequestOptions): Promise<OIDCUserInfo> {
+    return this.request<OIDCUserInfo>(
+      {
+        method: "GET",
+        path: "/api/v1/oauth/userinfo",
+        auth: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Readiness probe
+   *
+   * Kubernetes readiness probe. Runs every readiness check registered in the health registry (database, cache, event bus, storage, ...) concurrently with per-check timeouts and reports per-dependency status. Returns 503 when a critical dependency is down or the server is shutting down; optional dependencies only degrade the status.
+   *
+   * GET /api/v1/ready
+   */
+  async healthReadyz2(options?: RequestOptions): Promise<HealthReport> {
+    return this.request<HealthReport>(
+      {
+        method: "GET",
+        path: "/api/v1/ready",
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取所有用户
+   *
+   * 获取所有用户列表（仅管理员）。此端点从Redis缓存提供频繁访问的用户数据，TTL为5分钟。如果Redis不可用，数据直接从PostgreSQL数据库提供。缓存状态在响应头中提供。
+   *
+   * GET /api/v1/users
+   */
+  async userGetUsers(params?: UserGetUsersParams, options?: RequestOptions): Promise<Page<SafeUser>> {
+    return this.request<Page<SafeUser>>(
+      {
+        method: "GET",
+        path: "/api/v1/users",
+        query: { page: params?.page, limit: params?.limit },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /** 从 params.page（默认第 1 页）开始逐页调用 userGetUsers，逐条返回数据 */
+  async *userGetUsersIter(params?: UserGetUsersParams, options?: RequestOptions): AsyncGenerator<SafeUser> {
+    yield* paginate(params?.page, (page) => this.userGetUsers({ ...params, page }, options));
+  }
+
+  /**
+   * 获取当前用户资料
+   *
+   * 返回当前登录用户的资料，读取走缓存仓库。响应带有 ETag，携带 If-None-Match 且未变更时返回 304
+   *
+   * 资源未修改（304）时抛出 NotModifiedError。
+   *
+   * GET /api/v1/users/me
+   */
+  async profileGetProfile(params?: ProfileGetProfileParams, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "GET",
+        path: "/api/v1/users/me",
+        headers: { "If-None-Match": params?.["If-None-Match"] },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 更新当前用户资料
+   *
+   * 更新当前登录用户的用户名、姓名和头像URL，更新后相关缓存立即失效。 携带 If-Match 时仅在资料未被其他请求修改过时更新，否则返回 412
+   *
+   * PUT /api/v1/users/me
+   */
+  async profileUpdateProfile(body: UpdateUserRequest, params?: ProfileUpdateProfileParams, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "PUT",
+        path: "/api/v1/users/me",
+        headers: { "If-Match": params?.["If-Match"] },
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 注销当前用户账户
+   *
+   * 校验当前密码后软删除当前用户，并吊销该用户已签发的全部令牌
+   *
+   * DELETE /api/v1/users/me
+   */
+  async profileDeleteAccount(body: DeleteAccountRequest, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/users/me",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 上传当前用户头像
+   *
+   * 以 multipart/form-data 流式上传头像，文件直接写入对象存储而不缓存在内存或临时文件中。内容类型根据文件头探测，不信任客户端声明的类型。上传成功后旧头像将被删除。返回的头像地址带有内容哈希（v 参数），内容变化时地址随之变化，可以长期缓存；启用签名链接时头像地址为 GET /api/v1/users/{id}/avatar。
+   *
+   * POST /api/v1/users/me/avatar
+   */
+  async avatarUploadAvatar(avatar: Blob, avatarFilename?: string, options?: RequestOptions): Promise<SafeUser> {
+    const form = new FormData();
+    form.append("avatar", avatar, avatarFilename ?? "avatar");
+    return this.request<SafeUser>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/avatar",
+        form,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 导出当前用户的个人数据
+   *
+   * 返回当前用户个人数据的完整副本：资料、全部站内信和各通知类型在各渠道上的投递偏好。version 为格式版本，字段发生不兼容的变化时递增
+   *
+   * POST /api/v1/users/me/data-export
+   */
+  async privacyExportMyData(options?: RequestOptions): Promise<PersonalDataArchive> {
+    return this.request<PersonalDataArchive>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/data-export",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 申请变更当前用户邮箱
+   *
+   * 校验当前密码后向新邮箱发送验证令牌，新邮箱在调用验证接口确认后才生效，令牌有效期 24 小时
+   *
+   * POST /api/v1/users/me/email
+   */
+  async profileRequestEmailChange(body: ChangeEmailRequest, options?: RequestOptions): Promise<EmailChangeResponse> {
+    return this.request<EmailChangeResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/email",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 确认变更当前用户邮箱
+   *
+   * 使用发送到新邮箱的验证令牌确认邮箱变更，令牌只能使用一次
+   *
+   * POST /api/v1/users/me/email/verify
+   */
+  async profileConfirmEmailChange(body: ConfirmEmailChangeRequest, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/email/verify",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 抹除当前用户的账户
+   *
+   * 校验当前密码后抹除当前用户的个人数据：吊销已签发的全部令牌，删除站内信、通知偏好和头像文件，将用户名、邮箱、姓名等替换为占位值并注销账户。操作不可撤销，原邮箱和用户名可以重新注册
+   *
+   * POST /api/v1/users/me/erasure
+   */
+  async privacyEraseMyAccount(body: DeleteAccountRequest, options?: RequestOptions): Promise<ErasureReport> {
+    return this.request<ErasureReport>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/erasure",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取通知偏好
+   *
+   * 返回当前用户对每种通知类型在每个已启用渠道上是否投递，未设置的使用通知类型的默认渠道
+   *
+   * GET /api/v1/users/me/notification-preferences
+   */
+  async notificationGetNotificationPreferences(options?: RequestOptions): Promise<Array<NotificationPreference>> {
+    return this.request<Array<NotificationPreference>>(
+      {
+        method: "GET",
+        path: "/api/v1/users/me/notification-preferences",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 修改通知偏好
+   *
+   * 修改当前用户对指定通知类型和渠道的偏好，未列出的保持不变，返回修改后的全部偏好
+   *
+   * PUT /api/v1/users/me/notification-preferences
+   */
+  async notificationUpdateNotificationPreferences(body: UpdateNotificationPreferencesRequest, options?: RequestOptions): Promise<Array<NotificationPreference>> {
+    return this.request<Array<NotificationPreference>>(
+      {
+        method: "PUT",
+        path: "/api/v1/users/me/notification-preferences",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取通知收件箱
+   *
+   * 分页返回当前用户的站内信，按创建时间倒序。unread=true 时只返回未读通知；unread_count 为全部未读数，读取走缓存
+   *
+   * GET /api/v1/users/me/notifications
+   */
+  async notificationListNotifications(params?: NotificationListNotificationsParams, options?: RequestOptions): Promise<NotificationListResponse> {
+    return this.request<NotificationListResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/users/me/notifications",
+        query: { unread: params?.unread, page: params?.page, limit: params?.limit },
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 标记全部通知已读
+   *
+   * 把当前用户的全部未读通知标记为已读
+   *
+   * POST /api/v1/users/me/notifications/read
+   */
+  async notificationMarkAllNotificationsRead(options?: RequestOptions): Promise<MarkNotificationsReadResponse> {
+    return this.request<MarkNotificationsReadResponse>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/notifications/read",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 标记通知已读
+   *
+   * 把当前用户的一条通知标记为已读，已读的通知保留原来的已读时间
+   *
+   * POST /api/v1/users/me/notifications/{id}/read
+   */
+  async notificationMarkNotificationRead(id: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/notifications/" + encodeURIComponent(id) + "/read",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 修改当前用户密码
+   *
+   * 校验当前密码后修改密码，并吊销该用户此前签发的全部令牌，客户端需要重新登录
+   *
+   * POST /api/v1/users/me/password
+   */
+  async profileChangePassword(body: ChangePasswordRequest, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "POST",
+        path: "/api/v1/users/me/password",
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取我的用量配额
+   *
+   * 返回当前用户本月（UTC）各项配额的用量、软限制和硬限制，用量在每月 1 日零点重置。查询本身不消耗 api_calls 配额
+   *
+   * GET /api/v1/users/me/quota
+   */
+  async quotaGetMyQuota(options?: RequestOptions): Promise<Array<Usage>> {
+    return this.request<Array<Usage>>(
+      {
+        method: "GET",
+        path: "/api/v1/users/me/quota",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * 获取我的订阅
+   *
+   * 返回当前用户生效的套餐和订阅状态，没有有效订阅时套餐为默认套餐。查询本身不消耗 api_calls 配额
+   *
+   * GET /api/v1/users/me/subscription
+   */
+  async billingGetMySubscription(options?: RequestOptions): Promise<SubscriptionResponse> {
+    return this.request<SubscriptionResponse>(
+      {
+        method: "GET",
+        path: "/api/v1/users/me/subscription",
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Get user by ID
+   *
+   * Get a specific user by ID. This endpoint serves frequently accessed user profile data from Redis cache with 5-minute TTL. If Redis is unavailable, data is served directly from PostgreSQL database. Cache status is provided in response headers.
+   *
+   * GET /api/v1/users/{id}
+   */
+  async userGetUser(id: string, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "GET",
+        path: "/api/v1/users/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Update user
+   *
+   * Update a user's information. When If-Match is sent the update only applies if the user has not changed since it was fetched.
+   *
+   * PUT /api/v1/users/{id}
+   */
+  async userUpdateUser(id: string, body: UpdateUserRequest, params?: UserUpdateUserParams, options?: RequestOptions): Promise<SafeUser> {
+    return this.request<SafeUser>(
+      {
+        method: "PUT",
+        path: "/api/v1/users/" + encodeURIComponent(id),
+        headers: { "If-Match": params?.["If-Match"] },
+        body,
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Delete user
+   *
+   * Delete a user (admin only or own account)
+   *
+   * DELETE /api/v1/users/{id}
+   */
+  async userDeleteUser(id: string, options?: RequestOptions): Promise<void> {
+    return this.request<void>(
+      {
+        method: "DELETE",
+        path: "/api/v1/users/" + encodeURIComponent(id),
+        auth: true,
+        envelope: true,
+      },
+      options,
+    );
+  }
+
+  /**
+   * Liveness probe
+   *
+   * Kubernetes liveness probe. Runs only the liveness checks registered in the health registry (process-level state, never external dependencies), so a failing database does not cause the pod to be restarted. Returns 200 when alive and 503 otherwise.
+   *
+   * GET /healthz
+   */
+  async healthHealthz(options?: RequestOptions): Promise<HealthReport> {
+    return this.request<HealthReport>(
+      {
+        method: "GET",
+        path: "/healthz",
+      },
+      options,
+    );
+  }
+
+  /**
+   * OpenAPI document
+   *
+   * Get the OpenAPI 3.1 document of this API. It is generated from the handler annotations by `make openapi` and embedded at build time; the Swagger UI at /swagger/index.html renders it.
+   *
+   * GET /openapi.json
+   */
+  async metaOpenAPISpec(options?: RequestOptions): Promise<Record<string, unknown>> {
+    return this.request<Record<string, unknown>>(
+      {
+        method: "GET",
+        path: "/openapi.json",
+      },
+      options,
+    );
+  }
+
+  /**
+   * Readiness probe
+   *
+   * Kubernetes readiness probe. Runs every readiness check registered in the health registry (database, cache, event bus, storage, ...) concurrently with per-check timeouts and reports per-dependency status. Returns 503 when a critical dependency is down or the server is shutting down; optional dependencies only degrade the status.
+   *
+   * GET /readyz
+   */
+  async healthReadyz(options?: RequestOptions): Promise<HealthReport> {
+    return this.request<HealthReport>(
+      {
+        method: "GET",
+        path: "/readyz",
+      },
+      options,
+    );
+  }
+}
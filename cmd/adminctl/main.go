@@ -0,0 +1,445 @@
+// adminctl 运维管理命令行工具，复用应用的 bootstrap 容器执行常见的运维操作，无需 psql、redis-cli 或修改代码
+//
+// 用法：
+//
+//	go run ./cmd/adminctl create-admin -username ops -email ops@example.com
+//	go run ./cmd/adminctl reset-password -user ops@example.com
+//	go run ./cmd/adminctl blacklist-token -token <jwt>
+//	go run ./cmd/adminctl flush-cache
+//	go run ./cmd/adminctl show-config -section redis
+//	go run ./cmd/adminctl run-seeds
+//	go run ./cmd/adminctl reencrypt-users
+//
+// 配置与服务相同，通过 APP_ENV 和 APP_* 环境变量选择；除 show-config 外的命令会连接数据库和缓存
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-server/internal/audit"
+	"go-server/internal/bootstrap"
+	"go-server/internal/config"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/validation"
+	"go-server/pkg/cache"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// auditSource 审计事件中标记操作来源
+const auditSource = "adminctl"
+
+// errUsage 参数错误，退出码为 2
+var errUsage = errors.New("usage error")
+
+// command 子命令
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+// commands 按帮助输出顺序排列的子命令
+var commands = []command{
+	{name: "create-admin", summary: "Create a user with the admin role", run: runCreateAdmin},
+	{name: "reset-password", summary: "Reset a user's password to a temporary one and revoke their tokens", run: runResetPassword},
+	{name: "blacklist-token", summary: "Revoke an access token, or every token issued to a user", run: runBlacklistToken},
+	{name: "flush-cache", summary: "Delete every key in the application cache", run: runFlushCache},
+	{name: "show-config", summary: "Print the effective configuration with secrets redacted", run: runShowConfig},
+	{name: "run-seeds", summary: "Seed the initial admin and test users", run: runSeeds},
+	{name: "reencrypt-users", summary: "Encrypt user personal data with the active field encryption key", run: runReencryptUsers},
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 执行子命令并返回退出码
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := lookup(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "Error: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(ctx, args[1:], stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// lookup 按名称查找子命令
+func lookup(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usage 输出帮助信息
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Admin CLI")
+	fmt.Fprintln(w, "=========")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  adminctl <command> [options]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `adminctl <command> -h` for the options of a command.")
+	fmt.Fprintln(w, "The configuration is selected with APP_ENV and APP_* environment variables, as for the server.")
+}
+
+// newFlagSet 创建子命令的参数集，错误由 run 统一输出
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("adminctl "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseFlags 解析子命令参数，不接受多余的位置参数
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, fs.Args())
+	}
+	return nil
+}
+
+// withContainer 创建应用容器执行操作，结束后按阶段关闭各组件
+func withContainer(fn func(c *bootstrap.Container) error) error {
+	container, err := bootstrap.NewContainer()
+	if err != nil {
+		return fmt.Errorf("failed to initialize application container: %w", err)
+	}
+	defer container.Cleanup()
+
+	return fn(container)
+}
+
+// record 记录审计事件，执行者为空，来源标记为 adminctl
+func record(ctx context.Context, c *bootstrap.Container, action, targetID string, err error, details map[string]interface{}) {
+	if c.AuditRecorder == nil {
+		return
+	}
+
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["source"] = auditSource
+
+	event := audit.Event{
+		Action:   action,
+		TargetID: targetID,
+		Outcome:  audit.OutcomeSuccess,
+		Details:  details,
+		Time:     time.Now(),
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	c.AuditRecorder.Record(ctx, event)
+}
+
+// runCreateAdmin 创建管理员用户，未指定密码时生成临时密码
+func runCreateAdmin(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("create-admin")
+	req := &models.RegisterRequest{}
+	fs.StringVar(&req.Username, "username", "", "Username (required)")
+	fs.StringVar(&req.Email, "email", "", "Email address (required)")
+	fs.StringVar(&req.Password, "password", "", "Password; a temporary password is generated and printed when empty")
+	fs.StringVar(&req.FirstName, "first-name", "", "First name")
+	fs.StringVar(&req.LastName, "last-name", "", "Last name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	generated := req.Password == ""
+	if generated {
+		password, err := temporaryPassword()
+		if err != nil {
+			return err
+		}
+		req.Password = password
+	}
+
+	// 与注册接口使用相同的校验规则
+	if err := validation.Setup(); err != nil {
+		return fmt.Errorf("failed to set up validation: %w", err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		user, err := c.UserService.Register(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		roles := []string{models.RoleUser, models.RoleAdmin}
+		_, err = c.UserService.AssignRoles(ctx, user.ID, roles, "")
+		record(ctx, c, audit.ActionRolesAssigned, user.ID, err, map[string]interface{}{"roles": roles})
+		if err != nil {
+			return fmt.Errorf("user %s was created but granting the admin role failed: %w", user.ID, err)
+		}
+
+		fmt.Fprintf(stdout, "Created admin %s (%s, id %s)\n", user.Username, user.Email, user.ID)
+		if generated {
+			fmt.Fprintf(stdout, "Temporary password: %s\n", req.Password)
+		}
+		return nil
+	})
+}
+
+// runResetPassword 重置用户密码为临时密码并撤销其所有令牌
+func runResetPassword(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("reset-password")
+	identifier := fs.String("user", "", "User ID or email address (required)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *identifier == "" {
+		return fmt.Errorf("%w: -user is required", errUsage)
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		user, err := findUser(ctx, c, *identifier)
+		if err != nil {
+			return err
+		}
+
+		password, err := c.UserService.ResetPassword(ctx, user.ID)
+		if err != nil {
+			record(ctx, c, audit.ActionPasswordReset, user.ID, err, nil)
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+
+		revoked := revokeUserTokens(ctx, c, user.ID, stdout)
+		record(ctx, c, audit.ActionPasswordReset, user.ID, nil, map[string]interface{}{"tokens_revoked": revoked})
+
+		fmt.Fprintf(stdout, "Password reset for %s (id %s)\n", user.Email, user.ID)
+		fmt.Fprintf(stdout, "Temporary password: %s\n", password)
+		return nil
+	})
+}
+
+// runBlacklistToken 撤销单个令牌，或指定用户时撤销该用户已签发的所有令牌
+func runBlacklistToken(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("blacklist-token")
+	token := fs.String("token", "", "Access token to revoke")
+	identifier := fs.String("user", "", "User ID or email address whose tokens should all be revoked")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if (*token == "") == (*identifier == "") {
+		return fmt.Errorf("%w: specify exactly one of -token or -user", errUsage)
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		if c.BlacklistService == nil {
+			return errors.New("token blacklist is not available, check the cache configuration")
+		}
+
+		if *token != "" {
+			claims, err := c.JWTManager.ValidateTokenWithContext(ctx, *token)
+			if err != nil {
+				return fmt.Errorf("invalid token: %w", err)
+			}
+			err = c.BlacklistService.AddToBlacklist(ctx, *token)
+			record(ctx, c, audit.ActionTokensRevoked, claims.UserID, err, map[string]interface{}{"scope": "token"})
+			if err != nil {
+				return fmt.Errorf("failed to revoke token: %w", err)
+			}
+			fmt.Fprintf(stdout, "Revoked token of user %s\n", claims.UserID)
+			return nil
+		}
+
+		user, err := findUser(ctx, c, *identifier)
+		if err != nil {
+			return err
+		}
+		if !revokeUserTokens(ctx, c, user.ID, stdout) {
+			return errors.New("failed to revoke tokens")
+		}
+		fmt.Fprintf(stdout, "Revoked every token issued to %s (id %s)\n", user.Email, user.ID)
+		return nil
+	})
+}
+
+// runFlushCache 清空应用缓存
+func runFlushCache(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("flush-cache")
+	force := fs.Bool("force", false, "Flush even when the cache driver cannot limit the flush to the application key prefix")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		if c.Cache == nil {
+			return errors.New("cache is not available, check the cache configuration")
+		}
+		if !cache.Supports(c.Cache, cache.CapabilityScopedClear) && !*force {
+			return fmt.Errorf("%w: the %s driver flushes the whole cache server, rerun with -force to confirm", errUsage, c.Config.Cache.Driver)
+		}
+
+		if err := c.Cache.Clear(ctx); err != nil {
+			return fmt.Errorf("failed to flush cache: %w", err)
+		}
+		fmt.Fprintf(stdout, "Flushed the %s cache\n", c.Config.Cache.Driver)
+		return nil
+	})
+}
+
+// runShowConfig 输出生效的配置，不连接数据库和缓存
+func runShowConfig(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("show-config")
+	section := fs.String("section", "", "Only print this top-level section, such as redis or jwt")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if _, err := config.LoadConfig(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return renderConfig(stdout, viper.AllSettings(), *section)
+}
+
+// renderConfig 以缩进的 JSON 输出配置，密钥和密码替换为占位符
+func renderConfig(w io.Writer, settings map[string]interface{}, section string) error {
+	err := bootstrap.RenderConfig(w, settings, section)
+	if errors.Is(err, bootstrap.ErrUnknownConfigSection) {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return err
+}
+
+// runSeeds 植入初始数据
+func runSeeds(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("run-seeds")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		if err := c.Database.Seed(); err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
+		}
+		fmt.Fprintln(stdout, "Seeds applied")
+		return nil
+	})
+}
+
+// runReencryptUsers 用当前版本的字段加密密钥重新加密用户的个人数据并补全邮箱盲索引
+// 启用字段加密、新增密钥版本后以及删除旧版本的密钥前执行，已用当前密钥加密的用户被跳过，中断后可以重新执行
+func runReencryptUsers(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := newFlagSet("reencrypt-users")
+	batchSize := fs.Int("batch-size", 500, "Users read per batch")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("%w: -batch-size must be positive", errUsage)
+	}
+
+	return withContainer(func(c *bootstrap.Container) error {
+		result, err := repositories.ReencryptUsers(ctx, c.Database.DB, *batchSize, func(progress repositories.ReencryptResult) {
+			fmt.Fprintf(stdout, "Scanned %d users, updated %d\n", progress.Scanned, progress.Updated)
+		})
+		if errors.Is(err, repositories.ErrEncryptionDisabled) {
+			return fmt.Errorf("%w: set encryption.enabled and the encryption keys first", err)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Re-encrypted %d of %d users\n", result.Updated, result.Scanned)
+		return nil
+	})
+}
+
+// findUser 按用户ID或邮箱查找用户
+func findUser(ctx context.Context, c *bootstrap.Container, identifier string) (*models.User, error) {
+	var (
+		user *models.User
+		err  error
+	)
+	if _, parseErr := uuid.Parse(identifier); parseErr == nil {
+		user, err = c.UserService.GetByID(ctx, identifier)
+	} else {
+		user, err = c.UserService.GetByEmail(ctx, identifier)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user %q: %w", identifier, err)
+	}
+	return user, nil
+}
+
+// revokeUserTokens 撤销用户已签发的所有令牌，黑名单不可用时输出警告
+func revokeUserTokens(ctx context.Context, c *bootstrap.Container, userID string, stdout io.Writer) bool {
+	if c.BlacklistService == nil {
+		fmt.Fprintln(stdout, "Warning: token blacklist is not available, existing tokens stay valid until they expire")
+		return false
+	}
+
+	err := c.BlacklistService.RevokeUserTokens(ctx, userID)
+	record(ctx, c, audit.ActionTokensRevoked, userID, err, map[string]interface{}{"scope": "user"})
+	if err != nil {
+		fmt.Fprintf(stdout, "Warning: failed to revoke tokens: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// temporaryPassword 生成随机临时密码，重新生成直到同时包含字母和数字以满足密码强度规则
+func temporaryPassword() (string, error) {
+	buf := make([]byte, 12)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password := base64.RawURLEncoding.EncodeToString(buf)
+		if strings.ContainsAny(password, "0123456789") && strings.IndexFunc(password, unicode.IsLetter) >= 0 {
+			return password, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_Usage 测试帮助、未知命令和参数错误在连接数据库前返回
+func TestRun_Usage(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"无参数", nil, 2},
+		{"帮助", []string{"help"}, 0},
+		{"未知命令", []string{"drop-database"}, 2},
+		{"子命令帮助", []string{"flush-cache", "-h"}, 0},
+		{"缺少用户", []string{"reset-password"}, 2},
+		{"令牌和用户同时指定", []string{"blacklist-token", "-token", "t", "-user", "u"}, 2},
+		{"令牌和用户都未指定", []string{"blacklist-token"}, 2},
+		{"管理员邮箱无效", []string{"create-admin", "-username", "ops", "-email", "not-an-email"}, 2},
+		{"多余的参数", []string{"run-seeds", "extra"}, 2},
+		{"批量大小无效", []string{"reencrypt-users", "-batch-size", "0"}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.code, run(context.Background(), tc.args, &stdout, &stderr), stderr.String())
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	settings := map[string]interface{}{
+		"database": map[string]interface{}{"host": "db", "password": "hunter2"},
+		"jwt":      map[string]interface{}{"secret_key": "s3cret", "expires_in": 24},
+		"storage":  map[string]interface{}{"s3": map[string]interface{}{"secret_access_key": "aws", "bucket": "avatars"}},
+	}
+
+	t.Run("密钥和密码脱敏", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, renderConfig(&out, settings, ""))
+		assert.NotContains(t, out.String(), "hunter2")
+		assert.NotContains(t, out.String(), "s3cret")
+		assert.NotContains(t, out.String(), "aws")
+
+		var rendered map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &rendered))
+		assert.Equal(t, "db", rendered["database"]["host"])
+		assert.Equal(t, float64(24), rendered["jwt"]["expires_in"])
+	})
+
+	t.Run("只输出指定部分", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, renderConfig(&out, settings, "JWT"))
+		assert.JSONEq(t, `{"secret_key":"[REDACTED]","expires_in":24}`, out.String())
+
+		assert.ErrorIs(t, renderConfig(&out, settings, "missing"), errUsage)
+	})
+}
+
+func TestTemporaryPassword(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		password, err := temporaryPassword()
+		require.NoError(t, err)
+		assert.Len(t, password, 16)
+		assert.True(t, bytes.ContainsAny([]byte(password), "0123456789"))
+		assert.GreaterOrEqual(t, bytes.IndexFunc([]byte(password), unicode.IsLetter), 0)
+		assert.False(t, seen[password])
+		seen[password] = true
+	}
+}
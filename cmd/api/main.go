@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 
-	_ "go-server/docs" // 导入Swagger文档
 	"go-server/internal/bootstrap"
 	"go-server/internal/config"
 	"go-server/internal/logger"
@@ -12,7 +14,7 @@ import (
 
 // @title Golang 模板 API
 // @version 1.0
-// @description 一个使用Go和Gin框架构建的RESTful API模板，具有基于Redis的缓存、速率限制和增强的错误处理功能。所有API端点都受到速率限制保护：匿名用户（100次/分钟），认证用户（200次/分钟）。频繁访问的数据（用户配置文件、配置信息）从Redis缓存提供，TTL为5分钟。API具有集中式错误处理，带有用于请求跟踪的关联ID、详细的字段级验证错误（支持国际化消息）和全面的错误上下文。速率限制、缓存头和关联ID都包含在所有响应中。当Redis不可用时，系统会优雅地降级到数据库查询。
+// @description 一个使用Go和Gin框架构建的RESTful API模板，具有基于Redis的缓存、速率限制和增强的错误处理功能。所有API端点都受到速率限制保护：匿名用户（100次/分钟），认证用户（200次/分钟）。频繁访问的数据（用户配置文件、配置信息）从Redis缓存提供，TTL为5分钟。API具有集中式错误处理，带有用于请求跟踪的关联ID、详细的字段级验证错误（支持国际化消息）和全面的错误上下文。速率限制、缓存头和关联ID都包含在所有响应中。当Redis不可用时，系统会优雅地降级到数据库查询。所有错误代码及其HTTP状态码、是否可重试和文档链接可通过 GET /api/v1/meta/errors 获取；请求头 Accept 为 application/problem+json 时错误按 RFC 7807 格式返回。
 // @termsOfService http://swagger.io/terms/
 
 // @contact.name API 支持
@@ -25,12 +27,44 @@ import (
 // @host localhost:8080
 // @BasePath /
 
-// @securityDefinitions.apikey BearerAuth
-// @in header
-// @name Authorization
-// @description 输入"Bearer"后跟空格和JWT令牌。
+// @securityDefinitions.bearer BearerAuth
+// @bearerFormat JWT
+// @description 在 Authorization 请求头中携带 JWT 访问令牌："Bearer <token>"。
+
+// overrideFlags 收集可重复的 -set key=value 参数
+type overrideFlags map[string]string
+
+func (o overrideFlags) String() string {
+	return fmt.Sprint(map[string]string(o))
+}
+
+func (o overrideFlags) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("应为 key=value 格式: %q", value)
+	}
+	o[strings.TrimSpace(key)] = val
+	return nil
+}
 
 func main() {
+	overrides := overrideFlags{}
+	flag.Var(overrides, "set", "覆盖配置项，优先级高于配置文件和环境变量，可重复，如 -set server.port=9090")
+	printConfig := flag.Bool("print-config", false, "输出脱敏后生效的配置并退出")
+	section := flag.String("section", "", "与 -print-config 一起使用，只输出指定的顶层配置部分")
+	flag.Parse()
+
+	config.SetOverrides(overrides)
+
+	if *printConfig {
+		if err := bootstrap.PrintConfig(os.Stdout, *section); err != nil {
+			fmt.Fprintf(os.Stderr, "输出配置失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "配置文件: %s\n", strings.Join(config.LoadedFiles(), ", "))
+		return
+	}
+
 	// 创建临时日志记录器用于启动时的错误处理
 	tempLogger, err := logger.NewManager(config.LoggingConfig{
 		Level:    "info",
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// goWriter 生成 Go 客户端源码
+type goWriter struct {
+	b       strings.Builder
+	imports map[string]bool
+}
+
+func (w *goWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+// generateGo 生成 pkg/client 的 client_gen.go
+func generateGo(a *api) ([]byte, error) {
+	w := &goWriter{imports: map[string]bool{"context": true, "net/http": true}}
+	w.errorCodes(a)
+	for _, def := range a.types {
+		w.typeDef(def)
+	}
+	for _, op := range a.operations {
+		w.operation(op)
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by go run ./cmd/genclient; DO NOT EDIT.\n\n")
+	out.WriteString("package client\n\n")
+	imports := make([]string, 0, len(w.imports))
+	for path := range w.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	out.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+	fmt.Fprintf(&out, "// APIVersion 生成客户端使用的接口文档版本\nconst APIVersion = %s\n\n", quote(a.version))
+	out.WriteString(w.b.String())
+
+	source, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated Go client: %w", err)
+	}
+	return source, nil
+}
+
+// errorCodes 生成服务端错误代码常量
+func (w *goWriter) errorCodes(a *api) {
+	w.printf("// 服务端返回的错误代码\nconst (\n")
+	for _, code := range a.errorCodes {
+		w.printf("\t%s ErrorCode = %s\n", errorCodeConst(code), quote(code))
+	}
+	w.printf(")\n\n")
+	w.printf("// ErrorCodes 服务端定义的所有错误代码\nvar ErrorCodes = []ErrorCode{\n")
+	for _, code := range a.errorCodes {
+		w.printf("\t%s,\n", errorCodeConst(code))
+	}
+	w.printf("}\n\n")
+}
+
+// errorCodeConst 错误代码常量名，如 VALIDATION_ERROR 对应 ErrCodeValidation
+func errorCodeConst(code string) string {
+	trimmed := strings.TrimSuffix(code, "_ERROR")
+	if trimmed == "" {
+		trimmed = code
+	}
+	return "ErrCode" + exported(strings.ToLower(trimmed))
+}
+
+// typeDef 生成组件对应的结构体
+func (w *goWriter) typeDef(def *typeDef) {
+	w.printf("// %s %s\n", def.name, oneLine(def.description, def.schema))
+	w.printf("type %s struct {\n", def.name)
+	for _, f := range def.fields {
+		tag := f.jsonName
+		if !f.required {
+			tag += ",omitempty"
+		}
+		w.printf("\t%s %s `json:%s`", f.name, w.goType(f.typ), quote(tag))
+		if f.description != "" {
+			w.printf(" // %s", oneLine(f.description, ""))
+		}
+		w.printf("\n")
+	}
+	w.printf("}\n\n")
+}
+
+// goType 类型对应的 Go 类型
+func (w *goWriter) goType(t *typeRef) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "string"
+	case kindInteger:
+		name = "int"
+	case kindInt64:
+		name = "int64"
+	case kindNumber:
+		name = "float64"
+	case kindBoolean:
+		name = "bool"
+	case kindTime:
+		w.imports["time"] = true
+		name = "time.Time"
+	case kindBinary:
+		return "[]byte"
+	case kindErrorCode:
+		name = "ErrorCode"
+	case kindNamed:
+		name = t.name
+	case kindArray:
+		return "[]" + w.goType(t.elem)
+	case kindMap:
+		return "map[string]" + w.goType(t.elem)
+	case kindObject:
+		return "map[string]any"
+	default:
+		return "any"
+	}
+	if t.nullable {
+		return "*" + name
+	}
+	return name
+}
+
+// goParam 参数对应的 Go 类型，可选的布尔值使用指针以区分未设置和 false
+func (w *goWriter) goParam(p *param) string {
+	if p.typ.kind == kindBoolean && !p.required {
+		return "*bool"
+	}
+	t := *p.typ
+	t.nullable = false
+	return w.goType(&t)
+}
+
+// operation 生成接口方法，分页接口额外生成遍历方法
+func (w *goWriter) operation(op *operation) {
+	name := exported(op.id)
+	params := op.params()
+	if len(params) > 0 {
+		w.paramsType(name, op, params)
+	}
+
+	// 参数列表：路径参数、请求体、查询参数和请求头
+	var args []string
+	pathArgs := map[string]string{}
+	for _, p := range op.pathParams {
+		arg := goIdent(unexported(p.name))
+		pathArgs[p.name] = arg
+		args = append(args, arg+" string")
+	}
+	if op.body != nil {
+		args = append(args, "body "+w.goType(op.body))
+	}
+	for _, field := range op.multipart {
+		arg := goIdent(unexported(field))
+		w.imports["io"] = true
+		args = append(args, arg+"Filename string", arg+" io.Reader")
+	}
+	if len(params) > 0 {
+		args = append(args, "params *"+name+"Params")
+	}
+
+	var result string
+	switch {
+	case op.pageItem != nil:
+		result = "*Page[" + w.goType(op.pageItem) + "]"
+	case op.result == nil:
+	case op.result.kind == kindNamed:
+		result = "*" + w.goType(op.result)
+	default:
+		result = w.goType(op.result)
+	}
+
+	w.printf("// %s %s\n", name, oneLine(op.summary, op.method+" "+op.path))
+	if op.description != "" && op.description != op.summary {
+		w.printf("// %s\n", oneLine(op.description, ""))
+	}
+	if op.notModified {
+		w.printf("//\n// 资源未修改（304）时返回 ErrNotModified。\n")
+	}
+	w.printf("//\n// %s %s\n", strings.ToUpper(op.method), op.path)
+	w.printf("func (c *Client) %s(ctx context.Context, %s) ", name, strings.Join(append(args, "opts ...RequestOption"), ", "))
+	if result == "" {
+		w.printf("error {\n")
+	} else {
+		w.printf("(%s, error) {\n", result)
+	}
+
+	w.printf("\treq := &request{method: %s, path: %s", httpMethod(op.method), w.pathExpr(op.path, pathArgs))
+	if op.auth {
+		w.printf(", auth: true")
+	}
+	switch op.mode {
+	case modeEnvelope:
+		w.printf(", envelope: true")
+	case modeText:
+		w.printf(", text: true")
+	}
+	w.printf("}\n")
+	if op.body != nil {
+		w.printf("\treq.body = body\n")
+	}
+	for _, field := range op.multipart {
+		arg := goIdent(unexported(field))
+		w.printf("\treq.files = append(req.files, file{field: %s, filename: %sFilename, reader: %s})\n", quote(field), arg, arg)
+	}
+	if len(params) > 0 {
+		w.printf("\tparams.apply(req)\n")
+	}
+
+	switch {
+	case result == "":
+		w.printf("\treturn c.do(ctx, req, nil, opts)\n")
+	case strings.HasPrefix(result, "*"):
+		w.printf("\tvar out %s\n", result[1:])
+		w.printf("\tif err := c.do(ctx, req, &out, opts); err != nil {\n\t\treturn nil, err\n\t}\n")
+		w.printf("\treturn &out, nil\n")
+	default:
+		w.printf("\tvar out %s\n", result)
+		w.printf("\terr := c.do(ctx, req, &out, opts)\n")
+		w.printf("\treturn out, err\n")
+	}
+	w.printf("}\n\n")
+
+	if op.paginated() {
+		w.iterator(name, op, args)
+	}
+}
+
+// iterator 生成逐条遍历所有分页结果的方法
+func (w *goWriter) iterator(name string, op *operation, args []string) {
+	item := w.goType(op.pageItem)
+	w.imports["iter"] = true
+	w.printf("// %sIter 从 params.Page（默认第 1 页）开始逐页调用 %s，逐条返回数据，出错时返回错误并结束\n", name, name)
+	w.printf("func (c *Client) %sIter(ctx context.Context, %s) iter.Seq2[%s, error] {\n",
+		name, strings.Join(append(args, "opts ...RequestOption"), ", "), item)
+	w.printf("\tvar p %sParams\n\tif params != nil {\n\t\tp = *params\n\t}\n", name)
+
+	var callArgs []string
+	for _, arg := range args {
+		argName, _, _ := strings.Cut(arg, " ")
+		if argName == "params" {
+			argName = "&p"
+		}
+		callArgs = append(callArgs, argName)
+	}
+	w.printf("\treturn paginate(p.Page, func(page int) (*Page[%s], error) {\n", item)
+	w.printf("\t\tp.Page = page\n")
+	w.printf("\t\treturn c.%s(ctx, %s)\n", name, strings.Join(append(callArgs, "opts..."), ", "))
+	w.printf("\t})\n}\n\n")
+}
+
+// paramsType 生成查询参数和请求头的结构体及其 apply 方法
+func (w *goWriter) paramsType(name string, op *operation, params []*param) {
+	w.printf("// %sParams %s 的查询参数和请求头，零值的参数不会发送\n", name, name)
+	w.printf("type %sParams struct {\n", name)
+	for _, p := range params {
+		w.printf("\t%s %s", p.field, w.goParam(p))
+		comment := p.description
+		if len(p.typ.enum) > 0 {
+			comment = strings.TrimSpace(comment + "，可选值：" + strings.Join(p.typ.enum, ", "))
+		}
+		if comment != "" {
+			w.printf(" // %s", oneLine(comment, ""))
+		}
+		w.printf("\n")
+	}
+	w.printf("}\n\n")
+
+	w.printf("// apply 将参数写入请求\n")
+	w.printf("func (p *%sParams) apply(r *request) {\n\tif p == nil {\n\t\treturn\n\t}\n", name)
+	for _, p := range params {
+		setter := "setQuery"
+		if p.in == "header" {
+			setter = "setHeader"
+		}
+		value, zero := w.formatParam(p)
+		if p.required {
+			w.printf("\tr.%s(%s, %s)\n", setter, quote(p.name), value)
+			continue
+		}
+		w.printf("\tif p.%s != %s {\n\t\tr.%s(%s, %s)\n\t}\n", p.field, zero, setter, quote(p.name), value)
+	}
+	w.printf("}\n\n")
+}
+
+// formatParam 参数值转换为字符串的表达式和参数的零值
+func (w *goWriter) formatParam(p *param) (string, string) {
+	field := "p." + p.field
+	switch w.goParam(p) {
+	case "*bool":
+		w.imports["strconv"] = true
+		return "strconv.FormatBool(*" + field + ")", "nil"
+	case "bool":
+		w.imports["strconv"] = true
+		return "strconv.FormatBool(" + field + ")", "false"
+	case "int":
+		w.imports["strconv"] = true
+		return "strconv.Itoa(" + field + ")", "0"
+	case "int64":
+		w.imports["strconv"] = true
+		return "strconv.FormatInt(" + field + ", 10)", "0"
+	case "float64":
+		w.imports["strconv"] = true
+		return "strconv.FormatFloat(" + field + ", 'f', -1, 64)", "0"
+	case "time.Time":
+		return field + ".Format(time.RFC3339)", "(time.Time{})"
+	case "string":
+		return field, `""`
+	default:
+		return "string(" + field + ")", `""`
+	}
+}
+
+// pathExpr 生成请求路径表达式，路径参数经过转义
+func (w *goWriter) pathExpr(path string, args map[string]string) string {
+	var parts []string
+	rest := path
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, quote(rest[:start]))
+		}
+		w.imports["net/url"] = true
+		parts = append(parts, "url.PathEscape("+args[rest[start+1:end]]+")")
+		rest = rest[end+1:]
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// httpMethod net/http 中的方法常量
+func httpMethod(method string) string {
+	switch method {
+	case "get":
+		return "http.MethodGet"
+	case "put":
+		return "http.MethodPut"
+	case "post":
+		return "http.MethodPost"
+	case "delete":
+		return "http.MethodDelete"
+	case "patch":
+		return "http.MethodPatch"
+	case "head":
+		return "http.MethodHead"
+	}
+	return quote(strings.ToUpper(method))
+}
+
+// goIdent 避开 Go 关键字和客户端方法中已使用的名称
+func goIdent(name string) string {
+	if token.IsKeyword(name) || name == "ctx" || name == "opts" || name == "req" || name == "out" || name == "body" || name == "params" || name == "file" {
+		return name + "Param"
+	}
+	return name
+}
+
+// oneLine 将多行说明合并为一行，为空时使用 fallback
+func oneLine(s, fallback string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return fallback
+	}
+	return s
+}
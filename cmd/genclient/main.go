@@ -0,0 +1,90 @@
+// genclient 根据 docs/openapi.json 生成 Go 和 TypeScript 接口客户端
+//
+// 生成的客户端包含所有接口的类型和方法、错误代码常量和分页遍历方法；
+// 令牌刷新、错误解析等与接口无关的部分在 pkg/client 中手写（TypeScript 客户端为单文件，一并生成）。
+//
+// 用法：
+//
+//	go run ./cmd/genclient            生成 pkg/client/client_gen.go 和 clients/typescript/client.ts
+//	go run ./cmd/genclient -check     检查生成的客户端是否最新（用于 CI）
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// output 一个生成的文件
+type output struct {
+	path    string // 相对模块根目录的路径
+	content []byte
+}
+
+func main() {
+	var (
+		root  = flag.String("root", ".", "Module root directory")
+		spec  = flag.String("spec", "docs/openapi.json", "OpenAPI document, relative to the module root")
+		goOut = flag.String("go-out", "pkg/client/client_gen.go", "Generated Go client, relative to the module root")
+		tsOut = flag.String("ts-out", "clients/typescript/client.ts", "Generated TypeScript client, relative to the module root")
+		check = flag.Bool("check", false, "Fail if the committed clients are stale")
+	)
+	flag.Parse()
+
+	outputs, err := generate(*root, *spec, *goOut, *tsOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate clients: %v\n", err)
+		os.Exit(1)
+	}
+
+	stale := false
+	for _, out := range outputs {
+		path := filepath.Join(*root, out.path)
+		if *check {
+			current, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(current, out.content) {
+				fmt.Fprintf(os.Stderr, "%s is out of date, run `make genclient`\n", path)
+				stale = true
+				continue
+			}
+			fmt.Printf("%s is up to date\n", path)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", filepath.Dir(path), err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, out.content, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated %s\n", path)
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
+
+// generate 读取文档并生成两个客户端
+func generate(root, spec, goOut, tsOut string) ([]output, error) {
+	data, err := os.ReadFile(filepath.Join(root, spec))
+	if err != nil {
+		return nil, err
+	}
+	a, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	goSource, err := generateGo(a)
+	if err != nil {
+		return nil, err
+	}
+	return []output{
+		{path: goOut, content: goSource},
+		{path: tsOut, content: generateTypeScript(a)},
+	}, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientsUpToDate 检查提交的客户端与 docs/openapi.json 生成的结果一致
+func TestClientsUpToDate(t *testing.T) {
+	outputs, err := generate("../..", "docs/openapi.json", "pkg/client/client_gen.go", "clients/typescript/client.ts")
+	require.NoError(t, err)
+
+	for _, out := range outputs {
+		current, err := os.ReadFile(filepath.Join("../..", out.path))
+		require.NoError(t, err)
+		assert.Equal(t, string(current), string(out.content), "%s is out of date, run `make genclient`", out.path)
+	}
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "FirstName", exported("first_name"))
+	assert.Equal(t, "IfNoneMatch", exported("If-None-Match"))
+	assert.Equal(t, "UserID", exported("user_id"))
+	assert.Equal(t, "TopViolatingIPs", exported("top_violating_ips"))
+	assert.Equal(t, "AdminUserListUsers", exported("adminUserListUsers"))
+	assert.Equal(t, "MetricsHTTPMetrics", exported("metricsHTTPMetrics"))
+	assert.Equal(t, "ifNoneMatch", unexported("If-None-Match"))
+	assert.Equal(t, "typeParam", goIdent(unexported("type")))
+	assert.Equal(t, "fileParam", goIdent(unexported("file")))
+
+	assert.Equal(t, "ErrCodeValidation", errorCodeConst("VALIDATION_ERROR"))
+	assert.Equal(t, "ErrCodeRateLimitExceeded", errorCodeConst("RATE_LIMIT_EXCEEDED"))
+}
+
+func TestLoad(t *testing.T) {
+	spec := []byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "test", "version": "2.0"},
+		"paths": {
+			"/items": {"get": {
+				"operationId": "itemList",
+				"security": [{"BearerAuth": []}],
+				"parameters": [{"name": "page", "in": "query", "schema": {"type": "integer"}}],
+				"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"allOf": [
+					{"$ref": "#/components/schemas/models.SuccessResponse"},
+					{"type": "object", "properties": {"data": {"allOf": [
+						{"$ref": "#/components/schemas/models.PaginatedResponse"},
+						{"type": "object", "properties": {"data": {"type": "array", "items": {"$ref": "#/components/schemas/a.Item"}}}}
+					]}}}
+				]}}}}}
+			}},
+			"/items/{id}": {"delete": {
+				"operationId": "itemDelete",
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+				"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/models.SuccessResponse"}}}}}
+			}},
+			"/items/stream": {"get": {
+				"operationId": "itemStream",
+				"responses": {"200": {"description": "ok", "content": {"text/event-stream": {"schema": {"type": "string"}}}}}
+			}},
+			"/sso/metadata": {"get": {
+				"operationId": "ssoMetadata",
+				"responses": {"200": {"description": "ok", "content": {"application/xml": {"schema": {"type": "string"}}}}}
+			}},
+			"/sso/login": {"get": {
+				"operationId": "ssoLogin",
+				"responses": {"302": {"description": "redirect"}, "404": {"description": "not found"}}
+			}},
+			"/token": {"post": {
+				"operationId": "token",
+				"requestBody": {"content": {"application/x-www-form-urlencoded": {"schema": {"type": "object", "properties": {"code": {"type": "string"}}}}}},
+				"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object"}}}}}
+			}}
+		},
+		"components": {"schemas": {
+			"models.SuccessResponse": {"type": "object"},
+			"models.PaginatedResponse": {"type": "object"},
+			"a.Item": {"type": "object", "required": ["id"], "properties": {
+				"id": {"type": "string"},
+				"seen_at": {"type": ["string", "null"], "format": "date-time"},
+				"tags": {"type": "array", "items": {"type": "string", "enum": ["x", "y"]}}
+			}},
+			"b.Item": {"type": "object"}
+		}}
+	}`)
+
+	a, err := load(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", a.version)
+
+	require.Len(t, a.types, 2, "envelope schemas are not generated")
+	assert.Equal(t, "AItem", a.types[0].name, "colliding names are prefixed with the package")
+	assert.Equal(t, "BItem", a.types[1].name)
+	fields := a.types[0].fields
+	require.Len(t, fields, 3)
+	assert.True(t, fields[0].required)
+	assert.Equal(t, &typeRef{kind: kindTime, nullable: true}, fields[1].typ)
+	assert.Equal(t, []string{"x", "y"}, fields[2].typ.elem.enum)
+
+	require.Len(t, a.operations, 2, "event streams, form posts, XML documents and redirects are not generated")
+	list := a.operations[0]
+	assert.Equal(t, modeEnvelope, list.mode)
+	assert.True(t, list.auth)
+	assert.True(t, list.paginated())
+	assert.Equal(t, "AItem", list.pageItem.name)
+
+	remove := a.operations[1]
+	assert.Equal(t, modeEnvelope, remove.mode)
+	assert.Nil(t, remove.result)
+	assert.False(t, remove.auth)
+	require.Len(t, remove.pathParams, 1)
+
+	_, err = load([]byte(`{"paths": {"/x": {"get": {"responses": {}}}}}`))
+	assert.Error(t, err, "operations need an operationId")
+}
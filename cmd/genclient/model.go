@@ -0,0 +1,578 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go-server/internal/openapi"
+)
+
+// 响应外层结构，客户端拆开后只返回其中的数据，不单独生成类型
+const (
+	successSchema   = "models.SuccessResponse"
+	paginatedSchema = "models.PaginatedResponse"
+	errorSchema     = "models.EnhancedErrorResponse"
+)
+
+// skippedSchemas 不生成类型的组件
+var skippedSchemas = []string{successSchema, paginatedSchema, errorSchema, "models.ErrorResponse"}
+
+// 值类型
+const (
+	kindString    = "string"
+	kindInteger   = "integer"
+	kindInt64     = "int64"
+	kindNumber    = "number"
+	kindBoolean   = "boolean"
+	kindTime      = "time"
+	kindBinary    = "binary"
+	kindAny       = "any"
+	kindObject    = "object" // 没有声明字段的对象
+	kindArray     = "array"
+	kindMap       = "map"
+	kindNamed     = "named"
+	kindErrorCode = "errorCode"
+)
+
+// typeRef 字段、参数或响应数据的类型
+type typeRef struct {
+	kind     string
+	elem     *typeRef // 数组元素或映射的值
+	name     string   // kindNamed 时的类型名
+	enum     []string // 字符串的可选值
+	nullable bool
+}
+
+// typeDef 由组件生成的类型
+type typeDef struct {
+	schema      string // 组件名，如 models.SafeUser
+	name        string // 生成的类型名，如 SafeUser
+	description string
+	fields      []*field
+}
+
+// field 对象的字段
+type field struct {
+	jsonName    string
+	name        string // 导出的字段名
+	description string
+	typ         *typeRef
+	required    bool
+}
+
+// param 路径、查询参数或请求头
+type param struct {
+	name        string // 参数名或请求头名
+	in          string
+	field       string // 导出的字段名
+	description string
+	required    bool
+	typ         *typeRef
+}
+
+// 响应的解析方式
+const (
+	modeEnvelope = "envelope" // 从 SuccessResponse 的 data 取出数据
+	modeRaw      = "raw"      // 响应体就是数据
+	modeText     = "text"     // 纯文本
+)
+
+// operation 一个接口
+type operation struct {
+	id          string // operationId
+	method      string
+	path        string
+	summary     string
+	description string
+
+	pathParams   []*param
+	queryParams  []*param
+	headerParams []*param
+
+	body      *typeRef // JSON 请求体
+	multipart []string // multipart/form-data 请求体中的文件字段
+
+	mode     string
+	result   *typeRef // 响应数据，nil 表示没有数据
+	pageItem *typeRef // 分页接口每条数据的类型
+
+	auth        bool // 需要访问令牌
+	notModified bool // 可能返回 304
+}
+
+// params 查询参数和请求头，生成到一个参数结构中
+func (op *operation) params() []*param {
+	return append(slices.Clone(op.queryParams), op.headerParams...)
+}
+
+// paginated 支持按页码遍历所有数据
+func (op *operation) paginated() bool {
+	if op.pageItem == nil {
+		return false
+	}
+	for _, p := range op.queryParams {
+		if p.name == "page" && p.typ.kind == kindInteger {
+			return true
+		}
+	}
+	return false
+}
+
+// api 从 OpenAPI 文档整理出的类型和接口
+type api struct {
+	version    string
+	types      []*typeDef
+	operations []*operation
+	errorCodes []string
+
+	names map[string]string // 组件名到类型名
+}
+
+// methodOrder 同一路径下操作的顺序
+var methodOrder = []string{"get", "put", "post", "delete", "patch", "head"}
+
+// load 解析 OpenAPI 文档
+func load(spec []byte) (*api, error) {
+	var doc openapi.Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	a := &api{version: doc.Info.Version, names: typeNames(doc.Components.Schemas)}
+	if schema := doc.Components.Schemas[errorSchema]; schema != nil {
+		if code := schema.Properties["code"]; code != nil {
+			a.errorCodes = enumValues(code)
+		}
+	}
+
+	schemas := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		if !slices.Contains(skippedSchemas, name) {
+			schemas = append(schemas, name)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return a.names[schemas[i]] < a.names[schemas[j]]
+	})
+	for _, name := range schemas {
+		a.types = append(a.types, a.typeDef(name, doc.Components.Schemas[name]))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, method := range methodOrder {
+			op := operationOf(item, method)
+			if op == nil || skipped(op) {
+				continue
+			}
+			parsed, err := a.operation(method, path, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			a.operations = append(a.operations, parsed)
+		}
+	}
+	return a, nil
+}
+
+// operationOf 返回路径下指定方法的操作
+func operationOf(item *openapi.PathItem, method string) *openapi.Operation {
+	switch method {
+	case "get":
+		return item.Get
+	case "put":
+		return item.Put
+	case "post":
+		return item.Post
+	case "delete":
+		return item.Delete
+	case "patch":
+		return item.Patch
+	case "head":
+		return item.Head
+	}
+	return nil
+}
+
+// typeNames 组件使用最后一段作为类型名，重名时加上包名
+func typeNames(schemas map[string]*openapi.Schema) map[string]string {
+	count := map[string]int{}
+	for name := range schemas {
+		count[shortName(name)]++
+	}
+	names := make(map[string]string, len(schemas))
+	for name := range schemas {
+		short := shortName(name)
+		if count[short] > 1 {
+			pkg, _, _ := strings.Cut(name, ".")
+			short = exported(pkg) + short
+		}
+		names[name] = short
+	}
+	return names
+}
+
+// shortName 组件名的最后一段
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// refName 引用的组件名
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// typeDef 整理组件的字段
+func (a *api) typeDef(name string, schema *openapi.Schema) *typeDef {
+	def := &typeDef{schema: name, name: a.names[name], description: schema.Description}
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	for _, prop := range props {
+		s := schema.Properties[prop]
+		def.fields = append(def.fields, &field{
+			jsonName:    prop,
+			name:        exported(prop),
+			description: s.Description,
+			typ:         a.resolve(s),
+			required:    slices.Contains(schema.Required, prop),
+		})
+	}
+	return def
+}
+
+// resolve 结构对应的类型
+func (a *api) resolve(s *openapi.Schema) *typeRef {
+	if s == nil {
+		return &typeRef{kind: kindAny}
+	}
+	if s.Ref != "" {
+		return &typeRef{kind: kindNamed, name: a.names[refName(s.Ref)]}
+	}
+	if len(s.AllOf) > 0 {
+		// 第一项是引用的组件，其余项只覆盖说明或泛型字段
+		return a.resolve(s.AllOf[0])
+	}
+
+	typ, nullable := schemaType(s)
+	ref := &typeRef{nullable: nullable}
+	switch typ {
+	case "string":
+		ref.kind = kindString
+		switch s.Format {
+		case "date-time":
+			ref.kind = kindTime
+		case "binary":
+			ref.kind = kindBinary
+		}
+		ref.enum = enumValues(s)
+		if len(ref.enum) > 0 && slices.Equal(ref.enum, a.errorCodes) {
+			ref.kind, ref.enum = kindErrorCode, nil
+		}
+	case "integer":
+		ref.kind = kindInteger
+		if s.Format == "int64" {
+			ref.kind = kindInt64
+		}
+	case "number":
+		ref.kind = kindNumber
+	case "boolean":
+		ref.kind = kindBoolean
+	case "array":
+		ref.kind, ref.elem = kindArray, a.resolve(s.Items)
+	case "object":
+		ref.kind = kindObject
+		if s.AdditionalProperties != nil {
+			ref.kind, ref.elem = kindMap, a.resolve(s.AdditionalProperties)
+		}
+	default:
+		ref.kind = kindAny
+	}
+	return ref
+}
+
+// schemaType 返回结构的类型和是否可为 null
+func schemaType(s *openapi.Schema) (string, bool) {
+	switch t := s.Type.(type) {
+	case string:
+		return t, false
+	case []interface{}:
+		var typ string
+		nullable := false
+		for _, item := range t {
+			if name, _ := item.(string); name == "null" {
+				nullable = true
+			} else {
+				typ = name
+			}
+		}
+		return typ, nullable
+	}
+	return "", false
+}
+
+// enumValues 字符串枚举的可选值
+func enumValues(s *openapi.Schema) []string {
+	var values []string
+	for _, value := range s.Enum {
+		if str, ok := value.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
+// operation 整理接口的参数、请求体和成功响应
+func (a *api) operation(method, path string, op *openapi.Operation) (*operation, error) {
+	if op.OperationID == "" {
+		return nil, fmt.Errorf("missing operationId")
+	}
+	result := &operation{
+		id:          op.OperationID,
+		method:      method,
+		path:        path,
+		summary:     op.Summary,
+		description: op.Description,
+		auth:        len(op.Security) > 0,
+	}
+
+	for _, p := range op.Parameters {
+		parsed := &param{
+			name:        p.Name,
+			in:          p.In,
+			field:       exported(p.Name),
+			description: p.Description,
+			required:    p.Required,
+			typ:         a.resolve(p.Schema),
+		}
+		switch p.In {
+		case "path":
+			result.pathParams = append(result.pathParams, parsed)
+		case "query":
+			result.queryParams = append(result.queryParams, parsed)
+		case "header":
+			result.headerParams = append(result.headerParams, parsed)
+		default:
+			return nil, fmt.Errorf("unsupported parameter location %q", p.In)
+		}
+	}
+
+	if body := op.RequestBody; body != nil {
+		switch {
+		case body.Content["application/json"] != nil:
+			result.body = a.resolve(body.Content["application/json"].Schema)
+		case body.Content["multipart/form-data"] != nil:
+			schema := body.Content["multipart/form-data"].Schema
+			for _, name := range sortedKeys(schema.Properties) {
+				if schema.Properties[name].Format != "binary" {
+					return nil, fmt.Errorf("unsupported multipart field %q", name)
+				}
+				result.multipart = append(result.multipart, name)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported request body")
+		}
+	}
+
+	response := successResponse(op.Responses)
+	if response == nil {
+		return nil, fmt.Errorf("missing success response")
+	}
+	result.notModified = op.Responses["304"] != nil
+	if len(response.Content) == 0 {
+		result.mode = modeRaw
+		return result, nil
+	}
+	if media := response.Content["application/json"]; media != nil {
+		a.responseData(result, media.Schema)
+		return result, nil
+	}
+	if response.Content["text/plain"] != nil {
+		result.mode = modeText
+		result.result = &typeRef{kind: kindString}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unsupported response content type")
+}
+
+// skippedContent 成功响应为这些内容类型的接口（Server-Sent Events 流、签名链接下载的文件和 SAML 元数据）
+// 由浏览器通过 EventSource 或链接直接访问，或由其他系统导入，不生成客户端方法
+var skippedContent = []string{"text/event-stream", "application/octet-stream", "application/xml"}
+
+// skippedRequestContent 请求体为这些内容类型的接口（OAuth 令牌端点）由依赖方的 OAuth 库调用，不生成客户端方法
+var skippedRequestContent = []string{"application/x-www-form-urlencoded"}
+
+// skipped 判断是否不为接口生成客户端方法
+func skipped(op *openapi.Operation) bool {
+	if op.RequestBody != nil {
+		for _, mime := range skippedRequestContent {
+			if op.RequestBody.Content[mime] != nil {
+				return true
+			}
+		}
+	}
+	response := successResponse(op.Responses)
+	if response == nil {
+		// 只有重定向响应的接口（单点登录）由浏览器直接访问
+		for code := range op.Responses {
+			if strings.HasPrefix(code, "3") && code != "304" {
+				return true
+			}
+		}
+		return false
+	}
+	for _, mime := range skippedContent {
+		if response.Content[mime] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// successResponse 状态码最小的 2xx 响应
+func successResponse(responses map[string]*openapi.Response) *openapi.Response {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	sort.Strings(codes)
+	return responses[codes[0]]
+}
+
+// responseData 判断响应是否使用 SuccessResponse 包装，以及数据是否分页
+func (a *api) responseData(op *operation, schema *openapi.Schema) {
+	op.mode = modeRaw
+	switch {
+	case schema == nil:
+		op.result = &typeRef{kind: kindAny}
+		return
+	case refName(schema.Ref) == successSchema:
+		op.mode = modeEnvelope
+		return
+	case len(schema.AllOf) == 0 || refName(schema.AllOf[0].Ref) != successSchema:
+		op.result = a.resolve(schema)
+		return
+	}
+
+	op.mode = modeEnvelope
+	if len(schema.AllOf) < 2 || schema.AllOf[1].Properties["data"] == nil {
+		return
+	}
+	data := schema.AllOf[1].Properties["data"]
+	if len(data.AllOf) == 2 && refName(data.AllOf[0].Ref) == paginatedSchema {
+		if items := data.AllOf[1].Properties["data"]; items != nil && items.Items != nil {
+			op.pageItem = a.resolve(items.Items)
+			return
+		}
+	}
+	op.result = a.resolve(data)
+}
+
+// sortedKeys 排序后的映射键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// initialisms 导出名中保持大写的缩写
+var initialisms = map[string]string{
+	"id":   "ID",
+	"ip":   "IP",
+	"ips":  "IPs",
+	"url":  "URL",
+	"http": "HTTP",
+	"json": "JSON",
+	"api":  "API",
+	"jwt":  "JWT",
+	"asn":  "ASN",
+	"ttl":  "TTL",
+	"uuid": "UUID",
+}
+
+// words 按下划线、连字符、点和大小写变化拆分名称
+func words(name string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return result
+}
+
+// exported 转换为导出名，如 first_name 转换为 FirstName、If-None-Match 转换为 IfNoneMatch
+func exported(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	result := b.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+// unexported 转换为首字母小写的名称，用于参数名
+func unexported(name string) string {
+	parts := words(name)
+	if len(parts) == 0 {
+		return "v"
+	}
+	result := strings.ToLower(parts[0]) + exported(strings.Join(parts[1:], "_"))
+	if len(parts) == 1 {
+		result = strings.ToLower(parts[0])
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		result = "v" + result
+	}
+	return result
+}
+
+// quote 生成字符串字面量（Go 和 TypeScript 通用）
+func quote(s string) string {
+	return strconv.Quote(s)
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tsRuntime TypeScript 客户端中与接口无关的部分：错误、令牌刷新、请求发送和分页遍历
+const tsRuntime = `/** 服务端返回的错误，code 对应服务端的 AppError 错误代码 */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly code: ErrorCode | string;
+  readonly userMessage?: string;
+  readonly details?: Record<string, unknown>;
+  readonly correlationId?: string;
+  readonly requestId?: string;
+
+  constructor(init: {
+    status: number;
+    code: ErrorCode | string;
+    message: string;
+    userMessage?: string;
+    details?: Record<string, unknown>;
+    correlationId?: string;
+    requestId?: string;
+  }) {
+    super(init.message);
+    this.name = "ApiError";
+    this.status = init.status;
+    this.code = init.code;
+    this.userMessage = init.userMessage;
+    this.details = init.details;
+    this.correlationId = init.correlationId;
+    this.requestId = init.requestId;
+  }
+
+  /** 判断错误代码 */
+  is(code: ErrorCode): boolean {
+    return this.code === code;
+  }
+}
+
+/** 条件请求的资源未修改（304） */
+export class NotModifiedError extends Error {
+  constructor() {
+    super("not modified");
+    this.name = "NotModifiedError";
+  }
+}
+
+/** 无法解析错误响应时按状态码推断错误代码 */
+function codeForStatus(status: number): ErrorCode | string {
+  switch (status) {
+    case 400:
+      return "VALIDATION_ERROR";
+    case 401:
+      return "UNAUTHORIZED";
+    case 403:
+      return "FORBIDDEN";
+    case 404:
+      return "NOT_FOUND";
+    case 409:
+      return "CONFLICT";
+    case 412:
+      return "PRECONDITION_FAILED";
+    case 413:
+      return "PAYLOAD_TOO_LARGE";
+    case 429:
+      return "RATE_LIMIT_EXCEEDED";
+    case 503:
+      return "SERVICE_UNAVAILABLE";
+    case 504:
+      return "TIMEOUT";
+  }
+  return status >= 500 ? "INTERNAL_ERROR" : "UNKNOWN";
+}
+
+/** 解析信封格式（{"error": {...}}）或 RFC 7807 格式的错误响应 */
+async function parseError(response: Response): Promise<ApiError> {
+  const init = {
+    status: response.status,
+    code: codeForStatus(response.status),
+    message: response.statusText || "HTTP " + response.status,
+    correlationId: response.headers.get("X-Correlation-ID") ?? undefined,
+    requestId: response.headers.get("X-Request-ID") ?? undefined,
+  } as ConstructorParameters<typeof ApiError>[0];
+  try {
+    const body = (await response.json()) as Record<string, any>;
+    const error = body.error && typeof body.error === "object" ? body.error : body;
+    if (typeof error.code === "string" && error.code !== "") init.code = error.code;
+    const message = error.message ?? error.detail ?? body.message;
+    if (typeof message === "string" && message !== "") init.message = message;
+    init.userMessage = error.user_message ?? (typeof body.detail === "string" ? body.detail : undefined);
+    init.details = error.details;
+    if (typeof body.correlation_id === "string") init.correlationId = body.correlation_id;
+  } catch {
+    // 响应体不是 JSON 时只使用状态码
+  }
+  return new ApiError(init);
+}
+
+/** 提供访问令牌，invalidate 在服务端返回 401 后调用以便下次重新获取 */
+export interface TokenProvider {
+  getToken(): Promise<string | undefined>;
+  invalidate?(token: string): void;
+}
+
+/** 读取 JWT 的过期时间（毫秒），不校验签名 */
+function tokenExpiry(token: string): number | undefined {
+  const payload = token.split(".")[1];
+  if (!payload) return undefined;
+  try {
+    const claims = JSON.parse(atob(payload.replace(/-/g, "+").replace(/_/g, "/")));
+    return typeof claims.exp === "number" ? claims.exp * 1000 : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** 缓存令牌，在过期前 leewayMs 或令牌失效后重新获取；并发请求共用一次获取 */
+export class RefreshingTokenProvider implements TokenProvider {
+  private token?: string;
+  private expiry?: number;
+  private pending?: Promise<string>;
+
+  constructor(
+    private readonly fetchToken: () => Promise<string>,
+    private readonly leewayMs = 30_000,
+  ) {}
+
+  async getToken(): Promise<string> {
+    if (this.token && (this.expiry === undefined || Date.now() < this.expiry - this.leewayMs)) {
+      return this.token;
+    }
+    if (!this.pending) {
+      this.pending = this.fetchToken()
+        .then((token) => {
+          this.token = token;
+          this.expiry = tokenExpiry(token);
+          return token;
+        })
+        .finally(() => {
+          this.pending = undefined;
+        });
+    }
+    return this.pending;
+  }
+
+  invalidate(token: string): void {
+    if (this.token === token) {
+      this.token = undefined;
+      this.expiry = undefined;
+    }
+  }
+}
+
+/** 使用邮箱和密码登录获取令牌，令牌过期或失效后自动重新登录 */
+export function loginTokenProvider(client: ApiClient, email: string, password: string): RefreshingTokenProvider {
+  return new RefreshingTokenProvider(async () => (await client.authLogin({ email, password })).token);
+}
+
+export interface ClientOptions {
+  /** 服务地址，如 http://localhost:8080 */
+  baseUrl: string;
+  /** 固定的访问令牌或令牌提供者 */
+  token?: string | TokenProvider;
+  /** 默认使用全局 fetch */
+  fetch?: typeof fetch;
+  /** 每个请求都携带的请求头 */
+  headers?: Record<string, string>;
+}
+
+export interface RequestOptions {
+  headers?: Record<string, string>;
+  signal?: AbortSignal;
+  /** 收到成功响应后调用，用于读取 ETag 等响应头 */
+  onResponse?: (response: Response) => void;
+}
+
+type QueryValue = string | number | boolean | undefined | null;
+
+interface RequestSpec {
+  method: string;
+  path: string;
+  query?: Record<string, QueryValue>;
+  headers?: Record<string, QueryValue>;
+  body?: unknown;
+  form?: FormData;
+  auth?: boolean;
+  envelope?: boolean;
+  text?: boolean;
+}
+
+/** 从 page（默认第 1 页）开始逐页获取并逐条返回数据 */
+async function* paginate<T>(page: number | undefined, fetchPage: (page: number) => Promise<Page<T>>): AsyncGenerator<T> {
+  for (let current = page && page > 0 ? page : 1; ; current++) {
+    const result = await fetchPage(current);
+    const items = result.data ?? [];
+    yield* items;
+    if (items.length === 0 || current >= (result.pagination?.total_pages ?? 0)) return;
+  }
+}
+
+/** 一页数据 */
+export interface Page<T> {
+  data: T[];
+  pagination: Pagination;
+}
+`
+
+// tsIdentifier 可以直接作为属性名的标识符
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsWriter 生成 TypeScript 客户端源码
+type tsWriter struct {
+	b strings.Builder
+}
+
+func (w *tsWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+// generateTypeScript 生成单文件的 TypeScript 客户端，只依赖 fetch
+func generateTypeScript(a *api) []byte {
+	w := &tsWriter{}
+	w.printf("// Code generated by go run ./cmd/genclient; DO NOT EDIT.\n\n")
+	w.printf("/** 生成客户端使用的接口文档版本 */\nexport const API_VERSION = %s;\n\n", quote(a.version))
+	w.printf("/** 服务端返回的错误代码 */\nexport const ERROR_CODES = [\n")
+	for _, code := range a.errorCodes {
+		w.printf("  %s,\n", quote(code))
+	}
+	w.printf("] as const;\n\nexport type ErrorCode = (typeof ERROR_CODES)[number];\n\n")
+	w.printf("%s\n", tsRuntime)
+
+	for _, def := range a.types {
+		w.typeDef(def)
+	}
+	for _, op := range a.operations {
+		if params := op.params(); len(params) > 0 {
+			w.paramsType(op, params)
+		}
+	}
+
+	w.printf("/** 接口客户端，方法名与 operationId 一致 */\n")
+	w.printf("export class ApiClient {\n")
+	w.printf(`  private readonly baseUrl: string;
+  private readonly tokenProvider?: TokenProvider;
+  private readonly fetchImpl: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.tokenProvider =
+      typeof options.token === "string" ? { getToken: async () => options.token as string } : options.token;
+    this.fetchImpl = options.fetch ?? fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+  /** 发送请求并解析响应，需要认证的请求收到 401 后使令牌失效并重试一次 */
+  private async request<T>(spec: RequestSpec, options?: RequestOptions, retried = false): Promise<T> {
+    const url = new URL(this.baseUrl + spec.path);
+    for (const [key, value] of Object.entries(spec.query ?? {})) {
+      if (value !== undefined && value !== null && value !== "") url.searchParams.set(key, String(value));
+    }
+    const headers = new Headers(this.headers);
+    headers.set("Accept", spec.text ? "text/plain" : "application/json");
+    for (const [key, value] of Object.entries(spec.headers ?? {})) {
+      if (value !== undefined && value !== null && value !== "") headers.set(key, String(value));
+    }
+    for (const [key, value] of Object.entries(options?.headers ?? {})) headers.set(key, value);
+    let token: string | undefined;
+    if (spec.auth && this.tokenProvider) {
+      token = await this.tokenProvider.getToken();
+      if (token) headers.set("Authorization", "Bearer " + token);
+    }
+    let body: BodyInit | undefined;
+    if (spec.form) {
+      body = spec.form;
+    } else if (spec.body !== undefined) {
+      headers.set("Content-Type", "application/json");
+      body = JSON.stringify(spec.body);
+    }
+
+    const response = await this.fetchImpl(url, { method: spec.method, headers, body, signal: options?.signal });
+    if (response.status === 401 && token && !retried && this.tokenProvider?.invalidate) {
+      this.tokenProvider.invalidate(token);
+      return this.request<T>(spec, options, true);
+    }
+    if (response.status === 304) throw new NotModifiedError();
+    if (!response.ok) throw await parseError(response);
+    options?.onResponse?.(response);
+
+    if (spec.text) return (await response.text()) as T;
+    const text = await response.text();
+    if (text === "") return undefined as T;
+    const data = JSON.parse(text);
+    return (spec.envelope ? data.data : data) as T;
+  }
+`)
+	for _, op := range a.operations {
+		w.operation(op)
+	}
+	w.printf("}\n")
+	return []byte(w.b.String())
+}
+
+// typeDef 生成组件对应的接口
+func (w *tsWriter) typeDef(def *typeDef) {
+	w.printf("/** %s */\n", oneLine(def.description, def.schema))
+	w.printf("export interface %s {\n", def.name)
+	for _, f := range def.fields {
+		if f.description != "" {
+			w.printf("  /** %s */\n", oneLine(f.description, ""))
+		}
+		optional := "?"
+		if f.required {
+			optional = ""
+		}
+		w.printf("  %s%s: %s;\n", tsProperty(f.jsonName), optional, tsType(f.typ))
+	}
+	w.printf("}\n\n")
+}
+
+// tsType 类型对应的 TypeScript 类型
+func tsType(t *typeRef) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "string"
+		if len(t.enum) > 0 {
+			values := make([]string, len(t.enum))
+			for i, value := range t.enum {
+				values[i] = quote(value)
+			}
+			name = strings.Join(values, " | ")
+		}
+	case kindInteger, kindInt64, kindNumber:
+		name = "number"
+	case kindBoolean:
+		name = "boolean"
+	case kindTime:
+		name = "string"
+	case kindBinary:
+		name = "Blob"
+	case kindErrorCode:
+		name = "ErrorCode"
+	case kindNamed:
+		name = t.name
+	case kindArray:
+		name = "Array<" + tsType(t.elem) + ">"
+	case kindMap:
+		name = "Record<string, " + tsType(t.elem) + ">"
+	case kindObject:
+		name = "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+	if t.nullable {
+		return name + " | null"
+	}
+	return name
+}
+
+// tsProperty 属性名，不是标识符时加引号
+func tsProperty(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return quote(name)
+}
+
+// tsParam 读取参数结构中的参数
+func tsParam(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return "params?." + name
+	}
+	return "params?.[" + quote(name) + "]"
+}
+
+// paramsType 生成查询参数和请求头的接口
+func (w *tsWriter) paramsType(op *operation, params []*param) {
+	w.printf("/** %s 的查询参数和请求头 */\n", op.id)
+	w.printf("export interface %sParams {\n", exported(op.id))
+	for _, p := range params {
+		if p.description != "" {
+			w.printf("  /** %s */\n", oneLine(p.description, ""))
+		}
+		optional := "?"
+		if p.required {
+			optional = ""
+		}
+		w.printf("  %s%s: %s;\n", tsProperty(p.name), optional, tsType(p.typ))
+	}
+	w.printf("}\n\n")
+}
+
+// operation 生成接口方法，分页接口额外生成异步遍历方法
+func (w *tsWriter) operation(op *operation) {
+	params := op.params()
+	var args, callArgs []string
+	pathArgs := map[string]string{}
+	for _, p := range op.pathParams {
+		arg := unexported(p.name)
+		pathArgs[p.name] = arg
+		args = append(args, arg+": string")
+		callArgs = append(callArgs, arg)
+	}
+	if op.body != nil {
+		args = append(args, "body: "+tsType(op.body))
+		callArgs = append(callArgs, "body")
+	}
+	for _, field := range op.multipart {
+		arg := unexported(field)
+		args = append(args, arg+": Blob", arg+"Filename?: string")
+		callArgs = append(callArgs, arg, arg+"Filename")
+	}
+	if len(params) > 0 {
+		args = append(args, "params?: "+exported(op.id)+"Params")
+	}
+	args = append(args, "options?: RequestOptions")
+
+	result := "void"
+	switch {
+	case op.pageItem != nil:
+		result = "Page<" + tsType(op.pageItem) + ">"
+	case op.result != nil:
+		result = tsType(op.result)
+	}
+
+	w.printf("\n  /**\n   * %s\n", oneLine(op.summary, op.id))
+	if op.description != "" && op.description != op.summary {
+		w.printf("   *\n   * %s\n", oneLine(op.description, ""))
+	}
+	if op.notModified {
+		w.printf("   *\n   * 资源未修改（304）时抛出 NotModifiedError。\n")
+	}
+	w.printf("   *\n   * %s %s\n   */\n", strings.ToUpper(op.method), op.path)
+	w.printf("  async %s(%s): Promise<%s> {\n", op.id, strings.Join(args, ", "), result)
+	for _, field := range op.multipart {
+		arg := unexported(field)
+		w.printf("    const form = new FormData();\n")
+		w.printf("    form.append(%s, %s, %sFilename ?? %s);\n", quote(field), arg, arg, quote(field))
+	}
+	w.printf("    return this.request<%s>(\n      {\n", result)
+	w.printf("        method: %s,\n", quote(strings.ToUpper(op.method)))
+	w.printf("        path: %s,\n", tsPath(op.path, pathArgs))
+	if len(op.queryParams) > 0 {
+		w.printf("        query: {")
+		for i, p := range op.queryParams {
+			if i > 0 {
+				w.printf(",")
+			}
+			w.printf(" %s: %s", tsProperty(p.name), tsParam(p.name))
+		}
+		w.printf(" },\n")
+	}
+	if len(op.headerParams) > 0 {
+		w.printf("        headers: {")
+		for i, p := range op.headerParams {
+			if i > 0 {
+				w.printf(",")
+			}
+			w.printf(" %s: %s", quote(p.name), tsParam(p.name))
+		}
+		w.printf(" },\n")
+	}
+	if op.body != nil {
+		w.printf("        body,\n")
+	}
+	if len(op.multipart) > 0 {
+		w.printf("        form,\n")
+	}
+	if op.auth {
+		w.printf("        auth: true,\n")
+	}
+	switch op.mode {
+	case modeEnvelope:
+		w.printf("        envelope: true,\n")
+	case modeText:
+		w.printf("        text: true,\n")
+	}
+	w.printf("      },\n      options,\n    );\n  }\n")
+
+	if op.paginated() {
+		item := tsType(op.pageItem)
+		w.printf("\n  /** 从 params.page（默认第 1 页）开始逐页调用 %s，逐条返回数据 */\n", op.id)
+		w.printf("  async *%sIter(%s): AsyncGenerator<%s> {\n", op.id, strings.Join(args, ", "), item)
+		callArgs = append(callArgs, "{ ...params, page }", "options")
+		w.printf("    yield* paginate(params?.page, (page) => this.%s(%s));\n  }\n", op.id, strings.Join(callArgs, ", "))
+	}
+}
+
+// tsPath 生成请求路径表达式，路径参数经过转义
+func tsPath(path string, args map[string]string) string {
+	var parts []string
+	rest := path
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, quote(rest[:start]))
+		}
+		parts = append(parts, "encodeURIComponent("+args[rest[start+1:end]]+")")
+		rest = rest[end+1:]
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
@@ -0,0 +1,148 @@
+// loadtest 对运行中的服务压测关键接口，记录延迟分位数和错误率，并与提交的基线比较作为性能回退门禁
+//
+// 每个场景依次以固定速率运行：预热后按 -rate 每秒发出请求，持续 -duration，
+// 结果与 -baseline 比较，P95/P99 延迟增长超过 -max-regression（且超过 -min-delta 毫秒）
+// 或错误率增加超过 -max-error-increase 时以退出码 1 结束。
+//
+// 用法：
+//
+//	make loadtest                                            启动 docker-compose 压测环境并运行
+//	go run ./cmd/loadtest -base-url http://localhost:8080    对已运行的服务压测并与基线比较
+//	go run ./cmd/loadtest -scenarios profile,users-list      只运行部分场景
+//	go run ./cmd/loadtest -update-baseline                   以本次结果覆盖基线文件
+//
+// 基线与机器相关，应在固定的参考环境上用 make loadtest-baseline 记录后提交
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	var (
+		baseURL          = flag.String("base-url", "http://localhost:8080", "Base URL of the server under test")
+		scenarioNames    = flag.String("scenarios", "", "Comma-separated scenarios to run (default all)")
+		rate             = flag.Int("rate", 50, "Requests per second for each scenario")
+		duration         = flag.Duration("duration", 20*time.Second, "Measured duration of each scenario")
+		warmup           = flag.Duration("warmup", 3*time.Second, "Unmeasured warm-up before each scenario")
+		concurrency      = flag.Int("concurrency", 64, "Maximum in-flight requests")
+		timeout          = flag.Duration("timeout", 10*time.Second, "Per-request timeout")
+		email            = flag.String("email", "loadtest@example.com", "Load test user, registered if missing")
+		password         = flag.String("password", "Benchmark-Pass-42", "Password of the load test user")
+		adminEmail       = flag.String("admin-email", "admin@example.com", "Admin account for admin scenarios (the development seed admin by default)")
+		adminPassword    = flag.String("admin-password", "password", "Password of the admin account")
+		baselinePath     = flag.String("baseline", "cmd/loadtest/baseline.json", "Baseline to compare against")
+		updateBaseline   = flag.Bool("update-baseline", false, "Write the results to the baseline instead of comparing")
+		out              = flag.String("out", "", "Also write the results as JSON to this file")
+		maxRegression    = flag.Float64("max-regression", 0.2, "Allowed relative increase of p95/p99 latency")
+		minDelta         = flag.Float64("min-delta", 2, "Latency increases below this many milliseconds are treated as noise")
+		maxErrorIncrease = flag.Float64("max-error-increase", 0.01, "Allowed absolute increase of the error rate")
+	)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	selected, err := selectScenarios(*scenarioNames)
+	if err != nil {
+		fail(err)
+	}
+	if *rate <= 0 || *concurrency <= 0 || *duration <= 0 {
+		fail(errors.New("-rate, -concurrency and -duration must be positive"))
+	}
+
+	var baseline *Report
+	if !*updateBaseline {
+		baseline, err = readReport(*baselinePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			fail(fmt.Errorf("no baseline at %s, record one on the reference environment with `make loadtest-baseline`", *baselinePath))
+		}
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = *concurrency
+	transport.MaxIdleConnsPerHost = *concurrency
+	httpClient := &http.Client{Transport: transport, Timeout: *timeout}
+
+	env := &environment{baseURL: *baseURL, email: *email, password: *password}
+	if err := prepare(ctx, httpClient, env, *adminEmail, *adminPassword); err != nil {
+		fail(fmt.Errorf("prepare: %w", err))
+	}
+
+	report := Report{
+		BaseURL:   *baseURL,
+		Rate:      *rate,
+		Duration:  duration.String(),
+		StartedAt: time.Now().UTC(),
+		Scenarios: make(map[string]Stats),
+	}
+	var order []string
+	l := load{rate: *rate, duration: *duration, warmup: *warmup, concurrency: *concurrency}
+	for _, sc := range selected {
+		if sc.admin && env.adminToken == "" {
+			fmt.Fprintf(os.Stderr, "skip %s: could not log in as %s\n", sc.name, *adminEmail)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "run %s: %s\n", sc.name, sc.description)
+		res, err := run(ctx, httpClient, sc, env, l)
+		if err != nil {
+			fail(err)
+		}
+		if ctx.Err() != nil {
+			fail(errors.New("interrupted"))
+		}
+		if res.firstError != "" {
+			fmt.Fprintf(os.Stderr, "  %d errors, first: %s\n", res.errors, res.firstError)
+		}
+		report.Scenarios[sc.name] = summarize(res)
+		order = append(order, sc.name)
+	}
+
+	writeTable(os.Stdout, report, baseline, order)
+	if *out != "" {
+		if err := writeReport(*out, report); err != nil {
+			fail(err)
+		}
+	}
+
+	if *updateBaseline {
+		if err := writeReport(*baselinePath, report); err != nil {
+			fail(err)
+		}
+		fmt.Printf("Wrote baseline %s\n", *baselinePath)
+		return
+	}
+
+	regressions, notes := compare(*baseline, report, thresholds{
+		maxRegression:    *maxRegression,
+		minDelta:         *minDelta,
+		maxErrorIncrease: *maxErrorIncrease,
+	})
+	for _, note := range notes {
+		fmt.Printf("note: %s\n", note)
+	}
+	if len(regressions) > 0 {
+		for _, regression := range regressions {
+			fmt.Printf("REGRESSION %s\n", regression)
+		}
+		os.Exit(1)
+	}
+	fmt.Println("No performance regressions against the baseline")
+}
+
+// fail 输出错误并以退出码 1 结束
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "loadtest: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/pkg/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPercentile 测试最近秩法的分位数
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 100))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+// TestSummarize 测试未能发出的请求计入错误率
+func TestSummarize(t *testing.T) {
+	stats := summarize(result{
+		latencies: []time.Duration{3 * time.Millisecond, time.Millisecond, 2 * time.Millisecond},
+		errors:    1,
+		dropped:   1,
+		elapsed:   2 * time.Second,
+	})
+
+	assert.Equal(t, 4, stats.Requests)
+	assert.Equal(t, 0.4, stats.ErrorRate)
+	assert.Equal(t, 2.0, stats.RPS)
+	assert.Equal(t, 2.0, stats.P50)
+	assert.Equal(t, 3.0, stats.Max)
+}
+
+// TestCompare 测试回退判定的阈值和噪声下限
+func TestCompare(t *testing.T) {
+	baseline := Report{Rate: 50, Scenarios: map[string]Stats{
+		"profile": {P95: 10, P99: 20, ErrorRate: 0},
+		"health":  {P95: 1, P99: 2, ErrorRate: 0},
+		"login":   {P95: 80, P99: 100, ErrorRate: 0.001},
+	}}
+	current := Report{Rate: 100, Scenarios: map[string]Stats{
+		"profile":      {P95: 15, P99: 21, ErrorRate: 0},     // P95 增长 50%
+		"health":       {P95: 2, P99: 3.5, ErrorRate: 0},     // 增长比例大但低于噪声下限
+		"login":        {P95: 80, P99: 100, ErrorRate: 0.05}, // 错误率上升
+		"openapi-gzip": {P95: 5, P99: 6},
+	}}
+
+	regressions, notes := compare(baseline, current, thresholds{maxRegression: 0.2, minDelta: 2, maxErrorIncrease: 0.01})
+
+	require.Len(t, regressions, 2)
+	assert.Contains(t, regressions[0], "login: error rate")
+	assert.Contains(t, regressions[1], "profile: p95 latency")
+	assert.Len(t, notes, 2, "新场景没有基线，速率与基线不同")
+}
+
+// TestScenarios 测试每个场景在真实路由上返回期望的状态码
+func TestScenarios(t *testing.T) {
+	server := httptest.NewServer(apitest.New(t).Engine)
+	defer server.Close()
+
+	ctx := context.Background()
+	httpClient := server.Client()
+	env := &environment{baseURL: server.URL, email: "loadtest@example.com", password: "Benchmark-Pass-42"}
+	require.NoError(t, prepare(ctx, httpClient, env, "admin@example.com", apitest.DefaultPassword))
+	require.NotEmpty(t, env.adminToken)
+
+	// 用户已存在时直接登录
+	require.NoError(t, prepare(ctx, httpClient, env, "", ""))
+
+	report := Report{Rate: 20, Scenarios: make(map[string]Stats)}
+	var order []string
+	for _, sc := range scenarios {
+		res, err := run(ctx, httpClient, sc, env, load{rate: 20, duration: 300 * time.Millisecond, concurrency: 4})
+		require.NoError(t, err)
+		assert.Zero(t, res.errors, "%s: %s", sc.name, res.firstError)
+		assert.NotEmpty(t, res.latencies, sc.name)
+		assert.Equal(t, len(res.latencies), res.statuses[sc.status], sc.name)
+		report.Scenarios[sc.name] = summarize(res)
+		order = append(order, sc.name)
+	}
+
+	var table bytes.Buffer
+	writeTable(&table, report, nil, order)
+	assert.Contains(t, table.String(), "profile-etag")
+}
+
+// TestSelectScenarios 测试按名称选择场景
+func TestSelectScenarios(t *testing.T) {
+	selected, err := selectScenarios("profile, health")
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "profile", selected[0].name)
+
+	all, err := selectScenarios("")
+	require.NoError(t, err)
+	assert.Len(t, all, len(scenarios))
+
+	_, err = selectScenarios("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats 一个场景的汇总结果，延迟单位为毫秒
+type Stats struct {
+	Requests  int         `json:"requests"`
+	Errors    int         `json:"errors"`
+	Dropped   int         `json:"dropped"`
+	ErrorRate float64     `json:"error_rate"` // 错误和未能发出的请求占全部计划请求的比例
+	RPS       float64     `json:"rps"`        // 实际完成的每秒请求数
+	P50       float64     `json:"p50_ms"`
+	P95       float64     `json:"p95_ms"`
+	P99       float64     `json:"p99_ms"`
+	Max       float64     `json:"max_ms"`
+	Statuses  map[int]int `json:"statuses,omitempty"`
+}
+
+// Report 一次压测的结果，同一格式也用作基线文件
+type Report struct {
+	BaseURL   string           `json:"base_url"`
+	Rate      int              `json:"rate"`
+	Duration  string           `json:"duration"`
+	StartedAt time.Time        `json:"started_at"`
+	Scenarios map[string]Stats `json:"scenarios"`
+}
+
+// summarize 计算延迟分位数和错误率
+func summarize(res result) Stats {
+	latencies := append([]time.Duration(nil), res.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	stats := Stats{
+		Requests: len(latencies) + res.errors,
+		Errors:   res.errors,
+		Dropped:  res.dropped,
+		P50:      milliseconds(percentile(latencies, 50)),
+		P95:      milliseconds(percentile(latencies, 95)),
+		P99:      milliseconds(percentile(latencies, 99)),
+		Statuses: res.statuses,
+	}
+	if len(latencies) > 0 {
+		stats.Max = milliseconds(latencies[len(latencies)-1])
+	}
+	if planned := stats.Requests + stats.Dropped; planned > 0 {
+		stats.ErrorRate = round(float64(stats.Errors+stats.Dropped) / float64(planned))
+	}
+	if res.elapsed > 0 {
+		stats.RPS = round(float64(stats.Requests) / res.elapsed.Seconds())
+	}
+	return stats
+}
+
+// percentile 返回已排序延迟的 p 分位数（最近秩法），没有样本时为 0
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// milliseconds 将时长转换为保留三位小数的毫秒数
+func milliseconds(d time.Duration) float64 {
+	return round(float64(d) / float64(time.Millisecond))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// thresholds 判定性能回退的阈值
+type thresholds struct {
+	maxRegression    float64 // P95、P99 相对基线允许增长的比例
+	minDelta         float64 // 延迟增长不超过该毫秒数时视为噪声
+	maxErrorIncrease float64 // 错误率相对基线允许增加的绝对值
+}
+
+// compare 将结果与基线比较，返回所有回退的描述；基线中没有的场景只提示不判定
+func compare(baseline, current Report, t thresholds) (regressions, notes []string) {
+	names := make([]string, 0, len(current.Scenarios))
+	for name := range current.Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cur := current.Scenarios[name]
+		base, ok := baseline.Scenarios[name]
+		if !ok {
+			notes = append(notes, fmt.Sprintf("%s: no baseline, not compared", name))
+			continue
+		}
+		for _, metric := range []struct {
+			name      string
+			base, cur float64
+		}{{"p95", base.P95, cur.P95}, {"p99", base.P99, cur.P99}} {
+			if metric.cur-metric.base > t.minDelta && metric.cur > metric.base*(1+t.maxRegression) {
+				regressions = append(regressions, fmt.Sprintf("%s: %s latency %.2fms -> %.2fms (+%.0f%%, limit +%.0f%%)",
+					name, metric.name, metric.base, metric.cur, (metric.cur/metric.base-1)*100, t.maxRegression*100))
+			}
+		}
+		if cur.ErrorRate-base.ErrorRate > t.maxErrorIncrease {
+			regressions = append(regressions, fmt.Sprintf("%s: error rate %.2f%% -> %.2f%% (limit +%.2f%%)",
+				name, base.ErrorRate*100, cur.ErrorRate*100, t.maxErrorIncrease*100))
+		}
+	}
+	if baseline.Rate != 0 && baseline.Rate != current.Rate {
+		notes = append(notes, fmt.Sprintf("baseline was recorded at %d req/s, this run used %d req/s", baseline.Rate, current.Rate))
+	}
+	return regressions, notes
+}
+
+// writeTable 输出结果表格，有基线时附带基线的 P95
+func writeTable(w io.Writer, report Report, baseline *Report, order []string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\trequests\trps\terrors\tp50 ms\tp95 ms\tp99 ms\tmax ms\tbaseline p95\t")
+	for _, name := range order {
+		stats, ok := report.Scenarios[name]
+		if !ok {
+			continue
+		}
+		basePCT := "-"
+		if baseline != nil {
+			if base, ok := baseline.Scenarios[name]; ok {
+				basePCT = fmt.Sprintf("%.2f", base.P95)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.2f%%\t%.2f\t%.2f\t%.2f\t%.2f\t%s\t\n",
+			name, stats.Requests, stats.RPS, stats.ErrorRate*100, stats.P50, stats.P95, stats.P99, stats.Max, basePCT)
+	}
+	tw.Flush()
+}
+
+// readReport 读取结果或基线文件
+func readReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// writeReport 以缩进的 JSON 写入结果或基线文件
+func writeReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// load 一个场景的压力参数
+type load struct {
+	rate        int           // 每秒请求数
+	duration    time.Duration // 记录结果的时长
+	warmup      time.Duration // 开始记录前的预热时长，预热期间的请求不计入结果
+	concurrency int           // 同时进行的请求上限
+}
+
+// result 一个场景的原始结果
+type result struct {
+	latencies  []time.Duration // 成功请求的延迟
+	errors     int             // 网络错误或状态码不符的请求数
+	dropped    int             // 所有并发槽都被占用而未能按时发出的请求数
+	statuses   map[int]int     // 按状态码统计的请求数，网络错误不计入
+	firstError string          // 第一个错误的描述，便于排查
+	elapsed    time.Duration
+}
+
+// sample 一次请求的结果
+type sample struct {
+	latency time.Duration
+	status  int
+	err     string
+}
+
+// run 以固定速率执行场景：按 rate 生成请求时机，由至多 concurrency 个 worker 发出
+// 时机到来时没有空闲 worker 则记为 dropped，说明服务已跟不上该速率，不会在客户端排队掩盖延迟
+func run(ctx context.Context, httpClient *http.Client, sc scenario, env *environment, l load) (result, error) {
+	if sc.setup != nil {
+		if err := sc.setup(ctx, httpClient, env); err != nil {
+			return result{}, fmt.Errorf("set up %s: %w", sc.name, err)
+		}
+	}
+	if l.warmup > 0 {
+		fire(ctx, httpClient, sc, env, load{rate: l.rate, duration: l.warmup, concurrency: l.concurrency})
+	}
+	return fire(ctx, httpClient, sc, env, l), nil
+}
+
+// fire 在 l.duration 内按速率发出请求并收集结果
+func fire(ctx context.Context, httpClient *http.Client, sc scenario, env *environment, l load) result {
+	ctx, cancel := context.WithTimeout(ctx, l.duration)
+	defer cancel()
+
+	ticks := make(chan struct{})
+	samples := make(chan sample, l.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < l.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ticks {
+				samples <- send(context.WithoutCancel(ctx), httpClient, sc, env)
+			}
+		}()
+	}
+
+	res := result{statuses: make(map[int]int)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s := range samples {
+			switch {
+			case s.err != "":
+				res.errors++
+				if res.firstError == "" {
+					res.firstError = s.err
+				}
+			default:
+				res.latencies = append(res.latencies, s.latency)
+			}
+			if s.status != 0 {
+				res.statuses[s.status]++
+			}
+		}
+	}()
+
+	start := time.Now()
+	interval := time.Second / time.Duration(max(l.rate, 1))
+	ticker := time.NewTicker(interval)
+	dropped := 0
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case ticks <- struct{}{}:
+			default:
+				dropped++
+			}
+		}
+	}
+	ticker.Stop()
+	close(ticks)
+	wg.Wait()
+	close(samples)
+	<-done
+
+	res.dropped = dropped
+	res.elapsed = time.Since(start)
+	return res
+}
+
+// send 发出一次请求并读完响应体，延迟包含读取响应体的时间
+func send(ctx context.Context, httpClient *http.Client, sc scenario, env *environment) sample {
+	req, err := sc.request(ctx, env)
+	if err != nil {
+		return sample{err: err.Error()}
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return sample{err: err.Error()}
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	s := sample{latency: latency, status: resp.StatusCode}
+	switch {
+	case err != nil:
+		s.err = fmt.Sprintf("read body: %v", err)
+	case resp.StatusCode != sc.status:
+		s.err = fmt.Sprintf("%s %s: status %d, want %d", req.Method, req.URL.Path, resp.StatusCode, sc.status)
+	}
+	return s
+}
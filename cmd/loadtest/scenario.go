@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go-server/pkg/client"
+)
+
+// scenario 一个压测场景，每次请求都重新构造以免共享请求体
+type scenario struct {
+	name        string
+	description string
+	admin       bool // 需要管理员令牌，未能以管理员登录时跳过
+	status      int  // 期望的状态码，其他状态码计为错误
+	request     func(ctx context.Context, env *environment) (*http.Request, error)
+	// setup 在场景开始前执行，用于取得依赖其他场景副作用的数据
+	setup func(ctx context.Context, httpClient *http.Client, env *environment) error
+}
+
+// scenarios 按输出顺序排列的场景，覆盖认证、缓存、ETag 和压缩等对性能敏感的路径
+var scenarios = []scenario{
+	{
+		name:        "health",
+		description: "GET /api/v1/health, database and cache statistics",
+		status:      http.StatusOK,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			return env.newRequest(ctx, http.MethodGet, "/api/v1/health", "", nil)
+		},
+	},
+	{
+		name:        "login",
+		description: "POST /api/v1/auth/login, password hashing and token signing",
+		status:      http.StatusOK,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			body := fmt.Sprintf(`{"email":%q,"password":%q}`, env.email, env.password)
+			req, err := env.newRequest(ctx, http.MethodPost, "/api/v1/auth/login", "", strings.NewReader(body))
+			if err == nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			return req, err
+		},
+	},
+	{
+		name:        "profile",
+		description: "GET /api/v1/users/me, token validation, blacklist check and user cache",
+		status:      http.StatusOK,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			return env.newRequest(ctx, http.MethodGet, "/api/v1/users/me", env.userToken, nil)
+		},
+	},
+	{
+		name:        "profile-etag",
+		description: "GET /api/v1/users/me with If-None-Match, conditional response",
+		status:      http.StatusNotModified,
+		// 登录会更新最后登录时间，ETag 须在登录场景之后重新获取
+		setup: fetchProfileETag,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			req, err := env.newRequest(ctx, http.MethodGet, "/api/v1/users/me", env.userToken, nil)
+			if err == nil {
+				req.Header.Set("If-None-Match", env.profileETag)
+			}
+			return req, err
+		},
+	},
+	{
+		name:        "users-list",
+		description: "GET /api/v1/users, paginated list served from the response cache",
+		admin:       true,
+		status:      http.StatusOK,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			return env.newRequest(ctx, http.MethodGet, "/api/v1/users?page=1&limit=20", env.adminToken, nil)
+		},
+	},
+	{
+		name:        "openapi-gzip",
+		description: "GET /openapi.json with Accept-Encoding: gzip, response compression",
+		status:      http.StatusOK,
+		request: func(ctx context.Context, env *environment) (*http.Request, error) {
+			req, err := env.newRequest(ctx, http.MethodGet, "/openapi.json", "", nil)
+			if err == nil {
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+			return req, err
+		},
+	},
+}
+
+// environment 场景共享的服务地址和准备阶段取得的令牌
+type environment struct {
+	baseURL     string
+	email       string
+	password    string
+	userToken   string
+	adminToken  string
+	profileETag string
+}
+
+// newRequest 构造请求，token 非空时携带 Bearer 令牌
+func (env *environment) newRequest(ctx context.Context, method, path, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, env.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// prepare 注册（已存在时直接登录）压测用户，并尝试以管理员登录
+// 管理员登录失败不是错误，需要管理员的场景会被跳过
+func prepare(ctx context.Context, httpClient *http.Client, env *environment, adminEmail, adminPassword string) error {
+	api := client.New(env.baseURL, client.WithHTTPClient(httpClient))
+
+	username := strings.SplitN(env.email, "@", 2)[0]
+	_, err := api.AuthRegister(ctx, client.RegisterRequest{Username: username, Email: env.email, Password: env.password})
+	if err != nil && !errors.Is(err, client.ErrCodeConflict) {
+		return fmt.Errorf("register %s: %w", env.email, err)
+	}
+	login, err := api.AuthLogin(ctx, client.LoginRequest{Email: env.email, Password: env.password})
+	if err != nil {
+		return fmt.Errorf("log in as %s: %w", env.email, err)
+	}
+	env.userToken = login.Token
+
+	if adminEmail != "" {
+		admin, err := api.AuthLogin(ctx, client.LoginRequest{Email: adminEmail, Password: adminPassword})
+		if err == nil {
+			env.adminToken = admin.Token
+		}
+	}
+	return nil
+}
+
+// fetchProfileETag 取得压测用户资料当前的 ETag
+func fetchProfileETag(ctx context.Context, httpClient *http.Client, env *environment) error {
+	req, err := env.newRequest(ctx, http.MethodGet, "/api/v1/users/me", env.userToken, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch profile: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	env.profileETag = resp.Header.Get("ETag")
+	if env.profileETag == "" {
+		return fmt.Errorf("fetch profile: response has no ETag (status %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// selectScenarios 按逗号分隔的名称选择场景，为空时选择全部
+func selectScenarios(names string) ([]scenario, error) {
+	if strings.TrimSpace(names) == "" {
+		return scenarios, nil
+	}
+	var selected []scenario
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, sc := range scenarios {
+			if sc.name == name {
+				selected = append(selected, sc)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown scenario %q", name)
+		}
+	}
+	return selected, nil
+}
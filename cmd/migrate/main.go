@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -13,14 +16,26 @@ import (
 	"go-server/internal/logger"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 func main() {
 	var (
-		action   = flag.String("action", "", "Migration action (up, down, create, status)")
+		action   = flag.String("action", "", "Migration action (up, down, redo, create, status)")
 		name     = flag.String("name", "", "Migration name (for create action)")
 		batchID  = flag.String("batch", "", "Batch ID to rollback (for down action)")
 		help     = flag.Bool("help", false, "Show help")
+
+		safe        = flag.Bool("safe", false, "Refuse to run pending migrations that could lock large tables (for up action)")
+		forceUnlock = flag.Bool("force-unlock", false, "Terminate the session holding the migration lock, then run the action if given")
+
+		description = flag.String("description", "", "Migration description (for create action)")
+		tmpl        = flag.String("template", database.TemplateBlank, "Migration template: "+strings.Join(database.MigrationTemplates(), ", "))
+		table       = flag.String("table", "", "Table name (create_table, add_column, add_index templates)")
+		column      = flag.String("column", "", "Column name (add_column template)")
+		columnType  = flag.String("type", "", "Column SQL type (add_column template, default VARCHAR(255))")
+		columns     = flag.String("columns", "", "Comma-separated indexed columns (add_index template)")
+		unique      = flag.Bool("unique", false, "Create a unique index (add_index template)")
 	)
 	flag.Parse()
 
@@ -29,18 +44,51 @@ func main() {
 		return
 	}
 
-	if *action == "" {
+	if *action == "" && !*forceUnlock {
 		fmt.Println("Error: action is required")
 		showHelp()
 		os.Exit(1)
 	}
 
+	// Generating migration files doesn't need a database connection
+	if strings.ToLower(*action) == "create" {
+		params := database.MigrationTemplateParams{
+			Table:      *table,
+			Column:     *column,
+			ColumnType: *columnType,
+			Columns:    splitColumns(*columns),
+			Unique:     *unique,
+		}
+		if isTerminal(os.Stdin) {
+			promptMissing(bufio.NewReader(os.Stdin), os.Stdout, *tmpl, name, &params)
+		}
+		if *name == "" {
+			fmt.Println("Error: name is required for create action")
+			showHelp()
+			os.Exit(1)
+		}
+
+		migrator := database.NewMigrator(nil, logger.NewZapLogger(zap.NewNop()), nil)
+		version, err := migrator.CreateMigrationFromTemplate(*name, *description, *tmpl, params)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s/%s_up.sql and %s/%s_down.sql\n", database.MigrationsDir, version, database.MigrationsDir, version)
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Resolve secret references (Vault / AWS Secrets Manager)
+	if err := config.NewSecretResolver(cfg.Secrets).Resolve(context.Background(), cfg); err != nil {
+		log.Fatalf("Failed to resolve secrets: %v", err)
+	}
+
 	// Initialize logger
 	loggerManager, err := logger.NewManager(cfg.Logging)
 	if err != nil {
@@ -64,10 +112,39 @@ func main() {
 	// Initialize migrator
 	migrator := database.NewMigrator(db.DB, loggerInstance, &cfg.Database)
 
+	if *forceUnlock {
+		holders, err := migrator.ForceUnlockMigrations(ctx)
+		if err != nil {
+			loggerInstance.Fatal(ctx, "Failed to force unlock migrations", logger.Error(err))
+		}
+		if len(holders) == 0 {
+			fmt.Println("Migration lock is not held")
+		}
+		for _, holder := range holders {
+			fmt.Printf("Terminated session holding the migration lock: %s\n", holder)
+		}
+		if *action == "" {
+			return
+		}
+	}
+
 	// Execute action
 	switch strings.ToLower(*action) {
 	case "up":
-		if err := migrator.RunMigrations(); err != nil {
+		if *safe {
+			issues, err := migrator.RunMigrationsSafely()
+			if errors.Is(err, database.ErrUnsafeMigration) {
+				fmt.Printf("Refusing to run migrations, %d unsafe operation(s) found:\n\n", len(issues))
+				for _, issue := range issues {
+					fmt.Printf("%s\n\n", issue)
+				}
+				fmt.Println("Fix them or add a '-- lint:ignore <rule>' comment to the migration.")
+				os.Exit(1)
+			}
+			if err != nil {
+				loggerInstance.Fatal(ctx, "Failed to run migrations", logger.Error(err))
+			}
+		} else if err := migrator.RunMigrations(); err != nil {
 			loggerInstance.Fatal(ctx, "Failed to run migrations", logger.Error(err))
 		}
 		loggerInstance.Info(ctx, "Migrations completed successfully")
@@ -96,16 +173,16 @@ func main() {
 		}
 		loggerInstance.Info(ctx, "Rollback completed successfully", logger.String("batch_id", *batchID))
 
-	case "create":
-		if *name == "" {
-			fmt.Println("Error: name is required for create action")
-			showHelp()
-			os.Exit(1)
+	case "redo":
+		version, err := migrator.Redo()
+		if err != nil {
+			loggerInstance.Fatal(ctx, "Failed to redo migration", logger.Error(err))
 		}
-		if err := migrator.CreateMigration(*name, ""); err != nil {
-			loggerInstance.Fatal(ctx, "Failed to create migration", logger.Error(err))
+		if version == "" {
+			loggerInstance.Info(ctx, "No migrations to redo")
+			return
 		}
-		loggerInstance.Info(ctx, "Migration created successfully", logger.String("name", *name))
+		loggerInstance.Info(ctx, "Redo completed successfully", logger.String("version", version))
 
 	case "status":
 		migrations, err := migrator.GetMigrationStatus()
@@ -113,6 +190,14 @@ func main() {
 			loggerInstance.Fatal(ctx, "Failed to get migration status", logger.Error(err))
 		}
 
+		holders, err := migrator.MigrationLockHolders(ctx)
+		if err != nil {
+			loggerInstance.Fatal(ctx, "Failed to get migration lock holders", logger.Error(err))
+		}
+		for _, holder := range holders {
+			fmt.Printf("Migration lock held by %s\n", holder)
+		}
+
 		if len(migrations) == 0 {
 			fmt.Println("No migrations have been applied")
 			return
@@ -145,18 +230,86 @@ func showHelp() {
 	fmt.Println("Actions:")
 	fmt.Println("  up     - Run all pending migrations")
 	fmt.Println("  down   - Rollback the last batch of migrations")
-	fmt.Println("  create - Create a new migration file")
+	fmt.Println("  redo   - Rollback and reapply the last migration (development)")
+	fmt.Println("  create - Create a pair of up/down migration files from a template")
 	fmt.Println("  status - Show migration status")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  -name <name>    Migration name (required for create action)")
-	fmt.Println("  -batch <id>     Batch ID to rollback (optional for down action)")
-	fmt.Println("  -help           Show this help message")
+	fmt.Println("  -name <name>          Migration name (required for create action)")
+	fmt.Println("  -description <text>   Migration description (create action, defaults to the name)")
+	fmt.Println("  -template <template>  " + strings.Join(database.MigrationTemplates(), ", ") + " (create action, default blank)")
+	fmt.Println("  -table <table>        Table name (create_table, add_column, add_index)")
+	fmt.Println("  -column <column>      Column name (add_column)")
+	fmt.Println("  -type <sql type>      Column type (add_column, default VARCHAR(255))")
+	fmt.Println("  -columns <a,b>        Indexed columns (add_index)")
+	fmt.Println("  -unique               Create a unique index (add_index)")
+	fmt.Println("  -batch <id>           Batch ID to rollback (optional for down action)")
+	fmt.Println("  -safe                 Check pending migrations for table-locking operations first (up action)")
+	fmt.Println("  -force-unlock         Terminate the session holding the migration lock (stuck deployments only)")
+	fmt.Println("  -help                 Show this help message")
+	fmt.Println()
+	fmt.Println("When run from a terminal, create prompts for missing template values.")
+	fmt.Println("up, down and redo wait up to database.migration_lock_timeout seconds for other instances to finish migrating.")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  migrate -action=up")
 	fmt.Println("  migrate -action=create -name=add_user_table")
+	fmt.Println("  migrate -action=create -name=create_orders -template=create_table -table=orders")
+	fmt.Println("  migrate -action=create -name=add_orders_status -template=add_column -table=orders -column=status -type='VARCHAR(20) NOT NULL DEFAULT ''pending'''")
+	fmt.Println("  migrate -action=create -name=index_orders_user -template=add_index -table=orders -columns=user_id,created_at")
+	fmt.Println("  migrate -action=up -safe")
+	fmt.Println("  migrate -action=redo")
+	fmt.Println("  migrate -force-unlock")
 	fmt.Println("  migrate -action=down")
 	fmt.Println("  migrate -action=down -batch=550e8400-e29b-41d4-a716-446655440000")
 	fmt.Println("  migrate -action=status")
-}
\ No newline at end of file
+}
+
+// splitColumns splits a comma-separated column list, ignoring empty entries
+func splitColumns(value string) []string {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// isTerminal reports whether the file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptMissing asks for the values the template needs that weren't given as flags
+func promptMissing(in *bufio.Reader, out io.Writer, template string, name *string, params *database.MigrationTemplateParams) {
+	ask := func(label string) string {
+		fmt.Fprintf(out, "%s: ", label)
+		line, _ := in.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	if *name == "" {
+		*name = ask("Migration name")
+	}
+	if template == database.TemplateBlank {
+		return
+	}
+	if params.Table == "" {
+		params.Table = ask("Table")
+	}
+	switch template {
+	case database.TemplateAddColumn:
+		if params.Column == "" {
+			params.Column = ask("Column")
+		}
+		if params.ColumnType == "" {
+			params.ColumnType = ask("Column type [VARCHAR(255)]")
+		}
+	case database.TemplateAddIndex:
+		if len(params.Columns) == 0 {
+			params.Columns = splitColumns(ask("Columns (comma-separated)"))
+		}
+	}
+}
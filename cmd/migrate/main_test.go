@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-server/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitColumns(t *testing.T) {
+	assert.Equal(t, []string{"user_id", "created_at"}, splitColumns(" user_id, ,created_at,"))
+	assert.Nil(t, splitColumns(""))
+}
+
+func TestPromptMissing(t *testing.T) {
+	t.Run("只询问缺少的参数", func(t *testing.T) {
+		in := bufio.NewReader(strings.NewReader("create_orders_index\nuser_id, status\n"))
+		var out bytes.Buffer
+		name := ""
+		params := database.MigrationTemplateParams{Table: "orders"}
+
+		promptMissing(in, &out, database.TemplateAddIndex, &name, &params)
+
+		assert.Equal(t, "create_orders_index", name)
+		assert.Equal(t, []string{"user_id", "status"}, params.Columns)
+		assert.NotContains(t, out.String(), "Table")
+	})
+
+	t.Run("空白模板只需要名称", func(t *testing.T) {
+		in := bufio.NewReader(strings.NewReader("cleanup\n"))
+		var out bytes.Buffer
+		name := ""
+		params := database.MigrationTemplateParams{}
+
+		promptMissing(in, &out, database.TemplateBlank, &name, &params)
+
+		assert.Equal(t, "cleanup", name)
+		assert.Equal(t, "Migration name: ", out.String())
+	})
+}
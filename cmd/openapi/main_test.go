@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpecUpToDate 检查提交的 docs/openapi.json 与注释生成的结果一致，且每个注册的路由都有注释
+func TestSpecUpToDate(t *testing.T) {
+	spec, problems, err := generate("../..")
+	require.NoError(t, err)
+	assert.Empty(t, problems, "handler annotations drift from the registered routes")
+
+	current, err := os.ReadFile("../../docs/openapi.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(current), string(spec), "docs/openapi.json is out of date, run `make openapi`")
+}
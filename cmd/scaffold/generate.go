@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates 解析后的模板，以文件名（不含 .tmpl）为名称
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"join":    strings.Join,
+	"article": article,
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// migrationRegex 迁移文件名，如 011_create_subscriptions_table_up.sql
+var migrationRegex = regexp.MustCompile(`^(\d+)_.+_(up|down)\.sql$`)
+
+// generate 渲染资源的全部文件，Go 文件经过 gofmt 格式化
+func generate(r *resource) ([]output, error) {
+	files := []struct {
+		template string
+		path     string
+	}{
+		{"model.go", "internal/models/" + r.Snake + ".go"},
+		{"migration_up.sql", "migrations/" + r.Migration + "_up.sql"},
+		{"migration_down.sql", "migrations/" + r.Migration + "_down.sql"},
+		{"repository.go", "internal/repositories/" + r.Snake + "_repository.go"},
+		{"memory_repository.go", "internal/repositories/memory_" + r.Snake + "_repository.go"},
+		{"cached_repository.go", "internal/repositories/cached_" + r.Snake + "_repository.go"},
+		{"service.go", "internal/services/" + r.Snake + "_service.go"},
+		{"service_test.go", "internal/services/" + r.Snake + "_service_test.go"},
+		{"handler.go", "internal/handlers/" + r.Snake + ".go"},
+		{"handler_test.go", "internal/handlers/" + r.Snake + "_test.go"},
+		{"routes.go", "internal/routes/" + r.Snake + ".go"},
+	}
+
+	outputs := make([]output, 0, len(files))
+	for _, file := range files {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, file.template+".tmpl", r); err != nil {
+			return nil, fmt.Errorf("render %s: %w", file.path, err)
+		}
+		content := buf.Bytes()
+		if strings.HasSuffix(file.path, ".go") {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("format %s: %w", file.path, err)
+			}
+			content = formatted
+		}
+		outputs = append(outputs, output{path: file.path, content: content})
+	}
+	return outputs, nil
+}
+
+// nextMigration 返回下一个迁移版本号，目录不存在时从 1 开始
+func nextMigration(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	latest := 0
+	for _, entry := range entries {
+		match := migrationRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if version, err := strconv.Atoi(match[1]); err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest + 1, nil
+}
+
+// nextSteps 生成后需要手动完成的接入步骤
+func nextSteps(r *resource) string {
+	return fmt.Sprintf(`
+Next steps:
+  1. Create the %[1]s repository, service and handler in internal/bootstrap, for example
+       repo := repositories.NewCached%[2]sRepository(repositories.New%[2]sRepository(db), appCache, 0)
+       handler := handlers.New%[2]sHandler(services.New%[2]sService(repo))
+  2. Pass the handler through routes.NewRouter and register the routes in Router.SetupRoutes:
+       Setup%[2]sRoutes(r.engine, r.%[3]sHandler, r.jwtManager, r.banList, r.userRepository)
+  3. Add cases for the new routes to TestAPI in pkg/apitest/apitest_test.go,
+     every documented response must be covered
+  4. Review the generated fields, validation rules and tests, then run
+       make db-migrate
+       make openapi genclient
+       go test ./...
+`, r.Words, r.Name, r.Var)
+}
@@ -0,0 +1,80 @@
+// scaffold 按模板的约定生成一个资源的增删改查代码
+//
+// 生成模型和请求结构、迁移、仓库（GORM 实现、内存实现和缓存装饰器）、服务、带接口文档注释的处理器、
+// 路由以及服务和处理器的测试。读取接口所有登录用户可用，修改接口仅管理员可用；列表接口支持分页和排序。
+// 生成的文件不会覆盖已有文件，接入启动流程和路由的步骤在生成后打印。
+//
+// 字段格式为 name:type，类型以 ? 结尾时字段可为空。支持的类型：
+// string、text、int、int64、float、decimal（字符串，NUMERIC(12,2)）、bool、time、uuid。
+// id、created_at 和 updated_at 自动生成，不能声明。
+//
+// 用法：
+//
+//	go run ./cmd/scaffold -resource=Order -fields="total:decimal,status:string,note:text?"
+//	go run ./cmd/scaffold -resource=Order -fields="..." -label=订单    文档和提示信息使用的名称
+//	go run ./cmd/scaffold -resource=Order -fields="..." -dry-run      只打印将生成的文件
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// output 一个生成的文件
+type output struct {
+	path    string // 相对模块根目录的路径
+	content []byte
+}
+
+func main() {
+	var (
+		name   = flag.String("resource", "", "Resource name in UpperCamelCase, such as Order")
+		fields = flag.String("fields", "", `Comma-separated fields as name:type, such as "total:decimal,status:string,note:text?"`)
+		label  = flag.String("label", "", "Name used in API documentation and messages, defaults to the resource name")
+		root   = flag.String("root", ".", "Module root directory")
+		dryRun = flag.Bool("dry-run", false, "Print the files that would be generated without writing them")
+	)
+	flag.Parse()
+
+	migration, err := nextMigration(filepath.Join(*root, "migrations"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read migrations: %v\n", err)
+		os.Exit(1)
+	}
+	r, err := newResource(*name, *fields, *label, migration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid resource: %v\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	outputs, err := generate(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate %s: %v\n", r.Name, err)
+		os.Exit(1)
+	}
+
+	// 任何一个文件已存在时都不写入，避免生成一半
+	for _, out := range outputs {
+		if _, err := os.Stat(filepath.Join(*root, out.path)); err == nil {
+			fmt.Fprintf(os.Stderr, "%s already exists, remove it or choose another resource name\n", out.path)
+			os.Exit(1)
+		}
+	}
+
+	for _, out := range outputs {
+		if *dryRun {
+			fmt.Printf("Would generate %s\n", out.path)
+			continue
+		}
+		path := filepath.Join(*root, out.path)
+		if err := os.WriteFile(path, out.content, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated %s\n", out.path)
+	}
+	fmt.Print(nextSteps(r))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "line_item", snakeCase("LineItem"))
+	assert.Equal(t, "api_key", snakeCase("APIKey"))
+	assert.Equal(t, "order", snakeCase("Order"))
+
+	assert.Equal(t, "UnitPrice", camelCase("unit_price"))
+	assert.Equal(t, "CustomerID", camelCase("customer_id"))
+
+	assert.Equal(t, "Orders", plural("Order"))
+	assert.Equal(t, "categories", plural("category"))
+	assert.Equal(t, "keys", plural("key"))
+	assert.Equal(t, "addresses", plural("address"))
+	assert.Equal(t, "batches", plural("batch"))
+	assert.Equal(t, "boxes", plural("box"))
+}
+
+func TestNewResource(t *testing.T) {
+	r, err := newResource("lineItem", "unit_price:decimal, sku:string, note:text?", "订单项", 12)
+	require.NoError(t, err)
+	assert.Equal(t, "LineItem", r.Name)
+	assert.Equal(t, "lineItem", r.Var)
+	assert.Equal(t, "lineItems", r.PluralVar)
+	assert.Equal(t, "line_items", r.Table)
+	assert.Equal(t, "/api/v1/line-items", r.Path)
+	assert.Equal(t, "012_create_line_items_table", r.Migration)
+	assert.Equal(t, []string{"created_at", "updated_at", "unit_price", "sku"}, r.SortFields(), "可为空和不可排序的字段不参与排序")
+
+	require.Len(t, r.Fields, 3)
+	note := r.Fields[2]
+	assert.Equal(t, "*string", note.GoType())
+	assert.Equal(t, "TEXT", note.SQLType())
+	assert.Equal(t, "omitempty,max=10000", note.Binding())
+	assert.Equal(t, "NUMERIC(12,2) NOT NULL", r.Fields[0].SQLType())
+
+	invalid := []struct{ name, fields string }{
+		{"", "total:decimal"},
+		{"order_item", "total:decimal"},
+		{"Type", "total:decimal"},
+		{"Order", ""},
+		{"Order", "total"},
+		{"Order", "total:money"},
+		{"Order", "Total:decimal"},
+		{"Order", "id:uuid"},
+		{"Order", "total:decimal,total:int"},
+	}
+	for _, tc := range invalid {
+		_, err := newResource(tc.name, tc.fields, "", 1)
+		assert.Error(t, err, "%s %s", tc.name, tc.fields)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	r, err := newResource("Order", "total:decimal,status:string,note:text?,placed_at:time,quantity:int,weight:float,paid:bool,customer_id:uuid?", "订单", 12)
+	require.NoError(t, err)
+
+	outputs, err := generate(r)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, out := range outputs {
+		files[out.path] = string(out.content)
+		if strings.HasSuffix(out.path, ".go") {
+			_, err := parser.ParseFile(token.NewFileSet(), out.path, out.content, parser.ParseComments)
+			assert.NoError(t, err, out.path)
+		}
+	}
+	assert.Len(t, files, 11)
+
+	assert.Contains(t, files["migrations/012_create_orders_table_up.sql"], "total NUMERIC(12,2) NOT NULL,")
+	assert.Contains(t, files["migrations/012_create_orders_table_up.sql"], "note TEXT,")
+	assert.Contains(t, files["migrations/012_create_orders_table_down.sql"], "DROP TABLE IF EXISTS orders;")
+	assert.Contains(t, files["internal/models/order.go"], `CustomerID *string`)
+	assert.Contains(t, files["internal/repositories/order_repository.go"],
+		`var OrderSortFields = []string{"created_at", "updated_at", "total", "status", "placed_at", "quantity", "weight"}`)
+	assert.Contains(t, files["internal/repositories/memory_order_repository.go"], `strconv.ParseFloat(a.Total, 64)`)
+	assert.Contains(t, files["internal/repositories/cached_order_repository.go"], `const OrderListCacheTag = "orders:list"`)
+	assert.Contains(t, files["internal/handlers/order.go"], "// @Sort created_at,updated_at,total,status,placed_at,quantity,weight")
+	assert.Contains(t, files["internal/handlers/order.go"], "// @Router /api/v1/orders/{id} [put]")
+	assert.Contains(t, files["internal/routes/order.go"], "adminGroup.DELETE(\"/:id\", orderHandler.DeleteOrder)")
+	assert.Contains(t, files["internal/services/order_service_test.go"], `"time"`, "必填的时间字段在测试中需要赋值")
+}
+
+func TestNextMigration(t *testing.T) {
+	dir := t.TempDir()
+	next, err := nextMigration(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, next)
+
+	for _, name := range []string{"001_init_up.sql", "001_init_down.sql", "009_add_index_up.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	next, err = nextMigration(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, next)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// resourceRegex 资源名称：大驼峰的 Go 标识符，如 Order、LineItem
+	resourceRegex = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+	// fieldNameRegex 字段名称：小写下划线形式，同时用作列名和 JSON 字段名
+	fieldNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+)
+
+// reservedFields 每个资源都有的字段，不能在 -fields 中声明
+var reservedFields = []string{"id", "created_at", "updated_at"}
+
+// fieldType 字段类型在各层的表示
+type fieldType struct {
+	goType   string // 模型和请求中的 Go 类型
+	sqlType  string // 迁移中的列类型
+	gormType string // gorm 标签中的 type，为空时由 GORM 推断
+	binding  string // 必填字段的校验规则
+	example  string // 文档示例
+	sortable bool   // 是否可以排序
+	zero     string // 测试中使用的非零值
+}
+
+// fieldTypes 支持的字段类型
+// decimal 使用字符串保存，避免浮点数的精度损失，数据库中为 NUMERIC
+var fieldTypes = map[string]fieldType{
+	"string":  {goType: "string", sqlType: "VARCHAR(255)", gormType: "varchar(255)", binding: "required,max=255", example: "example", sortable: true, zero: `"example"`},
+	"text":    {goType: "string", sqlType: "TEXT", gormType: "text", binding: "required,max=10000", example: "text", zero: `"text"`},
+	"int":     {goType: "int", sqlType: "INTEGER", binding: "min=0", example: "1", sortable: true, zero: "1"},
+	"int64":   {goType: "int64", sqlType: "BIGINT", binding: "min=0", example: "1", sortable: true, zero: "1"},
+	"float":   {goType: "float64", sqlType: "DOUBLE PRECISION", example: "1.5", sortable: true, zero: "1.5"},
+	"decimal": {goType: "string", sqlType: "NUMERIC(12,2)", gormType: "numeric(12,2)", binding: "required,numeric", example: "19.99", sortable: true, zero: `"19.99"`},
+	"bool":    {goType: "bool", sqlType: "BOOLEAN", example: "true", zero: "true"},
+	"time":    {goType: "time.Time", sqlType: "TIMESTAMP", binding: "required", sortable: true, zero: "time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)"},
+	"uuid":    {goType: "string", sqlType: "UUID", gormType: "uuid", binding: "required,uuid", example: "123e4567-e89b-12d3-a456-426614174000", zero: `"123e4567-e89b-12d3-a456-426614174000"`},
+}
+
+// field 资源的一个字段
+type field struct {
+	fieldType
+	Name     string // Go 字段名，如 UnitPrice
+	Column   string // 列名和 JSON 字段名，如 unit_price
+	Kind     string // -fields 中声明的类型
+	Optional bool   // 类型以 ? 结尾时可为空，Go 类型为指针
+}
+
+// GoType 模型和请求中的 Go 类型，可为空的字段为指针
+func (f field) GoType() string {
+	if f.Optional {
+		return "*" + f.goType
+	}
+	return f.goType
+}
+
+// SQLType 迁移中的列定义
+func (f field) SQLType() string {
+	switch {
+	case f.Optional:
+		return f.sqlType
+	case f.Kind == "bool":
+		return f.sqlType + " NOT NULL DEFAULT FALSE"
+	default:
+		return f.sqlType + " NOT NULL"
+	}
+}
+
+// Gorm 模型的 gorm 标签
+func (f field) Gorm() string {
+	var parts []string
+	if f.gormType != "" {
+		parts = append(parts, "type:"+f.gormType)
+	}
+	if !f.Optional && f.Kind != "bool" {
+		parts = append(parts, "not null")
+	}
+	return strings.Join(parts, ";")
+}
+
+// Binding 请求的校验规则，可为空的字段只在提供时校验
+func (f field) Binding() string {
+	if !f.Optional {
+		return f.binding
+	}
+	rule := strings.TrimPrefix(strings.TrimPrefix(f.binding, "required"), ",")
+	if rule == "" {
+		return ""
+	}
+	return "omitempty," + rule
+}
+
+// Example 文档示例
+func (f field) Example() string {
+	return f.example
+}
+
+// TestValue 测试中赋给不可为空字段的值
+func (f field) TestValue() string {
+	return f.zero
+}
+
+// resource 生成代码使用的资源名称和字段
+type resource struct {
+	Name        string  // 大驼峰名称，如 LineItem
+	Var         string  // 小驼峰名称，用作变量名，如 lineItem
+	Plural      string  // 大驼峰复数，如 LineItems
+	PluralVar   string  // 小驼峰复数，如 lineItems
+	Snake       string  // 下划线名称，用于文件名，如 line_item
+	Table       string  // 表名，如 line_items
+	Tag         string  // 接口文档的分组和路径的最后一段，如 line-items
+	Path        string  // 接口路径，如 /api/v1/line-items
+	Label       string  // 文档和提示信息中的名称，默认与 Name 相同
+	Words       string  // 注释和错误信息中的名称，如 line item
+	PluralWords string  // Words 的复数，如 line items
+	Migration   string  // 迁移版本，如 012_create_line_items_table
+	Fields      []field // 声明的字段，不含 id 和时间戳
+}
+
+// SortFields 可排序的列，默认按第一个（created_at）排序
+func (r *resource) SortFields() []string {
+	fields := []string{"created_at", "updated_at"}
+	for _, f := range r.SortableFields() {
+		fields = append(fields, f.Column)
+	}
+	return fields
+}
+
+// SortableFields 可排序的声明字段
+// 可为空的字段不参与排序，NULL 的排序位置在数据库和内存仓库中不一致
+func (r *resource) SortableFields() []field {
+	var fields []field
+	for _, f := range r.Fields {
+		if f.sortable && !f.Optional {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// HasSortableDecimal 是否有可排序的 decimal 字段，内存仓库按数值比较
+func (r *resource) HasSortableDecimal() bool {
+	for _, f := range r.SortableFields() {
+		if f.Kind == "decimal" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTimeField 是否有 time 类型的字段
+func (r *resource) HasTimeField() bool {
+	for _, f := range r.Fields {
+		if f.Kind == "time" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRequiredTimeField 是否有不可为空的 time 字段，测试中需要为其赋值
+func (r *resource) HasRequiredTimeField() bool {
+	for _, f := range r.Fields {
+		if f.Kind == "time" && !f.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// newResource 解析资源名称和字段声明，如 -resource=Order -fields="total:decimal,status:string,note:text?"
+func newResource(name, fields, label string, migration int) (*resource, error) {
+	if name != "" {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	if !resourceRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid resource name %q, use an UpperCamelCase identifier such as Order", name)
+	}
+
+	snake := snakeCase(name)
+	r := &resource{
+		Name:   name,
+		Var:    strings.ToLower(name[:1]) + name[1:],
+		Plural: plural(name),
+		Snake:  snake,
+		Table:  plural(snake),
+		Tag:    strings.ReplaceAll(plural(snake), "_", "-"),
+		Label:  label,
+		Words:  strings.ReplaceAll(snake, "_", " "),
+	}
+	r.PluralVar = strings.ToLower(r.Plural[:1]) + r.Plural[1:]
+	r.PluralWords = strings.ReplaceAll(r.Table, "_", " ")
+	if token.IsKeyword(r.Var) || token.IsKeyword(r.PluralVar) {
+		return nil, fmt.Errorf("resource name %q is a Go keyword when lowercased", name)
+	}
+	if r.Label == "" {
+		r.Label = name
+	}
+	r.Path = "/api/v1/" + r.Tag
+	r.Migration = fmt.Sprintf("%03d_create_%s_table", migration, r.Table)
+
+	seen := make(map[string]bool)
+	for _, spec := range strings.Split(fields, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		f, err := parseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[f.Column] {
+			return nil, fmt.Errorf("field %q is declared more than once", f.Column)
+		}
+		seen[f.Column] = true
+		r.Fields = append(r.Fields, f)
+	}
+	if len(r.Fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required, such as -fields=\"name:string\"")
+	}
+	return r, nil
+}
+
+// parseField 解析 name:type 或 name:type?（可为空）
+func parseField(spec string) (field, error) {
+	column, kind, ok := strings.Cut(spec, ":")
+	if !ok {
+		return field{}, fmt.Errorf("invalid field %q, use name:type", spec)
+	}
+	column, kind = strings.TrimSpace(column), strings.TrimSpace(kind)
+	if !fieldNameRegex.MatchString(column) {
+		return field{}, fmt.Errorf("invalid field name %q, use lowercase letters, digits and underscores", column)
+	}
+	for _, reserved := range reservedFields {
+		if column == reserved {
+			return field{}, fmt.Errorf("field %q is generated for every resource", column)
+		}
+	}
+
+	optional := strings.HasSuffix(kind, "?")
+	kind = strings.TrimSuffix(kind, "?")
+	ft, ok := fieldTypes[kind]
+	if !ok {
+		return field{}, fmt.Errorf("unknown type %q for field %q, available: %s", kind, column, strings.Join(fieldTypeNames(), ", "))
+	}
+	return field{
+		fieldType: ft,
+		Name:      camelCase(column),
+		Column:    column,
+		Kind:      kind,
+		Optional:  optional,
+	}, nil
+}
+
+// article 英文名称前的不定冠词
+func article(words string) string {
+	if strings.ContainsAny(words[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
+
+// fieldTypeNames 支持的字段类型名称
+func fieldTypeNames() []string {
+	return []string{"string", "text", "int", "int64", "float", "decimal", "bool", "time", "uuid"}
+}
+
+// commonInitialisms 按 Go 命名习惯全部大写的缩写
+var commonInitialisms = map[string]string{"id": "ID", "url": "URL", "ip": "IP", "api": "API", "uuid": "UUID", "http": "HTTP", "sku": "SKU"}
+
+// camelCase 将 unit_price 转换为 UnitPrice，user_id 转换为 UserID
+func camelCase(snake string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		if part == "" {
+			continue
+		}
+		if initialism, ok := commonInitialisms[part]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// snakeCase 将 LineItem 转换为 line_item
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// plural 英文名词的复数形式，只处理常见的规则变化
+func plural(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou"):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// HasRequiredBinding 请求中是否有必填字段，缺少时创建接口返回 400
+func (r *resource) HasRequiredBinding() bool {
+	for _, f := range r.Fields {
+		if strings.HasPrefix(f.Binding(), "required") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+// Package docs 提供由 cmd/openapi 根据处理器注释生成的 OpenAPI 3.1 文档
+package docs
+
+import _ "embed"
+
+// OpenAPI 生成的 OpenAPI 3.1 文档（JSON），运行 make openapi 更新
+//
+//go:embed openapi.json
+var OpenAPI []byte
@@ -0,0 +1,269 @@
+// Package announcements 管理员发布的系统公告
+//
+// 公告按角色和租户定向，在生效时间和过期时间之间可见。未过期的公告（包括尚未生效的）作为一个整体缓存在共享缓存中，
+// 读取时按当前时间和查看者过滤，因此定时生效和过期不依赖缓存失效；写操作删除缓存并通知本实例的 SSE 连接，
+// 其他实例的连接在下一次定期检查时读取到变更。
+package announcements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/tenancy"
+	"go-server/pkg/cache"
+)
+
+// DefaultCacheTTL 未过期公告的默认缓存时间
+const DefaultCacheTTL = time.Minute
+
+// cacheKey 未过期公告的缓存键，公告跨租户定向，不添加租户前缀
+const cacheKey = "announcements:unexpired"
+
+var (
+	// ErrNotFound 公告不存在
+	ErrNotFound = errors.New("announcement not found")
+	// ErrInvalidSchedule 过期时间不晚于生效时间
+	ErrInvalidSchedule = errors.New("announcement must end after it starts")
+)
+
+// UserLookup 按ID查找查看者，用户服务实现了该接口
+type UserLookup interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// Viewer 查看公告的用户，未登录时没有角色
+type Viewer struct {
+	Roles    []string
+	TenantID string
+}
+
+// Config 公告服务配置
+type Config struct {
+	// CacheTTL 未过期公告的缓存时间，<=0 时使用 DefaultCacheTTL
+	CacheTTL time.Duration
+	// OnError 缓存读写失败时调用，operation 描述失败的步骤，为 nil 时忽略
+	OnError func(operation string, err error)
+}
+
+// Service 公告服务：管理公告、按查看者筛选生效中的公告并通知订阅者
+type Service struct {
+	repo   repositories.AnnouncementRepository
+	users  UserLookup
+	cache  cache.Cache
+	config Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	watchers map[chan struct{}]struct{}
+	closed   bool
+}
+
+// NewService 创建公告服务，cache 为 nil 时每次从数据库读取
+func NewService(repo repositories.AnnouncementRepository, users UserLookup, c cache.Cache, config Config) *Service {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultCacheTTL
+	}
+	return &Service{
+		repo:     repo,
+		users:    users,
+		cache:    c,
+		config:   config,
+		now:      time.Now,
+		watchers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Create 创建公告
+func (s *Service) Create(ctx context.Context, announcement *models.Announcement) error {
+	if err := normalize(announcement); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, announcement); err != nil {
+		return err
+	}
+	s.changed(ctx)
+	return nil
+}
+
+// Get 返回公告
+func (s *Service) Get(ctx context.Context, id string) (*models.Announcement, error) {
+	announcement, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrAnnouncementNotFound) {
+		return nil, ErrNotFound
+	}
+	return announcement, err
+}
+
+// List 返回一页公告，按创建时间倒序，以及公告总数
+func (s *Service) List(ctx context.Context, page, limit int) ([]*models.Announcement, int64, error) {
+	return s.repo.List(ctx, (page-1)*limit, limit)
+}
+
+// Update 替换公告的定义，返回修改后的公告
+func (s *Service) Update(ctx context.Context, announcement *models.Announcement) (*models.Announcement, error) {
+	if err := normalize(announcement); err != nil {
+		return nil, err
+	}
+	err := s.repo.Update(ctx, announcement)
+	if errors.Is(err, repositories.ErrAnnouncementNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ctx)
+	return s.Get(ctx, announcement.ID)
+}
+
+// Delete 删除公告
+func (s *Service) Delete(ctx context.Context, id string) error {
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, repositories.ErrAnnouncementNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.changed(ctx)
+	return nil
+}
+
+// normalize 校验时间范围并填充默认级别
+func normalize(announcement *models.Announcement) error {
+	if announcement.StartsAt != nil && announcement.EndsAt != nil && !announcement.EndsAt.After(*announcement.StartsAt) {
+		return ErrInvalidSchedule
+	}
+	if announcement.Level == "" {
+		announcement.Level = models.AnnouncementLevelInfo
+	}
+	if announcement.Roles == nil {
+		announcement.Roles = []string{}
+	}
+	return nil
+}
+
+// Viewer 返回用户的角色和租户；userID 为空或用户不存在时按未登录处理，租户取自请求上下文
+func (s *Service) Viewer(ctx context.Context, userID string) (Viewer, error) {
+	viewer := Viewer{TenantID: tenancy.ID(ctx)}
+	if userID == "" {
+		return viewer, nil
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return viewer, nil
+	}
+	if err != nil {
+		return Viewer{}, fmt.Errorf("failed to look up announcement viewer: %w", err)
+	}
+	viewer.Roles = user.GetRoles()
+	if user.TenantID != nil {
+		viewer.TenantID = *user.TenantID
+	}
+	return viewer, nil
+}
+
+// Visible 返回当前对查看者生效的公告，按创建时间倒序
+func (s *Service) Visible(ctx context.Context, viewer Viewer) ([]models.Announcement, error) {
+	unexpired, err := s.unexpired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	visible := []models.Announcement{}
+	for _, announcement := range unexpired {
+		if announcement.ActiveAt(now) && announcement.VisibleTo(viewer.Roles, viewer.TenantID) {
+			visible = append(visible, announcement)
+		}
+	}
+	return visible, nil
+}
+
+// unexpired 返回未过期的公告，优先读取缓存
+func (s *Service) unexpired(ctx context.Context) ([]models.Announcement, error) {
+	if s.cache != nil {
+		if announcements, ok := cache.GetAs[[]models.Announcement](ctx, s.cache, cacheKey); ok {
+			return announcements, nil
+		}
+	}
+
+	loaded, err := s.repo.ListUnexpired(ctx, s.now())
+	if err != nil {
+		return nil, err
+	}
+	announcements := make([]models.Announcement, len(loaded))
+	for i, announcement := range loaded {
+		announcements[i] = *announcement
+	}
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, cacheKey, announcements, s.config.CacheTTL); err != nil {
+			s.report("写入公告缓存", err)
+		}
+	}
+	return announcements, nil
+}
+
+// Watch 订阅公告变更，本实例的写操作完成后向返回的通道发送信号；连续的变更可能合并为一个信号
+// 服务关闭后通道被关闭，调用返回的函数取消订阅
+func (s *Service) Watch() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	if s.closed {
+		close(ch)
+	} else {
+		s.watchers[ch] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.watchers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// Close 关闭全部订阅通道，使 SSE 长连接结束、HTTP 服务器能够排空，客户端会重连到其他实例
+func (s *Service) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for ch := range s.watchers {
+		close(ch)
+		delete(s.watchers, ch)
+	}
+	return nil
+}
+
+// changed 删除缓存并通知订阅者
+func (s *Service) changed(ctx context.Context) {
+	if s.cache != nil {
+		if err := s.cache.Delete(ctx, cacheKey); err != nil {
+			s.report("删除公告缓存", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// report 报告缓存错误
+func (s *Service) report(operation string, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(operation, err)
+	}
+}
@@ -0,0 +1,269 @@
+package announcements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tenantA = "11111111-1111-1111-1111-111111111111"
+
+// newService 创建使用内存仓库的公告服务，users 和 c 为 nil 时不查询用户、不使用缓存
+func newService(users UserLookup, c cache.Cache) *Service {
+	return NewService(repositories.NewMemoryAnnouncementRepository(), users, c, Config{})
+}
+
+// mustCreate 创建公告，未设置标题时使用默认标题和正文
+func mustCreate(t *testing.T, s *Service, announcement *models.Announcement) *models.Announcement {
+	t.Helper()
+	if announcement.Title == "" {
+		announcement.Title = "维护通知"
+		announcement.Body = "今晚维护"
+	}
+	require.NoError(t, s.Create(context.Background(), announcement))
+	return announcement
+}
+
+func titles(announcements []models.Announcement) []string {
+	result := []string{}
+	for _, announcement := range announcements {
+		result = append(result, announcement.Title)
+	}
+	return result
+}
+
+func at(t time.Time) *time.Time {
+	return &t
+}
+
+func TestCreate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		announcement models.Announcement
+		wantErr      error
+		wantLevel    string
+	}{
+		{
+			name:         "默认级别和角色",
+			announcement: models.Announcement{Title: "维护通知"},
+			wantLevel:    models.AnnouncementLevelInfo,
+		},
+		{
+			name:         "保留指定的级别",
+			announcement: models.Announcement{Title: "故障", Level: models.AnnouncementLevelCritical},
+			wantLevel:    models.AnnouncementLevelCritical,
+		},
+		{
+			name:         "过期时间等于生效时间",
+			announcement: models.Announcement{Title: "无效", StartsAt: at(now), EndsAt: at(now)},
+			wantErr:      ErrInvalidSchedule,
+		},
+		{
+			name:         "过期时间早于生效时间",
+			announcement: models.Announcement{Title: "无效", StartsAt: at(now), EndsAt: at(now.Add(-time.Minute))},
+			wantErr:      ErrInvalidSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			announcement := tt.announcement
+			err := newService(nil, nil).Create(context.Background(), &announcement)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, announcement.ID)
+			assert.Equal(t, tt.wantLevel, announcement.Level)
+			assert.Equal(t, []string{}, announcement.Roles)
+		})
+	}
+}
+
+func TestVisible(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := newService(nil, cache.NewMemoryCache())
+	service.now = func() time.Time { return now }
+
+	tenant := tenantA
+	mustCreate(t, service, &models.Announcement{Title: "全员"})
+	mustCreate(t, service, &models.Announcement{Title: "管理员", Roles: []string{"admin"}})
+	mustCreate(t, service, &models.Announcement{Title: "租户", TargetTenantID: &tenant})
+	mustCreate(t, service, &models.Announcement{Title: "未生效", StartsAt: at(now.Add(time.Hour))})
+	mustCreate(t, service, &models.Announcement{Title: "已过期", EndsAt: at(now.Add(-time.Hour))})
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		later  time.Duration // 查询前时钟前进的时长
+		want   []string
+	}{
+		{
+			name: "未登录",
+			want: []string{"全员"},
+		},
+		{
+			name:   "租户管理员",
+			viewer: Viewer{Roles: []string{"user", "admin"}, TenantID: tenantA},
+			want:   []string{"全员", "管理员", "租户"},
+		},
+		{
+			// 缓存的列表包含尚未生效的公告，到达生效时间后无需失效缓存即可看到
+			name:  "到达生效时间",
+			later: 2 * time.Hour,
+			want:  []string{"全员", "未生效"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.now = func() time.Time { return now.Add(tt.later) }
+
+			visible, err := service.Visible(context.Background(), tt.viewer)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, titles(visible))
+		})
+	}
+}
+
+func TestWritesInvalidateCache(t *testing.T) {
+	ctx := context.Background()
+	memoryCache := cache.NewMemoryCache()
+	service := newService(nil, memoryCache)
+
+	announcement := mustCreate(t, service, &models.Announcement{Title: "旧标题"})
+	visible, err := service.Visible(ctx, Viewer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"旧标题"}, titles(visible))
+	exists, err := memoryCache.Exists(ctx, cacheKey)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	updated, err := service.Update(ctx, &models.Announcement{ID: announcement.ID, Title: "新标题", Body: "正文"})
+	require.NoError(t, err)
+	assert.Equal(t, "新标题", updated.Title)
+	visible, err = service.Visible(ctx, Viewer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"新标题"}, titles(visible))
+
+	require.NoError(t, service.Delete(ctx, announcement.ID))
+	visible, err = service.Visible(ctx, Viewer{})
+	require.NoError(t, err)
+	assert.Empty(t, visible)
+}
+
+func TestNotFound(t *testing.T) {
+	ctx := context.Background()
+	service := newService(nil, nil)
+	const id = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Get", func() error {
+			_, err := service.Get(ctx, id)
+			return err
+		}},
+		{"Update", func() error {
+			_, err := service.Update(ctx, &models.Announcement{ID: id, Title: "不存在"})
+			return err
+		}},
+		{"Delete", func() error {
+			return service.Delete(ctx, id)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), ErrNotFound)
+		})
+	}
+}
+
+func TestViewer(t *testing.T) {
+	ctx := context.Background()
+	users := repositories.NewMemoryUserRepository()
+	service := newService(users, nil)
+
+	admin := factory.User().Admin().WithTenant(tenantA).Build()
+	require.NoError(t, users.Create(ctx, admin))
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantRoles  []string
+		wantTenant string
+	}{
+		{
+			name:       "租户管理员",
+			userID:     admin.ID,
+			wantRoles:  admin.GetRoles(),
+			wantTenant: tenantA,
+		},
+		{
+			name: "未登录",
+		},
+		{
+			name:   "用户已删除时按未登录处理",
+			userID: "00000000-0000-0000-0000-000000000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer, err := service.Viewer(ctx, tt.userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRoles, viewer.Roles)
+			assert.Equal(t, tt.wantTenant, viewer.TenantID)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	service := newService(nil, nil)
+
+	changes, cancel := service.Watch()
+	mustCreate(t, service, &models.Announcement{})
+	mustCreate(t, service, &models.Announcement{})
+	select {
+	case <-changes:
+	default:
+		t.Fatal("expected a change signal")
+	}
+	select {
+	case <-changes:
+		t.Fatal("consecutive changes should be coalesced")
+	default:
+	}
+
+	cancel()
+	mustCreate(t, service, &models.Announcement{})
+	select {
+	case <-changes:
+		t.Fatal("cancelled watchers should not be signalled")
+	default:
+	}
+
+	changes, _ = service.Watch()
+	require.NoError(t, service.Close(ctx))
+	_, ok := <-changes
+	assert.False(t, ok, "closing the service closes watchers")
+
+	changes, _ = service.Watch()
+	_, ok = <-changes
+	assert.False(t, ok, "watching a closed service returns a closed channel")
+}
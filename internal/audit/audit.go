@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+)
+
+// 审计动作
+const (
+	ActionProfileUpdated       = "user.profile_updated"
+	ActionPasswordChanged      = "user.password_changed"
+	ActionEmailChangeRequested = "user.email_change_requested"
+	ActionEmailChanged         = "user.email_changed"
+	ActionAccountDeleted       = "user.account_deleted"
+	ActionTokensRevoked        = "user.tokens_revoked"
+	ActionDataExported         = "user.data_exported"
+	ActionAccountErased        = "user.account_erased"
+	ActionOAuthConsentGranted  = "user.oauth_consent_granted"
+	ActionOAuthConsentDenied   = "user.oauth_consent_denied"
+	ActionSSOLogin             = "user.sso_login"
+	ActionDeviceApproved       = "user.device_approved"
+	ActionDeviceDenied         = "user.device_denied"
+	ActionMagicLinkLogin       = "user.magic_link_login"
+)
+
+// 管理员审计动作
+const (
+	ActionUserActivated        = "admin.user_activated"
+	ActionUserDeactivated      = "admin.user_deactivated"
+	ActionPasswordReset        = "admin.password_reset"
+	ActionRolesAssigned        = "admin.roles_assigned"
+	ActionImpersonationStarted = "admin.impersonation_started"
+	ActionImpersonationStopped = "admin.impersonation_stopped"
+	ActionFeatureFlagCreated   = "admin.feature_flag_created"
+	ActionFeatureFlagUpdated   = "admin.feature_flag_updated"
+	ActionFeatureFlagDeleted   = "admin.feature_flag_deleted"
+	ActionBanLifted            = "admin.ban_lifted"
+	ActionAnnouncementCreated  = "admin.announcement_created"
+	ActionAnnouncementUpdated  = "admin.announcement_updated"
+	ActionAnnouncementDeleted  = "admin.announcement_deleted"
+	ActionExportCreated        = "admin.export_created"
+	ActionUsersImported        = "admin.users_imported"
+	ActionUserErased           = "admin.user_erased"
+	ActionOAuthClientCreated   = "admin.oauth_client_created"
+	ActionOAuthClientDeleted   = "admin.oauth_client_deleted"
+	ActionMaintenanceEnabled   = "admin.maintenance_enabled"
+	ActionMaintenanceDisabled  = "admin.maintenance_disabled"
+	ActionDrainStarted         = "admin.drain_started"
+	ActionRateLimitsUpdated    = "admin.rate_limits_updated"
+	ActionBackupCreated        = "admin.backup_created"
+	ActionBackupDownloaded     = "admin.backup_downloaded"
+	ActionBackupRestored       = "admin.backup_restored"
+)
+
+// 系统审计动作，由后台任务记录，管理员手动触发时带有管理员的用户ID
+const (
+	ActionRetentionPurged = "system.retention_purged"
+)
+
+// 审计结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event 审计事件，记录谁在什么时候对哪个对象做了什么
+type Event struct {
+	Action    string                 `json:"action"`              // 动作，如 user.password_changed
+	ActorID   string                 `json:"actor_id,omitempty"`  // 执行操作的用户ID
+	TargetID  string                 `json:"target_id,omitempty"` // 被操作的用户ID
+	Outcome   string                 `json:"outcome"`             // 结果：success 或 failure
+	Reason    string                 `json:"reason,omitempty"`    // 失败原因
+	IP        string                 `json:"ip,omitempty"`        // 客户端IP
+	UserAgent string                 `json:"user_agent,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"` // 附加信息，不得包含密码或令牌
+	Time      time.Time              `json:"time"`
+}
+
+// Recorder 审计事件记录器
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+// LogRecorder 将审计事件写入 audit 模块的结构化日志
+type LogRecorder struct {
+	logger logger.Logger
+}
+
+// NewLogRecorder 创建写入日志的审计记录器
+func NewLogRecorder(log logger.Logger) *LogRecorder {
+	return &LogRecorder{logger: log.WithModule("audit")}
+}
+
+// Record 记录审计事件，失败的操作以 Warn 级别记录
+func (r *LogRecorder) Record(ctx context.Context, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+
+	fields := []logger.Field{
+		logger.String("audit_action", event.Action),
+		logger.String("actor_id", event.ActorID),
+		logger.String("target_id", event.TargetID),
+		logger.String("outcome", event.Outcome),
+		logger.String("client_ip", event.IP),
+		logger.String("user_agent", event.UserAgent),
+		logger.Any("audit_time", event.Time),
+	}
+	if event.Reason != "" {
+		fields = append(fields, logger.String("reason", event.Reason))
+	}
+	if len(event.Details) > 0 {
+		fields = append(fields, logger.Any("details", event.Details))
+	}
+
+	if event.Outcome == OutcomeFailure {
+		r.logger.Warn(ctx, "审计事件", fields...)
+		return
+	}
+	r.logger.Info(ctx, "审计事件", fields...)
+}
+
+// NopRecorder 丢弃所有审计事件，用于测试或未配置日志时
+type NopRecorder struct{}
+
+// Record 不做任何处理
+func (NopRecorder) Record(context.Context, Event) {}
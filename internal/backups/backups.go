@@ -0,0 +1,490 @@
+// Package backups 数据库的逻辑备份和恢复
+//
+// 备份由管理员通过接口创建或按间隔自动创建，由接收请求（或检查到期）的实例在后台执行 pg_dump，
+// 以流式方式计算校验和并上传到对象存储，存储层负责加密；备份任务记录在 backups 表中，任何实例都可以查询和下载。
+// backups 表不包含在备份中，恢复备份不会改变备份列表。到达保留期限的备份由定期清理删除文件和记录。
+//
+// 每个实例同一时间只执行一个备份或恢复。恢复以单个事务执行 pg_restore，失败时数据库保持恢复前的状态；
+// 恢复期间其他实例仍在处理请求，应先开启维护模式。
+package backups
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/storage"
+)
+
+// 默认配置
+const (
+	DefaultTimeout       = time.Hour
+	DefaultRetention     = 7 * 24 * time.Hour
+	DefaultCheckInterval = 10 * time.Minute
+)
+
+// KeyPrefix 备份文件在对象存储中的键前缀，本地驱动不公开提供该前缀下的文件
+const KeyPrefix = "backups"
+
+// ContentType 备份文件（pg_dump 自定义格式）的内容类型
+const ContentType = "application/octet-stream"
+
+// finishTimeout 任务结束或被中断时记录结果的超时时间
+const finishTimeout = 10 * time.Second
+
+// purgeBatch 每次清理读取的过期备份数
+const purgeBatch = 100
+
+var (
+	// ErrNotFound 备份不存在，或尚未完成无法下载
+	ErrNotFound = errors.New("backup not found")
+	// ErrNotCompleted 备份未完成，不能恢复
+	ErrNotCompleted = errors.New("backup is not completed")
+	// ErrBusy 本实例正在执行备份或恢复
+	ErrBusy = errors.New("a backup or restore is already in progress")
+	// ErrClosed 服务已停止，不再接受新任务
+	ErrClosed = errors.New("backup service is stopped")
+)
+
+// Dumper 导出和导入数据库
+type Dumper interface {
+	// Dump 将数据库写入 w
+	Dump(ctx context.Context, w io.Writer) error
+	// Restore 用 r 中的备份替换数据库的内容
+	Restore(ctx context.Context, r io.Reader) error
+}
+
+// Config 备份服务配置
+type Config struct {
+	// Timeout 单个备份或恢复的最长执行时间，<=0 时使用 DefaultTimeout
+	Timeout time.Duration
+	// Retention 备份的保留时间，<=0 时使用 DefaultRetention
+	Retention time.Duration
+	// Interval 自动备份的间隔，<=0 时不自动备份
+	Interval time.Duration
+	// CheckInterval 检查自动备份是否到期和清理过期备份的间隔，<=0 时使用 DefaultCheckInterval
+	CheckInterval time.Duration
+	// AfterRestore 恢复成功后调用，用于清空缓存中恢复前的数据，为 nil 时忽略
+	AfterRestore func(ctx context.Context) error
+	// OnBackup 备份结束（完成或失败）后调用，为 nil 时忽略
+	OnBackup func(backup *models.Backup)
+	// OnRestore 恢复结束（完成或失败）后调用，为 nil 时忽略
+	OnRestore func(status models.RestoreStatus)
+	// OnError 记录结果、清理等后台步骤失败时调用，operation 描述失败的步骤，为 nil 时忽略
+	OnError func(operation string, err error)
+}
+
+// Service 备份服务：创建备份、恢复备份和下载备份文件
+type Service struct {
+	repo   repositories.BackupRepository
+	store  storage.Storage
+	dumper Dumper
+	config Config
+	now    func() time.Time
+
+	ctx    context.Context // 停止时取消，中断正在执行的任务
+	cancel context.CancelFunc
+	jobs   sync.WaitGroup
+
+	mu      sync.Mutex
+	busy    bool
+	closed  bool
+	restore models.RestoreStatus
+
+	startOnce sync.Once
+	loopDone  chan struct{}
+}
+
+// NewService 创建备份服务，store 应为加密存储
+func NewService(repo repositories.BackupRepository, store storage.Storage, dumper Dumper, config Config) *Service {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultCheckInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		repo:     repo,
+		store:    store,
+		dumper:   dumper,
+		config:   config,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		restore:  models.RestoreStatus{State: models.RestoreStateIdle},
+		loopDone: make(chan struct{}),
+	}
+}
+
+// Create 创建备份任务并在后台执行，返回任务的初始状态
+// 任务继承 ctx 中的追踪信息，但不随 ctx 取消而中止
+func (s *Service) Create(ctx context.Context, createdBy string) (*models.Backup, error) {
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	backup := &models.Backup{
+		Trigger: models.BackupTriggerManual,
+		Status:  models.BackupStatusPending,
+	}
+	if createdBy != "" {
+		backup.CreatedBy = &createdBy
+	}
+	if err := s.repo.Create(ctx, backup); err != nil {
+		s.release()
+		return nil, err
+	}
+	s.startBackup(ctx, backup)
+	return backup, nil
+}
+
+// startBackup 在后台执行已占用名额的备份任务
+func (s *Service) startBackup(ctx context.Context, backup *models.Backup) {
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.Timeout)
+	stop := context.AfterFunc(s.ctx, cancel)
+	job := *backup
+	go func() {
+		defer s.release()
+		defer cancel()
+		defer stop()
+		s.runBackup(jobCtx, &job)
+	}()
+}
+
+// acquire 占用本实例的执行名额
+func (s *Service) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.busy {
+		return ErrBusy
+	}
+	s.busy = true
+	s.jobs.Add(1)
+	return nil
+}
+
+// release 归还执行名额
+func (s *Service) release() {
+	s.mu.Lock()
+	s.busy = false
+	s.mu.Unlock()
+	s.jobs.Done()
+}
+
+// Get 返回备份
+func (s *Service) Get(ctx context.Context, id string) (*models.Backup, error) {
+	backup, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrBackupNotFound) {
+		return nil, ErrNotFound
+	}
+	return backup, err
+}
+
+// List 按创建时间倒序分页返回备份和总数，page 从 1 开始
+func (s *Service) List(ctx context.Context, page, limit int) ([]*models.Backup, int64, error) {
+	return s.repo.List(ctx, (page-1)*limit, limit)
+}
+
+// runBackup 生成备份文件并记录结果
+func (s *Service) runBackup(ctx context.Context, backup *models.Backup) {
+	if err := s.repo.MarkRunning(ctx, backup.ID); err != nil {
+		s.report("更新备份状态", err)
+	}
+
+	object, checksum, size, err := s.generate(ctx, backup)
+
+	now := s.now()
+	expiresAt := now.Add(s.config.Retention)
+	backup.CompletedAt, backup.ExpiresAt = &now, &expiresAt
+	switch {
+	case err == nil:
+		backup.Status = models.BackupStatusCompleted
+		backup.ObjectKey, backup.Checksum, backup.Size = object.Key, checksum, size
+	case s.ctx.Err() != nil:
+		backup.Status = models.BackupStatusFailed
+		backup.Error = "backup interrupted by server shutdown"
+	default:
+		backup.Status = models.BackupStatusFailed
+		backup.Error = err.Error()
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	if err := s.repo.Finish(finishCtx, backup); err != nil {
+		s.report("记录备份结果", err)
+	}
+	if s.config.OnBackup != nil {
+		s.config.OnBackup(backup)
+	}
+}
+
+// generate 将 pg_dump 的输出写入管道，同时把管道的另一端上传到对象存储，返回对象、校验和和备份大小
+func (s *Service) generate(ctx context.Context, backup *models.Backup) (*storage.Object, string, int64, error) {
+	reader, writer := io.Pipe()
+	hash := sha256.New()
+	counter := &countingWriter{}
+	dumped := make(chan error, 1)
+	go func() {
+		err := s.dumper.Dump(ctx, io.MultiWriter(writer, hash, counter))
+		writer.CloseWithError(err)
+		dumped <- err
+	}()
+
+	key := storage.GenerateKey(KeyPrefix, backup.ID, "dump")
+	object, err := s.store.Put(ctx, key, reader, -1, ContentType)
+	// 上传失败时让 pg_dump 的下一次写入返回错误，避免导出协程阻塞
+	reader.CloseWithError(err)
+	if dumpErr := <-dumped; dumpErr != nil {
+		err = dumpErr
+	}
+	if err != nil {
+		if object != nil {
+			_ = s.store.Delete(context.WithoutCancel(ctx), key)
+		}
+		return nil, "", 0, err
+	}
+	return object, hex.EncodeToString(hash.Sum(nil)), counter.n, nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+// Write 实现 io.Writer 接口
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Restore 在后台用已完成的备份替换数据库的内容，返回恢复的初始状态
+// 恢复不随 ctx 取消而中止
+func (s *Service) Restore(ctx context.Context, id, requestedBy string) (models.RestoreStatus, error) {
+	backup, err := s.Get(ctx, id)
+	if err != nil {
+		return models.RestoreStatus{}, err
+	}
+	if backup.Status != models.BackupStatusCompleted {
+		return models.RestoreStatus{}, ErrNotCompleted
+	}
+	if err := s.acquire(); err != nil {
+		return models.RestoreStatus{}, err
+	}
+
+	startedAt := s.now()
+	status := models.RestoreStatus{
+		State:       models.RestoreStateRunning,
+		BackupID:    backup.ID,
+		RequestedBy: requestedBy,
+		StartedAt:   &startedAt,
+	}
+	s.mu.Lock()
+	s.restore = status
+	s.mu.Unlock()
+
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.Timeout)
+	stop := context.AfterFunc(s.ctx, cancel)
+	go func() {
+		defer s.release()
+		defer cancel()
+		defer stop()
+		s.runRestore(jobCtx, backup, status)
+	}()
+	return status, nil
+}
+
+// runRestore 读取备份文件并导入数据库，记录恢复结果
+func (s *Service) runRestore(ctx context.Context, backup *models.Backup, status models.RestoreStatus) {
+	err := s.load(ctx, backup)
+	if err == nil && s.config.AfterRestore != nil {
+		if hookErr := s.config.AfterRestore(ctx); hookErr != nil {
+			s.report("恢复后清理缓存", hookErr)
+		}
+	}
+
+	finishedAt := s.now()
+	status.FinishedAt = &finishedAt
+	switch {
+	case err == nil:
+		status.State = models.RestoreStateCompleted
+	case s.ctx.Err() != nil:
+		status.State = models.RestoreStateFailed
+		status.Error = "restore interrupted by server shutdown"
+	default:
+		status.State = models.RestoreStateFailed
+		status.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.restore = status
+	s.mu.Unlock()
+	if s.config.OnRestore != nil {
+		s.config.OnRestore(status)
+	}
+}
+
+// load 解密备份文件并交给 pg_restore
+func (s *Service) load(ctx context.Context, backup *models.Backup) error {
+	reader, _, err := s.store.Get(ctx, backup.ObjectKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer reader.Close()
+	return s.dumper.Restore(ctx, reader)
+}
+
+// RestoreStatus 返回本实例最近一次恢复的状态
+func (s *Service) RestoreStatus() models.RestoreStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restore
+}
+
+// Open 打开已完成备份的文件（已解密），调用方负责关闭返回的 ReadCloser
+// 存储层在读到文件末尾时才能确认内容完整，读取中途出错说明文件已损坏
+func (s *Service) Open(ctx context.Context, id string) (io.ReadCloser, *models.Backup, error) {
+	backup, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if backup.Status != models.BackupStatusCompleted {
+		return nil, nil, ErrNotFound
+	}
+
+	reader, _, err := s.store.Get(ctx, backup.ObjectKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+	return reader, backup, nil
+}
+
+// FileName 返回下载时建议的文件名，如 backup-20260102-030405.dump
+func FileName(backup *models.Backup) string {
+	return "backup-" + backup.CreatedAt.UTC().Format("20060102-150405") + ".dump"
+}
+
+// Start 启动检查自动备份和清理过期备份的后台任务
+func (s *Service) Start() {
+	s.startOnce.Do(func() {
+		go s.loop()
+	})
+}
+
+// Stop 停止后台任务，中断正在执行的备份或恢复并等待其记录结果
+func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cancel()
+	s.startOnce.Do(func() {
+		close(s.loopDone)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		<-s.loopDone
+		s.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) loop() {
+	defer close(s.loopDone)
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.Purge(s.ctx); err != nil && s.ctx.Err() == nil {
+			s.report("清理过期备份", err)
+		}
+		if _, err := s.RunScheduled(s.ctx); err != nil && s.ctx.Err() == nil {
+			s.report("创建自动备份", err)
+		}
+	}
+}
+
+// RunScheduled 距上一次自动备份（任何实例创建的）已超过间隔时在后台创建自动备份，返回是否创建
+// 未配置间隔或本实例正在执行备份或恢复时不创建
+func (s *Service) RunScheduled(ctx context.Context) (bool, error) {
+	if s.config.Interval <= 0 {
+		return false, nil
+	}
+	if err := s.acquire(); err != nil {
+		if errors.Is(err, ErrBusy) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	backup := &models.Backup{}
+	created, err := s.repo.CreateScheduled(ctx, backup, s.now().Add(-s.config.Interval))
+	if err != nil || !created {
+		s.release()
+		return false, err
+	}
+	s.startBackup(ctx, backup)
+	return true, nil
+}
+
+// Purge 删除已过保留期限的备份文件和记录，返回删除的备份数
+func (s *Service) Purge(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		expired, err := s.repo.ListExpired(ctx, s.now(), purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+		for _, backup := range expired {
+			if backup.ObjectKey != "" {
+				if err := s.store.Delete(ctx, backup.ObjectKey); err != nil {
+					return purged, fmt.Errorf("failed to delete backup file: %w", err)
+				}
+			}
+			// 其他实例可能同时清理了同一个备份
+			if err := s.repo.Delete(ctx, backup.ID); err != nil && !errors.Is(err, repositories.ErrBackupNotFound) {
+				return purged, err
+			}
+			purged++
+		}
+		if len(expired) < purgeBatch {
+			return purged, nil
+		}
+	}
+}
+
+// report 报告后台步骤的错误
+func (s *Service) report(operation string, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(operation, err)
+	}
+}
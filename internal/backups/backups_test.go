@@ -0,0 +1,356 @@
+package backups
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDumper 导出固定内容，记录导入的内容；block 不为 nil 时导出和导入等待其关闭
+type fakeDumper struct {
+	content []byte
+	err     error
+	block   chan struct{}
+
+	mu       sync.Mutex
+	restored []byte
+}
+
+func (d *fakeDumper) Dump(ctx context.Context, w io.Writer) error {
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	if d.err != nil {
+		return d.err
+	}
+	_, err := w.Write(d.content)
+	return err
+}
+
+func (d *fakeDumper) Restore(ctx context.Context, r io.Reader) error {
+	if err := d.wait(ctx); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if d.err != nil {
+		return d.err
+	}
+	d.mu.Lock()
+	d.restored = data
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *fakeDumper) wait(ctx context.Context) error {
+	if d.block == nil {
+		return nil
+	}
+	select {
+	case <-d.block:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *fakeDumper) Restored() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.restored
+}
+
+// newLocal 创建保存在临时目录中的本地存储
+func newLocal(t *testing.T) *storage.LocalStorage {
+	t.Helper()
+	local, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	return local
+}
+
+// newService 创建使用内存仓库的备份服务，测试结束时停止
+func newService(t *testing.T, store storage.Storage, dumper Dumper, config Config) *Service {
+	t.Helper()
+	service := NewService(repositories.NewMemoryBackupRepository(), store, dumper, config)
+	t.Cleanup(func() { service.Stop(context.Background()) })
+	return service
+}
+
+// waitBackup 等待备份结束并返回最终状态
+func waitBackup(t *testing.T, s *Service, id string) *models.Backup {
+	t.Helper()
+	var backup *models.Backup
+	require.Eventually(t, func() bool {
+		var err error
+		backup, err = s.Get(context.Background(), id)
+		require.NoError(t, err)
+		return backup.Finished()
+	}, 2*time.Second, time.Millisecond)
+	return backup
+}
+
+// waitRestore 等待恢复结束并返回最终状态
+func waitRestore(t *testing.T, s *Service) models.RestoreStatus {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return s.RestoreStatus().State != models.RestoreStateRunning
+	}, 2*time.Second, time.Millisecond)
+	return s.RestoreStatus()
+}
+
+func TestService_BackupAndRestore(t *testing.T) {
+	content := []byte("PGDMP custom archive")
+	restored := false
+	dumper := &fakeDumper{content: content}
+	local := newLocal(t)
+	store, err := storage.NewEncryptedStorage(local, map[uint32][]byte{1: bytes.Repeat([]byte("k"), storage.EncryptedKeySize)}, 0)
+	require.NoError(t, err)
+	service := newService(t, store, dumper, Config{
+		Retention:    48 * time.Hour,
+		AfterRestore: func(ctx context.Context) error { restored = true; return nil },
+	})
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.BackupTriggerManual, backup.Trigger)
+	require.NotNil(t, backup.CreatedBy)
+	assert.Equal(t, "admin-1", *backup.CreatedBy)
+
+	backup = waitBackup(t, service, backup.ID)
+	require.Equal(t, models.BackupStatusCompleted, backup.Status, backup.Error)
+	sum := sha256.Sum256(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), backup.Checksum)
+	assert.Equal(t, int64(len(content)), backup.Size)
+	require.NotNil(t, backup.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), *backup.ExpiresAt, time.Minute)
+	assert.True(t, strings.HasPrefix(backup.ObjectKey, KeyPrefix+"/"+backup.ID+"/"))
+
+	raw, err := os.ReadFile(filepath.Join(local.BaseDir(), filepath.FromSlash(backup.ObjectKey)))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), string(content), "备份文件加密保存")
+
+	reader, opened, err := service.Open(ctx, backup.ID)
+	require.NoError(t, err)
+	downloaded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, content, downloaded)
+	assert.Equal(t, "backup-"+opened.CreatedAt.UTC().Format("20060102-150405")+".dump", FileName(opened))
+
+	status, err := service.Restore(ctx, backup.ID, "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, models.RestoreStateRunning, status.State)
+	status = waitRestore(t, service)
+	assert.Equal(t, models.RestoreStateCompleted, status.State, status.Error)
+	assert.Equal(t, backup.ID, status.BackupID)
+	assert.Equal(t, "admin-2", status.RequestedBy)
+	assert.Equal(t, content, dumper.Restored())
+	assert.True(t, restored, "恢复后调用 AfterRestore")
+
+	backups, total, err := service.List(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, backups, 1)
+	assert.Equal(t, backup.ID, backups[0].ID)
+}
+
+func TestService_FailedBackup(t *testing.T) {
+	reported := make(chan *models.Backup, 1)
+	local := newLocal(t)
+	service := newService(t, local, &fakeDumper{err: errors.New("pg_dump failed: connection refused")}, Config{
+		OnBackup: func(backup *models.Backup) { reported <- backup },
+	})
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx, "")
+	require.NoError(t, err)
+	assert.Nil(t, backup.CreatedBy)
+	backup = waitBackup(t, service, backup.ID)
+	assert.Equal(t, models.BackupStatusFailed, backup.Status)
+	assert.Contains(t, backup.Error, "connection refused")
+	assert.Empty(t, backup.ObjectKey)
+	require.NotNil(t, backup.ExpiresAt, "失败的记录也按保留期限清理")
+	select {
+	case finished := <-reported:
+		assert.Equal(t, backup.ID, finished.ID)
+	case <-time.After(time.Second):
+		t.Fatal("备份结束后没有调用 OnBackup")
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"下载失败的备份", func() error {
+			_, _, err := service.Open(ctx, backup.ID)
+			return err
+		}, ErrNotFound},
+		{"从失败的备份恢复", func() error {
+			_, err := service.Restore(ctx, backup.ID, "admin")
+			return err
+		}, ErrNotCompleted},
+		{"从不存在的备份恢复", func() error {
+			_, err := service.Restore(ctx, "missing", "admin")
+			return err
+		}, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), tt.wantErr)
+		})
+	}
+
+	err = filepath.WalkDir(local.BaseDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			t.Errorf("失败时删除已上传的部分文件: %s", path)
+		}
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestService_OneJobAtATime(t *testing.T) {
+	dumper := &fakeDumper{content: []byte("archive"), block: make(chan struct{})}
+	service := newService(t, newLocal(t), dumper, Config{})
+	ctx := context.Background()
+
+	first, err := service.Create(ctx, "admin")
+	require.NoError(t, err)
+	_, err = service.Create(ctx, "admin")
+	assert.ErrorIs(t, err, ErrBusy)
+	created, err := service.RunScheduled(ctx)
+	require.NoError(t, err)
+	assert.False(t, created, "未配置间隔时不自动备份")
+
+	close(dumper.block)
+	require.Equal(t, models.BackupStatusCompleted, waitBackup(t, service, first.ID).Status)
+
+	dumper.block = make(chan struct{})
+	_, err = service.Restore(ctx, first.ID, "admin")
+	require.NoError(t, err)
+	_, err = service.Create(ctx, "admin")
+	assert.ErrorIs(t, err, ErrBusy, "恢复期间不能备份")
+	_, err = service.Restore(ctx, first.ID, "admin")
+	assert.ErrorIs(t, err, ErrBusy)
+	close(dumper.block)
+	assert.Equal(t, models.RestoreStateCompleted, waitRestore(t, service).State)
+}
+
+func TestService_FailedRestore(t *testing.T) {
+	dumper := &fakeDumper{content: []byte("archive")}
+	called := false
+	service := newService(t, newLocal(t), dumper, Config{
+		AfterRestore: func(ctx context.Context) error { called = true; return nil },
+	})
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx, "admin")
+	require.NoError(t, err)
+	require.Equal(t, models.BackupStatusCompleted, waitBackup(t, service, backup.ID).Status)
+
+	dumper.err = errors.New("pg_restore failed: relation already exists")
+	_, err = service.Restore(ctx, backup.ID, "admin")
+	require.NoError(t, err)
+	status := waitRestore(t, service)
+	assert.Equal(t, models.RestoreStateFailed, status.State)
+	assert.Contains(t, status.Error, "relation already exists")
+	assert.NotNil(t, status.FinishedAt)
+	assert.False(t, called, "恢复失败时不清理缓存")
+}
+
+func TestService_ScheduledBackups(t *testing.T) {
+	repo := repositories.NewMemoryBackupRepository()
+	store := newLocal(t)
+	dumper := &fakeDumper{content: []byte("archive")}
+	service := NewService(repo, store, dumper, Config{Interval: time.Hour})
+	t.Cleanup(func() { service.Stop(context.Background()) })
+	ctx := context.Background()
+
+	created, err := service.RunScheduled(ctx)
+	require.NoError(t, err)
+	require.True(t, created)
+	backups, _, err := service.List(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, models.BackupTriggerScheduled, backups[0].Trigger)
+	waitBackup(t, service, backups[0].ID)
+
+	created, err = service.RunScheduled(ctx)
+	require.NoError(t, err)
+	assert.False(t, created, "间隔内已有自动备份")
+
+	// 其他实例看到同一条记录，同样不创建
+	other := NewService(repo, store, dumper, Config{Interval: time.Hour})
+	t.Cleanup(func() { other.Stop(context.Background()) })
+	created, err = other.RunScheduled(ctx)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	created, err = service.RunScheduled(ctx)
+	require.NoError(t, err)
+	assert.True(t, created, "超过间隔后再次备份")
+}
+
+func TestService_Purge(t *testing.T) {
+	local := newLocal(t)
+	service := newService(t, local, &fakeDumper{content: []byte("archive")}, Config{Retention: time.Hour})
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx, "admin")
+	require.NoError(t, err)
+	backup = waitBackup(t, service, backup.ID)
+	path := filepath.Join(local.BaseDir(), filepath.FromSlash(backup.ObjectKey))
+	require.FileExists(t, path)
+
+	purged, err := service.Purge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	purged, err = service.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoFileExists(t, path)
+	_, err = service.Get(ctx, backup.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_StopInterruptsJobs(t *testing.T) {
+	dumper := &fakeDumper{content: []byte("archive"), block: make(chan struct{})}
+	service := newService(t, newLocal(t), dumper, Config{})
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx, "admin")
+	require.NoError(t, err)
+	require.NoError(t, service.Stop(ctx))
+
+	backup, err = service.Get(ctx, backup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackupStatusFailed, backup.Status)
+	assert.Equal(t, "backup interrupted by server shutdown", backup.Error)
+
+	_, err = service.Create(ctx, "admin")
+	assert.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,131 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// 默认的客户端程序，从 PATH 中查找
+const (
+	DefaultPgDump    = "pg_dump"
+	DefaultPgRestore = "pg_restore"
+)
+
+// stderrLimit 错误信息中保留的 stderr 字节数
+const stderrLimit = 2048
+
+// PostgresConfig pg_dump 和 pg_restore 的连接配置
+type PostgresConfig struct {
+	PgDump    string // pg_dump 的路径，为空时使用 DefaultPgDump
+	PgRestore string // pg_restore 的路径，为空时使用 DefaultPgRestore
+	Host      string
+	Port      int
+	User      string
+	Password  string
+	DBName    string
+	SSLMode   string
+	// ExcludeTables 不导出的表，恢复时也不会删除这些表
+	ExcludeTables []string
+}
+
+// PostgresDumper 调用 pg_dump 和 pg_restore 备份和恢复 PostgreSQL 数据库
+// 连接参数通过 PG* 环境变量传给子进程，密码不出现在进程的命令行中
+type PostgresDumper struct {
+	config PostgresConfig
+}
+
+// NewPostgresDumper 创建 PostgreSQL 备份实现
+func NewPostgresDumper(config PostgresConfig) *PostgresDumper {
+	if config.PgDump == "" {
+		config.PgDump = DefaultPgDump
+	}
+	if config.PgRestore == "" {
+		config.PgRestore = DefaultPgRestore
+	}
+	return &PostgresDumper{config: config}
+}
+
+// Check 检查 pg_dump 和 pg_restore 是否可以执行
+func (d *PostgresDumper) Check() error {
+	for _, program := range []string{d.config.PgDump, d.config.PgRestore} {
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("backup client not found: %w", err)
+		}
+	}
+	return nil
+}
+
+// Dump 以自定义格式导出数据库，不包含所有者和权限，便于恢复到其他角色拥有的数据库
+func (d *PostgresDumper) Dump(ctx context.Context, w io.Writer) error {
+	args := []string{"--format=custom", "--no-owner", "--no-privileges"}
+	for _, table := range d.config.ExcludeTables {
+		args = append(args, "--exclude-table="+table)
+	}
+	cmd := exec.CommandContext(ctx, d.config.PgDump, args...)
+	cmd.Env = d.env()
+	cmd.Stdout = w
+	return run(cmd, "pg_dump")
+}
+
+// Restore 在单个事务中删除备份中的对象并重新创建，任何错误都会回滚整个恢复
+func (d *PostgresDumper) Restore(ctx context.Context, r io.Reader) error {
+	cmd := exec.CommandContext(ctx, d.config.PgRestore,
+		"--clean", "--if-exists", "--no-owner", "--no-privileges",
+		"--single-transaction", "--exit-on-error",
+		"--dbname="+d.config.DBName)
+	cmd.Env = d.env()
+	cmd.Stdin = r
+	return run(cmd, "pg_restore")
+}
+
+// env 返回带有连接参数的子进程环境变量
+func (d *PostgresDumper) env() []string {
+	env := os.Environ()
+	for name, value := range map[string]string{
+		"PGHOST":     d.config.Host,
+		"PGUSER":     d.config.User,
+		"PGPASSWORD": d.config.Password,
+		"PGDATABASE": d.config.DBName,
+		"PGSSLMODE":  d.config.SSLMode,
+	} {
+		if value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	if d.config.Port > 0 {
+		env = append(env, "PGPORT="+strconv.Itoa(d.config.Port))
+	}
+	return env
+}
+
+// run 执行命令，失败时错误信息包含 stderr 的开头部分
+func run(cmd *exec.Cmd, name string) error {
+	stderr := &limitedBuffer{limit: stderrLimit}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, message)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// limitedBuffer 只保留前 limit 个字节的缓冲区，超出部分丢弃
+type limitedBuffer struct {
+	strings.Builder
+	limit int
+}
+
+// Write 实现 io.Writer 接口，超出限制时仍报告全部写入，避免子进程因管道错误退出
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		b.Builder.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,69 @@
+package backups
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// script 在临时目录中创建可执行的 shell 脚本，代替 pg_dump 和 pg_restore
+func script(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755))
+	return path
+}
+
+func TestPostgresDumper_Dump(t *testing.T) {
+	dumper := NewPostgresDumper(PostgresConfig{
+		PgDump:        script(t, "pg_dump", `echo "$@"; echo "$PGHOST:$PGPORT/$PGDATABASE $PGUSER $PGPASSWORD $PGSSLMODE"`),
+		Host:          "db.internal",
+		Port:          5433,
+		User:          "app",
+		Password:      "secret",
+		DBName:        "app_db",
+		SSLMode:       "require",
+		ExcludeTables: []string{"backups"},
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, dumper.Dump(context.Background(), &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "--format=custom --no-owner --no-privileges --exclude-table=backups", lines[0])
+	assert.Equal(t, "db.internal:5433/app_db app secret require", lines[1], "连接参数通过环境变量传递")
+	assert.NotContains(t, lines[0], "secret", "密码不出现在命令行中")
+}
+
+func TestPostgresDumper_Restore(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "restored")
+	dumper := NewPostgresDumper(PostgresConfig{
+		PgRestore: script(t, "pg_restore", `echo "$@" > `+output+`; cat >> `+output),
+		DBName:    "app_db",
+	})
+
+	require.NoError(t, dumper.Restore(context.Background(), strings.NewReader("PGDMP archive")))
+	restored, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "--clean --if-exists --no-owner --no-privileges --single-transaction --exit-on-error --dbname=app_db\nPGDMP archive", string(restored))
+}
+
+func TestPostgresDumper_Errors(t *testing.T) {
+	dumper := NewPostgresDumper(PostgresConfig{
+		PgDump:    script(t, "pg_dump", `echo 'pg_dump: error: connection to server failed' >&2; exit 1`),
+		PgRestore: filepath.Join(t.TempDir(), "missing"),
+	})
+
+	err := dumper.Dump(context.Background(), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection to server failed", "错误信息包含 stderr")
+
+	assert.Error(t, dumper.Check(), "pg_restore 不存在")
+	assert.Error(t, dumper.Restore(context.Background(), strings.NewReader("")))
+}
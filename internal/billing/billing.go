@@ -0,0 +1,275 @@
+// Package billing 订阅套餐和权益
+//
+// 套餐由配置定义，每个套餐包含一组权益（如 advanced_search）、限流和用量配额。用户的订阅由 Stripe webhook 同步到
+// subscriptions 表，订阅有效（active、trialing、past_due）时用户享有订阅的套餐，否则使用默认套餐。
+// 用户当前的套餐在缓存中保存一段时间，webhook 更新订阅后立即失效。
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/stripe"
+)
+
+// DefaultCacheTTL 用户当前套餐的默认缓存时间
+const DefaultCacheTTL = time.Minute
+
+// webhook 的处理结果
+const (
+	OutcomeApplied = "applied"
+	OutcomeIgnored = "ignored"
+	OutcomeStale   = "stale"
+)
+
+var (
+	// ErrInvalidWebhook webhook 签名无效或请求体无法解析
+	ErrInvalidWebhook = errors.New("billing: invalid webhook")
+	// ErrUnknownPrice 订阅的价格没有对应的套餐，需要在 billing.plans 中配置 stripe_price_ids；返回错误让 Stripe 稍后重试
+	ErrUnknownPrice = errors.New("billing: subscription price does not match any plan")
+)
+
+// UserStore 查找用户，用户服务实现了该接口
+type UserStore interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// Config 订阅服务配置
+type Config struct {
+	// Plans 套餐列表，按展示顺序排列
+	Plans []models.Plan
+	// DefaultPlan 没有有效订阅时使用的套餐
+	DefaultPlan string
+	// WebhookSecret Stripe webhook 签名密钥（whsec_...）
+	WebhookSecret string
+	// SignatureTolerance 签名时间戳允许的偏差，<=0 时使用 stripe.DefaultTolerance
+	SignatureTolerance time.Duration
+	// CacheTTL 用户当前套餐的缓存时间，<=0 时使用 DefaultCacheTTL
+	CacheTTL time.Duration
+}
+
+// Service 订阅服务
+type Service struct {
+	subscriptions repositories.SubscriptionRepository
+	users         UserStore
+	cache         cache.Cache
+	plans         map[string]*models.Plan
+	prices        map[string]string // Stripe 价格ID -> 套餐标识
+	config        Config
+	now           func() time.Time
+}
+
+// NewService 创建订阅服务，默认套餐不存在时返回错误；c 为 nil 时不缓存用户的套餐
+func NewService(subscriptions repositories.SubscriptionRepository, users UserStore, c cache.Cache, config Config) (*Service, error) {
+	if config.SignatureTolerance <= 0 {
+		config.SignatureTolerance = stripe.DefaultTolerance
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultCacheTTL
+	}
+
+	s := &Service{
+		subscriptions: subscriptions,
+		users:         users,
+		cache:         c,
+		plans:         make(map[string]*models.Plan, len(config.Plans)),
+		prices:        make(map[string]string),
+		config:        config,
+		now:           time.Now,
+	}
+	for i := range config.Plans {
+		plan := &config.Plans[i]
+		s.plans[plan.ID] = plan
+		for _, price := range plan.StripePriceIDs {
+			s.prices[price] = plan.ID
+		}
+	}
+	if _, ok := s.plans[config.DefaultPlan]; !ok {
+		return nil, fmt.Errorf("billing: default plan %q is not defined", config.DefaultPlan)
+	}
+	return s, nil
+}
+
+// Plans 返回所有套餐，按配置中的顺序排列
+func (s *Service) Plans() []*models.Plan {
+	plans := make([]*models.Plan, 0, len(s.config.Plans))
+	for i := range s.config.Plans {
+		plans = append(plans, &s.config.Plans[i])
+	}
+	return plans
+}
+
+// DefaultPlan 返回默认套餐
+func (s *Service) DefaultPlan() *models.Plan {
+	return s.plans[s.config.DefaultPlan]
+}
+
+// PlanForUser 返回用户当前享有的套餐，优先读取缓存
+func (s *Service) PlanForUser(ctx context.Context, userID string) (*models.Plan, error) {
+	if s.cache != nil {
+		if planID, found := cache.GetAs[string](ctx, s.cache, planCacheKey(userID)); found {
+			if plan, ok := s.plans[planID]; ok {
+				return plan, nil
+			}
+		}
+	}
+
+	subscription, err := s.subscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := s.effectivePlan(subscription)
+	if s.cache != nil {
+		_ = s.cache.Set(ctx, planCacheKey(userID), plan.ID, s.config.CacheTTL)
+	}
+	return plan, nil
+}
+
+// Subscription 返回用户当前享有的套餐和订阅，从未订阅时订阅为 nil
+func (s *Service) Subscription(ctx context.Context, userID string) (*models.SubscriptionResponse, error) {
+	subscription, err := s.subscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SubscriptionResponse{Plan: s.effectivePlan(subscription), Subscription: subscription}, nil
+}
+
+// HandleWebhook 校验并应用 Stripe 事件，只处理订阅的创建、更新和删除
+// 订阅通过 metadata.user_id 关联用户，没有时按 Stripe 客户ID查找已同步的订阅；无法关联的事件被忽略
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, signature string) (*models.StripeWebhookResponse, error) {
+	event, err := stripe.ConstructEvent(payload, signature, s.config.WebhookSecret, s.config.SignatureTolerance, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	result := &models.StripeWebhookResponse{EventID: event.ID, Outcome: OutcomeIgnored}
+	switch event.Type {
+	case stripe.EventSubscriptionCreated, stripe.EventSubscriptionUpdated, stripe.EventSubscriptionDeleted:
+	default:
+		return result, nil
+	}
+
+	object, err := event.Subscription()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	userID, existing, err := s.attribute(ctx, object)
+	if err != nil || userID == "" {
+		return result, err
+	}
+
+	status := object.Status
+	if event.Type == stripe.EventSubscriptionDeleted {
+		status = models.SubscriptionStatusCanceled
+	}
+	next := &models.Subscription{
+		UserID:               userID,
+		Status:               status,
+		StripeCustomerID:     object.Customer,
+		StripeSubscriptionID: object.ID,
+		CancelAtPeriodEnd:    object.CancelAtPeriodEnd,
+		LastEventAt:          event.CreatedAt(),
+	}
+	if end := object.PeriodEnd(); !end.IsZero() {
+		next.CurrentPeriodEnd = &end
+	}
+
+	if existing != nil {
+		if next.LastEventAt.Before(existing.LastEventAt) {
+			result.Outcome = OutcomeStale
+			return result, nil
+		}
+		// 用户换了新的订阅后，旧订阅的取消事件不能覆盖仍然有效的新订阅
+		if existing.StripeSubscriptionID != object.ID && existing.Entitled() && !next.Entitled() {
+			return result, nil
+		}
+	}
+
+	next.PlanID, err = s.planForPrices(object.PriceIDs())
+	if err != nil {
+		if !next.Entitled() && existing != nil {
+			// 已结束的订阅不再需要套餐，沿用原来的套餐
+			next.PlanID = existing.PlanID
+		} else if !next.Entitled() {
+			next.PlanID = s.config.DefaultPlan
+		} else {
+			return nil, err
+		}
+	}
+
+	if err := s.subscriptions.Save(ctx, next); err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		_ = s.cache.Delete(ctx, planCacheKey(userID))
+	}
+	result.Outcome = OutcomeApplied
+	return result, nil
+}
+
+// attribute 找到订阅所属的用户及其已同步的订阅，找不到用户时返回空的用户ID
+func (s *Service) attribute(ctx context.Context, object *stripe.Subscription) (string, *models.Subscription, error) {
+	userID := object.Metadata["user_id"]
+	if userID == "" {
+		existing, err := s.subscriptions.GetByStripeCustomerID(ctx, object.Customer)
+		if errors.Is(err, repositories.ErrSubscriptionNotFound) {
+			return "", nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		return existing.UserID, existing, nil
+	}
+
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		// 用户不存在或已停用，订阅无处可记
+		return "", nil, nil
+	}
+	existing, err := s.subscription(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	return userID, existing, nil
+}
+
+// subscription 返回用户的订阅，从未订阅时返回 nil
+func (s *Service) subscription(ctx context.Context, userID string) (*models.Subscription, error) {
+	subscription, err := s.subscriptions.GetByUserID(ctx, userID)
+	if errors.Is(err, repositories.ErrSubscriptionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// effectivePlan 返回订阅对应的套餐，订阅无效或套餐已从配置中删除时返回默认套餐
+func (s *Service) effectivePlan(subscription *models.Subscription) *models.Plan {
+	if subscription != nil && subscription.Entitled() {
+		if plan, ok := s.plans[subscription.PlanID]; ok {
+			return plan
+		}
+	}
+	return s.DefaultPlan()
+}
+
+// planForPrices 按订阅项的价格找到套餐
+func (s *Service) planForPrices(prices []string) (string, error) {
+	for _, price := range prices {
+		if planID, ok := s.prices[price]; ok {
+			return planID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %v", ErrUnknownPrice, slices.Clip(prices))
+}
+
+// planCacheKey 用户当前套餐的缓存键
+func planCacheKey(userID string) string {
+	return "billing:plan:" + userID
+}
@@ -0,0 +1,270 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/stripe"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSecret = "whsec_test"
+
+// newUser 创建订阅的用户，返回包含该用户的内存仓库
+func newUser(t *testing.T) (*repositories.MemoryUserRepository, *models.User) {
+	t.Helper()
+	users := repositories.NewMemoryUserRepository()
+	user := factory.User().WithUsername("alice").WithEmail("alice@example.com").Build()
+	require.NoError(t, users.Create(context.Background(), user))
+	return users, user
+}
+
+// newService 创建包含免费版和专业版套餐、使用内存缓存的计费服务，时钟固定为 now
+func newService(t *testing.T, subscriptions repositories.SubscriptionRepository, users UserStore, now time.Time) *Service {
+	t.Helper()
+	service, err := NewService(subscriptions, users, cache.NewMemoryCache(), Config{
+		Plans: []models.Plan{
+			{ID: "free", Name: "免费版"},
+			{ID: "pro", Name: "专业版", Entitlements: []string{"advanced_search"}, RateLimit: 600, StripePriceIDs: []string{"price_pro_monthly", "price_pro_yearly"}},
+		},
+		DefaultPlan:   "free",
+		WebhookSecret: webhookSecret,
+	})
+	require.NoError(t, err)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+// subscriptionEvent 构造订阅事件的请求体
+func subscriptionEvent(t *testing.T, id, eventType string, created time.Time, subscription map[string]any) []byte {
+	t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"id":      id,
+		"type":    eventType,
+		"created": created.Unix(),
+		"data":    map[string]any{"object": subscription},
+	})
+	require.NoError(t, err)
+	return payload
+}
+
+// stripeSubscription 构造 Stripe 订阅对象
+func stripeSubscription(id, userID, status, price string) map[string]any {
+	subscription := map[string]any{
+		"id":                 id,
+		"customer":           "cus_alice",
+		"status":             status,
+		"current_period_end": time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		"items":              map[string]any{"data": []any{map[string]any{"price": map[string]any{"id": price}}}},
+	}
+	if userID != "" {
+		subscription["metadata"] = map[string]any{"user_id": userID}
+	}
+	return subscription
+}
+
+// deliver 以 signedAt 为签名时间签名并投递事件
+func deliver(t *testing.T, s *Service, payload []byte, signedAt time.Time) (*models.StripeWebhookResponse, error) {
+	t.Helper()
+	return s.HandleWebhook(context.Background(), payload, stripe.SignatureHeader(payload, webhookSecret, signedAt))
+}
+
+func TestNewServiceRequiresDefaultPlan(t *testing.T) {
+	_, err := NewService(repositories.NewMemorySubscriptionRepository(), repositories.NewMemoryUserRepository(), nil, Config{
+		Plans:       []models.Plan{{ID: "pro"}},
+		DefaultPlan: "free",
+	})
+	assert.Error(t, err)
+}
+
+func TestPlanForUserWithoutSubscription(t *testing.T) {
+	users, user := newUser(t)
+	service := newService(t, repositories.NewMemorySubscriptionRepository(), users, time.Now())
+
+	plan, err := service.PlanForUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "free", plan.ID)
+
+	response, err := service.Subscription(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "free", response.Plan.ID)
+	assert.Nil(t, response.Subscription)
+}
+
+func TestHandleWebhookLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	users, user := newUser(t)
+	subscriptions := repositories.NewMemorySubscriptionRepository()
+	service := newService(t, subscriptions, users, now)
+
+	// 先读取一次，确认 webhook 会让缓存的套餐失效
+	plan, err := service.PlanForUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "free", plan.ID)
+
+	created := now.Add(-time.Minute)
+	result, err := deliver(t, service, subscriptionEvent(t, "evt_1", stripe.EventSubscriptionCreated, created,
+		stripeSubscription("sub_1", user.ID, models.SubscriptionStatusActive, "price_pro_monthly")), now)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeApplied, result.Outcome)
+	assert.Equal(t, "evt_1", result.EventID)
+
+	plan, err = service.PlanForUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", plan.ID)
+	assert.True(t, plan.HasEntitlement("advanced_search"))
+
+	stored, err := subscriptions.GetByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "sub_1", stored.StripeSubscriptionID)
+	assert.Equal(t, "cus_alice", stored.StripeCustomerID)
+	require.NotNil(t, stored.CurrentPeriodEnd)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), *stored.CurrentPeriodEnd)
+
+	// 没有 metadata 的事件按 Stripe 客户关联到用户
+	deleted := subscriptionEvent(t, "evt_2", stripe.EventSubscriptionDeleted, created.Add(30*time.Second),
+		stripeSubscription("sub_1", "", models.SubscriptionStatusActive, "price_retired"))
+	result, err = deliver(t, service, deleted, now)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeApplied, result.Outcome)
+
+	response, err := service.Subscription(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "free", response.Plan.ID)
+	require.NotNil(t, response.Subscription)
+	assert.Equal(t, models.SubscriptionStatusCanceled, response.Subscription.Status)
+	assert.Equal(t, "pro", response.Subscription.PlanID)
+}
+
+func TestHandleWebhookOutcomes(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	const unknownUser = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name        string
+		before      func(userID string) []byte // 先投递的事件，为 nil 时不投递
+		event       func(userID string) []byte
+		wantOutcome string
+		wantSubID   string // 为空时用户没有订阅
+		wantStatus  string
+	}{
+		{
+			name: "丢弃过期的事件",
+			before: func(userID string) []byte {
+				return subscriptionEvent(t, "evt_2", stripe.EventSubscriptionUpdated, now,
+					stripeSubscription("sub_1", userID, models.SubscriptionStatusActive, "price_pro_yearly"))
+			},
+			event: func(userID string) []byte {
+				return subscriptionEvent(t, "evt_1", stripe.EventSubscriptionUpdated, now.Add(-time.Minute),
+					stripeSubscription("sub_1", userID, models.SubscriptionStatusIncomplete, "price_pro_yearly"))
+			},
+			wantOutcome: OutcomeStale,
+			wantSubID:   "sub_1",
+			wantStatus:  models.SubscriptionStatusActive,
+		},
+		{
+			name: "保留替换后的订阅",
+			before: func(userID string) []byte {
+				return subscriptionEvent(t, "evt_1", stripe.EventSubscriptionCreated, now.Add(-time.Minute),
+					stripeSubscription("sub_new", userID, models.SubscriptionStatusActive, "price_pro_monthly"))
+			},
+			event: func(userID string) []byte {
+				return subscriptionEvent(t, "evt_2", stripe.EventSubscriptionDeleted, now,
+					stripeSubscription("sub_old", userID, models.SubscriptionStatusCanceled, "price_pro_monthly"))
+			},
+			wantOutcome: OutcomeIgnored,
+			wantSubID:   "sub_new",
+			wantStatus:  models.SubscriptionStatusActive,
+		},
+		{
+			name: "其他事件类型",
+			event: func(string) []byte {
+				return subscriptionEvent(t, "evt_1", "invoice.paid", now, map[string]any{"id": "in_1"})
+			},
+			wantOutcome: OutcomeIgnored,
+		},
+		{
+			name: "未知用户",
+			event: func(string) []byte {
+				return subscriptionEvent(t, "evt_2", stripe.EventSubscriptionCreated, now,
+					stripeSubscription("sub_1", unknownUser, models.SubscriptionStatusActive, "price_pro_monthly"))
+			},
+			wantOutcome: OutcomeIgnored,
+		},
+		{
+			name: "未知客户",
+			event: func(string) []byte {
+				return subscriptionEvent(t, "evt_3", stripe.EventSubscriptionCreated, now,
+					stripeSubscription("sub_1", "", models.SubscriptionStatusActive, "price_pro_monthly"))
+			},
+			wantOutcome: OutcomeIgnored,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users, user := newUser(t)
+			subscriptions := repositories.NewMemorySubscriptionRepository()
+			service := newService(t, subscriptions, users, now)
+			if tt.before != nil {
+				_, err := deliver(t, service, tt.before(user.ID), now)
+				require.NoError(t, err)
+			}
+
+			result, err := deliver(t, service, tt.event(user.ID), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOutcome, result.Outcome)
+
+			stored, err := subscriptions.GetByUserID(ctx, user.ID)
+			if tt.wantSubID == "" {
+				assert.ErrorIs(t, err, repositories.ErrSubscriptionNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSubID, stored.StripeSubscriptionID)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+			assert.True(t, stored.Entitled())
+		})
+	}
+}
+
+func TestHandleWebhookErrors(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	users, user := newUser(t)
+	service := newService(t, repositories.NewMemorySubscriptionRepository(), users, now)
+	payload := subscriptionEvent(t, "evt_1", stripe.EventSubscriptionCreated, now,
+		stripeSubscription("sub_1", user.ID, models.SubscriptionStatusActive, "price_pro_monthly"))
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		secret   string
+		signedAt time.Time
+		wantErr  error
+	}{
+		{"签名密钥错误", payload, "whsec_other", now, ErrInvalidWebhook},
+		{"签名时间超出容差", payload, webhookSecret, now.Add(-time.Hour), ErrInvalidWebhook},
+		{
+			"未知价格",
+			subscriptionEvent(t, "evt_2", stripe.EventSubscriptionCreated, now,
+				stripeSubscription("sub_1", user.ID, models.SubscriptionStatusActive, "price_unknown")),
+			webhookSecret, now, ErrUnknownPrice,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.HandleWebhook(context.Background(), tt.payload, stripe.SignatureHeader(tt.payload, tt.secret, tt.signedAt))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
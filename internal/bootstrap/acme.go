@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/pkg/autotls"
+	"go-server/pkg/cache"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// initializeACME 初始化自动 TLS 证书管理，证书缓存在对象存储或 Redis 中，多实例共享
+// 启用后缓存不可用时启动失败，避免每个实例各自申请证书而触发 ACME 服务器的频率限制
+func (c *Container) initializeACME() error {
+	cfg := c.Config.Server.ACME
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var certCache autocert.Cache
+	switch cfg.Cache {
+	case "redis":
+		redisCache, ok := c.Cache.(*cache.RedisCache)
+		if !ok {
+			return errors.New("证书缓存配置为 redis，但 Redis 缓存不可用")
+		}
+		certCache = autotls.NewRedisCache(redisCache.GetClient(), cfg.CachePrefix)
+	default:
+		if c.Storage == nil {
+			return errors.New("证书缓存配置为 storage，但对象存储不可用")
+		}
+		certCache = autotls.NewStorageCache(c.Storage, cfg.CachePrefix)
+	}
+
+	tlsMetrics := metrics.NewTLSMetrics()
+	if c.MetricsRegistry != nil {
+		c.MetricsRegistry.RegisterTLS(tlsMetrics)
+	}
+
+	manager, err := autotls.New(autotls.Config{
+		Domains:      cfg.Domains,
+		Email:        cfg.Email,
+		DirectoryURL: cfg.DirectoryURL,
+		RenewBefore:  time.Duration(cfg.RenewBefore) * 24 * time.Hour,
+		Cache:        certCache,
+		Recorder:     tlsMetrics,
+	})
+	if err != nil {
+		return fmt.Errorf("初始化证书管理失败: %w", err)
+	}
+	c.CertManager = manager
+
+	c.Logger.GetLogger("app").Info(context.Background(), "自动 TLS 证书已启用",
+		logger.Any("domains", manager.Domains()),
+		logger.String("challenge", cfg.Challenge),
+		logger.String("cache", cfg.Cache))
+	return nil
+}
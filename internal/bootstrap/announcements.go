@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/announcements"
+	"go-server/internal/logger"
+	"go-server/internal/repositories"
+)
+
+// initializeAnnouncements 创建公告服务，关闭时先结束 SSE 连接以便 HTTP 服务器排空
+// 未启用或没有数据库时不创建，公告接口返回服务不可用
+func (c *Container) initializeAnnouncements() error {
+	announcementsConfig := c.Config.Announcements
+	if !announcementsConfig.Enabled || c.Database == nil {
+		return nil
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	service := announcements.NewService(
+		repositories.NewAnnouncementRepository(c.Database.DB),
+		c.UserService,
+		c.userCache(),
+		announcements.Config{
+			CacheTTL: time.Duration(announcementsConfig.CacheTTL) * time.Second,
+			OnError: func(operation string, err error) {
+				appLogger.Warn(context.Background(), operation+"失败", logger.Error(err))
+			},
+		},
+	)
+	c.Announcements = service
+	c.Shutdown.Register(PhaseStopAccepting, "announcement_streams", service.Close)
+
+	appLogger.Info(context.Background(), "公告服务已启用",
+		logger.Int("cache_ttl_seconds", announcementsConfig.CacheTTL),
+		logger.Bool("stream", announcementsConfig.Stream))
+
+	return nil
+}
@@ -2,19 +2,56 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
 	"time"
 
+	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/internal/metrics"
 	"go-server/pkg/auth"
+	"go-server/pkg/auth/password"
 	"go-server/pkg/cache"
 )
 
-// initializeAuth 初始化JWT管理器和黑名单服务
+// initializeAuth 初始化密码哈希、JWT管理器和黑名单服务
 func (c *Container) initializeAuth() error {
 	appLogger := c.Logger.GetLogger("app")
 
+	hasher, err := password.NewManager(passwordPolicy(c.Config.Auth))
+	if err != nil {
+		return fmt.Errorf("初始化密码哈希失败: %w", err)
+	}
+	c.PasswordHasher = hasher
+
+	policy := c.Config.Auth.PasswordPolicy
+	rules, breaches, err := passwordRules(policy)
+	if err != nil {
+		return fmt.Errorf("初始化密码策略失败: %w", err)
+	}
+	c.PasswordChecker = password.NewChecker(rules, breaches)
+
+	appLogger.Info(context.Background(), "密码哈希和密码策略已初始化",
+		logger.String("algorithm", c.Config.Auth.PasswordAlgorithm),
+		logger.Int("min_length", rules.MinLength),
+		logger.Int("denylist_size", len(rules.Denylist)),
+		logger.Bool("breach_check", breaches != nil))
+
+	// 加载签名密钥（当前密钥 + 轮换前的旧密钥）
+	keys, err := buildJWTKeySet(c.Config.JWT)
+	if err != nil {
+		return fmt.Errorf("加载JWT签名密钥失败: %w", err)
+	}
+
+	appLogger.Info(context.Background(), "JWT签名密钥已加载",
+		logger.String("algorithm", c.Config.JWT.Algorithm),
+		logger.String("active_kid", c.Config.JWT.KeyID),
+		logger.Any("kids", keys.IDs()))
+
 	// 初始化基础JWT管理器
-	c.JWTManager = auth.NewJWTManager(c.Config.JWT.SecretKey, c.Config.JWT.ExpiresIn)
+	c.JWTManager = auth.NewJWTManagerWithKeys(keys, c.Config.JWT.ExpiresIn, nil)
+	c.JWTManager.SetRefreshExpiresIn(time.Duration(c.Config.JWT.RefreshExpiresIn) * time.Hour)
 
 	// 如果Redis可用，初始化JWT令牌黑名单服务
 	if c.Cache != nil {
@@ -23,6 +60,13 @@ func (c *Container) initializeAuth() error {
 			CleanupInterval: 1 * time.Hour,
 			BatchSize:       100,
 		}
+		if filterCfg := c.Config.JWT.BlacklistFilter; filterCfg.Enabled {
+			blacklistConfig.Filter = &cache.BlacklistFilterConfig{
+				ExpectedTokens:    filterCfg.ExpectedTokens,
+				FalsePositiveRate: filterCfg.FalsePositiveRate,
+				RefreshInterval:   time.Duration(filterCfg.RefreshInterval) * time.Second,
+			}
+		}
 
 		c.BlacklistService = cache.NewBlacklistService(c.Cache, c.JWTManager, blacklistConfig)
 
@@ -31,11 +75,12 @@ func (c *Container) initializeAuth() error {
 			logger.Int("batch_size", blacklistConfig.BatchSize))
 
 		// 使用黑名单支持重新初始化JWT管理器
-		c.JWTManager = auth.NewJWTManagerWithBlacklist(
-			c.Config.JWT.SecretKey,
+		c.JWTManager = auth.NewJWTManagerWithKeys(
+			keys,
 			c.Config.JWT.ExpiresIn,
 			c.BlacklistService,
 		)
+		c.JWTManager.SetRefreshExpiresIn(time.Duration(c.Config.JWT.RefreshExpiresIn) * time.Hour)
 
 		appLogger.Info(context.Background(), "JWT管理器已重新初始化，具有黑名单支持")
 
@@ -43,14 +88,210 @@ func (c *Container) initializeAuth() error {
 		go c.startBlacklistCleanup(blacklistConfig.CleanupInterval)
 
 		appLogger.Info(context.Background(), "JWT黑名单清理例程已启动")
+
+		c.startBlacklistFilter()
 	} else {
 		appLogger.Warn(context.Background(), "JWT黑名单服务不可用 - Redis缓存未初始化")
 		appLogger.Warn(context.Background(), "令牌将仅使用标准JWT验证")
 	}
 
+	// 按验证所用的密钥统计令牌，热更新密钥后据此判断旧密钥签发的令牌是否仍在使用
+	c.JWTMetrics = metrics.NewJWTMetrics()
+	c.MetricsRegistry.RegisterJWT(c.JWTMetrics)
+	c.JWTManager.SetVerificationObserver(c.JWTMetrics.RecordVerification)
+
 	return nil
 }
 
+// passwordPolicy 根据认证配置构建密码哈希策略
+func passwordPolicy(cfg config.AuthConfig) password.Policy {
+	return password.Policy{
+		Algorithm:  cfg.PasswordAlgorithm,
+		BcryptCost: cfg.BcryptCost,
+		Argon2: password.Argon2Params{
+			Memory:      uint32(cfg.Argon2.Memory),
+			Iterations:  uint32(cfg.Argon2.Iterations),
+			Parallelism: uint8(cfg.Argon2.Parallelism),
+		},
+	}
+}
+
+// passwordRules 根据密码策略配置构建新密码的检查规则，未启用泄露密码检查时泄露库为 nil
+func passwordRules(cfg config.PasswordPolicyConfig) (password.Rules, password.BreachChecker, error) {
+	denylist := append([]string(nil), cfg.Denylist...)
+	if cfg.DenylistFile != "" {
+		file, err := os.Open(cfg.DenylistFile)
+		if err != nil {
+			return password.Rules{}, nil, fmt.Errorf("读取禁用密码列表失败: %w", err)
+		}
+		defer file.Close()
+		entries, err := password.LoadDenylist(file)
+		if err != nil {
+			return password.Rules{}, nil, fmt.Errorf("读取禁用密码列表失败: %w", err)
+		}
+		denylist = append(denylist, entries...)
+	}
+
+	rules := password.Rules{
+		MinLength:      cfg.MinLength,
+		MaxLength:      cfg.MaxLength,
+		MinEntropy:     cfg.MinEntropy,
+		Denylist:       denylist,
+		RejectUserInfo: cfg.RejectUserInfo,
+	}
+	var breaches password.BreachChecker
+	if cfg.BreachCheck.Enabled {
+		breaches = password.NewPwnedPasswords(cfg.BreachCheck.APIURL, time.Duration(cfg.BreachCheck.Timeout)*time.Second)
+	}
+	return rules, breaches, nil
+}
+
+// buildJWTKeySet 根据配置构建签名密钥集合，旧密钥仅用于验证轮换前签发的令牌
+func buildJWTKeySet(cfg config.JWTConfig) (*auth.KeySet, error) {
+	active, previous, err := jwtSigningKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewKeySet(active, previous...)
+}
+
+// jwtSigningKeys 根据配置加载活动密钥和旧密钥
+func jwtSigningKeys(cfg config.JWTConfig) (*auth.SigningKey, []*auth.SigningKey, error) {
+	keyID := cfg.KeyID
+	if keyID == "" {
+		keyID = auth.DefaultKeyID
+	}
+
+	var active *auth.SigningKey
+	switch cfg.Algorithm {
+	case auth.AlgorithmRS256, auth.AlgorithmES256:
+		pemData, err := readKeyMaterial(cfg.PrivateKey, cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		active, err = auth.ParsePrivateKeyPEM(keyID, pemData)
+		if err != nil {
+			return nil, nil, err
+		}
+		if active.Algorithm != cfg.Algorithm {
+			return nil, nil, fmt.Errorf("私钥类型与签名算法 %s 不匹配", cfg.Algorithm)
+		}
+	default:
+		active = auth.NewHMACKey(keyID, cfg.SecretKey)
+	}
+
+	var previous []*auth.SigningKey
+	for _, keyCfg := range cfg.PreviousKeys {
+		switch keyCfg.Algorithm {
+		case auth.AlgorithmRS256, auth.AlgorithmES256:
+			pemData, err := readKeyMaterial(keyCfg.PublicKey, keyCfg.PublicKeyFile)
+			if err != nil {
+				return nil, nil, fmt.Errorf("旧密钥 %s: %w", keyCfg.KeyID, err)
+			}
+			key, err := auth.ParsePublicKeyPEM(keyCfg.KeyID, pemData)
+			if err != nil {
+				return nil, nil, fmt.Errorf("旧密钥 %s: %w", keyCfg.KeyID, err)
+			}
+			previous = append(previous, key)
+		default:
+			previous = append(previous, auth.NewHMACKey(keyCfg.KeyID, keyCfg.SecretKey))
+		}
+	}
+
+	return active, previous, nil
+}
+
+// jwtKeysEqual 两份配置的签名密钥是否相同
+func jwtKeysEqual(a, b config.JWTConfig) bool {
+	return a.Algorithm == b.Algorithm &&
+		a.KeyID == b.KeyID &&
+		a.SecretKey == b.SecretKey &&
+		a.PrivateKey == b.PrivateKey &&
+		a.PrivateKeyFile == b.PrivateKeyFile &&
+		slices.Equal(a.PreviousKeys, b.PreviousKeys)
+}
+
+// jwtRotationGrace 返回热更新签名密钥后原活动密钥的宽限期，未配置时为令牌有效期
+func jwtRotationGrace(cfg config.JWTConfig) time.Duration {
+	if cfg.RotationGracePeriod > 0 {
+		return time.Duration(cfg.RotationGracePeriod) * time.Second
+	}
+	return time.Duration(cfg.ExpiresIn) * time.Hour
+}
+
+// readKeyMaterial 读取 PEM 密钥，优先使用内联内容
+func readKeyMaterial(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	return data, nil
+}
+
+// startBlacklistFilter 加载黑名单本地布隆过滤器并启动定期同步，关闭时停止同步
+func (c *Container) startBlacklistFilter() {
+	appLogger := c.Logger.GetLogger("app")
+
+	if !c.Config.JWT.BlacklistFilter.Enabled {
+		return
+	}
+	if !c.BlacklistService.FilterEnabled() {
+		appLogger.Warn(context.Background(), "缓存驱动不支持列出键，JWT黑名单过滤器已停用",
+			logger.String("driver", c.Config.Cache.Driver))
+		return
+	}
+
+	// 首次加载失败时过滤器保持未就绪，检查回退到缓存查询
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := c.BlacklistService.RefreshFilter(ctx); err != nil {
+		appLogger.Warn(ctx, "加载JWT黑名单过滤器失败，将在下次同步时重试", logger.Error(err))
+	}
+	cancel()
+
+	interval := c.BlacklistService.FilterRefreshInterval()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				if err := c.BlacklistService.RefreshFilter(ctx); err != nil {
+					appLogger.Warn(ctx, "同步JWT黑名单过滤器失败", logger.Error(err))
+				}
+				cancel()
+			}
+		}
+	}()
+
+	c.Shutdown.Register(PhaseStopWorkers, "jwt_blacklist_filter", func(ctx context.Context) error {
+		close(stop)
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	stats, _ := c.BlacklistService.FilterStats()
+	appLogger.Info(context.Background(), "JWT黑名单过滤器已启用",
+		logger.Bool("ready", stats.Ready),
+		logger.Int("tokens", stats.Tokens),
+		logger.String("refresh_interval", interval.String()))
+}
+
 // startBlacklistCleanup 启动黑名单清理后台任务
 func (c *Container) startBlacklistCleanup(interval time.Duration) {
 	ticker := time.NewTicker(interval)
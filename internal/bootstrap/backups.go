@@ -0,0 +1,115 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-server/internal/backups"
+	"go-server/internal/logger"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/fieldcrypt"
+	"go-server/pkg/storage"
+)
+
+// initializeBackups 创建数据库备份服务，备份文件加密后保存在对象存储中
+// 未启用、没有数据库或对象存储不可用时不创建；找不到 pg_dump 或 pg_restore 时记录警告并跳过，备份接口返回服务不可用
+func (c *Container) initializeBackups() error {
+	backupsConfig := c.Config.Backups
+	if !backupsConfig.Enabled || c.Database == nil || c.Storage == nil {
+		return nil
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	dbConfig := c.Config.Database
+	dumper := backups.NewPostgresDumper(backups.PostgresConfig{
+		PgDump:    backupsConfig.PgDump,
+		PgRestore: backupsConfig.PgRestore,
+		Host:      dbConfig.Host,
+		Port:      dbConfig.Port,
+		User:      dbConfig.User,
+		Password:  dbConfig.Password,
+		DBName:    dbConfig.DBName,
+		SSLMode:   dbConfig.SSLMode,
+		// 恢复不改变备份列表
+		ExcludeTables: []string{models.Backup{}.TableName()},
+	})
+	if err := dumper.Check(); err != nil {
+		appLogger.Warn(context.Background(), "未找到 PostgreSQL 客户端，数据库备份不可用", logger.Error(err))
+		return nil
+	}
+
+	keys, err := fieldcrypt.ParseKeys(backupsConfig.EncryptionKeys)
+	if err != nil {
+		return fmt.Errorf("备份加密密钥格式错误: %w", err)
+	}
+	store, err := storage.NewEncryptedStorage(c.Storage, keys, backupsConfig.ActiveKey)
+	if err != nil {
+		return err
+	}
+
+	service := backups.NewService(
+		repositories.NewBackupRepository(c.Database.DB),
+		store,
+		dumper,
+		backups.Config{
+			Timeout:      time.Duration(backupsConfig.Timeout) * time.Minute,
+			Retention:    time.Duration(backupsConfig.Retention) * 24 * time.Hour,
+			Interval:     time.Duration(backupsConfig.Interval) * time.Hour,
+			AfterRestore: c.clearRestoredCache,
+			OnBackup: func(backup *models.Backup) {
+				fields := []logger.Field{
+					logger.String("backup_id", backup.ID),
+					logger.String("trigger", backup.Trigger),
+					logger.Int64("size", backup.Size),
+				}
+				if backup.Status == models.BackupStatusFailed {
+					appLogger.Warn(context.Background(), "数据库备份失败", append(fields, logger.String("error", backup.Error))...)
+				} else {
+					appLogger.Info(context.Background(), "数据库备份完成", fields...)
+				}
+			},
+			OnRestore: func(status models.RestoreStatus) {
+				fields := []logger.Field{
+					logger.String("backup_id", status.BackupID),
+					logger.String("requested_by", status.RequestedBy),
+				}
+				if status.State == models.RestoreStateFailed {
+					appLogger.Error(context.Background(), "数据库恢复失败", append(fields, logger.String("error", status.Error))...)
+				} else {
+					appLogger.Warn(context.Background(), "数据库已从备份恢复", fields...)
+				}
+			},
+			OnError: func(operation string, err error) {
+				appLogger.Warn(context.Background(), operation+"失败", logger.Error(err))
+			},
+		},
+	)
+	c.Backups = service
+
+	c.OnStart("backups", func(ctx context.Context) error {
+		service.Start()
+		return nil
+	})
+	// 中断正在执行的备份或恢复，须在关闭数据库之前执行
+	c.Shutdown.Register(PhaseStopWorkers, "backups", service.Stop)
+
+	appLogger.Info(context.Background(), "数据库备份已启用",
+		logger.Int("interval_hours", backupsConfig.Interval),
+		logger.Int("retention_days", backupsConfig.Retention),
+		logger.Int("timeout_minutes", backupsConfig.Timeout))
+
+	return nil
+}
+
+// clearRestoredCache 恢复后清空用户缓存，避免继续返回恢复前的数据
+// 缓存驱动不能只清空本实例的键时不清空，由缓存过期时间兜底
+func (c *Container) clearRestoredCache(ctx context.Context) error {
+	userCache := c.userCache()
+	if userCache == nil || !cache.Supports(userCache, cache.CapabilityScopedClear) {
+		return nil
+	}
+	return userCache.Clear(ctx)
+}
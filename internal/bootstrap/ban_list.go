@@ -0,0 +1,84 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/banlist"
+	"go-server/pkg/cache"
+)
+
+// initializeBanList 初始化自动封禁名单，根据限流违规热点封禁 IP 或用户
+// 缓存是 Redis 时封禁记录在实例间共享，否则保存在内存中，只对当前实例有效
+func (c *Container) initializeBanList() {
+	cfg := c.Config.RateLimit.AutoBan
+	if !cfg.Enabled {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	var store banlist.Store
+	if redisCache, ok := c.Cache.(*cache.RedisCache); ok {
+		store = banlist.NewRedisStore(redisCache.GetClient(), cfg.KeyPrefix)
+	} else {
+		store = banlist.NewMemoryStore()
+		appLogger.Warn(context.Background(), "缓存不是 Redis，封禁记录将保存在内存中，仅对当前实例有效")
+	}
+
+	window := time.Duration(cfg.Window) * time.Second
+	banList := banlist.New(store, func() []banlist.Offender {
+		return c.rateLimitOffenders(window)
+	}, banlist.Config{
+		Threshold:        cfg.Threshold,
+		MinViolationRate: cfg.MinViolationRate,
+		Duration:         time.Duration(cfg.Duration) * time.Second,
+		Interval:         time.Duration(cfg.CheckInterval) * time.Second,
+		GracePeriod:      window,
+		OnBan: func(ban *banlist.Ban) {
+			appLogger.Warn(context.Background(), "限流违规过多，已自动封禁",
+				logger.String("type", ban.Type),
+				logger.String("identifier", ban.Identifier),
+				logger.Int("violations", ban.Violations),
+				logger.Float64("violation_rate", ban.ViolationRate),
+				logger.String("expires_at", ban.ExpiresAt.Format(time.RFC3339)))
+		},
+		OnError: func(err error) {
+			appLogger.Warn(context.Background(), "自动封禁检查失败", logger.Error(err))
+		},
+	})
+	c.BanList = banList
+
+	c.OnStart("ban_list", func(ctx context.Context) error {
+		banList.Start()
+		return nil
+	})
+	c.Shutdown.Register(PhaseStopWorkers, "ban_list", banList.Stop)
+
+	appLogger.Info(context.Background(), "自动封禁已启用",
+		logger.Int("threshold", cfg.Threshold),
+		logger.Float64("min_violation_rate", cfg.MinViolationRate),
+		logger.Int("window_seconds", cfg.Window),
+		logger.Int("duration_seconds", cfg.Duration),
+		logger.Int("check_interval_seconds", cfg.CheckInterval))
+}
+
+// rateLimitOffenders 返回统计窗口内的限流违规热点；限流中间件在封禁名单之后创建，因此在检查时读取
+// warn 和 shadow 模式下限流不拦截请求，违规只用于评估限制，不触发封禁
+func (c *Container) rateLimitOffenders(window time.Duration) []banlist.Offender {
+	if c.RateLimiter == nil || !c.Config.RateLimit.Enabled || !c.RateLimiter.Enforcing() {
+		return nil
+	}
+
+	hotspots := c.RateLimiter.Metrics().GetEffectivenessMetrics(window).ViolationHotspots
+	offenders := make([]banlist.Offender, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		offenders = append(offenders, banlist.Offender{
+			Type:          hotspot.Type,
+			Identifier:    hotspot.Identifier,
+			Violations:    hotspot.ViolationCount,
+			ViolationRate: hotspot.ViolationRate,
+		})
+	}
+	return offenders
+}
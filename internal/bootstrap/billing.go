@@ -0,0 +1,58 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/billing"
+	"go-server/internal/logger"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+)
+
+// initializeBilling 创建订阅服务，未启用或没有数据库时不创建，不限制权益，相关接口返回服务不可用
+func (c *Container) initializeBilling() error {
+	billingConfig := c.Config.Billing
+	if !billingConfig.Enabled || c.Database == nil {
+		return nil
+	}
+
+	plans := make([]models.Plan, 0, len(billingConfig.Plans))
+	for _, plan := range billingConfig.Plans {
+		quotas := make([]models.PlanQuota, 0, len(plan.Quotas))
+		for _, limit := range plan.Quotas {
+			quotas = append(quotas, models.PlanQuota{Name: limit.Name, Soft: limit.Soft, Hard: limit.Hard})
+		}
+		plans = append(plans, models.Plan{
+			ID:             plan.ID,
+			Name:           plan.Name,
+			Entitlements:   append([]string{}, plan.Entitlements...),
+			RateLimit:      plan.RateLimit,
+			Quotas:         quotas,
+			StripePriceIDs: append([]string(nil), plan.StripePriceIDs...),
+		})
+	}
+
+	service, err := billing.NewService(
+		repositories.NewSubscriptionRepository(c.Database.DB),
+		c.UserService,
+		c.Cache,
+		billing.Config{
+			Plans:              plans,
+			DefaultPlan:        billingConfig.DefaultPlan,
+			WebhookSecret:      billingConfig.StripeWebhookSecret,
+			SignatureTolerance: time.Duration(billingConfig.SignatureTolerance) * time.Second,
+			CacheTTL:           time.Duration(billingConfig.CacheTTL) * time.Second,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	c.Billing = service
+
+	c.Logger.GetLogger("app").Info(context.Background(), "订阅套餐已启用",
+		logger.Int("plans", len(plans)),
+		logger.String("default_plan", billingConfig.DefaultPlan))
+
+	return nil
+}
@@ -3,23 +3,216 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/internal/repositories"
 	"go-server/pkg/cache"
 )
 
-// initializeCache 初始化Redis缓存
+// cacheKeyPrefix 应用写入缓存的键前缀
+const cacheKeyPrefix = "golang_template:"
+
+// initializeCache 按配置的驱动初始化缓存（Redis或memcached）
 func (c *Container) initializeCache() error {
 	appLogger := c.Logger.GetLogger("app")
 
+	var appCache cache.Cache
+	var err error
+	switch c.Config.Cache.Driver {
+	case "memcached":
+		appCache, err = c.newMemcachedCache()
+	default:
+		appCache, err = c.newRedisCache()
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Cache = appCache
+
+	c.Shutdown.Register(PhaseCloseResources, "cache", func(ctx context.Context) error {
+		return appCache.Close()
+	})
+
+	c.initializeCacheSupervisor(appCache)
+
+	// 测试缓存连接
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	testKey := "startup_test"
+	if err := appCache.Set(ctx, testKey, "test", 10*time.Second); err != nil {
+		appLogger.Warn(context.Background(), "缓存测试操作失败",
+			logger.Error(err))
+		appLogger.Warn(context.Background(), "缓存可能不稳定 - 建议检查缓存配置")
+	} else {
+		appCache.Delete(ctx, testKey) // 清理测试键
+		appLogger.Info(context.Background(), "缓存连接验证成功")
+	}
+
+	return nil
+}
+
+// initializeCacheSupervisor 按配置创建缓存健康监督器
+// Redis 连续失败后用户缓存进入直通模式，请求不再为每次缓存访问等待超时；状态切换写入日志，统计通过缓存统计接口查看
+func (c *Container) initializeCacheSupervisor(appCache cache.Cache) {
+	supervisorConfig := c.Config.Cache.Supervisor
+	if !supervisorConfig.Enabled {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	supervisor := cache.NewSupervisor(appCache, cache.SupervisorConfig{
+		FailureThreshold: supervisorConfig.FailureThreshold,
+		ProbeInterval:    time.Duration(supervisorConfig.ProbeInterval) * time.Second,
+		ProbeTimeout:     time.Duration(supervisorConfig.ProbeTimeout) * time.Millisecond,
+		OnStateChange: func(from, to cache.SupervisorState, err error) {
+			if to == cache.StateDegraded {
+				appLogger.Error(context.Background(), "缓存连续失败，用户缓存进入直通模式",
+					logger.String("from", from.String()),
+					logger.String("to", to.String()),
+					logger.Int("failure_threshold", supervisorConfig.FailureThreshold),
+					logger.Error(err))
+				return
+			}
+			appLogger.Info(context.Background(), "缓存已恢复，用户缓存退出直通模式",
+				logger.String("from", from.String()),
+				logger.String("to", to.String()))
+		},
+	})
+	c.CacheSupervisor = supervisor
+
+	c.OnStart("cache_supervisor", func(ctx context.Context) error {
+		supervisor.Start()
+		return nil
+	})
+
+	c.Shutdown.Register(PhaseStopWorkers, "cache_supervisor", supervisor.Stop)
+}
+
+// userCache 返回用户缓存使用的缓存，启用健康监督时为监督器包装后的缓存
+func (c *Container) userCache() cache.Cache {
+	if c.CacheSupervisor != nil {
+		return c.CacheSupervisor
+	}
+	return c.Cache
+}
+
+// initializeCacheWarmer 创建缓存预热器，并按配置在启动时预热
+// 预热失败不是致命错误，请求会在缓存未命中时回源数据库
+func (c *Container) initializeCacheWarmer() {
+	if c.Cache == nil {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	warmupConfig := c.Config.Cache.Warmup
+
+	warmer := cache.NewWarmer(time.Duration(warmupConfig.Timeout) * time.Second)
+	warmer.Register("users", c.warmUsers(warmupConfig.TopUsers))
+	c.CacheWarmer = warmer
+
+	c.Shutdown.Register(PhaseStopWorkers, "cache_warmer", warmer.Close)
+
+	if !warmupConfig.OnStartup {
+		return
+	}
+
+	status, err := warmer.Run(context.Background(), cache.WarmTriggerStartup, warmupConfig.Datasets)
+	if err != nil {
+		appLogger.Warn(context.Background(), "启动时缓存预热失败",
+			logger.Error(err))
+		return
+	}
+
+	for _, dataset := range status.Datasets {
+		appLogger.Info(context.Background(), "缓存预热完成",
+			logger.String("dataset", dataset.Name),
+			logger.Int("entries", dataset.Done),
+			logger.Int64("duration_ms", dataset.DurationMS))
+	}
+}
+
+// warmUsers 预热用户列表前几页和其中前 topUsers 个用户的详情
+// 按用户列表接口的默认每页数量分页，使预热的缓存键与默认请求一致
+func (c *Container) warmUsers(topUsers int) cache.WarmFunc {
+	const pageSize = 10
+
+	return func(ctx context.Context, progress cache.WarmProgress) error {
+		done := 0
+		for page := 1; done < topUsers; page++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			users, total, err := c.UserService.GetAll(ctx, page, pageSize)
+			if err != nil {
+				return fmt.Errorf("加载用户列表失败: %w", err)
+			}
+			target := topUsers
+			if int(total) < target {
+				target = int(total)
+			}
+
+			for _, user := range users {
+				if done >= target {
+					break
+				}
+				if _, err := c.UserService.GetByID(ctx, user.ID); err != nil {
+					return fmt.Errorf("加载用户 %s 失败: %w", user.ID, err)
+				}
+				done++
+				progress(done, target)
+			}
+
+			if len(users) < pageSize {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+// cacheCodec 返回配置的用户缓存序列化方式，未配置时使用 JSON
+func cacheCodec(cfg *config.Config) (cache.Codec, error) {
+	name := cfg.Cache.Codec
+	if name == "" {
+		name = cache.CodecJSON
+	}
+	codec, err := cache.LookupCodec(name)
+	if err != nil {
+		return nil, fmt.Errorf("初始化缓存序列化方式失败: %w", err)
+	}
+	// protobuf 等只支持特定类型的编码不能保存用户记录，启动时拒绝，而不是让用户缓存静默失效
+	if err := repositories.CheckCacheCodec(codec); err != nil {
+		return nil, fmt.Errorf("缓存序列化方式 %s 不能用于用户缓存: %w", name, err)
+	}
+	return codec, nil
+}
+
+// cacheMethodTTLs 将配置中按方法覆盖的缓存过期时间（秒）转换为时长
+func cacheMethodTTLs(cfg *config.Config) map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(cfg.Cache.TTLs))
+	for method, seconds := range cfg.Cache.TTLs {
+		ttls[method] = time.Duration(seconds) * time.Second
+	}
+	return ttls
+}
+
+// newRedisCache 创建Redis缓存
+func (c *Container) newRedisCache() (cache.Cache, error) {
+	appLogger := c.Logger.GetLogger("app")
+
 	// 创建Redis缓存配置
 	redisConfig := &cache.RedisConfig{
 		Host:         c.Config.Redis.Host,
 		Port:         c.Config.Redis.Port,
 		Password:     c.Config.Redis.Password,
 		DB:           c.Config.Redis.DB,
-		Prefix:       "golang_template:",
+		Prefix:       cacheKeyPrefix,
 		PoolSize:     c.Config.Redis.PoolSize,
 		MinIdleConns: 5,
 		DialTimeout:  5 * time.Second,
@@ -31,29 +224,38 @@ func (c *Container) initializeCache() error {
 	// 初始化Redis缓存
 	redisCache, err := cache.NewRedisCache(redisConfig)
 	if err != nil {
-		return fmt.Errorf("初始化Redis缓存失败: %w", err)
+		return nil, fmt.Errorf("初始化Redis缓存失败: %w", err)
 	}
 
-	c.Cache = redisCache
-
 	appLogger.Info(context.Background(), "Redis缓存初始化成功",
 		logger.String("host", fmt.Sprintf("%s:%d", c.Config.Redis.Host, c.Config.Redis.Port)),
 		logger.Int("database", c.Config.Redis.DB),
 		logger.Int("pool_size", c.Config.Redis.PoolSize))
 
-	// 测试Redis连接
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	return redisCache, nil
+}
 
-	testKey := "startup_test"
-	if err := redisCache.Set(ctx, testKey, "test", 10*time.Second); err != nil {
-		appLogger.Warn(context.Background(), "Redis缓存测试操作失败",
-			logger.Error(err))
-		appLogger.Warn(context.Background(), "缓存可能不稳定 - 建议检查Redis配置")
-	} else {
-		redisCache.Delete(ctx, testKey) // 清理测试键
-		appLogger.Info(context.Background(), "Redis缓存连接验证成功")
+// newMemcachedCache 创建memcached缓存
+// memcached 不支持的能力（按模式列出键、查询剩余TTL等）由调用方通过 cache.Supports 检测后降级
+func (c *Container) newMemcachedCache() (cache.Cache, error) {
+	appLogger := c.Logger.GetLogger("app")
+	memcachedConfig := c.Config.Cache.Memcached
+
+	memcachedCache, err := cache.NewMemcachedCache(&cache.MemcachedConfig{
+		Servers:      memcachedConfig.Servers,
+		Prefix:       cacheKeyPrefix,
+		Timeout:      time.Duration(memcachedConfig.Timeout) * time.Millisecond,
+		MaxIdleConns: memcachedConfig.MaxIdleConns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("初始化memcached缓存失败: %w", err)
 	}
 
-	return nil
+	appLogger.Info(context.Background(), "memcached缓存初始化成功",
+		logger.String("servers", strings.Join(memcachedConfig.Servers, ",")),
+		logger.Int("max_idle_conns", memcachedConfig.MaxIdleConns),
+		logger.String("capabilities", cache.CapabilitiesOf(memcachedCache).String()))
+	appLogger.Warn(context.Background(), "memcached不支持按模式列出键，黑名单统计等依赖键扫描的功能将不可用")
+
+	return memcachedCache, nil
 }
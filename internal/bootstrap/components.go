@@ -0,0 +1,485 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"go-server/internal/database"
+	"go-server/internal/logger"
+	"go-server/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+// 内置组件名称，WithComponent 使用相同名称时替换对应组件
+const (
+	ComponentConfig         = "config"
+	ComponentLogger         = "logger"
+	ComponentShutdown       = "shutdown"
+	ComponentEncryption     = "encryption"
+	ComponentDatabase       = "database"
+	ComponentCache          = "cache"
+	ComponentPreflight      = "preflight"
+	ComponentEvents         = "events"
+	ComponentDomainEvents   = "domain_events"
+	ComponentStorage        = "storage"
+	ComponentHealth         = "health"
+	ComponentACME           = "acme"
+	ComponentMTLS           = "mtls"
+	ComponentAuth           = "auth"
+	ComponentRepositories   = "repositories"
+	ComponentServices       = "services"
+	ComponentCacheWarmer    = "cache_warmer"
+	ComponentFeatureFlags   = "feature_flags"
+	ComponentBanList        = "ban_list"
+	ComponentQuota          = "quota"
+	ComponentBilling        = "billing"
+	ComponentMaintenance    = "maintenance"
+	ComponentDrain          = "drain"
+	ComponentGeoIP          = "geoip"
+	ComponentNotifications  = "notifications"
+	ComponentAnnouncements  = "announcements"
+	ComponentExports        = "exports"
+	ComponentBackups        = "backups"
+	ComponentImports        = "imports"
+	ComponentPrivacy        = "privacy"
+	ComponentOIDC           = "oidc"
+	ComponentSAML           = "saml"
+	ComponentPasswordless   = "passwordless"
+	ComponentHandlers       = "handlers"
+	ComponentMiddlewares    = "middlewares"
+	ComponentRouter         = "router"
+	ComponentConfigHandlers = "config_handlers"
+)
+
+// Component 容器中的一个组件
+// Init 在 DependsOn 中的组件全部初始化后执行，把结果写入容器字段；
+// 需要在服务启动时运行的任务通过 c.OnStart 注册，关闭逻辑通过 c.OnStop 注册
+type Component struct {
+	Name        string
+	Description string   // 用于错误信息，如"数据库"
+	DependsOn   []string // 依赖的组件名称，只影响初始化顺序
+	Optional    bool     // 初始化失败时记录警告并继续，而不是终止启动
+	Init        func(c *Container) error
+}
+
+// Option 容器选项
+type Option func(*containerOptions)
+
+// containerOptions 创建容器时收集的选项
+type containerOptions struct {
+	components []Component
+}
+
+// WithComponent 添加组件，名称与已有组件相同时替换该组件
+// 测试可以借此换用模拟实现，而不需要修改 bootstrap 代码
+func WithComponent(component Component) Option {
+	return func(o *containerOptions) {
+		for i, existing := range o.components {
+			if existing.Name == component.Name {
+				o.components[i] = component
+				return
+			}
+		}
+		o.components = append(o.components, component)
+	}
+}
+
+// WithCache 使用指定的缓存替换 Redis/memcached，缓存由调用方负责关闭
+func WithCache(appCache cache.Cache) Option {
+	return WithComponent(Component{
+		Name:        ComponentCache,
+		Description: "缓存",
+		DependsOn:   []string{ComponentLogger},
+		Init: func(c *Container) error {
+			c.Cache = appCache
+			return nil
+		},
+	})
+}
+
+// WithDatabase 使用已建立的数据库连接，连接由调用方负责关闭
+func WithDatabase(db *database.Database) Option {
+	return WithComponent(Component{
+		Name:        ComponentDatabase,
+		Description: "数据库",
+		DependsOn:   []string{ComponentLogger},
+		Init: func(c *Container) error {
+			c.Database = db
+			return nil
+		},
+	})
+}
+
+// WithDialector 使用指定的 GORM 方言（如 SQLite）连接数据库，其余初始化步骤与 PostgreSQL 相同
+func WithDialector(dialector gorm.Dialector) Option {
+	return WithComponent(Component{
+		Name:        ComponentDatabase,
+		Description: "数据库",
+		DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentShutdown, ComponentEncryption},
+		Init: func(c *Container) error {
+			return c.initializeDatabase(dialector)
+		},
+	})
+}
+
+// defaultComponents 返回内置组件，列表顺序即依赖相同时的初始化顺序
+func defaultComponents() []Component {
+	return []Component{
+		{
+			Name:        ComponentConfig,
+			Description: "配置",
+			Init:        (*Container).initializeConfig,
+		},
+		{
+			Name:        ComponentLogger,
+			Description: "日志系统",
+			DependsOn:   []string{ComponentConfig},
+			Init:        (*Container).initializeLogger,
+		},
+		{
+			// 创建关闭管理器，后续组件在初始化时注册关闭钩子
+			Name:        ComponentShutdown,
+			Description: "关闭管理器",
+			DependsOn:   []string{ComponentConfig, ComponentLogger},
+			Init: func(c *Container) error {
+				c.initializeShutdown()
+				return nil
+			},
+		},
+		{
+			// 字段加密的密钥环在数据库之前安装，迁移和之后的读写都使用该密钥环
+			Name:        ComponentEncryption,
+			Description: "字段加密",
+			DependsOn:   []string{ComponentConfig, ComponentLogger},
+			Init:        (*Container).initializeEncryption,
+		},
+		{
+			Name:        ComponentDatabase,
+			Description: "数据库",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentShutdown, ComponentEncryption},
+			Init: func(c *Container) error {
+				return c.initializeDatabase(nil)
+			},
+		},
+		{
+			// 缓存不可用时在没有缓存的情况下运行
+			Name:        ComponentCache,
+			Description: "缓存",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentShutdown},
+			Optional:    true,
+			Init:        (*Container).initializeCache,
+		},
+		{
+			// 数据库和缓存连接后立即检查依赖，有问题时在接收请求前终止启动
+			Name:        ComponentPreflight,
+			Description: "启动检查",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentDatabase, ComponentCache},
+			Init:        (*Container).runPreflight,
+		},
+		{
+			// 事件总线不可用时使用空操作总线，事件将被丢弃
+			Name:        ComponentEvents,
+			Description: "事件总线",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentShutdown},
+			Optional:    true,
+			Init:        (*Container).initializeEvents,
+		},
+		{
+			Name:        ComponentDomainEvents,
+			Description: "领域事件分发器",
+			DependsOn:   []string{ComponentEvents},
+			Init: func(c *Container) error {
+				c.initializeDomainEvents()
+				return nil
+			},
+		},
+		{
+			// 对象存储不可用时上传接口返回服务不可用
+			Name:        ComponentStorage,
+			Description: "对象存储",
+			DependsOn:   []string{ComponentConfig, ComponentLogger},
+			Optional:    true,
+			Init:        (*Container).initializeStorage,
+		},
+		{
+			Name:        ComponentHealth,
+			Description: "健康检查",
+			DependsOn:   []string{ComponentDatabase, ComponentCache, ComponentEvents, ComponentStorage},
+			Init: func(c *Container) error {
+				c.initializeHealth()
+				return nil
+			},
+		},
+		{
+			Name:        ComponentACME,
+			Description: "自动 TLS 证书",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentCache, ComponentStorage, ComponentHealth},
+			Init:        (*Container).initializeACME,
+		},
+		{
+			Name:        ComponentMTLS,
+			Description: "mTLS",
+			DependsOn:   []string{ComponentConfig, ComponentLogger},
+			Init:        (*Container).initializeMTLS,
+		},
+		{
+			Name:        ComponentDrain,
+			Description: "实例排空",
+			DependsOn:   []string{ComponentHealth, ComponentShutdown},
+			Init: func(c *Container) error {
+				c.initializeDrain()
+				return nil
+			},
+		},
+		{
+			Name:        ComponentAuth,
+			Description: "认证服务",
+			DependsOn:   []string{ComponentCache, ComponentShutdown, ComponentHealth},
+			Init:        (*Container).initializeAuth,
+		},
+		{
+			Name:        ComponentRepositories,
+			Description: "仓储层",
+			DependsOn:   []string{ComponentDatabase, ComponentCache},
+			Init:        (*Container).initializeRepositories,
+		},
+		{
+			Name:        ComponentServices,
+			Description: "服务层",
+			DependsOn:   []string{ComponentRepositories, ComponentAuth, ComponentDomainEvents, ComponentStorage},
+			Init:        (*Container).initializeServices,
+		},
+		{
+			Name:        ComponentCacheWarmer,
+			Description: "缓存预热",
+			DependsOn:   []string{ComponentServices},
+			Init: func(c *Container) error {
+				c.initializeCacheWarmer()
+				return nil
+			},
+		},
+		{
+			Name:        ComponentFeatureFlags,
+			Description: "功能开关",
+			DependsOn:   []string{ComponentCache, ComponentShutdown},
+			Init: func(c *Container) error {
+				c.initializeFeatureFlags()
+				return nil
+			},
+		},
+		{
+			Name:        ComponentBanList,
+			Description: "自动封禁名单",
+			DependsOn:   []string{ComponentCache, ComponentShutdown},
+			Init: func(c *Container) error {
+				c.initializeBanList()
+				return nil
+			},
+		},
+		{
+			Name:        ComponentBilling,
+			Description: "订阅套餐",
+			DependsOn:   []string{ComponentServices, ComponentCache},
+			Init:        (*Container).initializeBilling,
+		},
+		{
+			Name:        ComponentMaintenance,
+			Description: "维护模式",
+			DependsOn:   []string{ComponentCache, ComponentHealth},
+			Init: func(c *Container) error {
+				c.initializeMaintenance()
+				return nil
+			},
+		},
+		{
+			// 订阅套餐的配额覆盖默认配额
+			Name:        ComponentQuota,
+			Description: "用量配额",
+			DependsOn:   []string{ComponentCache, ComponentBilling},
+			Init: func(c *Container) error {
+				c.initializeQuota()
+				return nil
+			},
+		},
+		{
+			// 数据库文件不可用时不查询地理位置
+			Name:        ComponentGeoIP,
+			Description: "GeoIP 查询",
+			DependsOn:   []string{ComponentConfig, ComponentLogger, ComponentShutdown},
+			Optional:    true,
+			Init:        (*Container).initializeGeoIP,
+		},
+		{
+			Name:        ComponentNotifications,
+			Description: "通知服务",
+			DependsOn:   []string{ComponentServices, ComponentEvents, ComponentDomainEvents, ComponentShutdown},
+			Init:        (*Container).initializeNotifications,
+		},
+		{
+			Name:        ComponentAnnouncements,
+			Description: "公告服务",
+			DependsOn:   []string{ComponentServices, ComponentShutdown},
+			Init:        (*Container).initializeAnnouncements,
+		},
+		{
+			Name:        ComponentExports,
+			Description: "数据导出",
+			DependsOn:   []string{ComponentRepositories, ComponentStorage, ComponentShutdown},
+			Init:        (*Container).initializeExports,
+		},
+		{
+			Name:        ComponentBackups,
+			Description: "数据库备份",
+			DependsOn:   []string{ComponentDatabase, ComponentStorage, ComponentCache, ComponentShutdown},
+			Init:        (*Container).initializeBackups,
+		},
+		{
+			Name:        ComponentImports,
+			Description: "批量导入",
+			DependsOn:   []string{ComponentRepositories, ComponentAuth},
+			Init:        (*Container).initializeImports,
+		},
+		{
+			Name:        ComponentPrivacy,
+			Description: "个人数据",
+			DependsOn:   []string{ComponentRepositories, ComponentStorage, ComponentAuth, ComponentNotifications},
+			Init:        (*Container).initializePrivacy,
+		},
+		{
+			Name:        ComponentOIDC,
+			Description: "OpenID Connect",
+			DependsOn:   []string{ComponentServices, ComponentCache, ComponentAuth},
+			Init:        (*Container).initializeOIDC,
+		},
+		{
+			Name:        ComponentSAML,
+			Description: "SAML 单点登录",
+			DependsOn:   []string{ComponentServices, ComponentCache, ComponentAuth},
+			Init:        (*Container).initializeSAML,
+		},
+		{
+			Name:        ComponentPasswordless,
+			Description: "无密码登录",
+			DependsOn:   []string{ComponentServices, ComponentCache, ComponentAuth, ComponentEvents},
+			Init:        (*Container).initializePasswordless,
+		},
+		{
+			Name:        ComponentHandlers,
+			Description: "处理器层",
+			DependsOn:   []string{ComponentServices, ComponentHealth, ComponentCacheWarmer, ComponentFeatureFlags, ComponentBanList, ComponentGeoIP, ComponentNotifications, ComponentAnnouncements, ComponentExports, ComponentBackups, ComponentImports, ComponentPrivacy, ComponentOIDC, ComponentSAML, ComponentPasswordless, ComponentQuota, ComponentBilling, ComponentMaintenance, ComponentDrain},
+			Init:        (*Container).initializeHandlers,
+		},
+		{
+			Name:        ComponentMiddlewares,
+			Description: "中间件",
+			DependsOn:   []string{ComponentAuth, ComponentRepositories, ComponentFeatureFlags, ComponentBanList, ComponentGeoIP, ComponentHealth, ComponentBilling, ComponentMaintenance, ComponentDrain},
+			Init:        (*Container).setupMiddlewares,
+		},
+		{
+			Name:        ComponentRouter,
+			Description: "路由",
+			DependsOn:   []string{ComponentHandlers, ComponentMiddlewares, ComponentMTLS},
+			Init:        (*Container).initializeRouter,
+		},
+		{
+			// 注册配置变更处理器，配置文件监控在 Start 时启动
+			Name:        ComponentConfigHandlers,
+			Description: "配置变更处理器",
+			DependsOn:   []string{ComponentRouter},
+			Init: func(c *Container) error {
+				c.registerConfigHandlers()
+				c.OnStart("config_watcher", c.startConfigWatcher)
+				return nil
+			},
+		},
+	}
+}
+
+// resolveComponents 按依赖关系排序组件，依赖相同时保持列表顺序
+// 依赖不存在或存在循环依赖时返回错误
+func resolveComponents(components []Component) ([]Component, error) {
+	index := make(map[string]int, len(components))
+	for i, component := range components {
+		if _, exists := index[component.Name]; exists {
+			return nil, fmt.Errorf("组件 %s 重复注册", component.Name)
+		}
+		index[component.Name] = i
+	}
+
+	pending := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for i, component := range components {
+		for _, dependency := range component.DependsOn {
+			j, ok := index[dependency]
+			if !ok {
+				return nil, fmt.Errorf("组件 %s 依赖的组件 %s 不存在", component.Name, dependency)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ordered := make([]Component, 0, len(components))
+	done := make([]bool, len(components))
+	for len(ordered) < len(components) {
+		// 每次选择列表中最靠前的、依赖已全部就绪的组件
+		next := -1
+		for i := range components {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var blocked []string
+			for i, component := range components {
+				if !done[i] {
+					blocked = append(blocked, component.Name)
+				}
+			}
+			return nil, fmt.Errorf("组件存在循环依赖: %v", blocked)
+		}
+
+		done[next] = true
+		ordered = append(ordered, components[next])
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+		}
+	}
+	return ordered, nil
+}
+
+// initializeComponents 按依赖顺序初始化组件
+func (c *Container) initializeComponents(components []Component) error {
+	ordered, err := resolveComponents(components)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range ordered {
+		if component.Init == nil {
+			continue
+		}
+		err := component.Init(c)
+		if err == nil {
+			continue
+		}
+
+		description := component.Description
+		if description == "" {
+			description = component.Name
+		}
+		// 日志系统初始化之前的组件不能是可选的
+		if !component.Optional || c.Logger == nil {
+			return fmt.Errorf("初始化%s失败: %w", description, err)
+		}
+		c.Logger.GetLogger("app").Warn(
+			context.Background(),
+			fmt.Sprintf("%s初始化失败，将在没有该组件的情况下运行", description),
+			logger.String("component", component.Name),
+			logger.Error(err),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-server/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCache 用于替换缓存组件的空实现
+type stubCache struct {
+	cache.Cache
+}
+
+// componentNames 返回组件名称列表
+func componentNames(components []Component) []string {
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = component.Name
+	}
+	return names
+}
+
+func TestResolveComponents_Order(t *testing.T) {
+	ordered, err := resolveComponents([]Component{
+		{Name: "router", DependsOn: []string{"handlers"}},
+		{Name: "config"},
+		{Name: "handlers", DependsOn: []string{"services"}},
+		{Name: "services", DependsOn: []string{"config"}},
+		{Name: "logger", DependsOn: []string{"config"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config", "services", "handlers", "router", "logger"}, componentNames(ordered))
+}
+
+func TestResolveComponents_Errors(t *testing.T) {
+	_, err := resolveComponents([]Component{{Name: "a", DependsOn: []string{"missing"}}})
+	assert.ErrorContains(t, err, "missing")
+
+	_, err = resolveComponents([]Component{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c"},
+	})
+	assert.ErrorContains(t, err, "循环依赖")
+
+	_, err = resolveComponents([]Component{{Name: "a"}, {Name: "a"}})
+	assert.ErrorContains(t, err, "重复注册")
+}
+
+func TestDefaultComponents_Resolve(t *testing.T) {
+	ordered, err := resolveComponents(defaultComponents())
+	require.NoError(t, err)
+
+	position := make(map[string]int)
+	for i, component := range ordered {
+		position[component.Name] = i
+	}
+	for _, component := range ordered {
+		for _, dependency := range component.DependsOn {
+			assert.Less(t, position[dependency], position[component.Name], "%s 应在 %s 之前初始化", dependency, component.Name)
+		}
+	}
+}
+
+func TestWithCache_ReplacesComponent(t *testing.T) {
+	mockCache := &stubCache{}
+	options := &containerOptions{components: defaultComponents()}
+	WithCache(mockCache)(options)
+	WithComponent(Component{Name: "extra", DependsOn: []string{ComponentCache}})(options)
+
+	assert.Len(t, options.components, len(defaultComponents())+1)
+
+	c := &Container{}
+	for _, component := range options.components {
+		if component.Name == ComponentCache {
+			require.NoError(t, component.Init(c))
+		}
+	}
+	assert.Same(t, mockCache, c.Cache)
+}
+
+func TestInitializeComponents(t *testing.T) {
+	c := &Container{}
+	var order []string
+	record := func(name string, err error) func(*Container) error {
+		return func(c *Container) error {
+			order = append(order, name)
+			c.OnStart(name, func(ctx context.Context) error {
+				order = append(order, "start:"+name)
+				return nil
+			})
+			return err
+		}
+	}
+
+	err := c.initializeComponents([]Component{
+		{Name: "b", DependsOn: []string{"a"}, Init: record("b", nil)},
+		{Name: "a", Init: record("a", nil)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background()))
+	require.NoError(t, c.Start(context.Background()), "重复调用不再执行钩子")
+	assert.Equal(t, []string{"a", "b", "start:a", "start:b"}, order)
+
+	err = (&Container{}).initializeComponents([]Component{
+		{Name: "database", Description: "数据库", Init: record("database", errors.New("connection refused"))},
+	})
+	assert.EqualError(t, err, "初始化数据库失败: connection refused")
+}
@@ -3,9 +3,15 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/internal/middleware"
+	"go-server/internal/services"
+	"go-server/pkg/fieldcrypt"
+	"go-server/pkg/response"
 )
 
 // initializeConfig 初始化配置管理器
@@ -22,10 +28,135 @@ func (c *Container) initializeConfig() error {
 	return nil
 }
 
+// registerConfigSubscribers 注册支持热重载的配置订阅者
+// 新配置需先通过所有订阅者校验，然后原子切换并依次应用
+func (c *Container) registerConfigSubscribers() {
+	// 日志配置：更新请求和响应体记录策略，输出相关配置变化时重建日志记录器
+	c.ConfigManager.Subscribe(config.NewSubscriber("logging", nil,
+		func(oldConfig, newConfig *config.Config) error {
+			middleware.UpdateBodyCaptureConfig(newConfig.Logging.BodyCapture)
+
+			oldLogging, newLogging := oldConfig.Logging, newConfig.Logging
+			oldLogging.BodyCapture, newLogging.BodyCapture = config.LoggingBodyCaptureConfig{}, config.LoggingBodyCaptureConfig{}
+			if reflect.DeepEqual(oldLogging, newLogging) {
+				return nil
+			}
+			return c.Logger.UpdateConfig(newConfig.Logging)
+		}))
+
+	// 跨域、速率限制和压缩：原子替换中间件参数，仅影响新请求
+	if c.CORS != nil {
+		c.ConfigManager.Subscribe(c.CORS)
+	}
+	if c.BodyLimiter != nil {
+		c.ConfigManager.Subscribe(c.BodyLimiter)
+	}
+	if c.MaintenanceGate != nil {
+		c.ConfigManager.Subscribe(c.MaintenanceGate)
+	}
+	if c.RateLimiter != nil {
+		c.ConfigManager.Subscribe(c.RateLimiter)
+	}
+	if c.Compressor != nil {
+		c.ConfigManager.Subscribe(c.Compressor)
+	}
+
+	// 缓存过期时间：仅影响新写入的缓存项
+	if setter, ok := c.UserService.(services.CacheTTLSetter); ok && c.Cache != nil {
+		c.ConfigManager.Subscribe(config.NewSubscriber("cache_ttl", nil,
+			func(oldConfig, newConfig *config.Config) error {
+				setter.SetCacheTTL(time.Duration(newConfig.Redis.CacheTTL) * time.Second)
+				return nil
+			}))
+	}
+	if setter, ok := c.UserService.(services.NegativeCacheTTLSetter); ok && c.Cache != nil {
+		c.ConfigManager.Subscribe(config.NewSubscriber("negative_cache_ttl", nil,
+			func(oldConfig, newConfig *config.Config) error {
+				setter.SetNegativeCacheTTL(time.Duration(newConfig.Redis.NegativeCacheTTL) * time.Second)
+				return nil
+			}))
+	}
+	if setter, ok := c.UserService.(services.CacheMethodTTLSetter); ok && c.Cache != nil {
+		c.ConfigManager.Subscribe(config.NewSubscriber("cache_method_ttls", nil,
+			func(oldConfig, newConfig *config.Config) error {
+				setter.SetCacheMethodTTLs(cacheMethodTTLs(newConfig))
+				return nil
+			}))
+	}
+
+	// JWT签名密钥：新令牌立即使用新密钥签名，原活动密钥在宽限期内仍可验证已签发的令牌
+	if c.JWTManager != nil {
+		appLogger := c.Logger.GetLogger("config")
+		c.ConfigManager.Subscribe(config.NewSubscriber("jwt_keys",
+			func(newConfig *config.Config) error {
+				_, _, err := jwtSigningKeys(newConfig.JWT)
+				return err
+			},
+			func(oldConfig, newConfig *config.Config) error {
+				if jwtKeysEqual(oldConfig.JWT, newConfig.JWT) {
+					return nil
+				}
+
+				active, previous, err := jwtSigningKeys(newConfig.JWT)
+				if err != nil {
+					return err
+				}
+				grace := jwtRotationGrace(newConfig.JWT)
+				if err := c.JWTManager.Keys().Reload(active, previous, grace); err != nil {
+					return err
+				}
+				appLogger.Info(context.Background(), "JWT签名密钥已更新",
+					logger.String("algorithm", active.Algorithm),
+					logger.String("active_kid", active.ID),
+					logger.Any("kids", c.JWTManager.Keys().IDs()),
+					logger.String("grace_period", grace.String()))
+				return nil
+			}))
+	}
+
+	// 字段加密密钥：外部密钥后端中新增密钥版本或切换当前版本后立即生效，开关和盲索引密钥需要重启
+	if fieldcrypt.Enabled() {
+		appLogger := c.Logger.GetLogger("config")
+		c.ConfigManager.Subscribe(config.NewSubscriber("encryption",
+			func(newConfig *config.Config) error {
+				_, err := rotatedKeyring(fieldcrypt.Current(), newConfig.Encryption)
+				return err
+			},
+			func(oldConfig, newConfig *config.Config) error {
+				if oldConfig.Encryption.Enabled != newConfig.Encryption.Enabled ||
+					oldConfig.Encryption.BlindIndexKey != newConfig.Encryption.BlindIndexKey {
+					appLogger.Warn(context.Background(), "字段加密开关和盲索引密钥更改需要重启服务才能生效")
+				}
+				if oldConfig.Encryption.Keys == newConfig.Encryption.Keys && oldConfig.Encryption.ActiveKey == newConfig.Encryption.ActiveKey {
+					return nil
+				}
+
+				keyring, err := rotatedKeyring(fieldcrypt.Current(), newConfig.Encryption)
+				if err != nil {
+					return err
+				}
+				fieldcrypt.Install(keyring)
+				appLogger.Info(context.Background(), "字段加密密钥已更新",
+					logger.Int("active_key", int(keyring.ActiveVersion())),
+					logger.Any("key_versions", keyring.Versions()))
+				return nil
+			}))
+	}
+}
+
 // registerConfigHandlers 注册配置变更处理器
 func (c *Container) registerConfigHandlers() {
 	appLogger := c.Logger.GetLogger("config")
 
+	c.registerConfigSubscribers()
+
+	// 密钥轮换回调（不记录密钥值）
+	c.ConfigManager.OnSecretRotation(func(ctx context.Context, rotation config.SecretRotation) {
+		appLogger.Info(ctx, "外部密钥已轮换",
+			logger.String("field", rotation.Field),
+			logger.String("reference", rotation.Reference))
+	})
+
 	// 日志配置变更处理器
 	c.ConfigManager.RegisterHandler(config.ConfigChangeTypeLogging,
 		func(ctx context.Context, change config.ConfigChange) {
@@ -38,12 +169,11 @@ func (c *Container) registerConfigHandlers() {
 				logger.String("old_format", oldConfig.Format),
 				logger.String("new_format", newConfig.Format),
 				logger.String("old_output", oldConfig.Output),
-				logger.String("new_output", newConfig.Output))
-
-			// 更新日志管理器配置
-			if err := c.Logger.UpdateConfig(newConfig); err != nil {
-				appLogger.Error(ctx, "更新日志配置失败", logger.Error(err))
-			}
+				logger.String("new_output", newConfig.Output),
+				logger.Bool("old_body_capture", oldConfig.BodyCapture.Enabled),
+				logger.Bool("new_body_capture", newConfig.BodyCapture.Enabled),
+				logger.Bool("old_sampling", oldConfig.Sampling.Enabled),
+				logger.Bool("new_sampling", newConfig.Sampling.Enabled))
 		})
 
 	// 速率限制配置变更处理器
@@ -59,6 +189,10 @@ func (c *Container) registerConfigHandlers() {
 				logger.Int("new_requests", newConfig.Requests),
 				logger.String("old_window", oldConfig.Window),
 				logger.String("new_window", newConfig.Window))
+
+			if oldConfig.RedisKey != newConfig.RedisKey {
+				appLogger.Warn(ctx, "速率限制Redis键名前缀更改需要重启服务才能生效")
+			}
 		})
 
 	// 服务器配置变更处理器
@@ -77,7 +211,19 @@ func (c *Container) registerConfigHandlers() {
 				logger.Int("old_write_timeout", oldConfig.WriteTimeout),
 				logger.Int("new_write_timeout", newConfig.WriteTimeout))
 
-			appLogger.Warn(ctx, "服务器配置更改（主机/端口）需要重启应用程序才能生效")
+			// 错误响应格式立即生效，主机、端口和超时需要重启
+			response.ConfigureErrorFormat(newConfig.ErrorFormat, newConfig.ProblemTypeBaseURL)
+			if oldConfig.ErrorFormat != newConfig.ErrorFormat || oldConfig.ProblemTypeBaseURL != newConfig.ProblemTypeBaseURL {
+				appLogger.Info(ctx, "错误响应格式已更新",
+					logger.String("old_error_format", oldConfig.ErrorFormat),
+					logger.String("new_error_format", newConfig.ErrorFormat),
+					logger.String("problem_type_base_url", newConfig.ProblemTypeBaseURL))
+			}
+
+			if oldConfig.Host != newConfig.Host || oldConfig.Port != newConfig.Port ||
+				oldConfig.ReadTimeout != newConfig.ReadTimeout || oldConfig.WriteTimeout != newConfig.WriteTimeout {
+				appLogger.Warn(ctx, "服务器配置更改（主机/端口）需要重启应用程序才能生效")
+			}
 		})
 
 	// JWT配置变更处理器
@@ -90,8 +236,8 @@ func (c *Container) registerConfigHandlers() {
 				logger.Int("old_expires_in", oldConfig.ExpiresIn),
 				logger.Int("new_expires_in", newConfig.ExpiresIn))
 
-			if oldConfig.SecretKey != newConfig.SecretKey {
-				appLogger.Warn(ctx, "JWT密钥已更改 - 现有令牌将失效")
+			if oldConfig.ExpiresIn != newConfig.ExpiresIn || oldConfig.RefreshExpiresIn != newConfig.RefreshExpiresIn {
+				appLogger.Warn(ctx, "JWT过期时间更改需要重启应用程序才能生效")
 			}
 		})
 
@@ -109,9 +255,21 @@ func (c *Container) registerConfigHandlers() {
 				logger.Int("old_db", oldConfig.DB),
 				logger.Int("new_db", newConfig.DB),
 				logger.Int("old_pool_size", oldConfig.PoolSize),
-				logger.Int("new_pool_size", newConfig.PoolSize))
+				logger.Int("new_pool_size", newConfig.PoolSize),
+				logger.Int("old_cache_ttl", oldConfig.CacheTTL),
+				logger.Int("new_cache_ttl", newConfig.CacheTTL),
+				logger.Int("old_negative_cache_ttl", oldConfig.NegativeCacheTTL),
+				logger.Int("new_negative_cache_ttl", newConfig.NegativeCacheTTL))
 
-			appLogger.Warn(ctx, "Redis连接更改需要重启服务才能生效")
+			// 缓存过期时间支持热重载，连接参数需要重启
+			connectionChanged := oldConfig.Host != newConfig.Host ||
+				oldConfig.Port != newConfig.Port ||
+				oldConfig.Password != newConfig.Password ||
+				oldConfig.DB != newConfig.DB ||
+				oldConfig.PoolSize != newConfig.PoolSize
+			if connectionChanged {
+				appLogger.Warn(ctx, "Redis连接更改需要重启服务才能生效")
+			}
 		})
 
 	// 数据库配置变更处理器
@@ -139,9 +297,29 @@ func (c *Container) registerConfigHandlers() {
 
 			appLogger.Info(ctx, "认证配置已更改",
 				logger.Int("old_bcrypt_cost", oldConfig.BcryptCost),
-				logger.Int("new_bcrypt_cost", newConfig.BcryptCost))
+				logger.Int("new_bcrypt_cost", newConfig.BcryptCost),
+				logger.String("old_password_algorithm", oldConfig.PasswordAlgorithm),
+				logger.String("new_password_algorithm", newConfig.PasswordAlgorithm))
+
+			if c.PasswordHasher != nil && passwordPolicy(oldConfig) != passwordPolicy(newConfig) {
+				if err := c.PasswordHasher.SetPolicy(passwordPolicy(newConfig)); err != nil {
+					appLogger.Error(ctx, "更新密码哈希策略失败，继续使用原策略", logger.Error(err))
+				} else {
+					appLogger.Info(ctx, "密码哈希策略已更新，新密码使用新策略，已有密码在下次登录成功时重新哈希")
+				}
+			}
 
-			appLogger.Info(ctx, "认证配置更改仅影响新密码操作")
+			if c.PasswordChecker != nil && !reflect.DeepEqual(oldConfig.PasswordPolicy, newConfig.PasswordPolicy) {
+				rules, breaches, err := passwordRules(newConfig.PasswordPolicy)
+				if err != nil {
+					appLogger.Error(ctx, "更新密码策略失败，继续使用原策略", logger.Error(err))
+					return
+				}
+				c.PasswordChecker.Update(rules, breaches)
+				appLogger.Info(ctx, "密码策略已更新，只影响之后的注册和修改密码",
+					logger.Int("min_length", rules.MinLength),
+					logger.Bool("breach_check", breaches != nil))
+			}
 		})
 
 	// 压缩配置变更处理器
@@ -160,4 +338,35 @@ func (c *Container) registerConfigHandlers() {
 				logger.Bool("enabled", newConfig.Enabled),
 				logger.Int("threshold", newConfig.Threshold))
 		})
+
+	// 请求体大小限制配置变更处理器
+	c.ConfigManager.RegisterHandler(config.ConfigChangeTypeBodyLimit,
+		func(ctx context.Context, change config.ConfigChange) {
+			oldConfig := change.OldValue.(config.BodyLimitConfig)
+			newConfig := change.NewValue.(config.BodyLimitConfig)
+
+			appLogger.Info(ctx, "请求体大小限制配置已更改",
+				logger.Bool("old_enabled", oldConfig.Enabled),
+				logger.Bool("new_enabled", newConfig.Enabled),
+				logger.Int64("old_max_bytes", oldConfig.MaxBytes),
+				logger.Int64("new_max_bytes", newConfig.MaxBytes),
+				logger.Int64("old_multipart_max_bytes", oldConfig.MultipartMaxBytes),
+				logger.Int64("new_multipart_max_bytes", newConfig.MultipartMaxBytes),
+				logger.Int("new_route_rules", len(newConfig.Routes)))
+		})
+
+	// 跨域配置变更处理器
+	c.ConfigManager.RegisterHandler(config.ConfigChangeTypeCORS,
+		func(ctx context.Context, change config.ConfigChange) {
+			oldConfig := change.OldValue.(config.CORSConfig)
+			newConfig := change.NewValue.(config.CORSConfig)
+
+			appLogger.Info(ctx, "跨域配置已更改",
+				logger.Bool("old_enabled", oldConfig.Enabled),
+				logger.Bool("new_enabled", newConfig.Enabled),
+				logger.Any("old_allowed_origins", oldConfig.AllowedOrigins),
+				logger.Any("new_allowed_origins", newConfig.AllowedOrigins),
+				logger.Bool("old_allow_credentials", oldConfig.AllowCredentials),
+				logger.Bool("new_allow_credentials", newConfig.AllowCredentials))
+		})
 }
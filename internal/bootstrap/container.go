@@ -2,18 +2,47 @@ package bootstrap
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
 	"log"
+	"sync"
 
+	"go-server/internal/announcements"
+	"go-server/internal/audit"
+	"go-server/internal/backups"
+	"go-server/internal/billing"
 	"go-server/internal/config"
 	"go-server/internal/database"
+	domainevents "go-server/internal/events"
+	"go-server/internal/exports"
 	"go-server/internal/handlers"
+	"go-server/internal/imports"
 	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/internal/middleware"
+	"go-server/internal/notifications"
+	"go-server/internal/oidc"
+	"go-server/internal/passwordless"
+	"go-server/internal/privacy"
 	"go-server/internal/repositories"
+	"go-server/internal/retention"
 	"go-server/internal/routes"
 	"go-server/internal/services"
+	"go-server/internal/sso"
 	"go-server/pkg/auth"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/autotls"
+	"go-server/pkg/banlist"
 	"go-server/pkg/cache"
+	"go-server/pkg/drain"
+	"go-server/pkg/errorreporting"
+	"go-server/pkg/events"
+	"go-server/pkg/featureflags"
+	"go-server/pkg/geoip"
+	"go-server/pkg/health"
+	"go-server/pkg/maintenance"
+	"go-server/pkg/mtls"
+	"go-server/pkg/quota"
+	"go-server/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,141 +55,188 @@ type Container struct {
 	Config        *config.Config
 
 	// 核心组件
-	Logger   *logger.Manager
-	Database *database.Database
-	Cache    cache.Cache
+	Logger          *logger.Manager
+	Shutdown        *ShutdownManager
+	Database        *database.Database
+	Cache           cache.Cache
+	CacheSupervisor *cache.Supervisor // 未启用缓存健康监督时为 nil
+	EventBus        events.Bus
+	DomainEvents    *domainevents.Dispatcher
+	Storage         storage.Storage
+	MediaSigner     *storage.URLSigner // 未启用媒体签名链接时为 nil
+	ErrorReporter   errorreporting.Reporter
+	StartupReport   *StartupReport // 启动检查的结果，未启用启动检查时为 nil
+
+	// 健康检查和指标
+	HealthRegistry  *health.Registry
+	MetricsRegistry *metrics.Registry
+	HTTPMetrics     *metrics.HTTPMetrics
+	JWTMetrics      *metrics.JWTMetrics
+	AuthMetrics     *metrics.AuthMetrics
+	// 未启用指标快照或没有数据库时为 nil
+	MetricsSnapshotter *services.MetricsSnapshotter
+	// 未启用最后登录时间批量写入或没有缓存时为 nil
+	LastLoginFlusher *services.LastLoginFlusher
+
+	// 部署前排空实例
+	DrainTracker *drain.Tracker
+	Drainer      *drain.Drainer
 
 	// 认证和授权
 	JWTManager       *auth.JWTManager
 	BlacklistService *cache.BlacklistService
+	PasswordHasher   *password.Manager
+	PasswordChecker  *password.Checker
+
+	// 缓存预热
+	CacheWarmer *cache.Warmer
+
+	// 功能开关
+	FeatureFlags *featureflags.Manager
+
+	// 自动封禁名单，未启用时为 nil
+	BanList *banlist.BanList
+
+	// 用量配额，未启用时为 nil
+	Quota *quota.Manager
+
+	// IP 地理位置查询，未启用或数据库不可用时为 nil
+	GeoIP *geoip.Reader
+
+	// 自动 TLS 证书，未启用时为 nil
+	CertManager *autotls.Manager
+
+	// 内部服务调用的 mTLS 监听器配置和服务账户，未启用时为 nil
+	MTLSConfig        *tls.Config
+	ServiceAuthorizer *mtls.Authorizer
 
 	// 仓储层
-	UserRepository repositories.UserRepository
+	UserRepository   repositories.UserRepository
+	TenantRepository repositories.TenantRepository
 
 	// 服务层
 	UserService services.UserService
 
-	// 处理器层
-	AuthHandler   *handlers.AuthHandler
-	UserHandler   *handlers.UserHandler
-	HealthHandler *handlers.HealthHandler
-
-	// 中间件和路由
-	Middlewares []gin.HandlerFunc
-	Router      *routes.Router
-}
+	// 审计
+	AuditRecorder audit.Recorder
 
-// NewContainer 创建并初始化应用容器
-// 按照依赖顺序初始化所有组件：配置 -> 日志 -> 数据库 -> 缓存 -> 服务 -> 处理器
-func NewContainer() (*Container, error) {
-	c := &Container{}
+	// 通知服务，未启用或没有数据库时为 nil
+	Notifications *notifications.Service
 
-	// 1. 初始化配置管理器
-	if err := c.initializeConfig(); err != nil {
-		return nil, fmt.Errorf("初始化配置失败: %w", err)
-	}
+	// 公告服务，未启用或没有数据库时为 nil
+	Announcements *announcements.Service
 
-	// 2. 初始化日志系统
-	if err := c.initializeLogger(); err != nil {
-		return nil, fmt.Errorf("初始化日志系统失败: %w", err)
-	}
+	// 数据导出服务，未启用、没有数据库或对象存储不可用时为 nil
+	Exports *exports.Service
 
-	// 3. 初始化数据库
-	if err := c.initializeDatabase(); err != nil {
-		return nil, fmt.Errorf("初始化数据库失败: %w", err)
-	}
+	// 批量导入服务，未启用或没有数据库时为 nil
+	Imports *imports.Service
 
-	// 4. 初始化缓存（Redis）
-	if err := c.initializeCache(); err != nil {
-		// 缓存初始化失败不是致命错误，记录警告后继续
-		c.Logger.GetLogger("app").Warn(
-			context.Background(),
-			"缓存初始化失败，将在没有缓存的情况下运行",
-			logger.Error(err),
-		)
-	}
+	// 个人数据服务，没有数据库时为 nil
+	Privacy *privacy.Service
 
-	// 5. 初始化JWT和黑名单服务
-	if err := c.initializeAuth(); err != nil {
-		return nil, fmt.Errorf("初始化认证服务失败: %w", err)
-	}
+	// OpenID Connect 提供方，未启用或没有数据库时为 nil
+	OIDC *oidc.Service
 
-	// 6. 初始化仓储层
-	if err := c.initializeRepositories(); err != nil {
-		return nil, fmt.Errorf("初始化仓储层失败: %w", err)
-	}
+	// SAML 单点登录服务，未启用或没有数据库时为 nil
+	SAML *sso.Service
 
-	// 7. 初始化服务层
-	if err := c.initializeServices(); err != nil {
-		return nil, fmt.Errorf("初始化服务层失败: %w", err)
-	}
+	// 无密码登录服务，未启用或没有数据库时为 nil
+	Passwordless *passwordless.Service
 
-	// 8. 初始化处理器层
-	if err := c.initializeHandlers(); err != nil {
-		return nil, fmt.Errorf("初始化处理器层失败: %w", err)
-	}
+	// 订阅服务，未启用或没有数据库时为 nil
+	Billing *billing.Service
 
-	// 9. 设置中间件
-	if err := c.setupMiddlewares(); err != nil {
-		return nil, fmt.Errorf("设置中间件失败: %w", err)
-	}
+	// 维护模式开关，未启用时为 nil
+	Maintenance *maintenance.Mode
 
-	// 10. 初始化路由
-	if err := c.initializeRouter(); err != nil {
-		return nil, fmt.Errorf("初始化路由失败: %w", err)
-	}
+	// 数据保留策略任务，未启用或没有数据库时为 nil
+	Retention *retention.Engine
 
-	// 11. 注册配置变更处理器
-	c.registerConfigHandlers()
+	// 数据库备份服务，未启用、没有数据库或对象存储不可用时为 nil
+	Backups *backups.Service
 
-	// 12. 启动配置文件监控
-	if err := c.ConfigManager.StartWatching(); err != nil {
-		c.Logger.GetLogger("app").Warn(
-			context.Background(),
-			"启动配置文件监控失败",
-			logger.Error(err),
-		)
-	}
+	// 处理器层
+	AuthHandler          *handlers.AuthHandler
+	UserHandler          *handlers.UserHandler
+	HealthHandler        *handlers.HealthHandler
+	AvatarHandler        *handlers.AvatarHandler
+	ProfileHandler       *handlers.ProfileHandler
+	AdminUserHandler     *handlers.AdminUserHandler
+	AdminOverviewHandler *handlers.AdminOverviewHandler
+	MetricsHandler       *handlers.MetricsHandler
+	LoggingHandler       *handlers.LoggingHandler
+	MetaHandler          *handlers.MetaHandler
+	CacheHandler         *handlers.CacheHandler
+	FeatureFlagHandler   *handlers.FeatureFlagHandler
+	BanListHandler       *handlers.BanListHandler
+	NotificationHandler  *handlers.NotificationHandler
+	AnnouncementHandler  *handlers.AnnouncementHandler
+	ExportHandler        *handlers.ExportHandler
+	ImportHandler        *handlers.ImportHandler
+	PrivacyHandler       *handlers.PrivacyHandler
+	OIDCHandler          *handlers.OIDCHandler
+	SAMLHandler          *handlers.SAMLHandler
+	PasswordlessHandler  *handlers.PasswordlessHandler
+	QuotaHandler         *handlers.QuotaHandler
+	BillingHandler       *handlers.BillingHandler
+	MaintenanceHandler   *handlers.MaintenanceHandler
+	DrainHandler         *handlers.DrainHandler
+	RateLimitHandler     *handlers.RateLimitHandler
+	RetentionHandler     *handlers.RetentionHandler
+	BackupHandler        *handlers.BackupHandler
 
-	return c, nil
+	// 中间件和路由
+	Middlewares     []gin.HandlerFunc
+	CORS            *middleware.CORS
+	BodyLimiter     *middleware.BodyLimiter
+	MaintenanceGate *middleware.Maintenance
+	Tenants         *middleware.TenantResolver
+	RateLimiter     *middleware.DistributedRateLimiter
+	Compressor      *middleware.Compressor
+	ResponseCache   *middleware.ResponseCache
+	Coalescer       *middleware.RequestCoalescer
+	Router          *routes.Router
+
+	// 启动钩子，由 Start 按注册顺序执行
+	startMu    sync.Mutex
+	startHooks []namedStartHook
+	started    bool
+
+	// 排空完成且配置了 exit_after_drain 时关闭，Run 收到后关闭服务并退出
+	drained chan struct{}
 }
 
-// Cleanup 清理所有资源
-func (c *Container) Cleanup() {
-	ctx := context.Background()
-	appLogger := c.Logger.GetLogger("app")
-
-	// 停止配置监控
-	if c.ConfigManager != nil {
-		c.ConfigManager.StopWatching()
-		appLogger.Info(ctx, "配置文件监控已停止")
+// NewContainer 创建并初始化应用容器
+// 各组件声明依赖关系，按依赖顺序初始化：配置 -> 日志 -> 数据库 -> 缓存 -> 事件 -> 存储 -> 服务 -> 处理器
+// 通过 WithComponent、WithCache、WithDatabase 等选项替换组件实现，通过 RegisterModule 或 WithModule 加载模块；
+// 后台任务在 Start 时启动
+func NewContainer(opts ...Option) (*Container, error) {
+	options := &containerOptions{components: defaultComponents()}
+	for _, m := range RegisteredModules() {
+		options.components = append(options.components, moduleComponent(m))
+	}
+	for _, opt := range opts {
+		opt(options)
 	}
 
-	// 关闭数据库连接
-	if c.Database != nil {
-		if err := c.Database.Close(); err != nil {
-			appLogger.Error(ctx, "关闭数据库连接失败", logger.Error(err))
-		} else {
-			appLogger.Info(ctx, "数据库连接已关闭")
-		}
+	c := &Container{}
+	if err := c.initializeComponents(options.components); err != nil {
+		return nil, err
 	}
 
-	// 关闭缓存连接
-	if c.Cache != nil {
-		if err := c.Cache.Close(); err != nil {
-			appLogger.Error(ctx, "关闭缓存连接失败", logger.Error(err))
-		} else {
-			appLogger.Info(ctx, "缓存连接已关闭")
-		}
+	return c, nil
+}
+
+// Cleanup 按阶段关闭所有组件，可重复调用
+// 顺序：停止接收请求 -> 排空HTTP请求 -> 停止后台任务 -> 关闭数据库/缓存 -> 刷新日志
+func (c *Container) Cleanup() {
+	if c.Shutdown == nil {
+		return
 	}
 
-	// 关闭日志系统
-	if c.Logger != nil {
-		if err := c.Logger.Stop(); err != nil {
-			log.Printf("关闭日志系统失败: %v", err)
-		} else {
-			log.Println("日志系统已关闭")
-		}
+	if err := c.Shutdown.Shutdown(context.Background()); err != nil {
+		log.Printf("应用程序关闭过程中出现错误: %v", err)
 	}
 }
 
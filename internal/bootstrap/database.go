@@ -6,20 +6,33 @@ import (
 
 	"go-server/internal/database"
 	"go-server/internal/logger"
+
+	"gorm.io/gorm"
 )
 
-// initializeDatabase 初始化数据库连接
-func (c *Container) initializeDatabase() error {
+// initializeDatabase 初始化数据库连接，dialector 为 nil 时按配置连接 PostgreSQL
+func (c *Container) initializeDatabase(dialector gorm.Dialector) error {
 	appLogger := c.Logger.GetLogger("app")
 
 	// 创建数据库连接
-	db, err := database.NewDatabase(c.Config, c.Logger)
+	var db *database.Database
+	var err error
+	if dialector != nil {
+		db, err = database.NewDatabaseWithDialector(c.Config, c.Logger, dialector)
+	} else {
+		db, err = database.NewDatabase(c.Config, c.Logger)
+	}
 	if err != nil {
 		return fmt.Errorf("连接数据库失败: %w", err)
 	}
 
 	c.Database = db
 
+	// 数据库在所有使用方停止后关闭
+	c.Shutdown.Register(PhaseCloseResources, "database", func(ctx context.Context) error {
+		return db.Close()
+	})
+
 	appLogger.Info(context.Background(), "数据库连接建立成功",
 		logger.String("host", fmt.Sprintf("%s:%d", c.Config.Database.Host, c.Config.Database.Port)),
 		logger.String("database", c.Config.Database.DBName),
@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/drain"
+)
+
+// drainPath 排空接口的路径，不计入处理中的请求
+const drainPath = "/api/v1/admin/drain"
+
+// initializeDrain 初始化部署前的实例排空
+// 排空提前执行关闭流程的停止接收请求和停止后台任务阶段，进程退出时不再重复执行
+func (c *Container) initializeDrain() {
+	cfg := c.Config.Server.Shutdown
+	c.DrainTracker = drain.NewTracker()
+
+	drainConfig := drain.Config{
+		StopAccepting: func(ctx context.Context) error {
+			return c.Shutdown.RunPhase(ctx, PhaseStopAccepting)
+		},
+		StopWorkers: func(ctx context.Context) error {
+			return c.Shutdown.RunPhase(ctx, PhaseStopWorkers)
+		},
+		Timeout: time.Duration(cfg.DrainTimeout) * time.Second,
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	c.drained = make(chan struct{})
+	drainConfig.OnDrained = func() {
+		status := c.Drainer.Status()
+		appLogger.Info(context.Background(), "实例已排空",
+			logger.Any("steps", status.Steps),
+			logger.Bool("exit_after_drain", cfg.ExitAfterDrain))
+		if cfg.ExitAfterDrain {
+			close(c.drained)
+		}
+	}
+	c.Drainer = drain.New(c.DrainTracker, drainConfig)
+}
@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"go-server/internal/config"
+	"go-server/internal/logger"
+	"go-server/pkg/fieldcrypt"
+)
+
+// initializeEncryption 按配置安装字段加密的密钥环，须在数据库之前初始化，之后读写的用户邮箱和姓名按密钥加解密
+func (c *Container) initializeEncryption() error {
+	encryption := c.Config.Encryption
+	if !encryption.Enabled {
+		fieldcrypt.Install(nil)
+		return nil
+	}
+
+	keys, err := fieldcrypt.ParseKeys(encryption.Keys)
+	if err != nil {
+		return fmt.Errorf("解析字段加密密钥失败: %w", err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(keys, encryption.ActiveKey, []byte(encryption.BlindIndexKey))
+	if err != nil {
+		return fmt.Errorf("创建字段加密密钥环失败: %w", err)
+	}
+	fieldcrypt.Install(keyring)
+
+	c.Logger.GetLogger("app").Info(context.Background(), "字段加密已启用",
+		logger.Int("active_key", int(keyring.ActiveVersion())),
+		logger.Any("key_versions", keyring.Versions()))
+
+	return nil
+}
+
+// rotatedKeyring 按新配置中的密钥列表返回新的密钥环，盲索引密钥沿用当前密钥环的密钥
+func rotatedKeyring(current *fieldcrypt.Keyring, encryption config.EncryptionConfig) (*fieldcrypt.Keyring, error) {
+	keys, err := fieldcrypt.ParseKeys(encryption.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return current.WithKeys(keys, encryption.ActiveKey)
+}
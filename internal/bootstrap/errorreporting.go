@@ -0,0 +1,52 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/errorreporting"
+)
+
+// initializeErrorReporting 初始化错误上报
+// 未启用或初始化失败时使用空操作上报器，错误上报不可用不影响服务启动
+func (c *Container) initializeErrorReporting() {
+	appLogger := c.Logger.GetLogger("app")
+	reportingCfg := c.Config.ErrorReporting
+
+	c.ErrorReporter = errorreporting.NewNoopReporter()
+	if !reportingCfg.Enabled {
+		return
+	}
+
+	environment := reportingCfg.Environment
+	if environment == "" {
+		environment = c.Config.Mode
+	}
+	serverName, _ := os.Hostname()
+
+	reporter, err := errorreporting.New(errorreporting.Config{
+		DSN:         reportingCfg.DSN,
+		Environment: environment,
+		Release:     reportingCfg.Release,
+		ServerName:  serverName,
+		SampleRate:  reportingCfg.SampleRate,
+		ScrubFields: reportingCfg.ScrubFields,
+		Timeout:     time.Duration(reportingCfg.Timeout) * time.Second,
+		QueueSize:   reportingCfg.QueueSize,
+	})
+	if err != nil {
+		appLogger.Warn(context.Background(), "错误上报初始化失败，错误将不会被上报", logger.Error(err))
+		return
+	}
+
+	c.ErrorReporter = reporter
+
+	// 在刷新日志前发送尚未上报的错误
+	c.Shutdown.Register(PhaseFlushLogs, "error_reporting", reporter.Flush)
+
+	appLogger.Info(context.Background(), "错误上报已启用",
+		logger.String("environment", environment),
+		logger.Float64("sample_rate", reportingCfg.SampleRate))
+}
@@ -0,0 +1,89 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainevents "go-server/internal/events"
+	"go-server/internal/logger"
+	"go-server/pkg/events"
+)
+
+// initializeEvents 初始化事件总线
+// 未启用时使用空操作总线，领域服务无需关心事件是否真正投递
+func (c *Container) initializeEvents() error {
+	appLogger := c.Logger.GetLogger("app")
+	eventsCfg := c.Config.Events
+
+	if !eventsCfg.Enabled {
+		c.EventBus = events.NewNoopBus()
+		appLogger.Info(context.Background(), "事件总线未启用，事件将被丢弃")
+		return nil
+	}
+
+	bus, err := events.New(events.Config{
+		Driver: eventsCfg.Driver,
+		Source: eventsCfg.Source,
+		NATS: events.NATSConfig{
+			URL:            eventsCfg.NATS.URL,
+			Name:           eventsCfg.NATS.Name,
+			QueueGroup:     eventsCfg.NATS.QueueGroup,
+			ConnectTimeout: time.Duration(eventsCfg.NATS.ConnectTimeout) * time.Second,
+			MaxReconnects:  eventsCfg.NATS.MaxReconnects,
+			DrainTimeout:   time.Duration(eventsCfg.DrainTimeout) * time.Second,
+		},
+		Kafka: events.KafkaConfig{
+			Brokers:     eventsCfg.Kafka.Brokers,
+			ClientID:    eventsCfg.Kafka.ClientID,
+			GroupID:     eventsCfg.Kafka.GroupID,
+			TopicPrefix: eventsCfg.Kafka.TopicPrefix,
+		},
+	})
+	if err != nil {
+		// 连接失败时回退到空操作总线，保证服务可用
+		c.EventBus = events.NewNoopBus()
+		return fmt.Errorf("初始化事件总线失败: %w", err)
+	}
+
+	c.EventBus = bus
+
+	// 排空事件总线，确保待发送事件在数据库关闭前送达
+	c.Shutdown.Register(PhaseStopWorkers, "events", c.drainEvents)
+
+	appLogger.Info(context.Background(), "事件总线初始化成功",
+		logger.String("driver", bus.Driver()),
+		logger.String("source", eventsCfg.Source))
+
+	return nil
+}
+
+// initializeDomainEvents 初始化进程内领域事件分发器
+// 用户服务通过分发器发出用户生命周期事件，审计、Webhook、缓存预热等功能在这里之后通过 c.DomainEvents 订阅
+func (c *Container) initializeDomainEvents() {
+	c.DomainEvents = domainevents.NewDispatcher(domainevents.DefaultWorkers, domainevents.DefaultQueueSize)
+
+	// 在事件总线之后注册，关闭时先于事件总线排空，订阅者仍可发布集成事件
+	c.Shutdown.Register(PhaseStopWorkers, "domain_events", c.DomainEvents.Close)
+
+	c.Logger.GetLogger("app").Debug(context.Background(), "领域事件分发器已初始化",
+		logger.Int("workers", domainevents.DefaultWorkers),
+		logger.Int("queue_size", domainevents.DefaultQueueSize))
+}
+
+// drainEvents 排空事件总线，等待处理中的事件完成并刷新待发送事件
+func (c *Container) drainEvents(ctx context.Context) error {
+	if c.EventBus == nil {
+		return nil
+	}
+
+	timeout := time.Duration(c.Config.Events.DrainTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return c.EventBus.Drain(drainCtx)
+}
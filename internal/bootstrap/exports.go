@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"go-server/internal/exports"
+	"go-server/internal/logger"
+	"go-server/internal/repositories"
+)
+
+// initializeExports 创建数据导出服务，关闭时中断正在执行的导出并记录结果
+// 未启用、没有数据库或对象存储不可用时不创建，导出接口返回服务不可用
+func (c *Container) initializeExports() error {
+	exportsConfig := c.Config.Exports
+	if !exportsConfig.Enabled || c.Database == nil || c.Storage == nil {
+		return nil
+	}
+
+	users, ok := c.UserRepository.(exports.UserSource)
+	if !ok {
+		return fmt.Errorf("用户仓库不支持数据导出: %T", c.UserRepository)
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	service := exports.NewService(
+		repositories.NewExportRepository(c.Database.DB),
+		users,
+		c.Storage,
+		exports.Config{
+			BatchSize:  exportsConfig.BatchSize,
+			MaxRunning: exportsConfig.MaxRunning,
+			Retention:  time.Duration(exportsConfig.Retention) * time.Hour,
+			URLTTL:     time.Duration(exportsConfig.URLTTL) * time.Second,
+			SigningKey: c.exportSigningKey(),
+			OnError: func(operation string, err error) {
+				appLogger.Warn(context.Background(), operation+"失败", logger.Error(err))
+			},
+		},
+	)
+	c.Exports = service
+
+	c.OnStart("exports", func(ctx context.Context) error {
+		service.Start()
+		return nil
+	})
+	c.Shutdown.Register(PhaseStopWorkers, "exports", service.Stop)
+
+	appLogger.Info(context.Background(), "数据导出已启用",
+		logger.Int("batch_size", exportsConfig.BatchSize),
+		logger.Int("max_running", exportsConfig.MaxRunning),
+		logger.Int("retention_hours", exportsConfig.Retention),
+		logger.Int("url_ttl_seconds", exportsConfig.URLTTL))
+
+	return nil
+}
+
+// exportSigningKey 返回下载链接的签名密钥，未配置时从 JWT 密钥派生，避免直接复用令牌的签名密钥
+func (c *Container) exportSigningKey() []byte {
+	if key := c.Config.Exports.SigningKey; key != "" {
+		return []byte(key)
+	}
+	mac := hmac.New(sha256.New, []byte(c.Config.JWT.SecretKey))
+	mac.Write([]byte("exports"))
+	return mac.Sum(nil)
+}
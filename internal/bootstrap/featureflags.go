@@ -0,0 +1,61 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/cache"
+	"go-server/pkg/featureflags"
+)
+
+// initializeFeatureFlags 初始化功能开关
+// redis 存储需要 Redis 缓存，缓存不是 Redis 或无法写入时回退到进程内存储，开关修改只对当前实例有效
+func (c *Container) initializeFeatureFlags() {
+	cfg := c.Config.FeatureFlags
+	if !cfg.Enabled {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	flags := make([]*featureflags.Flag, 0, len(cfg.Flags))
+	for _, flag := range cfg.Flags {
+		flags = append(flags, &featureflags.Flag{
+			Key:         flag.Key,
+			Description: flag.Description,
+			Enabled:     flag.Enabled,
+			Percentage:  flag.Percentage,
+			Users:       flag.Users,
+		})
+	}
+
+	var store featureflags.Store
+	redisCache, isRedis := c.Cache.(*cache.RedisCache)
+	switch {
+	case cfg.Backend == "redis" && isRedis:
+		redisStore := featureflags.NewRedisStore(redisCache.GetClient(), cfg.KeyPrefix)
+		if err := redisStore.Seed(context.Background(), flags...); err != nil {
+			appLogger.Warn(context.Background(), "写入初始功能开关失败，功能开关将保存在内存中",
+				logger.Error(err))
+			store = featureflags.NewMemoryStore(flags...)
+			break
+		}
+		store = redisStore
+	case cfg.Backend == "redis":
+		appLogger.Warn(context.Background(), "缓存不是 Redis，功能开关将保存在内存中，修改仅对当前实例有效")
+		store = featureflags.NewMemoryStore(flags...)
+	default:
+		store = featureflags.NewMemoryStore(flags...)
+	}
+
+	manager := featureflags.NewManager(store, time.Duration(cfg.CacheTTL)*time.Second)
+	manager.Start(context.Background())
+	c.FeatureFlags = manager
+
+	c.Shutdown.Register(PhaseStopWorkers, "feature_flags", manager.Close)
+
+	appLogger.Info(context.Background(), "功能开关已初始化",
+		logger.String("backend", cfg.Backend),
+		logger.Int("flags", len(flags)),
+		logger.Int("cache_ttl_seconds", cfg.CacheTTL))
+}
@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/geoip"
+)
+
+// initializeGeoIP 打开 MaxMind 数据库，查询请求的国家和自治系统
+// 数据库文件不存在或无效时组件初始化失败，服务继续运行但不查询地理位置
+func (c *Container) initializeGeoIP() error {
+	cfg := c.Config.GeoIP
+	if !cfg.Enabled {
+		return nil
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	reader, err := geoip.Open(geoip.Config{
+		CountryDatabase: cfg.CountryDatabase,
+		ASNDatabase:     cfg.ASNDatabase,
+		RefreshInterval: time.Duration(cfg.RefreshInterval) * time.Second,
+		OnReload: func(path string) {
+			appLogger.Info(context.Background(), "GeoIP 数据库已更新", logger.String("path", path))
+		},
+		OnError: func(err error) {
+			appLogger.Warn(context.Background(), "GeoIP 数据库刷新失败，继续使用原来的数据库", logger.Error(err))
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("打开 GeoIP 数据库失败: %w", err)
+	}
+	c.GeoIP = reader
+
+	c.OnStart("geoip", func(ctx context.Context) error {
+		reader.Start()
+		return nil
+	})
+	c.Shutdown.Register(PhaseCloseResources, "geoip", func(ctx context.Context) error {
+		return reader.Close()
+	})
+
+	appLogger.Info(context.Background(), "GeoIP 查询已启用",
+		logger.String("country_database", cfg.CountryDatabase),
+		logger.String("asn_database", cfg.ASNDatabase),
+		logger.Int("refresh_interval_seconds", cfg.RefreshInterval))
+	return nil
+}
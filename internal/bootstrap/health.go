@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/pkg/health"
+)
+
+// initializeHealth 初始化健康检查注册中心并注册各子系统的检查项
+// 数据库为关键依赖；缓存、事件总线和对象存储不可用时服务仍可降级运行
+func (c *Container) initializeHealth() {
+	c.HealthRegistry = health.NewRegistry(health.DefaultCheckTimeout)
+
+	// 导出到 Prometheus 的指标，缓存驱动提供指标收集器时按驱动名称注册
+	c.MetricsRegistry = metrics.NewRegistry()
+	c.HTTPMetrics = metrics.NewHTTPMetrics()
+	c.MetricsRegistry.RegisterHTTP(c.HTTPMetrics)
+	if instrumented, ok := c.Cache.(interface{ Metrics() *metrics.CacheMetrics }); ok {
+		c.MetricsRegistry.RegisterCache(c.Config.Cache.Driver, instrumented.Metrics())
+	}
+
+	// 存活检查：仅检查进程自身状态
+	c.HealthRegistry.RegisterLiveness("logger", health.CheckerFunc(func(ctx context.Context) error {
+		if c.Logger == nil || !c.Logger.IsStarted() {
+			return errors.New("logger manager is not running")
+		}
+		return nil
+	}))
+
+	// 就绪检查：外部依赖
+	if c.Database != nil {
+		c.HealthRegistry.RegisterReadiness("database", health.CheckerFunc(func(ctx context.Context) error {
+			return c.Database.Health()
+		}))
+	}
+
+	if c.Cache != nil {
+		c.HealthRegistry.RegisterReadiness("cache", health.CheckerFunc(c.Cache.Health), health.Optional())
+	}
+
+	if c.EventBus != nil && c.Config.Events.Enabled {
+		c.HealthRegistry.RegisterReadiness("events", health.CheckerFunc(c.EventBus.Health), health.Optional())
+	}
+
+	if c.Storage != nil {
+		c.HealthRegistry.RegisterReadiness("storage", health.CheckerFunc(c.Storage.Health), health.Optional())
+	}
+
+	// 关闭时先标记为未就绪，并等待负载均衡器摘除流量后再排空请求
+	preStopDelay := time.Duration(c.Config.Server.Shutdown.PreStopDelay) * time.Second
+	c.Shutdown.Register(PhaseStopAccepting, "readiness", func(ctx context.Context) error {
+		c.HealthRegistry.SetShuttingDown(true)
+		if preStopDelay <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(preStopDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	c.Logger.GetLogger("app").Info(context.Background(), "健康检查已注册",
+		logger.Any("readiness_checks", c.HealthRegistry.Names()))
+}
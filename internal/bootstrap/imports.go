@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"go-server/internal/imports"
+	"go-server/internal/logger"
+	"go-server/internal/repositories"
+)
+
+// initializeImports 创建批量导入服务，未启用或没有数据库时不创建，导入接口返回服务不可用
+// 导入经由缓存仓库写入，创建用户后失效其邮箱、用户名的负缓存和用户列表缓存
+func (c *Container) initializeImports() error {
+	importsConfig := c.Config.Imports
+	if !importsConfig.Enabled || c.Database == nil {
+		return nil
+	}
+
+	repo := c.UserRepository
+	if userCache := c.userCache(); userCache != nil {
+		repo = repositories.NewCachedUserRepository(repo, userCache)
+	}
+	users, ok := repo.(repositories.UserImporter)
+	if !ok {
+		return fmt.Errorf("用户仓库不支持批量导入: %T", repo)
+	}
+
+	c.Imports = imports.NewService(users, imports.Config{
+		BatchSize: importsConfig.BatchSize,
+		MaxRows:   importsConfig.MaxRows,
+		Hasher:    c.PasswordHasher,
+	})
+
+	c.Logger.GetLogger("app").Info(context.Background(), "批量导入已启用",
+		logger.Int("batch_size", importsConfig.BatchSize),
+		logger.Int("max_rows", importsConfig.MaxRows))
+
+	return nil
+}
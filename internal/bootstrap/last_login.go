@@ -0,0 +1,57 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/pkg/cache"
+)
+
+// initializeLastLoginBatching 创建最后登录时间的缓冲存储和批量写入任务，返回交给用户服务的存储
+// 缓存是 Redis 时登录缓冲在实例间共享，否则保存在内存中；未启用、没有缓存或用户仓储不支持批量写入时返回 nil，登录直接更新数据库
+func (c *Container) initializeLastLoginBatching() repositories.LastLoginStore {
+	cfg := c.Config.Database.LastLoginBatching
+	if !cfg.Enabled || c.Cache == nil {
+		return nil
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	writer, ok := c.UserRepository.(repositories.LastLoginWriter)
+	if !ok {
+		appLogger.Warn(context.Background(), "用户仓储不支持批量写入最后登录时间，登录将直接更新数据库")
+		return nil
+	}
+
+	var store repositories.LastLoginStore
+	if redisCache, ok := c.Cache.(*cache.RedisCache); ok {
+		store = repositories.NewRedisLastLoginStore(redisCache.GetClient(), "users")
+	} else {
+		store = repositories.NewMemoryLastLoginStore()
+		appLogger.Warn(context.Background(), "缓存不是 Redis，最后登录时间将缓冲在内存中，进程异常退出时未写入的登录会丢失")
+	}
+
+	flusher := services.NewLastLoginFlusher(store, writer, services.LastLoginFlushConfig{
+		Interval:  time.Duration(cfg.Interval) * time.Second,
+		BatchSize: cfg.BatchSize,
+		OnError: func(err error) {
+			appLogger.Warn(context.Background(), "写入最后登录时间失败", logger.Error(err))
+		},
+	})
+	c.LastLoginFlusher = flusher
+
+	c.OnStart("last_login", func(ctx context.Context) error {
+		flusher.Start()
+		return nil
+	})
+
+	// 停止时写入剩余的缓冲登录，须在关闭数据库之前执行
+	c.Shutdown.Register(PhaseStopWorkers, "last_login", flusher.Stop)
+
+	appLogger.Info(context.Background(), "最后登录时间批量写入已启用",
+		logger.Int("interval_seconds", cfg.Interval),
+		logger.Int("batch_size", cfg.BatchSize))
+	return store
+}
@@ -0,0 +1,76 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"go-server/internal/logger"
+)
+
+// StartHook 启动钩子，在组件全部初始化后、开始接收请求前执行
+type StartHook func(ctx context.Context) error
+
+// namedStartHook 带名称的启动钩子
+type namedStartHook struct {
+	name string
+	hook StartHook
+}
+
+// initializeShutdown 创建关闭管理器，并注册日志和配置监控的关闭钩子
+// 其他组件在各自的初始化函数中注册钩子
+func (c *Container) initializeShutdown() {
+	c.Shutdown = NewShutdownManager(c.Config.Server.Shutdown, c.Logger.GetLogger("shutdown"))
+
+	// 停止配置文件监控，避免关闭期间触发热重载
+	c.Shutdown.Register(PhaseStopWorkers, "config_watcher", func(ctx context.Context) error {
+		c.ConfigManager.StopWatching()
+		return nil
+	})
+
+	// 刷新缓冲日志并关闭日志文件；标准 log 输出恢复到 stderr，保证之后的输出不丢失
+	c.Shutdown.Register(PhaseFlushLogs, "logger", func(ctx context.Context) error {
+		log.SetOutput(os.Stderr)
+		return c.Logger.Stop()
+	})
+}
+
+// OnStart 注册启动钩子，Start 按注册顺序执行；组件按依赖顺序初始化，被依赖的组件先启动
+func (c *Container) OnStart(name string, hook StartHook) {
+	c.startMu.Lock()
+	defer c.startMu.Unlock()
+	c.startHooks = append(c.startHooks, namedStartHook{name: name, hook: hook})
+}
+
+// OnStop 注册关闭钩子，等同于 c.Shutdown.Register
+func (c *Container) OnStop(phase ShutdownPhase, name string, hook ShutdownHook) {
+	c.Shutdown.Register(phase, name, hook)
+}
+
+// Start 按注册顺序执行启动钩子，只执行一次；钩子失败时停止并返回错误
+// 只执行一次性操作（如 adminctl）时不需要调用 Start
+func (c *Container) Start(ctx context.Context) error {
+	c.startMu.Lock()
+	defer c.startMu.Unlock()
+
+	if c.started {
+		return nil
+	}
+	c.started = true
+
+	for _, h := range c.startHooks {
+		if err := h.hook(ctx); err != nil {
+			return fmt.Errorf("启动%s失败: %w", h.name, err)
+		}
+	}
+	return nil
+}
+
+// startConfigWatcher 启动配置文件监控，失败时只记录警告
+func (c *Container) startConfigWatcher(ctx context.Context) error {
+	if err := c.ConfigManager.StartWatching(); err != nil {
+		c.Logger.GetLogger("app").Warn(ctx, "启动配置文件监控失败", logger.Error(err))
+	}
+	return nil
+}
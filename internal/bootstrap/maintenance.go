@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/pkg/cache"
+	"go-server/pkg/maintenance"
+)
+
+// initializeMaintenance 初始化维护模式开关，并在健康检查中附带维护倒计时
+// 缓存是 Redis 时管理员开启的维护在实例间共享，否则保存在内存中，只对当前实例有效
+func (c *Container) initializeMaintenance() {
+	cfg := c.Config.Maintenance
+	if !cfg.Enabled {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	var store maintenance.Store
+	if redisCache, ok := c.Cache.(*cache.RedisCache); ok {
+		store = maintenance.NewRedisStore(redisCache.GetClient(), cfg.KeyPrefix)
+	} else {
+		store = maintenance.NewMemoryStore()
+		appLogger.Warn(context.Background(), "缓存不是 Redis，维护模式开关将保存在内存中，仅对当前实例有效")
+	}
+	c.Maintenance = maintenance.New(store, time.Duration(cfg.RefreshInterval)*time.Second)
+
+	if c.HealthRegistry != nil {
+		c.HealthRegistry.SetMaintenance(c.Maintenance.Countdown)
+	}
+
+	appLogger.Info(context.Background(), "维护模式已启用",
+		logger.Bool("active", cfg.Active),
+		logger.Any("allow_paths", cfg.AllowPaths))
+}
@@ -0,0 +1,55 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+)
+
+// initializeMetricsSnapshots 创建指标快照任务，定期把 HTTP、限流和用户缓存指标的增量写入数据库
+// 未启用或没有数据库时不创建，指标历史接口返回服务不可用
+func (c *Container) initializeMetricsSnapshots(usersCacheMetrics *metrics.CacheMetrics) {
+	snapshotConfig := c.Config.Metrics.Snapshots
+	if !snapshotConfig.Enabled || c.Database == nil {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	snapshotter := services.NewMetricsSnapshotter(
+		repositories.NewMetricsSnapshotRepository(c.Database.DB),
+		services.MetricsSources{
+			HTTP:      c.HTTPMetrics,
+			Cache:     usersCacheMetrics,
+			RateLimit: c.rateLimitStats,
+		},
+		services.MetricsSnapshotConfig{
+			Interval:        time.Duration(snapshotConfig.Interval) * time.Second,
+			RawRetention:    time.Duration(snapshotConfig.RawRetention) * time.Hour,
+			RollupInterval:  time.Duration(snapshotConfig.RollupInterval) * time.Minute,
+			RollupRetention: time.Duration(snapshotConfig.RollupRetention) * 24 * time.Hour,
+			OnError: func(operation string, err error) {
+				appLogger.Warn(context.Background(), "指标快照"+operation+"失败", logger.Error(err))
+			},
+		},
+	)
+	c.MetricsSnapshotter = snapshotter
+
+	c.OnStart("metrics_snapshots", func(ctx context.Context) error {
+		snapshotter.Start()
+		return nil
+	})
+
+	// 停止时写入最后一个不完整时间桶，须在关闭数据库之前执行
+	c.Shutdown.Register(PhaseStopWorkers, "metrics_snapshots", snapshotter.Stop)
+
+	appLogger.Info(context.Background(), "指标快照已启用",
+		logger.String("instance", snapshotter.Instance()),
+		logger.Int("interval_seconds", snapshotConfig.Interval),
+		logger.Int("raw_retention_hours", snapshotConfig.RawRetention),
+		logger.Int("rollup_interval_minutes", snapshotConfig.RollupInterval),
+		logger.Int("rollup_retention_days", snapshotConfig.RollupRetention))
+}
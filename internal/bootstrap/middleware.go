@@ -3,9 +3,15 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"go-server/docs"
 	"go-server/internal/logger"
 	"go-server/internal/middleware"
+	"go-server/internal/tenancy"
+	"go-server/pkg/cache"
+	"go-server/pkg/i18n"
+	"go-server/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +22,12 @@ func (c *Container) setupMiddlewares() error {
 
 	var middlewares []gin.HandlerFunc
 
+	// 本地存储的媒体文件不需要请求日志和压缩（图片已压缩），这些中间件跳过该路径
+	var mediaPaths []string
+	if path := c.localMediaPath(); path != "" {
+		mediaPaths = append(mediaPaths, path)
+	}
+
 	// 设置Gin模式
 	if c.Config.Mode == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -23,31 +35,106 @@ func (c *Container) setupMiddlewares() error {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	// 1. 结构化日志中间件（REQ-MW-003）
-	middlewares = append(middlewares, middleware.StructuredLoggingMiddleware(c.Config))
+	// 错误响应格式，envelope 模式下仍按请求头 Accept 协商 RFC 7807 格式
+	response.ConfigureErrorFormat(c.Config.Server.ErrorFormat, c.Config.Server.ProblemTypeBaseURL)
+
+	// 0. HTTP 指标中间件，最先安装以便耗时包含其余中间件
+	if c.HTTPMetrics != nil {
+		middlewares = append(middlewares, middleware.HTTPMetricsMiddleware(c.HTTPMetrics))
+		appLogger.Debug(context.Background(), "HTTP 指标中间件已初始化")
+	}
+
+	// 统计处理中的请求，排空实例时等待它们完成
+	if c.DrainTracker != nil {
+		middlewares = append(middlewares, middleware.DrainTrackerMiddleware(c.DrainTracker, drainPath))
+	}
+
+	// 协商响应语言，之后的中间件和处理器返回的错误消息使用该语言
+	middlewares = append(middlewares, middleware.LocaleMiddleware(i18n.Default()))
+	appLogger.Debug(context.Background(), "语言协商中间件已初始化",
+		logger.Any("locales", i18n.Default().Locales()))
+
+	// 查询客户端的国家和自治系统，请求日志和按国家的流量统计从上下文读取
+	if c.GeoIP != nil {
+		middlewares = append(middlewares, middleware.GeoIPMiddleware(c.GeoIP, c.HTTPMetrics))
+		appLogger.Debug(context.Background(), "GeoIP 中间件已初始化")
+	}
+
+	// 1. 结构化日志中间件（REQ-MW-003），请求日志使用应用的日志管理器，共享采样和运行时级别设置
+	middleware.SetLoggerManager(c.Logger)
+	middlewares = append(middlewares, middleware.SkipPaths(middleware.StructuredLoggingMiddleware(c.Config), mediaPaths...))
 	appLogger.Debug(context.Background(), "结构化日志中间件已初始化")
 
-	// 2. 增强恢复中间件
+	// 每个请求的查询计数，依赖结构化日志中间件设置的关联ID
+	if c.Database != nil && c.Database.RequestQueries() != nil {
+		middlewares = append(middlewares, middleware.RequestQueryMiddleware(c.Database.RequestQueries()))
+		appLogger.Debug(context.Background(), "请求查询计数中间件已初始化")
+	}
+
+	// 2. 增强恢复中间件，panic 转换为带堆栈的内部错误响应并计入 HTTP 指标，恢复的 panic 和 5xx 错误发送到错误上报服务
+	c.initializeErrorReporting()
 	recoveryLogger := c.Logger.GetLogger("recovery")
-	middlewares = append(middlewares, middleware.RecoveryMiddleware(recoveryLogger))
+	middlewares = append(middlewares, middleware.RecoveryMiddleware(recoveryLogger, c.ErrorReporter, c.HTTPMetrics))
 	appLogger.Debug(context.Background(), "增强恢复中间件已初始化")
 
-	// 3. CORS中间件
-	allowedOrigins := []string{"*"}
-	if c.Config.Mode == "production" {
-		allowedOrigins = []string{"https://yourdomain.com"}
+	// 3. CORS中间件，始终安装以便通过配置热重载调整策略
+	cors, err := middleware.NewCORS(c.Config.CORS)
+	if err != nil {
+		return fmt.Errorf("初始化CORS中间件失败: %w", err)
 	}
-	middlewares = append(middlewares, middleware.CORSMiddleware(allowedOrigins))
+	c.CORS = cors
+	middlewares = append(middlewares, c.CORS.Middleware())
 	appLogger.Debug(context.Background(), "CORS中间件已初始化",
-		logger.Any("allowed_origins", allowedOrigins))
+		logger.Bool("enabled", c.Config.CORS.Enabled),
+		logger.Any("allowed_origins", c.Config.CORS.AllowedOrigins),
+		logger.Bool("allow_credentials", c.Config.CORS.AllowCredentials))
 
 	// 4. 安全头中间件
 	middlewares = append(middlewares, middleware.SecurityHeadersMiddleware(c.Config))
 	appLogger.Debug(context.Background(), "安全头部中间件已初始化")
 
-	// 5. 分布式速率限制中间件（REQ-MW-001）
+	// 维护模式中间件，维护期间的请求在限流之前拒绝，不占用限流配额
+	if c.Maintenance != nil {
+		c.MaintenanceGate = middleware.NewMaintenance(c.Config.Maintenance, c.Maintenance)
+		middlewares = append(middlewares, c.MaintenanceGate.Middleware())
+		appLogger.Debug(context.Background(), "维护模式中间件已初始化")
+	}
+
+	// 5. 租户解析中间件，需在速率限制之前确定租户以便按租户计数和应用租户的限制
+	if c.Config.Tenancy.Enabled {
+		resolver := tenancy.NewResolver(c.TenantRepository, time.Duration(c.Config.Tenancy.CacheTTL)*time.Second)
+		c.Tenants = middleware.NewTenantResolver(c.Config.Tenancy, resolver, c.JWTManager)
+		middlewares = append(middlewares, c.Tenants.Middleware())
+		appLogger.Info(context.Background(), "租户解析中间件已初始化",
+			logger.Any("sources", c.Config.Tenancy.Sources),
+			logger.Bool("required", c.Config.Tenancy.Required))
+	}
+
+	// 订阅套餐中间件，需在速率限制之前识别用户以便应用套餐的限制
+	if c.Billing != nil {
+		middlewares = append(middlewares, middleware.PlanMiddleware(c.Billing, c.JWTManager))
+		appLogger.Debug(context.Background(), "订阅套餐中间件已初始化")
+	}
+
+	// 6. 分布式速率限制中间件（REQ-MW-001）
+	// 自动封禁的调用方在限流之前拒绝，不占用限流配额；认证后的用户封禁在路由中检查
+	if c.BanList != nil {
+		middlewares = append(middlewares, middleware.BanListMiddleware(c.BanList))
+		appLogger.Debug(context.Background(), "自动封禁中间件已初始化")
+	}
+
+	// 始终安装以便通过配置热重载启用或禁用，禁用时请求直接通过
+	c.RateLimiter = middleware.NewRateLimiterFromConfig(c.Config)
+	c.Shutdown.Register(PhaseCloseResources, "rate_limiter", func(ctx context.Context) error {
+		return c.RateLimiter.Close()
+	})
+	c.initializeRateLimitOverrides()
+	middlewares = append(middlewares, c.RateLimiter.Middleware())
+	if c.GeoIP != nil {
+		// 违规记录带上 IP 的国家和自治系统
+		c.RateLimiter.Metrics().SetLocator(c.GeoIP)
+	}
 	if c.Config.RateLimit.Enabled {
-		middlewares = append(middlewares, middleware.RateLimiterMiddleware(c.Config))
 
 		rateLimitInfo := map[string]interface{}{
 			"enabled":  c.Config.RateLimit.Enabled,
@@ -74,9 +161,24 @@ func (c *Container) setupMiddlewares() error {
 		appLogger.Warn(context.Background(), "速率限制中间件已禁用")
 	}
 
-	// 6. 压缩中间件（REQ-MW-002）
+	// 7. 请求体大小限制中间件，需在压缩中间件之前安装，以便同时限制 gzip 请求体解压后的大小
+	bodyLimiter, err := middleware.NewBodyLimiter(c.Config.BodyLimit)
+	if err != nil {
+		return fmt.Errorf("初始化请求体大小限制中间件失败: %w", err)
+	}
+	c.BodyLimiter = bodyLimiter
+	middlewares = append(middlewares, c.BodyLimiter.Middleware())
+	appLogger.Debug(context.Background(), "请求体大小限制中间件已初始化",
+		logger.Bool("enabled", c.Config.BodyLimit.Enabled),
+		logger.Int64("max_bytes", c.Config.BodyLimit.MaxBytes),
+		logger.Int64("multipart_max_bytes", c.Config.BodyLimit.MultipartMaxBytes),
+		logger.Int64("max_decompressed_bytes", c.Config.BodyLimit.MaxDecompressedBytes),
+		logger.Int("route_rules", len(c.Config.BodyLimit.Routes)))
+
+	// 8. 压缩中间件（REQ-MW-002），同样始终安装以支持热重载
+	c.Compressor = middleware.NewCompressor(c.Config.Compression)
+	middlewares = append(middlewares, middleware.SkipPaths(c.Compressor.Middleware(), mediaPaths...))
 	if c.Config.Compression.Enabled {
-		middlewares = append(middlewares, middleware.CompressionMiddleware(c.Config.Compression.Threshold))
 
 		compressionInfo := map[string]interface{}{
 			"enabled":   c.Config.Compression.Enabled,
@@ -97,23 +199,85 @@ func (c *Container) setupMiddlewares() error {
 			logger.String("note", "响应将以未压缩方式发送，带宽使用可能更高"))
 	}
 
-	// 7. 请求大小限制中间件
-	middlewares = append(middlewares, middleware.RequestSizeLimitMiddleware(10<<20)) // 10MB
-	appLogger.Debug(context.Background(), "请求大小限制中间件已初始化",
-		logger.Int("limit_mb", 10))
+	// 9. OpenAPI 请求校验中间件，需在请求体大小限制之后读取请求体，在幂等中间件之前拒绝不符合文档的请求
+	if c.Config.OpenAPI.ValidateRequests {
+		validator, err := middleware.NewOpenAPIValidator(c.Config.OpenAPI, docs.OpenAPI)
+		if err != nil {
+			return fmt.Errorf("初始化OpenAPI请求校验中间件失败: %w", err)
+		}
+		middlewares = append(middlewares, validator.Middleware())
+		appLogger.Debug(context.Background(), "OpenAPI 请求校验中间件已初始化",
+			logger.Bool("strict", c.Config.OpenAPI.Strict),
+			logger.Any("exclude_paths", c.Config.OpenAPI.ExcludePaths))
+	}
+
+	// 10. 幂等请求中间件，需在请求体大小限制之后读取请求体
+	if c.Config.Idempotency.Enabled {
+		var store middleware.IdempotencyStore
+		if redisCache, ok := c.Cache.(*cache.RedisCache); ok {
+			store = middleware.NewRedisIdempotencyStore(redisCache.GetClient())
+		} else {
+			store = middleware.NewMemoryIdempotencyStore()
+			appLogger.Warn(context.Background(), "幂等记录将保存在内存中，仅对当前实例有效")
+		}
+		middlewares = append(middlewares, middleware.NewIdempotency(c.Config.Idempotency, store).Middleware())
+		appLogger.Debug(context.Background(), "幂等请求中间件已初始化",
+			logger.Any("methods", c.Config.Idempotency.Methods),
+			logger.Int("ttl_seconds", c.Config.Idempotency.TTL))
+	}
+
+	// 11. 实体标签中间件，为 GET 响应设置 ETag 并处理 If-None-Match
+	if c.Config.ETag.Enabled {
+		middlewares = append(middlewares, middleware.NewETag(c.Config.ETag).Middleware())
+		appLogger.Debug(context.Background(), "实体标签中间件已初始化",
+			logger.Int64("max_body_size", c.Config.ETag.MaxBodySize),
+			logger.Any("exclude_paths", c.Config.ETag.ExcludePaths))
+	}
+
+	// 12. 响应缓存中间件挂载在具体的 GET 路由上，位于认证中间件之后，这里只负责创建
+	if c.Config.ResponseCache.Enabled && c.Cache != nil {
+		c.ResponseCache = middleware.NewResponseCache(c.Config.ResponseCache, c.Cache)
+		appLogger.Debug(context.Background(), "响应缓存中间件已初始化",
+			logger.Int("routes", len(c.Config.ResponseCache.Routes)),
+			logger.Int("stale_while_revalidate", c.Config.ResponseCache.StaleWhileRevalidate))
+	} else if c.Config.ResponseCache.Enabled {
+		appLogger.Warn(context.Background(), "缓存不可用，响应缓存已禁用")
+	}
+
+	// 请求合并中间件同样挂载在具体的 GET 路由上，位于响应缓存中间件之后
+	if c.Config.Coalescing.Enabled {
+		c.Coalescer = middleware.NewRequestCoalescer(c.Config.Coalescing)
+		appLogger.Debug(context.Background(), "请求合并中间件已初始化",
+			logger.Int64("max_body_size", c.Config.Coalescing.MaxBodySize))
+	}
+
+	// 13. 功能开关中间件，在首次读取时按认证后的用户评估开关
+	if c.FeatureFlags != nil {
+		middlewares = append(middlewares, middleware.FeatureFlagsMiddleware(c.FeatureFlags))
+		appLogger.Debug(context.Background(), "功能开关中间件已初始化")
+	}
 
 	c.Middlewares = middlewares
 
 	appLogger.Info(context.Background(), "增强的中间件栈已配置完成",
 		logger.Int("middleware_count", len(middlewares)),
 		logger.Any("features", []string{
+			"http_metrics",
+			"locale_negotiation",
 			"structured_json_logging",
 			"enhanced_error_recovery",
 			"cors",
 			"security_headers",
+			"multi_tenancy",
+			"subscription_plans",
 			"distributed_rate_limiting",
 			"gzip_compression",
 			"request_size_protection",
+			"openapi_request_validation",
+			"idempotency_keys",
+			"etag_conditional_requests",
+			"response_cache",
+			"feature_flags",
 		}))
 
 	return nil
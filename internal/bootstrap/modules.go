@@ -0,0 +1,95 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go-server/pkg/health"
+)
+
+// Module 可插拔的子系统（如支付、通知），注册后由容器管理生命周期
+//
+//   - Init 在内置组件全部初始化后执行，可以使用容器中的服务并通过 c.GetEngine() 注册路由
+//   - Start 在 Container.Start 时按依赖顺序执行，用于启动后台任务
+//   - Stop 在关闭的"停止后台任务"阶段执行，后启动的模块先停止
+//   - HealthCheck 注册为就绪检查，返回错误时 /readyz 报告未就绪
+type Module interface {
+	Name() string
+	Init(c *Container) error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	HealthCheck(ctx context.Context) error
+}
+
+// ModuleDependencies 模块可选实现的接口，声明依赖的其他模块或内置组件
+type ModuleDependencies interface {
+	DependsOn() []string
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   = make(map[string]Module)
+)
+
+// RegisterModule 注册模块，之后创建的容器都会加载该模块
+// 通常在模块包的 init 函数中调用，名称重复时 panic（与 database/sql.Register 一致）
+func RegisterModule(m Module) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+
+	if m == nil {
+		panic("bootstrap: RegisterModule module is nil")
+	}
+	if _, exists := modules[m.Name()]; exists {
+		panic("bootstrap: RegisterModule called twice for module " + m.Name())
+	}
+	modules[m.Name()] = m
+}
+
+// RegisteredModules 返回已注册的模块，按名称排序
+func RegisteredModules() []Module {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+
+	registered := make([]Module, 0, len(modules))
+	for _, m := range modules {
+		registered = append(registered, m)
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Name() < registered[j].Name()
+	})
+	return registered
+}
+
+// WithModule 只为当前容器加载模块，名称与已注册模块相同时替换该模块
+func WithModule(m Module) Option {
+	return WithComponent(moduleComponent(m))
+}
+
+// moduleComponent 把模块包装为组件，默认依赖路由，即在内置组件全部就绪后初始化
+func moduleComponent(m Module) Component {
+	dependsOn := []string{ComponentRouter}
+	if deps, ok := m.(ModuleDependencies); ok {
+		dependsOn = append(dependsOn, deps.DependsOn()...)
+	}
+
+	return Component{
+		Name:        m.Name(),
+		Description: fmt.Sprintf("模块 %s", m.Name()),
+		DependsOn:   dependsOn,
+		Init: func(c *Container) error {
+			if err := m.Init(c); err != nil {
+				return err
+			}
+
+			c.OnStart(m.Name(), m.Start)
+			c.OnStop(PhaseStopWorkers, m.Name(), m.Stop)
+			if c.HealthRegistry != nil {
+				c.HealthRegistry.RegisterReadiness(m.Name(), health.CheckerFunc(m.HealthCheck))
+			}
+			return nil
+		},
+	}
+}
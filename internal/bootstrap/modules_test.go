@@ -0,0 +1,81 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-server/internal/config"
+	"go-server/pkg/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModule 记录生命周期调用顺序的模块
+type fakeModule struct {
+	name      string
+	deps      []string
+	calls     *[]string
+	healthErr error
+}
+
+func (m *fakeModule) Name() string { return m.name }
+
+func (m *fakeModule) DependsOn() []string { return m.deps }
+
+func (m *fakeModule) Init(c *Container) error {
+	*m.calls = append(*m.calls, "init:"+m.name)
+	return nil
+}
+
+func (m *fakeModule) Start(ctx context.Context) error {
+	*m.calls = append(*m.calls, "start:"+m.name)
+	return nil
+}
+
+func (m *fakeModule) Stop(ctx context.Context) error {
+	*m.calls = append(*m.calls, "stop:"+m.name)
+	return nil
+}
+
+func (m *fakeModule) HealthCheck(ctx context.Context) error { return m.healthErr }
+
+func TestModule_Lifecycle(t *testing.T) {
+	var calls []string
+	c := &Container{
+		Shutdown:       newTestShutdownManager(t, config.ShutdownConfig{}),
+		HealthRegistry: health.NewRegistry(health.DefaultCheckTimeout),
+	}
+
+	options := &containerOptions{components: []Component{{Name: ComponentRouter}}}
+	WithModule(&fakeModule{name: "notifications", deps: []string{"payments"}, calls: &calls})(options)
+	WithModule(&fakeModule{name: "payments", calls: &calls, healthErr: errors.New("gateway unreachable")})(options)
+
+	require.NoError(t, c.initializeComponents(options.components))
+	require.NoError(t, c.Start(context.Background()))
+	require.NoError(t, c.Shutdown.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{
+		"init:payments", "init:notifications",
+		"start:payments", "start:notifications",
+		"stop:notifications", "stop:payments",
+	}, calls)
+
+	report := c.HealthRegistry.CheckReadiness(context.Background())
+	assert.False(t, report.Healthy(), "模块健康检查失败时未就绪")
+}
+
+func TestRegisterModule(t *testing.T) {
+	var calls []string
+	module := &fakeModule{name: "test_registry_module", calls: &calls}
+	RegisterModule(module)
+	t.Cleanup(func() {
+		modulesMu.Lock()
+		delete(modules, module.name)
+		modulesMu.Unlock()
+	})
+
+	assert.Contains(t, RegisteredModules(), Module(module))
+	assert.Panics(t, func() { RegisterModule(module) }, "名称重复时 panic")
+}
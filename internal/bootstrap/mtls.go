@@ -0,0 +1,48 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"go-server/internal/logger"
+	"go-server/pkg/mtls"
+)
+
+// initializeMTLS 加载 mTLS 监听器的证书和服务账户
+// 证书文件无效时启动失败，而不是静默地不提供内部接口
+func (c *Container) initializeMTLS() error {
+	cfg := c.Config.Server.MTLS
+	if !cfg.Enabled {
+		return nil
+	}
+
+	tlsConfig, err := mtls.NewTLSConfig(mtls.ServerConfig{
+		CertFile:     cfg.CertFile,
+		KeyFile:      cfg.KeyFile,
+		ClientCAFile: cfg.ClientCAFile,
+	})
+	if err != nil {
+		return fmt.Errorf("加载 mTLS 证书失败: %w", err)
+	}
+
+	accounts := make([]mtls.ServiceAccount, len(cfg.ServiceAccounts))
+	for i, account := range cfg.ServiceAccounts {
+		accounts[i] = mtls.ServiceAccount{
+			Name:        account.Name,
+			Identities:  account.Identities,
+			Permissions: account.Permissions,
+		}
+	}
+	authorizer, err := mtls.NewAuthorizer(accounts)
+	if err != nil {
+		return fmt.Errorf("加载服务账户失败: %w", err)
+	}
+
+	c.MTLSConfig = tlsConfig
+	c.ServiceAuthorizer = authorizer
+
+	c.Logger.GetLogger("app").Info(context.Background(), "mTLS 已启用",
+		logger.String("port", cfg.Port),
+		logger.Int("service_accounts", len(accounts)))
+	return nil
+}
@@ -0,0 +1,67 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/notifications"
+	"go-server/internal/repositories"
+	"go-server/pkg/events"
+)
+
+// initializeNotifications 创建通知服务，注册邮件、短信和推送渠道并订阅用户生命周期事件
+// 未启用或没有数据库时不创建，通知接口返回服务不可用
+func (c *Container) initializeNotifications() error {
+	notificationsConfig := c.Config.Notifications
+	if !notificationsConfig.Enabled || c.Database == nil {
+		return nil
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	templates, err := notifications.NewTemplates(notifications.DefaultTemplates...)
+	if err != nil {
+		return fmt.Errorf("加载通知模板失败: %w", err)
+	}
+
+	// 事件总线未启用时会丢弃投递任务，改用进程内总线，任务只在本实例处理
+	var queue notifications.Queue = c.EventBus
+	if c.EventBus == nil || c.EventBus.Driver() == events.DriverNoop {
+		bus := events.NewMemoryBus()
+		queue = bus
+		// 先于取消订阅注册，关闭时在取消订阅之后等待处理中的任务完成
+		c.Shutdown.Register(PhaseStopWorkers, "notifications_queue", bus.Drain)
+		appLogger.Warn(context.Background(), "事件总线未启用，通知在进程内投递，邮件、短信和推送不会发送")
+	}
+
+	service := notifications.NewService(
+		repositories.NewNotificationRepository(c.Database.DB),
+		c.UserService,
+		queue,
+		c.userCache(),
+		templates,
+		notifications.Config{
+			Channels:       notificationsConfig.Channels,
+			UnreadCountTTL: time.Duration(notificationsConfig.UnreadCountTTL) * time.Second,
+			OnError: func(operation string, err error) {
+				appLogger.Warn(context.Background(), "通知"+operation+"失败", logger.Error(err))
+			},
+		},
+	)
+	service.RegisterChannel(notifications.NewEmailChannel(c.EventBus))
+	service.RegisterChannel(notifications.NewSMSChannel(c.EventBus))
+	service.RegisterChannel(notifications.NewPushChannel(c.EventBus))
+	service.SubscribeUserEvents(c.DomainEvents)
+	c.Notifications = service
+
+	c.OnStart("notifications", service.Start)
+	c.Shutdown.Register(PhaseStopWorkers, "notifications", service.Stop)
+
+	appLogger.Info(context.Background(), "通知服务已启用",
+		logger.String("channels", strings.Join(service.Channels(), ",")),
+		logger.Int("unread_count_ttl_seconds", notificationsConfig.UnreadCountTTL))
+
+	return nil
+}
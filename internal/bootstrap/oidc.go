@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/oidc"
+	"go-server/internal/repositories"
+)
+
+// initializeOIDC 创建 OpenID Connect 提供方，未启用或没有数据库时不创建，相关接口返回服务不可用
+// 授权码保存在共享缓存中，多实例部署时任一实例都能兑换
+func (c *Container) initializeOIDC() error {
+	oidcConfig := c.Config.OIDC
+	if !oidcConfig.Enabled || c.Database == nil {
+		return nil
+	}
+	if c.Cache == nil {
+		return errors.New("OpenID Connect 需要缓存保存授权码")
+	}
+
+	c.OIDC = oidc.NewService(
+		repositories.NewOAuthClientRepository(c.Database.DB),
+		c.UserService,
+		c.Cache,
+		c.JWTManager,
+		oidc.Config{
+			Issuer:     oidcConfig.Issuer,
+			ConsentURL: oidcConfig.ConsentURL,
+			CodeTTL:    time.Duration(oidcConfig.CodeTTL) * time.Second,
+			IDTokenTTL: time.Duration(oidcConfig.IDTokenTTL) * time.Second,
+		},
+	)
+
+	c.Logger.GetLogger("app").Info(context.Background(), "OpenID Connect 提供方已启用",
+		logger.String("issuer", c.OIDC.Issuer()),
+		logger.Int("code_ttl_seconds", oidcConfig.CodeTTL))
+
+	return nil
+}
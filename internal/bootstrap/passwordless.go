@@ -0,0 +1,46 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/passwordless"
+)
+
+// initializePasswordless 创建无密码登录服务，未启用或没有数据库时不创建，相关接口返回服务不可用
+// 设备码和登录链接保存在共享缓存中，多实例部署时任一实例都能兑换
+func (c *Container) initializePasswordless() error {
+	passwordlessConfig := c.Config.Passwordless
+	if !passwordlessConfig.Enabled || c.Database == nil {
+		return nil
+	}
+	if c.Cache == nil {
+		return errors.New("无密码登录需要缓存保存设备码和登录链接")
+	}
+
+	c.Passwordless = passwordless.NewService(
+		c.UserService,
+		c.Cache,
+		c.JWTManager,
+		c.EventBus,
+		passwordless.Config{
+			VerificationURL: passwordlessConfig.VerificationURL,
+			MagicLinkURL:    passwordlessConfig.MagicLinkURL,
+			DeviceCodeTTL:   time.Duration(passwordlessConfig.DeviceCodeTTL) * time.Second,
+			PollInterval:    time.Duration(passwordlessConfig.PollInterval) * time.Second,
+			MagicLinkTTL:    time.Duration(passwordlessConfig.MagicLinkTTL) * time.Second,
+		},
+	)
+
+	appLogger := c.Logger.GetLogger("app")
+	if !c.Config.Events.Enabled {
+		appLogger.Warn(context.Background(), "事件总线未启用，登录链接不会被发送")
+	}
+	appLogger.Info(context.Background(), "无密码登录已启用",
+		logger.Int("device_code_ttl_seconds", passwordlessConfig.DeviceCodeTTL),
+		logger.Int("magic_link_ttl_seconds", passwordlessConfig.MagicLinkTTL))
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/database"
+	"go-server/internal/logger"
+)
+
+// 启动检查项的状态
+const (
+	PreflightOK      = "ok"
+	PreflightSkipped = "skipped" // 当前配置下不需要检查
+	PreflightWarn    = "warn"    // 不影响启动，但相关功能受限
+	PreflightFail    = "fail"    // 终止启动
+)
+
+// preflightSeverity 状态的严重程度，用于合并同一检查项的多个问题
+var preflightSeverity = map[string]int{PreflightOK: 0, PreflightSkipped: 0, PreflightWarn: 1, PreflightFail: 2}
+
+// defaultJWTSecret 未配置 jwt.secret_key 时的默认值
+const defaultJWTSecret = "your-secret-key-change-in-production"
+
+// PreflightCheck 一项启动检查的结果
+type PreflightCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"` // 检查到的情况
+	Hint       string `json:"hint,omitempty"`   // 未通过时的处理建议
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// StartupReport 启动报告，汇总各项启动检查的结果
+type StartupReport struct {
+	Mode        string           `json:"mode"`
+	ConfigFiles []string         `json:"config_files"`
+	Checks      []PreflightCheck `json:"checks"`
+}
+
+// Status 返回最严重的检查状态
+func (r *StartupReport) Status() string {
+	status := PreflightOK
+	for _, check := range r.Checks {
+		if preflightSeverity[check.Status] > preflightSeverity[status] {
+			status = check.Status
+		}
+	}
+	return status
+}
+
+// Err 汇总失败的检查及处理建议，没有失败时返回 nil
+func (r *StartupReport) Err() error {
+	var messages []string
+	for _, check := range r.Checks {
+		if check.Status != PreflightFail {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s", check.Name, check.Detail)
+		if check.Hint != "" {
+			message += "（" + check.Hint + "）"
+		}
+		messages = append(messages, message)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("启动检查未通过: %s", strings.Join(messages, "；"))
+}
+
+// runPreflight 检查数据库、迁移、缓存、密钥和日志目录，结果作为一条启动报告写入日志
+// 有检查失败时返回错误终止启动，避免问题拖到第一个请求才暴露
+func (c *Container) runPreflight() error {
+	cfg := c.Config.Preflight
+	if !cfg.Enabled {
+		return nil
+	}
+
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) PreflightCheck
+	}{
+		{"database", c.preflightDatabase},
+		{"migrations", c.preflightMigrations},
+		{"cache", c.preflightCache},
+		{"secrets", func(ctx context.Context) PreflightCheck { return preflightSecrets(c.Config) }},
+		{"log_directory", func(ctx context.Context) PreflightCheck { return preflightLogDirectory(c.Config.Logging) }},
+	}
+
+	report := &StartupReport{Mode: c.Config.Mode, ConfigFiles: config.LoadedFiles()}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		startedAt := time.Now()
+		result := check.run(ctx)
+		cancel()
+		result.Name = check.name
+		result.DurationMs = time.Since(startedAt).Milliseconds()
+		report.Checks = append(report.Checks, result)
+	}
+	c.StartupReport = report
+
+	appLogger := c.Logger.GetLogger("app")
+	fields := []logger.Field{
+		logger.String("mode", report.Mode),
+		logger.Any("config_files", report.ConfigFiles),
+		logger.Any("checks", report.Checks),
+	}
+	switch report.Status() {
+	case PreflightFail:
+		appLogger.Error(context.Background(), "启动报告：检查未通过", fields...)
+		return report.Err()
+	case PreflightWarn:
+		appLogger.Warn(context.Background(), "启动报告：部分检查有警告", fields...)
+	default:
+		appLogger.Info(context.Background(), "启动报告：检查全部通过", fields...)
+	}
+	return nil
+}
+
+// preflightDatabase 检查数据库连通性
+func (c *Container) preflightDatabase(ctx context.Context) PreflightCheck {
+	if c.Database == nil {
+		return PreflightCheck{Status: PreflightFail, Detail: "数据库未连接", Hint: "检查 database 配置"}
+	}
+
+	sqlDB, err := c.Database.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		return PreflightCheck{
+			Status: PreflightFail,
+			Detail: err.Error(),
+			Hint:   fmt.Sprintf("确认数据库已启动，并检查 database.host（%s:%d）和网络连通性", c.Config.Database.Host, c.Config.Database.Port),
+		}
+	}
+	return PreflightCheck{Status: PreflightOK, Detail: c.Database.DB.Dialector.Name()}
+}
+
+// preflightMigrations 检查是否有未执行的 SQL 迁移
+func (c *Container) preflightMigrations(ctx context.Context) PreflightCheck {
+	if c.Database == nil {
+		return PreflightCheck{Status: PreflightSkipped, Detail: "数据库未连接"}
+	}
+
+	migrator := database.NewMigrator(c.Database.DB.WithContext(ctx), c.Logger.GetLogger("app"), &c.Config.Database)
+	pending, err := migrator.PendingMigrations()
+	if err != nil {
+		return PreflightCheck{Status: PreflightWarn, Detail: err.Error(), Hint: "无法确认迁移状态，可运行 go run ./cmd/migrate -action=status 查看"}
+	}
+	if len(pending) == 0 {
+		return PreflightCheck{Status: PreflightOK, Detail: "没有待执行的迁移"}
+	}
+
+	status := PreflightWarn
+	if c.Config.Preflight.FailOnPendingMigrations {
+		status = PreflightFail
+	}
+	return PreflightCheck{
+		Status: status,
+		Detail: fmt.Sprintf("%d 个迁移待执行: %s", len(pending), strings.Join(pending, ", ")),
+		Hint:   "运行 go run ./cmd/migrate -action=up",
+	}
+}
+
+// preflightCache 检查缓存是否可用，缓存不可用时服务仍可降级运行
+func (c *Container) preflightCache(ctx context.Context) PreflightCheck {
+	err := errors.New("缓存初始化失败")
+	if c.Cache != nil {
+		err = c.Cache.Health(ctx)
+	}
+	if err == nil {
+		return PreflightCheck{Status: PreflightOK, Detail: c.Config.Cache.Driver}
+	}
+
+	status := PreflightWarn
+	if c.Config.Preflight.RequireCache {
+		status = PreflightFail
+	}
+	hint := fmt.Sprintf("检查 redis.host（%s:%d）和 redis.password；缓存不可用时令牌黑名单、分布式限流和响应缓存停用", c.Config.Redis.Host, c.Config.Redis.Port)
+	if c.Config.Cache.Driver == "memcached" {
+		hint = "检查 cache.memcached.servers；缓存不可用时令牌黑名单、分布式限流和响应缓存停用"
+	}
+	return PreflightCheck{Status: status, Detail: err.Error(), Hint: hint}
+}
+
+// preflightSecrets 检查密钥引用是否已解析，以及签发令牌所需的密钥是否存在
+func preflightSecrets(cfg *config.Config) PreflightCheck {
+	result := PreflightCheck{Status: PreflightOK}
+	var details, hints []string
+	problem := func(status, detail, hint string) {
+		if preflightSeverity[status] > preflightSeverity[result.Status] {
+			result.Status = status
+		}
+		details = append(details, detail)
+		hints = append(hints, hint)
+	}
+
+	if unresolved := config.UnresolvedSecrets(cfg); len(unresolved) > 0 {
+		problem(PreflightFail, "密钥引用未解析: "+strings.Join(unresolved, ", "), "配置 secrets.vault 或 secrets.aws 中对应的密钥后端")
+	}
+
+	switch cfg.JWT.Algorithm {
+	case "RS256", "ES256":
+		if cfg.JWT.PrivateKey == "" && cfg.JWT.PrivateKeyFile == "" {
+			problem(PreflightFail, "jwt.private_key 和 jwt.private_key_file 均为空", "通过 APP_JWT_PRIVATE_KEY 环境变量或外部密钥引用设置私钥")
+		} else if cfg.JWT.PrivateKey == "" {
+			if _, err := os.Stat(cfg.JWT.PrivateKeyFile); err != nil {
+				problem(PreflightFail, "无法读取 jwt.private_key_file: "+err.Error(), "确认私钥文件已挂载到容器")
+			}
+		}
+	default:
+		if cfg.JWT.SecretKey == "" {
+			problem(PreflightFail, "jwt.secret_key 为空", "通过 APP_JWT_SECRET_KEY 环境变量或外部密钥引用设置")
+		} else if cfg.JWT.SecretKey == defaultJWTSecret {
+			problem(PreflightWarn, "jwt.secret_key 使用默认值", "部署前通过 APP_JWT_SECRET_KEY 环境变量或外部密钥引用设置")
+		}
+	}
+
+	result.Detail = strings.Join(details, "；")
+	result.Hint = strings.Join(hints, "；")
+	return result
+}
+
+// preflightLogDirectory 检查日志目录是否可写；日志文件按需创建，不检查时要到第一次写入才会发现权限问题
+func preflightLogDirectory(cfg config.LoggingConfig) PreflightCheck {
+	if cfg.Output != "file" && cfg.Output != "both" {
+		return PreflightCheck{Status: PreflightSkipped, Detail: "日志输出到 " + cfg.Output}
+	}
+
+	hint := "检查 logging.directory 的权限，或将 logging.output 改为 stdout"
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return PreflightCheck{Status: PreflightFail, Detail: err.Error(), Hint: hint}
+	}
+	file, err := os.CreateTemp(cfg.Directory, ".preflight-*")
+	if err != nil {
+		return PreflightCheck{Status: PreflightFail, Detail: err.Error(), Hint: hint}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return PreflightCheck{Status: PreflightOK, Detail: cfg.Directory}
+}
@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-server/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupReport_Err(t *testing.T) {
+	report := &StartupReport{Checks: []PreflightCheck{
+		{Name: "database", Status: PreflightOK},
+		{Name: "cache", Status: PreflightWarn, Detail: "connection refused"},
+	}}
+	assert.Equal(t, PreflightWarn, report.Status())
+	assert.NoError(t, report.Err(), "警告不终止启动")
+
+	report.Checks = append(report.Checks,
+		PreflightCheck{Name: "migrations", Status: PreflightFail, Detail: "2 个迁移待执行", Hint: "运行迁移"},
+		PreflightCheck{Name: "secrets", Status: PreflightFail, Detail: "jwt.secret_key 为空"})
+	assert.Equal(t, PreflightFail, report.Status())
+	assert.EqualError(t, report.Err(), "启动检查未通过: migrations: 2 个迁移待执行（运行迁移）；secrets: jwt.secret_key 为空")
+}
+
+func TestPreflightSecrets(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{Algorithm: "HS256", SecretKey: "jwt-secret-with-at-least-32-characters"}}
+	assert.Equal(t, PreflightOK, preflightSecrets(cfg).Status)
+
+	cfg.JWT.SecretKey = defaultJWTSecret
+	assert.Equal(t, PreflightWarn, preflightSecrets(cfg).Status)
+
+	cfg.JWT.SecretKey = ""
+	cfg.Database.Password = "vault://secret/data/go-server#db_password"
+	check := preflightSecrets(cfg)
+	assert.Equal(t, PreflightFail, check.Status)
+	assert.Contains(t, check.Detail, "database.password")
+	assert.Contains(t, check.Detail, "jwt.secret_key 为空")
+
+	cfg = &config.Config{JWT: config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem")}}
+	check = preflightSecrets(cfg)
+	assert.Equal(t, PreflightFail, check.Status)
+	assert.Contains(t, check.Detail, "jwt.private_key_file")
+}
+
+func TestPreflightLogDirectory(t *testing.T) {
+	assert.Equal(t, PreflightSkipped, preflightLogDirectory(config.LoggingConfig{Output: "stdout"}).Status)
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	check := preflightLogDirectory(config.LoggingConfig{Output: "file", Directory: dir})
+	assert.Equal(t, PreflightOK, check.Status)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "检查后删除临时文件")
+
+	// 日志目录与已有文件同名时无法创建
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	check = preflightLogDirectory(config.LoggingConfig{Output: "both", Directory: file})
+	assert.Equal(t, PreflightFail, check.Status)
+	assert.Contains(t, check.Hint, "logging.directory")
+}
@@ -0,0 +1,54 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-server/internal/config"
+	"go-server/internal/logger"
+
+	"github.com/spf13/viper"
+)
+
+// ErrUnknownConfigSection 指定的配置部分不存在
+var ErrUnknownConfigSection = errors.New("未知的配置部分")
+
+// PrintConfig 加载配置并输出所有分层合并后生效的配置，section 非空时只输出该顶层部分
+func PrintConfig(w io.Writer, section string) error {
+	if _, err := config.LoadConfig(); err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	return RenderConfig(w, viper.AllSettings(), section)
+}
+
+// RenderConfig 以缩进的 JSON 输出配置，密钥和密码替换为占位符
+func RenderConfig(w io.Writer, settings map[string]interface{}, section string) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("编码配置失败: %w", err)
+	}
+
+	redactor := logger.NewRedactor(append(config.SecretFieldNames(), "password", "secret", "token", "dsn"))
+	redacted, ok := redactor.RedactJSON(data)
+	if !ok {
+		return errors.New("配置脱敏失败")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(redacted, &value); err != nil {
+		return fmt.Errorf("解码配置失败: %w", err)
+	}
+	if section != "" {
+		value, ok = value.(map[string]interface{})[strings.ToLower(section)]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownConfigSection, section)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"go-server/internal/logger"
+	"go-server/internal/privacy"
+	"go-server/internal/repositories"
+)
+
+// initializePrivacy 创建个人数据服务，没有数据库时不创建，个人数据接口返回服务不可用
+// 抹除经由缓存仓库写入，失效用户原邮箱、用户名的缓存和为其缓存的响应
+func (c *Container) initializePrivacy() error {
+	if c.Database == nil {
+		return nil
+	}
+
+	repo := c.UserRepository
+	if userCache := c.userCache(); userCache != nil {
+		repo = repositories.NewCachedUserRepository(repo, userCache)
+	}
+	users, ok := repo.(repositories.UserEraser)
+	if !ok {
+		return fmt.Errorf("用户仓库不支持抹除个人数据: %T", repo)
+	}
+
+	// 通知服务未启用时直接读写通知表，之前写入的站内信同样需要导出和删除
+	inbox := privacy.NewRepositoryInbox(repositories.NewNotificationRepository(c.Database.DB))
+	if c.Notifications != nil {
+		inbox = c.Notifications
+	}
+
+	c.Privacy = privacy.NewService(users, inbox, c.Storage, c.userCache(), c.BlacklistService)
+
+	c.Logger.GetLogger("app").Info(context.Background(), "个人数据服务已启用",
+		logger.Bool("notifications_enabled", c.Notifications != nil),
+		logger.Bool("storage", c.Storage != nil),
+		logger.Bool("token_revocation", c.BlacklistService != nil))
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package bootstrap
+
+import (
+	"context"
+	"strings"
+
+	"go-server/internal/logger"
+	"go-server/pkg/cache"
+	"go-server/pkg/quota"
+)
+
+// initializeQuota 初始化用量配额，按自然月统计每个用户的用量
+// 缓存是 Redis 时计数在实例间共享，否则保存在内存中，只对当前实例有效；启用订阅时用户套餐的配额覆盖同名的默认配额
+func (c *Container) initializeQuota() {
+	cfg := c.Config.Quota
+	if !cfg.Enabled {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	var store quota.Store
+	if redisCache, ok := c.Cache.(*cache.RedisCache); ok {
+		store = quota.NewRedisStore(redisCache.GetClient(), cfg.KeyPrefix)
+	} else {
+		store = quota.NewMemoryStore()
+		appLogger.Warn(context.Background(), "缓存不是 Redis，用量配额计数将保存在内存中，仅对当前实例有效")
+	}
+
+	limits := make([]quota.Limit, 0, len(cfg.Limits))
+	for _, limit := range cfg.Limits {
+		limits = append(limits, quota.Limit{Name: limit.Name, Soft: limit.Soft, Hard: limit.Hard})
+	}
+	config := quota.Config{
+		OnSoftLimit: func(subject string, usage quota.Usage) {
+			appLogger.Warn(context.Background(), "用量已达到软限制",
+				logger.String("subject", subject),
+				logger.String("quota", usage.Quota),
+				logger.Int64("used", usage.Used),
+				logger.Int64("soft", usage.Soft),
+				logger.Int64("hard", usage.Hard))
+		},
+	}
+	if c.Billing != nil {
+		config.Limits = c.planQuotaLimits
+	}
+	c.Quota = quota.New(store, limits, config)
+
+	appLogger.Info(context.Background(), "用量配额已启用", logger.Int("limits", len(limits)))
+}
+
+// planQuotaLimits 返回用户套餐的配额，查询套餐失败时使用默认配额
+func (c *Container) planQuotaLimits(ctx context.Context, subject string) []quota.Limit {
+	userID, ok := strings.CutPrefix(subject, "user:")
+	if !ok {
+		return nil
+	}
+	plan, err := c.Billing.PlanForUser(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	limits := make([]quota.Limit, 0, len(plan.Quotas))
+	for _, limit := range plan.Quotas {
+		limits = append(limits, quota.Limit{Name: limit.Name, Soft: limit.Soft, Hard: limit.Hard})
+	}
+	return limits
+}
@@ -0,0 +1,43 @@
+package bootstrap
+
+import (
+	"context"
+
+	"go-server/internal/logger"
+	"go-server/internal/middleware"
+	"go-server/pkg/cache"
+)
+
+// initializeRateLimitOverrides 加载管理员设置的运行时速率限制覆盖并订阅其他实例的修改
+// 覆盖保存在 Redis 缓存中，缓存不是 Redis 时覆盖只对当前实例有效且重启后丢失
+func (c *Container) initializeRateLimitOverrides() {
+	appLogger := c.Logger.GetLogger("app")
+	redisCache, isRedis := c.Cache.(*cache.RedisCache)
+	if !isRedis {
+		appLogger.Warn(context.Background(), "缓存不是 Redis，运行时速率限制修改仅对当前实例有效")
+		return
+	}
+
+	c.RateLimiter.SetOverrideStore(middleware.NewRedisRateLimitOverrideStore(redisCache.GetClient(), c.Config.RateLimit.RedisKey))
+	if err := c.RateLimiter.ReloadOverrides(context.Background()); err != nil {
+		appLogger.Warn(context.Background(), "加载运行时速率限制失败，暂时使用配置文件中的限制",
+			logger.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.RateLimiter.WatchOverrides(ctx, func(err error) {
+			appLogger.Warn(ctx, "同步运行时速率限制失败", logger.Error(err))
+		})
+	}()
+	c.Shutdown.Register(PhaseStopWorkers, "rate_limit_overrides", func(ctx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+}
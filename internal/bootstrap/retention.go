@@ -0,0 +1,84 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/repositories"
+	"go-server/internal/retention"
+)
+
+// initializeRetention 创建数据保留策略任务，按配置的策略定期清理过期数据
+// 未启用或没有数据库时不创建，保留策略接口返回服务不可用
+func (c *Container) initializeRetention() {
+	retentionConfig := c.Config.Retention
+	if !retentionConfig.Enabled || c.Database == nil {
+		return
+	}
+
+	appLogger := c.Logger.GetLogger("app")
+	policies := make([]retention.Policy, 0, len(retentionConfig.Policies))
+	for _, policyConfig := range retentionConfig.Policies {
+		policy := retention.Policy{
+			Table:     policyConfig.Table,
+			Retention: time.Duration(policyConfig.Retention) * 24 * time.Hour,
+		}
+		switch policyConfig.Table {
+		case retention.TableUsers:
+			purger, ok := c.UserRepository.(repositories.UserPurger)
+			if !ok {
+				appLogger.Warn(context.Background(), "用户仓储不支持清理已删除的用户，跳过保留策略",
+					logger.String("table", policyConfig.Table))
+				continue
+			}
+			policy.Target = retention.DeletedUsers(purger)
+		case retention.TableMetricsSnapshots:
+			purger, ok := repositories.NewMetricsSnapshotRepository(c.Database.DB).(repositories.MetricsSnapshotPurger)
+			if !ok {
+				continue
+			}
+			policy.Target = retention.MetricsSnapshots(purger)
+		default:
+			// 配置校验已拒绝不支持的表
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	engine := retention.NewEngine(policies, c.AuditRecorder, retention.Config{
+		Interval:  time.Duration(retentionConfig.Interval) * time.Minute,
+		BatchSize: retentionConfig.BatchSize,
+		DryRun:    retentionConfig.DryRun,
+		OnReport: func(report *retention.Report) {
+			for _, result := range report.Results {
+				fields := []logger.Field{
+					logger.String("table", result.Table),
+					logger.String("retention", result.Retention),
+					logger.String("cutoff", result.Cutoff.Format(time.RFC3339)),
+					logger.Int64("rows", result.Rows),
+					logger.Bool("dry_run", report.DryRun),
+				}
+				if result.Error != "" {
+					appLogger.Warn(context.Background(), "数据保留策略清理失败", append(fields, logger.String("error", result.Error))...)
+				} else if result.Rows > 0 || report.DryRun {
+					appLogger.Info(context.Background(), "数据保留策略清理完成", fields...)
+				}
+			}
+		},
+	})
+	c.Retention = engine
+
+	c.OnStart("retention", func(ctx context.Context) error {
+		engine.Start()
+		return nil
+	})
+
+	// 等待正在执行的清理结束，须在关闭数据库之前执行
+	c.Shutdown.Register(PhaseStopWorkers, "retention", engine.Stop)
+
+	appLogger.Info(context.Background(), "数据保留策略已启用",
+		logger.Int("interval_minutes", retentionConfig.Interval),
+		logger.Int("policies", len(policies)),
+		logger.Bool("dry_run", retentionConfig.DryRun))
+}
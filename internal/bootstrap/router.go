@@ -3,16 +3,49 @@ package bootstrap
 import (
 	"context"
 
+	"go-server/internal/handlers"
 	"go-server/internal/routes"
 )
 
 // initializeRouter 初始化路由
 func (c *Container) initializeRouter() error {
+	// 速率限制器在中间件阶段创建，其管理处理器在这里创建
+	c.RateLimitHandler = handlers.NewRateLimitHandler(c.RateLimiter, c.AuditRecorder)
+
 	// 创建路由
 	c.Router = routes.NewRouter(
 		c.AuthHandler,
 		c.UserHandler,
 		c.HealthHandler,
+		c.AvatarHandler,
+		c.ProfileHandler,
+		c.AdminUserHandler,
+		c.AdminOverviewHandler,
+		c.MetricsHandler,
+		c.LoggingHandler,
+		c.MetaHandler,
+		c.CacheHandler,
+		c.FeatureFlagHandler,
+		c.BanListHandler,
+		c.NotificationHandler,
+		c.AnnouncementHandler,
+		c.ExportHandler,
+		c.ImportHandler,
+		c.PrivacyHandler,
+		c.OIDCHandler,
+		c.SAMLHandler,
+		c.PasswordlessHandler,
+		c.QuotaHandler,
+		c.BillingHandler,
+		c.MaintenanceHandler,
+		c.DrainHandler,
+		c.RateLimitHandler,
+		c.RetentionHandler,
+		c.BackupHandler,
+		c.ResponseCache,
+		c.Coalescer,
+		c.BanList,
+		c.Quota,
 		c.JWTManager,
 		c.UserRepository,
 		c.Middlewares,
@@ -21,6 +54,14 @@ func (c *Container) initializeRouter() error {
 	// 设置路由
 	c.Router.SetupRoutes()
 
+	// 启用 mTLS 时注册供内部服务调用的路由
+	if c.ServiceAuthorizer != nil {
+		routes.SetupInternalRoutes(c.Router.GetEngine(), c.ServiceAuthorizer, c.UserHandler)
+	}
+
+	// 本地存储时提供已上传文件的访问
+	c.mountLocalStorage(c.Router.GetEngine())
+
 	c.Logger.GetLogger("app").Info(context.Background(), "路由系统已初始化")
 
 	return nil
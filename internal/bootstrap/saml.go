@@ -0,0 +1,72 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/sso"
+	"go-server/internal/tenancy"
+	"go-server/pkg/saml"
+)
+
+// initializeSAML 创建 SAML 单点登录服务，未启用或没有数据库时不创建，相关接口返回服务不可用
+// 认证请求ID保存在共享缓存中，多实例部署时任一实例都能处理身份提供方提交的响应
+func (c *Container) initializeSAML() error {
+	samlConfig := c.Config.SAML
+	if !samlConfig.Enabled || c.Database == nil {
+		return nil
+	}
+	if c.Cache == nil {
+		return errors.New("SAML 单点登录需要缓存保存认证请求")
+	}
+
+	baseURL := strings.TrimSuffix(samlConfig.BaseURL, "/")
+	providers := make([]*sso.Provider, 0, len(samlConfig.Providers))
+	for _, provider := range samlConfig.Providers {
+		cert, err := saml.ParseCertificate(provider.IdPCertificate)
+		if err != nil {
+			return fmt.Errorf("租户 %s 的身份提供方证书无效: %w", provider.Tenant, err)
+		}
+		prefix := baseURL + "/api/v1/auth/saml/" + provider.Tenant
+		providers = append(providers, &sso.Provider{
+			Tenant: provider.Tenant,
+			SP: &saml.ServiceProvider{
+				EntityID: prefix + "/metadata",
+				ACSURL:   prefix + "/acs",
+				IdP: saml.IdentityProvider{
+					EntityID:    provider.IdPEntityID,
+					SSOURL:      provider.IdPSSOURL,
+					Certificate: cert,
+				},
+				ClockSkew: time.Duration(samlConfig.ClockSkew) * time.Second,
+			},
+			AutoProvision: provider.AutoProvision,
+			Attributes: sso.AttributeMapping{
+				Email:     provider.EmailAttribute,
+				Username:  provider.UsernameAttribute,
+				FirstName: provider.FirstNameAttribute,
+				LastName:  provider.LastNameAttribute,
+			},
+		})
+	}
+
+	// 租户来自路径而不是租户解析中间件，启用多租户时单独查找
+	var tenants sso.TenantLookup
+	if c.Config.Tenancy.Enabled {
+		tenants = tenancy.NewResolver(c.TenantRepository, time.Duration(c.Config.Tenancy.CacheTTL)*time.Second)
+	}
+
+	c.SAML = sso.NewService(providers, c.UserService, tenants, c.Cache, c.JWTManager, sso.Config{
+		SuccessURL: samlConfig.SuccessURL,
+		RequestTTL: time.Duration(samlConfig.RequestTTL) * time.Second,
+	})
+
+	c.Logger.GetLogger("app").Info(context.Background(), "SAML 单点登录已启用",
+		logger.Int("providers", len(providers)))
+
+	return nil
+}
@@ -2,7 +2,10 @@ package bootstrap
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,6 +14,8 @@ import (
 
 	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/pkg/autotls"
+	"go-server/pkg/mtls"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,10 +25,17 @@ type Server struct {
 	httpServer *http.Server
 	config     *config.Config
 	logger     logger.Logger
+
+	// 自动 TLS 证书，为 nil 时提供 HTTP
+	certManager *autotls.Manager
+	// http-01 验证服务器，未使用 http-01 验证时为 nil
+	challengeServer *http.Server
+	// 内部服务调用的 mTLS 服务器，未启用 mTLS 时为 nil
+	mtlsServer *http.Server
 }
 
-// NewServer 创建新的HTTP服务器
-func NewServer(cfg *config.Config, engine *gin.Engine, appLogger logger.Logger) *Server {
+// NewServer 创建新的HTTP服务器，certManager 不为 nil 时提供 HTTPS，mtlsConfig 不为 nil 时另外启动 mTLS 监听器
+func NewServer(cfg *config.Config, engine *gin.Engine, appLogger logger.Logger, certManager *autotls.Manager, mtlsConfig *tls.Config) *Server {
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
 		Handler:      engine,
@@ -31,36 +43,137 @@ func NewServer(cfg *config.Config, engine *gin.Engine, appLogger logger.Logger)
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
-	return &Server{
-		httpServer: server,
-		config:     cfg,
-		logger:     appLogger,
+	s := &Server{
+		httpServer:  server,
+		config:      cfg,
+		logger:      appLogger,
+		certManager: certManager,
+	}
+
+	if certManager != nil {
+		server.TLSConfig = certManager.TLSConfig()
+		if cfg.Server.ACME.Challenge == autotls.ChallengeHTTP01 {
+			// 处理 http-01 验证请求，其他请求重定向到 HTTPS
+			s.challengeServer = &http.Server{
+				Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.ACME.HTTPPort),
+				Handler:      certManager.HTTPHandler(nil),
+				ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+				WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+			}
+		}
+	}
+
+	if mtlsConfig != nil {
+		// 与主端口使用同一个路由，只有该端口的请求带有客户端证书身份
+		s.mtlsServer = &http.Server{
+			Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.MTLS.Port),
+			Handler:      mtls.Handler(engine),
+			TLSConfig:    mtlsConfig,
+			ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+			WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		}
 	}
+
+	return s
 }
 
-// Start 启动HTTP服务器
+// servers 返回需要启动的全部服务器，主服务器在前
+func (s *Server) servers() []*http.Server {
+	servers := []*http.Server{s.httpServer}
+	if s.challengeServer != nil {
+		servers = append(servers, s.challengeServer)
+	}
+	if s.mtlsServer != nil {
+		servers = append(servers, s.mtlsServer)
+	}
+	return servers
+}
+
+// Start 启动HTTP服务器，以及 ACME 验证和 mTLS 监听器，任一服务器停止时返回
 func (s *Server) Start() error {
+	scheme := "http"
+	if s.certManager != nil {
+		scheme = "https"
+	}
+
 	s.logger.Info(context.Background(), "启动服务器",
 		logger.String("address", s.httpServer.Addr),
-		logger.String("swagger_url", fmt.Sprintf("http://%s:%s/swagger/index.html",
-			s.config.Server.Host, s.config.Server.Port)))
+		logger.String("swagger_url", fmt.Sprintf("%s://%s:%s/swagger/index.html",
+			scheme, s.config.Server.Host, s.config.Server.Port)))
 
 	s.logger.Info(context.Background(), "健康检查端点可用",
-		logger.String("health_url", fmt.Sprintf("http://%s:%s/api/v1/health",
-			s.config.Server.Host, s.config.Server.Port)))
+		logger.String("health_url", fmt.Sprintf("%s://%s:%s/api/v1/health",
+			scheme, s.config.Server.Host, s.config.Server.Port)),
+		logger.String("liveness_url", fmt.Sprintf("%s://%s:%s/healthz",
+			scheme, s.config.Server.Host, s.config.Server.Port)),
+		logger.String("readiness_url", fmt.Sprintf("%s://%s:%s/readyz",
+			scheme, s.config.Server.Host, s.config.Server.Port)))
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("服务器启动失败: %w", err)
+	// 先监听全部端口，任一端口不可用时不启动任何服务器
+	servers := s.servers()
+	listeners := make([]net.Listener, 0, len(servers))
+	for _, server := range servers {
+		ln, err := net.Listen("tcp", server.Addr)
+		if err != nil {
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			return fmt.Errorf("服务器启动失败: %w", err)
+		}
+		listeners = append(listeners, ln)
 	}
 
-	return nil
+	if s.challengeServer != nil {
+		s.logger.Info(context.Background(), "ACME http-01 验证服务器已启动",
+			logger.String("address", s.challengeServer.Addr))
+	}
+	if s.mtlsServer != nil {
+		s.logger.Info(context.Background(), "mTLS 服务器已启动",
+			logger.String("address", s.mtlsServer.Addr))
+	}
+
+	errs := make(chan error, len(servers))
+	for i, server := range servers {
+		go func() {
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ServeTLS(listeners[i], "", "")
+			} else {
+				err = server.Serve(listeners[i])
+			}
+			if err != nil && err != http.ErrServerClosed {
+				errs <- fmt.Errorf("服务器 %s 运行失败: %w", server.Addr, err)
+				return
+			}
+			errs <- nil
+		}()
+	}
+
+	if s.certManager != nil {
+		// 开始监听后再预先申请证书，tls-alpn-01 验证需要 HTTPS 端口可用
+		go func() {
+			if err := s.certManager.Prefetch(); err != nil {
+				s.logger.Warn(context.Background(), "预先获取 TLS 证书失败，将在首次握手时重试", logger.Error(err))
+				return
+			}
+			s.logger.Info(context.Background(), "TLS 证书已就绪", logger.Any("domains", s.certManager.Domains()))
+		}()
+	}
+
+	return <-errs
 }
 
 // Shutdown 优雅关闭服务器
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info(ctx, "正在优雅关闭服务器...")
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
+	var errs []error
+	for _, server := range s.servers() {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
 		return fmt.Errorf("服务器关闭失败: %w", err)
 	}
 
@@ -72,13 +185,24 @@ func (s *Server) Shutdown(ctx context.Context) error {
 func Run(container *Container) error {
 	appLogger := container.Logger.GetLogger("app")
 
+	// 启动后台任务（配置监控、缓存健康监督等）
+	if err := container.Start(context.Background()); err != nil {
+		container.Cleanup()
+		return err
+	}
+
 	// 创建服务器
 	server := NewServer(
 		container.Config,
 		container.GetEngine(),
 		appLogger,
+		container.CertManager,
+		container.MTLSConfig,
 	)
 
+	// 关闭时停止接收新连接，并等待处理中的请求完成
+	container.Shutdown.Register(PhaseDrainHTTP, "http_server", server.Shutdown)
+
 	// 记录系统架构摘要
 	logSystemSummary(container, appLogger)
 
@@ -94,23 +218,29 @@ func Run(container *Container) error {
 
 	select {
 	case err := <-serverErrors:
+		container.Cleanup()
 		return fmt.Errorf("服务器错误: %w", err)
+	case <-container.drained:
+		// 排空已停止接收请求和后台任务，关闭时只需关闭服务器和资源
+		appLogger.Info(context.Background(), "实例已排空，开始关闭")
+		if err := container.Shutdown.Shutdown(context.Background()); err != nil {
+			return fmt.Errorf("优雅关闭未完全成功: %w", err)
+		}
+		return nil
 	case sig := <-quit:
-		appLogger.Info(context.Background(), "收到关闭信号",
+		appLogger.Info(context.Background(), "收到关闭信号，开始优雅关闭",
 			logger.String("signal", sig.String()))
 
-		// 给服务器5秒时间来完成当前正在处理的请求
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		// 关闭期间再次收到信号时立即退出
+		go func() {
+			<-quit
+			fmt.Fprintln(os.Stderr, "再次收到关闭信号，强制退出")
+			os.Exit(1)
+		}()
 
-		if err := server.Shutdown(ctx); err != nil {
-			return fmt.Errorf("强制关闭服务器: %w", err)
+		if err := container.Shutdown.Shutdown(context.Background()); err != nil {
+			return fmt.Errorf("优雅关闭未完全成功: %w", err)
 		}
-
-		// 清理资源
-		container.Cleanup()
-
-		appLogger.Info(context.Background(), "应用程序已优雅退出")
 		return nil
 	}
 }
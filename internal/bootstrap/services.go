@@ -2,17 +2,29 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"go-server/internal/audit"
 	"go-server/internal/handlers"
 	"go-server/internal/logger"
+	"go-server/internal/metrics"
 	"go-server/internal/repositories"
 	"go-server/internal/services"
+	"go-server/internal/validation"
 )
 
 // initializeRepositories 初始化仓储层
 func (c *Container) initializeRepositories() error {
 	// 初始化用户仓储
 	c.UserRepository = repositories.NewUserRepository(c.Database.DB)
+	if setter, ok := c.UserRepository.(repositories.RetryPolicySetter); ok {
+		// 序列化失败、死锁和连接中断按 database.retry 重试
+		setter.SetRetryPolicy(c.Database.RetryPolicy())
+	}
+
+	// 初始化租户仓储
+	c.TenantRepository = repositories.NewTenantRepository(c.Database.DB)
 
 	return nil
 }
@@ -24,16 +36,45 @@ func (c *Container) initializeServices() error {
 	// 根据是否有缓存，创建相应的用户服务
 	if c.Cache != nil {
 		// 使用支持缓存的服务
-		c.UserService = services.NewUserServiceWithCache(c.UserRepository, c.Cache)
+		codec, err := cacheCodec(c.Config)
+		if err != nil {
+			return err
+		}
+		c.UserService = services.NewUserServiceWithCache(c.UserRepository, c.userCache(),
+			services.WithEventPublisher(c.EventBus),
+			services.WithDomainEvents(c.DomainEvents),
+			services.WithPasswordHasher(c.PasswordHasher),
+			services.WithPasswordChecker(c.PasswordChecker),
+			services.WithCacheCodec(codec),
+			services.WithCacheErrorHandler(func(operation string, err error) {
+				appLogger.Warn(context.Background(), "用户缓存"+operation+"失败", logger.Error(err))
+			}),
+			services.WithLastLoginStore(c.initializeLastLoginBatching()))
+
+		cacheTTL := time.Duration(c.Config.Redis.CacheTTL) * time.Second
+		if setter, ok := c.UserService.(services.CacheTTLSetter); ok {
+			setter.SetCacheTTL(cacheTTL)
+		}
+		if setter, ok := c.UserService.(services.NegativeCacheTTLSetter); ok {
+			setter.SetNegativeCacheTTL(time.Duration(c.Config.Redis.NegativeCacheTTL) * time.Second)
+		}
+		if setter, ok := c.UserService.(services.CacheMethodTTLSetter); ok {
+			setter.SetCacheMethodTTLs(cacheMethodTTLs(c.Config))
+		}
 
 		appLogger.Info(context.Background(), "用户服务已初始化，支持Redis缓存",
-			logger.String("cache_type", "Redis"),
-			logger.String("ttl", "5分钟"))
+			logger.String("cache_type", c.Config.Cache.Driver),
+			logger.String("ttl", cacheTTL.String()),
+			logger.String("codec", codec.Name()))
 		appLogger.Info(context.Background(), "频繁访问的数据将从Redis缓存提供")
 		appLogger.Info(context.Background(), "缓存内存使用将由Redis管理，当内存超过80%时使用LRU淘汰策略")
 	} else {
 		// 无缓存服务
-		c.UserService = services.NewUserService(c.UserRepository)
+		c.UserService = services.NewUserService(c.UserRepository,
+			services.WithEventPublisher(c.EventBus),
+			services.WithDomainEvents(c.DomainEvents),
+			services.WithPasswordHasher(c.PasswordHasher),
+			services.WithPasswordChecker(c.PasswordChecker))
 
 		appLogger.Info(context.Background(), "用户服务已初始化，不支持缓存",
 			logger.String("reason", "Redis不可用"))
@@ -47,12 +88,88 @@ func (c *Container) initializeServices() error {
 func (c *Container) initializeHandlers() error {
 	appLogger := c.Logger.GetLogger("app")
 
+	// 注册自定义校验规则和多语言错误消息，须在处理请求之前完成
+	if err := validation.Setup(); err != nil {
+		return fmt.Errorf("初始化请求校验失败: %w", err)
+	}
+
+	// 审计事件写入 audit 模块的结构化日志
+	c.AuditRecorder = audit.NewLogRecorder(appLogger)
+
 	// 初始化处理器
-	c.AuthHandler = handlers.NewAuthHandler(c.JWTManager, c.UserService, c.BlacklistService)
+	// 按操作和结果统计登录、注册、刷新令牌和登出
+	c.AuthMetrics = metrics.NewAuthMetrics()
+	c.MetricsRegistry.RegisterAuth(c.AuthMetrics)
+	c.AuthHandler = handlers.NewAuthHandler(c.JWTManager, c.UserService, c.BlacklistService, c.AuthMetrics)
 	c.UserHandler = handlers.NewUserHandler(c.UserService)
-	c.HealthHandler = handlers.NewHealthHandler(c.Database, c.Cache)
+	var usersCacheMetrics *metrics.CacheMetrics
+	if provider, ok := c.UserService.(services.CacheMetricsProvider); ok {
+		usersCacheMetrics = provider.CacheMetrics()
+		c.MetricsRegistry.RegisterCache("users", usersCacheMetrics)
+	}
+	c.initializeMetricsSnapshots(usersCacheMetrics)
+	c.initializeRetention()
+	c.HealthHandler = handlers.NewHealthHandler(c.Database, c.Cache, c.HealthRegistry, c.BlacklistService, c.MetricsRegistry)
+	c.AvatarHandler = handlers.NewAvatarHandler(c.UserService, c.Storage, c.Config.Storage.MaxUploadSize, c.Config.Storage.AllowedContentTypes, c.MediaSigner)
+	c.ProfileHandler = handlers.NewProfileHandler(c.UserService, c.BlacklistService, c.AuditRecorder)
+	c.AdminUserHandler = handlers.NewAdminUserHandler(c.UserService, c.JWTManager, c.BlacklistService, c.AuditRecorder)
+	c.AdminOverviewHandler = handlers.NewAdminOverviewHandler(c.UserRepository, c.Database, c.Cache, c.Config.Cache.Driver, c.rateLimitStats)
+	var metricsHistory handlers.MetricsHistorySource
+	if c.MetricsSnapshotter != nil {
+		metricsHistory = c.MetricsSnapshotter
+	}
+	var requestQueries *metrics.RequestQueryMetrics
+	if c.Database != nil {
+		requestQueries = c.Database.RequestQueries()
+	}
+	c.MetricsHandler = handlers.NewMetricsHandler(handlers.MetricsHandlerOptions{
+		HTTP:            c.HTTPMetrics,
+		History:         metricsHistory,
+		RateLimitSeries: c.rateLimitTimeSeries,
+		RequestQueries:  requestQueries,
+		GeoIP:           c.GeoIP != nil,
+	})
+	c.LoggingHandler = handlers.NewLoggingHandler(c.Logger)
+	c.MetaHandler = handlers.NewMetaHandler()
+	c.CacheHandler = handlers.NewCacheHandler(c.userCache(), c.Config.Cache.Driver, c.CacheWarmer)
+	c.FeatureFlagHandler = handlers.NewFeatureFlagHandler(c.FeatureFlags, c.AuditRecorder)
+	c.BanListHandler = handlers.NewBanListHandler(c.BanList, c.AuditRecorder)
+	c.MaintenanceHandler = handlers.NewMaintenanceHandler(c.Maintenance, c.AuditRecorder)
+	c.DrainHandler = handlers.NewDrainHandler(c.Drainer, c.AuditRecorder)
+	c.RetentionHandler = handlers.NewRetentionHandler(c.Retention)
+	c.BackupHandler = handlers.NewBackupHandler(c.Backups, c.AuditRecorder)
+	c.NotificationHandler = handlers.NewNotificationHandler(c.Notifications)
+	var streamInterval time.Duration
+	if c.Config.Announcements.Stream {
+		streamInterval = time.Duration(c.Config.Announcements.StreamInterval) * time.Second
+	}
+	c.AnnouncementHandler = handlers.NewAnnouncementHandler(c.Announcements, c.AuditRecorder, streamInterval)
+	c.ExportHandler = handlers.NewExportHandler(c.Exports, c.AuditRecorder)
+	c.ImportHandler = handlers.NewImportHandler(c.Imports, c.AuditRecorder)
+	c.PrivacyHandler = handlers.NewPrivacyHandler(c.Privacy, c.UserService, c.AuditRecorder)
+	c.OIDCHandler = handlers.NewOIDCHandler(c.OIDC, c.AuditRecorder)
+	c.SAMLHandler = handlers.NewSAMLHandler(c.SAML, c.AuditRecorder)
+	c.PasswordlessHandler = handlers.NewPasswordlessHandler(c.Passwordless, c.AuditRecorder)
+	c.QuotaHandler = handlers.NewQuotaHandler(c.Quota)
+	c.BillingHandler = handlers.NewBillingHandler(c.Billing)
 
 	appLogger.Info(context.Background(), "所有处理器已初始化")
 
 	return nil
 }
+
+// rateLimitStats 返回限流统计；限流中间件在处理器之后创建，因此在请求时读取
+func (c *Container) rateLimitStats() (metrics.RateLimitStats, bool) {
+	if c.RateLimiter == nil || !c.Config.RateLimit.Enabled {
+		return metrics.RateLimitStats{}, false
+	}
+	return c.RateLimiter.Metrics().GetStats(), true
+}
+
+// rateLimitTimeSeries 返回限流时间序列，与 rateLimitStats 一样在请求时读取
+func (c *Container) rateLimitTimeSeries(window, resolution time.Duration) ([]metrics.RateLimitTimeSeriesPoint, bool) {
+	if c.RateLimiter == nil || !c.Config.RateLimit.Enabled {
+		return nil, false
+	}
+	return c.RateLimiter.Metrics().GetTimeSeries(window, resolution), true
+}
@@ -0,0 +1,227 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/logger"
+)
+
+// ShutdownPhase 优雅关闭阶段，按声明顺序依次执行
+type ShutdownPhase int
+
+const (
+	PhaseStopAccepting  ShutdownPhase = iota // 停止接收新请求（标记未就绪，等待流量摘除）
+	PhaseDrainHTTP                           // 排空处理中的HTTP请求
+	PhaseStopWorkers                         // 停止后台任务、调度器、事件消费者和配置监控
+	PhaseCloseResources                      // 关闭数据库、缓存等共享资源
+	PhaseFlushLogs                           // 刷新并关闭日志，必须最后执行
+)
+
+// shutdownPhases 执行顺序
+var shutdownPhases = []ShutdownPhase{
+	PhaseStopAccepting,
+	PhaseDrainHTTP,
+	PhaseStopWorkers,
+	PhaseCloseResources,
+	PhaseFlushLogs,
+}
+
+// String 返回阶段名称
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PhaseStopAccepting:
+		return "stop_accepting"
+	case PhaseDrainHTTP:
+		return "drain_http"
+	case PhaseStopWorkers:
+		return "stop_workers"
+	case PhaseCloseResources:
+		return "close_resources"
+	case PhaseFlushLogs:
+		return "flush_logs"
+	default:
+		return fmt.Sprintf("phase_%d", int(p))
+	}
+}
+
+// ShutdownHook 关闭钩子，应在 ctx 到期前返回
+type ShutdownHook func(ctx context.Context) error
+
+// namedHook 带名称的关闭钩子
+type namedHook struct {
+	name string
+	hook ShutdownHook
+}
+
+// phaseRun 阶段的执行结果，保证每个阶段只执行一次
+type phaseRun struct {
+	once sync.Once
+	err  error
+}
+
+// ShutdownManager 按阶段执行组件注册的关闭钩子
+// 同一阶段内的钩子按注册的逆序执行（与 defer 一致），后初始化的组件先关闭
+type ShutdownManager struct {
+	mu       sync.Mutex
+	hooks    map[ShutdownPhase][]namedHook
+	timeouts map[ShutdownPhase]time.Duration
+	runs     map[ShutdownPhase]*phaseRun
+	logger   logger.Logger
+	once     sync.Once
+	err      error
+}
+
+// NewShutdownManager 根据配置创建关闭管理器，未配置的阶段使用默认超时时间
+func NewShutdownManager(cfg config.ShutdownConfig, appLogger logger.Logger) *ShutdownManager {
+	seconds := func(value, fallback int) time.Duration {
+		if value <= 0 {
+			value = fallback
+		}
+		return time.Duration(value) * time.Second
+	}
+
+	timeouts := map[ShutdownPhase]time.Duration{
+		PhaseStopAccepting:  time.Duration(cfg.PreStopDelay)*time.Second + 5*time.Second,
+		PhaseDrainHTTP:      seconds(cfg.DrainTimeout, 15),
+		PhaseStopWorkers:    seconds(cfg.WorkersTimeout, 10),
+		PhaseCloseResources: seconds(cfg.ResourcesTimeout, 5),
+		PhaseFlushLogs:      seconds(cfg.LogsTimeout, 3),
+	}
+
+	return &ShutdownManager{
+		hooks:    make(map[ShutdownPhase][]namedHook),
+		timeouts: timeouts,
+		runs:     make(map[ShutdownPhase]*phaseRun),
+		logger:   appLogger,
+	}
+}
+
+// Register 在指定阶段注册关闭钩子
+func (m *ShutdownManager) Register(phase ShutdownPhase, name string, hook ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks[phase] = append(m.hooks[phase], namedHook{name: name, hook: hook})
+}
+
+// SetTimeout 覆盖指定阶段的超时时间
+func (m *ShutdownManager) SetTimeout(phase ShutdownPhase, timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeouts[phase] = timeout
+}
+
+// Shutdown 依次执行所有阶段，只会执行一次，重复调用返回首次的结果
+// 某个钩子失败或超时不会阻止后续钩子执行，所有错误合并后返回
+func (m *ShutdownManager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		m.err = m.run(ctx)
+	})
+	return m.err
+}
+
+// run 执行所有关闭阶段
+func (m *ShutdownManager) run(ctx context.Context) error {
+	start := time.Now()
+	var errs []error
+
+	for _, phase := range shutdownPhases {
+		// 日志在最后阶段关闭，此前记录完成信息，避免丢失
+		if phase == PhaseFlushLogs {
+			m.logger.Info(ctx, "应用程序关闭阶段执行完毕，正在刷新日志",
+				logger.Int64("elapsed_ms", time.Since(start).Milliseconds()),
+				logger.Int("errors", len(errs)))
+		}
+
+		if err := m.RunPhase(ctx, phase); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RunPhase 提前执行单个阶段（如排空实例时），每个阶段只执行一次，Shutdown 不会重复执行已执行的阶段
+// 阶段正在执行时调用会等待其完成并返回相同的结果
+func (m *ShutdownManager) RunPhase(ctx context.Context, phase ShutdownPhase) error {
+	m.mu.Lock()
+	run, ok := m.runs[phase]
+	if !ok {
+		run = &phaseRun{}
+		m.runs[phase] = run
+	}
+	m.mu.Unlock()
+
+	run.once.Do(func() {
+		run.err = m.runPhase(ctx, phase)
+	})
+	return run.err
+}
+
+// runPhase 在阶段超时时间内按逆序执行该阶段的钩子
+func (m *ShutdownManager) runPhase(ctx context.Context, phase ShutdownPhase) error {
+	m.mu.Lock()
+	hooks := append([]namedHook(nil), m.hooks[phase]...)
+	timeout := m.timeouts[phase]
+	m.mu.Unlock()
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logPhase := phase != PhaseFlushLogs
+	if logPhase {
+		m.logger.Info(ctx, "执行关闭阶段",
+			logger.String("phase", phase.String()),
+			logger.Int("hooks", len(hooks)),
+			logger.String("timeout", timeout.String()))
+	}
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if err := runHook(phaseCtx, hook.hook); err != nil {
+			err = fmt.Errorf("%s/%s: %w", phase, hook.name, err)
+			errs = append(errs, err)
+			if logPhase {
+				m.logger.Error(ctx, "关闭钩子执行失败",
+					logger.String("phase", phase.String()),
+					logger.String("hook", hook.name),
+					logger.Error(err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHook 执行单个钩子，钩子忽略上下文时也能在超时后返回
+func runHook(ctx context.Context, hook ShutdownHook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("shutdown hook panicked: %v", rec)
+			}
+		}()
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
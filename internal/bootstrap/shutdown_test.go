@@ -0,0 +1,122 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestShutdownManager 创建使用空操作日志的关闭管理器
+func newTestShutdownManager(t *testing.T, cfg config.ShutdownConfig) *ShutdownManager {
+	manager, err := logger.NewManager(config.LoggingConfig{Level: "info", Format: "json", Output: "stdout"})
+	require.NoError(t, err)
+	return NewShutdownManager(cfg, manager.GetLogger("shutdown"))
+}
+
+func TestShutdownManager_PhaseOrder(t *testing.T) {
+	m := newTestShutdownManager(t, config.ShutdownConfig{})
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) ShutdownHook {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// 注册顺序与执行顺序无关，按阶段执行；同一阶段内逆序执行
+	m.Register(PhaseFlushLogs, "logger", record("logger"))
+	m.Register(PhaseCloseResources, "database", record("database"))
+	m.Register(PhaseCloseResources, "cache", record("cache"))
+	m.Register(PhaseStopWorkers, "events", record("events"))
+	m.Register(PhaseDrainHTTP, "http_server", record("http_server"))
+	m.Register(PhaseStopAccepting, "readiness", record("readiness"))
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.Equal(t, []string{"readiness", "http_server", "events", "cache", "database", "logger"}, order)
+}
+
+func TestShutdownManager_RunsOnce(t *testing.T) {
+	m := newTestShutdownManager(t, config.ShutdownConfig{})
+
+	calls := 0
+	m.Register(PhaseCloseResources, "database", func(ctx context.Context) error {
+		calls++
+		return errors.New("close failed")
+	})
+
+	first := m.Shutdown(context.Background())
+	second := m.Shutdown(context.Background())
+	assert.Equal(t, 1, calls)
+	assert.ErrorContains(t, first, "close_resources/database: close failed")
+	assert.Equal(t, first, second)
+}
+
+func TestShutdownManager_RunPhaseEarly(t *testing.T) {
+	m := newTestShutdownManager(t, config.ShutdownConfig{})
+
+	var order []string
+	m.Register(PhaseStopAccepting, "readiness", func(ctx context.Context) error {
+		order = append(order, "readiness")
+		return nil
+	})
+	m.Register(PhaseDrainHTTP, "http_server", func(ctx context.Context) error {
+		order = append(order, "http_server")
+		return nil
+	})
+	m.Register(PhaseStopWorkers, "events", func(ctx context.Context) error {
+		order = append(order, "events")
+		return nil
+	})
+
+	// 排空实例时提前执行的阶段在关闭时不再执行
+	require.NoError(t, m.RunPhase(context.Background(), PhaseStopAccepting))
+	require.NoError(t, m.RunPhase(context.Background(), PhaseStopWorkers))
+	require.NoError(t, m.RunPhase(context.Background(), PhaseStopWorkers))
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.Equal(t, []string{"readiness", "events", "http_server"}, order)
+}
+
+func TestShutdownManager_FailureDoesNotSkipLaterPhases(t *testing.T) {
+	m := newTestShutdownManager(t, config.ShutdownConfig{})
+	m.SetTimeout(PhaseDrainHTTP, 20*time.Millisecond)
+
+	flushed := false
+	m.Register(PhaseDrainHTTP, "stuck", func(ctx context.Context) error {
+		// 忽略上下文的钩子也不能阻塞后续阶段
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	m.Register(PhaseStopWorkers, "panics", func(ctx context.Context) error {
+		panic("boom")
+	})
+	m.Register(PhaseFlushLogs, "logger", func(ctx context.Context) error {
+		flushed = true
+		return nil
+	})
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "boom")
+	assert.True(t, flushed, "logs must be flushed even when earlier phases fail")
+}
+
+func TestShutdownPhase_String(t *testing.T) {
+	assert.Equal(t, "drain_http", PhaseDrainHTTP.String())
+	assert.Equal(t, "phase_42", ShutdownPhase(42).String())
+}
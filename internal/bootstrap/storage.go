@@ -0,0 +1,137 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go-server/internal/backups"
+	"go-server/internal/exports"
+	"go-server/internal/logger"
+	"go-server/pkg/response"
+	"go-server/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// initializeStorage 初始化对象存储
+func (c *Container) initializeStorage() error {
+	appLogger := c.Logger.GetLogger("app")
+	storageCfg := c.Config.Storage
+
+	store, err := storage.New(storage.Config{
+		Driver: storageCfg.Driver,
+		Local: storage.LocalConfig{
+			BaseDir: storageCfg.Local.BaseDir,
+			BaseURL: storageCfg.Local.BaseURL,
+		},
+		S3: storage.S3Config{
+			Endpoint:        storageCfg.S3.Endpoint,
+			Region:          storageCfg.S3.Region,
+			Bucket:          storageCfg.S3.Bucket,
+			AccessKeyID:     storageCfg.S3.AccessKeyID,
+			SecretAccessKey: storageCfg.S3.SecretAccessKey,
+			UseSSL:          storageCfg.S3.UseSSL,
+			PublicURL:       storageCfg.S3.PublicURL,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("初始化对象存储失败: %w", err)
+	}
+
+	c.Storage = store
+	if storageCfg.Media.SignedURLs {
+		c.MediaSigner = storage.NewURLSigner(c.mediaSigningKey(), time.Duration(storageCfg.Media.URLTTL)*time.Second)
+	}
+
+	appLogger.Info(context.Background(), "对象存储初始化成功",
+		logger.String("driver", store.Driver()),
+		logger.Int64("max_upload_size", storageCfg.MaxUploadSize),
+		logger.Bool("signed_media_urls", storageCfg.Media.SignedURLs))
+
+	return nil
+}
+
+// mediaSigningKey 返回媒体链接的签名密钥，未配置时从 JWT 密钥派生，与导出链接的密钥互不相同
+func (c *Container) mediaSigningKey() []byte {
+	if key := c.Config.Storage.Media.SigningKey; key != "" {
+		return []byte(key)
+	}
+	mac := hmac.New(sha256.New, []byte(c.Config.JWT.SecretKey))
+	mac.Write([]byte("media"))
+	return mac.Sum(nil)
+}
+
+// localMediaPath 返回本地驱动通过 HTTP 提供文件的路径前缀，不通过 HTTP 提供时返回空字符串
+func (c *Container) localMediaPath() string {
+	if c.Config.Storage.Driver != "local" {
+		return ""
+	}
+	baseURL := strings.TrimSuffix(c.Config.Storage.Local.BaseURL, "/")
+	if !strings.HasPrefix(baseURL, "/") {
+		return ""
+	}
+	return baseURL
+}
+
+// mountLocalStorage 本地驱动且访问前缀为路径时，通过 HTTP 提供已上传的文件
+// 导出文件只能通过签名链接下载，备份只能由管理员下载，证书缓存包含私钥，都不通过该路径提供
+// 对象键不会复用，文件以 immutable 长期缓存；启用媒体签名链接时只接受签名有效的请求
+func (c *Container) mountLocalStorage(engine *gin.Engine) {
+	local, ok := c.Storage.(*storage.LocalStorage)
+	if !ok {
+		return
+	}
+
+	baseURL := c.localMediaPath()
+	if baseURL == "" {
+		return
+	}
+
+	hidden := []string{"/" + exports.KeyPrefix, "/" + backups.KeyPrefix}
+	if acme := c.Config.Server.ACME; acme.Enabled && acme.Cache == "storage" {
+		hidden = append(hidden, "/"+strings.Trim(acme.CachePrefix, "/"))
+	}
+
+	engine.Group(baseURL, mediaMiddleware(c.MediaSigner)).StaticFS("/", hiddenPrefixFS{
+		FileSystem: gin.Dir(local.BaseDir(), false),
+		prefixes:   hidden,
+	})
+}
+
+// mediaMiddleware 设置媒体文件的缓存头，signer 不为 nil 时校验链接签名
+func mediaMiddleware(signer *storage.URLSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer != nil {
+			if err := signer.Verify(c.Request.URL.EscapedPath(), c.Request.URL.Query()); err != nil {
+				response.ForbiddenError(c, "媒体链接无效或已过期")
+				c.Abort()
+				return
+			}
+		}
+		c.Header("Cache-Control", storage.ImmutableCacheControl)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Next()
+	}
+}
+
+// hiddenPrefixFS 隐藏 prefixes 目录下的文件，访问时按不存在处理
+type hiddenPrefixFS struct {
+	http.FileSystem
+	prefixes []string
+}
+
+// Open 打开文件，name 为 http.FileServer 清理后的绝对路径
+func (fs hiddenPrefixFS) Open(name string) (http.File, error) {
+	for _, prefix := range fs.prefixes {
+		if name == prefix || strings.HasPrefix(name, prefix+"/") {
+			return nil, os.ErrNotExist
+		}
+	}
+	return fs.FileSystem.Open(name)
+}
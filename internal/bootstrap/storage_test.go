@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountLocalStorage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContainer := func(t *testing.T, signer *storage.URLSigner) *gin.Engine {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "avatars"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "avatars", "a.png"), []byte("png"), 0o644))
+		store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: dir, BaseURL: "/uploads"})
+		require.NoError(t, err)
+
+		c := &Container{Config: &config.Config{}, Storage: store, MediaSigner: signer}
+		c.Config.Storage.Driver = "local"
+		c.Config.Storage.Local.BaseURL = "/uploads"
+		engine := gin.New()
+		c.mountLocalStorage(engine)
+		return engine
+	}
+
+	get := func(engine *gin.Engine, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	t.Run("媒体文件长期缓存", func(t *testing.T) {
+		recorder := get(newContainer(t, nil), "/uploads/avatars/a.png?v=0123456789abcdef")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "png", recorder.Body.String())
+		assert.Equal(t, storage.ImmutableCacheControl, recorder.Header().Get("Cache-Control"))
+	})
+
+	t.Run("启用签名链接时校验签名", func(t *testing.T) {
+		signer := storage.NewURLSigner([]byte("media-key"), time.Hour)
+		engine := newContainer(t, signer)
+
+		assert.Equal(t, http.StatusForbidden, get(engine, "/uploads/avatars/a.png").Code)
+
+		signed, err := signer.Sign("/uploads/avatars/a.png?v=0123456789abcdef")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, get(engine, signed).Code)
+
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, get(engine, "/uploads/avatars/b.png?"+u.RawQuery).Code)
+	})
+}
@@ -0,0 +1,71 @@
+// Package buildinfo 提供构建时注入的版本信息
+//
+// 通过 -ldflags 注入，见 Makefile 的 LDFLAGS：
+//
+//	go build -ldflags "-X go-server/internal/buildinfo.Version=1.2.0 -X go-server/internal/buildinfo.GitCommit=abc123"
+//
+// 未注入时从 Go 工具链嵌入的 VCS 信息中读取提交和构建时间
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// 构建时通过 -ldflags -X 注入
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildTime = ""
+)
+
+// startTime 进程启动时间，用于计算运行时长
+var startTime = time.Now()
+
+// Info 版本和构建信息
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+}
+
+// Get 返回版本和构建信息
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// StartTime 返回进程启动时间
+func StartTime() time.Time {
+	return startTime
+}
+
+// Uptime 返回进程运行时长
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
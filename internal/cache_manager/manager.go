@@ -9,6 +9,14 @@ import (
 	"go-server/pkg/cache"
 )
 
+// UserListCacheTag 用户列表和用户计数缓存共用的标签，用于按标签批量失效而无需扫描键空间
+const UserListCacheTag = "users:list"
+
+// UserCacheTag 单个用户相关缓存共用的标签，如该用户的响应缓存，用户变更时按标签失效
+func UserCacheTag(userID string) string {
+	return "users:id:" + userID
+}
+
 // Manager 统一的缓存管理器接口
 type Manager interface {
 	// 用户相关缓存操作
@@ -71,33 +79,28 @@ func (m *manager) InvalidateUserListCache() error {
 		return nil
 	}
 
-	patterns := []string{
-		"users:all:*",
-		"users:count",
-	}
-
-	for _, pattern := range patterns {
-		if err := m.InvalidateByPattern(pattern); err != nil {
-			return fmt.Errorf("failed to invalidate user list cache pattern %s: %w", pattern, err)
-		}
+	ctx := context.Background()
+	if err := m.cache.InvalidateTag(ctx, UserListCacheTag); err != nil {
+		return fmt.Errorf("failed to invalidate user list cache tag %s: %w", UserListCacheTag, err)
 	}
 
 	return nil
 }
 
 // GetUserFromCache 从缓存获取用户信息
+// 用户按 JSON 缓存，不包含 json:"-" 字段（密码哈希、头像存储键等）
 func (m *manager) GetUserFromCache(key string) (*models.User, bool) {
 	if m.cache == nil {
 		return nil, false
 	}
 
 	ctx := context.Background()
-	cachedValue, found := m.cache.Get(ctx, key)
+	user, found := cache.GetAs[models.User](ctx, m.cache, key)
 	if !found {
 		return nil, false
 	}
 
-	return m.unmarshalUser(cachedValue)
+	return &user, true
 }
 
 // SetUserCache 设置用户信息到缓存
@@ -121,12 +124,12 @@ func (m *manager) GetUserListFromCache(key string) ([]*models.User, int64, bool)
 	}
 
 	ctx := context.Background()
-	cachedValue, found := m.cache.Get(ctx, key)
+	result, found := cache.GetAs[UserListResult](ctx, m.cache, key)
 	if !found {
 		return nil, 0, false
 	}
 
-	return m.unmarshalUserListResult(cachedValue)
+	return result.Users, result.Total, true
 }
 
 // SetUserListCache 设置用户列表到缓存
@@ -144,7 +147,7 @@ func (m *manager) SetUserListCache(key string, users []*models.User, total int64
 		Users: users,
 		Total: total,
 	}
-	return m.cache.Set(ctx, key, result, ttl)
+	return m.cache.SetWithTags(ctx, key, result, ttl, UserListCacheTag)
 }
 
 // InvalidateByPattern 按模式失效缓存
@@ -203,38 +206,8 @@ func (m *manager) Delete(ctx context.Context, key string) error {
 	return m.cache.Delete(ctx, key)
 }
 
-// Helper methods
-
 // UserListResult 用户列表缓存结果
 type UserListResult struct {
 	Users []*models.User `json:"users"`
 	Total int64          `json:"total"`
 }
-
-// unmarshalUser 反序列化用户对象
-func (m *manager) unmarshalUser(value interface{}) (*models.User, bool) {
-	if value == nil {
-		return nil, false
-	}
-
-	switch v := value.(type) {
-	case *models.User:
-		return v, true
-	default:
-		return nil, false
-	}
-}
-
-// unmarshalUserListResult 反序列化用户列表结果
-func (m *manager) unmarshalUserListResult(value interface{}) ([]*models.User, int64, bool) {
-	if value == nil {
-		return nil, 0, false
-	}
-
-	switch v := value.(type) {
-	case UserListResult:
-		return v.Users, v.Total, true
-	default:
-		return nil, 0, false
-	}
-}
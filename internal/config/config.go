@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -14,15 +19,46 @@ import (
 )
 
 type Config struct {
-	Server      ServerConfig      `mapstructure:"server"`
-	Database    DatabaseConfig    `mapstructure:"database"`
-	Auth        AuthConfig        `mapstructure:"auth"`
-	JWT         JWTConfig         `mapstructure:"jwt"`
-	Redis       RedisConfig       `mapstructure:"redis"`
-	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
-	Compression CompressionConfig `mapstructure:"compression"`
-	Logging     LoggingConfig     `mapstructure:"logging"`
-	Mode        string            `mapstructure:"mode"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Database       DatabaseConfig       `mapstructure:"database"`
+	Auth           AuthConfig           `mapstructure:"auth"`
+	JWT            JWTConfig            `mapstructure:"jwt"`
+	Redis          RedisConfig          `mapstructure:"redis"`
+	Cache          CacheConfig          `mapstructure:"cache"`
+	RateLimit      RateLimitConfig      `mapstructure:"rate_limit"`
+	Compression    CompressionConfig    `mapstructure:"compression"`
+	CORS           CORSConfig           `mapstructure:"cors"`
+	BodyLimit      BodyLimitConfig      `mapstructure:"body_limit"`
+	Idempotency    IdempotencyConfig    `mapstructure:"idempotency"`
+	ETag           ETagConfig           `mapstructure:"etag"`
+	ResponseCache  ResponseCacheConfig  `mapstructure:"response_cache"`
+	Coalescing     CoalescingConfig     `mapstructure:"coalescing"`
+	OpenAPI        OpenAPIConfig        `mapstructure:"openapi"`
+	FeatureFlags   FeatureFlagsConfig   `mapstructure:"feature_flags"`
+	Tenancy        TenancyConfig        `mapstructure:"tenancy"`
+	GeoIP          GeoIPConfig          `mapstructure:"geoip"`
+	Logging        LoggingConfig        `mapstructure:"logging"`
+	ErrorReporting ErrorReportingConfig `mapstructure:"error_reporting"`
+	Events         EventsConfig         `mapstructure:"events"`
+	Storage        StorageConfig        `mapstructure:"storage"`
+	Secrets        SecretsConfig        `mapstructure:"secrets"`
+	Remote         RemoteConfig         `mapstructure:"remote"`
+	Metrics        MetricsConfig        `mapstructure:"metrics"`
+	Notifications  NotificationsConfig  `mapstructure:"notifications"`
+	Announcements  AnnouncementsConfig  `mapstructure:"announcements"`
+	Exports        ExportsConfig        `mapstructure:"exports"`
+	Retention      RetentionConfig      `mapstructure:"retention"`
+	Backups        BackupsConfig        `mapstructure:"backups"`
+	Imports        ImportsConfig        `mapstructure:"imports"`
+	Encryption     EncryptionConfig     `mapstructure:"encryption"`
+	OIDC           OIDCConfig           `mapstructure:"oidc"`
+	SAML           SAMLConfig           `mapstructure:"saml"`
+	Passwordless   PasswordlessConfig   `mapstructure:"passwordless"`
+	Quota          QuotaConfig          `mapstructure:"quota"`
+	Billing        BillingConfig        `mapstructure:"billing"`
+	Maintenance    MaintenanceConfig    `mapstructure:"maintenance"`
+	Preflight      PreflightConfig      `mapstructure:"preflight"`
+	Mode           string               `mapstructure:"mode"`
 }
 
 // ConfigChangeType 表示配置变更的类型
@@ -37,6 +73,8 @@ const (
 	ConfigChangeTypeRedis                               // Redis配置变更
 	ConfigChangeTypeDatabase                            // 数据库配置变更
 	ConfigChangeTypeCompression                         // 压缩配置变更
+	ConfigChangeTypeCORS                                // 跨域配置变更
+	ConfigChangeTypeBodyLimit                           // 请求体大小限制配置变更
 	ConfigChangeTypeUnknown                             // 未知配置变更
 )
 
@@ -59,6 +97,11 @@ type ConfigManager struct {
 	v           *viper.Viper                               // Viper实例
 	watcher     *fsnotify.Watcher                          // 文件监控器
 	handlers    map[ConfigChangeType][]ConfigChangeHandler // 配置变更处理器
+	subscribers []Subscriber                               // 配置订阅者（校验后原子应用）
+	reloadMu    sync.Mutex                                 // 串行化重新加载
+	secrets     *SecretResolver                            // 密钥引用解析器
+	rotations   []SecretRotationHandler                    // 密钥轮换回调
+	signals     chan os.Signal                             // SIGHUP 信号（重新读取环境变量和配置文件）
 	ctx         context.Context                            // 上下文
 	cancel      context.CancelFunc                         // 取消函数
 	running     bool                                       // 是否正在运行
@@ -66,10 +109,66 @@ type ConfigManager struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port         string `mapstructure:"port"`          // 端口号
-	Host         string `mapstructure:"host"`          // 主机地址
-	ReadTimeout  int    `mapstructure:"read_timeout"`  // 读取超时时间（秒）
-	WriteTimeout int    `mapstructure:"write_timeout"` // 写入超时时间（秒）
+	Port         string         `mapstructure:"port"`          // 端口号
+	Host         string         `mapstructure:"host"`          // 主机地址
+	ReadTimeout  int            `mapstructure:"read_timeout"`  // 读取超时时间（秒）
+	WriteTimeout int            `mapstructure:"write_timeout"` // 写入超时时间（秒）
+	Shutdown     ShutdownConfig `mapstructure:"shutdown"`      // 优雅关闭配置
+
+	// 错误响应格式：envelope 为统一响应结构，problem 为 RFC 7807 application/problem+json
+	// envelope 模式下，请求头 Accept 包含 application/problem+json 的客户端仍会收到 RFC 7807 格式
+	ErrorFormat        string `mapstructure:"error_format"`
+	ProblemTypeBaseURL string `mapstructure:"problem_type_base_url"` // 问题类型URI前缀，拼接小写连字符形式的错误代码
+
+	ACME ACMEConfig `mapstructure:"acme"` // 通过 ACME 自动申请 TLS 证书
+	MTLS MTLSConfig `mapstructure:"mtls"` // 内部服务调用的双向 TLS 监听器
+}
+
+// ACMEConfig 自动 TLS 证书配置
+// 启用后服务器在 server.port 上提供 HTTPS，证书通过 ACME（默认 Let's Encrypt）申请并在到期前自动续期
+type ACMEConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // 是否启用
+	Domains      []string `mapstructure:"domains"`       // 申请证书的域名，不支持通配符
+	Email        string   `mapstructure:"email"`         // ACME 账户联系邮箱，用于接收到期提醒
+	DirectoryURL string   `mapstructure:"directory_url"` // ACME 目录地址，为空时使用 Let's Encrypt 生产环境，测试时可使用 staging 地址
+	// 验证方式：http-01 需要在 http_port（通常为 80）上监听并把其他 HTTP 请求重定向到 HTTPS；
+	// tls-alpn-01 直接在 HTTPS 端口完成验证，要求 server.port 对外为 443
+	Challenge   string `mapstructure:"challenge"`
+	HTTPPort    string `mapstructure:"http_port"`    // http-01 验证监听的端口
+	Cache       string `mapstructure:"cache"`        // 证书缓存：storage 为对象存储，redis 为 Redis；多实例部署需共享缓存
+	CachePrefix string `mapstructure:"cache_prefix"` // 缓存键前缀，使用对象存储时该前缀下的对象包含私钥，不能公开访问
+	RenewBefore int    `mapstructure:"renew_before"` // 证书到期前多少天续期
+}
+
+// MTLSConfig 双向 TLS 配置
+// 启用后在 port 上另外监听一个要求客户端证书的 HTTPS 端口，证书的 SAN 映射到服务账户，/internal/v1 下的路由按服务账户的权限授权
+type MTLSConfig struct {
+	Enabled         bool                   `mapstructure:"enabled"`          // 是否启用
+	Port            string                 `mapstructure:"port"`             // 监听端口，不能与 server.port 相同
+	CertFile        string                 `mapstructure:"cert_file"`        // 服务器证书文件（PEM）
+	KeyFile         string                 `mapstructure:"key_file"`         // 服务器私钥文件（PEM）
+	ClientCAFile    string                 `mapstructure:"client_ca_file"`   // 签发客户端证书的 CA 文件（PEM）
+	ServiceAccounts []ServiceAccountConfig `mapstructure:"service_accounts"` // 服务账户
+}
+
+// ServiceAccountConfig 服务账户配置
+type ServiceAccountConfig struct {
+	Name        string   `mapstructure:"name"`        // 账户名称，用于日志和错误信息
+	Identities  []string `mapstructure:"identities"`  // 客户端证书的 SAN（URI、DNS 名称或邮箱），如 spiffe://cluster.local/ns/billing/sa/worker
+	Permissions []string `mapstructure:"permissions"` // 权限，如 users:read；users:* 包含 users 的全部操作，* 包含全部权限
+}
+
+// ShutdownConfig 优雅关闭各阶段的超时配置（秒）
+type ShutdownConfig struct {
+	PreStopDelay     int `mapstructure:"pre_stop_delay"`    // 标记未就绪后等待负载均衡器摘除流量的时间
+	DrainTimeout     int `mapstructure:"drain_timeout"`     // 排空处理中HTTP请求的超时时间
+	WorkersTimeout   int `mapstructure:"workers_timeout"`   // 停止后台任务和事件消费者的超时时间
+	ResourcesTimeout int `mapstructure:"resources_timeout"` // 关闭数据库、缓存等资源的超时时间
+	LogsTimeout      int `mapstructure:"logs_timeout"`      // 刷新日志的超时时间
+
+	// 通过 POST /api/v1/admin/drain 排空后是否自动退出进程；为 false 时进程保持运行（未就绪）直到收到 SIGTERM，
+	// 适合 Kubernetes preStop 钩子，避免容器自行退出后被重启
+	ExitAfterDrain bool `mapstructure:"exit_after_drain"`
 }
 
 // DatabaseConfig 数据库配置
@@ -84,26 +183,156 @@ type DatabaseConfig struct {
 	MaxOpenConns    int `mapstructure:"max_open_conns"`    // 最大打开连接数
 	MaxIdleConns    int `mapstructure:"max_idle_conns"`    // 最大空闲连接数
 	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"` // 连接最大生存时间（秒）
+	// 查询监控设置
+	SlowQueryThreshold int                        `mapstructure:"slow_query_threshold"` // 慢查询阈值（毫秒）
+	RequestQueries     DatabaseRequestQueryConfig `mapstructure:"request_queries"`      // 每个请求的查询计数和 N+1 检测
+	// 写入合并设置
+	LastLoginBatching DatabaseLastLoginBatchConfig `mapstructure:"last_login_batching"` // 最后登录时间的批量写入
+	// 重试设置
+	Retry DatabaseRetryConfig `mapstructure:"retry"` // 暂时性错误（序列化失败、死锁、连接中断）的重试
+	// 迁移设置
+	MigrationLockTimeout int `mapstructure:"migration_lock_timeout"` // 等待其他实例释放迁移锁的超时时间（秒）
+}
+
+// DatabaseRequestQueryConfig 请求查询计数配置
+// 按关联ID统计每个请求执行的SQL，查询过多或同一语句重复执行时输出带调用栈的告警
+type DatabaseRequestQueryConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // 是否启用
+	MaxQueries      int  `mapstructure:"max_queries"`      // 单个请求的查询数超过该值时告警
+	RepeatThreshold int  `mapstructure:"repeat_threshold"` // 同一语句（忽略参数）在单个请求中执行达到该次数时按 N+1 告警
+	TopOffenders    int  `mapstructure:"top_offenders"`    // 管理接口列出的最差路由数
+}
+
+// DatabaseLastLoginBatchConfig 最后登录时间批量写入配置
+// 登录时间先缓冲在 Redis（未使用 Redis 缓存时为进程内存）中，按间隔批量写入数据库，避免每次登录执行一条 UPDATE
+type DatabaseLastLoginBatchConfig struct {
+	Enabled   bool `mapstructure:"enabled"`    // 是否启用，需要启用缓存
+	Interval  int  `mapstructure:"interval"`   // 写入间隔（秒）
+	BatchSize int  `mapstructure:"batch_size"` // 每条 UPDATE 语句写入的最大用户数
+}
+
+// DatabaseRetryConfig 数据库暂时性错误重试配置
+// 序列化失败、死锁和连接中断时按带抖动的指数退避重试，重试用尽后向客户端返回 503 和 Retry-After 头
+type DatabaseRetryConfig struct {
+	Enabled        bool `mapstructure:"enabled"`         // 是否启用
+	MaxAttempts    int  `mapstructure:"max_attempts"`    // 最大尝试次数（含第一次）
+	InitialBackoff int  `mapstructure:"initial_backoff"` // 第一次重试前的退避时间（毫秒）
+	MaxBackoff     int  `mapstructure:"max_backoff"`     // 退避时间上限（毫秒）
 }
 
 // AuthConfig 认证配置
 type AuthConfig struct {
-	BcryptCost int `mapstructure:"bcrypt_cost"` // bcrypt加密成本
+	BcryptCost        int          `mapstructure:"bcrypt_cost"`        // bcrypt加密成本
+	PasswordAlgorithm string       `mapstructure:"password_algorithm"` // 新密码的哈希算法（bcrypt、argon2id），已有密码在下次登录成功时按新算法和参数重新哈希
+	Argon2            Argon2Config `mapstructure:"argon2"`             // argon2id 参数，提高后已有的 argon2id 哈希同样在登录时重新哈希
+
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"` // 注册和修改密码时新密码须满足的规则
+}
+
+// PasswordPolicyConfig 密码策略配置，各项规则为零值时不检查
+type PasswordPolicyConfig struct {
+	MinLength      int               `mapstructure:"min_length"`       // 最小长度（字符）
+	MaxLength      int               `mapstructure:"max_length"`       // 最大长度（字节），bcrypt 只使用前 72 字节
+	MinEntropy     float64           `mapstructure:"min_entropy"`      // 最小熵（比特），按使用的字符类别和长度估算
+	Denylist       []string          `mapstructure:"denylist"`         // 禁用的密码，不区分大小写
+	DenylistFile   string            `mapstructure:"denylist_file"`    // 禁用密码列表文件，每行一个，与 denylist 合并
+	RejectUserInfo bool              `mapstructure:"reject_user_info"` // 禁止包含用户名、邮箱用户名部分或姓名
+	BreachCheck    BreachCheckConfig `mapstructure:"breach_check"`     // 泄露密码检查
+}
+
+// BreachCheckConfig 泄露密码检查配置
+//
+// 使用 HaveIBeenPwned 的 k-匿名范围查询，只发送密码 SHA-1 哈希的前 5 位；查询失败或超时时放行
+type BreachCheckConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否启用
+	APIURL  string `mapstructure:"api_url"` // 密码库 API 地址，可指向自建的镜像
+	Timeout int    `mapstructure:"timeout"` // 查询超时时间（秒）
+}
+
+// Argon2Config argon2id 密码哈希参数
+type Argon2Config struct {
+	Memory      int `mapstructure:"memory"`      // 内存（KiB）
+	Iterations  int `mapstructure:"iterations"`  // 迭代次数
+	Parallelism int `mapstructure:"parallelism"` // 并行度
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey string `mapstructure:"secret_key"` // 密钥
-	ExpiresIn int    `mapstructure:"expires_in"` // 过期时间（小时）
+	SecretKey           string                   `mapstructure:"secret_key"`            // 密钥（HS256）
+	ExpiresIn           int                      `mapstructure:"expires_in"`            // 过期时间（小时）
+	RefreshExpiresIn    int                      `mapstructure:"refresh_expires_in"`    // 刷新令牌过期时间（小时），修改后需要重启
+	Algorithm           string                   `mapstructure:"algorithm"`             // 签名算法（HS256、RS256、ES256）
+	KeyID               string                   `mapstructure:"key_id"`                // 当前签名密钥ID，写入令牌头部的 kid
+	PrivateKey          string                   `mapstructure:"private_key"`           // PEM 格式私钥内容（RS256/ES256）
+	PrivateKeyFile      string                   `mapstructure:"private_key_file"`      // PEM 格式私钥文件路径（RS256/ES256）
+	PreviousKeys        []JWTKeyConfig           `mapstructure:"previous_keys"`         // 轮换前的旧密钥，仅用于验证未过期的令牌
+	RotationGracePeriod int                      `mapstructure:"rotation_grace_period"` // 热更新签名密钥后原活动密钥仍可验证令牌的时间（秒），0 表示使用 expires_in
+	BlacklistFilter     JWTBlacklistFilterConfig `mapstructure:"blacklist_filter"`      // 令牌黑名单本地布隆过滤器，修改后需要重启
+}
+
+// JWTBlacklistFilterConfig 令牌黑名单本地布隆过滤器配置
+//
+// 启用后大部分未吊销的令牌无需访问 Redis 即可通过黑名单检查，
+// 其他实例吊销的令牌最晚在 refresh_interval 后生效。共享同一 Redis 的实例须同时启用
+type JWTBlacklistFilterConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`             // 是否启用，缓存驱动不支持列出键（memcached）时自动停用
+	ExpectedTokens    int     `mapstructure:"expected_tokens"`     // 预期的黑名单令牌数，用于计算过滤器大小
+	FalsePositiveRate float64 `mapstructure:"false_positive_rate"` // 期望误判率，误判的令牌回退到 Redis 查询
+	RefreshInterval   int     `mapstructure:"refresh_interval"`    // 从 Redis 同步的间隔（秒）
+}
+
+// JWTKeyConfig 仅用于验证的JWT密钥配置
+type JWTKeyConfig struct {
+	KeyID         string `mapstructure:"key_id"`          // 密钥ID
+	Algorithm     string `mapstructure:"algorithm"`       // 签名算法（HS256、RS256、ES256）
+	SecretKey     string `mapstructure:"secret_key"`      // HMAC 密钥（HS256）
+	PublicKey     string `mapstructure:"public_key"`      // PEM 格式公钥内容（RS256/ES256）
+	PublicKeyFile string `mapstructure:"public_key_file"` // PEM 格式公钥文件路径（RS256/ES256）
 }
 
 // RedisConfig Redis配置
 type RedisConfig struct {
-	Host     string `mapstructure:"host"`      // 主机地址
-	Port     int    `mapstructure:"port"`      // 端口号
-	Password string `mapstructure:"password"`  // 密码
-	DB       int    `mapstructure:"db"`        // 数据库编号
-	PoolSize int    `mapstructure:"pool_size"` // 连接池大小
+	Host             string `mapstructure:"host"`               // 主机地址
+	Port             int    `mapstructure:"port"`               // 端口号
+	Password         string `mapstructure:"password"`           // 密码
+	DB               int    `mapstructure:"db"`                 // 数据库编号
+	PoolSize         int    `mapstructure:"pool_size"`          // 连接池大小
+	CacheTTL         int    `mapstructure:"cache_ttl"`          // 缓存过期时间（秒），支持热重载
+	NegativeCacheTTL int    `mapstructure:"negative_cache_ttl"` // “用户不存在”结果的缓存时间（秒），0 表示不缓存，支持热重载
+}
+
+// CacheConfig 缓存驱动配置，缓存过期时间仍使用 redis.cache_ttl 和 redis.negative_cache_ttl
+type CacheConfig struct {
+	Driver     string                `mapstructure:"driver"`     // 缓存驱动（redis、memcached），修改后需要重启
+	Memcached  CacheMemcachedConfig  `mapstructure:"memcached"`  // memcached 配置，驱动为 memcached 时生效
+	Warmup     CacheWarmupConfig     `mapstructure:"warmup"`     // 缓存预热配置
+	Codec      string                `mapstructure:"codec"`      // 用户缓存的序列化方式（json、gob、msgpack），修改后需要重启
+	TTLs       map[string]int        `mapstructure:"ttls"`       // 按仓储方法覆盖缓存过期时间（秒），未设置的方法使用 redis.cache_ttl，支持热重载
+	Supervisor CacheSupervisorConfig `mapstructure:"supervisor"` // 缓存健康监督配置，修改后需要重启
+}
+
+// CacheSupervisorConfig 缓存健康监督配置
+// 缓存连续失败达到阈值后用户缓存进入直通模式，请求跳过缓存直接访问数据库，后台探测恢复
+type CacheSupervisorConfig struct {
+	Enabled          bool `mapstructure:"enabled"`           // 是否启用
+	FailureThreshold int  `mapstructure:"failure_threshold"` // 连续失败多少次后进入直通模式
+	ProbeInterval    int  `mapstructure:"probe_interval"`    // 直通模式下探测恢复的间隔（秒）
+	ProbeTimeout     int  `mapstructure:"probe_timeout"`     // 单次探测的超时时间（毫秒）
+}
+
+// CacheWarmupConfig 缓存预热配置，避免冷启动时大量请求同时访问数据库
+type CacheWarmupConfig struct {
+	OnStartup bool     `mapstructure:"on_startup"` // 启动时预热，预热完成后才开始处理请求
+	Datasets  []string `mapstructure:"datasets"`   // 启动时预热的数据集，为空表示全部（users）
+	TopUsers  int      `mapstructure:"top_users"`  // 预热的用户数量（按用户列表的默认排序取前N个）
+	Timeout   int      `mapstructure:"timeout"`    // 单次预热的超时时间（秒）
+}
+
+// CacheMemcachedConfig memcached 配置
+type CacheMemcachedConfig struct {
+	Servers      []string `mapstructure:"servers"`        // 服务器地址列表（host:port），键按哈希分布到各服务器
+	Timeout      int      `mapstructure:"timeout"`        // 连接和读写超时（毫秒）
+	MaxIdleConns int      `mapstructure:"max_idle_conns"` // 每个服务器的最大空闲连接数
 }
 
 // RateLimitConfig 速率限制配置
@@ -112,6 +341,34 @@ type RateLimitConfig struct {
 	Requests int    `mapstructure:"requests"`  // 请求次数限制
 	Window   string `mapstructure:"window"`    // 时间窗口
 	RedisKey string `mapstructure:"redis_key"` // Redis键名前缀
+	// 模式：enforce 超过限制时返回 429；warn 不拦截，接近或超过限制时添加 X-RateLimit-Warning 头；
+	// shadow 不拦截也不添加响应头，只记录统计（本应拦截的请求计入限流数），用于上线前评估限制
+	Mode          string `mapstructure:"mode"`
+	WarnThreshold int    `mapstructure:"warn_threshold"` // warn 模式下用量达到限制的该百分比时提示即将限流
+	// 算法：sliding_window 统计时间窗口内的请求数；token_bucket 使用令牌桶，每个时间窗口补充限制数量的令牌，
+	// 请求按路由的 costs 扣除令牌，开销较大的接口可以消耗更多令牌
+	Algorithm string                `mapstructure:"algorithm"`
+	Burst     int                   `mapstructure:"burst"` // token_bucket 算法下桶容量超出限制的令牌数，允许短时突发
+	Costs     []RateLimitCostConfig `mapstructure:"costs"` // token_bucket 算法下按路由的请求消耗，未列出的路由消耗 1 个令牌
+
+	AutoBan RateLimitAutoBanConfig `mapstructure:"auto_ban"` // 根据违规热点自动封禁
+}
+
+// RateLimitCostConfig 单个路由每次请求消耗的令牌数
+type RateLimitCostConfig struct {
+	Route string `mapstructure:"route"` // 路由模板，如 /api/v1/users/search
+	Cost  int    `mapstructure:"cost"`  // 消耗的令牌数
+}
+
+// RateLimitAutoBanConfig 自动封禁配置，定期检查限流违规热点，封禁违规过多的 IP 或用户
+type RateLimitAutoBanConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`            // 是否启用
+	Threshold        int     `mapstructure:"threshold"`          // 统计窗口内的违规次数达到该值时封禁
+	MinViolationRate float64 `mapstructure:"min_violation_rate"` // 同时要求的最低违规比例（百分比），0 表示不要求
+	Window           int     `mapstructure:"window"`             // 统计违规热点的时间窗口（秒），也是管理员解除封禁后的宽限期
+	Duration         int     `mapstructure:"duration"`           // 封禁时长（秒）
+	CheckInterval    int     `mapstructure:"check_interval"`     // 检查违规热点的间隔（秒）
+	KeyPrefix        string  `mapstructure:"key_prefix"`         // Redis键名前缀，缓存不是 Redis 时封禁只对当前实例有效
 }
 
 // CompressionConfig 压缩配置
@@ -120,36 +377,491 @@ type CompressionConfig struct {
 	Threshold int  `mapstructure:"threshold"` // 压缩阈值（字节）
 }
 
-// LoggingConfig 日志配置
+// CORSConfig 跨域资源共享配置
+type CORSConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`           // 是否启用
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // 允许的来源，支持 * 和 https://*.example.com 形式的子域名通配
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // 允许的请求方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // 允许的请求头
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`   // 允许浏览器读取的响应头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带凭证，不能与 * 来源同时使用
+	MaxAge           int      `mapstructure:"max_age"`           // 预检请求缓存时间（秒）
+}
+
+// BodyLimitConfig 请求体大小限制配置
+type BodyLimitConfig struct {
+	Enabled              bool             `mapstructure:"enabled"`                // 是否启用
+	MaxBytes             int64            `mapstructure:"max_bytes"`              // 默认请求体上限（字节）
+	MultipartMaxBytes    int64            `mapstructure:"multipart_max_bytes"`    // multipart/form-data 请求体上限（字节），0 表示使用 max_bytes
+	MaxDecompressedBytes int64            `mapstructure:"max_decompressed_bytes"` // 压缩请求体（gzip、deflate、zstd）解压后的上限（字节），0 表示使用请求体上限
+	Routes               []BodyLimitRoute `mapstructure:"routes"`                 // 按路由前缀覆盖的上限
+}
+
+// BodyLimitRoute 按路由前缀覆盖的请求体上限，多条规则匹配时使用最长的前缀
+type BodyLimitRoute struct {
+	PathPrefix        string `mapstructure:"path_prefix"`         // 路由前缀，如 /api/v1/users
+	MaxBytes          int64  `mapstructure:"max_bytes"`           // 请求体上限（字节），0 表示使用默认值
+	MultipartMaxBytes int64  `mapstructure:"multipart_max_bytes"` // multipart 请求体上限（字节），0 表示使用默认值
+}
+
+// IdempotencyConfig 幂等请求配置
+type IdempotencyConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`      // 是否启用
+	TTL         int      `mapstructure:"ttl"`          // 已完成请求的响应保存时间（秒）
+	LockTimeout int      `mapstructure:"lock_timeout"` // 处理中请求的占用时间（秒），超时后允许重试
+	Methods     []string `mapstructure:"methods"`      // 需要幂等处理的请求方法
+	KeyPrefix   string   `mapstructure:"key_prefix"`   // Redis键名前缀
+}
+
+// ETagConfig 实体标签与条件请求配置
+type ETagConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // 是否启用
+	MaxBodySize  int64    `mapstructure:"max_body_size"` // 计算 ETag 时缓冲的最大响应体（字节），超出时直接输出不计算
+	ExcludePaths []string `mapstructure:"exclude_paths"` // 不计算 ETag 的路径前缀，如流式接口
+}
+
+// ResponseCacheConfig HTTP 响应缓存配置
+type ResponseCacheConfig struct {
+	Enabled              bool                 `mapstructure:"enabled"`                // 是否启用
+	KeyPrefix            string               `mapstructure:"key_prefix"`             // 缓存键前缀
+	StaleWhileRevalidate int                  `mapstructure:"stale_while_revalidate"` // 过期后仍可返回旧响应并在后台刷新的时间（秒），0 表示不启用
+	MaxBodySize          int64                `mapstructure:"max_body_size"`          // 可缓存的最大响应体（字节）
+	Routes               []ResponseCacheRoute `mapstructure:"routes"`                 // 按路由配置的缓存规则
+}
+
+// ResponseCacheRoute 单个路由的响应缓存规则
+type ResponseCacheRoute struct {
+	Path   string   `mapstructure:"path"`   // Gin 路由模式，如 /api/v1/users/:id
+	TTL    int      `mapstructure:"ttl"`    // 缓存时间（秒）
+	Shared bool     `mapstructure:"shared"` // 是否在所有用户之间共享，否则按当前用户区分缓存
+	Tags   []string `mapstructure:"tags"`   // 失效标签，支持 {id} 形式的路由参数占位符
+}
+
+// CoalescingConfig 合并同时进行的相同 GET 请求的配置
+type CoalescingConfig struct {
+	Enabled     bool  `mapstructure:"enabled"`       // 是否启用，只对挂载了合并中间件的路由生效
+	MaxBodySize int64 `mapstructure:"max_body_size"` // 可共享的最大响应体（字节），超出时等待的请求各自执行处理器
+}
+
+// OpenAPIConfig 按 OpenAPI 文档校验请求的配置
+type OpenAPIConfig struct {
+	ValidateRequests bool     `mapstructure:"validate_requests"` // 是否按文档校验请求参数和请求体
+	Strict           bool     `mapstructure:"strict"`            // 严格模式：拒绝文档中未声明的字段、查询参数和请求体
+	ExcludePaths     []string `mapstructure:"exclude_paths"`     // 不校验的路径前缀
+}
+
+// FeatureFlagsConfig 功能开关配置
+type FeatureFlagsConfig struct {
+	Enabled   bool                `mapstructure:"enabled"`    // 是否启用
+	Backend   string              `mapstructure:"backend"`    // 开关存储：config（进程内，来自配置文件）或 redis（多实例共享）
+	CacheTTL  int                 `mapstructure:"cache_ttl"`  // 本地缓存开关定义的时间（秒），Redis 存储通过发布订阅提前失效
+	KeyPrefix string              `mapstructure:"key_prefix"` // Redis键名前缀
+	Flags     []FeatureFlagConfig `mapstructure:"flags"`      // 初始开关，Redis 存储中已存在的开关不会被覆盖
+}
+
+// FeatureFlagConfig 单个功能开关的定义
+type FeatureFlagConfig struct {
+	Key         string   `mapstructure:"key"`         // 开关键
+	Description string   `mapstructure:"description"` // 说明
+	Enabled     bool     `mapstructure:"enabled"`     // 总开关
+	Percentage  int      `mapstructure:"percentage"`  // 按用户灰度的比例（0-100）
+	Users       []string `mapstructure:"users"`       // 始终开启的用户ID
+}
+
+// TenancyConfig 多租户配置
+type TenancyConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // 是否启用租户解析
+	Required     bool     `mapstructure:"required"`      // 是否拒绝无法确定租户的请求
+	Sources      []string `mapstructure:"sources"`       // 租户来源及顺序：header、subdomain、jwt，多个来源确定的租户必须一致
+	Header       string   `mapstructure:"header"`        // 携带租户ID或标识的请求头
+	BaseDomain   string   `mapstructure:"base_domain"`   // 子域名解析的基础域名，如 example.com 时 acme.example.com 解析为 acme
+	CacheTTL     int      `mapstructure:"cache_ttl"`     // 本地缓存租户信息的时间（秒）
+	ExcludePaths []string `mapstructure:"exclude_paths"` // 不解析租户的路径前缀，如健康检查
+}
+
+// GeoIPConfig IP 地理位置查询配置，使用 MaxMind GeoLite2/GeoIP2 数据库
+type GeoIPConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`          // 是否启用，启用后请求日志、限流违规记录和流量统计带上国家和自治系统
+	CountryDatabase string `mapstructure:"country_database"` // GeoLite2-Country 或 GeoLite2-City 数据库路径
+	ASNDatabase     string `mapstructure:"asn_database"`     // GeoLite2-ASN 数据库路径，为空时不查询自治系统
+	RefreshInterval int    `mapstructure:"refresh_interval"` // 检查数据库文件是否更新的间隔（秒），0 表示不检查
+}
+
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`       // 日志级别
 	Format     string `mapstructure:"format"`      // 日志格式
-	Output     string `mapstructure:"output"`      // 输出位置
+	Output     string `mapstructure:"output"`      // 输出位置（stdout、file、both、loki、http）
 	Directory  string `mapstructure:"directory"`   // 日志文件目录
 	MaxSize    int    `mapstructure:"max_size"`    // 单个日志文件最大大小（MB）
 	MaxBackups int    `mapstructure:"max_backups"` // 最大备份文件数
 	MaxAge     int    `mapstructure:"max_age"`     // 日志文件最大保存天数
 	Compress   bool   `mapstructure:"compress"`    // 是否压缩旧日志文件
+
+	BodyCapture LoggingBodyCaptureConfig `mapstructure:"body_capture"` // 请求和响应体记录配置
+	Sampling    LoggingSamplingConfig    `mapstructure:"sampling"`     // 日志采样配置
+	Shipping    LoggingShippingConfig    `mapstructure:"shipping"`     // 远程日志推送配置，输出为 loki 或 http 时使用
+}
+
+// LoggingBodyCaptureConfig 请求日志中请求体和响应体的记录配置，仅记录 JSON 内容
+type LoggingBodyCaptureConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // 是否记录请求体和响应体
+	MaxBytes     int      `mapstructure:"max_bytes"`     // 记录的最大字节数，超出时不记录内容
+	RedactFields []string `mapstructure:"redact_fields"` // 需要脱敏的字段路径，如 password、data.*.email
+}
+
+// LoggingSamplingConfig 日志采样配置，仅对 warn 以下级别的日志采样，错误日志全部记录
+type LoggingSamplingConfig struct {
+	Enabled    bool `mapstructure:"enabled"`    // 是否启用采样
+	Initial    int  `mapstructure:"initial"`    // 每秒内相同消息完整记录的条数
+	Thereafter int  `mapstructure:"thereafter"` // 超出 initial 后每 N 条记录 1 条
+}
+
+// LoggingShippingConfig 远程日志推送配置，日志以 JSON 格式批量推送
+// 输出为 loki 时推送到 Loki 的 push API，输出为 http 时以 NDJSON 格式 POST 到任意地址（如 Logstash、Vector）
+type LoggingShippingConfig struct {
+	URL           string            `mapstructure:"url"`            // 推送地址，如 http://loki:3100/loki/api/v1/push
+	Headers       map[string]string `mapstructure:"headers"`        // 附加请求头，如 Authorization、X-Scope-OrgID
+	Labels        map[string]string `mapstructure:"labels"`         // Loki 流标签
+	BatchSize     int               `mapstructure:"batch_size"`     // 每批最多推送的日志条数
+	FlushInterval int               `mapstructure:"flush_interval"` // 批次未满时的推送间隔（毫秒）
+	QueueSize     int               `mapstructure:"queue_size"`     // 待推送日志的缓冲条数，队列满时丢弃新日志
+	MaxRetries    int               `mapstructure:"max_retries"`    // 推送失败时的最大重试次数
+	Timeout       int               `mapstructure:"timeout"`        // 单次推送请求超时时间（秒）
+}
+
+// ErrorReportingConfig 错误上报配置，将恢复的 panic 和 5xx 错误上报到 Sentry 兼容服务
+type ErrorReportingConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`      // 是否启用错误上报
+	DSN         string   `mapstructure:"dsn"`          // Sentry 兼容的 DSN，支持外部密钥引用
+	Environment string   `mapstructure:"environment"`  // 上报的环境名称，为空时使用运行模式
+	Release     string   `mapstructure:"release"`      // 发布版本
+	SampleRate  float64  `mapstructure:"sample_rate"`  // 上报采样率，取值 (0, 1]
+	ScrubFields []string `mapstructure:"scrub_fields"` // 需要脱敏的请求头、查询参数和错误详情字段，不区分大小写
+	Timeout     int      `mapstructure:"timeout"`      // 单次上报请求超时时间（秒）
+	QueueSize   int      `mapstructure:"queue_size"`   // 待上报事件的缓冲数量，队列满时丢弃新事件
+}
+
+// EventsConfig 事件总线配置
+type EventsConfig struct {
+	Enabled      bool              `mapstructure:"enabled"`       // 是否启用
+	Driver       string            `mapstructure:"driver"`        // 驱动类型（nats、kafka、memory）
+	Source       string            `mapstructure:"source"`        // 事件来源标识
+	DrainTimeout int               `mapstructure:"drain_timeout"` // 关闭时排空超时时间（秒）
+	NATS         EventsNATSConfig  `mapstructure:"nats"`          // NATS配置
+	Kafka        EventsKafkaConfig `mapstructure:"kafka"`         // Kafka配置
+}
+
+// EventsNATSConfig NATS事件驱动配置
+type EventsNATSConfig struct {
+	URL            string `mapstructure:"url"`             // 服务器地址，多个地址以逗号分隔
+	Name           string `mapstructure:"name"`            // 客户端连接名称
+	QueueGroup     string `mapstructure:"queue_group"`     // 订阅队列组
+	ConnectTimeout int    `mapstructure:"connect_timeout"` // 连接超时时间（秒）
+	MaxReconnects  int    `mapstructure:"max_reconnects"`  // 最大重连次数（-1表示无限）
+}
+
+// EventsKafkaConfig Kafka事件驱动配置
+type EventsKafkaConfig struct {
+	Brokers     []string `mapstructure:"brokers"`      // Broker地址列表
+	ClientID    string   `mapstructure:"client_id"`    // 客户端ID
+	GroupID     string   `mapstructure:"group_id"`     // 消费者组ID
+	TopicPrefix string   `mapstructure:"topic_prefix"` // 主题前缀
+}
+
+// StorageConfig 对象存储配置
+type StorageConfig struct {
+	Driver              string             `mapstructure:"driver"`                // 驱动类型（local、s3）
+	MaxUploadSize       int64              `mapstructure:"max_upload_size"`       // 单个文件最大上传大小（字节）
+	AllowedContentTypes []string           `mapstructure:"allowed_content_types"` // 允许上传的内容类型
+	Local               StorageLocalConfig `mapstructure:"local"`                 // 本地文件系统配置
+	S3                  StorageS3Config    `mapstructure:"s3"`                    // S3兼容存储配置
+	Media               StorageMediaConfig `mapstructure:"media"`                 // 头像等媒体文件的访问配置
+}
+
+// StorageMediaConfig 媒体文件访问配置
+// 媒体文件的对象键唯一且内容不变，本地驱动提供的文件使用 immutable 缓存，不经过压缩和请求日志中间件
+type StorageMediaConfig struct {
+	SignedURLs bool   `mapstructure:"signed_urls"` // 是否使用带有效期的签名链接访问媒体文件（CDN 或本地文件服务校验签名）
+	SigningKey string `mapstructure:"signing_key"` // 签名链接的密钥，CDN 校验签名时须配置相同的密钥；为空时由 jwt.secret_key 派生
+	URLTTL     int    `mapstructure:"url_ttl"`     // 签名链接的有效期（秒）
+}
+
+// StorageLocalConfig 本地文件系统存储配置
+type StorageLocalConfig struct {
+	BaseDir string `mapstructure:"base_dir"` // 文件存储根目录
+	BaseURL string `mapstructure:"base_url"` // 公开访问地址前缀
+}
+
+// StorageS3Config S3兼容存储配置（AWS S3、MinIO等）
+type StorageS3Config struct {
+	Endpoint        string `mapstructure:"endpoint"`          // 服务地址
+	Region          string `mapstructure:"region"`            // 区域
+	Bucket          string `mapstructure:"bucket"`            // 存储桶名称
+	AccessKeyID     string `mapstructure:"access_key_id"`     // 访问密钥ID
+	SecretAccessKey string `mapstructure:"secret_access_key"` // 访问密钥
+	UseSSL          bool   `mapstructure:"use_ssl"`           // 是否使用HTTPS
+	PublicURL       string `mapstructure:"public_url"`        // 公开访问地址前缀（如CDN地址）
+}
+
+// SecretsConfig 外部密钥后端配置
+// 敏感配置项可写成引用形式（如 vault://secret/data/go-server#db_password、awssm://go-server/prod#jwt_secret），
+// 启动时解析为实际值，并按刷新间隔定期拉取以支持密钥轮换
+type SecretsConfig struct {
+	RefreshInterval int                `mapstructure:"refresh_interval"` // 刷新间隔（秒），0 表示仅在加载配置时解析
+	Timeout         int                `mapstructure:"timeout"`          // 单次读取超时时间（秒）
+	Vault           VaultSecretsConfig `mapstructure:"vault"`            // HashiCorp Vault 配置
+	AWS             AWSSecretsConfig   `mapstructure:"aws"`              // AWS Secrets Manager 配置
+}
+
+// VaultSecretsConfig HashiCorp Vault 配置
+type VaultSecretsConfig struct {
+	Address   string `mapstructure:"address"`   // 服务地址，为空时读取 VAULT_ADDR
+	Token     string `mapstructure:"token"`     // 访问令牌，为空时读取 VAULT_TOKEN
+	Namespace string `mapstructure:"namespace"` // 命名空间（Vault Enterprise）
+}
+
+// AWSSecretsConfig AWS Secrets Manager 配置
+type AWSSecretsConfig struct {
+	Region          string `mapstructure:"region"`            // 区域，为空时读取 AWS_REGION
+	Endpoint        string `mapstructure:"endpoint"`          // 自定义服务地址（如 LocalStack）
+	AccessKeyID     string `mapstructure:"access_key_id"`     // 访问密钥ID，为空时读取 AWS_ACCESS_KEY_ID
+	SecretAccessKey string `mapstructure:"secret_access_key"` // 访问密钥，为空时读取 AWS_SECRET_ACCESS_KEY
+	SessionToken    string `mapstructure:"session_token"`     // 会话令牌，为空时读取 AWS_SESSION_TOKEN
+}
+
+// RemoteConfig 远程配置源，从 etcd 或 Consul KV 读取 YAML 配置文档并监听变更
+//
+// 远程文档作为一个配置层，优先级高于配置文件、低于环境变量；变更后触发热重载，
+// 速率限制、功能开关和日志级别等支持热重载的配置无需重新部署即可在所有实例生效
+type RemoteConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`   // 是否启用
+	Provider  string   `mapstructure:"provider"`  // 后端类型（etcd、consul）
+	Endpoints []string `mapstructure:"endpoints"` // 服务地址，如 http://etcd:2379 或 http://consul:8500，失败时依次尝试
+	Key       string   `mapstructure:"key"`       // 存放 YAML 配置文档的键，如 go-server/config/production
+	Token     string   `mapstructure:"token"`     // Consul ACL 令牌，为空时读取 CONSUL_HTTP_TOKEN
+	Username  string   `mapstructure:"username"`  // etcd 用户名，启用认证时使用
+	Password  string   `mapstructure:"password"`  // etcd 密码
+	Timeout   int      `mapstructure:"timeout"`   // 单次读取超时时间（秒）
+	Optional  bool     `mapstructure:"optional"`  // 启动时远程配置不可用是否继续使用本地配置
+}
+
+// MetricsConfig 内部指标配置
+type MetricsConfig struct {
+	Snapshots MetricsSnapshotsConfig `mapstructure:"snapshots"` // 指标快照持久化配置，修改后需要重启
+}
+
+// MetricsSnapshotsConfig 指标快照持久化配置
+// 按采集间隔把限流、缓存和 HTTP 计数器的增量写入 metrics_snapshots 表，进程重启后仍可查看历史；
+// 原始快照保留 raw_retention 小时，每个汇总间隔结束后合并为一条汇总快照，保留 rollup_retention 天
+type MetricsSnapshotsConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // 是否启用
+	Interval        int  `mapstructure:"interval"`         // 采集间隔（秒）
+	RawRetention    int  `mapstructure:"raw_retention"`    // 原始快照保留时间（小时）
+	RollupInterval  int  `mapstructure:"rollup_interval"`  // 降采样的汇总间隔（分钟），需为采集间隔的整数倍
+	RollupRetention int  `mapstructure:"rollup_retention"` // 汇总快照保留时间（天）
+}
+
+// NotificationsConfig 用户通知配置，修改后需要重启
+// 投递任务经事件总线分发，事件总线未启用时在进程内投递；邮件、短信和推送发布为事件由外部网关发送
+type NotificationsConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`          // 是否启用，需要数据库
+	Channels       []string `mapstructure:"channels"`         // 启用的渠道（email、sms、push、in_app）
+	UnreadCountTTL int      `mapstructure:"unread_count_ttl"` // 未读数缓存时间（秒）
+}
+
+// AnnouncementsConfig 公告配置，修改后需要重启
+// 生效中的公告缓存在共享缓存中，写操作时失效；缓存时间只是多实例间的兜底
+type AnnouncementsConfig struct {
+	Enabled        bool `mapstructure:"enabled"`         // 是否启用，需要数据库
+	CacheTTL       int  `mapstructure:"cache_ttl"`       // 生效中公告的缓存时间（秒）
+	Stream         bool `mapstructure:"stream"`          // 是否启用 SSE 推送接口
+	StreamInterval int  `mapstructure:"stream_interval"` // SSE 连接重新检查公告和发送心跳的间隔（秒）
+}
+
+// ExportsConfig 数据导出配置，修改后需要重启
+// 导出文件存放在 storage 配置的对象存储中，只能通过签名链接下载
+type ExportsConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`     // 是否启用，需要数据库
+	BatchSize  int    `mapstructure:"batch_size"`  // 每批读取的用户数
+	MaxRunning int    `mapstructure:"max_running"` // 每个实例同时执行的导出任务数
+	Retention  int    `mapstructure:"retention"`   // 导出文件的保留时间（小时）
+	URLTTL     int    `mapstructure:"url_ttl"`     // 下载链接的有效期（秒）
+	SigningKey string `mapstructure:"signing_key"` // 下载链接的签名密钥，为空时由 jwt.secret_key 派生
+}
+
+// RetentionConfig 数据保留策略配置，修改后需要重启
+// 每个实例按 interval 定期删除超出保留期限的数据，每张表的清理结果记录审计事件；
+// dry_run 时只统计可删除的行数并写入日志，管理员可通过 /api/v1/admin/retention 查看报告或手动清理
+type RetentionConfig struct {
+	Enabled   bool                    `mapstructure:"enabled"`    // 是否启用，需要数据库
+	Interval  int                     `mapstructure:"interval"`   // 定期清理的间隔（分钟）
+	BatchSize int                     `mapstructure:"batch_size"` // 每批删除的行数
+	DryRun    bool                    `mapstructure:"dry_run"`    // 定期清理只统计可删除的行数，不删除数据
+	Policies  []RetentionPolicyConfig `mapstructure:"policies"`   // 各表的保留策略，未配置的表不清理
+}
+
+// RetentionPolicyConfig 一张表的保留策略
+type RetentionPolicyConfig struct {
+	Table     string `mapstructure:"table"`     // 表名：users（软删除的用户）或 metrics_snapshots
+	Retention int    `mapstructure:"retention"` // 保留天数，users 为删除账户后的宽限期
+}
+
+// BackupsConfig 数据库备份配置，修改后需要重启
+// 备份由 pg_dump 生成，加密后保存在对象存储的 backups/ 前缀下；运行服务的主机或镜像中须安装与数据库版本匹配的 PostgreSQL 客户端
+type BackupsConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // 是否启用，需要数据库和对象存储
+	PgDump         string `mapstructure:"pg_dump"`         // pg_dump 的路径，为空时从 PATH 中查找
+	PgRestore      string `mapstructure:"pg_restore"`      // pg_restore 的路径，为空时从 PATH 中查找
+	Timeout        int    `mapstructure:"timeout"`         // 单个备份或恢复的最长执行时间（分钟）
+	Interval       int    `mapstructure:"interval"`        // 自动备份的间隔（小时），为0时只能手动备份
+	Retention      int    `mapstructure:"retention"`       // 备份的保留时间（天），过期后删除文件和记录
+	EncryptionKeys string `mapstructure:"encryption_keys"` // 备份文件的加密密钥列表，格式为"版本:base64密钥"，逗号分隔，密钥长度32字节；删除旧版本后无法读取用其加密的备份
+	ActiveKey      uint32 `mapstructure:"active_key"`      // 加密新备份使用的密钥版本，为0时使用最大的版本
+}
+
+// ImportsConfig 批量导入配置，修改后需要重启
+// 导入在请求中同步执行，max_rows 应使校验和计算密码哈希能在 server.write_timeout 内完成
+type ImportsConfig struct {
+	Enabled   bool `mapstructure:"enabled"`    // 是否启用，需要数据库
+	BatchSize int  `mapstructure:"batch_size"` // 每个事务写入的用户数
+	MaxRows   int  `mapstructure:"max_rows"`   // 单个文件最多包含的数据行数
+}
+
+// EncryptionConfig 字段加密配置，启用后用户的邮箱和姓名以 AES-256-GCM 密文保存
+// 密钥可以引用外部密钥后端，刷新时新增的密钥版本立即生效；enabled 和 blind_index_key 修改后需要重启
+type EncryptionConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`         // 是否启用
+	Keys          string `mapstructure:"keys"`            // 加密密钥列表，格式为"版本:base64密钥"，逗号分隔，密钥长度32字节
+	ActiveKey     uint32 `mapstructure:"active_key"`      // 加密新数据使用的密钥版本，为0时使用最大的版本
+	BlindIndexKey string `mapstructure:"blind_index_key"` // 计算邮箱盲索引的密钥，至少32字节，修改后须重建索引
 }
 
-// LoadConfig 加载配置文件
+// OIDCConfig OpenID Connect 提供方配置，修改后需要重启
+// ID 令牌使用 jwt 配置的活动密钥签名，客户端通过 /.well-known/jwks.json 验证，因此要求非对称算法
+type OIDCConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`      // 是否启用，需要数据库
+	Issuer     string `mapstructure:"issuer"`       // 签发者地址，即本服务对外的根地址，写入 ID 令牌的 iss 声明
+	ConsentURL string `mapstructure:"consent_url"`  // 前端授权同意页地址，作为发现文档中的 authorization_endpoint
+	CodeTTL    int    `mapstructure:"code_ttl"`     // 授权码的有效期（秒）
+	IDTokenTTL int    `mapstructure:"id_token_ttl"` // ID 令牌的有效期（秒）
+}
+
+// SAMLConfig SAML 2.0 单点登录配置，修改后需要重启
+// 每个租户对接一个身份提供方，SP 的实体ID为 {base_url}/api/v1/auth/saml/{tenant}/metadata，断言消费服务为 {base_url}/api/v1/auth/saml/{tenant}/acs
+type SAMLConfig struct {
+	Enabled    bool                 `mapstructure:"enabled"`     // 是否启用，需要数据库
+	BaseURL    string               `mapstructure:"base_url"`    // 本服务对外的根地址
+	SuccessURL string               `mapstructure:"success_url"` // 前端的登录完成页面，访问令牌放在 URL 片段 #token= 中
+	ClockSkew  int                  `mapstructure:"clock_skew"`  // 校验断言有效期时允许的时钟偏差（秒）
+	RequestTTL int                  `mapstructure:"request_ttl"` // 认证请求的有效期（秒），用户须在此时间内完成身份提供方的登录
+	Providers  []SAMLProviderConfig `mapstructure:"providers"`   // 各租户的身份提供方
+}
+
+// PasswordlessConfig 无密码登录配置（设备授权流程和邮件登录链接），修改后需要重启
+// 登录链接通过 user.magic_link_requested 事件发送，需要启用事件总线并由通知服务投递
+type PasswordlessConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`          // 是否启用，需要数据库
+	VerificationURL string `mapstructure:"verification_url"` // 前端设备验证页面地址，用户在此输入设备上显示的用户码
+	MagicLinkURL    string `mapstructure:"magic_link_url"`   // 前端登录链接页面地址，链接令牌以 token 查询参数附加在其后
+	DeviceCodeTTL   int    `mapstructure:"device_code_ttl"`  // 设备码和用户码的有效期（秒）
+	PollInterval    int    `mapstructure:"poll_interval"`    // 设备轮询令牌接口的最小间隔（秒）
+	MagicLinkTTL    int    `mapstructure:"magic_link_ttl"`   // 登录链接的有效期（秒）
+}
+
+// QuotaConfig 用量配额配置，按自然月（UTC）统计每个用户的用量，修改后需要重启
+// 缓存是 Redis 时计数在实例间共享，否则保存在内存中，只对当前实例有效
+type QuotaConfig struct {
+	Enabled   bool               `mapstructure:"enabled"`    // 是否启用
+	KeyPrefix string             `mapstructure:"key_prefix"` // Redis 键前缀
+	Limits    []QuotaLimitConfig `mapstructure:"limits"`     // 各项配额的限制，api_calls 由每个认证的 /api/v1/users 请求消耗
+}
+
+// QuotaLimitConfig 一项配额的每月限制
+type QuotaLimitConfig struct {
+	Name string `mapstructure:"name"` // 配额名称，如 api_calls
+	Soft int64  `mapstructure:"soft"` // 软限制，达到时在响应中提醒并记录日志，0 表示没有软限制
+	Hard int64  `mapstructure:"hard"` // 硬限制，超过时返回 429，0 表示不限制
+}
+
+// BillingConfig 订阅套餐配置，用户的订阅由 Stripe webhook（/api/v1/billing/webhooks/stripe）同步，修改后需要重启
+// 套餐的限流覆盖全局和租户的认证用户限制，套餐的配额覆盖 quota.limits 中的同名配额
+type BillingConfig struct {
+	Enabled             bool         `mapstructure:"enabled"`               // 是否启用，需要数据库
+	DefaultPlan         string       `mapstructure:"default_plan"`          // 没有有效订阅时使用的套餐
+	StripeWebhookSecret string       `mapstructure:"stripe_webhook_secret"` // Stripe webhook 签名密钥（whsec_...）
+	SignatureTolerance  int          `mapstructure:"signature_tolerance"`   // 签名时间戳允许的偏差（秒），防止重放
+	CacheTTL            int          `mapstructure:"cache_ttl"`             // 用户当前套餐的缓存时间（秒）
+	Plans               []PlanConfig `mapstructure:"plans"`                 // 套餐列表，按展示顺序排列
+}
+
+// PlanConfig 一个订阅套餐
+type PlanConfig struct {
+	ID             string             `mapstructure:"id"`               // 套餐标识，如 pro
+	Name           string             `mapstructure:"name"`             // 展示名称
+	Entitlements   []string           `mapstructure:"entitlements"`     // 套餐包含的权益，如 advanced_search
+	RateLimit      int                `mapstructure:"rate_limit"`       // 限流窗口内的请求数，0 表示使用租户或全局配置
+	Quotas         []QuotaLimitConfig `mapstructure:"quotas"`           // 套餐的每月用量配额，需要启用 quota
+	StripePriceIDs []string           `mapstructure:"stripe_price_ids"` // 对应的 Stripe 价格ID，订阅的价格据此映射到套餐
+}
+
+// MaintenanceConfig 维护模式配置，enabled 和 key_prefix 修改后需要重启，其余配置支持热重载
+// 管理员也可以通过 /api/v1/admin/maintenance 开启维护；缓存是 Redis 时开关在实例间共享，否则只对当前实例有效
+type MaintenanceConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`            // 是否安装维护模式中间件
+	Active            bool     `mapstructure:"active"`             // 是否通过配置开启维护，开启期间管理员不能通过接口结束维护
+	Message           string   `mapstructure:"message"`            // 通过配置开启维护时展示给用户的说明
+	EstimatedDowntime int      `mapstructure:"estimated_downtime"` // 通过配置开启维护时的预计时长（秒），0 表示未知
+	BypassTokens      string   `mapstructure:"bypass_tokens"`      // 运维人员的旁路令牌，逗号分隔，通过 X-Maintenance-Bypass 请求头传递
+	AllowPaths        []string `mapstructure:"allow_paths"`        // 维护期间仍可访问的路径前缀
+	KeyPrefix         string   `mapstructure:"key_prefix"`         // Redis 键前缀
+	RefreshInterval   int      `mapstructure:"refresh_interval"`   // 本地缓存维护状态的时间（秒），其他实例的开关最多延迟这么久生效
+}
+
+// PreflightConfig 启动检查配置，修改后需要重启
+// 启动时检查数据库、迁移、缓存、密钥和日志目录，结果汇总为一条启动报告日志；失败的检查会终止启动
+type PreflightConfig struct {
+	Enabled                 bool `mapstructure:"enabled"`                    // 是否执行启动检查
+	Timeout                 int  `mapstructure:"timeout"`                    // 每项检查的超时时间（秒）
+	RequireCache            bool `mapstructure:"require_cache"`              // 缓存不可用时终止启动，否则只记录警告
+	FailOnPendingMigrations bool `mapstructure:"fail_on_pending_migrations"` // 存在未执行的 SQL 迁移时终止启动，否则只记录警告
+}
+
+// SAMLProviderConfig 租户的 SAML 身份提供方，取自身份提供方的元数据
+type SAMLProviderConfig struct {
+	Tenant             string `mapstructure:"tenant"`               // 租户标识，启用多租户时须为已存在的租户
+	IdPEntityID        string `mapstructure:"idp_entity_id"`        // 身份提供方的实体ID
+	IdPSSOURL          string `mapstructure:"idp_sso_url"`          // 支持 HTTP-Redirect 绑定的单点登录地址
+	IdPCertificate     string `mapstructure:"idp_certificate"`      // 签名证书，PEM 格式或元数据中 X509Certificate 的 base64 内容
+	AutoProvision      bool   `mapstructure:"auto_provision"`       // 是否为不存在的用户自动创建账户
+	EmailAttribute     string `mapstructure:"email_attribute"`      // 邮箱属性名，为空时使用 NameID
+	UsernameAttribute  string `mapstructure:"username_attribute"`   // 自动创建用户时的用户名属性名，为空时使用邮箱的本地部分
+	FirstNameAttribute string `mapstructure:"first_name_attribute"` // 名的属性名，每次登录时同步
+	LastNameAttribute  string `mapstructure:"last_name_attribute"`  // 姓的属性名，每次登录时同步
+}
+
+// LoadConfig 按 layers.go 中说明的顺序分层加载配置
 func LoadConfig() (*Config, error) {
 	var config Config
 
+	// 每次加载从空白状态开始，避免重新加载时保留已删除的配置项
+	viper.Reset()
+
+	// .env 文件可以设置 APP_ENV，因此最先加载
+	if err := loadDotEnv(dotEnvFile); err != nil {
+		return nil, err
+	}
+
 	// 设置环境
 	env := os.Getenv("APP_ENV")
 	if env == "" {
 		env = "development"
 	}
 
-	// 设置配置文件路径
-	viper.SetConfigName(env)
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
 
-	// 设置环境变量前缀
+	// 设置环境变量前缀，嵌套配置项的点号替换为下划线
 	viper.SetEnvPrefix("APP")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	// 设置默认值
@@ -158,9 +870,52 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
+	viper.SetDefault("server.shutdown.pre_stop_delay", 0)
+	viper.SetDefault("server.shutdown.drain_timeout", 15)
+	viper.SetDefault("server.shutdown.workers_timeout", 10)
+	viper.SetDefault("server.shutdown.resources_timeout", 5)
+	viper.SetDefault("server.shutdown.logs_timeout", 3)
+	viper.SetDefault("server.shutdown.exit_after_drain", false)
+	viper.SetDefault("server.error_format", "envelope")
+	viper.SetDefault("server.problem_type_base_url", "/problems/")
+	viper.SetDefault("server.acme.enabled", false)
+	viper.SetDefault("server.acme.domains", []string{})
+	viper.SetDefault("server.acme.email", "")
+	viper.SetDefault("server.acme.directory_url", "")
+	viper.SetDefault("server.acme.challenge", "http-01")
+	viper.SetDefault("server.acme.http_port", "80")
+	viper.SetDefault("server.acme.cache", "storage")
+	viper.SetDefault("server.acme.cache_prefix", "acme")
+	viper.SetDefault("server.acme.renew_before", 30)
+	viper.SetDefault("server.mtls.enabled", false)
+	viper.SetDefault("server.mtls.port", "8443")
+	viper.SetDefault("server.mtls.cert_file", "")
+	viper.SetDefault("server.mtls.key_file", "")
+	viper.SetDefault("server.mtls.client_ca_file", "")
 	viper.SetDefault("auth.bcrypt_cost", 12)
+	viper.SetDefault("auth.password_algorithm", "bcrypt")
+	viper.SetDefault("auth.argon2.memory", 65536)
+	viper.SetDefault("auth.argon2.iterations", 3)
+	viper.SetDefault("auth.argon2.parallelism", 2)
+	viper.SetDefault("auth.password_policy.min_length", 8)
+	viper.SetDefault("auth.password_policy.max_length", 72)
+	viper.SetDefault("auth.password_policy.min_entropy", 0)
+	viper.SetDefault("auth.password_policy.denylist", []string{})
+	viper.SetDefault("auth.password_policy.denylist_file", "")
+	viper.SetDefault("auth.password_policy.reject_user_info", true)
+	viper.SetDefault("auth.password_policy.breach_check.enabled", false)
+	viper.SetDefault("auth.password_policy.breach_check.api_url", "https://api.pwnedpasswords.com")
+	viper.SetDefault("auth.password_policy.breach_check.timeout", 3)
 	viper.SetDefault("jwt.secret_key", "your-secret-key-change-in-production")
 	viper.SetDefault("jwt.expires_in", 24)
+	viper.SetDefault("jwt.refresh_expires_in", 168)
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.key_id", "default")
+	viper.SetDefault("jwt.rotation_grace_period", 0)
+	viper.SetDefault("jwt.blacklist_filter.enabled", false)
+	viper.SetDefault("jwt.blacklist_filter.expected_tokens", 100000)
+	viper.SetDefault("jwt.blacklist_filter.false_positive_rate", 0.001)
+	viper.SetDefault("jwt.blacklist_filter.refresh_interval", 5)
 
 	// 根据环境设置数据库连接池默认值
 	if env == "production" {
@@ -177,6 +932,19 @@ func LoadConfig() (*Config, error) {
 		viper.SetDefault("database.max_idle_conns", 10)
 		viper.SetDefault("database.conn_max_lifetime", 3600) // 1小时（秒）
 	}
+	viper.SetDefault("database.slow_query_threshold", 50)   // 50毫秒
+	viper.SetDefault("database.migration_lock_timeout", 60) // 60秒
+	viper.SetDefault("database.request_queries.enabled", false)
+	viper.SetDefault("database.request_queries.max_queries", 50)
+	viper.SetDefault("database.request_queries.repeat_threshold", 10)
+	viper.SetDefault("database.request_queries.top_offenders", 20)
+	viper.SetDefault("database.last_login_batching.enabled", false)
+	viper.SetDefault("database.last_login_batching.interval", 10) // 10秒
+	viper.SetDefault("database.last_login_batching.batch_size", 500)
+	viper.SetDefault("database.retry.enabled", true)
+	viper.SetDefault("database.retry.max_attempts", 3)
+	viper.SetDefault("database.retry.initial_backoff", 50) // 50毫秒
+	viper.SetDefault("database.retry.max_backoff", 1000)   // 1秒
 
 	// Redis默认值
 	viper.SetDefault("redis.host", "localhost")
@@ -184,17 +952,108 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.pool_size", 10)
+	viper.SetDefault("redis.cache_ttl", 300)
+	viper.SetDefault("redis.negative_cache_ttl", 30)
+
+	// 缓存驱动默认配置
+	viper.SetDefault("cache.driver", "redis")
+	viper.SetDefault("cache.memcached.servers", []string{"localhost:11211"})
+	viper.SetDefault("cache.memcached.timeout", 3000)
+	viper.SetDefault("cache.memcached.max_idle_conns", 10)
+	viper.SetDefault("cache.warmup.on_startup", true)
+	viper.SetDefault("cache.warmup.datasets", []string{})
+	viper.SetDefault("cache.warmup.top_users", 100)
+	viper.SetDefault("cache.warmup.timeout", 30)
+	viper.SetDefault("cache.codec", "json")
+	viper.SetDefault("cache.ttls", map[string]int{})
+	viper.SetDefault("cache.supervisor.enabled", true)
+	viper.SetDefault("cache.supervisor.failure_threshold", 5)
+	viper.SetDefault("cache.supervisor.probe_interval", 5)
+	viper.SetDefault("cache.supervisor.probe_timeout", 1000)
 
 	// 速率限制默认值
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.requests", 100)
 	viper.SetDefault("rate_limit.window", "1m")
 	viper.SetDefault("rate_limit.redis_key", "rate_limit")
+	viper.SetDefault("rate_limit.mode", "enforce")
+	viper.SetDefault("rate_limit.warn_threshold", 80) // 80%
+	viper.SetDefault("rate_limit.algorithm", "sliding_window")
+	viper.SetDefault("rate_limit.burst", 0)
+	viper.SetDefault("rate_limit.auto_ban.enabled", false)
+	viper.SetDefault("rate_limit.auto_ban.threshold", 50)
+	viper.SetDefault("rate_limit.auto_ban.min_violation_rate", 50)
+	viper.SetDefault("rate_limit.auto_ban.window", 600)
+	viper.SetDefault("rate_limit.auto_ban.duration", 3600)
+	viper.SetDefault("rate_limit.auto_ban.check_interval", 30)
+	viper.SetDefault("rate_limit.auto_ban.key_prefix", "banlist")
 
 	// 压缩默认值
 	viper.SetDefault("compression.enabled", true)
 	viper.SetDefault("compression.threshold", 1024)
 
+	// 跨域默认值
+	viper.SetDefault("cors.enabled", true)
+	viper.SetDefault("cors.allowed_origins", []string{"*"})
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("cors.allowed_headers", []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key", "If-Match", "If-None-Match", "traceparent", "tracestate"})
+	viper.SetDefault("cors.exposed_headers", []string{"X-Correlation-ID", "X-Request-ID", "traceparent", "ETag"})
+	viper.SetDefault("cors.allow_credentials", false)
+	viper.SetDefault("cors.max_age", 43200)
+
+	// 请求体大小限制默认值
+	viper.SetDefault("body_limit.enabled", true)
+	viper.SetDefault("body_limit.max_bytes", 1<<20)
+	viper.SetDefault("body_limit.multipart_max_bytes", 10<<20)
+	viper.SetDefault("body_limit.max_decompressed_bytes", 10<<20)
+
+	// 幂等请求默认值
+	viper.SetDefault("idempotency.enabled", true)
+	viper.SetDefault("idempotency.ttl", 86400)
+	viper.SetDefault("idempotency.lock_timeout", 60)
+	viper.SetDefault("idempotency.methods", []string{"POST", "PUT"})
+	viper.SetDefault("idempotency.key_prefix", "idempotency")
+
+	// 实体标签默认值
+	viper.SetDefault("etag.enabled", true)
+	viper.SetDefault("etag.max_body_size", 1<<20)
+	viper.SetDefault("etag.exclude_paths", []string{"/swagger/"})
+
+	// 响应缓存默认值
+	viper.SetDefault("response_cache.enabled", true)
+	viper.SetDefault("response_cache.key_prefix", "response_cache")
+	viper.SetDefault("response_cache.stale_while_revalidate", 30)
+	viper.SetDefault("response_cache.max_body_size", 1<<20)
+
+	// 请求合并默认值
+	viper.SetDefault("coalescing.enabled", true)
+	viper.SetDefault("coalescing.max_body_size", 1<<20)
+
+	// OpenAPI 请求校验默认值
+	viper.SetDefault("openapi.validate_requests", false)
+	viper.SetDefault("openapi.strict", false)
+	viper.SetDefault("openapi.exclude_paths", []string{"/swagger/", "/openapi.json"})
+
+	// 功能开关默认配置
+	viper.SetDefault("feature_flags.enabled", true)
+	viper.SetDefault("feature_flags.backend", "config")
+	viper.SetDefault("feature_flags.cache_ttl", 30)
+	viper.SetDefault("feature_flags.key_prefix", "featureflags")
+
+	// 多租户默认配置
+	viper.SetDefault("geoip.enabled", false)
+	viper.SetDefault("geoip.country_database", "data/GeoLite2-Country.mmdb")
+	viper.SetDefault("geoip.asn_database", "")
+	viper.SetDefault("geoip.refresh_interval", 3600)
+
+	viper.SetDefault("tenancy.enabled", false)
+	viper.SetDefault("tenancy.required", false)
+	viper.SetDefault("tenancy.sources", []string{"jwt", "header", "subdomain"})
+	viper.SetDefault("tenancy.header", "X-Tenant-ID")
+	viper.SetDefault("tenancy.base_domain", "")
+	viper.SetDefault("tenancy.cache_ttl", 60)
+	viper.SetDefault("tenancy.exclude_paths", []string{"/healthz", "/readyz", "/api/v1/health", "/api/v1/ready", "/api/v1/live", "/api/v1/metrics", "/swagger/", "/openapi.json", "/.well-known/"})
+
 	// 日志默认值
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
@@ -204,16 +1063,181 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("logging.max_backups", 3)
 	viper.SetDefault("logging.max_age", 28)
 	viper.SetDefault("logging.compress", true)
+	viper.SetDefault("logging.body_capture.enabled", false)
+	viper.SetDefault("logging.body_capture.max_bytes", 4096)
+	viper.SetDefault("logging.body_capture.redact_fields", []string{
+		"password", "current_password", "new_password", "confirm_password",
+		"token", "access_token", "refresh_token", "authorization", "secret", "api_key", "email",
+	})
+	viper.SetDefault("logging.sampling.enabled", false)
+	viper.SetDefault("logging.sampling.initial", 100)
+	viper.SetDefault("logging.sampling.thereafter", 100)
+	viper.SetDefault("logging.shipping.url", "")
+	viper.SetDefault("logging.shipping.labels", map[string]string{"app": "go-server"})
+	viper.SetDefault("logging.shipping.batch_size", 500)
+	viper.SetDefault("logging.shipping.flush_interval", 1000)
+	viper.SetDefault("logging.shipping.queue_size", 10000)
+	viper.SetDefault("logging.shipping.max_retries", 3)
+	viper.SetDefault("logging.shipping.timeout", 10)
+
+	// 错误上报默认值
+	viper.SetDefault("error_reporting.enabled", false)
+	viper.SetDefault("error_reporting.dsn", "")
+	viper.SetDefault("error_reporting.environment", "")
+	viper.SetDefault("error_reporting.release", "")
+	viper.SetDefault("error_reporting.sample_rate", 1.0)
+	viper.SetDefault("error_reporting.scrub_fields", []string{
+		"authorization", "cookie", "set-cookie", "x-api-key",
+		"password", "token", "access_token", "refresh_token", "secret", "email",
+	})
+	viper.SetDefault("error_reporting.timeout", 5)
+	viper.SetDefault("error_reporting.queue_size", 100)
+
+	// 事件总线默认值
+	viper.SetDefault("events.enabled", false)
+	viper.SetDefault("events.driver", "nats")
+	viper.SetDefault("events.source", "go-server")
+	viper.SetDefault("events.drain_timeout", 10)
+	viper.SetDefault("events.nats.url", "nats://localhost:4222")
+	viper.SetDefault("events.nats.name", "go-server")
+	viper.SetDefault("events.nats.connect_timeout", 5)
+	viper.SetDefault("events.nats.max_reconnects", 60)
+	viper.SetDefault("events.kafka.brokers", []string{"localhost:9092"})
+	viper.SetDefault("events.kafka.client_id", "go-server")
+	viper.SetDefault("events.kafka.group_id", "go-server")
+
+	// 对象存储默认值
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.max_upload_size", 5*1024*1024)
+	viper.SetDefault("storage.allowed_content_types", []string{"image/jpeg", "image/png", "image/gif", "image/webp"})
+	viper.SetDefault("storage.local.base_dir", "./uploads")
+	viper.SetDefault("storage.local.base_url", "/uploads")
+	viper.SetDefault("storage.media.signed_urls", false)
+	viper.SetDefault("storage.media.url_ttl", 3600)
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.use_ssl", true)
+
+	// 密钥后端默认值
+	viper.SetDefault("secrets.refresh_interval", 0)
+	viper.SetDefault("secrets.timeout", 5)
+
+	// 远程配置默认值
+	viper.SetDefault("remote.enabled", false)
+	viper.SetDefault("remote.provider", RemoteProviderEtcd)
+	viper.SetDefault("remote.endpoints", []string{})
+	viper.SetDefault("remote.timeout", 5)
+	viper.SetDefault("remote.optional", false)
+
+	// 指标快照默认值
+	viper.SetDefault("metrics.snapshots.enabled", true)
+	viper.SetDefault("metrics.snapshots.interval", 60)
+	viper.SetDefault("metrics.snapshots.raw_retention", 24)
+	viper.SetDefault("metrics.snapshots.rollup_interval", 60)
+	viper.SetDefault("metrics.snapshots.rollup_retention", 30)
+
+	// 通知默认值
+	viper.SetDefault("notifications.enabled", true)
+	viper.SetDefault("notifications.channels", []string{"email", "sms", "push", "in_app"})
+	viper.SetDefault("notifications.unread_count_ttl", 300)
+
+	// 公告默认值
+	viper.SetDefault("announcements.enabled", true)
+	viper.SetDefault("announcements.cache_ttl", 60)
+	viper.SetDefault("announcements.stream", true)
+	viper.SetDefault("announcements.stream_interval", 15)
+
+	// 数据导出默认值
+	viper.SetDefault("exports.enabled", true)
+	viper.SetDefault("exports.batch_size", 1000)
+	viper.SetDefault("exports.max_running", 2)
+	viper.SetDefault("exports.retention", 24)
+	viper.SetDefault("exports.url_ttl", 900)
+	viper.SetDefault("exports.signing_key", "")
+
+	// 数据保留策略默认值
+	viper.SetDefault("retention.enabled", false)
+	viper.SetDefault("retention.interval", 60)
+	viper.SetDefault("retention.batch_size", 1000)
+	viper.SetDefault("retention.dry_run", true)
+
+	// 数据库备份默认值
+	viper.SetDefault("backups.enabled", false)
+	viper.SetDefault("backups.timeout", 60)
+	viper.SetDefault("backups.interval", 0)
+	viper.SetDefault("backups.retention", 7)
+
+	// 批量导入默认值
+	viper.SetDefault("imports.enabled", true)
+	viper.SetDefault("imports.batch_size", 100)
+	viper.SetDefault("imports.max_rows", 1000)
+
+	// 字段加密默认值
+	viper.SetDefault("encryption.enabled", false)
+	viper.SetDefault("encryption.keys", "")
+	viper.SetDefault("encryption.active_key", 0)
+	viper.SetDefault("encryption.blind_index_key", "")
+
+	// OpenID Connect 默认值
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.issuer", "")
+	viper.SetDefault("oidc.consent_url", "")
+	viper.SetDefault("oidc.code_ttl", 60)
+	viper.SetDefault("oidc.id_token_ttl", 3600)
+
+	// SAML 单点登录默认值
+	viper.SetDefault("saml.enabled", false)
+	viper.SetDefault("saml.base_url", "")
+	viper.SetDefault("saml.success_url", "")
+	viper.SetDefault("saml.clock_skew", 90)
+	viper.SetDefault("saml.request_ttl", 600)
+
+	// 无密码登录默认值
+	viper.SetDefault("passwordless.enabled", false)
+	viper.SetDefault("passwordless.verification_url", "")
+	viper.SetDefault("passwordless.magic_link_url", "")
+	viper.SetDefault("passwordless.device_code_ttl", 600)
+	viper.SetDefault("passwordless.poll_interval", 5)
+	viper.SetDefault("passwordless.magic_link_ttl", 900)
+
+	// 用量配额默认值
+	viper.SetDefault("quota.enabled", false)
+	viper.SetDefault("quota.key_prefix", "quota")
+
+	// 订阅套餐默认值
+	viper.SetDefault("billing.enabled", false)
+	viper.SetDefault("billing.default_plan", "free")
+	viper.SetDefault("billing.signature_tolerance", 300)
+	viper.SetDefault("billing.cache_ttl", 60)
+
+	// 维护模式默认值
+	viper.SetDefault("maintenance.enabled", true)
+	viper.SetDefault("maintenance.active", false)
+	viper.SetDefault("maintenance.estimated_downtime", 0)
+	viper.SetDefault("maintenance.bypass_tokens", "")
+	viper.SetDefault("maintenance.allow_paths", []string{"/healthz", "/readyz", "/api/v1/health", "/api/v1/ready", "/api/v1/live", "/api/v1/admin/maintenance", "/api/v1/admin/drain"})
+	viper.SetDefault("maintenance.key_prefix", "maintenance")
+	viper.SetDefault("maintenance.refresh_interval", 5)
+
+	// 启动检查默认值
+	viper.SetDefault("preflight.enabled", true)
+	viper.SetDefault("preflight.timeout", 5)
+	viper.SetDefault("preflight.require_cache", false)
+	viper.SetDefault("preflight.fail_on_pending_migrations", false)
 
 	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			log.Printf("未找到配置文件，使用默认值和环境变量")
-		} else {
-			return nil, err
-		}
+	if err := mergeConfigFiles(env); err != nil {
+		return nil, err
+	}
+	if len(LoadedFiles()) == 0 {
+		log.Printf("未找到配置文件，使用默认值和环境变量")
+	}
+	if err := mergeRemoteConfig(); err != nil {
+		return nil, err
 	}
 
+	// 命令行参数覆盖
+	applyOverrides()
+
 	// 解析配置
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
@@ -241,6 +1265,12 @@ func NewConfigManager() (*ConfigManager, error) {
 		return nil, fmt.Errorf("加载初始配置失败: %w", err)
 	}
 
+	// 解析外部密钥引用
+	secrets := NewSecretResolver(cfg.Secrets)
+	if err := secrets.Resolve(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("解析初始配置密钥失败: %w", err)
+	}
+
 	// 验证初始配置
 	validator := NewValidator(cfg)
 	validationResult := validator.Validate()
@@ -254,6 +1284,7 @@ func NewConfigManager() (*ConfigManager, error) {
 		config:      cfg,
 		validConfig: deepCopyConfig(cfg),
 		handlers:    make(map[ConfigChangeType][]ConfigChangeHandler),
+		secrets:     secrets,
 		ctx:         ctx,
 		cancel:      cancel,
 		running:     false,
@@ -295,11 +1326,27 @@ func (cm *ConfigManager) StartWatching() error {
 		log.Printf("警告：将configs目录添加到监控器失败: %v", err)
 	}
 
+	// SIGHUP 触发重新加载，用于环境变量变更或无法触发文件事件的挂载卷
+	cm.signals = make(chan os.Signal, 1)
+	signal.Notify(cm.signals, syscall.SIGHUP)
+
 	cm.running = true
 
 	// 启动文件监控goroutine
 	go cm.watchFiles()
 
+	// 配置中包含密钥引用时定期刷新以支持轮换
+	if interval := time.Duration(cm.config.Secrets.RefreshInterval) * time.Second; interval > 0 && cm.secrets.HasReferences() {
+		go cm.refreshSecretsLoop(interval)
+		log.Printf("已启用密钥定期刷新，间隔: %s", interval)
+	}
+
+	// 监听远程配置变更
+	if cm.config.Remote.Enabled {
+		go cm.watchRemoteLoop(cm.config.Remote)
+		log.Printf("开始监听远程配置: %s %s", cm.config.Remote.Provider, cm.config.Remote.Key)
+	}
+
 	log.Printf("开始监控配置文件: %s", configFile)
 	return nil
 }
@@ -317,6 +1364,9 @@ func (cm *ConfigManager) StopWatching() {
 	if cm.watcher != nil {
 		cm.watcher.Close()
 	}
+	if cm.signals != nil {
+		signal.Stop(cm.signals)
+	}
 	cm.running = false
 
 	log.Println("已停止配置文件监控")
@@ -351,18 +1401,24 @@ func (cm *ConfigManager) watchFiles() {
 				return
 			}
 
-			// 只处理YAML文件的写入事件
-			if event.Op&fsnotify.Write == fsnotify.Write && filepath.Ext(event.Name) == ".yaml" {
+			// 处理YAML文件的写入、创建和重命名事件（编辑器和 ConfigMap 通常以重命名方式原子替换文件）
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 && filepath.Ext(event.Name) == ".yaml" {
 				log.Printf("配置文件已变更: %s", event.Name)
 
 				// 添加小延迟以处理快速文件写入
 				time.Sleep(100 * time.Millisecond)
 
-				if err := cm.reloadConfig(); err != nil {
+				if err := cm.Reload(); err != nil {
 					log.Printf("重新加载配置失败: %v", err)
 				}
 			}
 
+		case <-cm.signals:
+			log.Printf("收到 SIGHUP，重新加载配置")
+			if err := cm.Reload(); err != nil {
+				log.Printf("重新加载配置失败: %v", err)
+			}
+
 		case err, ok := <-cm.watcher.Errors:
 			if !ok {
 				return
@@ -375,41 +1431,6 @@ func (cm *ConfigManager) watchFiles() {
 	}
 }
 
-// reloadConfig 从文件重新加载配置
-func (cm *ConfigManager) reloadConfig() error {
-	// 加载新配置
-	newConfig, err := LoadConfig()
-	if err != nil {
-		return fmt.Errorf("加载新配置失败: %w", err)
-	}
-
-	// 验证新配置
-	validator := NewValidator(newConfig)
-	validationResult := validator.Validate()
-	if !validationResult.Valid {
-		log.Printf("配置验证失败，保持之前的有效配置:\n%s", validationResult.FormatErrors())
-		return fmt.Errorf("新配置无效")
-	}
-
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
-
-	// 检测变更并通知处理器
-	changes := cm.detectChanges(cm.config, newConfig)
-
-	// 更新配置
-	cm.validConfig = deepCopyConfig(newConfig)
-	cm.config = newConfig
-
-	// 通知处理器变更
-	for _, change := range changes {
-		cm.notifyHandlers(change)
-	}
-
-	log.Printf("配置成功重新加载，共 %d 项变更", len(changes))
-	return nil
-}
-
 // detectChanges 检测新旧配置之间的差异
 func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigChange {
 	var changes []ConfigChange
@@ -423,7 +1444,12 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 		oldConfig.Logging.MaxSize != newConfig.Logging.MaxSize ||
 		oldConfig.Logging.MaxBackups != newConfig.Logging.MaxBackups ||
 		oldConfig.Logging.MaxAge != newConfig.Logging.MaxAge ||
-		oldConfig.Logging.Compress != newConfig.Logging.Compress {
+		oldConfig.Logging.Compress != newConfig.Logging.Compress ||
+		oldConfig.Logging.BodyCapture.Enabled != newConfig.Logging.BodyCapture.Enabled ||
+		oldConfig.Logging.BodyCapture.MaxBytes != newConfig.Logging.BodyCapture.MaxBytes ||
+		!slices.Equal(oldConfig.Logging.BodyCapture.RedactFields, newConfig.Logging.BodyCapture.RedactFields) ||
+		oldConfig.Logging.Sampling != newConfig.Logging.Sampling ||
+		!reflect.DeepEqual(oldConfig.Logging.Shipping, newConfig.Logging.Shipping) {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeLogging,
 			OldValue:  oldConfig.Logging,
@@ -455,11 +1481,43 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 		})
 	}
 
+	// 检查跨域配置变更
+	if oldConfig.CORS.Enabled != newConfig.CORS.Enabled ||
+		!slices.Equal(oldConfig.CORS.AllowedOrigins, newConfig.CORS.AllowedOrigins) ||
+		!slices.Equal(oldConfig.CORS.AllowedMethods, newConfig.CORS.AllowedMethods) ||
+		!slices.Equal(oldConfig.CORS.AllowedHeaders, newConfig.CORS.AllowedHeaders) ||
+		!slices.Equal(oldConfig.CORS.ExposedHeaders, newConfig.CORS.ExposedHeaders) ||
+		oldConfig.CORS.AllowCredentials != newConfig.CORS.AllowCredentials ||
+		oldConfig.CORS.MaxAge != newConfig.CORS.MaxAge {
+		changes = append(changes, ConfigChange{
+			Type:      ConfigChangeTypeCORS,
+			OldValue:  oldConfig.CORS,
+			NewValue:  newConfig.CORS,
+			Timestamp: now,
+		})
+	}
+
+	// 检查请求体大小限制配置变更
+	if oldConfig.BodyLimit.Enabled != newConfig.BodyLimit.Enabled ||
+		oldConfig.BodyLimit.MaxBytes != newConfig.BodyLimit.MaxBytes ||
+		oldConfig.BodyLimit.MultipartMaxBytes != newConfig.BodyLimit.MultipartMaxBytes ||
+		oldConfig.BodyLimit.MaxDecompressedBytes != newConfig.BodyLimit.MaxDecompressedBytes ||
+		!slices.Equal(oldConfig.BodyLimit.Routes, newConfig.BodyLimit.Routes) {
+		changes = append(changes, ConfigChange{
+			Type:      ConfigChangeTypeBodyLimit,
+			OldValue:  oldConfig.BodyLimit,
+			NewValue:  newConfig.BodyLimit,
+			Timestamp: now,
+		})
+	}
+
 	// 检查服务器配置变更
 	if oldConfig.Server.Host != newConfig.Server.Host ||
 		oldConfig.Server.Port != newConfig.Server.Port ||
 		oldConfig.Server.ReadTimeout != newConfig.Server.ReadTimeout ||
-		oldConfig.Server.WriteTimeout != newConfig.Server.WriteTimeout {
+		oldConfig.Server.WriteTimeout != newConfig.Server.WriteTimeout ||
+		oldConfig.Server.ErrorFormat != newConfig.Server.ErrorFormat ||
+		oldConfig.Server.ProblemTypeBaseURL != newConfig.Server.ProblemTypeBaseURL {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeServer,
 			OldValue:  oldConfig.Server,
@@ -469,7 +1527,7 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 	}
 
 	// 检查认证配置变更
-	if oldConfig.Auth.BcryptCost != newConfig.Auth.BcryptCost {
+	if !reflect.DeepEqual(oldConfig.Auth, newConfig.Auth) {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeAuth,
 			OldValue:  oldConfig.Auth,
@@ -480,7 +1538,14 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 
 	// 检查JWT配置变更
 	if oldConfig.JWT.SecretKey != newConfig.JWT.SecretKey ||
-		oldConfig.JWT.ExpiresIn != newConfig.JWT.ExpiresIn {
+		oldConfig.JWT.ExpiresIn != newConfig.JWT.ExpiresIn ||
+		oldConfig.JWT.RefreshExpiresIn != newConfig.JWT.RefreshExpiresIn ||
+		oldConfig.JWT.Algorithm != newConfig.JWT.Algorithm ||
+		oldConfig.JWT.KeyID != newConfig.JWT.KeyID ||
+		oldConfig.JWT.PrivateKey != newConfig.JWT.PrivateKey ||
+		oldConfig.JWT.PrivateKeyFile != newConfig.JWT.PrivateKeyFile ||
+		oldConfig.JWT.RotationGracePeriod != newConfig.JWT.RotationGracePeriod ||
+		!slices.Equal(oldConfig.JWT.PreviousKeys, newConfig.JWT.PreviousKeys) {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeJWT,
 			OldValue:  oldConfig.JWT,
@@ -494,7 +1559,9 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 		oldConfig.Redis.Port != newConfig.Redis.Port ||
 		oldConfig.Redis.Password != newConfig.Redis.Password ||
 		oldConfig.Redis.DB != newConfig.Redis.DB ||
-		oldConfig.Redis.PoolSize != newConfig.Redis.PoolSize {
+		oldConfig.Redis.PoolSize != newConfig.Redis.PoolSize ||
+		oldConfig.Redis.CacheTTL != newConfig.Redis.CacheTTL ||
+		oldConfig.Redis.NegativeCacheTTL != newConfig.Redis.NegativeCacheTTL {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeRedis,
 			OldValue:  oldConfig.Redis,
@@ -512,7 +1579,8 @@ func (cm *ConfigManager) detectChanges(oldConfig, newConfig *Config) []ConfigCha
 		oldConfig.Database.SSLMode != newConfig.Database.SSLMode ||
 		oldConfig.Database.MaxOpenConns != newConfig.Database.MaxOpenConns ||
 		oldConfig.Database.MaxIdleConns != newConfig.Database.MaxIdleConns ||
-		oldConfig.Database.ConnMaxLifetime != newConfig.Database.ConnMaxLifetime {
+		oldConfig.Database.ConnMaxLifetime != newConfig.Database.ConnMaxLifetime ||
+		oldConfig.Database.SlowQueryThreshold != newConfig.Database.SlowQueryThreshold {
 		changes = append(changes, ConfigChange{
 			Type:      ConfigChangeTypeDatabase,
 			OldValue:  oldConfig.Database,
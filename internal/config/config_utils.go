@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"maps"
 )
 
 // deepCopyConfig 使用JSON序列化进行深拷贝
@@ -33,46 +34,158 @@ func deepCopyConfig(cfg *Config) *Config {
 func manualDeepCopy(cfg *Config) *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         cfg.Server.Port,
-			Host:         cfg.Server.Host,
-			ReadTimeout:  cfg.Server.ReadTimeout,
-			WriteTimeout: cfg.Server.WriteTimeout,
+			Port:               cfg.Server.Port,
+			Host:               cfg.Server.Host,
+			ReadTimeout:        cfg.Server.ReadTimeout,
+			WriteTimeout:       cfg.Server.WriteTimeout,
+			Shutdown:           cfg.Server.Shutdown,
+			ErrorFormat:        cfg.Server.ErrorFormat,
+			ProblemTypeBaseURL: cfg.Server.ProblemTypeBaseURL,
+			ACME:               copyACMEConfig(cfg.Server.ACME),
+			MTLS:               copyMTLSConfig(cfg.Server.MTLS),
 		},
 		Database: DatabaseConfig{
-			Host:            cfg.Database.Host,
-			Port:            cfg.Database.Port,
-			User:            cfg.Database.User,
-			Password:        cfg.Database.Password,
-			DBName:          cfg.Database.DBName,
-			SSLMode:         cfg.Database.SSLMode,
-			MaxOpenConns:    cfg.Database.MaxOpenConns,
-			MaxIdleConns:    cfg.Database.MaxIdleConns,
-			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+			Host:                 cfg.Database.Host,
+			Port:                 cfg.Database.Port,
+			User:                 cfg.Database.User,
+			Password:             cfg.Database.Password,
+			DBName:               cfg.Database.DBName,
+			SSLMode:              cfg.Database.SSLMode,
+			MaxOpenConns:         cfg.Database.MaxOpenConns,
+			MaxIdleConns:         cfg.Database.MaxIdleConns,
+			ConnMaxLifetime:      cfg.Database.ConnMaxLifetime,
+			SlowQueryThreshold:   cfg.Database.SlowQueryThreshold,
+			RequestQueries:       cfg.Database.RequestQueries,
+			LastLoginBatching:    cfg.Database.LastLoginBatching,
+			Retry:                cfg.Database.Retry,
+			MigrationLockTimeout: cfg.Database.MigrationLockTimeout,
 		},
 		Auth: AuthConfig{
-			BcryptCost: cfg.Auth.BcryptCost,
+			BcryptCost:        cfg.Auth.BcryptCost,
+			PasswordAlgorithm: cfg.Auth.PasswordAlgorithm,
+			Argon2:            cfg.Auth.Argon2,
+			PasswordPolicy:    copyPasswordPolicy(cfg.Auth.PasswordPolicy),
 		},
 		JWT: JWTConfig{
-			SecretKey: cfg.JWT.SecretKey,
-			ExpiresIn: cfg.JWT.ExpiresIn,
+			SecretKey:           cfg.JWT.SecretKey,
+			ExpiresIn:           cfg.JWT.ExpiresIn,
+			RefreshExpiresIn:    cfg.JWT.RefreshExpiresIn,
+			Algorithm:           cfg.JWT.Algorithm,
+			KeyID:               cfg.JWT.KeyID,
+			PrivateKey:          cfg.JWT.PrivateKey,
+			PrivateKeyFile:      cfg.JWT.PrivateKeyFile,
+			PreviousKeys:        append([]JWTKeyConfig(nil), cfg.JWT.PreviousKeys...),
+			RotationGracePeriod: cfg.JWT.RotationGracePeriod,
+			BlacklistFilter:     cfg.JWT.BlacklistFilter,
 		},
 		Redis: RedisConfig{
-			Host:     cfg.Redis.Host,
-			Port:     cfg.Redis.Port,
-			Password: cfg.Redis.Password,
-			DB:       cfg.Redis.DB,
-			PoolSize: cfg.Redis.PoolSize,
+			Host:             cfg.Redis.Host,
+			Port:             cfg.Redis.Port,
+			Password:         cfg.Redis.Password,
+			DB:               cfg.Redis.DB,
+			PoolSize:         cfg.Redis.PoolSize,
+			CacheTTL:         cfg.Redis.CacheTTL,
+			NegativeCacheTTL: cfg.Redis.NegativeCacheTTL,
+		},
+		Cache: CacheConfig{
+			Driver: cfg.Cache.Driver,
+			Memcached: CacheMemcachedConfig{
+				Servers:      append([]string(nil), cfg.Cache.Memcached.Servers...),
+				Timeout:      cfg.Cache.Memcached.Timeout,
+				MaxIdleConns: cfg.Cache.Memcached.MaxIdleConns,
+			},
+			Warmup: CacheWarmupConfig{
+				OnStartup: cfg.Cache.Warmup.OnStartup,
+				Datasets:  append([]string(nil), cfg.Cache.Warmup.Datasets...),
+				TopUsers:  cfg.Cache.Warmup.TopUsers,
+				Timeout:   cfg.Cache.Warmup.Timeout,
+			},
+			Codec: cfg.Cache.Codec,
+			TTLs:  maps.Clone(cfg.Cache.TTLs),
+			Supervisor: CacheSupervisorConfig{
+				Enabled:          cfg.Cache.Supervisor.Enabled,
+				FailureThreshold: cfg.Cache.Supervisor.FailureThreshold,
+				ProbeInterval:    cfg.Cache.Supervisor.ProbeInterval,
+				ProbeTimeout:     cfg.Cache.Supervisor.ProbeTimeout,
+			},
 		},
 		RateLimit: RateLimitConfig{
-			Enabled:  cfg.RateLimit.Enabled,
-			Requests: cfg.RateLimit.Requests,
-			Window:   cfg.RateLimit.Window,
-			RedisKey: cfg.RateLimit.RedisKey,
+			Enabled:       cfg.RateLimit.Enabled,
+			Requests:      cfg.RateLimit.Requests,
+			Window:        cfg.RateLimit.Window,
+			RedisKey:      cfg.RateLimit.RedisKey,
+			Mode:          cfg.RateLimit.Mode,
+			WarnThreshold: cfg.RateLimit.WarnThreshold,
+			Algorithm:     cfg.RateLimit.Algorithm,
+			Burst:         cfg.RateLimit.Burst,
+			Costs:         append([]RateLimitCostConfig(nil), cfg.RateLimit.Costs...),
+			AutoBan:       cfg.RateLimit.AutoBan,
 		},
 		Compression: CompressionConfig{
 			Enabled:   cfg.Compression.Enabled,
 			Threshold: cfg.Compression.Threshold,
 		},
+		CORS: CORSConfig{
+			Enabled:          cfg.CORS.Enabled,
+			AllowedOrigins:   append([]string(nil), cfg.CORS.AllowedOrigins...),
+			AllowedMethods:   append([]string(nil), cfg.CORS.AllowedMethods...),
+			AllowedHeaders:   append([]string(nil), cfg.CORS.AllowedHeaders...),
+			ExposedHeaders:   append([]string(nil), cfg.CORS.ExposedHeaders...),
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		},
+		BodyLimit: BodyLimitConfig{
+			Enabled:              cfg.BodyLimit.Enabled,
+			MaxBytes:             cfg.BodyLimit.MaxBytes,
+			MultipartMaxBytes:    cfg.BodyLimit.MultipartMaxBytes,
+			MaxDecompressedBytes: cfg.BodyLimit.MaxDecompressedBytes,
+			Routes:               append([]BodyLimitRoute(nil), cfg.BodyLimit.Routes...),
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:     cfg.Idempotency.Enabled,
+			TTL:         cfg.Idempotency.TTL,
+			LockTimeout: cfg.Idempotency.LockTimeout,
+			Methods:     append([]string(nil), cfg.Idempotency.Methods...),
+			KeyPrefix:   cfg.Idempotency.KeyPrefix,
+		},
+		ETag: ETagConfig{
+			Enabled:      cfg.ETag.Enabled,
+			MaxBodySize:  cfg.ETag.MaxBodySize,
+			ExcludePaths: append([]string(nil), cfg.ETag.ExcludePaths...),
+		},
+		ResponseCache: ResponseCacheConfig{
+			Enabled:              cfg.ResponseCache.Enabled,
+			KeyPrefix:            cfg.ResponseCache.KeyPrefix,
+			StaleWhileRevalidate: cfg.ResponseCache.StaleWhileRevalidate,
+			MaxBodySize:          cfg.ResponseCache.MaxBodySize,
+			Routes:               copyResponseCacheRoutes(cfg.ResponseCache.Routes),
+		},
+		Coalescing: CoalescingConfig{
+			Enabled:     cfg.Coalescing.Enabled,
+			MaxBodySize: cfg.Coalescing.MaxBodySize,
+		},
+		OpenAPI: OpenAPIConfig{
+			ValidateRequests: cfg.OpenAPI.ValidateRequests,
+			Strict:           cfg.OpenAPI.Strict,
+			ExcludePaths:     append([]string(nil), cfg.OpenAPI.ExcludePaths...),
+		},
+		FeatureFlags: FeatureFlagsConfig{
+			Enabled:   cfg.FeatureFlags.Enabled,
+			Backend:   cfg.FeatureFlags.Backend,
+			CacheTTL:  cfg.FeatureFlags.CacheTTL,
+			KeyPrefix: cfg.FeatureFlags.KeyPrefix,
+			Flags:     copyFeatureFlags(cfg.FeatureFlags.Flags),
+		},
+		Tenancy: TenancyConfig{
+			Enabled:      cfg.Tenancy.Enabled,
+			Required:     cfg.Tenancy.Required,
+			Sources:      append([]string(nil), cfg.Tenancy.Sources...),
+			Header:       cfg.Tenancy.Header,
+			BaseDomain:   cfg.Tenancy.BaseDomain,
+			CacheTTL:     cfg.Tenancy.CacheTTL,
+			ExcludePaths: append([]string(nil), cfg.Tenancy.ExcludePaths...),
+		},
+		GeoIP: cfg.GeoIP,
 		Logging: LoggingConfig{
 			Level:      cfg.Logging.Level,
 			Format:     cfg.Logging.Format,
@@ -82,7 +195,199 @@ func manualDeepCopy(cfg *Config) *Config {
 			MaxBackups: cfg.Logging.MaxBackups,
 			MaxAge:     cfg.Logging.MaxAge,
 			Compress:   cfg.Logging.Compress,
+			BodyCapture: LoggingBodyCaptureConfig{
+				Enabled:      cfg.Logging.BodyCapture.Enabled,
+				MaxBytes:     cfg.Logging.BodyCapture.MaxBytes,
+				RedactFields: append([]string(nil), cfg.Logging.BodyCapture.RedactFields...),
+			},
+			Sampling: cfg.Logging.Sampling,
+			Shipping: LoggingShippingConfig{
+				URL:           cfg.Logging.Shipping.URL,
+				Headers:       maps.Clone(cfg.Logging.Shipping.Headers),
+				Labels:        maps.Clone(cfg.Logging.Shipping.Labels),
+				BatchSize:     cfg.Logging.Shipping.BatchSize,
+				FlushInterval: cfg.Logging.Shipping.FlushInterval,
+				QueueSize:     cfg.Logging.Shipping.QueueSize,
+				MaxRetries:    cfg.Logging.Shipping.MaxRetries,
+				Timeout:       cfg.Logging.Shipping.Timeout,
+			},
+		},
+		ErrorReporting: ErrorReportingConfig{
+			Enabled:     cfg.ErrorReporting.Enabled,
+			DSN:         cfg.ErrorReporting.DSN,
+			Environment: cfg.ErrorReporting.Environment,
+			Release:     cfg.ErrorReporting.Release,
+			SampleRate:  cfg.ErrorReporting.SampleRate,
+			ScrubFields: append([]string(nil), cfg.ErrorReporting.ScrubFields...),
+			Timeout:     cfg.ErrorReporting.Timeout,
+			QueueSize:   cfg.ErrorReporting.QueueSize,
+		},
+		Events: EventsConfig{
+			Enabled: cfg.Events.Enabled,
+			Driver:  cfg.Events.Driver,
+			Source:  cfg.Events.Source,
+			NATS: EventsNATSConfig{
+				URL:            cfg.Events.NATS.URL,
+				Name:           cfg.Events.NATS.Name,
+				QueueGroup:     cfg.Events.NATS.QueueGroup,
+				ConnectTimeout: cfg.Events.NATS.ConnectTimeout,
+				MaxReconnects:  cfg.Events.NATS.MaxReconnects,
+			},
+			Kafka: EventsKafkaConfig{
+				Brokers:     append([]string(nil), cfg.Events.Kafka.Brokers...),
+				ClientID:    cfg.Events.Kafka.ClientID,
+				GroupID:     cfg.Events.Kafka.GroupID,
+				TopicPrefix: cfg.Events.Kafka.TopicPrefix,
+			},
+			DrainTimeout: cfg.Events.DrainTimeout,
 		},
-		Mode: cfg.Mode,
+		Storage: StorageConfig{
+			Driver:              cfg.Storage.Driver,
+			MaxUploadSize:       cfg.Storage.MaxUploadSize,
+			AllowedContentTypes: append([]string(nil), cfg.Storage.AllowedContentTypes...),
+			Local: StorageLocalConfig{
+				BaseDir: cfg.Storage.Local.BaseDir,
+				BaseURL: cfg.Storage.Local.BaseURL,
+			},
+			S3: StorageS3Config{
+				Endpoint:        cfg.Storage.S3.Endpoint,
+				Region:          cfg.Storage.S3.Region,
+				Bucket:          cfg.Storage.S3.Bucket,
+				AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+				SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+				UseSSL:          cfg.Storage.S3.UseSSL,
+				PublicURL:       cfg.Storage.S3.PublicURL,
+			},
+			Media: cfg.Storage.Media,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: cfg.Secrets.RefreshInterval,
+			Timeout:         cfg.Secrets.Timeout,
+			Vault:           cfg.Secrets.Vault,
+			AWS:             cfg.Secrets.AWS,
+		},
+		Remote: RemoteConfig{
+			Enabled:   cfg.Remote.Enabled,
+			Provider:  cfg.Remote.Provider,
+			Endpoints: append([]string(nil), cfg.Remote.Endpoints...),
+			Key:       cfg.Remote.Key,
+			Token:     cfg.Remote.Token,
+			Username:  cfg.Remote.Username,
+			Password:  cfg.Remote.Password,
+			Timeout:   cfg.Remote.Timeout,
+			Optional:  cfg.Remote.Optional,
+		},
+		Metrics: MetricsConfig{
+			Snapshots: cfg.Metrics.Snapshots,
+		},
+		Notifications: NotificationsConfig{
+			Enabled:        cfg.Notifications.Enabled,
+			Channels:       append([]string(nil), cfg.Notifications.Channels...),
+			UnreadCountTTL: cfg.Notifications.UnreadCountTTL,
+		},
+		Announcements: cfg.Announcements,
+		Exports:       cfg.Exports,
+		Retention:     copyRetentionConfig(cfg.Retention),
+		Backups:       cfg.Backups,
+		Imports:       cfg.Imports,
+		Encryption:    cfg.Encryption,
+		OIDC:          cfg.OIDC,
+		SAML:          copySAMLConfig(cfg.SAML),
+		Passwordless:  cfg.Passwordless,
+		Quota:         copyQuotaConfig(cfg.Quota),
+		Billing:       copyBillingConfig(cfg.Billing),
+		Maintenance:   copyMaintenanceConfig(cfg.Maintenance),
+		Preflight:     cfg.Preflight,
+		Mode:          cfg.Mode,
+	}
+}
+
+// copyRetentionConfig 复制数据保留策略配置，策略列表不与原配置共享
+func copyRetentionConfig(cfg RetentionConfig) RetentionConfig {
+	cfg.Policies = append([]RetentionPolicyConfig(nil), cfg.Policies...)
+	return cfg
+}
+
+// copyQuotaConfig 复制用量配额配置，限制列表不与原配置共享
+func copyQuotaConfig(cfg QuotaConfig) QuotaConfig {
+	cfg.Limits = append([]QuotaLimitConfig(nil), cfg.Limits...)
+	return cfg
+}
+
+// copyBillingConfig 复制订阅套餐配置，套餐及其列表不与原配置共享
+func copyBillingConfig(cfg BillingConfig) BillingConfig {
+	plans := make([]PlanConfig, len(cfg.Plans))
+	for i, plan := range cfg.Plans {
+		plan.Entitlements = append([]string(nil), plan.Entitlements...)
+		plan.Quotas = append([]QuotaLimitConfig(nil), plan.Quotas...)
+		plan.StripePriceIDs = append([]string(nil), plan.StripePriceIDs...)
+		plans[i] = plan
+	}
+	cfg.Plans = plans
+	return cfg
+}
+
+// copyMaintenanceConfig 复制维护模式配置，放行路径列表不与原配置共享
+func copyMaintenanceConfig(cfg MaintenanceConfig) MaintenanceConfig {
+	cfg.AllowPaths = append([]string(nil), cfg.AllowPaths...)
+	return cfg
+}
+
+// copySAMLConfig 复制 SAML 配置，身份提供方列表不与原配置共享
+func copySAMLConfig(cfg SAMLConfig) SAMLConfig {
+	cfg.Providers = append([]SAMLProviderConfig(nil), cfg.Providers...)
+	return cfg
+}
+
+// copyFeatureFlags 深拷贝功能开关定义，包括每个开关的用户列表
+func copyFeatureFlags(flags []FeatureFlagConfig) []FeatureFlagConfig {
+	if flags == nil {
+		return nil
+	}
+	copied := make([]FeatureFlagConfig, len(flags))
+	for i, flag := range flags {
+		copied[i] = flag
+		copied[i].Users = append([]string(nil), flag.Users...)
+	}
+	return copied
+}
+
+// copyPasswordPolicy 深拷贝密码策略，包括禁用密码列表；保留空列表与 nil 的区别，避免热重载时误报认证配置变更
+func copyPasswordPolicy(policy PasswordPolicyConfig) PasswordPolicyConfig {
+	policy.Denylist = append(policy.Denylist[:0:0], policy.Denylist...)
+	return policy
+}
+
+// copyACMEConfig 深拷贝自动证书配置，包括域名列表
+func copyACMEConfig(acme ACMEConfig) ACMEConfig {
+	acme.Domains = append(acme.Domains[:0:0], acme.Domains...)
+	return acme
+}
+
+// copyMTLSConfig 深拷贝双向 TLS 配置，包括服务账户的身份和权限列表
+func copyMTLSConfig(mtls MTLSConfig) MTLSConfig {
+	if mtls.ServiceAccounts == nil {
+		return mtls
+	}
+	accounts := make([]ServiceAccountConfig, len(mtls.ServiceAccounts))
+	for i, account := range mtls.ServiceAccounts {
+		accounts[i] = account
+		accounts[i].Identities = append([]string(nil), account.Identities...)
+		accounts[i].Permissions = append([]string(nil), account.Permissions...)
+	}
+	mtls.ServiceAccounts = accounts
+	return mtls
+}
+
+// copyResponseCacheRoutes 深拷贝响应缓存规则，包括每条规则的标签列表
+func copyResponseCacheRoutes(routes []ResponseCacheRoute) []ResponseCacheRoute {
+	if routes == nil {
+		return nil
+	}
+	copied := make([]ResponseCacheRoute, len(routes))
+	for i, route := range routes {
+		copied[i] = route
+		copied[i].Tags = append([]string(nil), route.Tags...)
 	}
+	return copied
 }
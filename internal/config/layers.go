@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// 配置按以下顺序分层加载，后加载的覆盖先加载的：
+//
+//  1. 代码中的默认值
+//  2. configs/config.yaml：所有环境共享的基础配置（可选）
+//  3. configs/{APP_ENV}.yaml：当前环境的配置
+//  4. 远程配置文档（启用 remote 时），见 remote.go
+//  5. .env 文件：只设置进程中尚未存在的环境变量
+//  6. APP_ 前缀的环境变量，如 APP_DATABASE_HOST 覆盖 database.host
+//  7. 命令行参数，通过 SetOverrides 设置，如 cmd/api -set redis.db=2
+//
+// YAML 文件中的 ${VAR}、${VAR:-默认值} 和 ${VAR:?错误信息} 在解析前展开，$${ 表示字面量 ${
+
+// configSearchPaths 配置文件的查找目录，按顺序取第一个存在的文件
+var configSearchPaths = []string{"./configs", "."}
+
+// dotEnvFile .env 文件路径，相对于工作目录
+const dotEnvFile = ".env"
+
+// envRefRegex 匹配 ${VAR}、${VAR:-default} 和 ${VAR:?message}，以及转义的 $${
+var envRefRegex = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:-|:\?)([^}]*))?\}`)
+
+var (
+	layersMu    sync.Mutex
+	overrides   map[string]string
+	loadedFiles []string
+	// dotEnvKeys 由 .env 文件设置的环境变量，重新加载时允许被新的 .env 内容覆盖
+	dotEnvKeys = make(map[string]bool)
+)
+
+// SetOverrides 设置命令行参数覆盖的配置项（键为 viper 路径，如 server.port），优先级最高，重新加载时保留
+func SetOverrides(values map[string]string) {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+
+	overrides = make(map[string]string, len(values))
+	for key, value := range values {
+		overrides[strings.ToLower(key)] = value
+	}
+}
+
+// LoadedFiles 返回最近一次 LoadConfig 读取的配置文件，按加载顺序排列
+func LoadedFiles() []string {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	return append([]string(nil), loadedFiles...)
+}
+
+// ExpandEnv 展开字符串中的环境变量引用，${VAR:?message} 引用的变量未设置或为空时返回错误
+func ExpandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envRefRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+
+		match := envRefRegex.FindStringSubmatch(ref)
+		name, operator, operand := match[1], match[2], match[3]
+		current, ok := os.LookupEnv(name)
+		if ok && current != "" {
+			return current
+		}
+
+		switch operator {
+		case ":-":
+			return operand
+		case ":?":
+			message := operand
+			if message == "" {
+				message = "未设置"
+			}
+			missing = append(missing, fmt.Sprintf("%s: %s", name, message))
+		}
+		return current
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("缺少必需的环境变量: %s", strings.Join(missing, "; "))
+	}
+	return expanded, nil
+}
+
+// mergeConfigFiles 依次读取基础配置和环境配置，展开环境变量后合并到 viper
+func mergeConfigFiles(env string) error {
+	var files []string
+	for _, name := range []string{"config", env} {
+		path, ok := findConfigFile(name + ".yaml")
+		if !ok {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+		}
+		expanded, err := ExpandEnv(string(content))
+		if err != nil {
+			return fmt.Errorf("配置文件 %s: %w", path, err)
+		}
+		if err := viper.MergeConfig(bytes.NewReader([]byte(expanded))); err != nil {
+			return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+		files = append(files, path)
+	}
+
+	layersMu.Lock()
+	loadedFiles = files
+	layersMu.Unlock()
+	return nil
+}
+
+// findConfigFile 在查找目录中定位配置文件
+func findConfigFile(name string) (string, bool) {
+	for _, dir := range configSearchPaths {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// applyOverrides 将命令行覆盖写入 viper
+func applyOverrides() {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		viper.Set(key, overrides[key])
+	}
+}
+
+// loadDotEnv 读取 .env 文件并设置尚未存在的环境变量，文件不存在时忽略
+func loadDotEnv(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	defer file.Close()
+
+	layersMu.Lock()
+	defer layersMu.Unlock()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("%s 第 %d 行格式错误，应为 KEY=VALUE", path, lineNumber)
+		}
+		value = unquoteDotEnvValue(strings.TrimSpace(value))
+
+		// 进程环境变量优先于 .env 文件
+		if _, exists := os.LookupEnv(key); exists && !dotEnvKeys[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("设置环境变量 %s 失败: %w", key, err)
+		}
+		dotEnvKeys[key] = true
+	}
+	return scanner.Err()
+}
+
+// unquoteDotEnvValue 去掉值两侧的引号，未加引号的值去掉行尾注释
+func unquoteDotEnvValue(value string) string {
+	if len(value) >= 2 {
+		if quote := value[0]; (quote == '"' || quote == '\'') && value[len(value)-1] == quote {
+			return value[1 : len(value)-1]
+		}
+	}
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
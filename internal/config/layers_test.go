@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("LAYERS_TEST_HOST", "db.internal")
+	t.Setenv("LAYERS_TEST_EMPTY", "")
+
+	for _, tc := range []struct {
+		name, input, expected string
+	}{
+		{"已设置", "host: ${LAYERS_TEST_HOST}", "host: db.internal"},
+		{"未设置", "host: ${LAYERS_TEST_MISSING}", "host: "},
+		{"默认值", "port: ${LAYERS_TEST_MISSING:-5432}", "port: 5432"},
+		{"空值使用默认值", "port: ${LAYERS_TEST_EMPTY:-5432}", "port: 5432"},
+		{"已设置时忽略默认值", "${LAYERS_TEST_HOST:-localhost}", "db.internal"},
+		{"转义", "password: p$${LAYERS_TEST_HOST}", "password: p${LAYERS_TEST_HOST}"},
+		{"普通的美元符号", "password: pa$$word$", "password: pa$$word$"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, err := ExpandEnv(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, expanded)
+		})
+	}
+
+	t.Run("必需的变量", func(t *testing.T) {
+		_, err := ExpandEnv("secret: ${LAYERS_TEST_MISSING:?请设置 JWT 密钥}")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "LAYERS_TEST_MISSING: 请设置 JWT 密钥")
+	})
+}
+
+func TestLoadConfig_Layers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0o755))
+	write := func(path, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+
+	write("configs/config.yaml", `
+server:
+  port: "8000"
+  host: "0.0.0.0"
+redis:
+  host: "${LAYERS_TEST_REDIS_HOST:-redis.local}"
+  db: 1
+database:
+  host: "base-db"
+`)
+	write("configs/staging.yaml", `
+server:
+  port: "8100"
+database:
+  host: "staging-db"
+  user: "${LAYERS_TEST_DB_USER}"
+`)
+	write(".env", `
+# 本地开发覆盖
+export LAYERS_TEST_DB_USER="app"
+APP_ENV=staging
+APP_DATABASE_HOST=dotenv-db # 被进程环境变量覆盖
+APP_REDIS_DB=4
+`)
+
+	t.Chdir(dir)
+	t.Setenv("APP_ENV", "")
+	os.Unsetenv("APP_ENV")
+	t.Setenv("APP_DATABASE_HOST", "env-db")
+	for _, key := range []string{"LAYERS_TEST_DB_USER", "APP_REDIS_DB"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Cleanup(func() {
+		dotEnvKeys = make(map[string]bool)
+	})
+
+	SetOverrides(map[string]string{"Server.Port": "9000"})
+	t.Cleanup(func() { SetOverrides(nil) })
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.Mode, ".env 可以选择环境")
+	assert.Equal(t, []string{filepath.Join("configs", "config.yaml"), filepath.Join("configs", "staging.yaml")}, LoadedFiles())
+	assert.Equal(t, "0.0.0.0", cfg.Server.Host, "基础配置")
+	assert.Equal(t, "redis.local", cfg.Redis.Host, "展开默认值")
+	assert.Equal(t, "app", cfg.Database.User, ".env 中的变量可用于展开")
+	assert.Equal(t, 4, cfg.Redis.DB, ".env 覆盖配置文件")
+	assert.Equal(t, "env-db", cfg.Database.Host, "环境变量优先于 .env 和配置文件")
+	assert.Equal(t, "9000", cfg.Server.Port, "命令行参数优先级最高")
+}
+
+func TestUnquoteDotEnvValue(t *testing.T) {
+	assert.Equal(t, "a b", unquoteDotEnvValue(`"a b"`))
+	assert.Equal(t, "a # not a comment", unquoteDotEnvValue(`'a # not a comment'`))
+	assert.Equal(t, "value", unquoteDotEnvValue("value # comment"))
+	assert.Equal(t, "pa#ss", unquoteDotEnvValue("pa#ss"))
+}
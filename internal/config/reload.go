@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Subscriber 配置订阅者，在配置重新加载时以两阶段方式接收新配置：
+// 先由所有订阅者校验，全部通过后才切换配置并依次应用
+type Subscriber interface {
+	// Name 返回订阅者名称，用于日志
+	Name() string
+
+	// ValidateConfig 校验新配置是否可被应用，返回错误时放弃本次重载
+	ValidateConfig(newConfig *Config) error
+
+	// ApplyConfig 应用新配置，实现方应原子地替换内部状态，避免请求看到一半新一半旧的配置
+	ApplyConfig(oldConfig, newConfig *Config) error
+}
+
+// funcSubscriber 基于函数的订阅者
+type funcSubscriber struct {
+	name     string
+	validate func(newConfig *Config) error
+	apply    func(oldConfig, newConfig *Config) error
+}
+
+// NewSubscriber 使用函数创建订阅者，validate 可以为 nil
+func NewSubscriber(name string, validate func(newConfig *Config) error, apply func(oldConfig, newConfig *Config) error) Subscriber {
+	return &funcSubscriber{name: name, validate: validate, apply: apply}
+}
+
+// Name 返回订阅者名称
+func (s *funcSubscriber) Name() string {
+	return s.name
+}
+
+// ValidateConfig 校验新配置
+func (s *funcSubscriber) ValidateConfig(newConfig *Config) error {
+	if s.validate == nil {
+		return nil
+	}
+	return s.validate(newConfig)
+}
+
+// ApplyConfig 应用新配置
+func (s *funcSubscriber) ApplyConfig(oldConfig, newConfig *Config) error {
+	if s.apply == nil {
+		return nil
+	}
+	return s.apply(oldConfig, newConfig)
+}
+
+// Subscribe 注册配置订阅者，订阅者按注册顺序应用配置
+func (cm *ConfigManager) Subscribe(subscriber Subscriber) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.subscribers = append(cm.subscribers, subscriber)
+}
+
+// Reload 重新读取配置文件和环境变量并应用到所有订阅者
+// 流程：加载 -> 解析密钥引用 -> 全局校验 -> 订阅者校验 -> 原子切换 -> 订阅者应用 -> 通知变更处理器；
+// 任一订阅者应用失败时，已应用的订阅者回滚到旧配置，当前配置保持不变
+func (cm *ConfigManager) Reload() error {
+	cm.reloadMu.Lock()
+	defer cm.reloadMu.Unlock()
+
+	newConfig, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载新配置失败: %w", err)
+	}
+
+	if cm.secrets != nil {
+		if err := cm.secrets.Resolve(context.Background(), newConfig); err != nil {
+			log.Printf("解析密钥失败，保持之前的有效配置: %v", err)
+			return err
+		}
+	}
+
+	return cm.apply(newConfig)
+}
+
+// apply 校验新配置并原子切换，调用方需持有 reloadMu
+func (cm *ConfigManager) apply(newConfig *Config) error {
+	// 全局校验
+	validationResult := NewValidator(newConfig).Validate()
+	if !validationResult.Valid {
+		log.Printf("配置验证失败，保持之前的有效配置:\n%s", validationResult.FormatErrors())
+		return fmt.Errorf("新配置无效")
+	}
+
+	cm.mu.RLock()
+	oldConfig := cm.config
+	subscribers := append([]Subscriber(nil), cm.subscribers...)
+	cm.mu.RUnlock()
+
+	changes := cm.detectChanges(oldConfig, newConfig)
+
+	// 订阅者校验，任一失败则放弃本次重载
+	for _, subscriber := range subscribers {
+		if err := subscriber.ValidateConfig(newConfig); err != nil {
+			log.Printf("配置订阅者 %s 拒绝新配置，保持之前的有效配置: %v", subscriber.Name(), err)
+			return fmt.Errorf("配置订阅者 %s 校验失败: %w", subscriber.Name(), err)
+		}
+	}
+
+	// 原子切换当前配置
+	cm.mu.Lock()
+	cm.config = newConfig
+	cm.mu.Unlock()
+
+	// 依次应用到订阅者
+	applied := make([]Subscriber, 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		if err := subscriber.ApplyConfig(oldConfig, newConfig); err != nil {
+			applyErr := fmt.Errorf("配置订阅者 %s 应用失败: %w", subscriber.Name(), err)
+			log.Printf("%v，回滚到之前的配置", applyErr)
+			return errors.Join(applyErr, cm.rollback(applied, oldConfig, newConfig))
+		}
+		applied = append(applied, subscriber)
+	}
+
+	cm.mu.Lock()
+	cm.validConfig = deepCopyConfig(newConfig)
+	for _, change := range changes {
+		cm.notifyHandlers(change)
+	}
+	cm.mu.Unlock()
+
+	log.Printf("配置成功重新加载，共 %d 项变更，已应用到 %d 个订阅者", len(changes), len(applied))
+	return nil
+}
+
+// rollback 恢复旧配置并让已应用新配置的订阅者重新应用旧配置
+func (cm *ConfigManager) rollback(applied []Subscriber, oldConfig, newConfig *Config) error {
+	cm.mu.Lock()
+	cm.config = oldConfig
+	cm.mu.Unlock()
+
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i].ApplyConfig(newConfig, oldConfig); err != nil {
+			errs = append(errs, fmt.Errorf("配置订阅者 %s 回滚失败: %w", applied[i].Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReloadTest 在临时目录中准备开发环境配置文件，返回修改缓存过期时间的函数
+func setupReloadTest(t *testing.T) func(ttl string) {
+	original, err := os.ReadFile(filepath.Join("..", "..", "configs", "development.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(original), "cache_ttl: 300")
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0o755))
+	path := filepath.Join(dir, "configs", "development.yaml")
+	require.NoError(t, os.WriteFile(path, original, 0o644))
+
+	t.Chdir(dir)
+	t.Setenv("APP_ENV", "development")
+
+	return func(ttl string) {
+		content := strings.Replace(string(original), "cache_ttl: 300", "cache_ttl: "+ttl, 1)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestConfigManager_ReloadAppliesSubscribers(t *testing.T) {
+	setCacheTTL := setupReloadTest(t)
+	cm, err := NewConfigManager()
+	require.NoError(t, err)
+
+	var applied []int
+	cm.Subscribe(NewSubscriber("recorder", nil, func(oldConfig, newConfig *Config) error {
+		applied = append(applied, oldConfig.Redis.CacheTTL, newConfig.Redis.CacheTTL)
+		return nil
+	}))
+
+	setCacheTTL("600")
+	require.NoError(t, cm.Reload())
+
+	assert.Equal(t, []int{300, 600}, applied)
+	assert.Equal(t, 600, cm.GetConfig().Redis.CacheTTL)
+}
+
+func TestConfigManager_ReloadRejectedBySubscriber(t *testing.T) {
+	setCacheTTL := setupReloadTest(t)
+	cm, err := NewConfigManager()
+	require.NoError(t, err)
+
+	applyCalled := false
+	cm.Subscribe(NewSubscriber("guard", func(newConfig *Config) error {
+		if newConfig.Redis.CacheTTL > 600 {
+			return errors.New("cache ttl too long")
+		}
+		return nil
+	}, func(oldConfig, newConfig *Config) error {
+		applyCalled = true
+		return nil
+	}))
+
+	setCacheTTL("900")
+	err = cm.Reload()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "guard")
+
+	// 校验失败时不应用任何订阅者，保持之前的配置
+	assert.False(t, applyCalled)
+	assert.Equal(t, 300, cm.GetConfig().Redis.CacheTTL)
+}
+
+func TestConfigManager_ReloadRollsBackOnApplyFailure(t *testing.T) {
+	setCacheTTL := setupReloadTest(t)
+	cm, err := NewConfigManager()
+	require.NoError(t, err)
+
+	current := 0
+	cm.Subscribe(NewSubscriber("first", nil, func(oldConfig, newConfig *Config) error {
+		current = newConfig.Redis.CacheTTL
+		return nil
+	}))
+	cm.Subscribe(NewSubscriber("second", nil, func(oldConfig, newConfig *Config) error {
+		return errors.New("apply failed")
+	}))
+
+	setCacheTTL("600")
+	require.Error(t, cm.Reload())
+
+	// 已应用的订阅者回滚到旧配置
+	assert.Equal(t, 300, current)
+	assert.Equal(t, 300, cm.GetConfig().Redis.CacheTTL)
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 支持的远程配置后端
+const (
+	RemoteProviderEtcd   = "etcd"
+	RemoteProviderConsul = "consul"
+)
+
+// remoteWatchMaxBackoff 监听失败后重试的最长间隔
+const remoteWatchMaxBackoff = time.Minute
+
+// remoteProvider 远程配置后端，版本号单调递增，用于判断配置文档是否变更
+type remoteProvider interface {
+	// Get 读取配置文档，键不存在时返回 nil 内容
+	Get(ctx context.Context) ([]byte, uint64, error)
+	// Watch 阻塞直到配置文档的版本不同于 since 或 ctx 结束，返回新的内容和版本号
+	Watch(ctx context.Context, since uint64) ([]byte, uint64, error)
+}
+
+// remoteDocument 最近一次从远程后端读取的配置文档
+//
+// LoadConfig 优先使用缓存的文档，重新加载配置时不会访问远程后端；
+// 文档由监听协程在远程配置变更时更新
+type remoteDocument struct {
+	source  string
+	content []byte
+	version uint64
+}
+
+var (
+	remoteMu    sync.Mutex
+	remoteCache *remoteDocument
+)
+
+// remoteSource 返回远程配置源的标识，如 etcd://go-server/config/production
+func remoteSource(cfg RemoteConfig) string {
+	return cfg.Provider + "://" + strings.TrimLeft(cfg.Key, "/")
+}
+
+// cachedRemoteDocument 返回指定配置源缓存的文档
+func cachedRemoteDocument(source string) (*remoteDocument, bool) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	if remoteCache == nil || remoteCache.source != source {
+		return nil, false
+	}
+	return remoteCache, true
+}
+
+// storeRemoteDocument 缓存远程配置文档
+func storeRemoteDocument(source string, content []byte, version uint64) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	remoteCache = &remoteDocument{source: source, content: content, version: version}
+}
+
+// remoteConfigFromViper 读取远程配置源的设置
+// 逐项读取而不是 UnmarshalKey，以便环境变量可以覆盖单个嵌套配置项
+func remoteConfigFromViper() RemoteConfig {
+	return RemoteConfig{
+		Enabled:   viper.GetBool("remote.enabled"),
+		Provider:  viper.GetString("remote.provider"),
+		Endpoints: viper.GetStringSlice("remote.endpoints"),
+		Key:       viper.GetString("remote.key"),
+		Token:     viper.GetString("remote.token"),
+		Username:  viper.GetString("remote.username"),
+		Password:  viper.GetString("remote.password"),
+		Timeout:   viper.GetInt("remote.timeout"),
+		Optional:  viper.GetBool("remote.optional"),
+	}
+}
+
+// newRemoteProvider 根据配置创建远程配置后端
+func newRemoteProvider(cfg RemoteConfig) (remoteProvider, error) {
+	if len(cfg.Endpoints) == 0 || cfg.Key == "" {
+		return nil, errors.New("远程配置需要设置 endpoints 和 key")
+	}
+
+	endpoints := make([]string, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
+		endpoints[i] = strings.TrimRight(endpoint, "/")
+	}
+	client := &http.Client{}
+
+	switch cfg.Provider {
+	case RemoteProviderEtcd:
+		return &etcdProvider{
+			endpoints: endpoints,
+			key:       strings.TrimLeft(cfg.Key, "/"),
+			username:  cfg.Username,
+			password:  cfg.Password,
+			client:    client,
+		}, nil
+	case RemoteProviderConsul:
+		token := cfg.Token
+		if token == "" {
+			token = os.Getenv("CONSUL_HTTP_TOKEN")
+		}
+		return &consulProvider{
+			endpoints: endpoints,
+			key:       strings.TrimLeft(cfg.Key, "/"),
+			token:     token,
+			client:    client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的远程配置后端: %s", cfg.Provider)
+	}
+}
+
+// remoteTimeout 返回单次读取的超时时间
+func remoteTimeout(cfg RemoteConfig) time.Duration {
+	if cfg.Timeout <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(cfg.Timeout) * time.Second
+}
+
+// mergeRemoteConfig 将远程配置文档合并到 viper，首次加载时从远程后端读取
+func mergeRemoteConfig() error {
+	cfg := remoteConfigFromViper()
+	if !cfg.Enabled {
+		return nil
+	}
+	source := remoteSource(cfg)
+
+	document, ok := cachedRemoteDocument(source)
+	if !ok {
+		provider, err := newRemoteProvider(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout(cfg))
+		defer cancel()
+		content, version, err := provider.Get(ctx)
+		if err != nil {
+			if cfg.Optional {
+				log.Printf("读取远程配置 %s 失败，使用本地配置: %v", source, err)
+				return nil
+			}
+			return fmt.Errorf("读取远程配置 %s 失败: %w", source, err)
+		}
+		storeRemoteDocument(source, content, version)
+		document, _ = cachedRemoteDocument(source)
+	}
+
+	if len(document.content) == 0 {
+		return nil
+	}
+	expanded, err := ExpandEnv(string(document.content))
+	if err != nil {
+		return fmt.Errorf("远程配置 %s: %w", source, err)
+	}
+	if err := viper.MergeConfig(bytes.NewReader([]byte(expanded))); err != nil {
+		return fmt.Errorf("解析远程配置 %s 失败: %w", source, err)
+	}
+
+	layersMu.Lock()
+	loadedFiles = append(loadedFiles, source)
+	layersMu.Unlock()
+	return nil
+}
+
+// watchRemoteLoop 监听远程配置文档，变更后更新缓存并重新加载配置，直到停止监控
+func (cm *ConfigManager) watchRemoteLoop(cfg RemoteConfig) {
+	provider, err := newRemoteProvider(cfg)
+	if err != nil {
+		log.Printf("创建远程配置后端失败，不再监听远程配置: %v", err)
+		return
+	}
+	source := remoteSource(cfg)
+
+	backoff := time.Second
+	retry := func(err error) bool {
+		log.Printf("监听远程配置 %s 失败，%s 后重试: %v", source, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-cm.ctx.Done():
+			return false
+		}
+		backoff = min(backoff*2, remoteWatchMaxBackoff)
+		return true
+	}
+
+	for {
+		var (
+			content []byte
+			version uint64
+			err     error
+		)
+
+		document, ok := cachedRemoteDocument(source)
+		if ok {
+			content, version, err = provider.Watch(cm.ctx, document.version)
+		} else {
+			// 启动时远程后端不可用（optional），先完整读取一次
+			ctx, cancel := context.WithTimeout(cm.ctx, remoteTimeout(cfg))
+			content, version, err = provider.Get(ctx)
+			cancel()
+		}
+		if cm.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !retry(err) {
+				return
+			}
+			continue
+		}
+		backoff = time.Second
+
+		if ok && version == document.version {
+			continue
+		}
+		storeRemoteDocument(source, content, version)
+
+		log.Printf("远程配置 %s 已变更（版本 %d），重新加载配置", source, version)
+		if err := cm.Reload(); err != nil {
+			log.Printf("应用远程配置失败，保持之前的有效配置: %v", err)
+		}
+	}
+}
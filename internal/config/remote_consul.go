@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// consulWaitTime 阻塞查询的最长等待时间，超时后返回当前版本并重新发起查询
+const consulWaitTime = "5m"
+
+// consulProvider 通过 HTTP API 读取 Consul KV 中的配置文档，使用阻塞查询监听变更
+type consulProvider struct {
+	endpoints []string
+	key       string
+	token     string
+	client    *http.Client
+}
+
+// Get 读取配置文档，版本号为 X-Consul-Index
+func (p *consulProvider) Get(ctx context.Context) ([]byte, uint64, error) {
+	return p.query(ctx, url.Values{"raw": {""}})
+}
+
+// Watch 发起阻塞查询，配置文档变更或等待超时后返回
+// 索引回退（如 Consul 集群重建）时版本号不同于 since，同样按变更处理
+func (p *consulProvider) Watch(ctx context.Context, since uint64) ([]byte, uint64, error) {
+	return p.query(ctx, url.Values{
+		"raw":   {""},
+		"index": {strconv.FormatUint(since, 10)},
+		"wait":  {consulWaitTime},
+	})
+}
+
+// query 依次尝试各个服务地址读取配置文档
+func (p *consulProvider) query(ctx context.Context, params url.Values) ([]byte, uint64, error) {
+	var lastErr error
+	for _, endpoint := range p.endpoints {
+		content, index, err := p.queryEndpoint(ctx, endpoint, params)
+		if err == nil {
+			return content, index, nil
+		}
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, 0, lastErr
+}
+
+// queryEndpoint 向单个服务地址读取配置文档，键不存在时返回 nil 内容
+func (p *consulProvider) queryEndpoint(ctx context.Context, endpoint string, params url.Values) ([]byte, uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/v1/kv/"+p.key+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Consul-Token", p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return nil, 0, fmt.Errorf("consul 返回状态码 %d: %s", resp.StatusCode, string(body))
+	}
+
+	index, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul 响应缺少有效的 X-Consul-Index: %q", resp.Header.Get("X-Consul-Index"))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, index, nil
+	}
+	return body, index, nil
+}
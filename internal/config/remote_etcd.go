@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etcdProvider 通过 etcd v3 的 JSON 网关（/v3/kv/range、/v3/watch）读取和监听配置文档
+// 版本号为 etcd 的 revision
+type etcdProvider struct {
+	endpoints []string
+	key       string
+	username  string
+	password  string
+	client    *http.Client
+}
+
+// etcdInt JSON 网关把 int64 编码为字符串
+type etcdInt int64
+
+func (i *etcdInt) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = etcdInt(value)
+	return nil
+}
+
+// etcdHeader 响应头
+type etcdHeader struct {
+	Revision etcdInt `json:"revision"`
+}
+
+// etcdKV 键值对，键和值为 base64 编码
+type etcdKV struct {
+	Value       string  `json:"value"`
+	ModRevision etcdInt `json:"mod_revision"`
+}
+
+// etcdWatchResponse 监听流中的一条消息
+type etcdWatchResponse struct {
+	Result *struct {
+		Header          etcdHeader `json:"header"`
+		Canceled        bool       `json:"canceled"`
+		CompactRevision etcdInt    `json:"compact_revision"`
+		CancelReason    string     `json:"cancel_reason"`
+		Events          []struct {
+			Type string `json:"type"` // PUT 事件省略该字段
+			Kv   etcdKV `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Get 读取配置文档，版本号为读取时集群的 revision
+func (p *etcdProvider) Get(ctx context.Context) ([]byte, uint64, error) {
+	var result struct {
+		Header etcdHeader `json:"header"`
+		Kvs    []etcdKV   `json:"kvs"`
+	}
+	err := p.withEndpoints(ctx, func(endpoint, token string) error {
+		resp, err := p.post(ctx, endpoint, "/v3/kv/range", token, map[string]string{"key": p.encodedKey()})
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(result.Kvs) == 0 {
+		return nil, uint64(result.Header.Revision), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(result.Kvs[0].Value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("解码 etcd 配置文档失败: %w", err)
+	}
+	return content, uint64(result.Header.Revision), nil
+}
+
+// Watch 监听 since 之后的变更，收到事件后返回最新的配置文档
+func (p *etcdProvider) Watch(ctx context.Context, since uint64) ([]byte, uint64, error) {
+	var (
+		content   []byte
+		version   uint64
+		compacted bool
+	)
+	err := p.withEndpoints(ctx, func(endpoint, token string) error {
+		request := map[string]interface{}{
+			"create_request": map[string]string{
+				"key":            p.encodedKey(),
+				"start_revision": strconv.FormatUint(since+1, 10),
+			},
+		}
+		resp, err := p.post(ctx, endpoint, "/v3/watch", token, request)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var message etcdWatchResponse
+			if err := decoder.Decode(&message); err != nil {
+				return fmt.Errorf("读取 etcd 监听流失败: %w", err)
+			}
+			if message.Error != nil {
+				return fmt.Errorf("etcd 监听失败: %s", message.Error.Message)
+			}
+			if message.Result == nil {
+				continue
+			}
+			if message.Result.Canceled {
+				// 请求的 revision 已被压缩，改为重新读取当前文档
+				if message.Result.CompactRevision > 0 {
+					compacted = true
+					return nil
+				}
+				return fmt.Errorf("etcd 取消了监听: %s", message.Result.CancelReason)
+			}
+			if len(message.Result.Events) == 0 {
+				continue
+			}
+
+			last := message.Result.Events[len(message.Result.Events)-1]
+			version = uint64(message.Result.Header.Revision)
+			if last.Type == "DELETE" {
+				content = nil
+				return nil
+			}
+			content, err = base64.StdEncoding.DecodeString(last.Kv.Value)
+			if err != nil {
+				return fmt.Errorf("解码 etcd 配置文档失败: %w", err)
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if compacted {
+		return p.Get(ctx)
+	}
+	return content, version, nil
+}
+
+// encodedKey 返回 base64 编码的键
+func (p *etcdProvider) encodedKey() string {
+	return base64.StdEncoding.EncodeToString([]byte(p.key))
+}
+
+// withEndpoints 依次尝试各个服务地址，启用认证时先获取令牌
+func (p *etcdProvider) withEndpoints(ctx context.Context, fn func(endpoint, token string) error) error {
+	var lastErr error
+	for _, endpoint := range p.endpoints {
+		token, err := p.authenticate(ctx, endpoint)
+		if err == nil {
+			err = fn(endpoint, token)
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// authenticate 使用用户名和密码获取令牌，未配置用户名时返回空令牌
+func (p *etcdProvider) authenticate(ctx context.Context, endpoint string) (string, error) {
+	if p.username == "" {
+		return "", nil
+	}
+
+	resp, err := p.post(ctx, endpoint, "/v3/auth/authenticate", "", map[string]string{
+		"name":     p.username,
+		"password": p.password,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析 etcd 认证响应失败: %w", err)
+	}
+	return result.Token, nil
+}
+
+// post 发送 JSON 请求，状态码不是 200 时返回错误
+func (p *etcdProvider) post(ctx context.Context, endpoint, path, token string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("etcd %s 返回状态码 %d: %s", path, resp.StatusCode, string(message))
+	}
+	return resp, nil
+}
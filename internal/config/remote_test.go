@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul 模拟 Consul KV 的阻塞查询
+type fakeConsul struct {
+	mu      sync.Mutex
+	value   []byte
+	index   uint64
+	changed chan struct{}
+}
+
+func newFakeConsul(value string) *fakeConsul {
+	return &fakeConsul{value: []byte(value), index: 10, changed: make(chan struct{})}
+}
+
+func (f *fakeConsul) set(value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value = []byte(value)
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/kv/go-server/config" || r.Header.Get("X-Consul-Token") != "acl-token" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	changed := f.changed
+	since, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	blocking := since != 0 && since == f.index
+	f.mu.Unlock()
+
+	if blocking {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if f.value == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write(f.value)
+}
+
+func TestConsulProvider(t *testing.T) {
+	consul := newFakeConsul("redis:\n  db: 3\n")
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	provider, err := newRemoteProvider(RemoteConfig{
+		Provider:  RemoteProviderConsul,
+		Endpoints: []string{"http://127.0.0.1:1", server.URL + "/"},
+		Key:       "/go-server/config",
+		Token:     "acl-token",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	content, version, err := provider.Get(ctx)
+	require.NoError(t, err, "第一个地址不可用时尝试下一个")
+	assert.Equal(t, "redis:\n  db: 3\n", string(content))
+	assert.Equal(t, uint64(10), version)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		consul.set("redis:\n  db: 4\n")
+	}()
+	content, version, err = provider.Watch(ctx, version)
+	require.NoError(t, err)
+	assert.Equal(t, "redis:\n  db: 4\n", string(content))
+	assert.Equal(t, uint64(11), version)
+
+	consul.mu.Lock()
+	consul.value = nil
+	consul.mu.Unlock()
+	content, _, err = provider.Get(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, content, "键不存在时返回空文档")
+}
+
+func TestEtcdProvider(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("go-server/config"))
+	encode := func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			fmt.Fprint(w, `{"token":"etcd-token"}`)
+		case "/v3/kv/range":
+			assert.Equal(t, "etcd-token", r.Header.Get("Authorization"))
+			assert.JSONEq(t, `"`+key+`"`, string(request["key"]))
+			fmt.Fprintf(w, `{"header":{"revision":"41"},"kvs":[{"value":%q,"mod_revision":"40"}]}`, encode("redis:\n  db: 5\n"))
+		case "/v3/watch":
+			assert.JSONEq(t, `{"key":"`+key+`","start_revision":"42"}`, string(request["create_request"]))
+			fmt.Fprint(w, `{"result":{"header":{"revision":"41"},"created":true}}`+"\n")
+			w.(http.Flusher).Flush()
+			fmt.Fprintf(w, `{"result":{"header":{"revision":"43"},"events":[{"kv":{"value":%q,"mod_revision":"43"}}]}}`+"\n", encode("redis:\n  db: 6\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := newRemoteProvider(RemoteConfig{
+		Provider:  RemoteProviderEtcd,
+		Endpoints: []string{server.URL},
+		Key:       "go-server/config",
+		Username:  "app",
+		Password:  "secret",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	content, version, err := provider.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "redis:\n  db: 5\n", string(content))
+	assert.Equal(t, uint64(41), version)
+
+	content, version, err = provider.Watch(ctx, version)
+	require.NoError(t, err)
+	assert.Equal(t, "redis:\n  db: 6\n", string(content))
+	assert.Equal(t, uint64(43), version)
+}
+
+func TestConfigManager_RemoteConfig(t *testing.T) {
+	consul := newFakeConsul("redis:\n  db: 3\nrate_limit:\n  requests: ${REMOTE_TEST_REQUESTS:-250}\n")
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	setupReloadTest(t)
+	t.Setenv("APP_REMOTE_ENABLED", "true")
+	t.Setenv("APP_REMOTE_PROVIDER", RemoteProviderConsul)
+	t.Setenv("APP_REMOTE_ENDPOINTS", server.URL)
+	t.Setenv("APP_REMOTE_KEY", "go-server/config")
+	t.Setenv("APP_REMOTE_TOKEN", "acl-token")
+	t.Setenv("APP_REDIS_PASSWORD", "from-env")
+	t.Cleanup(func() { storeRemoteDocument("", nil, 0) })
+
+	cm, err := NewConfigManager()
+	require.NoError(t, err)
+	cfg := cm.GetConfig()
+	assert.Equal(t, 3, cfg.Redis.DB, "远程配置覆盖配置文件")
+	assert.Equal(t, 250, cfg.RateLimit.Requests, "远程文档支持环境变量展开")
+	assert.Equal(t, "from-env", cfg.Redis.Password, "环境变量优先于远程配置")
+	assert.Contains(t, LoadedFiles(), "consul://go-server/config")
+
+	reloaded := make(chan *Config, 1)
+	cm.Subscribe(NewSubscriber("recorder", nil, func(oldConfig, newConfig *Config) error {
+		reloaded <- newConfig
+		return nil
+	}))
+	require.NoError(t, cm.StartWatching())
+	defer cm.StopWatching()
+
+	consul.set("redis:\n  db: 8\n")
+	select {
+	case newConfig := <-reloaded:
+		assert.Equal(t, 8, newConfig.Redis.DB)
+	case <-time.After(5 * time.Second):
+		t.Fatal("远程配置变更后未重新加载")
+	}
+}
+
+func TestLoadConfig_RemoteOptional(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0o755))
+	t.Chdir(dir)
+	t.Setenv("APP_REMOTE_ENABLED", "true")
+	t.Setenv("APP_REMOTE_PROVIDER", RemoteProviderConsul)
+	t.Setenv("APP_REMOTE_ENDPOINTS", "http://127.0.0.1:1")
+	t.Setenv("APP_REMOTE_KEY", "go-server/config")
+	t.Setenv("APP_REMOTE_TIMEOUT", "1")
+
+	_, err := LoadConfig()
+	require.Error(t, err, "远程配置不可用时默认启动失败")
+
+	t.Setenv("APP_REMOTE_OPTIONAL", "true")
+	_, err = LoadConfig()
+	require.NoError(t, err)
+}
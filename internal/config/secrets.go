@@ -0,0 +1,291 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 密钥引用的后端前缀
+const (
+	SecretSchemeVault = "vault" // HashiCorp Vault KV 引擎，如 vault://secret/data/go-server#db_password
+	SecretSchemeAWS   = "awssm" // AWS Secrets Manager，如 awssm://go-server/production#jwt_secret
+)
+
+// SecretProvider 外部密钥后端
+type SecretProvider interface {
+	// Name 返回后端名称，用于日志
+	Name() string
+
+	// GetSecret 读取指定路径下的密钥，返回键值对
+	GetSecret(ctx context.Context, path string) (map[string]string, error)
+}
+
+// SecretRotation 密钥轮换事件，不包含密钥值
+type SecretRotation struct {
+	Field     string    // 配置项，如 jwt.secret_key
+	Reference string    // 密钥引用
+	RotatedAt time.Time // 检测到轮换的时间
+}
+
+// SecretRotationHandler 密钥轮换回调，在新配置成功应用后调用
+type SecretRotationHandler func(ctx context.Context, rotation SecretRotation)
+
+// secretRef 解析后的密钥引用
+type secretRef struct {
+	raw    string // 原始引用
+	scheme string // 后端前缀
+	path   string // 密钥路径
+	key    string // 密钥中的字段，为空时要求密钥只有一个字段
+}
+
+// secretField 支持引用外部密钥的配置项
+type secretField struct {
+	name  string
+	value *string
+}
+
+// secretFields 返回支持引用外部密钥的配置项
+func secretFields(cfg *Config) []secretField {
+	return []secretField{
+		{name: "database.password", value: &cfg.Database.Password},
+		{name: "jwt.secret_key", value: &cfg.JWT.SecretKey},
+		{name: "jwt.private_key", value: &cfg.JWT.PrivateKey},
+		{name: "redis.password", value: &cfg.Redis.Password},
+		{name: "error_reporting.dsn", value: &cfg.ErrorReporting.DSN},
+		{name: "storage.s3.access_key_id", value: &cfg.Storage.S3.AccessKeyID},
+		{name: "storage.s3.secret_access_key", value: &cfg.Storage.S3.SecretAccessKey},
+		{name: "storage.media.signing_key", value: &cfg.Storage.Media.SigningKey},
+		{name: "exports.signing_key", value: &cfg.Exports.SigningKey},
+		{name: "encryption.keys", value: &cfg.Encryption.Keys},
+		{name: "encryption.blind_index_key", value: &cfg.Encryption.BlindIndexKey},
+		{name: "backups.encryption_keys", value: &cfg.Backups.EncryptionKeys},
+		{name: "billing.stripe_webhook_secret", value: &cfg.Billing.StripeWebhookSecret},
+		{name: "maintenance.bypass_tokens", value: &cfg.Maintenance.BypassTokens},
+	}
+}
+
+// SecretFieldNames 返回支持引用外部密钥的配置项名称，展示配置时应对这些配置项脱敏
+func SecretFieldNames() []string {
+	fields := secretFields(&Config{})
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+	return names
+}
+
+// UnresolvedSecrets 返回值仍为密钥引用的配置项，通常是没有配置对应的密钥后端
+func UnresolvedSecrets(cfg *Config) []string {
+	var names []string
+	for _, field := range secretFields(cfg) {
+		if IsSecretReference(*field.value) {
+			names = append(names, field.name)
+		}
+	}
+	return names
+}
+
+// IsSecretReference 判断配置值是否为外部密钥引用
+func IsSecretReference(value string) bool {
+	_, ok := parseSecretRef(value)
+	return ok
+}
+
+// parseSecretRef 解析 scheme://path#key 形式的密钥引用
+func parseSecretRef(value string) (secretRef, bool) {
+	scheme, rest, found := strings.Cut(value, "://")
+	if !found || (scheme != SecretSchemeVault && scheme != SecretSchemeAWS) {
+		return secretRef{}, false
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	if path == "" {
+		return secretRef{}, false
+	}
+	return secretRef{raw: value, scheme: scheme, path: path, key: key}, true
+}
+
+// SecretResolver 将配置中的密钥引用解析为实际值
+type SecretResolver struct {
+	providers map[string]SecretProvider // 后端前缀 -> 后端
+	refs      map[string]secretRef      // 配置项 -> 最近一次解析的引用
+	timeout   time.Duration             // 单次读取超时时间
+}
+
+// NewSecretResolver 根据配置创建密钥解析器，仅注册已配置的后端
+func NewSecretResolver(cfg SecretsConfig) *SecretResolver {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	r := &SecretResolver{
+		providers: make(map[string]SecretProvider),
+		refs:      make(map[string]secretRef),
+		timeout:   timeout,
+	}
+
+	if provider := newVaultProvider(cfg.Vault); provider != nil {
+		r.RegisterProvider(SecretSchemeVault, provider)
+	}
+	if provider := newAWSSecretsProvider(cfg.AWS); provider != nil {
+		r.RegisterProvider(SecretSchemeAWS, provider)
+	}
+
+	return r
+}
+
+// RegisterProvider 注册密钥后端，同名前缀将被替换
+func (r *SecretResolver) RegisterProvider(scheme string, provider SecretProvider) {
+	r.providers[scheme] = provider
+}
+
+// HasReferences 最近一次解析的配置中是否包含密钥引用
+func (r *SecretResolver) HasReferences() bool {
+	return len(r.refs) > 0
+}
+
+// Resolve 将配置中的密钥引用替换为实际值，并记录引用以便后续刷新
+// 任一引用解析失败时返回错误，配置不应被使用
+func (r *SecretResolver) Resolve(ctx context.Context, cfg *Config) error {
+	refs := make(map[string]secretRef)
+	cache := make(map[string]map[string]string)
+
+	for _, field := range secretFields(cfg) {
+		ref, ok := parseSecretRef(*field.value)
+		if !ok {
+			continue
+		}
+
+		value, err := r.lookup(ctx, ref, cache)
+		if err != nil {
+			return fmt.Errorf("解析配置项 %s 的密钥失败: %w", field.name, err)
+		}
+		*field.value = value
+		refs[field.name] = ref
+	}
+
+	r.refs = refs
+	return nil
+}
+
+// Refresh 重新读取已记录的密钥引用，将发生变化的值写入 cfg 并返回轮换列表
+// cfg 应为当前生效配置的副本
+func (r *SecretResolver) Refresh(ctx context.Context, cfg *Config) ([]SecretRotation, error) {
+	var rotations []SecretRotation
+	cache := make(map[string]map[string]string)
+	now := time.Now()
+
+	for _, field := range secretFields(cfg) {
+		ref, ok := r.refs[field.name]
+		if !ok {
+			continue
+		}
+
+		value, err := r.lookup(ctx, ref, cache)
+		if err != nil {
+			return nil, fmt.Errorf("刷新配置项 %s 的密钥失败: %w", field.name, err)
+		}
+		if value != *field.value {
+			*field.value = value
+			rotations = append(rotations, SecretRotation{Field: field.name, Reference: ref.raw, RotatedAt: now})
+		}
+	}
+
+	return rotations, nil
+}
+
+// lookup 读取单个引用的值，同一路径在一次解析中只读取一次
+func (r *SecretResolver) lookup(ctx context.Context, ref secretRef, cache map[string]map[string]string) (string, error) {
+	provider, ok := r.providers[ref.scheme]
+	if !ok {
+		return "", fmt.Errorf("密钥后端 %s 未配置", ref.scheme)
+	}
+
+	cacheKey := ref.scheme + "://" + ref.path
+	data, ok := cache[cacheKey]
+	if !ok {
+		fetchCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		var err error
+		data, err = provider.GetSecret(fetchCtx, ref.path)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+		cache[cacheKey] = data
+	}
+
+	if ref.key == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("密钥 %s 包含 %d 个字段，引用中必须用 #key 指定字段", ref.path, len(data))
+		}
+		for _, value := range data {
+			return value, nil
+		}
+	}
+
+	value, ok := data[ref.key]
+	if !ok {
+		return "", fmt.Errorf("密钥 %s 中不存在字段 %s", ref.path, ref.key)
+	}
+	return value, nil
+}
+
+// OnSecretRotation 注册密钥轮换回调
+func (cm *ConfigManager) OnSecretRotation(handler SecretRotationHandler) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.rotations = append(cm.rotations, handler)
+}
+
+// RefreshSecrets 重新读取密钥引用，有变化时按热重载流程校验并应用新配置
+func (cm *ConfigManager) RefreshSecrets(ctx context.Context) error {
+	cm.reloadMu.Lock()
+	defer cm.reloadMu.Unlock()
+
+	newConfig := cm.GetConfig()
+	rotations, err := cm.secrets.Refresh(ctx, newConfig)
+	if err != nil {
+		return err
+	}
+	if len(rotations) == 0 {
+		return nil
+	}
+
+	if err := cm.apply(newConfig); err != nil {
+		return err
+	}
+
+	cm.mu.RLock()
+	handlers := append([]SecretRotationHandler(nil), cm.rotations...)
+	cm.mu.RUnlock()
+
+	for _, rotation := range rotations {
+		log.Printf("密钥已轮换: %s (%s)", rotation.Field, rotation.Reference)
+		for _, handler := range handlers {
+			handler(ctx, rotation)
+		}
+	}
+	return nil
+}
+
+// refreshSecretsLoop 按刷新间隔定期拉取密钥，直到停止监控
+func (cm *ConfigManager) refreshSecretsLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := cm.RefreshSecrets(cm.ctx); err != nil {
+				log.Printf("刷新密钥失败，保持当前密钥: %v", err)
+			}
+		case <-cm.ctx.Done():
+			return
+		}
+	}
+}
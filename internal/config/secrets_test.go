@@ -0,0 +1,252 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault 模拟 Vault KV v2 接口
+type fakeVault struct {
+	mu       sync.Mutex
+	secrets  map[string]map[string]string
+	requests atomic.Int32
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	vault := &fakeVault{secrets: make(map[string]map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vault.requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+
+		vault.mu.Lock()
+		data, ok := vault.secrets[strings.TrimPrefix(r.URL.Path, "/v1/")]
+		vault.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{}})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     data,
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return vault, server
+}
+
+func (v *fakeVault) set(path string, data map[string]string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets[path] = data
+}
+
+func TestParseSecretRef(t *testing.T) {
+	ref, ok := parseSecretRef("vault://secret/data/go-server#db_password")
+	require.True(t, ok)
+	assert.Equal(t, SecretSchemeVault, ref.scheme)
+	assert.Equal(t, "secret/data/go-server", ref.path)
+	assert.Equal(t, "db_password", ref.key)
+
+	ref, ok = parseSecretRef("awssm://go-server/production")
+	require.True(t, ok)
+	assert.Equal(t, SecretSchemeAWS, ref.scheme)
+	assert.Equal(t, "", ref.key)
+
+	assert.False(t, IsSecretReference("plain-password"))
+	assert.False(t, IsSecretReference("https://example.com"))
+	assert.False(t, IsSecretReference("vault://"))
+}
+
+func TestSecretResolver_ResolveAndRefresh(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("secret/data/go-server", map[string]string{
+		"db_password": "db-secret",
+		"jwt_secret":  "jwt-secret-with-at-least-32-characters",
+	})
+
+	resolver := NewSecretResolver(SecretsConfig{
+		Timeout: 1,
+		Vault:   VaultSecretsConfig{Address: server.URL, Token: "test-token"},
+	})
+
+	cfg := &Config{}
+	cfg.Database.Password = "vault://secret/data/go-server#db_password"
+	cfg.JWT.SecretKey = "vault://secret/data/go-server#jwt_secret"
+	cfg.Redis.Password = "plain"
+
+	require.NoError(t, resolver.Resolve(context.Background(), cfg))
+	assert.Equal(t, "db-secret", cfg.Database.Password)
+	assert.Equal(t, "jwt-secret-with-at-least-32-characters", cfg.JWT.SecretKey)
+	assert.Equal(t, "plain", cfg.Redis.Password)
+	assert.True(t, resolver.HasReferences())
+	// 同一路径只读取一次
+	assert.Equal(t, int32(1), vault.requests.Load())
+
+	// 无变化时不产生轮换
+	rotations, err := resolver.Refresh(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Empty(t, rotations)
+
+	vault.set("secret/data/go-server", map[string]string{
+		"db_password": "db-secret",
+		"jwt_secret":  "rotated-jwt-secret-with-at-least-32-chars",
+	})
+	rotations, err = resolver.Refresh(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, rotations, 1)
+	assert.Equal(t, "jwt.secret_key", rotations[0].Field)
+	assert.Equal(t, "rotated-jwt-secret-with-at-least-32-chars", cfg.JWT.SecretKey)
+}
+
+func TestSecretResolver_Errors(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("secret/data/go-server", map[string]string{"a": "1", "b": "2"})
+
+	resolver := NewSecretResolver(SecretsConfig{
+		Vault: VaultSecretsConfig{Address: server.URL, Token: "test-token"},
+	})
+
+	tests := []struct {
+		name      string
+		reference string
+		contains  string
+	}{
+		{"后端未配置", "awssm://go-server/production#jwt", "awssm"},
+		{"字段不存在", "vault://secret/data/go-server#missing", "missing"},
+		{"多字段未指定", "vault://secret/data/go-server", "#key"},
+		{"密钥不存在", "vault://secret/data/unknown#a", "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.JWT.SecretKey = tt.reference
+			err := resolver.Resolve(context.Background(), cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "jwt.secret_key")
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestAWSSecretsProvider_GetSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240101/us-east-1/secretsmanager/aws4_request"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch body["SecretId"] {
+		case "go-server/production":
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"jwt_secret":"from-aws"}`})
+		case "go-server/redis":
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": "redis-password"})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+		}
+	}))
+	defer server.Close()
+
+	provider := newAWSSecretsProvider(AWSSecretsConfig{
+		Region:          "us-east-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	})
+	require.NotNil(t, provider)
+	provider.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	data, err := provider.GetSecret(context.Background(), "go-server/production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"jwt_secret": "from-aws"}, data)
+
+	data, err = provider.GetSecret(context.Background(), "go-server/redis")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"": "redis-password"}, data)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ResourceNotFoundException")
+}
+
+func TestSignAWSRequestV4(t *testing.T) {
+	// AWS SigV4 测试套件中的 get-vanilla 用例
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	signAWSRequestV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestConfigManager_RefreshSecrets(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("secret/data/go-server", map[string]string{"jwt_secret": "initial-jwt-secret-at-least-32-characters"})
+
+	original, err := os.ReadFile(filepath.Join("..", "..", "configs", "development.yaml"))
+	require.NoError(t, err)
+	content := strings.Replace(string(original),
+		`secret_key: "dev-secret-key-change-in-production"`,
+		`secret_key: "vault://secret/data/go-server#jwt_secret"`, 1)
+	content = strings.Replace(content, `address: ""  # 为空时使用 VAULT_ADDR`, `address: "`+server.URL+`"  #`, 1)
+	content = strings.Replace(content, `token: ""  # 为空时使用 VAULT_TOKEN`, `token: "test-token"  #`, 1)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configs", "development.yaml"), []byte(content), 0o644))
+	t.Chdir(dir)
+	t.Setenv("APP_ENV", "development")
+
+	cm, err := NewConfigManager()
+	require.NoError(t, err)
+	assert.Equal(t, "initial-jwt-secret-at-least-32-characters", cm.GetConfig().JWT.SecretKey)
+
+	var applied string
+	cm.Subscribe(NewSubscriber("jwt", nil, func(oldConfig, newConfig *Config) error {
+		applied = newConfig.JWT.SecretKey
+		return nil
+	}))
+	var rotated []string
+	cm.OnSecretRotation(func(ctx context.Context, rotation SecretRotation) {
+		rotated = append(rotated, rotation.Field)
+	})
+
+	vault.set("secret/data/go-server", map[string]string{"jwt_secret": "rotated-jwt-secret-at-least-32-characters"})
+	require.NoError(t, cm.RefreshSecrets(context.Background()))
+
+	assert.Equal(t, "rotated-jwt-secret-at-least-32-characters", cm.GetConfig().JWT.SecretKey)
+	assert.Equal(t, "rotated-jwt-secret-at-least-32-characters", applied)
+	assert.Equal(t, []string{"jwt.secret_key"}, rotated)
+
+	// 轮换后的密钥长度不足时被拒绝，保持当前密钥
+	vault.set("secret/data/go-server", map[string]string{"jwt_secret": "short"})
+	require.Error(t, cm.RefreshSecrets(context.Background()))
+	assert.Equal(t, "rotated-jwt-secret-at-least-32-characters", cm.GetConfig().JWT.SecretKey)
+}
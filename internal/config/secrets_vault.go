@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultProvider 通过 HTTP API 读取 HashiCorp Vault KV 引擎中的密钥
+// 同时支持 KV v1（secret/go-server）和 KV v2（secret/data/go-server）路径
+type vaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// newVaultProvider 创建 Vault 后端，未配置地址时返回 nil
+func newVaultProvider(cfg VaultSecretsConfig) *vaultProvider {
+	address := cfg.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return nil
+	}
+
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	return &vaultProvider{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{},
+	}
+}
+
+// Name 返回后端名称
+func (p *vaultProvider) Name() string {
+	return "vault"
+}
+
+// GetSecret 读取 Vault 中的密钥
+func (p *vaultProvider) GetSecret(ctx context.Context, path string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		json.Unmarshal(body, &errResp)
+		return nil, fmt.Errorf("读取密钥 %s 失败，状态码 %d: %s", path, resp.StatusCode, strings.Join(errResp.Errors, "; "))
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("解析密钥 %s 失败: %w", path, err)
+	}
+
+	// KV v2 的密钥位于 data.data，同时包含 data.metadata
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	return stringifySecret(data), nil
+}
+
+// stringifySecret 将 JSON 对象中的值转换为字符串
+func stringifySecret(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case nil:
+			values[key] = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			values[key] = string(encoded)
+		}
+	}
+	return values
+}
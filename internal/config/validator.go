@@ -2,11 +2,18 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+
+	"go-server/pkg/cache"
+	"go-server/pkg/fieldcrypt"
+	"go-server/pkg/saml"
 )
 
 // ValidationError 表示配置验证错误
@@ -61,12 +68,91 @@ func (v *Validator) Validate() *ValidationResult {
 	// 验证Redis配置
 	v.validateRedis(result)
 
+	// 验证缓存驱动配置
+	v.validateCache(result)
+
 	// 验证速率限制配置
 	v.validateRateLimit(result)
 
+	// 验证跨域配置
+	v.validateCORS(result)
+
+	// 验证请求体大小限制配置
+	v.validateBodyLimit(result)
+
+	// 验证幂等请求配置
+	v.validateIdempotency(result)
+
+	// 验证实体标签配置
+	v.validateETag(result)
+
+	// 验证响应缓存配置
+	v.validateResponseCache(result)
+
+	// 验证请求合并配置
+	v.validateCoalescing(result)
+
+	// 验证 OpenAPI 请求校验配置
+	v.validateOpenAPI(result)
+
+	// 验证功能开关配置
+	v.validateFeatureFlags(result)
+
+	// 验证多租户配置
+	v.validateTenancy(result)
+
+	// 验证地理位置查询配置
+	v.validateGeoIP(result)
+
 	// 验证日志配置
 	v.validateLogging(result)
 
+	// 验证错误上报配置
+	v.validateErrorReporting(result)
+
+	// 验证事件总线配置
+	v.validateEvents(result)
+
+	// 验证对象存储配置
+	v.validateStorage(result)
+
+	// 验证密钥后端配置
+	v.validateSecrets(result)
+	v.validateRemote(result)
+
+	// 验证指标快照配置
+	v.validateMetricsSnapshots(result)
+
+	// 验证通知配置
+	v.validateNotifications(result)
+
+	// 验证公告配置
+	v.validateAnnouncements(result)
+
+	// 验证数据导出配置
+	v.validateExports(result)
+	v.validateRetention(result)
+	v.validateBackups(result)
+	v.validateImports(result)
+
+	// 验证字段加密配置
+	v.validateEncryption(result)
+
+	// 验证 OpenID Connect 配置
+	v.validateOIDC(result)
+	v.validateSAML(result)
+	v.validatePasswordless(result)
+
+	// 验证用量配额配置
+	v.validateQuota(result)
+	v.validateBilling(result)
+
+	// 验证维护模式配置
+	v.validateMaintenance(result)
+
+	// 验证启动检查配置
+	v.validatePreflight(result)
+
 	// 验证应用模式
 	v.validateMode(result)
 
@@ -136,6 +222,197 @@ func (v *Validator) validateServer(result *ValidationResult) {
 		})
 		result.Valid = false
 	}
+
+	// 验证错误响应格式，为空时使用 envelope
+	if server.ErrorFormat != "" && server.ErrorFormat != "envelope" && server.ErrorFormat != "problem" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.error_format",
+			Message: "错误响应格式必须是 envelope 或 problem",
+			Value:   server.ErrorFormat,
+		})
+		result.Valid = false
+	}
+
+	// 验证优雅关闭超时时间
+	shutdownTimeouts := []struct {
+		field string
+		value int
+	}{
+		{"server.shutdown.pre_stop_delay", server.Shutdown.PreStopDelay},
+		{"server.shutdown.drain_timeout", server.Shutdown.DrainTimeout},
+		{"server.shutdown.workers_timeout", server.Shutdown.WorkersTimeout},
+		{"server.shutdown.resources_timeout", server.Shutdown.ResourcesTimeout},
+		{"server.shutdown.logs_timeout", server.Shutdown.LogsTimeout},
+	}
+	for _, timeout := range shutdownTimeouts {
+		if timeout.value < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   timeout.field,
+				Message: "优雅关闭超时时间不能为负数",
+				Value:   timeout.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	v.validateACME(result)
+	v.validateMTLS(result)
+}
+
+// validateACME 验证自动 TLS 证书配置
+func (v *Validator) validateACME(result *ValidationResult) {
+	acme := v.config.Server.ACME
+	if !acme.Enabled {
+		return
+	}
+
+	if len(acme.Domains) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.acme.domains",
+			Message: "启用自动证书时必须至少配置一个域名",
+			Value:   acme.Domains,
+		})
+		result.Valid = false
+	}
+	for _, domain := range acme.Domains {
+		if domain == "" || strings.Contains(domain, "*") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "server.acme.domains",
+				Message: "域名不能为空，且不支持通配符",
+				Value:   domain,
+			})
+			result.Valid = false
+		}
+	}
+
+	switch acme.Challenge {
+	case "http-01":
+		if port, err := strconv.Atoi(acme.HTTPPort); err != nil || port < 1 || port > 65535 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "server.acme.http_port",
+				Message: "http-01 验证端口必须在1到65535之间",
+				Value:   acme.HTTPPort,
+			})
+			result.Valid = false
+		} else if acme.HTTPPort == v.config.Server.Port {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "server.acme.http_port",
+				Message: "http-01 验证端口不能与服务器端口相同",
+				Value:   acme.HTTPPort,
+			})
+			result.Valid = false
+		}
+	case "tls-alpn-01":
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.acme.challenge",
+			Message: "验证方式必须是以下之一: http-01, tls-alpn-01",
+			Value:   acme.Challenge,
+		})
+		result.Valid = false
+	}
+
+	if acme.Cache != "storage" && acme.Cache != "redis" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.acme.cache",
+			Message: "证书缓存必须是以下之一: storage, redis",
+			Value:   acme.Cache,
+		})
+		result.Valid = false
+	}
+	if acme.CachePrefix == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.acme.cache_prefix",
+			Message: "证书缓存前缀是必需的",
+			Value:   acme.CachePrefix,
+		})
+		result.Valid = false
+	}
+	if acme.RenewBefore < 1 || acme.RenewBefore > 60 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.acme.renew_before",
+			Message: "续期提前天数必须在1到60之间",
+			Value:   acme.RenewBefore,
+		})
+		result.Valid = false
+	}
+}
+
+// validateMTLS 验证双向 TLS 配置
+func (v *Validator) validateMTLS(result *ValidationResult) {
+	mtls := v.config.Server.MTLS
+	if !mtls.Enabled {
+		return
+	}
+
+	if port, err := strconv.Atoi(mtls.Port); err != nil || port < 1 || port > 65535 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.mtls.port",
+			Message: "mTLS 端口必须在1到65535之间",
+			Value:   mtls.Port,
+		})
+		result.Valid = false
+	} else if mtls.Port == v.config.Server.Port {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "server.mtls.port",
+			Message: "mTLS 端口不能与服务器端口相同",
+			Value:   mtls.Port,
+		})
+		result.Valid = false
+	}
+
+	for _, file := range []struct {
+		field string
+		value string
+	}{
+		{"server.mtls.cert_file", mtls.CertFile},
+		{"server.mtls.key_file", mtls.KeyFile},
+		{"server.mtls.client_ca_file", mtls.ClientCAFile},
+	} {
+		if file.value == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   file.field,
+				Message: "启用 mTLS 时必须设置证书文件",
+				Value:   file.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	names := make(map[string]bool)
+	identities := make(map[string]string)
+	for i, account := range mtls.ServiceAccounts {
+		field := fmt.Sprintf("server.mtls.service_accounts[%d]", i)
+		if account.Name == "" || names[account.Name] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".name",
+				Message: "服务账户名称不能为空且不能重复",
+				Value:   account.Name,
+			})
+			result.Valid = false
+		}
+		names[account.Name] = true
+
+		if len(account.Identities) == 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".identities",
+				Message: "服务账户必须至少配置一个证书身份",
+				Value:   account.Identities,
+			})
+			result.Valid = false
+		}
+		for _, identity := range account.Identities {
+			if owner, exists := identities[identity]; exists {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   field + ".identities",
+					Message: "证书身份重复配置，已属于服务账户 " + owner,
+					Value:   identity,
+				})
+				result.Valid = false
+			}
+			identities[identity] = account.Name
+		}
+	}
 }
 
 // validateDatabase 验证数据库配置
@@ -209,6 +486,102 @@ func (v *Validator) validateDatabase(result *ValidationResult) {
 		}
 	}
 
+	// 验证慢查询阈值（0表示使用默认值）
+	if db.SlowQueryThreshold < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "database.slow_query_threshold",
+			Message: "数据库慢查询阈值不能为负数",
+			Value:   db.SlowQueryThreshold,
+		})
+		result.Valid = false
+	}
+
+	// 验证迁移锁超时（0表示使用默认值）
+	if db.MigrationLockTimeout < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "database.migration_lock_timeout",
+			Message: "迁移锁超时时间不能为负数",
+			Value:   db.MigrationLockTimeout,
+		})
+		result.Valid = false
+	}
+
+	// 验证请求查询计数（启用时阈值必须为正数）
+	if db.RequestQueries.Enabled {
+		if db.RequestQueries.MaxQueries <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.request_queries.max_queries",
+				Message: "单个请求的查询数阈值必须大于0",
+				Value:   db.RequestQueries.MaxQueries,
+			})
+			result.Valid = false
+		}
+		if db.RequestQueries.RepeatThreshold < 2 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.request_queries.repeat_threshold",
+				Message: "重复语句阈值必须至少为2",
+				Value:   db.RequestQueries.RepeatThreshold,
+			})
+			result.Valid = false
+		}
+		if db.RequestQueries.TopOffenders <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.request_queries.top_offenders",
+				Message: "列出的最差路由数必须大于0",
+				Value:   db.RequestQueries.TopOffenders,
+			})
+			result.Valid = false
+		}
+	}
+
+	// 验证最后登录时间批量写入（启用时间隔和批次大小必须为正数）
+	if db.LastLoginBatching.Enabled {
+		if db.LastLoginBatching.Interval <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.last_login_batching.interval",
+				Message: "最后登录时间写入间隔必须大于0",
+				Value:   db.LastLoginBatching.Interval,
+			})
+			result.Valid = false
+		}
+		if db.LastLoginBatching.BatchSize <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.last_login_batching.batch_size",
+				Message: "最后登录时间批次大小必须大于0",
+				Value:   db.LastLoginBatching.BatchSize,
+			})
+			result.Valid = false
+		}
+	}
+
+	// 验证暂时性错误重试（启用时尝试次数至少为1，退避时间必须为正数且上限不小于初始值）
+	if db.Retry.Enabled {
+		if db.Retry.MaxAttempts < 1 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.retry.max_attempts",
+				Message: "数据库重试最大尝试次数必须至少为1",
+				Value:   db.Retry.MaxAttempts,
+			})
+			result.Valid = false
+		}
+		if db.Retry.InitialBackoff <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.retry.initial_backoff",
+				Message: "数据库重试初始退避时间必须大于0",
+				Value:   db.Retry.InitialBackoff,
+			})
+			result.Valid = false
+		}
+		if db.Retry.MaxBackoff < db.Retry.InitialBackoff {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "database.retry.max_backoff",
+				Message: "数据库重试退避时间上限不能小于初始退避时间",
+				Value:   db.Retry.MaxBackoff,
+			})
+			result.Valid = false
+		}
+	}
+
 	// 在生产模式下，确保设置了密码
 	if v.config.Mode == "production" && db.Password == "" {
 		result.Errors = append(result.Errors, ValidationError{
@@ -233,21 +606,153 @@ func (v *Validator) validateAuth(result *ValidationResult) {
 		})
 		result.Valid = false
 	}
+
+	// 验证密码哈希算法
+	switch auth.PasswordAlgorithm {
+	case "bcrypt":
+	case "argon2id":
+		v.validateArgon2(auth.Argon2, result)
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_algorithm",
+			Message: "密码哈希算法必须是 bcrypt 或 argon2id",
+			Value:   auth.PasswordAlgorithm,
+		})
+		result.Valid = false
+	}
+
+	v.validatePasswordPolicy(auth.PasswordPolicy, result)
+}
+
+// validatePasswordPolicy 验证密码策略
+func (v *Validator) validatePasswordPolicy(policy PasswordPolicyConfig, result *ValidationResult) {
+	if policy.MinLength < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_policy.min_length",
+			Message: "密码最小长度不能为负数",
+			Value:   policy.MinLength,
+		})
+		result.Valid = false
+	}
+	if policy.MaxLength < 0 || (policy.MaxLength > 0 && policy.MaxLength < policy.MinLength) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_policy.max_length",
+			Message: "密码最大长度不能为负数，且不能小于最小长度",
+			Value:   policy.MaxLength,
+		})
+		result.Valid = false
+	}
+	if policy.MinEntropy < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_policy.min_entropy",
+			Message: "密码最小熵不能为负数",
+			Value:   policy.MinEntropy,
+		})
+		result.Valid = false
+	}
+	if policy.DenylistFile != "" {
+		if _, err := os.Stat(policy.DenylistFile); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "auth.password_policy.denylist_file",
+				Message: "禁用密码列表文件不存在或无法访问",
+				Value:   policy.DenylistFile,
+			})
+			result.Valid = false
+		}
+	}
+
+	breach := policy.BreachCheck
+	if !breach.Enabled {
+		return
+	}
+	if parsed, err := url.Parse(breach.APIURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_policy.breach_check.api_url",
+			Message: "泄露密码库API地址必须是有效的HTTP(S) URL",
+			Value:   breach.APIURL,
+		})
+		result.Valid = false
+	}
+	if breach.Timeout <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.password_policy.breach_check.timeout",
+			Message: "泄露密码查询超时时间必须大于0",
+			Value:   breach.Timeout,
+		})
+		result.Valid = false
+	}
+}
+
+// validateArgon2 验证 argon2id 参数
+func (v *Validator) validateArgon2(argon2 Argon2Config, result *ValidationResult) {
+	if argon2.Parallelism < 1 || argon2.Parallelism > 255 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.argon2.parallelism",
+			Message: "argon2id并行度必须在1到255之间",
+			Value:   argon2.Parallelism,
+		})
+		result.Valid = false
+	}
+	if argon2.Iterations < 1 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.argon2.iterations",
+			Message: "argon2id迭代次数必须大于0",
+			Value:   argon2.Iterations,
+		})
+		result.Valid = false
+	}
+	// argon2id 要求每个并行通道至少 8 KiB 内存，上限 4 GiB
+	if argon2.Memory < 8*argon2.Parallelism || argon2.Memory > 4*1024*1024 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "auth.argon2.memory",
+			Message: "argon2id内存（KiB）必须不小于并行度的8倍且不超过4194304",
+			Value:   argon2.Memory,
+		})
+		result.Valid = false
+	}
 }
 
 // validateJWT 验证JWT配置
 func (v *Validator) validateJWT(result *ValidationResult) {
 	jwt := v.config.JWT
 
-	// 验证JWT密钥
-	if jwt.SecretKey == "" {
+	// 验证签名算法
+	switch jwt.Algorithm {
+	case "HS256", "":
+	case "RS256", "ES256":
+		if jwt.PrivateKey == "" && jwt.PrivateKeyFile == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "jwt.private_key_file",
+				Message: "使用非对称签名算法时必须设置私钥（private_key 或 private_key_file）",
+				Value:   jwt.Algorithm,
+			})
+			result.Valid = false
+		}
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "jwt.algorithm",
+			Message: "JWT签名算法必须是以下之一: HS256, RS256, ES256",
+			Value:   jwt.Algorithm,
+		})
+		result.Valid = false
+	}
+
+	// 验证旧密钥
+	v.validateJWTPreviousKeys(result)
+
+	// 验证黑名单过滤器
+	v.validateJWTBlacklistFilter(result)
+
+	// 验证JWT密钥（非对称算法不使用共享密钥）
+	isHMAC := jwt.Algorithm != "RS256" && jwt.Algorithm != "ES256"
+	if isHMAC && jwt.SecretKey == "" {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   "jwt.secret_key",
 			Message: "JWT密钥是必需的",
 			Value:   "[空]",
 		})
 		result.Valid = false
-	} else {
+	} else if isHMAC {
 		// 出于安全考虑，JWT密钥应至少32个字符
 		if len(jwt.SecretKey) < 32 {
 			result.Errors = append(result.Errors, ValidationError{
@@ -285,63 +790,320 @@ func (v *Validator) validateJWT(result *ValidationResult) {
 		})
 		result.Valid = false
 	}
-}
-
-// validateRedis validates Redis configuration
-func (v *Validator) validateRedis(result *ValidationResult) {
-	redis := v.config.Redis
 
-	// 验证Redis主机地址
-	if redis.Host == "" {
+	if jwt.RefreshExpiresIn <= 0 {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "redis.host",
-			Message: "Redis主机地址是必需的",
-			Value:   redis.Host,
+			Field:   "jwt.refresh_expires_in",
+			Message: "刷新令牌过期时间必须大于0",
+			Value:   jwt.RefreshExpiresIn,
 		})
 		result.Valid = false
-	}
-
-	// 验证Redis端口
-	if redis.Port <= 0 || redis.Port > 65535 {
+	} else if jwt.RefreshExpiresIn < jwt.ExpiresIn {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "redis.port",
-			Message: "Redis端口必须在1到65535之间",
-			Value:   redis.Port,
+			Field:   "jwt.refresh_expires_in",
+			Message: "刷新令牌过期时间不能短于访问令牌过期时间",
+			Value:   jwt.RefreshExpiresIn,
 		})
 		result.Valid = false
-	}
-
-	// 验证Redis数据库编号
-	if redis.DB < 0 || redis.DB > 15 {
+	} else if jwt.RefreshExpiresIn > 8760 {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "redis.db",
-			Message: "Redis数据库编号必须在0到15之间",
-			Value:   redis.DB,
+			Field:   "jwt.refresh_expires_in",
+			Message: "出于安全考虑，刷新令牌过期时间不应超过8760小时（1年）",
+			Value:   jwt.RefreshExpiresIn,
 		})
 		result.Valid = false
 	}
 
-	// 验证Redis连接池大小
-	if redis.PoolSize <= 0 {
+	if jwt.RotationGracePeriod < 0 {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "redis.pool_size",
-			Message: "Redis连接池大小必须大于0",
-			Value:   redis.PoolSize,
+			Field:   "jwt.rotation_grace_period",
+			Message: "JWT密钥轮换宽限期不能为负数",
+			Value:   jwt.RotationGracePeriod,
 		})
 		result.Valid = false
 	}
 }
 
-// validateRateLimit 验证速率限制配置
-func (v *Validator) validateRateLimit(result *ValidationResult) {
-	rateLimit := v.config.RateLimit
+// validateJWTBlacklistFilter 验证令牌黑名单过滤器配置，未启用时不校验
+func (v *Validator) validateJWTBlacklistFilter(result *ValidationResult) {
+	filter := v.config.JWT.BlacklistFilter
+	if !filter.Enabled {
+		return
+	}
 
-	// 验证速率限制请求数
-	if rateLimit.Requests <= 0 {
+	if filter.ExpectedTokens <= 0 {
 		result.Errors = append(result.Errors, ValidationError{
-			Field:   "rate_limit.requests",
-			Message: "速率限制请求数必须大于0",
-			Value:   rateLimit.Requests,
+			Field:   "jwt.blacklist_filter.expected_tokens",
+			Message: "黑名单过滤器的预期令牌数必须大于0",
+			Value:   filter.ExpectedTokens,
+		})
+		result.Valid = false
+	}
+	if filter.FalsePositiveRate <= 0 || filter.FalsePositiveRate >= 1 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "jwt.blacklist_filter.false_positive_rate",
+			Message: "黑名单过滤器的误判率必须在0到1之间",
+			Value:   filter.FalsePositiveRate,
+		})
+		result.Valid = false
+	}
+	if filter.RefreshInterval <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "jwt.blacklist_filter.refresh_interval",
+			Message: "黑名单过滤器的同步间隔必须大于0秒",
+			Value:   filter.RefreshInterval,
+		})
+		result.Valid = false
+	}
+}
+
+// validateJWTPreviousKeys 验证轮换前的旧密钥
+func (v *Validator) validateJWTPreviousKeys(result *ValidationResult) {
+	jwt := v.config.JWT
+	seen := map[string]bool{jwt.KeyID: true}
+
+	for i, key := range jwt.PreviousKeys {
+		field := fmt.Sprintf("jwt.previous_keys[%d]", i)
+
+		if key.KeyID == "" || seen[key.KeyID] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".key_id",
+				Message: "旧密钥ID不能为空且不能与其他密钥重复",
+				Value:   key.KeyID,
+			})
+			result.Valid = false
+		}
+		seen[key.KeyID] = true
+
+		switch key.Algorithm {
+		case "HS256", "":
+			if key.SecretKey == "" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   field + ".secret_key",
+					Message: "HS256旧密钥必须设置secret_key",
+					Value:   "[空]",
+				})
+				result.Valid = false
+			}
+		case "RS256", "ES256":
+			if key.PublicKey == "" && key.PublicKeyFile == "" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   field + ".public_key_file",
+					Message: "非对称旧密钥必须设置公钥（public_key 或 public_key_file）",
+					Value:   key.Algorithm,
+				})
+				result.Valid = false
+			}
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".algorithm",
+				Message: "JWT签名算法必须是以下之一: HS256, RS256, ES256",
+				Value:   key.Algorithm,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateRedis validates Redis configuration
+func (v *Validator) validateRedis(result *ValidationResult) {
+	redis := v.config.Redis
+
+	// 验证Redis主机地址
+	if redis.Host == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.host",
+			Message: "Redis主机地址是必需的",
+			Value:   redis.Host,
+		})
+		result.Valid = false
+	}
+
+	// 验证Redis端口
+	if redis.Port <= 0 || redis.Port > 65535 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.port",
+			Message: "Redis端口必须在1到65535之间",
+			Value:   redis.Port,
+		})
+		result.Valid = false
+	}
+
+	// 验证Redis数据库编号
+	if redis.DB < 0 || redis.DB > 15 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.db",
+			Message: "Redis数据库编号必须在0到15之间",
+			Value:   redis.DB,
+		})
+		result.Valid = false
+	}
+
+	// 验证Redis连接池大小
+	if redis.PoolSize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.pool_size",
+			Message: "Redis连接池大小必须大于0",
+			Value:   redis.PoolSize,
+		})
+		result.Valid = false
+	}
+
+	// 验证缓存过期时间
+	if redis.CacheTTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.cache_ttl",
+			Message: "缓存过期时间必须大于0秒",
+			Value:   redis.CacheTTL,
+		})
+		result.Valid = false
+	}
+
+	// 验证“用户不存在”结果的缓存时间
+	if redis.NegativeCacheTTL < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "redis.negative_cache_ttl",
+			Message: "负缓存过期时间不能为负数",
+			Value:   redis.NegativeCacheTTL,
+		})
+		result.Valid = false
+	}
+}
+
+// cacheTTLMethods 可单独设置缓存过期时间的仓储方法
+var cacheTTLMethods = []string{"get_by_id", "get_by_email", "get_by_username", "get_all", "exists", "count"}
+
+// cacheCodecs 返回可用的缓存序列化方式，即 pkg/cache 中已注册的编码
+func cacheCodecs() []string {
+	return cache.Codecs()
+}
+
+// validateCache 验证缓存驱动配置，驱动为空时使用 redis
+func (v *Validator) validateCache(result *ValidationResult) {
+	cache := v.config.Cache
+
+	switch cache.Driver {
+	case "", "redis":
+	case "memcached":
+		if len(cache.Memcached.Servers) == 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.memcached.servers",
+				Message: "使用memcached驱动时必须至少设置一个服务器地址",
+				Value:   cache.Memcached.Servers,
+			})
+			result.Valid = false
+		}
+		if cache.Memcached.Timeout <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.memcached.timeout",
+				Message: "memcached超时时间必须大于0毫秒",
+				Value:   cache.Memcached.Timeout,
+			})
+			result.Valid = false
+		}
+		if cache.Memcached.MaxIdleConns < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.memcached.max_idle_conns",
+				Message: "memcached最大空闲连接数不能为负数",
+				Value:   cache.Memcached.MaxIdleConns,
+			})
+			result.Valid = false
+		}
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cache.driver",
+			Message: "缓存驱动必须是以下之一: redis, memcached",
+			Value:   cache.Driver,
+		})
+		result.Valid = false
+	}
+
+	if codecs := cacheCodecs(); cache.Codec != "" && !slices.Contains(codecs, cache.Codec) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cache.codec",
+			Message: "缓存序列化方式必须是以下之一: " + strings.Join(codecs, ", "),
+			Value:   cache.Codec,
+		})
+		result.Valid = false
+	}
+
+	// 验证按方法的缓存过期时间
+	for _, method := range slices.Sorted(maps.Keys(cache.TTLs)) {
+		ttl := cache.TTLs[method]
+		if !slices.Contains(cacheTTLMethods, method) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.ttls." + method,
+				Message: "未知的缓存方法，必须是以下之一: " + strings.Join(cacheTTLMethods, ", "),
+				Value:   method,
+			})
+			result.Valid = false
+		} else if ttl < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.ttls." + method,
+				Message: "缓存过期时间不能为负数",
+				Value:   ttl,
+			})
+			result.Valid = false
+		}
+	}
+
+	// 验证缓存预热配置
+	if cache.Warmup.TopUsers < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cache.warmup.top_users",
+			Message: "预热用户数量不能为负数",
+			Value:   cache.Warmup.TopUsers,
+		})
+		result.Valid = false
+	}
+	if cache.Warmup.Timeout < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cache.warmup.timeout",
+			Message: "预热超时时间不能为负数",
+			Value:   cache.Warmup.Timeout,
+		})
+		result.Valid = false
+	}
+
+	// 验证缓存健康监督配置
+	if cache.Supervisor.Enabled {
+		if cache.Supervisor.FailureThreshold <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.supervisor.failure_threshold",
+				Message: "连续失败阈值必须大于0",
+				Value:   cache.Supervisor.FailureThreshold,
+			})
+			result.Valid = false
+		}
+		if cache.Supervisor.ProbeInterval <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.supervisor.probe_interval",
+				Message: "探测间隔必须大于0",
+				Value:   cache.Supervisor.ProbeInterval,
+			})
+			result.Valid = false
+		}
+		if cache.Supervisor.ProbeTimeout <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cache.supervisor.probe_timeout",
+				Message: "探测超时时间必须大于0",
+				Value:   cache.Supervisor.ProbeTimeout,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateRateLimit 验证速率限制配置
+func (v *Validator) validateRateLimit(result *ValidationResult) {
+	rateLimit := v.config.RateLimit
+
+	// 验证速率限制请求数
+	if rateLimit.Requests <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.requests",
+			Message: "速率限制请求数必须大于0",
+			Value:   rateLimit.Requests,
 		})
 		result.Valid = false
 	}
@@ -376,6 +1138,113 @@ func (v *Validator) validateRateLimit(result *ValidationResult) {
 		})
 		result.Valid = false
 	}
+
+	// 验证速率限制模式
+	switch rateLimit.Mode {
+	case "", "enforce", "warn", "shadow":
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.mode",
+			Message: "速率限制模式必须为 enforce、warn 或 shadow",
+			Value:   rateLimit.Mode,
+		})
+		result.Valid = false
+	}
+	if rateLimit.Mode == "warn" && (rateLimit.WarnThreshold < 1 || rateLimit.WarnThreshold > 100) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.warn_threshold",
+			Message: "速率限制警告阈值必须在1到100之间",
+			Value:   rateLimit.WarnThreshold,
+		})
+		result.Valid = false
+	}
+
+	// 验证速率限制算法和令牌消耗
+	switch rateLimit.Algorithm {
+	case "", "sliding_window", "token_bucket":
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.algorithm",
+			Message: "速率限制算法必须为 sliding_window 或 token_bucket",
+			Value:   rateLimit.Algorithm,
+		})
+		result.Valid = false
+	}
+	if rateLimit.Burst < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.burst",
+			Message: "令牌桶突发容量不能为负数",
+			Value:   rateLimit.Burst,
+		})
+		result.Valid = false
+	}
+	for i, cost := range rateLimit.Costs {
+		field := fmt.Sprintf("rate_limit.costs[%d]", i)
+		if !strings.HasPrefix(cost.Route, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".route",
+				Message: "路由必须是以 / 开头的路由模板",
+				Value:   cost.Route,
+			})
+			result.Valid = false
+		}
+		if cost.Cost < 1 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".cost",
+				Message: "请求消耗的令牌数必须大于0",
+				Value:   cost.Cost,
+			})
+			result.Valid = false
+		}
+	}
+
+	v.validateAutoBan(result)
+}
+
+// validateAutoBan 验证自动封禁配置
+func (v *Validator) validateAutoBan(result *ValidationResult) {
+	autoBan := v.config.RateLimit.AutoBan
+	if !autoBan.Enabled {
+		return
+	}
+
+	positives := []struct {
+		field string
+		value int
+	}{
+		{"rate_limit.auto_ban.threshold", autoBan.Threshold},
+		{"rate_limit.auto_ban.window", autoBan.Window},
+		{"rate_limit.auto_ban.duration", autoBan.Duration},
+		{"rate_limit.auto_ban.check_interval", autoBan.CheckInterval},
+	}
+	for _, p := range positives {
+		if p.value <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   p.field,
+				Message: "自动封禁设置必须大于0",
+				Value:   p.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	if autoBan.MinViolationRate < 0 || autoBan.MinViolationRate > 100 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.auto_ban.min_violation_rate",
+			Message: "最低违规比例必须在0到100之间",
+			Value:   autoBan.MinViolationRate,
+		})
+		result.Valid = false
+	}
+
+	if autoBan.KeyPrefix == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "rate_limit.auto_ban.key_prefix",
+			Message: "自动封禁Redis键名前缀是必需的",
+			Value:   autoBan.KeyPrefix,
+		})
+		result.Valid = false
+	}
 }
 
 // validateLogging 验证日志配置
@@ -437,7 +1306,7 @@ func (v *Validator) validateLogging(result *ValidationResult) {
 	}
 
 	// 验证日志输出位置
-	validOutputs := []string{"stdout", "stderr", "file", "both", "console"}
+	validOutputs := []string{"stdout", "stderr", "file", "both", "console", "loki", "http"}
 	if logging.Output == "" {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   "logging.output",
@@ -472,6 +1341,102 @@ func (v *Validator) validateLogging(result *ValidationResult) {
 			// 这里不设为错误，只作为提示，因为用户可能想要保留配置
 		}
 	}
+
+	// 如果输出为远程服务，验证推送设置
+	if logging.Output == "loki" || logging.Output == "http" {
+		v.validateLogShippingSettings(logging, result)
+	}
+
+	// 验证请求和响应体记录设置
+	if logging.BodyCapture.Enabled && logging.BodyCapture.MaxBytes <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "logging.body_capture.max_bytes",
+			Message: "启用请求和响应体记录时，最大记录字节数必须大于0",
+			Value:   logging.BodyCapture.MaxBytes,
+		})
+		result.Valid = false
+	}
+	for _, field := range logging.BodyCapture.RedactFields {
+		if slices.Contains(strings.Split(field, "."), "") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "logging.body_capture.redact_fields",
+				Message: "脱敏字段路径格式无效，路径的每一级都不能为空",
+				Value:   field,
+			})
+			result.Valid = false
+		}
+	}
+
+	// 验证日志采样设置
+	if logging.Sampling.Enabled {
+		if logging.Sampling.Initial <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "logging.sampling.initial",
+				Message: "启用日志采样时，每秒完整记录的条数必须大于0",
+				Value:   logging.Sampling.Initial,
+			})
+			result.Valid = false
+		}
+		if logging.Sampling.Thereafter <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "logging.sampling.thereafter",
+				Message: "启用日志采样时，采样间隔必须大于0",
+				Value:   logging.Sampling.Thereafter,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateLogShippingSettings 验证远程日志推送设置
+func (v *Validator) validateLogShippingSettings(logging LoggingConfig, result *ValidationResult) {
+	shipping := logging.Shipping
+	if parsed, err := url.Parse(shipping.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "logging.shipping.url",
+			Message: "日志输出为 loki 或 http 时，推送地址必须是有效的 http(s) URL",
+			Value:   shipping.URL,
+		})
+		result.Valid = false
+	}
+
+	positives := []struct {
+		field string
+		value int
+	}{
+		{"logging.shipping.batch_size", shipping.BatchSize},
+		{"logging.shipping.flush_interval", shipping.FlushInterval},
+		{"logging.shipping.queue_size", shipping.QueueSize},
+		{"logging.shipping.timeout", shipping.Timeout},
+	}
+	for _, p := range positives {
+		if p.value <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   p.field,
+				Message: "日志推送设置必须大于0",
+				Value:   p.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	if logging.Output == "loki" && len(shipping.Labels) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "logging.shipping.labels",
+			Message: "日志输出为 loki 时至少需要一个流标签",
+			Value:   shipping.Labels,
+		})
+		result.Valid = false
+	}
+
+	if shipping.MaxRetries < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "logging.shipping.max_retries",
+			Message: "日志推送重试次数不能为负数",
+			Value:   shipping.MaxRetries,
+		})
+		result.Valid = false
+	}
 }
 
 // validateFileLoggingSettings 验证文件日志相关设置
@@ -663,6 +1628,1278 @@ func getDiskUsage(path string) (DiskUsage, error) {
 	return usage, nil
 }
 
+// validateErrorReporting 验证错误上报配置
+func (v *Validator) validateErrorReporting(result *ValidationResult) {
+	reporting := v.config.ErrorReporting
+	if !reporting.Enabled {
+		return
+	}
+
+	if parsed, err := url.Parse(reporting.DSN); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User == nil || parsed.Host == "" {
+		// 外部密钥引用在解析后才能得到实际的 DSN
+		if !IsSecretReference(reporting.DSN) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "error_reporting.dsn",
+				Message: "启用错误上报时必须设置有效的 DSN，格式为 https://<key>@<host>/<project_id>",
+				Value:   "[REDACTED]",
+			})
+			result.Valid = false
+		}
+	}
+
+	if reporting.SampleRate <= 0 || reporting.SampleRate > 1 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "error_reporting.sample_rate",
+			Message: "错误上报采样率必须大于0且不超过1",
+			Value:   reporting.SampleRate,
+		})
+		result.Valid = false
+	}
+
+	if reporting.Timeout <= 0 || reporting.QueueSize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "error_reporting.timeout",
+			Message: "错误上报超时时间和队列大小必须大于0",
+			Value:   fmt.Sprintf("timeout=%d, queue_size=%d", reporting.Timeout, reporting.QueueSize),
+		})
+		result.Valid = false
+	}
+}
+
+// validateEvents 验证事件总线配置
+func (v *Validator) validateEvents(result *ValidationResult) {
+	events := v.config.Events
+
+	// 未启用时不验证驱动配置
+	if !events.Enabled {
+		return
+	}
+
+	// 验证驱动类型
+	switch events.Driver {
+	case "nats":
+		if events.NATS.URL == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "events.nats.url",
+				Message: "使用NATS驱动时必须设置服务器地址",
+				Value:   events.NATS.URL,
+			})
+			result.Valid = false
+		}
+	case "kafka":
+		if len(events.Kafka.Brokers) == 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "events.kafka.brokers",
+				Message: "使用Kafka驱动时必须至少设置一个Broker地址",
+				Value:   events.Kafka.Brokers,
+			})
+			result.Valid = false
+		}
+	case "memory":
+		// 进程内驱动无需额外配置
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "events.driver",
+			Message: "事件驱动必须是以下之一: nats, kafka, memory",
+			Value:   events.Driver,
+		})
+		result.Valid = false
+	}
+
+	// 验证排空超时时间
+	if events.DrainTimeout < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "events.drain_timeout",
+			Message: "事件总线排空超时时间不能为负数",
+			Value:   events.DrainTimeout,
+		})
+		result.Valid = false
+	}
+}
+
+// validateCORS 验证跨域配置
+func (v *Validator) validateCORS(result *ValidationResult) {
+	cors := v.config.CORS
+	if !cors.Enabled {
+		return
+	}
+
+	if len(cors.AllowedOrigins) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cors.allowed_origins",
+			Message: "启用跨域时必须设置允许的来源",
+			Value:   cors.AllowedOrigins,
+		})
+		result.Valid = false
+	}
+
+	for _, origin := range cors.AllowedOrigins {
+		if origin == "*" {
+			if cors.AllowCredentials {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "cors.allow_credentials",
+					Message: "允许携带凭证时来源不能为 *，请列出具体的来源",
+					Value:   cors.AllowCredentials,
+				})
+				result.Valid = false
+			}
+			continue
+		}
+
+		scheme, host, found := strings.Cut(origin, "://")
+		if !found || (scheme != "http" && scheme != "https") || host == "" || strings.Contains(host, "/") ||
+			(strings.Contains(host, "*") && !strings.HasPrefix(host, "*.")) || strings.Count(host, "*") > 1 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "cors.allowed_origins",
+				Message: "来源格式必须为 scheme://host[:port]，通配符仅支持 https://*.example.com 形式",
+				Value:   origin,
+			})
+			result.Valid = false
+		}
+	}
+
+	if cors.MaxAge < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "cors.max_age",
+			Message: "预检请求缓存时间不能为负数",
+			Value:   cors.MaxAge,
+		})
+		result.Valid = false
+	}
+}
+
+// validateBodyLimit 验证请求体大小限制配置
+func (v *Validator) validateBodyLimit(result *ValidationResult) {
+	bodyLimit := v.config.BodyLimit
+	if !bodyLimit.Enabled {
+		return
+	}
+
+	if bodyLimit.MaxBytes <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "body_limit.max_bytes",
+			Message: "请求体上限必须大于0",
+			Value:   bodyLimit.MaxBytes,
+		})
+		result.Valid = false
+	}
+
+	if bodyLimit.MultipartMaxBytes < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "body_limit.multipart_max_bytes",
+			Message: "multipart 请求体上限不能为负数",
+			Value:   bodyLimit.MultipartMaxBytes,
+		})
+		result.Valid = false
+	}
+
+	if bodyLimit.MaxDecompressedBytes < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "body_limit.max_decompressed_bytes",
+			Message: "解压后的请求体上限不能为负数",
+			Value:   bodyLimit.MaxDecompressedBytes,
+		})
+		result.Valid = false
+	}
+
+	for i, route := range bodyLimit.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("body_limit.routes[%d].path_prefix", i),
+				Message: "路由前缀必须以 / 开头",
+				Value:   route.PathPrefix,
+			})
+			result.Valid = false
+		}
+		if route.MaxBytes < 0 || route.MultipartMaxBytes < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("body_limit.routes[%d]", i),
+				Message: "路由的请求体上限不能为负数",
+				Value:   route,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateIdempotency 验证幂等请求配置
+func (v *Validator) validateIdempotency(result *ValidationResult) {
+	idempotency := v.config.Idempotency
+	if !idempotency.Enabled {
+		return
+	}
+
+	if idempotency.TTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "idempotency.ttl",
+			Message: "幂等响应保存时间必须大于0",
+			Value:   idempotency.TTL,
+		})
+		result.Valid = false
+	}
+
+	if idempotency.LockTimeout <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "idempotency.lock_timeout",
+			Message: "幂等请求占用时间必须大于0",
+			Value:   idempotency.LockTimeout,
+		})
+		result.Valid = false
+	}
+
+	validMethods := []string{"POST", "PUT", "PATCH", "DELETE"}
+	for _, method := range idempotency.Methods {
+		if !slices.Contains(validMethods, strings.ToUpper(method)) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "idempotency.methods",
+				Message: fmt.Sprintf("请求方法必须是以下之一: %s", strings.Join(validMethods, ", ")),
+				Value:   method,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateETag 验证实体标签配置
+func (v *Validator) validateETag(result *ValidationResult) {
+	etag := v.config.ETag
+	if !etag.Enabled {
+		return
+	}
+
+	if etag.MaxBodySize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "etag.max_body_size",
+			Message: "计算ETag时缓冲的最大响应体必须大于0",
+			Value:   etag.MaxBodySize,
+		})
+		result.Valid = false
+	}
+
+	for _, path := range etag.ExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "etag.exclude_paths",
+				Message: "排除路径必须以 / 开头",
+				Value:   path,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateOpenAPI 验证 OpenAPI 请求校验配置
+func (v *Validator) validateOpenAPI(result *ValidationResult) {
+	openAPI := v.config.OpenAPI
+	if !openAPI.ValidateRequests {
+		return
+	}
+
+	for _, path := range openAPI.ExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "openapi.exclude_paths",
+				Message: "排除路径必须以 / 开头",
+				Value:   path,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateFeatureFlags 验证功能开关配置
+func (v *Validator) validateFeatureFlags(result *ValidationResult) {
+	featureFlags := v.config.FeatureFlags
+	if !featureFlags.Enabled {
+		return
+	}
+
+	if featureFlags.Backend != "config" && featureFlags.Backend != "redis" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "feature_flags.backend",
+			Message: "功能开关存储必须是 config 或 redis",
+			Value:   featureFlags.Backend,
+		})
+		result.Valid = false
+	}
+
+	if featureFlags.CacheTTL < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "feature_flags.cache_ttl",
+			Message: "开关缓存时间不能为负数",
+			Value:   featureFlags.CacheTTL,
+		})
+		result.Valid = false
+	}
+
+	if featureFlags.Backend == "redis" && featureFlags.KeyPrefix == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "feature_flags.key_prefix",
+			Message: "功能开关键前缀不能为空",
+			Value:   featureFlags.KeyPrefix,
+		})
+		result.Valid = false
+	}
+
+	seen := make(map[string]bool, len(featureFlags.Flags))
+	for i, flag := range featureFlags.Flags {
+		if flag.Key == "" || seen[flag.Key] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("feature_flags.flags[%d].key", i),
+				Message: "开关键不能为空且不能重复",
+				Value:   flag.Key,
+			})
+			result.Valid = false
+		}
+		seen[flag.Key] = true
+
+		if flag.Percentage < 0 || flag.Percentage > 100 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("feature_flags.flags[%d].percentage", i),
+				Message: "灰度比例必须在0到100之间",
+				Value:   flag.Percentage,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateTenancy 验证多租户配置
+func (v *Validator) validateTenancy(result *ValidationResult) {
+	tenancy := v.config.Tenancy
+	if !tenancy.Enabled {
+		return
+	}
+
+	if len(tenancy.Sources) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "tenancy.sources",
+			Message: "至少需要一个租户来源",
+			Value:   tenancy.Sources,
+		})
+		result.Valid = false
+	}
+
+	for _, source := range tenancy.Sources {
+		switch source {
+		case "header":
+			if tenancy.Header == "" {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "tenancy.header",
+					Message: "使用请求头来源时必须指定请求头名称",
+					Value:   tenancy.Header,
+				})
+				result.Valid = false
+			}
+		case "subdomain":
+			if strings.HasPrefix(tenancy.BaseDomain, ".") {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "tenancy.base_domain",
+					Message: "基础域名不能以 . 开头",
+					Value:   tenancy.BaseDomain,
+				})
+				result.Valid = false
+			}
+		case "jwt":
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "tenancy.sources",
+				Message: "租户来源必须是 header、subdomain 或 jwt",
+				Value:   source,
+			})
+			result.Valid = false
+		}
+	}
+
+	if tenancy.CacheTTL < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "tenancy.cache_ttl",
+			Message: "租户缓存时间不能为负数",
+			Value:   tenancy.CacheTTL,
+		})
+		result.Valid = false
+	}
+
+	for _, path := range tenancy.ExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "tenancy.exclude_paths",
+				Message: "排除路径必须以 / 开头",
+				Value:   path,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateGeoIP 验证地理位置查询配置
+func (v *Validator) validateGeoIP(result *ValidationResult) {
+	geoIP := v.config.GeoIP
+	if !geoIP.Enabled {
+		return
+	}
+
+	if geoIP.CountryDatabase == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "geoip.country_database",
+			Message: "启用地理位置查询时必须指定国家数据库路径",
+			Value:   geoIP.CountryDatabase,
+		})
+		result.Valid = false
+	}
+
+	if geoIP.RefreshInterval < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "geoip.refresh_interval",
+			Message: "数据库检查间隔不能为负数",
+			Value:   geoIP.RefreshInterval,
+		})
+		result.Valid = false
+	}
+}
+
+// validateResponseCache 验证响应缓存配置
+func (v *Validator) validateResponseCache(result *ValidationResult) {
+	responseCache := v.config.ResponseCache
+	if !responseCache.Enabled {
+		return
+	}
+
+	if responseCache.KeyPrefix == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "response_cache.key_prefix",
+			Message: "响应缓存键前缀不能为空",
+			Value:   responseCache.KeyPrefix,
+		})
+		result.Valid = false
+	}
+
+	if responseCache.StaleWhileRevalidate < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "response_cache.stale_while_revalidate",
+			Message: "旧响应可用时间不能为负数",
+			Value:   responseCache.StaleWhileRevalidate,
+		})
+		result.Valid = false
+	}
+
+	if responseCache.MaxBodySize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "response_cache.max_body_size",
+			Message: "可缓存的最大响应体必须大于0",
+			Value:   responseCache.MaxBodySize,
+		})
+		result.Valid = false
+	}
+
+	for i, route := range responseCache.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("response_cache.routes[%d].path", i),
+				Message: "路由必须以 / 开头",
+				Value:   route.Path,
+			})
+			result.Valid = false
+		}
+		if route.TTL <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("response_cache.routes[%d].ttl", i),
+				Message: "缓存时间必须大于0",
+				Value:   route.TTL,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateCoalescing 验证请求合并配置
+func (v *Validator) validateCoalescing(result *ValidationResult) {
+	coalescing := v.config.Coalescing
+	if !coalescing.Enabled {
+		return
+	}
+
+	if coalescing.MaxBodySize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "coalescing.max_body_size",
+			Message: "可共享的最大响应体必须大于0",
+			Value:   coalescing.MaxBodySize,
+		})
+		result.Valid = false
+	}
+}
+
+// validateStorage 验证对象存储配置
+func (v *Validator) validateStorage(result *ValidationResult) {
+	storage := v.config.Storage
+
+	// 验证驱动类型
+	switch storage.Driver {
+	case "local":
+		if storage.Local.BaseDir == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "storage.local.base_dir",
+				Message: "使用本地驱动时必须设置存储目录",
+				Value:   storage.Local.BaseDir,
+			})
+			result.Valid = false
+		}
+	case "s3":
+		if storage.S3.Endpoint == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "storage.s3.endpoint",
+				Message: "使用S3驱动时必须设置服务地址",
+				Value:   storage.S3.Endpoint,
+			})
+			result.Valid = false
+		}
+		if storage.S3.Bucket == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "storage.s3.bucket",
+				Message: "使用S3驱动时必须设置存储桶名称",
+				Value:   storage.S3.Bucket,
+			})
+			result.Valid = false
+		}
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "storage.driver",
+			Message: "存储驱动必须是以下之一: local, s3",
+			Value:   storage.Driver,
+		})
+		result.Valid = false
+	}
+
+	if storage.Media.SignedURLs && storage.Media.URLTTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "storage.media.url_ttl",
+			Message: "启用签名链接时有效期必须大于0",
+			Value:   storage.Media.URLTTL,
+		})
+		result.Valid = false
+	}
+
+	// 验证最大上传大小
+	if storage.MaxUploadSize <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "storage.max_upload_size",
+			Message: "最大上传大小必须大于0",
+			Value:   storage.MaxUploadSize,
+		})
+		result.Valid = false
+	}
+}
+
+// validateSecrets 验证密钥后端配置
+func (v *Validator) validateSecrets(result *ValidationResult) {
+	secrets := v.config.Secrets
+
+	if secrets.RefreshInterval < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "secrets.refresh_interval",
+			Message: "密钥刷新间隔不能为负数",
+			Value:   secrets.RefreshInterval,
+		})
+		result.Valid = false
+	}
+
+	if secrets.Timeout <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "secrets.timeout",
+			Message: "密钥读取超时时间必须大于0",
+			Value:   secrets.Timeout,
+		})
+		result.Valid = false
+	}
+
+	// 解析后仍为引用说明对应后端未配置
+	for _, field := range secretFields(v.config) {
+		if IsSecretReference(*field.value) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: "密钥引用未解析，请检查 secrets 配置",
+				Value:   *field.value,
+			})
+			result.Valid = false
+		}
+	}
+}
+
+// validateRemote 验证远程配置源
+func (v *Validator) validateRemote(result *ValidationResult) {
+	remote := v.config.Remote
+	if !remote.Enabled {
+		return
+	}
+
+	if remote.Provider != RemoteProviderEtcd && remote.Provider != RemoteProviderConsul {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "remote.provider",
+			Message: fmt.Sprintf("远程配置后端必须是以下之一: %s, %s", RemoteProviderEtcd, RemoteProviderConsul),
+			Value:   remote.Provider,
+		})
+		result.Valid = false
+	}
+
+	if len(remote.Endpoints) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "remote.endpoints",
+			Message: "启用远程配置时必须设置服务地址",
+			Value:   remote.Endpoints,
+		})
+		result.Valid = false
+	}
+
+	if remote.Key == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "remote.key",
+			Message: "启用远程配置时必须设置配置文档的键",
+			Value:   remote.Key,
+		})
+		result.Valid = false
+	}
+
+	if remote.Timeout <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "remote.timeout",
+			Message: "远程配置读取超时时间必须大于0",
+			Value:   remote.Timeout,
+		})
+		result.Valid = false
+	}
+}
+
+// validateMetricsSnapshots 验证指标快照持久化配置
+func (v *Validator) validateMetricsSnapshots(result *ValidationResult) {
+	snapshots := v.config.Metrics.Snapshots
+	if !snapshots.Enabled {
+		return
+	}
+
+	if snapshots.Interval <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.interval",
+			Message: "指标采集间隔必须大于0",
+			Value:   snapshots.Interval,
+		})
+		result.Valid = false
+	}
+
+	if snapshots.RawRetention <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.raw_retention",
+			Message: "原始快照保留时间必须大于0",
+			Value:   snapshots.RawRetention,
+		})
+		result.Valid = false
+	}
+
+	if snapshots.RollupInterval <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.rollup_interval",
+			Message: "汇总间隔必须大于0",
+			Value:   snapshots.RollupInterval,
+		})
+		result.Valid = false
+	} else if snapshots.Interval > 0 && (snapshots.RollupInterval*60)%snapshots.Interval != 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.rollup_interval",
+			Message: "汇总间隔必须是采集间隔的整数倍",
+			Value:   snapshots.RollupInterval,
+		})
+		result.Valid = false
+	} else if snapshots.RawRetention > 0 && snapshots.RollupInterval > snapshots.RawRetention*60 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.rollup_interval",
+			Message: "汇总间隔不能超过原始快照保留时间，否则汇总前原始快照已被删除",
+			Value:   snapshots.RollupInterval,
+		})
+		result.Valid = false
+	}
+
+	if snapshots.RollupRetention <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "metrics.snapshots.rollup_retention",
+			Message: "汇总快照保留时间必须大于0",
+			Value:   snapshots.RollupRetention,
+		})
+		result.Valid = false
+	}
+}
+
+// validateNotifications 验证通知配置
+func (v *Validator) validateNotifications(result *ValidationResult) {
+	notifications := v.config.Notifications
+	if !notifications.Enabled {
+		return
+	}
+
+	validChannels := []string{"email", "sms", "push", "in_app"}
+	for _, channel := range notifications.Channels {
+		if !slices.Contains(validChannels, channel) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "notifications.channels",
+				Message: fmt.Sprintf("无效的通知渠道 '%s'，必须是以下之一: %s", channel, strings.Join(validChannels, ", ")),
+				Value:   channel,
+			})
+			result.Valid = false
+		}
+	}
+
+	if notifications.UnreadCountTTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "notifications.unread_count_ttl",
+			Message: "未读数缓存时间必须大于0",
+			Value:   notifications.UnreadCountTTL,
+		})
+		result.Valid = false
+	}
+}
+
+// validateAnnouncements 验证公告配置
+func (v *Validator) validateAnnouncements(result *ValidationResult) {
+	announcements := v.config.Announcements
+	if !announcements.Enabled {
+		return
+	}
+
+	if announcements.CacheTTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "announcements.cache_ttl",
+			Message: "公告缓存时间必须大于0",
+			Value:   announcements.CacheTTL,
+		})
+		result.Valid = false
+	}
+
+	if announcements.Stream && announcements.StreamInterval <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "announcements.stream_interval",
+			Message: "公告推送的检查间隔必须大于0",
+			Value:   announcements.StreamInterval,
+		})
+		result.Valid = false
+	}
+}
+
+// validateExports 验证数据导出配置
+func (v *Validator) validateExports(result *ValidationResult) {
+	exports := v.config.Exports
+	if !exports.Enabled {
+		return
+	}
+
+	if exports.BatchSize < 1 || exports.BatchSize > 10000 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "exports.batch_size",
+			Message: "导出批量大小必须在1到10000之间",
+			Value:   exports.BatchSize,
+		})
+		result.Valid = false
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+		label string
+	}{
+		{"exports.max_running", exports.MaxRunning, "同时执行的导出任务数"},
+		{"exports.retention", exports.Retention, "导出文件保留时间"},
+		{"exports.url_ttl", exports.URLTTL, "下载链接有效期"},
+	} {
+		if field.value <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: field.label + "必须大于0",
+				Value:   field.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	// 使用 RS256/ES256 时没有可以派生签名密钥的共享密钥
+	if exports.SigningKey == "" && v.config.JWT.SecretKey == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "exports.signing_key",
+			Message: "未配置 jwt.secret_key 时必须设置下载链接的签名密钥",
+		})
+		result.Valid = false
+	}
+}
+
+// validateRetention 验证数据保留策略配置
+func (v *Validator) validateRetention(result *ValidationResult) {
+	retention := v.config.Retention
+	if !retention.Enabled {
+		return
+	}
+
+	if retention.Interval <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "retention.interval",
+			Message: "清理间隔必须大于0",
+			Value:   retention.Interval,
+		})
+		result.Valid = false
+	}
+	if retention.BatchSize < 1 || retention.BatchSize > 10000 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "retention.batch_size",
+			Message: "每批删除的行数必须在1到10000之间",
+			Value:   retention.BatchSize,
+		})
+		result.Valid = false
+	}
+
+	validTables := []string{"users", "metrics_snapshots"}
+	seen := make(map[string]bool, len(retention.Policies))
+	for i, policy := range retention.Policies {
+		field := fmt.Sprintf("retention.policies[%d]", i)
+		if !slices.Contains(validTables, policy.Table) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".table",
+				Message: fmt.Sprintf("不支持清理的表 '%s'，必须是以下之一: %s", policy.Table, strings.Join(validTables, ", ")),
+				Value:   policy.Table,
+			})
+			result.Valid = false
+		} else if seen[policy.Table] {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".table",
+				Message: fmt.Sprintf("表 '%s' 的保留策略重复", policy.Table),
+				Value:   policy.Table,
+			})
+			result.Valid = false
+		}
+		seen[policy.Table] = true
+
+		if policy.Retention <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field + ".retention",
+				Message: "保留天数必须大于0",
+				Value:   policy.Retention,
+			})
+			result.Valid = false
+		}
+	}
+
+}
+
+// validateBackups 验证数据库备份配置
+func (v *Validator) validateBackups(result *ValidationResult) {
+	backups := v.config.Backups
+	if !backups.Enabled {
+		return
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+		label string
+	}{
+		{"backups.timeout", backups.Timeout, "备份的最长执行时间"},
+		{"backups.retention", backups.Retention, "备份保留时间"},
+	} {
+		if field.value <= 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: field.label + "必须大于0",
+				Value:   field.value,
+			})
+			result.Valid = false
+		}
+	}
+	if backups.Interval < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "backups.interval",
+			Message: "自动备份间隔不能为负数",
+			Value:   backups.Interval,
+		})
+		result.Valid = false
+	}
+
+	// 备份包含全部数据，必须加密保存
+	keys, err := fieldcrypt.ParseKeys(backups.EncryptionKeys)
+	if err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "backups.encryption_keys",
+			Message: "备份加密密钥格式错误: " + err.Error(),
+		})
+		result.Valid = false
+	} else if _, ok := keys[backups.ActiveKey]; backups.ActiveKey != 0 && !ok {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "backups.active_key",
+			Message: "加密新备份使用的密钥版本不在密钥列表中",
+			Value:   backups.ActiveKey,
+		})
+		result.Valid = false
+	}
+}
+
+// validateImports 验证批量导入配置
+func (v *Validator) validateImports(result *ValidationResult) {
+	imports := v.config.Imports
+	if !imports.Enabled {
+		return
+	}
+
+	if imports.BatchSize < 1 || imports.BatchSize > 1000 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "imports.batch_size",
+			Message: "导入批量大小必须在1到1000之间",
+			Value:   imports.BatchSize,
+		})
+		result.Valid = false
+	}
+	if imports.MaxRows < 1 || imports.MaxRows > 100000 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "imports.max_rows",
+			Message: "导入文件的最大行数必须在1到100000之间",
+			Value:   imports.MaxRows,
+		})
+		result.Valid = false
+	}
+}
+
+// validateEncryption 验证字段加密配置，错误信息中不包含密钥
+func (v *Validator) validateEncryption(result *ValidationResult) {
+	encryption := v.config.Encryption
+	if !encryption.Enabled {
+		return
+	}
+
+	keys, err := fieldcrypt.ParseKeys(encryption.Keys)
+	if err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "encryption.keys",
+			Message: "加密密钥格式错误: " + err.Error(),
+		})
+		result.Valid = false
+	} else if _, ok := keys[encryption.ActiveKey]; encryption.ActiveKey != 0 && !ok {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "encryption.active_key",
+			Message: "加密新数据使用的密钥版本不在密钥列表中",
+			Value:   encryption.ActiveKey,
+		})
+		result.Valid = false
+	}
+
+	if len(encryption.BlindIndexKey) < fieldcrypt.MinIndexKeySize {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "encryption.blind_index_key",
+			Message: fmt.Sprintf("盲索引密钥至少需要%d字节", fieldcrypt.MinIndexKeySize),
+		})
+		result.Valid = false
+	}
+}
+
+// validateOIDC 验证 OpenID Connect 配置
+func (v *Validator) validateOIDC(result *ValidationResult) {
+	oidc := v.config.OIDC
+	if !oidc.Enabled {
+		return
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		label string
+	}{
+		{"oidc.issuer", oidc.Issuer, "签发者地址"},
+		{"oidc.consent_url", oidc.ConsentURL, "授权同意页地址"},
+	} {
+		if parsed, err := url.Parse(field.value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: field.label + "必须是有效的HTTP(S) URL",
+				Value:   field.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	if oidc.CodeTTL <= 0 || oidc.CodeTTL > 600 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "oidc.code_ttl",
+			Message: "授权码有效期必须在1到600秒之间",
+			Value:   oidc.CodeTTL,
+		})
+		result.Valid = false
+	}
+	if oidc.IDTokenTTL <= 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "oidc.id_token_ttl",
+			Message: "ID令牌有效期必须大于0",
+			Value:   oidc.IDTokenTTL,
+		})
+		result.Valid = false
+	}
+
+	// 客户端通过 JWKS 验证 ID 令牌，HMAC 密钥无法公开
+	if v.config.JWT.Algorithm != "RS256" && v.config.JWT.Algorithm != "ES256" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "jwt.algorithm",
+			Message: "启用 OpenID Connect 时签名算法必须为 RS256 或 ES256",
+			Value:   v.config.JWT.Algorithm,
+		})
+		result.Valid = false
+	}
+}
+
+// samlTenantPattern 租户标识出现在 SAML 路径中，只允许 URL 安全的字符
+var samlTenantPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,63}$`)
+
+// validatePasswordless 验证无密码登录配置
+func (v *Validator) validatePasswordless(result *ValidationResult) {
+	passwordless := v.config.Passwordless
+	if !passwordless.Enabled {
+		return
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		label string
+	}{
+		{"passwordless.verification_url", passwordless.VerificationURL, "设备验证页地址"},
+		{"passwordless.magic_link_url", passwordless.MagicLinkURL, "登录链接页地址"},
+	} {
+		if parsed, err := url.Parse(field.value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: field.label + "必须是有效的HTTP(S) URL",
+				Value:   field.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+		max   int
+		label string
+	}{
+		{"passwordless.device_code_ttl", passwordless.DeviceCodeTTL, 1800, "设备码有效期"},
+		{"passwordless.poll_interval", passwordless.PollInterval, 60, "轮询间隔"},
+		{"passwordless.magic_link_ttl", passwordless.MagicLinkTTL, 3600, "登录链接有效期"},
+	} {
+		if field.value <= 0 || field.value > field.max {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field.name,
+				Message: fmt.Sprintf("%s必须在1到%d秒之间", field.label, field.max),
+				Value:   field.value,
+			})
+			result.Valid = false
+		}
+	}
+
+	if passwordless.PollInterval >= passwordless.DeviceCodeTTL {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "passwordless.poll_interval",
+			Message: "轮询间隔必须小于设备码有效期",
+			Value:   passwordless.PollInterval,
+		})
+		result.Valid = false
+	}
+}
+
+// quotaNamePattern 配额名称出现在计数键中，只允许小写字母、数字和下划线
+var quotaNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// validateQuota 验证用量配额配置
+func (v *Validator) validateQuota(result *ValidationResult) {
+	cfg := v.config.Quota
+	if !cfg.Enabled {
+		return
+	}
+
+	invalid := func(field, message string, value interface{}) {
+		result.Errors = append(result.Errors, ValidationError{Field: field, Message: message, Value: value})
+		result.Valid = false
+	}
+
+	if cfg.KeyPrefix == "" {
+		invalid("quota.key_prefix", "Redis 键前缀不能为空", cfg.KeyPrefix)
+	}
+	if len(cfg.Limits) == 0 {
+		invalid("quota.limits", "启用用量配额时至少需要一项配额", len(cfg.Limits))
+	}
+
+	seen := make(map[string]bool, len(cfg.Limits))
+	for i, limit := range cfg.Limits {
+		field := fmt.Sprintf("quota.limits[%d]", i)
+		if !quotaNamePattern.MatchString(limit.Name) {
+			invalid(field+".name", "配额名称只能包含小写字母、数字和下划线，且以字母开头", limit.Name)
+		} else if seen[limit.Name] {
+			invalid(field+".name", "配额名称重复", limit.Name)
+		}
+		seen[limit.Name] = true
+
+		if limit.Soft < 0 {
+			invalid(field+".soft", "软限制不能为负数", limit.Soft)
+		}
+		if limit.Hard < 0 {
+			invalid(field+".hard", "硬限制不能为负数", limit.Hard)
+		}
+		if limit.Hard > 0 && limit.Soft > limit.Hard {
+			invalid(field+".soft", "软限制不能大于硬限制", limit.Soft)
+		}
+	}
+}
+
+// validateBilling 验证订阅套餐配置
+func (v *Validator) validateBilling(result *ValidationResult) {
+	cfg := v.config.Billing
+	if !cfg.Enabled {
+		return
+	}
+
+	invalid := func(field, message string, value interface{}) {
+		result.Errors = append(result.Errors, ValidationError{Field: field, Message: message, Value: value})
+		result.Valid = false
+	}
+
+	if cfg.StripeWebhookSecret == "" {
+		invalid("billing.stripe_webhook_secret", "启用订阅时必须设置 Stripe webhook 签名密钥", "")
+	}
+	if cfg.SignatureTolerance <= 0 {
+		invalid("billing.signature_tolerance", "签名时间戳偏差必须大于0", cfg.SignatureTolerance)
+	}
+	if cfg.CacheTTL < 0 {
+		invalid("billing.cache_ttl", "套餐缓存时间不能为负数", cfg.CacheTTL)
+	}
+
+	plans := make(map[string]bool, len(cfg.Plans))
+	prices := make(map[string]bool)
+	for i, plan := range cfg.Plans {
+		field := fmt.Sprintf("billing.plans[%d]", i)
+		if !quotaNamePattern.MatchString(plan.ID) {
+			invalid(field+".id", "套餐标识只能包含小写字母、数字和下划线，且以字母开头", plan.ID)
+		} else if plans[plan.ID] {
+			invalid(field+".id", "套餐标识重复", plan.ID)
+		}
+		plans[plan.ID] = true
+
+		if plan.RateLimit < 0 {
+			invalid(field+".rate_limit", "限流不能为负数", plan.RateLimit)
+		}
+		for j, limit := range plan.Quotas {
+			quotaField := fmt.Sprintf("%s.quotas[%d]", field, j)
+			if !quotaNamePattern.MatchString(limit.Name) {
+				invalid(quotaField+".name", "配额名称只能包含小写字母、数字和下划线，且以字母开头", limit.Name)
+			}
+			if limit.Soft < 0 || limit.Hard < 0 {
+				invalid(quotaField, "配额限制不能为负数", limit)
+			} else if limit.Hard > 0 && limit.Soft > limit.Hard {
+				invalid(quotaField+".soft", "软限制不能大于硬限制", limit.Soft)
+			}
+		}
+		for _, price := range plan.StripePriceIDs {
+			if prices[price] {
+				invalid(field+".stripe_price_ids", "Stripe 价格只能对应一个套餐", price)
+			}
+			prices[price] = true
+		}
+	}
+
+	if !plans[cfg.DefaultPlan] {
+		invalid("billing.default_plan", "默认套餐必须是 billing.plans 中的套餐", cfg.DefaultPlan)
+	}
+}
+
+// validateMaintenance 验证维护模式配置
+func (v *Validator) validateMaintenance(result *ValidationResult) {
+	cfg := v.config.Maintenance
+	if !cfg.Enabled {
+		return
+	}
+
+	invalid := func(field, message string, value interface{}) {
+		result.Errors = append(result.Errors, ValidationError{Field: field, Message: message, Value: value})
+		result.Valid = false
+	}
+
+	if cfg.KeyPrefix == "" {
+		invalid("maintenance.key_prefix", "Redis 键前缀不能为空", cfg.KeyPrefix)
+	}
+	if cfg.EstimatedDowntime < 0 {
+		invalid("maintenance.estimated_downtime", "预计维护时长不能为负数", cfg.EstimatedDowntime)
+	}
+	if cfg.RefreshInterval <= 0 {
+		invalid("maintenance.refresh_interval", "维护状态刷新间隔必须大于0", cfg.RefreshInterval)
+	}
+	for i, path := range cfg.AllowPaths {
+		if !strings.HasPrefix(path, "/") {
+			invalid(fmt.Sprintf("maintenance.allow_paths[%d]", i), "放行路径必须以 / 开头", path)
+		}
+	}
+}
+
+// validatePreflight 验证启动检查配置
+func (v *Validator) validatePreflight(result *ValidationResult) {
+	cfg := v.config.Preflight
+	if cfg.Enabled && cfg.Timeout <= 0 {
+		result.Errors = append(result.Errors, ValidationError{Field: "preflight.timeout", Message: "启动检查超时时间必须大于0", Value: cfg.Timeout})
+		result.Valid = false
+	}
+}
+
+// validateSAML 验证 SAML 单点登录配置
+func (v *Validator) validateSAML(result *ValidationResult) {
+	cfg := v.config.SAML
+	if !cfg.Enabled {
+		return
+	}
+
+	invalid := func(field, message string, value interface{}) {
+		result.Errors = append(result.Errors, ValidationError{Field: field, Message: message, Value: value})
+		result.Valid = false
+	}
+	isHTTPURL := func(value string) bool {
+		parsed, err := url.Parse(value)
+		return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+	}
+
+	if !isHTTPURL(cfg.BaseURL) {
+		invalid("saml.base_url", "服务根地址必须是有效的HTTP(S) URL", cfg.BaseURL)
+	}
+	if !isHTTPURL(cfg.SuccessURL) {
+		invalid("saml.success_url", "登录完成页地址必须是有效的HTTP(S) URL", cfg.SuccessURL)
+	}
+	if cfg.ClockSkew < 0 || cfg.ClockSkew > 600 {
+		invalid("saml.clock_skew", "时钟偏差必须在0到600秒之间", cfg.ClockSkew)
+	}
+	if cfg.RequestTTL <= 0 || cfg.RequestTTL > 3600 {
+		invalid("saml.request_ttl", "认证请求有效期必须在1到3600秒之间", cfg.RequestTTL)
+	}
+	if len(cfg.Providers) == 0 {
+		invalid("saml.providers", "启用 SAML 时至少需要配置一个身份提供方", nil)
+	}
+
+	seen := make(map[string]bool, len(cfg.Providers))
+	for i, provider := range cfg.Providers {
+		prefix := fmt.Sprintf("saml.providers[%d]", i)
+		switch {
+		case !samlTenantPattern.MatchString(provider.Tenant):
+			invalid(prefix+".tenant", "租户标识只能包含字母、数字、下划线和连字符", provider.Tenant)
+		case seen[provider.Tenant]:
+			invalid(prefix+".tenant", "每个租户只能配置一个身份提供方", provider.Tenant)
+		}
+		seen[provider.Tenant] = true
+
+		if provider.IdPEntityID == "" {
+			invalid(prefix+".idp_entity_id", "身份提供方的实体ID是必需的", provider.IdPEntityID)
+		}
+		if !isHTTPURL(provider.IdPSSOURL) {
+			invalid(prefix+".idp_sso_url", "单点登录地址必须是有效的HTTP(S) URL", provider.IdPSSOURL)
+		}
+		// 证书内容较长，错误中不回显
+		if _, err := saml.ParseCertificate(provider.IdPCertificate); err != nil {
+			invalid(prefix+".idp_certificate", "无法解析签名证书: "+err.Error(), nil)
+		}
+	}
+}
+
 // validateMode 验证应用模式
 func (v *Validator) validateMode(result *ValidationResult) {
 	mode := v.config.Mode
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBackfillBatchSize is used when BackfillOptions.BatchSize is not set
+const DefaultBackfillBatchSize = 1000
+
+// BackfillOptions configures BackfillInBatches
+type BackfillOptions struct {
+	Table     string        // Table to update
+	KeyColumn string        // Primary key column used to pick each batch, defaults to id
+	Set       string        // SET clause, e.g. "status = ?"
+	SetArgs   []interface{} // Arguments for the placeholders in Set
+	Where     string        // Rows still to backfill, e.g. "status IS NULL"; must stop matching once a row is updated
+	WhereArgs []interface{} // Arguments for the placeholders in Where
+	BatchSize int           // Rows updated per statement, defaults to DefaultBackfillBatchSize
+	Pause     time.Duration // Sleep between batches so replicas and autovacuum can keep up
+	// Progress is called after each batch with the total number of rows updated so far
+	Progress func(updated int64)
+}
+
+// BackfillInBatches updates the rows matching opts.Where in short batches, each in its own
+// statement, so that backfilling a new column never holds row locks on the whole table.
+// It is the middle step of adding a NOT NULL column safely: add it nullable, backfill, then
+// set NOT NULL. Rows locked by other transactions are skipped and picked up by a later batch.
+// It returns the number of rows updated
+func BackfillInBatches(ctx context.Context, db *gorm.DB, opts BackfillOptions) (int64, error) {
+	if opts.KeyColumn == "" {
+		opts.KeyColumn = "id"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBackfillBatchSize
+	}
+	if err := validateIdentifier("table", opts.Table); err != nil {
+		return 0, err
+	}
+	if err := validateIdentifier("key column", opts.KeyColumn); err != nil {
+		return 0, err
+	}
+	if opts.Set == "" || opts.Where == "" {
+		return 0, fmt.Errorf("backfill of %s requires both Set and Where", opts.Table)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (SELECT %s FROM %s WHERE %s LIMIT %d FOR UPDATE SKIP LOCKED)",
+		opts.Table, opts.Set, opts.KeyColumn, opts.KeyColumn, opts.Table, opts.Where, opts.BatchSize)
+	args := append(append([]interface{}{}, opts.SetArgs...), opts.WhereArgs...)
+
+	var updated int64
+	for {
+		result := db.WithContext(ctx).Exec(query, args...)
+		if result.Error != nil {
+			return updated, fmt.Errorf("failed to backfill %s after %d rows: %w", opts.Table, updated, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return updated, nil
+		}
+
+		updated += result.RowsAffected
+		if opts.Progress != nil {
+			opts.Progress(updated)
+		}
+
+		if opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return updated, ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+}
@@ -3,14 +3,15 @@ package database
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/internal/metrics"
 	"go-server/internal/models"
+	"go-server/internal/tenancy"
 
 	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
@@ -20,13 +21,15 @@ import (
 
 // Database 数据库连接和健康监控
 type Database struct {
-	DB           *gorm.DB               // 数据库连接
-	config       *config.DatabaseConfig // 数据库配置
-	logger       logger.Logger          // 日志记录器
-	healthStatus *PoolHealthStatus      // 连接池健康状态
-	queryStats   *QueryPerformanceStats // 查询性能统计
-	queryMu      sync.RWMutex           // 查询统计读写锁
-	mu           sync.RWMutex           // 读写锁
+	DB             *gorm.DB                     // 数据库连接
+	config         *config.DatabaseConfig       // 数据库配置
+	logger         logger.Logger                // 日志记录器
+	healthStatus   *PoolHealthStatus            // 连接池健康状态
+	queryStats     *QueryPerformanceStats       // 查询性能统计
+	metrics        *metrics.DatabaseMetrics     // 查询指标（延迟直方图等）
+	requestQueries *metrics.RequestQueryMetrics // 每个请求的查询计数，未启用时为 nil
+	queryMu        sync.RWMutex                 // 查询统计读写锁
+	mu             sync.RWMutex                 // 读写锁
 }
 
 // PoolHealthStatus 连接池健康状态指标
@@ -73,7 +76,7 @@ const (
 )
 
 // updateQueryStats 更新查询性能统计
-func (d *Database) updateQueryStats(duration time.Duration, query string, vars []interface{}, err error, rowsAffected int64) {
+func (d *Database) updateQueryStats(duration time.Duration, _ string, _ []interface{}, _ error, _ int64) {
 	d.queryMu.Lock()
 	defer d.queryMu.Unlock()
 
@@ -94,131 +97,11 @@ func (d *Database) updateQueryStats(duration time.Duration, query string, vars [
 		d.queryStats.AverageDuration = time.Duration(d.queryStats.TotalDurationNanos / d.queryStats.TotalQueries)
 	}
 
-	// 检查是否为慢查询
-	if duration > SlowQueryThreshold {
+	// 检查是否为慢查询（日志由查询监控插件输出）
+	if duration > d.queryStats.SlowQueryThreshold {
 		atomic.AddInt64(&d.queryStats.SlowQueries, 1)
 		d.queryStats.LastSlowQuery = time.Now()
-
-		// 记录慢查询
-		d.logSlowQuery(duration, query, vars, err, rowsAffected)
-	}
-}
-
-// logSlowQuery 记录慢查询及其性能指标
-func (d *Database) logSlowQuery(duration time.Duration, query string, vars []interface{}, err error, rowsAffected int64) {
-	// 清理查询字符串以便记录
-	cleanQuery := strings.TrimSpace(query)
-	if cleanQuery == "" {
-		cleanQuery = "未知查询"
 	}
-
-	// 构建日志消息
-	logMessage := fmt.Sprintf(
-		"[慢查询] 持续时间: %v | 查询: %s | 影响行数: %d",
-		duration,
-		cleanQuery,
-		rowsAffected,
-	)
-
-	// 如果有错误信息，添加到日志中
-	if err != nil {
-		logMessage += fmt.Sprintf(" | 错误: %v", err)
-	}
-
-	// 记录慢查询
-	d.logger.Warn(context.Background(), "检测到数据库慢查询",
-		logger.String("duration", duration.String()),
-		logger.String("query", cleanQuery),
-		logger.Int64("rows_affected", rowsAffected),
-		logger.String("error", func() string {
-			if err != nil {
-				return err.Error()
-			}
-			return ""
-		}()),
-		logger.Any("parameters", func() interface{} {
-			if len(vars) > 0 && d.config != nil && config.IsDevelopment("development") {
-				return vars
-			}
-			return nil
-		}()))
-}
-
-// registerQueryCallbacks 向GORM注册查询监控回调
-func (d *Database) registerQueryCallbacks() {
-	callback := d.DB.Callback()
-
-	// 为查询操作注册回调
-	callback.Query().Before("gorm:query").Register("query_monitor:before", func(db *gorm.DB) {
-		db.InstanceSet("query_start_time", time.Now())
-	})
-
-	callback.Query().After("gorm:query").Register("query_monitor:after", func(db *gorm.DB) {
-		if startTime, ok := db.InstanceGet("query_start_time"); ok {
-			if start, ok := startTime.(time.Time); ok {
-				duration := time.Since(start)
-				d.updateQueryStats(duration, db.Statement.SQL.String(), db.Statement.Vars, db.Error, db.RowsAffected)
-			}
-		}
-	})
-
-	// 为创建操作注册回调
-	callback.Create().Before("gorm:create").Register("query_monitor:create_before", func(db *gorm.DB) {
-		db.InstanceSet("query_start_time", time.Now())
-	})
-
-	callback.Create().After("gorm:create").Register("query_monitor:create_after", func(db *gorm.DB) {
-		if startTime, ok := db.InstanceGet("query_start_time"); ok {
-			if start, ok := startTime.(time.Time); ok {
-				duration := time.Since(start)
-				d.updateQueryStats(duration, db.Statement.SQL.String(), db.Statement.Vars, db.Error, db.RowsAffected)
-			}
-		}
-	})
-
-	// 为更新操作注册回调
-	callback.Update().Before("gorm:update").Register("query_monitor:update_before", func(db *gorm.DB) {
-		db.InstanceSet("query_start_time", time.Now())
-	})
-
-	callback.Update().After("gorm:update").Register("query_monitor:update_after", func(db *gorm.DB) {
-		if startTime, ok := db.InstanceGet("query_start_time"); ok {
-			if start, ok := startTime.(time.Time); ok {
-				duration := time.Since(start)
-				d.updateQueryStats(duration, db.Statement.SQL.String(), db.Statement.Vars, db.Error, db.RowsAffected)
-			}
-		}
-	})
-
-	// 为删除操作注册回调
-	callback.Delete().Before("gorm:delete").Register("query_monitor:delete_before", func(db *gorm.DB) {
-		db.InstanceSet("query_start_time", time.Now())
-	})
-
-	callback.Delete().After("gorm:delete").Register("query_monitor:delete_after", func(db *gorm.DB) {
-		if startTime, ok := db.InstanceGet("query_start_time"); ok {
-			if start, ok := startTime.(time.Time); ok {
-				duration := time.Since(start)
-				d.updateQueryStats(duration, db.Statement.SQL.String(), db.Statement.Vars, db.Error, db.RowsAffected)
-			}
-		}
-	})
-
-	// 为原始操作注册回调
-	callback.Raw().Before("gorm:raw").Register("query_monitor:raw_before", func(db *gorm.DB) {
-		db.InstanceSet("query_start_time", time.Now())
-	})
-
-	callback.Raw().After("gorm:raw").Register("query_monitor:raw_after", func(db *gorm.DB) {
-		if startTime, ok := db.InstanceGet("query_start_time"); ok {
-			if start, ok := startTime.(time.Time); ok {
-				duration := time.Since(start)
-				d.updateQueryStats(duration, db.Statement.SQL.String(), db.Statement.Vars, db.Error, db.RowsAffected)
-			}
-		}
-	})
-
-	d.logger.Debug(context.Background(), "数据库查询监控回调已注册")
 }
 
 // NewDatabase 创建新的数据库连接
@@ -232,6 +115,12 @@ func NewDatabase(cfg *config.Config, loggerManager *logger.Manager) (*Database,
         cfg.Database.SSLMode,
     )
 
+	return NewDatabaseWithDialector(cfg, loggerManager, postgres.Open(dsn))
+}
+
+// NewDatabaseWithDialector 使用指定的 GORM 方言创建数据库连接
+// 连接池、查询监控和租户插件与 NewDatabase 相同，测试可以借此换用 SQLite 等数据库
+func NewDatabaseWithDialector(cfg *config.Config, loggerManager *logger.Manager, dialector gorm.Dialector) (*Database, error) {
 	// 配置GORM日志
 	var gormLogLevel gormlogger.LogLevel
 	if config.IsDevelopment(cfg.Mode) {
@@ -240,7 +129,7 @@ func NewDatabase(cfg *config.Config, loggerManager *logger.Manager) (*Database,
 		gormLogLevel = gormlogger.Error
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormlogger.Default.LogMode(gormLogLevel),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
@@ -291,6 +180,12 @@ func NewDatabase(cfg *config.Config, loggerManager *logger.Manager) (*Database,
 
 	dbLogger.Info(context.Background(), "数据库连接建立成功")
 
+	// 解析慢查询阈值
+	slowQueryThreshold := time.Duration(cfg.Database.SlowQueryThreshold) * time.Millisecond
+	if slowQueryThreshold <= 0 {
+		slowQueryThreshold = SlowQueryThreshold // 默认回退值
+	}
+
 	// Initialize database with health monitoring and query performance tracking
 	database := &Database{
 		DB:     db,
@@ -303,9 +198,10 @@ func NewDatabase(cfg *config.Config, loggerManager *logger.Manager) (*Database,
 			IsHealthy:          true,
 		},
 		queryStats: &QueryPerformanceStats{
-			SlowQueryThreshold: SlowQueryThreshold,
+			SlowQueryThreshold: slowQueryThreshold,
 			MinDuration:        0, // Will be set on first query
 		},
+		metrics: metrics.NewDatabaseMetrics(),
 	}
 
 	// Perform initial health check
@@ -313,11 +209,31 @@ func NewDatabase(cfg *config.Config, loggerManager *logger.Manager) (*Database,
 		dbLogger.Warn(context.Background(), "初始健康检查失败", logger.Error(err))
 	}
 
-	// Register query monitoring callbacks
-	database.registerQueryCallbacks()
+	// 注册查询监控插件
+	queryMonitor := NewQueryMonitorPlugin(slowQueryThreshold, dbLogger, database.metrics)
+	queryMonitor.AddObserver(database.updateQueryStats)
+	if err := db.Use(queryMonitor); err != nil {
+		return nil, fmt.Errorf("注册查询监控插件失败: %w", err)
+	}
 
 	dbLogger.Info(context.Background(), "数据库查询监控已启用",
-		logger.String("slow_query_threshold", SlowQueryThreshold.String()))
+		logger.String("slow_query_threshold", slowQueryThreshold.String()))
+
+	// 注册请求查询计数插件，按关联ID统计每个请求执行的SQL
+	if rq := cfg.Database.RequestQueries; rq.Enabled {
+		database.requestQueries = metrics.NewRequestQueryMetrics(rq.MaxQueries, rq.RepeatThreshold, rq.TopOffenders)
+		if err := db.Use(NewRequestQueryPlugin(database.requestQueries)); err != nil {
+			return nil, fmt.Errorf("注册请求查询计数插件失败: %w", err)
+		}
+		dbLogger.Info(context.Background(), "请求查询计数已启用",
+			logger.Int("max_queries", rq.MaxQueries),
+			logger.Int("repeat_threshold", rq.RepeatThreshold))
+	}
+
+	// 注册租户插件，以带租户的上下文执行的语句自动按 tenant_id 过滤
+	if err := db.Use(tenancy.NewPlugin()); err != nil {
+		return nil, fmt.Errorf("注册租户插件失败: %w", err)
+	}
 
 	return database, nil
 }
@@ -327,7 +243,16 @@ func (d *Database) AutoMigrate() error {
 	d.logger.Info(context.Background(), "正在运行数据库迁移")
 
 	err := d.DB.AutoMigrate(
+		&models.Tenant{},
 		&models.User{},
+		&models.MetricsSnapshot{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Announcement{},
+		&models.Export{},
+		&models.OAuthClient{},
+		&models.Subscription{},
+		&models.Backup{},
 	)
 	if err != nil {
 		return fmt.Errorf("运行迁移失败: %w", err)
@@ -341,7 +266,7 @@ func (d *Database) AutoMigrate() error {
 	}
 
 	// 如果在开发模式下，植入初始数据
-	if err := d.seedData(); err != nil {
+	if err := d.Seed(); err != nil {
 		d.logger.Warn(context.Background(), "植入初始数据失败", logger.Error(err))
 	}
 
@@ -380,8 +305,8 @@ func (d *Database) createIndexes() error {
 	return nil
 }
 
-// seedData seeds initial data for development
-func (d *Database) seedData() error {
+// Seed seeds the initial admin and test users, skipping when the admin user already exists
+func (d *Database) Seed() error {
 	d.logger.Info(context.Background(), "正在植入初始数据")
 
 	// Check if admin user already exists
@@ -522,6 +447,11 @@ func (d *Database) GetConnectionPoolStats() (map[string]interface{}, error) {
 
 	stats := sqlDB.Stats()
 
+	utilization := float64(0)
+	if stats.MaxOpenConnections > 0 {
+		utilization = float64(stats.OpenConnections) / float64(stats.MaxOpenConnections) * 100
+	}
+
 	return map[string]interface{}{
 		"open_connections":    stats.OpenConnections,
 		"in_use":              stats.InUse,
@@ -530,13 +460,31 @@ func (d *Database) GetConnectionPoolStats() (map[string]interface{}, error) {
 		"wait_duration":       stats.WaitDuration.String(),
 		"max_idle_closed":     stats.MaxIdleTimeClosed,
 		"max_lifetime_closed": stats.MaxLifetimeClosed,
-		"max_open_conns":      d.config.MaxOpenConns,
+		"max_open_conns":      stats.MaxOpenConnections,
 		"max_idle_conns":      d.config.MaxIdleConns,
 		"conn_max_lifetime":   time.Duration(d.config.ConnMaxLifetime) * time.Second,
-		"utilization_percent": float64(stats.OpenConnections) / float64(d.config.MaxOpenConns) * 100,
+		"utilization_percent": utilization,
 	}, nil
 }
 
+// GetMetrics returns the query metrics recorded by the query monitor plugin
+func (d *Database) GetMetrics() *metrics.DatabaseMetrics {
+	return d.metrics
+}
+
+// RequestQueries returns the per-request query counts, nil when database.request_queries is disabled
+func (d *Database) RequestQueries() *metrics.RequestQueryMetrics {
+	return d.requestQueries
+}
+
+// GetQueryLatencyHistograms returns query latency histograms keyed by query type
+func (d *Database) GetQueryLatencyHistograms() map[string]metrics.HistogramSnapshot {
+	if d.metrics == nil {
+		return map[string]metrics.HistogramSnapshot{}
+	}
+	return d.metrics.GetLatencyHistograms()
+}
+
 // GetQueryPerformanceStats returns the current query performance statistics
 func (d *Database) GetQueryPerformanceStats() *QueryPerformanceStats {
 	d.queryMu.RLock()
@@ -9,8 +9,8 @@ import (
 	"go-server/internal/config"
 	"go-server/internal/logger"
 	"go-server/internal/models"
+	"go-server/pkg/testutil/factory"
 
-	"github.com/google/uuid"
 )
 
 // setupBenchmarkDB creates a test database connection for benchmarks
@@ -65,19 +65,8 @@ func cleanupBenchmarkDB(b *testing.B, db *Database) {
 	}
 }
 
-// createTestUser creates a test user for benchmarks
-func createTestUser(index int) models.User {
-	return models.User{
-		ID:        uuid.New().String(),
-		Username:  fmt.Sprintf("benchuser_%d", index),
-		Email:     fmt.Sprintf("bench-%d@example.com", index),
-		Password:  "$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi",
-		FirstName: fmt.Sprintf("Bench_%d", index),
-		LastName:  "User",
-		IsActive:  true,
-		IsAdmin:   false,
-	}
-}
+// benchUsers builds benchmark users; the bench- email prefix is what cleanupBenchmarkDB deletes
+var benchUsers = factory.User().WithPrefix("bench")
 
 // BenchmarkDatabaseConnection benchmarks database connection establishment
 func BenchmarkDatabaseConnection(b *testing.B) {
@@ -234,7 +223,7 @@ func BenchmarkInsertOperations(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		user := createTestUser(i)
+		user := *benchUsers.Build()
 		if err := db.DB.Create(&user).Error; err != nil {
 			b.Fatalf("Insert failed: %v", err)
 		}
@@ -267,7 +256,7 @@ func BenchmarkBatchInsert(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				var users []models.User
 				for j := 0; j < batchSize; j++ {
-					users = append(users, createTestUser(i*batchSize+j))
+					users = append(users, *benchUsers.Build())
 				}
 
 				if err := db.DB.CreateInBatches(users, batchSize).Error; err != nil {
@@ -294,7 +283,7 @@ func BenchmarkUpdateOperations(b *testing.B) {
 	// Create some test users first
 	var testUsers []models.User
 	for i := 0; i < 100; i++ {
-		user := createTestUser(i)
+		user := *benchUsers.Build()
 		if err := db.DB.Create(&user).Error; err != nil {
 			b.Fatalf("Failed to create test user: %v", err)
 		}
@@ -333,7 +322,7 @@ func BenchmarkDeleteOperations(b *testing.B) {
 	createTestUsers := func(count int) []models.User {
 		var users []models.User
 		for i := 0; i < count; i++ {
-			user := createTestUser(i)
+			user := *benchUsers.Build()
 			if err := db.DB.Create(&user).Error; err != nil {
 				b.Fatalf("Failed to create test user: %v", err)
 			}
@@ -369,7 +358,7 @@ func BenchmarkComplexQueries(b *testing.B) {
 	// Create test data
 	var testUsers []models.User
 	for i := 0; i < 1000; i++ {
-		user := createTestUser(i)
+		user := *benchUsers.Build()
 		user.IsActive = i%2 == 0 // Mix of active/inactive users
 		user.IsAdmin = i%10 == 0 // 10% admin users
 		if err := db.DB.Create(&user).Error; err != nil {
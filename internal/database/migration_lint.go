@@ -0,0 +1,152 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Migration safety rules reported by LintMigration
+const (
+	RuleNotNullWithoutDefault   = "not_null_without_default"
+	RuleColumnTypeChange        = "column_type_change"
+	RuleIndexWithoutConcurrency = "index_without_concurrently"
+	RuleConcurrentInBatch       = "concurrently_with_other_statements"
+)
+
+// ErrUnsafeMigration is returned by RunMigrationsSafely when a pending migration fails the safety checks
+var ErrUnsafeMigration = errors.New("pending migrations contain unsafe operations")
+
+// lintIgnoreRegex matches "-- lint:ignore rule[, rule]" comments that suppress a rule for a whole migration
+var lintIgnoreRegex = regexp.MustCompile(`(?im)^\s*--\s*lint:ignore\s+([a-z_,\s]+)$`)
+
+var (
+	alterTableRegex    = regexp.MustCompile(`^ALTER TABLE (IF EXISTS )?(ONLY )?([A-Z0-9_."]+)`)
+	addClauseRegex     = regexp.MustCompile(`\bADD (\S+)`)
+	setNotNullRegex    = regexp.MustCompile(`\bALTER (COLUMN )?\S+ SET NOT NULL\b`)
+	typeChangeRegex    = regexp.MustCompile(`\bALTER (COLUMN )?\S+ (SET DATA )?TYPE\b`)
+	createIndexRegex   = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX\b`)
+	indexTableRegex    = regexp.MustCompile(`\bON (ONLY )?([A-Z0-9_."]+)`)
+	createTableRegex   = regexp.MustCompile(`^CREATE TABLE (IF NOT EXISTS )?([A-Z0-9_."]+)`)
+	whitespaceRegex    = regexp.MustCompile(`\s+`)
+	lineCommentRegex   = regexp.MustCompile(`--[^\n]*`)
+	concurrentlyRegex  = regexp.MustCompile(`(?i)\bCONCURRENTLY\b`)
+	notNullRegex       = regexp.MustCompile(`\bNOT NULL\b`)
+	columnDefaultRegex = regexp.MustCompile(`\bDEFAULT\b`)
+)
+
+// MigrationIssue is an operation in a migration that can lock a table or fail on a large one
+type MigrationIssue struct {
+	Version   string
+	Rule      string
+	Statement string
+	Message   string
+}
+
+// String formats the issue for the CLI
+func (i MigrationIssue) String() string {
+	return fmt.Sprintf("%s [%s] %s\n    %s", i.Version, i.Rule, i.Message, i.Statement)
+}
+
+// LintMigration checks the up SQL of a migration for operations that take long table locks.
+// Statements are split on semicolons, so dollar-quoted function bodies may be reported
+// statement by statement; suppress false positives with a "-- lint:ignore <rule>" comment
+func LintMigration(file *MigrationFile) []MigrationIssue {
+	ignored := make(map[string]bool)
+	for _, match := range lintIgnoreRegex.FindAllStringSubmatch(file.Up, -1) {
+		for _, rule := range strings.Split(match[1], ",") {
+			ignored[strings.TrimSpace(rule)] = true
+		}
+	}
+
+	statements := splitStatements(file.Up)
+
+	// Tables created in this migration are empty, so locking them is harmless
+	created := make(map[string]bool)
+	for _, statement := range statements {
+		if match := createTableRegex.FindStringSubmatch(statement); match != nil {
+			created[normalizeTableName(match[2])] = true
+		}
+	}
+
+	var issues []MigrationIssue
+	report := func(rule, statement, message string) {
+		if !ignored[rule] {
+			issues = append(issues, MigrationIssue{Version: file.Version, Rule: rule, Statement: statement, Message: message})
+		}
+	}
+
+	for _, statement := range statements {
+		if createIndexRegex.MatchString(statement) {
+			if concurrentlyRegex.MatchString(statement) {
+				if len(statements) > 1 {
+					report(RuleConcurrentInBatch, statement,
+						"CREATE INDEX CONCURRENTLY cannot run in a transaction; put it in its own migration")
+				}
+				continue
+			}
+			if match := indexTableRegex.FindStringSubmatch(statement); match != nil && created[normalizeTableName(match[2])] {
+				continue
+			}
+			report(RuleIndexWithoutConcurrency, statement,
+				"CREATE INDEX blocks writes to the table while it builds; use CREATE INDEX CONCURRENTLY")
+			continue
+		}
+
+		match := alterTableRegex.FindStringSubmatch(statement)
+		if match == nil || created[normalizeTableName(match[3])] {
+			continue
+		}
+		if addsColumn(statement) && notNullRegex.MatchString(statement) && !columnDefaultRegex.MatchString(statement) {
+			report(RuleNotNullWithoutDefault, statement,
+				"adding a NOT NULL column without a DEFAULT fails on tables with rows; add it nullable, backfill in batches, then set NOT NULL")
+		}
+		if setNotNullRegex.MatchString(statement) {
+			report(RuleNotNullWithoutDefault, statement,
+				"SET NOT NULL scans the whole table under an exclusive lock; add a NOT VALID check constraint and validate it first")
+		}
+		if typeChangeRegex.MatchString(statement) {
+			report(RuleColumnTypeChange, statement,
+				"changing a column type rewrites the table under an exclusive lock; add a new column and backfill it instead")
+		}
+	}
+	return issues
+}
+
+// addsColumn reports whether an ALTER TABLE statement adds a column rather than a constraint
+func addsColumn(statement string) bool {
+	for _, match := range addClauseRegex.FindAllStringSubmatch(statement, -1) {
+		switch match[1] {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE", "VALUE":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements strips line comments and returns the upper-cased, whitespace-normalized statements
+func splitStatements(sql string) []string {
+	sql = lineCommentRegex.ReplaceAllString(sql, "")
+
+	var statements []string
+	for _, statement := range strings.Split(sql, ";") {
+		statement = strings.TrimSpace(whitespaceRegex.ReplaceAllString(statement, " "))
+		if statement != "" {
+			statements = append(statements, strings.ToUpper(statement))
+		}
+	}
+	return statements
+}
+
+// normalizeTableName drops quotes and the public schema prefix
+func normalizeTableName(name string) string {
+	name = strings.ReplaceAll(name, `"`, "")
+	return strings.TrimPrefix(name, "PUBLIC.")
+}
+
+// runsOutsideTransaction reports whether a migration must not be wrapped in a transaction
+func runsOutsideTransaction(sql string) bool {
+	return concurrentlyRegex.MatchString(lineCommentRegex.ReplaceAllString(sql, ""))
+}
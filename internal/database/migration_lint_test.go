@@ -0,0 +1,70 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintRules(up string) []string {
+	var rules []string
+	for _, issue := range LintMigration(&MigrationFile{Version: "v", Up: up}) {
+		rules = append(rules, issue.Rule)
+	}
+	return rules
+}
+
+func TestLintMigration(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		up    string
+		rules []string
+	}{
+		{"可空列", "ALTER TABLE users ADD COLUMN nickname VARCHAR(50);", nil},
+		{"带默认值的非空列", "ALTER TABLE users ADD COLUMN score INTEGER NOT NULL DEFAULT 0;", nil},
+		{"无默认值的非空列", "ALTER TABLE users ADD COLUMN score INTEGER NOT NULL;", []string{RuleNotNullWithoutDefault}},
+		{"省略 COLUMN 关键字", "alter table users add score integer not null;", []string{RuleNotNullWithoutDefault}},
+		{"非空检查约束不是新列", "ALTER TABLE users ADD CONSTRAINT score_not_null CHECK (score IS NOT NULL) NOT VALID;", nil},
+		{"设置非空", "ALTER TABLE users ALTER COLUMN score SET NOT NULL;", []string{RuleNotNullWithoutDefault}},
+		{"修改类型", "ALTER TABLE users ALTER COLUMN score TYPE BIGINT;", []string{RuleColumnTypeChange}},
+		{"普通索引", "CREATE INDEX idx_users_score ON users(score);", []string{RuleIndexWithoutConcurrency}},
+		{"并发索引", "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS uidx_users_score ON users (score);", nil},
+		{"并发索引与其他语句", "ALTER TABLE users ADD COLUMN score INTEGER;\nCREATE INDEX CONCURRENTLY idx_users_score ON users(score);", []string{RuleConcurrentInBatch}},
+		{"新建表上的操作", "CREATE TABLE orders (id UUID, total INTEGER);\nALTER TABLE orders ADD COLUMN status TEXT NOT NULL;\nCREATE INDEX idx_orders_status ON orders(status);", nil},
+		{"忽略注释", "-- lint:ignore index_without_concurrently\nCREATE INDEX idx_users_score ON users(score);", nil},
+		{"注释中的语句", "-- CREATE INDEX idx_users_score ON users(score);\nSELECT 1;", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.rules, lintRules(tc.up))
+		})
+	}
+}
+
+func TestLintMigration_Templates(t *testing.T) {
+	for _, params := range []struct {
+		template string
+		params   MigrationTemplateParams
+	}{
+		{TemplateCreateTable, MigrationTemplateParams{Table: "orders"}},
+		{TemplateAddColumn, MigrationTemplateParams{Table: "orders", Column: "status"}},
+		{TemplateAddIndex, MigrationTemplateParams{Table: "orders", Columns: []string{"status"}}},
+	} {
+		up, _, err := RenderMigration(params.template, "v", "d", params.params)
+		require.NoError(t, err)
+		assert.Empty(t, LintMigration(&MigrationFile{Version: "v", Up: up}), params.template)
+	}
+}
+
+func TestLintMigration_ExistingMigrations(t *testing.T) {
+	up, err := os.ReadFile(filepath.Join("..", "..", MigrationsDir, "001_create_users_table_up.sql"))
+	require.NoError(t, err)
+	assert.Empty(t, LintMigration(&MigrationFile{Version: "001", Up: string(up)}))
+}
+
+func TestRunsOutsideTransaction(t *testing.T) {
+	assert.True(t, runsOutsideTransaction("CREATE INDEX concurrently idx ON users(score);"))
+	assert.False(t, runsOutsideTransaction("-- Use CONCURRENTLY next time\nCREATE INDEX idx ON users(score);"))
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-server/internal/logger"
+)
+
+// migrationLockID is the pg_advisory_lock key guarding schema migrations.
+// It fits in 32 bits, so pg_locks reports it as classid 0 and objid migrationLockID
+const migrationLockID int64 = 0x6d696772 // "migr"
+
+// DefaultMigrationLockTimeout is used when database.migration_lock_timeout is not set
+const DefaultMigrationLockTimeout = 60 * time.Second
+
+// migrationLockPollInterval is how often a waiting instance retries the lock
+const migrationLockPollInterval = 500 * time.Millisecond
+
+// ErrMigrationLockTimeout is returned when another instance holds the migration lock past the timeout
+var ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+
+// MigrationLockHolder describes a database session holding the migration lock
+type MigrationLockHolder struct {
+	PID             int       `gorm:"column:pid"`
+	ApplicationName string    `gorm:"column:application_name"`
+	ClientAddr      string    `gorm:"column:client_addr"`
+	State           string    `gorm:"column:state"`
+	BackendStart    time.Time `gorm:"column:backend_start"`
+}
+
+// String formats the holder for logs and error messages
+func (h MigrationLockHolder) String() string {
+	client := h.ClientAddr
+	if client == "" {
+		client = "local socket"
+	}
+	return fmt.Sprintf("pid %d (%s) from %s, connected %s, state %s",
+		h.PID, h.ApplicationName, client, h.BackendStart.Format(time.RFC3339), h.State)
+}
+
+// describeHolders joins lock holders into a single message
+func describeHolders(holders []MigrationLockHolder) string {
+	if len(holders) == 0 {
+		return "an unknown session"
+	}
+	descriptions := make([]string, len(holders))
+	for i, holder := range holders {
+		descriptions[i] = holder.String()
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// migrationLockTimeout returns the configured lock timeout
+func (m *Migrator) migrationLockTimeout() time.Duration {
+	if m.config != nil && m.config.MigrationLockTimeout > 0 {
+		return time.Duration(m.config.MigrationLockTimeout) * time.Second
+	}
+	return DefaultMigrationLockTimeout
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock, so that replicas
+// starting at the same time apply migrations one after another instead of racing.
+// Advisory locks belong to a session, so the lock is taken on a dedicated connection
+// that stays checked out of the pool until fn returns
+func (m *Migrator) withMigrationLock(ctx context.Context, fn func() error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.acquireMigrationLock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		// Unlock even if ctx was cancelled; closing the connection would release it too
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			m.logger.Warn(ctx, "Failed to release migration lock", logger.Error(err))
+		}
+	}()
+
+	return fn()
+}
+
+// acquireMigrationLock polls pg_try_advisory_lock until the lock is taken or the timeout expires
+func (m *Migrator) acquireMigrationLock(ctx context.Context, conn *sql.Conn) error {
+	timeout := m.migrationLockTimeout()
+	deadline := time.Now().Add(timeout)
+	waiting := false
+
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired {
+			if waiting {
+				m.logger.Info(ctx, "Acquired migration lock")
+			}
+			return nil
+		}
+
+		if !waiting {
+			holders, _ := m.MigrationLockHolders(ctx)
+			m.logger.Info(ctx, "Waiting for migration lock held by another instance",
+				logger.String("holder", describeHolders(holders)),
+				logger.String("timeout", timeout.String()))
+			waiting = true
+		}
+
+		if time.Now().After(deadline) {
+			holders, _ := m.MigrationLockHolders(ctx)
+			return fmt.Errorf("%w after %s, held by %s; use -force-unlock if that session is stuck",
+				ErrMigrationLockTimeout, timeout, describeHolders(holders))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(migrationLockPollInterval):
+		}
+	}
+}
+
+// MigrationLockHolders returns the sessions currently holding the migration lock
+func (m *Migrator) MigrationLockHolders(ctx context.Context) ([]MigrationLockHolder, error) {
+	var holders []MigrationLockHolder
+	err := m.db.WithContext(ctx).Raw(`
+		SELECT a.pid,
+		       COALESCE(a.application_name, '') AS application_name,
+		       COALESCE(host(a.client_addr), '') AS client_addr,
+		       COALESCE(a.state, '') AS state,
+		       a.backend_start
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.classid = 0 AND l.objid::bigint = ? AND l.objsubid = 1 AND l.granted`,
+		migrationLockID).Scan(&holders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migration lock holders: %w", err)
+	}
+	return holders, nil
+}
+
+// ForceUnlockMigrations terminates the sessions holding the migration lock. It is an escape hatch
+// for a deployment that hung mid-migration with its connection still open; a crashed process
+// releases the lock on its own. It returns the terminated holders
+func (m *Migrator) ForceUnlockMigrations(ctx context.Context) ([]MigrationLockHolder, error) {
+	holders, err := m.MigrationLockHolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, holder := range holders {
+		var terminated bool
+		if err := m.db.WithContext(ctx).Raw("SELECT pg_terminate_backend(?)", holder.PID).Scan(&terminated).Error; err != nil {
+			return nil, fmt.Errorf("failed to terminate session %d: %w", holder.PID, err)
+		}
+		m.logger.Warn(ctx, "Terminated session holding migration lock",
+			logger.String("holder", holder.String()),
+			logger.Bool("terminated", terminated))
+	}
+	return holders, nil
+}
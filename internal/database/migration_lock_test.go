@@ -0,0 +1,30 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrator_MigrationLockTimeout(t *testing.T) {
+	assert.Equal(t, DefaultMigrationLockTimeout, (&Migrator{}).migrationLockTimeout())
+	assert.Equal(t, DefaultMigrationLockTimeout, (&Migrator{config: &config.DatabaseConfig{}}).migrationLockTimeout())
+	assert.Equal(t, 5*time.Second, (&Migrator{config: &config.DatabaseConfig{MigrationLockTimeout: 5}}).migrationLockTimeout())
+}
+
+func TestDescribeHolders(t *testing.T) {
+	assert.Equal(t, "an unknown session", describeHolders(nil))
+
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	holders := []MigrationLockHolder{
+		{PID: 42, ApplicationName: "go-server", ClientAddr: "10.0.0.7", State: "active", BackendStart: started},
+		{PID: 43, State: "idle", BackendStart: started},
+	}
+	assert.Equal(t,
+		"pid 42 (go-server) from 10.0.0.7, connected 2024-01-02T03:04:05Z, state active; "+
+			"pid 43 () from local socket, connected 2024-01-02T03:04:05Z, state idle",
+		describeHolders(holders))
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Migration templates available to CreateMigrationFromTemplate
+const (
+	TemplateBlank       = "blank"
+	TemplateCreateTable = "create_table"
+	TemplateAddColumn   = "add_column"
+	TemplateAddIndex    = "add_index"
+)
+
+// identifierRegex matches the unquoted PostgreSQL identifiers accepted by the templates
+var identifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// MigrationTemplateParams holds the values substituted into a migration template
+type MigrationTemplateParams struct {
+	Table      string   // Table name, required by every template except blank
+	Column     string   // Column name (add_column)
+	ColumnType string   // Column SQL type (add_column), defaults to VARCHAR(255)
+	Columns    []string // Indexed columns (add_index)
+	Unique     bool     // Create a unique index (add_index)
+}
+
+// migrationTemplateData is the data passed to the up and down templates
+type migrationTemplateData struct {
+	MigrationTemplateParams
+	Version     string
+	Description string
+	IndexName   string
+}
+
+// migrationTemplate is a pair of up and down SQL templates
+type migrationTemplate struct {
+	up       *template.Template
+	down     *template.Template
+	validate func(params *MigrationTemplateParams) error
+}
+
+// migrationHeader is the comment block every generated file starts with; extractDescription reads it back
+const migrationHeader = `-- Migration: {{.Version}}_{{.Direction}}
+-- Description: {{.Description}}
+-- Version: {{.Version}}_{{.Direction}}
+`
+
+var migrationTemplates = map[string]migrationTemplate{
+	TemplateBlank: {
+		up: newMigrationTemplate("up", `
+-- Add your UP migration SQL here
+-- Example:
+-- CREATE TABLE example (
+--     id SERIAL PRIMARY KEY,
+--     name VARCHAR(255) NOT NULL,
+--     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+-- );
+`),
+		down: newMigrationTemplate("down", `
+-- Add your DOWN migration SQL here
+-- Example:
+-- DROP TABLE IF EXISTS example;
+`),
+		validate: func(params *MigrationTemplateParams) error { return nil },
+	},
+	TemplateCreateTable: {
+		up: newMigrationTemplate("up", `
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Add columns here
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_deleted_at ON {{.Table}}(deleted_at);
+`),
+		down: newMigrationTemplate("down", `
+DROP INDEX IF EXISTS idx_{{.Table}}_deleted_at;
+DROP TABLE IF EXISTS {{.Table}};
+`),
+		validate: func(params *MigrationTemplateParams) error {
+			return validateIdentifier("table", params.Table)
+		},
+	},
+	TemplateAddColumn: {
+		up: newMigrationTemplate("up", `
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS {{.Column}} {{.ColumnType}};
+`),
+		down: newMigrationTemplate("down", `
+ALTER TABLE {{.Table}} DROP COLUMN IF EXISTS {{.Column}};
+`),
+		validate: func(params *MigrationTemplateParams) error {
+			if err := validateIdentifier("table", params.Table); err != nil {
+				return err
+			}
+			if err := validateIdentifier("column", params.Column); err != nil {
+				return err
+			}
+			if params.ColumnType == "" {
+				params.ColumnType = "VARCHAR(255)"
+			}
+			if strings.ContainsAny(params.ColumnType, ";\n") {
+				return fmt.Errorf("invalid column type %q", params.ColumnType)
+			}
+			return nil
+		},
+	},
+	TemplateAddIndex: {
+		up: newMigrationTemplate("up", `
+CREATE {{if .Unique}}UNIQUE {{end}}INDEX CONCURRENTLY IF NOT EXISTS {{.IndexName}} ON {{.Table}}({{join .Columns ", "}});
+`),
+		down: newMigrationTemplate("down", `
+DROP INDEX CONCURRENTLY IF EXISTS {{.IndexName}};
+`),
+		validate: func(params *MigrationTemplateParams) error {
+			if err := validateIdentifier("table", params.Table); err != nil {
+				return err
+			}
+			if len(params.Columns) == 0 {
+				return fmt.Errorf("at least one column is required")
+			}
+			for _, column := range params.Columns {
+				if err := validateIdentifier("column", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// newMigrationTemplate parses a template body for one direction, prefixed with the migration header
+func newMigrationTemplate(direction, body string) *template.Template {
+	header := strings.ReplaceAll(migrationHeader, "{{.Direction}}", direction)
+	return template.Must(template.New(direction).
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(header + body))
+}
+
+// MigrationTemplates returns the names of the available migration templates
+func MigrationTemplates() []string {
+	names := make([]string, 0, len(migrationTemplates))
+	for name := range migrationTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderMigration renders the up and down SQL of a migration from a template
+func RenderMigration(name, version, description string, params MigrationTemplateParams) (string, string, error) {
+	tmpl, ok := migrationTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown migration template %q, available: %s", name, strings.Join(MigrationTemplates(), ", "))
+	}
+	if err := tmpl.validate(&params); err != nil {
+		return "", "", fmt.Errorf("template %s: %w", name, err)
+	}
+
+	data := migrationTemplateData{
+		MigrationTemplateParams: params,
+		Version:                 version,
+		Description:             strings.TrimSpace(strings.ReplaceAll(description, "\n", " ")),
+	}
+	if name == TemplateAddIndex {
+		data.IndexName = indexName(params.Table, params.Columns, params.Unique)
+	}
+
+	var up, down bytes.Buffer
+	if err := tmpl.up.Execute(&up, data); err != nil {
+		return "", "", fmt.Errorf("failed to render up migration: %w", err)
+	}
+	if err := tmpl.down.Execute(&down, data); err != nil {
+		return "", "", fmt.Errorf("failed to render down migration: %w", err)
+	}
+	return up.String(), down.String(), nil
+}
+
+// indexName follows the idx_<table>_<columns> naming used by the existing migrations
+func indexName(table string, columns []string, unique bool) string {
+	prefix := "idx_"
+	if unique {
+		prefix = "uidx_"
+	}
+	name := prefix + table + "_" + strings.Join(columns, "_")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// validateIdentifier checks that a table or column name is a plain lowercase identifier
+func validateIdentifier(kind, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", kind)
+	}
+	if !identifierRegex.MatchString(value) {
+		return fmt.Errorf("invalid %s name %q, use lowercase letters, digits and underscores", kind, value)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMigration(t *testing.T) {
+	t.Run("create_table", func(t *testing.T) {
+		up, down, err := RenderMigration(TemplateCreateTable, "1700000000_create_orders", "Create orders", MigrationTemplateParams{Table: "orders"})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(up, "-- Migration: 1700000000_create_orders_up\n-- Description: Create orders\n"))
+		assert.Contains(t, up, "CREATE TABLE IF NOT EXISTS orders (")
+		assert.Contains(t, down, "DROP TABLE IF EXISTS orders;")
+	})
+
+	t.Run("add_column 默认类型", func(t *testing.T) {
+		up, down, err := RenderMigration(TemplateAddColumn, "v", "d", MigrationTemplateParams{Table: "orders", Column: "status"})
+		require.NoError(t, err)
+		assert.Contains(t, up, "ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(255);")
+		assert.Contains(t, down, "ALTER TABLE orders DROP COLUMN IF EXISTS status;")
+	})
+
+	t.Run("add_index 唯一索引", func(t *testing.T) {
+		up, down, err := RenderMigration(TemplateAddIndex, "v", "d", MigrationTemplateParams{Table: "orders", Columns: []string{"user_id", "number"}, Unique: true})
+		require.NoError(t, err)
+		assert.Contains(t, up, "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS uidx_orders_user_id_number ON orders(user_id, number);")
+		assert.Contains(t, down, "DROP INDEX CONCURRENTLY IF EXISTS uidx_orders_user_id_number;")
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		for _, tc := range []struct {
+			template string
+			params   MigrationTemplateParams
+		}{
+			{"unknown", MigrationTemplateParams{}},
+			{TemplateCreateTable, MigrationTemplateParams{}},
+			{TemplateCreateTable, MigrationTemplateParams{Table: "orders; DROP TABLE users"}},
+			{TemplateAddColumn, MigrationTemplateParams{Table: "orders", Column: "status", ColumnType: "TEXT; DROP TABLE users"}},
+			{TemplateAddIndex, MigrationTemplateParams{Table: "orders"}},
+		} {
+			_, _, err := RenderMigration(tc.template, "v", "d", tc.params)
+			assert.Error(t, err, tc.template)
+		}
+	})
+}
+
+func TestIndexName_Truncated(t *testing.T) {
+	name := indexName(strings.Repeat("t", 40), []string{strings.Repeat("c", 40)}, false)
+	assert.Len(t, name, 63)
+}
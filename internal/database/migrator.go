@@ -60,83 +60,110 @@ func (m *Migrator) InitializeMigrations() error {
 	return nil
 }
 
-// CreateMigration creates a new migration file
+// CreateMigration creates a new blank migration file
 func (m *Migrator) CreateMigration(name, description string) error {
+	_, err := m.CreateMigrationFromTemplate(name, description, TemplateBlank, MigrationTemplateParams{})
+	return err
+}
+
+// CreateMigrationFromTemplate creates a pair of up/down migration files rendered from a template
+// and returns the new migration version
+func (m *Migrator) CreateMigrationFromTemplate(name, description, templateName string, params MigrationTemplateParams) (string, error) {
 	ctx := context.Background()
 
 	// Generate version from timestamp and name
 	version := fmt.Sprintf("%d_%s", time.Now().Unix(), strings.ToLower(strings.ReplaceAll(name, " ", "_")))
+	if description == "" {
+		description = name
+	}
+
+	upSQL, downSQL, err := RenderMigration(templateName, version, description, params)
+	if err != nil {
+		return "", err
+	}
 
 	// Ensure migrations directory exists
 	if err := os.MkdirAll(MigrationsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create migrations directory: %w", err)
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
 	}
 
-	// Generate migration file content
-	upSQL := fmt.Sprintf(`-- Migration: %s
--- Description: %s
--- Version: %s
-
--- Add your UP migration SQL here
--- Example:
--- CREATE TABLE example (
---     id SERIAL PRIMARY KEY,
---     name VARCHAR(255) NOT NULL,
---     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
--- );
-`, description, description, version)
-
-	downSQL := fmt.Sprintf(`-- Migration: %s
--- Description: %s
--- Version: %s
-
--- Add your DOWN migration SQL here
--- Example:
--- DROP TABLE IF EXISTS example;
-`, description, description, version)
-
-	// Write up migration file
+	// Write up migration file, never overwriting an existing migration
 	upFile := filepath.Join(MigrationsDir, fmt.Sprintf("%s_up.sql", version))
-	if err := os.WriteFile(upFile, []byte(upSQL), 0644); err != nil {
-		return fmt.Errorf("failed to create up migration file: %w", err)
+	if err := writeNewFile(upFile, upSQL); err != nil {
+		return "", fmt.Errorf("failed to create up migration file: %w", err)
 	}
 
 	// Write down migration file
 	downFile := filepath.Join(MigrationsDir, fmt.Sprintf("%s_down.sql", version))
-	if err := os.WriteFile(downFile, []byte(downSQL), 0644); err != nil {
-		return fmt.Errorf("failed to create down migration file: %w", err)
+	if err := writeNewFile(downFile, downSQL); err != nil {
+		os.Remove(upFile)
+		return "", fmt.Errorf("failed to create down migration file: %w", err)
 	}
 
 	m.logger.Info(ctx, "Created migration files",
 		logger.String("version", version),
+		logger.String("template", templateName),
 		logger.String("description", description))
-	return nil
+	return version, nil
+}
+
+// writeNewFile writes a file that must not exist yet
+func writeNewFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
-// RunMigrations runs all pending migrations
+// RunMigrations runs all pending migrations while holding the migration lock
 func (m *Migrator) RunMigrations() error {
+	return m.withMigrationLock(context.Background(), func() error {
+		_, err := m.runPendingMigrations(false)
+		return err
+	})
+}
+
+// RunMigrationsSafely lints the pending migrations with LintMigration and runs them only if
+// no issues are found. Otherwise nothing is applied and the issues are returned with ErrUnsafeMigration
+func (m *Migrator) RunMigrationsSafely() ([]MigrationIssue, error) {
+	var issues []MigrationIssue
+	err := m.withMigrationLock(context.Background(), func() error {
+		var err error
+		issues, err = m.runPendingMigrations(true)
+		return err
+	})
+	return issues, err
+}
+
+// runPendingMigrations runs all pending migrations, the caller must hold the migration lock
+func (m *Migrator) runPendingMigrations(safe bool) ([]MigrationIssue, error) {
 	ctx := context.Background()
 
 	// Initialize migrations system
 	if err := m.InitializeMigrations(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Load migration files
 	migrations, err := m.loadMigrationFiles()
 	if err != nil {
-		return fmt.Errorf("failed to load migration files: %w", err)
+		return nil, fmt.Errorf("failed to load migration files: %w", err)
 	}
 
 	if len(migrations) == 0 {
 		m.logger.Info(ctx, "No migrations to run")
-		return nil
+		return nil, nil
 	}
 
 	// Get applied migrations
 	appliedMigrations, err := m.getAppliedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
 	// Find pending migrations
@@ -144,7 +171,18 @@ func (m *Migrator) RunMigrations() error {
 
 	if len(pendingMigrations) == 0 {
 		m.logger.Info(ctx, "No pending migrations")
-		return nil
+		return nil, nil
+	}
+
+	// Refuse to run anything if a pending migration could lock a large table
+	if safe {
+		var issues []MigrationIssue
+		for _, migration := range pendingMigrations {
+			issues = append(issues, LintMigration(migration)...)
+		}
+		if len(issues) > 0 {
+			return issues, fmt.Errorf("%w: %d issue(s)", ErrUnsafeMigration, len(issues))
+		}
 	}
 
 	// Generate batch ID for this migration run
@@ -153,18 +191,25 @@ func (m *Migrator) RunMigrations() error {
 	// Run pending migrations in order
 	for _, migration := range pendingMigrations {
 		if err := m.runMigration(migration, batchID); err != nil {
-			return fmt.Errorf("failed to run migration %s: %w", migration.Version, err)
+			return nil, fmt.Errorf("failed to run migration %s: %w", migration.Version, err)
 		}
 	}
 
 	m.logger.Info(ctx, "Successfully ran migrations",
 		logger.Int("count", len(pendingMigrations)),
 		logger.String("batch_id", batchID.String()))
-	return nil
+	return nil, nil
 }
 
-// Rollback rolls back the last batch of migrations
+// Rollback rolls back the last batch of migrations while holding the migration lock
 func (m *Migrator) Rollback(batchID string) error {
+	return m.withMigrationLock(context.Background(), func() error {
+		return m.rollbackBatch(batchID)
+	})
+}
+
+// rollbackBatch rolls back a batch of migrations, the caller must hold the migration lock
+func (m *Migrator) rollbackBatch(batchID string) error {
 	ctx := context.Background()
 
 	// Get applied migrations for the specified batch
@@ -192,6 +237,57 @@ func (m *Migrator) Rollback(batchID string) error {
 	return nil
 }
 
+// Redo rolls back the most recently applied migration and applies it again from its file,
+// so that edits to a migration under development can be re-run. It returns the redone version
+func (m *Migrator) Redo() (string, error) {
+	var version string
+	err := m.withMigrationLock(context.Background(), func() error {
+		var err error
+		version, err = m.redoLatest()
+		return err
+	})
+	return version, err
+}
+
+// redoLatest rolls back and reapplies the latest migration, the caller must hold the migration lock
+func (m *Migrator) redoLatest() (string, error) {
+	ctx := context.Background()
+
+	var latest Migration
+	if err := m.db.Order("applied_at DESC").First(&latest).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get latest migration: %w", err)
+	}
+
+	// Load the file before rolling back so a missing file doesn't leave the migration unapplied
+	files, err := m.loadMigrationFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to load migration files: %w", err)
+	}
+	var file *MigrationFile
+	for _, candidate := range files {
+		if candidate.Version == latest.Version {
+			file = candidate
+			break
+		}
+	}
+	if file == nil {
+		return "", fmt.Errorf("migration file for %s not found in %s", latest.Version, MigrationsDir)
+	}
+
+	if err := m.rollbackMigration(&latest); err != nil {
+		return "", fmt.Errorf("failed to rollback migration %s: %w", latest.Version, err)
+	}
+	if err := m.runMigration(file, latest.BatchID); err != nil {
+		return "", fmt.Errorf("failed to reapply migration %s: %w", latest.Version, err)
+	}
+
+	m.logger.Info(ctx, "Successfully redid migration", logger.String("version", latest.Version))
+	return latest.Version, nil
+}
+
 // GetMigrationStatus returns the current migration status
 func (m *Migrator) GetMigrationStatus() ([]Migration, error) {
 	var migrations []Migration
@@ -202,6 +298,32 @@ func (m *Migrator) GetMigrationStatus() ([]Migration, error) {
 	return migrations, nil
 }
 
+// PendingMigrations returns the versions of migration files that haven't been applied yet.
+// Unlike RunMigrations it doesn't create the migrations directory or table when they are missing
+func (m *Migrator) PendingMigrations() ([]string, error) {
+	if _, err := os.Stat(MigrationsDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	files, err := m.loadMigrationFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]bool{}
+	if m.db.Migrator().HasTable(&Migration{}) {
+		if applied, err = m.getAppliedMigrations(); err != nil {
+			return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+	}
+
+	var versions []string
+	for _, file := range m.getPendingMigrations(files, applied) {
+		versions = append(versions, file.Version)
+	}
+	return versions, nil
+}
+
 // loadMigrationFiles loads migration files from the migrations directory
 func (m *Migrator) loadMigrationFiles() ([]*MigrationFile, error) {
 	ctx := context.Background()
@@ -297,6 +419,29 @@ func (m *Migrator) runMigration(file *MigrationFile, batchID uuid.UUID) error {
 
 	m.logger.Info(ctx, "Running migration", logger.String("version", file.Version))
 
+	// Record migration
+	migration := Migration{
+		Version:     file.Version,
+		Description: extractDescription(file.Up),
+		Up:          file.Up,
+		Down:        file.Down,
+		BatchID:     batchID,
+		AppliedAt:   time.Now(),
+	}
+
+	// CREATE INDEX CONCURRENTLY can't run inside a transaction, so such migrations
+	// are executed on their own and recorded afterwards
+	if runsOutsideTransaction(file.Up) {
+		if err := m.db.Exec(file.Up).Error; err != nil {
+			return fmt.Errorf("failed to execute up migration: %w", err)
+		}
+		if err := m.db.Create(&migration).Error; err != nil {
+			return fmt.Errorf("failed to record migration: %w", err)
+		}
+		m.logger.Info(ctx, "Migration completed successfully", logger.String("version", file.Version))
+		return nil
+	}
+
 	// Start transaction
 	tx := m.db.Begin()
 	if tx.Error != nil {
@@ -309,16 +454,6 @@ func (m *Migrator) runMigration(file *MigrationFile, batchID uuid.UUID) error {
 		return fmt.Errorf("failed to execute up migration: %w", err)
 	}
 
-	// Record migration
-	migration := Migration{
-		Version:     file.Version,
-		Description: extractDescription(file.Up),
-		Up:          file.Up,
-		Down:        file.Down,
-		BatchID:     batchID,
-		AppliedAt:   time.Now(),
-	}
-
 	if err := tx.Create(&migration).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to record migration: %w", err)
@@ -339,6 +474,18 @@ func (m *Migrator) rollbackMigration(migration *Migration) error {
 
 	m.logger.Info(ctx, "Rolling back migration", logger.String("version", migration.Version))
 
+	// DROP INDEX CONCURRENTLY can't run inside a transaction either
+	if runsOutsideTransaction(migration.Down) {
+		if err := m.db.Exec(migration.Down).Error; err != nil {
+			return fmt.Errorf("failed to execute down migration: %w", err)
+		}
+		if err := m.db.Delete(&Migration{}, "version = ?", migration.Version).Error; err != nil {
+			return fmt.Errorf("failed to remove migration record: %w", err)
+		}
+		m.logger.Info(ctx, "Migration rolled back successfully", logger.String("version", migration.Version))
+		return nil
+	}
+
 	// Start transaction
 	tx := m.db.Begin()
 	if tx.Error != nil {
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+
+	"gorm.io/gorm"
+)
+
+// queryStartTimeKey 查询开始时间在GORM实例中的键名
+const queryStartTimeKey = "query_monitor:start_time"
+
+// QueryObserver 查询完成后的观察回调
+type QueryObserver func(duration time.Duration, query string, vars []interface{}, err error, rowsAffected int64)
+
+// QueryMonitorPlugin GORM查询监控插件
+// 记录每条SQL的耗时直方图，并对超过阈值的慢查询输出带关联ID的告警日志
+type QueryMonitorPlugin struct {
+	threshold time.Duration            // 慢查询阈值
+	logger    logger.Logger            // 日志记录器
+	metrics   *metrics.DatabaseMetrics // 查询指标
+	observers []QueryObserver          // 额外的观察回调
+}
+
+// NewQueryMonitorPlugin 创建查询监控插件
+func NewQueryMonitorPlugin(threshold time.Duration, log logger.Logger, dbMetrics *metrics.DatabaseMetrics) *QueryMonitorPlugin {
+	if threshold <= 0 {
+		threshold = SlowQueryThreshold
+	}
+	if dbMetrics == nil {
+		dbMetrics = metrics.NewDatabaseMetrics()
+	}
+
+	// 慢查询日志由插件统一输出，避免重复记录
+	dbMetrics.SetSlowQueryThreshold(threshold)
+	dbMetrics.SetSlowQueryLogging(false)
+
+	return &QueryMonitorPlugin{
+		threshold: threshold,
+		logger:    log,
+		metrics:   dbMetrics,
+	}
+}
+
+// AddObserver 添加查询观察回调，必须在插件注册前调用
+func (p *QueryMonitorPlugin) AddObserver(observer QueryObserver) {
+	p.observers = append(p.observers, observer)
+}
+
+// Threshold 返回慢查询阈值
+func (p *QueryMonitorPlugin) Threshold() time.Duration {
+	return p.threshold
+}
+
+// Metrics 返回插件记录的查询指标
+func (p *QueryMonitorPlugin) Metrics() *metrics.DatabaseMetrics {
+	return p.metrics
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *QueryMonitorPlugin) Name() string {
+	return "query_monitor"
+}
+
+// Initialize 实现 gorm.Plugin 接口，向各类操作注册前后回调
+func (p *QueryMonitorPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	// 查询操作
+	if err := callback.Query().Before("gorm:query").Register("query_monitor:query_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("query_monitor:query_after", p.after(metrics.QueryTypeSelect)); err != nil {
+		return err
+	}
+
+	// 创建操作
+	if err := callback.Create().Before("gorm:create").Register("query_monitor:create_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("query_monitor:create_after", p.after(metrics.QueryTypeInsert)); err != nil {
+		return err
+	}
+
+	// 更新操作
+	if err := callback.Update().Before("gorm:update").Register("query_monitor:update_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("query_monitor:update_after", p.after(metrics.QueryTypeUpdate)); err != nil {
+		return err
+	}
+
+	// 删除操作
+	if err := callback.Delete().Before("gorm:delete").Register("query_monitor:delete_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("query_monitor:delete_after", p.after(metrics.QueryTypeDelete)); err != nil {
+		return err
+	}
+
+	// 行查询操作
+	if err := callback.Row().Before("gorm:row").Register("query_monitor:row_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("query_monitor:row_after", p.after(metrics.QueryTypeSelect)); err != nil {
+		return err
+	}
+
+	// 原始SQL操作，类型由语句推断
+	if err := callback.Raw().Before("gorm:raw").Register("query_monitor:raw_before", p.before); err != nil {
+		return err
+	}
+	if err := callback.Raw().After("gorm:raw").Register("query_monitor:raw_after", p.after(-1)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// before 记录查询开始时间
+func (p *QueryMonitorPlugin) before(db *gorm.DB) {
+	db.InstanceSet(queryStartTimeKey, time.Now())
+}
+
+// after 返回记录查询耗时的回调，queryType 为负数时根据SQL推断类型
+func (p *QueryMonitorPlugin) after(queryType metrics.QueryType) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		startTime, ok := db.InstanceGet(queryStartTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := startTime.(time.Time)
+		if !ok {
+			return
+		}
+
+		duration := time.Since(start)
+		query := db.Statement.SQL.String()
+
+		qt := queryType
+		if qt < 0 {
+			qt = classifyQuery(query)
+		}
+
+		// gorm.ErrRecordNotFound 属于正常业务结果，不计为失败查询
+		err := db.Error
+		if err == gorm.ErrRecordNotFound {
+			err = nil
+		}
+
+		p.metrics.RecordQuery(qt, query, duration, err == nil, db.RowsAffected, nil, err)
+
+		for _, observer := range p.observers {
+			observer(duration, query, db.Statement.Vars, err, db.RowsAffected)
+		}
+
+		if duration > p.threshold {
+			p.logSlowQuery(db.Statement.Context, duration, query, err, db.RowsAffected)
+		}
+	}
+}
+
+// logSlowQuery 输出慢查询日志，关联ID从语句上下文中获取
+func (p *QueryMonitorPlugin) logSlowQuery(ctx context.Context, duration time.Duration, query string, err error, rowsAffected int64) {
+	if p.logger == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cleanQuery := strings.TrimSpace(query)
+	if cleanQuery == "" {
+		cleanQuery = "未知查询"
+	}
+
+	fields := []logger.Field{
+		logger.String("duration", duration.String()),
+		logger.Int64("duration_ms", duration.Milliseconds()),
+		logger.String("threshold", p.threshold.String()),
+		logger.String("query", cleanQuery),
+		logger.Int64("rows_affected", rowsAffected),
+	}
+	if err != nil {
+		fields = append(fields, logger.Error(err))
+	}
+
+	p.logger.Warn(ctx, "检测到数据库慢查询", fields...)
+}
+
+// classifyQuery 根据SQL语句推断查询类型
+func classifyQuery(query string) metrics.QueryType {
+	trimmed := strings.TrimSpace(query)
+	if idx := strings.IndexAny(trimmed, " \t\n"); idx > 0 {
+		trimmed = trimmed[:idx]
+	}
+
+	switch strings.ToUpper(trimmed) {
+	case "SELECT", "WITH":
+		return metrics.QueryTypeSelect
+	case "INSERT":
+		return metrics.QueryTypeInsert
+	case "UPDATE":
+		return metrics.QueryTypeUpdate
+	case "DELETE":
+		return metrics.QueryTypeDelete
+	case "CREATE", "ALTER", "DROP", "TRUNCATE":
+		return metrics.QueryTypeDDL
+	default:
+		return metrics.QueryTypeOther
+	}
+}
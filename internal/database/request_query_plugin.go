@@ -0,0 +1,101 @@
+package database
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"go-server/internal/metrics"
+	"go-server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// maxRequestQueryStackFrames 告警堆栈保留的应用调用帧数
+const maxRequestQueryStackFrames = 16
+
+// RequestQueryPlugin GORM请求查询计数插件
+// 按语句上下文中的关联ID统计每个请求执行的SQL，用于发现单个请求查询过多和 N+1 查询
+type RequestQueryPlugin struct {
+	metrics *metrics.RequestQueryMetrics
+}
+
+// NewRequestQueryPlugin 创建请求查询计数插件
+func NewRequestQueryPlugin(requestQueries *metrics.RequestQueryMetrics) *RequestQueryPlugin {
+	return &RequestQueryPlugin{metrics: requestQueries}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *RequestQueryPlugin) Name() string {
+	return "request_queries"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在各类操作完成后计数
+func (p *RequestQueryPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Query().After("gorm:query").Register("request_queries:query", p.record); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("request_queries:create", p.record); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("request_queries:update", p.record); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("request_queries:delete", p.record); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("request_queries:row", p.record); err != nil {
+		return err
+	}
+	return callback.Raw().After("gorm:raw").Register("request_queries:raw", p.record)
+}
+
+// record 将语句计入关联ID对应的请求，不在请求中执行的语句忽略
+func (p *RequestQueryPlugin) record(db *gorm.DB) {
+	if db.Statement == nil || db.Statement.Context == nil {
+		return
+	}
+	correlationID := utils.CorrelationIDFromContext(db.Statement.Context)
+	if correlationID == "" {
+		return
+	}
+	p.metrics.Record(correlationID, db.Statement.SQL.String(), applicationStack)
+}
+
+// applicationStack 返回发出语句的应用调用栈，跳过 GORM、运行时和本插件的帧
+func applicationStack() []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]string, 0, maxRequestQueryStackFrames)
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame.Function) {
+			stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+			if len(stack) == maxRequestQueryStackFrames {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// isInternalFrame 判断调用帧是否属于 GORM、标准库或本插件
+func isInternalFrame(function string) bool {
+	switch {
+	case strings.HasPrefix(function, "gorm.io/"),
+		strings.HasPrefix(function, "runtime."),
+		strings.HasPrefix(function, "database/sql."),
+		strings.HasPrefix(function, "go-server/internal/database.(*RequestQueryPlugin)"),
+		strings.HasPrefix(function, "go-server/internal/database.applicationStack"),
+		strings.HasPrefix(function, "go-server/internal/metrics."):
+		return true
+	}
+	return false
+}
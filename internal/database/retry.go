@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultRetryPolicy is used when database.retry is not configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// RetryPolicy controls how Retry retries transient database errors
+type RetryPolicy struct {
+	MaxAttempts    int           // attempts including the first one; 1 or less disables retries
+	InitialBackoff time.Duration // backoff before the first retry, doubled for every further retry
+	MaxBackoff     time.Duration // upper bound of a single backoff, also sent to clients as the Retry-After hint
+}
+
+// NewRetryPolicy builds a retry policy from database.retry. A disabled config still classifies
+// transient errors, so clients get a Retry-After hint, but never retries
+func NewRetryPolicy(cfg config.DatabaseRetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.InitialBackoff) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.MaxBackoff) * time.Millisecond,
+	}
+	if !cfg.Enabled {
+		policy.MaxAttempts = 1
+	}
+	return policy
+}
+
+// RetryPolicy returns the retry policy configured in database.retry
+func (d *Database) RetryPolicy() RetryPolicy {
+	if d.config == nil {
+		return DefaultRetryPolicy
+	}
+	return NewRetryPolicy(d.config.Retry)
+}
+
+// backoff returns the jittered wait before retry number n (starting at 1): a random duration
+// between half and all of the exponential backoff, so concurrent retries of a deadlock spread out
+func (p RetryPolicy) backoff(n int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < n && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// TransientError is returned by Retry when a retryable error persists after the last attempt.
+// It implements pkg/errors.TransientError, so handlers answer it with 503 and a Retry-After header
+type TransientError struct {
+	Err        error         // error of the last attempt
+	Attempts   int           // number of attempts made
+	retryAfter time.Duration // suggested wait before the client retries
+}
+
+// Error implements error
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("transient database error after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the suggested wait before the client retries
+func (e *TransientError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not retryable or runs out of attempts,
+// waiting a jittered exponential backoff between attempts. The wait stops early when ctx is done.
+// A retryable error that persists is returned as a *TransientError.
+//
+// fn must be safe to run again after a failure: a serialization failure or deadlock rolls back the
+// whole transaction, so fn should run the complete transaction rather than a statement inside one.
+// A connection reset may hide a committed write; an insert retried with the same primary key then fails
+// with a unique violation instead of writing twice
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		transient := &TransientError{Err: err, Attempts: attempt, retryAfter: policy.MaxBackoff}
+		if attempt >= attempts {
+			return transient
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return transient
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableSQLStates are the PostgreSQL error codes, besides the connection exception class 08,
+// after which the same statement or transaction may succeed
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"55P03": true, // lock_not_available
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+	"53300": true, // too_many_connections
+}
+
+// IsRetryable reports whether err is a transient database error: a serialization failure, a deadlock,
+// or a lost or refused connection. Context cancellation and errors already returned by Retry are not
+// retryable, so nested retries do not multiply
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var transient *TransientError
+	if errors.As(err, &transient) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return retryableSQLStates[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// pgx marks errors raised before anything was sent to the server as safe to retry
+	var opErr *net.OpError
+	return pgconn.SafeToRetry(err) || errors.As(err, &opErr)
+}
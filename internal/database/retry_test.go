@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	apperrors "go-server/pkg/errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fastRetryPolicy keeps backoffs short so the tests do not sleep
+var fastRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", fmt.Errorf("failed to update user: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"bad connection", driver.ErrBadConn, true},
+		{"record not found", gorm.ErrRecordNotFound, false},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"already retried", &TransientError{Err: &pgconn.PgError{Code: "40001"}, Attempts: 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetry_SucceedsAfterTransientErrors(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastRetryPolicy, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_ExhaustedReturnsTransientError(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastRetryPolicy, func(ctx context.Context) error {
+		calls++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	assert.Equal(t, 3, calls)
+
+	var transient *TransientError
+	require.ErrorAs(t, err, &transient)
+	assert.Equal(t, 3, transient.Attempts)
+
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr, "the last attempt's error stays in the chain")
+	assert.Equal(t, "40P01", pgErr.Code)
+
+	retryAfter, ok := apperrors.RetryAfterHint(fmt.Errorf("failed to update user: %w", err))
+	require.True(t, ok)
+	assert.Equal(t, fastRetryPolicy.MaxBackoff, retryAfter)
+}
+
+func TestRetry_PermanentErrorIsNotRetried(t *testing.T) {
+	calls := 0
+	permanent := &pgconn.PgError{Code: "23505"}
+	err := Retry(context.Background(), fastRetryPolicy, func(ctx context.Context) error {
+		calls++
+		return permanent
+	})
+	assert.Equal(t, 1, calls)
+	assert.Same(t, permanent, err)
+
+	_, ok := apperrors.RetryAfterHint(err)
+	assert.False(t, ok)
+}
+
+func TestRetry_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(ctx, policy, func(ctx context.Context) error {
+			calls++
+			return driver.ErrBadConn
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		var transient *TransientError
+		require.ErrorAs(t, err, &transient)
+		assert.Equal(t, 1, calls)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Retry kept waiting after the context was canceled")
+	}
+}
+
+func TestRetry_DisabledStillClassifies(t *testing.T) {
+	policy := NewRetryPolicy(config.DatabaseRetryConfig{Enabled: false, MaxAttempts: 5, InitialBackoff: 50, MaxBackoff: 1000})
+	assert.Equal(t, 1, policy.MaxAttempts)
+
+	calls := 0
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return &pgconn.PgError{Code: "40001"}
+	})
+	assert.Equal(t, 1, calls)
+	assert.True(t, errors.As(err, new(*TransientError)))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for i := 0; i < 100; i++ {
+		first := policy.backoff(1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		third := policy.backoff(3)
+		assert.GreaterOrEqual(t, third, 200*time.Millisecond)
+		assert.LessOrEqual(t, third, 400*time.Millisecond)
+
+		capped := policy.backoff(8)
+		assert.GreaterOrEqual(t, capped, 500*time.Millisecond)
+		assert.LessOrEqual(t, capped, time.Second)
+	}
+}
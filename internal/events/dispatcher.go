@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed 分发器关闭后继续发出事件时记录的错误
+var ErrClosed = errors.New("events: dispatcher is closed")
+
+// Handler 处理领域事件，返回的错误只记录日志，不会重试
+type Handler func(ctx context.Context, event Event) error
+
+// 默认的工作协程数和队列长度
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 1024
+)
+
+// envelope 队列中的事件及其上下文
+type envelope struct {
+	ctx   context.Context
+	event Event
+}
+
+// Dispatcher 进程内的领域事件分发器
+// Emit 将事件放入有界队列后立即返回，工作协程按注册顺序调用订阅者；同一事件的订阅者依次执行，
+// 不同事件之间并发处理，不保证顺序。队列已满时丢弃事件并记录日志，避免订阅者拖慢请求
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	all      []Handler
+	closed   bool
+
+	queue   chan envelope
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+}
+
+// NewDispatcher 创建分发器并启动工作协程，workers 和 queueSize <=0 时使用默认值
+func NewDispatcher(workers, queueSize int) *Dispatcher {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	d := &Dispatcher{
+		handlers: make(map[string][]Handler),
+		queue:    make(chan envelope, queueSize),
+	}
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Subscribe 订阅指定类型的事件
+func (d *Dispatcher) Subscribe(eventName string, handler Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventName] = append(d.handlers[eventName], handler)
+}
+
+// SubscribeAll 订阅所有类型的事件，适用于审计、转发等通用订阅者
+func (d *Dispatcher) SubscribeAll(handler Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.all = append(d.all, handler)
+}
+
+// On 以具体的事件类型订阅，事件类型由 T 的 EventName 确定
+func On[T Event](d *Dispatcher, handler func(ctx context.Context, event T) error) {
+	var zero T
+	d.Subscribe(zero.EventName(), func(ctx context.Context, event Event) error {
+		typed, ok := event.(T)
+		if !ok {
+			return nil
+		}
+		return handler(ctx, typed)
+	})
+}
+
+// Emit 将事件放入队列异步分发，订阅者收到的上下文保留请求上下文中的值但不随请求取消
+func (d *Dispatcher) Emit(ctx context.Context, event Event) {
+	if event == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		log.Printf("events: dropping %s: %v", event.EventName(), ErrClosed)
+		return
+	}
+
+	select {
+	case d.queue <- envelope{ctx: context.WithoutCancel(ctx), event: event}:
+	default:
+		d.dropped.Add(1)
+		log.Printf("events: queue is full, dropping %s", event.EventName())
+	}
+}
+
+// Dropped 返回因队列已满而丢弃的事件数
+func (d *Dispatcher) Dropped() int64 {
+	return d.dropped.Load()
+}
+
+// Close 停止接收新事件，等待队列中的事件分发完成或 ctx 到期
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work 从队列中取出事件并分发
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for item := range d.queue {
+		d.dispatch(item.ctx, item.event)
+	}
+}
+
+// dispatch 依次调用事件的订阅者
+func (d *Dispatcher) dispatch(ctx context.Context, event Event) {
+	d.mu.RLock()
+	handlers := make([]Handler, 0, len(d.handlers[event.EventName()])+len(d.all))
+	handlers = append(handlers, d.handlers[event.EventName()]...)
+	handlers = append(handlers, d.all...)
+	d.mu.RUnlock()
+
+	for _, handler := range handlers {
+		d.invoke(ctx, handler, event)
+	}
+}
+
+// invoke 调用订阅者，订阅者的错误和 panic 不影响其他订阅者
+func (d *Dispatcher) invoke(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("events: handler for %s panicked: %v\n%s", event.EventName(), r, debug.Stack())
+		}
+	}()
+
+	if err := handler(ctx, event); err != nil {
+		log.Printf("events: handler for %s failed: %v", event.EventName(), err)
+	}
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type correlationKey struct{}
+
+func TestDispatcher_DispatchesToSubscribers(t *testing.T) {
+	dispatcher := NewDispatcher(2, 16)
+
+	var (
+		mu      sync.Mutex
+		deleted []UserDeleted
+		names   []string
+	)
+	On(dispatcher, func(ctx context.Context, event UserDeleted) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deleted = append(deleted, event)
+		assert.Equal(t, "req-1", ctx.Value(correlationKey{}))
+		return nil
+	})
+	dispatcher.SubscribeAll(func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, event.EventName())
+		return nil
+	})
+
+	// 请求结束后上下文被取消，订阅者仍能读取其中的值
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), correlationKey{}, "req-1"))
+	dispatcher.Emit(ctx, UserDeleted{UserID: "u1"})
+	dispatcher.Emit(ctx, UserLoggedIn{UserID: "u2"})
+	cancel()
+
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	assert.Equal(t, []UserDeleted{{UserID: "u1"}}, deleted)
+	assert.ElementsMatch(t, []string{EventUserDeleted, EventUserLoggedIn}, names)
+}
+
+func TestDispatcher_IsolatesFailingHandlers(t *testing.T) {
+	dispatcher := NewDispatcher(1, 16)
+
+	var calls int
+	dispatcher.Subscribe(EventUserCreated, func(ctx context.Context, event Event) error {
+		panic("boom")
+	})
+	dispatcher.Subscribe(EventUserCreated, func(ctx context.Context, event Event) error {
+		return errors.New("failed")
+	})
+	dispatcher.Subscribe(EventUserCreated, func(ctx context.Context, event Event) error {
+		calls++
+		return nil
+	})
+
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u1"})
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u2"})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestDispatcher_DropsWhenFullOrClosed(t *testing.T) {
+	dispatcher := NewDispatcher(1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	dispatcher.Subscribe(EventUserCreated, func(ctx context.Context, event Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u1"})
+	<-started
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u2"}) // 占满队列
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u3"}) // 丢弃
+	assert.Equal(t, int64(1), dispatcher.Dropped())
+
+	// 订阅者仍在处理时关闭超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, dispatcher.Close(ctx), context.DeadlineExceeded)
+
+	close(release)
+	<-started
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	// 关闭后发出的事件被丢弃
+	dispatcher.Emit(context.Background(), UserCreated{UserID: "u4"})
+}
@@ -0,0 +1,93 @@
+// Package events 用户生命周期的领域事件
+//
+// 用户服务通过 Emitter 接口发出类型化的领域事件，订阅者在 Dispatcher 上按事件类型注册，
+// 事件由后台工作协程异步分发。审计、Webhook、缓存预热等功能只订阅事件，不需要修改服务代码：
+//
+//	events.On(dispatcher, func(ctx context.Context, e events.UserDeleted) error { ... })
+//
+// 领域事件只在进程内分发；需要投递给其他服务的集成事件仍通过 pkg/events 的消息总线发布。
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// 领域事件类型
+const (
+	EventUserCreated  = "user.created"
+	EventUserUpdated  = "user.updated"
+	EventUserDeleted  = "user.deleted"
+	EventUserLoggedIn = "user.logged_in"
+)
+
+// 用户资料中可能被修改的字段，用于 UserUpdated.Fields
+const (
+	FieldUsername  = "username"
+	FieldFirstName = "first_name"
+	FieldLastName  = "last_name"
+	FieldAvatar    = "avatar"
+	FieldEmail     = "email"
+	FieldPassword  = "password"
+	FieldIsActive  = "is_active"
+	FieldRoles     = "roles"
+)
+
+// Event 领域事件
+type Event interface {
+	// EventName 返回事件类型，如 user.created
+	EventName() string
+}
+
+// UserCreated 用户注册成功
+type UserCreated struct {
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventName 实现 Event 接口
+func (UserCreated) EventName() string { return EventUserCreated }
+
+// UserUpdated 用户资料、密码、状态或角色被修改
+type UserUpdated struct {
+	UserID     string    `json:"user_id"`
+	ActorID    string    `json:"actor_id,omitempty"` // 执行修改的用户ID，用户本人修改或未知时为空
+	Fields     []string  `json:"fields"`             // 被修改的字段，见 Field* 常量
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventName 实现 Event 接口
+func (UserUpdated) EventName() string { return EventUserUpdated }
+
+// UserDeleted 用户被删除
+type UserDeleted struct {
+	UserID     string    `json:"user_id"`
+	ActorID    string    `json:"actor_id,omitempty"` // 执行删除的用户ID
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventName 实现 Event 接口
+func (UserDeleted) EventName() string { return EventUserDeleted }
+
+// UserLoggedIn 用户登录成功
+type UserLoggedIn struct {
+	UserID     string    `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventName 实现 Event 接口
+func (UserLoggedIn) EventName() string { return EventUserLoggedIn }
+
+// Emitter 领域事件发出接口，服务只依赖该接口
+type Emitter interface {
+	// Emit 发出事件，不等待订阅者处理完成，也不返回订阅者的错误
+	Emit(ctx context.Context, event Event)
+}
+
+// NopEmitter 丢弃所有事件
+type NopEmitter struct{}
+
+// Emit 丢弃事件
+func (NopEmitter) Emit(context.Context, Event) {}
@@ -0,0 +1,85 @@
+package exports
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/storage"
+)
+
+// DownloadPath 下载接口的路径前缀，完整路径为 /api/v1/exports/{id}/download
+const DownloadPath = "/api/v1/exports/"
+
+var (
+	// ErrInvalidSignature 下载链接的签名不正确
+	ErrInvalidSignature = errors.New("invalid export download signature")
+	// ErrLinkExpired 下载链接已过期
+	ErrLinkExpired = errors.New("export download link has expired")
+)
+
+// DownloadURL 签发已完成导出的下载链接，有效期为 URLTTL，且不晚于文件的删除时间
+func (s *Service) DownloadURL(export *models.Export) string {
+	expires := s.now().Add(s.config.URLTTL)
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expires) {
+		expires = *export.ExpiresAt
+	}
+	unix := expires.Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(unix, 10)},
+		"signature": {s.sign(export.ID, unix)},
+	}
+	return DownloadPath + export.ID + "/download?" + query.Encode()
+}
+
+// Open 校验下载链接并打开导出文件，调用方负责关闭返回的 ReadCloser
+// 下载不需要身份验证，签名即授权，因此跨租户读取任务记录
+func (s *Service) Open(ctx context.Context, id, expires, signature string) (io.ReadCloser, *models.Export, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.sign(id, unix))) {
+		return nil, nil, ErrInvalidSignature
+	}
+	if s.now().Unix() > unix {
+		return nil, nil, ErrLinkExpired
+	}
+
+	export, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrExportNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != models.ExportStatusCompleted {
+		return nil, nil, ErrNotFound
+	}
+
+	reader, _, err := s.store.Get(ctx, export.ObjectKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	return reader, export, nil
+}
+
+// FileName 返回下载时建议的文件名，如 users-20260102-030405.csv
+func FileName(export *models.Export) string {
+	return "users-" + export.CreatedAt.UTC().Format("20060102-150405") + "." + export.Format
+}
+
+// sign 计算导出任务ID和过期时间的 HMAC-SHA256 签名
+func (s *Service) sign(id string, expires int64) string {
+	mac := hmac.New(sha256.New, s.config.SigningKey)
+	mac.Write([]byte(id + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,392 @@
+// Package exports 在后台生成用户数据导出文件
+//
+// 导出任务记录在 exports 表中，由接收请求的实例在后台按主键分批读取用户，以流式方式写入 CSV、JSON 或 XLSX 文件并上传到对象存储，
+// 不在内存中保留整个文件；进度按批写入数据库，任何实例都可以查询。文件只能通过带 HMAC 签名、限时有效的链接下载，
+// 到达保留期限后由定期清理删除文件和任务记录。
+package exports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/storage"
+)
+
+// 默认配置
+const (
+	DefaultBatchSize     = 1000
+	DefaultMaxRunning    = 2
+	DefaultRetention     = 24 * time.Hour
+	DefaultURLTTL        = 15 * time.Minute
+	DefaultPurgeInterval = 10 * time.Minute
+)
+
+// KeyPrefix 导出文件在对象存储中的键前缀，本地驱动不公开提供该前缀下的文件
+const KeyPrefix = "exports"
+
+// finishTimeout 任务结束或被中断时记录结果的超时时间
+const finishTimeout = 10 * time.Second
+
+// purgeBatch 每次清理读取的过期任务数
+const purgeBatch = 100
+
+var (
+	// ErrNotFound 导出任务不存在
+	ErrNotFound = errors.New("export not found")
+	// ErrUnsupportedFormat 不支持的文件格式
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+	// ErrTooManyRows 导出的行数超过格式的上限
+	ErrTooManyRows = errors.New("too many rows for the export format")
+	// ErrTooManyRunning 本实例正在执行的导出任务已达上限
+	ErrTooManyRunning = errors.New("too many exports in progress")
+	// ErrClosed 服务已停止，不再接受新任务
+	ErrClosed = errors.New("export service is stopped")
+)
+
+// contentTypes 各格式的内容类型
+var contentTypes = map[string]string{
+	models.ExportFormatCSV:  "text/csv; charset=utf-8",
+	models.ExportFormatJSON: "application/json",
+	models.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ContentType 返回格式的内容类型
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// UserSource 导出的用户数据来源，用户仓库实现了该接口
+type UserSource interface {
+	// Search 按条件分页查询用户，导出只使用返回的总数
+	Search(ctx context.Context, filter repositories.UserFilter, offset, limit int) ([]*models.User, int64, error)
+	// ScanUsers 按主键顺序返回 afterID 之后的一批用户
+	ScanUsers(ctx context.Context, filter repositories.UserFilter, afterID string, limit int) ([]*models.User, error)
+}
+
+// Config 导出服务配置
+type Config struct {
+	// BatchSize 每批读取的用户数，也是进度更新的粒度，<=0 时使用 DefaultBatchSize
+	BatchSize int
+	// MaxRunning 本实例同时执行的导出任务数，<=0 时使用 DefaultMaxRunning
+	MaxRunning int
+	// Retention 导出文件的保留时间，<=0 时使用 DefaultRetention
+	Retention time.Duration
+	// URLTTL 下载链接的有效期，不超过文件的保留期限，<=0 时使用 DefaultURLTTL
+	URLTTL time.Duration
+	// PurgeInterval 清理过期文件的间隔，<=0 时使用 DefaultPurgeInterval
+	PurgeInterval time.Duration
+	// SigningKey 下载链接的 HMAC 密钥，多个实例须使用相同的密钥
+	SigningKey []byte
+	// OnError 更新进度、清理等后台步骤失败时调用，operation 描述失败的步骤，为 nil 时忽略
+	OnError func(operation string, err error)
+}
+
+// Service 导出服务：创建导出任务、在后台生成文件并签发下载链接
+type Service struct {
+	repo   repositories.ExportRepository
+	users  UserSource
+	store  storage.Storage
+	config Config
+	now    func() time.Time
+
+	ctx    context.Context // 停止时取消，中断正在执行的任务
+	cancel context.CancelFunc
+	jobs   sync.WaitGroup
+
+	mu      sync.Mutex
+	running int
+	closed  bool
+
+	startOnce sync.Once
+	purgeDone chan struct{}
+}
+
+// NewService 创建导出服务
+func NewService(repo repositories.ExportRepository, users UserSource, store storage.Storage, config Config) *Service {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.MaxRunning <= 0 {
+		config.MaxRunning = DefaultMaxRunning
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.URLTTL <= 0 {
+		config.URLTTL = DefaultURLTTL
+	}
+	if config.PurgeInterval <= 0 {
+		config.PurgeInterval = DefaultPurgeInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		repo:      repo,
+		users:     users,
+		store:     store,
+		config:    config,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+		purgeDone: make(chan struct{}),
+	}
+}
+
+// Create 创建导出任务并在后台生成文件，返回任务的初始状态
+// 任务继承 ctx 中的租户和追踪信息，但不随 ctx 取消而中止
+func (s *Service) Create(ctx context.Context, createdBy, format string, filter models.ExportFilter) (*models.Export, error) {
+	if _, ok := contentTypes[format]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	_, total, err := s.users.Search(ctx, userFilter(filter), 0, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users to export: %w", err)
+	}
+	if format == models.ExportFormatXLSX && total > MaxXLSXRows {
+		return nil, fmt.Errorf("%w: %d users exceed the xlsx limit of %d rows", ErrTooManyRows, total, MaxXLSXRows)
+	}
+
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	export := &models.Export{
+		Format:    format,
+		Status:    models.ExportStatusPending,
+		Filter:    filter,
+		TotalRows: total,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.Create(ctx, export); err != nil {
+		s.release()
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.ctx, cancel)
+	job := *export
+	go func() {
+		defer s.release()
+		defer cancel()
+		defer stop()
+		s.run(jobCtx, &job)
+	}()
+	return export, nil
+}
+
+// acquire 占用一个执行名额
+func (s *Service) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.running >= s.config.MaxRunning {
+		return ErrTooManyRunning
+	}
+	s.running++
+	s.jobs.Add(1)
+	return nil
+}
+
+// release 归还执行名额
+func (s *Service) release() {
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	s.jobs.Done()
+}
+
+// Get 返回导出任务，已完成的任务附带新签发的下载链接
+func (s *Service) Get(ctx context.Context, id string) (*models.Export, error) {
+	export, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrExportNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if export.Status == models.ExportStatusCompleted {
+		export.DownloadURL = s.DownloadURL(export)
+	}
+	return export, nil
+}
+
+// run 生成文件并记录结果
+func (s *Service) run(ctx context.Context, export *models.Export) {
+	object, err := s.generate(ctx, export)
+
+	now := s.now()
+	expiresAt := now.Add(s.config.Retention)
+	export.CompletedAt, export.ExpiresAt = &now, &expiresAt
+	switch {
+	case err == nil:
+		export.Status = models.ExportStatusCompleted
+		export.ObjectKey, export.Size = object.Key, object.Size
+	case s.ctx.Err() != nil:
+		export.Status = models.ExportStatusFailed
+		export.Error = "export interrupted by server shutdown"
+	default:
+		export.Status = models.ExportStatusFailed
+		export.Error = err.Error()
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	if err := s.repo.Finish(finishCtx, export); err != nil {
+		s.report("记录导出结果", err)
+	}
+}
+
+// generate 将用户写入管道，同时把管道的另一端上传到对象存储
+func (s *Service) generate(ctx context.Context, export *models.Export) (*storage.Object, error) {
+	reader, writer := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		err := s.write(ctx, export, writer)
+		writer.CloseWithError(err)
+		written <- err
+	}()
+
+	key := storage.GenerateKey(KeyPrefix, export.ID, export.Format)
+	object, err := s.store.Put(ctx, key, reader, -1, ContentType(export.Format))
+	// 上传失败时让写入端的下一次写入返回错误，避免写入协程阻塞
+	reader.CloseWithError(err)
+	if writeErr := <-written; writeErr != nil {
+		err = writeErr
+	}
+	if err != nil {
+		if object != nil {
+			_ = s.store.Delete(context.WithoutCancel(ctx), key)
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// write 按主键分批读取用户并写入文件，每批之后更新进度
+func (s *Service) write(ctx context.Context, export *models.Export, w io.Writer) error {
+	rows, err := newRowWriter(export.Format, w)
+	if err != nil {
+		return err
+	}
+
+	filter := userFilter(export.Filter)
+	afterID := ""
+	for {
+		users, err := s.users.ScanUsers(ctx, filter, afterID, s.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read users: %w", err)
+		}
+		for _, user := range users {
+			if err := rows.Write(newRecord(user)); err != nil {
+				return err
+			}
+		}
+		export.ProcessedRows += int64(len(users))
+		// 导出过程中新增的用户也会被导出，总数不小于已写入的行数
+		export.TotalRows = max(export.TotalRows, export.ProcessedRows)
+		if err := s.repo.UpdateProgress(ctx, export.ID, export.TotalRows, export.ProcessedRows); err != nil {
+			s.report("更新导出进度", err)
+		}
+		if len(users) < s.config.BatchSize {
+			return rows.Close()
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// userFilter 将导出的筛选条件转换为用户仓库的筛选条件
+func userFilter(filter models.ExportFilter) repositories.UserFilter {
+	return repositories.UserFilter{
+		Query:    filter.Query,
+		IsActive: filter.IsActive,
+		IsAdmin:  filter.IsAdmin,
+	}
+}
+
+// Start 启动定期清理过期导出文件的后台任务
+func (s *Service) Start() {
+	s.startOnce.Do(func() {
+		go s.purgeLoop()
+	})
+}
+
+// Stop 停止清理任务，中断正在执行的导出并等待其记录结果
+func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cancel()
+	s.startOnce.Do(func() {
+		close(s.purgeDone)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		<-s.purgeDone
+		s.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) purgeLoop() {
+	defer close(s.purgeDone)
+
+	ticker := time.NewTicker(s.config.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.Purge(s.ctx); err != nil && s.ctx.Err() == nil {
+			s.report("清理过期导出", err)
+		}
+	}
+}
+
+// Purge 删除所有租户中已过保留期限的导出文件和任务记录，返回删除的任务数
+func (s *Service) Purge(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		expired, err := s.repo.ListExpired(ctx, s.now(), purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+		for _, export := range expired {
+			if export.ObjectKey != "" {
+				if err := s.store.Delete(ctx, export.ObjectKey); err != nil {
+					return purged, fmt.Errorf("failed to delete export file: %w", err)
+				}
+			}
+			// 其他实例可能同时清理了同一个任务
+			if err := s.repo.Delete(ctx, export.ID); err != nil && !errors.Is(err, repositories.ErrExportNotFound) {
+				return purged, err
+			}
+			purged++
+		}
+		if len(expired) < purgeBatch {
+			return purged, nil
+		}
+	}
+}
+
+// report 报告后台步骤的错误
+func (s *Service) report(operation string, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(operation, err)
+	}
+}
@@ -0,0 +1,334 @@
+package exports
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/storage"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newService 创建使用内存任务仓库的导出服务，测试结束时停止
+func newService(t *testing.T, users UserSource, store storage.Storage, config Config) *Service {
+	t.Helper()
+	if config.SigningKey == nil {
+		config.SigningKey = []byte("test-signing-key")
+	}
+	service := NewService(repositories.NewMemoryExportRepository(), users, store, config)
+	t.Cleanup(func() { service.Stop(context.Background()) })
+	return service
+}
+
+// newStore 创建保存在临时目录中的本地存储
+func newStore(t *testing.T) *storage.LocalStorage {
+	t.Helper()
+	store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+	require.NoError(t, err)
+	return store
+}
+
+// newUsers 创建包含 users 的内存用户仓库
+func newUsers(t *testing.T, users ...*models.User) *repositories.MemoryUserRepository {
+	t.Helper()
+	repo := repositories.NewMemoryUserRepository()
+	for _, user := range users {
+		require.NoError(t, repo.Create(context.Background(), user))
+	}
+	return repo
+}
+
+// waitExport 等待导出任务结束并返回最终状态
+func waitExport(t *testing.T, service *Service, id string) *models.Export {
+	t.Helper()
+	var export *models.Export
+	require.Eventually(t, func() bool {
+		var err error
+		export, err = service.Get(context.Background(), id)
+		return err == nil && export.Finished()
+	}, 5*time.Second, 5*time.Millisecond)
+	return export
+}
+
+// readObject 返回存储中对象的内容
+func readObject(t *testing.T, store storage.Storage, key string) []byte {
+	t.Helper()
+	reader, _, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+// blockingUsers 在 release 关闭或上下文取消前阻塞 ScanUsers，用于模拟执行中的任务
+type blockingUsers struct {
+	*repositories.MemoryUserRepository
+	total   int64
+	release chan struct{}
+}
+
+func (u *blockingUsers) Search(ctx context.Context, filter repositories.UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	return nil, u.total, nil
+}
+
+func (u *blockingUsers) ScanUsers(ctx context.Context, filter repositories.UserFilter, afterID string, limit int) ([]*models.User, error) {
+	select {
+	case <-u.release:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	store := newStore(t)
+	users := newUsers(t,
+		factory.User().WithUsername("alice").WithName("=SUM(A1)", "<b>&").Build(),
+		factory.User().WithUsername("bob").Admin().Build(),
+		factory.User().WithUsername("carol").Inactive().Build(),
+	)
+	service := newService(t, users, store, Config{BatchSize: 2})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		format    string
+		filter    models.ExportFilter
+		wantTotal int64
+		check     func(t *testing.T, data []byte)
+	}{
+		{
+			name:      "csv",
+			format:    models.ExportFormatCSV,
+			wantTotal: 3,
+			check: func(t *testing.T, data []byte) {
+				rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, 4)
+				assert.Equal(t, header, rows[0])
+				byUsername := map[string][]string{}
+				for _, row := range rows[1:] {
+					byUsername[row[1]] = row
+				}
+				alice := byUsername["alice"]
+				require.NotNil(t, alice)
+				assert.Equal(t, []string{"'=SUM(A1)", "<b>&"}, alice[3:5], "公式前缀单引号")
+				assert.Equal(t, []string{"true", "false"}, alice[5:7])
+				assert.True(t, strings.HasSuffix(alice[9], "Z"), "时间统一为 UTC")
+				assert.Less(t, rows[1][0], rows[2][0], "按主键顺序导出")
+			},
+		},
+		{
+			name:      "json",
+			format:    models.ExportFormatJSON,
+			filter:    models.ExportFilter{IsAdmin: boolPtr(true)},
+			wantTotal: 1,
+			check: func(t *testing.T, data []byte) {
+				var records []map[string]interface{}
+				require.NoError(t, json.Unmarshal(data, &records))
+				require.Len(t, records, 1)
+				assert.Equal(t, "bob", records[0]["username"])
+				assert.Equal(t, true, records[0]["is_admin"])
+				assert.NotContains(t, records[0], "password")
+			},
+		},
+		{
+			name:   "empty json",
+			format: models.ExportFormatJSON,
+			filter: models.ExportFilter{Query: "nobody"},
+			check: func(t *testing.T, data []byte) {
+				assert.JSONEq(t, "[]", string(data))
+			},
+		},
+		{
+			name:      "xlsx",
+			format:    models.ExportFormatXLSX,
+			wantTotal: 3,
+			check: func(t *testing.T, data []byte) {
+				archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+				require.NoError(t, err)
+				parts := map[string]string{}
+				for _, file := range archive.File {
+					reader, err := file.Open()
+					require.NoError(t, err)
+					content, err := io.ReadAll(reader)
+					require.NoError(t, err)
+					reader.Close()
+					parts[file.Name] = string(content)
+				}
+				require.Contains(t, parts, "[Content_Types].xml")
+				require.Contains(t, parts, "xl/workbook.xml")
+				sheet := parts["xl/worksheets/sheet1.xml"]
+				assert.Equal(t, 4, strings.Count(sheet, "<row>"))
+				assert.Contains(t, sheet, "&lt;b&gt;&amp;", "文本转义")
+				assert.Contains(t, sheet, "=SUM(A1)", "XLSX 的内联字符串不会被当作公式")
+				assert.Contains(t, sheet, `<c t="b"><v>1</v></c>`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export, err := service.Create(context.Background(), "admin-id", tt.format, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, models.ExportStatusPending, export.Status)
+			assert.Equal(t, tt.wantTotal, export.TotalRows)
+
+			export = waitExport(t, service, export.ID)
+			require.Equal(t, models.ExportStatusCompleted, export.Status, export.Error)
+			assert.Equal(t, tt.wantTotal, export.ProcessedRows)
+			assert.NotEmpty(t, export.DownloadURL)
+			assert.Equal(t, now.Add(DefaultRetention), *export.ExpiresAt)
+
+			tt.check(t, readObject(t, store, export.ObjectKey))
+		})
+	}
+}
+
+func TestCreateRejectsUnsupportedFormats(t *testing.T) {
+	users := &blockingUsers{total: MaxXLSXRows + 1, release: make(chan struct{})}
+	close(users.release)
+	service := newService(t, users, newStore(t), Config{})
+
+	tests := []struct {
+		name    string
+		format  string
+		wantErr error
+	}{
+		{"xlsx 超出行数上限", models.ExportFormatXLSX, ErrTooManyRows},
+		{"不支持的格式", "pdf", ErrUnsupportedFormat},
+		{"csv 没有行数上限", models.ExportFormatCSV, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export, err := service.Create(context.Background(), "admin-id", tt.format, models.ExportFilter{})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			waitExport(t, service, export.ID)
+		})
+	}
+}
+
+func TestDownloadLinks(t *testing.T) {
+	store := newStore(t)
+	service := newService(t, newUsers(t, factory.User().Build()), store, Config{URLTTL: time.Minute, Retention: time.Hour})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	export, err := service.Create(context.Background(), "admin-id", models.ExportFormatCSV, models.ExportFilter{})
+	require.NoError(t, err)
+	export = waitExport(t, service, export.ID)
+
+	link, err := url.Parse(export.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, DownloadPath+export.ID+"/download", link.Path)
+	expires, signature := link.Query().Get("expires"), link.Query().Get("signature")
+
+	reader, opened, err := service.Open(context.Background(), export.ID, expires, signature)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, readObject(t, store, export.ObjectKey), data)
+	assert.Regexp(t, `^users-\d{8}-\d{6}\.csv$`, FileName(opened))
+
+	tests := []struct {
+		name      string
+		id        string
+		expires   string
+		signature string
+		later     time.Duration // 打开前时钟前进的时长
+		wantErr   error
+	}{
+		{"签名错误", export.ID, expires, signature + "0", 0, ErrInvalidSignature},
+		{"签名绑定任务ID", missingID, expires, signature, 0, ErrInvalidSignature},
+		{"过期时间格式错误", export.ID, "not-a-number", signature, 0, ErrInvalidSignature},
+		{"链接已过期", export.ID, expires, signature, 2 * time.Minute, ErrLinkExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.now = func() time.Time { return now.Add(tt.later) }
+			_, _, err := service.Open(context.Background(), tt.id, tt.expires, tt.signature)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("links expire with the file", func(t *testing.T) {
+		service.now = func() time.Time { return export.ExpiresAt.Add(-time.Second) }
+		link, err := url.Parse(service.DownloadURL(export))
+		require.NoError(t, err)
+		assert.Equal(t, strconv.FormatInt(export.ExpiresAt.Unix(), 10), link.Query().Get("expires"))
+	})
+}
+
+func TestPurge(t *testing.T) {
+	store := newStore(t)
+	service := newService(t, newUsers(t, factory.User().Build()), store, Config{Retention: time.Hour})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	export, err := service.Create(context.Background(), "admin-id", models.ExportFormatCSV, models.ExportFilter{})
+	require.NoError(t, err)
+	export = waitExport(t, service, export.ID)
+
+	purged, err := service.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged, "未过保留期限")
+
+	service.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+	purged, err = service.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = service.Get(context.Background(), export.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	exists, err := store.Exists(context.Background(), export.ObjectKey)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStopInterruptsRunningExports(t *testing.T) {
+	users := &blockingUsers{total: 10, release: make(chan struct{})}
+	service := newService(t, users, newStore(t), Config{MaxRunning: 1})
+
+	export, err := service.Create(context.Background(), "admin-id", models.ExportFormatCSV, models.ExportFilter{})
+	require.NoError(t, err)
+	_, err = service.Create(context.Background(), "admin-id", models.ExportFormatCSV, models.ExportFilter{})
+	assert.ErrorIs(t, err, ErrTooManyRunning)
+
+	require.NoError(t, service.Stop(context.Background()))
+	stopped, err := service.Get(context.Background(), export.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusFailed, stopped.Status)
+	assert.Equal(t, "export interrupted by server shutdown", stopped.Error)
+	assert.Empty(t, stopped.ObjectKey)
+
+	_, err = service.Create(context.Background(), "admin-id", models.ExportFormatCSV, models.ExportFilter{})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// missingID 不存在的导出任务ID
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+func boolPtr(v bool) *bool {
+	return &v
+}
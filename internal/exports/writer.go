@@ -0,0 +1,157 @@
+package exports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-server/internal/models"
+)
+
+// header 导出文件的列名，与 record 的 JSON 字段一致
+var header = []string{"id", "username", "email", "first_name", "last_name", "is_active", "is_admin", "tenant_id", "last_login", "created_at"}
+
+// record 导出的用户字段，不包含密码、头像存储键等内部字段
+type record struct {
+	ID        string     `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	IsActive  bool       `json:"is_active"`
+	IsAdmin   bool       `json:"is_admin"`
+	TenantID  *string    `json:"tenant_id"`
+	LastLogin *time.Time `json:"last_login"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// newRecord 从用户生成导出记录，时间统一为 UTC
+func newRecord(user *models.User) record {
+	r := record{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		IsActive:  user.IsActive,
+		IsAdmin:   user.IsAdmin,
+		TenantID:  user.TenantID,
+		CreatedAt: user.CreatedAt.UTC(),
+	}
+	if user.LastLogin != nil {
+		lastLogin := user.LastLogin.UTC()
+		r.LastLogin = &lastLogin
+	}
+	return r
+}
+
+// cells 按 header 的顺序返回单元格文本，空值为空字符串
+func (r record) cells() []string {
+	tenantID, lastLogin := "", ""
+	if r.TenantID != nil {
+		tenantID = *r.TenantID
+	}
+	if r.LastLogin != nil {
+		lastLogin = r.LastLogin.Format(time.RFC3339)
+	}
+	return []string{
+		r.ID, r.Username, r.Email, r.FirstName, r.LastName,
+		strconv.FormatBool(r.IsActive), strconv.FormatBool(r.IsAdmin),
+		tenantID, lastLogin, r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// rowWriter 按格式逐行写入导出文件，Close 写入文件结尾但不关闭底层写入器
+type rowWriter interface {
+	Write(r record) error
+	Close() error
+}
+
+// newRowWriter 创建指定格式的写入器并写入文件开头
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	switch format {
+	case models.ExportFormatCSV:
+		return newCSVWriter(w)
+	case models.ExportFormatJSON:
+		return newJSONWriter(w)
+	case models.ExportFormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// csvWriter 写入带表头的 CSV 文件
+type csvWriter struct {
+	csv *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := &csvWriter{csv: csv.NewWriter(w)}
+	if err := writer.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write 写入一行，以公式字符开头的单元格加上单引号前缀，防止在电子表格中打开时被当作公式执行
+func (w *csvWriter) Write(r record) error {
+	cells := r.cells()
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cells[i] = "'" + cell
+		}
+	}
+	return w.csv.Write(cells)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// jsonWriter 写入 JSON 数组，每行一个对象，不在内存中保留已写入的数据
+type jsonWriter struct {
+	w     *bufio.Writer
+	first bool
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	writer := &jsonWriter{w: bufio.NewWriter(w), first: true}
+	if _, err := writer.w.WriteString("["); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *jsonWriter) Write(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if w.first {
+		separator, w.first = "\n", false
+	}
+	if _, err := w.w.WriteString(separator); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	end := "\n]\n"
+	if w.first {
+		end = "]\n"
+	}
+	if _, err := w.w.WriteString(end); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
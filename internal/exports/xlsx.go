@@ -0,0 +1,109 @@
+package exports
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// MaxXLSXRows XLSX 工作表最多容纳的数据行数（1048576 行减去表头）
+const MaxXLSXRows = 1048575
+
+// booleanColumns header 中写为布尔单元格的列：is_active、is_admin
+var booleanColumns = map[int]bool{5: true, 6: true}
+
+// xlsxParts 工作簿中除工作表外的固定部件，按写入顺序排列
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="users" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter 以流式方式写入只有一个工作表的 XLSX 文件
+//
+// 单元格使用内联字符串而不是共享字符串表，因此无需在内存中保留全部数据，代价是文件略大
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{zip: archive, sheet: bufio.NewWriter(file)}
+	writer.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err := writer.row(header, nil); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write 写入一行，布尔列写为布尔单元格，其他列写为文本
+func (w *xlsxWriter) Write(r record) error {
+	if w.rows > MaxXLSXRows {
+		return fmt.Errorf("%w: more than %d rows", ErrTooManyRows, MaxXLSXRows)
+	}
+	return w.row(r.cells(), booleanColumns)
+}
+
+// row 写入一行单元格，booleans 中的列写为布尔单元格
+func (w *xlsxWriter) row(cells []string, booleans map[int]bool) error {
+	w.rows++
+	w.sheet.WriteString("<row>")
+	for i, cell := range cells {
+		switch {
+		case cell == "":
+			w.sheet.WriteString("<c/>")
+		case booleans[i]:
+			value := "0"
+			if cell == "true" {
+				value = "1"
+			}
+			w.sheet.WriteString(`<c t="b"><v>` + value + `</v></c>`)
+		default:
+			w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(w.sheet, []byte(cell)); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
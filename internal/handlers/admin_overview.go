@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-server/internal/buildinfo"
+	"go-server/internal/database"
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminOverviewTTL 系统概览的缓存时间，仪表盘轮询时避免每次都查询数据库和缓存
+const adminOverviewTTL = 5 * time.Second
+
+// RateLimitStatsFunc 返回当前的限流统计，未启用限流时返回 false
+type RateLimitStatsFunc func() (metrics.RateLimitStats, bool)
+
+// AdminOverviewHandler 处理管理后台系统概览接口（/api/v1/admin/overview）
+type AdminOverviewHandler struct {
+	userRepo       repositories.UserRepository
+	db             *database.Database
+	cache          cache.Cache
+	cacheDriver    string
+	rateLimitStats RateLimitStatsFunc
+	ttl            time.Duration
+
+	mu        sync.Mutex
+	cached    *models.AdminOverviewResponse
+	expiresAt time.Time
+}
+
+// NewAdminOverviewHandler 创建系统概览处理器，db、appCache 和 rateLimitStats 为 nil 时对应部分报告未配置
+func NewAdminOverviewHandler(userRepo repositories.UserRepository, db *database.Database, appCache cache.Cache, cacheDriver string, rateLimitStats RateLimitStatsFunc) *AdminOverviewHandler {
+	return &AdminOverviewHandler{
+		userRepo:       userRepo,
+		db:             db,
+		cache:          appCache,
+		cacheDriver:    cacheDriver,
+		rateLimitStats: rateLimitStats,
+		ttl:            adminOverviewTTL,
+	}
+}
+
+// Overview godoc
+// @Summary 系统概览
+// @Description 汇总用户数量、请求和限流统计、缓存统计、数据库连接池健康状态、运行时长和版本信息，供管理后台仪表盘使用（仅管理员）。结果在进程内缓存 5 秒，refresh=true 时重新采集。某一部分采集失败时在该部分的 error 字段中说明，接口仍返回 200。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param refresh query bool false "跳过缓存重新采集"
+// @Success 200 {object} models.SuccessResponse{data=models.AdminOverviewResponse} "成功获取系统概览"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Router /api/v1/admin/overview [get]
+func (h *AdminOverviewHandler) Overview(c *gin.Context) {
+	overview := h.overview(c.Request.Context(), c.Query("refresh") == "true")
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "admin_overview.retrieved"), overview)
+}
+
+// overview 返回缓存的概览，过期或 refresh 时重新采集
+// 采集期间持有锁，并发请求等待同一次采集的结果
+func (h *AdminOverviewHandler) overview(ctx context.Context, refresh bool) *models.AdminOverviewResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !refresh && h.cached != nil && now.Before(h.expiresAt) {
+		return h.cached
+	}
+
+	h.cached = h.collect(ctx)
+	h.expiresAt = now.Add(h.ttl)
+	return h.cached
+}
+
+// collect 采集各部分的状态
+func (h *AdminOverviewHandler) collect(ctx context.Context) *models.AdminOverviewResponse {
+	uptime := buildinfo.Uptime().Truncate(time.Second)
+	overview := &models.AdminOverviewResponse{
+		GeneratedAt: time.Now().UTC(),
+		Uptime: models.AdminOverviewUptime{
+			StartedAt: buildinfo.StartTime().UTC(),
+			Seconds:   int64(uptime.Seconds()),
+			Human:     uptime.String(),
+		},
+		Build:    buildinfo.Get(),
+		Users:    h.userCounts(ctx),
+		Cache:    h.cacheStatus(ctx),
+		Database: h.databaseStatus(),
+	}
+
+	if h.rateLimitStats != nil {
+		if stats, ok := h.rateLimitStats(); ok {
+			// 最近请求明细过长，概览中只保留汇总
+			stats.RecentRequests = nil
+			overview.RateLimit = &stats
+		}
+	}
+	return overview
+}
+
+// userCounts 统计用户数量，包含已停用的用户
+func (h *AdminOverviewHandler) userCounts(ctx context.Context) models.AdminOverviewUsers {
+	var users models.AdminOverviewUsers
+	if h.userRepo == nil {
+		users.Error = "user repository is not configured"
+		return users
+	}
+
+	active, admin := true, true
+	counts := []struct {
+		filter repositories.UserFilter
+		target *int64
+	}{
+		{repositories.UserFilter{}, &users.Total},
+		{repositories.UserFilter{IsActive: &active}, &users.Active},
+		{repositories.UserFilter{IsAdmin: &admin}, &users.Admins},
+	}
+	for _, count := range counts {
+		_, total, err := h.userRepo.Search(ctx, count.filter, 0, 1)
+		if err != nil {
+			return models.AdminOverviewUsers{Error: err.Error()}
+		}
+		*count.target = total
+	}
+
+	users.Inactive = users.Total - users.Active
+	return users
+}
+
+// cacheStatus 检查缓存健康状态并读取统计信息
+func (h *AdminOverviewHandler) cacheStatus(ctx context.Context) models.AdminOverviewComponent {
+	status := models.AdminOverviewComponent{Driver: h.cacheDriver}
+	if h.cache == nil {
+		status.Status = "not_configured"
+		return status
+	}
+
+	if err := h.cache.Health(ctx); err != nil {
+		status.Status = "unhealthy"
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Status = "healthy"
+	stats, err := h.cache.GetStats(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Stats = stats
+	return status
+}
+
+// databaseStatus 返回数据库连接池的健康状态和统计信息
+func (h *AdminOverviewHandler) databaseStatus() models.AdminOverviewComponent {
+	if h.db == nil {
+		return models.AdminOverviewComponent{Status: "not_configured"}
+	}
+
+	status := models.AdminOverviewComponent{Driver: h.db.DB.Dialector.Name()}
+
+	if err := h.db.Health(); err != nil {
+		status.Status = "unhealthy"
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Status = "healthy"
+	stats, err := h.db.GetConnectionPoolStats()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Stats = stats
+	return status
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUserRepository 按筛选条件返回固定的用户数量，并记录查询次数
+type countingUserRepository struct {
+	repositories.UserRepository
+	searches int
+}
+
+func (r *countingUserRepository) Search(ctx context.Context, filter repositories.UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	r.searches++
+	switch {
+	case filter.IsActive != nil:
+		return nil, 8, nil
+	case filter.IsAdmin != nil:
+		return nil, 2, nil
+	default:
+		return nil, 10, nil
+	}
+}
+
+func TestAdminOverviewHandler_Overview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &countingUserRepository{}
+	rateLimit := metrics.NewRateLimitMetrics()
+	rateLimit.RecordRequest("10.0.0.1", "", "/api/v1/users", 0, true, "", 0, 100)
+	rateLimit.RecordRequest("10.0.0.1", "", "/api/v1/users", 0, false, "rate_limit_exceeded", 0, 100)
+	handler := NewAdminOverviewHandler(repo, nil, nil, "redis", func() (metrics.RateLimitStats, bool) {
+		return rateLimit.GetStats(), true
+	})
+
+	get := func(target string) (*overviewResponseBody, int) {
+		c, w := newAdminContext(http.MethodGet, target, "", "")
+		handler.Overview(c)
+
+		var body overviewResponseBody
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return &body, w.Code
+	}
+
+	body, code := get("/api/v1/admin/overview")
+	assert.Equal(t, http.StatusOK, code)
+	overview := body.Data
+	assert.Equal(t, models.AdminOverviewUsers{Total: 10, Active: 8, Inactive: 2, Admins: 2}, overview.Users)
+	require.NotNil(t, overview.RateLimit)
+	assert.Equal(t, uint64(2), overview.RateLimit.TotalRequests)
+	assert.Equal(t, uint64(1), overview.RateLimit.ThrottledRequests)
+	assert.Empty(t, overview.RateLimit.RecentRequests, "概览中不包含最近请求明细")
+	assert.Equal(t, "not_configured", overview.Cache.Status)
+	assert.Equal(t, "redis", overview.Cache.Driver)
+	assert.Equal(t, "not_configured", overview.Database.Status)
+	assert.NotEmpty(t, overview.Build.GoVersion)
+
+	// 缓存期内不再查询
+	get("/api/v1/admin/overview")
+	assert.Equal(t, 3, repo.searches)
+
+	get("/api/v1/admin/overview?refresh=true")
+	assert.Equal(t, 6, repo.searches, "refresh=true 时重新采集")
+}
+
+// overviewResponseBody 系统概览的响应体
+type overviewResponseBody struct {
+	Success bool                         `json:"success"`
+	Data    models.AdminOverviewResponse `json:"data"`
+}
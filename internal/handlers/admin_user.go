@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/internal/validation"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// impersonationTTL 模拟登录令牌的有效期
+const impersonationTTL = 15 * time.Minute
+
+// AdminUserHandler 处理管理员用户管理接口（/api/v1/admin/users）
+type AdminUserHandler struct {
+	userService services.UserService
+	jwtManager  *auth.JWTManager
+	blacklist   *cache.BlacklistService
+	audit       audit.Recorder
+}
+
+// NewAdminUserHandler 创建管理员用户管理处理器，blacklist 为 nil 时停用用户和重置密码不会吊销已签发的令牌，
+// recorder 为 nil 时不记录审计事件
+func NewAdminUserHandler(userService services.UserService, jwtManager *auth.JWTManager, blacklist *cache.BlacklistService, recorder audit.Recorder) *AdminUserHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &AdminUserHandler{
+		userService: userService,
+		jwtManager:  jwtManager,
+		blacklist:   blacklist,
+		audit:       recorder,
+	}
+}
+
+// ListUsers godoc
+// @Summary 按条件列出用户
+// @Description 分页列出用户，包含已停用的用户（仅管理员）。q 按用户名、邮箱和姓名模糊匹配；role=admin 只返回管理员，role=user 只返回普通用户。结果不经过缓存。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "搜索关键字"
+// @Param active query bool false "是否激活"
+// @Param role query string false "角色" Enums(user, admin)
+// @Paginate
+// @PaginatedSuccess models.SafeUser "成功获取用户"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Router /api/v1/admin/users [get]
+func (h *AdminUserHandler) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		response.ValidationError(c, localize(c, "pagination.invalid_page"),
+			errors.ErrorDetails{Field: "page", Message: localize(c, "pagination.invalid_page"), Value: page})
+		return
+	}
+	if limit < 1 || limit > 100 {
+		response.ValidationError(c, localize(c, "pagination.invalid_limit"),
+			errors.ErrorDetails{Field: "limit", Message: localize(c, "pagination.invalid_limit"), Value: limit})
+		return
+	}
+
+	filter, ok := userFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "user.get_failed"), err)
+		return
+	}
+
+	safeUsers := make([]models.SafeUser, len(users))
+	for i, user := range users {
+		safeUsers[i] = user.ToSafeUser()
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "user.retrieved"), models.PaginatedResponse{
+		Data: safeUsers,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// StreamUsers godoc
+// @Summary 流式列出全部用户
+// @Description 不分页地列出匹配条件的全部用户，包含已停用的用户（仅管理员），按用户ID排序。用户逐行从数据库读取并逐个写入响应，内存占用与用户数量无关，适合导出大量用户；需要异步生成文件时使用数据导出接口。响应开始后读取失败时响应体被截断，不是合法的 JSON。结果不经过缓存。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "搜索关键字"
+// @Param active query bool false "是否激活"
+// @Param role query string false "角色" Enums(user, admin)
+// @Success 200 {object} models.SuccessResponse{data=[]models.SafeUser} "成功获取用户"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Router /api/v1/admin/users/stream [get]
+func (h *AdminUserHandler) StreamUsers(c *gin.Context) {
+	filter, ok := userFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	stream := response.NewJSONArrayStream(c, http.StatusOK, localize(c, "user.retrieved"))
+	err := h.userService.StreamUsers(c.Request.Context(), filter, func(user *models.User) error {
+		return stream.Send(user.ToSafeUser())
+	})
+	if err == nil {
+		err = stream.Close()
+	}
+	if err != nil {
+		if !stream.Started() {
+			response.DatabaseError(c, localize(c, "user.get_failed"), err)
+			return
+		}
+		stream.Abort(err)
+	}
+}
+
+// userFilterFromQuery 解析用户列表的 q、active 和 role 查询参数，参数错误时发送校验错误响应并返回 false
+func userFilterFromQuery(c *gin.Context) (repositories.UserFilter, bool) {
+	filter := repositories.UserFilter{Query: strings.TrimSpace(c.Query("q"))}
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			response.ValidationError(c, localize(c, "admin_user.invalid_active"),
+				errors.ErrorDetails{Field: "active", Message: localize(c, "admin_user.active_detail"), Value: value})
+			return filter, false
+		}
+		filter.IsActive = &active
+	}
+	switch role := c.Query("role"); role {
+	case "":
+	case models.RoleAdmin, models.RoleUser:
+		isAdmin := role == models.RoleAdmin
+		filter.IsAdmin = &isAdmin
+	default:
+		response.ValidationError(c, localize(c, "admin_user.unknown_role"),
+			errors.ErrorDetails{Field: "role", Message: localize(c, "admin_user.role_detail"), Value: role})
+		return filter, false
+	}
+	return filter, true
+}
+
+// ActivateUser godoc
+// @Summary 激活用户
+// @Description 重新激活已停用的用户（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "用户已激活"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/admin/users/{id}/activate [post]
+func (h *AdminUserHandler) ActivateUser(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// DeactivateUser godoc
+// @Summary 停用用户
+// @Description 停用用户并吊销其已签发的全部令牌，停用的用户无法登录（仅管理员）。管理员不能停用自己。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "用户已停用"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限或不能停用自己"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/admin/users/{id}/deactivate [post]
+func (h *AdminUserHandler) DeactivateUser(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// setActive 激活或停用路径参数指定的用户
+func (h *AdminUserHandler) setActive(c *gin.Context, active bool) {
+	adminID, ok := h.currentAdminID(c)
+	if !ok {
+		return
+	}
+	targetID := c.Param("id")
+
+	action := audit.ActionUserActivated
+	if !active {
+		action = audit.ActionUserDeactivated
+	}
+
+	user, err := h.userService.SetActive(c.Request.Context(), targetID, active, adminID)
+	if err != nil {
+		h.record(c, action, adminID, targetID, err, nil)
+		h.respondError(c, targetID, localize(c, "admin_user.update_status_failed"), err)
+		return
+	}
+
+	var details map[string]interface{}
+	if !active {
+		details = map[string]interface{}{"tokens_revoked": h.revokeTokens(c, targetID)}
+	}
+	h.record(c, action, adminID, targetID, nil, details)
+
+	message := localize(c, "admin_user.activated")
+	if !active {
+		message = localize(c, "admin_user.deactivated")
+	}
+	response.Success(c, http.StatusOK, message, user.ToSafeUser())
+}
+
+// ResetPassword godoc
+// @Summary 强制重置用户密码
+// @Description 将用户密码重置为随机临时密码并吊销其已签发的全部令牌（仅管理员）。临时密码只在本次响应中返回，需要通过安全渠道转交用户，用户登录后应立即修改密码。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} models.SuccessResponse{data=models.PasswordResetResponse} "密码已重置"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *AdminUserHandler) ResetPassword(c *gin.Context) {
+	adminID, ok := h.currentAdminID(c)
+	if !ok {
+		return
+	}
+	targetID := c.Param("id")
+
+	password, err := h.userService.ResetPassword(c.Request.Context(), targetID)
+	if err != nil {
+		h.record(c, audit.ActionPasswordReset, adminID, targetID, err, nil)
+		h.respondError(c, targetID, localize(c, "admin_user.reset_password_failed"), err)
+		return
+	}
+
+	revoked := h.revokeTokens(c, targetID)
+	h.record(c, audit.ActionPasswordReset, adminID, targetID, nil, map[string]interface{}{"tokens_revoked": revoked})
+	response.Success(c, http.StatusOK, localize(c, "admin_user.password_reset"), models.PasswordResetResponse{
+		UserID:            targetID,
+		TemporaryPassword: password,
+	})
+}
+
+// AssignRoles godoc
+// @Summary 分配用户角色
+// @Description 设置用户的完整角色集合（仅管理员）。包含 admin 时授予管理员权限，否则撤销；管理员不能撤销自己的管理员角色。
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Param request body models.AssignRolesRequest true "角色列表"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "角色已更新"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限或不能撤销自己的管理员角色"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *AdminUserHandler) AssignRoles(c *gin.Context) {
+	adminID, ok := h.currentAdminID(c)
+	if !ok {
+		return
+	}
+	targetID := c.Param("id")
+
+	var req models.AssignRolesRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.AssignRoles(c.Request.Context(), targetID, req.Roles, adminID)
+	if err != nil {
+		h.record(c, audit.ActionRolesAssigned, adminID, targetID, err, map[string]interface{}{"roles": req.Roles})
+		h.respondError(c, targetID, localize(c, "admin_user.assign_role_failed"), err)
+		return
+	}
+
+	h.record(c, audit.ActionRolesAssigned, adminID, targetID, nil, map[string]interface{}{"roles": user.GetRoles()})
+	response.Success(c, http.StatusOK, localize(c, "admin_user.role_updated"), user.ToSafeUser())
+}
+
+// Impersonate godoc
+// @Summary 模拟登录用户
+// @Description 以目标用户身份签发短期令牌，令牌的 act 声明记录管理员ID，imp_banner 声明为模拟登录横幅（仅管理员）。不能模拟自己或其他管理员，模拟登录期间不能再次模拟。令牌有效期 15 分钟。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} models.SuccessResponse{data=models.ImpersonationResponse} "模拟登录令牌已签发"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "不能模拟自己"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限或目标用户是管理员"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *AdminUserHandler) Impersonate(c *gin.Context) {
+	adminID, ok := h.currentAdminID(c)
+	if !ok {
+		return
+	}
+	targetID := c.Param("id")
+
+	if _, impersonating := c.Get("impersonator_id"); impersonating {
+		h.record(c, audit.ActionImpersonationStarted, adminID, targetID, stderrors.New("nested impersonation"), nil)
+		response.ForbiddenError(c, localize(c, "admin_user.nested_impersonation"))
+		return
+	}
+	if targetID == adminID {
+		h.record(c, audit.ActionImpersonationStarted, adminID, targetID, stderrors.New("self impersonation"), nil)
+		response.ValidationError(c, localize(c, "admin_user.impersonate_self"),
+			errors.ErrorDetails{Field: "id", Message: localize(c, "admin_user.impersonate_self_detail"), Value: targetID})
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), targetID)
+	if err != nil {
+		h.record(c, audit.ActionImpersonationStarted, adminID, targetID, err, nil)
+		h.respondError(c, targetID, localize(c, "admin_user.impersonate_failed"), err)
+		return
+	}
+	if user.IsAdmin {
+		h.record(c, audit.ActionImpersonationStarted, adminID, targetID, stderrors.New("target is an admin"), nil)
+		response.ForbiddenError(c, localize(c, "admin_user.impersonate_admin"))
+		return
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	token, err := h.jwtManager.GenerateImpersonationToken(user.ID, user.Username, user.Email, adminID, impersonationTTL)
+	if err != nil {
+		h.record(c, audit.ActionImpersonationStarted, adminID, targetID, err, nil)
+		response.InternalServerErrorWithCause(c, localize(c, "admin_user.issue_impersonation_token_failed"), err)
+		return
+	}
+
+	h.record(c, audit.ActionImpersonationStarted, adminID, targetID, nil, map[string]interface{}{"expires_at": expiresAt})
+	response.Success(c, http.StatusOK, localize(c, "admin_user.impersonation_token_issued"), models.ImpersonationResponse{
+		Token:          token,
+		ExpiresAt:      expiresAt,
+		ImpersonatorID: adminID,
+		User:           user.ToSafeUser(),
+	})
+}
+
+// StopImpersonation godoc
+// @Summary 结束模拟登录
+// @Description 使用模拟登录令牌调用，吊销该令牌并记录审计事件，之后该令牌不能再使用。普通令牌不能调用此接口。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "模拟登录已结束"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "当前令牌不是模拟登录令牌"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 500 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "吊销令牌失败"
+// @Router /api/v1/admin/impersonation/stop [post]
+func (h *AdminUserHandler) StopImpersonation(c *gin.Context) {
+	targetID, ok := h.currentAdminID(c)
+	if !ok {
+		return
+	}
+	impersonatorID, impersonating := c.Get("impersonator_id")
+	if !impersonating {
+		response.ValidationError(c, localize(c, "admin_user.not_impersonation_token"))
+		return
+	}
+	adminID := impersonatorID.(string)
+
+	if h.blacklist != nil {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if err := h.blacklist.AddToBlacklist(c.Request.Context(), token); err != nil {
+			h.record(c, audit.ActionImpersonationStopped, adminID, targetID, err, nil)
+			response.CacheError(c, localize(c, "admin_user.revoke_impersonation_token_failed"), err)
+			return
+		}
+	}
+
+	h.record(c, audit.ActionImpersonationStopped, adminID, targetID, nil, nil)
+	response.Success(c, http.StatusOK, localize(c, "admin_user.impersonation_ended"), nil)
+}
+
+// currentAdminID 返回认证中间件写入的管理员ID，未认证时写入 401 响应
+func (h *AdminUserHandler) currentAdminID(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
+		return "", false
+	}
+	return userID.(string), true
+}
+
+// revokeTokens 吊销用户已签发的全部令牌，失败时只记录审计信息，不影响已完成的操作
+func (h *AdminUserHandler) revokeTokens(c *gin.Context, userID string) bool {
+	if h.blacklist == nil {
+		return false
+	}
+	if err := h.blacklist.RevokeUserTokens(c.Request.Context(), userID); err != nil {
+		adminID, _ := c.Get("user_id")
+		h.record(c, audit.ActionTokensRevoked, adminID.(string), userID, err, nil)
+		return false
+	}
+	return true
+}
+
+// respondError 将用户管理错误映射为对应的 HTTP 状态码
+func (h *AdminUserHandler) respondError(c *gin.Context, userID, message string, err error) {
+	switch {
+	case stderrors.Is(err, services.ErrCannotModifySelf):
+		response.ForbiddenError(c, err.Error())
+	case stderrors.Is(err, services.ErrInvalidRole):
+		response.ValidationError(c, localize(c, "admin_user.unknown_role"),
+			errors.ErrorDetails{Field: "roles", Message: err.Error()})
+	case stderrors.Is(err, repositories.ErrUserNotFound) || err.Error() == "user not found":
+		response.NotFoundError(c, "User", userID)
+	default:
+		response.InternalServerErrorWithCause(c, message, err)
+	}
+}
+
+// record 记录一次管理员操作的审计事件，err 不为 nil 时记为失败
+func (h *AdminUserHandler) record(c *gin.Context, action, adminID, targetID string, err error, details map[string]interface{}) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   adminID,
+		TargetID:  targetID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/internal/validation"
+	"go-server/pkg/auth"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminContext 构造管理员请求上下文
+func newAdminContext(method, target, body, targetID string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", "admin")
+	if targetID != "" {
+		c.Params = gin.Params{{Key: "id", Value: targetID}}
+	}
+	return c, w
+}
+
+func TestAdminUserHandler_ListUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("按条件筛选", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+
+		active, isAdmin := false, true
+		filter := repositories.UserFilter{Query: "john", IsActive: &active, IsAdmin: &isAdmin}
+		users := []*models.User{factory.User().WithID("1").WithEmail("john@example.com").WithUsername("john").Build()}
+		mockService.On("ListUsers", filter, 2, 5).Return(users, int64(6), nil)
+
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users?q=john&active=false&role=admin&page=2&limit=5", "", "")
+		handler.ListUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_pages":2`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("无效的筛选参数", func(t *testing.T) {
+		handler := NewAdminUserHandler(new(MockUserService), nil, nil, nil)
+
+		for _, query := range []string{"active=maybe", "role=root", "limit=500"} {
+			c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users?"+query, "", "")
+			handler.ListUsers(c)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+func TestAdminUserHandler_StreamUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("流式返回全部用户", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+
+		active := true
+		users := []*models.User{
+			factory.User().WithID("1").WithEmail("alice@example.com").WithUsername("alice").Build(),
+			factory.User().WithID("2").WithEmail("bob@example.com").WithUsername("bob").Build(),
+		}
+		mockService.On("StreamUsers", repositories.UserFilter{IsActive: &active}).Return(users, nil)
+
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users/stream?active=true", "", "")
+		handler.StreamUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Success bool              `json:"success"`
+			Data    []models.SafeUser `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Success)
+		require.Len(t, body.Data, 2)
+		assert.Equal(t, "bob", body.Data[1].Username)
+		assert.NotContains(t, w.Body.String(), "password")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("开始发送前失败时返回错误响应", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+		mockService.On("StreamUsers", repositories.UserFilter{}).Return(nil, stderrors.New("connection refused"))
+
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users/stream", "", "")
+		handler.StreamUsers(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("发送中途失败时截断响应", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+		users := []*models.User{factory.User().WithID("1").Build()}
+		mockService.On("StreamUsers", repositories.UserFilter{}).Return(users, stderrors.New("connection reset"))
+
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users/stream", "", "")
+		handler.StreamUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, json.Valid(w.Body.Bytes()))
+		assert.Len(t, c.Errors, 1)
+	})
+
+	t.Run("无效的筛选参数", func(t *testing.T) {
+		handler := NewAdminUserHandler(new(MockUserService), nil, nil, nil)
+
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/users/stream?role=root", "", "")
+		handler.StreamUsers(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminUserHandler_SetActive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("停用用户并记录审计事件", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(mockService, nil, nil, auditor)
+		user := factory.User().WithID("1").WithEmail("john@example.com").WithUsername("john").Build()
+		user.IsActive = false
+		mockService.On("SetActive", "1", false, "admin").Return(user, nil)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/1/deactivate", "", "1")
+		handler.DeactivateUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionUserDeactivated, auditor.events[0].Action)
+		assert.Equal(t, "admin", auditor.events[0].ActorID)
+		assert.Equal(t, "1", auditor.events[0].TargetID)
+	})
+
+	t.Run("不能停用自己", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(mockService, nil, nil, auditor)
+		mockService.On("SetActive", "admin", false, "admin").Return(nil, services.ErrCannotModifySelf)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/admin/deactivate", "", "admin")
+		handler.DeactivateUser(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+		mockService.On("SetActive", "missing", true, "admin").Return(nil, repositories.ErrUserNotFound)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/missing/activate", "", "missing")
+		handler.ActivateUser(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAdminUserHandler_ResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockUserService)
+	auditor := &recordingAuditor{}
+	handler := NewAdminUserHandler(mockService, nil, nil, auditor)
+	mockService.On("ResetPassword", "1").Return("Xk3v9QmZ2pLr7TfA", nil)
+
+	c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/1/password-reset", "", "1")
+	handler.ResetPassword(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Xk3v9QmZ2pLr7TfA")
+	require.Len(t, auditor.events, 1)
+	assert.Equal(t, audit.ActionPasswordReset, auditor.events[0].Action)
+	assert.NotContains(t, auditor.events[0].Details, "temporary_password")
+}
+
+func TestAdminUserHandler_AssignRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	require.NoError(t, validation.Setup())
+
+	t.Run("分配角色", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(mockService, nil, nil, auditor)
+		user := factory.User().WithID("1").WithEmail("john@example.com").WithUsername("john").Build()
+		user.IsAdmin = true
+		mockService.On("AssignRoles", "1", []string{"user", "admin"}, "admin").Return(user, nil)
+
+		c, w := newAdminContext(http.MethodPut, "/api/v1/admin/users/1/roles", `{"roles":["user","admin"]}`, "1")
+		handler.AssignRoles(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionRolesAssigned, auditor.events[0].Action)
+	})
+
+	t.Run("未知角色", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, nil, nil, nil)
+
+		c, w := newAdminContext(http.MethodPut, "/api/v1/admin/users/1/roles", `{"roles":["root"]}`, "1")
+		handler.AssignRoles(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "AssignRoles", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAdminUserHandler_Impersonate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := auth.NewJWTManager("test-secret", 24)
+
+	t.Run("签发模拟登录令牌", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(mockService, jwtManager, nil, auditor)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("john@example.com").WithUsername("john").Build(), nil)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/1/impersonate", "", "1")
+		handler.Impersonate(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data models.ImpersonationResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		claims, err := jwtManager.ValidateToken(body.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "1", claims.UserID)
+		assert.Equal(t, "admin", claims.Act.Subject)
+		assert.NotEmpty(t, claims.Banner)
+
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionImpersonationStarted, auditor.events[0].Action)
+		assert.Equal(t, audit.OutcomeSuccess, auditor.events[0].Outcome)
+	})
+
+	t.Run("不能模拟管理员", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(mockService, jwtManager, nil, auditor)
+		target := factory.User().WithID("2").WithEmail("root@example.com").WithUsername("root").Build()
+		target.IsAdmin = true
+		mockService.On("GetByID", "2").Return(target, nil)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/2/impersonate", "", "2")
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	})
+
+	t.Run("模拟登录期间不能再次模拟", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAdminUserHandler(mockService, jwtManager, nil, nil)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/1/impersonate", "", "1")
+		c.Set("impersonator_id", "other-admin")
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+
+	t.Run("不能模拟自己", func(t *testing.T) {
+		handler := NewAdminUserHandler(new(MockUserService), jwtManager, nil, nil)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/users/admin/impersonate", "", "admin")
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminUserHandler_StopImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("结束模拟登录并记录审计事件", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(new(MockUserService), nil, nil, auditor)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/impersonation/stop", "", "")
+		c.Set("user_id", "1")
+		c.Set("impersonator_id", "admin")
+		handler.StopImpersonation(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionImpersonationStopped, auditor.events[0].Action)
+		assert.Equal(t, "admin", auditor.events[0].ActorID)
+		assert.Equal(t, "1", auditor.events[0].TargetID)
+		assert.Equal(t, audit.OutcomeSuccess, auditor.events[0].Outcome)
+	})
+
+	t.Run("普通令牌不能结束模拟登录", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		handler := NewAdminUserHandler(new(MockUserService), nil, nil, auditor)
+
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/impersonation/stop", "", "")
+		handler.StopImpersonation(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, auditor.events)
+	})
+}
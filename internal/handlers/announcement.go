@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-server/internal/announcements"
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AnnouncementHandler 处理公告接口：客户端读取和订阅生效中的公告（/api/v1/announcements），
+// 管理员管理公告（/api/v1/admin/announcements）
+type AnnouncementHandler struct {
+	service        *announcements.Service
+	audit          audit.Recorder
+	streamInterval time.Duration
+}
+
+// NewAnnouncementHandler 创建公告处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+// streamInterval 为 SSE 连接重新检查公告和发送心跳的间隔，<=0 时推送接口返回 503
+func NewAnnouncementHandler(service *announcements.Service, recorder audit.Recorder, streamInterval time.Duration) *AnnouncementHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &AnnouncementHandler{
+		service:        service,
+		audit:          recorder,
+		streamInterval: streamInterval,
+	}
+}
+
+// ListAnnouncements godoc
+// @Summary 获取生效中的公告
+// @Description 返回当前对调用者生效的公告，按创建时间倒序。未登录时只返回不限角色的公告；租户取自用户所属租户或请求的租户。公告列表来自共享缓存，管理员修改后立即失效
+// @Tags announcements
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]models.Announcement} "成功获取公告"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/announcements [get]
+func (h *AnnouncementHandler) ListAnnouncements(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	viewer, err := h.service.Viewer(ctx, c.GetString("user_id"))
+	if err != nil {
+		response.DatabaseError(c, localize(c, "announcement.get_failed"), err)
+		return
+	}
+	visible, err := h.service.Visible(ctx, viewer)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "announcement.get_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "announcement.retrieved"), visible)
+}
+
+// StreamAnnouncements godoc
+// @Summary 订阅公告推送
+// @Description 以 Server-Sent Events 推送对调用者生效的公告。连接建立后立即发送一次 announcements 事件，之后在公告变化（发布、修改、删除、到达生效或过期时间）时再次发送，data 为完整的公告列表 JSON；公告没有变化时定期发送注释行作为心跳。其他实例上的修改在下一次定期检查时推送
+// @Tags announcements
+// @Produce json,event-stream
+// @Security BearerAuth
+// @Success 200 {string} string "公告事件流"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务或推送未启用"
+// @Router /api/v1/announcements/stream [get]
+func (h *AnnouncementHandler) StreamAnnouncements(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	if h.streamInterval <= 0 {
+		response.ServiceUnavailableError(c, "announcements_stream", localize(c, "announcement.stream_disabled"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	viewer, err := h.service.Viewer(ctx, c.GetString("user_id"))
+	if err != nil {
+		response.DatabaseError(c, localize(c, "announcement.get_failed"), err)
+		return
+	}
+
+	changes, stop := h.service.Watch()
+	defer stop()
+	ticker := time.NewTicker(h.streamInterval)
+	defer ticker.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	controller := http.NewResponseController(c.Writer)
+	var last []byte
+	send := func() bool {
+		// 服务器的写超时针对整个响应，每次写入前延长截止时间，使连接不会在写超时后被中断
+		_ = controller.SetWriteDeadline(time.Now().Add(2 * h.streamInterval))
+
+		var err error
+		data, loadErr := h.visibleJSON(c, viewer)
+		if loadErr != nil || bytes.Equal(data, last) {
+			// 读取失败时保持客户端已有的公告，只发送心跳
+			_, err = io.WriteString(c.Writer, ": ping\n\n")
+		} else {
+			last = data
+			_, err = fmt.Fprintf(c.Writer, "event: announcements\ndata: %s\n\n", data)
+		}
+		if err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-changes:
+			if !ok {
+				// 服务关闭，结束连接以便 HTTP 服务器排空
+				return
+			}
+		case <-ticker.C:
+		}
+		if !send() {
+			return
+		}
+	}
+}
+
+// visibleJSON 返回对查看者生效的公告的 JSON 编码
+func (h *AnnouncementHandler) visibleJSON(c *gin.Context, viewer announcements.Viewer) ([]byte, error) {
+	visible, err := h.service.Visible(c.Request.Context(), viewer)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(visible)
+}
+
+// AdminListAnnouncements godoc
+// @Summary 列出公告
+// @Description 分页列出全部公告，包括尚未生效和已过期的公告，按创建时间倒序（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Paginate default(20)
+// @Success 200 {object} models.SuccessResponse{data=models.AnnouncementListResponse} "成功获取公告"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/admin/announcements [get]
+func (h *AnnouncementHandler) AdminListAnnouncements(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		response.ValidationError(c, localize(c, "pagination.invalid_page"),
+			errors.ErrorDetails{Field: "page", Message: localize(c, "pagination.invalid_page"), Value: page})
+		return
+	}
+	if limit < 1 || limit > 100 {
+		response.ValidationError(c, localize(c, "pagination.invalid_limit"),
+			errors.ErrorDetails{Field: "limit", Message: localize(c, "pagination.invalid_limit"), Value: limit})
+		return
+	}
+
+	items, total, err := h.service.List(c.Request.Context(), page, limit)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "announcement.get_failed"), err)
+		return
+	}
+	list := make([]models.Announcement, len(items))
+	for i, item := range items {
+		list[i] = *item
+	}
+	response.Success(c, http.StatusOK, localize(c, "announcement.retrieved"), models.AnnouncementListResponse{
+		Announcements: list,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// AdminGetAnnouncement godoc
+// @Summary 获取公告
+// @Description 获取指定公告（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "公告ID"
+// @Success 200 {object} models.SuccessResponse{data=models.Announcement} "成功获取公告"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/admin/announcements/{id} [get]
+func (h *AnnouncementHandler) AdminGetAnnouncement(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := announcementID(c)
+	if !ok {
+		return
+	}
+	announcement, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.serviceError(c, id, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "announcement.retrieved"), announcement)
+}
+
+// CreateAnnouncement godoc
+// @Summary 发布公告
+// @Description 创建公告（仅管理员）。roles 为空时对所有用户可见，tenant_id 为空时对所有租户可见；starts_at 为空时立即生效，ends_at 为空时一直有效。本实例的订阅者立即收到推送
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AnnouncementRequest true "公告定义"
+// @Success 201 {object} models.SuccessResponse{data=models.Announcement} "公告已发布"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误或过期时间不晚于生效时间"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/admin/announcements [post]
+func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.AnnouncementRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	announcement := announcementFromRequest(&req)
+	announcement.CreatedBy = c.GetString("user_id")
+	err := h.service.Create(c.Request.Context(), announcement)
+	h.record(c, audit.ActionAnnouncementCreated, announcement, err)
+	if err != nil {
+		h.serviceError(c, "", err)
+		return
+	}
+	response.Success(c, http.StatusCreated, localize(c, "announcement.published"), announcement)
+}
+
+// UpdateAnnouncement godoc
+// @Summary 修改公告
+// @Description 替换公告的完整定义（仅管理员），本实例的订阅者立即收到推送，其他实例在缓存失效后的下一次检查时推送
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "公告ID"
+// @Param request body models.AnnouncementRequest true "公告定义"
+// @Success 200 {object} models.SuccessResponse{data=models.Announcement} "公告已修改"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误或过期时间不晚于生效时间"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/admin/announcements/{id} [put]
+func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := announcementID(c)
+	if !ok {
+		return
+	}
+	var req models.AnnouncementRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	announcement := announcementFromRequest(&req)
+	announcement.ID = id
+	updated, err := h.service.Update(c.Request.Context(), announcement)
+	h.record(c, audit.ActionAnnouncementUpdated, announcement, err)
+	if err != nil {
+		h.serviceError(c, id, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "announcement.updated"), updated)
+}
+
+// DeleteAnnouncement godoc
+// @Summary 删除公告
+// @Description 删除公告（仅管理员），要提前结束公告也可以把 ends_at 修改为当前时间
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "公告ID"
+// @Success 200 {object} models.SuccessResponse "公告已删除"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "公告服务未启用"
+// @Router /api/v1/admin/announcements/{id} [delete]
+func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := announcementID(c)
+	if !ok {
+		return
+	}
+	err := h.service.Delete(c.Request.Context(), id)
+	h.record(c, audit.ActionAnnouncementDeleted, &models.Announcement{ID: id}, err)
+	if err != nil {
+		h.serviceError(c, id, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "announcement.deleted"), nil)
+}
+
+// available 公告服务未启用时返回 503
+func (h *AnnouncementHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "announcements", localize(c, "announcement.disabled"))
+		return false
+	}
+	return true
+}
+
+// announcementID 返回路径中的公告ID，不是 UUID 时按不存在处理
+func announcementID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.NotFoundError(c, "announcement", id)
+		return "", false
+	}
+	return id, true
+}
+
+// announcementFromRequest 将请求转换为公告，时间统一为 UTC
+func announcementFromRequest(req *models.AnnouncementRequest) *models.Announcement {
+	announcement := &models.Announcement{
+		Title:          req.Title,
+		Body:           req.Body,
+		Level:          req.Level,
+		Roles:          req.Roles,
+		TargetTenantID: req.TenantID,
+	}
+	if req.StartsAt != nil {
+		startsAt := req.StartsAt.UTC()
+		announcement.StartsAt = &startsAt
+	}
+	if req.EndsAt != nil {
+		endsAt := req.EndsAt.UTC()
+		announcement.EndsAt = &endsAt
+	}
+	return announcement
+}
+
+// serviceError 将公告服务的错误转换为响应
+func (h *AnnouncementHandler) serviceError(c *gin.Context, id string, err error) {
+	switch {
+	case stderrors.Is(err, announcements.ErrNotFound):
+		response.NotFoundError(c, "announcement", id)
+	case stderrors.Is(err, announcements.ErrInvalidSchedule):
+		response.ValidationError(c, localize(c, "announcement.invalid_window"), errors.ErrorDetails{
+			Field:   "ends_at",
+			Message: err.Error(),
+		})
+	default:
+		response.DatabaseError(c, localize(c, "announcement.save_failed"), err)
+	}
+}
+
+// record 记录公告变更的审计事件，目标为公告ID
+func (h *AnnouncementHandler) record(c *gin.Context, action string, announcement *models.Announcement, err error) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   c.GetString("user_id"),
+		TargetID:  announcement.ID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if action != audit.ActionAnnouncementDeleted {
+		event.Details = map[string]interface{}{
+			"title": announcement.Title,
+			"level": announcement.Level,
+			"roles": announcement.Roles,
+		}
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
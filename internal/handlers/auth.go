@@ -1,117 +1,265 @@
 package handlers
 
 import (
+	stderrors "errors"
 	"net/http"
 	"strings"
 
+	"go-server/internal/metrics"
 	"go-server/internal/models"
 	"go-server/internal/services"
+	"go-server/internal/validation"
 	"go-server/pkg/auth"
 	"go-server/pkg/cache"
 	"go-server/pkg/errors"
+	"go-server/pkg/i18n"
 	"go-server/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 认证接口的错误消息，Message 使用英文，UserMessage 按 Accept-Language 选择
+var (
+	invalidCredentialsMessages   = i18n.Default().Messages("auth.invalid_credentials")
+	emailTakenMessages           = i18n.Default().Messages("auth.email_taken")
+	usernameTakenMessages        = i18n.Default().Messages("auth.username_taken")
+	invalidRefreshTokenMessages  = i18n.Default().Messages("auth.invalid_refresh_token")
+	missingAuthorizationMessages = i18n.Default().Messages("auth.missing_authorization")
+	invalidAuthorizationMessages = i18n.Default().Messages("auth.invalid_authorization")
+	invalidTokenMessages         = i18n.Default().Messages("auth.invalid_token")
+)
+
 type AuthHandler struct {
 	jwtManager       *auth.JWTManager
 	userService      services.UserService
 	blacklistService *cache.BlacklistService
+	metrics          *metrics.AuthMetrics
 }
 
-func NewAuthHandler(jwtManager *auth.JWTManager, userService services.UserService, blacklistService *cache.BlacklistService) *AuthHandler {
+// NewAuthHandler 创建认证处理器，blacklistService 为 nil 时登出和刷新不吊销令牌，authMetrics 为 nil 时不统计认证结果
+func NewAuthHandler(jwtManager *auth.JWTManager, userService services.UserService, blacklistService *cache.BlacklistService, authMetrics *metrics.AuthMetrics) *AuthHandler {
 	return &AuthHandler{
 		jwtManager:       jwtManager,
 		userService:      userService,
 		blacklistService: blacklistService,
+		metrics:          authMetrics,
 	}
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user with email and password and return an access token together with a refresh token.
+// @Description Use the refresh token with POST /api/v1/auth/refresh to obtain a new pair before the access token expires.
+// @Description Error messages are localized according to the Accept-Language header (en, zh).
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param loginRequest body models.LoginRequest true "Login credentials"
 // @Success 200 {object} models.SuccessResponse{data=models.LoginResponse} "Login successful"
-// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid input data"
-// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Invalid credentials"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid input data, with field-level details"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Invalid credentials or deactivated account"
 // @Header 200 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 400 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 401 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request format: "+err.Error())
+	if !validation.BindJSON(c, &req) {
+		h.metrics.Record(metrics.AuthOperationLogin, metrics.AuthOutcomeInvalidRequest)
 		return
 	}
 
 	// Validate credentials using user service
-	user, err := h.userService.Login(&req)
+	user, err := h.userService.Login(c.Request.Context(), &req)
 	if err != nil {
-		response.UnauthorizedError(c, "Invalid credentials")
+		// 不区分用户不存在、密码错误和账户停用，避免泄露账户是否存在
+		h.metrics.Record(metrics.AuthOperationLogin, metrics.AuthOutcomeInvalidCredentials)
+		h.localizedError(c, errors.NewUnauthorizedError(invalidCredentialsMessages[validation.LanguageEnglish]), invalidCredentialsMessages)
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Username, user.Email)
+	tokens, err := h.issueTokens(user)
 	if err != nil {
-		response.InternalServerErrorWithCause(c, "Failed to generate token", err)
+		h.metrics.Record(metrics.AuthOperationLogin, metrics.AuthOutcomeError)
+		response.InternalServerErrorWithCause(c, localize(c, "auth.generate_token_failed"), err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Login successful", models.LoginResponse{
-		Token: token,
-		User:  user.ToSafeUser(),
+	h.metrics.Record(metrics.AuthOperationLogin, metrics.AuthOutcomeSuccess)
+	response.Success(c, http.StatusOK, localize(c, "auth.login_succeeded"), models.LoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user.ToSafeUser(),
 	})
 }
 
 // Register godoc
 // @Summary Register new user
-// @Description Register a new user account
+// @Description Register a new user account. The username may contain letters, digits, underscores, dots and hyphens; the password must satisfy the password policy.
+// @Description Error messages are localized according to the Accept-Language header (en, zh).
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param registerRequest body models.RegisterRequest true "User registration data"
 // @Success 201 {object} models.SuccessResponse{data=models.SafeUser} "Registration successful"
-// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid input data with field-level details"
-// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Conflict error - User already exists"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid input data or a password that does not meet the password policy, with field-level details and suggestions"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Conflict error - Email already registered or username already taken"
 // @Header 201 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 400 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 409 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Router /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request format: "+err.Error())
+	if !validation.BindJSON(c, &req) {
+		h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeInvalidRequest)
 		return
 	}
 
 	// Create user using user service
-	user, err := h.userService.Register(&req)
+	user, err := h.userService.Register(c.Request.Context(), &req)
 	if err != nil {
+		if passwordPolicyError(c, "password", err) {
+			h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeInvalidRequest)
+			return
+		}
 		if err.Error() == "user with this email already exists" {
-			response.ConflictError(c, "Email already registered", map[string]interface{}{
+			h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeConflict)
+			h.localizedError(c, errors.NewConflictError(emailTakenMessages[validation.LanguageEnglish], map[string]interface{}{
 				"field": "email",
 				"value": req.Email,
-			})
+			}), emailTakenMessages)
 			return
 		}
 		if err.Error() == "username already taken" {
-			response.ConflictError(c, "Username already taken", map[string]interface{}{
+			h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeConflict)
+			h.localizedError(c, errors.NewConflictError(usernameTakenMessages[validation.LanguageEnglish], map[string]interface{}{
 				"field": "username",
 				"value": req.Username,
-			})
+			}), usernameTakenMessages)
+			return
+		}
+		h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeError)
+		response.InternalServerErrorWithCause(c, localize(c, "auth.register_failed"), err)
+		return
+	}
+
+	h.metrics.Record(metrics.AuthOperationRegister, metrics.AuthOutcomeSuccess)
+	response.Created(c, localize(c, "auth.registered"), user.ToSafeUser())
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and refresh token. The refresh token is rotated: the submitted token is revoked and cannot be used again.
+// @Description Refresh tokens are rejected after logout, after the user changes their password and once the account is deleted or deactivated.
+// @Description Error messages are localized according to the Accept-Language header (en, zh).
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refreshTokenRequest body models.RefreshTokenRequest true "Refresh token returned by login or the previous refresh"
+// @Success 200 {object} models.SuccessResponse{data=models.RefreshTokenResponse} "Token refreshed"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Missing refresh token"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Invalid, expired, revoked or already used refresh token"
+// @Failure 500 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Failed to check or revoke the refresh token"
+// @Header 200 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 400 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 401 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 500 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !validation.BindJSON(c, &req) {
+		h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeInvalidRequest)
+		return
+	}
+
+	ctx := c.Request.Context()
+	claims, err := h.jwtManager.ValidateRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		h.invalidRefreshToken(c, err.Error())
+		return
+	}
+
+	// 管理器未配置黑名单时（如测试环境）也要拒绝已轮换和已吊销的刷新令牌
+	if h.blacklistService != nil {
+		blacklisted, err := h.blacklistService.IsBlacklisted(ctx, req.RefreshToken)
+		if err != nil {
+			h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeError)
+			response.CacheError(c, localize(c, "auth.check_refresh_token_failed"), err)
+			return
+		}
+		if blacklisted {
+			h.invalidRefreshToken(c, "token is blacklisted")
+			return
+		}
+	}
+
+	// 重新读取用户，账户删除或停用后不再签发令牌，并使用最新的用户名和邮箱
+	user, err := h.userService.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			h.invalidRefreshToken(c, "user not found")
+			return
+		}
+		h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeError)
+		response.InternalServerErrorWithCause(c, localize(c, "auth.refresh_failed"), err)
+		return
+	}
+	if !user.IsActive {
+		h.invalidRefreshToken(c, "account is deactivated")
+		return
+	}
+
+	// 先吊销原刷新令牌再签发新令牌，吊销失败时不签发，避免同一刷新令牌被重复使用
+	if h.blacklistService != nil {
+		if err := h.blacklistService.AddToBlacklist(ctx, req.RefreshToken); err != nil {
+			h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeError)
+			response.CacheError(c, localize(c, "auth.revoke_refresh_token_failed"), err)
 			return
 		}
-		response.InternalServerErrorWithCause(c, "Failed to register user", err)
+	}
+
+	tokens, err := h.issueTokens(user)
+	if err != nil {
+		h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeError)
+		response.InternalServerErrorWithCause(c, localize(c, "auth.generate_token_failed"), err)
 		return
 	}
 
-	response.Created(c, "User registered successfully", user.ToSafeUser())
+	h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeSuccess)
+	response.Success(c, http.StatusOK, localize(c, "auth.token_refreshed"), tokens)
+}
+
+// issueTokens 为用户签发访问令牌和刷新令牌
+func (h *AuthHandler) issueTokens(user *models.User) (*models.RefreshTokenResponse, error) {
+	var tenantID string
+	if user.TenantID != nil {
+		tenantID = *user.TenantID
+	}
+	token, err := h.jwtManager.GenerateTenantToken(user.ID, user.Username, user.Email, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := h.jwtManager.GenerateRefreshToken(user.ID, user.Username, user.Email, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RefreshTokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(h.jwtManager.ExpiresIn().Seconds()),
+	}, nil
+}
+
+// invalidRefreshToken 写入刷新令牌无效的 401 响应，reason 只用于内部诊断
+func (h *AuthHandler) invalidRefreshToken(c *gin.Context, reason string) {
+	h.metrics.Record(metrics.AuthOperationRefresh, metrics.AuthOutcomeInvalidToken)
+	h.localizedError(c, errors.NewInvalidTokenError(reason), invalidRefreshTokenMessages)
+}
+
+// localizedError 写入错误响应，UserMessage 使用 Accept-Language 对应的消息
+func (h *AuthHandler) localizedError(c *gin.Context, appErr *errors.AppError, messages map[string]string) {
+	response.ErrorWithAppError(c, validation.Localize(appErr, messages, validation.Language(c)))
 }
 
 // Me godoc
@@ -129,18 +277,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Me(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		response.UnauthorizedError(c, "User not authenticated")
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
 		return
 	}
 
 	// Get user from database using user service
-	user, err := h.userService.GetByID(userID.(string))
+	user, err := h.userService.GetByID(c.Request.Context(), userID.(string))
 	if err != nil {
 		response.NotFoundError(c, "User", userID.(string))
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User profile retrieved successfully", user.ToSafeUser())
+	response.Success(c, http.StatusOK, localize(c, "auth.profile_retrieved"), user.ToSafeUser())
 }
 
 // ChangePassword godoc
@@ -152,51 +300,72 @@ func (h *AuthHandler) Me(c *gin.Context) {
 // @Security BearerAuth
 // @Param changePasswordRequest body models.ChangePasswordRequest true "Password change data"
 // @Success 200 {object} models.SuccessResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input, old password is incorrect or the new password does not meet the password policy"
 // @Failure 401 {object} models.ErrorResponse
 // @Router /api/v1/auth/change-password [post]
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	var req models.ChangePasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request format: "+err.Error())
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		response.UnauthorizedError(c, "User not authenticated")
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
 		return
 	}
 
 	// Change password using user service
-	if err := h.userService.ChangePassword(userID.(string), &req); err != nil {
+	if err := h.userService.ChangePassword(c.Request.Context(), userID.(string), &req); err != nil {
 		if err.Error() == "old password is incorrect" {
-			response.ValidationError(c, "Old password is incorrect",
-				errors.ErrorDetails{Field: "old_password", Message: "Old password is incorrect"})
+			response.ValidationError(c, localize(c, "auth.incorrect_old_password"),
+				errors.ErrorDetails{Field: "old_password", Message: localize(c, "auth.incorrect_old_password")})
+			return
+		}
+		if passwordPolicyError(c, "new_password", err) {
 			return
 		}
-		response.InternalServerErrorWithCause(c, "Failed to change password", err)
+		response.InternalServerErrorWithCause(c, localize(c, "auth.change_password_failed"), err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Password changed successfully", nil)
+	response.Success(c, http.StatusOK, localize(c, "auth.password_changed"), nil)
+}
+
+// passwordPolicyError 新密码不满足密码策略时写入 400 响应并返回 true，每条违反的规则对应一个字段错误，附带修复建议
+func passwordPolicyError(c *gin.Context, field string, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	if !stderrors.As(err, &policyErr) {
+		return false
+	}
+	response.ErrorWithAppError(c, validation.PasswordPolicyError(field, policyErr.Violations, validation.Language(c)))
+	return true
 }
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout the current user by blacklisting their JWT token
+// @Description Logout the current user by blacklisting their access token. Pass the refresh token in the optional request body to revoke it as well; otherwise it stays valid until it expires.
+// @Description Error messages are localized according to the Accept-Language header (en, zh).
 // @Tags auth
+// @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} models.SuccessResponse
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Param logoutRequest body models.LogoutRequest false "Refresh token to revoke together with the access token"
+// @Success 200 {object} models.SuccessResponse{data=models.LogoutResponse} "Logout successful"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Malformed request body"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing, malformed or invalid access token, or a refresh token issued to another user"
+// @Failure 500 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Failed to blacklist the tokens"
+// @Header 200 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 400 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 401 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 500 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Extract the token from the Authorization header
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		response.UnauthorizedError(c, "Authorization header is required")
+		h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeInvalidToken)
+		h.localizedError(c, errors.NewUnauthorizedError(missingAuthorizationMessages[validation.LanguageEnglish]), missingAuthorizationMessages)
 		return
 	}
 
@@ -204,30 +373,72 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 	if tokenString == authHeader {
 		// No "Bearer " prefix found
-		response.InvalidTokenError(c, "Invalid authorization header format, expected 'Bearer <token>'")
+		h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeInvalidToken)
+		h.localizedError(c, errors.NewInvalidTokenError(invalidAuthorizationMessages[validation.LanguageEnglish]), invalidAuthorizationMessages)
+		return
+	}
+
+	// 请求体可以省略，携带时同时吊销其中的刷新令牌
+	var req models.LogoutRequest
+	if c.Request.ContentLength != 0 && !validation.BindJSON(c, &req) {
+		h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeInvalidRequest)
 		return
 	}
 
 	// Validate the token before blacklisting to ensure it's a valid token
 	claims, err := h.jwtManager.ValidateToken(tokenString)
 	if err != nil {
-		response.InvalidTokenError(c, "Invalid or expired token: "+err.Error())
+		h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeInvalidToken)
+		h.localizedError(c, errors.NewInvalidTokenError(err.Error()), invalidTokenMessages)
 		return
 	}
 
-	// Add the token to the blacklist
+	// 只能吊销自己的刷新令牌
+	if req.RefreshToken != "" {
+		refreshClaims, err := h.jwtManager.ValidateRefreshToken(c.Request.Context(), req.RefreshToken)
+		if err != nil || refreshClaims.UserID != claims.UserID {
+			reason := "refresh token belongs to another user"
+			if err != nil {
+				reason = err.Error()
+			}
+			h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeInvalidToken)
+			h.localizedError(c, errors.NewInvalidTokenError(reason), invalidRefreshTokenMessages)
+			return
+		}
+	}
+
+	// Add the tokens to the blacklist
 	if h.blacklistService != nil {
-		if err := h.blacklistService.AddToBlacklist(c.Request.Context(), tokenString); err != nil {
-			// Log the cache error but still return success to the user
-			// The token will naturally expire, so this is not a critical failure
-			response.CacheError(c, "Failed to blacklist token", err)
+		for _, token := range []string{tokenString, req.RefreshToken} {
+			if token == "" {
+				continue
+			}
+			if err := h.blacklistService.AddToBlacklist(c.Request.Context(), token); err != nil {
+				// 令牌未能吊销时不能报告登出成功，否则客户端会误以为令牌已失效
+				h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeError)
+				response.CacheError(c, localize(c, "auth.blacklist_token_failed"), err)
+				return
+			}
 		}
 	}
 
 	// Return success response
-	response.Success(c, http.StatusOK, "Logout successful", map[string]interface{}{
-		"user_id":  claims.UserID,
-		"username": claims.Username,
-		"email":    claims.Email,
+	h.metrics.Record(metrics.AuthOperationLogout, metrics.AuthOutcomeSuccess)
+	response.Success(c, http.StatusOK, localize(c, "auth.logout_succeeded"), models.LogoutResponse{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Email:    claims.Email,
 	})
 }
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Publish the public keys used to sign access tokens so that other services can verify them. Keys are identified by the kid token header; HMAC keys are never published.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go-server/internal/services"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+	"go-server/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// avatarFormField 上传头像的表单字段名
+const avatarFormField = "avatar"
+
+// avatarRedirectMaxAge 未签名时头像跳转的缓存时间（秒），头像更新后最多在该时间内仍跳转到旧头像
+const avatarRedirectMaxAge = 60
+
+// AvatarHandler 处理用户头像上传和访问
+type AvatarHandler struct {
+	userService         services.UserService
+	storage             storage.Storage
+	maxUploadSize       int64
+	allowedContentTypes []string
+	signer              *storage.URLSigner
+}
+
+// NewAvatarHandler 创建头像处理器，storage 为 nil 时上传接口返回 503
+// signer 不为 nil 时用户资料中的头像地址指向 GET /api/v1/users/{id}/avatar，由该接口跳转到带签名的媒体地址
+func NewAvatarHandler(userService services.UserService, store storage.Storage, maxUploadSize int64, allowedContentTypes []string, signer *storage.URLSigner) *AvatarHandler {
+	return &AvatarHandler{
+		userService:         userService,
+		storage:             store,
+		maxUploadSize:       maxUploadSize,
+		allowedContentTypes: allowedContentTypes,
+		signer:              signer,
+	}
+}
+
+// UploadAvatar godoc
+// @Summary 上传当前用户头像
+// @Description 以 multipart/form-data 流式上传头像，文件直接写入对象存储而不缓存在内存或临时文件中。内容类型根据文件头探测，不信任客户端声明的类型。上传成功后旧头像将被删除。返回的头像地址带有内容哈希（v 参数），内容变化时地址随之变化，可以长期缓存；启用签名链接时头像地址为 GET /api/v1/users/{id}/avatar。
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param avatar formData file true "头像文件（JPEG、PNG、GIF、WebP）"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "头像上传成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 413 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "文件过大"
+// @Failure 415 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "不支持的文件类型"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "对象存储不可用"
+// @Header 200 {string} X-Correlation-ID "请求追踪的唯一标识符"
+// @Router /api/v1/users/me/avatar [post]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
+		return
+	}
+
+	if h.storage == nil {
+		response.ServiceUnavailableError(c, "storage", localize(c, "avatar.storage_unavailable"))
+		return
+	}
+
+	part, err := h.findAvatarPart(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			h.respondUploadError(c, err)
+			return
+		}
+		response.ValidationError(c, localize(c, "avatar.invalid_form"),
+			errors.ErrorDetails{Field: avatarFormField, Message: err.Error()})
+		return
+	}
+	defer part.Close()
+
+	// 限制读取大小并根据文件头探测真实内容类型
+	limited := storage.LimitReader(part, h.maxUploadSize)
+	contentType, reader, err := storage.DetectContentType(limited)
+	if err != nil {
+		h.respondUploadError(c, err)
+		return
+	}
+	if err := storage.ValidateContentType(contentType, h.allowedContentTypes); err != nil {
+		h.respondUploadError(c, err)
+		return
+	}
+
+	// 写入的同时计算内容哈希，加入头像地址
+	hashing := storage.NewHashingReader(reader)
+	key := storage.GenerateKey("avatars", userID.(string), storage.ExtensionForContentType(contentType))
+	object, err := h.storage.Put(c.Request.Context(), key, hashing, -1, contentType)
+	if err != nil {
+		h.respondUploadError(c, err)
+		return
+	}
+
+	avatarURL := storage.VersionedURL(object.URL, hashing.Sum())
+	if h.signer != nil {
+		avatarURL = storage.VersionedURL("/api/v1/users/"+url.PathEscape(userID.(string))+"/avatar", hashing.Sum())
+	}
+
+	user, previousKey, err := h.userService.UpdateAvatar(c.Request.Context(), userID.(string), object.Key, avatarURL)
+	if err != nil {
+		// 用户更新失败时清理已上传的对象
+		h.storage.Delete(context.Background(), object.Key)
+		response.DatabaseError(c, localize(c, "avatar.update_failed"), err)
+		return
+	}
+
+	if previousKey != "" && previousKey != object.Key {
+		h.storage.Delete(context.Background(), previousKey)
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "avatar.uploaded"), user.ToSafeUser())
+}
+
+// GetAvatar godoc
+// @Summary 获取用户头像
+// @Description 跳转到用户头像的媒体地址，不需要访问令牌，可直接用于 img 标签。启用签名链接时跳转到带有效期的签名地址，跳转本身按签名有效期的一半私有缓存；否则跳转到带内容哈希的公开地址。媒体文件以 immutable 长期缓存，不经过压缩和请求日志中间件。
+// @Tags users
+// @Produce json
+// @Param id path string true "用户ID"
+// @Param v query string false "内容哈希，仅用于区分头像版本，不影响跳转目标"
+// @Success 302 "跳转到头像地址"
+// @Header 302 {string} Location "头像的媒体地址"
+// @Header 302 {string} Cache-Control "跳转的缓存策略"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在或未设置头像"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "对象存储不可用"
+// @Router /api/v1/users/{id}/avatar [get]
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	if h.storage == nil {
+		response.ServiceUnavailableError(c, "storage", localize(c, "avatar.storage_unavailable"))
+		return
+	}
+
+	userID := c.Param("id")
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if _, transient := errors.RetryAfterHint(err); transient {
+			response.DatabaseError(c, localize(c, "user.get_failed"), err)
+			return
+		}
+		response.NotFoundError(c, "User", userID)
+		return
+	}
+	if user.AvatarKey == "" {
+		response.NotFoundError(c, "Avatar", userID)
+		return
+	}
+
+	// 与 redirect 相同不写入响应体，但跳转允许缓存
+	target := storage.VersionedURL(h.storage.URL(user.AvatarKey), avatarVersion(user.Avatar))
+	if h.signer == nil {
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(avatarRedirectMaxAge))
+		c.Header("Location", target)
+		c.Status(http.StatusFound)
+		return
+	}
+
+	signed, err := h.signer.Sign(target)
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "avatar.url_failed"), err)
+		return
+	}
+	// 缓存的跳转在签名过期之前失效
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(h.signer.TTL().Seconds()/2)))
+	c.Header("Location", signed)
+	c.Status(http.StatusFound)
+}
+
+// avatarVersion 返回头像地址中的内容哈希
+func avatarVersion(avatarURL string) string {
+	u, err := url.Parse(avatarURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("v")
+}
+
+// findAvatarPart 流式读取 multipart 请求体，返回头像文件所在的部分
+func (h *AvatarHandler) findAvatarPart(c *gin.Context) (io.ReadCloser, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return nil, stderrors.New("missing avatar file")
+			}
+			return nil, err
+		}
+		if part.FormName() == avatarFormField && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// respondUploadError 将存储错误映射为对应的 HTTP 状态码
+func (h *AvatarHandler) respondUploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case stderrors.As(err, &maxBytesErr):
+		// 整个请求体超过了请求体大小限制中间件的上限
+		response.PayloadTooLargeError(c, maxBytesErr.Limit)
+	case stderrors.Is(err, storage.ErrTooLarge):
+		appError := errors.NewValidationError(localize(c, "avatar.too_large"),
+			errors.ErrorDetails{Field: avatarFormField, Message: localize(c, "avatar.too_large"), Value: h.maxUploadSize})
+		appError.StatusCode = http.StatusRequestEntityTooLarge
+		response.ErrorWithAppError(c, appError)
+	case stderrors.Is(err, storage.ErrUnsupportedContent):
+		appError := errors.NewValidationError(localize(c, "avatar.unsupported_type"),
+			errors.ErrorDetails{Field: avatarFormField, Message: err.Error(), Value: h.allowedContentTypes})
+		appError.StatusCode = http.StatusUnsupportedMediaType
+		response.ErrorWithAppError(c, appError)
+	default:
+		response.InternalServerErrorWithCause(c, localize(c, "avatar.save_failed"), err)
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/pkg/storage"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// pngFile 最小的 PNG 文件头
+var pngFile = append([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'}, make([]byte, 64)...)
+
+// newAvatarRequest 构造包含头像文件的 multipart 请求
+func newAvatarRequest(t *testing.T, field string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "avatar.png")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	allowed := []string{"image/png", "image/jpeg"}
+
+	newStore := func(t *testing.T) *storage.LocalStorage {
+		store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+		require.NoError(t, err)
+		return store
+	}
+
+	t.Run("成功上传头像并删除旧头像", func(t *testing.T) {
+		mockService := new(MockUserService)
+		store := newStore(t)
+		handler := NewAvatarHandler(mockService, store, 1024, allowed, nil)
+
+		_, err := store.Put(t.Context(), "avatars/1/old.png", bytes.NewReader(pngFile), -1, "image/png")
+		require.NoError(t, err)
+
+		user := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build()
+		mockService.On("UpdateAvatar", "1", mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "avatars/1/") && strings.HasSuffix(key, ".png")
+		}), mock.MatchedBy(func(url string) bool {
+			// 地址带有内容哈希
+			return strings.HasPrefix(url, "/uploads/avatars/1/") && strings.Contains(url, ".png?v=")
+		})).Return(user, "avatars/1/old.png", nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "avatar", pngFile)
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		exists, err := store.Exists(t.Context(), "avatars/1/old.png")
+		require.NoError(t, err)
+		assert.False(t, exists, "previous avatar should be removed")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("不支持的文件类型", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAvatarHandler(mockService, newStore(t), 1024, allowed, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "avatar", []byte("<html><body>not an image</body></html>"))
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		mockService.AssertNotCalled(t, "UpdateAvatar", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("文件过大", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewAvatarHandler(mockService, newStore(t), 32, allowed, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "avatar", pngFile)
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("缺少头像文件", func(t *testing.T) {
+		handler := NewAvatarHandler(new(MockUserService), newStore(t), 1024, allowed, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "file", pngFile)
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("存储不可用", func(t *testing.T) {
+		handler := NewAvatarHandler(new(MockUserService), nil, 1024, allowed, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "avatar", pngFile)
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("启用签名链接时头像地址指向跳转接口", func(t *testing.T) {
+		mockService := new(MockUserService)
+		signer := storage.NewURLSigner([]byte("media-key"), time.Hour)
+		handler := NewAvatarHandler(mockService, newStore(t), 1024, allowed, signer)
+
+		user := factory.User().WithID("1").Build()
+		mockService.On("UpdateAvatar", "1", mock.AnythingOfType("string"), mock.MatchedBy(func(url string) bool {
+			return strings.HasPrefix(url, "/api/v1/users/1/avatar?v=")
+		})).Return(user, "", nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newAvatarRequest(t, "avatar", pngFile)
+		c.Set("user_id", "1")
+
+		handler.UploadAvatar(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestAvatarHandler_GetAvatar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+	require.NoError(t, err)
+
+	withAvatar := factory.User().WithID("1").Build()
+	withAvatar.AvatarKey = "avatars/1/a.png"
+	withAvatar.Avatar = "/uploads/avatars/1/a.png?v=0123456789abcdef"
+	withoutAvatar := factory.User().WithID("2").Build()
+
+	getAvatar := func(handler *AvatarHandler, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/"+id+"/avatar", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		handler.GetAvatar(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	t.Run("跳转到带内容哈希的地址", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetByID", "1").Return(withAvatar, nil)
+		handler := NewAvatarHandler(mockService, store, 1024, nil, nil)
+
+		w := getAvatar(handler, "1")
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/uploads/avatars/1/a.png?v=0123456789abcdef", w.Header().Get("Location"))
+		assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("启用签名链接时跳转到签名地址", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetByID", "1").Return(withAvatar, nil)
+		signer := storage.NewURLSigner([]byte("media-key"), time.Hour)
+		handler := NewAvatarHandler(mockService, store, 1024, nil, signer)
+
+		w := getAvatar(handler, "1")
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "private, max-age=1800", w.Header().Get("Cache-Control"))
+		location, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", location.Query().Get("v"))
+		assert.NoError(t, signer.Verify(location.EscapedPath(), location.Query()))
+	})
+
+	t.Run("未设置头像", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetByID", "2").Return(withoutAvatar, nil)
+		handler := NewAvatarHandler(mockService, store, 1024, nil, nil)
+
+		assert.Equal(t, http.StatusNotFound, getAvatar(handler, "2").Code)
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockService.On("GetByID", "3").Return(nil, errors.New("user not found"))
+		handler := NewAvatarHandler(mockService, store, 1024, nil, nil)
+
+		assert.Equal(t, http.StatusNotFound, getAvatar(handler, "3").Code)
+	})
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"go-server/internal/audit"
+	"go-server/internal/backups"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BackupHandler 处理数据库备份接口：管理员创建、查询和下载备份（/api/v1/admin/backups），
+// 从备份恢复数据库并查询恢复状态（/api/v1/admin/restore）
+type BackupHandler struct {
+	service *backups.Service
+	audit   audit.Recorder
+}
+
+// NewBackupHandler 创建备份处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewBackupHandler(service *backups.Service, recorder audit.Recorder) *BackupHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &BackupHandler{service: service, audit: recorder}
+}
+
+// CreateBackup godoc
+// @Summary 创建数据库备份
+// @Description 在后台执行 pg_dump 并把加密后的备份文件保存到对象存储（仅管理员）。轮询 GET /api/v1/admin/backups/{id} 查看进度；每个实例同一时间只执行一个备份或恢复
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.SuccessResponse{data=models.Backup} "备份任务已创建"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "本实例正在执行备份或恢复"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/backups [post]
+func (h *BackupHandler) CreateBackup(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	backup, err := h.service.Create(c.Request.Context(), c.GetString("user_id"))
+	event := h.event(c, audit.ActionBackupCreated, err)
+	if backup != nil {
+		event.TargetID = backup.ID
+	}
+	h.audit.Record(c.Request.Context(), event)
+	switch {
+	case stderrors.Is(err, backups.ErrBusy):
+		response.ConflictError(c, localize(c, "backup.busy"), nil)
+	case stderrors.Is(err, backups.ErrClosed):
+		response.ServiceUnavailableError(c, "backups", localize(c, "service.shutting_down"))
+	case err != nil:
+		response.DatabaseError(c, localize(c, "backup.create_failed"), err)
+	default:
+		response.Success(c, http.StatusAccepted, localize(c, "backup.created"), backup)
+	}
+}
+
+// ListBackups godoc
+// @Summary 列出数据库备份
+// @Description 分页列出手动和自动创建的备份，包括执行中和失败的备份，按创建时间倒序（仅管理员）。过期的备份由定期清理删除
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Paginate default(20)
+// @Success 200 {object} models.SuccessResponse{data=models.BackupListResponse} "成功获取备份"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/backups [get]
+func (h *BackupHandler) ListBackups(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		response.ValidationError(c, localize(c, "pagination.invalid_page"),
+			errors.ErrorDetails{Field: "page", Message: localize(c, "pagination.invalid_page"), Value: page})
+		return
+	}
+	if limit < 1 || limit > 100 {
+		response.ValidationError(c, localize(c, "pagination.invalid_limit"),
+			errors.ErrorDetails{Field: "limit", Message: localize(c, "pagination.invalid_limit"), Value: limit})
+		return
+	}
+
+	items, total, err := h.service.List(c.Request.Context(), page, limit)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "backup.get_failed"), err)
+		return
+	}
+	list := make([]models.Backup, len(items))
+	for i, item := range items {
+		list[i] = *item
+	}
+	response.Success(c, http.StatusOK, localize(c, "backup.retrieved"), models.BackupListResponse{
+		Backups: list,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// GetBackup godoc
+// @Summary 查询数据库备份
+// @Description 返回备份的状态、大小和 SHA-256 校验和（仅管理员），校验和针对解密后的备份文件
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "备份ID"
+// @Success 200 {object} models.SuccessResponse{data=models.Backup} "成功获取备份"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/backups/{id} [get]
+func (h *BackupHandler) GetBackup(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+	backup, err := h.service.Get(c.Request.Context(), id)
+	if stderrors.Is(err, backups.ErrNotFound) {
+		response.NotFoundError(c, "backup", id)
+		return
+	}
+	if err != nil {
+		response.DatabaseError(c, localize(c, "backup.get_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "backup.retrieved"), backup)
+}
+
+// DownloadBackup godoc
+// @Summary 下载数据库备份
+// @Description 下载解密后的备份文件（pg_dump 自定义格式，可用 pg_restore 导入）（仅管理员）。文件在传输过程中逐块校验，文件损坏时连接在中途断开
+// @Tags admin
+// @Produce json,octet-stream
+// @Security BearerAuth
+// @Param id path string true "备份ID"
+// @Success 200 {string} string "备份文件"
+// @Header 200 {string} Content-Disposition "attachment; filename=backup-20260102-030405.dump"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份不存在、未完成或文件已删除"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/backups/{id}/download [get]
+func (h *BackupHandler) DownloadBackup(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+	reader, backup, err := h.service.Open(c.Request.Context(), id)
+	switch {
+	case stderrors.Is(err, backups.ErrNotFound):
+		response.NotFoundError(c, "backup", id)
+		return
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "backup.read_failed"), err)
+		return
+	}
+	defer reader.Close()
+
+	// 备份包含全部数据，记录每一次下载
+	event := h.event(c, audit.ActionBackupDownloaded, nil)
+	event.TargetID = backup.ID
+	h.audit.Record(c.Request.Context(), event)
+
+	c.DataFromReader(http.StatusOK, backup.Size, backups.ContentType, reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": backups.FileName(backup)}),
+		"Cache-Control":       "private, no-store",
+	})
+}
+
+// RestoreBackup godoc
+// @Summary 从备份恢复数据库
+// @Description 在后台用已完成的备份替换数据库的内容（仅管理员），confirm 须与路径中的备份ID相同。恢复在单个事务中执行，失败时数据库保持恢复前的状态；备份列表不受恢复影响。恢复期间其他实例仍在处理请求，应先开启维护模式。轮询 GET /api/v1/admin/restore 查看进度
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "备份ID"
+// @Param request body models.RestoreBackupRequest true "确认要恢复的备份ID"
+// @Success 202 {object} models.SuccessResponse{data=models.RestoreStatus} "恢复已开始"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误或确认的备份ID不一致"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份不存在"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份未完成，或本实例正在执行备份或恢复"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/backups/{id}/restore [post]
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+	var req models.RestoreBackupRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if req.Confirm != id {
+		response.ValidationError(c, localize(c, "backup.confirm_mismatch"), errors.ErrorDetails{
+			Field:   "confirm",
+			Message: localize(c, "backup.confirm_detail"),
+			Value:   req.Confirm,
+		})
+		return
+	}
+
+	status, err := h.service.Restore(c.Request.Context(), id, c.GetString("user_id"))
+	event := h.event(c, audit.ActionBackupRestored, err)
+	event.TargetID = id
+	h.audit.Record(c.Request.Context(), event)
+	switch {
+	case stderrors.Is(err, backups.ErrNotFound):
+		response.NotFoundError(c, "backup", id)
+	case stderrors.Is(err, backups.ErrNotCompleted):
+		response.ConflictError(c, localize(c, "backup.not_completed"), nil)
+	case stderrors.Is(err, backups.ErrBusy):
+		response.ConflictError(c, localize(c, "backup.busy"), nil)
+	case stderrors.Is(err, backups.ErrClosed):
+		response.ServiceUnavailableError(c, "backups", localize(c, "service.shutting_down"))
+	case err != nil:
+		response.DatabaseError(c, localize(c, "backup.restore_failed"), err)
+	default:
+		response.Success(c, http.StatusAccepted, localize(c, "backup.restore_started"), status)
+	}
+}
+
+// GetRestoreStatus godoc
+// @Summary 查询恢复状态
+// @Description 返回本实例最近一次从备份恢复的状态（仅管理员），本实例没有执行过恢复时 state 为 idle。恢复由接收请求的实例执行，多实例部署时须在同一实例上查询
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=models.RestoreStatus} "成功获取恢复状态"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "备份服务未启用"
+// @Router /api/v1/admin/restore [get]
+func (h *BackupHandler) GetRestoreStatus(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "backup.restore_status_retrieved"), h.service.RestoreStatus())
+}
+
+// available 备份服务未启用时返回 503
+func (h *BackupHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "backups", localize(c, "backup.disabled"))
+		return false
+	}
+	return true
+}
+
+// backupID 返回路径中的备份ID，不是 UUID 时按不存在处理
+func backupID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.NotFoundError(c, "backup", id)
+		return "", false
+	}
+	return id, true
+}
+
+// event 返回管理员操作备份的审计事件，err 不为 nil 时结果为失败
+func (h *BackupHandler) event(c *gin.Context, action string, err error) audit.Event {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	return event
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/pkg/banlist"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BanListHandler 处理自动封禁名单管理接口（/api/v1/admin/bans）
+type BanListHandler struct {
+	banList *banlist.BanList
+	audit   audit.Recorder
+}
+
+// NewBanListHandler 创建封禁名单管理处理器，banList 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewBanListHandler(banList *banlist.BanList, recorder audit.Recorder) *BanListHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &BanListHandler{
+		banList: banList,
+		audit:   recorder,
+	}
+}
+
+// ListBans godoc
+// @Summary 列出封禁
+// @Description 列出因限流违规被自动封禁的 IP 和用户，按到期时间排序（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]banlist.Ban} "成功获取封禁名单"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "自动封禁未启用"
+// @Router /api/v1/admin/bans [get]
+func (h *BanListHandler) ListBans(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	bans, err := h.banList.List(c.Request.Context())
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "ban_list.get_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "ban_list.retrieved"), bans)
+}
+
+// Unban godoc
+// @Summary 解除封禁
+// @Description 提前解除 IP 或用户的封禁（仅管理员），解除后的一个统计窗口内不会因之前的违规再次自动封禁
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "封禁对象类型" enums(ip, user)
+// @Param identifier path string true "IP 地址或用户ID"
+// @Success 200 {object} models.SuccessResponse "封禁已解除"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "封禁对象类型不合法"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "封禁不存在或已到期"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "自动封禁未启用"
+// @Router /api/v1/admin/bans/{type}/{identifier} [delete]
+func (h *BanListHandler) Unban(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	banType, identifier := c.Param("type"), c.Param("identifier")
+	err := h.banList.Unban(c.Request.Context(), banType, identifier)
+	h.record(c, banType, identifier, err)
+	switch {
+	case err == nil:
+		response.Success(c, http.StatusOK, localize(c, "ban_list.unbanned"), nil)
+	case stderrors.Is(err, banlist.ErrInvalidType):
+		response.ValidationError(c, localize(c, "ban_list.invalid_kind"), errors.ErrorDetails{
+			Field:      "type",
+			Message:    localize(c, "ban_list.kind_detail"),
+			Value:      banType,
+			Constraint: "oneof=ip user",
+		})
+	case stderrors.Is(err, banlist.ErrNotFound):
+		response.NotFoundError(c, "ban", banType+":"+identifier)
+	default:
+		response.InternalServerErrorWithCause(c, localize(c, "ban_list.unban_failed"), err)
+	}
+}
+
+// available 自动封禁未启用时返回 503
+func (h *BanListHandler) available(c *gin.Context) bool {
+	if h.banList == nil {
+		response.ServiceUnavailableError(c, "ban_list", localize(c, "ban_list.disabled"))
+		return false
+	}
+	return true
+}
+
+// record 记录解除封禁的审计事件，目标为 <type>:<identifier>
+func (h *BanListHandler) record(c *gin.Context, banType, identifier string, err error) {
+	event := audit.Event{
+		Action:    audit.ActionBanLifted,
+		ActorID:   c.GetString("user_id"),
+		TargetID:  banType + ":" + identifier,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/pkg/banlist"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanListHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newHandler := func() (*BanListHandler, *banlist.BanList, *recordingAuditor) {
+		banList := banlist.New(banlist.NewMemoryStore(), func() []banlist.Offender {
+			return []banlist.Offender{
+				{Type: banlist.TypeIP, Identifier: "203.0.113.7", Violations: 100, ViolationRate: 90},
+			}
+		}, banlist.Config{Duration: time.Hour})
+		_, err := banList.Evaluate(context.Background())
+		require.NoError(t, err)
+		auditor := &recordingAuditor{}
+		return NewBanListHandler(banList, auditor), banList, auditor
+	}
+	withBan := func(c *gin.Context, banType, identifier string) {
+		c.Params = gin.Params{{Key: "type", Value: banType}, {Key: "identifier", Value: identifier}}
+	}
+
+	t.Run("列出封禁", func(t *testing.T) {
+		handler, _, _ := newHandler()
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/bans", "", "")
+		handler.ListBans(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+		var body struct {
+			Data []banlist.Ban `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 1)
+		assert.Equal(t, "203.0.113.7", body.Data[0].Identifier)
+		assert.Equal(t, banlist.ReasonRateLimitViolations, body.Data[0].Reason)
+	})
+
+	t.Run("解除封禁并记录审计事件", func(t *testing.T) {
+		handler, banList, auditor := newHandler()
+		c, w := newAdminContext(http.MethodDelete, "/api/v1/admin/bans/ip/203.0.113.7", "", "")
+		withBan(c, banlist.TypeIP, "203.0.113.7")
+		handler.Unban(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		ban, err := banList.Check(c.Request.Context(), banlist.TypeIP, "203.0.113.7")
+		require.NoError(t, err)
+		assert.Nil(t, ban)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionBanLifted, auditor.events[0].Action)
+		assert.Equal(t, "ip:203.0.113.7", auditor.events[0].TargetID)
+		assert.Equal(t, "admin", auditor.events[0].ActorID)
+	})
+
+	t.Run("未封禁的对象返回404", func(t *testing.T) {
+		handler, _, auditor := newHandler()
+		c, w := newAdminContext(http.MethodDelete, "/api/v1/admin/bans/user/u1", "", "")
+		withBan(c, banlist.TypeUser, "u1")
+		handler.Unban(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	})
+
+	t.Run("非法的类型返回400", func(t *testing.T) {
+		handler, _, _ := newHandler()
+		c, w := newAdminContext(http.MethodDelete, "/api/v1/admin/bans/email/a@example.com", "", "")
+		withBan(c, "email", "a@example.com")
+		handler.Unban(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("未启用时返回503", func(t *testing.T) {
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/bans", "", "")
+		NewBanListHandler(nil, nil).ListBans(c)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
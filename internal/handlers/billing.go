@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/billing"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+	"go-server/pkg/stripe"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingHandler 处理订阅套餐接口和 Stripe webhook
+type BillingHandler struct {
+	service *billing.Service
+}
+
+// NewBillingHandler 创建订阅处理器，service 为 nil 时接口返回 503
+func NewBillingHandler(service *billing.Service) *BillingHandler {
+	return &BillingHandler{
+		service: service,
+	}
+}
+
+// ListPlans godoc
+// @Summary 获取订阅套餐
+// @Description 返回所有订阅套餐及其包含的权益、限流和用量配额，按展示顺序排列
+// @Tags billing
+// @Produce json
+// @Success 200 {object} models.SuccessResponse{data=[]models.Plan} "成功获取订阅套餐"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "订阅未启用"
+// @Router /api/v1/billing/plans [get]
+func (h *BillingHandler) ListPlans(c *gin.Context) {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "billing", localize(c, "billing.disabled"))
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "billing.plans_retrieved"), h.service.Plans())
+}
+
+// GetMySubscription godoc
+// @Summary 获取我的订阅
+// @Description 返回当前用户生效的套餐和订阅状态，没有有效订阅时套餐为默认套餐。查询本身不消耗 api_calls 配额
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=models.SubscriptionResponse} "成功获取订阅"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "订阅未启用"
+// @Router /api/v1/users/me/subscription [get]
+func (h *BillingHandler) GetMySubscription(c *gin.Context) {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "billing", localize(c, "billing.disabled"))
+		return
+	}
+
+	subscription, err := h.service.Subscription(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "billing.get_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "billing.retrieved"), subscription)
+}
+
+// StripeWebhook godoc
+// @Summary 接收 Stripe webhook
+// @Description 校验 Stripe-Signature 签名后同步订阅的创建、更新和删除，其他事件直接确认。乱序到达的旧事件不会覆盖较新的状态。
+// @Description 订阅的价格没有对应的套餐时返回 400，Stripe 会稍后重试，可在配置中补充 stripe_price_ids 后等待重试
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "Stripe 签名，如 t=1492774577,v1=5257a869..."
+// @Param event body stripe.Event true "Stripe 事件"
+// @Success 200 {object} models.SuccessResponse{data=models.StripeWebhookResponse} "事件已处理"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "签名无效或订阅的价格没有对应的套餐"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "订阅未启用"
+// @Router /api/v1/billing/webhooks/stripe [post]
+func (h *BillingHandler) StripeWebhook(c *gin.Context) {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "billing", localize(c, "billing.disabled"))
+		return
+	}
+
+	// 签名针对原始请求体计算，不能先解析再序列化
+	payload, err := c.GetRawData()
+	if err != nil {
+		response.ValidationError(c, localize(c, "validation.read_body_failed"), errors.ErrorDetails{Field: "body", Message: err.Error()})
+		return
+	}
+
+	result, err := h.service.HandleWebhook(c.Request.Context(), payload, c.GetHeader(stripe.SignatureHeaderName))
+	switch {
+	case stderrors.Is(err, billing.ErrInvalidWebhook):
+		response.ValidationError(c, localize(c, "billing.invalid_signature"), errors.ErrorDetails{Field: stripe.SignatureHeaderName, Message: err.Error()})
+	case stderrors.Is(err, billing.ErrUnknownPrice):
+		response.ErrorWithAppError(c, errors.NewBusinessLogicError(localize(c, "billing.unknown_price"), map[string]interface{}{"reason": err.Error()}))
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "billing.webhook_failed"), err)
+	default:
+		response.Success(c, http.StatusOK, localize(c, "billing.event_processed"), result)
+	}
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/cache"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultCacheKeysLimit 键列表默认返回的数量
+	defaultCacheKeysLimit = 100
+	// maxCacheKeysLimit 键列表单次最多返回的数量
+	maxCacheKeysLimit = 1000
+	// maxCacheKeysScans 键列表单次请求最多执行的 SCAN 次数，匹配的键稀疏时提前返回游标，避免请求耗时过长
+	maxCacheKeysScans = 100
+)
+
+type CacheHandler struct {
+	cache  cache.Cache
+	driver string
+	warmer *cache.Warmer
+}
+
+func NewCacheHandler(appCache cache.Cache, driver string, warmer *cache.Warmer) *CacheHandler {
+	return &CacheHandler{
+		cache:  appCache,
+		driver: driver,
+		warmer: warmer,
+	}
+}
+
+// GetStats godoc
+// @Summary Get cache statistics
+// @Description Get the statistics reported by the cache driver, together with the optional capabilities it supports (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=models.CacheStatsResponse} "Cache statistics"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 500 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Cache error - Failed to read statistics"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/stats [get]
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	if h.cache == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	stats, err := h.cache.GetStats(c.Request.Context())
+	if err != nil {
+		response.CacheError(c, localize(c, "cache.stats_failed"), err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "cache.stats_retrieved"), models.CacheStatsResponse{
+		Driver:       h.driver,
+		Capabilities: cache.CapabilitiesOf(h.cache).Names(),
+		Stats:        stats,
+	})
+}
+
+// ListKeys godoc
+// @Summary List cache keys
+// @Description List the cache keys matching a glob pattern, without the application key prefix (admin only). Keys are read incrementally with SCAN, so listing never blocks the cache server; each page holds about limit keys, sorted within the page. Pass the returned cursor to fetch the next page; an empty cursor means the scan is complete. Keys changed during the scan may be returned twice or not at all. Not supported by the memcached driver.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param pattern query string false "Glob pattern" default(*)
+// @Param limit query int false "Approximate number of keys per page (1-1000)" default(100)
+// @Param cursor query string false "Cursor returned by the previous page" default(0)
+// @Success 200 {object} models.SuccessResponse{data=models.CacheKeysResponse} "Matching keys"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid limit or cursor, or the cache driver cannot list keys"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/keys [get]
+func (h *CacheHandler) ListKeys(c *gin.Context) {
+	if h.cache == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	pattern := c.DefaultQuery("pattern", "*")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultCacheKeysLimit)))
+	if err != nil || limit < 1 || limit > maxCacheKeysLimit {
+		response.ValidationError(c, localize(c, "cache.invalid_keys_request"), errors.ErrorDetails{
+			Field:      "limit",
+			Message:    localize(c, "cache.limit_detail"),
+			Value:      c.Query("limit"),
+			Constraint: "min=1,max=1000",
+		})
+		return
+	}
+
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		response.ValidationError(c, localize(c, "cache.invalid_keys_request"), errors.ErrorDetails{
+			Field:   "cursor",
+			Message: localize(c, "cache.cursor_detail"),
+			Value:   c.Query("cursor"),
+		})
+		return
+	}
+
+	scanner, ok := h.cache.(cache.KeyScanner)
+	if !ok || !cache.Supports(h.cache, cache.CapabilityKeys) {
+		h.unsupported(c, "Listing keys")
+		return
+	}
+
+	keys := []string{}
+	for scans := 0; scans < maxCacheKeysScans; scans++ {
+		batch, next, err := scanner.ScanKeys(c.Request.Context(), pattern, cursor, int64(limit-len(keys)))
+		if err != nil {
+			response.CacheError(c, localize(c, "cache.list_keys_failed"), err)
+			return
+		}
+		keys = append(keys, batch...)
+		cursor = next
+		if cursor == 0 || len(keys) >= limit {
+			break
+		}
+	}
+
+	// SCAN 可能重复返回同一个键
+	sort.Strings(keys)
+	keys = slices.Compact(keys)
+	result := models.CacheKeysResponse{
+		Pattern: pattern,
+		Keys:    keys,
+	}
+	if cursor != 0 {
+		result.Cursor = strconv.FormatUint(cursor, 10)
+		result.Truncated = true
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "cache.keys_retrieved"), result)
+}
+
+// DeleteKey godoc
+// @Summary Delete cache key
+// @Description Delete a single cache key, given without the application key prefix (admin only). The next read reloads the value from the database.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Cache key"
+// @Success 200 {object} models.SuccessResponse "Cache key deleted"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Not found - Cache key does not exist"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/keys/{key} [delete]
+func (h *CacheHandler) DeleteKey(c *gin.Context) {
+	if h.cache == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	key := c.Param("key")
+	exists, err := h.cache.Exists(c.Request.Context(), key)
+	if err != nil {
+		response.CacheError(c, localize(c, "cache.check_key_failed"), err)
+		return
+	}
+	if !exists {
+		response.NotFoundError(c, "Cache key", key)
+		return
+	}
+
+	if err := h.cache.Delete(c.Request.Context(), key); err != nil {
+		response.CacheError(c, localize(c, "cache.delete_key_failed"), err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "cache.key_deleted"), gin.H{
+		"key": key,
+	})
+}
+
+// Flush godoc
+// @Summary Flush cache
+// @Description Delete every key under the application key prefix (admin only). Drivers that cannot scope the flush to the prefix (memcached) wipe the whole cache server, so the request must then be confirmed with force=true.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param force query bool false "Confirm flushing the whole cache server when the driver cannot scope the flush"
+// @Success 200 {object} models.SuccessResponse "Cache flushed"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Business rule violation - Flush would wipe the whole cache server and was not confirmed"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/flush [post]
+func (h *CacheHandler) Flush(c *gin.Context) {
+	if h.cache == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	scoped := cache.Supports(h.cache, cache.CapabilityScopedClear)
+	if force, _ := strconv.ParseBool(c.Query("force")); !scoped && !force {
+		response.ErrorWithAppError(c, errors.NewBusinessLogicError(
+			"Flushing this cache driver wipes the whole cache server; repeat the request with force=true to confirm",
+			map[string]interface{}{
+				"driver": h.driver,
+			},
+		))
+		return
+	}
+
+	if err := h.cache.Clear(c.Request.Context()); err != nil {
+		response.CacheError(c, localize(c, "cache.flush_failed"), err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "cache.flushed"), gin.H{
+		"scoped": scoped,
+	})
+}
+
+// Warm godoc
+// @Summary Warm cache
+// @Description Start warming the cache in the background (admin only). Without datasets every registered dataset is warmed; otherwise only the named ones. Only one warm-up runs at a time. Poll GET /api/v1/admin/cache/warm for progress.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param warmCacheRequest body models.WarmCacheRequest false "Datasets to warm"
+// @Success 202 {object} models.SuccessResponse{data=cache.WarmStatus} "Warm-up started"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Unknown dataset"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Conflict - A warm-up is already in progress"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/warm [post]
+func (h *CacheHandler) Warm(c *gin.Context) {
+	if h.warmer == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	// 请求体可省略，省略时预热全部数据集
+	var req models.WarmCacheRequest
+	if c.Request.ContentLength != 0 && !validation.BindJSON(c, &req) {
+		return
+	}
+
+	status, err := h.warmer.Start(c.Request.Context(), cache.WarmTriggerAPI, req.Datasets)
+	switch {
+	case stderrors.Is(err, cache.ErrWarmupInProgress):
+		current, _ := h.warmer.Status()
+		response.ConflictError(c, localize(c, "cache.warmup_in_progress"), map[string]interface{}{
+			"id": current.ID,
+		})
+		return
+	case stderrors.Is(err, cache.ErrUnknownDataset):
+		response.ValidationError(c, localize(c, "cache.invalid_warmup_request"), errors.ErrorDetails{
+			Field:       "datasets",
+			Message:     err.Error(),
+			Suggestions: h.warmer.Datasets(),
+		})
+		return
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "cache.warmup_failed"), err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, localize(c, "cache.warmup_started"), status)
+}
+
+// WarmStatus godoc
+// @Summary Get cache warm-up progress
+// @Description Get the progress of the running or most recent cache warm-up, including the startup warm-up (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=cache.WarmStatus} "Warm-up progress"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Not found - No warm-up has run yet"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Service unavailable - Cache is not configured"
+// @Router /api/v1/admin/cache/warm [get]
+func (h *CacheHandler) WarmStatus(c *gin.Context) {
+	if h.warmer == nil {
+		response.ServiceUnavailableError(c, "cache", localize(c, "cache.unavailable"))
+		return
+	}
+
+	status, ok := h.warmer.Status()
+	if !ok {
+		response.NotFoundError(c, "cache warm-up", "latest")
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "cache.warmup_retrieved"), status)
+}
+
+// unsupported 返回驱动不支持该操作的错误
+func (h *CacheHandler) unsupported(c *gin.Context, operation string) {
+	response.ErrorWithAppError(c, errors.NewBusinessLogicError(
+		operation+" is not supported by the "+h.driver+" cache driver",
+		map[string]interface{}{
+			"driver":       h.driver,
+			"capabilities": cache.CapabilitiesOf(h.cache).Names(),
+		},
+	))
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/pkg/drain"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainHandler 处理部署前排空实例的接口（/api/v1/admin/drain）
+type DrainHandler struct {
+	drainer *drain.Drainer
+	audit   audit.Recorder
+}
+
+// NewDrainHandler 创建排空处理器，drainer 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewDrainHandler(drainer *drain.Drainer, recorder audit.Recorder) *DrainHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &DrainHandler{
+		drainer: drainer,
+		audit:   recorder,
+	}
+}
+
+// GetDrain godoc
+// @Summary 获取排空进度
+// @Description 返回当前实例的排空进度和处理中的请求数（仅管理员）。state 为 drained 时进程可以安全退出
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=drain.Status} "成功获取排空进度"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "排空不可用"
+// @Router /api/v1/admin/drain [get]
+func (h *DrainHandler) GetDrain(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "drain.retrieved"), h.drainer.Status())
+}
+
+// StartDrain godoc
+// @Summary 排空实例
+// @Description 在后台排空处理请求的实例（仅管理员）：先将就绪检查标记为失败并等待 server.shutdown.pre_stop_delay 秒让负载均衡器摘除流量，
+// @Description 再等待处理中的请求完成（最多 server.shutdown.drain_timeout 秒），最后停止后台任务和事件消费者。排空期间端口保持打开，可通过 GET 轮询进度。
+// @Description 排空完成后 server.shutdown.exit_after_drain 为 true 时进程自动退出，否则等待 SIGTERM 并跳过已完成的关闭阶段。排空只执行一次，重复调用返回 200 和当前进度
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=drain.Status} "排空已在进行中或已完成"
+// @Success 202 {object} models.SuccessResponse{data=drain.Status} "已开始排空"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "排空不可用"
+// @Router /api/v1/admin/drain [post]
+func (h *DrainHandler) StartDrain(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	status, started := h.drainer.Start()
+	if !started {
+		response.Success(c, http.StatusOK, localize(c, "drain.in_progress"), status)
+		return
+	}
+
+	h.audit.Record(c.Request.Context(), audit.Event{
+		Action:    audit.ActionDrainStarted,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   map[string]interface{}{"in_flight": status.InFlight},
+	})
+	response.Success(c, http.StatusAccepted, localize(c, "drain.started"), status)
+}
+
+// available 排空不可用时返回 503
+func (h *DrainHandler) available(c *gin.Context) bool {
+	if h.drainer == nil {
+		response.ServiceUnavailableError(c, "drain", localize(c, "drain.unavailable"))
+		return false
+	}
+	return true
+}
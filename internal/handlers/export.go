@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"mime"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/exports"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExportHandler 处理用户数据导出接口：管理员创建和查询导出任务（/api/v1/admin/exports），
+// 通过签名链接下载导出文件（/api/v1/exports/{id}/download）
+type ExportHandler struct {
+	service *exports.Service
+	audit   audit.Recorder
+}
+
+// NewExportHandler 创建导出处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewExportHandler(service *exports.Service, recorder audit.Recorder) *ExportHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &ExportHandler{service: service, audit: recorder}
+}
+
+// CreateExport godoc
+// @Summary 导出用户数据
+// @Description 创建导出任务并在后台生成 CSV、JSON 或 XLSX 文件（仅管理员），筛选条件与用户列表相同。轮询 GET /api/v1/admin/exports/{id} 查看进度，完成后响应中的 download_url 为限时有效的下载链接。XLSX 最多导出 1048575 行
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ExportRequest true "导出格式和筛选条件"
+// @Success 202 {object} models.SuccessResponse{data=models.Export} "导出任务已创建"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误或行数超过格式上限"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "本实例正在执行的导出任务已达上限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导出服务未启用"
+// @Router /api/v1/admin/exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.ExportRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	filter := models.ExportFilter{Query: req.Query, IsActive: req.IsActive, IsAdmin: req.IsAdmin}
+	export, err := h.service.Create(c.Request.Context(), c.GetString("user_id"), req.Format, filter)
+	h.record(c, export, req.Format, filter, err)
+	switch {
+	case stderrors.Is(err, exports.ErrTooManyRows):
+		response.ValidationError(c, localize(c, "export.too_many_rows"), errors.ErrorDetails{
+			Field:       "format",
+			Message:     err.Error(),
+			Value:       req.Format,
+			Suggestions: []string{models.ExportFormatCSV, models.ExportFormatJSON},
+		})
+	case stderrors.Is(err, exports.ErrTooManyRunning):
+		response.ConflictError(c, localize(c, "export.too_many_running"), nil)
+	case stderrors.Is(err, exports.ErrClosed):
+		response.ServiceUnavailableError(c, "exports", localize(c, "service.shutting_down"))
+	case err != nil:
+		response.DatabaseError(c, localize(c, "export.create_failed"), err)
+	default:
+		response.Success(c, http.StatusAccepted, localize(c, "export.created"), export)
+	}
+}
+
+// GetExport godoc
+// @Summary 查询导出任务
+// @Description 返回导出任务的状态和进度（仅管理员）。任务完成后 download_url 为新签发的下载链接，链接在 exports.url_ttl 后失效，过期后重新查询即可获得新链接；文件在 expires_at 后删除
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "导出任务ID"
+// @Success 200 {object} models.SuccessResponse{data=models.Export} "成功获取导出任务"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导出任务不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导出服务未启用"
+// @Router /api/v1/admin/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id, ok := exportID(c)
+	if !ok {
+		return
+	}
+	export, err := h.service.Get(c.Request.Context(), id)
+	if stderrors.Is(err, exports.ErrNotFound) {
+		response.NotFoundError(c, "export", id)
+		return
+	}
+	if err != nil {
+		response.DatabaseError(c, localize(c, "export.get_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "export.retrieved"), export)
+}
+
+// DownloadExport godoc
+// @Summary 下载导出文件
+// @Description 通过 GET /api/v1/admin/exports/{id} 返回的签名链接下载导出文件，不需要访问令牌，签名过期后返回 403
+// @Tags exports
+// @Produce json,octet-stream
+// @Param id path string true "导出任务ID"
+// @Param expires query int true "链接过期时间（Unix 秒）"
+// @Param signature query string true "链接签名"
+// @Success 200 {string} string "导出文件"
+// @Header 200 {string} Content-Disposition "attachment; filename=users-20260102-030405.csv"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "签名无效或链接已过期"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导出文件不存在或已删除"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导出服务未启用"
+// @Router /api/v1/exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id := c.Param("id")
+	reader, export, err := h.service.Open(c.Request.Context(), id, c.Query("expires"), c.Query("signature"))
+	switch {
+	case stderrors.Is(err, exports.ErrInvalidSignature):
+		response.ForbiddenError(c, localize(c, "export.invalid_link"))
+		return
+	case stderrors.Is(err, exports.ErrLinkExpired):
+		response.ForbiddenError(c, localize(c, "export.link_expired"))
+		return
+	case stderrors.Is(err, exports.ErrNotFound):
+		response.NotFoundError(c, "export", id)
+		return
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "export.read_failed"), err)
+		return
+	}
+	defer reader.Close()
+
+	// 所有格式都以附件下载，避免浏览器直接渲染导出的用户数据
+	c.DataFromReader(http.StatusOK, export.Size, "application/octet-stream", reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": exports.FileName(export)}),
+		"Cache-Control":       "private, no-store",
+	})
+}
+
+// available 导出服务未启用时返回 503
+func (h *ExportHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "exports", localize(c, "export.disabled"))
+		return false
+	}
+	return true
+}
+
+// exportID 返回路径中的导出任务ID，不是 UUID 时按不存在处理
+func exportID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.NotFoundError(c, "export", id)
+		return "", false
+	}
+	return id, true
+}
+
+// record 记录创建导出任务的审计事件，目标为导出任务ID
+func (h *ExportHandler) record(c *gin.Context, export *models.Export, format string, filter models.ExportFilter, err error) {
+	event := audit.Event{
+		Action:    audit.ActionExportCreated,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details: map[string]interface{}{
+			"format":    format,
+			"query":     filter.Query,
+			"is_active": filter.IsActive,
+			"is_admin":  filter.IsAdmin,
+		},
+	}
+	if export != nil {
+		event.TargetID = export.ID
+		event.Details["total_rows"] = export.TotalRows
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/featureflags"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagHandler 处理功能开关管理接口（/api/v1/admin/feature-flags）
+type FeatureFlagHandler struct {
+	manager *featureflags.Manager
+	audit   audit.Recorder
+}
+
+// NewFeatureFlagHandler 创建功能开关管理处理器，manager 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewFeatureFlagHandler(manager *featureflags.Manager, recorder audit.Recorder) *FeatureFlagHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &FeatureFlagHandler{
+		manager: manager,
+		audit:   recorder,
+	}
+}
+
+// ListFeatureFlags godoc
+// @Summary 列出功能开关
+// @Description 列出所有功能开关的定义，按键排序（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]featureflags.Flag} "成功获取功能开关"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关未启用"
+// @Router /api/v1/admin/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	flags, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "feature_flag.get_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "feature_flag.retrieved"), flags)
+}
+
+// GetFeatureFlag godoc
+// @Summary 获取功能开关
+// @Description 获取指定功能开关的定义（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "开关键"
+// @Success 200 {object} models.SuccessResponse{data=featureflags.Flag} "成功获取功能开关"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关未启用"
+// @Router /api/v1/admin/feature-flags/{key} [get]
+func (h *FeatureFlagHandler) GetFeatureFlag(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	key := c.Param("key")
+	flag, err := h.manager.Get(c.Request.Context(), key)
+	if err != nil {
+		h.storeError(c, key, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "feature_flag.retrieved"), flag)
+}
+
+// CreateFeatureFlag godoc
+// @Summary 创建功能开关
+// @Description 创建功能开关（仅管理员）。percentage 为按用户ID哈希分桶开启的比例，100 表示对所有请求开启；users 中的用户始终开启；enabled 为 false 时对所有用户关闭
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateFeatureFlagRequest true "功能开关定义"
+// @Success 201 {object} models.SuccessResponse{data=featureflags.Flag} "功能开关已创建"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关已存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关未启用"
+// @Router /api/v1/admin/feature-flags [post]
+func (h *FeatureFlagHandler) CreateFeatureFlag(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.CreateFeatureFlagRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	flag := &featureflags.Flag{
+		Key:         req.Key,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Percentage:  req.Percentage,
+		Users:       req.Users,
+	}
+	err := h.manager.Create(c.Request.Context(), flag)
+	h.record(c, audit.ActionFeatureFlagCreated, flag, err)
+	if err != nil {
+		h.storeError(c, req.Key, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, localize(c, "feature_flag.created"), flag)
+}
+
+// UpdateFeatureFlag godoc
+// @Summary 修改功能开关
+// @Description 替换功能开关的完整定义（仅管理员），所有实例在收到变更通知或本地缓存过期后生效
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "开关键"
+// @Param request body models.UpdateFeatureFlagRequest true "功能开关定义"
+// @Success 200 {object} models.SuccessResponse{data=featureflags.Flag} "功能开关已修改"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关未启用"
+// @Router /api/v1/admin/feature-flags/{key} [put]
+func (h *FeatureFlagHandler) UpdateFeatureFlag(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.UpdateFeatureFlagRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	flag := &featureflags.Flag{
+		Key:         c.Param("key"),
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Percentage:  req.Percentage,
+		Users:       req.Users,
+	}
+	err := h.manager.Update(c.Request.Context(), flag)
+	h.record(c, audit.ActionFeatureFlagUpdated, flag, err)
+	if err != nil {
+		h.storeError(c, flag.Key, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "feature_flag.updated"), flag)
+}
+
+// DeleteFeatureFlag godoc
+// @Summary 删除功能开关
+// @Description 删除功能开关（仅管理员），删除后的开关对所有用户视为关闭
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "开关键"
+// @Success 200 {object} models.SuccessResponse "功能开关已删除"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "功能开关未启用"
+// @Router /api/v1/admin/feature-flags/{key} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	key := c.Param("key")
+	err := h.manager.Delete(c.Request.Context(), key)
+	h.record(c, audit.ActionFeatureFlagDeleted, &featureflags.Flag{Key: key}, err)
+	if err != nil {
+		h.storeError(c, key, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "feature_flag.deleted"), nil)
+}
+
+// available 功能开关未启用时返回 503
+func (h *FeatureFlagHandler) available(c *gin.Context) bool {
+	if h.manager == nil {
+		response.ServiceUnavailableError(c, "feature_flags", localize(c, "feature_flag.disabled"))
+		return false
+	}
+	return true
+}
+
+// storeError 将存储错误转换为响应
+func (h *FeatureFlagHandler) storeError(c *gin.Context, key string, err error) {
+	switch {
+	case stderrors.Is(err, featureflags.ErrNotFound):
+		response.NotFoundError(c, "feature_flag", key)
+	case stderrors.Is(err, featureflags.ErrExists):
+		response.ConflictError(c, localize(c, "feature_flag.exists"), map[string]interface{}{"key": key})
+	case stderrors.Is(err, featureflags.ErrInvalidFlag):
+		response.ValidationError(c, localize(c, "feature_flag.invalid"), errors.ErrorDetails{
+			Field:   "key",
+			Message: err.Error(),
+			Value:   key,
+		})
+	default:
+		response.InternalServerErrorWithCause(c, localize(c, "feature_flag.save_failed"), err)
+	}
+}
+
+// record 记录开关变更的审计事件，目标为开关键
+func (h *FeatureFlagHandler) record(c *gin.Context, action string, flag *featureflags.Flag, err error) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   c.GetString("user_id"),
+		TargetID:  flag.Key,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if action != audit.ActionFeatureFlagDeleted {
+		event.Details = map[string]interface{}{
+			"enabled":    flag.Enabled,
+			"percentage": flag.Percentage,
+			"users":      len(flag.Users),
+		}
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/pkg/featureflags"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newHandler := func() (*FeatureFlagHandler, *featureflags.Manager, *recordingAuditor) {
+		manager := featureflags.NewManager(featureflags.NewMemoryStore(
+			&featureflags.Flag{Key: "beta", Enabled: true, Percentage: 10},
+		), time.Minute)
+		auditor := &recordingAuditor{}
+		return NewFeatureFlagHandler(manager, auditor), manager, auditor
+	}
+	withKey := func(c *gin.Context, key string) {
+		c.Params = gin.Params{{Key: "key", Value: key}}
+	}
+
+	t.Run("创建开关并记录审计事件", func(t *testing.T) {
+		handler, manager, auditor := newHandler()
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/feature-flags",
+			`{"key":"new_dashboard","enabled":true,"percentage":100}`, "")
+		handler.CreateFeatureFlag(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.True(t, manager.IsEnabled(c.Request.Context(), "new_dashboard", ""))
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionFeatureFlagCreated, auditor.events[0].Action)
+		assert.Equal(t, "new_dashboard", auditor.events[0].TargetID)
+		assert.Equal(t, "admin", auditor.events[0].ActorID)
+	})
+
+	t.Run("重复创建返回409", func(t *testing.T) {
+		handler, _, auditor := newHandler()
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/feature-flags", `{"key":"beta"}`, "")
+		handler.CreateFeatureFlag(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	})
+
+	t.Run("非法的开关键返回400", func(t *testing.T) {
+		handler, _, _ := newHandler()
+		c, w := newAdminContext(http.MethodPost, "/api/v1/admin/feature-flags", `{"key":"Bad Key"}`, "")
+		handler.CreateFeatureFlag(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("修改开关", func(t *testing.T) {
+		handler, manager, _ := newHandler()
+		c, w := newAdminContext(http.MethodPut, "/api/v1/admin/feature-flags/beta",
+			`{"enabled":true,"users":["u1"]}`, "")
+		withKey(c, "beta")
+		handler.UpdateFeatureFlag(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, manager.IsEnabled(c.Request.Context(), "beta", "u1"))
+	})
+
+	t.Run("获取和删除不存在的开关返回404", func(t *testing.T) {
+		handler, _, _ := newHandler()
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/feature-flags/missing", "", "")
+		withKey(c, "missing")
+		handler.GetFeatureFlag(c)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		c, w = newAdminContext(http.MethodDelete, "/api/v1/admin/feature-flags/missing", "", "")
+		withKey(c, "missing")
+		handler.DeleteFeatureFlag(c)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("未启用时返回503", func(t *testing.T) {
+		handler := NewFeatureFlagHandler(nil, nil)
+		c, w := newAdminContext(http.MethodGet, "/api/v1/admin/feature-flags", "", "")
+		handler.ListFeatureFlags(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -7,21 +7,36 @@ import (
 	"time"
 
 	"go-server/internal/database"
+	"go-server/internal/metrics"
 	"go-server/pkg/cache"
+	"go-server/pkg/health"
 	"go-server/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
 type HealthHandler struct {
-	db    *database.Database
-	cache cache.Cache
+	db        *database.Database
+	cache     cache.Cache
+	registry  *health.Registry
+	blacklist *cache.BlacklistService
+	metrics   *metrics.Registry
 }
 
-func NewHealthHandler(db *database.Database, cache cache.Cache) *HealthHandler {
+// NewHealthHandler 创建健康检查处理器，metricsRegistry 为 nil 时 Prometheus 端点只输出空文档
+func NewHealthHandler(db *database.Database, cache cache.Cache, registry *health.Registry, blacklist *cache.BlacklistService, metricsRegistry *metrics.Registry) *HealthHandler {
+	if registry == nil {
+		registry = health.NewRegistry(0)
+	}
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewRegistry()
+	}
 	return &HealthHandler{
-		db:    db,
-		cache: cache,
+		db:        db,
+		cache:     cache,
+		registry:  registry,
+		blacklist: blacklist,
+		metrics:   metricsRegistry,
 	}
 }
 
@@ -64,6 +79,11 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		},
 	}
 
+	// Surface the maintenance countdown without changing the health status
+	if maintenance := h.registry.Maintenance(ctx); maintenance != nil {
+		healthResponse["maintenance"] = maintenance
+	}
+
 	// Add headers for monitoring systems
 	c.Header("X-Database-Status", dbMetrics["status"].(string))
 	c.Header("X-Cache-Status", cacheMetrics["status"].(string))
@@ -97,164 +117,120 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		statusCode = http.StatusOK // Still return 200 but indicate degraded state
 	}
 
-	response.Success(c, statusCode, "Comprehensive health check completed", healthResponse)
+	response.Success(c, statusCode, localize(c, "health.completed"), healthResponse)
 }
 
-// Ready godoc
-// @Summary Enhanced readiness check endpoint
-// @Description Comprehensive readiness check validating database connectivity and cache readiness with detailed performance metrics. This endpoint tests actual read/write operations and provides latency measurements, connection pool status, and cache response times for production monitoring.
+// Healthz godoc
+// @Summary Liveness probe
+// @Description Kubernetes liveness probe. Runs only the liveness checks registered in the health registry (process-level state, never external dependencies), so a failing database does not cause the pod to be restarted. Returns 200 when alive and 503 otherwise.
 // @Tags health
 // @Produce json
-// @Success 200 {object} models.SuccessResponse{data=models.HealthResponse}
-// @Header 200 {string} X-Database-Ready "Database readiness status (ready, not_ready)"
-// @Header 200 {string} X-Cache-Ready "Redis cache readiness status (ready, not_ready, not_configured)"
-// @Header 200 {integer} X-Database-Response-Time "Database health check response time in milliseconds"
-// @Header 200 {integer} X-Cache-Response-Time "Cache read/write test response time in milliseconds"
-// @Router /api/v1/ready [get]
-func (h *HealthHandler) Ready(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
-
-	// Track response times
-	dbStartTime := time.Now()
-
-	// Check database readiness with performance tracking
-	dbReady := false
-	var dbError error
-	var dbResponseTime time.Duration
-	var dbPoolStats map[string]interface{}
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /healthz [get]
+// @Router /api/v1/live [get]
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	h.writeReport(c, h.registry.CheckLiveness(c.Request.Context()))
+}
 
-	if h.db != nil {
-		if err := h.db.Health(); err == nil {
-			dbReady = true
-			dbResponseTime = time.Since(dbStartTime)
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Kubernetes readiness probe. Runs every readiness check registered in the health registry (database, cache, event bus, storage, ...) concurrently with per-check timeouts and reports per-dependency status. Returns 503 when a critical dependency is down or the server is shutting down; optional dependencies only degrade the status.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+// @Router /api/v1/ready [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	h.writeReport(c, h.registry.CheckReadiness(c.Request.Context()))
+}
 
-			// Get connection pool stats for readiness assessment
-			if stats, err := h.db.GetConnectionPoolStats(); err == nil {
-				dbPoolStats = stats
-			}
-		} else {
-			dbError = err
-			dbResponseTime = time.Since(dbStartTime)
-		}
+// writeReport 根据检查结果写入探针响应，unhealthy 时返回 503
+func (h *HealthHandler) writeReport(c *gin.Context, report *health.Report) {
+	statusCode := http.StatusOK
+	if !report.Healthy() {
+		statusCode = http.StatusServiceUnavailable
 	}
 
-	// Check Redis cache readiness with performance tracking
-	cacheReady := false
-	var cacheError error
-	var cacheResponseTime time.Duration
-	var cacheStats map[string]interface{}
-
-	if h.cache != nil {
-		cacheTestStart := time.Now()
-
-		// Comprehensive readiness check - test actual operations
-		testKey := "readiness_check"
-		testValue := "ready"
-
-		if err := h.cache.Set(ctx, testKey, testValue, 5*time.Second); err != nil {
-			cacheError = err
-		} else {
-			if _, found := h.cache.Get(ctx, testKey); found {
-				cacheReady = true
-				h.cache.Delete(ctx, testKey) // Clean up
-			} else {
-				cacheError = fmt.Errorf("cache read test failed")
-			}
-		}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(statusCode, report)
+}
 
-		cacheResponseTime = time.Since(cacheTestStart)
+// Metrics godoc
+// @Summary Database metrics endpoint
+// @Description Returns database connection pool statistics (open, idle, in-use connections and wait count), query performance statistics and per-operation query latency histograms collected by the query monitor plugin. The cache section reports hits, misses, loads, load latency and the per-key-prefix breakdown of each instrumented cache, keyed by cache name. The jwt_blacklist section reports the number of revoked tokens (read from the blacklist index, without scanning the keyspace) and, when the bloom filter is enabled, its skip and false-positive counters under jwt_blacklist.filter.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/metrics [get]
+func (h *HealthHandler) Metrics(c *gin.Context) {
+	if h.db == nil {
+		response.ServiceUnavailableError(c, "database", localize(c, "health.database_not_configured"))
+		return
+	}
 
-		// Get cache stats for additional readiness info
-		if stats, err := h.cache.GetStats(ctx); err == nil {
-			cacheStats = stats
-		}
-	} else {
-		// Cache is optional for readiness - service can operate without cache
-		cacheReady = true
-		cacheResponseTime = 0
+	poolStats, err := h.db.GetConnectionPoolStats()
+	if err != nil {
+		response.DatabaseError(c, localize(c, "health.pool_stats_failed"), err)
+		return
 	}
 
-	// Build comprehensive readiness response
-	readyResponse := map[string]interface{}{
-		"status":    "ready",
+	metricsResponse := map[string]interface{}{
 		"timestamp": time.Now().UTC(),
-		"version":   "1.0.0",
-		"checks": map[string]interface{}{
-			"database": map[string]interface{}{
-				"ready":            dbReady,
-				"response_time_ms": dbResponseTime.Milliseconds(),
-				"error": func() string {
-					if dbError != nil {
-						return dbError.Error()
-					}
-					return ""
-				}(),
-			},
-			"cache": map[string]interface{}{
-				"ready":            cacheReady,
-				"response_time_ms": cacheResponseTime.Milliseconds(),
-				"error": func() string {
-					if cacheError != nil {
-						return cacheError.Error()
-					}
-					return ""
-				}(),
-			},
-		},
-		"performance": map[string]interface{}{
-			"database_response_time_ms": dbResponseTime.Milliseconds(),
-			"cache_response_time_ms":    cacheResponseTime.Milliseconds(),
-			"total_check_time_ms":       time.Since(dbStartTime).Milliseconds(),
+		"database": map[string]interface{}{
+			"pool_stats":         poolStats,
+			"query_stats":        h.db.GetQueryPerformanceStatsJSON(),
+			"latency_histograms": h.db.GetQueryLatencyHistograms(),
 		},
 	}
-
-	// Include pool stats if available
-	if dbPoolStats != nil {
-		readyResponse["checks"].(map[string]interface{})["database"].(map[string]interface{})["pool_stats"] = map[string]interface{}{
-			"utilization_percent": dbPoolStats["utilization_percent"],
-			"open_connections":    dbPoolStats["open_connections"],
-			"max_open_conns":      dbPoolStats["max_open_conns"],
-		}
+	if blacklistMetrics := h.getBlacklistMetrics(c.Request.Context()); blacklistMetrics != nil {
+		metricsResponse["jwt_blacklist"] = blacklistMetrics
 	}
-
-	// Include cache stats if available
-	if cacheStats != nil {
-		if memoryInfo, ok := cacheStats["memory"].(map[string]interface{}); ok {
-			readyResponse["checks"].(map[string]interface{})["cache"].(map[string]interface{})["memory_usage_percent"] = memoryInfo["memory_usage_percent"]
-		}
-		if dbInfo, ok := cacheStats["database"].(map[string]interface{}); ok {
-			readyResponse["checks"].(map[string]interface{})["cache"].(map[string]interface{})["hit_rate_percent"] = dbInfo["keyspace_hit_rate_percent"]
-		}
+	if cacheStats := h.metrics.CacheStats(); len(cacheStats) > 0 {
+		metricsResponse["cache"] = cacheStats
 	}
 
-	// Determine overall readiness - database is required, cache is optional
-	overallReady := dbReady
-	if !overallReady {
-		readyResponse["status"] = "not_ready"
-	}
+	response.Success(c, http.StatusOK, localize(c, "health.metrics_retrieved"), metricsResponse)
+}
 
-	// Add monitoring headers
-	c.Header("X-Database-Ready", fmt.Sprintf("%t", dbReady))
-	c.Header("X-Cache-Ready", fmt.Sprintf("%t", cacheReady))
-	c.Header("X-Database-Response-Time", fmt.Sprintf("%d", dbResponseTime.Milliseconds()))
-	c.Header("X-Cache-Response-Time", fmt.Sprintf("%d", cacheResponseTime.Milliseconds()))
+// Prometheus godoc
+// @Summary Prometheus metrics endpoint
+// @Description Returns the registered metrics in the Prometheus text exposition format: HTTP request counts, latency histograms, request/response sizes and in-flight requests by method, route and status, and cache hits, misses, loads, load latency histograms and per-key-prefix counters labelled by cache name.
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "Metrics in the Prometheus text exposition format"
+// @Router /api/v1/metrics/prometheus [get]
+func (h *HealthHandler) Prometheus(c *gin.Context) {
+	c.Header("Content-Type", metrics.PrometheusContentType)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if err := h.metrics.WritePrometheus(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
 
-	statusCode := http.StatusOK
-	if !overallReady {
-		statusCode = http.StatusServiceUnavailable
+// getBlacklistMetrics collects JWT blacklist metrics, returning nil when the blacklist is not configured
+func (h *HealthHandler) getBlacklistMetrics(ctx context.Context) map[string]interface{} {
+	if h.blacklist == nil {
+		return nil
 	}
 
-	response.Success(c, statusCode, "Enhanced readiness check completed", readyResponse)
+	blacklistMetrics := map[string]interface{}{
+		"filter_enabled": h.blacklist.FilterEnabled(),
+	}
+	if size, err := h.blacklist.GetBlacklistSize(ctx); err != nil {
+		blacklistMetrics["size_error"] = err.Error()
+	} else {
+		blacklistMetrics["size"] = size
+	}
+	if stats, ok := h.blacklist.FilterStats(); ok {
+		blacklistMetrics["filter"] = stats
+	}
+	return blacklistMetrics
 }
 
-// Live godoc
-// @Summary Liveness check endpoint
-// @Description Check if the API server is alive (basic liveness probe)
-// @Tags health
-// @Produce json
-// @Success 200 {object} models.SuccessResponse
-// @Router /api/v1/live [get]
 // getDatabaseHealthMetrics collects comprehensive database health metrics
 func (h *HealthHandler) getDatabaseHealthMetrics() map[string]interface{} {
 	if h.db == nil {
@@ -414,22 +390,3 @@ func (h *HealthHandler) calculateOverallStatus(dbMetrics, cacheMetrics map[strin
 
 	return "unknown"
 }
-
-func (h *HealthHandler) Live(c *gin.Context) {
-	// Basic liveness check - just verify the service is running
-	// This should always return success if the service is up
-	systemMetrics := h.getSystemMetrics()
-
-	liveResponse := map[string]interface{}{
-		"status":    "alive",
-		"timestamp": systemMetrics["timestamp"],
-		"version":   systemMetrics["version"],
-		"uptime":    systemMetrics["uptime_human"],
-		"system": map[string]interface{}{
-			"uptime_seconds": systemMetrics["uptime_seconds"],
-			"go_version":     systemMetrics["go_version"],
-		},
-	}
-
-	response.Success(c, http.StatusOK, "Service is alive", liveResponse)
-}
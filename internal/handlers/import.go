@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"go-server/internal/audit"
+	"go-server/internal/imports"
+	"go-server/internal/models"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importFormField 上传导入文件的表单字段名
+const importFormField = "file"
+
+// ImportHandler 处理管理员批量导入用户（/api/v1/admin/imports）
+type ImportHandler struct {
+	service *imports.Service
+	audit   audit.Recorder
+}
+
+// NewImportHandler 创建导入处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewImportHandler(service *imports.Service, recorder audit.Recorder) *ImportHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &ImportHandler{service: service, audit: recorder}
+}
+
+// ImportUsers godoc
+// @Summary 批量导入用户
+// @Description 以 multipart/form-data 上传 CSV 或 JSON 文件批量创建用户（仅管理员）。CSV 须包含 username、email、password 列，可包含 first_name、last_name 列；JSON 为对象数组，字段与注册请求相同。每行按注册请求的规则校验，邮箱（不区分大小写）或用户名与文件中之前的行或现有用户重复的行被跳过；有效的行分批写入，每批一个事务。dry_run=true 时只校验并返回错误报告。导入的用户为已激活的普通用户，不发送欢迎通知
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "导入文件（CSV 或 JSON）"
+// @Param format query string false "文件格式（csv、json），默认按文件扩展名判断"
+// @Param dry_run query bool false "只校验不写入"
+// @Success 200 {object} models.SuccessResponse{data=models.ImportReport} "导入报告，errors 列出被跳过的行"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误、文件无法解析或行数超过上限"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 413 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "文件过大"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "导入服务未启用"
+// @Router /api/v1/admin/imports [post]
+func (h *ImportHandler) ImportUsers(c *gin.Context) {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "imports", localize(c, "import.disabled"))
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.ValidationError(c, localize(c, "validation.invalid_dry_run"),
+				errors.ErrorDetails{Field: "dry_run", Message: localize(c, "validation.dry_run_detail"), Value: value})
+			return
+		}
+		dryRun = parsed
+	}
+
+	file, fileName, err := h.findFilePart(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			response.PayloadTooLargeError(c, maxBytesErr.Limit)
+			return
+		}
+		response.ValidationError(c, localize(c, "import.invalid_form"),
+			errors.ErrorDetails{Field: importFormField, Message: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(path.Ext(fileName)), ".")
+	}
+	if format != models.ImportFormatCSV && format != models.ImportFormatJSON {
+		response.ValidationError(c, localize(c, "import.unsupported_format"), errors.ErrorDetails{
+			Field:       "format",
+			Message:     localize(c, "import.format_detail"),
+			Value:       format,
+			Suggestions: []string{models.ImportFormatCSV, models.ImportFormatJSON},
+		})
+		return
+	}
+
+	report, err := h.service.Import(c.Request.Context(), format, file, dryRun)
+	if !dryRun {
+		h.record(c, format, report, err)
+	}
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case stderrors.As(err, &maxBytesErr):
+		response.PayloadTooLargeError(c, maxBytesErr.Limit)
+	case stderrors.Is(err, imports.ErrInvalidFile), stderrors.Is(err, imports.ErrTooManyRows):
+		response.ValidationError(c, localize(c, "import.invalid_file"),
+			errors.ErrorDetails{Field: importFormField, Message: err.Error(), Value: fileName})
+	case err != nil:
+		response.DatabaseError(c, localize(c, "import.failed"), err)
+	case dryRun:
+		response.Success(c, http.StatusOK, localize(c, "import.validated"), report)
+	default:
+		response.Success(c, http.StatusOK, localize(c, "import.completed"), report)
+	}
+}
+
+// findFilePart 流式读取 multipart 请求体，返回导入文件所在的部分及其文件名
+func (h *ImportHandler) findFilePart(c *gin.Context) (io.ReadCloser, string, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return nil, "", stderrors.New("missing import file")
+			}
+			return nil, "", err
+		}
+		if part.FormName() == importFormField && part.FileName() != "" {
+			return part, part.FileName(), nil
+		}
+		part.Close()
+	}
+}
+
+// record 记录批量导入的审计事件，试运行不记录
+func (h *ImportHandler) record(c *gin.Context, format string, report *models.ImportReport, err error) {
+	event := audit.Event{
+		Action:    audit.ActionUsersImported,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   map[string]interface{}{"format": format},
+	}
+	if report != nil {
+		event.Details["total_rows"] = report.TotalRows
+		event.Details["created"] = report.Created
+		event.Details["skipped_rows"] = report.TotalRows - report.Created
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"go-server/internal/validation"
+	"go-server/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localize 返回消息目录中 key 在请求协商语言下的文本，{0}、{1} 依次替换为 args
+// 接口的 message 和错误消息都通过它选择语言，不要在处理器中直接写入某种语言的文本
+func localize(c *gin.Context, key string, args ...string) string {
+	return i18n.Default().Translate(validation.Language(c), key, args...)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		locale         string // 语言协商中间件写入请求上下文的语言
+		key            string
+		args           []string
+		expected       string
+	}{
+		{name: "未指定语言使用英文", key: "pagination.invalid_page", expected: "Page must be greater than 0"},
+		{name: "Accept-Language 中文", acceptLanguage: "zh-CN,zh;q=0.9", key: "pagination.invalid_page", expected: "页码必须大于0"},
+		{name: "不支持的语言使用英文", acceptLanguage: "fr", key: "auth.unauthenticated", expected: "User not authenticated"},
+		{name: "优先使用请求上下文中的语言", acceptLanguage: "en", locale: "zh", key: "auth.unauthenticated", expected: "用户未身份验证"},
+		{name: "替换参数", acceptLanguage: "zh", key: "resource.get_failed", args: []string{"订单"}, expected: "获取订单失败"},
+		{name: "英文替换参数", key: "resource.created", args: []string{"Order"}, expected: "Order created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				c.Request.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.locale != "" {
+				c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), tt.locale))
+			}
+
+			assert.Equal(t, tt.expected, localize(c, tt.key, tt.args...))
+		})
+	}
+}
+
+func TestHandlerMessagesFollowAcceptLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "英文", acceptLanguage: "en-US", expected: "Usage quotas are not enabled"},
+		{name: "中文", acceptLanguage: "zh-CN", expected: "用量配额未启用"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/quota", nil)
+			c.Request.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			NewQuotaHandler(nil).GetMyQuota(c)
+
+			require.Equal(t, http.StatusServiceUnavailable, w.Code)
+			var body struct {
+				Message string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expected, body.Message)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-server/internal/logger"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoggingHandler struct {
+	manager *logger.Manager
+}
+
+func NewLoggingHandler(manager *logger.Manager) *LoggingHandler {
+	return &LoggingHandler{
+		manager: manager,
+	}
+}
+
+// GetLevels godoc
+// @Summary Get log levels
+// @Description Get the global log level and the per-module level overrides currently in effect (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=logger.LevelSettings} "Current log levels"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Router /api/v1/admin/logging/level [get]
+func (h *LoggingHandler) GetLevels(c *gin.Context) {
+	response.Success(c, http.StatusOK, localize(c, "logging.retrieved"), h.manager.Levels())
+}
+
+// UpdateLevel godoc
+// @Summary Update log level
+// @Description Change the log level at runtime without a restart (admin only). Without a module the global level is changed and is restored from configuration on the next reload; with a module the level overrides the global level for that module, and an empty level removes the override.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param updateLogLevelRequest body models.UpdateLogLevelRequest true "Module and level"
+// @Success 200 {object} models.SuccessResponse{data=logger.LevelSettings} "Log level updated"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Validation error - Invalid module or level"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication error - Missing or invalid token"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authorization error - Admin privileges required"
+// @Router /api/v1/admin/logging/level [put]
+func (h *LoggingHandler) UpdateLevel(c *gin.Context) {
+	var req models.UpdateLogLevelRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var err error
+	switch {
+	case req.Module == "" && req.Level == "":
+		response.ValidationError(c, localize(c, "logging.invalid_request"), errors.ErrorDetails{
+			Field:   "level",
+			Message: localize(c, "logging.level_detail"),
+		})
+		return
+	case req.Module == "":
+		err = h.manager.SetLevel(req.Level)
+	case req.Level == "":
+		h.manager.ResetModuleLevel(req.Module)
+	default:
+		err = h.manager.SetModuleLevel(req.Module, req.Level)
+	}
+	if err != nil {
+		response.ValidationError(c, localize(c, "logging.invalid_request"), errors.ErrorDetails{
+			Field:   "level",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "logging.updated"), h.manager.Levels())
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/maintenance"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler 处理维护模式管理接口（/api/v1/admin/maintenance）
+type MaintenanceHandler struct {
+	mode  *maintenance.Mode
+	audit audit.Recorder
+}
+
+// NewMaintenanceHandler 创建维护模式管理处理器，mode 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewMaintenanceHandler(mode *maintenance.Mode, recorder audit.Recorder) *MaintenanceHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &MaintenanceHandler{
+		mode:  mode,
+		audit: recorder,
+	}
+}
+
+// GetMaintenance godoc
+// @Summary 获取维护状态
+// @Description 返回当前生效的维护状态，source 为 config 表示由配置开启（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=maintenance.State} "成功获取维护状态"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "维护模式未启用"
+// @Router /api/v1/admin/maintenance [get]
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	state, err := h.mode.Current(c.Request.Context())
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "maintenance.get_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "maintenance.retrieved"), state)
+}
+
+// EnableMaintenance godoc
+// @Summary 开启维护
+// @Description 开启维护模式（仅管理员）。维护期间除放行路径外的请求返回 503 MAINTENANCE_MODE，携带 X-Maintenance-Bypass 旁路令牌的请求不受影响。
+// @Description 已在维护中时更新说明和预计结束时间，开始时间不变；缓存是 Redis 时其他实例在 maintenance.refresh_interval 内生效
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param enableMaintenanceRequest body models.EnableMaintenanceRequest true "维护说明和预计时长"
+// @Success 200 {object} models.SuccessResponse{data=maintenance.State} "维护已开启"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数不合法"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "维护模式未启用"
+// @Router /api/v1/admin/maintenance [put]
+func (h *MaintenanceHandler) EnableMaintenance(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.EnableMaintenanceRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	state, err := h.mode.Enable(c.Request.Context(), req.Message, duration, c.GetString("user_id"))
+	h.record(c, audit.ActionMaintenanceEnabled, map[string]interface{}{"duration_seconds": req.DurationSeconds}, err)
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "maintenance.enable_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "maintenance.enabled"), state)
+}
+
+// DisableMaintenance godoc
+// @Summary 结束维护
+// @Description 结束管理员开启的维护（仅管理员）。由配置开启的维护需要修改 maintenance.active 才能结束，此时返回 409
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=maintenance.State} "维护已结束"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "维护由配置开启"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "维护模式未启用"
+// @Router /api/v1/admin/maintenance [delete]
+func (h *MaintenanceHandler) DisableMaintenance(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	if h.mode.Forced() != nil {
+		response.ConflictError(c, localize(c, "maintenance.configured"), map[string]interface{}{
+			"source": maintenance.SourceConfig,
+		})
+		return
+	}
+
+	state, err := h.mode.Disable(c.Request.Context(), c.GetString("user_id"))
+	h.record(c, audit.ActionMaintenanceDisabled, nil, err)
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "maintenance.disable_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "maintenance.ended"), state)
+}
+
+// available 维护模式未启用时返回 503
+func (h *MaintenanceHandler) available(c *gin.Context) bool {
+	if h.mode == nil {
+		response.ServiceUnavailableError(c, "maintenance", localize(c, "maintenance.disabled"))
+		return false
+	}
+	return true
+}
+
+// record 记录开关维护的审计事件
+func (h *MaintenanceHandler) record(c *gin.Context, action string, details map[string]interface{}, err error) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-server/docs"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetaHandler struct{}
+
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+// ListErrorCodes godoc
+// @Summary List error codes
+// @Description Get the machine-readable catalog of every error code the API can return, with its HTTP status, whether the request can be retried as-is, and a documentation link. The documentation link is also the RFC 7807 problem type URI.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.SuccessResponse{data=[]errors.ErrorCodeInfo} "Error code catalog"
+// @Router /api/v1/meta/errors [get]
+func (h *MetaHandler) ListErrorCodes(c *gin.Context) {
+	response.Success(c, http.StatusOK, localize(c, "meta.error_codes_retrieved"), errors.Catalog(response.ProblemTypeBaseURL()))
+}
+
+// OpenAPISpec godoc
+// @Summary OpenAPI document
+// @Description Get the OpenAPI 3.1 document of this API. It is generated from the handler annotations by `make openapi` and embedded at build time; the Swagger UI at /swagger/index.html renders it.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} object "OpenAPI 3.1 document"
+// @Router /openapi.json [get]
+func (h *MetaHandler) OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.OpenAPI)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 指标历史查询的默认和最大时长（小时）
+const (
+	defaultMetricsHistoryHours = 24
+	maxMetricsHistoryHours     = 24 * 366
+)
+
+// 限流时间序列查询的默认值
+const (
+	defaultRateLimitSeriesWindow     = time.Hour
+	defaultRateLimitSeriesResolution = time.Minute
+)
+
+// RateLimitTimeSeriesFunc 返回最近 window 时长内按 resolution 汇总的限流统计，未启用限流时返回 false
+type RateLimitTimeSeriesFunc func(window, resolution time.Duration) ([]metrics.RateLimitTimeSeriesPoint, bool)
+
+// MetricsHistorySource 返回最近 window 时长内的指标历史
+type MetricsHistorySource interface {
+	History(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error)
+}
+
+// MetricsHandlerOptions 运行指标处理器的数据来源，为 nil 的来源对应接口返回服务不可用
+type MetricsHandlerOptions struct {
+	HTTP            *metrics.HTTPMetrics         // HTTP 服务指标
+	History         MetricsHistorySource         // 指标历史
+	RateLimitSeries RateLimitTimeSeriesFunc      // 限流时间序列
+	RequestQueries  *metrics.RequestQueryMetrics // 按请求统计的数据库查询
+	GeoIP           bool                         // 是否启用了 IP 地理位置查询，未启用时按国家统计的接口返回服务不可用
+}
+
+// MetricsHandler 管理后台的运行指标查询
+type MetricsHandler struct {
+	http            *metrics.HTTPMetrics
+	history         MetricsHistorySource
+	rateLimitSeries RateLimitTimeSeriesFunc
+	requestQueries  *metrics.RequestQueryMetrics
+	geoIP           bool
+}
+
+// NewMetricsHandler 创建运行指标处理器
+func NewMetricsHandler(opts MetricsHandlerOptions) *MetricsHandler {
+	return &MetricsHandler{
+		http:            opts.HTTP,
+		history:         opts.History,
+		rateLimitSeries: opts.RateLimitSeries,
+		requestQueries:  opts.RequestQueries,
+		geoIP:           opts.GeoIP,
+	}
+}
+
+// HTTPMetrics godoc
+// @Summary HTTP 服务指标
+// @Description 按方法、路由模板和状态码返回请求数、请求和响应字节数以及耗时直方图，并返回当前和峰值处理中的请求数（仅管理员）。未匹配任何路由的请求计入 unmatched 路由。结果按请求数从多到少排序，可按路由模板和方法过滤。同样的指标以 Prometheus 格式在 /api/v1/metrics/prometheus 导出。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param route query string false "只返回该路由模板的统计，如 /api/v1/users/:id"
+// @Param method query string false "只返回该方法的统计，如 GET"
+// @Success 200 {object} models.SuccessResponse{data=metrics.HTTPStats} "成功获取 HTTP 服务指标"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "未启用 HTTP 指标"
+// @Router /api/v1/admin/metrics/http [get]
+func (h *MetricsHandler) HTTPMetrics(c *gin.Context) {
+	if h.http == nil {
+		response.ServiceUnavailableError(c, "metrics", localize(c, "metrics.http_disabled"))
+		return
+	}
+
+	stats := h.http.GetStats()
+	route := c.Query("route")
+	method := strings.ToUpper(c.Query("method"))
+	if route != "" || method != "" {
+		filtered := make([]metrics.HTTPRouteStats, 0, len(stats.Routes))
+		for _, series := range stats.Routes {
+			if (route == "" || series.Route == route) && (method == "" || series.Method == method) {
+				filtered = append(filtered, series)
+			}
+		}
+		stats.Routes = filtered
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "metrics.http_retrieved"), stats)
+}
+
+// Countries godoc
+// @Summary 按国家统计流量
+// @Description 按客户端 IP 所在国家返回请求数、4xx 和 5xx 数以及响应字节数（仅管理员），需要启用 GeoIP 查询。无法定位的请求计入 unknown。结果按请求数从多到少排序。同样的请求数以 Prometheus 格式在 /api/v1/metrics/prometheus 的 http_requests_by_country_total 导出。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]metrics.CountryStats} "成功获取按国家统计的流量"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "未启用 GeoIP 查询"
+// @Router /api/v1/admin/metrics/countries [get]
+func (h *MetricsHandler) Countries(c *gin.Context) {
+	if h.http == nil || !h.geoIP {
+		response.ServiceUnavailableError(c, "geoip", localize(c, "metrics.geoip_disabled"))
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "metrics.countries_retrieved"), h.http.GetCountryStats())
+}
+
+// History godoc
+// @Summary 指标历史
+// @Description 返回最近 hours 小时内按时间桶汇总的请求数、5xx 数、平均耗时、限流和用户缓存统计，供管理后台绘制趋势图（仅管理员）。数据来自定期写入数据库的指标快照，进程重启后仍然保留。查询范围不超过原始快照保留时长时按采集间隔返回，否则按汇总间隔返回，尚未结束的汇总时间桶不包含在内。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param hours query int false "查询最近多少小时，默认 24" minimum(1) maximum(8784)
+// @Success 200 {object} models.SuccessResponse{data=models.MetricsHistoryResponse} "成功获取指标历史"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "参数无效"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 500 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询指标快照失败"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "未启用指标快照"
+// @Router /api/v1/admin/metrics/history [get]
+func (h *MetricsHandler) History(c *gin.Context) {
+	if h.history == nil {
+		response.ServiceUnavailableError(c, "metrics", localize(c, "metrics.history_disabled"))
+		return
+	}
+
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", strconv.Itoa(defaultMetricsHistoryHours)))
+	if err != nil || hours < 1 || hours > maxMetricsHistoryHours {
+		response.ValidationError(c, localize(c, "metrics.invalid_history_request"), errors.ErrorDetails{
+			Field:      "hours",
+			Message:    localize(c, "metrics.hours_detail"),
+			Value:      c.Query("hours"),
+			Constraint: "min=1,max=8784",
+		})
+		return
+	}
+
+	history, err := h.history.History(c.Request.Context(), time.Duration(hours)*time.Hour)
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "metrics.history_failed"), err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "metrics.history_retrieved"), history)
+}
+
+// RateLimitTimeSeries godoc
+// @Summary 限流时间序列
+// @Description 返回本实例最近 window 时长内按 resolution 汇总的限流检查数、拒绝数、拒绝率和检查耗时（平均、最大、P95），供管理后台绘制限流趋势（仅管理员）。数据来自进程内按分钟汇总的统计，保留 24 小时，进程重启后清零；resolution 向上取整到分钟，数据点按 resolution 的整数倍对齐，没有请求的时间段返回 0。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param window query string false "查询最近多长时间，Go 时长格式，最大 24h" default(1h)
+// @Param resolution query string false "每个数据点的时长，Go 时长格式，不超过 window" default(1m)
+// @Success 200 {object} models.SuccessResponse{data=[]metrics.RateLimitTimeSeriesPoint} "成功获取限流时间序列"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "参数无效"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "未启用限流"
+// @Router /api/v1/admin/metrics/rate-limit [get]
+func (h *MetricsHandler) RateLimitTimeSeries(c *gin.Context) {
+	if h.rateLimitSeries == nil {
+		response.ServiceUnavailableError(c, "rate_limit", localize(c, "metrics.rate_limit_disabled"))
+		return
+	}
+
+	window, err := parseDurationQuery(c, "window", defaultRateLimitSeriesWindow)
+	if err != nil || window < time.Minute || window > metrics.DefaultTimeSeriesRetention {
+		response.ValidationError(c, localize(c, "metrics.invalid_rate_limit_request"), errors.ErrorDetails{
+			Field:      "window",
+			Message:    localize(c, "metrics.window_detail"),
+			Value:      c.Query("window"),
+			Constraint: "min=1m,max=24h",
+		})
+		return
+	}
+	resolution, err := parseDurationQuery(c, "resolution", defaultRateLimitSeriesResolution)
+	if err != nil || resolution < time.Minute || resolution > window {
+		response.ValidationError(c, localize(c, "metrics.invalid_rate_limit_request"), errors.ErrorDetails{
+			Field:      "resolution",
+			Message:    localize(c, "metrics.resolution_detail"),
+			Value:      c.Query("resolution"),
+			Constraint: "min=1m,max=window",
+		})
+		return
+	}
+
+	points, ok := h.rateLimitSeries(window, resolution)
+	if !ok {
+		response.ServiceUnavailableError(c, "rate_limit", localize(c, "metrics.rate_limit_disabled"))
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "metrics.rate_limit_retrieved"), points)
+}
+
+// QueryOffenders godoc
+// @Summary 请求查询最多的路由
+// @Description 返回本实例查询过多或出现 N+1 查询的路由（仅管理员），需要启用 database.request_queries。单个请求的查询数超过 max_queries，或同一语句（忽略参数）执行达到 repeat_threshold 次时，请求计入所属路由，并记录查询数最多的请求的重复语句和调用栈。结果按单个请求的最大查询数从多到少排序，数据在进程内保存，重启后清零。
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=metrics.RequestQueryStats} "成功获取请求查询统计"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "未启用请求查询计数"
+// @Router /api/v1/admin/metrics/queries [get]
+func (h *MetricsHandler) QueryOffenders(c *gin.Context) {
+	if h.requestQueries == nil {
+		response.ServiceUnavailableError(c, "metrics", localize(c, "metrics.request_queries_disabled"))
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "metrics.request_queries_retrieved"), h.requestQueries.GetStats())
+}
+
+// parseDurationQuery 解析 Go 时长格式的查询参数，参数为空时返回默认值
+func parseDurationQuery(c *gin.Context, name string, defaultValue time.Duration) (time.Duration, error) {
+	value := c.Query(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler_HTTPMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	httpMetrics := metrics.NewHTTPMetrics()
+	for _, req := range []struct {
+		method, route string
+		status        int
+	}{
+		{"GET", "/api/v1/users/:id", 200},
+		{"GET", "/api/v1/users/:id", 200},
+		{"PUT", "/api/v1/users/:id", 200},
+		{"GET", "/api/v1/users", 200},
+	} {
+		httpMetrics.RequestStarted()
+		httpMetrics.RequestFinished(req.method, req.route, req.status, time.Millisecond, 0, 10)
+	}
+	handler := NewMetricsHandler(MetricsHandlerOptions{HTTP: httpMetrics})
+
+	get := func(target string) (metrics.HTTPStats, int) {
+		c, w := newAdminContext(http.MethodGet, target, "", "")
+		handler.HTTPMetrics(c)
+
+		var body struct {
+			Data metrics.HTTPStats `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Data, w.Code
+	}
+
+	stats, code := get("/api/v1/admin/metrics/http")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint64(4), stats.TotalRequests)
+	require.Len(t, stats.Routes, 3)
+	assert.Equal(t, uint64(2), stats.Routes[0].Requests)
+
+	stats, _ = get("/api/v1/admin/metrics/http?route=/api/v1/users/:id&method=get")
+	require.Len(t, stats.Routes, 1)
+	assert.Equal(t, "GET", stats.Routes[0].Method)
+	assert.Equal(t, "/api/v1/users/:id", stats.Routes[0].Route)
+}
+
+func TestMetricsHandler_HTTPMetricsDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/http", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{}).HTTPMetrics(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// fakeMetricsHistory 记录查询时长并返回固定结果
+type fakeMetricsHistory struct {
+	window time.Duration
+	err    error
+}
+
+func (f *fakeMetricsHistory) History(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error) {
+	f.window = window
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.MetricsHistoryResponse{
+		Resolution: 60,
+		Points:     []models.MetricsHistoryPoint{{HTTPRequests: 5}},
+	}, nil
+}
+
+func TestMetricsHandler_Countries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	httpMetrics := metrics.NewHTTPMetrics()
+	httpMetrics.RecordCountry("DE", http.StatusOK, 10)
+	httpMetrics.RecordCountry("US", http.StatusOK, 10)
+	httpMetrics.RecordCountry("US", http.StatusTooManyRequests, 0)
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/countries", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{HTTP: httpMetrics, GeoIP: true}).Countries(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+
+	var body struct {
+		Data []metrics.CountryStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, metrics.CountryStats{Country: "US", Requests: 2, ClientErrors: 1, ResponseBytes: 10}, body.Data[0])
+	assert.Equal(t, "DE", body.Data[1].Country)
+
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/countries", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{HTTP: httpMetrics}).Countries(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestMetricsHandler_History(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	history := &fakeMetricsHistory{}
+	handler := NewMetricsHandler(MetricsHandlerOptions{History: history})
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/history", "", "")
+	handler.History(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 24*time.Hour, history.window)
+
+	var body struct {
+		Data models.MetricsHistoryResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 60, body.Data.Resolution)
+	require.Len(t, body.Data.Points, 1)
+	assert.Equal(t, int64(5), body.Data.Points[0].HTTPRequests)
+
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/history?hours=168", "", "")
+	handler.History(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 168*time.Hour, history.window)
+
+	for _, hours := range []string{"0", "abc", "9000"} {
+		c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/history?hours="+hours, "", "")
+		handler.History(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, hours)
+	}
+
+	history.err = errors.New("connection refused")
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/history", "", "")
+	handler.History(c)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestMetricsHandler_HistoryDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/history", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{}).History(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestMetricsHandler_RateLimitTimeSeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rateLimitMetrics := metrics.NewRateLimitMetrics()
+	rateLimitMetrics.RecordRequest("127.0.0.1", "", "/api/v1/users", time.Millisecond, true, "", 1, 100)
+	rateLimitMetrics.RecordRequest("127.0.0.1", "", "/api/v1/users", time.Millisecond, false, "", 101, 100)
+
+	var window, resolution time.Duration
+	handler := NewMetricsHandler(MetricsHandlerOptions{
+		RateLimitSeries: func(w, r time.Duration) ([]metrics.RateLimitTimeSeriesPoint, bool) {
+			window, resolution = w, r
+			return rateLimitMetrics.GetTimeSeries(w, r), true
+		},
+	})
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/rate-limit", "", "")
+	handler.RateLimitTimeSeries(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Hour, window)
+	assert.Equal(t, time.Minute, resolution)
+
+	var body struct {
+		Data []metrics.RateLimitTimeSeriesPoint `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Data)
+	var requests, throttled uint64
+	for _, point := range body.Data {
+		requests += point.Requests
+		throttled += point.Throttled
+	}
+	assert.Equal(t, uint64(2), requests)
+	assert.Equal(t, uint64(1), throttled)
+
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/rate-limit?window=6h&resolution=15m", "", "")
+	handler.RateLimitTimeSeries(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 6*time.Hour, window)
+	assert.Equal(t, 15*time.Minute, resolution)
+
+	for _, query := range []string{"window=abc", "window=48h", "window=30s", "resolution=2h", "resolution=10s"} {
+		c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/rate-limit?"+query, "", "")
+		handler.RateLimitTimeSeries(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestMetricsHandler_RateLimitTimeSeriesDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/rate-limit", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{}).RateLimitTimeSeries(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	disabled := func(window, resolution time.Duration) ([]metrics.RateLimitTimeSeriesPoint, bool) { return nil, false }
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/rate-limit", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{RateLimitSeries: disabled}).RateLimitTimeSeries(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestMetricsHandler_QueryOffenders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	requestQueries := metrics.NewRequestQueryMetrics(50, 2, 10)
+	requestQueries.Begin("req-1")
+	requestQueries.Record("req-1", "SELECT * FROM roles WHERE user_id = $1", nil)
+	requestQueries.Record("req-1", "SELECT * FROM roles WHERE user_id = $1", nil)
+	requestQueries.End("req-1", http.MethodGet, "/api/v1/users")
+
+	c, w := newAdminContext(http.MethodGet, "/api/v1/admin/metrics/queries", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{RequestQueries: requestQueries}).QueryOffenders(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+
+	var body struct {
+		Data metrics.RequestQueryStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, uint64(1), body.Data.FlaggedRequests)
+	require.Len(t, body.Data.Offenders, 1)
+	assert.Equal(t, "/api/v1/users", body.Data.Offenders[0].Route)
+	assert.Equal(t, "SELECT * FROM roles WHERE user_id = ?", body.Data.Offenders[0].TopStatement)
+
+	c, w = newAdminContext(http.MethodGet, "/api/v1/admin/metrics/queries", "", "")
+	NewMetricsHandler(MetricsHandlerOptions{}).QueryOffenders(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
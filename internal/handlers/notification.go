@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"go-server/internal/models"
+	"go-server/internal/notifications"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler 处理当前用户的通知收件箱和通知偏好接口（/api/v1/users/me/notifications）
+type NotificationHandler struct {
+	service *notifications.Service
+}
+
+// NewNotificationHandler 创建通知处理器，service 为 nil 时接口返回 503
+func NewNotificationHandler(service *notifications.Service) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// ListNotifications godoc
+// @Summary 获取通知收件箱
+// @Description 分页返回当前用户的站内信，按创建时间倒序。unread=true 时只返回未读通知；unread_count 为全部未读数，读取走缓存
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "只返回未读通知"
+// @Paginate default(20)
+// @Success 200 {object} models.SuccessResponse{data=models.NotificationListResponse} "成功获取通知"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "查询参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知服务未启用"
+// @Router /api/v1/users/me/notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		response.ValidationError(c, localize(c, "pagination.invalid_page"),
+			errors.ErrorDetails{Field: "page", Message: localize(c, "pagination.invalid_page"), Value: page})
+		return
+	}
+	if limit < 1 || limit > 100 {
+		response.ValidationError(c, localize(c, "pagination.invalid_limit"),
+			errors.ErrorDetails{Field: "limit", Message: localize(c, "pagination.invalid_limit"), Value: limit})
+		return
+	}
+	var unreadOnly bool
+	if value := c.Query("unread"); value != "" {
+		var err error
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			response.ValidationError(c, localize(c, "notification.invalid_unread"),
+				errors.ErrorDetails{Field: "unread", Message: localize(c, "notification.unread_detail"), Value: value})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	items, total, err := h.service.List(ctx, userID, unreadOnly, page, limit)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.get_failed"), err)
+		return
+	}
+	unread, err := h.service.UnreadCount(ctx, userID)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.count_unread_failed"), err)
+		return
+	}
+
+	list := make([]models.Notification, len(items))
+	for i, item := range items {
+		list[i] = *item
+	}
+	response.Success(c, http.StatusOK, localize(c, "notification.retrieved"), models.NotificationListResponse{
+		Notifications: list,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+		UnreadCount: unread,
+	})
+}
+
+// MarkNotificationRead godoc
+// @Summary 标记通知已读
+// @Description 把当前用户的一条通知标记为已读，已读的通知保留原来的已读时间
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "通知ID"
+// @Success 200 {object} models.SuccessResponse "通知已标记为已读"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知服务未启用"
+// @Router /api/v1/users/me/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.NotFoundError(c, "notification", id)
+		return
+	}
+	err := h.service.MarkRead(c.Request.Context(), userID, id)
+	if stderrors.Is(err, notifications.ErrNotFound) {
+		response.NotFoundError(c, "notification", id)
+		return
+	}
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.mark_read_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "notification.marked_read"), nil)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary 标记全部通知已读
+// @Description 把当前用户的全部未读通知标记为已读
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=models.MarkNotificationsReadResponse} "全部通知已标记为已读"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知服务未启用"
+// @Router /api/v1/users/me/notifications/read [post]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.mark_read_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "notification.all_marked_read"), models.MarkNotificationsReadResponse{Marked: marked})
+}
+
+// GetNotificationPreferences godoc
+// @Summary 获取通知偏好
+// @Description 返回当前用户对每种通知类型在每个已启用渠道上是否投递，未设置的使用通知类型的默认渠道
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]models.NotificationPreference} "成功获取通知偏好"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知服务未启用"
+// @Router /api/v1/users/me/notification-preferences [get]
+func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.service.Preferences(c.Request.Context(), userID)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.get_preferences_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "notification.preferences_retrieved"), preferences)
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary 修改通知偏好
+// @Description 修改当前用户对指定通知类型和渠道的偏好，未列出的保持不变，返回修改后的全部偏好
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateNotificationPreferencesRequest true "通知偏好"
+// @Success 200 {object} models.SuccessResponse{data=[]models.NotificationPreference} "通知偏好已修改"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误或通知类型、渠道不存在"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "通知服务未启用"
+// @Router /api/v1/users/me/notification-preferences [put]
+func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	preferences := make([]models.NotificationPreference, len(req.Preferences))
+	for i, preference := range req.Preferences {
+		preferences[i] = models.NotificationPreference{
+			Type:    preference.Type,
+			Channel: preference.Channel,
+			Enabled: preference.Enabled,
+		}
+	}
+	ctx := c.Request.Context()
+	err := h.service.UpdatePreferences(ctx, userID, preferences)
+	switch {
+	case stderrors.Is(err, notifications.ErrUnknownType):
+		response.ValidationError(c, localize(c, "notification.unknown_type"), errors.ErrorDetails{Field: "preferences.type", Message: err.Error()})
+		return
+	case stderrors.Is(err, notifications.ErrUnknownChannel):
+		response.ValidationError(c, localize(c, "notification.channel_disabled"), errors.ErrorDetails{Field: "preferences.channel", Message: err.Error()})
+		return
+	case err != nil:
+		response.DatabaseError(c, localize(c, "notification.save_preferences_failed"), err)
+		return
+	}
+
+	updated, err := h.service.Preferences(ctx, userID)
+	if err != nil {
+		response.DatabaseError(c, localize(c, "notification.get_preferences_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "notification.preferences_updated"), updated)
+}
+
+// currentUserID 返回当前用户ID，通知服务未启用时返回 503
+func (h *NotificationHandler) currentUserID(c *gin.Context) (string, bool) {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "notifications", localize(c, "notification.disabled"))
+		return "", false
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
+		return "", false
+	}
+	return userID.(string), true
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/url"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/oidc"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler 处理 OpenID Connect 提供方接口：发现文档、授权、令牌和用户信息（/api/v1/oauth），
+// 以及管理员管理客户端（/api/v1/admin/oauth-clients）
+type OIDCHandler struct {
+	service *oidc.Service
+	audit   audit.Recorder
+}
+
+// NewOIDCHandler 创建 OpenID Connect 处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewOIDCHandler(service *oidc.Service, recorder audit.Recorder) *OIDCHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &OIDCHandler{
+		service: service,
+		audit:   recorder,
+	}
+}
+
+// Discovery godoc
+// @Summary OpenID Connect 发现文档
+// @Description 返回 OpenID Connect 提供方的元数据，依赖方据此找到授权、令牌、用户信息端点和公钥集合。authorization_endpoint 为前端的授权同意页面
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} models.OIDCDiscovery
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	doc, err := h.service.Discovery()
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "oidc.discovery_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, doc)
+}
+
+// Authorize godoc
+// @Summary 读取授权请求
+// @Description 授权同意页面携带用户的访问令牌调用，校验依赖方的授权请求（参数与授权端点的查询参数相同）并返回需要展示的客户端名称和范围。只支持 code 响应类型和 S256 PKCE，scope 须包含 openid，不支持的范围被忽略
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Param response_type query string true "响应类型" Enums(code)
+// @Param client_id query string true "客户端ID"
+// @Param redirect_uri query string true "回调地址"
+// @Param scope query string true "以空格分隔的范围"
+// @Param state query string false "原样返回给客户端的状态"
+// @Param nonce query string false "写入 ID 令牌的随机数"
+// @Param code_challenge query string true "PKCE 挑战值"
+// @Param code_challenge_method query string true "PKCE 挑战方法" Enums(S256)
+// @Success 200 {object} models.SuccessResponse{data=models.OAuthConsentResponse} "授权请求有效"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "授权请求无效，error_code 为 OAuth 错误码"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/oauth/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.OAuthAuthorizeRequest
+	if !validation.BindQuery(c, &req) {
+		return
+	}
+	consent, err := h.service.Consent(c.Request.Context(), &req)
+	if err != nil {
+		h.authorizationError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "oidc.request_valid"), consent)
+}
+
+// Approve godoc
+// @Summary 提交授权决定
+// @Description 授权同意页面提交用户的决定和原始授权请求。同意时签发一次性授权码，拒绝时返回 access_denied 错误，客户端应将浏览器重定向到响应中的 redirect_to
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.OAuthConsentRequest true "授权请求和用户的决定"
+// @Success 200 {object} models.SuccessResponse{data=models.OAuthRedirectResponse} "授权决定已处理"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "授权请求无效，error_code 为 OAuth 错误码"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/oauth/authorize [post]
+func (h *OIDCHandler) Approve(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.OAuthConsentRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	userID := c.GetString("user_id")
+	redirectTo, err := h.service.Decide(c.Request.Context(), &req.OAuthAuthorizeRequest, userID, req.Approve)
+	if err != nil {
+		h.authorizationError(c, err)
+		return
+	}
+
+	action := audit.ActionOAuthConsentGranted
+	if !req.Approve {
+		action = audit.ActionOAuthConsentDenied
+	}
+	h.audit.Record(c.Request.Context(), audit.Event{
+		Action:    action,
+		ActorID:   userID,
+		TargetID:  userID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   map[string]interface{}{"client_id": req.ClientID, "scope": req.Scope},
+	})
+	response.Success(c, http.StatusOK, localize(c, "oidc.decision_processed"), models.OAuthRedirectResponse{RedirectTo: redirectTo})
+}
+
+// Token godoc
+// @Summary 兑换令牌
+// @Description 依赖方用授权码和 code_verifier 换取访问令牌和 ID 令牌（RFC 6749 第 4.1.3 节）。机密客户端通过 HTTP Basic 认证或 client_secret 参数提供客户端密钥，公开客户端只提供 client_id。授权码只能兑换一次。错误响应使用 OAuth 2.0 格式
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "授权类型" Enums(authorization_code)
+// @Param code formData string true "授权码"
+// @Param redirect_uri formData string true "授权请求中的回调地址"
+// @Param code_verifier formData string true "PKCE 验证值"
+// @Param client_id formData string false "客户端ID，使用 HTTP Basic 认证时省略"
+// @Param client_secret formData string false "客户端密钥，使用 HTTP Basic 认证时省略"
+// @Success 200 {object} models.OAuthTokenResponse
+// @Failure 400 {object} models.OAuthErrorResponse "请求无效或授权码无效"
+// @Failure 401 {object} models.OAuthErrorResponse "客户端认证失败"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/oauth/token [post]
+func (h *OIDCHandler) Token(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	req := oidc.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+		CodeVerifier: c.PostForm("code_verifier"),
+	}
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// client_secret_basic 的凭据先按表单编码（RFC 6749 第 2.3.1 节）
+		req.ClientID, _ = url.QueryUnescape(id)
+		req.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	tokens, err := h.service.Exchange(c.Request.Context(), req)
+	var protoErr *oidc.Error
+	switch {
+	case stderrors.As(err, &protoErr):
+		status := http.StatusBadRequest
+		if protoErr.Code == oidc.ErrorInvalidClient {
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		c.JSON(status, models.OAuthErrorResponse{Error: protoErr.Code, ErrorDescription: protoErr.Description})
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "oidc.exchange_failed"), err)
+	default:
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+// UserInfo godoc
+// @Summary 用户信息
+// @Description 返回访问令牌所属用户的标准声明（OpenID Connect Core 第 5.3 节）
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.OIDCUserInfo
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/oauth/userinfo [get]
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	info, err := h.service.UserInfo(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		response.UnauthorizedError(c, localize(c, "oidc.user_not_found"))
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// ListClients godoc
+// @Summary 列出 OpenID Connect 客户端
+// @Description 列出已注册的客户端，按创建时间倒序（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]models.OAuthClient} "成功获取客户端"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/admin/oauth-clients [get]
+func (h *OIDCHandler) ListClients(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	clients, err := h.service.ListClients(c.Request.Context())
+	if err != nil {
+		response.DatabaseError(c, localize(c, "oidc.get_clients_failed"), err)
+		return
+	}
+	list := make([]models.OAuthClient, len(clients))
+	for i, client := range clients {
+		list[i] = *client
+	}
+	response.Success(c, http.StatusOK, localize(c, "oidc.clients_retrieved"), list)
+}
+
+// CreateClient godoc
+// @Summary 注册 OpenID Connect 客户端
+// @Description 注册依赖方（仅管理员）。回调地址须为不带片段的 http 或 https 绝对地址，授权请求中的 redirect_uri 须与其中之一完全一致。机密客户端的客户端密钥只在响应中返回一次
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.OAuthClientRequest true "客户端定义"
+// @Success 201 {object} models.SuccessResponse{data=models.OAuthClientCreatedResponse} "客户端已注册"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/admin/oauth-clients [post]
+func (h *OIDCHandler) CreateClient(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.OAuthClientRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	client, secret, err := h.service.RegisterClient(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		h.record(c, audit.ActionOAuthClientCreated, "", err, map[string]interface{}{"name": req.Name})
+		if stderrors.Is(err, oidc.ErrInvalidRedirectURI) {
+			response.ValidationError(c, localize(c, "oidc.invalid_redirect_uri"), errors.ErrorDetails{Field: "redirect_uris", Message: err.Error()})
+			return
+		}
+		response.DatabaseError(c, localize(c, "oidc.register_client_failed"), err)
+		return
+	}
+	h.record(c, audit.ActionOAuthClientCreated, client.ID, nil, map[string]interface{}{
+		"name":          client.Name,
+		"confidential":  client.Confidential,
+		"redirect_uris": client.RedirectURIs,
+	})
+	response.Success(c, http.StatusCreated, localize(c, "oidc.client_registered"), models.OAuthClientCreatedResponse{
+		Client:       *client,
+		ClientSecret: secret,
+	})
+}
+
+// DeleteClient godoc
+// @Summary 删除 OpenID Connect 客户端
+// @Description 删除客户端（仅管理员），之后该客户端不能再发起授权或兑换令牌，已签发的令牌在过期前仍然有效
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "客户端ID"
+// @Success 200 {object} models.SuccessResponse "客户端已删除"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "客户端不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "OpenID Connect 未启用"
+// @Router /api/v1/admin/oauth-clients/{id} [delete]
+func (h *OIDCHandler) DeleteClient(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	id := c.Param("id")
+	err := h.service.DeleteClient(c.Request.Context(), id)
+	h.record(c, audit.ActionOAuthClientDeleted, id, err, nil)
+	switch {
+	case stderrors.Is(err, oidc.ErrClientNotFound):
+		response.NotFoundError(c, "oauth client", id)
+	case err != nil:
+		response.DatabaseError(c, localize(c, "oidc.delete_client_failed"), err)
+	default:
+		response.Success(c, http.StatusOK, localize(c, "oidc.client_deleted"), nil)
+	}
+}
+
+// available OpenID Connect 未启用时返回 503
+func (h *OIDCHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "oidc", localize(c, "oidc.disabled"))
+		return false
+	}
+	return true
+}
+
+// authorizationError 将授权请求的错误转换为响应，协议错误的 error_code 为 OAuth 错误码
+func (h *OIDCHandler) authorizationError(c *gin.Context, err error) {
+	var protoErr *oidc.Error
+	if stderrors.As(err, &protoErr) {
+		response.ValidationError(c, localize(c, "oidc.invalid_request"), errors.ErrorDetails{
+			Field:     protoErr.Param,
+			Message:   protoErr.Description,
+			ErrorCode: protoErr.Code,
+		})
+		return
+	}
+	response.InternalServerErrorWithCause(c, localize(c, "oidc.request_failed"), err)
+}
+
+// record 记录客户端管理的审计事件，目标为客户端ID
+func (h *OIDCHandler) record(c *gin.Context, action, clientID string, err error, details map[string]interface{}) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   c.GetString("user_id"),
+		TargetID:  clientID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
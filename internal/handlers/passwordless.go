@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/passwordless"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordlessHandler 处理无密码登录接口：设备授权流程（/api/v1/auth/device）和邮件登录链接（/api/v1/auth/magic-link）
+type PasswordlessHandler struct {
+	service *passwordless.Service
+	audit   audit.Recorder
+}
+
+// NewPasswordlessHandler 创建无密码登录处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewPasswordlessHandler(service *passwordless.Service, recorder audit.Recorder) *PasswordlessHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &PasswordlessHandler{
+		service: service,
+		audit:   recorder,
+	}
+}
+
+// StartDevice godoc
+// @Summary 申请设备码
+// @Description 设备（电视、命令行工具）申请设备码和用户码（RFC 8628）。设备显示 user_code 和 verification_uri（或把 verification_uri_complete 显示为二维码），然后按 interval 轮询令牌接口
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse{data=models.DeviceCodeResponse} "设备码已创建"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "无密码登录未启用"
+// @Router /api/v1/auth/device/code [post]
+func (h *PasswordlessHandler) StartDevice(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	device, err := h.service.StartDevice(c.Request.Context())
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "passwordless.create_device_code_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, http.StatusOK, localize(c, "passwordless.device_code_created"), device)
+}
+
+// DeviceToken godoc
+// @Summary 设备轮询令牌
+// @Description 设备用设备码轮询，用户允许后返回与密码登录相同的访问令牌，设备码只能兑换一次。用户尚未决定时返回 400，error_code 为 authorization_pending；轮询过快为 slow_down，设备应把间隔增加 5 秒；用户拒绝为 access_denied；设备码无效或过期为 expired_token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.DeviceTokenRequest true "设备码"
+// @Success 200 {object} models.SuccessResponse{data=models.LoginResponse} "登录成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误，或用户尚未允许、轮询过快、用户拒绝、设备码无效或过期"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "无密码登录未启用"
+// @Router /api/v1/auth/device/token [post]
+func (h *PasswordlessHandler) DeviceToken(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.DeviceTokenRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	tokens, err := h.service.PollDevice(c.Request.Context(), req.DeviceCode)
+	var deviceErr *passwordless.DeviceError
+	switch {
+	case stderrors.As(err, &deviceErr):
+		response.ValidationError(c, localize(c, "passwordless.authorization_pending"), errors.ErrorDetails{
+			Field:     "device_code",
+			Message:   deviceErr.Description,
+			ErrorCode: deviceErr.Code,
+		})
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "passwordless.issue_token_failed"), err)
+	default:
+		response.Success(c, http.StatusOK, localize(c, "auth.login_succeeded"), tokens)
+	}
+}
+
+// VerifyDevice godoc
+// @Summary 允许或拒绝设备登录
+// @Description 已登录用户在验证页面输入设备上显示的用户码，允许后设备以该用户身份登录。用户码不区分大小写，只能使用一次
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DeviceVerifyRequest true "用户码和决定"
+// @Success 200 {object} models.SuccessResponse "已记录决定"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误或用户码无效、已过期"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "无密码登录未启用"
+// @Router /api/v1/auth/device/verify [post]
+func (h *PasswordlessHandler) VerifyDevice(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.DeviceVerifyRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	action := audit.ActionDeviceDenied
+	if req.Approve {
+		action = audit.ActionDeviceApproved
+	}
+	err := h.service.VerifyDevice(c.Request.Context(), req.UserCode, userID, req.Approve)
+	h.record(c, action, userID, err)
+	switch {
+	case stderrors.Is(err, passwordless.ErrInvalidUserCode):
+		response.ValidationError(c, localize(c, "passwordless.invalid_user_code"), errors.ErrorDetails{Field: "user_code", Message: err.Error()})
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "passwordless.verify_device_failed"), err)
+	case req.Approve:
+		response.Success(c, http.StatusOK, localize(c, "passwordless.device_approved"), nil)
+	default:
+		response.Success(c, http.StatusOK, localize(c, "passwordless.device_denied"), nil)
+	}
+}
+
+// RequestMagicLink godoc
+// @Summary 申请登录链接
+// @Description 向账号邮箱发送登录链接，链接在 passwordless.magic_link_ttl 内有效且只能使用一次。邮箱不存在或账号已停用时同样返回 202，不泄露账号是否存在
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MagicLinkRequest true "账号邮箱"
+// @Success 202 {object} models.SuccessResponse{data=models.MagicLinkResponse} "登录链接已发送"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "无密码登录未启用"
+// @Router /api/v1/auth/magic-link [post]
+func (h *PasswordlessHandler) RequestMagicLink(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.MagicLinkRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	expiresAt, err := h.service.RequestMagicLink(c.Request.Context(), req.Email)
+	switch {
+	case stderrors.Is(err, passwordless.ErrMagicLinkUnavailable):
+		response.ServiceUnavailableError(c, "magic_link", localize(c, "passwordless.magic_link_unavailable"))
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "passwordless.send_magic_link_failed"), err)
+	default:
+		response.Success(c, http.StatusAccepted, localize(c, "passwordless.magic_link_sent"), models.MagicLinkResponse{ExpiresAt: expiresAt})
+	}
+}
+
+// RedeemMagicLink godoc
+// @Summary 使用登录链接登录
+// @Description 用登录链接中的令牌换取与密码登录相同的访问令牌，令牌只能使用一次
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MagicLinkVerifyRequest true "链接令牌"
+// @Success 200 {object} models.SuccessResponse{data=models.LoginResponse} "登录成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "链接无效、已过期或已使用"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "无密码登录未启用"
+// @Router /api/v1/auth/magic-link/verify [post]
+func (h *PasswordlessHandler) RedeemMagicLink(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.MagicLinkVerifyRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	tokens, err := h.service.RedeemMagicLink(c.Request.Context(), req.Token)
+	switch {
+	case stderrors.Is(err, passwordless.ErrInvalidMagicLink), stderrors.Is(err, passwordless.ErrUserInactive):
+		h.record(c, audit.ActionMagicLinkLogin, "", err)
+		response.UnauthorizedError(c, localize(c, "passwordless.invalid_magic_link"))
+	case err != nil:
+		response.InternalServerErrorWithCause(c, localize(c, "auth.login_failed"), err)
+	default:
+		h.record(c, audit.ActionMagicLinkLogin, tokens.User.ID, nil)
+		response.Success(c, http.StatusOK, localize(c, "auth.login_succeeded"), tokens)
+	}
+}
+
+// available 无密码登录未启用时返回 503
+func (h *PasswordlessHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "passwordless", localize(c, "passwordless.disabled"))
+		return false
+	}
+	return true
+}
+
+// record 记录设备授权和登录链接的审计事件
+func (h *PasswordlessHandler) record(c *gin.Context, action, userID string, err error) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   userID,
+		TargetID:  userID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
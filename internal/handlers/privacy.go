@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/privacy"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler 处理个人数据请求：用户导出自己的数据、抹除自己的账户（/api/v1/users/me），
+// 管理员代用户抹除账户（/api/v1/admin/users/{id}/erasure）
+type PrivacyHandler struct {
+	service     *privacy.Service
+	userService services.UserService
+	audit       audit.Recorder
+}
+
+// NewPrivacyHandler 创建个人数据处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewPrivacyHandler(service *privacy.Service, userService services.UserService, recorder audit.Recorder) *PrivacyHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &PrivacyHandler{service: service, userService: userService, audit: recorder}
+}
+
+// ExportMyData godoc
+// @Summary 导出当前用户的个人数据
+// @Description 返回当前用户个人数据的完整副本：资料、全部站内信和各通知类型在各渠道上的投递偏好。version 为格式版本，字段发生不兼容的变化时递增
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=models.PersonalDataArchive} "个人数据"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "个人数据服务不可用"
+// @Router /api/v1/users/me/data-export [post]
+func (h *PrivacyHandler) ExportMyData(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "privacy", localize(c, "privacy.unavailable"))
+		return
+	}
+
+	archive, err := h.service.Export(c.Request.Context(), userID)
+	h.record(c, audit.ActionDataExported, userID, userID, err, nil)
+	if err != nil {
+		h.respondError(c, userID, localize(c, "privacy.export_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "privacy.exported"), archive)
+}
+
+// EraseMyAccount godoc
+// @Summary 抹除当前用户的账户
+// @Description 校验当前密码后抹除当前用户的个人数据：吊销已签发的全部令牌，删除站内信、通知偏好和头像文件，将用户名、邮箱、姓名等替换为占位值并注销账户。操作不可撤销，原邮箱和用户名可以重新注册
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DeleteAccountRequest true "当前密码"
+// @Success 200 {object} models.SuccessResponse{data=models.ErasureReport} "账户已抹除"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "当前密码错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "个人数据服务不可用"
+// @Router /api/v1/users/me/erasure [post]
+func (h *PrivacyHandler) EraseMyAccount(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "privacy", localize(c, "privacy.unavailable"))
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		h.record(c, audit.ActionAccountErased, userID, userID, err, nil)
+		h.respondError(c, userID, localize(c, "privacy.anonymize_failed"), err)
+		return
+	}
+	if _, err := h.userService.ValidateCredentials(c.Request.Context(), user.Email, req.Password); err != nil {
+		h.record(c, audit.ActionAccountErased, userID, userID, err, nil)
+		response.ValidationError(c, localize(c, "auth.incorrect_password"),
+			errors.ErrorDetails{Field: "password", Message: localize(c, "auth.password_incorrect")})
+		return
+	}
+
+	h.erase(c, audit.ActionAccountErased, userID, userID)
+}
+
+// EraseUser godoc
+// @Summary 抹除用户的账户
+// @Description 代用户抹除其个人数据（仅管理员），用于处理通过其他渠道提出的删除请求，已注销的账户也可以抹除。处理与用户自行抹除相同，操作不可撤销。管理员不能通过该接口抹除自己
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} models.SuccessResponse{data=models.ErasureReport} "账户已抹除"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限或不能抹除自己"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "个人数据服务不可用"
+// @Router /api/v1/admin/users/{id}/erasure [post]
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	adminID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "privacy", localize(c, "privacy.unavailable"))
+		return
+	}
+
+	targetID := c.Param("id")
+	if targetID == adminID {
+		response.ForbiddenError(c, localize(c, "privacy.anonymize_self"))
+		return
+	}
+	h.erase(c, audit.ActionUserErased, adminID, targetID)
+}
+
+// erase 抹除用户的个人数据并记录审计事件
+func (h *PrivacyHandler) erase(c *gin.Context, action, actorID, targetID string) {
+	report, err := h.service.Erase(c.Request.Context(), targetID)
+	if err != nil {
+		h.record(c, action, actorID, targetID, err, nil)
+		h.respondError(c, targetID, localize(c, "privacy.anonymize_failed"), err)
+		return
+	}
+
+	h.record(c, action, actorID, targetID, nil, map[string]interface{}{
+		"notifications_deleted": report.NotificationsDeleted,
+		"avatar_deleted":        report.AvatarDeleted,
+		"tokens_revoked":        report.TokensRevoked,
+	})
+	response.Success(c, http.StatusOK, localize(c, "privacy.anonymized"), report)
+}
+
+// currentUserID 返回认证中间件写入的用户ID，未认证时写入 401 响应
+func (h *PrivacyHandler) currentUserID(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
+		return "", false
+	}
+	return userID.(string), true
+}
+
+// respondError 将个人数据服务的错误映射为对应的 HTTP 状态码
+func (h *PrivacyHandler) respondError(c *gin.Context, userID, message string, err error) {
+	if stderrors.Is(err, repositories.ErrUserNotFound) || err.Error() == "user not found" {
+		response.NotFoundError(c, "User", userID)
+		return
+	}
+	response.InternalServerErrorWithCause(c, message, err)
+}
+
+// record 记录一次个人数据请求的审计事件，err 不为 nil 时记为失败
+func (h *PrivacyHandler) record(c *gin.Context, action, actorID, targetID string, err error, details map[string]interface{}) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/services"
+	"go-server/internal/validation"
+	"go-server/pkg/cache"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler 处理当前用户的自助资料接口（/api/v1/users/me）
+type ProfileHandler struct {
+	userService services.UserService
+	blacklist   *cache.BlacklistService
+	audit       audit.Recorder
+}
+
+// NewProfileHandler 创建个人资料处理器，blacklist 为 nil 时修改密码和注销账户不会吊销已签发的令牌，
+// recorder 为 nil 时不记录审计事件
+func NewProfileHandler(userService services.UserService, blacklist *cache.BlacklistService, recorder audit.Recorder) *ProfileHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &ProfileHandler{
+		userService: userService,
+		blacklist:   blacklist,
+		audit:       recorder,
+	}
+}
+
+// GetProfile godoc
+// @Summary 获取当前用户资料
+// @Description 返回当前登录用户的资料，读取走缓存仓库。响应带有 ETag，携带 If-None-Match 且未变更时返回 304
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param If-None-Match header string false "上次获取时的 ETag"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "获取成功"
+// @Success 304 "资料未变更"
+// @Header 200 {string} ETag "资料的实体标签"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户不存在"
+// @Router /api/v1/users/me [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response.NotFoundError(c, "User", userID)
+			return
+		}
+		response.InternalServerErrorWithCause(c, localize(c, "profile.get_failed"), err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, localize(c, "profile.retrieved"), user.ToSafeUser())
+}
+
+// UpdateProfile godoc
+// @Summary 更新当前用户资料
+// @Description 更新当前登录用户的用户名、姓名和头像URL，更新后相关缓存立即失效。
+// @Description 携带 If-Match 时仅在资料未被其他请求修改过时更新，否则返回 412
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param If-Match header string false "获取资料时的 ETag"
+// @Param request body models.UpdateUserRequest true "资料信息"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "更新成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用户名已被占用"
+// @Failure 412 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "资料已被修改"
+// @Header 200 {string} ETag "更新后资料的实体标签"
+// @Router /api/v1/users/me [put]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	if !checkUserIfMatch(c, h.userService, userID) {
+		return
+	}
+
+	user, err := h.userService.Update(c.Request.Context(), userID, &req, userID)
+	if err != nil {
+		h.record(c, audit.ActionProfileUpdated, userID, err, nil)
+		switch err.Error() {
+		case "user not found":
+			response.NotFoundError(c, "User", userID)
+		case "username already taken":
+			response.ConflictError(c, localize(c, "auth.username_taken"), map[string]interface{}{
+				"field": "username",
+				"value": req.Username,
+			})
+		default:
+			response.InternalServerErrorWithCause(c, localize(c, "profile.update_failed"), err)
+		}
+		return
+	}
+
+	h.record(c, audit.ActionProfileUpdated, userID, nil, nil)
+	safeUser := user.ToSafeUser()
+	response.SetETag(c, response.ComputeETag(safeUser))
+	response.Success(c, http.StatusOK, localize(c, "profile.updated"), safeUser)
+}
+
+// ChangePassword godoc
+// @Summary 修改当前用户密码
+// @Description 校验当前密码后修改密码，并吊销该用户此前签发的全部令牌，客户端需要重新登录
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangePasswordRequest true "密码信息"
+// @Success 200 {object} models.SuccessResponse "密码修改成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "当前密码错误或新密码不符合密码策略"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Router /api/v1/users/me/password [post]
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, &req); err != nil {
+		h.record(c, audit.ActionPasswordChanged, userID, err, nil)
+		if err.Error() == "old password is incorrect" {
+			response.ValidationError(c, localize(c, "auth.incorrect_password"),
+				errors.ErrorDetails{Field: "old_password", Message: localize(c, "auth.incorrect_old_password")})
+			return
+		}
+		if passwordPolicyError(c, "new_password", err) {
+			return
+		}
+		response.InternalServerErrorWithCause(c, localize(c, "auth.change_password_failed"), err)
+		return
+	}
+
+	revoked := h.revokeTokens(c, userID)
+	h.record(c, audit.ActionPasswordChanged, userID, nil, map[string]interface{}{"tokens_revoked": revoked})
+	response.Success(c, http.StatusOK, localize(c, "profile.password_changed"), nil)
+}
+
+// RequestEmailChange godoc
+// @Summary 申请变更当前用户邮箱
+// @Description 校验当前密码后向新邮箱发送验证令牌，新邮箱在调用验证接口确认后才生效，令牌有效期 24 小时
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangeEmailRequest true "新邮箱和当前密码"
+// @Success 202 {object} models.SuccessResponse{data=models.EmailChangeResponse} "验证邮件已发送"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求格式错误或当前密码错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "邮箱已被占用"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "邮箱变更不可用"
+// @Router /api/v1/users/me/email [post]
+func (h *ProfileHandler) RequestEmailChange(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	expiresAt, err := h.userService.RequestEmailChange(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.record(c, audit.ActionEmailChangeRequested, userID, err, nil)
+		h.respondEmailChangeError(c, userID, req.NewEmail, err)
+		return
+	}
+
+	h.record(c, audit.ActionEmailChangeRequested, userID, nil, nil)
+	response.Success(c, http.StatusAccepted, localize(c, "profile.email_verification_sent"), models.EmailChangeResponse{
+		NewEmail:  req.NewEmail,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ConfirmEmailChange godoc
+// @Summary 确认变更当前用户邮箱
+// @Description 使用发送到新邮箱的验证令牌确认邮箱变更，令牌只能使用一次
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ConfirmEmailChangeRequest true "验证令牌"
+// @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "邮箱变更成功"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "验证令牌无效或已过期"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "邮箱已被占用"
+// @Router /api/v1/users/me/email/verify [post]
+func (h *ProfileHandler) ConfirmEmailChange(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmEmailChangeRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.ConfirmEmailChange(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		h.record(c, audit.ActionEmailChanged, userID, err, nil)
+		h.respondEmailChangeError(c, userID, "", err)
+		return
+	}
+
+	h.record(c, audit.ActionEmailChanged, userID, nil, nil)
+	response.Success(c, http.StatusOK, localize(c, "profile.email_changed"), user.ToSafeUser())
+}
+
+// DeleteAccount godoc
+// @Summary 注销当前用户账户
+// @Description 校验当前密码后软删除当前用户，并吊销该用户已签发的全部令牌
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DeleteAccountRequest true "当前密码"
+// @Success 200 {object} models.SuccessResponse "账户已注销"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "当前密码错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Router /api/v1/users/me [delete]
+func (h *ProfileHandler) DeleteAccount(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		h.record(c, audit.ActionAccountDeleted, userID, err, nil)
+		if err.Error() == "user not found" {
+			response.NotFoundError(c, "User", userID)
+			return
+		}
+		response.InternalServerErrorWithCause(c, localize(c, "profile.delete_failed"), err)
+		return
+	}
+
+	if _, err := h.userService.ValidateCredentials(c.Request.Context(), user.Email, req.Password); err != nil {
+		h.record(c, audit.ActionAccountDeleted, userID, err, nil)
+		response.ValidationError(c, localize(c, "auth.incorrect_password"),
+			errors.ErrorDetails{Field: "password", Message: localize(c, "auth.password_incorrect")})
+		return
+	}
+
+	if err := h.userService.Delete(c.Request.Context(), userID, userID); err != nil {
+		h.record(c, audit.ActionAccountDeleted, userID, err, nil)
+		response.InternalServerErrorWithCause(c, localize(c, "profile.delete_failed"), err)
+		return
+	}
+
+	revoked := h.revokeTokens(c, userID)
+	h.record(c, audit.ActionAccountDeleted, userID, nil, map[string]interface{}{"tokens_revoked": revoked})
+	response.Success(c, http.StatusOK, localize(c, "profile.deleted"), nil)
+}
+
+// checkUserIfMatch 请求携带 If-Match 时校验用户资料的当前 ETag，防止覆盖并发的修改，校验失败时已写入响应
+func checkUserIfMatch(c *gin.Context, userService services.UserService, userID string) bool {
+	if c.GetHeader(response.IfMatchHeader) == "" {
+		return true
+	}
+
+	user, err := userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response.NotFoundError(c, "User", userID)
+			return false
+		}
+		response.InternalServerErrorWithCause(c, localize(c, "profile.get_failed"), err)
+		return false
+	}
+	return response.CheckIfMatch(c, response.ComputeETag(user.ToSafeUser()))
+}
+
+// currentUserID 返回认证中间件写入的用户ID，未认证时写入 401 响应
+func (h *ProfileHandler) currentUserID(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
+		return "", false
+	}
+	return userID.(string), true
+}
+
+// revokeTokens 吊销用户已签发的全部令牌，失败时只记录审计信息，不影响已完成的操作
+func (h *ProfileHandler) revokeTokens(c *gin.Context, userID string) bool {
+	if h.blacklist == nil {
+		return false
+	}
+	if err := h.blacklist.RevokeUserTokens(c.Request.Context(), userID); err != nil {
+		h.record(c, audit.ActionTokensRevoked, userID, err, nil)
+		return false
+	}
+	return true
+}
+
+// respondEmailChangeError 将邮箱变更错误映射为对应的 HTTP 状态码
+func (h *ProfileHandler) respondEmailChangeError(c *gin.Context, userID, newEmail string, err error) {
+	switch {
+	case stderrors.Is(err, services.ErrPasswordIncorrect):
+		response.ValidationError(c, localize(c, "auth.incorrect_password"),
+			errors.ErrorDetails{Field: "password", Message: err.Error()})
+	case stderrors.Is(err, services.ErrEmailUnchanged):
+		response.ValidationError(c, localize(c, "profile.same_email"),
+			errors.ErrorDetails{Field: "new_email", Message: err.Error()})
+	case stderrors.Is(err, services.ErrInvalidEmailChangeToken):
+		response.ValidationError(c, localize(c, "profile.invalid_email_token"),
+			errors.ErrorDetails{Field: "token", Message: err.Error()})
+	case stderrors.Is(err, services.ErrEmailChangeUnavailable):
+		response.ServiceUnavailableError(c, "email", localize(c, "profile.email_change_unavailable"))
+	case err.Error() == "user with this email already exists":
+		response.ConflictError(c, localize(c, "profile.email_taken"), map[string]interface{}{
+			"field": "new_email",
+			"value": newEmail,
+		})
+	case err.Error() == "user not found":
+		response.NotFoundError(c, "User", userID)
+	default:
+		response.InternalServerErrorWithCause(c, localize(c, "profile.change_email_failed"), err)
+	}
+}
+
+// record 记录一次自助操作的审计事件，err 不为 nil 时记为失败
+func (h *ProfileHandler) record(c *gin.Context, action, userID string, err error, details map[string]interface{}) {
+	event := audit.Event{
+		Action:    action,
+		ActorID:   userID,
+		TargetID:  userID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
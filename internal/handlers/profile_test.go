@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/internal/models"
+	"go-server/internal/services"
+	"go-server/internal/validation"
+	"go-server/pkg/response"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditor 记录审计事件，便于断言
+type recordingAuditor struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditor) Record(ctx context.Context, event audit.Event) {
+	r.events = append(r.events, event)
+}
+
+// newProfileContext 构造已认证用户的 JSON 请求上下文
+func newProfileContext(method, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/users/me", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", "1")
+	return c, w
+}
+
+func TestProfileHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	require.NoError(t, validation.Setup())
+
+	t.Run("获取当前用户资料", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewProfileHandler(mockService, nil, nil)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
+
+		c, w := newProfileContext(http.MethodGet, "")
+		handler.GetProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "test@example.com")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("未认证", func(t *testing.T) {
+		handler := NewProfileHandler(new(MockUserService), nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+		handler.GetProfile(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("更新资料只能更新自己", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewProfileHandler(mockService, nil, auditor)
+		mockService.On("Update", "1", mock.AnythingOfType("*models.UpdateUserRequest"), "1").
+			Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("newname").Build(), nil)
+
+		c, w := newProfileContext(http.MethodPut, `{"username":"newname"}`)
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionProfileUpdated, auditor.events[0].Action)
+		assert.Equal(t, audit.OutcomeSuccess, auditor.events[0].Outcome)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("If-Match 与当前资料不一致时拒绝更新", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewProfileHandler(mockService, nil, nil)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
+
+		c, w := newProfileContext(http.MethodPut, `{"username":"newname"}`)
+		c.Request.Header.Set("If-Match", `W/"outdated"`)
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("If-Match 与当前资料一致时更新", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewProfileHandler(mockService, nil, nil)
+		current := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build()
+		updated := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("newname").Build()
+		mockService.On("GetByID", "1").Return(current, nil)
+		mockService.On("Update", "1", mock.AnythingOfType("*models.UpdateUserRequest"), "1").Return(updated, nil)
+
+		c, w := newProfileContext(http.MethodPut, `{"username":"newname"}`)
+		c.Request.Header.Set("If-Match", response.ComputeETag(current.ToSafeUser()))
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, response.ComputeETag(updated.ToSafeUser()), w.Header().Get("ETag"))
+	})
+
+	t.Run("修改密码时当前密码错误", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewProfileHandler(mockService, nil, auditor)
+		mockService.On("ChangePassword", "1", mock.AnythingOfType("*models.ChangePasswordRequest")).
+			Return(errors.New("old password is incorrect"))
+
+		c, w := newProfileContext(http.MethodPost, `{"old_password":"wrong","new_password":"NewPassword123"}`)
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionPasswordChanged, auditor.events[0].Action)
+		assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	})
+
+	t.Run("申请变更邮箱", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewProfileHandler(mockService, nil, auditor)
+		expiresAt := time.Now().Add(services.EmailChangeTTL)
+		mockService.On("RequestEmailChange", "1", &models.ChangeEmailRequest{NewEmail: "new@example.com", Password: "password123"}).
+			Return(expiresAt, nil)
+
+		c, w := newProfileContext(http.MethodPost, `{"new_email":"new@example.com","password":"password123"}`)
+		handler.RequestEmailChange(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), "new@example.com")
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionEmailChangeRequested, auditor.events[0].Action)
+	})
+
+	t.Run("邮箱变更错误映射", func(t *testing.T) {
+		cases := []struct {
+			err  error
+			code int
+		}{
+			{services.ErrPasswordIncorrect, http.StatusBadRequest},
+			{services.ErrEmailUnchanged, http.StatusBadRequest},
+			{errors.New("user with this email already exists"), http.StatusConflict},
+			{services.ErrEmailChangeUnavailable, http.StatusServiceUnavailable},
+		}
+		for _, tc := range cases {
+			mockService := new(MockUserService)
+			handler := NewProfileHandler(mockService, nil, nil)
+			mockService.On("RequestEmailChange", "1", mock.Anything).Return(time.Time{}, tc.err)
+
+			c, w := newProfileContext(http.MethodPost, `{"new_email":"new@example.com","password":"password123"}`)
+			handler.RequestEmailChange(c)
+
+			assert.Equal(t, tc.code, w.Code, tc.err.Error())
+		}
+	})
+
+	t.Run("确认邮箱变更令牌无效", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewProfileHandler(mockService, nil, nil)
+		mockService.On("ConfirmEmailChange", "1", "bad").Return(nil, services.ErrInvalidEmailChangeToken)
+
+		c, w := newProfileContext(http.MethodPost, `{"token":"bad"}`)
+		handler.ConfirmEmailChange(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("注销账户", func(t *testing.T) {
+		mockService := new(MockUserService)
+		auditor := &recordingAuditor{}
+		handler := NewProfileHandler(mockService, nil, auditor)
+		user := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build()
+		mockService.On("GetByID", "1").Return(user, nil)
+		mockService.On("ValidateCredentials", "test@example.com", "password123").Return(user, nil)
+		mockService.On("Delete", "1", "1").Return(nil)
+
+		c, w := newProfileContext(http.MethodDelete, `{"password":"password123"}`)
+		handler.DeleteAccount(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, audit.ActionAccountDeleted, auditor.events[0].Action)
+		assert.Equal(t, audit.OutcomeSuccess, auditor.events[0].Outcome)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("注销账户时密码错误", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := NewProfileHandler(mockService, nil, nil)
+		user := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build()
+		mockService.On("GetByID", "1").Return(user, nil)
+		mockService.On("ValidateCredentials", "test@example.com", "wrong").Return(nil, errors.New("invalid credentials"))
+
+		c, w := newProfileContext(http.MethodDelete, `{"password":"wrong"}`)
+		handler.DeleteAccount(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Delete", "1", "1")
+	})
+}
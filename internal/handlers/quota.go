@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-server/internal/middleware"
+	"go-server/pkg/quota"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaHandler 处理用量配额查询接口（/api/v1/users/me/quota）
+type QuotaHandler struct {
+	manager *quota.Manager
+}
+
+// NewQuotaHandler 创建用量配额处理器，manager 为 nil 时接口返回 503
+func NewQuotaHandler(manager *quota.Manager) *QuotaHandler {
+	return &QuotaHandler{
+		manager: manager,
+	}
+}
+
+// GetMyQuota godoc
+// @Summary 获取我的用量配额
+// @Description 返回当前用户本月（UTC）各项配额的用量、软限制和硬限制，用量在每月 1 日零点重置。查询本身不消耗 api_calls 配额
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=[]quota.Usage} "成功获取用量配额"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "用量配额未启用"
+// @Router /api/v1/users/me/quota [get]
+func (h *QuotaHandler) GetMyQuota(c *gin.Context) {
+	if h.manager == nil {
+		response.ServiceUnavailableError(c, "quota", localize(c, "quota.disabled"))
+		return
+	}
+
+	usages, err := h.manager.UsageAll(c.Request.Context(), middleware.QuotaSubject(c.GetString("user_id")))
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "quota.get_failed"), err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "quota.retrieved"), usages)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"go-server/internal/audit"
+	"go-server/internal/middleware"
+	"go-server/internal/models"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitHandler 处理运行时速率限制管理接口（/api/v1/admin/rate-limits）
+type RateLimitHandler struct {
+	limiter *middleware.DistributedRateLimiter
+	audit   audit.Recorder
+}
+
+// NewRateLimitHandler 创建速率限制管理处理器，limiter 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewRateLimitHandler(limiter *middleware.DistributedRateLimiter, recorder audit.Recorder) *RateLimitHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &RateLimitHandler{
+		limiter: limiter,
+		audit:   recorder,
+	}
+}
+
+// GetRateLimits godoc
+// @Summary 获取速率限制
+// @Description 返回当前生效的速率限制和管理员设置的覆盖（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=middleware.RateLimitState} "成功获取速率限制"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "速率限制未初始化"
+// @Router /api/v1/admin/rate-limits [get]
+func (h *RateLimitHandler) GetRateLimits(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "rate_limit.retrieved"), h.limiter.State())
+}
+
+// UpdateRateLimits godoc
+// @Summary 修改速率限制
+// @Description 替换管理员设置的速率限制覆盖（仅管理员），覆盖优先于配置文件，字段为 0 或空时沿用配置文件中的值，提交空对象恢复配置文件中的限制。
+// @Description 设置了路由限制的路由单独计数，不占用全局限制。缓存是 Redis 时覆盖保存在 Redis 中，重启后仍然有效，并通过发布订阅同步到所有实例
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateRateLimitsRequest true "速率限制覆盖"
+// @Success 200 {object} models.SuccessResponse{data=middleware.RateLimitState} "速率限制已修改"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "请求参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "速率限制未初始化"
+// @Router /api/v1/admin/rate-limits [put]
+func (h *RateLimitHandler) UpdateRateLimits(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.UpdateRateLimitsRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	overrides := middleware.RateLimitOverrides{
+		RateLimitTier: middleware.RateLimitTier{
+			Anonymous:     req.Anonymous,
+			Authenticated: req.Authenticated,
+		},
+		Window:    req.Window,
+		Mode:      req.Mode,
+		UpdatedBy: c.GetString("user_id"),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if len(req.Routes) > 0 {
+		overrides.Routes = make(map[string]middleware.RateLimitTier, len(req.Routes))
+		for route, limit := range req.Routes {
+			overrides.Routes[route] = middleware.RateLimitTier{
+				Anonymous:     limit.Anonymous,
+				Authenticated: limit.Authenticated,
+			}
+		}
+	}
+
+	err := h.limiter.UpdateOverrides(c.Request.Context(), overrides)
+	h.record(c, &overrides, err)
+	if err != nil {
+		if stderrors.Is(err, middleware.ErrInvalidRateLimitOverrides) {
+			response.ValidationError(c, localize(c, "rate_limit.invalid"), errors.ErrorDetails{
+				Field:   "window",
+				Message: err.Error(),
+				Value:   req.Window,
+			})
+			return
+		}
+		response.InternalServerErrorWithCause(c, localize(c, "rate_limit.save_failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "rate_limit.updated"), h.limiter.State())
+}
+
+// available 速率限制器未初始化时返回 503
+func (h *RateLimitHandler) available(c *gin.Context) bool {
+	if h.limiter == nil {
+		response.ServiceUnavailableError(c, "rate_limit", localize(c, "rate_limit.unavailable"))
+		return false
+	}
+	return true
+}
+
+// record 记录速率限制修改的审计事件
+func (h *RateLimitHandler) record(c *gin.Context, overrides *middleware.RateLimitOverrides, err error) {
+	event := audit.Event{
+		Action:    audit.ActionRateLimitsUpdated,
+		ActorID:   c.GetString("user_id"),
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details: map[string]interface{}{
+			"anonymous":     overrides.Anonymous,
+			"authenticated": overrides.Authenticated,
+			"window":        overrides.Window,
+			"mode":          overrides.Mode,
+			"routes":        len(overrides.Routes),
+		},
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
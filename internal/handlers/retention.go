@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"go-server/internal/retention"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler 处理数据保留策略的接口（/api/v1/admin/retention）
+type RetentionHandler struct {
+	engine *retention.Engine
+}
+
+// NewRetentionHandler 创建数据保留策略处理器，engine 为 nil 时接口返回 503
+// 清理结果由 engine 按表记录审计事件
+func NewRetentionHandler(engine *retention.Engine) *RetentionHandler {
+	return &RetentionHandler{engine: engine}
+}
+
+// GetRetention godoc
+// @Summary 获取数据保留策略
+// @Description 返回各表的保留策略、定期清理的间隔和本实例最近一次清理的报告（仅管理员）
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse{data=retention.Status} "成功获取数据保留策略"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "数据保留策略未启用"
+// @Router /api/v1/admin/retention [get]
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	response.Success(c, http.StatusOK, localize(c, "retention.retrieved"), h.engine.Status())
+}
+
+// RunRetention godoc
+// @Summary 执行数据保留策略
+// @Description 立即按所有保留策略清理一次过期数据并返回报告（仅管理员）。默认为试运行，只统计各表可删除的行数；
+// @Description dry_run=false 时分批删除数据，每张表删除的行数记录一条审计事件。客户端断开不会中断清理
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "是否只统计可删除的行数" default(true)
+// @Success 200 {object} models.SuccessResponse{data=retention.Report} "清理完成"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "参数错误"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "清理正在执行"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "数据保留策略未启用"
+// @Router /api/v1/admin/retention/run [post]
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	dryRun := true
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.ValidationError(c, localize(c, "validation.invalid_dry_run"),
+				errors.ErrorDetails{Field: "dry_run", Message: localize(c, "validation.dry_run_detail"), Value: value})
+			return
+		}
+		dryRun = parsed
+	}
+
+	// 删除分批提交，客户端断开时继续清理，避免只清理了部分表
+	report, err := h.engine.Run(context.WithoutCancel(c.Request.Context()), retention.RunOptions{
+		DryRun:  dryRun,
+		ActorID: c.GetString("user_id"),
+	})
+	if stderrors.Is(err, retention.ErrRunning) {
+		response.ConflictError(c, localize(c, "retention.in_progress"), nil)
+		return
+	}
+	if err != nil {
+		response.InternalServerErrorWithCause(c, localize(c, "retention.failed"), err)
+		return
+	}
+	response.Success(c, http.StatusOK, localize(c, "retention.completed"), report)
+}
+
+// available 数据保留策略未启用时返回 503
+func (h *RetentionHandler) available(c *gin.Context) bool {
+	if h.engine == nil {
+		response.ServiceUnavailableError(c, "retention", localize(c, "retention.disabled"))
+		return false
+	}
+	return true
+}
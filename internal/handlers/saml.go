@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"go-server/internal/audit"
+	"go-server/internal/sso"
+	"go-server/internal/tenancy"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+	"go-server/pkg/saml"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SAMLHandler 处理 SAML 2.0 单点登录接口（/api/v1/auth/saml/{tenant}）
+type SAMLHandler struct {
+	service *sso.Service
+	audit   audit.Recorder
+}
+
+// NewSAMLHandler 创建 SAML 单点登录处理器，service 为 nil 时接口返回 503，recorder 为 nil 时不记录审计事件
+func NewSAMLHandler(service *sso.Service, recorder audit.Recorder) *SAMLHandler {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &SAMLHandler{
+		service: service,
+		audit:   recorder,
+	}
+}
+
+// Metadata godoc
+// @Summary SAML 服务提供方元数据
+// @Description 返回租户的 SP 元数据（实体ID、断言消费服务地址和支持的名称标识格式），身份提供方管理员导入后即可完成对接。要求身份提供方签名断言
+// @Tags auth
+// @Produce xml,json
+// @Param tenant path string true "租户标识"
+// @Success 200 {string} string "SP 元数据"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "租户没有配置身份提供方"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "SAML 单点登录未启用"
+// @Router /api/v1/auth/saml/{tenant}/metadata [get]
+func (h *SAMLHandler) Metadata(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	tenant := c.Param("tenant")
+	metadata, err := h.service.Metadata(tenant)
+	if err != nil {
+		h.loginError(c, tenant, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", metadata)
+}
+
+// Login godoc
+// @Summary 开始 SAML 单点登录
+// @Description 浏览器访问此地址开始登录，被重定向到租户的身份提供方（HTTP-Redirect 绑定）。认证请求在 saml.request_ttl 内有效，只能使用一次
+// @Tags auth
+// @Produce json
+// @Param tenant path string true "租户标识"
+// @Success 302 "重定向到身份提供方"
+// @Header 302 {string} Location "身份提供方的登录地址"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "租户已停用"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "租户不存在或没有配置身份提供方"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "SAML 单点登录未启用"
+// @Router /api/v1/auth/saml/{tenant}/login [get]
+func (h *SAMLHandler) Login(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	tenant := c.Param("tenant")
+	loginURL, err := h.service.LoginURL(c.Request.Context(), tenant)
+	if err != nil {
+		h.loginError(c, tenant, err)
+		return
+	}
+	redirect(c, http.StatusFound, loginURL)
+}
+
+// ACS godoc
+// @Summary SAML 断言消费服务
+// @Description 身份提供方通过浏览器以 HTTP-POST 绑定提交 SAMLResponse。验证签名、签发者、受众、有效期和对应的认证请求后，按邮箱找到用户（启用自动创建时创建用户）并同步姓名，签发访问令牌，重定向到 saml.success_url，令牌在 URL 片段 #token= 中
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param tenant path string true "租户标识"
+// @Param SAMLResponse formData string true "base64 编码的 SAML Response"
+// @Param RelayState formData string false "中继状态，忽略"
+// @Success 303 "登录成功，重定向到登录完成页面"
+// @Header 303 {string} Location "带有访问令牌的登录完成页面地址"
+// @Failure 400 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "缺少 SAMLResponse"
+// @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "响应无效、身份提供方拒绝登录或认证请求已过期"
+// @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "租户或用户已停用、用户不存在且未启用自动创建或用户属于其他租户"
+// @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "租户不存在或没有配置身份提供方"
+// @Failure 409 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "自动创建用户时用户名已被占用"
+// @Failure 413 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "SAMLResponse 过大"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "SAML 单点登录未启用"
+// @Router /api/v1/auth/saml/{tenant}/acs [post]
+func (h *SAMLHandler) ACS(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	tenant := c.Param("tenant")
+	samlResponse := c.PostForm("SAMLResponse")
+	if samlResponse == "" {
+		response.ValidationError(c, localize(c, "saml.missing_response"), errors.ErrorDetails{Field: "SAMLResponse", Message: localize(c, "saml.response_required")})
+		return
+	}
+	// base64 编码后约为原文的 4/3
+	if len(samlResponse) > saml.MaxResponseSize*4/3+4 {
+		response.PayloadTooLargeError(c, saml.MaxResponseSize*4/3)
+		return
+	}
+
+	result, err := h.service.Login(c.Request.Context(), tenant, samlResponse)
+	if err != nil {
+		h.record(c, tenant, "", err, nil)
+		h.loginError(c, tenant, err)
+		return
+	}
+	h.record(c, tenant, result.User.ID, nil, map[string]interface{}{"created": result.Created})
+	redirect(c, http.StatusSeeOther, result.RedirectURL)
+}
+
+// redirect 返回不带响应体的重定向，与文档一致（gin 的 Redirect 对 GET 请求会写入 HTML 响应体）
+func redirect(c *gin.Context, code int, location string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Location", location)
+	c.Status(code)
+}
+
+// available SAML 单点登录未启用时返回 503
+func (h *SAMLHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		response.ServiceUnavailableError(c, "saml", localize(c, "saml.disabled"))
+		return false
+	}
+	return true
+}
+
+// loginError 将单点登录的错误转换为响应
+func (h *SAMLHandler) loginError(c *gin.Context, tenant string, err error) {
+	var statusErr *saml.StatusError
+	switch {
+	case stderrors.Is(err, sso.ErrProviderNotFound), stderrors.Is(err, tenancy.ErrTenantNotFound):
+		response.NotFoundError(c, "saml provider", tenant)
+	case stderrors.Is(err, saml.ErrInvalidResponse), stderrors.Is(err, sso.ErrUnknownRequest):
+		response.UnauthorizedError(c, localize(c, "saml.invalid_response"))
+	case stderrors.As(err, &statusErr):
+		response.UnauthorizedError(c, localize(c, "saml.denied"))
+	case stderrors.Is(err, sso.ErrTenantInactive):
+		response.ForbiddenError(c, localize(c, "tenant.inactive"))
+	case stderrors.Is(err, sso.ErrUserInactive):
+		response.ForbiddenError(c, localize(c, "auth.account_inactive"))
+	case stderrors.Is(err, sso.ErrUserNotProvisioned):
+		response.ForbiddenError(c, localize(c, "saml.user_not_provisioned"))
+	case stderrors.Is(err, sso.ErrTenantMismatch):
+		response.ForbiddenError(c, localize(c, "saml.tenant_mismatch"))
+	case stderrors.Is(err, sso.ErrUsernameConflict):
+		response.ConflictError(c, localize(c, "auth.username_taken"), map[string]interface{}{"tenant": tenant})
+	default:
+		response.InternalServerErrorWithCause(c, localize(c, "saml.login_failed"), err)
+	}
+}
+
+// record 记录单点登录的审计事件，失败原因包含验证失败的具体原因
+func (h *SAMLHandler) record(c *gin.Context, tenant, userID string, err error, details map[string]interface{}) {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["tenant"] = tenant
+	event := audit.Event{
+		Action:    audit.ActionSSOLogin,
+		ActorID:   userID,
+		TargetID:  userID,
+		Outcome:   audit.OutcomeSuccess,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details:   details,
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	h.audit.Record(c.Request.Context(), event)
+}
@@ -7,6 +7,7 @@ import (
 
 	"go-server/internal/models"
 	"go-server/internal/services"
+	"go-server/internal/validation"
 	"go-server/pkg/errors"
 	"go-server/pkg/response"
 
@@ -29,9 +30,8 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 // @Tags users
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "页码" default(1)
-// @Param limit query int false "每页项目数量" default(10)
-// @Success 200 {object} models.SuccessResponse{data=models.PaginatedResponse} "成功获取用户"
+// @Paginate
+// @PaginatedSuccess models.SafeUser "成功获取用户"
 // @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要身份验证"
 // @Failure 403 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "需要管理员权限"
 // @Header 200 {string} X-Cache "缓存状态 (HIT, MISS, BYPASS, STALE)"
@@ -45,18 +45,18 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	// 检查用户是否为管理员
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		response.UnauthorizedError(c, "用户未身份验证")
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
 		return
 	}
 
-	currentUser, err := h.userService.GetByID(currentUserID.(string))
+	currentUser, err := h.userService.GetByID(c.Request.Context(), currentUserID.(string))
 	if err != nil {
-		response.UnauthorizedError(c, "用户未找到")
+		response.UnauthorizedError(c, localize(c, "user.not_found"))
 		return
 	}
 
 	if !currentUser.IsAdmin {
-		response.ForbiddenError(c, "需要管理员权限")
+		response.ForbiddenError(c, localize(c, "auth.admin_required"))
 		return
 	}
 
@@ -65,20 +65,20 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
 	if page < 1 {
-		response.ValidationError(c, "页码必须大于0",
-			errors.ErrorDetails{Field: "page", Message: "页码必须大于0", Value: page})
+		response.ValidationError(c, localize(c, "pagination.invalid_page"),
+			errors.ErrorDetails{Field: "page", Message: localize(c, "pagination.invalid_page"), Value: page})
 		return
 	}
 	if limit < 1 || limit > 100 {
-		response.ValidationError(c, "每页数量必须在1到100之间",
-			errors.ErrorDetails{Field: "limit", Message: "每页数量必须在1到100之间", Value: limit})
+		response.ValidationError(c, localize(c, "pagination.invalid_limit"),
+			errors.ErrorDetails{Field: "limit", Message: localize(c, "pagination.invalid_limit"), Value: limit})
 		return
 	}
 
 	// 从数据库获取用户
-	users, total, err := h.userService.GetAll(page, limit)
+	users, total, err := h.userService.GetAll(c.Request.Context(), page, limit)
 	if err != nil {
-		response.DatabaseError(c, "获取用户失败", err)
+		response.DatabaseError(c, localize(c, "user.get_failed"), err)
 		return
 	}
 
@@ -97,7 +97,7 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		TotalPages: totalPages,
 	}
 
-	response.Success(c, http.StatusOK, "成功获取用户", models.PaginatedResponse{
+	response.Success(c, http.StatusOK, localize(c, "user.retrieved"), models.PaginatedResponse{
 		Data:       safeUsers,
 		Pagination: pagination,
 	})
@@ -113,89 +113,102 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 // @Success 200 {object} models.SuccessResponse{data=models.SafeUser} "User retrieved successfully"
 // @Failure 401 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Authentication required"
 // @Failure 404 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "User not found"
+// @Failure 503 {object} models.ErrorResponse{error=models.EnhancedErrorResponse} "Transient database error; retry after the Retry-After header"
 // @Header 200 {string} X-Cache "Cache status (HIT, MISS, BYPASS, STALE)"
 // @Header 200 {integer} X-Cache-TTL "Time to live in seconds for cached data"
 // @Header 200 {string} X-Cache-Backend "Cache backend used (redis, database)"
 // @Header 200 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 401 {string} X-Correlation-ID "Unique identifier for request tracing"
 // @Header 404 {string} X-Correlation-ID "Unique identifier for request tracing"
+// @Header 503 {integer} Retry-After "Seconds to wait before retrying"
 // @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		response.ValidationError(c, "User ID is required",
-			errors.ErrorDetails{Field: "id", Message: "User ID is required"})
+		response.ValidationError(c, localize(c, "user.id_required"),
+			errors.ErrorDetails{Field: "id", Message: localize(c, "user.id_required")})
 		return
 	}
 
 	// Get user from database
-	user, err := h.userService.GetByID(userID)
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
 	if err != nil {
+		if _, transient := errors.RetryAfterHint(err); transient {
+			response.DatabaseError(c, localize(c, "user.get_one_failed"), err)
+			return
+		}
 		response.NotFoundError(c, "User", userID)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User retrieved successfully", user.ToSafeUser())
+	response.Success(c, http.StatusOK, localize(c, "user.retrieved_one"), user.ToSafeUser())
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update a user's information
+// @Description Update a user's information. When If-Match is sent the update only applies if the user has not changed since it was fetched.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag returned when the user was fetched"
 // @Param user body models.UpdateUserRequest true "User information"
 // @Success 200 {object} models.SuccessResponse{data=models.SafeUser}
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		response.ValidationError(c, "User ID is required",
-			errors.ErrorDetails{Field: "id", Message: "User ID is required"})
+		response.ValidationError(c, localize(c, "user.id_required"),
+			errors.ErrorDetails{Field: "id", Message: localize(c, "user.id_required")})
 		return
 	}
 
 	// Check if user is authenticated
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		response.UnauthorizedError(c, "User not authenticated")
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
 		return
 	}
 
 	var req models.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request format: "+err.Error())
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	if !checkUserIfMatch(c, h.userService, userID) {
 		return
 	}
 
 	// Update user using user service
-	user, err := h.userService.Update(userID, &req, currentUserID.(string))
+	user, err := h.userService.Update(c.Request.Context(), userID, &req, currentUserID.(string))
 	if err != nil {
 		if err.Error() == "user not found" {
 			response.NotFoundError(c, "User", userID)
 			return
 		}
 		if err.Error() == "you can only update your own profile" || err.Error() == "unauthorized" {
-			response.ForbiddenError(c, "You can only update your own profile")
+			response.ForbiddenError(c, localize(c, "user.update_forbidden"))
 			return
 		}
 		if err.Error() == "username already taken" {
-			response.ConflictError(c, "Username already taken", map[string]interface{}{
+			response.ConflictError(c, localize(c, "auth.username_taken"), map[string]interface{}{
 				"field": "username",
 				"value": req.Username,
 			})
 			return
 		}
-		response.InternalServerErrorWithCause(c, "Failed to update user", err)
+		response.InternalServerErrorWithCause(c, localize(c, "user.update_failed"), err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User updated successfully", user.ToSafeUser())
+	safeUser := user.ToSafeUser()
+	response.SetETag(c, response.ComputeETag(safeUser))
+	response.Success(c, http.StatusOK, localize(c, "user.updated"), safeUser)
 }
 
 // DeleteUser godoc
@@ -213,33 +226,33 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		response.ValidationError(c, "User ID is required",
-			errors.ErrorDetails{Field: "id", Message: "User ID is required"})
+		response.ValidationError(c, localize(c, "user.id_required"),
+			errors.ErrorDetails{Field: "id", Message: localize(c, "user.id_required")})
 		return
 	}
 
 	// Check if user is authenticated
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		response.UnauthorizedError(c, "User not authenticated")
+		response.UnauthorizedError(c, localize(c, "auth.unauthenticated"))
 		return
 	}
 
 	// Delete user using user service
-	if err := h.userService.Delete(userID, currentUserID.(string)); err != nil {
+	if err := h.userService.Delete(c.Request.Context(), userID, currentUserID.(string)); err != nil {
 		if err.Error() == "user not found" {
 			response.NotFoundError(c, "User", userID)
 			return
 		}
 		if err.Error() == "you can only delete your own account" || err.Error() == "unauthorized" {
-			response.ForbiddenError(c, "You can only delete your own account")
+			response.ForbiddenError(c, localize(c, "user.delete_forbidden"))
 			return
 		}
-		response.InternalServerErrorWithCause(c, "Failed to delete user", err)
+		response.InternalServerErrorWithCause(c, localize(c, "user.delete_failed"), err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User deleted successfully", gin.H{
+	response.Success(c, http.StatusOK, localize(c, "user.deleted"), gin.H{
 		"user_id": userID,
 	})
 }
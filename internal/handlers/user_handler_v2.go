@@ -55,7 +55,7 @@ func NewUserHandlerV2(userService services.UserServiceV2) *UserHandlerV2 {
 func (h *UserHandlerV2) Register(c *gin.Context) {
 	var dto validation.RegisterUserDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		h.errorHandler.HandleError(c, errors.NewValidationError("invalid request format: "+err.Error()))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "validation.invalid_format")+": "+err.Error()))
 		return
 	}
 
@@ -74,7 +74,7 @@ func (h *UserHandlerV2) Register(c *gin.Context) {
 
 	// 转换为安全用户响应
 	safeUser := h.domainUserToSafeUser(domainUser)
-	response.Success(c, http.StatusCreated, "User registered successfully", safeUser)
+	response.Success(c, http.StatusCreated, localize(c, "auth.registered"), safeUser)
 }
 
 // Login godoc
@@ -92,7 +92,7 @@ func (h *UserHandlerV2) Register(c *gin.Context) {
 func (h *UserHandlerV2) Login(c *gin.Context) {
 	var dto validation.LoginUserDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		h.errorHandler.HandleError(c, errors.NewValidationError("invalid request format: "+err.Error()))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "validation.invalid_format")+": "+err.Error()))
 		return
 	}
 
@@ -112,7 +112,7 @@ func (h *UserHandlerV2) Login(c *gin.Context) {
 	// 生成JWT令牌
 	token, err := h.generateToken(domainUser)
 	if err != nil {
-		h.errorHandler.HandleError(c, errors.NewInternalError("failed to generate token", err))
+		h.errorHandler.HandleError(c, errors.NewInternalError(localize(c, "auth.generate_token_failed"), err))
 		return
 	}
 
@@ -123,7 +123,7 @@ func (h *UserHandlerV2) Login(c *gin.Context) {
 		User:  safeUser,
 	}
 
-	response.Success(c, http.StatusOK, "Login successful", loginResponse)
+	response.Success(c, http.StatusOK, localize(c, "auth.login_succeeded"), loginResponse)
 }
 
 // GetUsers godoc
@@ -132,9 +132,8 @@ func (h *UserHandlerV2) Login(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "页码" default(1)
-// @Param limit query int false "每页项目数量" default(10)
-// @Success 200 {object} models.SuccessResponse{data=models.PaginatedResponse}
+// @Paginate
+// @PaginatedSuccess models.SafeUser
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Router /api/v1/users [get]
@@ -142,18 +141,18 @@ func (h *UserHandlerV2) GetUsers(c *gin.Context) {
 	// 检查用户权限
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		h.errorHandler.HandleError(c, errors.NewUnauthorizedError("user not authenticated"))
+		h.errorHandler.HandleError(c, errors.NewUnauthorizedError(localize(c, "auth.unauthenticated")))
 		return
 	}
 
 	currentUser, err := h.userService.GetByID(c.Request.Context(), currentUserID.(string))
 	if err != nil {
-		h.errorHandler.HandleError(c, errors.NewUnauthorizedError("user not found"))
+		h.errorHandler.HandleError(c, errors.NewUnauthorizedError(localize(c, "user.not_found")))
 		return
 	}
 
 	if !currentUser.IsAdmin() {
-		h.errorHandler.HandleError(c, errors.NewForbiddenError("admin privileges required"))
+		h.errorHandler.HandleError(c, errors.NewForbiddenError(localize(c, "auth.admin_required")))
 		return
 	}
 
@@ -190,7 +189,7 @@ func (h *UserHandlerV2) GetUsers(c *gin.Context) {
 		TotalPages: totalPages,
 	}
 
-	response.Success(c, http.StatusOK, "Users retrieved successfully", models.PaginatedResponse{
+	response.Success(c, http.StatusOK, localize(c, "user.retrieved"), models.PaginatedResponse{
 		Data:       safeUsers,
 		Pagination: pagination,
 	})
@@ -211,7 +210,7 @@ func (h *UserHandlerV2) GetUsers(c *gin.Context) {
 func (h *UserHandlerV2) GetUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		h.errorHandler.HandleError(c, errors.NewValidationError("user ID is required"))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "user.id_required")))
 		return
 	}
 
@@ -224,7 +223,7 @@ func (h *UserHandlerV2) GetUser(c *gin.Context) {
 
 	// 转换为安全用户响应
 	safeUser := h.domainUserToSafeUser(domainUser)
-	response.Success(c, http.StatusOK, "User retrieved successfully", safeUser)
+	response.Success(c, http.StatusOK, localize(c, "user.retrieved_one"), safeUser)
 }
 
 // UpdateUser godoc
@@ -246,20 +245,20 @@ func (h *UserHandlerV2) GetUser(c *gin.Context) {
 func (h *UserHandlerV2) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		h.errorHandler.HandleError(c, errors.NewValidationError("user ID is required"))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "user.id_required")))
 		return
 	}
 
 	// 获取当前用户ID
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		h.errorHandler.HandleError(c, errors.NewUnauthorizedError("user not authenticated"))
+		h.errorHandler.HandleError(c, errors.NewUnauthorizedError(localize(c, "auth.unauthenticated")))
 		return
 	}
 
 	var dto validation.UpdateUserDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		h.errorHandler.HandleError(c, errors.NewValidationError("invalid request format: "+err.Error()))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "validation.invalid_format")+": "+err.Error()))
 		return
 	}
 
@@ -278,7 +277,7 @@ func (h *UserHandlerV2) UpdateUser(c *gin.Context) {
 
 	// 转换为安全用户响应
 	safeUser := h.domainUserToSafeUser(domainUser)
-	response.Success(c, http.StatusOK, "User updated successfully", safeUser)
+	response.Success(c, http.StatusOK, localize(c, "user.updated"), safeUser)
 }
 
 // DeleteUser godoc
@@ -297,14 +296,14 @@ func (h *UserHandlerV2) UpdateUser(c *gin.Context) {
 func (h *UserHandlerV2) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		h.errorHandler.HandleError(c, errors.NewValidationError("user ID is required"))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "user.id_required")))
 		return
 	}
 
 	// 获取当前用户ID
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		h.errorHandler.HandleError(c, errors.NewUnauthorizedError("user not authenticated"))
+		h.errorHandler.HandleError(c, errors.NewUnauthorizedError(localize(c, "auth.unauthenticated")))
 		return
 	}
 
@@ -314,7 +313,7 @@ func (h *UserHandlerV2) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User deleted successfully", gin.H{
+	response.Success(c, http.StatusOK, localize(c, "user.deleted"), gin.H{
 		"user_id": userID,
 	})
 }
@@ -336,26 +335,26 @@ func (h *UserHandlerV2) DeleteUser(c *gin.Context) {
 func (h *UserHandlerV2) ChangePassword(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		h.errorHandler.HandleError(c, errors.NewValidationError("user ID is required"))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "user.id_required")))
 		return
 	}
 
 	// 获取当前用户ID
 	currentUserID, exists := c.Get("user_id")
 	if !exists {
-		h.errorHandler.HandleError(c, errors.NewUnauthorizedError("user not authenticated"))
+		h.errorHandler.HandleError(c, errors.NewUnauthorizedError(localize(c, "auth.unauthenticated")))
 		return
 	}
 
 	// 检查权限（只能修改自己的密码）
 	if userID != currentUserID.(string) {
-		h.errorHandler.HandleError(c, errors.NewForbiddenError("can only change your own password"))
+		h.errorHandler.HandleError(c, errors.NewForbiddenError(localize(c, "user.change_password_forbidden")))
 		return
 	}
 
 	var dto validation.ChangePasswordDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		h.errorHandler.HandleError(c, errors.NewValidationError("invalid request format: "+err.Error()))
+		h.errorHandler.HandleError(c, errors.NewValidationError(localize(c, "validation.invalid_format")+": "+err.Error()))
 		return
 	}
 
@@ -371,7 +370,7 @@ func (h *UserHandlerV2) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Password changed successfully", nil)
+	response.Success(c, http.StatusOK, localize(c, "auth.password_changed"), nil)
 }
 
 // Helper methods
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/testutil/factory"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +21,7 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) Register(req *models.RegisterRequest) (*models.User, error) {
+func (m *MockUserService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -25,7 +29,7 @@ func (m *MockUserService) Register(req *models.RegisterRequest) (*models.User, e
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) Login(req *models.LoginRequest) (*models.User, error) {
+func (m *MockUserService) Login(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,7 +37,7 @@ func (m *MockUserService) Login(req *models.LoginRequest) (*models.User, error)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) GetByID(id string) (*models.User, error) {
+func (m *MockUserService) GetByID(ctx context.Context, id string) (*models.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -41,7 +45,7 @@ func (m *MockUserService) GetByID(id string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) GetByEmail(email string) (*models.User, error) {
+func (m *MockUserService) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -49,7 +53,7 @@ func (m *MockUserService) GetByEmail(email string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) GetAll(page, limit int) ([]*models.User, int64, error) {
+func (m *MockUserService) GetAll(ctx context.Context, page, limit int) ([]*models.User, int64, error) {
 	args := m.Called(page, limit)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
@@ -57,7 +61,7 @@ func (m *MockUserService) GetAll(page, limit int) ([]*models.User, int64, error)
 	return args.Get(0).([]*models.User), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockUserService) Update(id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error) {
+func (m *MockUserService) Update(ctx context.Context, id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error) {
 	args := m.Called(id, req, requesterID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -65,22 +69,30 @@ func (m *MockUserService) Update(id string, req *models.UpdateUserRequest, reque
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) Delete(id string, requesterID string) error {
+func (m *MockUserService) Delete(ctx context.Context, id string, requesterID string) error {
 	args := m.Called(id, requesterID)
 	return args.Error(0)
 }
 
-func (m *MockUserService) ChangePassword(id string, req *models.ChangePasswordRequest) error {
+func (m *MockUserService) ChangePassword(ctx context.Context, id string, req *models.ChangePasswordRequest) error {
 	args := m.Called(id, req)
 	return args.Error(0)
 }
 
-func (m *MockUserService) UpdateLastLogin(id string) error {
+func (m *MockUserService) UpdateLastLogin(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserService) ValidateCredentials(email, password string) (*models.User, error) {
+func (m *MockUserService) UpdateAvatar(ctx context.Context, id, avatarKey, avatarURL string) (*models.User, string, error) {
+	args := m.Called(id, avatarKey, avatarURL)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*models.User), args.String(1), args.Error(2)
+}
+
+func (m *MockUserService) ValidateCredentials(ctx context.Context, email, password string) (*models.User, error) {
 	args := m.Called(email, password)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -88,17 +100,58 @@ func (m *MockUserService) ValidateCredentials(email, password string) (*models.U
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-// 创建测试用户
-func createTestUser(id, email, username string) *models.User {
-	return &models.User{
-		ID:        id,
-		Username:  username,
-		Email:     email,
-		FirstName: "Test",
-		LastName:  "User",
-		IsActive:  true,
-		IsAdmin:   false,
+func (m *MockUserService) RequestEmailChange(ctx context.Context, id string, req *models.ChangeEmailRequest) (time.Time, error) {
+	args := m.Called(id, req)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockUserService) ConfirmEmailChange(ctx context.Context, id, token string) (*models.User, error) {
+	args := m.Called(id, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) ListUsers(ctx context.Context, filter repositories.UserFilter, page, limit int) ([]*models.User, int64, error) {
+	args := m.Called(filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
 	}
+	return args.Get(0).([]*models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserService) StreamUsers(ctx context.Context, filter repositories.UserFilter, fn func(user *models.User) error) error {
+	args := m.Called(filter)
+	if users, ok := args.Get(0).([]*models.User); ok {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserService) SetActive(ctx context.Context, id string, active bool, requesterID string) (*models.User, error) {
+	args := m.Called(id, active, requesterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, id string) (string, error) {
+	args := m.Called(id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) AssignRoles(ctx context.Context, id string, roles []string, requesterID string) (*models.User, error) {
+	args := m.Called(id, roles, requesterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
 }
 
 func TestNewUserHandler(t *testing.T) {
@@ -129,12 +182,12 @@ func TestUserHandler_GetUsers(t *testing.T) {
 
 		// 准备模拟数据
 		users := []*models.User{
-			createTestUser("1", "user1@example.com", "user1"),
-			createTestUser("2", "user2@example.com", "user2"),
+			factory.User().WithID("1").WithEmail("user1@example.com").WithUsername("user1").Build(),
+			factory.User().WithID("2").WithEmail("user2@example.com").WithUsername("user2").Build(),
 		}
 
 		// Mock admin user check
-		adminUser := createTestUser("admin-id", "admin@example.com", "admin")
+		adminUser := factory.User().WithID("admin-id").WithEmail("admin@example.com").WithUsername("admin").Build()
 		adminUser.IsAdmin = true
 		mockService.On("GetByID", "admin-id").Return(adminUser, nil)
 		mockService.On("GetAll", 1, 10).Return(users, int64(2), nil)
@@ -159,7 +212,7 @@ func TestUserHandler_GetUsers(t *testing.T) {
 		c.Set("user_id", "user-id")
 
 		// Mock regular user (not admin)
-		regularUser := createTestUser("user-id", "user@example.com", "user")
+		regularUser := factory.User().WithID("user-id").WithEmail("user@example.com").WithUsername("user").Build()
 		regularUser.IsAdmin = false
 		mockService.On("GetByID", "user-id").Return(regularUser, nil)
 
@@ -193,7 +246,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 		mockService := new(MockUserService)
 		handler := NewUserHandler(mockService)
 
-		user := createTestUser("1", "test@example.com", "testuser")
+		user := factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build()
 
 		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
 		w := httptest.NewRecorder()
@@ -257,8 +310,8 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
 		c.Set("update_data", updateData)
 
-		mockService.On("GetByID", "1").Return(createTestUser("1", "test@example.com", "testuser"), nil)
-		mockService.On("Update", "1", updateData, "1").Return(createTestUser("1", "test@example.com", "testuser"), nil)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
+		mockService.On("Update", "1", updateData, "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
 
 		handler.UpdateUser(c)
 
@@ -301,7 +354,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		c.Set("is_admin", true)
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
 
-		mockService.On("GetByID", "1").Return(createTestUser("1", "test@example.com", "testuser"), nil)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
 		mockService.On("Delete", "1", "admin-id").Return(nil)
 
 		handler.DeleteUser(c)
@@ -324,7 +377,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		c.Set("is_admin", false)
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
 
-		mockService.On("GetByID", "1").Return(createTestUser("1", "test@example.com", "testuser"), nil)
+		mockService.On("GetByID", "1").Return(factory.User().WithID("1").WithEmail("test@example.com").WithUsername("testuser").Build(), nil)
 		mockService.On("Delete", "1", "1").Return(nil)
 
 		handler.DeleteUser(c)
@@ -0,0 +1,264 @@
+// Package imports 批量导入用户
+//
+// 导入文件（CSV 或 JSON）在请求中同步处理：先逐行按注册请求的校验规则检查字段，检查邮箱和用户名是否与文件中之前的行
+// 或现有用户重复，这一阶段只读；再将有效的行分批写入数据库，每批一个事务，某一批失败时该批的行均不导入，其他批不受影响。
+// 试运行只执行第一阶段并返回错误报告。
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/validation"
+	"go-server/pkg/auth/password"
+
+	"github.com/google/uuid"
+)
+
+// 默认配置
+const (
+	DefaultBatchSize = 100
+	DefaultMaxRows   = 1000
+)
+
+// maxPasswordBytes bcrypt 只能计算不超过 72 字节的密码的哈希，与当前的哈希算法无关，以便切换回 bcrypt
+const maxPasswordBytes = 72
+
+var (
+	// ErrUnsupportedFormat 不支持的文件格式
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	// ErrInvalidFile 文件结构错误，如缺少 CSV 表头中的必需列或 JSON 语法错误
+	ErrInvalidFile = errors.New("invalid import file")
+	// ErrTooManyRows 文件中的数据行超过上限
+	ErrTooManyRows = errors.New("too many rows in import file")
+)
+
+// Config 导入服务配置
+type Config struct {
+	// BatchSize 每个事务写入的行数，也是查询现有用户的批大小，<=0 时使用 DefaultBatchSize
+	BatchSize int
+	// MaxRows 单个文件最多包含的数据行数，<=0 时使用 DefaultMaxRows
+	MaxRows int
+	// Hasher 计算密码哈希，为空时使用默认策略
+	Hasher password.Hasher
+}
+
+// Service 导入服务：校验导入文件并批量创建用户
+type Service struct {
+	users  repositories.UserImporter
+	config Config
+	now    func() time.Time
+}
+
+// NewService 创建导入服务
+func NewService(users repositories.UserImporter, config Config) *Service {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.MaxRows <= 0 {
+		config.MaxRows = DefaultMaxRows
+	}
+	if config.Hasher == nil {
+		config.Hasher = password.Default()
+	}
+	return &Service{users: users, config: config, now: time.Now}
+}
+
+// Import 校验导入文件并创建其中有效的用户，dryRun 为 true 时只校验不写入
+// 导入的用户为已激活的普通用户，属于 ctx 中的租户；导入不发布用户创建事件，因此不会发送欢迎通知
+func (s *Service) Import(ctx context.Context, format string, file io.Reader, dryRun bool) (*models.ImportReport, error) {
+	rows, err := readRows(format, file, s.config.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{DryRun: dryRun, TotalRows: len(rows), Errors: []models.ImportRowError{}}
+	valid, err := s.check(rows, report)
+	if err != nil {
+		return nil, err
+	}
+	if valid, err = s.withoutConflicts(ctx, valid, report); err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(valid)
+
+	if !dryRun {
+		for start := 0; start < len(valid); start += s.config.BatchSize {
+			batch := valid[start:min(start+s.config.BatchSize, len(valid))]
+			if err := s.insert(ctx, batch); err != nil {
+				for _, r := range batch {
+					report.Errors = append(report.Errors, models.ImportRowError{
+						Row: r.number, Code: models.ImportErrorInsertFailed, Message: err.Error(),
+					})
+				}
+				continue
+			}
+			report.Created += len(batch)
+		}
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+	return report, nil
+}
+
+// check 校验各行字段并排除与文件中之前的行重复的行，返回通过检查的行
+func (s *Service) check(rows []row, report *models.ImportReport) ([]row, error) {
+	emails := make(map[string]int, len(rows))
+	usernames := make(map[string]int, len(rows))
+	valid := make([]row, 0, len(rows))
+	for _, r := range rows {
+		if r.err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row: r.number, Code: models.ImportErrorInvalidRow, Message: r.err.Error(),
+			})
+			continue
+		}
+
+		details, err := validation.ValidateStruct(&r.user, validation.LanguageEnglish)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate row %d: %w", r.number, err)
+		}
+		for _, detail := range details {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row: r.number, Field: detail.Field, Code: models.ImportErrorValidation, Message: detail.Message,
+			})
+		}
+		if len(details) > 0 {
+			continue
+		}
+		if len(r.user.Password) > maxPasswordBytes {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row: r.number, Field: "password", Code: models.ImportErrorValidation,
+				Message: fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes),
+			})
+			continue
+		}
+
+		email := strings.ToLower(r.user.Email)
+		duplicate := false
+		if first, ok := emails[email]; ok {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row: r.number, Field: "email", Code: models.ImportErrorDuplicateRow,
+				Message: fmt.Sprintf("email is already used by row %d", first),
+			})
+			duplicate = true
+		}
+		if first, ok := usernames[r.user.Username]; ok {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row: r.number, Field: "username", Code: models.ImportErrorDuplicateRow,
+				Message: fmt.Sprintf("username is already used by row %d", first),
+			})
+			duplicate = true
+		}
+		if duplicate {
+			continue
+		}
+		emails[email], usernames[r.user.Username] = r.number, r.number
+		valid = append(valid, r)
+	}
+	return valid, nil
+}
+
+// withoutConflicts 分批查询邮箱或用户名已被现有用户使用的行，返回其余的行
+func (s *Service) withoutConflicts(ctx context.Context, rows []row, report *models.ImportReport) ([]row, error) {
+	remaining := make([]row, 0, len(rows))
+	for start := 0; start < len(rows); start += s.config.BatchSize {
+		batch := rows[start:min(start+s.config.BatchSize, len(rows))]
+		emails := make([]string, len(batch))
+		usernames := make([]string, len(batch))
+		for i, r := range batch {
+			emails[i], usernames[i] = r.user.Email, r.user.Username
+		}
+
+		existing, err := s.users.FindConflicts(ctx, emails, usernames)
+		if err != nil {
+			return nil, err
+		}
+		takenEmails := make(map[string]bool, len(existing))
+		takenUsernames := make(map[string]bool, len(existing))
+		for _, user := range existing {
+			takenEmails[strings.ToLower(user.Email)], takenUsernames[user.Username] = true, true
+		}
+
+		for _, r := range batch {
+			conflict := false
+			if takenEmails[strings.ToLower(r.user.Email)] {
+				report.Errors = append(report.Errors, models.ImportRowError{
+					Row: r.number, Field: "email", Code: models.ImportErrorAlreadyExists,
+					Message: "email is already used by an existing user",
+				})
+				conflict = true
+			}
+			if takenUsernames[r.user.Username] {
+				report.Errors = append(report.Errors, models.ImportRowError{
+					Row: r.number, Field: "username", Code: models.ImportErrorAlreadyExists,
+					Message: "username is already used by an existing user",
+				})
+				conflict = true
+			}
+			if !conflict {
+				remaining = append(remaining, r)
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// insert 计算密码哈希并在一个事务中创建一批用户
+func (s *Service) insert(ctx context.Context, batch []row) error {
+	hashes, err := s.hashPasswords(batch)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	users := make([]*models.User, len(batch))
+	for i, r := range batch {
+		users[i] = &models.User{
+			ID:        uuid.New().String(),
+			Username:  r.user.Username,
+			Email:     r.user.Email,
+			Password:  hashes[i],
+			FirstName: r.user.FirstName,
+			LastName:  r.user.LastName,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return s.users.CreateBatch(ctx, users)
+}
+
+// hashPasswords 并行计算一批行的密码哈希，哈希的计算成本使串行计算成为导入的瓶颈
+func (s *Service) hashPasswords(batch []row) ([]string, error) {
+	hashes := make([]string, len(batch))
+	errs := make([]error, len(batch))
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, r := range batch {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			hashes[i], errs[i] = s.config.Hasher.Hash(r.user.Password)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hashes, nil
+}
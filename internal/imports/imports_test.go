@@ -0,0 +1,200 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newService 创建使用最低 bcrypt 成本的导入服务
+func newService(users repositories.UserImporter, config Config) *Service {
+	config.Hasher = password.Bcrypt{Cost: bcrypt.MinCost}
+	return NewService(users, config)
+}
+
+// failingUsers 创建包含 failUsername 的批次时返回错误，用于模拟写入失败的事务
+type failingUsers struct {
+	*repositories.MemoryUserRepository
+	failUsername string
+}
+
+func (u *failingUsers) CreateBatch(ctx context.Context, users []*models.User) error {
+	for _, user := range users {
+		if user.Username == u.failUsername {
+			return errors.New("connection reset")
+		}
+	}
+	return u.MemoryUserRepository.CreateBatch(ctx, users)
+}
+
+// codes 返回报告中各行的错误代码，键为 "行号/字段"
+func codes(report *models.ImportReport) map[string]string {
+	result := make(map[string]string, len(report.Errors))
+	for _, e := range report.Errors {
+		result[fmt.Sprintf("%d/%s", e.Row, e.Field)] = e.Code
+	}
+	return result
+}
+
+func TestImportRows(t *testing.T) {
+	csvFile := "\ufeffEmail,Username,Password,First_Name,Extra\n" +
+		"alice@example.com, alice ,password1,Alice,x\n" +
+		"bob@example.com,bob,short\n" +
+		"ALICE@example.com,alice2,password1\n" +
+		"taken@example.com,carol,password1\n" +
+		"dave@example.com,taken,password1\n" +
+		"erin@example.com,erin,password1\n"
+	csvErrors := map[string]string{
+		"2/password": models.ImportErrorValidation,
+		"3/email":    models.ImportErrorDuplicateRow,
+		"4/email":    models.ImportErrorAlreadyExists,
+		"5/username": models.ImportErrorAlreadyExists,
+	}
+
+	tests := []struct {
+		name        string
+		format      string
+		file        string
+		dryRun      bool
+		wantTotal   int
+		wantValid   int
+		wantCreated int
+		wantErrors  map[string]string
+	}{
+		{
+			name:       "csv 试运行",
+			format:     models.ImportFormatCSV,
+			file:       csvFile,
+			dryRun:     true,
+			wantTotal:  6,
+			wantValid:  2,
+			wantErrors: csvErrors,
+		},
+		{
+			name:        "csv 导入",
+			format:      models.ImportFormatCSV,
+			file:        csvFile,
+			wantTotal:   6,
+			wantValid:   2,
+			wantCreated: 2,
+			wantErrors:  csvErrors,
+		},
+		{
+			name:   "json 导入",
+			format: models.ImportFormatJSON,
+			file: `[
+				{"username": "alice", "email": "alice@example.com", "password": "password1", "unknown": 1},
+				{"username": 42, "email": "bob@example.com", "password": "password1"},
+				{"username": "carol", "email": "not-an-email", "password": "password1"}
+			]`,
+			wantTotal:   3,
+			wantValid:   1,
+			wantCreated: 1,
+			wantErrors: map[string]string{
+				"2/":      models.ImportErrorInvalidRow,
+				"3/email": models.ImportErrorValidation,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repositories.NewMemoryUserRepository()
+			existing := factory.User().WithUsername("taken").WithEmail("Taken@Example.com").Build()
+			require.NoError(t, repo.Create(context.Background(), existing))
+
+			report, err := newService(repo, Config{BatchSize: 2}).Import(context.Background(), tt.format, strings.NewReader(tt.file), tt.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, tt.dryRun, report.DryRun)
+			assert.Equal(t, tt.wantTotal, report.TotalRows)
+			assert.Equal(t, tt.wantValid, report.ValidRows)
+			assert.Equal(t, tt.wantCreated, report.Created)
+			assert.Equal(t, tt.wantErrors, codes(report))
+
+			count, err := repo.Count(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, int64(1+tt.wantCreated), count, "试运行不创建用户")
+		})
+	}
+}
+
+func TestImportCreatesUsers(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+	service := newService(repo, Config{})
+	file := "email,username,password,first_name\nalice@example.com,alice,password1,Alice\n"
+
+	report, err := service.Import(context.Background(), models.ImportFormatCSV, strings.NewReader(file), false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+
+	alice, err := repo.GetByUsername(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", alice.FirstName)
+	assert.True(t, alice.IsActive)
+	assert.False(t, alice.IsAdmin)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(alice.Password), []byte("password1")))
+
+	report, err = service.Import(context.Background(), models.ImportFormatCSV, strings.NewReader(file), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Created, "重复导入时所有行都已存在")
+}
+
+func TestImportInvalidFiles(t *testing.T) {
+	service := newService(repositories.NewMemoryUserRepository(), Config{MaxRows: 2})
+
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		err    error
+	}{
+		{"empty csv", models.ImportFormatCSV, "", ErrInvalidFile},
+		{"missing column", models.ImportFormatCSV, "username,email\nalice,alice@example.com\n", ErrInvalidFile},
+		{"malformed csv", models.ImportFormatCSV, "username,email,password\n\"alice,a@example.com,x\n", ErrInvalidFile},
+		{"too many csv rows", models.ImportFormatCSV, "username,email,password\na,a,a\nb,b,b\nc,c,c\n", ErrTooManyRows},
+		{"json object", models.ImportFormatJSON, `{"username": "alice"}`, ErrInvalidFile},
+		{"truncated json", models.ImportFormatJSON, `[{"username": "alice"}`, ErrInvalidFile},
+		{"too many json rows", models.ImportFormatJSON, `[{}, {}, {}]`, ErrTooManyRows},
+		{"unsupported format", "xml", "<users/>", ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Import(context.Background(), tt.format, strings.NewReader(tt.file), true)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestImportFailedBatch(t *testing.T) {
+	repo := &failingUsers{MemoryUserRepository: repositories.NewMemoryUserRepository(), failUsername: "carol"}
+	service := newService(repo, Config{BatchSize: 2})
+
+	file := "username,email,password\n" +
+		"alice,alice@example.com,password1\n" +
+		"bob,bob@example.com,password1\n" +
+		"carol,carol@example.com,password1\n" +
+		"dave,dave@example.com,password1\n" +
+		"erin,erin@example.com,password1\n"
+	report, err := service.Import(context.Background(), models.ImportFormatCSV, strings.NewReader(file), false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.ValidRows)
+	assert.Equal(t, 3, report.Created, "失败的批次不影响其他批次")
+	assert.Equal(t, map[string]string{
+		"3/": models.ImportErrorInsertFailed,
+		"4/": models.ImportErrorInsertFailed,
+	}, codes(report))
+
+	_, err = repo.GetByUsername(context.Background(), "dave")
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound, "同一批次的行均未导入")
+}
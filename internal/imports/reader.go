@@ -0,0 +1,122 @@
+package imports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-server/internal/models"
+)
+
+// requiredColumns CSV 表头必须包含的列，first_name 和 last_name 可以省略，其他列被忽略
+var requiredColumns = []string{"username", "email", "password"}
+
+// row 导入文件中的一个数据行
+type row struct {
+	number int                    // 数据行号，从 1 开始
+	user   models.RegisterRequest // 行中的用户字段
+	err    error                  // 行无法解析时的错误
+}
+
+// readRows 读取导入文件中的所有数据行，超过 maxRows 行时返回 ErrTooManyRows
+// 单行无法解析时记录在行中，文件结构错误时返回 ErrInvalidFile
+func readRows(format string, r io.Reader, maxRows int) ([]row, error) {
+	switch format {
+	case models.ImportFormatCSV:
+		return readCSV(r, maxRows)
+	case models.ImportFormatJSON:
+		return readJSON(r, maxRows)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// readCSV 读取带表头的 CSV 文件，列的顺序不限，缺少的单元格视为空值
+func readCSV(r io.Reader, maxRows int) ([]row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// 电子表格软件保存的 UTF-8 CSV 可能以 BOM 开头
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidFile, name)
+		}
+	}
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrTooManyRows, maxRows)
+		}
+		rows = append(rows, row{
+			number: len(rows) + 1,
+			user: models.RegisterRequest{
+				Username:  strings.TrimSpace(cell(record, "username")),
+				Email:     strings.TrimSpace(cell(record, "email")),
+				Password:  cell(record, "password"),
+				FirstName: strings.TrimSpace(cell(record, "first_name")),
+				LastName:  strings.TrimSpace(cell(record, "last_name")),
+			},
+		})
+	}
+}
+
+// readJSON 读取对象数组，字段与注册请求相同，未知字段被忽略
+// 字段类型错误只影响所在的行，语法错误使整个文件无效
+func readJSON(r io.Reader, maxRows int) ([]row, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array of users", ErrInvalidFile)
+	}
+
+	var rows []row
+	for decoder.More() {
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrTooManyRows, maxRows)
+		}
+		current := row{number: len(rows) + 1}
+		if err := decoder.Decode(&current.user); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+			current.err = fmt.Errorf("field %s must be of type %s", typeErr.Field, typeErr.Type)
+		}
+		rows = append(rows, current)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	return rows, nil
+}
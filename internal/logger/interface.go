@@ -3,6 +3,8 @@ package logger
 import (
 	"context"
 
+	"go-server/pkg/tracecontext"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -37,6 +39,20 @@ type Logger interface {
 	Sync() error
 }
 
+// LevelEnabler 可以预先判断是否记录某个级别日志的日志记录器
+// 热路径在构建字段和格式化消息之前先判断，避免为被过滤的日志分配内存
+type LevelEnabler interface {
+	Enabled(level zapcore.Level) bool
+}
+
+// Enabled 判断日志记录器是否记录该级别的日志，无法判断的记录器视为记录
+func Enabled(l Logger, level zapcore.Level) bool {
+	if enabler, ok := l.(LevelEnabler); ok {
+		return enabler.Enabled(level)
+	}
+	return true
+}
+
 // Field represents a key-value pair for structured logging
 type Field struct {
 	Key   string
@@ -84,6 +100,7 @@ type zapLoggerImpl struct {
 	logger *zap.Logger
 	module string
 	fields []Field
+	levels *moduleLevels // 为 nil 时仅按 zap 核心的级别过滤
 }
 
 // NewZapLogger 创建一个新的基于 zap 的日志记录器
@@ -94,6 +111,15 @@ func NewZapLogger(zapLogger *zap.Logger) Logger {
 	}
 }
 
+// newLevelFilteredLogger 创建按模块判断日志级别的日志记录器
+func newLevelFilteredLogger(zapLogger *zap.Logger, levels *moduleLevels) Logger {
+	return &zapLoggerImpl{
+		logger: zapLogger,
+		fields: make([]Field, 0),
+		levels: levels,
+	}
+}
+
 // Debug logs a debug message with context and optional fields
 func (l *zapLoggerImpl) Debug(ctx context.Context, message string, fields ...Field) {
 	l.log(ctx, zapcore.DebugLevel, message, fields...)
@@ -130,6 +156,7 @@ func (l *zapLoggerImpl) WithFields(fields ...Field) Logger {
 		logger: l.logger,
 		module: l.module,
 		fields: allFields,
+		levels: l.levels,
 	}
 }
 
@@ -139,6 +166,7 @@ func (l *zapLoggerImpl) WithModule(module string) Logger {
 		logger: l.logger,
 		module: module,
 		fields: l.fields,
+		levels: l.levels,
 	}
 }
 
@@ -152,8 +180,21 @@ func (l *zapLoggerImpl) Sync() error {
 	return l.logger.Sync()
 }
 
+// Enabled 实现 LevelEnabler 接口，按模块级别和 zap 核心的级别判断
+func (l *zapLoggerImpl) Enabled(level zapcore.Level) bool {
+	if l.levels != nil && !l.levels.enabled(l.module, level) {
+		return false
+	}
+	return l.logger.Core().Enabled(level)
+}
+
 // log 是内部日志记录方法
 func (l *zapLoggerImpl) log(ctx context.Context, level zapcore.Level, message string, fields ...Field) {
+	// 按模块判断是否需要记录，避免为不记录的日志构建字段
+	if l.levels != nil && !l.levels.enabled(l.module, level) {
+		return
+	}
+
 	// 创建 zap 字段
 	zapFields := make([]zap.Field, 0, len(l.fields)+len(fields)+2)
 
@@ -179,6 +220,14 @@ func (l *zapLoggerImpl) log(ctx context.Context, level zapcore.Level, message st
 		}
 	}
 
+	// 从上下文中提取请求ID和 W3C 链路上下文，便于与链路追踪系统关联
+	if requestID, ok := ctx.Value("request_id").(string); ok && requestID != "" {
+		zapFields = append(zapFields, zap.String("request_id", requestID))
+	}
+	if span, ok := tracecontext.FromContext(ctx); ok {
+		zapFields = append(zapFields, zap.String("trace_id", span.TraceID), zap.String("span_id", span.SpanID))
+	}
+
 	// 使用 zap 记录日志
 	switch level {
 	case zapcore.DebugLevel:
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelSettings 当前生效的全局日志级别和按模块覆盖的日志级别
+type LevelSettings struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// moduleLevels 管理全局日志级别和按模块覆盖的日志级别
+// 模块覆盖以写时复制的方式保存，记录日志时无需加锁
+type moduleLevels struct {
+	mu        sync.Mutex // 保护覆盖表的写入
+	global    zap.AtomicLevel
+	overrides atomic.Pointer[map[string]zapcore.Level]
+}
+
+// newModuleLevels 创建以 level 为全局级别的模块级别管理器
+func newModuleLevels(level zapcore.Level) *moduleLevels {
+	levels := &moduleLevels{global: zap.NewAtomicLevelAt(level)}
+	levels.overrides.Store(&map[string]zapcore.Level{})
+	return levels
+}
+
+// Enabled 实现 zapcore.LevelEnabler 接口，只要全局级别或任一模块覆盖允许即返回 true
+// 具体模块是否记录由 enabled 判断
+func (l *moduleLevels) Enabled(level zapcore.Level) bool {
+	if l.global.Enabled(level) {
+		return true
+	}
+	for _, override := range *l.overrides.Load() {
+		if level >= override {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled 判断指定模块是否记录该级别的日志，模块覆盖优先于全局级别
+func (l *moduleLevels) enabled(module string, level zapcore.Level) bool {
+	if override, ok := (*l.overrides.Load())[module]; ok {
+		return level >= override
+	}
+	return l.global.Enabled(level)
+}
+
+// setGlobal 修改全局日志级别
+func (l *moduleLevels) setGlobal(level zapcore.Level) {
+	l.global.SetLevel(level)
+}
+
+// setModule 覆盖模块的日志级别
+func (l *moduleLevels) setModule(module string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overrides := maps.Clone(*l.overrides.Load())
+	overrides[module] = level
+	l.overrides.Store(&overrides)
+}
+
+// resetModule 移除模块的日志级别覆盖
+func (l *moduleLevels) resetModule(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overrides := maps.Clone(*l.overrides.Load())
+	delete(overrides, module)
+	l.overrides.Store(&overrides)
+}
+
+// settings 返回当前的级别设置
+func (l *moduleLevels) settings() LevelSettings {
+	overrides := *l.overrides.Load()
+	settings := LevelSettings{
+		Level:   l.global.Level().String(),
+		Modules: make(map[string]string, len(overrides)),
+	}
+	for module, level := range overrides {
+		settings.Modules[module] = level.String()
+	}
+	return settings
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-server/internal/config"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newFileManager 创建输出到临时目录的日志管理器
+func newFileManager(t *testing.T, cfg config.LoggingConfig) *Manager {
+	t.Helper()
+
+	cfg.Format = "json"
+	cfg.Output = "file"
+	cfg.Directory = t.TempDir()
+
+	manager, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Failed to start logger manager: %v", err)
+	}
+	t.Cleanup(func() { manager.Stop() })
+	return manager
+}
+
+// readLogs 读取日志目录中的全部日志内容
+func readLogs(t *testing.T, manager *Manager) string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(manager.GetConfig().Directory, "*.log"))
+	if err != nil {
+		t.Fatalf("Failed to list log files: %v", err)
+	}
+
+	var content strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		content.Write(data)
+	}
+	return content.String()
+}
+
+func TestManagerModuleLevels(t *testing.T) {
+	manager := newFileManager(t, config.LoggingConfig{Level: "info"})
+	ctx := context.Background()
+
+	httpLogger := manager.GetLogger("http")
+	dbLogger := manager.GetLogger("database")
+
+	if err := manager.SetModuleLevel("http", "warn"); err != nil {
+		t.Fatalf("SetModuleLevel failed: %v", err)
+	}
+	if err := manager.SetModuleLevel("database", "debug"); err != nil {
+		t.Fatalf("SetModuleLevel failed: %v", err)
+	}
+
+	// 已创建的日志记录器立即使用新级别
+	httpLogger.Info(ctx, "http info suppressed")
+	httpLogger.Warn(ctx, "http warn logged")
+	dbLogger.Debug(ctx, "database debug logged")
+	manager.GetLogger("app").Debug(ctx, "app debug suppressed")
+
+	manager.ResetModuleLevel("http")
+	httpLogger.Info(ctx, "http info after reset")
+
+	logs := readLogs(t, manager)
+	for _, expected := range []string{"http warn logged", "database debug logged", "http info after reset"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("expected log %q in output:\n%s", expected, logs)
+		}
+	}
+	for _, unexpected := range []string{"http info suppressed", "app debug suppressed"} {
+		if strings.Contains(logs, unexpected) {
+			t.Errorf("unexpected log %q in output:\n%s", unexpected, logs)
+		}
+	}
+
+	settings := manager.Levels()
+	if settings.Level != "info" || len(settings.Modules) != 1 || settings.Modules["database"] != "debug" {
+		t.Errorf("unexpected level settings: %+v", settings)
+	}
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	manager := newFileManager(t, config.LoggingConfig{Level: "info"})
+	if err := manager.SetModuleLevel("http", "warn"); err != nil {
+		t.Fatalf("SetModuleLevel failed: %v", err)
+	}
+
+	httpLogger := manager.GetLogger("http")
+	if Enabled(httpLogger, zapcore.InfoLevel) || !Enabled(httpLogger, zapcore.WarnLevel) {
+		t.Error("expected the module override to filter info and keep warn")
+	}
+	appLogger := manager.GetLogger("app")
+	if Enabled(appLogger, zapcore.DebugLevel) || !Enabled(appLogger, zapcore.InfoLevel) {
+		t.Error("expected the global level to filter debug and keep info")
+	}
+	if Enabled(&noopLogger{}, zapcore.ErrorLevel) {
+		t.Error("expected the noop logger to log nothing")
+	}
+}
+
+func TestManagerSetLevel(t *testing.T) {
+	manager := newFileManager(t, config.LoggingConfig{Level: "info"})
+
+	if err := manager.SetLevel("verbose"); err == nil {
+		t.Error("SetLevel should reject unknown levels")
+	}
+	if err := manager.SetModuleLevel("", "debug"); err == nil {
+		t.Error("SetModuleLevel should reject empty module names")
+	}
+
+	if err := manager.SetLevel("error"); err != nil {
+		t.Fatalf("SetLevel failed: %v", err)
+	}
+	if err := manager.SetModuleLevel("http", "debug"); err != nil {
+		t.Fatalf("SetModuleLevel failed: %v", err)
+	}
+
+	// 配置更新后全局级别恢复为配置中的级别，模块覆盖保留
+	cfg := manager.GetConfig()
+	cfg.Level = "warn"
+	if err := manager.UpdateConfig(cfg); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+
+	settings := manager.Levels()
+	if settings.Level != "warn" || settings.Modules["http"] != "debug" {
+		t.Errorf("unexpected level settings after UpdateConfig: %+v", settings)
+	}
+}
+
+func TestManagerSampling(t *testing.T) {
+	manager := newFileManager(t, config.LoggingConfig{
+		Level:    "info",
+		Sampling: config.LoggingSamplingConfig{Enabled: true, Initial: 2, Thereafter: 5},
+	})
+	ctx := context.Background()
+	httpLogger := manager.GetLogger("http")
+
+	for i := 0; i < 12; i++ {
+		httpLogger.Info(ctx, "HTTP Request: GET /health -> 200")
+		httpLogger.Error(ctx, "HTTP Request Error: GET /users -> 500")
+	}
+
+	// 前 2 条完整记录，之后每 5 条记录 1 条（第 7、12 条）；错误日志不采样
+	logs := readLogs(t, manager)
+	if count := strings.Count(logs, "GET /health -> 200"); count != 4 {
+		t.Errorf("expected 4 sampled info logs, got %d", count)
+	}
+	if count := strings.Count(logs, "GET /users -> 500"); count != 12 {
+		t.Errorf("expected 12 error logs, got %d", count)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"time"
 
@@ -137,6 +138,8 @@ type Manager struct {
 	zapLogger  *zap.Logger
 	logger     Logger
 	fileWriter *DateRotatingWriter // 自定义日期轮转写入器
+	shipper    *Shipper            // 远程日志推送器，输出为 loki 或 http 时使用
+	levels     *moduleLevels       // 全局和按模块覆盖的日志级别，配置更新时保留模块覆盖
 	started    bool
 }
 
@@ -149,18 +152,37 @@ func NewManager(cfg config.LoggingConfig) (*Manager, error) {
 		fileWriter = NewDateRotatingWriter(cfg.Directory, cfg.MaxAge, cfg.Compress)
 	}
 
-	zapLogger, err := buildZapLogger(cfg, fileWriter)
+	// 如果需要远程推送，创建日志推送器
+	var shipper *Shipper
+	if isShippingOutput(cfg.Output) {
+		var err error
+		shipper, err = NewShipper(cfg.Output, cfg.Shipping)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log shipper: %w", err)
+		}
+	}
+
+	level, err := parseLogLevel(cfg.Level)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build zap logger: %w", err)
+		return nil, fmt.Errorf("invalid log level: %w", err)
 	}
+	levels := newModuleLevels(level)
 
-	logger := NewZapLogger(zapLogger)
+	zapLogger, err := buildZapLogger(cfg, fileWriter, shipper, levels)
+	if err != nil {
+		if shipper != nil {
+			shipper.Close()
+		}
+		return nil, fmt.Errorf("failed to build zap logger: %w", err)
+	}
 
 	return &Manager{
 		config:     cfg,
 		zapLogger:  zapLogger,
-		logger:     logger,
+		logger:     newLevelFilteredLogger(zapLogger, levels),
 		fileWriter: fileWriter,
+		shipper:    shipper,
+		levels:     levels,
 		started:    false,
 	}, nil
 }
@@ -209,6 +231,11 @@ func (m *Manager) Stop() error {
 		}
 	}
 
+	// 推送剩余日志并停止推送器
+	if m.shipper != nil {
+		m.shipper.Close()
+	}
+
 	m.started = false
 	return nil
 }
@@ -229,6 +256,11 @@ func (m *Manager) GetLogger(name string) Logger {
 
 // UpdateConfig 更新日志配置
 func (m *Manager) UpdateConfig(newConfig config.LoggingConfig) error {
+	level, err := parseLogLevel(newConfig.Level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -250,21 +282,69 @@ func (m *Manager) UpdateConfig(newConfig config.LoggingConfig) error {
 		fileWriter = NewDateRotatingWriter(newConfig.Directory, newConfig.MaxAge, newConfig.Compress)
 	}
 
+	// 推送配置未变化时复用当前的推送器，避免已创建的日志记录器写入已关闭的推送器
+	shipper := m.shipper
+	if shipper != nil && (newConfig.Output != m.config.Output || !reflect.DeepEqual(newConfig.Shipping, m.config.Shipping)) {
+		shipper.Close()
+		shipper = nil
+	}
+	if shipper == nil && isShippingOutput(newConfig.Output) {
+		if shipper, err = NewShipper(newConfig.Output, newConfig.Shipping); err != nil {
+			m.shipper = nil
+			return fmt.Errorf("failed to create log shipper: %w", err)
+		}
+	}
+
 	// 构建新的 zap 日志记录器
-	newZapLogger, err := buildZapLogger(newConfig, fileWriter)
+	newZapLogger, err := buildZapLogger(newConfig, fileWriter, shipper, m.levels)
 	if err != nil {
 		return fmt.Errorf("failed to build new zap logger: %w", err)
 	}
 
-	// 更新配置和日志记录器
+	// 更新配置和日志记录器，全局级别恢复为配置中的级别，模块覆盖保持不变
 	m.config = newConfig
 	m.fileWriter = fileWriter
+	m.shipper = shipper
 	m.zapLogger = newZapLogger
-	m.logger = NewZapLogger(newZapLogger)
+	m.levels.setGlobal(level)
+	m.logger = newLevelFilteredLogger(newZapLogger, m.levels)
 
 	return nil
 }
 
+// SetLevel 运行时修改全局日志级别，配置热重载后恢复为配置中的级别
+func (m *Manager) SetLevel(level string) error {
+	zapLevel, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	m.levels.setGlobal(zapLevel)
+	return nil
+}
+
+// SetModuleLevel 运行时覆盖指定模块的日志级别，立即对该模块已创建的日志记录器生效
+func (m *Manager) SetModuleLevel(module, level string) error {
+	if module == "" {
+		return fmt.Errorf("module name is required")
+	}
+	zapLevel, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	m.levels.setModule(module, zapLevel)
+	return nil
+}
+
+// ResetModuleLevel 移除模块的日志级别覆盖，该模块恢复使用全局级别
+func (m *Manager) ResetModuleLevel(module string) {
+	m.levels.resetModule(module)
+}
+
+// Levels 返回当前的全局日志级别和模块级别覆盖
+func (m *Manager) Levels() LevelSettings {
+	return m.levels.settings()
+}
+
 // GetConfig 返回当前的日志配置
 func (m *Manager) GetConfig() config.LoggingConfig {
 	m.mu.RLock()
@@ -279,14 +359,8 @@ func (m *Manager) IsStarted() bool {
 	return m.started
 }
 
-// buildZapLogger 根据配置构建 zap 日志记录器
-func buildZapLogger(cfg config.LoggingConfig, fileWriter *DateRotatingWriter) (*zap.Logger, error) {
-	// 解析日志级别
-	level, err := parseLogLevel(cfg.Level)
-	if err != nil {
-		return nil, fmt.Errorf("invalid log level: %w", err)
-	}
-
+// buildZapLogger 根据配置构建 zap 日志记录器，核心按 level 过滤日志级别
+func buildZapLogger(cfg config.LoggingConfig, fileWriter *DateRotatingWriter, shipper *Shipper, level zapcore.LevelEnabler) (*zap.Logger, error) {
 	var cores []zapcore.Core
 
 	// 根据输出类型创建不同的编码器和核心
@@ -398,6 +472,27 @@ func buildZapLogger(cfg config.LoggingConfig, fileWriter *DateRotatingWriter) (*
 		fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(fileWriter), level)
 		cores = append(cores, consoleCore, fileCore)
 
+	case "loki", "http":
+		// 推送到远程服务 - 始终使用 JSON 格式，便于日志系统解析
+		if shipper == nil {
+			return nil, fmt.Errorf("log shipper is required for %s output", cfg.Output)
+		}
+
+		encoderConfig := zapcore.EncoderConfig{
+			TimeKey:        "timestamp",
+			LevelKey:       "level",
+			NameKey:        "logger",
+			MessageKey:     "message",
+			StacktraceKey:  "stacktrace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeDuration: zapcore.StringDurationEncoder,
+		}
+
+		shipperCore := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), shipper, level)
+		cores = append(cores, shipperCore)
+
 	default:
 		return nil, fmt.Errorf("unsupported output type: %s", cfg.Output)
 	}
@@ -410,12 +505,22 @@ func buildZapLogger(cfg config.LoggingConfig, fileWriter *DateRotatingWriter) (*
 		core = zapcore.NewTee(cores...)
 	}
 
+	// 高流量时对低级别日志采样，warn 及以上级别的日志不受影响
+	if cfg.Sampling.Enabled {
+		core = newSamplingCore(core, cfg.Sampling)
+	}
+
 	// 创建日志记录器
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	return logger, nil
 }
 
+// isShippingOutput 判断输出类型是否推送到远程服务
+func isShippingOutput(output string) bool {
+	return output == "loki" || output == "http"
+}
+
 // parseLogLevel 解析日志级别字符串
 func parseLogLevel(levelStr string) (zapcore.Level, error) {
 	switch levelStr {
@@ -447,3 +552,6 @@ func (l *noopLogger) WithFields(fields ...Field) Logger             { return l }
 func (l *noopLogger) WithModule(module string) Logger               { return l }
 func (l *noopLogger) WithCorrelationID(correlationID string) Logger { return l }
 func (l *noopLogger) Sync() error                                   { return nil }
+
+// Enabled 实现 LevelEnabler 接口，空操作的日志记录器不记录任何级别
+func (l *noopLogger) Enabled(level zapcore.Level) bool { return false }
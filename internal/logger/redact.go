@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue 脱敏后替换原值的占位符
+const RedactedValue = "[REDACTED]"
+
+// Redactor 按字段路径对写入日志的 JSON 数据进行脱敏
+//
+// 路径以 . 分隔且不区分大小写，* 匹配任意一级字段，如 data.*.token；
+// 不含 . 的路径匹配任意层级的同名字段，如 password；数组元素沿用数组所在字段的路径
+type Redactor struct {
+	names map[string]bool // 任意层级匹配的字段名
+	paths [][]string      // 完整路径
+}
+
+// NewRedactor 根据字段路径创建脱敏器，空路径会被忽略
+func NewRedactor(paths []string) *Redactor {
+	r := &Redactor{names: make(map[string]bool)}
+	for _, path := range paths {
+		path = strings.ToLower(strings.TrimSpace(path))
+		if path == "" {
+			continue
+		}
+		if !strings.Contains(path, ".") {
+			r.names[path] = true
+			continue
+		}
+		r.paths = append(r.paths, strings.Split(path, "."))
+	}
+	return r
+}
+
+// RedactJSON 解析 JSON 数据并返回脱敏后的紧凑 JSON，数据不是合法 JSON 时返回 false
+func (r *Redactor) RedactJSON(data []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(r.Redact(value))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// Redact 脱敏由 encoding/json 解析得到的值，map 和切片会被原地修改
+func (r *Redactor) Redact(value interface{}) interface{} {
+	return r.walk(value, nil)
+}
+
+// walk 递归遍历值并替换匹配路径的字段
+func (r *Redactor) walk(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], strings.ToLower(key))
+			if r.matches(childPath) {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = r.walk(child, childPath)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.walk(child, path)
+		}
+	}
+	return value
+}
+
+// matches 判断字段路径是否需要脱敏
+func (r *Redactor) matches(path []string) bool {
+	if r.names[path[len(path)-1]] {
+		return true
+	}
+
+	for _, pattern := range r.paths {
+		if len(pattern) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestRedactorRedactJSON(t *testing.T) {
+	redactor := NewRedactor([]string{"password", "Access_Token", "data.*.email", "profile.phone", " "})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "top level field",
+			input:    `{"username":"john","password":"secret123"}`,
+			expected: `{"password":"[REDACTED]","username":"john"}`,
+		},
+		{
+			name:     "field name at any depth and case insensitive",
+			input:    `{"data":{"tokens":{"ACCESS_TOKEN":"abc","expires_in":3600}}}`,
+			expected: `{"data":{"tokens":{"ACCESS_TOKEN":"[REDACTED]","expires_in":3600}}}`,
+		},
+		{
+			name:     "wildcard path through arrays",
+			input:    `{"data":{"users":[{"id":1,"email":"a@example.com"},{"id":2,"email":"b@example.com"}]},"email":"kept@example.com"}`,
+			expected: `{"data":{"users":[{"email":"[REDACTED]","id":1},{"email":"[REDACTED]","id":2}]},"email":"kept@example.com"}`,
+		},
+		{
+			name:     "exact path only",
+			input:    `{"profile":{"phone":"123"},"phone":"456"}`,
+			expected: `{"phone":"456","profile":{"phone":"[REDACTED]"}}`,
+		},
+		{
+			name:     "nested object value is replaced entirely",
+			input:    `{"password":{"old":"a","new":"b"}}`,
+			expected: `{"password":"[REDACTED]"}`,
+		},
+		{
+			name:     "top level array",
+			input:    `[{"password":"a"},{"name":"b"}]`,
+			expected: `[{"password":"[REDACTED]"},{"name":"b"}]`,
+		},
+		{
+			name:     "large numbers are preserved",
+			input:    `{"id":12345678901234567890}`,
+			expected: `{"id":12345678901234567890}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, ok := redactor.RedactJSON([]byte(tt.input))
+			if !ok {
+				t.Fatalf("RedactJSON(%s) returned not ok", tt.input)
+			}
+			if string(redacted) != tt.expected {
+				t.Errorf("RedactJSON(%s) = %s, want %s", tt.input, redacted, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedactorInvalidJSON(t *testing.T) {
+	redactor := NewRedactor([]string{"password"})
+
+	for _, input := range []string{`{"password":"secr`, `not json`, `{"a":1} {"b":2}`} {
+		if _, ok := redactor.RedactJSON([]byte(input)); ok {
+			t.Errorf("RedactJSON(%s) should fail", input)
+		}
+	}
+}
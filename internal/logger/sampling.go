@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"time"
+
+	"go-server/internal/config"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingCore 仅对 warn 以下级别的日志采样，warn 及以上级别的日志全部记录
+// 采样按级别和消息分组：每秒内相同消息先完整记录 initial 条，之后每 thereafter 条记录 1 条
+type samplingCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+// newSamplingCore 根据采样配置包装日志核心
+func newSamplingCore(core zapcore.Core, cfg config.LoggingSamplingConfig) zapcore.Core {
+	return &samplingCore{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, time.Second, cfg.Initial, cfg.Thereafter),
+	}
+}
+
+// With 实现 zapcore.Core 接口
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{
+		Core:    c.Core.With(fields),
+		sampled: c.sampled.With(fields),
+	}
+}
+
+// Check 实现 zapcore.Core 接口，warn 以下级别的日志交由采样器决定是否记录
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level >= zapcore.WarnLevel {
+		return c.Core.Check(entry, checked)
+	}
+	return c.sampled.Check(entry, checked)
+}
@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-server/internal/config"
+)
+
+// maxShipperBackoff 推送失败重试的最大等待时间
+const maxShipperBackoff = 5 * time.Second
+
+// shipperEntry 待推送的一条日志
+type shipperEntry struct {
+	timestamp time.Time
+	line      []byte
+}
+
+// batchEncoder 将一批日志编码为推送请求体
+type batchEncoder interface {
+	contentType() string
+	encode(entries []shipperEntry) ([]byte, error)
+}
+
+// lokiEncoder 编码为 Loki push API 的请求体，所有日志属于同一个流
+type lokiEncoder struct {
+	labels map[string]string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (e lokiEncoder) contentType() string {
+	return "application/json"
+}
+
+func (e lokiEncoder) encode(entries []shipperEntry) ([]byte, error) {
+	values := make([][2]string, len(entries))
+	for i, entry := range entries {
+		values[i] = [2]string{strconv.FormatInt(entry.timestamp.UnixNano(), 10), string(entry.line)}
+	}
+	return json.Marshal(map[string][]lokiStream{
+		"streams": {{Stream: e.labels, Values: values}},
+	})
+}
+
+// ndjsonEncoder 编码为每行一条 JSON 日志的请求体
+type ndjsonEncoder struct{}
+
+func (e ndjsonEncoder) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (e ndjsonEncoder) encode(entries []shipperEntry) ([]byte, error) {
+	var body bytes.Buffer
+	for _, entry := range entries {
+		body.Write(entry.line)
+		body.WriteByte('\n')
+	}
+	return body.Bytes(), nil
+}
+
+// Shipper 将日志批量推送到远程服务的写入器，实现 zapcore.WriteSyncer 接口
+//
+// 写入只进入有界队列而不等待网络请求：队列满时丢弃新日志并定期向标准错误报告丢弃数量，
+// 避免日志服务不可用时阻塞业务请求。推送失败时按指数退避重试，超过重试次数后丢弃该批日志
+type Shipper struct {
+	url           string
+	headers       map[string]string
+	encoder       batchEncoder
+	client        *http.Client
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+
+	queue     chan shipperEntry
+	flushes   chan chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	dropped   atomic.Int64
+}
+
+// NewShipper 根据输出类型（loki 或 http）和推送配置创建日志推送器，并启动后台推送协程
+func NewShipper(output string, cfg config.LoggingShippingConfig) (*Shipper, error) {
+	var encoder batchEncoder
+	switch output {
+	case "loki":
+		if len(cfg.Labels) == 0 {
+			return nil, fmt.Errorf("loki output requires at least one stream label")
+		}
+		encoder = lokiEncoder{labels: cfg.Labels}
+	case "http":
+		encoder = ndjsonEncoder{}
+	default:
+		return nil, fmt.Errorf("unsupported shipping output: %s", output)
+	}
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("shipping url is required for %s output", output)
+	}
+	if cfg.BatchSize <= 0 || cfg.FlushInterval <= 0 || cfg.QueueSize <= 0 || cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("shipping batch size, flush interval, queue size and timeout must be positive")
+	}
+
+	s := &Shipper{
+		url:           cfg.URL,
+		headers:       cfg.Headers,
+		encoder:       encoder,
+		client:        &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		maxRetries:    cfg.MaxRetries,
+		queue:         make(chan shipperEntry, cfg.QueueSize),
+		flushes:       make(chan chan struct{}),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	go s.run()
+
+	return s, nil
+}
+
+// Write 实现 io.Writer 接口，将一条编码后的日志加入推送队列
+func (s *Shipper) Write(p []byte) (int, error) {
+	select {
+	case <-s.done:
+		// 推送器关闭后仍持有旧日志记录器的调用方，日志输出到标准错误而不是静默丢失
+		return os.Stderr.Write(p)
+	default:
+	}
+
+	// zap 会复用缓冲区，需要复制
+	entry := shipperEntry{
+		timestamp: time.Now(),
+		line:      bytes.TrimRight(bytes.Clone(p), "\n"),
+	}
+	select {
+	case s.queue <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 实现 zapcore.WriteSyncer 接口，推送队列中已有的日志后返回
+func (s *Shipper) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case s.flushes <- ack:
+	case <-s.stopped:
+		return nil
+	}
+
+	select {
+	case <-ack:
+	case <-s.stopped:
+	}
+	return nil
+}
+
+// Close 推送队列中剩余的日志并停止后台推送协程
+func (s *Shipper) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	<-s.stopped
+	return nil
+}
+
+// run 后台推送循环，批次已满或到达推送间隔时推送
+func (s *Shipper) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]shipperEntry, 0, s.batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			s.send(batch)
+			batch = batch[:0]
+		}
+		s.reportDropped()
+	}
+	drain := func() {
+		for {
+			select {
+			case entry := <-s.queue:
+				batch = append(batch, entry)
+				if len(batch) >= s.batchSize {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case ack := <-s.flushes:
+			drain()
+			close(ack)
+		case <-s.done:
+			drain()
+			return
+		}
+	}
+}
+
+// send 推送一批日志，网络错误、429 和 5xx 响应按指数退避重试
+func (s *Shipper) send(entries []shipperEntry) {
+	body, err := s.encoder.encode(entries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to encode %d log entries for shipping: %v\n", len(entries), err)
+		return
+	}
+
+	backoff := 100 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retryable, err := s.post(body)
+		if err == nil {
+			return
+		}
+		if !retryable || attempt >= s.maxRetries {
+			fmt.Fprintf(os.Stderr, "Warning: failed to ship %d log entries after %d attempts: %v\n", len(entries), attempt+1, err)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxShipperBackoff)
+	}
+}
+
+// post 发送一次推送请求，返回失败时是否可以重试
+func (s *Shipper) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", s.encoder.contentType())
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
+
+// reportDropped 向标准错误报告因队列已满而丢弃的日志条数
+func (s *Shipper) reportDropped() {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: log shipping queue is full, dropped %d log entries\n", dropped)
+	}
+}
@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+)
+
+func newShippingConfig(url string) config.LoggingShippingConfig {
+	return config.LoggingShippingConfig{
+		URL:           url,
+		Headers:       map[string]string{"X-Scope-OrgID": "tenant-1"},
+		Labels:        map[string]string{"app": "go-server"},
+		BatchSize:     100,
+		FlushInterval: 60000,
+		QueueSize:     100,
+		MaxRetries:    2,
+		Timeout:       5,
+	}
+}
+
+// recordingServer 记录收到的推送请求体，前 failures 次请求返回 503
+func recordingServer(t *testing.T, failures int32) (*httptest.Server, func() []string, *atomic.Int32) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var bodies []string
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Scope-OrgID") != "tenant-1" {
+			t.Errorf("missing configured header, got %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, r.Header.Get("Content-Type")+"\n"+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}, &requests
+}
+
+func TestShipperLoki(t *testing.T) {
+	server, bodies, _ := recordingServer(t, 0)
+
+	manager, err := NewManager(config.LoggingConfig{
+		Level:    "info",
+		Format:   "text",
+		Output:   "loki",
+		Shipping: newShippingConfig(server.URL),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Failed to start logger manager: %v", err)
+	}
+
+	ctx := context.Background()
+	manager.GetLogger("http").Info(ctx, "first message")
+	manager.GetLogger("http").Warn(ctx, "second message", String("path", "/health"))
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Failed to stop logger manager: %v", err)
+	}
+
+	received := bodies()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 push request, got %d", len(received))
+	}
+	contentType, body, _ := strings.Cut(received[0], "\n")
+	if contentType != "application/json" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(body), &push); err != nil {
+		t.Fatalf("invalid loki payload %s: %v", body, err)
+	}
+	if len(push.Streams) != 1 || push.Streams[0].Stream["app"] != "go-server" {
+		t.Fatalf("unexpected streams: %+v", push.Streams)
+	}
+	values := push.Streams[0].Values
+	if len(values) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(values))
+	}
+
+	// 日志行始终为 JSON 格式
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(values[1][1]), &line); err != nil {
+		t.Fatalf("log line is not JSON: %s", values[1][1])
+	}
+	if line["message"] != "second message" || line["path"] != "/health" || line["module"] != "http" {
+		t.Errorf("unexpected log line: %v", line)
+	}
+}
+
+func TestShipperNDJSONRetries(t *testing.T) {
+	server, bodies, requests := recordingServer(t, 2)
+
+	shipper, err := NewShipper("http", newShippingConfig(server.URL))
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+	shipper.Write([]byte(`{"message":"a"}` + "\n"))
+	shipper.Write([]byte(`{"message":"b"}` + "\n"))
+	shipper.Sync()
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+	received := bodies()
+	if len(received) != 1 || received[0] != "application/x-ndjson\n"+`{"message":"a"}`+"\n"+`{"message":"b"}`+"\n" {
+		t.Errorf("unexpected ndjson payloads: %q", received)
+	}
+
+	shipper.Close()
+}
+
+func TestShipperBatchingAndBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	var batches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		batches.Add(1)
+	}))
+	defer server.Close()
+
+	cfg := newShippingConfig(server.URL)
+	cfg.BatchSize = 2
+	cfg.QueueSize = 3
+	shipper, err := NewShipper("http", cfg)
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+
+	// 第一批推送阻塞期间写入不会阻塞，超出队列容量的日志被丢弃
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		shipper.Write([]byte(`{"message":"x"}`))
+		time.Sleep(time.Millisecond)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("writes blocked for %v", elapsed)
+	}
+	if shipper.dropped.Load() == 0 {
+		t.Error("expected entries to be dropped when the queue is full")
+	}
+
+	close(release)
+	shipper.Close()
+
+	// 首批 2 条加上队列中最多 3 条，按批大小 2 推送
+	if got := batches.Load(); got < 2 || got > 3 {
+		t.Errorf("unexpected number of batches: %d", got)
+	}
+
+	// 关闭后写入的日志不再进入队列
+	if n, err := shipper.Write([]byte("late\n")); err != nil || n != 5 {
+		t.Errorf("write after close = %d, %v", n, err)
+	}
+}
+
+func TestNewShipperInvalidConfig(t *testing.T) {
+	cfg := newShippingConfig("http://localhost:3100/loki/api/v1/push")
+	cfg.Labels = nil
+	if _, err := NewShipper("loki", cfg); err == nil {
+		t.Error("loki output without labels should fail")
+	}
+
+	cfg = newShippingConfig("")
+	if _, err := NewShipper("http", cfg); err == nil {
+		t.Error("http output without url should fail")
+	}
+}
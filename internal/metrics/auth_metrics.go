@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Authentication operations recorded by AuthMetrics
+const (
+	AuthOperationLogin    = "login"
+	AuthOperationRegister = "register"
+	AuthOperationRefresh  = "refresh"
+	AuthOperationLogout   = "logout"
+)
+
+// Authentication outcomes recorded by AuthMetrics
+const (
+	AuthOutcomeSuccess            = "success"
+	AuthOutcomeInvalidCredentials = "invalid_credentials" // wrong username or password
+	AuthOutcomeInvalidToken       = "invalid_token"       // missing, expired, revoked or malformed token
+	AuthOutcomeInvalidRequest     = "invalid_request"     // validation or password policy failure
+	AuthOutcomeConflict           = "conflict"            // email or username already taken
+	AuthOutcomeError              = "error"               // server-side failure
+)
+
+// AuthAttempts is the number of authentication attempts of one operation with one outcome
+type AuthAttempts struct {
+	Operation string `json:"operation" example:"login"`
+	Outcome   string `json:"outcome" example:"success"`
+	Count     uint64 `json:"count"`
+}
+
+// authKey identifies an operation and outcome pair
+type authKey struct {
+	operation string
+	outcome   string
+}
+
+// AuthMetrics counts login, registration, token refresh and logout attempts by outcome, so
+// operators can spot credential stuffing and broken clients
+type AuthMetrics struct {
+	counts sync.Map // authKey -> *uint64
+}
+
+// NewAuthMetrics creates empty authentication metrics
+func NewAuthMetrics() *AuthMetrics {
+	return &AuthMetrics{}
+}
+
+// Record records an attempt of the given operation with the given outcome; nil metrics are ignored
+func (m *AuthMetrics) Record(operation, outcome string) {
+	if m == nil {
+		return
+	}
+	key := authKey{operation: operation, outcome: outcome}
+	count, ok := m.counts.Load(key)
+	if !ok {
+		count, _ = m.counts.LoadOrStore(key, new(uint64))
+	}
+	atomic.AddUint64(count.(*uint64), 1)
+}
+
+// Attempts returns the attempt counts ordered by operation and outcome
+func (m *AuthMetrics) Attempts() []AuthAttempts {
+	attempts := make([]AuthAttempts, 0)
+	m.counts.Range(func(k, v interface{}) bool {
+		key := k.(authKey)
+		attempts = append(attempts, AuthAttempts{
+			Operation: key.operation,
+			Outcome:   key.outcome,
+			Count:     atomic.LoadUint64(v.(*uint64)),
+		})
+		return true
+	})
+	sort.Slice(attempts, func(i, j int) bool {
+		if attempts[i].Operation != attempts[j].Operation {
+			return attempts[i].Operation < attempts[j].Operation
+		}
+		return attempts[i].Outcome < attempts[j].Outcome
+	})
+	return attempts
+}
+
+// writePrometheus writes the authentication attempt counters
+func (m *AuthMetrics) writePrometheus(p *promWriter) {
+	attempts := m.Attempts()
+	if len(attempts) == 0 {
+		return
+	}
+	p.header("auth_attempts_total", "Authentication attempts by operation (login, register, refresh, logout) and outcome.", "counter")
+	for _, a := range attempts {
+		p.sample("auth_attempts_total", float64(a.Count), "operation", a.Operation, "outcome", a.Outcome)
+	}
+}
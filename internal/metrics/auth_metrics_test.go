@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAuthMetrics(t *testing.T) {
+	authMetrics := NewAuthMetrics()
+	authMetrics.Record(AuthOperationLogin, AuthOutcomeSuccess)
+	authMetrics.Record(AuthOperationLogin, AuthOutcomeInvalidCredentials)
+	authMetrics.Record(AuthOperationLogin, AuthOutcomeInvalidCredentials)
+	authMetrics.Record(AuthOperationRefresh, AuthOutcomeInvalidToken)
+
+	attempts := authMetrics.Attempts()
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 series, got %d", len(attempts))
+	}
+	if a := attempts[0]; a.Operation != "login" || a.Outcome != "invalid_credentials" || a.Count != 2 {
+		t.Errorf("Unexpected failed login attempts: %+v", a)
+	}
+
+	registry := NewRegistry()
+	registry.RegisterAuth(authMetrics)
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	for _, line := range []string{
+		"# TYPE auth_attempts_total counter",
+		`auth_attempts_total{operation="login",outcome="invalid_credentials"} 2`,
+		`auth_attempts_total{operation="login",outcome="success"} 1`,
+		`auth_attempts_total{operation="refresh",outcome="invalid_token"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestAuthMetrics_NilIsNoop(t *testing.T) {
+	var authMetrics *AuthMetrics
+	authMetrics.Record(AuthOperationLogin, AuthOutcomeSuccess)
+}
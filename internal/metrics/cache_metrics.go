@@ -1,11 +1,19 @@
 package metrics
 
 import (
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// otherCachePrefix collects keys whose prefix is not tracked once maxPrefixes distinct prefixes are seen
+const otherCachePrefix = "other"
+
+// defaultMaxCachePrefixes bounds the per-prefix breakdown so that unusual key shapes can't grow it without limit
+const defaultMaxCachePrefixes = 64
+
 // CacheMetrics tracks cache performance statistics
 type CacheMetrics struct {
 	mu sync.RWMutex
@@ -14,6 +22,9 @@ type CacheMetrics struct {
 	hits   uint64
 	misses uint64
 
+	// Negative hits are hits on cached "not found" results, counted as part of hits
+	negativeHits uint64
+
 	// Operation counters
 	sets   uint64
 	gets   uint64
@@ -28,6 +39,16 @@ type CacheMetrics struct {
 	evictions uint64
 	errors    uint64
 
+	// Loads from the data source after a miss
+	loads       uint64
+	loadErrors  uint64
+	loadLatency *LatencyHistogram
+
+	// Per-key-prefix breakdown, map[string]*prefixCounters
+	prefixes    sync.Map
+	prefixCount int64
+	maxPrefixes int
+
 	// Operation history for recent performance analysis
 	operationHistory []CacheOperation
 	maxHistorySize   int
@@ -56,6 +77,7 @@ type CacheStats struct {
 	TotalRequests     uint64        `json:"total_requests"`
 	CacheHits         uint64        `json:"cache_hits"`
 	CacheMisses       uint64        `json:"cache_misses"`
+	NegativeHits      uint64        `json:"negative_hits"`
 	HitRate           float64       `json:"hit_rate"`
 	MissRate          float64       `json:"miss_rate"`
 	Sets              uint64        `json:"sets"`
@@ -66,27 +88,121 @@ type CacheStats struct {
 	AvgGetDuration    time.Duration `json:"avg_get_duration"`
 	AvgSetDuration    time.Duration `json:"avg_set_duration"`
 	AvgDeleteDuration time.Duration `json:"avg_delete_duration"`
+	Loads             uint64             `json:"loads"`
+	LoadErrors        uint64             `json:"load_errors"`
+	AvgLoadDuration   time.Duration      `json:"avg_load_duration"`
+	LoadLatency       HistogramSnapshot  `json:"load_latency"`
+	Prefixes          []CachePrefixStats `json:"prefixes,omitempty"`
 	RecentOperations  []CacheOperation `json:"recent_operations,omitempty"`
 }
 
+// CachePrefixStats represents the hit, miss and load counters for one key prefix
+type CachePrefixStats struct {
+	Prefix  string  `json:"prefix"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	Loads   uint64  `json:"loads"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// prefixCounters holds the counters of one key prefix
+type prefixCounters struct {
+	hits   uint64
+	misses uint64
+	loads  uint64
+}
+
 // NewCacheMetrics creates a new cache metrics instance
 func NewCacheMetrics() *CacheMetrics {
 	return &CacheMetrics{
 		maxHistorySize:   1000, // Keep last 1000 operations
 		operationHistory: make([]CacheOperation, 0),
+		loadLatency:      NewLatencyHistogram(),
+		maxPrefixes:      defaultMaxCachePrefixes,
 	}
 }
 
+// CacheKeyPrefix returns the prefix a key is grouped under in the per-prefix breakdown:
+// the first two colon-separated segments for keys with three or more segments, otherwise the first one.
+// A leading tenant scope ("tenant:<id>:") is skipped so that all tenants share the same prefixes
+func CacheKeyPrefix(key string) string {
+	if strings.HasPrefix(key, "tenant:") {
+		if i := strings.IndexByte(key[len("tenant:"):], ':'); i >= 0 {
+			key = key[len("tenant:")+i+1:]
+		}
+	}
+
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) == 3 {
+		return parts[0] + ":" + parts[1]
+	}
+	return parts[0]
+}
+
 // RecordHit records a cache hit
 func (cm *CacheMetrics) RecordHit() {
 	atomic.AddUint64(&cm.hits, 1)
 }
 
+// RecordNegativeHit records a hit on a cached "not found" result
+func (cm *CacheMetrics) RecordNegativeHit() {
+	atomic.AddUint64(&cm.hits, 1)
+	atomic.AddUint64(&cm.negativeHits, 1)
+}
+
 // RecordMiss records a cache miss
 func (cm *CacheMetrics) RecordMiss() {
 	atomic.AddUint64(&cm.misses, 1)
 }
 
+// RecordKeyHit records a cache hit and attributes it to the key's prefix
+func (cm *CacheMetrics) RecordKeyHit(key string) {
+	cm.RecordHit()
+	atomic.AddUint64(&cm.prefix(key).hits, 1)
+}
+
+// RecordKeyNegativeHit records a hit on a cached "not found" result and attributes it to the key's prefix
+func (cm *CacheMetrics) RecordKeyNegativeHit(key string) {
+	cm.RecordNegativeHit()
+	atomic.AddUint64(&cm.prefix(key).hits, 1)
+}
+
+// RecordKeyMiss records a cache miss and attributes it to the key's prefix
+func (cm *CacheMetrics) RecordKeyMiss(key string) {
+	cm.RecordMiss()
+	atomic.AddUint64(&cm.prefix(key).misses, 1)
+}
+
+// RecordLoad records a load from the data source after a cache miss
+func (cm *CacheMetrics) RecordLoad(key string, duration time.Duration, success bool) {
+	atomic.AddUint64(&cm.loads, 1)
+	if !success {
+		atomic.AddUint64(&cm.loadErrors, 1)
+	}
+	cm.loadLatency.Observe(duration)
+	atomic.AddUint64(&cm.prefix(key).loads, 1)
+}
+
+// prefix returns the counters for the key's prefix, creating them if needed
+// Once maxPrefixes prefixes are tracked, new prefixes are counted under "other"
+func (cm *CacheMetrics) prefix(key string) *prefixCounters {
+	name := CacheKeyPrefix(key)
+	if counters, ok := cm.prefixes.Load(name); ok {
+		return counters.(*prefixCounters)
+	}
+
+	if atomic.AddInt64(&cm.prefixCount, 1) > int64(cm.maxPrefixes) {
+		atomic.AddInt64(&cm.prefixCount, -1)
+		name = otherCachePrefix
+	}
+	counters, loaded := cm.prefixes.LoadOrStore(name, &prefixCounters{})
+	if loaded && name != otherCachePrefix {
+		// Another goroutine created the prefix first
+		atomic.AddInt64(&cm.prefixCount, -1)
+	}
+	return counters.(*prefixCounters)
+}
+
 // RecordSet records a cache set operation
 func (cm *CacheMetrics) RecordSet(key string, duration time.Duration, success bool) {
 	atomic.AddUint64(&cm.sets, 1)
@@ -111,9 +227,9 @@ func (cm *CacheMetrics) RecordGet(key string, duration time.Duration, hit bool,
 	atomic.AddInt64(&cm.totalGetDuration, int64(duration))
 
 	if hit {
-		cm.RecordHit()
+		cm.RecordKeyHit(key)
 	} else {
-		cm.RecordMiss()
+		cm.RecordKeyMiss(key)
 	}
 
 	if !success {
@@ -154,8 +270,21 @@ func (cm *CacheMetrics) RecordEviction() {
 
 // GetStats returns current cache statistics
 func (cm *CacheMetrics) GetStats() CacheStats {
+	stats := cm.Summary()
+
+	cm.mu.RLock()
+	stats.RecentOperations = make([]CacheOperation, len(cm.operationHistory))
+	copy(stats.RecentOperations, cm.operationHistory)
+	cm.mu.RUnlock()
+
+	return stats
+}
+
+// Summary returns current cache statistics without the recent operation history
+func (cm *CacheMetrics) Summary() CacheStats {
 	hits := atomic.LoadUint64(&cm.hits)
 	misses := atomic.LoadUint64(&cm.misses)
+	negativeHits := atomic.LoadUint64(&cm.negativeHits)
 	sets := atomic.LoadUint64(&cm.sets)
 	gets := atomic.LoadUint64(&cm.gets)
 	deletes := atomic.LoadUint64(&cm.deletes)
@@ -188,15 +317,14 @@ func (cm *CacheMetrics) GetStats() CacheStats {
 		avgDeleteDuration = time.Duration(totalDeleteDuration / int64(deletes))
 	}
 
-	cm.mu.RLock()
-	recentOps := make([]CacheOperation, len(cm.operationHistory))
-	copy(recentOps, cm.operationHistory)
-	cm.mu.RUnlock()
+	loads := atomic.LoadUint64(&cm.loads)
+	loadLatency := cm.loadLatency.Snapshot()
 
 	return CacheStats{
 		TotalRequests:     totalRequests,
 		CacheHits:         hits,
 		CacheMisses:       misses,
+		NegativeHits:      negativeHits,
 		HitRate:           hitRate,
 		MissRate:          missRate,
 		Sets:              sets,
@@ -207,14 +335,47 @@ func (cm *CacheMetrics) GetStats() CacheStats {
 		AvgGetDuration:    avgGetDuration,
 		AvgSetDuration:    avgSetDuration,
 		AvgDeleteDuration: avgDeleteDuration,
-		RecentOperations:  recentOps,
+		Loads:             loads,
+		LoadErrors:        atomic.LoadUint64(&cm.loadErrors),
+		AvgLoadDuration:   loadLatency.Mean,
+		LoadLatency:       loadLatency,
+		Prefixes:          cm.prefixStats(),
 	}
 }
 
+// prefixStats returns the per-prefix counters, busiest prefixes first
+func (cm *CacheMetrics) prefixStats() []CachePrefixStats {
+	var stats []CachePrefixStats
+	cm.prefixes.Range(func(key, value interface{}) bool {
+		counters := value.(*prefixCounters)
+		prefix := CachePrefixStats{
+			Prefix: key.(string),
+			Hits:   atomic.LoadUint64(&counters.hits),
+			Misses: atomic.LoadUint64(&counters.misses),
+			Loads:  atomic.LoadUint64(&counters.loads),
+		}
+		if total := prefix.Hits + prefix.Misses; total > 0 {
+			prefix.HitRate = float64(prefix.Hits) / float64(total) * 100
+		}
+		stats = append(stats, prefix)
+		return true
+	})
+
+	sort.Slice(stats, func(i, j int) bool {
+		ti, tj := stats[i].Hits+stats[i].Misses, stats[j].Hits+stats[j].Misses
+		if ti != tj {
+			return ti > tj
+		}
+		return stats[i].Prefix < stats[j].Prefix
+	})
+	return stats
+}
+
 // Reset resets all metrics
 func (cm *CacheMetrics) Reset() {
 	atomic.StoreUint64(&cm.hits, 0)
 	atomic.StoreUint64(&cm.misses, 0)
+	atomic.StoreUint64(&cm.negativeHits, 0)
 	atomic.StoreUint64(&cm.sets, 0)
 	atomic.StoreUint64(&cm.gets, 0)
 	atomic.StoreUint64(&cm.deletes, 0)
@@ -223,6 +384,15 @@ func (cm *CacheMetrics) Reset() {
 	atomic.StoreInt64(&cm.totalGetDuration, 0)
 	atomic.StoreInt64(&cm.totalSetDuration, 0)
 	atomic.StoreInt64(&cm.totalDeleteDuration, 0)
+	atomic.StoreUint64(&cm.loads, 0)
+	atomic.StoreUint64(&cm.loadErrors, 0)
+	cm.loadLatency.Reset()
+
+	cm.prefixes.Range(func(key, _ interface{}) bool {
+		cm.prefixes.Delete(key)
+		return true
+	})
+	atomic.StoreInt64(&cm.prefixCount, 0)
 
 	cm.mu.Lock()
 	cm.operationHistory = make([]CacheOperation, 0)
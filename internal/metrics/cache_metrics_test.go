@@ -21,6 +21,30 @@ func TestNewCacheMetrics(t *testing.T) {
 	}
 }
 
+func TestRecordNegativeHit(t *testing.T) {
+	cm := NewCacheMetrics()
+
+	cm.RecordHit()
+	cm.RecordNegativeHit()
+	cm.RecordMiss()
+
+	stats := cm.GetStats()
+	if stats.CacheHits != 2 {
+		t.Errorf("Expected negative hits to count as hits, got %d hits", stats.CacheHits)
+	}
+	if stats.NegativeHits != 1 {
+		t.Errorf("Expected 1 negative hit, got %d", stats.NegativeHits)
+	}
+	if stats.CacheMisses != 1 {
+		t.Errorf("Expected 1 miss, got %d", stats.CacheMisses)
+	}
+
+	cm.Reset()
+	if cm.GetStats().NegativeHits != 0 {
+		t.Error("Expected negative hits to be 0 after reset")
+	}
+}
+
 func TestRecordHitMiss(t *testing.T) {
 	cm := NewCacheMetrics()
 
@@ -499,4 +523,93 @@ func BenchmarkGetStats(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		cm.GetStats()
 	}
-}
\ No newline at end of file
+}
+func TestCacheKeyPrefix(t *testing.T) {
+	tests := map[string]string{
+		"user:id:123":               "user:id",
+		"users:all:0:10":            "users:all",
+		"users:count":               "users",
+		"session":                   "session",
+		"tenant:acme:user:email:x":  "user:email",
+		"tenant:acme:users:count":   "users",
+		"user:exists:email:a@b.com": "user:exists",
+	}
+
+	for key, expected := range tests {
+		if prefix := CacheKeyPrefix(key); prefix != expected {
+			t.Errorf("CacheKeyPrefix(%q) = %q, expected %q", key, prefix, expected)
+		}
+	}
+}
+
+func TestRecordLoadAndPrefixes(t *testing.T) {
+	cm := NewCacheMetrics()
+
+	cm.RecordKeyHit("user:id:1")
+	cm.RecordKeyHit("user:id:2")
+	cm.RecordKeyNegativeHit("user:id:3")
+	cm.RecordKeyMiss("user:id:4")
+	cm.RecordKeyMiss("users:count")
+	cm.RecordLoad("user:id:4", 20*time.Millisecond, true)
+	cm.RecordLoad("users:count", 40*time.Millisecond, false)
+
+	stats := cm.GetStats()
+	if stats.CacheHits != 3 || stats.NegativeHits != 1 || stats.CacheMisses != 2 {
+		t.Errorf("Unexpected hit/miss counters: %+v", stats)
+	}
+	if stats.Loads != 2 {
+		t.Errorf("Expected 2 loads, got %d", stats.Loads)
+	}
+	if stats.LoadErrors != 1 {
+		t.Errorf("Expected 1 load error, got %d", stats.LoadErrors)
+	}
+	if stats.AvgLoadDuration != 30*time.Millisecond {
+		t.Errorf("Expected average load duration 30ms, got %v", stats.AvgLoadDuration)
+	}
+	if stats.LoadLatency.Count != 2 {
+		t.Errorf("Expected 2 load latency observations, got %d", stats.LoadLatency.Count)
+	}
+
+	if len(stats.Prefixes) != 2 {
+		t.Fatalf("Expected 2 prefixes, got %d", len(stats.Prefixes))
+	}
+	user := stats.Prefixes[0]
+	if user.Prefix != "user:id" || user.Hits != 3 || user.Misses != 1 || user.Loads != 1 || user.HitRate != 75 {
+		t.Errorf("Unexpected stats for user:id prefix: %+v", user)
+	}
+	count := stats.Prefixes[1]
+	if count.Prefix != "users" || count.Hits != 0 || count.Misses != 1 || count.Loads != 1 {
+		t.Errorf("Unexpected stats for users prefix: %+v", count)
+	}
+
+	cm.Reset()
+	stats = cm.GetStats()
+	if stats.Loads != 0 || stats.LoadLatency.Count != 0 || len(stats.Prefixes) != 0 {
+		t.Errorf("Expected loads and prefixes to be cleared after reset: %+v", stats)
+	}
+}
+
+func TestPrefixOverflow(t *testing.T) {
+	cm := NewCacheMetrics()
+	cm.maxPrefixes = 2
+
+	cm.RecordKeyMiss("a:1:x")
+	cm.RecordKeyMiss("b:1:x")
+	cm.RecordKeyMiss("c:1:x")
+	cm.RecordKeyMiss("d:1:x")
+	cm.RecordKeyHit("a:1:y")
+
+	prefixes := map[string]CachePrefixStats{}
+	for _, prefix := range cm.GetStats().Prefixes {
+		prefixes[prefix.Prefix] = prefix
+	}
+	if len(prefixes) != 3 {
+		t.Fatalf("Expected 2 tracked prefixes and the overflow bucket, got %v", prefixes)
+	}
+	if prefixes["a:1"].Hits != 1 || prefixes["a:1"].Misses != 1 {
+		t.Errorf("Expected tracked prefixes to keep counting, got %+v", prefixes["a:1"])
+	}
+	if prefixes[otherCachePrefix].Misses != 2 {
+		t.Errorf("Expected new prefixes to be counted under %q, got %+v", otherCachePrefix, prefixes[otherCachePrefix])
+	}
+}
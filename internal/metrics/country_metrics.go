@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// UnknownCountry is the country label of requests whose client IP could not be located
+const UnknownCountry = "unknown"
+
+// otherCountry collects requests of new countries once maxCountries countries are tracked
+const otherCountry = "other"
+
+// defaultMaxCountries bounds the number of per-country series; ISO 3166-1 has fewer codes
+const defaultMaxCountries = 300
+
+// countryCounters holds the traffic counters of one country
+type countryCounters struct {
+	requests      uint64
+	clientErrors  uint64
+	serverErrors  uint64
+	responseBytes uint64
+}
+
+// CountryStats represents the traffic of clients located in one country
+type CountryStats struct {
+	Country       string `json:"country" example:"US"`
+	Requests      uint64 `json:"requests"`
+	ClientErrors  uint64 `json:"client_errors"`
+	ServerErrors  uint64 `json:"server_errors"`
+	ResponseBytes uint64 `json:"response_bytes"`
+}
+
+// RecordCountry records a completed request from a client in country (an ISO 3166-1 code)
+// An empty country is counted as "unknown"
+func (hm *HTTPMetrics) RecordCountry(country string, status int, responseBytes int64) {
+	counters := hm.countryFor(country)
+	atomic.AddUint64(&counters.requests, 1)
+	switch {
+	case status >= 500:
+		atomic.AddUint64(&counters.serverErrors, 1)
+	case status >= 400:
+		atomic.AddUint64(&counters.clientErrors, 1)
+	}
+	if responseBytes > 0 {
+		atomic.AddUint64(&counters.responseBytes, uint64(responseBytes))
+	}
+}
+
+// countryFor returns the counters of the country, creating them if needed
+// Once maxCountries countries are tracked, new countries are counted under "other"
+func (hm *HTTPMetrics) countryFor(country string) *countryCounters {
+	if country == "" {
+		country = UnknownCountry
+	}
+	if counters, ok := hm.countries.Load(country); ok {
+		return counters.(*countryCounters)
+	}
+
+	if atomic.AddInt64(&hm.countryCount, 1) > int64(hm.maxCountries) {
+		atomic.AddInt64(&hm.countryCount, -1)
+		country = otherCountry
+		if counters, ok := hm.countries.Load(country); ok {
+			return counters.(*countryCounters)
+		}
+	}
+	counters, loaded := hm.countries.LoadOrStore(country, &countryCounters{})
+	if loaded && country != otherCountry {
+		// Another goroutine created the counters first
+		atomic.AddInt64(&hm.countryCount, -1)
+	}
+	return counters.(*countryCounters)
+}
+
+// GetCountryStats returns per-country traffic, busiest country first
+func (hm *HTTPMetrics) GetCountryStats() []CountryStats {
+	stats := make([]CountryStats, 0)
+	hm.countries.Range(func(k, v interface{}) bool {
+		counters := v.(*countryCounters)
+		stats = append(stats, CountryStats{
+			Country:       k.(string),
+			Requests:      atomic.LoadUint64(&counters.requests),
+			ClientErrors:  atomic.LoadUint64(&counters.clientErrors),
+			ServerErrors:  atomic.LoadUint64(&counters.serverErrors),
+			ResponseBytes: atomic.LoadUint64(&counters.responseBytes),
+		})
+		return true
+	})
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Requests != stats[j].Requests {
+			return stats[i].Requests > stats[j].Requests
+		}
+		return stats[i].Country < stats[j].Country
+	})
+	return stats
+}
+
+// resetCountries clears all per-country counters
+func (hm *HTTPMetrics) resetCountries() {
+	hm.countries.Range(func(key, _ interface{}) bool {
+		hm.countries.Delete(key)
+		return true
+	})
+	atomic.StoreInt64(&hm.countryCount, 0)
+}
+
+// writeCountryPrometheus writes the per-country request counter; nothing is written until a country is recorded
+func (hm *HTTPMetrics) writeCountryPrometheus(p *promWriter) {
+	stats := hm.GetCountryStats()
+	if len(stats) == 0 {
+		return
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Country < stats[j].Country
+	})
+
+	p.header("http_requests_by_country_total", "HTTP requests by client country.", "counter")
+	for _, s := range stats {
+		p.sample("http_requests_by_country_total", float64(s.Requests), "country", s.Country)
+	}
+}
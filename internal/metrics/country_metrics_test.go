@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMetrics_RecordCountry(t *testing.T) {
+	hm := NewHTTPMetrics()
+	hm.RecordCountry("US", 200, 100)
+	hm.RecordCountry("US", 404, 20)
+	hm.RecordCountry("US", 503, 0)
+	hm.RecordCountry("DE", 200, 50)
+	hm.RecordCountry("", 200, 10)
+
+	stats := hm.GetCountryStats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 countries, got %+v", stats)
+	}
+	expected := CountryStats{Country: "US", Requests: 3, ClientErrors: 1, ServerErrors: 1, ResponseBytes: 120}
+	if stats[0] != expected {
+		t.Errorf("Expected busiest country %+v, got %+v", expected, stats[0])
+	}
+	if stats[1].Country != "DE" || stats[2].Country != UnknownCountry {
+		t.Errorf("Expected DE then %s ordered by name on ties, got %+v", UnknownCountry, stats[1:])
+	}
+
+	hm.Reset()
+	if stats := hm.GetCountryStats(); len(stats) != 0 {
+		t.Errorf("Expected reset to clear countries, got %+v", stats)
+	}
+}
+
+func TestHTTPMetrics_CountryOverflow(t *testing.T) {
+	hm := NewHTTPMetrics()
+	hm.maxCountries = 2
+
+	for _, country := range []string{"US", "DE", "FR", "JP", "US"} {
+		hm.RecordCountry(country, 200, 0)
+	}
+
+	countries := map[string]uint64{}
+	for _, s := range hm.GetCountryStats() {
+		countries[s.Country] = s.Requests
+	}
+	expected := map[string]uint64{"US": 2, "DE": 1, otherCountry: 2}
+	if len(countries) != len(expected) {
+		t.Fatalf("Expected countries %v, got %v", expected, countries)
+	}
+	for country, count := range expected {
+		if countries[country] != count {
+			t.Errorf("Expected %d requests for %s, got %d", count, country, countries[country])
+		}
+	}
+}
+
+func TestRegistryWritePrometheus_Countries(t *testing.T) {
+	hm := NewHTTPMetrics()
+	registry := NewRegistry()
+	registry.RegisterHTTP(hm)
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "http_requests_by_country_total") {
+		t.Errorf("Expected no country family before any country is recorded, got:\n%s", buf.String())
+	}
+
+	hm.RecordCountry("US", 200, 0)
+	hm.RecordCountry("DE", 200, 0)
+	hm.RecordCountry("US", 200, 0)
+	buf.Reset()
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	output := buf.String()
+	for _, line := range []string{
+		"# TYPE http_requests_by_country_total counter",
+		`http_requests_by_country_total{country="DE"} 1`,
+		`http_requests_by_country_total{country="US"} 2`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
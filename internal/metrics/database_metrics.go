@@ -32,6 +32,7 @@ type DatabaseMetrics struct {
 
 	// Slow query tracking
 	slowQueryThreshold time.Duration
+	logSlowQueries     bool
 	lastSlowQuery      time.Time
 	slowQueryHistory   []SlowQueryEntry
 	maxSlowQueryHistory int
@@ -43,6 +44,9 @@ type DatabaseMetrics struct {
 	// Error tracking
 	errors []QueryError
 	maxErrorHistory int
+
+	// Latency histograms per query type
+	latencyHistograms map[QueryType]*LatencyHistogram
 }
 
 // QueryEntry represents a single database query operation
@@ -123,13 +127,24 @@ const (
 func NewDatabaseMetrics() *DatabaseMetrics {
 	return &DatabaseMetrics{
 		slowQueryThreshold:   DefaultSlowQueryThreshold,
+		logSlowQueries:       true,
 		maxHistorySize:       DefaultMaxHistorySize,
 		maxSlowQueryHistory:  DefaultMaxSlowQueryHistory,
 		maxErrorHistory:      DefaultMaxErrorHistory,
 		queryHistory:         make([]QueryEntry, 0),
 		slowQueryHistory:     make([]SlowQueryEntry, 0),
 		errors:               make([]QueryError, 0),
+		latencyHistograms:    newQueryTypeHistograms(),
+	}
+}
+
+// newQueryTypeHistograms creates one latency histogram for each query type
+func newQueryTypeHistograms() map[QueryType]*LatencyHistogram {
+	histograms := make(map[QueryType]*LatencyHistogram)
+	for _, queryType := range []QueryType{QueryTypeSelect, QueryTypeInsert, QueryTypeUpdate, QueryTypeDelete, QueryTypeDDL, QueryTypeOther} {
+		histograms[queryType] = NewLatencyHistogram()
 	}
+	return histograms
 }
 
 // SetSlowQueryThreshold sets the threshold for considering a query as slow
@@ -137,6 +152,12 @@ func (dm *DatabaseMetrics) SetSlowQueryThreshold(threshold time.Duration) {
 	dm.slowQueryThreshold = threshold
 }
 
+// SetSlowQueryLogging enables or disables logging of slow queries.
+// Slow queries are still counted and kept in history when logging is disabled.
+func (dm *DatabaseMetrics) SetSlowQueryLogging(enabled bool) {
+	dm.logSlowQueries = enabled
+}
+
 // SetMaxHistorySize sets the maximum number of queries to keep in history
 func (dm *DatabaseMetrics) SetMaxHistorySize(size int) {
 	dm.mu.Lock()
@@ -171,6 +192,13 @@ func (dm *DatabaseMetrics) RecordQuery(queryType QueryType, query string, durati
 		atomic.AddUint64(&dm.otherQueries, 1)
 	}
 
+	// Record latency distribution
+	if histogram, ok := dm.latencyHistograms[queryType]; ok {
+		histogram.Observe(duration)
+	} else {
+		dm.latencyHistograms[QueryTypeOther].Observe(duration)
+	}
+
 	// Update min/max durations
 	maxDuration := atomic.LoadInt64(&dm.maxQueryDuration)
 	if duration > time.Duration(maxDuration) {
@@ -193,7 +221,9 @@ func (dm *DatabaseMetrics) RecordQuery(queryType QueryType, query string, durati
 		dm.mu.Unlock()
 		
 		// Log slow query for optimization review (REQ-DB-003)
-		dm.logSlowQuery(queryType, query, duration, parameters, err, rowsAffected)
+		if dm.logSlowQueries {
+			dm.logSlowQuery(queryType, query, duration, parameters, err, rowsAffected)
+		}
 		dm.recordSlowQuery(queryType, query, duration, parameters, err, rowsAffected)
 	}
 
@@ -489,6 +519,15 @@ func (dm *DatabaseMetrics) GetErrorRate() float64 {
 	return float64(failedQueries) / float64(totalQueries) * 100
 }
 
+// GetLatencyHistograms returns latency histogram snapshots keyed by query type name
+func (dm *DatabaseMetrics) GetLatencyHistograms() map[string]HistogramSnapshot {
+	snapshots := make(map[string]HistogramSnapshot, len(dm.latencyHistograms))
+	for queryType, histogram := range dm.latencyHistograms {
+		snapshots[dm.getQueryTypeName(queryType)] = histogram.Snapshot()
+	}
+	return snapshots
+}
+
 // GetTotalQueries returns the total number of queries
 func (dm *DatabaseMetrics) GetTotalQueries() uint64 {
 	return atomic.LoadUint64(&dm.totalQueries)
@@ -508,6 +547,9 @@ func (dm *DatabaseMetrics) Reset() {
 	atomic.StoreInt64(&dm.totalQueryDuration, 0)
 	atomic.StoreInt64(&dm.maxQueryDuration, 0)
 	atomic.StoreInt64(&dm.minQueryDuration, 0)
+	for _, histogram := range dm.latencyHistograms {
+		histogram.Reset()
+	}
 	// Clear last slow query timestamp
 	dm.mu.Lock()
 	dm.lastSlowQuery = time.Time{}
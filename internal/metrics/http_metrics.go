@@ -0,0 +1,272 @@
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UnmatchedRoute is the route label of requests that matched no registered route
+const UnmatchedRoute = "unmatched"
+
+// otherHTTPRoute collects requests of new routes once maxSeries series are tracked
+const otherHTTPRoute = "other"
+
+// defaultMaxHTTPSeries bounds the number of method/route/status series
+const defaultMaxHTTPSeries = 1000
+
+// HTTPMetrics tracks HTTP server latency, request/response sizes and in-flight requests,
+// broken down by method, route template and status code
+type HTTPMetrics struct {
+	inFlight     int64
+	peakInFlight int64
+	total        uint64
+
+	// map[httpSeriesKey]*httpSeries
+	series      sync.Map
+	seriesCount int64
+	maxSeries   int
+
+	// map[string]*countryCounters, recorded when GeoIP lookup is enabled
+	countries    sync.Map
+	countryCount int64
+	maxCountries int
+}
+
+// httpSeriesKey identifies one method/route/status series
+type httpSeriesKey struct {
+	method string
+	route  string
+	status int
+}
+
+// httpSeries holds the counters of one method/route/status series
+type httpSeries struct {
+	latency       *LatencyHistogram
+	requestBytes  uint64
+	responseBytes uint64
+	panics        uint64
+}
+
+// HTTPStats represents aggregated HTTP server statistics
+type HTTPStats struct {
+	InFlight      int64            `json:"in_flight"`
+	PeakInFlight  int64            `json:"peak_in_flight"`
+	TotalRequests uint64           `json:"total_requests"`
+	TotalPanics   uint64           `json:"total_panics"`
+	Routes        []HTTPRouteStats `json:"routes"`
+}
+
+// HTTPRouteStats represents the statistics of one method/route/status series
+type HTTPRouteStats struct {
+	Method        string            `json:"method"`
+	Route         string            `json:"route"`
+	Status        int               `json:"status"`
+	Requests      uint64            `json:"requests"`
+	RequestBytes  uint64            `json:"request_bytes"`
+	ResponseBytes uint64            `json:"response_bytes"`
+	Panics        uint64            `json:"panics,omitempty"` // panics recovered by the recovery middleware
+	AvgDuration   time.Duration     `json:"avg_duration"`
+	Latency       HistogramSnapshot `json:"latency"`
+}
+
+// knownHTTPMethods are reported as-is; any other method is reported as "OTHER"
+var knownHTTPMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "OPTIONS": true, "CONNECT": true, "TRACE": true,
+}
+
+// NewHTTPMetrics creates a new HTTP metrics instance
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		maxSeries:    defaultMaxHTTPSeries,
+		maxCountries: defaultMaxCountries,
+	}
+}
+
+// RequestStarted records a request entering the handler chain
+func (hm *HTTPMetrics) RequestStarted() {
+	current := atomic.AddInt64(&hm.inFlight, 1)
+	for {
+		peak := atomic.LoadInt64(&hm.peakInFlight)
+		if current <= peak || atomic.CompareAndSwapInt64(&hm.peakInFlight, peak, current) {
+			return
+		}
+	}
+}
+
+// RequestFinished records a completed request
+// route is the matched route template (e.g. /api/v1/users/:id), or empty if no route matched
+func (hm *HTTPMetrics) RequestFinished(method, route string, status int, duration time.Duration, requestBytes, responseBytes int64) {
+	atomic.AddInt64(&hm.inFlight, -1)
+	atomic.AddUint64(&hm.total, 1)
+
+	series := hm.seriesFor(method, route, status)
+	series.latency.Observe(duration)
+	if requestBytes > 0 {
+		atomic.AddUint64(&series.requestBytes, uint64(requestBytes))
+	}
+	if responseBytes > 0 {
+		atomic.AddUint64(&series.responseBytes, uint64(responseBytes))
+	}
+}
+
+// RecordPanic records a panic recovered while handling a request; the request itself is recorded
+// by RequestFinished with status 500
+func (hm *HTTPMetrics) RecordPanic(method, route string) {
+	atomic.AddUint64(&hm.seriesFor(method, route, http.StatusInternalServerError).panics, 1)
+}
+
+// seriesFor returns the series for the request, creating it if needed
+// Once maxSeries series are tracked, new series are counted under the "other" route
+func (hm *HTTPMetrics) seriesFor(method, route string, status int) *httpSeries {
+	if !knownHTTPMethods[method] {
+		method = "OTHER"
+	}
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	key := httpSeriesKey{method: method, route: route, status: status}
+	if series, ok := hm.series.Load(key); ok {
+		return series.(*httpSeries)
+	}
+
+	if atomic.AddInt64(&hm.seriesCount, 1) > int64(hm.maxSeries) {
+		atomic.AddInt64(&hm.seriesCount, -1)
+		key = httpSeriesKey{method: method, route: otherHTTPRoute, status: status}
+		if series, ok := hm.series.Load(key); ok {
+			return series.(*httpSeries)
+		}
+	}
+	series, loaded := hm.series.LoadOrStore(key, &httpSeries{latency: NewLatencyHistogram()})
+	if loaded && key.route != otherHTTPRoute {
+		// Another goroutine created the series first
+		atomic.AddInt64(&hm.seriesCount, -1)
+	}
+	return series.(*httpSeries)
+}
+
+// InFlight returns the number of requests currently being handled
+func (hm *HTTPMetrics) InFlight() int64 {
+	return atomic.LoadInt64(&hm.inFlight)
+}
+
+// GetStats returns current HTTP statistics, busiest series first
+func (hm *HTTPMetrics) GetStats() HTTPStats {
+	stats := HTTPStats{
+		InFlight:      atomic.LoadInt64(&hm.inFlight),
+		PeakInFlight:  atomic.LoadInt64(&hm.peakInFlight),
+		TotalRequests: atomic.LoadUint64(&hm.total),
+		Routes:        make([]HTTPRouteStats, 0),
+	}
+
+	hm.series.Range(func(k, v interface{}) bool {
+		key := k.(httpSeriesKey)
+		series := v.(*httpSeries)
+		latency := series.latency.Snapshot()
+		panics := atomic.LoadUint64(&series.panics)
+		stats.TotalPanics += panics
+		stats.Routes = append(stats.Routes, HTTPRouteStats{
+			Method:        key.method,
+			Route:         key.route,
+			Status:        key.status,
+			Requests:      latency.Count,
+			RequestBytes:  atomic.LoadUint64(&series.requestBytes),
+			ResponseBytes: atomic.LoadUint64(&series.responseBytes),
+			Panics:        panics,
+			AvgDuration:   latency.Mean,
+			Latency:       latency,
+		})
+		return true
+	})
+
+	sort.Slice(stats.Routes, func(i, j int) bool {
+		a, b := stats.Routes[i], stats.Routes[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+	return stats
+}
+
+// Reset clears all recorded requests; the in-flight gauge is kept since those requests are still running
+func (hm *HTTPMetrics) Reset() {
+	atomic.StoreUint64(&hm.total, 0)
+	atomic.StoreInt64(&hm.peakInFlight, atomic.LoadInt64(&hm.inFlight))
+	hm.series.Range(func(key, _ interface{}) bool {
+		hm.series.Delete(key)
+		return true
+	})
+	atomic.StoreInt64(&hm.seriesCount, 0)
+	hm.resetCountries()
+}
+
+// writePrometheus writes the HTTP metric families
+func (hm *HTTPMetrics) writePrometheus(p *promWriter) {
+	type entry struct {
+		key    httpSeriesKey
+		series *httpSeries
+	}
+	var entries []entry
+	hm.series.Range(func(k, v interface{}) bool {
+		entries = append(entries, entry{key: k.(httpSeriesKey), series: v.(*httpSeries)})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].key, entries[j].key
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	labels := func(key httpSeriesKey) []string {
+		return []string{"method", key.method, "route", key.route, "status", strconv.Itoa(key.status)}
+	}
+
+	p.header("http_requests_in_flight", "HTTP requests currently being handled.", "gauge")
+	p.sample("http_requests_in_flight", float64(atomic.LoadInt64(&hm.inFlight)))
+
+	p.header("http_requests_total", "HTTP requests by method, route and status.", "counter")
+	for _, e := range entries {
+		p.sample("http_requests_total", float64(atomic.LoadUint64(&e.series.latency.count)), labels(e.key)...)
+	}
+
+	p.header("http_request_duration_seconds", "HTTP request latency by method, route and status.", "histogram")
+	for _, e := range entries {
+		e.series.latency.writePrometheus(p, "http_request_duration_seconds", labels(e.key)...)
+	}
+
+	p.header("http_request_size_bytes_total", "HTTP request body bytes by method, route and status.", "counter")
+	for _, e := range entries {
+		p.sample("http_request_size_bytes_total", float64(atomic.LoadUint64(&e.series.requestBytes)), labels(e.key)...)
+	}
+
+	p.header("http_response_size_bytes_total", "HTTP response body bytes by method, route and status.", "counter")
+	for _, e := range entries {
+		p.sample("http_response_size_bytes_total", float64(atomic.LoadUint64(&e.series.responseBytes)), labels(e.key)...)
+	}
+
+	p.header("http_panics_total", "Panics recovered while handling HTTP requests, by method and route.", "counter")
+	for _, e := range entries {
+		if panics := atomic.LoadUint64(&e.series.panics); panics > 0 {
+			p.sample("http_panics_total", float64(panics), "method", e.key.method, "route", e.key.route)
+		}
+	}
+
+	hm.writeCountryPrometheus(p)
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHTTPMetrics_RecordRequests(t *testing.T) {
+	hm := NewHTTPMetrics()
+
+	hm.RequestStarted()
+	hm.RequestStarted()
+	if hm.InFlight() != 2 {
+		t.Errorf("Expected 2 in-flight requests, got %d", hm.InFlight())
+	}
+
+	hm.RequestFinished("GET", "/api/v1/users/:id", 200, 10*time.Millisecond, -1, 512)
+	hm.RequestFinished("GET", "/api/v1/users/:id", 200, 30*time.Millisecond, 0, 256)
+	hm.RequestStarted()
+	hm.RequestFinished("POST", "/api/v1/users", 201, 50*time.Millisecond, 128, 64)
+	hm.RequestStarted()
+	hm.RequestFinished("BREW", "", 404, time.Millisecond, 0, 0)
+
+	stats := hm.GetStats()
+	if stats.InFlight != 0 {
+		t.Errorf("Expected no in-flight requests, got %d", stats.InFlight)
+	}
+	if stats.PeakInFlight != 2 {
+		t.Errorf("Expected peak of 2 in-flight requests, got %d", stats.PeakInFlight)
+	}
+	if stats.TotalRequests != 4 {
+		t.Errorf("Expected 4 requests, got %d", stats.TotalRequests)
+	}
+	if len(stats.Routes) != 3 {
+		t.Fatalf("Expected 3 series, got %d", len(stats.Routes))
+	}
+
+	get := stats.Routes[0]
+	if get.Method != "GET" || get.Route != "/api/v1/users/:id" || get.Status != 200 {
+		t.Errorf("Expected the busiest series first, got %+v", get)
+	}
+	if get.Requests != 2 || get.ResponseBytes != 768 || get.RequestBytes != 0 {
+		t.Errorf("Unexpected counters for GET series: %+v", get)
+	}
+	if get.AvgDuration != 20*time.Millisecond {
+		t.Errorf("Expected average duration 20ms, got %v", get.AvgDuration)
+	}
+
+	var unmatched *HTTPRouteStats
+	for i := range stats.Routes {
+		if stats.Routes[i].Route == UnmatchedRoute {
+			unmatched = &stats.Routes[i]
+		}
+	}
+	if unmatched == nil || unmatched.Method != "OTHER" || unmatched.Status != 404 {
+		t.Errorf("Expected unknown methods and unmatched routes to be normalized, got %+v", unmatched)
+	}
+
+	hm.Reset()
+	stats = hm.GetStats()
+	if stats.TotalRequests != 0 || len(stats.Routes) != 0 {
+		t.Errorf("Expected reset to clear requests, got %+v", stats)
+	}
+}
+
+func TestHTTPMetrics_SeriesOverflow(t *testing.T) {
+	hm := NewHTTPMetrics()
+	hm.maxSeries = 2
+
+	for _, route := range []string{"/a", "/b", "/c", "/d", "/a"} {
+		hm.RequestStarted()
+		hm.RequestFinished("GET", route, 200, time.Millisecond, 0, 0)
+	}
+
+	routes := map[string]uint64{}
+	for _, series := range hm.GetStats().Routes {
+		routes[series.Route] = series.Requests
+	}
+	expected := map[string]uint64{"/a": 2, "/b": 1, otherHTTPRoute: 2}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected series %v, got %v", expected, routes)
+	}
+	for route, count := range expected {
+		if routes[route] != count {
+			t.Errorf("Expected %d requests for %s, got %d", count, route, routes[route])
+		}
+	}
+}
+
+func TestHTTPMetrics_Concurrent(t *testing.T) {
+	hm := NewHTTPMetrics()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				hm.RequestStarted()
+				hm.RequestFinished("GET", "/api/v1/users", 200, time.Millisecond, 0, 10)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := hm.GetStats()
+	if stats.TotalRequests != 5000 || stats.InFlight != 0 {
+		t.Errorf("Unexpected stats after concurrent requests: total=%d in_flight=%d", stats.TotalRequests, stats.InFlight)
+	}
+	if len(stats.Routes) != 1 || stats.Routes[0].ResponseBytes != 50000 {
+		t.Errorf("Expected a single series with 50000 response bytes, got %+v", stats.Routes)
+	}
+}
+
+func TestRegistryWritePrometheus_HTTP(t *testing.T) {
+	hm := NewHTTPMetrics()
+	hm.RequestStarted()
+	hm.RequestFinished("GET", "/api/v1/users/:id", 200, 2*time.Millisecond, 0, 100)
+	hm.RequestStarted()
+	hm.RecordPanic("POST", "/api/v1/users")
+
+	registry := NewRegistry()
+	registry.RegisterHTTP(hm)
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	output := buf.String()
+
+	expected := []string{
+		"# TYPE http_requests_in_flight gauge",
+		"http_requests_in_flight 1",
+		`http_requests_total{method="GET",route="/api/v1/users/:id",status="200"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/:id",status="200",le="0.001"} 0`,
+		`http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/:id",status="200",le="0.005"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/api/v1/users/:id",status="200"} 1`,
+		`http_response_size_bytes_total{method="GET",route="/api/v1/users/:id",status="200"} 100`,
+		"# TYPE http_panics_total counter",
+		`http_panics_total{method="POST",route="/api/v1/users"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
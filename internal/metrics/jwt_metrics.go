@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// JWTKeyVerifications is the number of tokens verified with one signing key
+type JWTKeyVerifications struct {
+	KeyID string `json:"kid" example:"default"`
+	State string `json:"state" example:"active"` // active, previous or retired
+	Count uint64 `json:"count"`
+}
+
+// jwtKey identifies a signing key in a given state
+type jwtKey struct {
+	kid   string
+	state string
+}
+
+// JWTMetrics counts verified tokens by the signing key that verified them, so operators can
+// tell when tokens signed with a replaced key are no longer in use
+type JWTMetrics struct {
+	counts sync.Map // jwtKey -> *uint64
+}
+
+// NewJWTMetrics creates empty JWT metrics
+func NewJWTMetrics() *JWTMetrics {
+	return &JWTMetrics{}
+}
+
+// RecordVerification records a token verified with the key kid in the given state
+func (m *JWTMetrics) RecordVerification(kid, state string) {
+	key := jwtKey{kid: kid, state: state}
+	count, ok := m.counts.Load(key)
+	if !ok {
+		count, _ = m.counts.LoadOrStore(key, new(uint64))
+	}
+	atomic.AddUint64(count.(*uint64), 1)
+}
+
+// Verifications returns the verification counts ordered by key ID and state
+func (m *JWTMetrics) Verifications() []JWTKeyVerifications {
+	verifications := make([]JWTKeyVerifications, 0)
+	m.counts.Range(func(k, v interface{}) bool {
+		key := k.(jwtKey)
+		verifications = append(verifications, JWTKeyVerifications{
+			KeyID: key.kid,
+			State: key.state,
+			Count: atomic.LoadUint64(v.(*uint64)),
+		})
+		return true
+	})
+	sort.Slice(verifications, func(i, j int) bool {
+		if verifications[i].KeyID != verifications[j].KeyID {
+			return verifications[i].KeyID < verifications[j].KeyID
+		}
+		return verifications[i].State < verifications[j].State
+	})
+	return verifications
+}
+
+// writePrometheus writes the JWT verification counters
+func (m *JWTMetrics) writePrometheus(p *promWriter) {
+	verifications := m.Verifications()
+	if len(verifications) == 0 {
+		return
+	}
+	p.header("jwt_verifications_total", "Tokens verified by signing key; state is active, previous or retired (replaced by a hot reload and still accepted during the grace period).", "counter")
+	for _, v := range verifications {
+		p.sample("jwt_verifications_total", float64(v.Count), "kid", v.KeyID, "state", v.State)
+	}
+}
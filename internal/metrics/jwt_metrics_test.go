@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJWTMetrics(t *testing.T) {
+	jwtMetrics := NewJWTMetrics()
+	jwtMetrics.RecordVerification("default", "retired")
+	jwtMetrics.RecordVerification("default", "active")
+	jwtMetrics.RecordVerification("default", "active")
+
+	verifications := jwtMetrics.Verifications()
+	if len(verifications) != 2 {
+		t.Fatalf("Expected 2 keys, got %d", len(verifications))
+	}
+	if v := verifications[0]; v.KeyID != "default" || v.State != "active" || v.Count != 2 {
+		t.Errorf("Unexpected active key verifications: %+v", v)
+	}
+
+	registry := NewRegistry()
+	registry.RegisterJWT(jwtMetrics)
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	for _, line := range []string{
+		"# TYPE jwt_verifications_total counter",
+		`jwt_verifications_total{kid="default",state="active"} 2`,
+		`jwt_verifications_total{kid="default",state="retired"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+}
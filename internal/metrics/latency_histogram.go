@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are the default upper bounds used for latency histograms
+var DefaultLatencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// LatencyHistogram is a lock-free fixed-bucket histogram of durations.
+// Each bucket counts observations less than or equal to its upper bound;
+// the final implicit bucket counts everything above the largest bound.
+type LatencyHistogram struct {
+	bounds []time.Duration
+	counts []uint64 // len(bounds)+1, last element is the +Inf bucket
+	count  uint64
+	sum    int64 // in nanoseconds
+}
+
+// HistogramBucket represents a single cumulative histogram bucket
+type HistogramBucket struct {
+	UpperBound string `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+// HistogramSnapshot is a point-in-time view of a latency histogram
+type HistogramSnapshot struct {
+	Count   uint64            `json:"count"`
+	Sum     time.Duration     `json:"sum"`
+	Mean    time.Duration     `json:"mean"`
+	Buckets []HistogramBucket `json:"buckets"`
+}
+
+// NewLatencyHistogram creates a histogram with the given bucket upper bounds.
+// Bounds must be sorted in ascending order; DefaultLatencyBuckets is used when none are given.
+func NewLatencyHistogram(bounds ...time.Duration) *LatencyHistogram {
+	if len(bounds) == 0 {
+		bounds = DefaultLatencyBuckets
+	}
+
+	b := make([]time.Duration, len(bounds))
+	copy(b, bounds)
+
+	return &LatencyHistogram{
+		bounds: b,
+		counts: make([]uint64, len(b)+1),
+	}
+}
+
+// Observe records a single duration
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	idx := len(h.bounds)
+	for i, bound := range h.bounds {
+		if d <= bound {
+			idx = i
+			break
+		}
+	}
+
+	atomic.AddUint64(&h.counts[idx], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+// Snapshot returns the cumulative bucket counts of the histogram
+func (h *LatencyHistogram) Snapshot() HistogramSnapshot {
+	snapshot := HistogramSnapshot{
+		Count:   atomic.LoadUint64(&h.count),
+		Sum:     time.Duration(atomic.LoadInt64(&h.sum)),
+		Buckets: make([]HistogramBucket, 0, len(h.counts)),
+	}
+
+	if snapshot.Count > 0 {
+		snapshot.Mean = snapshot.Sum / time.Duration(snapshot.Count)
+	}
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		snapshot.Buckets = append(snapshot.Buckets, HistogramBucket{
+			UpperBound: bound.String(),
+			Count:      cumulative,
+		})
+	}
+	cumulative += atomic.LoadUint64(&h.counts[len(h.bounds)])
+	snapshot.Buckets = append(snapshot.Buckets, HistogramBucket{
+		UpperBound: "+Inf",
+		Count:      cumulative,
+	})
+
+	return snapshot
+}
+
+// Reset clears all recorded observations
+func (h *LatencyHistogram) Reset() {
+	for i := range h.counts {
+		atomic.StoreUint64(&h.counts[i], 0)
+	}
+	atomic.StoreUint64(&h.count, 0)
+	atomic.StoreInt64(&h.sum, 0)
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewLatencyHistogram_DefaultBuckets(t *testing.T) {
+	h := NewLatencyHistogram()
+
+	snapshot := h.Snapshot()
+	if len(snapshot.Buckets) != len(DefaultLatencyBuckets)+1 {
+		t.Errorf("Expected %d buckets, got %d", len(DefaultLatencyBuckets)+1, len(snapshot.Buckets))
+	}
+	if snapshot.Count != 0 {
+		t.Errorf("Expected count to be 0, got %d", snapshot.Count)
+	}
+	if snapshot.Buckets[len(snapshot.Buckets)-1].UpperBound != "+Inf" {
+		t.Errorf("Expected last bucket to be +Inf, got %s", snapshot.Buckets[len(snapshot.Buckets)-1].UpperBound)
+	}
+}
+
+func TestLatencyHistogram_ObserveCumulative(t *testing.T) {
+	h := NewLatencyHistogram(10*time.Millisecond, 100*time.Millisecond)
+
+	h.Observe(5 * time.Millisecond)
+	h.Observe(10 * time.Millisecond)
+	h.Observe(50 * time.Millisecond)
+	h.Observe(time.Second)
+
+	snapshot := h.Snapshot()
+	expected := []uint64{2, 3, 4}
+	for i, bucket := range snapshot.Buckets {
+		if bucket.Count != expected[i] {
+			t.Errorf("Expected bucket %s to have count %d, got %d", bucket.UpperBound, expected[i], bucket.Count)
+		}
+	}
+
+	if snapshot.Count != 4 {
+		t.Errorf("Expected count to be 4, got %d", snapshot.Count)
+	}
+
+	expectedSum := 1065 * time.Millisecond
+	if snapshot.Sum != expectedSum {
+		t.Errorf("Expected sum to be %v, got %v", expectedSum, snapshot.Sum)
+	}
+	if snapshot.Mean != expectedSum/4 {
+		t.Errorf("Expected mean to be %v, got %v", expectedSum/4, snapshot.Mean)
+	}
+}
+
+func TestLatencyHistogram_Reset(t *testing.T) {
+	h := NewLatencyHistogram()
+	h.Observe(20 * time.Millisecond)
+	h.Reset()
+
+	snapshot := h.Snapshot()
+	if snapshot.Count != 0 || snapshot.Sum != 0 {
+		t.Errorf("Expected empty histogram after reset, got count=%d sum=%v", snapshot.Count, snapshot.Sum)
+	}
+	for _, bucket := range snapshot.Buckets {
+		if bucket.Count != 0 {
+			t.Errorf("Expected bucket %s to be empty after reset, got %d", bucket.UpperBound, bucket.Count)
+		}
+	}
+}
+
+func TestLatencyHistogram_ConcurrentObserve(t *testing.T) {
+	h := NewLatencyHistogram()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.Observe(time.Duration(j) * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := h.Snapshot()
+	if snapshot.Count != 5000 {
+		t.Errorf("Expected count to be 5000, got %d", snapshot.Count)
+	}
+	if snapshot.Buckets[len(snapshot.Buckets)-1].Count != 5000 {
+		t.Errorf("Expected +Inf bucket to be 5000, got %d", snapshot.Buckets[len(snapshot.Buckets)-1].Count)
+	}
+}
+
+func TestDatabaseMetrics_LatencyHistograms(t *testing.T) {
+	dm := NewDatabaseMetrics()
+
+	dm.RecordQuery(QueryTypeSelect, "SELECT 1", 3*time.Millisecond, true, 1, nil, nil)
+	dm.RecordQuery(QueryTypeSelect, "SELECT 2", 30*time.Millisecond, true, 1, nil, nil)
+	dm.RecordQuery(QueryTypeInsert, "INSERT INTO t VALUES (1)", 2*time.Millisecond, true, 1, nil, nil)
+
+	histograms := dm.GetLatencyHistograms()
+	if histograms["SELECT"].Count != 2 {
+		t.Errorf("Expected 2 SELECT observations, got %d", histograms["SELECT"].Count)
+	}
+	if histograms["INSERT"].Count != 1 {
+		t.Errorf("Expected 1 INSERT observation, got %d", histograms["INSERT"].Count)
+	}
+	if histograms["DELETE"].Count != 0 {
+		t.Errorf("Expected 0 DELETE observations, got %d", histograms["DELETE"].Count)
+	}
+
+	dm.Reset()
+	if dm.GetLatencyHistograms()["SELECT"].Count != 0 {
+		t.Error("Expected histograms to be cleared after reset")
+	}
+}
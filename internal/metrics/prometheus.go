@@ -0,0 +1,265 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry collects the metrics exported in the Prometheus text exposition format
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]*CacheMetrics
+	auth   *AuthMetrics
+	http   *HTTPMetrics
+	jwt    *JWTMetrics
+	tls    *TLSMetrics
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		caches: make(map[string]*CacheMetrics),
+	}
+}
+
+// RegisterCache registers cache metrics under the given name, exported as the "cache" label
+// Registering the same name again replaces the previous metrics; nil metrics are ignored
+func (r *Registry) RegisterCache(name string, cacheMetrics *CacheMetrics) {
+	if cacheMetrics == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.caches[name] = cacheMetrics
+}
+
+// RegisterAuth registers the authentication attempt metrics, replacing any previously registered ones
+func (r *Registry) RegisterAuth(authMetrics *AuthMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auth = authMetrics
+}
+
+// RegisterHTTP registers the HTTP server metrics, replacing any previously registered ones
+func (r *Registry) RegisterHTTP(httpMetrics *HTTPMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.http = httpMetrics
+}
+
+// RegisterJWT registers the JWT verification metrics, replacing any previously registered ones
+func (r *Registry) RegisterJWT(jwtMetrics *JWTMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jwt = jwtMetrics
+}
+
+// RegisterTLS registers the ACME certificate metrics, replacing any previously registered ones
+func (r *Registry) RegisterTLS(tlsMetrics *TLSMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tls = tlsMetrics
+}
+
+// CacheStats returns the statistics of every registered cache, without the recent operation history
+func (r *Registry) CacheStats() map[string]CacheStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make(map[string]CacheStats, len(r.caches))
+	for name, cacheMetrics := range r.caches {
+		stats[name] = cacheMetrics.Summary()
+	}
+	return stats
+}
+
+// WritePrometheus writes all registered metrics in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	p := newPromWriter(w)
+
+	r.mu.RLock()
+	names := make([]string, 0, len(r.caches))
+	for name := range r.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	caches := make([]*CacheMetrics, len(names))
+	for i, name := range names {
+		caches[i] = r.caches[name]
+	}
+	authMetrics := r.auth
+	httpMetrics := r.http
+	jwtMetrics := r.jwt
+	tlsMetrics := r.tls
+	r.mu.RUnlock()
+
+	if authMetrics != nil {
+		authMetrics.writePrometheus(p)
+	}
+	if httpMetrics != nil {
+		httpMetrics.writePrometheus(p)
+	}
+	if jwtMetrics != nil {
+		jwtMetrics.writePrometheus(p)
+	}
+	if tlsMetrics != nil {
+		tlsMetrics.writePrometheus(p)
+	}
+	writeCacheMetrics(p, names, caches)
+	return p.flush()
+}
+
+// writeCacheMetrics writes the cache metric families, one series per cache
+func writeCacheMetrics(p *promWriter, names []string, caches []*CacheMetrics) {
+	if len(caches) == 0 {
+		return
+	}
+
+	stats := make([]CacheStats, len(caches))
+	for i, cacheMetrics := range caches {
+		stats[i] = cacheMetrics.Summary()
+	}
+
+	counters := []struct {
+		name  string
+		help  string
+		value func(CacheStats) uint64
+	}{
+		{"cache_hits_total", "Cache lookups that found a value, including negative hits.", func(s CacheStats) uint64 { return s.CacheHits }},
+		{"cache_negative_hits_total", "Cache lookups that found a cached \"not found\" result.", func(s CacheStats) uint64 { return s.NegativeHits }},
+		{"cache_misses_total", "Cache lookups that found nothing.", func(s CacheStats) uint64 { return s.CacheMisses }},
+		{"cache_loads_total", "Loads from the data source after a cache miss.", func(s CacheStats) uint64 { return s.Loads }},
+		{"cache_load_errors_total", "Loads from the data source that failed.", func(s CacheStats) uint64 { return s.LoadErrors }},
+		{"cache_evictions_total", "Cache entries evicted before they expired.", func(s CacheStats) uint64 { return s.Evictions }},
+		{"cache_errors_total", "Cache operations that failed.", func(s CacheStats) uint64 { return s.Errors }},
+	}
+	for _, counter := range counters {
+		p.header(counter.name, counter.help, "counter")
+		for i, name := range names {
+			p.sample(counter.name, float64(counter.value(stats[i])), "cache", name)
+		}
+	}
+
+	p.header("cache_operations_total", "Cache operations by type.", "counter")
+	for i, name := range names {
+		p.sample("cache_operations_total", float64(stats[i].Gets), "cache", name, "operation", "get")
+		p.sample("cache_operations_total", float64(stats[i].Sets), "cache", name, "operation", "set")
+		p.sample("cache_operations_total", float64(stats[i].Deletes), "cache", name, "operation", "delete")
+	}
+
+	p.header("cache_load_duration_seconds", "Time spent loading from the data source after a cache miss.", "histogram")
+	for i, name := range names {
+		caches[i].loadLatency.writePrometheus(p, "cache_load_duration_seconds", "cache", name)
+	}
+
+	prefixCounters := []struct {
+		name  string
+		help  string
+		value func(CachePrefixStats) uint64
+	}{
+		{"cache_prefix_hits_total", "Cache hits by key prefix.", func(s CachePrefixStats) uint64 { return s.Hits }},
+		{"cache_prefix_misses_total", "Cache misses by key prefix.", func(s CachePrefixStats) uint64 { return s.Misses }},
+		{"cache_prefix_loads_total", "Loads from the data source by key prefix.", func(s CachePrefixStats) uint64 { return s.Loads }},
+	}
+	for _, counter := range prefixCounters {
+		p.header(counter.name, counter.help, "counter")
+		for i, name := range names {
+			for _, prefix := range stats[i].Prefixes {
+				p.sample(counter.name, float64(counter.value(prefix)), "cache", name, "prefix", prefix.Prefix)
+			}
+		}
+	}
+}
+
+// writePrometheus writes the histogram as cumulative buckets with bounds in seconds
+func (h *LatencyHistogram) writePrometheus(p *promWriter, name string, labels ...string) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		p.sample(name+"_bucket", float64(cumulative), append(labels, "le", formatPromValue(bound.Seconds()))...)
+	}
+	cumulative += atomic.LoadUint64(&h.counts[len(h.bounds)])
+	p.sample(name+"_bucket", float64(cumulative), append(labels, "le", "+Inf")...)
+	p.sample(name+"_sum", float64(atomic.LoadInt64(&h.sum))/1e9, labels...)
+	p.sample(name+"_count", float64(cumulative), labels...)
+}
+
+// promWriter writes metric families in the Prometheus text exposition format, keeping the first write error
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newPromWriter(w io.Writer) *promWriter {
+	return &promWriter{w: bufio.NewWriter(w)}
+}
+
+// header writes the HELP and TYPE lines of a metric family
+func (p *promWriter) header(name, help, metricType string) {
+	p.write("# HELP " + name + " " + strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help) + "\n")
+	p.write("# TYPE " + name + " " + metricType + "\n")
+}
+
+// sample writes one sample; labels are given as alternating names and values
+func (p *promWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i])
+			b.WriteString(`="`)
+			b.WriteString(escapeLabelValue(labels[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatPromValue(value))
+	b.WriteByte('\n')
+	p.write(b.String())
+}
+
+func (p *promWriter) write(s string) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = p.w.WriteString(s)
+}
+
+func (p *promWriter) flush() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// escapeLabelValue escapes backslashes, double quotes and newlines in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatPromValue formats a sample value, using the exposition format's spelling of infinities and NaN
+func formatPromValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWritePrometheus(t *testing.T) {
+	registry := NewRegistry()
+
+	users := NewCacheMetrics()
+	users.RecordKeyHit("user:id:1")
+	users.RecordKeyMiss("user:id:2")
+	users.RecordLoad("user:id:2", 3*time.Millisecond, true)
+	registry.RegisterCache("users", users)
+
+	redis := NewCacheMetrics()
+	redis.RecordGet(`odd"key:x:1`, time.Millisecond, true, true)
+	redis.RecordSet("user:id:1", time.Millisecond, true)
+	registry.RegisterCache("redis", redis)
+	registry.RegisterCache("ignored", nil)
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	output := buf.String()
+
+	expected := []string{
+		"# TYPE cache_hits_total counter",
+		`cache_hits_total{cache="redis"} 1`,
+		`cache_hits_total{cache="users"} 1`,
+		`cache_misses_total{cache="users"} 1`,
+		`cache_loads_total{cache="users"} 1`,
+		`cache_operations_total{cache="redis",operation="set"} 1`,
+		"# TYPE cache_load_duration_seconds histogram",
+		`cache_load_duration_seconds_bucket{cache="users",le="0.001"} 0`,
+		`cache_load_duration_seconds_bucket{cache="users",le="0.005"} 1`,
+		`cache_load_duration_seconds_bucket{cache="users",le="+Inf"} 1`,
+		`cache_load_duration_seconds_sum{cache="users"} 0.003`,
+		`cache_load_duration_seconds_count{cache="users"} 1`,
+		`cache_prefix_hits_total{cache="users",prefix="user:id"} 1`,
+		`cache_prefix_hits_total{cache="redis",prefix="odd\"key:x"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output, "ignored") {
+		t.Error("Expected nil cache metrics to be ignored")
+	}
+	if strings.Count(output, "# TYPE cache_hits_total ") != 1 {
+		t.Error("Expected each metric family to be declared once")
+	}
+	// Caches are written in name order
+	if strings.Index(output, `cache_hits_total{cache="redis"}`) > strings.Index(output, `cache_hits_total{cache="users"}`) {
+		t.Error("Expected caches to be sorted by name")
+	}
+}
+
+func TestRegistryWritePrometheus_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewRegistry().WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output without registered metrics, got %q", buf.String())
+	}
+}
@@ -1,10 +1,15 @@
 package metrics
 
 import (
+	"container/heap"
 	"log"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go-server/pkg/clock"
+	"go-server/pkg/geoip"
 )
 
 // RateLimitMetrics tracks rate limiting effectiveness statistics
@@ -20,14 +25,26 @@ type RateLimitMetrics struct {
 	ipViolations     map[string]*ViolationTracker
 	userViolations   map[string]*ViolationTracker
 	maxViolationsMap int
+	lastCleanup      time.Time
 
-	// Request history for recent performance analysis
+	// Request history for recent performance analysis, a ring buffer of at most
+	// maxHistorySize entries; once full, historyStart is the index of the oldest entry
 	requestHistory []RateLimitRequest
+	historyStart   int
 	maxHistorySize int
 
+	// Per-minute aggregates, a ring indexed by Unix minute covering DefaultTimeSeriesRetention
+	timeSeries []rateLimitBucket
+
 	// Rate limit configuration tracking
 	rateLimitConfig RateLimitConfig
 
+	// Optional GeoIP lookup for violating IPs
+	locator geoip.Locator
+
+	// Source of request timestamps and the current minute, replaced by a fake clock in tests
+	clock clock.Clock
+
 	// Performance tracking
 	totalCheckDuration int64 // in nanoseconds
 	maxCheckDuration    int64 // in nanoseconds
@@ -55,6 +72,8 @@ type ViolationTracker struct {
 	LastViolation    time.Time     `json:"last_violation"`
 	ViolationHistory []time.Time   `json:"violation_history"`
 	FirstViolation   time.Time     `json:"first_violation"`
+	Country          string        `json:"country,omitempty"` // Client country of an IP, when GeoIP lookup is enabled
+	ASN              uint          `json:"asn,omitempty"`     // Autonomous system of an IP, when GeoIP lookup is enabled
 }
 
 // RateLimitConfig represents the current rate limiting configuration
@@ -62,6 +81,12 @@ type RateLimitConfig struct {
 	RequestsPerMinute int           `json:"requests_per_minute"`
 	WindowSize        time.Duration `json:"window_size"`
 	Enabled           bool          `json:"enabled"`
+	// Mode is enforce, warn or shadow; outside enforce mode throttled counts are requests that would have been blocked
+	Mode string `json:"mode,omitempty"`
+	// Algorithm is sliding_window or token_bucket; with a token bucket limits and counts are measured in tokens
+	Algorithm string `json:"algorithm,omitempty"`
+	// Burst is the number of tokens a bucket holds beyond the limit
+	Burst int `json:"burst,omitempty"`
 }
 
 // RateLimitStats represents aggregated rate limit statistics
@@ -108,6 +133,15 @@ const (
 	DefaultRateLimitHistorySize = 5000
 	DefaultMaxViolationsMap    = 10000
 	DefaultWindowSize          = time.Minute
+
+	// recentRequestsInStats bounds the request history returned by GetStats; use GetRecentRequests for more
+	recentRequestsInStats = 100
+	// maxViolationHistory bounds the violation timestamps kept per identifier
+	maxViolationHistory = 100
+	// violationCleanupInterval is how often trackViolation scans for expired violations
+	violationCleanupInterval = time.Minute
+	// violationRetention is how long an identifier is tracked after its last violation
+	violationRetention = 24 * time.Hour
 )
 
 // NewRateLimitMetrics creates a new rate limit metrics instance
@@ -118,6 +152,8 @@ func NewRateLimitMetrics() *RateLimitMetrics {
 		maxViolationsMap: DefaultMaxViolationsMap,
 		maxHistorySize:   DefaultRateLimitHistorySize,
 		requestHistory:   make([]RateLimitRequest, 0),
+		timeSeries:       make([]rateLimitBucket, int(DefaultTimeSeriesRetention/time.Minute)),
+		clock:            clock.Real,
 		rateLimitConfig: RateLimitConfig{
 			RequestsPerMinute: DefaultRateLimitPerMinute,
 			WindowSize:        DefaultWindowSize,
@@ -133,6 +169,27 @@ func (rlm *RateLimitMetrics) UpdateConfig(config RateLimitConfig) {
 	rlm.rateLimitConfig = config
 }
 
+// SetLocator enables GeoIP lookup of violating IPs; IPs already tracked are not located
+func (rlm *RateLimitMetrics) SetLocator(locator geoip.Locator) {
+	rlm.mu.Lock()
+	defer rlm.mu.Unlock()
+	rlm.locator = locator
+}
+
+// SetClock replaces the clock used to timestamp requests and to bound time windows; nil restores the system clock
+func (rlm *RateLimitMetrics) SetClock(clk clock.Clock) {
+	rlm.mu.Lock()
+	defer rlm.mu.Unlock()
+	rlm.clock = clock.OrReal(clk)
+}
+
+// now reads the clock under the read lock, for callers that do not already hold the mutex
+func (rlm *RateLimitMetrics) now() time.Time {
+	rlm.mu.RLock()
+	defer rlm.mu.RUnlock()
+	return rlm.clock.Now()
+}
+
 // RecordRequest records a rate limit request check
 func (rlm *RateLimitMetrics) RecordRequest(ip, userID, endpoint string, duration time.Duration, allowed bool, reason string, currentCount, limit int64) {
 	// Update atomic counters
@@ -167,8 +224,7 @@ func (rlm *RateLimitMetrics) RecordRequest(ip, userID, endpoint string, duration
 		Duration:     duration,
 		Allowed:      allowed,
 		Reason:       reason,
-		Timestamp:    time.Now(),
-		WindowSize:   rlm.rateLimitConfig.WindowSize,
+		Timestamp:    rlm.now(),
 		CurrentCount: currentCount,
 		Limit:        limit,
 	})
@@ -179,57 +235,59 @@ func (rlm *RateLimitMetrics) trackViolation(ip, userID string) {
 	rlm.mu.Lock()
 	defer rlm.mu.Unlock()
 
-	now := time.Now()
-
-	// Track IP violations
+	now := rlm.clock.Now()
 	if ip != "" {
-		if rlm.ipViolations[ip] == nil {
-			rlm.ipViolations[ip] = &ViolationTracker{
-				Identifier: ip,
-				FirstViolation: now,
-				ViolationHistory: make([]time.Time, 0),
+		tracker := recordViolation(rlm.ipViolations, ip, now)
+		if tracker.TotalViolations == 1 && rlm.locator != nil {
+			// Located once per tracked IP, the lookup is a memory-mapped read
+			if location, ok := rlm.locator.Locate(ip); ok {
+				tracker.Country = location.Country
+				tracker.ASN = location.ASN
 			}
 		}
-		
-		tracker := rlm.ipViolations[ip]
-		tracker.TotalViolations++
-		tracker.LastViolation = now
-		tracker.ViolationHistory = append(tracker.ViolationHistory, now)
-
-		// Trim violation history to reasonable size
-		if len(tracker.ViolationHistory) > 100 {
-			tracker.ViolationHistory = tracker.ViolationHistory[50:] // Keep last 50
-		}
 	}
-
-	// Track user violations
 	if userID != "" {
-		if rlm.userViolations[userID] == nil {
-			rlm.userViolations[userID] = &ViolationTracker{
-				Identifier: userID,
-				FirstViolation: now,
-				ViolationHistory: make([]time.Time, 0),
-			}
-		}
-		
-		tracker := rlm.userViolations[userID]
-		tracker.TotalViolations++
-		tracker.LastViolation = now
-		tracker.ViolationHistory = append(tracker.ViolationHistory, now)
-
-		// Trim violation history to reasonable size
-		if len(tracker.ViolationHistory) > 100 {
-			tracker.ViolationHistory = tracker.ViolationHistory[50:] // Keep last 50
+		recordViolation(rlm.userViolations, userID, now)
+	}
+
+	// Scanning the maps on every violation is O(n) per throttled request, so expired
+	// entries are removed periodically and the maps are trimmed only when over the limit
+	if now.Sub(rlm.lastCleanup) >= violationCleanupInterval {
+		rlm.cleanupOldViolations()
+	} else if len(rlm.ipViolations) > rlm.maxViolationsMap || len(rlm.userViolations) > rlm.maxViolationsMap {
+		rlm.trimViolations()
+	}
+}
+
+// recordViolation records a violation for an identifier, keeping at most maxViolationHistory timestamps
+func recordViolation(violations map[string]*ViolationTracker, identifier string, now time.Time) *ViolationTracker {
+	tracker := violations[identifier]
+	if tracker == nil {
+		tracker = &ViolationTracker{
+			Identifier:       identifier,
+			FirstViolation:   now,
+			ViolationHistory: make([]time.Time, 0, maxViolationHistory),
 		}
+		violations[identifier] = tracker
 	}
 
-	// Cleanup old violations to prevent memory leaks
-	rlm.cleanupOldViolations()
+	tracker.TotalViolations++
+	tracker.LastViolation = now
+	if len(tracker.ViolationHistory) == maxViolationHistory {
+		// Shift in place so the backing array never grows
+		copy(tracker.ViolationHistory, tracker.ViolationHistory[1:])
+		tracker.ViolationHistory[maxViolationHistory-1] = now
+		return tracker
+	}
+	tracker.ViolationHistory = append(tracker.ViolationHistory, now)
+	return tracker
 }
 
 // cleanupOldViolations removes old violations to prevent memory leaks
 func (rlm *RateLimitMetrics) cleanupOldViolations() {
-	cutoff := time.Now().Add(-24 * time.Hour) // Keep last 24 hours
+	now := rlm.clock.Now()
+	rlm.lastCleanup = now
+	cutoff := now.Add(-violationRetention)
 
 	// Cleanup IP violations
 	for ip, tracker := range rlm.ipViolations {
@@ -245,7 +303,11 @@ func (rlm *RateLimitMetrics) cleanupOldViolations() {
 		}
 	}
 
-	// Ensure maps don't grow too large
+	rlm.trimViolations()
+}
+
+// trimViolations ensures the violation maps don't grow too large
+func (rlm *RateLimitMetrics) trimViolations() {
 	if len(rlm.ipViolations) > rlm.maxViolationsMap {
 		rlm.trimViolationsMap(rlm.ipViolations)
 	}
@@ -254,51 +316,65 @@ func (rlm *RateLimitMetrics) cleanupOldViolations() {
 	}
 }
 
-// trimViolationsMap trims the violations map to a reasonable size
+// trimViolationsMap trims the violations map to half its maximum size, keeping the most recent violators
+// Trimming to half the limit amortizes the O(n log n) sort over the following maxViolationsMap/2 new identifiers
 func (rlm *RateLimitMetrics) trimViolationsMap(violations map[string]*ViolationTracker) {
-	type violationEntry struct {
-		identifier string
-		lastViolation time.Time
+	keepCount := rlm.maxViolationsMap / 2
+	if len(violations) <= keepCount {
+		return
 	}
 
-	// Collect all violations with their last violation time
-	var allViolations []violationEntry
-	for id, tracker := range violations {
-		allViolations = append(allViolations, violationEntry{
-			identifier: id,
-			lastViolation: tracker.LastViolation,
-		})
+	trackers := make([]*ViolationTracker, 0, len(violations))
+	for _, tracker := range violations {
+		trackers = append(trackers, tracker)
 	}
 
 	// Sort by last violation time (oldest first)
-	for i := 0; i < len(allViolations); i++ {
-		for j := i + 1; j < len(allViolations); j++ {
-			if allViolations[i].lastViolation.After(allViolations[j].lastViolation) {
-				allViolations[i], allViolations[j] = allViolations[j], allViolations[i]
-			}
-		}
-	}
+	sort.Slice(trackers, func(i, j int) bool {
+		return trackers[i].LastViolation.Before(trackers[j].LastViolation)
+	})
 
-	// Keep only the most recent violations
-	keepCount := rlm.maxViolationsMap / 2
-	for i := 0; i < len(allViolations)-keepCount; i++ {
-		delete(violations, allViolations[i].identifier)
+	for _, tracker := range trackers[:len(trackers)-keepCount] {
+		delete(violations, tracker.Identifier)
 	}
 }
 
-// recordRequest records a request in the history
+// recordRequest records a request in the history, overwriting the oldest entry once the history is full
 func (rlm *RateLimitMetrics) recordRequest(request RateLimitRequest) {
 	rlm.mu.Lock()
 	defer rlm.mu.Unlock()
 
-	// Add new request
-	rlm.requestHistory = append(rlm.requestHistory, request)
+	request.WindowSize = rlm.rateLimitConfig.WindowSize
+	rlm.observeTimeSeries(request.Timestamp, request.Duration, request.Allowed)
 
-	// Trim history if it exceeds max size
-	if len(rlm.requestHistory) > rlm.maxHistorySize {
-		// Remove oldest requests (keep only the last maxHistorySize)
-		rlm.requestHistory = rlm.requestHistory[rlm.maxHistorySize/2:]
+	if len(rlm.requestHistory) < rlm.maxHistorySize {
+		rlm.requestHistory = append(rlm.requestHistory, request)
+		return
+	}
+	if len(rlm.requestHistory) == 0 {
+		return
 	}
+	rlm.requestHistory[rlm.historyStart] = request
+	rlm.historyStart = (rlm.historyStart + 1) % len(rlm.requestHistory)
+}
+
+// historyAt returns the i-th request of the history, oldest first; the caller must hold rlm.mu
+func (rlm *RateLimitMetrics) historyAt(i int) *RateLimitRequest {
+	return &rlm.requestHistory[(rlm.historyStart+i)%len(rlm.requestHistory)]
+}
+
+// recentRequestsLocked copies the last limit requests of the history, oldest first; the caller must hold rlm.mu
+func (rlm *RateLimitMetrics) recentRequestsLocked(limit int) []RateLimitRequest {
+	size := len(rlm.requestHistory)
+	if limit <= 0 || limit > size {
+		limit = size
+	}
+
+	recentRequests := make([]RateLimitRequest, limit)
+	for i := range recentRequests {
+		recentRequests[i] = *rlm.historyAt(size - limit + i)
+	}
+	return recentRequests
 }
 
 // GetStats returns current rate limit statistics
@@ -346,9 +422,7 @@ func (rlm *RateLimitMetrics) GetStats() RateLimitStats {
 	rlm.mu.RLock()
 	topIPs := rlm.getTopViolators(rlm.ipViolations, 10)
 	topUsers := rlm.getTopViolators(rlm.userViolations, 10)
-	
-	recentRequests := make([]RateLimitRequest, len(rlm.requestHistory))
-	copy(recentRequests, rlm.requestHistory)
+	recentRequests := rlm.recentRequestsLocked(recentRequestsInStats)
 	config := rlm.rateLimitConfig
 	rlm.mu.RUnlock()
 
@@ -369,47 +443,69 @@ func (rlm *RateLimitMetrics) GetStats() RateLimitStats {
 	}
 }
 
-// getTopViolators returns the top violators from a violations map
+// getTopViolators returns the top violators from a violations map, highest violation count first
+// A min-heap of size limit keeps this O(n log limit) and copies only the returned trackers
 func (rlm *RateLimitMetrics) getTopViolators(violations map[string]*ViolationTracker, limit int) []ViolationTracker {
-	var violators []ViolationTracker
-	for _, tracker := range violations {
-		violators = append(violators, *tracker)
+	if limit <= 0 {
+		return nil
 	}
 
-	// Sort by total violations (highest first)
-	for i := 0; i < len(violators); i++ {
-		for j := i + 1; j < len(violators); j++ {
-			if violators[i].TotalViolations < violators[j].TotalViolations {
-				violators[i], violators[j] = violators[j], violators[i]
-			}
+	top := make(violatorHeap, 0, limit)
+	for _, tracker := range violations {
+		if len(top) < limit {
+			heap.Push(&top, tracker)
+		} else if violatorLess(top[0], tracker) {
+			top[0] = tracker
+			heap.Fix(&top, 0)
 		}
 	}
 
-	// Return top violators
-	if limit > len(violators) {
-		limit = len(violators)
+	violators := make([]ViolationTracker, len(top))
+	for i := len(violators) - 1; i >= 0; i-- {
+		violators[i] = heap.Pop(&top).(*ViolationTracker).snapshot()
+	}
+	return violators
+}
+
+// violatorLess reports whether a ranks below b: fewer violations, or as many with a later identifier
+func violatorLess(a, b *ViolationTracker) bool {
+	if a.TotalViolations != b.TotalViolations {
+		return a.TotalViolations < b.TotalViolations
 	}
-	return violators[:limit]
+	return a.Identifier > b.Identifier
+}
+
+// violatorHeap is a min-heap of trackers ordered by violatorLess
+type violatorHeap []*ViolationTracker
+
+func (h violatorHeap) Len() int           { return len(h) }
+func (h violatorHeap) Less(i, j int) bool { return violatorLess(h[i], h[j]) }
+func (h violatorHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *violatorHeap) Push(x interface{}) {
+	*h = append(*h, x.(*ViolationTracker))
+}
+
+func (h *violatorHeap) Pop() interface{} {
+	old := *h
+	tracker := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return tracker
 }
 
-// GetRecentRequests returns the most recent rate limit requests
+// snapshot returns a copy of the tracker that does not share its violation history
+func (vt *ViolationTracker) snapshot() ViolationTracker {
+	copied := *vt
+	copied.ViolationHistory = append([]time.Time(nil), vt.ViolationHistory...)
+	return copied
+}
+
+// GetRecentRequests returns the most recent rate limit requests, oldest first
 func (rlm *RateLimitMetrics) GetRecentRequests(limit int) []RateLimitRequest {
 	rlm.mu.RLock()
 	defer rlm.mu.RUnlock()
 
-	if limit <= 0 || limit > len(rlm.requestHistory) {
-		limit = len(rlm.requestHistory)
-	}
-
-	// Return the last 'limit' requests
-	start := len(rlm.requestHistory) - limit
-	if start < 0 {
-		start = 0
-	}
-
-	recentRequests := make([]RateLimitRequest, limit)
-	copy(recentRequests, rlm.requestHistory[start:])
-	return recentRequests
+	return rlm.recentRequestsLocked(limit)
 }
 
 // GetThrottleRate returns the current throttle rate as a percentage
@@ -434,97 +530,64 @@ func (rlm *RateLimitMetrics) GetViolationStats() (map[string]ViolationTracker, m
 	rlm.mu.RLock()
 	defer rlm.mu.RUnlock()
 
-	ipStats := make(map[string]ViolationTracker)
+	ipStats := make(map[string]ViolationTracker, len(rlm.ipViolations))
 	for ip, tracker := range rlm.ipViolations {
-		ipStats[ip] = *tracker
+		ipStats[ip] = tracker.snapshot()
 	}
 
-	userStats := make(map[string]ViolationTracker)
+	userStats := make(map[string]ViolationTracker, len(rlm.userViolations))
 	for userID, tracker := range rlm.userViolations {
-		userStats[userID] = *tracker
+		userStats[userID] = tracker.snapshot()
 	}
 
 	return ipStats, userStats
 }
 
 // GetEffectivenessMetrics calculates detailed effectiveness metrics
+// Rates and check times come from the per-minute aggregates, so the window is rounded to whole minutes
+// and may extend beyond the request history; hotspots are identified from the request history within the window
 func (rlm *RateLimitMetrics) GetEffectivenessMetrics(timeWindow time.Duration) RateLimitEffectiveness {
 	rlm.mu.RLock()
 	defer rlm.mu.RUnlock()
 
-	now := time.Now()
+	now := rlm.clock.Now()
 	cutoff := now.Add(-timeWindow)
+	configuredRPS := float64(rlm.rateLimitConfig.RequestsPerMinute) / 60.0
 
-	// Filter requests within time window
-	var recentRequests []RateLimitRequest
-	for _, req := range rlm.requestHistory {
-		if req.Timestamp.After(cutoff) {
-			recentRequests = append(recentRequests, req)
-		}
-	}
-
-	if len(recentRequests) == 0 {
+	aggregate := rlm.aggregateLocked(cutoff.Unix()/60, now.Unix()/60)
+	if aggregate.total == 0 {
 		return RateLimitEffectiveness{
-			ConfiguredRPS: float64(rlm.rateLimitConfig.RequestsPerMinute) / 60.0,
-		}
-	}
-
-	// Calculate metrics
-	totalRequests := len(recentRequests)
-	throttledCount := 0
-	var totalCheckDuration time.Duration
-	var checkDurations []time.Duration
-
-	for _, req := range recentRequests {
-		totalCheckDuration += req.Duration
-		checkDurations = append(checkDurations, req.Duration)
-		if !req.Allowed {
-			throttledCount++
+			ConfiguredRPS: configuredRPS,
 		}
 	}
 
 	// Calculate rates
-	requestsPerSecond := float64(totalRequests) / timeWindow.Seconds()
-	throttleRate := float64(throttledCount) / float64(totalRequests) * 100
-	avgCheckTime := totalCheckDuration / time.Duration(totalRequests)
-
-	// Calculate P95 check time
-	if len(checkDurations) > 0 {
-		// Simple insertion sort for small slices
-		for i := 1; i < len(checkDurations); i++ {
-			key := checkDurations[i]
-			j := i - 1
-			for j >= 0 && checkDurations[j] > key {
-				checkDurations[j+1] = checkDurations[j]
-				j--
-			}
-			checkDurations[j+1] = key
-		}
-	}
-
-	p95CheckTime := time.Duration(0)
-	if len(checkDurations) > 0 {
-		p95Index := int(float64(len(checkDurations)) * 0.95)
-		if p95Index >= len(checkDurations) {
-			p95Index = len(checkDurations) - 1
-		}
-		p95CheckTime = checkDurations[p95Index]
-	}
+	requestsPerSecond := float64(aggregate.total) / timeWindow.Seconds()
+	throttleRate := float64(aggregate.throttled) / float64(aggregate.total) * 100
+	avgCheckTime := aggregate.checkDuration / time.Duration(aggregate.total)
 
 	// Calculate effectiveness score
 	effectivenessScore := rlm.calculateEffectivenessScore(throttleRate)
 
-	// Identify violation hotspots
-	hotspots := rlm.identifyHotspots(recentRequests, cutoff)
+	// The history is in chronological order, so walk back from the newest request
+	// until the window ends instead of copying the whole history
+	counts := newHotspotCounts()
+	for i := len(rlm.requestHistory) - 1; i >= 0; i-- {
+		req := rlm.historyAt(i)
+		if !req.Timestamp.After(cutoff) {
+			break
+		}
+		counts.add(req)
+	}
 
 	return RateLimitEffectiveness{
 		RequestsPerSecond:  requestsPerSecond,
 		ThrottleRate:       throttleRate,
 		EffectivenessScore: effectivenessScore,
-		ViolationHotspots:  hotspots,
+		ViolationHotspots:  rlm.hotspotsFromCounts(counts),
 		AverageCheckTime:   avgCheckTime,
-		P95CheckTime:       p95CheckTime,
-		ConfiguredRPS:      float64(rlm.rateLimitConfig.RequestsPerMinute) / 60.0,
+		P95CheckTime:       aggregate.quantile(0.95),
+		ConfiguredRPS:      configuredRPS,
 		ActualRPS:          requestsPerSecond,
 	}
 }
@@ -548,42 +611,64 @@ func (rlm *RateLimitMetrics) calculateEffectivenessScore(throttleRate float64) f
 	}
 }
 
-// identifyHotspots identifies rate limit violation hotspots
-func (rlm *RateLimitMetrics) identifyHotspots(requests []RateLimitRequest, cutoff time.Time) []Hotspot {
-	ipViolations := make(map[string]int)
-	userViolations := make(map[string]int)
-	ipTotalRequests := make(map[string]int)
-	userTotalRequests := make(map[string]int)
-
-	for _, req := range requests {
-		if !req.Allowed {
-			if req.IP != "" {
-				ipViolations[req.IP]++
-			}
-			if req.UserID != "" {
-				userViolations[req.UserID]++
-			}
-		}
-		
+// hotspotCounts counts requests and violations per IP and user
+type hotspotCounts struct {
+	ipViolations      map[string]int
+	userViolations    map[string]int
+	ipTotalRequests   map[string]int
+	userTotalRequests map[string]int
+}
+
+func newHotspotCounts() hotspotCounts {
+	return hotspotCounts{
+		ipViolations:      make(map[string]int),
+		userViolations:    make(map[string]int),
+		ipTotalRequests:   make(map[string]int),
+		userTotalRequests: make(map[string]int),
+	}
+}
+
+// add counts one request
+func (hc hotspotCounts) add(req *RateLimitRequest) {
+	if !req.Allowed {
 		if req.IP != "" {
-			ipTotalRequests[req.IP]++
+			hc.ipViolations[req.IP]++
 		}
 		if req.UserID != "" {
-			userTotalRequests[req.UserID]++
+			hc.userViolations[req.UserID]++
 		}
 	}
 
+	if req.IP != "" {
+		hc.ipTotalRequests[req.IP]++
+	}
+	if req.UserID != "" {
+		hc.userTotalRequests[req.UserID]++
+	}
+}
+
+// identifyHotspots identifies rate limit violation hotspots
+func (rlm *RateLimitMetrics) identifyHotspots(requests []RateLimitRequest, cutoff time.Time) []Hotspot {
+	counts := newHotspotCounts()
+	for i := range requests {
+		counts.add(&requests[i])
+	}
+	return rlm.hotspotsFromCounts(counts)
+}
+
+// hotspotsFromCounts returns the top 10 identifiers with significant traffic and violation rates
+func (rlm *RateLimitMetrics) hotspotsFromCounts(counts hotspotCounts) []Hotspot {
 	var hotspots []Hotspot
 
 	// Add IP hotspots
-	for ip, violations := range ipViolations {
-		total := ipTotalRequests[ip]
+	for ip, violations := range counts.ipViolations {
+		total := counts.ipTotalRequests[ip]
 		if total >= 10 { // Only include IPs with significant traffic
 			rate := float64(violations) / float64(total) * 100
 			if rate > 5.0 { // Only include if violation rate is significant
 				hotspots = append(hotspots, Hotspot{
 					Identifier:     ip,
-					Type:          "ip",
+					Type:           "ip",
 					ViolationCount: violations,
 					ViolationRate:  rate,
 					LastViolation:  rlm.getLastViolationTime(ip, true),
@@ -593,14 +678,14 @@ func (rlm *RateLimitMetrics) identifyHotspots(requests []RateLimitRequest, cutof
 	}
 
 	// Add user hotspots
-	for userID, violations := range userViolations {
-		total := userTotalRequests[userID]
+	for userID, violations := range counts.userViolations {
+		total := counts.userTotalRequests[userID]
 		if total >= 5 { // Only include users with some traffic
 			rate := float64(violations) / float64(total) * 100
 			if rate > 5.0 { // Only include if violation rate is significant
 				hotspots = append(hotspots, Hotspot{
 					Identifier:     userID,
-					Type:          "user",
+					Type:           "user",
 					ViolationCount: violations,
 					ViolationRate:  rate,
 					LastViolation:  rlm.getLastViolationTime(userID, false),
@@ -610,13 +695,16 @@ func (rlm *RateLimitMetrics) identifyHotspots(requests []RateLimitRequest, cutof
 	}
 
 	// Sort by violation count (highest first)
-	for i := 0; i < len(hotspots); i++ {
-		for j := i + 1; j < len(hotspots); j++ {
-			if hotspots[i].ViolationCount < hotspots[j].ViolationCount {
-				hotspots[i], hotspots[j] = hotspots[j], hotspots[i]
-			}
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.ViolationCount != b.ViolationCount {
+			return a.ViolationCount > b.ViolationCount
 		}
-	}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Identifier < b.Identifier
+	})
 
 	// Return top 10 hotspots
 	if len(hotspots) > 10 {
@@ -653,6 +741,8 @@ func (rlm *RateLimitMetrics) Reset() {
 	rlm.ipViolations = make(map[string]*ViolationTracker)
 	rlm.userViolations = make(map[string]*ViolationTracker)
 	rlm.requestHistory = make([]RateLimitRequest, 0)
+	rlm.historyStart = 0
+	rlm.timeSeries = make([]rateLimitBucket, len(rlm.timeSeries))
 	rlm.mu.Unlock()
 
 	log.Println("Rate limit metrics reset")
@@ -663,10 +753,15 @@ func (rlm *RateLimitMetrics) SetMaxHistorySize(size int) {
 	rlm.mu.Lock()
 	defer rlm.mu.Unlock()
 
+	if size < 0 {
+		size = 0
+	}
 	rlm.maxHistorySize = size
-	if len(rlm.requestHistory) > size {
-		// Trim history to new size
-		rlm.requestHistory = rlm.requestHistory[len(rlm.requestHistory)-size:]
+	// Copy into a new buffer in chronological order so the ring starts at index 0
+	rlm.requestHistory = rlm.recentRequestsLocked(size)
+	rlm.historyStart = 0
+	if size == 0 {
+		rlm.requestHistory = rlm.requestHistory[:0]
 	}
 }
 
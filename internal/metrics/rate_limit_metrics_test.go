@@ -1,9 +1,13 @@
 package metrics
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
+
+	"go-server/pkg/clock"
+	"go-server/pkg/geoip"
 )
 
 func TestNewRateLimitMetrics(t *testing.T) {
@@ -301,6 +305,38 @@ func TestGetViolationStats(t *testing.T) {
 	}
 }
 
+// countingLocator locates every IP in a fixed country and counts lookups
+type countingLocator struct {
+	lookups int
+}
+
+func (l *countingLocator) Locate(ip string) (geoip.Location, bool) {
+	l.lookups++
+	return geoip.Location{Country: "NL", ASN: 64512}, true
+}
+
+func TestViolationGeoIP(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+	locator := &countingLocator{}
+	rlm.SetLocator(locator)
+
+	rlm.RecordRequest("192.168.1.1", "user1", "/test", time.Millisecond, false, "", 101, 100)
+	rlm.RecordRequest("192.168.1.1", "user1", "/test", time.Millisecond, false, "", 101, 100)
+	rlm.RecordRequest("192.168.1.2", "", "/test", time.Millisecond, true, "", 1, 100)
+
+	ipStats, userStats := rlm.GetViolationStats()
+	tracker := ipStats["192.168.1.1"]
+	if tracker.Country != "NL" || tracker.ASN != 64512 {
+		t.Errorf("Expected violating IP to be located in NL/AS64512, got %q/%d", tracker.Country, tracker.ASN)
+	}
+	if userStats["user1"].Country != "" {
+		t.Errorf("Expected user trackers not to be located, got %q", userStats["user1"].Country)
+	}
+	if locator.lookups != 1 {
+		t.Errorf("Expected one lookup per violating IP, got %d", locator.lookups)
+	}
+}
+
 func TestGetEffectivenessMetrics(t *testing.T) {
 	rlm := NewRateLimitMetrics()
 
@@ -688,6 +724,115 @@ func TestIdentifyHotspots(t *testing.T) {
 	}
 }
 
+func TestRequestHistoryRingBuffer(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+	rlm.SetMaxHistorySize(10)
+
+	for i := 0; i < 25; i++ {
+		rlm.RecordRequest("127.0.0.1", "", "/test", time.Duration(i+1)*time.Millisecond, true, "", 1, 100)
+	}
+
+	if len(rlm.requestHistory) != 10 {
+		t.Fatalf("Expected history to hold exactly 10 requests, got %d", len(rlm.requestHistory))
+	}
+
+	recent := rlm.GetRecentRequests(0)
+	if len(recent) != 10 {
+		t.Fatalf("Expected 10 recent requests, got %d", len(recent))
+	}
+	for i, req := range recent {
+		expected := time.Duration(16+i) * time.Millisecond
+		if req.Duration != expected {
+			t.Errorf("Expected request %d to have duration %v, got %v", i, expected, req.Duration)
+		}
+	}
+
+	// Growing the history keeps the existing requests in order
+	rlm.SetMaxHistorySize(20)
+	rlm.RecordRequest("127.0.0.1", "", "/test", 26*time.Millisecond, true, "", 1, 100)
+	recent = rlm.GetRecentRequests(3)
+	if len(recent) != 3 || recent[0].Duration != 24*time.Millisecond || recent[2].Duration != 26*time.Millisecond {
+		t.Errorf("Expected the last 3 requests in order after growing the history, got %+v", recent)
+	}
+}
+
+func TestGetStatsBoundsRecentRequests(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+
+	for i := 0; i < recentRequestsInStats*3; i++ {
+		rlm.RecordRequest("127.0.0.1", "", "/test", time.Duration(i+1)*time.Microsecond, true, "", 1, 100)
+	}
+
+	stats := rlm.GetStats()
+	if len(stats.RecentRequests) != recentRequestsInStats {
+		t.Fatalf("Expected %d recent requests in stats, got %d", recentRequestsInStats, len(stats.RecentRequests))
+	}
+	last := stats.RecentRequests[len(stats.RecentRequests)-1]
+	if last.Duration != time.Duration(recentRequestsInStats*3)*time.Microsecond {
+		t.Errorf("Expected the newest request last, got duration %v", last.Duration)
+	}
+}
+
+func TestGetTopViolatorsLargeMap(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+
+	violations := make(map[string]*ViolationTracker)
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		violations[id] = &ViolationTracker{Identifier: id, TotalViolations: uint64(i % 500)}
+	}
+
+	top := rlm.getTopViolators(violations, 10)
+	if len(top) != 10 {
+		t.Fatalf("Expected 10 top violators, got %d", len(top))
+	}
+	for i := 1; i < len(top); i++ {
+		if top[i-1].TotalViolations < top[i].TotalViolations {
+			t.Errorf("Expected violators in descending order, got %d before %d", top[i-1].TotalViolations, top[i].TotalViolations)
+		}
+	}
+	if top[0].TotalViolations != 499 || top[9].TotalViolations != 495 {
+		t.Errorf("Expected violation counts 499..495, got %d..%d", top[0].TotalViolations, top[9].TotalViolations)
+	}
+
+	if got := rlm.getTopViolators(violations, 0); len(got) != 0 {
+		t.Errorf("Expected no violators for limit 0, got %d", len(got))
+	}
+}
+
+func TestViolationHistoryBounded(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+
+	for i := 0; i < maxViolationHistory*3; i++ {
+		rlm.RecordRequest("192.168.1.1", "", "/test", time.Millisecond, false, "", 101, 100)
+	}
+
+	ipStats, _ := rlm.GetViolationStats()
+	tracker := ipStats["192.168.1.1"]
+	if tracker.TotalViolations != uint64(maxViolationHistory*3) {
+		t.Errorf("Expected %d total violations, got %d", maxViolationHistory*3, tracker.TotalViolations)
+	}
+	if len(tracker.ViolationHistory) != maxViolationHistory {
+		t.Errorf("Expected violation history to hold %d timestamps, got %d", maxViolationHistory, len(tracker.ViolationHistory))
+	}
+}
+
+func TestEffectivenessP95(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+
+	// Record durations 100ms..1ms so the history is not already sorted
+	for i := 100; i >= 1; i-- {
+		rlm.RecordRequest("127.0.0.1", "", "/test", time.Duration(i)*time.Millisecond, true, "", 1, 100)
+	}
+
+	// The 95th of 100 checks falls in the 50ms-100ms histogram bucket, which holds 50 checks
+	// after 50 below it: 50ms + (95-50)/50 * 50ms
+	effectiveness := rlm.GetEffectivenessMetrics(time.Hour)
+	if effectiveness.P95CheckTime != 95*time.Millisecond {
+		t.Errorf("Expected P95 check time to be 95ms, got %v", effectiveness.P95CheckTime)
+	}
+}
+
 // Benchmark tests
 func BenchmarkRecordRequest(b *testing.B) {
 	rlm := NewRateLimitMetrics()
@@ -726,4 +871,41 @@ func BenchmarkGetEffectivenessMetrics(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		rlm.GetEffectivenessMetrics(time.Hour)
 	}
-}
\ No newline at end of file
+}
+
+func BenchmarkTrackViolationManyIPs(b *testing.B) {
+	rlm := NewRateLimitMetrics()
+	ips := make([]string, 20000)
+	for i := range ips {
+		ips[i] = fmt.Sprintf("10.%d.%d.%d", i/65536, (i/256)%256, i%256)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rlm.RecordRequest(ips[i%len(ips)], "", "/test", time.Millisecond, false, "", 101, 100)
+	}
+}
+
+func TestRateLimitMetricsFakeClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	rlm := NewRateLimitMetrics()
+	rlm.SetClock(clk)
+
+	rlm.RecordRequest("192.168.1.1", "", "/api/v1/users", time.Millisecond, false, "rate_limit_exceeded", 11, 10)
+	recent := rlm.GetRecentRequests(1)
+	if len(recent) != 1 || !recent[0].Timestamp.Equal(clk.Now()) {
+		t.Fatalf("Expected the request to be stamped with the fake clock, got %+v", recent)
+	}
+
+	// A violation from another IP after the retention period triggers cleanup of the stale one
+	clk.Advance(violationRetention + time.Minute)
+	rlm.RecordRequest("192.168.1.2", "", "/api/v1/users", time.Millisecond, false, "rate_limit_exceeded", 11, 10)
+
+	ipViolations, _ := rlm.GetViolationStats()
+	if _, ok := ipViolations["192.168.1.1"]; ok {
+		t.Error("Expected the violation older than the retention period to be cleaned up")
+	}
+	if _, ok := ipViolations["192.168.1.2"]; !ok {
+		t.Error("Expected the recent violation to be kept")
+	}
+}
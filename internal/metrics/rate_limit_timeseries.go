@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"time"
+)
+
+// DefaultTimeSeriesRetention is how long per-minute rate limit aggregates are kept
+const DefaultTimeSeriesRetention = 24 * time.Hour
+
+// rateLimitCheckBounds are the histogram upper bounds for rate limit check durations,
+// finer than DefaultLatencyBuckets since a check is usually a single Redis round trip
+var rateLimitCheckBounds = [...]time.Duration{
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	1 * time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+}
+
+// rateLimitBucket aggregates the rate limit checks of one minute
+type rateLimitBucket struct {
+	minute        int64 // Unix time in minutes; a bucket holding another minute is stale
+	total         uint64
+	throttled     uint64
+	checkDuration time.Duration
+	maxCheck      time.Duration
+	checkCounts   [len(rateLimitCheckBounds) + 1]uint64 // last element counts checks above the largest bound
+}
+
+// RateLimitTimeSeriesPoint aggregates the rate limit checks of one interval
+type RateLimitTimeSeriesPoint struct {
+	Start            time.Time     `json:"start"`
+	Requests         uint64        `json:"requests"`
+	Throttled        uint64        `json:"throttled"`
+	ThrottleRate     float64       `json:"throttle_rate"` // percentage
+	AvgCheckDuration time.Duration `json:"avg_check_duration"`
+	MaxCheckDuration time.Duration `json:"max_check_duration"`
+	P95CheckDuration time.Duration `json:"p95_check_duration"`
+}
+
+// observe adds one check to the bucket
+func (b *rateLimitBucket) observe(duration time.Duration, allowed bool) {
+	b.total++
+	if !allowed {
+		b.throttled++
+	}
+	b.checkDuration += duration
+	if duration > b.maxCheck {
+		b.maxCheck = duration
+	}
+
+	idx := len(rateLimitCheckBounds)
+	for i, bound := range rateLimitCheckBounds {
+		if duration <= bound {
+			idx = i
+			break
+		}
+	}
+	b.checkCounts[idx]++
+}
+
+// merge adds the counts of another bucket
+func (b *rateLimitBucket) merge(other *rateLimitBucket) {
+	b.total += other.total
+	b.throttled += other.throttled
+	b.checkDuration += other.checkDuration
+	if other.maxCheck > b.maxCheck {
+		b.maxCheck = other.maxCheck
+	}
+	for i, count := range other.checkCounts {
+		b.checkCounts[i] += count
+	}
+}
+
+// quantile estimates the q-quantile of the check durations by linear interpolation within
+// the histogram bucket holding it, capped at the largest observed duration
+func (b *rateLimitBucket) quantile(q float64) time.Duration {
+	if b.total == 0 {
+		return 0
+	}
+
+	rank := q * float64(b.total)
+	var cumulative uint64
+	var lower time.Duration
+	for i, count := range b.checkCounts {
+		if count > 0 && float64(cumulative+count) >= rank {
+			if i == len(rateLimitCheckBounds) {
+				return b.maxCheck
+			}
+			upper := rateLimitCheckBounds[i]
+			estimate := lower + time.Duration((rank-float64(cumulative))/float64(count)*float64(upper-lower))
+			if estimate > b.maxCheck {
+				estimate = b.maxCheck
+			}
+			return estimate
+		}
+		cumulative += count
+		if i < len(rateLimitCheckBounds) {
+			lower = rateLimitCheckBounds[i]
+		}
+	}
+	return b.maxCheck
+}
+
+// point converts the aggregate into a time series point starting at start
+func (b *rateLimitBucket) point(start time.Time) RateLimitTimeSeriesPoint {
+	point := RateLimitTimeSeriesPoint{
+		Start:            start,
+		Requests:         b.total,
+		Throttled:        b.throttled,
+		MaxCheckDuration: b.maxCheck,
+		P95CheckDuration: b.quantile(0.95),
+	}
+	if b.total > 0 {
+		point.ThrottleRate = float64(b.throttled) / float64(b.total) * 100
+		point.AvgCheckDuration = b.checkDuration / time.Duration(b.total)
+	}
+	return point
+}
+
+// observeTimeSeries adds a check to the bucket of its minute; the caller must hold rlm.mu for writing
+func (rlm *RateLimitMetrics) observeTimeSeries(at time.Time, duration time.Duration, allowed bool) {
+	if len(rlm.timeSeries) == 0 {
+		return
+	}
+
+	minute := at.Unix() / 60
+	bucket := &rlm.timeSeries[minute%int64(len(rlm.timeSeries))]
+	if bucket.minute != minute {
+		*bucket = rateLimitBucket{minute: minute}
+	}
+	bucket.observe(duration, allowed)
+}
+
+// aggregateLocked merges the buckets of minutes in [from, to]; the caller must hold rlm.mu
+func (rlm *RateLimitMetrics) aggregateLocked(from, to int64) rateLimitBucket {
+	var total rateLimitBucket
+	size := int64(len(rlm.timeSeries))
+	if size == 0 {
+		return total
+	}
+	if oldest := to - size + 1; from < oldest {
+		from = oldest
+	}
+
+	for minute := from; minute <= to; minute++ {
+		bucket := &rlm.timeSeries[minute%size]
+		if bucket.minute == minute {
+			total.merge(bucket)
+		}
+	}
+	return total
+}
+
+// GetTimeSeries returns the rate limit aggregates of the last window, one point per resolution, oldest first.
+// Resolution is rounded up to whole minutes and points are aligned to multiples of it; the window is
+// capped at the retained history (DefaultTimeSeriesRetention). Intervals without checks are returned with zero counts.
+func (rlm *RateLimitMetrics) GetTimeSeries(window, resolution time.Duration) []RateLimitTimeSeriesPoint {
+	rlm.mu.RLock()
+	defer rlm.mu.RUnlock()
+
+	size := int64(len(rlm.timeSeries))
+	if size == 0 {
+		return []RateLimitTimeSeriesPoint{}
+	}
+
+	step := int64((resolution + time.Minute - 1) / time.Minute)
+	if step < 1 {
+		step = 1
+	}
+	minutes := int64((window + time.Minute - 1) / time.Minute)
+	if minutes < 1 || minutes > size {
+		minutes = size
+	}
+
+	now := rlm.clock.Now().Unix() / 60
+	oldest := now - minutes + 1
+	first := oldest - oldest%step
+
+	points := make([]RateLimitTimeSeriesPoint, 0, (now-first)/step+1)
+	for start := first; start <= now; start += step {
+		from := start
+		if from < oldest {
+			from = oldest
+		}
+		to := start + step - 1
+		if to > now {
+			to = now
+		}
+		aggregate := rlm.aggregateLocked(from, to)
+		points = append(points, aggregate.point(time.Unix(start*60, 0).UTC()))
+	}
+	return points
+}
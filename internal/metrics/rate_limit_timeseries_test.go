@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"go-server/pkg/clock"
+)
+
+func TestRateLimitTimeSeries(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+	now := time.Now()
+	// Pin the clock so the test cannot straddle a minute boundary
+	rlm.SetClock(clock.NewFake(now))
+
+	record := func(at time.Time, duration time.Duration, allowed bool) {
+		rlm.recordRequest(RateLimitRequest{IP: "127.0.0.1", Duration: duration, Allowed: allowed, Timestamp: at})
+	}
+	record(now.Add(-90*time.Minute), time.Millisecond, true)
+	record(now.Add(-5*time.Minute), 2*time.Millisecond, false)
+	record(now.Add(-5*time.Minute), 4*time.Millisecond, true)
+	record(now, time.Millisecond, true)
+
+	points := rlm.GetTimeSeries(10*time.Minute, time.Minute)
+	if len(points) != 10 {
+		t.Fatalf("Expected 10 one-minute points, got %d", len(points))
+	}
+	last := points[len(points)-1]
+	if last.Requests != 1 || !last.Start.Equal(now.Truncate(time.Minute).UTC()) {
+		t.Errorf("Expected the current minute to hold 1 request, got %+v", last)
+	}
+	fiveMinutesAgo := points[len(points)-6]
+	if fiveMinutesAgo.Requests != 2 || fiveMinutesAgo.Throttled != 1 || fiveMinutesAgo.ThrottleRate != 50 {
+		t.Errorf("Expected 2 requests with 1 throttled five minutes ago, got %+v", fiveMinutesAgo)
+	}
+	if fiveMinutesAgo.AvgCheckDuration != 3*time.Millisecond || fiveMinutesAgo.MaxCheckDuration != 4*time.Millisecond {
+		t.Errorf("Expected 3ms average and 4ms max check duration, got %+v", fiveMinutesAgo)
+	}
+
+	var total uint64
+	for _, point := range rlm.GetTimeSeries(2*time.Hour, 30*time.Minute) {
+		if point.Start.Minute()%30 != 0 {
+			t.Errorf("Expected points aligned to 30 minutes, got %v", point.Start)
+		}
+		total += point.Requests
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 requests over two hours, got %d", total)
+	}
+
+	rlm.Reset()
+	for _, point := range rlm.GetTimeSeries(time.Hour, time.Minute) {
+		if point.Requests != 0 {
+			t.Errorf("Expected no requests after reset, got %+v", point)
+		}
+	}
+}
+
+func TestRateLimitTimeSeriesStaleBuckets(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+	now := time.Now()
+
+	// A check one full retention ago maps to the same bucket as the current minute
+	rlm.recordRequest(RateLimitRequest{Duration: time.Millisecond, Allowed: false, Timestamp: now.Add(-DefaultTimeSeriesRetention)})
+	rlm.recordRequest(RateLimitRequest{Duration: time.Millisecond, Allowed: true, Timestamp: now})
+
+	points := rlm.GetTimeSeries(time.Minute, time.Minute)
+	if len(points) != 1 || points[0].Requests != 1 || points[0].Throttled != 0 {
+		t.Errorf("Expected the stale bucket to be replaced, got %+v", points)
+	}
+}
+
+func TestEffectivenessBeyondRequestHistory(t *testing.T) {
+	rlm := NewRateLimitMetrics()
+	rlm.SetMaxHistorySize(10)
+
+	for i := 0; i < 100; i++ {
+		rlm.RecordRequest("127.0.0.1", "", "/test", time.Millisecond, i%4 != 0, "", 1, 100)
+	}
+
+	effectiveness := rlm.GetEffectivenessMetrics(time.Hour)
+	if effectiveness.ThrottleRate != 25 {
+		t.Errorf("Expected throttle rate over all 100 requests to be 25%%, got %f", effectiveness.ThrottleRate)
+	}
+	if expected := 100 / time.Hour.Seconds(); effectiveness.RequestsPerSecond != expected {
+		t.Errorf("Expected %f requests per second, got %f", expected, effectiveness.RequestsPerSecond)
+	}
+}
+
+func TestRateLimitBucketQuantile(t *testing.T) {
+	var bucket rateLimitBucket
+	if bucket.quantile(0.95) != 0 {
+		t.Error("Expected zero quantile for an empty bucket")
+	}
+
+	bucket.observe(time.Second, true)
+	if bucket.quantile(0.5) != time.Second {
+		t.Errorf("Expected checks above the largest bound to report the max, got %v", bucket.quantile(0.5))
+	}
+}
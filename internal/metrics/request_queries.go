@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default thresholds of RequestQueryMetrics
+const (
+	DefaultMaxRequestQueries  = 50 // statements per request before the request is flagged
+	DefaultRepeatThreshold    = 10 // executions of one statement shape per request that indicate an N+1 pattern
+	DefaultMaxQueryOffenders  = 20 // routes kept in the offender list
+	maxTrackedStatementShapes = 256
+	maxTrackedOffenderRoutes  = 1000
+)
+
+var (
+	// statementLiteralRegex matches placeholders, quoted strings and numbers in SQL
+	statementLiteralRegex = regexp.MustCompile(`\$\d+|'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+	// statementListRegex matches placeholder lists such as (?, ?, ?) left after literal replacement
+	statementListRegex = regexp.MustCompile(`\(\?(?:\s*,\s*\?)+\)`)
+)
+
+// RequestQueryMetrics counts the database statements executed for each HTTP request,
+// keyed by the request's correlation ID, and flags requests that run too many statements
+// or repeat one statement shape often enough to suggest an N+1 pattern.
+// Flagged requests are aggregated per route so the worst offenders can be listed.
+// Concurrent requests that share a correlation ID (propagated by a caller) are counted together.
+type RequestQueryMetrics struct {
+	maxQueries      int
+	repeatThreshold int
+	maxOffenders    int
+
+	mu        sync.Mutex
+	active    map[string]*requestQueries
+	offenders map[string]*QueryOffender
+	requests  uint64
+	flagged   uint64
+}
+
+// requestQueries holds the statements counted for one correlation ID
+type requestQueries struct {
+	refs       int
+	count      int
+	statements map[string]int
+	flagged    bool
+	stack      []string
+}
+
+// RequestQueryReport summarizes the statements executed for one request
+type RequestQueryReport struct {
+	Queries        int      `json:"queries"`
+	TopStatement   string   `json:"top_statement,omitempty"`
+	TopRepetitions int      `json:"top_repetitions"`
+	TooManyQueries bool     `json:"too_many_queries"`
+	NPlusOne       bool     `json:"n_plus_one"`
+	Stack          []string `json:"stack,omitempty"` // application frames that issued the statement crossing a threshold
+}
+
+// Flagged reports whether the request crossed either threshold
+func (r RequestQueryReport) Flagged() bool {
+	return r.TooManyQueries || r.NPlusOne
+}
+
+// QueryOffender aggregates the flagged requests of one route
+type QueryOffender struct {
+	Method            string    `json:"method"`
+	Route             string    `json:"route"`
+	FlaggedRequests   uint64    `json:"flagged_requests"`
+	NPlusOneRequests  uint64    `json:"n_plus_one_requests"`
+	MaxQueries        int       `json:"max_queries"`
+	LastQueries       int       `json:"last_queries"`
+	TopStatement      string    `json:"top_statement,omitempty"`
+	TopRepetitions    int       `json:"top_repetitions"`
+	Stack             []string  `json:"stack,omitempty"`
+	LastCorrelationID string    `json:"last_correlation_id"`
+	LastSeen          time.Time `json:"last_seen"`
+}
+
+// RequestQueryStats represents the thresholds, totals and worst offending routes
+type RequestQueryStats struct {
+	MaxQueries      int             `json:"max_queries"`
+	RepeatThreshold int             `json:"repeat_threshold"`
+	Requests        uint64          `json:"requests"`
+	FlaggedRequests uint64          `json:"flagged_requests"`
+	Offenders       []QueryOffender `json:"offenders"`
+}
+
+// NewRequestQueryMetrics creates request query metrics; non-positive arguments use the defaults
+func NewRequestQueryMetrics(maxQueries, repeatThreshold, maxOffenders int) *RequestQueryMetrics {
+	if maxQueries <= 0 {
+		maxQueries = DefaultMaxRequestQueries
+	}
+	if repeatThreshold <= 0 {
+		repeatThreshold = DefaultRepeatThreshold
+	}
+	if maxOffenders <= 0 {
+		maxOffenders = DefaultMaxQueryOffenders
+	}
+	return &RequestQueryMetrics{
+		maxQueries:      maxQueries,
+		repeatThreshold: repeatThreshold,
+		maxOffenders:    maxOffenders,
+		active:          make(map[string]*requestQueries),
+		offenders:       make(map[string]*QueryOffender),
+	}
+}
+
+// Begin starts counting statements for a request
+func (m *RequestQueryMetrics) Begin(correlationID string) {
+	if correlationID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.active[correlationID]
+	if !ok {
+		r = &requestQueries{statements: make(map[string]int)}
+		m.active[correlationID] = r
+	}
+	r.refs++
+}
+
+// Record counts a statement executed on behalf of a request. Statements for correlation IDs
+// without an active request are ignored. stack is called at most once per request, when the
+// request first crosses a threshold, to capture where the statement was issued
+func (m *RequestQueryMetrics) Record(correlationID, statement string, stack func() []string) {
+	if correlationID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.active[correlationID]
+	if !ok {
+		return
+	}
+	r.count++
+	shape := NormalizeStatement(statement)
+	if _, tracked := r.statements[shape]; tracked || len(r.statements) < maxTrackedStatementShapes {
+		r.statements[shape]++
+	}
+
+	if !r.flagged && (r.count > m.maxQueries || r.statements[shape] >= m.repeatThreshold) {
+		r.flagged = true
+		if stack != nil {
+			r.stack = stack()
+		}
+	}
+}
+
+// End stops counting statements for a request and returns its report.
+// Flagged requests are added to the offenders of the method and route
+func (m *RequestQueryMetrics) End(correlationID, method, route string) RequestQueryReport {
+	if correlationID == "" {
+		return RequestQueryReport{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.active[correlationID]
+	if !ok {
+		return RequestQueryReport{}
+	}
+	r.refs--
+	if r.refs <= 0 {
+		delete(m.active, correlationID)
+	}
+
+	report := RequestQueryReport{Queries: r.count, Stack: r.stack}
+	for shape, count := range r.statements {
+		if count > report.TopRepetitions || (count == report.TopRepetitions && shape < report.TopStatement) {
+			report.TopStatement, report.TopRepetitions = shape, count
+		}
+	}
+	report.TooManyQueries = report.Queries > m.maxQueries
+	report.NPlusOne = report.TopRepetitions >= m.repeatThreshold
+
+	m.requests++
+	if report.Flagged() {
+		m.flagged++
+		m.recordOffender(correlationID, method, route, report)
+	}
+	return report
+}
+
+// recordOffender adds a flagged request to its route; callers hold the lock
+func (m *RequestQueryMetrics) recordOffender(correlationID, method, route string, report RequestQueryReport) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	key := method + " " + route
+	offender, ok := m.offenders[key]
+	if !ok {
+		if len(m.offenders) >= maxTrackedOffenderRoutes {
+			return
+		}
+		offender = &QueryOffender{Method: method, Route: route}
+		m.offenders[key] = offender
+	}
+
+	offender.FlaggedRequests++
+	if report.NPlusOne {
+		offender.NPlusOneRequests++
+	}
+	offender.LastQueries = report.Queries
+	offender.LastCorrelationID = correlationID
+	offender.LastSeen = time.Now()
+	if report.Queries >= offender.MaxQueries {
+		offender.MaxQueries = report.Queries
+		offender.TopStatement = report.TopStatement
+		offender.TopRepetitions = report.TopRepetitions
+		offender.Stack = report.Stack
+	}
+}
+
+// GetStats returns the totals and the worst offending routes, most statements per request first
+func (m *RequestQueryMetrics) GetStats() RequestQueryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offenders := make([]QueryOffender, 0, len(m.offenders))
+	for _, offender := range m.offenders {
+		offenders = append(offenders, *offender)
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].MaxQueries != offenders[j].MaxQueries {
+			return offenders[i].MaxQueries > offenders[j].MaxQueries
+		}
+		if offenders[i].FlaggedRequests != offenders[j].FlaggedRequests {
+			return offenders[i].FlaggedRequests > offenders[j].FlaggedRequests
+		}
+		return offenders[i].Method+offenders[i].Route < offenders[j].Method+offenders[j].Route
+	})
+	if len(offenders) > m.maxOffenders {
+		offenders = offenders[:m.maxOffenders]
+	}
+
+	return RequestQueryStats{
+		MaxQueries:      m.maxQueries,
+		RepeatThreshold: m.repeatThreshold,
+		Requests:        m.requests,
+		FlaggedRequests: m.flagged,
+		Offenders:       offenders,
+	}
+}
+
+// Reset clears the totals and offenders; requests in progress keep counting
+func (m *RequestQueryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.offenders = make(map[string]*QueryOffender)
+	m.requests = 0
+	m.flagged = 0
+}
+
+// NormalizeStatement reduces a SQL statement to its shape: placeholders and literals become ?,
+// lists of values collapse to (?) and whitespace is collapsed, so the same query issued with
+// different arguments is counted as one statement
+func NormalizeStatement(statement string) string {
+	shape := statementLiteralRegex.ReplaceAllString(statement, "?")
+	shape = statementListRegex.ReplaceAllString(shape, "(?)")
+	return strings.Join(strings.Fields(shape), " ")
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalizeStatement(t *testing.T) {
+	cases := map[string]string{
+		`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL`: `SELECT * FROM "users" WHERE id = ? AND "users"."deleted_at" IS NULL`,
+		"SELECT * FROM users WHERE name = 'o''brien'   LIMIT 10":               "SELECT * FROM users WHERE name = ? LIMIT ?",
+		"SELECT * FROM roles WHERE user_id IN ($1,$2, $3)":                     "SELECT * FROM roles WHERE user_id IN (?)",
+		"SELECT * FROM roles WHERE user_id IN (1)":                             "SELECT * FROM roles WHERE user_id IN (?)",
+		"SELECT * FROM t2 WHERE a =\n\t1.5":                                    "SELECT * FROM t2 WHERE a = ?",
+	}
+	for statement, want := range cases {
+		if got := NormalizeStatement(statement); got != want {
+			t.Errorf("NormalizeStatement(%q) = %q, want %q", statement, got, want)
+		}
+	}
+}
+
+func TestRequestQueryMetrics_NPlusOne(t *testing.T) {
+	m := NewRequestQueryMetrics(50, 3, 10)
+
+	stackCalls := 0
+	stack := func() []string {
+		stackCalls++
+		return []string{"go-server/internal/repositories.(*userRepository).GetByID (user_repository.go:42)"}
+	}
+
+	m.Begin("req-1")
+	m.Record("req-1", "SELECT * FROM users LIMIT $1", stack)
+	for i := 1; i <= 5; i++ {
+		m.Record("req-1", fmt.Sprintf("SELECT * FROM roles WHERE user_id = %d", i), stack)
+	}
+	m.Record("unknown", "SELECT 1", stack)
+	report := m.End("req-1", "GET", "/api/v1/users")
+
+	if report.Queries != 6 {
+		t.Errorf("Expected 6 queries, got %d", report.Queries)
+	}
+	if !report.NPlusOne || report.TooManyQueries {
+		t.Errorf("Expected only an N+1 report, got %+v", report)
+	}
+	if report.TopStatement != "SELECT * FROM roles WHERE user_id = ?" || report.TopRepetitions != 5 {
+		t.Errorf("Unexpected top statement %q repeated %d times", report.TopStatement, report.TopRepetitions)
+	}
+	if stackCalls != 1 || len(report.Stack) != 1 {
+		t.Errorf("Expected the stack to be captured once, got %d calls and %v", stackCalls, report.Stack)
+	}
+
+	// 请求结束后的语句不再计数
+	m.Record("req-1", "SELECT 1", stack)
+	if report := m.End("req-1", "GET", "/api/v1/users"); report.Queries != 0 {
+		t.Errorf("Expected an empty report for a finished request, got %+v", report)
+	}
+
+	stats := m.GetStats()
+	if stats.Requests != 1 || stats.FlaggedRequests != 1 {
+		t.Errorf("Expected 1 flagged request out of 1, got %d out of %d", stats.FlaggedRequests, stats.Requests)
+	}
+	if len(stats.Offenders) != 1 {
+		t.Fatalf("Expected 1 offender, got %d", len(stats.Offenders))
+	}
+	offender := stats.Offenders[0]
+	if offender.Method != "GET" || offender.Route != "/api/v1/users" || offender.NPlusOneRequests != 1 || offender.LastCorrelationID != "req-1" {
+		t.Errorf("Unexpected offender %+v", offender)
+	}
+}
+
+func TestRequestQueryMetrics_TooManyQueries(t *testing.T) {
+	m := NewRequestQueryMetrics(3, 10, 10)
+
+	m.Begin("req-1")
+	for _, table := range []string{"users", "roles", "tenants", "sessions"} {
+		m.Record("req-1", "SELECT * FROM "+table, nil)
+	}
+	report := m.End("req-1", "GET", "")
+	if !report.TooManyQueries || report.NPlusOne {
+		t.Errorf("Expected only a too-many-queries report, got %+v", report)
+	}
+
+	m.Begin("req-2")
+	m.Record("req-2", "SELECT * FROM users", nil)
+	if report := m.End("req-2", "GET", "/api/v1/users/:id"); report.Flagged() {
+		t.Errorf("Expected request under the thresholds not to be flagged, got %+v", report)
+	}
+
+	stats := m.GetStats()
+	if stats.Requests != 2 || stats.FlaggedRequests != 1 {
+		t.Errorf("Expected 1 flagged request out of 2, got %d out of %d", stats.FlaggedRequests, stats.Requests)
+	}
+	if len(stats.Offenders) != 1 || stats.Offenders[0].Route != UnmatchedRoute {
+		t.Fatalf("Expected the unmatched route as the only offender, got %+v", stats.Offenders)
+	}
+}
+
+func TestRequestQueryMetrics_SharedCorrelationID(t *testing.T) {
+	m := NewRequestQueryMetrics(0, 0, 0)
+
+	// 携带同一关联ID的并发请求共同计数，最后一个请求结束前仍然计数
+	m.Begin("shared")
+	m.Begin("shared")
+	m.Record("shared", "SELECT 1", nil)
+	m.End("shared", "GET", "/a")
+	m.Record("shared", "SELECT 2", nil)
+	if report := m.End("shared", "GET", "/b"); report.Queries != 2 {
+		t.Errorf("Expected 2 queries for the shared correlation ID, got %d", report.Queries)
+	}
+
+	m.Begin("")
+	m.Record("", "SELECT 1", nil)
+	if report := m.End("", "GET", "/a"); report.Queries != 0 {
+		t.Errorf("Expected requests without a correlation ID to be ignored, got %+v", report)
+	}
+}
+
+func TestRequestQueryMetrics_OffenderOrder(t *testing.T) {
+	m := NewRequestQueryMetrics(1, 10, 2)
+
+	for i, route := range []string{"/a", "/b", "/c"} {
+		id := fmt.Sprintf("req-%d", i)
+		m.Begin(id)
+		for q := 0; q <= i+1; q++ {
+			m.Record(id, fmt.Sprintf("SELECT * FROM t%d", q), nil)
+		}
+		m.End(id, "GET", route)
+	}
+
+	stats := m.GetStats()
+	if len(stats.Offenders) != 2 {
+		t.Fatalf("Expected the offender list to be limited to 2, got %d", len(stats.Offenders))
+	}
+	if stats.Offenders[0].Route != "/c" || stats.Offenders[1].Route != "/b" {
+		t.Errorf("Expected offenders ordered by max queries, got %s, %s", stats.Offenders[0].Route, stats.Offenders[1].Route)
+	}
+
+	m.Reset()
+	if stats := m.GetStats(); stats.Requests != 0 || len(stats.Offenders) != 0 {
+		t.Errorf("Expected reset to clear totals and offenders, got %+v", stats)
+	}
+}
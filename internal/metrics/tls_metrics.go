@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TLSCertificateStats describes the certificate managed for one domain
+type TLSCertificateStats struct {
+	Domain   string    `json:"domain" example:"api.example.com"`
+	NotAfter time.Time `json:"not_after"` // expiry of the certificate last served or issued; zero until one is seen
+	Issued   uint64    `json:"issued"`    // certificates obtained or renewed
+	Errors   uint64    `json:"errors"`    // failures to load or obtain a certificate during a handshake
+}
+
+// TLSMetrics tracks the certificates obtained through ACME, so operators can alert on failed renewals
+// before a certificate expires
+type TLSMetrics struct {
+	mu      sync.Mutex
+	domains map[string]*TLSCertificateStats
+}
+
+// NewTLSMetrics creates empty TLS certificate metrics
+func NewTLSMetrics() *TLSMetrics {
+	return &TLSMetrics{domains: make(map[string]*TLSCertificateStats)}
+}
+
+// RecordIssued records a certificate obtained or renewed for domain
+func (m *TLSMetrics) RecordIssued(domain string, notAfter time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.domain(domain)
+	stats.Issued++
+	stats.NotAfter = notAfter
+}
+
+// RecordCertificate records the expiry of the certificate served for domain
+func (m *TLSMetrics) RecordCertificate(domain string, notAfter time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.domain(domain).NotAfter = notAfter
+}
+
+// RecordError records a failure to load or obtain a certificate for domain
+func (m *TLSMetrics) RecordError(domain string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.domain(domain).Errors++
+}
+
+// domain returns the stats of domain, creating them if needed; callers hold the lock
+func (m *TLSMetrics) domain(domain string) *TLSCertificateStats {
+	stats, ok := m.domains[domain]
+	if !ok {
+		stats = &TLSCertificateStats{Domain: domain}
+		m.domains[domain] = stats
+	}
+	return stats
+}
+
+// Certificates returns the certificate stats ordered by domain
+func (m *TLSMetrics) Certificates() []TLSCertificateStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	certificates := make([]TLSCertificateStats, 0, len(m.domains))
+	for _, stats := range m.domains {
+		certificates = append(certificates, *stats)
+	}
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].Domain < certificates[j].Domain
+	})
+	return certificates
+}
+
+// writePrometheus writes the certificate expiry gauge and the issuance and error counters
+func (m *TLSMetrics) writePrometheus(p *promWriter) {
+	certificates := m.Certificates()
+	if len(certificates) == 0 {
+		return
+	}
+
+	p.header("tls_certificate_expiry_timestamp_seconds", "Expiry of the certificate served for the domain, as a Unix timestamp.", "gauge")
+	for _, c := range certificates {
+		if !c.NotAfter.IsZero() {
+			p.sample("tls_certificate_expiry_timestamp_seconds", float64(c.NotAfter.Unix()), "domain", c.Domain)
+		}
+	}
+	p.header("tls_certificate_issued_total", "Certificates obtained or renewed through ACME.", "counter")
+	for _, c := range certificates {
+		p.sample("tls_certificate_issued_total", float64(c.Issued), "domain", c.Domain)
+	}
+	p.header("tls_certificate_errors_total", "Handshakes that failed to load or obtain a certificate for the domain.", "counter")
+	for _, c := range certificates {
+		p.sample("tls_certificate_errors_total", float64(c.Errors), "domain", c.Domain)
+	}
+}
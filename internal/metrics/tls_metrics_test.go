@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTLSMetrics(t *testing.T) {
+	tlsMetrics := NewTLSMetrics()
+	notAfter := time.Unix(1800000000, 0)
+	tlsMetrics.RecordIssued("b.example.com", notAfter)
+	tlsMetrics.RecordCertificate("b.example.com", notAfter.Add(time.Hour))
+	tlsMetrics.RecordError("a.example.com")
+
+	certificates := tlsMetrics.Certificates()
+	if len(certificates) != 2 {
+		t.Fatalf("Expected 2 domains, got %d", len(certificates))
+	}
+	if c := certificates[0]; c.Domain != "a.example.com" || c.Errors != 1 || !c.NotAfter.IsZero() {
+		t.Errorf("Unexpected stats for a domain without a certificate: %+v", c)
+	}
+	if c := certificates[1]; c.Issued != 1 || !c.NotAfter.Equal(notAfter.Add(time.Hour)) {
+		t.Errorf("Unexpected stats for an issued certificate: %+v", c)
+	}
+
+	registry := NewRegistry()
+	registry.RegisterTLS(tlsMetrics)
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	for _, line := range []string{
+		"# TYPE tls_certificate_expiry_timestamp_seconds gauge",
+		`tls_certificate_expiry_timestamp_seconds{domain="b.example.com"} 1.8000036e+09`,
+		`tls_certificate_issued_total{domain="b.example.com"} 1`,
+		`tls_certificate_errors_total{domain="a.example.com"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `tls_certificate_expiry_timestamp_seconds{domain="a.example.com"}`) {
+		t.Errorf("Domains without a certificate should have no expiry sample, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// 日志和压缩中间件每个请求的内存分配预算，只统计中间件本身，不含路由和处理函数的分配
+// 关联ID、请求ID、链路上下文和请求上下文的分配是必需的，其余状态从池中复用
+const (
+	loggingAllocBudget         = 27 // 日志级别过滤掉请求日志时
+	loggingEnabledAllocBudget  = 41 // 记录请求日志时，含字段装箱、消息拼接和 zap 编码
+	loggingCaptureAllocBudget  = 27 // 启用请求和响应体记录且请求日志被过滤时，不做脱敏
+	compressionAllocBudget     = 4  // 压缩响应时
+	compressionSkipAllocBudget = 0  // 客户端不支持压缩时
+)
+
+// middlewareAllocs 返回中间件在每个请求上额外的内存分配次数
+func middlewareAllocs(t *testing.T, newRequest func() *http.Request, handler gin.HandlerFunc, middleware ...gin.HandlerFunc) float64 {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("sync.Pool 在竞态检测下会随机丢弃对象，分配次数不稳定")
+	}
+	gin.SetMode(gin.TestMode)
+
+	measure := func(middleware ...gin.HandlerFunc) float64 {
+		router := gin.New()
+		router.Use(middleware...)
+		router.Any("/test", handler)
+
+		serve := func() {
+			router.ServeHTTP(httptest.NewRecorder(), newRequest())
+		}
+		serve() // 预热池和路由
+		return testing.AllocsPerRun(200, serve)
+	}
+	return measure(middleware...) - measure()
+}
+
+// useFileLogger 使用写入临时目录、指定级别的日志管理器，测试结束后关闭
+func useFileLogger(t *testing.T, level string) *config.Config {
+	t.Helper()
+	cfg := &config.Config{
+		Logging: config.LoggingConfig{
+			Level:     level,
+			Format:    "json",
+			Output:    "file",
+			Directory: t.TempDir(),
+		},
+	}
+	require.NoError(t, InitializeLoggerManager(cfg))
+	t.Cleanup(func() { ShutdownLoggerManager() })
+	return cfg
+}
+
+func TestStructuredLoggingMiddleware_AllocationBudget(t *testing.T) {
+	okHandler := func(c *gin.Context) { c.String(http.StatusOK, "OK") }
+	getRequest := func() *http.Request { return httptest.NewRequest(http.MethodGet, "/test", nil) }
+
+	t.Run("LevelFiltered", func(t *testing.T) {
+		cfg := useFileLogger(t, "error")
+		allocs := middlewareAllocs(t, getRequest, okHandler, StructuredLoggingMiddleware(cfg))
+		if allocs > loggingAllocBudget {
+			t.Errorf("Expected at most %d allocations per request with the request log filtered, got %.0f", loggingAllocBudget, allocs)
+		}
+	})
+
+	t.Run("LevelEnabled", func(t *testing.T) {
+		cfg := useFileLogger(t, "info")
+		allocs := middlewareAllocs(t, getRequest, okHandler, StructuredLoggingMiddleware(cfg))
+		if allocs > loggingEnabledAllocBudget {
+			t.Errorf("Expected at most %d allocations per logged request, got %.0f", loggingEnabledAllocBudget, allocs)
+		}
+	})
+
+	t.Run("BodyCapture", func(t *testing.T) {
+		cfg := useFileLogger(t, "error")
+		cfg.Logging.BodyCapture = config.LoggingBodyCaptureConfig{Enabled: true, MaxBytes: 1024}
+		t.Cleanup(func() { UpdateBodyCaptureConfig(config.LoggingBodyCaptureConfig{}) })
+
+		postRequest := func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"alice"}`))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}
+		jsonHandler := func(c *gin.Context) {
+			c.GetRawData()
+			c.Data(http.StatusOK, "application/json", []byte(`{"ok":true}`))
+		}
+		allocs := middlewareAllocs(t, postRequest, jsonHandler, StructuredLoggingMiddleware(cfg))
+		if allocs > loggingCaptureAllocBudget {
+			t.Errorf("Expected at most %d allocations per request with body capture, got %.0f", loggingCaptureAllocBudget, allocs)
+		}
+	})
+}
+
+func TestCompressionMiddleware_AllocationBudget(t *testing.T) {
+	response := strings.Repeat("compressible ", 200)
+	handler := func(c *gin.Context) { c.String(http.StatusOK, response) }
+
+	t.Run("Compressed", func(t *testing.T) {
+		newRequest := func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			return req
+		}
+		allocs := middlewareAllocs(t, newRequest, handler, CompressionMiddleware(1024))
+		if allocs > compressionAllocBudget {
+			t.Errorf("Expected at most %d allocations per compressed response, got %.0f", compressionAllocBudget, allocs)
+		}
+	})
+
+	t.Run("NotAccepted", func(t *testing.T) {
+		newRequest := func() *http.Request { return httptest.NewRequest(http.MethodGet, "/test", nil) }
+		allocs := middlewareAllocs(t, newRequest, handler, CompressionMiddleware(1024))
+		if allocs > compressionSkipAllocBudget {
+			t.Errorf("Expected at most %d allocations when the client does not accept gzip, got %.0f", compressionSkipAllocBudget, allocs)
+		}
+	})
+}
+
+// BenchmarkStructuredLoggingMiddleware_LevelFiltered 请求日志被日志级别过滤时的开销
+func BenchmarkStructuredLoggingMiddleware_LevelFiltered(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Logging: config.LoggingConfig{Level: "error", Format: "json", Output: "file", Directory: b.TempDir()},
+	}
+	require.NoError(b, InitializeLoggerManager(cfg))
+	defer ShutdownLoggerManager()
+
+	router := gin.New()
+	router.Use(StructuredLoggingMiddleware(cfg))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// BenchmarkStructuredLoggingMiddleware_BodyCapture 启用请求和响应体记录时的开销
+func BenchmarkStructuredLoggingMiddleware_BodyCapture(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Logging: config.LoggingConfig{
+			Level:       "error",
+			Format:      "json",
+			Output:      "file",
+			Directory:   b.TempDir(),
+			BodyCapture: config.LoggingBodyCaptureConfig{Enabled: true, MaxBytes: 4096},
+		},
+	}
+	require.NoError(b, InitializeLoggerManager(cfg))
+	defer ShutdownLoggerManager()
+	defer UpdateBodyCaptureConfig(config.LoggingBodyCaptureConfig{})
+
+	router := gin.New()
+	router.Use(StructuredLoggingMiddleware(cfg))
+	response := []byte(`{"items":[` + strings.Repeat(`{"id":1,"name":"item"},`, 100) + `{"id":2}]}`)
+	router.GET("/test", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", response)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// BenchmarkParseAcceptEncoding 解析 Accept-Encoding 头的开销
+func BenchmarkParseAcceptEncoding(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseAcceptEncoding("br;q=1.0, deflate;q=0.5, gzip;q=0.8")
+	}
+}
@@ -12,11 +12,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// ImpersonationBannerHeader 模拟登录期间每个响应都携带的横幅响应头
+const ImpersonationBannerHeader = "X-Impersonation-Banner"
+
 func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			response.Error(c, http.StatusUnauthorized, "Authorization header is required")
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.missing_authorization"))
 			c.Abort()
 			return
 		}
@@ -24,14 +27,21 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		// Extract token from "Bearer <token>"
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			response.Error(c, http.StatusUnauthorized, "Bearer token is required")
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.bearer_required"))
 			c.Abort()
 			return
 		}
 
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
-			response.Error(c, http.StatusUnauthorized, "Invalid token")
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.invalid_token"))
+			c.Abort()
+			return
+		}
+
+		// Impersonation tokens must carry the banner claim so clients always show it
+		if claims.IsImpersonated() != (claims.Banner != "") {
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.invalid_token"))
 			c.Abort()
 			return
 		}
@@ -40,6 +50,11 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		if claims.IsImpersonated() {
+			c.Set("impersonator_id", claims.Act.Subject)
+			c.Set("impersonation_banner", claims.Banner)
+			c.Header(ImpersonationBannerHeader, claims.Banner)
+		}
 		c.Next()
 	}
 }
@@ -65,22 +80,22 @@ func AdminOnlyMiddleware(userRepo repositories.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userIDVal, exists := c.Get("user_id")
 		if !exists {
-			response.Error(c, http.StatusUnauthorized, "User not authenticated")
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.unauthenticated"))
 			c.Abort()
 			return
 		}
 
 		userID, ok := userIDVal.(string)
 		if !ok {
-			response.Error(c, http.StatusUnauthorized, "Invalid user ID format in context")
+			response.Error(c, http.StatusUnauthorized, localize(c, "auth.invalid_user_id"))
 			c.Abort()
 			return
 		}
 
 		// Fetch user model from the database using the correct repository method
-		userModel, err := userRepo.GetByID(userID)
+		userModel, err := userRepo.GetByID(c.Request.Context(), userID)
 		if err != nil {
-			response.Error(c, http.StatusForbidden, "User not found or repository error")
+			response.Error(c, http.StatusForbidden, localize(c, "auth.user_lookup_failed"))
 			c.Abort()
 			return
 		}
@@ -89,14 +104,14 @@ func AdminOnlyMiddleware(userRepo repositories.UserRepository) gin.HandlerFunc {
 		mapper := user.NewMapper()
 		userDomainModel, err := mapper.ToDomainModel(userModel)
 		if err != nil {
-			response.Error(c, http.StatusInternalServerError, "Could not process user data")
+			response.Error(c, http.StatusInternalServerError, localize(c, "auth.user_data_failed"))
 			c.Abort()
 			return
 		}
 
 		// Check if the user has an admin role
 		if !userDomainModel.IsAdmin() {
-			response.Error(c, http.StatusForbidden, "Admin access required")
+			response.Error(c, http.StatusForbidden, localize(c, "auth.admin_required"))
 			c.Abort()
 			return
 		}
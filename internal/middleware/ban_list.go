@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"go-server/pkg/banlist"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// banListIPCheckedKey 标记请求的客户端 IP 已检查过封禁，认证后再次安装时只检查用户
+const banListIPCheckedKey = "ban_list_ip_checked"
+
+// BanListMiddleware 拒绝被封禁的调用方，返回 403 和 Retry-After 头
+// 全局安装时检查客户端 IP，在认证中间件之后安装时还检查用户ID；banList 为 nil 时直接放行。
+// 读取封禁名单失败时放行请求，与速率限制的 fail-open 策略一致
+func BanListMiddleware(banList *banlist.BanList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if banList == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		var ban *banlist.Ban
+		var err error
+		if !c.GetBool(banListIPCheckedKey) {
+			c.Set(banListIPCheckedKey, true)
+			ban, err = banList.Check(ctx, banlist.TypeIP, c.ClientIP())
+		}
+		if ban == nil && err == nil {
+			if userID := c.GetString("user_id"); userID != "" {
+				ban, err = banList.Check(ctx, banlist.TypeUser, userID)
+			}
+		}
+		if err != nil || ban == nil {
+			c.Next()
+			return
+		}
+
+		if retryAfter := time.Until(ban.ExpiresAt); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		}
+		response.ForbiddenError(c, localize(c, "ban_list.blocked"))
+		c.Abort()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/pkg/banlist"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBanListRouter 全局检查 IP，/me 模拟认证后再次检查用户
+func newBanListRouter(banList *banlist.BanList) *gin.Engine {
+	router := gin.New()
+	router.Use(BanListMiddleware(banList))
+	router.GET("/public", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	authenticated := func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+		c.Next()
+	}
+	router.GET("/me", authenticated, BanListMiddleware(banList), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestBanListMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	banList := banlist.New(banlist.NewMemoryStore(), func() []banlist.Offender {
+		return []banlist.Offender{
+			{Type: banlist.TypeIP, Identifier: "203.0.113.7", Violations: 100, ViolationRate: 90},
+			{Type: banlist.TypeUser, Identifier: "u1", Violations: 100, ViolationRate: 90},
+		}
+	}, banlist.Config{Duration: time.Hour})
+	_, err := banList.Evaluate(ctx)
+	require.NoError(t, err)
+	router := newBanListRouter(banList)
+
+	serve := func(path, ip, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":12345"
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("被封禁的 IP 返回 403", func(t *testing.T) {
+		w := serve("/public", "203.0.113.7", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "rate limit violations")
+	})
+
+	t.Run("未封禁的调用方正常访问", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/public", "198.51.100.1", "").Code)
+		assert.Equal(t, http.StatusOK, serve("/me", "198.51.100.1", "u2").Code)
+	})
+
+	t.Run("认证后检查被封禁的用户", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/public", "198.51.100.1", "u1").Code, "认证之前不知道用户")
+		assert.Equal(t, http.StatusForbidden, serve("/me", "198.51.100.1", "u1").Code)
+	})
+
+	t.Run("解除封禁后放行", func(t *testing.T) {
+		require.NoError(t, banList.Unban(ctx, banlist.TypeIP, "203.0.113.7"))
+		assert.Equal(t, http.StatusOK, serve("/public", "203.0.113.7", "").Code)
+	})
+
+	t.Run("未启用时直接放行", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/public", nil)
+		newBanListRouter(nil).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
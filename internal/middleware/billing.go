@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+
+	"go-server/internal/billing"
+	"go-server/internal/models"
+	"go-server/pkg/auth"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlanContextKey gin 上下文中保存当前套餐的键
+const PlanContextKey = "plan"
+
+// planState 当前请求的套餐，在第一次读取时才查询
+type planState struct {
+	service *billing.Service
+	userID  string // 从令牌识别的用户，分组上的认证中间件写入的 user_id 优先
+	once    sync.Once
+	plan    *models.Plan
+	err     error
+}
+
+// resolve 返回请求用户的套餐，匿名请求使用默认套餐
+func (s *planState) resolve(c *gin.Context) (*models.Plan, error) {
+	s.once.Do(func() {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			userID = s.userID
+		}
+		if userID == "" {
+			s.plan = s.service.DefaultPlan()
+			return
+		}
+		s.plan, s.err = s.service.PlanForUser(c.Request.Context(), userID)
+	})
+	return s.plan, s.err
+}
+
+// PlanMiddleware 将当前请求的套餐写入 gin 上下文，需安装在速率限制之前以便按套餐限流
+// 全局中间件运行时认证中间件尚未执行，这里从有效的访问令牌识别用户；无效的令牌按匿名处理，由认证中间件拒绝。
+// 套餐在第一次读取时才查询，不读取套餐的请求不访问缓存和数据库
+func PlanMiddleware(service *billing.Service, jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := &planState{service: service}
+		if tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && tokenString != "" && jwtManager != nil {
+			if claims, err := jwtManager.ValidateTokenWithContext(c.Request.Context(), tokenString); err == nil {
+				state.userID = claims.UserID
+			}
+		}
+
+		c.Set(PlanContextKey, state)
+		c.Next()
+	}
+}
+
+// CurrentPlan 返回当前请求的套餐，未启用订阅或查询失败时返回 false
+func CurrentPlan(c *gin.Context) (*models.Plan, bool) {
+	state, ok := currentPlanState(c)
+	if !ok {
+		return nil, false
+	}
+	plan, err := state.resolve(c)
+	return plan, err == nil
+}
+
+// planUserID 返回套餐中间件从令牌识别的用户，用于认证中间件之前的速率限制
+func planUserID(c *gin.Context) string {
+	if state, ok := currentPlanState(c); ok {
+		return state.userID
+	}
+	return ""
+}
+
+// currentPlanState 返回 gin 上下文中的套餐状态
+func currentPlanState(c *gin.Context) (*planState, bool) {
+	value, exists := c.Get(PlanContextKey)
+	if !exists {
+		return nil, false
+	}
+	state, ok := value.(*planState)
+	return state, ok
+}
+
+// RequireEntitlement 要求当前用户的套餐包含权益，否则返回 403，需要安装在认证中间件之后
+// 未启用订阅（没有安装套餐中间件）时直接放行，查询套餐失败时返回 503
+func RequireEntitlement(entitlement string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state, ok := currentPlanState(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		plan, err := state.resolve(c)
+		if err != nil {
+			response.ServiceUnavailableError(c, "billing", localize(c, "billing.resolve_plan_failed"))
+			c.Abort()
+			return
+		}
+		if !plan.HasEntitlement(entitlement) {
+			response.ErrorWithAppError(c, errors.NewEntitlementRequiredError(entitlement, plan.ID))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/internal/billing"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBilling 创建订阅服务，用户 pro 订阅了包含 advanced_search 权益的套餐
+func newTestBilling(t *testing.T) *billing.Service {
+	t.Helper()
+	subscriptions := repositories.NewMemorySubscriptionRepository()
+	require.NoError(t, subscriptions.Save(context.Background(), &models.Subscription{
+		UserID: "pro",
+		PlanID: "pro",
+		Status: models.SubscriptionStatusActive,
+	}))
+
+	service, err := billing.NewService(subscriptions, repositories.NewMemoryUserRepository(), nil, billing.Config{
+		Plans: []models.Plan{
+			{ID: "free"},
+			{ID: "pro", Entitlements: []string{"advanced_search"}, RateLimit: 600},
+		},
+		DefaultPlan: "free",
+	})
+	require.NoError(t, err)
+	return service
+}
+
+func TestRequireEntitlement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := newTestBilling(t)
+
+	newRouter := func(installPlan bool) *gin.Engine {
+		router := gin.New()
+		if installPlan {
+			router.Use(PlanMiddleware(service, nil))
+		}
+		authenticated := func(c *gin.Context) {
+			if user := c.GetHeader("X-Test-User"); user != "" {
+				c.Set("user_id", user)
+			}
+			c.Next()
+		}
+		router.GET("/search", authenticated, RequireEntitlement("advanced_search"), func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+		return router
+	}
+
+	serve := func(router *gin.Engine, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/search", nil)
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	router := newRouter(true)
+	assert.Equal(t, http.StatusOK, serve(router, "pro").Code)
+
+	w := serve(router, "free")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var body struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "ENTITLEMENT_REQUIRED", body.Error.Code)
+	assert.Equal(t, "advanced_search", body.Error.Details["entitlement"])
+	assert.Equal(t, "free", body.Error.Details["plan"])
+
+	// 未启用订阅时不限制权益
+	assert.Equal(t, http.StatusOK, serve(newRouter(false), "free").Code)
+}
+
+func TestPlanMiddleware_IdentifiesUserFromToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := auth.NewJWTManager("test-secret", 1)
+	token, err := jwtManager.GenerateToken("pro", "pro", "pro@example.com")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(PlanMiddleware(newTestBilling(t), jwtManager))
+	router.GET("/plan", func(c *gin.Context) {
+		plan, ok := CurrentPlan(c)
+		require.True(t, ok)
+		c.String(http.StatusOK, plan.ID+" "+planUserID(c))
+	})
+
+	for _, tc := range []struct {
+		authorization string
+		want          string
+	}{
+		{"Bearer " + token, "pro pro"},
+		{"Bearer invalid", "free "},
+		{"", "free "},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/plan", nil)
+		req.Header.Set("Authorization", tc.authorization)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Body.String(), "authorization %q", tc.authorization)
+	}
+}
+
+func TestRateLimiter_PlanLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := newTestBilling(t)
+	limiter := &DistributedRateLimiter{}
+	settings := &rateLimitSettings{anonymousRequests: 60, authenticatedRequests: 120}
+
+	for _, tc := range []struct {
+		user          string
+		authenticated bool
+		want          int
+	}{
+		{"pro", true, 600},
+		{"free", true, 120},
+		{"", false, 60},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		PlanMiddleware(service, nil)(c)
+		if tc.user != "" {
+			c.Set("user_id", tc.user)
+		}
+		assert.Equal(t, tc.want, limiter.limitFor(c, settings, tc.authenticated), "user %q", tc.user)
+	}
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"go-server/internal/config"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// decompressedLimitContextKey 用于在Gin上下文中存储解压后的请求体上限
+const decompressedLimitContextKey = "body_limit_decompressed"
+
+// defaultMaxDecompressedBytes 未安装请求体限制中间件时，压缩请求体解压后的上限
+const defaultMaxDecompressedBytes int64 = 10 << 20
+
+// bodyLimitRule 解析后的路由规则
+type bodyLimitRule struct {
+	pathPrefix        string
+	maxBytes          int64
+	multipartMaxBytes int64
+}
+
+// bodyLimitPolicy 解析后的请求体限制策略
+type bodyLimitPolicy struct {
+	enabled              bool
+	maxBytes             int64
+	multipartMaxBytes    int64
+	maxDecompressedBytes int64
+	rules                []bodyLimitRule // 按前缀长度降序排列
+}
+
+// newBodyLimitPolicy 将请求体限制配置解析为策略
+func newBodyLimitPolicy(cfg config.BodyLimitConfig) (*bodyLimitPolicy, error) {
+	if cfg.Enabled && cfg.MaxBytes <= 0 {
+		return nil, fmt.Errorf("请求体上限必须大于0: %d", cfg.MaxBytes)
+	}
+	if cfg.MultipartMaxBytes < 0 || cfg.MaxDecompressedBytes < 0 {
+		return nil, fmt.Errorf("请求体上限不能为负数")
+	}
+
+	policy := &bodyLimitPolicy{
+		enabled:              cfg.Enabled,
+		maxBytes:             cfg.MaxBytes,
+		multipartMaxBytes:    cfg.MultipartMaxBytes,
+		maxDecompressedBytes: cfg.MaxDecompressedBytes,
+	}
+	if policy.multipartMaxBytes == 0 {
+		policy.multipartMaxBytes = policy.maxBytes
+	}
+
+	for _, route := range cfg.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return nil, fmt.Errorf("路由前缀必须以 / 开头: %q", route.PathPrefix)
+		}
+		if route.MaxBytes < 0 || route.MultipartMaxBytes < 0 {
+			return nil, fmt.Errorf("路由 %s 的请求体上限不能为负数", route.PathPrefix)
+		}
+		policy.rules = append(policy.rules, bodyLimitRule{
+			pathPrefix:        route.PathPrefix,
+			maxBytes:          route.MaxBytes,
+			multipartMaxBytes: route.MultipartMaxBytes,
+		})
+	}
+	slices.SortStableFunc(policy.rules, func(a, b bodyLimitRule) int {
+		return len(b.pathPrefix) - len(a.pathPrefix)
+	})
+
+	return policy, nil
+}
+
+// limitFor 返回请求路径对应的请求体上限
+func (p *bodyLimitPolicy) limitFor(path string, multipart bool) int64 {
+	maxBytes, multipartMaxBytes := p.maxBytes, p.multipartMaxBytes
+	for _, rule := range p.rules {
+		if !strings.HasPrefix(path, rule.pathPrefix) {
+			continue
+		}
+		if rule.maxBytes > 0 {
+			maxBytes = rule.maxBytes
+		}
+		if rule.multipartMaxBytes > 0 {
+			multipartMaxBytes = rule.multipartMaxBytes
+		}
+		break
+	}
+
+	if multipart {
+		return multipartMaxBytes
+	}
+	return maxBytes
+}
+
+// BodyLimiter 支持配置热重载的请求体大小限制中间件
+// 请求体以流式方式限制而不会被缓存：声明的 Content-Length 超出上限时直接返回 413，
+// 否则读取超过上限时返回 *http.MaxBytesError，由读取方转换为 413 响应
+type BodyLimiter struct {
+	policy atomic.Pointer[bodyLimitPolicy]
+}
+
+// NewBodyLimiter 根据请求体大小限制配置创建中间件
+func NewBodyLimiter(cfg config.BodyLimitConfig) (*BodyLimiter, error) {
+	policy, err := newBodyLimitPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := &BodyLimiter{}
+	limiter.policy.Store(policy)
+	return limiter, nil
+}
+
+// Middleware 返回 gin 中间件，禁用时请求直接通过
+func (l *BodyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := l.policy.Load()
+		if !policy.enabled || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		multipart, err := isMultipartRequest(c.Request)
+		if err != nil {
+			response.ValidationError(c, localize(c, "validation.invalid_multipart"), errors.ErrorDetails{
+				Field:   "Content-Type",
+				Message: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		limit := policy.limitFor(c.Request.URL.Path, multipart)
+		if c.Request.ContentLength > limit {
+			// 不读取请求体，并通知服务器在响应后关闭连接
+			c.Header("Connection", "close")
+			response.PayloadTooLargeError(c, limit)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		decompressedLimit := policy.maxDecompressedBytes
+		if decompressedLimit == 0 {
+			decompressedLimit = limit
+		}
+		c.Set(decompressedLimitContextKey, decompressedLimit)
+
+		c.Next()
+	}
+}
+
+// Name 实现 config.Subscriber 接口
+func (l *BodyLimiter) Name() string {
+	return "body_limit"
+}
+
+// ValidateConfig 实现 config.Subscriber 接口，校验新的请求体大小限制配置
+func (l *BodyLimiter) ValidateConfig(newConfig *config.Config) error {
+	_, err := newBodyLimitPolicy(newConfig.BodyLimit)
+	return err
+}
+
+// ApplyConfig 实现 config.Subscriber 接口，原子替换限制策略，仅影响新请求
+func (l *BodyLimiter) ApplyConfig(oldConfig, newConfig *config.Config) error {
+	policy, err := newBodyLimitPolicy(newConfig.BodyLimit)
+	if err != nil {
+		return err
+	}
+	l.policy.Store(policy)
+	return nil
+}
+
+// isMultipartRequest 判断是否为 multipart 请求，缺少 boundary 的 multipart 请求返回错误
+func isMultipartRequest(r *http.Request) (bool, error) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(strings.ToLower(contentType), "multipart/") {
+		return false, nil
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true, err
+	}
+	if params["boundary"] == "" {
+		return true, http.ErrMissingBoundary
+	}
+	return true, nil
+}
+
+// decompressedBodyLimit 返回压缩请求体解压后的上限
+func decompressedBodyLimit(c *gin.Context) int64 {
+	if limit, ok := c.Get(decompressedLimitContextKey); ok {
+		if limit, ok := limit.(int64); ok && limit > 0 {
+			return limit
+		}
+	}
+	return defaultMaxDecompressedBytes
+}
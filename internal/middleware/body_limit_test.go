@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	stderrors "errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBodyLimitRouter 创建读取完整请求体的测试路由，读取超限时返回 413
+func newBodyLimitRouter(t *testing.T, handlers ...gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(handlers...)
+	router.Any("/*path", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			c.String(http.StatusRequestEntityTooLarge, "limit %d", maxBytesErr.Limit)
+			return
+		}
+		require.NoError(t, err)
+		c.String(http.StatusOK, "read %d", len(body))
+	})
+	return router
+}
+
+func newBodyLimitConfig() config.BodyLimitConfig {
+	return config.BodyLimitConfig{
+		Enabled:           true,
+		MaxBytes:          16,
+		MultipartMaxBytes: 512,
+		Routes: []config.BodyLimitRoute{
+			{PathPrefix: "/api/v1", MaxBytes: 32},
+			{PathPrefix: "/api/v1/uploads", MultipartMaxBytes: 1024},
+		},
+	}
+}
+
+// TestBodyLimiter_DeclaredLength 测试声明的 Content-Length 超限时不读取请求体直接返回 413
+func TestBodyLimiter_DeclaredLength(t *testing.T) {
+	limiter, err := NewBodyLimiter(newBodyLimitConfig())
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware())
+
+	req := httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(strings.Repeat("x", 17)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+
+	req = httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(strings.Repeat("x", 16)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "read 16", w.Body.String())
+}
+
+// TestRequestSizeLimitMiddleware 测试固定上限的中间件，上限小于等于 0 时不限制
+func TestRequestSizeLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxSize int64
+		want    int
+	}{
+		{"超出上限", 16, http.StatusRequestEntityTooLarge},
+		{"上限为0", 0, http.StatusOK},
+		{"上限为负数", -1, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var middleware gin.HandlerFunc
+			require.NotPanics(t, func() { middleware = RequestSizeLimitMiddleware(tt.maxSize) })
+			router := newBodyLimitRouter(t, middleware)
+
+			req := httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(strings.Repeat("x", 17)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+// TestBodyLimiter_Streaming 测试未声明长度的请求体在读取超限时失败
+func TestBodyLimiter_Streaming(t *testing.T) {
+	limiter, err := NewBodyLimiter(newBodyLimitConfig())
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware())
+
+	req := httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(strings.Repeat("x", 100)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "limit 16", w.Body.String())
+}
+
+// TestBodyLimiter_RouteRules 测试按路由前缀和 multipart 选择上限
+func TestBodyLimiter_RouteRules(t *testing.T) {
+	policy, err := newBodyLimitPolicy(newBodyLimitConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		path      string
+		multipart bool
+		expected  int64
+	}{
+		{"/health", false, 16},
+		{"/health", true, 512},
+		{"/api/v1/users", false, 32},
+		{"/api/v1/users", true, 512},
+		{"/api/v1/uploads/avatar", false, 16},
+		{"/api/v1/uploads/avatar", true, 1024},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.limitFor(tt.path, tt.multipart), tt.path)
+	}
+}
+
+// TestBodyLimiter_Multipart 测试 multipart 请求使用单独的上限并校验 boundary
+func TestBodyLimiter_Multipart(t *testing.T) {
+	limiter, err := NewBodyLimiter(newBodyLimitConfig())
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware())
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "a.txt")
+	require.NoError(t, err)
+	part.Write([]byte(strings.Repeat("x", 200)))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/uploads/file", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 普通请求体同样大小时超限
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/uploads/file", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// 缺少 boundary
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/uploads/file", strings.NewReader("x"))
+	req.Header.Set("Content-Type", "multipart/form-data")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestBodyLimiter_GzipBomb 测试 gzip 请求体解压后的大小同样受限
+func TestBodyLimiter_GzipBomb(t *testing.T) {
+	cfg := newBodyLimitConfig()
+	cfg.MaxBytes = 4096
+	cfg.MaxDecompressedBytes = 8192
+	cfg.Routes = nil
+	limiter, err := NewBodyLimiter(cfg)
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware(), CompressionMiddleware(1024))
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(bytes.Repeat([]byte{0}, 1<<20))
+	require.NoError(t, gz.Close())
+	require.Less(t, compressed.Len(), 4096)
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(compressed.Bytes()))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "limit 8192", w.Body.String())
+}
+
+// TestBodyLimiter_ZstdBomb 测试 zstd 请求体解压后的大小同样受限
+func TestBodyLimiter_ZstdBomb(t *testing.T) {
+	cfg := newBodyLimitConfig()
+	cfg.MaxBytes = 4096
+	cfg.MaxDecompressedBytes = 8192
+	cfg.Routes = nil
+	limiter, err := NewBodyLimiter(cfg)
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware(), CompressionMiddleware(1024))
+
+	var compressed bytes.Buffer
+	zw, err := zstd.NewWriter(&compressed)
+	require.NoError(t, err)
+	zw.Write(bytes.Repeat([]byte{0}, 1<<20))
+	require.NoError(t, zw.Close())
+	require.Less(t, compressed.Len(), 4096)
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(compressed.Bytes()))
+	req.Header.Set("Content-Encoding", "zstd")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+// TestBodyLimiter_ApplyConfig 测试热重载替换限制策略
+func TestBodyLimiter_ApplyConfig(t *testing.T) {
+	limiter, err := NewBodyLimiter(newBodyLimitConfig())
+	require.NoError(t, err)
+	router := newBodyLimitRouter(t, limiter.Middleware())
+
+	invalid := newBodyLimitConfig()
+	invalid.Routes = []config.BodyLimitRoute{{PathPrefix: "api", MaxBytes: 1}}
+	assert.Error(t, limiter.ValidateConfig(&config.Config{BodyLimit: invalid}))
+
+	disabled := newBodyLimitConfig()
+	disabled.Enabled = false
+	require.NoError(t, limiter.ApplyConfig(nil, &config.Config{BodyLimit: disabled}))
+
+	req := httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(strings.Repeat("x", 100)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "read 100", w.Body.String())
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go-server/internal/config"
+	"go-server/internal/tenancy"
+	"go-server/pkg/cache"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CoalescedHeader 标记响应来自同时进行的相同请求，值为 true
+const CoalescedHeader = "X-Coalesced"
+
+// coalescedResponse 首个请求记录的响应，由等待中的相同请求共享
+type coalescedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	data    interface{}
+	hasData bool
+}
+
+// coalescingWriter 在写出响应的同时保留副本
+type coalescingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+// Write 写入响应并保留副本，超过上限后不再保留
+func (w *coalescingWriter) Write(data []byte) (int, error) {
+	if !w.overflow {
+		if int64(w.body.Len()+len(data)) > w.limit {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应并保留副本
+func (w *coalescingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// RequestCoalescer 合并同时进行的相同 GET 请求
+// 挂载在具体的 GET 路由上（位于认证、权限和响应缓存中间件之后）。路径、排序后的查询参数、
+// 当前用户、租户和内容协商请求头都相同的并发请求只执行一次处理器，其余请求等待并返回相同的响应（带 X-Coalesced: true）。
+// 响应体超过上限、首个请求被客户端取消或处理器 panic 时不共享，等待的请求各自执行处理器
+type RequestCoalescer struct {
+	group       cache.Group
+	maxBodySize int64
+}
+
+// NewRequestCoalescer 根据配置创建请求合并中间件
+func NewRequestCoalescer(cfg config.CoalescingConfig) *RequestCoalescer {
+	return &RequestCoalescer{maxBodySize: cfg.MaxBodySize}
+}
+
+// Middleware 返回请求合并处理函数，rc 为 nil（未启用）时直接放行
+func (rc *RequestCoalescer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rc == nil || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		var (
+			leader    bool
+			recovered interface{}
+			panicked  bool
+		)
+		result, _, _ := rc.group.Do(rc.key(c), func() (interface{}, error) {
+			leader = true
+			defer func() {
+				if r := recover(); r != nil {
+					recovered, panicked = r, true
+				}
+			}()
+			return rc.execute(c), nil
+		})
+
+		if leader {
+			// 处理器的 panic 交给外层的恢复中间件处理
+			if panicked {
+				panic(recovered)
+			}
+			return
+		}
+
+		shared, ok := result.(*coalescedResponse)
+		if !ok || shared == nil {
+			c.Next()
+			return
+		}
+		rc.serve(c, shared)
+		c.Abort()
+	}
+}
+
+// execute 执行处理器并记录响应，响应不能共享时返回 nil
+func (rc *RequestCoalescer) execute(c *gin.Context) *coalescedResponse {
+	before := c.Writer.Header().Clone()
+	writer := &coalescingWriter{ResponseWriter: c.Writer, limit: rc.maxBodySize}
+	c.Writer = writer
+	defer func() { c.Writer = writer.ResponseWriter }()
+
+	c.Next()
+
+	if writer.overflow || c.Request.Context().Err() != nil {
+		return nil
+	}
+
+	// 只共享处理器链设置的响应头，之前的中间件已经为每个请求分别设置了关联ID等响应头
+	header := make(http.Header)
+	for name, values := range storableHeader(writer.Header()) {
+		if !slices.Equal(before.Values(name), values) {
+			header[name] = values
+		}
+	}
+
+	shared := &coalescedResponse{
+		status: writer.Status(),
+		header: header,
+		body:   writer.body.Bytes(),
+	}
+	shared.data, shared.hasData = response.DataFromContext(c)
+	return shared
+}
+
+// serve 发送共享的响应
+func (rc *RequestCoalescer) serve(c *gin.Context, shared *coalescedResponse) {
+	header := c.Writer.Header()
+	for name, values := range shared.header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set(CoalescedHeader, "true")
+	header.Set("Content-Length", strconv.Itoa(len(shared.body)))
+	if shared.hasData {
+		c.Set(response.DataContextKey, shared.data)
+	}
+
+	c.Writer.WriteHeader(shared.status)
+	c.Writer.Write(shared.body)
+}
+
+// key 生成合并键，查询参数按名称排序，按当前用户、租户和影响响应内容的请求头区分
+func (rc *RequestCoalescer) key(c *gin.Context) string {
+	scope := "anonymous"
+	if userID := c.GetString("user_id"); userID != "" {
+		scope = "user:" + userID
+	}
+
+	sum := sha256.Sum256([]byte(c.FullPath() + "\n" + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() +
+		"\n" + c.GetHeader("Accept") + "\n" + c.GetHeader("Accept-Language")))
+	return tenancy.CacheKey(c.Request.Context(), scope+":"+hex.EncodeToString(sum[:16]))
+}
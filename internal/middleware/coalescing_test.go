@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCoalescingRouter 创建挂载请求合并中间件的路由，处理器在 release 关闭前阻塞
+func newCoalescingRouter(rc *RequestCoalescer, calls *atomic.Int32, release <-chan struct{}, body string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Header("X-Request-ID", c.GetHeader("X-Test-ID"))
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	router.GET("/reports", rc.Middleware(), func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.Header("X-Report", "generated")
+		c.Header("Set-Cookie", "session=leader")
+		c.String(http.StatusOK, body)
+	})
+	return router
+}
+
+// serveConcurrently 同时发送请求，等待处理器开始执行后放行
+func serveConcurrently(t *testing.T, router *gin.Engine, calls *atomic.Int32, release chan struct{}, expectedCalls int32, requests ...*http.Request) []*httptest.ResponseRecorder {
+	t.Helper()
+	recorders := make([]*httptest.ResponseRecorder, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder, req *http.Request) {
+			defer wg.Done()
+			router.ServeHTTP(w, req)
+		}(recorders[i], req)
+	}
+
+	require.Eventually(t, func() bool { return calls.Load() == expectedCalls }, time.Second, time.Millisecond)
+	// 等待其余请求进入合并等待
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return recorders
+}
+
+func newCoalescingRequest(target, userID, testID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("X-User-ID", userID)
+	req.Header.Set("X-Test-ID", testID)
+	return req
+}
+
+func TestRequestCoalescer_SharesResponse(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	rc := NewRequestCoalescer(config.CoalescingConfig{MaxBodySize: 1 << 10})
+	router := newCoalescingRouter(rc, &calls, release, "report")
+
+	recorders := serveConcurrently(t, router, &calls, release, 1,
+		newCoalescingRequest("/reports?b=2&a=1", "1", "req-1"),
+		newCoalescingRequest("/reports?a=1&b=2", "1", "req-2"),
+		newCoalescingRequest("/reports?a=1&b=2", "1", "req-3"),
+	)
+
+	assert.Equal(t, int32(1), calls.Load(), "查询参数顺序不同的相同请求只执行一次处理器")
+	coalesced := 0
+	for i, w := range recorders {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "report", w.Body.String())
+		assert.Equal(t, "generated", w.Header().Get("X-Report"))
+		assert.Equal(t, []string{fmt.Sprintf("req-%d", i+1)}, w.Header().Values("X-Request-ID"), "关联ID按请求分别设置")
+		if w.Header().Get(CoalescedHeader) == "true" {
+			coalesced++
+			assert.Empty(t, w.Header().Get("Set-Cookie"), "不共享 Set-Cookie")
+		}
+	}
+	assert.Equal(t, 2, coalesced)
+}
+
+func TestRequestCoalescer_ScopesByUser(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	rc := NewRequestCoalescer(config.CoalescingConfig{MaxBodySize: 1 << 10})
+	router := newCoalescingRouter(rc, &calls, release, "report")
+
+	recorders := serveConcurrently(t, router, &calls, release, 2,
+		newCoalescingRequest("/reports", "1", "req-1"),
+		newCoalescingRequest("/reports", "2", "req-2"),
+	)
+
+	assert.Equal(t, int32(2), calls.Load(), "不同用户的请求不合并")
+	for _, w := range recorders {
+		assert.Empty(t, w.Header().Get(CoalescedHeader))
+	}
+}
+
+func TestRequestCoalescer_OversizedResponseNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	rc := NewRequestCoalescer(config.CoalescingConfig{MaxBodySize: 4})
+	router := newCoalescingRouter(rc, &calls, release, strings.Repeat("x", 16))
+
+	recorders := serveConcurrently(t, router, &calls, release, 1,
+		newCoalescingRequest("/reports", "1", "req-1"),
+		newCoalescingRequest("/reports", "1", "req-2"),
+	)
+
+	assert.Equal(t, int32(2), calls.Load(), "响应体超过上限时等待的请求各自执行处理器")
+	for _, w := range recorders {
+		assert.Equal(t, strings.Repeat("x", 16), w.Body.String())
+		assert.Empty(t, w.Header().Get(CoalescedHeader))
+	}
+}
+
+func TestRequestCoalescer_Disabled(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	close(release)
+	var rc *RequestCoalescer
+	router := newCoalescingRouter(rc, &calls, release, "report")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newCoalescingRequest("/reports", "1", "req-1"))
+	assert.Equal(t, "report", w.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -1,14 +1,22 @@
 package middleware
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+
+	"go-server/internal/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
 )
 
 // gzipWriterPool 是一个 gzip writer 的同步池，用于复用 gzip writer 以减少内存分配
@@ -19,6 +27,37 @@ var gzipWriterPool = sync.Pool{
 	},
 }
 
+// gzipResponseWriterPool 复用 gzip 响应写入器及其缓冲区
+var gzipResponseWriterPool = sync.Pool{
+	New: func() interface{} {
+		return &gzipResponseWriter{buffer: new(bytes.Buffer)}
+	},
+}
+
+// maxPooledBufferSize 放回池中的缓冲区的最大容量，更大的缓冲区丢弃，避免少数大响应长期占用内存
+const maxPooledBufferSize = 64 << 10
+
+// nonCompressibleTypes 已经压缩或逐条刷新的内容类型（小写），不再压缩
+var nonCompressibleTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"video/mp4",
+	"audio/mpeg",
+	"application/zip",
+	"application/x-gzip",
+	"text/event-stream", // SSE 逐条刷新，压缩收益很小，还会让部分代理缓冲整个流
+}
+
+// byteCounter 只统计写入字节数的写入器，用于估算压缩后的大小
+type byteCounter int
+
+func (n *byteCounter) Write(p []byte) (int, error) {
+	*n += byteCounter(len(p))
+	return len(p), nil
+}
+
 // gzipResponseWriter 是一个自定义的 ResponseWriter，用于 gzip 压缩响应
 type gzipResponseWriter struct {
 	gin.ResponseWriter
@@ -26,14 +65,25 @@ type gzipResponseWriter struct {
 	buffer     *bytes.Buffer
 	threshold  int // 压缩阈值（字节）
 	compressed bool // 标记是否已进行压缩
+	// passthrough 已经输出未压缩的数据（内容不可压缩、压缩没有收益或处理器主动刷新），之后的数据直接输出
+	passthrough bool
 }
 
 // Write 实现了 io.Writer 接口，对写入的数据进行 gzip 压缩
 func (g *gzipResponseWriter) Write(data []byte) (int, error) {
+	if g.passthrough {
+		return g.ResponseWriter.Write(data)
+	}
+
+	// 如果已经开始压缩，直接写入 gzip writer，不再经过缓冲区
+	if g.compressed {
+		return g.writer.Write(data)
+	}
+
 	// 检查是否应该压缩（基于内容类型）
 	if !g.shouldCompressContent() {
-		// 如果不应该压缩，直接写入缓冲区并返回
-		return g.buffer.Write(data)
+		// 如果不应该压缩，输出已缓冲的数据后直接输出，避免缓冲整个响应
+		return g.startPassthrough(data)
 	}
 
 	// 如果数据长度小于阈值，先存储到缓冲区
@@ -41,56 +91,67 @@ func (g *gzipResponseWriter) Write(data []byte) (int, error) {
 		return g.buffer.Write(data)
 	}
 
-	// 如果还没有开始压缩，并且缓冲区中的数据加上新数据超过阈值，则开始压缩
-	if !g.compressed && g.buffer.Len()+len(data) >= g.threshold {
-		// 合并缓冲区数据和新数据进行压缩测试
-		allData := append(g.buffer.Bytes(), data...)
-
-		// 测试压缩是否真的减少大小
-		var testBuf bytes.Buffer
-		testWriter := gzip.NewWriter(&testBuf)
-		_, err := testWriter.Write(allData)
-		if err == nil {
-			testWriter.Close()
-			compressedSize := testBuf.Len()
-			originalSize := len(allData)
-
-			// 如果压缩不会减少大小，则不压缩
-			if compressedSize >= originalSize {
-				// 直接写入所有数据而不压缩
-				_, err := g.ResponseWriter.Write(allData)
-				if err != nil {
-					return 0, err
-				}
-				g.buffer.Reset()
-				return len(data), nil
-			}
+	// 缓冲区中的数据加上新数据达到阈值，合并后进行压缩测试
+	allData := append(g.buffer.Bytes(), data...)
+
+	// 测试压缩是否真的减少大小，使用池中的 gzip writer 且只统计压缩后的字节数
+	var compressedSize byteCounter
+	testWriter := gzipWriterPool.Get().(*gzip.Writer)
+	testWriter.Reset(&compressedSize)
+	_, err := testWriter.Write(allData)
+	if err == nil {
+		err = testWriter.Close()
+	}
+	gzipWriterPool.Put(testWriter)
+	if err == nil {
+		originalSize := len(allData)
+
+		// 如果压缩不会减少大小，则不压缩
+		if int(compressedSize) >= originalSize {
+			return g.startPassthrough(data)
 		}
+	}
 
-		// 设置 Content-Encoding 头
-		g.Header().Set("Content-Encoding", "gzip")
-		g.Header().Set("Vary", "Accept-Encoding")
-		g.compressed = true
+	// 设置 Content-Encoding 头
+	g.Header().Set("Content-Encoding", "gzip")
+	g.Header().Set("Vary", "Accept-Encoding")
+	g.compressed = true
 
-		// 从池中获取 gzip writer
-		g.writer = gzipWriterPool.Get().(*gzip.Writer)
-		g.writer.Reset(g.ResponseWriter)
+	// 压缩后的响应体与原始响应体不同，强 ETag 降级为弱 ETag
+	if etag := g.Header().Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		g.Header().Set("ETag", "W/"+etag)
+	}
 
-		// 写入所有数据到压缩器
-		if _, err := g.writer.Write(allData); err != nil {
+	// 从池中获取 gzip writer
+	g.writer = gzipWriterPool.Get().(*gzip.Writer)
+	g.writer.Reset(g.ResponseWriter)
+
+	// 写入所有数据到压缩器
+	if _, err := g.writer.Write(allData); err != nil {
+		return 0, err
+	}
+	g.buffer.Reset() // 清空缓冲区
+	return len(data), nil
+}
+
+// WriteString 与 Write 相同，避免字符串绕过缓冲区和压缩直接写入底层写入器
+func (g *gzipResponseWriter) WriteString(s string) (int, error) {
+	return g.Write([]byte(s))
+}
+
+// startPassthrough 输出已缓冲的数据和 data，之后的数据不再压缩
+func (g *gzipResponseWriter) startPassthrough(data []byte) (int, error) {
+	g.passthrough = true
+	if g.buffer.Len() > 0 {
+		if _, err := g.ResponseWriter.Write(g.buffer.Bytes()); err != nil {
 			return 0, err
 		}
-		g.buffer.Reset() // 清空缓冲区
-		return len(data), nil
+		g.buffer.Reset()
 	}
-
-	// 如果已经开始压缩，直接写入 gzip writer
-	if g.compressed {
-		return g.writer.Write(data)
+	if len(data) == 0 {
+		return 0, nil
 	}
-
-	// 否则写入缓冲区
-	return g.buffer.Write(data)
+	return g.ResponseWriter.Write(data)
 }
 
 // shouldCompressContent 检查内容类型是否应该被压缩
@@ -101,21 +162,10 @@ func (g *gzipResponseWriter) shouldCompressContent() bool {
 		return true
 	}
 
-	// 不压缩已经压缩的内容类型
-	nonCompressibleTypes := []string{
-		"image/jpeg",
-		"image/png",
-		"image/gif",
-		"image/webp",
-		"video/mp4",
-		"audio/mpeg",
-		"application/zip",
-		"application/x-gzip",
-	}
-	
 	// 检查是否为不可压缩的内容类型
+	contentType = strings.ToLower(contentType)
 	for _, ct := range nonCompressibleTypes {
-		if strings.HasPrefix(strings.ToLower(contentType), strings.ToLower(ct)) {
+		if strings.HasPrefix(contentType, ct) {
 			return false
 		}
 	}
@@ -135,14 +185,29 @@ func (g *gzipResponseWriter) Close() error {
 	return nil
 }
 
+// release 恢复原响应写入器，并将写入器放回池中
+// 处理过程中 panic 时压缩流未关闭，其 gzip writer 不放回池中
+func (g *gzipResponseWriter) release(c *gin.Context) {
+	if c.Writer == g {
+		c.Writer = g.ResponseWriter
+	}
+
+	g.ResponseWriter = nil
+	g.writer = nil
+	g.passthrough = false
+	g.buffer.Reset()
+	if g.buffer.Cap() > maxPooledBufferSize {
+		g.buffer = new(bytes.Buffer)
+	}
+	gzipResponseWriterPool.Put(g)
+}
+
 // Flush 刷新缓冲区，确保所有数据都被写入
+// 还没有开始压缩时响应头和已缓冲的数据随刷新发出，之后的数据不再压缩，流式响应应在超过压缩阈值后再刷新
 func (g *gzipResponseWriter) Flush() {
 	if !g.compressed {
 		// 如果还没有压缩，直接写入缓冲区的内容
-		if g.buffer.Len() > 0 {
-			g.ResponseWriter.Write(g.buffer.Bytes())
-			g.buffer.Reset()
-		}
+		g.startPassthrough(nil)
 	} else {
 		// 如果已经压缩，刷新 gzip writer
 		if g.writer != nil {
@@ -152,12 +217,23 @@ func (g *gzipResponseWriter) Flush() {
 	g.ResponseWriter.Flush()
 }
 
-// decompressRequest 检查并解压缩gzip请求体
+// Unwrap 返回底层写入器，供 http.ResponseController 设置流式响应的写超时
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// supportedRequestEncodings 请求体支持的内容编码，不支持时在 415 响应的 Accept-Encoding 头中返回
+const supportedRequestEncodings = "gzip, deflate, zstd, identity"
+
+// zstdMaxWindow zstd 请求体允许的最大解码窗口，与 RFC 8878 建议解码器至少支持的 8MB 一致
+const zstdMaxWindow = 8 << 20
+
+// decompressRequest 按 Content-Encoding 以流式方式解压请求体，支持 gzip、deflate、zstd 和 identity
+// 多个编码按逆序解码（如 "gzip, zstd" 先解 zstd 再解 gzip），解压后的大小受请求体限制约束
 func decompressRequest(c *gin.Context) {
-	// 检查请求是否使用gzip压缩
-	contentEncoding := c.GetHeader("Content-Encoding")
-	if !strings.Contains(strings.ToLower(contentEncoding), "gzip") {
-		return // 不是gzip压缩的请求，无需处理
+	encodings := parseContentEncoding(c.GetHeader("Content-Encoding"))
+	if len(encodings) == 0 {
+		return // 未压缩的请求，无需处理
 	}
 
 	// 如果请求体为空，无需解压缩
@@ -165,26 +241,124 @@ func decompressRequest(c *gin.Context) {
 		return
 	}
 
-	// 创建gzip读取器来解压缩请求体
-	gzipReader, err := gzip.NewReader(c.Request.Body)
-	if err != nil {
-		// 如果解压缩失败，返回错误
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid gzip compressed request body",
-		})
-		c.Abort()
-		return
+	for _, encoding := range encodings {
+		if !isSupportedRequestEncoding(encoding) {
+			c.Header("Accept-Encoding", supportedRequestEncodings)
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"success": false,
+				"message": "Unsupported Content-Encoding: " + encoding,
+			})
+			c.Abort()
+			return
+		}
+	}
+
+	limit := decompressedBodyLimit(c)
+	body := &readCloser{Reader: c.Request.Body, closers: []io.Closer{c.Request.Body}}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		reader, err := newDecompressReader(encodings[i], body.Reader)
+		if err != nil {
+			body.Close()
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": fmt.Sprintf("Invalid %s compressed request body", encodings[i]),
+			})
+			c.Abort()
+			return
+		}
+		if reader == nil {
+			continue // identity
+		}
+		body.Reader = reader
+		body.closers = append(body.closers, reader)
+	}
+
+	// 替换请求体为解压缩后的内容，并限制解压后的大小以防御压缩炸弹
+	c.Request.Body = http.MaxBytesReader(c.Writer, body, limit)
+	c.Request.ContentLength = -1
+	c.Request.Header.Del("Content-Length")
+	c.Request.Header.Del("Content-Encoding")
+}
+
+// parseContentEncoding 解析 Content-Encoding 头，返回小写的编码列表，忽略只包含 identity 的情况
+func parseContentEncoding(header string) []string {
+	if header == "" {
+		return nil
+	}
+
+	var encodings []string
+	identityOnly := true
+	for _, part := range strings.Split(header, ",") {
+		encoding := strings.ToLower(strings.TrimSpace(part))
+		if encoding == "" {
+			continue
+		}
+		if encoding != "identity" {
+			identityOnly = false
+		}
+		encodings = append(encodings, encoding)
+	}
+	if identityOnly {
+		return nil
+	}
+	return encodings
+}
+
+// isSupportedRequestEncoding 判断请求体编码是否受支持
+func isSupportedRequestEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "zstd", "identity":
+		return true
+	default:
+		return false
+	}
+}
+
+// newDecompressReader 创建指定编码的解压读取器，identity 返回 nil
+// gzip 在创建时校验头部；deflate 和 zstd 的数据错误在读取请求体时返回
+func newDecompressReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		return newDeflateReader(r)
+	case "zstd":
+		// 限制解码窗口，避免恶意的帧头让解码器分配过大的缓冲区；解压后的大小由调用方限制
+		decoder, err := zstd.NewReader(r,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxWindow(zstdMaxWindow))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// newDeflateReader 创建 deflate 读取器
+// HTTP 规范中的 deflate 是 zlib 格式，但部分客户端发送不带 zlib 头的原始 deflate 数据，两种格式都接受
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(header) == 2 && isZlibHeader(header[0], header[1]) {
+		return zlib.NewReader(buffered)
 	}
+	return flate.NewReader(buffered), nil
+}
 
-	// 替换请求体为解压缩后的内容
-	c.Request.Body = &readCloser{Reader: gzipReader, originalCloser: c.Request.Body}
+// isZlibHeader 判断前两个字节是否为 zlib 头（压缩方法为 deflate，校验位正确）
+func isZlibHeader(cmf, flg byte) bool {
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && (uint16(cmf)<<8|uint16(flg))%31 == 0
 }
 
-// readCloser 包装gzip.Reader以实现io.ReadCloser接口
+// readCloser 包装解压读取器，关闭时依次关闭各层读取器和原始请求体
 type readCloser struct {
-	Reader        *gzip.Reader
-	originalCloser io.Closer
+	Reader  io.Reader
+	closers []io.Closer
 }
 
 func (rc *readCloser) Read(p []byte) (n int, err error) {
@@ -192,12 +366,14 @@ func (rc *readCloser) Read(p []byte) (n int, err error) {
 }
 
 func (rc *readCloser) Close() error {
-	// 关闭gzip读取器
-	if err := rc.Reader.Close(); err != nil {
-		return err
+	// 从最外层的解压读取器开始关闭，最后关闭原始请求体
+	var firstErr error
+	for i := len(rc.closers) - 1; i >= 0; i-- {
+		if err := rc.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	// 关闭原始请求体
-	return rc.originalCloser.Close()
+	return firstErr
 }
 
 // shouldCompress 检查是否应该对响应进行压缩
@@ -231,7 +407,7 @@ func parseAcceptEncoding(acceptEncoding string) bool {
 		return false
 	}
 
-	// 转换为小写进行处理
+	// 转换为小写进行处理，不含大写字母时不分配内存
 	acceptEncoding = strings.ToLower(acceptEncoding)
 
 	// 支持通配符
@@ -239,31 +415,33 @@ func parseAcceptEncoding(acceptEncoding string) bool {
 		return true
 	}
 
-	// 分割不同的编码类型
-	encodings := strings.Split(acceptEncoding, ",")
+	// 逐个检查不同的编码类型，不分割为切片以避免内存分配
+	for rest := acceptEncoding; rest != ""; {
+		var encoding string
+		encoding, rest, _ = strings.Cut(rest, ",")
 
-	for _, encoding := range encodings {
 		// 去除空格
 		encoding = strings.TrimSpace(encoding)
 
 		// 检查是否是 gzip
-		if strings.HasPrefix(encoding, "gzip") {
-			// 检查质量值
-			parts := strings.Split(encoding, ";")
-			if len(parts) == 1 {
-				// 没有质量值，默认为 1.0
-				return true
-			}
+		if !strings.HasPrefix(encoding, "gzip") {
+			continue
+		}
 
-			// 检查质量值是否为 0
-			for _, part := range parts[1:] {
-				part = strings.TrimSpace(part)
-				if strings.HasPrefix(part, "q=") {
-					qValue := strings.TrimPrefix(part, "q=")
-					if qValue != "0" {
-						return true
-					}
-				}
+		// 检查质量值
+		_, params, hasParams := strings.Cut(encoding, ";")
+		if !hasParams {
+			// 没有质量值，默认为 1.0
+			return true
+		}
+
+		// 检查质量值是否为 0
+		for params != "" {
+			var part string
+			part, params, _ = strings.Cut(params, ";")
+			part = strings.TrimSpace(part)
+			if qValue, ok := strings.CutPrefix(part, "q="); ok && qValue != "0" {
+				return true
 			}
 		}
 	}
@@ -280,45 +458,103 @@ func CompressionMiddleware(threshold int) gin.HandlerFunc {
 	}
 
 	return func(c *gin.Context) {
-		// 首先处理请求解压缩
-		decompressRequest(c)
-		if c.IsAborted() {
-			return // 如果解压缩失败，直接返回
-		}
+		compress(c, threshold)
+	}
+}
 
-		// 检查是否应该进行响应压缩
-		if !shouldCompress(c, threshold) {
-			c.Next()
-			return
-		}
+// compress 解压缩请求体并按阈值压缩响应
+func compress(c *gin.Context, threshold int) {
+	// 首先处理请求解压缩
+	decompressRequest(c)
+	if c.IsAborted() {
+		return // 如果解压缩失败，直接返回
+	}
+
+	// 检查是否应该进行响应压缩
+	if !shouldCompress(c, threshold) {
+		c.Next()
+		return
+	}
 
-		// 创建 gzip 响应写入器
-		gzipWriter := &gzipResponseWriter{
-			ResponseWriter: c.Writer,
-			buffer:         bytes.NewBuffer(nil),
-			threshold:      threshold,
-			compressed:     false,
+	// 从池中获取 gzip 响应写入器，请求结束后恢复原响应写入器再放回池中
+	gzipWriter := gzipResponseWriterPool.Get().(*gzipResponseWriter)
+	gzipWriter.ResponseWriter = c.Writer
+	gzipWriter.threshold = threshold
+	gzipWriter.compressed = false
+	gzipWriter.passthrough = false
+	defer gzipWriter.release(c)
+
+	// 替换响应写入器
+	c.Writer = gzipWriter
+
+	// 处理请求
+	c.Next()
+
+	// 请求处理完成后，确保所有数据都被写入
+	if !gzipWriter.compressed {
+		// 如果没有进行压缩，直接写入缓冲区的内容
+		if gzipWriter.buffer.Len() > 0 {
+			gzipWriter.ResponseWriter.Write(gzipWriter.buffer.Bytes())
 		}
+	} else {
+		// 如果已经压缩，关闭 gzip writer
+		gzipWriter.Close()
+	}
+}
 
-		// 替换响应写入器
-		c.Writer = gzipWriter
+// Compressor 支持配置热重载的压缩中间件，启用状态和阈值在每个请求开始时读取
+type Compressor struct {
+	enabled   atomic.Bool
+	threshold atomic.Int64
+}
 
-		// 处理请求
-		c.Next()
+// NewCompressor 根据压缩配置创建压缩中间件
+func NewCompressor(cfg config.CompressionConfig) *Compressor {
+	compressor := &Compressor{}
+	compressor.store(cfg)
+	return compressor
+}
 
-		// 请求处理完成后，确保所有数据都被写入
-		if !gzipWriter.compressed {
-			// 如果没有进行压缩，直接写入缓冲区的内容
-			if gzipWriter.buffer.Len() > 0 {
-				gzipWriter.ResponseWriter.Write(gzipWriter.buffer.Bytes())
-			}
-		} else {
-			// 如果已经压缩，关闭 gzip writer
-			gzipWriter.Close()
+// store 原子更新启用状态和阈值
+func (p *Compressor) store(cfg config.CompressionConfig) {
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = 1024
+	}
+	p.threshold.Store(int64(threshold))
+	p.enabled.Store(cfg.Enabled)
+}
+
+// Middleware 返回 gin 中间件，禁用时请求直接通过
+func (p *Compressor) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.enabled.Load() {
+			c.Next()
+			return
 		}
+		compress(c, int(p.threshold.Load()))
 	}
 }
 
+// Name 实现 config.Subscriber 接口
+func (p *Compressor) Name() string {
+	return "compression"
+}
+
+// ValidateConfig 实现 config.Subscriber 接口，校验新的压缩配置
+func (p *Compressor) ValidateConfig(newConfig *config.Config) error {
+	if newConfig.Compression.Threshold < 0 {
+		return fmt.Errorf("压缩阈值不能为负数: %d", newConfig.Compression.Threshold)
+	}
+	return nil
+}
+
+// ApplyConfig 实现 config.Subscriber 接口，仅影响新请求
+func (p *Compressor) ApplyConfig(oldConfig, newConfig *config.Config) error {
+	p.store(newConfig.Compression)
+	return nil
+}
+
 // CompressionMiddlewareWithConfig 创建带配置的 gzip 压缩中间件
 func CompressionMiddlewareWithConfig(config CompressionConfig) gin.HandlerFunc {
 	return CompressionMiddleware(config.Threshold)
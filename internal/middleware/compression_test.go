@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,7 +15,11 @@ import (
 	"testing"
 	"time"
 
+	"go-server/internal/config"
+	"go-server/pkg/response"
+
 	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -453,6 +460,97 @@ func TestCompressionMiddleware_InvalidGzipRequest(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Invalid gzip compressed request body")
 }
 
+// TestCompressionMiddleware_RequestEncodings 测试 deflate、zstd 和多重编码的请求体解压
+func TestCompressionMiddleware_RequestEncodings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CompressionMiddleware(1024))
+	router.POST("/test", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "%s|%s", c.GetHeader("Content-Encoding"), body)
+	})
+
+	originalData := strings.Repeat("compressed request payload ", 20)
+	compress := func(w io.WriteCloser, buf *bytes.Buffer, data []byte) []byte {
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		return compress(gzip.NewWriter(&buf), &buf, data)
+	}
+	zlibbed := func(data []byte) []byte {
+		var buf bytes.Buffer
+		return compress(zlib.NewWriter(&buf), &buf, data)
+	}
+	rawDeflated := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		return compress(w, &buf, data)
+	}
+	zstded := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		return compress(w, &buf, data)
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     string
+	}{
+		{"zlib deflate", "deflate", zlibbed([]byte(originalData)), "|" + originalData},
+		{"raw deflate", "deflate", rawDeflated([]byte(originalData)), "|" + originalData},
+		{"zstd", "zstd", zstded([]byte(originalData)), "|" + originalData},
+		{"identity", "identity", []byte(originalData), "identity|" + originalData},
+		{"gzip then zstd", "gzip, zstd", zstded(gzipped([]byte(originalData))), "|" + originalData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/test", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, w.Body.String(), "解压后移除 Content-Encoding 头")
+		})
+	}
+}
+
+// TestCompressionMiddleware_UnsupportedRequestEncoding 测试不支持的请求体编码返回 415
+func TestCompressionMiddleware_UnsupportedRequestEncoding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CompressionMiddleware(1024))
+	router.POST("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	req := httptest.NewRequest("POST", "/test", strings.NewReader("payload"))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, supportedRequestEncodings, w.Header().Get("Accept-Encoding"))
+	assert.Contains(t, w.Body.String(), "Unsupported Content-Encoding: br")
+}
+
 // TestCompressionMiddleware_NoCompressionHeader 测试没有压缩头的请求
 func TestCompressionMiddleware_NoCompressionHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -713,6 +811,99 @@ func TestCompressionMiddleware_StreamResponse(t *testing.T) {
 	assert.True(t, w.Body.Len() > 0)
 }
 
+// TestCompressionMiddleware_FlushBeforeThreshold 测试未达到压缩阈值就刷新后，之后的数据不再压缩
+func TestCompressionMiddleware_FlushBeforeThreshold(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CompressionMiddleware(1024))
+
+	head := strings.Repeat("head", 50)
+	tail := strings.Repeat("compressible tail ", 500)
+	router.GET("/stream", func(c *gin.Context) {
+		c.Writer.WriteString(head)
+		c.Writer.Flush()
+		c.Writer.WriteString(tail)
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 响应头已随刷新发出，之后切换为 gzip 会破坏响应体
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, head+tail, w.Body.String())
+}
+
+// TestCompressionMiddleware_SmallWritesAfterThreshold 测试开始压缩后的多次小块写入全部进入压缩流
+func TestCompressionMiddleware_SmallWritesAfterThreshold(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CompressionMiddleware(1024))
+
+	var expected strings.Builder
+	router.GET("/chunks", func(c *gin.Context) {
+		for i := 0; i < 1000; i++ {
+			chunk := fmt.Sprintf("chunk %d\n", i)
+			expected.WriteString(chunk)
+			c.Writer.WriteString(chunk)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/chunks", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), string(body))
+}
+
+// TestCompressionMiddleware_StreamedJSONArray 测试流式 JSON 数组响应边编码边压缩输出，而不是缓冲整个响应
+func TestCompressionMiddleware_StreamedJSONArray(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const items = 100000
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(CompressionMiddleware(1024))
+	router.GET("/users", func(c *gin.Context) {
+		stream := response.NewJSONArrayStream(c, http.StatusOK, "ok")
+		for i := 0; i < items; i++ {
+			require.NoError(t, stream.Send(map[string]interface{}{"id": i, "username": fmt.Sprintf("user%d", i)}))
+			if i == items/2 {
+				// 已经输出的压缩数据说明响应没有被缓冲
+				assert.NotZero(t, w.Body.Len(), "compressed data should be written while streaming")
+				assert.Less(t, c.Writer.(*gzipResponseWriter).buffer.Len(), 1024)
+			}
+		}
+		require.NoError(t, stream.Close())
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	var body struct {
+		Success bool                     `json:"success"`
+		Data    []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(reader).Decode(&body))
+	assert.True(t, body.Success)
+	assert.Equal(t, items, len(body.Data))
+}
+
 // TestCompressionMiddleware_SmallResponseWithLargeHeader 测试小响应但有大量头信息
 func TestCompressionMiddleware_SmallResponseWithLargeHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -1089,4 +1280,35 @@ func BenchmarkCompressionMiddleware_WithVsWithoutCompression(b *testing.B) {
 			router.ServeHTTP(w, req)
 		}
 	})
-}
\ No newline at end of file
+}
+
+// TestCompressor_ApplyConfig 测试压缩配置热重载
+func TestCompressor_ApplyConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	oldConfig := &config.Config{Compression: config.CompressionConfig{Enabled: false, Threshold: 1024}}
+	compressor := NewCompressor(oldConfig.Compression)
+
+	router := gin.New()
+	router.Use(compressor.Middleware())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("compressible ", 200))
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, "", send().Header().Get("Content-Encoding"))
+
+	newConfig := &config.Config{Compression: config.CompressionConfig{Enabled: true, Threshold: 512}}
+	require.NoError(t, compressor.ValidateConfig(newConfig))
+	require.NoError(t, compressor.ApplyConfig(oldConfig, newConfig))
+	assert.Equal(t, "gzip", send().Header().Get("Content-Encoding"))
+
+	assert.Error(t, compressor.ValidateConfig(&config.Config{Compression: config.CompressionConfig{Threshold: -1}}))
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsPolicy 解析后的跨域策略
+type corsPolicy struct {
+	enabled          bool
+	allowAll         bool            // 允许任意来源
+	origins          map[string]bool // 精确匹配的来源
+	wildcards        []corsWildcard  // 子域名通配来源
+	allowedMethods   string
+	allowedHeaders   string
+	allowAllHeaders  bool // 回显预检请求中的请求头
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// corsWildcard 形如 https://*.example.com 的子域名通配来源
+type corsWildcard struct {
+	scheme string // 协议，如 https
+	suffix string // 主机后缀，如 .example.com
+}
+
+// matches 判断来源是否为通配域名的子域名
+func (w corsWildcard) matches(origin string) bool {
+	scheme, host, found := strings.Cut(origin, "://")
+	return found && scheme == w.scheme && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix)
+}
+
+// newCORSPolicy 将跨域配置解析为策略，来源和方法不区分大小写
+func newCORSPolicy(cfg config.CORSConfig) (*corsPolicy, error) {
+	policy := &corsPolicy{
+		enabled:          cfg.Enabled,
+		origins:          make(map[string]bool),
+		allowCredentials: cfg.AllowCredentials,
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimRight(origin, "/"))
+		switch {
+		case origin == "*":
+			policy.allowAll = true
+		case strings.Contains(origin, "*"):
+			scheme, host, found := strings.Cut(origin, "://")
+			if !found || !strings.HasPrefix(host, "*.") || strings.Count(host, "*") > 1 {
+				return nil, fmt.Errorf("无效的通配来源 %q，仅支持 https://*.example.com 形式", origin)
+			}
+			policy.wildcards = append(policy.wildcards, corsWildcard{scheme: scheme, suffix: host[1:]})
+		default:
+			policy.origins[origin] = true
+		}
+	}
+
+	if policy.allowAll && cfg.AllowCredentials {
+		return nil, fmt.Errorf("允许携带凭证时来源不能为 *")
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	policy.allowedMethods = strings.Join(methods, ", ")
+
+	policy.allowAllHeaders = slices.Contains(cfg.AllowedHeaders, "*")
+	policy.allowedHeaders = strings.Join(cfg.AllowedHeaders, ", ")
+	policy.exposedHeaders = strings.Join(cfg.ExposedHeaders, ", ")
+
+	if cfg.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
+	return policy, nil
+}
+
+// allowOrigin 判断来源是否被允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, wildcard := range p.wildcards {
+		if wildcard.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS 支持配置热重载的跨域中间件，策略在每个请求开始时读取
+type CORS struct {
+	policy atomic.Pointer[corsPolicy]
+}
+
+// NewCORS 根据跨域配置创建跨域中间件
+func NewCORS(cfg config.CORSConfig) (*CORS, error) {
+	policy, err := newCORSPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cors := &CORS{}
+	cors.policy.Store(policy)
+	return cors, nil
+}
+
+// Middleware 返回 gin 中间件，禁用时请求直接通过
+func (m *CORS) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := m.policy.Load()
+		origin := c.GetHeader("Origin")
+		if !policy.enabled || origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !policy.allowOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// 非预检请求照常处理，浏览器会因缺少跨域响应头而拒绝读取响应
+			c.Next()
+			return
+		}
+
+		if policy.allowAll && !policy.allowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if policy.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if policy.exposedHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", policy.exposedHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", policy.allowedMethods)
+		allowedHeaders := policy.allowedHeaders
+		if policy.allowAllHeaders {
+			allowedHeaders = c.GetHeader("Access-Control-Request-Headers")
+		}
+		if allowedHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+		}
+		if policy.maxAge != "" {
+			c.Header("Access-Control-Max-Age", policy.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// Name 实现 config.Subscriber 接口
+func (m *CORS) Name() string {
+	return "cors"
+}
+
+// ValidateConfig 实现 config.Subscriber 接口，校验新的跨域配置
+func (m *CORS) ValidateConfig(newConfig *config.Config) error {
+	_, err := newCORSPolicy(newConfig.CORS)
+	return err
+}
+
+// ApplyConfig 实现 config.Subscriber 接口，原子替换跨域策略，仅影响新请求
+func (m *CORS) ApplyConfig(oldConfig, newConfig *config.Config) error {
+	policy, err := newCORSPolicy(newConfig.CORS)
+	if err != nil {
+		return err
+	}
+	m.policy.Store(policy)
+	return nil
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSConfig(origins []string, credentials bool) config.CORSConfig {
+	return config.CORSConfig{
+		Enabled:          true,
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"get", "post"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Correlation-ID"},
+		AllowCredentials: credentials,
+		MaxAge:           600,
+	}
+}
+
+func newCORSRouter(t *testing.T, cors *CORS) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(cors.Middleware())
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return router
+}
+
+func sendCORS(router *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestCORS_AllowedOrigins 测试精确来源和子域名通配来源
+func TestCORS_AllowedOrigins(t *testing.T) {
+	cors, err := NewCORS(newCORSConfig([]string{"https://app.example.com", "https://*.example.org"}, true))
+	require.NoError(t, err)
+	router := newCORSRouter(t, cors)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://api.example.org", false},
+		{"https://evil-example.org", false},
+		{"https://other.example.com", false},
+	}
+
+	for _, tt := range tests {
+		w := sendCORS(router, http.MethodGet, tt.origin, false)
+		assert.Equal(t, http.StatusOK, w.Code, tt.origin)
+		assert.Equal(t, "Origin", w.Header().Get("Vary"), tt.origin)
+		if tt.allowed {
+			assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"), tt.origin)
+			assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), tt.origin)
+			assert.Equal(t, "X-Correlation-ID", w.Header().Get("Access-Control-Expose-Headers"), tt.origin)
+		} else {
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), tt.origin)
+		}
+	}
+}
+
+// TestCORS_Preflight 测试预检请求
+func TestCORS_Preflight(t *testing.T) {
+	cors, err := NewCORS(newCORSConfig([]string{"https://app.example.com"}, false))
+	require.NoError(t, err)
+	router := newCORSRouter(t, cors)
+
+	w := sendCORS(router, http.MethodOptions, "https://app.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// 不允许的来源的预检请求被拒绝
+	w = sendCORS(router, http.MethodOptions, "https://evil.com", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// TestCORS_AllowAll 测试允许任意来源且不携带凭证时返回 *
+func TestCORS_AllowAll(t *testing.T) {
+	cfg := newCORSConfig([]string{"*"}, false)
+	cfg.AllowedHeaders = []string{"*"}
+	cors, err := NewCORS(cfg)
+	require.NoError(t, err)
+	router := newCORSRouter(t, cors)
+
+	w := sendCORS(router, http.MethodGet, "https://anything.example", false)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// 请求头配置为 * 时回显预检请求的请求头
+	w = sendCORS(router, http.MethodOptions, "https://anything.example", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+
+	// 没有 Origin 的请求不添加跨域响应头
+	w = sendCORS(router, http.MethodGet, "", false)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// TestCORS_InvalidConfig 测试无效的跨域配置
+func TestCORS_InvalidConfig(t *testing.T) {
+	_, err := NewCORS(newCORSConfig([]string{"*"}, true))
+	assert.Error(t, err)
+
+	_, err = NewCORS(newCORSConfig([]string{"https://api.*.example.com"}, false))
+	assert.Error(t, err)
+
+	cors, err := NewCORS(newCORSConfig([]string{"https://app.example.com"}, false))
+	require.NoError(t, err)
+	assert.Error(t, cors.ValidateConfig(&config.Config{CORS: newCORSConfig([]string{"*"}, true)}))
+}
+
+// TestCORS_ApplyConfig 测试热重载替换跨域策略
+func TestCORS_ApplyConfig(t *testing.T) {
+	cors, err := NewCORS(newCORSConfig([]string{"https://app.example.com"}, false))
+	require.NoError(t, err)
+	router := newCORSRouter(t, cors)
+
+	oldConfig := &config.Config{CORS: newCORSConfig([]string{"https://app.example.com"}, false)}
+	newConfig := &config.Config{CORS: newCORSConfig([]string{"https://new.example.com"}, false)}
+	require.NoError(t, cors.ValidateConfig(newConfig))
+	require.NoError(t, cors.ApplyConfig(oldConfig, newConfig))
+
+	w := sendCORS(router, http.MethodGet, "https://app.example.com", false)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	w = sendCORS(router, http.MethodGet, "https://new.example.com", false)
+	assert.Equal(t, "https://new.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// 禁用后不再添加跨域响应头
+	disabled := newCORSConfig([]string{"https://new.example.com"}, false)
+	disabled.Enabled = false
+	require.NoError(t, cors.ApplyConfig(newConfig, &config.Config{CORS: disabled}))
+	w = sendCORS(router, http.MethodGet, "https://new.example.com", false)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"strings"
+
+	"go-server/pkg/drain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainTrackerMiddleware 统计处理中的请求，排空实例时等待它们完成
+// skipPaths 中的路径前缀不计入，如排空进度接口本身，否则轮询进度的请求会让排空永远无法完成
+func DrainTrackerMiddleware(tracker *drain.Tracker, skipPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range skipPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		done := tracker.Begin()
+		defer done()
+		c.Next()
+	}
+}
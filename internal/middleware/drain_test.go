@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/pkg/drain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainTrackerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := drain.NewTracker()
+
+	var during int64
+	router := gin.New()
+	router.Use(DrainTrackerMiddleware(tracker, "/api/v1/admin/drain"))
+	router.Any("/*path", func(c *gin.Context) {
+		during = tracker.InFlight()
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+	assert.Equal(t, int64(1), during)
+	assert.Zero(t, tracker.InFlight())
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/admin/drain", nil))
+	assert.Zero(t, during, "排空进度接口不计入处理中的请求")
+}
@@ -2,12 +2,18 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"runtime/debug"
 	"time"
 
 	"go-server/internal/errors"
 	"go-server/internal/logger"
+	"go-server/internal/metrics"
 	"go-server/internal/models"
+	"go-server/pkg/errorreporting"
+	apperrors "go-server/pkg/errors"
+	"go-server/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,7 +33,7 @@ func ErrorHandlerMiddleware(logger logger.Logger) gin.HandlerFunc {
 				})
 
 				// Create a standardized error response
-				appErr := errors.NewInternalError("Internal server error", nil)
+				appErr := errors.NewInternalError(localize(c, "error.internal"), nil)
 				if correlationID := GetCorrelationIDFromContext(c); correlationID != "" {
 					appErr = appErr.WithCorrelationID(correlationID)
 				}
@@ -51,7 +57,7 @@ func ErrorHandlerMiddleware(logger logger.Logger) gin.HandlerFunc {
 			if customErr, ok := err.(*errors.AppError); ok {
 				appErr = customErr
 			} else {
-				appErr = errors.NewInternalError("Internal server error", err)
+				appErr = errors.NewInternalError(localize(c, "error.internal"), err)
 			}
 
 			// Add correlation ID if available
@@ -92,6 +98,24 @@ func sendErrorResponse(c *gin.Context, appErr *errors.AppError) {
 		}
 	}
 
+	// Render as RFC 7807 problem details when configured or requested via Accept
+	if response.WantsProblem(c) {
+		code := apperrors.ErrorCode(appErr.Code)
+		response.Problem(c, &response.ProblemDetails{
+			Type:          response.ProblemTypeURI(code),
+			Title:         http.StatusText(statusCode),
+			Status:        statusCode,
+			Detail:        errorResponse.Error.UserMessage,
+			Instance:      c.Request.URL.Path,
+			Code:          code,
+			Details:       appErr.Details,
+			InternalError: errorResponse.Error.InternalError,
+			CorrelationID: appErr.CorrelationID,
+			Timestamp:     errorResponse.Timestamp,
+		})
+		return
+	}
+
 	c.JSON(statusCode, errorResponse)
 }
 
@@ -113,33 +137,92 @@ func isDevelopmentEnvironment() bool {
 	return gin.Mode() == gin.DebugMode
 }
 
-// RecoveryMiddleware provides a recovery mechanism that works with the error handler
-func RecoveryMiddleware(logger logger.Logger) gin.HandlerFunc {
+// RecoveryMiddleware replaces gin's default recovery: a panic in a handler or later middleware becomes
+// an INTERNAL_ERROR AppError carrying the stack trace, is logged with the correlation ID, counted in
+// httpMetrics and sent to the reporter. 5xx AppError responses are reported too.
+// A nil reporter disables reporting and nil httpMetrics disables the panic counter.
+func RecoveryMiddleware(log logger.Logger, reporter errorreporting.Reporter, httpMetrics *metrics.HTTPMetrics) gin.HandlerFunc {
+	if reporter == nil {
+		reporter = errorreporting.NewNoopReporter()
+	}
+
 	return func(c *gin.Context) {
 		defer func() {
-			if err := recover(); err != nil {
-				// Log the panic with stack trace
-				logError(logger, c.Request.Context(), "Panic recovered", map[string]interface{}{
-					"error":       toString(err),
-					"stack_trace": string(debug.Stack()),
-					"method":      c.Request.Method,
-					"path":        c.Request.URL.Path,
-					"client_ip":   c.ClientIP(),
-				})
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// The handler deliberately aborted the response; net/http closes the connection quietly
+				panic(recovered)
+			}
 
-				// Create an appropriate error response
-				appErr := errors.NewInternalError("Internal server error", nil).
-					WithDetail("panic", true).
-					WithCorrelationID(GetCorrelationIDFromContext(c))
+			stack := debug.Stack()
+			correlationID := GetCorrelationIDFromContext(c)
+			appErr := apperrors.NewInternalError(localize(c, "error.internal"), panicError(recovered)).
+				WithStackTrace(string(stack)).
+				WithDetail("panic", true).
+				WithCorrelationID(correlationID)
+
+			log.Error(c.Request.Context(), "Panic recovered",
+				logger.String("correlation_id", correlationID),
+				logger.String("error", appErr.Cause.Error()),
+				logger.String("panic_type", fmt.Sprintf("%T", recovered)),
+				logger.String("method", c.Request.Method),
+				logger.String("path", c.Request.URL.Path),
+				logger.String("route", c.FullPath()),
+				logger.String("client_ip", c.ClientIP()),
+				logger.String("stack_trace", appErr.StackTrace))
+
+			if httpMetrics != nil {
+				httpMetrics.RecordPanic(c.Request.Method, c.FullPath())
+			}
 
-				// Send error response
-				sendErrorResponse(c, appErr)
+			report := errorreporting.FromPanic(recovered, stack)
+			report.CorrelationID = correlationID
+			report.UserID = userIDFromContext(c)
+			report.Request = c.Request
+			reporter.Capture(report)
+
+			if c.Writer.Written() {
+				// Part of the response is already on the wire; it cannot be replaced by an error response
 				c.Abort()
+				return
 			}
+			response.ErrorWithAppError(c, appErr)
+			c.Abort()
 		}()
 
 		c.Next()
+
+		// Report server errors rendered through the response package
+		if c.Writer.Status() >= 500 {
+			if appErr, ok := response.AppErrorFromContext(c); ok {
+				report := errorreporting.FromAppError(appErr)
+				if report.UserID == "" {
+					report.UserID = userIDFromContext(c)
+				}
+				report.Request = c.Request
+				reporter.Capture(report)
+			}
+		}
+	}
+}
+
+// panicError converts a recovered panic value to the cause of the internal error
+func panicError(recovered any) error {
+	if err, ok := recovered.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
+
+// userIDFromContext returns the authenticated user ID set by the auth middleware
+func userIDFromContext(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprint(userID)
 	}
+	return ""
 }
 
 // toString converts any value to string
@@ -184,7 +267,7 @@ func (h *ErrorHandler) HandleError(c *gin.Context, err error) {
 	if customErr, ok := err.(*errors.AppError); ok {
 		appErr = customErr
 	} else {
-		appErr = errors.NewInternalError("Internal server error", err)
+		appErr = errors.NewInternalError(localize(c, "error.internal"), err)
 	}
 
 	// Add correlation ID if available
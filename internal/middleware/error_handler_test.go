@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/pkg/errorreporting"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// captureReporter 保存上报内容的 Reporter
+type captureReporter struct {
+	mu      sync.Mutex
+	reports []*errorreporting.Report
+}
+
+func (r *captureReporter) Capture(report *errorreporting.Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+func (r *captureReporter) Flush(ctx context.Context) error { return nil }
+
+// newRecoveryRouter 创建安装了恢复中间件的路由，/panic 触发 panic
+func newRecoveryRouter(reporter errorreporting.Reporter, httpMetrics *metrics.HTTPMetrics) (*gin.Engine, *observer.ObservedLogs) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.ErrorLevel)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// 模拟结构化日志中间件设置的关联ID
+		c.Set(correlationIDContextKey, c.GetHeader(correlationIDHeader))
+	})
+	router.Use(RecoveryMiddleware(logger.NewZapLogger(zap.New(core)), reporter, httpMetrics))
+	router.GET("/panic/:id", func(c *gin.Context) {
+		var user map[string]string
+		user["name"] = c.Param("id") // assignment to entry in nil map
+	})
+	router.GET("/partial", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("after write")
+	})
+	router.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	return router, logs
+}
+
+func TestRecoveryMiddleware_ConvertsPanicToInternalError(t *testing.T) {
+	reporter := &captureReporter{}
+	httpMetrics := metrics.NewHTTPMetrics()
+	router, logs := newRecoveryRouter(reporter, httpMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic/42", nil)
+	req.Header.Set(correlationIDHeader, "panic-test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	var body struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Code    string                 `json:"code"`
+			Details map[string]interface{} `json:"details"`
+		} `json:"error"`
+		CorrelationID string `json:"correlation_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Success)
+	assert.Equal(t, "INTERNAL_ERROR", body.Error.Code)
+	assert.Equal(t, true, body.Error.Details["panic"])
+	assert.Equal(t, "panic-test", body.CorrelationID)
+	assert.NotContains(t, w.Body.String(), "goroutine", "the stack trace must not be sent to the client")
+
+	// 结构化日志包含关联ID、路由和堆栈
+	entries := logs.FilterMessage("Panic recovered").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "panic-test", fields["correlation_id"])
+	assert.Equal(t, "/panic/:id", fields["route"])
+	assert.Contains(t, fields["error"], "assignment to entry in nil map")
+	assert.True(t, strings.Contains(fields["stack_trace"].(string), "goroutine"))
+
+	// panic 计数
+	stats := httpMetrics.GetStats()
+	assert.Equal(t, uint64(1), stats.TotalPanics)
+	require.Len(t, stats.Routes, 1)
+	assert.Equal(t, "/panic/:id", stats.Routes[0].Route)
+	assert.Equal(t, http.StatusInternalServerError, stats.Routes[0].Status)
+
+	// 错误上报只有一次，带有关联ID和堆栈
+	require.Len(t, reporter.reports, 1)
+	report := reporter.reports[0]
+	assert.Equal(t, errorreporting.LevelFatal, report.Level)
+	assert.Equal(t, "panic-test", report.CorrelationID)
+	assert.Contains(t, report.StackTrace, "goroutine")
+}
+
+func TestRecoveryMiddleware_ResponseAlreadyWritten(t *testing.T) {
+	reporter := &captureReporter{}
+	router, _ := newRecoveryRouter(reporter, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "partial", w.Body.String(), "no error body is appended to a started response")
+	assert.Len(t, reporter.reports, 1)
+}
+
+func TestRecoveryMiddleware_RepanicsAbortHandler(t *testing.T) {
+	reporter := &captureReporter{}
+	router, _ := newRecoveryRouter(reporter, nil)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+	assert.Empty(t, reporter.reports)
+}
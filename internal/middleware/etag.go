@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"go-server/internal/config"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagResponseWriter 缓冲响应体以便计算 ETag，响应体超过上限或处理器主动刷新时改为直接输出
+type etagResponseWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	limit       int64
+	passthrough bool
+}
+
+// Write 缓冲响应体
+func (w *etagResponseWriter) Write(data []byte) (int, error) {
+	if !w.passthrough && int64(w.body.Len()+len(data)) <= w.limit {
+		return w.body.Write(data)
+	}
+	if err := w.startPassthrough(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 缓冲响应体
+func (w *etagResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 处理器主动刷新时说明是流式响应，不再计算 ETag
+func (w *etagResponseWriter) Flush() {
+	if err := w.startPassthrough(); err == nil {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Unwrap 返回底层写入器，供 http.ResponseController 设置流式响应的写超时
+func (w *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// startPassthrough 输出已缓冲的响应体并切换为直接输出
+func (w *etagResponseWriter) startPassthrough() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	if w.body.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	w.body.Reset()
+	return err
+}
+
+// ETag 实体标签中间件
+// 为 GET/HEAD 请求的 200 响应设置 ETag：优先使用处理器设置的 ETag 响应头，其次根据 response.Success
+// 发送的数据计算弱标签，其他响应按响应体计算强标签。If-None-Match 匹配时返回不带响应体的 304。
+// 写请求的 If-Match 校验由处理器调用 response.CheckIfMatch 完成
+type ETag struct {
+	enabled      bool
+	maxBodySize  int64
+	excludePaths []string
+}
+
+// NewETag 根据配置创建实体标签中间件
+func NewETag(cfg config.ETagConfig) *ETag {
+	return &ETag{
+		enabled:      cfg.Enabled,
+		maxBodySize:  cfg.MaxBodySize,
+		excludePaths: append([]string(nil), cfg.ExcludePaths...),
+	}
+}
+
+// Middleware 返回实体标签处理函数
+func (e *ETag) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !e.enabled || !e.applies(c.Request) {
+			c.Next()
+			return
+		}
+
+		writer := &etagResponseWriter{ResponseWriter: c.Writer, limit: e.maxBodySize}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		// 已直接输出或处理器自行写出了响应头（如自行返回 304）
+		if writer.passthrough || writer.ResponseWriter.Written() {
+			writer.startPassthrough()
+			return
+		}
+
+		if writer.Status() != http.StatusOK {
+			writer.startPassthrough()
+			return
+		}
+
+		tag := writer.Header().Get(response.ETagHeader)
+		if tag == "" {
+			tag = e.computeTag(c, writer.body.Bytes())
+			response.SetETag(c, tag)
+		}
+
+		if response.ETagMatches(c.GetHeader(response.IfNoneMatchHeader), tag) {
+			header := writer.Header()
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			writer.WriteHeader(http.StatusNotModified)
+			writer.WriteHeaderNow()
+			return
+		}
+
+		writer.startPassthrough()
+	}
+}
+
+// applies 判断请求是否需要计算 ETag
+func (e *ETag) applies(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	for _, prefix := range e.excludePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// computeTag 计算响应的 ETag，统一响应结构的时间戳每次都不同，因此只对数据部分计算
+func (e *ETag) computeTag(c *gin.Context, body []byte) string {
+	if data, ok := response.DataFromContext(c); ok {
+		return response.ComputeETag(data)
+	}
+	return response.StrongETag(body)
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/config"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newETagRouter(maxBodySize int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	etag := NewETag(config.ETagConfig{
+		Enabled:      true,
+		MaxBodySize:  maxBodySize,
+		ExcludePaths: []string{"/stream"},
+	})
+
+	router := gin.New()
+	router.Use(etag.Middleware())
+	router.GET("/users/1", func(c *gin.Context) {
+		response.Success(c, http.StatusOK, "ok", gin.H{"id": "1", "name": "john"})
+	})
+	router.GET("/plain", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	router.GET("/custom", func(c *gin.Context) {
+		response.SetETag(c, `"v42"`)
+		c.String(http.StatusOK, "custom")
+	})
+	router.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("a", 100))
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, "stream")
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		response.NotFoundError(c, "User", "2")
+	})
+	return router
+}
+
+func sendETagRequest(router *gin.Engine, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set(response.IfNoneMatchHeader, ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestETag_EnvelopeResponses 测试统一响应结构按数据计算稳定的弱 ETag，匹配时返回 304
+func TestETag_EnvelopeResponses(t *testing.T) {
+	router := newETagRouter(1 << 20)
+
+	first := sendETagRequest(router, "/users/1", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	tag := first.Header().Get(response.ETagHeader)
+	require.True(t, strings.HasPrefix(tag, `W/"`), tag)
+	assert.Equal(t, response.ComputeETag(gin.H{"id": "1", "name": "john"}), tag)
+
+	// 时间戳不同，ETag 保持不变
+	second := sendETagRequest(router, "/users/1", "")
+	assert.Equal(t, tag, second.Header().Get(response.ETagHeader))
+
+	notModified := sendETagRequest(router, "/users/1", `"other", `+tag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, tag, notModified.Header().Get(response.ETagHeader))
+
+	stale := sendETagRequest(router, "/users/1", `W/"stale"`)
+	assert.Equal(t, http.StatusOK, stale.Code)
+	assert.Contains(t, stale.Body.String(), "john")
+}
+
+// TestETag_BodyAndHandlerTags 测试按响应体计算强 ETag 以及使用处理器设置的 ETag
+func TestETag_BodyAndHandlerTags(t *testing.T) {
+	router := newETagRouter(1 << 20)
+
+	plain := sendETagRequest(router, "/plain", "")
+	assert.Equal(t, response.StrongETag([]byte("hello")), plain.Header().Get(response.ETagHeader))
+	assert.Equal(t, "hello", plain.Body.String())
+
+	custom := sendETagRequest(router, "/custom", `"v42"`)
+	assert.Equal(t, http.StatusNotModified, custom.Code)
+
+	wildcard := sendETagRequest(router, "/plain", "*")
+	assert.Equal(t, http.StatusNotModified, wildcard.Code)
+}
+
+// TestETag_Skipped 测试超出缓冲上限、排除路径和非 200 响应不计算 ETag
+func TestETag_Skipped(t *testing.T) {
+	router := newETagRouter(10)
+
+	large := sendETagRequest(router, "/large", "")
+	assert.Equal(t, http.StatusOK, large.Code)
+	assert.Len(t, large.Body.String(), 100)
+	assert.Empty(t, large.Header().Get(response.ETagHeader))
+
+	stream := sendETagRequest(router, "/stream", "*")
+	assert.Equal(t, http.StatusOK, stream.Code)
+	assert.Empty(t, stream.Header().Get(response.ETagHeader))
+
+	missing := sendETagRequest(newETagRouter(1<<20), "/missing", "*")
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Empty(t, missing.Header().Get(response.ETagHeader))
+}
+
+// TestCheckIfMatch 测试写请求的 If-Match 校验
+func TestCheckIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	current := response.ComputeETag(gin.H{"id": "1"})
+
+	cases := []struct {
+		header string
+		passed bool
+	}{
+		{"", true},
+		{current, true},
+		{strings.TrimPrefix(current, "W/"), true},
+		{"*", true},
+		{`W/"outdated"`, false},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/users/1", nil)
+		if tc.header != "" {
+			c.Request.Header.Set(response.IfMatchHeader, tc.header)
+		}
+
+		assert.Equal(t, tc.passed, response.CheckIfMatch(c, current), tc.header)
+		if !tc.passed {
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+			assert.Contains(t, w.Body.String(), "PRECONDITION_FAILED")
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"go-server/pkg/featureflags"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagsContextKey gin 上下文中保存开关集合的键
+const FeatureFlagsContextKey = "feature_flags"
+
+// FeatureFlagsMiddleware 将当前请求的开关评估结果写入 gin 上下文和请求上下文，
+// 处理器和服务通过 featureflags.Flags(ctx) 读取。开关在第一次读取时才评估，
+// 因此分组上的认证中间件写入的用户ID也会参与评估
+func FeatureFlagsMiddleware(manager *featureflags.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		set := featureflags.NewLazySet(func() map[string]bool {
+			return manager.Evaluate(ctx, c.GetString("user_id"))
+		})
+
+		c.Set(FeatureFlagsContextKey, set)
+		c.Request = c.Request.WithContext(featureflags.WithFlags(ctx, set))
+		c.Next()
+	}
+}
+
+// FeatureFlags 返回 gin 上下文中的开关集合，未经过中间件时所有开关视为关闭
+func FeatureFlags(c *gin.Context) *featureflags.Set {
+	if set, ok := c.Get(FeatureFlagsContextKey); ok {
+		if flags, ok := set.(*featureflags.Set); ok {
+			return flags
+		}
+	}
+	return featureflags.Flags(c.Request.Context())
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/pkg/featureflags"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFeatureFlagsMiddleware_EvaluatesAfterAuth 测试开关按认证中间件写入的用户评估
+func TestFeatureFlagsMiddleware_EvaluatesAfterAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := featureflags.NewManager(featureflags.NewMemoryStore(
+		&featureflags.Flag{Key: "beta", Enabled: true, Users: []string{"u1"}},
+		&featureflags.Flag{Key: "everyone", Enabled: true, Percentage: 100},
+	), time.Minute)
+
+	router := gin.New()
+	router.Use(FeatureFlagsMiddleware(manager))
+	authed := router.Group("/", func(c *gin.Context) {
+		if userID := c.GetHeader("X-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+	})
+	authed.GET("/flags", func(c *gin.Context) {
+		// 服务层通过请求上下文读取，与 gin 上下文中的结果一致
+		assert.Equal(t, FeatureFlags(c).All(), featureflags.Flags(c.Request.Context()).All())
+		c.JSON(http.StatusOK, FeatureFlags(c).All())
+	})
+
+	for _, tc := range []struct {
+		user string
+		want string
+	}{
+		{"u1", `{"beta":true,"everyone":true}`},
+		{"u2", `{"beta":false,"everyone":true}`},
+		{"", `{"beta":false,"everyone":true}`},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/flags", nil)
+		req.Header.Set("X-User", tc.user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, tc.want, w.Body.String(), "user %q", tc.user)
+	}
+}
+
+// TestFeatureFlags_WithoutMiddleware 测试未安装中间件时所有开关关闭
+func TestFeatureFlags_WithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background())
+	assert.False(t, FeatureFlags(c).Enabled("beta"))
+}
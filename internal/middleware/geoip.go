@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"go-server/internal/metrics"
+	"go-server/pkg/geoip"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeoIPContextKey gin 上下文中保存客户端地理位置的键
+const GeoIPContextKey = "geoip_location"
+
+// GeoIPMiddleware 查询客户端 IP 的国家和自治系统并写入 gin 上下文，请求日志从中读取；
+// httpMetrics 不为 nil 时请求结束后按国家统计流量，无法定位的请求计入 unknown
+func GeoIPMiddleware(locator geoip.Locator, httpMetrics *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		location, ok := locator.Locate(c.ClientIP())
+		if ok {
+			c.Set(GeoIPContextKey, location)
+		}
+
+		c.Next()
+
+		if httpMetrics != nil {
+			httpMetrics.RecordCountry(location.Country, c.Writer.Status(), int64(c.Writer.Size()))
+		}
+	}
+}
+
+// GeoLocation 返回 gin 上下文中的客户端地理位置，未启用或无法定位时返回 false
+func GeoLocation(c *gin.Context) (geoip.Location, bool) {
+	if value, ok := c.Get(GeoIPContextKey); ok {
+		if location, ok := value.(geoip.Location); ok {
+			return location, true
+		}
+	}
+	return geoip.Location{}, false
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/internal/metrics"
+	"go-server/pkg/geoip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticLocator 按固定的映射返回地理位置
+type staticLocator map[string]geoip.Location
+
+func (l staticLocator) Locate(ip string) (geoip.Location, bool) {
+	location, ok := l[ip]
+	return location, ok
+}
+
+func TestGeoIPMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	httpMetrics := metrics.NewHTTPMetrics()
+	locator := staticLocator{"203.0.113.7": {Country: "AU", ASN: 64500, Organization: "EXAMPLE"}}
+	router := gin.New()
+	router.Use(GeoIPMiddleware(locator, httpMetrics))
+	router.GET("/where", func(c *gin.Context) {
+		location, ok := GeoLocation(c)
+		if !ok {
+			c.String(http.StatusNotFound, "unknown")
+			return
+		}
+		c.String(http.StatusOK, location.Country)
+	})
+
+	serve := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/where", nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("203.0.113.7")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "AU", w.Body.String())
+
+	w = serve("198.51.100.1")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	stats := httpMetrics.GetCountryStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, metrics.CountryStats{Country: "AU", Requests: 1, ResponseBytes: 2}, stats[0])
+	assert.Equal(t, metrics.CountryStats{Country: metrics.UnknownCountry, Requests: 1, ClientErrors: 1, ResponseBytes: 7}, stats[1])
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"go-server/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMetricsMiddleware 按方法、路由模板和状态码记录请求耗时、请求和响应大小，并统计处理中的请求数
+// 需作为第一个中间件安装，以便耗时包含其他中间件，恢复的 panic 也按 500 计入
+func HTTPMetricsMiddleware(httpMetrics *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpMetrics.RequestStarted()
+
+		defer func() {
+			status := c.Writer.Status()
+			recovered := recover()
+			if recovered != nil {
+				status = http.StatusInternalServerError
+			}
+			httpMetrics.RequestFinished(c.Request.Method, c.FullPath(), status, time.Since(start), c.Request.ContentLength, int64(c.Writer.Size()))
+			if recovered != nil {
+				// 未被恢复中间件处理的 panic 继续向上传播
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHTTPMetricsMiddleware 测试按路由模板记录请求，并在请求结束后减少处理中的请求数
+func TestHTTPMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	httpMetrics := metrics.NewHTTPMetrics()
+	router := gin.New()
+	router.Use(HTTPMetricsMiddleware(httpMetrics))
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+
+	var inFlight int64
+	router.POST("/users/:id", func(c *gin.Context) {
+		inFlight = httpMetrics.InFlight()
+		c.String(http.StatusCreated, "created")
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	for _, id := range []string{"1", "2"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/"+id, strings.NewReader("body")))
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	assert.Equal(t, int64(1), inFlight)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	stats := httpMetrics.GetStats()
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Equal(t, uint64(4), stats.TotalRequests)
+
+	series := map[string]metrics.HTTPRouteStats{}
+	for _, route := range stats.Routes {
+		series[route.Method+" "+route.Route] = route
+	}
+	users := series["POST /users/:id"]
+	assert.Equal(t, uint64(2), users.Requests)
+	assert.Equal(t, http.StatusCreated, users.Status)
+	assert.Equal(t, uint64(8), users.RequestBytes)
+	assert.Equal(t, uint64(14), users.ResponseBytes)
+	assert.Equal(t, http.StatusInternalServerError, series["GET /panic"].Status)
+	assert.Equal(t, http.StatusNotFound, series["GET "+metrics.UnmatchedRoute].Status)
+}
@@ -0,0 +1,367 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/tenancy"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端提供幂等键的请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotencyReplayedHeader 标记响应为重放结果的响应头
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength 幂等键的最大长度
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencySkippedHeaders 不随响应一起保存的响应头，这些头与单次请求相关
+var idempotencySkippedHeaders = []string{
+	"Content-Length",
+	"Content-Encoding",
+	"Vary",
+	"Date",
+	"Set-Cookie",
+	"X-Correlation-Id",
+	"X-Request-Id",
+	"Retry-After",
+}
+
+// IdempotencyRecord 幂等键对应的请求记录
+type IdempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"`           // 请求指纹（方法、路径和请求体的哈希）
+	Completed   bool        `json:"completed"`             // 请求是否已处理完成
+	StatusCode  int         `json:"status_code,omitempty"` // 响应状态码
+	Header      http.Header `json:"header,omitempty"`      // 响应头
+	Body        []byte      `json:"body,omitempty"`        // 响应体
+	CreatedAt   time.Time   `json:"created_at"`            // 记录创建时间
+}
+
+// IdempotencyStore 幂等记录存储
+type IdempotencyStore interface {
+	// Reserve 占用幂等键，成功时返回 true；键已存在时返回已保存的记录
+	Reserve(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error)
+
+	// Save 保存已完成请求的记录
+	Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+
+	// Release 释放幂等键，允许使用同一个键重试
+	Release(ctx context.Context, key string) error
+}
+
+// RedisIdempotencyStore 基于 Redis 的幂等记录存储，适用于多实例部署
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewRedisIdempotencyStore 创建基于 Redis 的幂等记录存储
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+// Reserve 使用 SETNX 原子占用幂等键
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 已有记录恰好过期时重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		reserved, err := s.client.SetNX(ctx, key, data, ttl).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if reserved {
+			return nil, true, nil
+		}
+
+		existing, err := s.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		var stored IdempotencyRecord
+		if err := json.Unmarshal(existing, &stored); err != nil {
+			return nil, false, err
+		}
+		return &stored, false, nil
+	}
+
+	return nil, false, stderrors.New("idempotency key is being reserved concurrently")
+}
+
+// Save 保存已完成请求的记录
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// Release 删除幂等键
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// memoryIdempotencyEntry 内存存储中的记录
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore 内存幂等记录存储，仅在单实例内有效
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+}
+
+// NewMemoryIdempotencyStore 创建内存幂等记录存储
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+// Reserve 占用幂等键
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		stored := entry.record
+		return &stored, false, nil
+	}
+
+	// 顺便清理过期记录
+	for k, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{record: *record, expiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+// Save 保存已完成请求的记录
+func (s *MemoryIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryIdempotencyEntry{record: *record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release 删除幂等键
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// idempotencyResponseWriter 记录响应体以便保存
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+// Write 写入响应并保留副本
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应并保留副本
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等请求中间件
+// 携带 Idempotency-Key 的请求在首次处理后保存响应，相同键的重试直接重放保存的响应，
+// 键相同但请求内容不同或首次请求仍在处理中时返回 409
+type Idempotency struct {
+	store       IdempotencyStore
+	ttl         time.Duration
+	lockTimeout time.Duration
+	methods     []string
+	keyPrefix   string
+}
+
+// NewIdempotency 根据配置创建幂等请求中间件
+func NewIdempotency(cfg config.IdempotencyConfig, store IdempotencyStore) *Idempotency {
+	methods := make([]string, 0, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+
+	return &Idempotency{
+		store:       store,
+		ttl:         time.Duration(cfg.TTL) * time.Second,
+		lockTimeout: time.Duration(cfg.LockTimeout) * time.Second,
+		methods:     methods,
+		keyPrefix:   cfg.KeyPrefix,
+	}
+}
+
+// Middleware 返回幂等请求处理函数
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" || !slices.Contains(i.methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			response.ValidationError(c, localize(c, "idempotency.key_too_long"), errors.ErrorDetails{
+				Field:      IdempotencyKeyHeader,
+				Message:    localize(c, "idempotency.key_too_long_detail"),
+				Constraint: "max=255",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if stderrors.As(err, &maxBytesErr) {
+				response.PayloadTooLargeError(c, maxBytesErr.Limit)
+			} else {
+				response.BadRequest(c, localize(c, "validation.read_body_failed"))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := i.storageKey(c, idempotencyKey)
+		fingerprint := requestFingerprint(c.Request, body)
+		ctx := c.Request.Context()
+
+		existing, reserved, err := i.store.Reserve(ctx, key, &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			CreatedAt:   time.Now().UTC(),
+		}, i.lockTimeout)
+		if err != nil {
+			// 存储不可用时放行，不影响正常请求
+			c.Next()
+			return
+		}
+
+		if !reserved {
+			i.handleExisting(c, existing, fingerprint)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		// 请求结束后客户端可能已断开，保存时不使用请求的取消信号
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			// 服务端错误允许客户端使用同一个键重试
+			i.store.Release(saveCtx, key)
+			return
+		}
+
+		i.store.Save(saveCtx, key, &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  status,
+			Header:      storableHeader(writer.Header()),
+			Body:        writer.body.Bytes(),
+			CreatedAt:   time.Now().UTC(),
+		}, i.ttl)
+	}
+}
+
+// handleExisting 处理幂等键已存在的请求
+func (i *Idempotency) handleExisting(c *gin.Context, existing *IdempotencyRecord, fingerprint string) {
+	defer c.Abort()
+
+	if existing.Fingerprint != fingerprint {
+		response.ConflictError(c, localize(c, "idempotency.key_reused"), map[string]interface{}{
+			"header": IdempotencyKeyHeader,
+		})
+		return
+	}
+
+	if !existing.Completed {
+		c.Header("Retry-After", "1")
+		response.ConflictError(c, localize(c, "idempotency.in_progress"), map[string]interface{}{
+			"header": IdempotencyKeyHeader,
+		})
+		return
+	}
+
+	for name, values := range existing.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(IdempotencyReplayedHeader, "true")
+	c.Writer.WriteHeader(existing.StatusCode)
+	c.Writer.Write(existing.Body)
+}
+
+// storageKey 生成存储键，按租户和 Authorization 区分不同调用方，避免不同用户之间的键冲突
+func (i *Idempotency) storageKey(c *gin.Context, idempotencyKey string) string {
+	scope := "anonymous"
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		sum := sha256.Sum256([]byte(authorization))
+		scope = hex.EncodeToString(sum[:8])
+	}
+	return i.keyPrefix + ":" + tenancy.CacheKey(c.Request.Context(), scope+":"+idempotencyKey)
+}
+
+// requestFingerprint 计算请求指纹，用于识别同一个键下不同的请求
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storableHeader 复制需要随响应一起保存的响应头
+func storableHeader(header http.Header) http.Header {
+	stored := make(http.Header, len(header))
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if slices.Contains(idempotencySkippedHeaders, canonical) || strings.HasPrefix(canonical, "X-Ratelimit-") {
+			continue
+		}
+		stored[canonical] = append([]string(nil), values...)
+	}
+	return stored
+}
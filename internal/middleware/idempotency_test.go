@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyRouter(store IdempotencyStore, calls *atomic.Int32, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	idempotency := NewIdempotency(config.IdempotencyConfig{
+		Enabled:     true,
+		TTL:         60,
+		LockTimeout: 10,
+		Methods:     []string{"post", "put"},
+		KeyPrefix:   "idempotency",
+	}, store)
+
+	router := gin.New()
+	router.Use(idempotency.Middleware())
+	handler := func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("Location", "/orders/1")
+		c.JSON(status, gin.H{"call": n})
+	}
+	router.POST("/orders", handler)
+	router.GET("/orders", handler)
+	return router
+}
+
+func sendIdempotent(router *gin.Engine, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token-a")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotency_ReplaysStoredResponse 测试相同键的重试重放首次响应
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(NewMemoryIdempotencyStore(), &calls, http.StatusCreated)
+
+	first := sendIdempotent(router, http.MethodPost, "key-1", `{"amount":100}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotencyReplayedHeader))
+
+	second := sendIdempotent(router, http.MethodPost, "key-1", `{"amount":100}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "/orders/1", second.Header().Get("Location"))
+	assert.Equal(t, "true", second.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 不同的键会重新处理
+	third := sendIdempotent(router, http.MethodPost, "key-2", `{"amount":100}`)
+	assert.Equal(t, `{"call":2}`, third.Body.String())
+}
+
+// TestIdempotency_ConflictingPayload 测试相同键不同请求体返回 409
+func TestIdempotency_ConflictingPayload(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(NewMemoryIdempotencyStore(), &calls, http.StatusCreated)
+
+	sendIdempotent(router, http.MethodPost, "key-1", `{"amount":100}`)
+	w := sendIdempotent(router, http.MethodPost, "key-1", `{"amount":200}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "different request")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestIdempotency_InProgress 测试首次请求未完成时的重试返回 409
+func TestIdempotency_InProgress(t *testing.T) {
+	var calls atomic.Int32
+	store := NewMemoryIdempotencyStore()
+	router := newIdempotencyRouter(store, &calls, http.StatusCreated)
+
+	// 模拟另一个实例正在处理同一请求
+	key := "idempotency:anonymous:key-1"
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+	_, reserved, err := store.Reserve(context.Background(), key, &IdempotencyRecord{
+		Fingerprint: requestFingerprint(req, []byte(`{}`)),
+	}, time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	anonymous := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+	anonymous.Header.Set(IdempotencyKeyHeader, "key-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, anonymous)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+// TestIdempotency_ServerErrorAllowsRetry 测试服务端错误不保存响应
+func TestIdempotency_ServerErrorAllowsRetry(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(NewMemoryIdempotencyStore(), &calls, http.StatusInternalServerError)
+
+	sendIdempotent(router, http.MethodPost, "key-1", `{}`)
+	w := sendIdempotent(router, http.MethodPost, "key-1", `{}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestIdempotency_Skipped 测试未携带键或非幂等方法的请求直接通过
+func TestIdempotency_Skipped(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(NewMemoryIdempotencyStore(), &calls, http.StatusOK)
+
+	sendIdempotent(router, http.MethodPost, "", `{}`)
+	sendIdempotent(router, http.MethodPost, "", `{}`)
+	sendIdempotent(router, http.MethodGet, "key-1", "")
+	sendIdempotent(router, http.MethodGet, "key-1", "")
+	assert.Equal(t, int32(4), calls.Load())
+
+	w := sendIdempotent(router, http.MethodPost, strings.Repeat("k", 256), `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestIdempotency_ScopedByCaller 测试不同调用方使用相同的键互不影响
+func TestIdempotency_ScopedByCaller(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(NewMemoryIdempotencyStore(), &calls, http.StatusCreated)
+
+	sendIdempotent(router, http.MethodPost, "key-1", `{}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer token-b")
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"go-server/internal/validation"
+	"go-server/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleContextKey gin 上下文中保存协商出的语言的键
+const LocaleContextKey = "locale"
+
+// LocaleMiddleware 根据 Accept-Language 请求头从 bundle 支持的语言中协商响应语言，
+// 写入 gin 上下文和请求上下文（i18n.Locale 读取），并设置 Content-Language 响应头；
+// 响应内容随 Accept-Language 变化，因此同时添加 Vary: Accept-Language 以免共享缓存混用语言
+func LocaleMiddleware(bundle *i18n.Bundle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := bundle.Match(c.GetHeader("Accept-Language"))
+		c.Set(LocaleContextKey, locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
+
+// RequestLocale 返回 gin 上下文中协商出的语言，未经过语言协商中间件时使用默认语言
+func RequestLocale(c *gin.Context) string {
+	if value, ok := c.Get(LocaleContextKey); ok {
+		if locale, ok := value.(string); ok {
+			return locale
+		}
+	}
+	return i18n.DefaultLocale
+}
+
+// localize 返回消息目录中 key 在请求协商语言下的文本，{0}、{1} 依次替换为 args
+// 未经过语言协商中间件时根据 Accept-Language 请求头选择语言
+func localize(c *gin.Context, key string, args ...string) string {
+	return i18n.Default().Translate(validation.Language(c), key, args...)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/internal/validation"
+	"go-server/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(LocaleMiddleware(i18n.Default()))
+	router.GET("/locale", func(c *gin.Context) {
+		locale, _ := i18n.Locale(c.Request.Context())
+		c.String(http.StatusOK, RequestLocale(c)+"|"+locale+"|"+validation.Language(c))
+	})
+
+	serve := func(acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/locale", nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("zh-CN,zh;q=0.9,en;q=0.8")
+	assert.Equal(t, "zh|zh|zh", w.Body.String())
+	assert.Equal(t, "zh", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+
+	w = serve("fr-FR")
+	assert.Equal(t, "en|en|en", w.Body.String())
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	w = serve("")
+	assert.Equal(t, "en|en|en", w.Body.String())
+}
+
+func TestRequestLocaleWithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, i18n.DefaultLocale, RequestLocale(c))
+}
@@ -8,13 +8,19 @@ import (
 	"log"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/pkg/tracecontext"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap/zapcore"
 )
 
 // correlationIDHeader 用于在HTTP头中传递关联ID
@@ -23,6 +29,23 @@ const correlationIDHeader = "X-Correlation-ID"
 // correlationIDContextKey 用于在Gin上下文中存储关联ID
 const correlationIDContextKey = "correlation_id"
 
+// requestIDHeader 用于在响应头中返回本次请求的ID
+const requestIDHeader = "X-Request-ID"
+
+// 预先规范化的头名称，头名称不规范时每次读写都会分配规范化后的副本
+var (
+	canonicalCorrelationIDHeader = http.CanonicalHeaderKey(correlationIDHeader)
+	canonicalRequestIDHeader     = http.CanonicalHeaderKey(requestIDHeader)
+	canonicalTraceParentHeader   = http.CanonicalHeaderKey(tracecontext.TraceParentHeader)
+	canonicalTraceStateHeader    = http.CanonicalHeaderKey(tracecontext.TraceStateHeader)
+)
+
+// requestIDContextKey 用于在Gin上下文和请求上下文中存储请求ID
+const requestIDContextKey = "request_id"
+
+// spanContextKey 用于在Gin上下文中存储本次请求的 W3C 链路上下文
+const spanContextKey = "span_context"
+
 // loggerContextKey 用于在Gin上下文中存储日志管理器
 const loggerContextKey = "logger_manager"
 
@@ -31,43 +54,109 @@ var globalLoggerManager *logger.Manager
 
 // LogEntry 结构化日志条目
 type LogEntry struct {
-	Timestamp     time.Time     `json:"timestamp"`            // 请求时间戳
-	CorrelationID string        `json:"correlation_id"`       // 关联ID，用于追踪请求
-	Method        string        `json:"method"`               // HTTP方法
-	Path          string        `json:"path"`                 // 请求路径
-	Protocol      string        `json:"protocol"`             // 协议版本
-	StatusCode    int           `json:"status_code"`          // 响应状态码
-	Latency       time.Duration `json:"latency"`              // 请求处理延迟
-	ClientIP      string        `json:"client_ip"`            // 客户端IP地址
-	UserAgent     string        `json:"user_agent"`           // 用户代理
-	Referer       string        `json:"referer"`              // 来源页面
-	RequestSize   int64         `json:"request_size"`         // 请求体大小（字节）
-	ResponseSize  int64         `json:"response_size"`        // 响应体大小（字节）
-	ErrorMessage  string        `json:"error_message"`        // 错误信息（如果有）
-	IsSlowRequest bool          `json:"is_slow_request"`      // 是否为慢请求（>1秒）
-	Stacktrace    string        `json:"stacktrace,omitempty"` // 堆栈跟踪（错误时）
-}
-
-// responseBodyWriter 用于捕获响应体的包装器
+	Timestamp     time.Time     `json:"timestamp"`               // 请求时间戳
+	CorrelationID string        `json:"correlation_id"`          // 关联ID，用于追踪请求
+	RequestID     string        `json:"request_id"`              // 请求ID，每一跳不同
+	TraceID       string        `json:"trace_id"`                // W3C 链路ID
+	SpanID        string        `json:"span_id"`                 // W3C span ID，即本次请求在链路中的ID
+	Method        string        `json:"method"`                  // HTTP方法
+	Path          string        `json:"path"`                    // 请求路径
+	Protocol      string        `json:"protocol"`                // 协议版本
+	StatusCode    int           `json:"status_code"`             // 响应状态码
+	Latency       time.Duration `json:"latency"`                 // 请求处理延迟
+	ClientIP      string        `json:"client_ip"`               // 客户端IP地址
+	Country       string        `json:"country,omitempty"`       // 客户端所在国家（启用 GeoIP 时）
+	ASN           uint          `json:"asn,omitempty"`           // 客户端所属自治系统（启用 GeoIP 时）
+	UserAgent     string        `json:"user_agent"`              // 用户代理
+	Referer       string        `json:"referer"`                 // 来源页面
+	RequestSize   int64         `json:"request_size"`            // 请求体大小（字节）
+	ResponseSize  int64         `json:"response_size"`           // 响应体大小（字节）
+	ErrorMessage  string        `json:"error_message"`           // 错误信息（如果有）
+	IsSlowRequest bool          `json:"is_slow_request"`         // 是否为慢请求（>1秒）
+	Stacktrace    string        `json:"stacktrace,omitempty"`    // 堆栈跟踪（错误时）
+	RequestBody   string        `json:"request_body,omitempty"`  // 脱敏后的请求体（启用记录时）
+	ResponseBody  string        `json:"response_body,omitempty"` // 脱敏后的响应体（启用记录时）
+}
+
+// clientLocation 返回 GeoIP 中间件查询到的客户端国家和自治系统
+func clientLocation(c *gin.Context) (string, uint) {
+	location, _ := GeoLocation(c)
+	return location.Country, location.ASN
+}
+
+// responseBodyWriter 统计响应大小的包装器，启用记录时保留响应体的前 limit 个字节
 type responseBodyWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	size    int64
+	capture *bytes.Buffer
+	limit   int
 }
 
-func (r responseBodyWriter) Write(b []byte) (int, error) {
-	r.body.Write(b)
+func (r *responseBodyWriter) Write(b []byte) (int, error) {
+	r.size += int64(len(b))
+	if r.capture != nil && r.capture.Len() < r.limit {
+		r.capture.Write(b[:min(len(b), r.limit-r.capture.Len())])
+	}
 	return r.ResponseWriter.Write(b)
 }
 
+// Unwrap 返回底层写入器，供 http.ResponseController 设置流式响应的写超时
+func (r *responseBodyWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// maxLogFields 请求日志的最大字段数
+const maxLogFields = 20
+
+// requestLog 单个请求的日志状态，通过 requestLogPool 复用以减少每个请求的内存分配
+type requestLog struct {
+	entry  LogEntry
+	body   countingReadCloser
+	writer responseBodyWriter
+	fields [maxLogFields]logger.Field // 日志字段的预分配存储
+}
+
+// requestLogPool 复用请求日志状态
+var requestLogPool = sync.Pool{
+	New: func() interface{} {
+		return new(requestLog)
+	},
+}
+
+// bodyBufferPool 复用记录请求和响应体的缓冲区，缓冲区大小受记录上限约束
+var bodyBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// release 归还记录请求和响应体的缓冲区，并将日志状态放回池中
+func (l *requestLog) release() {
+	if l.body.capture != nil {
+		l.body.capture.Reset()
+		bodyBufferPool.Put(l.body.capture)
+	}
+	if l.writer.capture != nil {
+		l.writer.capture.Reset()
+		bodyBufferPool.Put(l.writer.capture)
+	}
+	*l = requestLog{}
+	requestLogPool.Put(l)
+}
+
 // generateCorrelationID 生成新的关联ID
 func generateCorrelationID() string {
 	return uuid.New().String()
 }
 
-// getCorrelationID 从请求头中获取关联ID，如果不存在则生成新的
-func getCorrelationID(c *gin.Context) string {
+// getCorrelationID 从请求头中获取关联ID，如果不存在则使用调用方传入的链路ID，都没有时生成新的
+// 关联ID在整条调用链中保持不变，请求ID则每一跳不同
+func getCorrelationID(c *gin.Context, span tracecontext.SpanContext, propagated bool) string {
 	// 首先尝试从请求头中获取
-	corrID := c.GetHeader(correlationIDHeader)
+	corrID := c.GetHeader(canonicalCorrelationIDHeader)
+	if corrID == "" && propagated {
+		corrID = span.TraceID
+	}
 	if corrID == "" {
 		// 如果请求头中没有，则生成新的关联ID
 		corrID = generateCorrelationID()
@@ -89,6 +178,32 @@ func GetCorrelationIDFromContext(c *gin.Context) string {
 	return ""
 }
 
+// startSpan 解析请求头中的 traceparent/tracestate，本次请求作为调用方 span 的子 span；
+// 请求头缺失或无效时开始新的链路，第二个返回值表示链路是否来自调用方
+func startSpan(c *gin.Context) (tracecontext.SpanContext, bool) {
+	parent, err := tracecontext.Parse(c.GetHeader(canonicalTraceParentHeader), c.GetHeader(canonicalTraceStateHeader))
+	if err != nil {
+		return tracecontext.New(), false
+	}
+	return parent.Child(), true
+}
+
+// GetRequestIDFromContext 从Gin上下文中获取请求ID
+func GetRequestIDFromContext(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
+
+// GetSpanContextFromContext 从Gin上下文中获取本次请求的 W3C 链路上下文
+// 调用下游服务时使用 tracecontext.Inject(c.Request.Context(), req.Header) 传递
+func GetSpanContextFromContext(c *gin.Context) (tracecontext.SpanContext, bool) {
+	if value, exists := c.Get(spanContextKey); exists {
+		if span, ok := value.(tracecontext.SpanContext); ok {
+			return span, true
+		}
+	}
+	return tracecontext.SpanContext{}, false
+}
+
 // GetLoggerFromContext 从Gin上下文中获取日志记录器
 func GetLoggerFromContext(c *gin.Context) logger.Logger {
 	if loggerManager, exists := c.Get(loggerContextKey); exists {
@@ -138,6 +253,12 @@ func GetLoggerManager() *logger.Manager {
 	return globalLoggerManager
 }
 
+// SetLoggerManager 使用已创建的日志管理器作为全局日志管理器
+// 请求日志与应用共用同一个管理器，运行时修改的日志级别对两者同时生效
+func SetLoggerManager(manager *logger.Manager) {
+	globalLoggerManager = manager
+}
+
 // ShutdownLoggerManager 关闭全局日志管理器
 func ShutdownLoggerManager() error {
 	if globalLoggerManager != nil {
@@ -160,11 +281,29 @@ func (l *noopLoggerAdapter) WithFields(fields ...logger.Field) logger.Logger
 func (l *noopLoggerAdapter) WithModule(module string) logger.Logger                            { return l }
 func (l *noopLoggerAdapter) WithCorrelationID(correlationID string) logger.Logger              { return l }
 func (l *noopLoggerAdapter) Sync() error                                                       { return nil }
+func (l *noopLoggerAdapter) Enabled(level zapcore.Level) bool                                  { return false }
+
+// requestLogLevel 根据状态码和错误情况确定请求日志的级别和消息前缀
+func requestLogLevel(entry *LogEntry) (zapcore.Level, string) {
+	if entry.StatusCode >= 500 || entry.ErrorMessage != "" {
+		// 服务器错误或有错误信息，使用ERROR级别
+		return zapcore.ErrorLevel, "HTTP Request Error: "
+	}
+	if entry.StatusCode >= 400 {
+		// 客户端错误，使用WARN级别
+		return zapcore.WarnLevel, "HTTP Request Warning: "
+	}
+	// 成功请求，使用INFO级别
+	return zapcore.InfoLevel, "HTTP Request: "
+}
 
 // logEntryWithNewLogger 使用新的日志系统记录HTTP请求日志
-func logEntryWithNewLogger(c *gin.Context, entry LogEntry) {
-	// 获取日志记录器
-	loggerInstance := GetLoggerFromContext(c)
+// 日志级别被过滤时不构建字段和消息；fields 为字段的预分配存储
+func logEntryWithNewLogger(loggerInstance logger.Logger, entry *LogEntry, fields []logger.Field) {
+	level, prefix := requestLogLevel(entry)
+	if !logger.Enabled(loggerInstance, level) {
+		return
+	}
 
 	// 创建上下文并添加关联ID
 	ctx := context.Background()
@@ -173,7 +312,10 @@ func logEntryWithNewLogger(c *gin.Context, entry LogEntry) {
 	}
 
 	// 准备日志字段
-	fields := []logger.Field{
+	fields = append(fields[:0],
+		logger.String("request_id", entry.RequestID),
+		logger.String("trace_id", entry.TraceID),
+		logger.String("span_id", entry.SpanID),
 		logger.String("method", entry.Method),
 		logger.String("path", entry.Path),
 		logger.String("protocol", entry.Protocol),
@@ -185,6 +327,14 @@ func logEntryWithNewLogger(c *gin.Context, entry LogEntry) {
 		logger.Int64("request_size", entry.RequestSize),
 		logger.Int64("response_size", entry.ResponseSize),
 		logger.Bool("is_slow_request", entry.IsSlowRequest),
+	)
+
+	// 如果查询到客户端地理位置，添加国家和自治系统字段
+	if entry.Country != "" {
+		fields = append(fields, logger.String("country", entry.Country))
+	}
+	if entry.ASN != 0 {
+		fields = append(fields, logger.Int64("asn", int64(entry.ASN)))
 	}
 
 	// 如果有错误信息，添加错误字段
@@ -192,78 +342,166 @@ func logEntryWithNewLogger(c *gin.Context, entry LogEntry) {
 		fields = append(fields, logger.String("error_message", entry.ErrorMessage))
 	}
 
+	// 如果记录了请求和响应体，添加对应字段
+	if entry.RequestBody != "" {
+		fields = append(fields, logger.String("request_body", entry.RequestBody))
+	}
+	if entry.ResponseBody != "" {
+		fields = append(fields, logger.String("response_body", entry.ResponseBody))
+	}
+
 	// 如果有堆栈跟踪，添加堆栈跟踪字段
 	if entry.Stacktrace != "" {
 		fields = append(fields, logger.Stacktrace("stacktrace", entry.Stacktrace))
 	}
 
-	// 根据状态码和错误情况确定日志级别
-	var logMessage string
-	var logLevel func(context.Context, string, ...logger.Field)
+	// 记录日志
+	logMessage := prefix + entry.Method + " " + entry.Path + " -> " + strconv.Itoa(entry.StatusCode)
+	switch level {
+	case zapcore.ErrorLevel:
+		loggerInstance.Error(ctx, logMessage, fields...)
+	case zapcore.WarnLevel:
+		loggerInstance.Warn(ctx, logMessage, fields...)
+	default:
+		loggerInstance.Info(ctx, logMessage, fields...)
+	}
+}
 
-	if entry.StatusCode >= 500 || entry.ErrorMessage != "" {
-		// 服务器错误或有错误信息，使用ERROR级别
-		logLevel = loggerInstance.Error
-		logMessage = fmt.Sprintf("HTTP Request Error: %s %s -> %d", entry.Method, entry.Path, entry.StatusCode)
-	} else if entry.StatusCode >= 400 {
-		// 客户端错误，使用WARN级别
-		logLevel = loggerInstance.Warn
-		logMessage = fmt.Sprintf("HTTP Request Warning: %s %s -> %d", entry.Method, entry.Path, entry.StatusCode)
-	} else {
-		// 成功请求，使用INFO级别
-		logLevel = loggerInstance.Info
-		logMessage = fmt.Sprintf("HTTP Request: %s %s -> %d", entry.Method, entry.Path, entry.StatusCode)
+// countingReadCloser 统计已读取字节数的请求体，启用记录时保留前 limit 个字节
+type countingReadCloser struct {
+	io.ReadCloser
+	n       int64
+	capture *bytes.Buffer
+	limit   int
+}
+
+// Read 读取请求体并累计读取的字节数
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	if r.capture != nil && r.capture.Len() < r.limit {
+		r.capture.Write(p[:min(n, r.limit-r.capture.Len())])
 	}
+	return n, err
+}
 
-	// 记录日志
-	logLevel(ctx, logMessage, fields...)
+// bodyCapture 请求和响应体记录策略
+type bodyCapture struct {
+	maxBytes int
+	redactor *logger.Redactor
+}
+
+// bodyCapturePolicy 当前的请求和响应体记录策略，为 nil 时不记录
+var bodyCapturePolicy atomic.Pointer[bodyCapture]
+
+// UpdateBodyCaptureConfig 更新请求和响应体记录配置（支持热重载），仅影响新请求
+func UpdateBodyCaptureConfig(cfg config.LoggingBodyCaptureConfig) {
+	if !cfg.Enabled || cfg.MaxBytes <= 0 {
+		bodyCapturePolicy.Store(nil)
+		return
+	}
+	bodyCapturePolicy.Store(&bodyCapture{
+		maxBytes: cfg.MaxBytes,
+		redactor: logger.NewRedactor(cfg.RedactFields),
+	})
+}
+
+// accepts 判断内容类型是否需要记录，仅记录可以脱敏的 JSON 内容
+func (b *bodyCapture) accepts(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// format 返回脱敏后的内容，超过大小上限或无法解析的内容不记录原文
+func (b *bodyCapture) format(data []byte) string {
+	switch {
+	case len(data) == 0:
+		return ""
+	case len(data) > b.maxBytes:
+		return fmt.Sprintf("[omitted: body exceeds %d bytes]", b.maxBytes)
+	}
+
+	redacted, ok := b.redactor.RedactJSON(data)
+	if !ok {
+		return "[omitted: invalid JSON]"
+	}
+	return string(redacted)
 }
 
 // StructuredLoggingMiddleware 创建结构化日志中间件
 func StructuredLoggingMiddleware(cfg *config.Config) gin.HandlerFunc {
 	slowRequestThreshold := time.Second // 1秒阈值用于检测慢请求
 
+	if cfg != nil {
+		UpdateBodyCaptureConfig(cfg.Logging.BodyCapture)
+	}
+
 	return func(c *gin.Context) {
 		// 记录请求开始时间
 		startTime := time.Now()
 
+		// 延续调用方的 W3C 链路，并为本次请求生成新的请求ID
+		span, propagated := startSpan(c)
+		requestID := generateCorrelationID()
+		c.Set(spanContextKey, span)
+		c.Set(requestIDContextKey, requestID)
+
 		// 获取或生成关联ID
-		correlationID := getCorrelationID(c)
+		correlationID := getCorrelationID(c, span, propagated)
 
-		// 在响应头中设置关联ID，便于客户端追踪
-		c.Header(correlationIDHeader, correlationID)
+		// 在响应头中设置关联ID、请求ID和本次请求的 traceparent，便于客户端追踪
+		c.Header(canonicalCorrelationIDHeader, correlationID)
+		c.Header(canonicalRequestIDHeader, requestID)
+		c.Header(canonicalTraceParentHeader, span.TraceParent())
+
+		// 将关联ID、请求ID和链路上下文写入请求上下文，便于下游（如数据库慢查询日志、调用其他服务）获取
+		ctx := context.WithValue(c.Request.Context(), correlationIDContextKey, correlationID)
+		ctx = context.WithValue(ctx, requestIDContextKey, requestID)
+		c.Request = c.Request.WithContext(tracecontext.WithSpanContext(ctx, span))
 
 		// 在上下文中设置日志管理器
 		if globalLoggerManager != nil && globalLoggerManager.IsStarted() {
 			c.Set(loggerContextKey, globalLoggerManager)
 		}
 
-		// 读取请求体大小（如果有）
-		var requestSize int64
-		if c.Request.Body != nil {
-			// 读取请求体但不消费它
-			bodyBytes, err := io.ReadAll(c.Request.Body)
-			if err == nil {
-				requestSize = int64(len(bodyBytes))
-				c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		// 从池中获取本次请求的日志状态，请求结束后恢复请求体和响应写入器再放回池中
+		state := requestLogPool.Get().(*requestLog)
+
+		// 统计实际读取的请求体大小，不缓存请求体，以便后续的大小限制以流式方式生效
+		requestBody := &state.body
+		capture := bodyCapturePolicy.Load()
+		originalBody := c.Request.Body
+		if originalBody != nil {
+			requestBody.ReadCloser = originalBody
+			c.Request.Body = requestBody
+			if capture != nil && capture.accepts(c.GetHeader("Content-Type")) {
+				// 多保留一个字节用于判断是否超过上限
+				requestBody.capture = bodyBufferPool.Get().(*bytes.Buffer)
+				requestBody.limit = capture.maxBytes + 1
 			}
 		}
 
-		// 包装响应写入器以捕获响应大小
-		responseBodyWriter := &responseBodyWriter{
-			ResponseWriter: c.Writer,
-			body:           bytes.NewBufferString(""),
+		// 包装响应写入器以统计响应大小，启用记录时保留响应体
+		responseBodyWriter := &state.writer
+		responseBodyWriter.ResponseWriter = c.Writer
+		if capture != nil {
+			responseBodyWriter.capture = bodyBufferPool.Get().(*bytes.Buffer)
+			responseBodyWriter.limit = capture.maxBytes + 1
 		}
 		c.Writer = responseBodyWriter
 
-		// 捕获可能的panic
+		// 捕获可能的panic，并在请求结束后放回日志状态
 		defer func() {
 			if err := recover(); err != nil {
 				// 创建错误日志条目
 				latency := time.Since(startTime)
-				entry := LogEntry{
+				state.entry = LogEntry{
 					Timestamp:     startTime,
 					CorrelationID: correlationID,
+					RequestID:     requestID,
+					TraceID:       span.TraceID,
+					SpanID:        span.SpanID,
 					Method:        c.Request.Method,
 					Path:          c.Request.URL.Path,
 					Protocol:      c.Request.Proto,
@@ -272,23 +510,34 @@ func StructuredLoggingMiddleware(cfg *config.Config) gin.HandlerFunc {
 					ClientIP:      c.ClientIP(),
 					UserAgent:     c.Request.UserAgent(),
 					Referer:       c.Request.Referer(),
-					RequestSize:   requestSize,
-					ResponseSize:  int64(responseBodyWriter.body.Len()),
+					RequestSize:   requestBody.n,
+					ResponseSize:  responseBodyWriter.size,
 					ErrorMessage:  fmt.Sprintf("Panic recovered: %v", err),
 					IsSlowRequest: latency > slowRequestThreshold,
 					Stacktrace:    string(debug.Stack()),
 				}
+				state.entry.Country, state.entry.ASN = clientLocation(c)
 
 				// 使用新的日志系统记录错误日志
-				logEntryWithNewLogger(c, entry)
+				logEntryWithNewLogger(GetLoggerFromContext(c), &state.entry, state.fields[:0])
 
 				// 返回标准错误响应
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"success":        false,
 					"message":        "Internal server error",
 					"correlation_id": correlationID,
+					"request_id":     requestID,
 				})
 			}
+
+			// 请求已结束，不再引用池中的状态
+			if c.Writer == responseBodyWriter {
+				c.Writer = responseBodyWriter.ResponseWriter
+			}
+			if c.Request.Body == requestBody {
+				c.Request.Body = originalBody
+			}
+			state.release()
 		}()
 
 		// 处理请求
@@ -307,9 +556,13 @@ func StructuredLoggingMiddleware(cfg *config.Config) gin.HandlerFunc {
 		isSlow := latency > slowRequestThreshold
 
 		// 创建结构化日志条目
-		entry := LogEntry{
+		entry := &state.entry
+		*entry = LogEntry{
 			Timestamp:     startTime,
 			CorrelationID: correlationID,
+			RequestID:     requestID,
+			TraceID:       span.TraceID,
+			SpanID:        span.SpanID,
 			Method:        c.Request.Method,
 			Path:          c.Request.URL.Path,
 			Protocol:      c.Request.Proto,
@@ -318,30 +571,40 @@ func StructuredLoggingMiddleware(cfg *config.Config) gin.HandlerFunc {
 			ClientIP:      c.ClientIP(),
 			UserAgent:     c.Request.UserAgent(),
 			Referer:       c.Request.Referer(),
-			RequestSize:   requestSize,
-			ResponseSize:  int64(responseBodyWriter.body.Len()),
+			RequestSize:   requestBody.n,
+			ResponseSize:  responseBodyWriter.size,
 			ErrorMessage:  errorMessage,
 			IsSlowRequest: isSlow,
 		}
+		entry.Country, entry.ASN = clientLocation(c)
+
+		// 记录脱敏后的请求和响应体，已压缩的响应无法脱敏，不记录；请求日志被过滤时不脱敏
+		loggerInstance := GetLoggerFromContext(c)
+		if level, _ := requestLogLevel(entry); capture != nil && logger.Enabled(loggerInstance, level) {
+			if requestBody.capture != nil {
+				entry.RequestBody = capture.format(requestBody.capture.Bytes())
+			}
+			if capture.accepts(c.Writer.Header().Get("Content-Type")) && c.Writer.Header().Get("Content-Encoding") == "" {
+				entry.ResponseBody = capture.format(responseBodyWriter.capture.Bytes())
+			}
+		}
 
 		// 使用新的日志系统记录结构化日志
-		logEntryWithNewLogger(c, entry)
+		logEntryWithNewLogger(loggerInstance, entry, state.fields[:0])
 
-		// 如果是慢请求，额外记录警告日志
-		if isSlow {
-			// 获取日志记录器并记录慢请求警告
-			loggerInstance := GetLoggerFromContext(c)
+		// 如果是慢请求，额外记录警告日志，级别被过滤时不格式化消息
+		if isSlow && logger.Enabled(loggerInstance, zapcore.WarnLevel) {
 			ctx := context.Background()
 			if correlationID != "" {
 				ctx = context.WithValue(ctx, "correlation_id", correlationID)
 			}
 
-			slowFields := []logger.Field{
+			slowFields := append(state.fields[:0],
 				logger.String("method", entry.Method),
 				logger.String("path", entry.Path),
 				logger.Int64("latency_ms", entry.Latency.Milliseconds()),
 				logger.String("client_ip", entry.ClientIP),
-			}
+			)
 
 			loggerInstance.Warn(ctx, fmt.Sprintf("Slow request detected: %v", entry.Latency), slowFields...)
 		}
@@ -355,8 +618,8 @@ func LoggingWithConfig(cfg *config.Config) gin.HandlerFunc {
 }
 
 // UpdateMiddlewareSetup 在 middleware.go 中更新中间件设置函数
-// 使用结构化日志中间件替换原有的简单日志中间件
-func UpdateMiddlewareSetupWithStructuredLogging(cfg *config.Config) []gin.HandlerFunc {
+// 使用结构化日志中间件替换原有的简单日志中间件；CORS 配置无效时返回错误
+func UpdateMiddlewareSetupWithStructuredLogging(cfg *config.Config) ([]gin.HandlerFunc, error) {
 	var middlewares []gin.HandlerFunc
 
 	// 设置Gin模式
@@ -380,15 +643,14 @@ func UpdateMiddlewareSetupWithStructuredLogging(cfg *config.Config) []gin.Handle
 		logMgr, _ = logger.NewManager(defaultConfig)
 	}
 	baseLogger := logMgr.GetLogger("recovery")
-	middlewares = append(middlewares, RecoveryMiddleware(baseLogger))
+	middlewares = append(middlewares, RecoveryMiddleware(baseLogger, nil, nil))
 
 	// 添加CORS中间件
-	allowedOrigins := []string{"*"}
-	if cfg.Mode == "production" {
-		// 在生产环境中，指定允许的源
-		allowedOrigins = []string{"https://yourdomain.com"}
+	cors, err := NewCORS(cfg.CORS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CORS middleware: %w", err)
 	}
-	middlewares = append(middlewares, CORSMiddleware(allowedOrigins))
+	middlewares = append(middlewares, cors.Middleware())
 
 	// 添加安全头中间件
 	middlewares = append(middlewares, SecurityHeadersMiddleware(cfg))
@@ -396,19 +658,22 @@ func UpdateMiddlewareSetupWithStructuredLogging(cfg *config.Config) []gin.Handle
 	// 添加分布式限流中间件
 	middlewares = append(middlewares, RateLimiterMiddleware(cfg))
 
+	// 添加请求大小限制（10MB），需在压缩中间件之前以限制解压后的大小
+	middlewares = append(middlewares, RequestSizeLimitMiddleware(10<<20))
+
 	// 添加压缩中间件
 	if cfg.Compression.Enabled {
 		middlewares = append(middlewares, CompressionMiddleware(cfg.Compression.Threshold))
 	}
 
-	// 添加请求大小限制（10MB）
-	middlewares = append(middlewares, RequestSizeLimitMiddleware(10<<20))
-
-	return middlewares
+	return middlewares, nil
 }
 
 // OnLoggingConfigChange 处理日志配置变更的回调函数
 func OnLoggingConfigChange(oldConfig, newConfig config.LoggingConfig) error {
+	// 更新请求和响应体记录配置
+	UpdateBodyCaptureConfig(newConfig.BodyCapture)
+
 	// 更新日志管理器配置
 	if err := UpdateLoggerConfig(newConfig); err != nil {
 		log.Printf("Failed to update logger configuration: %v", err)
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureBodyLogs 使用写入临时文件的日志管理器发送请求，返回日志内容
+func captureBodyLogs(t *testing.T, capture config.LoggingBodyCaptureConfig, req *http.Request, handler gin.HandlerFunc) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Logging: config.LoggingConfig{
+			Level:       "info",
+			Format:      "json",
+			Output:      "file",
+			Directory:   dir,
+			BodyCapture: capture,
+		},
+	}
+	require.NoError(t, InitializeLoggerManager(cfg))
+	t.Cleanup(func() { UpdateBodyCaptureConfig(config.LoggingBodyCaptureConfig{}) })
+
+	router := gin.New()
+	router.Use(StructuredLoggingMiddleware(cfg))
+	router.POST("/login", handler)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, ShutdownLoggerManager())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	return string(data)
+}
+
+func newLoginRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return req
+}
+
+// TestStructuredLoggingMiddleware_BodyCaptureRedaction 测试记录的请求和响应体已脱敏
+func TestStructuredLoggingMiddleware_BodyCaptureRedaction(t *testing.T) {
+	capture := config.LoggingBodyCaptureConfig{
+		Enabled:      true,
+		MaxBytes:     1024,
+		RedactFields: []string{"password", "access_token", "data.user.email"},
+	}
+
+	output := captureBodyLogs(t, capture, newLoginRequest(`{"email":"john@example.com","password":"secret123"}`), func(c *gin.Context) {
+		var req map[string]interface{}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{
+			"access_token": "eyJhbGciOi",
+			"user":         gin.H{"email": "john@example.com", "username": "john"},
+		}})
+	})
+
+	assert.Contains(t, output, `request_body`)
+	assert.Contains(t, output, `response_body`)
+	assert.Contains(t, output, `\"password\":\"[REDACTED]\"`)
+	assert.Contains(t, output, `\"access_token\":\"[REDACTED]\"`)
+	assert.Contains(t, output, `\"username\":\"john\"`)
+	assert.NotContains(t, output, "secret123")
+	assert.NotContains(t, output, "eyJhbGciOi")
+	// 请求体中的 email 不在脱敏路径上，响应体中的 email 已脱敏
+	assert.Equal(t, 1, strings.Count(output, "john@example.com"))
+}
+
+// TestStructuredLoggingMiddleware_BodyCaptureLimits 测试超过上限和非 JSON 的内容不记录原文
+func TestStructuredLoggingMiddleware_BodyCaptureLimits(t *testing.T) {
+	capture := config.LoggingBodyCaptureConfig{Enabled: true, MaxBytes: 16}
+
+	output := captureBodyLogs(t, capture, newLoginRequest(`{"password":"a-very-long-secret"}`), func(c *gin.Context) {
+		var req map[string]interface{}
+		c.ShouldBindJSON(&req)
+		c.String(http.StatusOK, "plain text response")
+	})
+
+	assert.Contains(t, output, "[omitted: body exceeds 16 bytes]")
+	assert.NotContains(t, output, "a-very-long-secret")
+	assert.NotContains(t, output, "response_body")
+}
+
+// TestStructuredLoggingMiddleware_BodyCaptureDisabled 测试默认不记录请求和响应体
+func TestStructuredLoggingMiddleware_BodyCaptureDisabled(t *testing.T) {
+	output := captureBodyLogs(t, config.LoggingBodyCaptureConfig{}, newLoginRequest(`{"password":"secret123"}`), func(c *gin.Context) {
+		var req map[string]interface{}
+		c.ShouldBindJSON(&req)
+		c.JSON(http.StatusOK, req)
+	})
+
+	assert.Contains(t, output, `"request_size":24`)
+	assert.NotContains(t, output, "request_body")
+	assert.NotContains(t, output, "secret123")
+}
@@ -16,6 +16,7 @@ import (
 
 	"go-server/internal/config"
 	"go-server/internal/logger"
+	"go-server/pkg/tracecontext"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -152,6 +153,92 @@ func TestStructuredLoggingMiddlewareWithExistingCorrelationID(t *testing.T) {
 	assert.Equal(t, existingCorrID, w.Header().Get("X-Correlation-ID"))
 }
 
+func TestStructuredLoggingMiddlewareTraceContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(StructuredLoggingMiddleware(&config.Config{Mode: "development"}))
+	router.GET("/test", func(c *gin.Context) {
+		span, ok := GetSpanContextFromContext(c)
+		require.True(t, ok)
+		fromRequest, ok := tracecontext.FromContext(c.Request.Context())
+		require.True(t, ok)
+		assert.Equal(t, span, fromRequest)
+
+		// 调用下游服务时传递本次请求的 span
+		outgoing := http.Header{}
+		tracecontext.Inject(c.Request.Context(), outgoing)
+		c.JSON(http.StatusOK, gin.H{
+			"request_id":     GetRequestIDFromContext(c),
+			"correlation_id": GetCorrelationIDFromContext(c),
+			"traceparent":    outgoing.Get("traceparent"),
+			"tracestate":     outgoing.Get("tracestate"),
+		})
+	})
+
+	serve := func(headers map[string]string) (*httptest.ResponseRecorder, map[string]string) {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w, body
+	}
+
+	t.Run("延续调用方的链路", func(t *testing.T) {
+		w, body := serve(map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"tracestate":  "congo=t61rcWkgMzE",
+		})
+
+		span, err := tracecontext.Parse(w.Header().Get("traceparent"), "")
+		require.NoError(t, err)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+		assert.NotEqual(t, "00f067aa0ba902b7", span.SpanID, "本次请求是调用方的子 span")
+		assert.True(t, span.Sampled())
+		assert.Equal(t, w.Header().Get("traceparent"), body["traceparent"])
+		assert.Equal(t, "congo=t61rcWkgMzE", body["tracestate"])
+
+		// 没有关联ID时使用链路ID，请求ID每一跳不同
+		assert.Equal(t, span.TraceID, w.Header().Get("X-Correlation-ID"))
+		assert.Equal(t, span.TraceID, body["correlation_id"])
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		assert.Equal(t, w.Header().Get("X-Request-ID"), body["request_id"])
+		assert.NotEqual(t, body["correlation_id"], body["request_id"])
+	})
+
+	t.Run("请求头中的关联ID优先", func(t *testing.T) {
+		w, _ := serve(map[string]string{
+			"traceparent":      "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"X-Correlation-ID": "order-42",
+		})
+		assert.Equal(t, "order-42", w.Header().Get("X-Correlation-ID"))
+	})
+
+	t.Run("无效的 traceparent 开始新的链路", func(t *testing.T) {
+		w, body := serve(map[string]string{
+			"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"tracestate":  "congo=t61rcWkgMzE",
+		})
+		span, err := tracecontext.Parse(w.Header().Get("traceparent"), "")
+		require.NoError(t, err)
+		assert.NotEqual(t, "00000000000000000000000000000000", span.TraceID)
+		assert.Empty(t, body["tracestate"], "traceparent 无效时忽略 tracestate")
+		assert.NotEqual(t, span.TraceID, w.Header().Get("X-Correlation-ID"))
+	})
+
+	t.Run("每个请求的请求ID不同", func(t *testing.T) {
+		first, _ := serve(nil)
+		second, _ := serve(nil)
+		assert.NotEqual(t, first.Header().Get("X-Request-ID"), second.Header().Get("X-Request-ID"))
+		assert.NotEqual(t, first.Header().Get("traceparent"), second.Header().Get("traceparent"))
+	})
+}
+
 func TestStructuredLoggingMiddlewareSlowRequest(t *testing.T) {
 	// 设置Gin为测试模式
 	gin.SetMode(gin.TestMode)
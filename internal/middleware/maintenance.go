@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/pkg/errors"
+	"go-server/pkg/maintenance"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceBypassHeader 运维人员传递旁路令牌的请求头
+const MaintenanceBypassHeader = "X-Maintenance-Bypass"
+
+// maintenanceSettings 维护模式中间件支持热重载的配置
+type maintenanceSettings struct {
+	allowPaths   []string
+	bypassTokens [][]byte
+}
+
+// newMaintenanceSettings 解析维护模式配置
+func newMaintenanceSettings(cfg config.MaintenanceConfig) *maintenanceSettings {
+	settings := &maintenanceSettings{
+		allowPaths: append([]string(nil), cfg.AllowPaths...),
+	}
+	for _, token := range strings.Split(cfg.BypassTokens, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			settings.bypassTokens = append(settings.bypassTokens, []byte(token))
+		}
+	}
+	return settings
+}
+
+// allowed 判断路径是否在放行列表中
+func (s *maintenanceSettings) allowed(path string) bool {
+	for _, prefix := range s.allowPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// bypassed 判断请求头中的令牌是否为有效的旁路令牌，逐个比较时不提前返回以免泄露令牌长度和位置
+func (s *maintenanceSettings) bypassed(token string) bool {
+	if token == "" {
+		return false
+	}
+	matched := 0
+	for _, candidate := range s.bypassTokens {
+		matched |= subtle.ConstantTimeCompare([]byte(token), candidate)
+	}
+	return matched == 1
+}
+
+// Maintenance 维护模式中间件，维护期间除放行路径和携带旁路令牌的请求外返回 503 MAINTENANCE_MODE
+type Maintenance struct {
+	mode     *maintenance.Mode
+	settings atomic.Pointer[maintenanceSettings]
+}
+
+// NewMaintenance 根据维护模式配置创建中间件，配置开启的维护立即生效
+func NewMaintenance(cfg config.MaintenanceConfig, mode *maintenance.Mode) *Maintenance {
+	m := &Maintenance{mode: mode}
+	m.settings.Store(newMaintenanceSettings(cfg))
+	m.mode.SetForced(forcedMaintenance(cfg, nil, time.Now()))
+	return m
+}
+
+// forcedMaintenance 返回配置开启的维护状态，配置未开启维护时返回 nil
+// 维护已由配置开启时（previous 不为 nil）保留原来的开始时间，预计结束时间随配置的预计时长变化
+func forcedMaintenance(cfg config.MaintenanceConfig, previous *maintenance.State, now time.Time) *maintenance.State {
+	if !cfg.Active {
+		return nil
+	}
+	startedAt := now.UTC()
+	if previous != nil && previous.StartedAt != nil {
+		startedAt = *previous.StartedAt
+	}
+	state := &maintenance.State{
+		Message:   cfg.Message,
+		StartedAt: &startedAt,
+	}
+	if cfg.EstimatedDowntime > 0 {
+		endsAt := startedAt.Add(time.Duration(cfg.EstimatedDowntime) * time.Second)
+		state.EndsAt = &endsAt
+	}
+	return state
+}
+
+// Middleware 返回 gin 中间件，维护期间设置 Retry-After 头（预计结束时间已知时）并返回 503
+func (m *Maintenance) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings := m.settings.Load()
+		if settings.allowed(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		// 读取存储失败时沿用上一次读到的状态
+		state, _ := m.mode.Current(c.Request.Context())
+		if !state.Enabled || settings.bypassed(c.GetHeader(MaintenanceBypassHeader)) {
+			c.Next()
+			return
+		}
+
+		remaining := state.Remaining(time.Now())
+		if remaining > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(remaining.Seconds())+1))
+		}
+		appErr := errors.NewMaintenanceError("api", remaining)
+		if state.Message != "" {
+			appErr = appErr.WithDetail("reason", state.Message)
+		}
+		if state.EndsAt != nil {
+			appErr = appErr.WithDetail("ends_at", state.EndsAt.UTC().Format(time.RFC3339))
+		}
+		response.ErrorWithAppError(c, appErr)
+		c.Abort()
+	}
+}
+
+// Name 实现 config.Subscriber 接口
+func (m *Maintenance) Name() string {
+	return "maintenance"
+}
+
+// ValidateConfig 实现 config.Subscriber 接口，维护模式配置由配置校验器校验
+func (m *Maintenance) ValidateConfig(newConfig *config.Config) error {
+	return nil
+}
+
+// ApplyConfig 实现 config.Subscriber 接口，替换放行路径和旁路令牌，并开启或结束配置开启的维护
+func (m *Maintenance) ApplyConfig(oldConfig, newConfig *config.Config) error {
+	m.settings.Store(newMaintenanceSettings(newConfig.Maintenance))
+	m.mode.SetForced(forcedMaintenance(newConfig.Maintenance, m.mode.Forced(), time.Now()))
+	return nil
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/pkg/maintenance"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceConfig() config.MaintenanceConfig {
+	return config.MaintenanceConfig{
+		Enabled:      true,
+		BypassTokens: "ops-token-1, ops-token-2",
+		AllowPaths:   []string{"/healthz", "/api/v1/admin/maintenance"},
+	}
+}
+
+func newMaintenanceRouter(m *Maintenance) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.Any("/*path", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func serveMaintenance(router *gin.Engine, path, bypass string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if bypass != "" {
+		req.Header.Set(MaintenanceBypassHeader, bypass)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMaintenance_BlocksRequests(t *testing.T) {
+	mode := maintenance.New(maintenance.NewMemoryStore(), time.Minute)
+	router := newMaintenanceRouter(NewMaintenance(newMaintenanceConfig(), mode))
+
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/users", "").Code)
+
+	_, err := mode.Enable(context.Background(), "数据库升级", 30*time.Minute, "admin")
+	require.NoError(t, err)
+
+	w := serveMaintenance(router, "/api/v1/users", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	retryAfter := w.Header().Get("Retry-After")
+	assert.NotEmpty(t, retryAfter)
+
+	var body struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "MAINTENANCE_MODE", body.Error.Code)
+	assert.Equal(t, "数据库升级", body.Error.Details["reason"])
+	assert.EqualValues(t, 29, body.Error.Details["estimated_downtime_minutes"])
+	assert.NotEmpty(t, body.Error.Details["ends_at"])
+
+	// 放行路径和旁路令牌不受维护影响
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/healthz", "").Code)
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/admin/maintenance", "").Code)
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/users", "ops-token-2").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMaintenance(router, "/api/v1/users", "wrong").Code)
+
+	_, err = mode.Disable(context.Background(), "admin")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/users", "").Code)
+}
+
+func TestMaintenance_UnknownDowntime(t *testing.T) {
+	mode := maintenance.New(maintenance.NewMemoryStore(), time.Minute)
+	router := newMaintenanceRouter(NewMaintenance(newMaintenanceConfig(), mode))
+	_, err := mode.Enable(context.Background(), "", 0, "admin")
+	require.NoError(t, err)
+
+	w := serveMaintenance(router, "/api/v1/users", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestMaintenance_ApplyConfig(t *testing.T) {
+	mode := maintenance.New(maintenance.NewMemoryStore(), time.Minute)
+	m := NewMaintenance(newMaintenanceConfig(), mode)
+	router := newMaintenanceRouter(m)
+
+	oldConfig := &config.Config{Maintenance: newMaintenanceConfig()}
+	newConfig := &config.Config{Maintenance: newMaintenanceConfig()}
+	newConfig.Maintenance.Active = true
+	newConfig.Maintenance.Message = "迁移中"
+	newConfig.Maintenance.EstimatedDowntime = 600
+	newConfig.Maintenance.BypassTokens = "rotated"
+	require.NoError(t, m.ValidateConfig(newConfig))
+	require.NoError(t, m.ApplyConfig(oldConfig, newConfig))
+
+	state, err := mode.Current(context.Background())
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, maintenance.SourceConfig, state.Source)
+	assert.Equal(t, "迁移中", state.Message)
+	startedAt := *state.StartedAt
+	assert.Equal(t, startedAt.Add(10*time.Minute), *state.EndsAt)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveMaintenance(router, "/api/v1/users", "ops-token-1").Code)
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/users", "rotated").Code)
+
+	// 延长预计时长时保留开始时间
+	longer := &config.Config{Maintenance: newConfig.Maintenance}
+	longer.Maintenance.EstimatedDowntime = 1200
+	require.NoError(t, m.ApplyConfig(newConfig, longer))
+	state, err = mode.Current(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, startedAt, *state.StartedAt)
+	assert.Equal(t, startedAt.Add(20*time.Minute), *state.EndsAt)
+
+	require.NoError(t, m.ApplyConfig(longer, oldConfig))
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/v1/users", "").Code)
+}
@@ -2,13 +2,11 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"go-server/internal/config"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,23 +26,24 @@ func LoggerMiddleware() gin.HandlerFunc {
 	})
 }
 
-func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	config.AllowOrigins = allowedOrigins
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
-	config.MaxAge = 12 * time.Hour
-
-	return cors.New(config)
-}
-
+// RequestSizeLimitMiddleware 创建固定上限的请求体大小限制中间件，multipart 请求使用相同的上限
+// maxSize 小于等于 0 时不限制请求体大小
 func RequestSizeLimitMiddleware(maxSize int64) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Request = c.Request.WithContext(c.Request.Context())
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
-		c.Next()
+	if maxSize <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter, err := NewBodyLimiter(config.BodyLimitConfig{
+		Enabled:           true,
+		MaxBytes:          maxSize,
+		MultipartMaxBytes: maxSize,
+	})
+	if err != nil {
+		panic(err)
 	}
+	return limiter.Middleware()
 }
 
 // SecurityHeadersMiddleware 创建增强的安全头中间件
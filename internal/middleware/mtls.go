@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"go-server/pkg/errors"
+	"go-server/pkg/mtls"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccountContextKey gin 上下文中保存当前服务账户的键
+const ServiceAccountContextKey = "service_account"
+
+// ServiceAuthMiddleware 把 mTLS 客户端证书身份映射到服务账户，写入 gin 上下文
+// 请求不是经过校验的 mTLS 连接时返回 401，证书身份没有映射到服务账户时返回 403
+func ServiceAuthMiddleware(authorizer *mtls.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := mtls.IdentityFromContext(c.Request.Context())
+		if !ok {
+			response.UnauthorizedError(c, localize(c, "mtls.certificate_required"))
+			c.Abort()
+			return
+		}
+
+		account, ok := authorizer.Authorize(identity)
+		if !ok {
+			response.ErrorWithAppError(c, errors.NewForbiddenError(localize(c, "mtls.unmapped_certificate")).
+				WithDetail("identities", identity.Names()))
+			c.Abort()
+			return
+		}
+
+		c.Set(ServiceAccountContextKey, account)
+		c.Next()
+	}
+}
+
+// CurrentServiceAccount 返回当前请求的服务账户
+func CurrentServiceAccount(c *gin.Context) (*mtls.ServiceAccount, bool) {
+	value, exists := c.Get(ServiceAccountContextKey)
+	if !exists {
+		return nil, false
+	}
+	account, ok := value.(*mtls.ServiceAccount)
+	return account, ok
+}
+
+// RequireServicePermission 要求当前服务账户拥有权限，否则返回 403，需要安装在 ServiceAuthMiddleware 之后
+func RequireServicePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account, ok := CurrentServiceAccount(c)
+		if !ok {
+			response.UnauthorizedError(c, localize(c, "mtls.unauthenticated"))
+			c.Abort()
+			return
+		}
+		if !account.Can(permission) {
+			response.ErrorWithAppError(c, errors.NewForbiddenError(localize(c, "mtls.permission_denied")).
+				WithDetail("service_account", account.Name).
+				WithDetail("permission", permission))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
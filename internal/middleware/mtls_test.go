@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/pkg/mtls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServiceAuthRouter 创建挂载服务账户认证的路由，/users 需要 users:read 权限
+func newServiceAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	authorizer, err := mtls.NewAuthorizer([]mtls.ServiceAccount{
+		{Name: "billing", Identities: []string{"spiffe://cluster.local/ns/billing/sa/worker"}, Permissions: []string{"users:read"}},
+		{Name: "reporting", Identities: []string{"reporting.internal"}, Permissions: []string{"metrics:read"}},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	group := router.Group("/internal", ServiceAuthMiddleware(authorizer))
+	group.GET("/users", RequireServicePermission("users:read"), func(c *gin.Context) {
+		account, _ := CurrentServiceAccount(c)
+		c.String(http.StatusOK, account.Name)
+	})
+	return router
+}
+
+// serviceRequest 创建带有客户端证书身份的请求，identity 为 nil 时模拟非 mTLS 端口的请求
+func serviceRequest(identity *mtls.Identity) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/internal/users", nil)
+	if identity != nil {
+		req = req.WithContext(mtls.ContextWithIdentity(req.Context(), *identity))
+	}
+	return req
+}
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	router := newServiceAuthRouter(t)
+
+	tests := []struct {
+		name     string
+		identity *mtls.Identity
+		status   int
+		body     string
+	}{
+		{"no client certificate", nil, http.StatusUnauthorized, ""},
+		{"unmapped identity", &mtls.Identity{DNSNames: []string{"unknown.internal"}}, http.StatusForbidden, ""},
+		{"missing permission", &mtls.Identity{DNSNames: []string{"reporting.internal"}}, http.StatusForbidden, ""},
+		{"authorized", &mtls.Identity{URIs: []string{"spiffe://cluster.local/ns/billing/sa/worker"}}, http.StatusOK, "billing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, serviceRequest(tt.identity))
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireServicePermission_ErrorDetails(t *testing.T) {
+	router := newServiceAuthRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, serviceRequest(&mtls.Identity{DNSNames: []string{"reporting.internal"}}))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	var body struct {
+		Error struct {
+			Code    string                 `json:"code"`
+			Details map[string]interface{} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "FORBIDDEN", body.Error.Code)
+	assert.Equal(t, "reporting", body.Error.Details["service_account"])
+	assert.Equal(t, "users:read", body.Error.Details["permission"])
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"strings"
+
+	"go-server/internal/config"
+	"go-server/internal/openapi"
+	"go-server/internal/validation"
+	"go-server/pkg/errors"
+	"go-server/pkg/i18n"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPIValidationMessages 请求与接口文档不符时的整体错误消息
+var openAPIValidationMessages = i18n.Default().Messages("openapi.request_mismatch")
+
+// OpenAPIValidator OpenAPI 请求校验中间件
+// 按 /openapi.json 提供的文档校验路由参数、查询参数、请求头和请求体，不符合时返回 400 和字段级错误；
+// 严格模式下文档未声明的字段、查询参数和请求体也会被拒绝，适合在预发布环境中发现文档未覆盖的行为。
+// 文档中没有的路由不校验
+type OpenAPIValidator struct {
+	validator    *openapi.RequestValidator
+	strict       bool
+	excludePaths []string
+}
+
+// NewOpenAPIValidator 使用 JSON 格式的 OpenAPI 文档创建请求校验中间件
+func NewOpenAPIValidator(cfg config.OpenAPIConfig, spec []byte) (*OpenAPIValidator, error) {
+	validator, err := openapi.NewRequestValidator(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIValidator{
+		validator:    validator,
+		strict:       cfg.Strict,
+		excludePaths: cfg.ExcludePaths,
+	}, nil
+}
+
+// Middleware 返回 gin 中间件
+func (v *OpenAPIValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" || v.isExcluded(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		op, ok := v.validator.Operation(c.Request.Method, route)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		lang := validation.Language(c)
+		fieldErrors, err := v.validator.ValidateRequest(op, c.Request, pathParams, v.strict)
+		if err != nil {
+			response.ErrorWithAppError(c, validation.TranslateError(err, lang))
+			c.Abort()
+			return
+		}
+		if len(fieldErrors) == 0 {
+			c.Next()
+			return
+		}
+
+		details := make([]errors.ErrorDetails, 0, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			details = append(details, openAPIErrorDetails(fieldError))
+		}
+		appErr := errors.NewValidationError(openAPIValidationMessages[validation.LanguageEnglish], details...).
+			AddInternationalizedMessages(openAPIValidationMessages)
+		response.ErrorWithAppError(c, appErr.WithUserMessage(appErr.GetLocalizedMessage(lang)))
+		c.Abort()
+	}
+}
+
+// isExcluded 判断路径是否不需要校验
+func (v *OpenAPIValidator) isExcluded(path string) bool {
+	for _, prefix := range v.excludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIErrorDetails 将文档校验错误转换为 ErrorDetails，错误代码为违反的关键字，如 MIN_LENGTH
+func openAPIErrorDetails(fieldError openapi.FieldError) errors.ErrorDetails {
+	detail := errors.ErrorDetails{
+		Field:      fieldError.Field,
+		Message:    fieldError.Message,
+		Constraint: fieldError.Constraint,
+		ErrorCode:  keywordErrorCode(fieldError.Keyword),
+	}
+
+	// 不回显密码等敏感字段的值
+	if !strings.Contains(strings.ToLower(fieldError.Field), "password") {
+		detail.Value = fieldError.Value
+	}
+	return detail
+}
+
+// keywordErrorCode 将驼峰形式的关键字转换为大写下划线形式
+func keywordErrorCode(keyword string) string {
+	var code strings.Builder
+	for i, r := range keyword {
+		if r >= 'A' && r <= 'Z' && i > 0 {
+			code.WriteByte('_')
+		}
+		code.WriteRune(r)
+	}
+	return strings.ToUpper(code.String())
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/docs"
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPISpec = `{
+  "openapi": "3.1.0",
+  "info": {"title": "test", "version": "1"},
+  "paths": {
+    "/items/{id}": {
+      "put": {
+        "operationId": "updateItem",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}},
+          {"name": "mode", "in": "query", "schema": {"type": "string", "enum": ["fast", "safe"]}}
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"allOf": [
+            {"$ref": "#/components/schemas/Base"},
+            {"type": "object", "properties": {"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}}}
+          ]}}}
+        },
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Base": {
+        "type": "object",
+        "required": ["name", "email"],
+        "properties": {
+          "name": {"type": "string", "minLength": 3},
+          "email": {"type": "string", "format": "email"},
+          "note": {"type": ["string", "null"]}
+        }
+      }
+    }
+  }
+}`
+
+func newOpenAPIValidationRouter(t *testing.T, spec string, strict bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	validator, err := NewOpenAPIValidator(config.OpenAPIConfig{
+		ValidateRequests: true,
+		Strict:           strict,
+		ExcludePaths:     []string{"/swagger/"},
+	}, []byte(spec))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.PUT("/items/:id", func(c *gin.Context) {
+		var body map[string]interface{}
+		require.NoError(t, c.ShouldBindJSON(&body), "校验后请求体应仍可读取")
+		c.JSON(http.StatusOK, body)
+	})
+	router.POST("/undocumented", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// validationErrors 返回响应中的字段错误
+func validationErrors(t *testing.T, w *httptest.ResponseRecorder) []map[string]interface{} {
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				ValidationErrors []map[string]interface{} `json:"validation_errors"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "VALIDATION_ERROR", body.Error.Code)
+	return body.Error.Details.ValidationErrors
+}
+
+func sendJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestOpenAPIValidator_ValidRequest 测试符合文档的请求正常到达处理器
+func TestOpenAPIValidator_ValidRequest(t *testing.T) {
+	router := newOpenAPIValidationRouter(t, testOpenAPISpec, true)
+
+	w := sendJSON(router, http.MethodPut, "/items/1?mode=fast", `{"name":"alice","email":"alice@example.com","note":null,"tags":["a"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"alice"`)
+
+	w = sendJSON(router, http.MethodPost, "/undocumented", `{"anything":true}`)
+	assert.Equal(t, http.StatusNoContent, w.Code, "文档中没有的路由不校验")
+}
+
+// TestOpenAPIValidator_FieldErrors 测试参数和请求体不符合文档时返回字段级错误
+func TestOpenAPIValidator_FieldErrors(t *testing.T) {
+	router := newOpenAPIValidationRouter(t, testOpenAPISpec, false)
+
+	w := sendJSON(router, http.MethodPut, "/items/0?mode=slow&extra=1", `{"name":"al","email":"not-an-email","tags":["a","b","c"],"unknown":1}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	codes := make(map[string]string)
+	for _, detail := range validationErrors(t, w) {
+		codes[detail["field"].(string)] = detail["error_code"].(string)
+	}
+	assert.Equal(t, map[string]string{
+		"path.id":    "MINIMUM",
+		"query.mode": "ENUM",
+		"name":       "MIN_LENGTH",
+		"email":      "FORMAT",
+		"tags":       "MAX_ITEMS",
+	}, codes, "非严格模式下不报告未声明的字段和查询参数")
+
+	w = sendJSON(router, http.MethodPut, "/items/abc", `{"name":"alice"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	details := validationErrors(t, w)
+	require.Len(t, details, 2)
+	assert.Equal(t, "path.id", details[0]["field"])
+	assert.Equal(t, "TYPE", details[0]["error_code"])
+	assert.Equal(t, "email", details[1]["field"])
+	assert.Equal(t, "REQUIRED", details[1]["error_code"])
+
+	w = sendJSON(router, http.MethodPut, "/items/1", "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "body", validationErrors(t, w)[0]["field"])
+}
+
+// TestOpenAPIValidator_Strict 测试严格模式拒绝文档未声明的字段、查询参数和请求体
+func TestOpenAPIValidator_Strict(t *testing.T) {
+	router := newOpenAPIValidationRouter(t, testOpenAPISpec, true)
+
+	w := sendJSON(router, http.MethodPut, "/items/1?extra=1", `{"name":"alice","email":"alice@example.com","unknown":1}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	details := validationErrors(t, w)
+	require.Len(t, details, 2)
+	assert.Equal(t, "query.extra", details[0]["field"])
+	assert.Equal(t, "UNDOCUMENTED", details[0]["error_code"])
+	assert.Equal(t, "unknown", details[1]["field"])
+	assert.Equal(t, "ADDITIONAL_PROPERTIES", details[1]["error_code"])
+}
+
+// TestOpenAPIValidator_GeneratedSpec 测试使用生成的文档校验注册请求
+func TestOpenAPIValidator_GeneratedSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator, err := NewOpenAPIValidator(config.OpenAPIConfig{ValidateRequests: true}, docs.OpenAPI)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.POST("/api/v1/auth/register", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w := sendJSON(router, http.MethodPost, "/api/v1/auth/register", `{"username":"jo","email":"jo@example.com","password":"secret1"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	details := validationErrors(t, w)
+	require.Len(t, details, 1)
+	assert.Equal(t, "username", details[0]["field"])
+	assert.Equal(t, "MIN_LENGTH", details[0]["error_code"])
+
+	w = sendJSON(router, http.MethodPost, "/api/v1/auth/register", `{"username":"john","email":"john@example.com","password":"secret1"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
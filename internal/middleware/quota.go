@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"strconv"
+	"time"
+
+	"go-server/pkg/errors"
+	"go-server/pkg/quota"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaSubject 返回用户的配额主体，配额中间件和用量接口使用同一个主体
+func QuotaSubject(userID string) string {
+	return "user:" + userID
+}
+
+// QuotaMiddleware 每个认证请求消耗一次 api_calls 配额，需要安装在认证中间件之后
+// 响应带有 X-Quota-Limit、X-Quota-Remaining 和 X-Quota-Reset 头；达到软限制时加上 X-Quota-Warning 头，
+// 超过硬限制时返回 429 和 Retry-After 头。manager 为 nil 或用户没有 api_calls 配额时直接放行，
+// 读取计数失败时放行请求，与速率限制的 fail-open 策略一致
+func QuotaMiddleware(manager *quota.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if manager == nil || userID == "" {
+			c.Next()
+			return
+		}
+
+		usage, err := manager.Consume(c.Request.Context(), quota.APICalls, QuotaSubject(userID), 1)
+		var exceeded *quota.ExceededError
+		switch {
+		case stderrors.As(err, &exceeded):
+			setQuotaHeaders(c, usage)
+			if retryAfter := time.Until(usage.ResetAt); retryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			}
+			response.ErrorWithAppError(c, errors.NewQuotaExceededError(quota.APICalls, int(usage.Hard), usage.ResetAt))
+			c.Abort()
+			return
+		case err != nil:
+			c.Next()
+			return
+		}
+
+		setQuotaHeaders(c, usage)
+		if usage.SoftReached {
+			c.Header("X-Quota-Warning", "soft limit reached")
+		}
+		c.Next()
+	}
+}
+
+// setQuotaHeaders 设置配额相关的响应头，没有硬限制时只设置重置时间
+func setQuotaHeaders(c *gin.Context, usage quota.Usage) {
+	if usage.Hard > 0 {
+		c.Header("X-Quota-Limit", strconv.FormatInt(usage.Hard, 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(usage.Remaining, 10))
+	}
+	c.Header("X-Quota-Reset", strconv.FormatInt(usage.ResetAt.Unix(), 10))
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/pkg/quota"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newQuotaRouter /me 模拟认证后消耗配额
+func newQuotaRouter(manager *quota.Manager) *gin.Engine {
+	router := gin.New()
+	authenticated := func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("user_id", user)
+		}
+		c.Next()
+	}
+	router.GET("/me", authenticated, QuotaMiddleware(manager), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := quota.New(quota.NewMemoryStore(), []quota.Limit{{Name: quota.APICalls, Soft: 2, Hard: 3}}, quota.Config{})
+	router := newQuotaRouter(manager)
+
+	serve := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("未达到软限制", func(t *testing.T) {
+		w := serve("u1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-Quota-Limit"))
+		assert.Equal(t, "2", w.Header().Get("X-Quota-Remaining"))
+		assert.NotEmpty(t, w.Header().Get("X-Quota-Reset"))
+		assert.Empty(t, w.Header().Get("X-Quota-Warning"))
+	})
+
+	t.Run("达到软限制时提醒", func(t *testing.T) {
+		w := serve("u1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("X-Quota-Warning"))
+		assert.Equal(t, http.StatusOK, serve("u1").Code)
+	})
+
+	t.Run("超过硬限制返回 429", func(t *testing.T) {
+		w := serve("u1")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "QUOTA_EXCEEDED")
+	})
+
+	t.Run("其他用户和匿名请求不受影响", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("u2").Code)
+		w := serve("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-Quota-Limit"))
+	})
+
+	t.Run("未启用配额时放行", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("X-Test-User", "u1")
+		newQuotaRouter(nil).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package middleware
+
+// raceEnabled 竞态检测下 sync.Pool 会随机丢弃对象，分配预算测试跳过
+const raceEnabled = true
@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidRateLimitOverrides 运行时限制覆盖不合法
+var ErrInvalidRateLimitOverrides = errors.New("invalid rate limit overrides")
+
+// RateLimitTier 匿名和认证用户的请求限制，0 表示沿用上一级的限制
+type RateLimitTier struct {
+	Anonymous     int `json:"anonymous" example:"100"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int `json:"authenticated" example:"200"` // 认证用户每个时间窗口的请求数
+}
+
+// RateLimitOverrides 管理员在运行时设置的限制，优先于配置文件；零值字段沿用配置文件中的值
+type RateLimitOverrides struct {
+	RateLimitTier
+	Window    string                   `json:"window,omitempty" example:"1m"`           // 时间窗口，如 30s、1m
+	Mode      string                   `json:"mode,omitempty" example:"enforce"`        // enforce、warn 或 shadow
+	Routes    map[string]RateLimitTier `json:"routes,omitempty"`                        // 按路由模板（如 /api/v1/users/:id）单独计数的限制
+	UpdatedBy string                   `json:"updated_by,omitempty"`                    // 最后修改的管理员ID
+	UpdatedAt time.Time                `json:"updated_at,omitempty" format:"date-time"` // 最后修改时间
+}
+
+// Validate 校验覆盖的取值
+func (o *RateLimitOverrides) Validate() error {
+	if o.Anonymous < 0 || o.Authenticated < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidRateLimitOverrides)
+	}
+	if o.Window != "" {
+		window, err := time.ParseDuration(o.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("%w: invalid window %q", ErrInvalidRateLimitOverrides, o.Window)
+		}
+	}
+	switch o.Mode {
+	case "", RateLimitModeEnforce, RateLimitModeWarn, RateLimitModeShadow:
+	default:
+		return fmt.Errorf("%w: invalid mode %q", ErrInvalidRateLimitOverrides, o.Mode)
+	}
+	for route, tier := range o.Routes {
+		if !strings.HasPrefix(route, "/") {
+			return fmt.Errorf("%w: route %q must be a path template starting with /", ErrInvalidRateLimitOverrides, route)
+		}
+		if tier.Anonymous < 0 || tier.Authenticated < 0 {
+			return fmt.Errorf("%w: limits of route %s must not be negative", ErrInvalidRateLimitOverrides, route)
+		}
+	}
+	return nil
+}
+
+// RateLimitOverrideStore 保存运行时限制覆盖，并把修改通知到所有实例
+type RateLimitOverrideStore interface {
+	// Load 读取保存的覆盖，没有保存过时返回 nil
+	Load(ctx context.Context) (*RateLimitOverrides, error)
+	// Save 保存覆盖并通知其他实例
+	Save(ctx context.Context, overrides *RateLimitOverrides) error
+	// Watch 订阅其他实例的修改，阻塞到 ctx 结束
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// RedisRateLimitOverrideStore 将运行时限制覆盖保存在 Redis 中，修改后通过发布订阅通知所有实例
+//
+// 键布局：
+//
+//	<prefix>:overrides          JSON 格式的覆盖
+//	<prefix>:overrides:changes  发布订阅频道，消息内容无意义
+type RedisRateLimitOverrideStore struct {
+	client  *redis.Client
+	key     string
+	channel string
+}
+
+// NewRedisRateLimitOverrideStore 创建 Redis 覆盖存储，prefix 为速率限制的键前缀（rate_limit.redis_key）
+func NewRedisRateLimitOverrideStore(client *redis.Client, prefix string) *RedisRateLimitOverrideStore {
+	return &RedisRateLimitOverrideStore{
+		client:  client,
+		key:     prefix + ":overrides",
+		channel: prefix + ":overrides:changes",
+	}
+}
+
+// Load 读取保存的覆盖
+func (s *RedisRateLimitOverrideStore) Load(ctx context.Context) (*RateLimitOverrides, error) {
+	data, err := s.client.Get(ctx, s.key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit overrides: %w", err)
+	}
+
+	var overrides RateLimitOverrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode rate limit overrides: %w", err)
+	}
+	return &overrides, nil
+}
+
+// Save 保存覆盖并发布变更通知，发布失败时其他实例在重启或下次修改后生效
+func (s *RedisRateLimitOverrideStore) Save(ctx context.Context, overrides *RateLimitOverrides) error {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save rate limit overrides: %w", err)
+	}
+	_ = s.client.Publish(ctx, s.channel, "updated").Err()
+	return nil
+}
+
+// Watch 订阅变更频道，收到消息时调用 onChange
+func (s *RedisRateLimitOverrideStore) Watch(ctx context.Context, onChange func()) error {
+	pubsub := s.client.Subscribe(ctx, s.channel)
+	defer pubsub.Close()
+
+	// 等待订阅确认，确保返回前已开始接收消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", s.channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			onChange()
+		}
+	}
+}
+
+// RateLimitState 速率限制当前生效的参数和管理员设置的覆盖
+type RateLimitState struct {
+	Enabled   bool                     `json:"enabled" example:"true"`   // 是否启用
+	Mode      string                   `json:"mode" example:"enforce"`   // 生效的模式
+	Window    string                   `json:"window" example:"1m0s"`    // 生效的时间窗口
+	Limits    RateLimitTier            `json:"limits"`                   // 生效的全局限制
+	Routes    map[string]RateLimitTier `json:"routes,omitempty"`         // 生效的按路由限制
+	Overrides RateLimitOverrides       `json:"overrides"`                // 管理员设置的覆盖，为空表示完全使用配置文件
+	Persisted bool                     `json:"persisted" example:"true"` // 覆盖是否保存在 Redis 中并同步到所有实例
+}
+
+// SetOverrideStore 设置运行时限制覆盖的存储，未设置时覆盖只对当前实例有效且重启后丢失
+// 需要在开始处理请求前调用
+func (r *DistributedRateLimiter) SetOverrideStore(store RateLimitOverrideStore) {
+	r.overrideStore = store
+}
+
+// OverrideStore 返回运行时限制覆盖的存储，未设置时返回 nil
+func (r *DistributedRateLimiter) OverrideStore() RateLimitOverrideStore {
+	return r.overrideStore
+}
+
+// State 返回当前生效的限制参数和覆盖
+func (r *DistributedRateLimiter) State() RateLimitState {
+	settings := r.settings.Load()
+	state := RateLimitState{
+		Enabled: settings.enabled,
+		Mode:    settings.mode,
+		Window:  settings.window.String(),
+		Limits: RateLimitTier{
+			Anonymous:     settings.anonymousRequests,
+			Authenticated: settings.authenticatedRequests,
+		},
+		Routes:    settings.routes,
+		Persisted: r.overrideStore != nil,
+	}
+	if overrides := r.overrides.Load(); overrides != nil {
+		state.Overrides = *overrides
+	}
+	return state
+}
+
+// UpdateOverrides 校验并保存运行时限制覆盖，保存成功后在当前实例生效，其他实例收到变更通知后生效
+// 传入零值覆盖表示恢复配置文件中的限制
+func (r *DistributedRateLimiter) UpdateOverrides(ctx context.Context, overrides RateLimitOverrides) error {
+	if err := overrides.Validate(); err != nil {
+		return err
+	}
+	if r.overrideStore != nil {
+		if err := r.overrideStore.Save(ctx, &overrides); err != nil {
+			return err
+		}
+	}
+	r.applyOverrides(&overrides)
+	return nil
+}
+
+// ReloadOverrides 从存储读取覆盖并生效，用于启动时和收到其他实例的变更通知时
+func (r *DistributedRateLimiter) ReloadOverrides(ctx context.Context) error {
+	if r.overrideStore == nil {
+		return nil
+	}
+	overrides, err := r.overrideStore.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if overrides != nil {
+		if err := overrides.Validate(); err != nil {
+			return err
+		}
+	}
+	r.applyOverrides(overrides)
+	return nil
+}
+
+// applyOverrides 在配置文件的限制上应用覆盖
+func (r *DistributedRateLimiter) applyOverrides(overrides *RateLimitOverrides) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides.Store(overrides)
+	r.applySettings(r.base.Load().withOverrides(overrides))
+}
+
+// withOverrides 返回应用覆盖后的限制参数，不修改 s
+func (s *rateLimitSettings) withOverrides(overrides *RateLimitOverrides) *rateLimitSettings {
+	merged := *s
+	if overrides == nil {
+		return &merged
+	}
+	if overrides.Anonymous > 0 {
+		merged.anonymousRequests = overrides.Anonymous
+	}
+	if overrides.Authenticated > 0 {
+		merged.authenticatedRequests = overrides.Authenticated
+	}
+	if window, err := time.ParseDuration(overrides.Window); err == nil && window > 0 {
+		merged.window = window
+	}
+	if overrides.Mode != "" {
+		merged.mode = overrides.Mode
+	}
+	if len(overrides.Routes) > 0 {
+		merged.routes = overrides.Routes
+	}
+	return &merged
+}
+
+// WatchOverrides 订阅其他实例的覆盖修改并重新加载，订阅断开后每秒重试，阻塞到 ctx 结束
+// onError 接收订阅和加载的错误，可以为 nil
+func (r *DistributedRateLimiter) WatchOverrides(ctx context.Context, onError func(error)) {
+	if r.overrideStore == nil {
+		return
+	}
+	report := func(err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	for {
+		err := r.overrideStore.Watch(ctx, func() {
+			report(r.ReloadOverrides(ctx))
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		report(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+		// 断线期间的修改可能丢失，重新订阅前重新加载
+		report(r.ReloadOverrides(ctx))
+	}
+}
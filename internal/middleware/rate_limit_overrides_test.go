@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedOverrideStore 模拟多个实例共用的 Redis 覆盖存储
+type sharedOverrideStore struct {
+	mu        sync.Mutex
+	data      []byte
+	listeners []func()
+}
+
+func (s *sharedOverrideStore) Load(ctx context.Context) (*RateLimitOverrides, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, nil
+	}
+	var overrides RateLimitOverrides
+	err := json.Unmarshal(s.data, &overrides)
+	return &overrides, err
+}
+
+func (s *sharedOverrideStore) Save(ctx context.Context, overrides *RateLimitOverrides) error {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.data = data
+	listeners := append([]func(){}, s.listeners...)
+	s.mu.Unlock()
+	for _, notify := range listeners {
+		notify()
+	}
+	return nil
+}
+
+func (s *sharedOverrideStore) Watch(ctx context.Context, onChange func()) error {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, onChange)
+	s.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func TestRateLimitOverrides_Validate(t *testing.T) {
+	valid := RateLimitOverrides{
+		RateLimitTier: RateLimitTier{Anonymous: 10},
+		Window:        "30s",
+		Mode:          RateLimitModeWarn,
+		Routes:        map[string]RateLimitTier{"/api/v1/users/search": {Anonymous: 5}},
+	}
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&RateLimitOverrides{}).Validate())
+
+	invalid := []RateLimitOverrides{
+		{RateLimitTier: RateLimitTier{Anonymous: -1}},
+		{Window: "soon"},
+		{Window: "-1m"},
+		{Mode: "block"},
+		{Routes: map[string]RateLimitTier{"api/v1/users": {Anonymous: 1}}},
+		{Routes: map[string]RateLimitTier{"/api/v1/users": {Authenticated: -1}}},
+	}
+	for _, overrides := range invalid {
+		assert.ErrorIs(t, overrides.Validate(), ErrInvalidRateLimitOverrides, "%+v", overrides)
+	}
+}
+
+func TestDistributedRateLimiter_UpdateOverrides(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer limiter.Close()
+
+	err := limiter.UpdateOverrides(context.Background(), RateLimitOverrides{
+		RateLimitTier: RateLimitTier{Anonymous: 30},
+		Window:        "30s",
+		Mode:          RateLimitModeShadow,
+	})
+	require.NoError(t, err)
+
+	state := limiter.State()
+	assert.Equal(t, RateLimitTier{Anonymous: 30, Authenticated: 20}, state.Limits, "authenticated limit falls back to the config")
+	assert.Equal(t, "30s", state.Window)
+	assert.Equal(t, RateLimitModeShadow, state.Mode)
+	assert.False(t, state.Persisted)
+	assert.False(t, limiter.Enforcing())
+
+	configuration := limiter.Metrics().GetStats().Configuration
+	assert.Equal(t, 60, configuration.RequestsPerMinute)
+	assert.Equal(t, 30*time.Second, configuration.WindowSize)
+
+	// 配置热重载替换配置文件中的限制，覆盖继续优先
+	newConfig := &config.Config{RateLimit: config.RateLimitConfig{Enabled: true, Requests: 50, Window: "1m"}}
+	require.NoError(t, limiter.ApplyConfig(nil, newConfig))
+	state = limiter.State()
+	assert.Equal(t, RateLimitTier{Anonymous: 30, Authenticated: 100}, state.Limits)
+	assert.Equal(t, RateLimitModeShadow, state.Mode)
+
+	// 空覆盖恢复配置文件中的限制
+	require.NoError(t, limiter.UpdateOverrides(context.Background(), RateLimitOverrides{}))
+	state = limiter.State()
+	assert.Equal(t, RateLimitTier{Anonymous: 50, Authenticated: 100}, state.Limits)
+	assert.Equal(t, "1m0s", state.Window)
+	assert.True(t, limiter.Enforcing())
+
+	assert.ErrorIs(t, limiter.UpdateOverrides(context.Background(), RateLimitOverrides{Mode: "block"}), ErrInvalidRateLimitOverrides)
+	assert.True(t, limiter.Enforcing(), "invalid overrides are not applied")
+}
+
+func TestDistributedRateLimiter_OverridesBroadcast(t *testing.T) {
+	store := &sharedOverrideStore{}
+	first := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer first.Close()
+	second := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer second.Close()
+	first.SetOverrideStore(store)
+	second.SetOverrideStore(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.WatchOverrides(ctx, func(err error) { t.Error(err) })
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.listeners) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, first.UpdateOverrides(context.Background(), RateLimitOverrides{
+		RateLimitTier: RateLimitTier{Authenticated: 500},
+		Routes:        map[string]RateLimitTier{"/api/v1/users/search": {Anonymous: 3}},
+	}))
+	state := second.State()
+	assert.True(t, state.Persisted)
+	assert.Equal(t, 500, state.Limits.Authenticated)
+	assert.Equal(t, RateLimitTier{Anonymous: 3}, state.Routes["/api/v1/users/search"])
+
+	// 新实例启动时加载保存的覆盖
+	third := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer third.Close()
+	third.SetOverrideStore(store)
+	require.NoError(t, third.ReloadOverrides(context.Background()))
+	assert.Equal(t, 500, third.State().Limits.Authenticated)
+}
+
+func TestDistributedRateLimiter_RouteLimit(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer limiter.Close()
+	require.NoError(t, limiter.UpdateOverrides(context.Background(), RateLimitOverrides{
+		Routes: map[string]RateLimitTier{"/api/v1/users/:id": {Anonymous: 3}},
+	}))
+
+	type result struct {
+		limit int
+		ok    bool
+	}
+	var got result
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		got.limit, got.ok = limiter.routeLimitFor(c, limiter.settings.Load(), c.Query("auth") != "")
+		c.Status(http.StatusNoContent)
+	}
+	router.GET("/api/v1/users/:id", handler)
+	router.GET("/api/v1/users", handler)
+
+	serve := func(path string) result {
+		got = result{}
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		return got
+	}
+	assert.Equal(t, result{limit: 3, ok: true}, serve("/api/v1/users/42"))
+	assert.Equal(t, result{}, serve("/api/v1/users/42?auth=1"), "a zero tier keeps the global limit")
+	assert.Equal(t, result{}, serve("/api/v1/users"))
+}
@@ -3,12 +3,15 @@ package middleware
 import (
     "context"
     "fmt"
+    "math"
     "net/http"
     "strconv"
     "sync"
+    "sync/atomic"
     "time"
 
     "go-server/internal/config"
+    "go-server/internal/metrics"
     "go-server/pkg/response"
 
     "github.com/gin-gonic/gin"
@@ -44,12 +47,45 @@ type MemoryRateLimiter struct {
 	maxReqs int                    // 最大请求数
 }
 
+// 速率限制模式
+const (
+	RateLimitModeEnforce = "enforce" // 超过限制时返回 429
+	RateLimitModeWarn    = "warn"    // 不拦截，接近或超过限制时添加 X-RateLimit-Warning 头
+	RateLimitModeShadow  = "shadow"  // 不拦截也不添加响应头，只记录统计，用于上线前评估限制
+)
+
+// rateLimitSettings 可热重载的速率限制参数，整体原子替换
+type rateLimitSettings struct {
+	enabled               bool
+	mode                  string // enforce、warn 或 shadow
+	warnThreshold         int    // warn 模式下用量达到限制的该百分比时添加警告头
+	anonymousRequests     int
+	authenticatedRequests int
+	window                time.Duration
+	routes                map[string]RateLimitTier // 按路由模板单独计数的限制，来自运行时覆盖
+	algorithm             string                   // sliding_window 或 token_bucket
+	burst                 int                      // 令牌桶容量超出限制的令牌数
+	costs                 map[string]int           // 令牌桶算法下按路由模板的请求消耗
+}
+
+// enforcing 是否拦截超过限制的请求
+func (s *rateLimitSettings) enforcing() bool {
+	return s.mode != RateLimitModeWarn && s.mode != RateLimitModeShadow
+}
+
 // DistributedRateLimiter 分布式速率限制器
 type DistributedRateLimiter struct {
-	config    RateLimiterConfig
-	redis     *redis.Client
-	fallback  *MemoryRateLimiter
-	anonymous *MemoryRateLimiter // 匿名用户内存限制器
+	config        RateLimiterConfig
+	settings      atomic.Pointer[rateLimitSettings]  // 当前生效的限制参数
+	base          atomic.Pointer[rateLimitSettings]  // 配置文件中的限制参数
+	overrides     atomic.Pointer[RateLimitOverrides] // 管理员设置的运行时覆盖
+	overrideStore RateLimitOverrideStore             // 覆盖的存储，为 nil 时只在当前实例生效
+	mu            sync.Mutex                         // 串行化配置热重载和覆盖的修改
+	redis         *redis.Client
+	fallback      *MemoryRateLimiter
+	anonymous     *MemoryRateLimiter        // 匿名用户内存限制器
+	buckets       *MemoryTokenBucket        // 令牌桶算法的内存降级限制器
+	metrics       *metrics.RateLimitMetrics // 放行和限流统计
 }
 
 // NewMemoryRateLimiter 创建内存速率限制器
@@ -61,8 +97,22 @@ func NewMemoryRateLimiter(maxReqs int, window time.Duration) *MemoryRateLimiter
 	}
 }
 
+// update 更新内存限制器的限制参数，已有的请求记录保留
+func (m *MemoryRateLimiter) update(maxReqs int, window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxReqs = maxReqs
+	m.window = window
+}
+
 // isAllowed 检查内存限制是否允许请求
 func (m *MemoryRateLimiter) isAllowed(clientID string) (bool, time.Duration) {
+	allowed, retryAfter, _ := m.check(clientID)
+	return allowed, retryAfter
+}
+
+// check 检查内存限制是否允许请求，同时返回窗口内已计入的请求数（放行时包含本次请求）
+func (m *MemoryRateLimiter) check(clientID string) (bool, time.Duration, int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -92,21 +142,21 @@ func (m *MemoryRateLimiter) isAllowed(clientID string) (bool, time.Duration) {
 			if retryAfter < 0 {
 				retryAfter = 0
 			}
-			return false, retryAfter
+			return false, retryAfter, len(validRequests)
 		}
-		return false, m.window
+		return false, m.window, len(validRequests)
 	}
 
 	// 如果maxReqs为0，直接拒绝所有请求
 	if m.maxReqs == 0 {
-		return false, m.window
+		return false, m.window, len(validRequests)
 	}
 
 	// 添加当前请求
 	validRequests = append(validRequests, now)
 	m.clients[clientID] = validRequests
 
-	return true, 0
+	return true, 0, len(validRequests)
 }
 
 // NewDistributedRateLimiter 创建分布式速率限制器
@@ -126,16 +176,166 @@ func NewDistributedRateLimiter(cfg RateLimiterConfig) *DistributedRateLimiter {
 	fallback := NewMemoryRateLimiter(cfg.AuthenticatedRequests, cfg.WindowDuration)
 	anonymous := NewMemoryRateLimiter(cfg.AnonymousRequests, cfg.WindowDuration)
 
-	return &DistributedRateLimiter{
+	limiter := &DistributedRateLimiter{
 		config:    cfg,
 		redis:     rdb,
 		fallback:  fallback,
 		anonymous: anonymous,
+		buckets:   NewMemoryTokenBucket(),
+		metrics:   metrics.NewRateLimitMetrics(),
+	}
+	settings := &rateLimitSettings{
+		enabled:               true,
+		mode:                  RateLimitModeEnforce,
+		algorithm:             RateLimitAlgorithmSlidingWindow,
+		anonymousRequests:     cfg.AnonymousRequests,
+		authenticatedRequests: cfg.AuthenticatedRequests,
+		window:                cfg.WindowDuration,
+	}
+	limiter.base.Store(settings)
+	limiter.storeSettings(settings)
+	return limiter
+}
+
+// NewRateLimiterFromConfig 根据应用配置创建分布式速率限制器
+func NewRateLimiterFromConfig(cfg *config.Config) *DistributedRateLimiter {
+	settings := rateLimitSettingsFromConfig(cfg.RateLimit)
+
+	// 创建速率限制器配置
+	limiterConfig := RateLimiterConfig{
+		RedisAddr:             fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		RedisPassword:         cfg.Redis.Password,
+		RedisDB:               cfg.Redis.DB,
+		RedisPoolSize:         cfg.Redis.PoolSize,
+		AnonymousRequests:     settings.anonymousRequests,
+		AuthenticatedRequests: settings.authenticatedRequests,
+		WindowDuration:        settings.window,
+		KeyPrefix:             cfg.RateLimit.RedisKey,
+		FallbackEnabled:       true, // 启用降级
+	}
+
+	limiter := NewDistributedRateLimiter(limiterConfig)
+	limiter.base.Store(settings)
+	limiter.storeSettings(settings)
+	return limiter
+}
+
+// rateLimitSettingsFromConfig 将速率限制配置转换为限制参数
+func rateLimitSettingsFromConfig(cfg config.RateLimitConfig) *rateLimitSettings {
+	// 解析时间窗口
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil || window <= 0 {
+		window = time.Minute // 默认 1 分钟
+	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = RateLimitModeEnforce
+	}
+	warnThreshold := cfg.WarnThreshold
+	if warnThreshold <= 0 || warnThreshold > 100 {
+		warnThreshold = 80 // 默认 80%
+	}
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = RateLimitAlgorithmSlidingWindow
+	}
+	burst := cfg.Burst
+	if burst < 0 {
+		burst = 0
+	}
+
+	return &rateLimitSettings{
+		enabled:               cfg.Enabled,
+		mode:                  mode,
+		warnThreshold:         warnThreshold,
+		anonymousRequests:     cfg.Requests,     // 使用配置中的匿名用户限制
+		authenticatedRequests: cfg.Requests * 2, // 认证用户是匿名用户的2倍
+		window:                window,
+		algorithm:             algorithm,
+		burst:                 burst,
+		costs:                 rateLimitCostsFromConfig(cfg.Costs),
+	}
+}
+
+// Name 实现 config.Subscriber 接口
+func (r *DistributedRateLimiter) Name() string {
+	return "rate_limit"
+}
+
+// ValidateConfig 实现 config.Subscriber 接口，校验新的速率限制配置
+func (r *DistributedRateLimiter) ValidateConfig(newConfig *config.Config) error {
+	if _, err := time.ParseDuration(newConfig.RateLimit.Window); err != nil {
+		return fmt.Errorf("无效的速率限制时间窗口 %q: %w", newConfig.RateLimit.Window, err)
+	}
+	if newConfig.RateLimit.Requests < 0 {
+		return fmt.Errorf("速率限制请求次数不能为负数: %d", newConfig.RateLimit.Requests)
 	}
+	switch newConfig.RateLimit.Mode {
+	case "", RateLimitModeEnforce, RateLimitModeWarn, RateLimitModeShadow:
+	default:
+		return fmt.Errorf("无效的速率限制模式 %q", newConfig.RateLimit.Mode)
+	}
+	switch newConfig.RateLimit.Algorithm {
+	case "", RateLimitAlgorithmSlidingWindow, RateLimitAlgorithmTokenBucket:
+	default:
+		return fmt.Errorf("无效的速率限制算法 %q", newConfig.RateLimit.Algorithm)
+	}
+	if newConfig.RateLimit.Burst < 0 {
+		return fmt.Errorf("令牌桶突发容量不能为负数: %d", newConfig.RateLimit.Burst)
+	}
+	for _, cost := range newConfig.RateLimit.Costs {
+		if cost.Cost < 1 {
+			return fmt.Errorf("路由 %s 的请求消耗必须大于0: %d", cost.Route, cost.Cost)
+		}
+	}
+	return nil
+}
+
+// ApplyConfig 实现 config.Subscriber 接口，原子替换限制参数，管理员设置的运行时覆盖继续优先
+// Redis 连接和键前缀的变更需要重启后生效
+func (r *DistributedRateLimiter) ApplyConfig(oldConfig, newConfig *config.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	base := rateLimitSettingsFromConfig(newConfig.RateLimit)
+	r.base.Store(base)
+	r.applySettings(base.withOverrides(r.overrides.Load()))
+	return nil
+}
+
+// applySettings 更新内存降级限制器并替换生效的限制参数
+func (r *DistributedRateLimiter) applySettings(settings *rateLimitSettings) {
+	r.fallback.update(settings.authenticatedRequests, settings.window)
+	r.anonymous.update(settings.anonymousRequests, settings.window)
+	r.storeSettings(settings)
+}
+
+// storeSettings 替换生效的限制参数，并同步到统计中记录的配置
+func (r *DistributedRateLimiter) storeSettings(settings *rateLimitSettings) {
+	r.settings.Store(settings)
+	r.metrics.UpdateConfig(metrics.RateLimitConfig{
+		RequestsPerMinute: int(float64(settings.anonymousRequests) * float64(time.Minute) / float64(settings.window)),
+		WindowSize:        settings.window,
+		Enabled:           settings.enabled,
+		Mode:              settings.mode,
+		Algorithm:         settings.algorithm,
+		Burst:             settings.burst,
+	})
+}
+
+// Metrics 返回速率限制的放行和限流统计
+func (r *DistributedRateLimiter) Metrics() *metrics.RateLimitMetrics {
+	return r.metrics
+}
+
+// Enforcing 是否启用且拦截超过限制的请求；warn 和 shadow 模式下统计中的限流数是按限制评估本应拦截的请求数
+func (r *DistributedRateLimiter) Enforcing() bool {
+	settings := r.settings.Load()
+	return settings.enabled && settings.enforcing()
 }
 
-// isAllowedRedis 使用 Redis 滑动窗口检查速率限制
-func (r *DistributedRateLimiter) isAllowedRedis(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+// isAllowedRedis 使用 Redis 滑动窗口检查速率限制，同时返回窗口内已计入的请求数（放行时包含本次请求）
+func (r *DistributedRateLimiter) isAllowedRedis(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, int, error) {
 	now := time.Now().Unix()
 	windowSeconds := int64(window.Seconds())
 
@@ -157,7 +357,7 @@ func (r *DistributedRateLimiter) isAllowedRedis(ctx context.Context, key string,
 			redis.call('ZADD', key, now, now)
 			-- 设置过期时间
 			redis.call('EXPIRE', key, window + 1)
-			return {1, 0}
+			return {1, 0, current + 1}
 		else
 			-- 获取最早的请求时间
 			local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
@@ -167,9 +367,9 @@ func (r *DistributedRateLimiter) isAllowedRedis(ctx context.Context, key string,
 				if retry_after < 0 then
 					retry_after = 0
 				end
-				return {0, retry_after}
+				return {0, retry_after, current}
 			else
-				return {0, window}
+				return {0, window, current}
 			end
 		end
 	`
@@ -177,71 +377,150 @@ func (r *DistributedRateLimiter) isAllowedRedis(ctx context.Context, key string,
 	// 执行 Lua 脚本
 	result, err := r.redis.Eval(ctx, luaScript, []string{key}, now, windowSeconds, limit).Result()
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 
 	// 解析结果
 	res := result.([]interface{})
 	allowed := res[0].(int64) == 1
 	retryAfter := time.Duration(res[1].(int64)) * time.Second
+	count := int(res[2].(int64))
 
-	return allowed, retryAfter, nil
+	return allowed, retryAfter, count, nil
 }
 
 // isAllowed 检查请求是否被允许
 func (r *DistributedRateLimiter) isAllowed(ctx context.Context, clientID string, isAuthenticated bool) (bool, time.Duration) {
 	var limit int
+	settings := r.settings.Load()
 
 	// 根据用户类型设置不同的限制
 	if isAuthenticated {
-		limit = r.config.AuthenticatedRequests
+		limit = settings.authenticatedRequests
 	} else {
-		limit = r.config.AnonymousRequests
+		limit = settings.anonymousRequests
+	}
+
+	allowed, retryAfter, _ := r.allow(ctx, clientID, limit, 1, isAuthenticated)
+	return allowed, retryAfter
+}
+
+// limitFor 返回请求适用的限制，租户设置了自己的限制时优先使用
+func (r *DistributedRateLimiter) limitFor(c *gin.Context, settings *rateLimitSettings, isAuthenticated bool) int {
+	limit := settings.anonymousRequests
+	if isAuthenticated {
+		limit = settings.authenticatedRequests
+	}
+
+	if tenant, ok := CurrentTenant(c); ok {
+		if isAuthenticated && tenant.RateLimit > 0 {
+			limit = tenant.RateLimit
+		} else if !isAuthenticated && tenant.AnonymousRateLimit > 0 {
+			limit = tenant.AnonymousRateLimit
+		}
+	}
+
+	// 订阅套餐的限制针对单个用户，优先于租户的限制
+	if plan, ok := CurrentPlan(c); ok && isAuthenticated && plan.RateLimit > 0 {
+		limit = plan.RateLimit
+	}
+	return limit
+}
+
+// routeLimitFor 返回管理员为请求路由设置的限制，没有设置时返回 false
+func (r *DistributedRateLimiter) routeLimitFor(c *gin.Context, settings *rateLimitSettings, isAuthenticated bool) (int, bool) {
+	tier, ok := settings.routes[c.FullPath()]
+	if !ok {
+		return 0, false
+	}
+	limit := tier.Anonymous
+	if isAuthenticated {
+		limit = tier.Authenticated
+	}
+	return limit, limit > 0
+}
+
+// allow 按给定限制检查请求，返回是否放行、重试等待时间和窗口内已计入的请求数（令牌桶算法下为已消耗的令牌数）
+// cost 为请求消耗的令牌数，只在令牌桶算法下生效；滑动窗口算法在 Redis 不可用时内存降级限制器使用全局限制
+func (r *DistributedRateLimiter) allow(ctx context.Context, clientID string, limit, cost int, isAuthenticated bool) (bool, time.Duration, int) {
+	settings := r.settings.Load()
+	if settings.algorithm == RateLimitAlgorithmTokenBucket {
+		return r.takeTokens(ctx, settings, clientID, limit, cost)
 	}
 
 	// 构建 Redis 键
 	key := fmt.Sprintf("%s:%s", r.config.KeyPrefix, clientID)
 
 	// 尝试使用 Redis 限制器
-	allowed, retryAfter, err := r.isAllowedRedis(ctx, key, limit, r.config.WindowDuration)
+	allowed, retryAfter, count, err := r.isAllowedRedis(ctx, key, limit, settings.window)
 	if err == nil {
-		return allowed, retryAfter
+		return allowed, retryAfter, count
 	}
 
 	// Redis 不可用时，使用内存降级限制器
 	if r.config.FallbackEnabled {
 		if isAuthenticated {
-			return r.fallback.isAllowed(clientID)
+			return r.fallback.check(clientID)
 		} else {
-			return r.anonymous.isAllowed(clientID)
+			return r.anonymous.check(clientID)
 		}
 	}
 
 	// 如果没有启用降级，则允许请求（fail-open 策略）
-	return true, 0
+	return true, 0, 0
 }
 
-// getClientID 获取客户端标识符
+// getClientID 获取客户端标识符，确定了租户时按租户分别计数
 func (r *DistributedRateLimiter) getClientID(c *gin.Context) string {
+	var prefix string
+	if tenant, ok := CurrentTenant(c); ok {
+		prefix = "tenant:" + tenant.ID + ":"
+	}
+
 	// 优先使用用户 ID（如果已认证）
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(string); ok {
-			return "user:" + id
+			return prefix + "user:" + id
 		}
 	}
+	if id := planUserID(c); id != "" {
+		return prefix + "user:" + id
+	}
 
 	// 否则使用 IP 地址
 	clientIP := c.ClientIP()
 	if clientIP == "" {
 		clientIP = c.Request.RemoteAddr
 	}
-	return "ip:" + clientIP
+	return prefix + "ip:" + clientIP
+}
+
+// recordCheck 记录一次限流检查的结果；warn 和 shadow 模式下未放行表示按限制本应拦截，原因带有模式后缀
+func (r *DistributedRateLimiter) recordCheck(c *gin.Context, settings *rateLimitSettings, duration time.Duration, allowed bool, count, limit int) {
+	var userID string
+	if id, ok := c.Get("user_id"); ok {
+		userID, _ = id.(string)
+	}
+
+	reason := ""
+	if !allowed {
+		reason = "rate_limit_exceeded"
+		if !settings.enforcing() {
+			reason += "_" + settings.mode
+		}
+	}
+
+	endpoint := c.FullPath()
+	if endpoint == "" {
+		endpoint = c.Request.URL.Path
+	}
+	r.metrics.RecordRequest(c.ClientIP(), userID, endpoint, duration, allowed, reason, int64(count), int64(limit))
 }
 
-// isUserAuthenticated 检查用户是否已认证
+// isUserAuthenticated 检查用户是否已认证，套餐中间件从令牌识别了用户时也视为已认证
 func (r *DistributedRateLimiter) isUserAuthenticated(c *gin.Context) bool {
 	_, exists := c.Get("user_id")
-	return exists
+	return exists || planUserID(c) != ""
 }
 
 // Close 关闭 Redis 连接
@@ -254,54 +533,53 @@ func (r *DistributedRateLimiter) Close() error {
 
 // RateLimiterMiddleware 创建速率限制中间件
 func RateLimiterMiddleware(cfg *config.Config) gin.HandlerFunc {
-	// 解析时间窗口
-	windowDuration, err := time.ParseDuration(cfg.RateLimit.Window)
-	if err != nil {
-		windowDuration = time.Minute // 默认 1 分钟
-	}
-
-	// 创建速率限制器配置
-	limiterConfig := RateLimiterConfig{
-		RedisAddr:             fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		RedisPassword:         cfg.Redis.Password,
-		RedisDB:               cfg.Redis.DB,
-		RedisPoolSize:         cfg.Redis.PoolSize,
-		AnonymousRequests:     cfg.RateLimit.Requests,     // 使用配置中的匿名用户限制
-		AuthenticatedRequests: cfg.RateLimit.Requests * 2, // 认证用户是匿名用户的2倍
-		WindowDuration:        windowDuration,
-		KeyPrefix:             cfg.RateLimit.RedisKey,
-		FallbackEnabled:       true, // 启用降级
-	}
-
-	// 创建分布式速率限制器
-	limiter := NewDistributedRateLimiter(limiterConfig)
+	return NewRateLimiterFromConfig(cfg).Middleware()
+}
 
+// Middleware 返回速率限制中间件，每个请求读取当前生效的限制参数，支持配置热重载
+func (r *DistributedRateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		settings := r.settings.Load()
+
 		// 如果速率限制被禁用，直接通过
-		if !cfg.RateLimit.Enabled {
+		if !settings.enabled {
 			c.Next()
 			return
 		}
 
 		// 获取客户端标识和认证状态
-		clientID := limiter.getClientID(c)
-		isAuthenticated := limiter.isUserAuthenticated(c)
-
-		// 检查速率限制
-		allowed, retryAfter := limiter.isAllowed(c.Request.Context(), clientID, isAuthenticated)
+		clientID := r.getClientID(c)
+		isAuthenticated := r.isUserAuthenticated(c)
+
+		// 检查速率限制，设置了路由限制的路由单独计数，不占用全局限制
+		limit := r.limitFor(c, settings, isAuthenticated)
+		if routeLimit, ok := r.routeLimitFor(c, settings, isAuthenticated); ok {
+			limit = routeLimit
+			clientID += ":route:" + c.FullPath()
+		}
+		cost := settings.costFor(c.FullPath())
+		checkStart := time.Now()
+		allowed, retryAfter, count := r.allow(c.Request.Context(), clientID, limit, cost, isAuthenticated)
+		// 令牌桶算法下响应头和统计中的限制为桶容量
+		limit = settings.capacity(limit)
+		r.recordCheck(c, settings, time.Since(checkStart), allowed, count, limit)
+
+		// shadow 模式只评估和记录统计，对客户端不可见
+		if settings.mode == RateLimitModeShadow {
+			c.Next()
+			return
+		}
 
-		if !allowed {
+		if !allowed && settings.enforcing() {
 			// 设置 Retry-After 头
 			if retryAfter > 0 {
-				c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 			}
 
 			// 返回 429 状态码和错误信息
-			message := "请求过于频繁，请稍后再试"
+			message := localize(c, "rate_limit.exceeded_anonymous")
 			if isAuthenticated {
-				message = "认证用户请求过于频繁，请稍后再试"
-			} else {
-				message = "匿名用户请求过于频繁，请稍后再试"
+				message = localize(c, "rate_limit.exceeded_authenticated")
 			}
 
 			c.JSON(http.StatusTooManyRequests, response.Response{
@@ -314,14 +592,22 @@ func RateLimiterMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// 设置速率限制相关的响应头
-		c.Header("X-RateLimit-Limit", strconv.Itoa(limiterConfig.AuthenticatedRequests))
-		if isAuthenticated {
-			c.Header("X-RateLimit-Remaining", strconv.Itoa(limiterConfig.AuthenticatedRequests-1))
-		} else {
-			c.Header("X-RateLimit-Limit", strconv.Itoa(limiterConfig.AnonymousRequests))
-			c.Header("X-RateLimit-Remaining", strconv.Itoa(limiterConfig.AnonymousRequests-1))
+		remaining := limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(int(time.Now().Add(settings.window).Unix())))
+
+		// warn 模式不拦截，超过限制或用量达到警告阈值时提示客户端即将被限流
+		if settings.mode == RateLimitModeWarn {
+			if !allowed {
+				c.Header("X-RateLimit-Warning", "exceeded")
+			} else if limit > 0 && count*100 >= limit*settings.warnThreshold {
+				c.Header("X-RateLimit-Warning", "approaching")
+			}
 		}
-		c.Header("X-RateLimit-Reset", strconv.Itoa(int(time.Now().Add(limiterConfig.WindowDuration).Unix())))
 
 		c.Next()
 	}
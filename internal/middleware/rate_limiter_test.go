@@ -135,7 +135,7 @@ func TestRateLimiterMiddleware_Anonymous(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
-	assert.Contains(t, w.Body.String(), "请求过于频繁")
+	assert.Contains(t, w.Body.String(), "Too many requests")
 }
 
 // TestRateLimiterMiddleware_Authenticated 测试认证用户速率限制
@@ -232,6 +232,56 @@ func TestRateLimiterMiddleware_Disabled(t *testing.T) {
 	}
 }
 
+// TestDistributedRateLimiter_ApplyConfig 测试配置热重载后限制参数立即生效
+func TestDistributedRateLimiter_ApplyConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 使用不可达的 Redis 地址，确保走内存降级限制器
+	oldConfig := &config.Config{
+		Redis: config.RedisConfig{Host: "127.0.0.1", Port: 1},
+		RateLimit: config.RateLimitConfig{
+			Enabled:  false,
+			Requests: 100,
+			Window:   "1m",
+			RedisKey: "test_reload",
+		},
+	}
+	limiter := NewRateLimiterFromConfig(oldConfig)
+	defer limiter.Close()
+
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func() int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "192.168.1.2:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusOK, send())
+
+	// 无效的时间窗口应被拒绝
+	invalid := *oldConfig
+	invalid.RateLimit.Window = "not-a-duration"
+	assert.Error(t, limiter.ValidateConfig(&invalid))
+
+	// 启用并收紧限制
+	newConfig := *oldConfig
+	newConfig.RateLimit.Enabled = true
+	newConfig.RateLimit.Requests = 1
+	require.NoError(t, limiter.ValidateConfig(&newConfig))
+	require.NoError(t, limiter.ApplyConfig(oldConfig, &newConfig))
+
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusTooManyRequests, send())
+}
+
 // TestGetClientID 测试客户端ID获取逻辑
 func TestGetClientID(t *testing.T) {
 	cfg := RateLimiterConfig{
@@ -510,7 +560,7 @@ func TestRateLimiterMiddleware_429Response(t *testing.T) {
 	router.ServeHTTP(w2, req2)
 
 	assert.Equal(t, http.StatusTooManyRequests, w2.Code)
-	assert.Contains(t, w2.Body.String(), "请求过于频繁")
+	assert.Contains(t, w2.Body.String(), "Too many requests")
 	assert.NotEmpty(t, w2.Header().Get("Retry-After"))
 }
 
@@ -687,3 +737,105 @@ func TestRateLimiterMiddleware_MiddlewareChain(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code, "认证用户请求 %d 应该成功", i+1)
 	}
 }
+
+// newModeTestLimiter 创建指定模式的限制器，Redis 指向不可用的端口以使用内存降级限制器
+func newModeTestLimiter(mode string, requests int) *DistributedRateLimiter {
+	return NewRateLimiterFromConfig(&config.Config{
+		RateLimit: config.RateLimitConfig{
+			Enabled:       true,
+			Requests:      requests,
+			Window:        "1m",
+			RedisKey:      "test_rate_limit_mode",
+			Mode:          mode,
+			WarnThreshold: 75,
+		},
+		Redis: config.RedisConfig{Host: "127.0.0.1", Port: 1, PoolSize: 1},
+	})
+}
+
+// serveModeRequests 发送 n 个来自同一IP的请求，返回各个响应
+func serveModeRequests(limiter *DistributedRateLimiter, n int) []*httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	responses := make([]*httptest.ResponseRecorder, n)
+	for i := range responses {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "192.168.7.7:1234"
+		responses[i] = httptest.NewRecorder()
+		router.ServeHTTP(responses[i], req)
+	}
+	return responses
+}
+
+func TestRateLimiterMiddleware_ShadowMode(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeShadow, 2)
+	defer limiter.Close()
+	assert.False(t, limiter.Enforcing())
+
+	for _, w := range serveModeRequests(limiter, 4) {
+		assert.Equal(t, http.StatusOK, w.Code, "shadow mode never blocks")
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"), "shadow mode is invisible to clients")
+		assert.Empty(t, w.Header().Get("X-RateLimit-Warning"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	}
+
+	// 统计按限制评估，超过限制的请求计为本应拦截
+	stats := limiter.Metrics().GetStats()
+	assert.Equal(t, uint64(4), stats.TotalRequests)
+	assert.Equal(t, uint64(2), stats.ThrottledRequests)
+	assert.Equal(t, RateLimitModeShadow, stats.Configuration.Mode)
+	require.NotEmpty(t, stats.RecentRequests)
+	assert.Equal(t, "rate_limit_exceeded_shadow", stats.RecentRequests[len(stats.RecentRequests)-1].Reason)
+}
+
+func TestRateLimiterMiddleware_WarnMode(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeWarn, 4)
+	defer limiter.Close()
+
+	responses := serveModeRequests(limiter, 6)
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code, "warn mode never blocks")
+	}
+
+	// 限制为 4、阈值为 75%：第 3 个请求开始提示即将限流，超过限制后提示已超过
+	assert.Empty(t, responses[1].Header().Get("X-RateLimit-Warning"))
+	assert.Equal(t, "2", responses[1].Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "approaching", responses[2].Header().Get("X-RateLimit-Warning"))
+	assert.Equal(t, "approaching", responses[3].Header().Get("X-RateLimit-Warning"))
+	assert.Equal(t, "0", responses[3].Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "exceeded", responses[4].Header().Get("X-RateLimit-Warning"))
+	assert.Equal(t, "exceeded", responses[5].Header().Get("X-RateLimit-Warning"))
+
+	assert.Equal(t, uint64(2), limiter.Metrics().GetStats().ThrottledRequests)
+}
+
+func TestRateLimiterMiddleware_EnforceModeHeaders(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeEnforce, 2)
+	defer limiter.Close()
+	assert.True(t, limiter.Enforcing())
+
+	responses := serveModeRequests(limiter, 3)
+	assert.Equal(t, "1", responses[0].Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "0", responses[1].Header().Get("X-RateLimit-Remaining"))
+	assert.Empty(t, responses[1].Header().Get("X-RateLimit-Warning"), "only warn mode adds warning headers")
+	assert.Equal(t, http.StatusTooManyRequests, responses[2].Code)
+}
+
+func TestDistributedRateLimiter_ModeHotReload(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeEnforce, 2)
+	defer limiter.Close()
+
+	newConfig := &config.Config{RateLimit: config.RateLimitConfig{Enabled: true, Requests: 2, Window: "1m", Mode: "block"}}
+	assert.Error(t, limiter.ValidateConfig(newConfig))
+
+	newConfig.RateLimit.Mode = RateLimitModeShadow
+	require.NoError(t, limiter.ValidateConfig(newConfig))
+	require.NoError(t, limiter.ApplyConfig(nil, newConfig))
+	assert.False(t, limiter.Enforcing())
+	assert.Equal(t, RateLimitModeShadow, limiter.Metrics().GetStats().Configuration.Mode)
+}
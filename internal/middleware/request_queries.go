@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestQueryMiddleware 统计每个请求执行的SQL，查询过多或出现 N+1 查询时输出带调用栈的告警
+// 语句由数据库的请求查询插件按关联ID计数，需安装在结构化日志中间件之后；requestQueries 为 nil 时不做处理
+func RequestQueryMiddleware(requestQueries *metrics.RequestQueryMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := GetCorrelationIDFromContext(c)
+		if requestQueries == nil || correlationID == "" {
+			c.Next()
+			return
+		}
+
+		requestQueries.Begin(correlationID)
+		defer func() {
+			report := requestQueries.End(correlationID, c.Request.Method, c.FullPath())
+			if !report.Flagged() {
+				return
+			}
+			GetLoggerFromContext(c).Warn(c.Request.Context(), "检测到请求查询过多",
+				logger.String("method", c.Request.Method),
+				logger.String("route", c.FullPath()),
+				logger.Int("queries", report.Queries),
+				logger.Bool("too_many_queries", report.TooManyQueries),
+				logger.Bool("n_plus_one", report.NPlusOne),
+				logger.String("top_statement", report.TopStatement),
+				logger.Int("top_repetitions", report.TopRepetitions),
+				logger.String("stack", strings.Join(report.Stack, "\n")),
+			)
+		}()
+
+		c.Next()
+	}
+}
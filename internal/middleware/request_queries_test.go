@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-server/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestQueryMiddleware 测试按关联ID统计请求中的语句，并按路由模板记录 N+1 查询
+func TestRequestQueryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	requestQueries := metrics.NewRequestQueryMetrics(50, 3, 10)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(correlationIDContextKey, c.GetHeader(correlationIDHeader))
+		c.Next()
+	})
+	router.Use(RequestQueryMiddleware(requestQueries))
+	router.GET("/users/:id", func(c *gin.Context) {
+		for i := 0; i < 3; i++ {
+			requestQueries.Record(GetCorrelationIDFromContext(c), "SELECT * FROM roles WHERE user_id = $1", nil)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, correlationID := range []string{"req-1", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set(correlationIDHeader, correlationID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	stats := requestQueries.GetStats()
+	assert.Equal(t, uint64(1), stats.Requests, "没有关联ID的请求不计数")
+	require.Len(t, stats.Offenders, 1)
+	assert.Equal(t, "/users/:id", stats.Offenders[0].Route)
+	assert.Equal(t, uint64(1), stats.Offenders[0].NPlusOneRequests)
+	assert.Equal(t, "req-1", stats.Offenders[0].LastCorrelationID)
+
+	// 未启用时直接放行
+	router = gin.New()
+	router.Use(RequestQueryMiddleware(nil))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/tenancy"
+	"go-server/pkg/cache"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ResponseCacheStatusHeader 标记响应缓存状态的响应头：HIT、MISS、STALE 或 BYPASS
+	ResponseCacheStatusHeader = "X-Cache"
+
+	// responseCacheRevalidateTTL 后台刷新锁的占用时间，避免多个请求同时刷新同一个旧响应
+	responseCacheRevalidateTTL = 10 * time.Second
+)
+
+// cachedResponse 缓存的响应
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`    // 响应状态码
+	Header     http.Header `json:"header"`         // 响应头
+	Body       []byte      `json:"body"`           // 响应体
+	ETag       string      `json:"etag,omitempty"` // 响应的实体标签
+	StoredAt   time.Time   `json:"stored_at"`      // 写入时间
+	FreshUntil time.Time   `json:"fresh_until"`    // 新鲜期截止时间，之后在旧响应可用期内返回旧响应
+}
+
+// responseCacheRule 解析后的路由缓存规则
+type responseCacheRule struct {
+	ttl    time.Duration
+	shared bool
+	tags   []string
+}
+
+// responseCacheWriter 记录处理器的响应以便写入缓存
+// 后台刷新时客户端已收到旧响应，处理器的输出只写入缓存，不再发送
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	header   http.Header
+	body     bytes.Buffer
+	limit    int64
+	status   int
+	discard  bool
+	overflow bool
+}
+
+// Header 后台刷新时返回独立的响应头，避免修改已发送的响应
+func (w *responseCacheWriter) Header() http.Header {
+	if w.discard {
+		return w.header
+	}
+	return w.ResponseWriter.Header()
+}
+
+// WriteHeader 记录状态码
+func (w *responseCacheWriter) WriteHeader(code int) {
+	w.status = code
+	if !w.discard {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Write 写入响应并保留副本，超过可缓存上限后不再保留
+func (w *responseCacheWriter) Write(data []byte) (int, error) {
+	if !w.overflow {
+		if int64(w.body.Len()+len(data)) > w.limit {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	if w.discard {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应并保留副本
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// ResponseCache HTTP 响应缓存中间件
+// 挂载在具体的 GET 路由上（位于认证和权限中间件之后），只缓存配置了规则的路由的 200 响应。
+// 缓存键由路径、排序后的查询参数和当前用户（非共享规则）组成；新鲜期内直接返回缓存，
+// 过期后的旧响应可用期内立即返回旧响应，并由一个请求在响应发送后重新执行处理器刷新缓存。
+// 缓存按规则中的标签写入，仓库在数据变更时按相同标签失效
+type ResponseCache struct {
+	cache       cache.Cache
+	keyPrefix   string
+	stale       time.Duration
+	maxBodySize int64
+	rules       map[string]responseCacheRule
+}
+
+// NewResponseCache 根据配置创建响应缓存中间件
+func NewResponseCache(cfg config.ResponseCacheConfig, store cache.Cache) *ResponseCache {
+	rules := make(map[string]responseCacheRule, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		rules[route.Path] = responseCacheRule{
+			ttl:    time.Duration(route.TTL) * time.Second,
+			shared: route.Shared,
+			tags:   append([]string(nil), route.Tags...),
+		}
+	}
+
+	return &ResponseCache{
+		cache:       store,
+		keyPrefix:   cfg.KeyPrefix,
+		stale:       time.Duration(cfg.StaleWhileRevalidate) * time.Second,
+		maxBodySize: cfg.MaxBodySize,
+		rules:       rules,
+	}
+}
+
+// Middleware 返回响应缓存处理函数，rc 为 nil（未启用）时直接放行
+func (rc *ResponseCache) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rc == nil || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		rule, ok := rc.rules[c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := rc.cacheKey(c, rule)
+
+		status := "MISS"
+		if strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			status = "BYPASS"
+		} else if entry, found := cache.GetAs[cachedResponse](ctx, rc.cache, key); found {
+			if time.Now().Before(entry.FreshUntil) {
+				rc.serve(c, &entry, "HIT")
+				c.Abort()
+				return
+			}
+
+			rc.serve(c, &entry, "STALE")
+			acquired, err := rc.cache.SetIfNotExists(ctx, key+":revalidate", "1", responseCacheRevalidateTTL)
+			if err != nil || !acquired {
+				c.Abort()
+				return
+			}
+			c.Writer.Flush()
+			rc.revalidate(c, key, rule)
+			return
+		}
+
+		writer := &responseCacheWriter{ResponseWriter: c.Writer, limit: rc.maxBodySize}
+		c.Writer = writer
+		c.Header(ResponseCacheStatusHeader, status)
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		rc.store(c, key, rule, writer)
+	}
+}
+
+// Invalidate 按标签删除缓存的响应
+func (rc *ResponseCache) Invalidate(ctx context.Context, tags ...string) error {
+	if rc == nil {
+		return nil
+	}
+	var errs []error
+	for _, tag := range tags {
+		if err := rc.cache.InvalidateTag(ctx, tag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// revalidate 旧响应发送后重新执行处理器并刷新缓存
+func (rc *ResponseCache) revalidate(c *gin.Context, key string, rule responseCacheRule) {
+	writer := &responseCacheWriter{
+		ResponseWriter: c.Writer,
+		header:         make(http.Header),
+		limit:          rc.maxBodySize,
+		discard:        true,
+	}
+	c.Writer = writer
+	c.Next()
+	c.Writer = writer.ResponseWriter
+
+	rc.store(c, key, rule, writer)
+	rc.cache.Delete(context.WithoutCancel(c.Request.Context()), key+":revalidate")
+}
+
+// serve 发送缓存的响应
+func (rc *ResponseCache) serve(c *gin.Context, entry *cachedResponse, status string) {
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	header.Set(ResponseCacheStatusHeader, status)
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	response.SetETag(c, entry.ETag)
+
+	c.Writer.WriteHeader(entry.StatusCode)
+	c.Writer.Write(entry.Body)
+}
+
+// store 将 200 响应写入缓存
+func (rc *ResponseCache) store(c *gin.Context, key string, rule responseCacheRule, writer *responseCacheWriter) {
+	status := writer.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status != http.StatusOK || writer.overflow || strings.Contains(writer.Header().Get("Cache-Control"), "no-store") {
+		return
+	}
+
+	header := storableHeader(writer.Header())
+	header.Del(ResponseCacheStatusHeader)
+	header.Del("Age")
+
+	etag := header.Get(response.ETagHeader)
+	header.Del(response.ETagHeader)
+	if etag == "" {
+		if data, ok := response.DataFromContext(c); ok {
+			etag = response.ComputeETag(data)
+		} else {
+			etag = response.StrongETag(writer.body.Bytes())
+		}
+	}
+
+	now := time.Now().UTC()
+	entry := &cachedResponse{
+		StatusCode: status,
+		Header:     header,
+		Body:       writer.body.Bytes(),
+		ETag:       etag,
+		StoredAt:   now,
+		FreshUntil: now.Add(rule.ttl),
+	}
+
+	// 请求结束后客户端可能已断开，写入时不使用请求的取消信号
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), 5*time.Second)
+	defer cancel()
+	rc.cache.SetWithTags(ctx, key, entry, rule.ttl+rc.stale, expandCacheTags(c, rule.tags)...)
+}
+
+// cacheKey 生成缓存键，查询参数按名称排序，非共享规则按当前用户区分，多租户时按租户区分
+func (rc *ResponseCache) cacheKey(c *gin.Context, rule responseCacheRule) string {
+	scope := "shared"
+	if !rule.shared {
+		scope = "anonymous"
+		if userID := c.GetString("user_id"); userID != "" {
+			scope = "user:" + userID
+		}
+	}
+
+	sum := sha256.Sum256([]byte(c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()))
+	return rc.keyPrefix + ":" + tenancy.CacheKey(c.Request.Context(), scope+":"+hex.EncodeToString(sum[:16]))
+}
+
+// expandCacheTags 将标签中的 {param} 占位符替换为路由参数
+func expandCacheTags(c *gin.Context, tags []string) []string {
+	expanded := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		for _, param := range c.Params {
+			tag = strings.ReplaceAll(tag, "{"+param.Key+"}", param.Value)
+		}
+		expanded = append(expanded, tag)
+	}
+	return expanded
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/pkg/cache"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taggedMemoryCache 只实现响应缓存用到的方法，调用其他方法会 panic
+type taggedMemoryCache struct {
+	cache.Cache
+	data map[string][]byte
+	tags map[string][]string
+}
+
+func newTaggedMemoryCache() *taggedMemoryCache {
+	return &taggedMemoryCache{data: make(map[string][]byte), tags: make(map[string][]string)}
+}
+
+func (m *taggedMemoryCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	data, found := m.data[key]
+	return data, found
+}
+
+func (m *taggedMemoryCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.data[key] = data
+	for _, tag := range tags {
+		m.tags[tag] = append(m.tags[tag], key)
+	}
+	return nil
+}
+
+func (m *taggedMemoryCache) SetIfNotExists(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if _, found := m.data[key]; found {
+		return false, nil
+	}
+	m.data[key] = []byte("1")
+	return true, nil
+}
+
+func (m *taggedMemoryCache) Delete(ctx context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func (m *taggedMemoryCache) InvalidateTag(ctx context.Context, tag string) error {
+	for _, key := range m.tags[tag] {
+		delete(m.data, key)
+	}
+	delete(m.tags, tag)
+	return nil
+}
+
+// expire 将所有缓存的响应标记为已过新鲜期
+func (m *taggedMemoryCache) expire(t *testing.T) {
+	for key, data := range m.data {
+		var entry cachedResponse
+		if json.Unmarshal(data, &entry) != nil {
+			continue
+		}
+		entry.FreshUntil = time.Now().Add(-time.Second)
+		updated, err := json.Marshal(entry)
+		require.NoError(t, err)
+		m.data[key] = updated
+	}
+}
+
+func newResponseCacheRouter(store cache.Cache, calls *atomic.Int32) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	responseCache := NewResponseCache(config.ResponseCacheConfig{
+		Enabled:              true,
+		KeyPrefix:            "response_cache",
+		StaleWhileRevalidate: 30,
+		MaxBodySize:          1 << 20,
+		Routes: []config.ResponseCacheRoute{
+			{Path: "/users/:id", TTL: 60, Shared: true, Tags: []string{"users:id:{id}"}},
+			{Path: "/me", TTL: 60},
+		},
+	}, store)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+	})
+	router.GET("/users/:id", responseCache.Middleware(), func(c *gin.Context) {
+		n := calls.Add(1)
+		if c.Param("id") == "missing" {
+			response.NotFoundError(c, "User", "missing")
+			return
+		}
+		response.Success(c, http.StatusOK, "ok", gin.H{"id": c.Param("id"), "call": n})
+	})
+	router.GET("/me", responseCache.Middleware(), func(c *gin.Context) {
+		calls.Add(1)
+		response.Success(c, http.StatusOK, "ok", gin.H{"id": c.GetString("user_id")})
+	})
+	router.GET("/uncached", responseCache.Middleware(), func(c *gin.Context) {
+		calls.Add(1)
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func sendCached(router *gin.Engine, path, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestResponseCache_HitAndInvalidate 测试命中缓存、查询参数归一化以及按标签失效
+func TestResponseCache_HitAndInvalidate(t *testing.T) {
+	var calls atomic.Int32
+	store := newTaggedMemoryCache()
+	router := newResponseCacheRouter(store, &calls)
+
+	first := sendCached(router, "/users/1?b=2&a=1", "u1")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get(ResponseCacheStatusHeader))
+
+	second := sendCached(router, "/users/1?a=1&b=2", "u2")
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get(ResponseCacheStatusHeader))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, response.ComputeETag(gin.H{"id": "1", "call": 1}), second.Header().Get(response.ETagHeader))
+	assert.Equal(t, int32(1), calls.Load())
+
+	require.NoError(t, NewResponseCache(config.ResponseCacheConfig{}, store).Invalidate(context.Background(), "users:id:1"))
+	third := sendCached(router, "/users/1?a=1&b=2", "u1")
+	assert.Equal(t, "MISS", third.Header().Get(ResponseCacheStatusHeader))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestResponseCache_Scope 测试非共享规则按用户区分缓存，未配置规则的路由和错误响应不缓存
+func TestResponseCache_Scope(t *testing.T) {
+	var calls atomic.Int32
+	router := newResponseCacheRouter(newTaggedMemoryCache(), &calls)
+
+	sendCached(router, "/me", "u1")
+	other := sendCached(router, "/me", "u2")
+	assert.Equal(t, "MISS", other.Header().Get(ResponseCacheStatusHeader))
+	assert.Contains(t, other.Body.String(), `"id":"u2"`)
+	again := sendCached(router, "/me", "u1")
+	assert.Equal(t, "HIT", again.Header().Get(ResponseCacheStatusHeader))
+	assert.Contains(t, again.Body.String(), `"id":"u1"`)
+
+	calls.Store(0)
+	sendCached(router, "/uncached", "u1")
+	sendCached(router, "/uncached", "u1")
+	sendCached(router, "/users/missing", "u1")
+	sendCached(router, "/users/missing", "u1")
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+// TestResponseCache_StaleWhileRevalidate 测试过期后返回旧响应并在后台刷新缓存
+func TestResponseCache_StaleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	store := newTaggedMemoryCache()
+	router := newResponseCacheRouter(store, &calls)
+
+	first := sendCached(router, "/users/1", "u1")
+	store.expire(t)
+
+	stale := sendCached(router, "/users/1", "u1")
+	assert.Equal(t, "STALE", stale.Header().Get(ResponseCacheStatusHeader))
+	assert.Equal(t, first.Body.String(), stale.Body.String())
+	assert.Equal(t, int32(2), calls.Load(), "刷新请求应在返回旧响应后重新执行处理器")
+
+	fresh := sendCached(router, "/users/1", "u1")
+	assert.Equal(t, "HIT", fresh.Header().Get(ResponseCacheStatusHeader))
+	assert.Contains(t, fresh.Body.String(), `"call":2`)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestResponseCache_Bypass 测试 Cache-Control: no-cache 跳过缓存读取但刷新缓存
+func TestResponseCache_Bypass(t *testing.T) {
+	var calls atomic.Int32
+	router := newResponseCacheRouter(newTaggedMemoryCache(), &calls)
+
+	sendCached(router, "/users/1", "u1")
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Cache-Control", "no-cache")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "BYPASS", w.Header().Get(ResponseCacheStatusHeader))
+	assert.Contains(t, w.Body.String(), `"call":2`)
+
+	cached := sendCached(router, "/users/1", "u1")
+	assert.Contains(t, cached.Body.String(), `"call":2`)
+}
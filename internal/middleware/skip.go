@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SkipPaths 请求路径位于 prefixes 中任一前缀下时跳过 handler，直接执行后续处理
+// 用于让静态媒体文件等请求绕过不需要的全局中间件，如压缩和请求日志
+func SkipPaths(handler gin.HandlerFunc, prefixes ...string) gin.HandlerFunc {
+	if len(prefixes) == 0 {
+		return handler
+	}
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, prefix := range prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+		handler(c)
+	}
+}
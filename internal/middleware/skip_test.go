@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var called bool
+	router := gin.New()
+	router.Use(SkipPaths(func(c *gin.Context) {
+		called = true
+		c.Next()
+	}, "/uploads"))
+	router.Any("/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := map[string]bool{
+		"/uploads/avatars/a.png": false,
+		"/uploads":               false,
+		"/uploads-other/a.png":   true,
+		"/api/v1/users":          true,
+	}
+	for path, want := range cases {
+		called = false
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.Equal(t, want, called, path)
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net"
+	"strings"
+
+	"go-server/internal/config"
+	"go-server/internal/models"
+	"go-server/internal/tenancy"
+	"go-server/pkg/auth"
+	"go-server/pkg/errors"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantContextKey gin 上下文中保存当前租户的键
+const TenantContextKey = "tenant"
+
+// 租户来源
+const (
+	TenantSourceHeader    = "header"
+	TenantSourceSubdomain = "subdomain"
+	TenantSourceJWT       = "jwt"
+)
+
+// TenantResolver 租户解析中间件
+// 按配置的顺序从请求头、子域名和令牌的 tenant_id 声明中确定租户，写入 gin 上下文和请求上下文；
+// 多个来源确定的租户不一致时返回 403，防止使用其他租户签发的令牌。令牌在这里只用于读取租户声明，
+// 认证仍由路由上的认证中间件完成
+type TenantResolver struct {
+	cfg        config.TenancyConfig
+	resolver   *tenancy.Resolver
+	jwtManager *auth.JWTManager
+}
+
+// NewTenantResolver 创建租户解析中间件，jwtManager 为 nil 时不从令牌读取租户
+func NewTenantResolver(cfg config.TenancyConfig, resolver *tenancy.Resolver, jwtManager *auth.JWTManager) *TenantResolver {
+	return &TenantResolver{
+		cfg:        cfg,
+		resolver:   resolver,
+		jwtManager: jwtManager,
+	}
+}
+
+// Middleware 返回 gin 中间件
+func (t *TenantResolver) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t.isExcluded(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		var (
+			tenant *models.Tenant
+			source string
+		)
+		for _, candidate := range t.cfg.Sources {
+			identifier := t.identifier(c, candidate)
+			if identifier == "" {
+				continue
+			}
+
+			resolved, err := t.resolver.Resolve(identifier)
+			if stderrors.Is(err, tenancy.ErrTenantNotFound) {
+				response.NotFoundError(c, "tenant", identifier)
+				c.Abort()
+				return
+			}
+			if err != nil {
+				response.ServiceUnavailableError(c, "tenancy", localize(c, "tenant.resolve_failed"))
+				c.Abort()
+				return
+			}
+
+			if tenant == nil {
+				tenant, source = resolved, candidate
+				continue
+			}
+			if resolved.ID != tenant.ID {
+				response.ForbiddenError(c, localize(c, "tenant.mismatch", candidate, source))
+				c.Abort()
+				return
+			}
+		}
+
+		if tenant == nil {
+			if t.cfg.Required {
+				response.ValidationError(c, localize(c, "tenant.required"), errors.ErrorDetails{
+					Field:   t.cfg.Header,
+					Message: localize(c, "tenant.required_detail", t.cfg.Header),
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !tenant.IsActive {
+			response.ForbiddenError(c, localize(c, "tenant.inactive"))
+			c.Abort()
+			return
+		}
+
+		c.Set(TenantContextKey, tenant)
+		c.Request = c.Request.WithContext(tenancy.WithTenant(c.Request.Context(), tenant))
+		c.Next()
+	}
+}
+
+// identifier 返回来源中的租户ID或标识，没有时返回空字符串
+func (t *TenantResolver) identifier(c *gin.Context, source string) string {
+	switch source {
+	case TenantSourceHeader:
+		return strings.TrimSpace(c.GetHeader(t.cfg.Header))
+	case TenantSourceSubdomain:
+		return t.subdomain(c.Request.Host)
+	case TenantSourceJWT:
+		if t.jwtManager == nil {
+			return ""
+		}
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			return ""
+		}
+		// 无效的令牌不参与租户解析，由认证中间件拒绝
+		claims, err := t.jwtManager.ValidateTokenWithContext(c.Request.Context(), tokenString)
+		if err != nil {
+			return ""
+		}
+		return claims.TenantID
+	}
+	return ""
+}
+
+// subdomain 返回基础域名下的一级子域名，如基础域名为 example.com 时 acme.example.com 返回 acme
+func (t *TenantResolver) subdomain(host string) string {
+	if t.cfg.BaseDomain == "" {
+		return ""
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(t.cfg.BaseDomain))
+	if !ok || label == "" || strings.Contains(label, ".") || label == "www" {
+		return ""
+	}
+	return label
+}
+
+// isExcluded 判断路径是否不需要解析租户
+func (t *TenantResolver) isExcluded(path string) bool {
+	for _, prefix := range t.cfg.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentTenant 返回 gin 上下文中的租户，未启用多租户或无法确定租户时返回 false
+func CurrentTenant(c *gin.Context) (*models.Tenant, bool) {
+	value, exists := c.Get(TenantContextKey)
+	if !exists {
+		return nil, false
+	}
+	tenant, ok := value.(*models.Tenant)
+	return tenant, ok && tenant != nil
+}
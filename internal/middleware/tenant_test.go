@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+	"go-server/internal/models"
+	"go-server/internal/tenancy"
+	"go-server/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	acmeTenantID   = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	globexTenantID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+)
+
+// stubTenantLookup 内存中的租户查找
+type stubTenantLookup map[string]*models.Tenant
+
+func (s stubTenantLookup) GetByID(id string) (*models.Tenant, error) {
+	for _, tenant := range s {
+		if tenant.ID == id {
+			return tenant, nil
+		}
+	}
+	return nil, tenancy.ErrTenantNotFound
+}
+
+func (s stubTenantLookup) GetBySlug(slug string) (*models.Tenant, error) {
+	if tenant, ok := s[slug]; ok {
+		return tenant, nil
+	}
+	return nil, tenancy.ErrTenantNotFound
+}
+
+func newTenantRouter(cfg config.TenancyConfig, jwtManager *auth.JWTManager) *gin.Engine {
+	lookup := stubTenantLookup{
+		"acme":    {ID: acmeTenantID, Slug: "acme", IsActive: true},
+		"globex":  {ID: globexTenantID, Slug: "globex", IsActive: true},
+		"initech": {ID: "4f1b2c3d-0000-4000-8000-000000000001", Slug: "initech", IsActive: false},
+	}
+	resolver := NewTenantResolver(cfg, tenancy.NewResolver(lookup, time.Minute), jwtManager)
+
+	router := gin.New()
+	router.Use(resolver.Middleware())
+	handler := func(c *gin.Context) {
+		tenant, ok := CurrentTenant(c)
+		if !ok {
+			c.String(http.StatusOK, "")
+			return
+		}
+		// 服务层通过请求上下文读取，应与 gin 上下文中的租户一致
+		if tenancy.ID(c.Request.Context()) != tenant.ID {
+			c.String(http.StatusInternalServerError, "request context tenant mismatch")
+			return
+		}
+		c.String(http.StatusOK, tenant.Slug)
+	}
+	router.GET("/api/v1/users", handler)
+	router.GET("/health", handler)
+	return router
+}
+
+func TestTenantResolver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.TenancyConfig{
+		Enabled:      true,
+		Sources:      []string{TenantSourceJWT, TenantSourceHeader, TenantSourceSubdomain},
+		Header:       "X-Tenant-ID",
+		BaseDomain:   "example.com",
+		ExcludePaths: []string{"/health"},
+	}
+	jwtManager := auth.NewJWTManager("tenant-test-secret", 1)
+	acmeToken, err := jwtManager.GenerateTenantToken("u1", "alice", "alice@example.com", acmeTenantID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		cfg      config.TenancyConfig
+		path     string
+		host     string
+		header   string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "请求头中的标识", header: "acme", wantCode: http.StatusOK, wantBody: "acme"},
+		{name: "请求头中的租户ID", header: globexTenantID, wantCode: http.StatusOK, wantBody: "globex"},
+		{name: "子域名", host: "globex.example.com:8080", wantCode: http.StatusOK, wantBody: "globex"},
+		{name: "忽略 www 子域名", host: "www.example.com", wantCode: http.StatusOK, wantBody: ""},
+		{name: "令牌声明", token: acmeToken, wantCode: http.StatusOK, wantBody: "acme"},
+		{name: "令牌与请求头一致", token: acmeToken, header: "acme", wantCode: http.StatusOK, wantBody: "acme"},
+		{name: "令牌与子域名不一致", token: acmeToken, host: "globex.example.com", wantCode: http.StatusForbidden},
+		{name: "租户不存在", header: "missing", wantCode: http.StatusNotFound},
+		{name: "租户已停用", header: "initech", wantCode: http.StatusForbidden},
+		{
+			name: "要求租户时缺少租户", wantCode: http.StatusBadRequest,
+			cfg: func() config.TenancyConfig { required := cfg; required.Required = true; return required }(),
+		},
+		{name: "排除的路径不解析租户", path: "/health", header: "missing", wantCode: http.StatusOK, wantBody: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routerCfg := cfg
+			if tt.cfg.Enabled {
+				routerCfg = tt.cfg
+			}
+			router := newTenantRouter(routerCfg, jwtManager)
+
+			path := tt.path
+			if path == "" {
+				path = "/api/v1/users"
+			}
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestRateLimiter_TenantLimits 测试速率限制按租户计数并使用租户的限制
+func TestRateLimiter_TenantLimits(t *testing.T) {
+	limiter := NewDistributedRateLimiter(RateLimiterConfig{
+		KeyPrefix:             "test",
+		AnonymousRequests:     10,
+		AuthenticatedRequests: 20,
+		WindowDuration:        time.Minute,
+	})
+	defer limiter.Close()
+	settings := limiter.settings.Load()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+	c.Request.RemoteAddr = "192.168.1.100:1234"
+	c.Set("user_id", "user123")
+	assert.Equal(t, 20, limiter.limitFor(c, settings, true))
+
+	c.Set(TenantContextKey, &models.Tenant{ID: acmeTenantID, RateLimit: 500})
+	assert.Equal(t, "tenant:"+acmeTenantID+":user:user123", limiter.getClientID(c))
+	assert.Equal(t, 500, limiter.limitFor(c, settings, true))
+	assert.Equal(t, 10, limiter.limitFor(c, settings, false), "未设置匿名限制时使用全局配置")
+}
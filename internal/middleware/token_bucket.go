@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 速率限制算法
+const (
+	RateLimitAlgorithmSlidingWindow = "sliding_window" // 统计时间窗口内的请求数
+	RateLimitAlgorithmTokenBucket   = "token_bucket"   // 令牌桶，请求按路由的消耗扣除令牌
+)
+
+// tokenBucketScript 原子地按经过的时间补充令牌并扣除本次请求的消耗
+// 返回是否放行、放行前需要等待的毫秒数（-1 表示不会补充令牌）和剩余令牌数（向下取整）
+var tokenBucketScript = redis.NewScript(`
+	local capacity = tonumber(ARGV[1])
+	local rate = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local cost = tonumber(ARGV[4])
+	local ttl = tonumber(ARGV[5])
+
+	local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+	local tokens = tonumber(state[1])
+	local ts = tonumber(state[2])
+	if tokens == nil or ts == nil then
+		tokens = capacity
+		ts = now
+	end
+	if now > ts then
+		tokens = math.min(capacity, tokens + (now - ts) * rate)
+		ts = now
+	end
+
+	local allowed = 0
+	local wait = 0
+	if tokens >= cost then
+		tokens = tokens - cost
+		allowed = 1
+	elseif rate > 0 then
+		wait = math.ceil((cost - tokens) / rate)
+	else
+		wait = -1
+	end
+
+	redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	return {allowed, wait, math.floor(tokens)}
+`)
+
+// MemoryTokenBucket 内存令牌桶（用于 Redis 不可用时的降级）
+type MemoryTokenBucket struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucketState // 客户端标识 -> 令牌桶
+	lastSweep time.Time
+}
+
+// tokenBucketState 单个客户端的令牌桶
+type tokenBucketState struct {
+	tokens   float64
+	updated  time.Time
+	capacity float64
+	rate     float64 // 每秒补充的令牌数
+}
+
+// NewMemoryTokenBucket 创建内存令牌桶
+func NewMemoryTokenBucket() *MemoryTokenBucket {
+	return &MemoryTokenBucket{
+		buckets:   make(map[string]*tokenBucketState),
+		lastSweep: time.Now(),
+	}
+}
+
+// take 从客户端的令牌桶扣除 cost 个令牌，桶容量为 capacity，每个 window 补充 limit 个令牌
+// 返回是否放行、重试等待时间和剩余令牌数
+func (b *MemoryTokenBucket) take(clientID string, capacity, limit int, window time.Duration, cost int) (bool, time.Duration, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	rate := tokenRate(limit, window)
+	b.sweep(now, window)
+
+	bucket, exists := b.buckets[clientID]
+	if !exists {
+		bucket = &tokenBucketState{tokens: float64(capacity), updated: now}
+		b.buckets[clientID] = bucket
+	}
+	bucket.capacity = float64(capacity)
+	bucket.rate = rate
+	bucket.refill(now)
+
+	if bucket.tokens >= float64(cost) {
+		bucket.tokens -= float64(cost)
+		return true, 0, int(bucket.tokens)
+	}
+	if rate <= 0 {
+		return false, window, int(bucket.tokens)
+	}
+	wait := time.Duration(math.Ceil((float64(cost) - bucket.tokens) / rate * float64(time.Second)))
+	return false, wait, int(bucket.tokens)
+}
+
+// sweep 每个时间窗口清理一次已经补满的令牌桶，补满的桶与新建的桶等价
+func (b *MemoryTokenBucket) sweep(now time.Time, window time.Duration) {
+	if now.Sub(b.lastSweep) < window {
+		return
+	}
+	b.lastSweep = now
+	for clientID, bucket := range b.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.capacity {
+			delete(b.buckets, clientID)
+		}
+	}
+}
+
+// refill 按经过的时间补充令牌，不超过容量
+func (s *tokenBucketState) refill(now time.Time) {
+	if elapsed := now.Sub(s.updated); elapsed > 0 {
+		s.tokens = math.Min(s.capacity, s.tokens+elapsed.Seconds()*s.rate)
+		s.updated = now
+	}
+}
+
+// tokenRate 返回每秒补充的令牌数
+func tokenRate(limit int, window time.Duration) float64 {
+	if limit <= 0 || window <= 0 {
+		return 0
+	}
+	return float64(limit) / window.Seconds()
+}
+
+// rateLimitCostsFromConfig 将按路由的请求消耗配置转换为路由模板到令牌数的映射
+func rateLimitCostsFromConfig(costs []config.RateLimitCostConfig) map[string]int {
+	if len(costs) == 0 {
+		return nil
+	}
+	result := make(map[string]int, len(costs))
+	for _, cost := range costs {
+		if cost.Cost > 0 {
+			result[cost.Route] = cost.Cost
+		}
+	}
+	return result
+}
+
+// capacity 返回令牌桶容量；滑动窗口算法下等于限制
+func (s *rateLimitSettings) capacity(limit int) int {
+	if s.algorithm == RateLimitAlgorithmTokenBucket {
+		return limit + s.burst
+	}
+	return limit
+}
+
+// costFor 返回路由每次请求消耗的令牌数，滑动窗口算法下每个请求计 1
+func (s *rateLimitSettings) costFor(route string) int {
+	if s.algorithm != RateLimitAlgorithmTokenBucket {
+		return 1
+	}
+	if cost, ok := s.costs[route]; ok {
+		return cost
+	}
+	return 1
+}
+
+// takeTokensRedis 使用 Redis 令牌桶检查速率限制，返回是否放行、重试等待时间和剩余令牌数
+func (r *DistributedRateLimiter) takeTokensRedis(ctx context.Context, key string, capacity, limit int, window time.Duration, cost int) (bool, time.Duration, int, error) {
+	rate := tokenRate(limit, window) / 1000 // 每毫秒补充的令牌数
+	ttl := window + time.Second
+	if rate > 0 {
+		// 桶从空到满所需的时间之后，过期与补满等价
+		if fill := time.Duration(float64(capacity)/rate) * time.Millisecond; fill+time.Second > ttl {
+			ttl = fill + time.Second
+		}
+	}
+
+	result, err := tokenBucketScript.Run(ctx, r.redis, []string{key},
+		capacity, strconv.FormatFloat(rate, 'g', -1, 64), time.Now().UnixMilli(), cost, ttl.Milliseconds()).Result()
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	res, ok := result.([]interface{})
+	if !ok || len(res) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected token bucket result %v", result)
+	}
+	allowed := res[0].(int64) == 1
+	wait := time.Duration(res[1].(int64)) * time.Millisecond
+	if wait < 0 {
+		wait = window
+	}
+	return allowed, wait, int(res[2].(int64)), nil
+}
+
+// takeTokens 按令牌桶检查请求，返回是否放行、重试等待时间和已消耗的令牌数（容量减去剩余令牌）
+// Redis 不可用时使用内存令牌桶
+func (r *DistributedRateLimiter) takeTokens(ctx context.Context, settings *rateLimitSettings, clientID string, limit, cost int) (bool, time.Duration, int) {
+	capacity := settings.capacity(limit)
+
+	// 令牌桶使用单独的键，与滑动窗口的有序集合互不影响，支持热重载切换算法
+	key := fmt.Sprintf("%s:tb:%s", r.config.KeyPrefix, clientID)
+	allowed, retryAfter, remaining, err := r.takeTokensRedis(ctx, key, capacity, limit, settings.window, cost)
+	if err != nil {
+		if !r.config.FallbackEnabled {
+			return true, 0, 0
+		}
+		allowed, retryAfter, remaining = r.buckets.take(clientID, capacity, limit, settings.window, cost)
+	}
+
+	used := capacity - remaining
+	if used < 0 {
+		used = 0
+	}
+	return allowed, retryAfter, used
+}
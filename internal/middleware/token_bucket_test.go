@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-server/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTokenBucket_Take(t *testing.T) {
+	bucket := NewMemoryTokenBucket()
+
+	// 容量 3，每分钟补充 60 个令牌（每秒 1 个）
+	allowed, retryAfter, remaining := bucket.take("client", 3, 60, time.Minute, 2)
+	assert.True(t, allowed)
+	assert.Zero(t, retryAfter)
+	assert.Equal(t, 1, remaining)
+
+	allowed, retryAfter, remaining = bucket.take("client", 3, 60, time.Minute, 2)
+	assert.False(t, allowed)
+	assert.InDelta(t, time.Second, retryAfter, float64(50*time.Millisecond), "one missing token refills in about a second")
+	assert.Equal(t, 1, remaining)
+
+	allowed, _, _ = bucket.take("other", 3, 60, time.Minute, 3)
+	assert.True(t, allowed, "buckets are per client")
+
+	allowed, retryAfter, _ = bucket.take("blocked", 0, 0, time.Minute, 1)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter, "a zero limit never refills")
+}
+
+func TestRateLimiterMiddleware_TokenBucketCosts(t *testing.T) {
+	limiter := NewRateLimiterFromConfig(&config.Config{
+		RateLimit: config.RateLimitConfig{
+			Enabled:   true,
+			Requests:  10,
+			Window:    "1h",
+			RedisKey:  "test_rate_limit_token_bucket",
+			Algorithm: RateLimitAlgorithmTokenBucket,
+			Burst:     5,
+			Costs:     []config.RateLimitCostConfig{{Route: "/search", Cost: 5}},
+		},
+		Redis: config.RedisConfig{Host: "127.0.0.1", Port: 1, PoolSize: 1},
+	})
+	defer limiter.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/search", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.GET("/read", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.168.8.8:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 桶容量为限制加突发：10 + 5
+	for i, remaining := range []string{"10", "5"} {
+		w := serve("/search")
+		require.Equal(t, http.StatusOK, w.Code, "search %d", i)
+		assert.Equal(t, "15", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	w := serve("/read")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4", w.Header().Get("X-RateLimit-Remaining"), "unlisted routes cost one token")
+
+	w = serve("/search")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "an expensive route is throttled before cheap ones")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, http.StatusOK, serve("/read").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, serve("/read").Code)
+
+	configuration := limiter.Metrics().GetStats().Configuration
+	assert.Equal(t, RateLimitAlgorithmTokenBucket, configuration.Algorithm)
+	assert.Equal(t, 5, configuration.Burst)
+}
+
+func TestDistributedRateLimiter_ValidateTokenBucketConfig(t *testing.T) {
+	limiter := newModeTestLimiter(RateLimitModeEnforce, 10)
+	defer limiter.Close()
+
+	newConfig := &config.Config{RateLimit: config.RateLimitConfig{Enabled: true, Requests: 10, Window: "1m", Algorithm: "leaky_bucket"}}
+	assert.Error(t, limiter.ValidateConfig(newConfig))
+
+	newConfig.RateLimit.Algorithm = RateLimitAlgorithmTokenBucket
+	newConfig.RateLimit.Costs = []config.RateLimitCostConfig{{Route: "/search", Cost: 0}}
+	assert.Error(t, limiter.ValidateConfig(newConfig))
+
+	newConfig.RateLimit.Costs[0].Cost = 3
+	require.NoError(t, limiter.ValidateConfig(newConfig))
+	require.NoError(t, limiter.ApplyConfig(nil, newConfig))
+	settings := limiter.settings.Load()
+	assert.Equal(t, 3, settings.costFor("/search"))
+	assert.Equal(t, 1, settings.costFor("/other"))
+}
@@ -298,7 +298,7 @@ func ValidationMiddleware(config ValidationConfig) gin.HandlerFunc {
 		// 检查请求内容类型
 		contentType := c.GetHeader("Content-Type")
 		if !strings.Contains(contentType, "application/json") && c.Request.Method != "GET" {
-			response.BadRequest(c, "Content-Type must be application/json")
+			response.BadRequest(c, localize(c, "validation.json_required"))
 			c.Abort()
 			return
 		}
@@ -316,9 +316,9 @@ func ValidationMiddleware(config ValidationConfig) gin.HandlerFunc {
 		} else {
 			// POST/PUT/DELETE请求使用JSON body
 			if err := c.ShouldBindJSON(&data); err != nil {
-				response.ValidationError(c, "Invalid JSON format", errors.ErrorDetails{
+				response.ValidationError(c, localize(c, "validation.invalid_json"), errors.ErrorDetails{
 					Field:      "body",
-					Message:    "Invalid JSON format: " + err.Error(),
+					Message:    localize(c, "validation.invalid_json") + ": " + err.Error(),
 					Value:      nil,
 					Constraint: "valid_json",
 				})
@@ -348,7 +348,7 @@ func ValidationMiddleware(config ValidationConfig) gin.HandlerFunc {
 
 		// 如果有验证错误，返回错误响应
 		if len(validationErrors) > 0 {
-			response.ValidationError(c, "Validation failed", validationErrors...)
+			response.ValidationError(c, localize(c, "validation.failed"), validationErrors...)
 			c.Abort()
 			return
 		}
@@ -550,7 +550,7 @@ func StructValidation(model interface{}) gin.HandlerFunc {
 		// 检查请求内容类型
 		contentType := c.GetHeader("Content-Type")
 		if !strings.Contains(contentType, "application/json") && c.Request.Method != "GET" {
-			response.BadRequest(c, "Content-Type must be application/json")
+			response.BadRequest(c, localize(c, "validation.json_required"))
 			c.Abort()
 			return
 		}
@@ -566,9 +566,9 @@ func StructValidation(model interface{}) gin.HandlerFunc {
 
 		// 绑定请求数据
 		if err := c.ShouldBindJSON(newModel); err != nil {
-			response.ValidationError(c, "Invalid request format", errors.ErrorDetails{
+			response.ValidationError(c, localize(c, "validation.invalid_format"), errors.ErrorDetails{
 				Field:      "body",
-				Message:    "Invalid JSON format: " + err.Error(),
+				Message:    localize(c, "validation.invalid_json") + ": " + err.Error(),
 				Value:      nil,
 				Constraint: "valid_json",
 			})
@@ -578,7 +578,7 @@ func StructValidation(model interface{}) gin.HandlerFunc {
 
 		// 使用Gin的内置验证
 		if err := c.ShouldBind(newModel); err != nil {
-			response.ValidationError(c, "Validation failed", errors.ErrorDetails{
+			response.ValidationError(c, localize(c, "validation.failed"), errors.ErrorDetails{
 				Field:      "validation",
 				Message:    err.Error(),
 				Value:      newModel,
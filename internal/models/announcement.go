@@ -0,0 +1,53 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// 公告级别
+const (
+	AnnouncementLevelInfo     = "info"
+	AnnouncementLevelWarning  = "warning"
+	AnnouncementLevelCritical = "critical"
+)
+
+// Announcement 管理员发布的系统公告，在 starts_at 和 ends_at 之间对匹配角色和租户的用户可见
+// 公告按 TargetTenantID 显式定向，不受租户插件的自动过滤，由仓库跨租户读写
+type Announcement struct {
+	ID             string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"` // 公告ID
+	Title          string     `json:"title" gorm:"type:varchar(200);not null" example:"计划维护"`                                                   // 标题
+	Body           string     `json:"body" gorm:"type:text;not null" example:"系统将于今晚 23:00 至 23:30 维护，期间可能无法登录。"`                               // 正文
+	Level          string     `json:"level" gorm:"type:varchar(16);not null;default:info" example:"warning"`                                    // 级别（info、warning、critical）
+	Roles          []string   `json:"roles" gorm:"serializer:json;type:jsonb;not null" example:"admin"`                                         // 可见的角色，为空时对所有用户（包括未登录用户）可见
+	TargetTenantID *string    `json:"tenant_id,omitempty" gorm:"column:target_tenant_id;type:uuid;index"`                                       // 可见的租户ID，为空时对所有租户可见
+	StartsAt       *time.Time `json:"starts_at,omitempty"`                                                                                      // 生效时间，为空时立即生效
+	EndsAt         *time.Time `json:"ends_at,omitempty" gorm:"index"`                                                                           // 过期时间，为空时一直有效
+	CreatedBy      string     `json:"created_by" gorm:"type:uuid"`                                                                              // 创建者ID
+	CreatedAt      time.Time  `json:"created_at"`                                                                                               // 创建时间
+	UpdatedAt      time.Time  `json:"updated_at"`                                                                                               // 更新时间
+}
+
+// TableName 返回Announcement模型的表名
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// ActiveAt 判断公告在指定时间是否生效
+func (a *Announcement) ActiveAt(t time.Time) bool {
+	return (a.StartsAt == nil || !t.Before(*a.StartsAt)) && (a.EndsAt == nil || t.Before(*a.EndsAt))
+}
+
+// VisibleTo 判断公告是否对拥有指定角色、属于指定租户的用户可见
+// tenantID 为空表示未启用多租户或请求不属于任何租户，此时只能看到不限租户的公告
+func (a *Announcement) VisibleTo(roles []string, tenantID string) bool {
+	if a.TargetTenantID != nil && *a.TargetTenantID != tenantID {
+		return false
+	}
+	if len(a.Roles) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(a.Roles, func(role string) bool {
+		return slices.Contains(roles, role)
+	})
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// 备份的触发方式
+const (
+	BackupTriggerManual    = "manual"    // 管理员通过接口创建
+	BackupTriggerScheduled = "scheduled" // 定期自动创建
+)
+
+// 备份状态
+const (
+	BackupStatusPending   = "pending"
+	BackupStatusRunning   = "running"
+	BackupStatusCompleted = "completed"
+	BackupStatusFailed    = "failed"
+)
+
+// Backup 数据库的逻辑备份（pg_dump 自定义格式），加密后保存在对象存储中
+// backups 表本身不包含在备份中，恢复备份不会改变备份列表
+type Backup struct {
+	ID          string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"`              // 备份ID
+	Trigger     string     `json:"trigger" gorm:"type:varchar(16);not null;index" example:"manual"`                                                       // 触发方式（manual、scheduled）
+	Status      string     `json:"status" gorm:"type:varchar(16);not null;default:pending" example:"completed"`                                           // 状态（pending、running、completed、failed）
+	Size        int64      `json:"size" example:"10485760"`                                                                                               // 备份文件大小（字节，加密前），完成后填写
+	Checksum    string     `json:"checksum,omitempty" gorm:"type:varchar(64)" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"` // 备份文件（加密前）的 SHA-256，完成后填写
+	ObjectKey   string     `json:"-" gorm:"type:varchar(255)"`                                                                                            // 对象存储键
+	Error       string     `json:"error,omitempty" gorm:"type:text"`                                                                                      // 失败原因
+	CreatedBy   *string    `json:"created_by,omitempty" gorm:"type:uuid"`                                                                                 // 创建者ID，定期备份为空
+	CreatedAt   time.Time  `json:"created_at"`                                                                                                            // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at"`                                                                                                            // 更新时间
+	CompletedAt *time.Time `json:"completed_at,omitempty"`                                                                                                // 完成或失败时间
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"index"`                                                                                     // 备份删除时间，完成后填写
+}
+
+// TableName 返回Backup模型的表名
+func (Backup) TableName() string {
+	return "backups"
+}
+
+// Finished 判断备份任务是否已结束
+func (b *Backup) Finished() bool {
+	return b.Status == BackupStatusCompleted || b.Status == BackupStatusFailed
+}
+
+// 恢复状态
+const (
+	RestoreStateIdle      = "idle"      // 本实例没有执行过恢复
+	RestoreStateRunning   = "running"   // 正在恢复
+	RestoreStateCompleted = "completed" // 恢复完成
+	RestoreStateFailed    = "failed"    // 恢复失败，pg_restore 在单个事务中执行，数据库保持恢复前的状态
+)
+
+// RestoreStatus 本实例最近一次恢复的状态
+type RestoreStatus struct {
+	State       string     `json:"state" example:"running"`                                            // 状态（idle、running、completed、failed）
+	BackupID    string     `json:"backup_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // 恢复的备份ID
+	RequestedBy string     `json:"requested_by,omitempty"`                                             // 发起恢复的管理员ID
+	StartedAt   *time.Time `json:"started_at,omitempty"`                                               // 开始时间
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`                                              // 结束时间
+	Error       string     `json:"error,omitempty"`                                                    // 失败原因
+}
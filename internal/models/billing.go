@@ -0,0 +1,81 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// 订阅状态，与 Stripe 订阅的 status 相同
+const (
+	SubscriptionStatusTrialing   = "trialing"
+	SubscriptionStatusActive     = "active"
+	SubscriptionStatusPastDue    = "past_due"
+	SubscriptionStatusIncomplete = "incomplete"
+	SubscriptionStatusUnpaid     = "unpaid"
+	SubscriptionStatusPaused     = "paused"
+	SubscriptionStatusCanceled   = "canceled"
+)
+
+// Plan 订阅套餐，由配置 billing.plans 定义
+type Plan struct {
+	ID             string      `json:"id" example:"pro"`                                   // 套餐标识
+	Name           string      `json:"name" example:"专业版"`                                 // 套餐名称
+	Entitlements   []string    `json:"entitlements" example:"advanced_search,data_export"` // 套餐包含的权益
+	RateLimit      int         `json:"rate_limit" example:"600"`                           // 限流窗口内的请求数，0 表示使用租户或全局配置
+	Quotas         []PlanQuota `json:"quotas"`                                             // 套餐的用量配额，覆盖 quota.limits 中的同名配额
+	StripePriceIDs []string    `json:"-"`                                                  // 对应的 Stripe 价格ID
+}
+
+// PlanQuota 套餐的每月用量配额
+type PlanQuota struct {
+	Name string `json:"name" example:"api_calls"` // 配额名称
+	Soft int64  `json:"soft" example:"80000"`     // 软限制，0 表示没有软限制
+	Hard int64  `json:"hard" example:"100000"`    // 硬限制，0 表示不限制
+}
+
+// HasEntitlement 判断套餐是否包含权益
+func (p *Plan) HasEntitlement(entitlement string) bool {
+	return slices.Contains(p.Entitlements, entitlement)
+}
+
+// Subscription 用户的订阅，由 Stripe webhook 同步，每个用户最多一条
+type Subscription struct {
+	ID                   string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"` // 订阅ID
+	UserID               string     `json:"user_id" gorm:"type:uuid;uniqueIndex;not null" example:"123e4567-e89b-12d3-a456-426614174000"`             // 用户ID
+	PlanID               string     `json:"plan_id" gorm:"type:varchar(50);not null" example:"pro"`                                                   // 套餐标识
+	Status               string     `json:"status" gorm:"type:varchar(20);not null" example:"active"`                                                 // 订阅状态
+	StripeCustomerID     string     `json:"-" gorm:"type:varchar(255);index"`                                                                         // Stripe 客户ID
+	StripeSubscriptionID string     `json:"-" gorm:"type:varchar(255);uniqueIndex"`                                                                   // Stripe 订阅ID
+	CurrentPeriodEnd     *time.Time `json:"current_period_end,omitempty" example:"2024-07-01T00:00:00Z"`                                              // 当前计费周期的结束时间
+	CancelAtPeriodEnd    bool       `json:"cancel_at_period_end" gorm:"not null;default:false" example:"false"`                                       // 是否在周期结束时取消
+	LastEventAt          time.Time  `json:"-"`                                                                                                        // 最后应用的 Stripe 事件的创建时间，用于丢弃乱序到达的旧事件
+	CreatedAt            time.Time  `json:"created_at"`                                                                                               // 创建时间
+	UpdatedAt            time.Time  `json:"updated_at"`                                                                                               // 更新时间
+}
+
+// TableName 返回Subscription模型的表名
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// Entitled 判断订阅是否让用户享有套餐权益，逾期付款（past_due）期间保留权益，由 Stripe 的催款流程决定何时取消
+func (s *Subscription) Entitled() bool {
+	switch s.Status {
+	case SubscriptionStatusActive, SubscriptionStatusTrialing, SubscriptionStatusPastDue:
+		return true
+	default:
+		return false
+	}
+}
+
+// SubscriptionResponse 当前用户的套餐和订阅
+type SubscriptionResponse struct {
+	Plan         *Plan         `json:"plan"`                   // 当前生效的套餐，没有有效订阅时为默认套餐
+	Subscription *Subscription `json:"subscription,omitempty"` // 订阅，从未订阅时为空
+}
+
+// StripeWebhookResponse Stripe webhook 的处理结果
+type StripeWebhookResponse struct {
+	EventID string `json:"event_id" example:"evt_1NG8Du2eZvKYlo2CUI79vXWy"` // 事件ID
+	Outcome string `json:"outcome" example:"applied"`                       // applied 已同步；ignored 不处理的事件类型或无法关联到用户；stale 比已同步的事件更旧
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// 导出文件格式
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+	ExportFormatXLSX = "xlsx"
+)
+
+// 导出任务状态
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportFilter 导出用户的筛选条件，与管理员用户列表的筛选条件相同
+type ExportFilter struct {
+	Query    string `json:"query,omitempty" example:"alice"` // 按用户名、邮箱或姓名模糊匹配
+	IsActive *bool  `json:"is_active,omitempty"`             // 按激活状态筛选
+	IsAdmin  *bool  `json:"is_admin,omitempty"`              // 按管理员角色筛选
+}
+
+// Export 后台生成的用户数据导出文件，记录任务进度和存储位置
+type Export struct {
+	ID            string       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"` // 导出任务ID
+	Format        string       `json:"format" gorm:"type:varchar(8);not null" example:"csv"`                                                     // 文件格式（csv、json、xlsx）
+	Status        string       `json:"status" gorm:"type:varchar(16);not null;default:pending" example:"running"`                                // 状态（pending、running、completed、failed）
+	Filter        ExportFilter `json:"filter" gorm:"serializer:json;type:jsonb;not null"`                                                        // 筛选条件
+	TotalRows     int64        `json:"total_rows" example:"120000"`                                                                              // 开始时匹配的用户数
+	ProcessedRows int64        `json:"processed_rows" example:"45000"`                                                                           // 已写入的用户数
+	Size          int64        `json:"size" example:"10485760"`                                                                                  // 文件大小（字节），完成后填写
+	ObjectKey     string       `json:"-" gorm:"type:varchar(255)"`                                                                               // 对象存储键
+	Error         string       `json:"error,omitempty" gorm:"type:text"`                                                                         // 失败原因
+	TenantID      *string      `json:"tenant_id,omitempty" gorm:"type:uuid;index"`                                                               // 所属租户ID，未启用多租户时为空
+	CreatedBy     string       `json:"created_by" gorm:"type:uuid"`                                                                              // 创建者ID
+	CreatedAt     time.Time    `json:"created_at"`                                                                                               // 创建时间
+	UpdatedAt     time.Time    `json:"updated_at"`                                                                                               // 更新时间
+	CompletedAt   *time.Time   `json:"completed_at,omitempty"`                                                                                   // 完成或失败时间
+	ExpiresAt     *time.Time   `json:"expires_at,omitempty" gorm:"index"`                                                                        // 文件删除时间，完成后填写
+	DownloadURL   string       `json:"download_url,omitempty" gorm:"-"`                                                                          // 带签名的下载地址，完成后每次查询重新签发
+}
+
+// TableName 返回Export模型的表名
+func (Export) TableName() string {
+	return "exports"
+}
+
+// Finished 判断导出任务是否已结束
+func (e *Export) Finished() bool {
+	return e.Status == ExportStatusCompleted || e.Status == ExportStatusFailed
+}
@@ -0,0 +1,33 @@
+package models
+
+// 导入文件格式
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// 导入行错误代码
+const (
+	ImportErrorInvalidRow    = "INVALID_ROW"       // 行无法解析，如 JSON 字段类型错误
+	ImportErrorValidation    = "VALIDATION_FAILED" // 字段未通过校验
+	ImportErrorDuplicateRow  = "DUPLICATE_IN_FILE" // 邮箱或用户名与文件中之前的行重复
+	ImportErrorAlreadyExists = "ALREADY_EXISTS"    // 邮箱或用户名已被现有用户使用
+	ImportErrorInsertFailed  = "INSERT_FAILED"     // 写入数据库失败，同一批次的行均未导入
+)
+
+// ImportRowError 导入文件中一行的错误，同一行可以有多个字段错误
+type ImportRowError struct {
+	Row     int    `json:"row" example:"3"`                                             // 数据行号，从 1 开始，不含 CSV 表头
+	Field   string `json:"field,omitempty" example:"email"`                             // 出错的字段，整行错误时为空
+	Code    string `json:"code" example:"ALREADY_EXISTS"`                               // 错误代码
+	Message string `json:"message" example:"email is already used by an existing user"` // 错误描述
+}
+
+// ImportReport 批量导入用户的结果，试运行时只校验不写入
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run" example:"true"`   // 是否为试运行
+	TotalRows int              `json:"total_rows" example:"120"` // 文件中的数据行数
+	ValidRows int              `json:"valid_rows" example:"117"` // 通过校验且没有重复的行数
+	Created   int              `json:"created" example:"0"`      // 已创建的用户数，试运行时为 0
+	Errors    []ImportRowError `json:"errors"`                   // 各行的错误，按行号排序
+}
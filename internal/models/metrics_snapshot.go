@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// MetricsSnapshot 一个实例在一个时间桶内的指标增量，进程重启后计数器归零，历史数据仍可查询
+// 原始快照的 Resolution 为采集间隔，降采样后的汇总快照为汇总间隔（秒）
+type MetricsSnapshot struct {
+	ID                 uint64    `json:"-" gorm:"primaryKey;autoIncrement"`                                                                                                               // 自增ID
+	Instance           string    `json:"instance" gorm:"type:varchar(255);not null;uniqueIndex:idx_metrics_snapshots_bucket,priority:1"`                                                  // 实例名称（主机名）
+	Resolution         int       `json:"resolution" gorm:"not null;uniqueIndex:idx_metrics_snapshots_bucket,priority:2;index:idx_metrics_snapshots_resolution_bucket_start,priority:1"`   // 时间桶长度（秒）
+	BucketStart        time.Time `json:"bucket_start" gorm:"not null;uniqueIndex:idx_metrics_snapshots_bucket,priority:3;index:idx_metrics_snapshots_resolution_bucket_start,priority:2"` // 时间桶开始时间
+	HTTPRequests       int64     `json:"http_requests" gorm:"column:http_requests;not null;default:0"`                                                                                    // HTTP 请求数
+	HTTPServerErrors   int64     `json:"http_server_errors" gorm:"column:http_server_errors;not null;default:0"`                                                                          // 状态码 5xx 的请求数
+	HTTPDurationMicros int64     `json:"http_duration_us" gorm:"column:http_duration_us;not null;default:0"`                                                                              // 请求耗时总和（微秒）
+	HTTPInFlight       int64     `json:"http_in_flight" gorm:"column:http_in_flight;not null;default:0"`                                                                                  // 采集时处理中的请求数，汇总快照为桶内最大值
+	RateLimitRequests  int64     `json:"rate_limit_requests" gorm:"not null;default:0"`                                                                                                   // 限流检查的请求数
+	RateLimitThrottled int64     `json:"rate_limit_throttled" gorm:"not null;default:0"`                                                                                                  // 被限流的请求数
+	CacheHits          int64     `json:"cache_hits" gorm:"not null;default:0"`                                                                                                            // 用户缓存命中数
+	CacheMisses        int64     `json:"cache_misses" gorm:"not null;default:0"`                                                                                                          // 用户缓存未命中数
+	CacheLoads         int64     `json:"cache_loads" gorm:"not null;default:0"`                                                                                                           // 未命中后从数据库加载的次数
+	CreatedAt          time.Time `json:"created_at"`                                                                                                                                      // 写入时间
+}
+
+// TableName 返回MetricsSnapshot模型的表名
+func (MetricsSnapshot) TableName() string {
+	return "metrics_snapshots"
+}
@@ -2,6 +2,10 @@ package models
 
 import (
 	"time"
+
+	"go-server/internal/buildinfo"
+	"go-server/internal/metrics"
+	"go-server/pkg/health"
 )
 
 // LoginRequest 登录请求
@@ -12,39 +16,292 @@ type LoginRequest struct {
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
-	Username  string `json:"username" binding:"required,min=3,max=50" example:"johndoe"` // 用户名
-	Email     string `json:"email" binding:"required,email" example:"john@example.com"`  // 邮箱地址
-	Password  string `json:"password" binding:"required,min=6" example:"password123"`    // 密码
-	FirstName string `json:"first_name" binding:"max=50" example:"John"`                 // 名
-	LastName  string `json:"last_name" binding:"max=50" example:"Doe"`                   // 姓
+	Username  string `json:"username" binding:"required,min=3,max=50,username_charset" example:"johndoe"` // 用户名
+	Email     string `json:"email" binding:"required,email" example:"john@example.com"`                   // 邮箱地址
+	Password  string `json:"password" binding:"required,min=6,password_strength" example:"password123"`   // 密码
+	FirstName string `json:"first_name" binding:"max=50" example:"John"`                                  // 名
+	LastName  string `json:"last_name" binding:"max=50" example:"Doe"`                                    // 姓
 }
 
 // UpdateUserRequest 更新用户请求
 type UpdateUserRequest struct {
-	Username  string `json:"username" binding:"omitempty,min=3,max=50"` // 用户名
-	FirstName string `json:"first_name" binding:"omitempty,max=50"`     // 名
-	LastName  string `json:"last_name" binding:"omitempty,max=50"`      // 姓
-	Avatar    string `json:"avatar" binding:"omitempty,url"`            // 头像URL
+	Username  string `json:"username" binding:"omitempty,min=3,max=50,username_charset"` // 用户名
+	FirstName string `json:"first_name" binding:"omitempty,max=50"`                      // 名
+	LastName  string `json:"last_name" binding:"omitempty,max=50"`                       // 姓
+	Avatar    string `json:"avatar" binding:"omitempty,url"`                             // 头像URL
 }
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string   `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // JWT令牌
-	User  SafeUser `json:"user"`                                                    // 安全用户信息
+	Token        string   `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`                   // JWT令牌
+	RefreshToken string   `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 刷新令牌，用于在访问令牌过期后换取新的令牌
+	ExpiresIn    int      `json:"expires_in,omitempty" example:"86400"`                                      // 访问令牌有效期（秒）
+	User         SafeUser `json:"user"`                                                                      // 安全用户信息
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 登录或上次刷新时返回的刷新令牌
+}
+
+// RefreshTokenResponse 刷新令牌响应，原刷新令牌随即失效
+type RefreshTokenResponse struct {
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`         // 新的访问令牌
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 新的刷新令牌
+	ExpiresIn    int    `json:"expires_in" example:"86400"`                                      // 访问令牌有效期（秒）
+}
+
+// LogoutRequest 登出请求，请求体可以省略
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 同时吊销的刷新令牌
+}
+
+// LogoutResponse 登出响应
+type LogoutResponse struct {
+	UserID   string `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"` // 用户ID
+	Username string `json:"username" example:"johndoe"`                             // 用户名
+	Email    string `json:"email" example:"john@example.com"`                       // 邮箱地址
 }
 
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password" binding:"required" example:"oldpassword123"`       // 旧密码
-	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword123"` // 新密码
+	OldPassword string `json:"old_password" binding:"required" example:"oldpassword123"`                         // 旧密码
+	NewPassword string `json:"new_password" binding:"required,min=6,password_strength" example:"newpassword123"` // 新密码
+}
+
+// ChangeEmailRequest 变更邮箱请求，新邮箱验证后才生效
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"john.new@example.com"` // 新邮箱地址
+	Password string `json:"password" binding:"required" example:"password123"`                 // 当前密码
+}
+
+// ConfirmEmailChangeRequest 确认邮箱变更请求
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015"` // 发送到新邮箱的验证令牌
+}
+
+// EmailChangeResponse 邮箱变更申请响应
+type EmailChangeResponse struct {
+	NewEmail  string    `json:"new_email" example:"john.new@example.com"`  // 待验证的新邮箱
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-02T00:00:00Z"` // 验证令牌过期时间
+}
+
+// DeleteAccountRequest 注销账户请求
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"` // 当前密码
+}
+
+// AssignRolesRequest 分配用户角色请求，角色列表为用户的完整角色集合
+type AssignRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,min=1,dive,oneof=user admin" example:"user,admin"` // 角色列表
+}
+
+// PasswordResetResponse 管理员重置密码响应
+type PasswordResetResponse struct {
+	UserID            string `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"` // 用户ID
+	TemporaryPassword string `json:"temporary_password" example:"Xk3v9QmZ2pLr7TfA"`          // 临时密码，只返回一次
+}
+
+// ImpersonationResponse 模拟登录响应
+type ImpersonationResponse struct {
+	Token          string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`        // 以目标用户身份签发的令牌
+	ExpiresAt      time.Time `json:"expires_at" example:"2024-01-01T00:15:00Z"`                      // 令牌过期时间
+	ImpersonatorID string    `json:"impersonator_id" example:"123e4567-e89b-12d3-a456-426614174000"` // 管理员用户ID
+	User           SafeUser  `json:"user"`                                                           // 目标用户
+}
+
+// UpdateLogLevelRequest 修改日志级别请求，module 为空时修改全局级别，level 为空时移除模块的级别覆盖
+type UpdateLogLevelRequest struct {
+	Module string `json:"module" binding:"omitempty,max=64" example:"http"`                     // 模块名称
+	Level  string `json:"level" binding:"omitempty,oneof=debug info warn error" example:"warn"` // 日志级别
+}
+
+// EnableMaintenanceRequest 开启维护请求，已在维护中时更新说明和预计结束时间
+type EnableMaintenanceRequest struct {
+	Message         string `json:"message" binding:"omitempty,max=500" example:"数据库升级中，预计 30 分钟"`        // 展示给用户的说明
+	DurationSeconds int    `json:"duration_seconds" binding:"omitempty,min=0,max=604800" example:"1800"` // 预计时长（秒），0 表示未知
+}
+
+// WarmCacheRequest 缓存预热请求，datasets 为空时预热全部数据集
+type WarmCacheRequest struct {
+	Datasets []string `json:"datasets" binding:"omitempty,dive,required,max=64" example:"users"` // 数据集名称
+}
+
+// CreateFeatureFlagRequest 创建功能开关请求
+type CreateFeatureFlagRequest struct {
+	Key         string   `json:"key" binding:"required,max=64" example:"new_dashboard"`                                         // 开关键，小写字母、数字、下划线、点和连字符
+	Description string   `json:"description" binding:"omitempty,max=255" example:"新版仪表盘"`                                       // 说明
+	Enabled     bool     `json:"enabled" example:"true"`                                                                        // 总开关
+	Percentage  int      `json:"percentage" binding:"min=0,max=100" example:"25"`                                               // 按用户灰度的比例（0-100）
+	Users       []string `json:"users" binding:"omitempty,dive,required,max=64" example:"123e4567-e89b-12d3-a456-426614174000"` // 始终开启的用户ID
+}
+
+// UpdateFeatureFlagRequest 修改功能开关请求，替换开关的完整定义
+type UpdateFeatureFlagRequest struct {
+	Description string   `json:"description" binding:"omitempty,max=255" example:"新版仪表盘"`                                       // 说明
+	Enabled     bool     `json:"enabled" example:"true"`                                                                        // 总开关
+	Percentage  int      `json:"percentage" binding:"min=0,max=100" example:"25"`                                               // 按用户灰度的比例（0-100）
+	Users       []string `json:"users" binding:"omitempty,dive,required,max=64" example:"123e4567-e89b-12d3-a456-426614174000"` // 始终开启的用户ID
+}
+
+// UpdateRateLimitsRequest 修改运行时速率限制请求，替换管理员设置的全部覆盖；字段为 0 或空时沿用配置文件中的值
+type UpdateRateLimitsRequest struct {
+	Anonymous     int                            `json:"anonymous" binding:"min=0" example:"100"`                                            // 匿名用户每个时间窗口的请求数
+	Authenticated int                            `json:"authenticated" binding:"min=0" example:"200"`                                        // 认证用户每个时间窗口的请求数
+	Window        string                         `json:"window" binding:"omitempty,max=16" example:"1m"`                                     // 时间窗口，如 30s、1m
+	Mode          string                         `json:"mode" binding:"omitempty,oneof=enforce warn shadow" example:"enforce"`               // enforce、warn 或 shadow
+	Routes        map[string]RateLimitRouteLimit `json:"routes" binding:"omitempty,max=100,dive,keys,startswith=/,max=255,endkeys,required"` // 按路由模板（如 /api/v1/users/:id）单独计数的限制
+}
+
+// RateLimitRouteLimit 单个路由的速率限制，0 表示该类用户沿用全局限制
+type RateLimitRouteLimit struct {
+	Anonymous     int `json:"anonymous" binding:"min=0" example:"10"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int `json:"authenticated" binding:"min=0" example:"20"` // 认证用户每个时间窗口的请求数
+}
+
+// NotificationListResponse 通知收件箱响应
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`            // 通知，按创建时间倒序
+	Pagination    Pagination     `json:"pagination"`               // 分页信息
+	UnreadCount   int64          `json:"unread_count" example:"3"` // 全部未读通知数
+}
+
+// MarkNotificationsReadResponse 标记全部通知已读响应
+type MarkNotificationsReadResponse struct {
+	Marked int64 `json:"marked" example:"3"` // 本次标记为已读的通知数
+}
+
+// UpdateNotificationPreferencesRequest 修改通知偏好请求，只修改列出的通知类型和渠道
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" binding:"required,min=1,max=100,dive"` // 偏好
+}
+
+// NotificationPreferenceRequest 一种通知类型在一个渠道上的投递偏好
+type NotificationPreferenceRequest struct {
+	Type    string `json:"type" binding:"required,max=64" example:"account.welcome"`               // 通知类型
+	Channel string `json:"channel" binding:"required,oneof=email sms push in_app" example:"email"` // 渠道
+	Enabled bool   `json:"enabled" example:"false"`                                                // 是否投递
+}
+
+// AnnouncementRequest 创建或修改公告请求，修改时替换公告的完整定义
+type AnnouncementRequest struct {
+	Title    string     `json:"title" binding:"required,max=200" example:"计划维护"`                                   // 标题
+	Body     string     `json:"body" binding:"required,max=10000" example:"系统将于今晚 23:00 至 23:30 维护，期间可能无法登录。"`     // 正文
+	Level    string     `json:"level" binding:"omitempty,oneof=info warning critical" example:"warning"`           // 级别，默认 info
+	Roles    []string   `json:"roles" binding:"omitempty,max=10,dive,oneof=user admin" example:"admin"`            // 可见的角色，为空时对所有用户可见
+	TenantID *string    `json:"tenant_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"` // 可见的租户ID，为空时对所有租户可见
+	StartsAt *time.Time `json:"starts_at" example:"2026-01-02T23:00:00Z"`                                          // 生效时间，为空时立即生效
+	EndsAt   *time.Time `json:"ends_at" example:"2026-01-02T23:30:00Z"`                                            // 过期时间，为空时一直有效，须晚于生效时间
+}
+
+// AnnouncementListResponse 公告管理列表响应
+type AnnouncementListResponse struct {
+	Announcements []Announcement `json:"announcements"` // 公告，按创建时间倒序
+	Pagination    Pagination     `json:"pagination"`    // 分页信息
+}
+
+// BackupListResponse 备份列表响应
+type BackupListResponse struct {
+	Backups    []Backup   `json:"backups"`    // 备份，按创建时间倒序
+	Pagination Pagination `json:"pagination"` // 分页信息
+}
+
+// RestoreBackupRequest 恢复备份的请求，confirm 须与路径中的备份ID相同，避免误操作覆盖数据库
+type RestoreBackupRequest struct {
+	Confirm string `json:"confirm" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"` // 要恢复的备份ID
+}
+
+// ExportRequest 创建用户数据导出的请求，筛选条件与管理员用户列表相同
+type ExportRequest struct {
+	Format   string `json:"format" binding:"required,oneof=csv json xlsx" example:"csv"` // 文件格式
+	Query    string `json:"query" binding:"max=100" example:"alice"`                     // 按用户名、邮箱或姓名模糊匹配
+	IsActive *bool  `json:"is_active"`                                                   // 按激活状态筛选
+	IsAdmin  *bool  `json:"is_admin"`                                                    // 按管理员角色筛选
+}
+
+// CacheStatsResponse 缓存统计响应
+type CacheStatsResponse struct {
+	Driver       string                 `json:"driver" example:"redis"`                       // 缓存驱动
+	Capabilities []string               `json:"capabilities" example:"keys,ttl,scoped_clear"` // 驱动支持的可选能力
+	Stats        map[string]interface{} `json:"stats"`                                        // 驱动返回的统计信息
+}
+
+// AdminOverviewResponse 管理后台系统概览，汇总用户、限流、缓存、数据库和构建信息
+// 各部分独立采集，某一部分失败时在该部分的 error 字段中说明，不影响其他部分
+type AdminOverviewResponse struct {
+	GeneratedAt time.Time               `json:"generated_at"`         // 概览生成时间，缓存期内的请求返回相同的时间
+	Uptime      AdminOverviewUptime     `json:"uptime"`               // 进程运行时长
+	Build       buildinfo.Info          `json:"build"`                // 版本和构建信息
+	Users       AdminOverviewUsers      `json:"users"`                // 用户数量
+	RateLimit   *metrics.RateLimitStats `json:"rate_limit,omitempty"` // 请求和限流统计，未启用限流时省略
+	Cache       AdminOverviewComponent  `json:"cache"`                // 缓存状态和统计
+	Database    AdminOverviewComponent  `json:"database"`             // 数据库连接池健康状态
+}
+
+// AdminOverviewUptime 进程运行时长
+type AdminOverviewUptime struct {
+	StartedAt time.Time `json:"started_at"`             // 进程启动时间
+	Seconds   int64     `json:"seconds" example:"3600"` // 运行秒数
+	Human     string    `json:"human" example:"1h0m0s"` // 可读的运行时长
+}
+
+// AdminOverviewUsers 用户数量统计
+type AdminOverviewUsers struct {
+	Total    int64  `json:"total" example:"120"`   // 用户总数（含已停用）
+	Active   int64  `json:"active" example:"110"`  // 已激活用户数
+	Inactive int64  `json:"inactive" example:"10"` // 已停用用户数
+	Admins   int64  `json:"admins" example:"3"`    // 管理员数
+	Error    string `json:"error,omitempty"`       // 统计失败时的错误信息
+}
+
+// AdminOverviewComponent 依赖组件的状态
+type AdminOverviewComponent struct {
+	Status string                 `json:"status" example:"healthy" enums:"healthy,unhealthy,not_configured"` // 组件状态
+	Driver string                 `json:"driver,omitempty" example:"redis"`                                  // 驱动名称
+	Stats  map[string]interface{} `json:"stats,omitempty"`                                                   // 组件返回的统计信息
+	Error  string                 `json:"error,omitempty"`                                                   // 不健康时的错误信息
+}
+
+// MetricsHistoryResponse 指标历史，按时间桶汇总所有实例的快照，供管理后台绘制趋势图
+type MetricsHistoryResponse struct {
+	Resolution int                   `json:"resolution" example:"60"` // 时间桶长度（秒），查询范围超过原始快照保留时长时使用汇总快照
+	From       time.Time             `json:"from"`                    // 查询范围开始时间
+	To         time.Time             `json:"to"`                      // 查询范围结束时间
+	Points     []MetricsHistoryPoint `json:"points"`                  // 按时间排序的数据点，没有快照的时间桶省略
+}
+
+// MetricsHistoryPoint 一个时间桶内所有实例的指标汇总
+type MetricsHistoryPoint struct {
+	Time               time.Time `json:"time"`                               // 时间桶开始时间
+	Instances          int       `json:"instances" example:"2"`              // 有快照的实例数
+	HTTPRequests       int64     `json:"http_requests" example:"1200"`       // HTTP 请求数
+	HTTPServerErrors   int64     `json:"http_server_errors" example:"3"`     // 状态码 5xx 的请求数
+	HTTPAvgLatencyMs   float64   `json:"http_avg_latency_ms" example:"12.5"` // 平均请求耗时（毫秒）
+	HTTPInFlight       int64     `json:"http_in_flight" example:"4"`         // 各实例处理中请求数的最大值之和
+	RateLimitRequests  int64     `json:"rate_limit_requests" example:"1100"` // 限流检查的请求数
+	RateLimitThrottled int64     `json:"rate_limit_throttled" example:"12"`  // 被限流的请求数
+	CacheHits          int64     `json:"cache_hits" example:"900"`           // 用户缓存命中数
+	CacheMisses        int64     `json:"cache_misses" example:"100"`         // 用户缓存未命中数
+	CacheLoads         int64     `json:"cache_loads" example:"95"`           // 未命中后从数据库加载的次数
+	CacheHitRate       float64   `json:"cache_hit_rate" example:"0.9"`       // 用户缓存命中率
+}
+
+// CacheKeysResponse 缓存键列表响应
+type CacheKeysResponse struct {
+	Pattern   string   `json:"pattern" example:"user:*"`        // 匹配模式
+	Keys      []string `json:"keys"`                            // 本页匹配的键（不含应用键前缀），页内按字典序排列
+	Cursor    string   `json:"cursor,omitempty" example:"1792"` // 获取下一页时传入的游标，遍历结束时为空
+	Truncated bool     `json:"truncated" example:"true"`        // 是否还有下一页
 }
 
 // HealthResponse 健康检查响应
 type HealthResponse struct {
-	Status    string            `json:"status" example:"healthy"`                 // 状态
-	Timestamp time.Time         `json:"timestamp" example:"2024-01-01T00:00:00Z"` // 时间戳
-	Version   string            `json:"version" example:"1.0.0"`                  // 版本号
-	Services  map[string]string `json:"services"`                                 // 服务状态
+	Status      string                 `json:"status" example:"healthy"`                 // 状态
+	Timestamp   time.Time              `json:"timestamp" example:"2024-01-01T00:00:00Z"` // 时间戳
+	Version     string                 `json:"version" example:"1.0.0"`                  // 版本号
+	Services    map[string]interface{} `json:"services"`                                 // 服务状态，数据库和缓存为包含 status 的对象
+	System      map[string]interface{} `json:"system"`                                   // 运行时信息
+	Maintenance *health.Maintenance    `json:"maintenance,omitempty"`                    // 维护倒计时，仅在维护期间出现
 }
 
 // ErrorResponse 错误响应
@@ -77,21 +334,32 @@ type EnhancedErrorResponse struct {
 // ErrorCode 机器可读的错误代码
 type ErrorCode string
 
-// 错误代码常量
+// 错误代码常量，与 pkg/errors 的错误目录保持一致，供 Swagger 文档生成枚举值
 const (
-	ErrorCodeValidation         ErrorCode = "VALIDATION_ERROR"    // 验证错误
-	ErrorCodeNotFound           ErrorCode = "NOT_FOUND"           // 未找到
-	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"        // 未授权
-	ErrorCodeForbidden          ErrorCode = "FORBIDDEN"           // 禁止访问
-	ErrorCodeConflict           ErrorCode = "CONFLICT"            // 冲突
-	ErrorCodeRateLimitExceeded  ErrorCode = "RATE_LIMIT_EXCEEDED" // 速率限制超出
-	ErrorCodeInternal           ErrorCode = "INTERNAL_ERROR"      // 内部错误
-	ErrorCodeDatabase           ErrorCode = "DATABASE_ERROR"      // 数据库错误
-	ErrorCodeCache              ErrorCode = "CACHE_ERROR"         // 缓存错误
-	ErrorCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE" // 服务不可用
-	ErrorCodeTimeout            ErrorCode = "TIMEOUT"             // 超时
-	ErrorCodeInvalidToken       ErrorCode = "INVALID_TOKEN"       // 无效令牌
-	ErrorCodeTokenBlacklisted   ErrorCode = "TOKEN_BLACKLISTED"   // 令牌已列入黑名单
+	ErrorCodeValidation          ErrorCode = "VALIDATION_ERROR"          // 验证错误
+	ErrorCodeNotFound            ErrorCode = "NOT_FOUND"                 // 未找到
+	ErrorCodeUnauthorized        ErrorCode = "UNAUTHORIZED"              // 未授权
+	ErrorCodeForbidden           ErrorCode = "FORBIDDEN"                 // 禁止访问
+	ErrorCodeConflict            ErrorCode = "CONFLICT"                  // 冲突
+	ErrorCodeRateLimitExceeded   ErrorCode = "RATE_LIMIT_EXCEEDED"       // 速率限制超出
+	ErrorCodeInternal            ErrorCode = "INTERNAL_ERROR"            // 内部错误
+	ErrorCodeDatabase            ErrorCode = "DATABASE_ERROR"            // 数据库错误
+	ErrorCodeCache               ErrorCode = "CACHE_ERROR"               // 缓存错误
+	ErrorCodeServiceUnavailable  ErrorCode = "SERVICE_UNAVAILABLE"       // 服务不可用
+	ErrorCodeTimeout             ErrorCode = "TIMEOUT"                   // 超时
+	ErrorCodeInvalidToken        ErrorCode = "INVALID_TOKEN"             // 无效令牌
+	ErrorCodeTokenBlacklisted    ErrorCode = "TOKEN_BLACKLISTED"         // 令牌已列入黑名单
+	ErrorCodeBusinessLogic       ErrorCode = "BUSINESS_LOGIC_ERROR"      // 业务逻辑错误
+	ErrorCodeQuotaExceeded       ErrorCode = "QUOTA_EXCEEDED"            // 配额超出
+	ErrorCodeMaintenance         ErrorCode = "MAINTENANCE_MODE"          // 维护模式
+	ErrorCodeThirdPartyService   ErrorCode = "THIRD_PARTY_SERVICE_ERROR" // 第三方服务错误
+	ErrorCodeConfiguration       ErrorCode = "CONFIGURATION_ERROR"       // 配置错误
+	ErrorCodeDependency          ErrorCode = "DEPENDENCY_ERROR"          // 依赖错误
+	ErrorCodeSecurity            ErrorCode = "SECURITY_ERROR"            // 安全错误
+	ErrorCodeDataIntegrity       ErrorCode = "DATA_INTEGRITY_ERROR"      // 数据完整性错误
+	ErrorCodePayloadTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"         // 请求体过大
+	ErrorCodePreconditionFailed  ErrorCode = "PRECONDITION_FAILED"       // 前置条件失败
+	ErrorCodeEntitlementRequired ErrorCode = "ENTITLEMENT_REQUIRED"      // 需要升级套餐
 )
 
 // FieldValidationError 字段级验证错误详细信息
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification 站内信，由通知服务的站内渠道写入，用户在通知收件箱中查看
+type Notification struct {
+	ID        string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"` // 通知ID
+	UserID    string     `json:"-" gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1"`                              // 接收者ID
+	Type      string     `json:"type" gorm:"type:varchar(64);not null" example:"account.password_changed"`                                 // 通知类型
+	Subject   string     `json:"subject" gorm:"type:varchar(255);not null" example:"密码已修改"`                                                // 标题
+	Body      string     `json:"body" gorm:"type:text;not null" example:"你的账号密码刚刚被修改。"`                                                    // 正文
+	ReadAt    *time.Time `json:"read_at" gorm:"index"`                                                                                     // 已读时间，未读时为 null
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_notifications_user_created,priority:2"`                                        // 创建时间
+}
+
+// TableName 返回Notification模型的表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference 用户对一种通知类型在一个渠道上的投递偏好，没有记录时使用通知类型的默认渠道
+type NotificationPreference struct {
+	UserID    string    `json:"-" gorm:"type:uuid;primaryKey"`                                     // 用户ID
+	Type      string    `json:"type" gorm:"type:varchar(64);primaryKey" example:"account.welcome"` // 通知类型
+	Channel   string    `json:"channel" gorm:"type:varchar(32);primaryKey" example:"email"`        // 渠道（email、sms、push、in_app）
+	Enabled   bool      `json:"enabled" gorm:"not null" example:"true"`                            // 是否投递
+	UpdatedAt time.Time `json:"-"`                                                                 // 更新时间
+}
+
+// TableName 返回NotificationPreference模型的表名
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
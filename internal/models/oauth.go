@@ -0,0 +1,121 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// OpenID Connect 支持的范围
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// OAuthClient 在本服务注册的 OpenID Connect 客户端（依赖方）
+// 机密客户端在令牌接口使用客户端密钥认证，公开客户端（单页应用、移动应用）只依靠 PKCE
+type OAuthClient struct {
+	ID           string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"123e4567-e89b-12d3-a456-426614174000"` // 客户端ID（client_id）
+	Name         string    `json:"name" gorm:"type:varchar(100);not null" example:"内部工单系统"`                                                  // 名称，显示在授权同意页面
+	SecretHash   string    `json:"-" gorm:"type:varchar(64)"`                                                                                // 客户端密钥的 SHA-256，公开客户端为空
+	Confidential bool      `json:"confidential" gorm:"not null;default:false" example:"true"`                                                // 是否为机密客户端
+	RedirectURIs []string  `json:"redirect_uris" gorm:"serializer:json;type:jsonb;not null" example:"https://tickets.example.com/callback"`  // 允许的回调地址，须完全匹配
+	CreatedBy    string    `json:"created_by" gorm:"type:uuid"`                                                                              // 创建者ID
+	CreatedAt    time.Time `json:"created_at"`                                                                                               // 创建时间
+	UpdatedAt    time.Time `json:"updated_at"`                                                                                               // 更新时间
+}
+
+// TableName 返回OAuthClient模型的表名
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// AllowsRedirectURI 判断回调地址是否已注册，按字符串完全匹配
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// OAuthClientRequest 注册 OpenID Connect 客户端的请求
+type OAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100" example:"内部工单系统"`                                                      // 名称
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=10,dive,url" example:"https://tickets.example.com/callback"` // 允许的回调地址
+	Confidential bool     `json:"confidential" example:"true"`                                                                           // 是否为机密客户端，机密客户端会签发客户端密钥
+}
+
+// OAuthClientCreatedResponse 注册客户端的响应，客户端密钥只在此时返回一次
+type OAuthClientCreatedResponse struct {
+	Client       OAuthClient `json:"client"`                                                         // 客户端
+	ClientSecret string      `json:"client_secret,omitempty" example:"5Zb0mV7c1JYHk2TQ3xq9aQ0b8l6P"` // 客户端密钥，公开客户端为空
+}
+
+// OAuthAuthorizeRequest 授权请求参数，与 OpenID Connect 授权端点的查询参数相同
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type" binding:"required" example:"code"`                                          // 响应类型，只支持 code
+	ClientID            string `json:"client_id" form:"client_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`                  // 客户端ID
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri" binding:"required" example:"https://tickets.example.com/callback"`            // 回调地址，须已注册
+	Scope               string `json:"scope" form:"scope" binding:"required" example:"openid profile email"`                                          // 以空格分隔的范围，须包含 openid
+	State               string `json:"state" form:"state" example:"af0ifjsldkj"`                                                                      // 原样返回给客户端的状态
+	Nonce               string `json:"nonce" form:"nonce" example:"n-0S6_WzA2Mj"`                                                                     // 写入 ID 令牌的随机数
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge" binding:"required" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"` // PKCE 挑战值
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method" binding:"required" example:"S256"`                          // PKCE 挑战方法，只支持 S256
+}
+
+// OAuthConsentResponse 授权同意页面需要展示的信息
+type OAuthConsentResponse struct {
+	ClientID   string   `json:"client_id" example:"123e4567-e89b-12d3-a456-426614174000"` // 客户端ID
+	ClientName string   `json:"client_name" example:"内部工单系统"`                             // 客户端名称
+	Scopes     []string `json:"scopes" example:"openid,profile,email"`                    // 客户端请求的范围
+}
+
+// OAuthConsentRequest 用户在授权同意页面做出的决定，附带原始授权请求
+type OAuthConsentRequest struct {
+	OAuthAuthorizeRequest
+	Approve bool `json:"approve" example:"true"` // 是否同意授权
+}
+
+// OAuthRedirectResponse 授权决定的结果，客户端应将浏览器重定向到 redirect_to
+type OAuthRedirectResponse struct {
+	RedirectTo string `json:"redirect_to" example:"https://tickets.example.com/callback?code=SplxlOBeZQQYbYS6WxSbIA&state=af0ifjsldkj"` // 带有授权码或错误的回调地址
+}
+
+// OAuthTokenResponse 令牌接口的响应（RFC 6749 第 5.1 节）
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 访问令牌，与登录签发的令牌相同
+	TokenType   string `json:"token_type" example:"Bearer"`                                    // 令牌类型
+	ExpiresIn   int    `json:"expires_in" example:"3600"`                                      // 访问令牌的有效期（秒）
+	IDToken     string `json:"id_token" example:"eyJhbGciOiJSUzI1NiIsImtpZCI6ImtleS0xIn0..."`  // ID 令牌
+	Scope       string `json:"scope" example:"openid profile email"`                           // 授予的范围
+}
+
+// OAuthErrorResponse OAuth 2.0 错误响应（RFC 6749 第 5.2 节）
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_grant"`                                       // 错误码
+	ErrorDescription string `json:"error_description,omitempty" example:"authorization code is invalid"` // 错误描述
+}
+
+// OIDCDiscovery OpenID Connect 发现文档
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer" example:"https://auth.example.com"`                                                   // 签发者
+	AuthorizationEndpoint             string   `json:"authorization_endpoint" example:"https://app.example.com/oauth/consent"`                      // 授权端点（授权同意页面）
+	TokenEndpoint                     string   `json:"token_endpoint" example:"https://auth.example.com/api/v1/oauth/token"`                        // 令牌端点
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint" example:"https://auth.example.com/api/v1/oauth/userinfo"`                  // 用户信息端点
+	JWKSURI                           string   `json:"jwks_uri" example:"https://auth.example.com/.well-known/jwks.json"`                           // 公钥集合
+	ResponseTypesSupported            []string `json:"response_types_supported" example:"code"`                                                     // 支持的响应类型
+	GrantTypesSupported               []string `json:"grant_types_supported" example:"authorization_code"`                                          // 支持的授权类型
+	SubjectTypesSupported             []string `json:"subject_types_supported" example:"public"`                                                    // 支持的主体标识类型
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported" example:"RS256"`                                       // ID 令牌的签名算法
+	ScopesSupported                   []string `json:"scopes_supported" example:"openid,profile,email"`                                             // 支持的范围
+	ClaimsSupported                   []string `json:"claims_supported" example:"sub,name,preferred_username,email"`                                // 支持的声明
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported" example:"client_secret_basic,client_secret_post,none"` // 令牌端点的客户端认证方式
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported" example:"S256"`                                             // 支持的 PKCE 挑战方法
+}
+
+// OIDCUserInfo 用户信息端点的响应
+type OIDCUserInfo struct {
+	Subject           string `json:"sub" example:"123e4567-e89b-12d3-a456-426614174000"` // 用户ID
+	Name              string `json:"name,omitempty" example:"John Doe"`                  // 全名
+	GivenName         string `json:"given_name,omitempty" example:"John"`                // 名
+	FamilyName        string `json:"family_name,omitempty" example:"Doe"`                // 姓
+	PreferredUsername string `json:"preferred_username,omitempty" example:"johndoe"`     // 用户名
+	Email             string `json:"email,omitempty" example:"john@example.com"`         // 邮箱地址
+}
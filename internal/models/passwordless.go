@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// DeviceCodeResponse 设备授权请求的响应（RFC 8628 第 3.2 节），设备显示 user_code 和 verification_uri 并轮询令牌接口
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code" example:"GmRhmhcxhwAzkoEqiMEg_DnyEysNkuNhszIySk9eS"`                        // 设备码，只用于轮询令牌接口，不要展示给用户
+	UserCode                string `json:"user_code" example:"WDJB-MJHT"`                                                          // 用户码，用户在验证页面输入
+	VerificationURI         string `json:"verification_uri" example:"https://app.example.com/device"`                              // 验证页面地址
+	VerificationURIComplete string `json:"verification_uri_complete" example:"https://app.example.com/device?user_code=WDJB-MJHT"` // 带有用户码的验证页面地址，可显示为二维码
+	ExpiresIn               int    `json:"expires_in" example:"600"`                                                               // 设备码和用户码的有效期（秒）
+	Interval                int    `json:"interval" example:"5"`                                                                   // 轮询间隔（秒）
+}
+
+// DeviceTokenRequest 设备轮询令牌的请求
+type DeviceTokenRequest struct {
+	DeviceCode string `json:"device_code" binding:"required" example:"GmRhmhcxhwAzkoEqiMEg_DnyEysNkuNhszIySk9eS"` // 设备码
+}
+
+// DeviceVerifyRequest 已登录用户在验证页面对设备登录做出的决定
+type DeviceVerifyRequest struct {
+	UserCode string `json:"user_code" binding:"required" example:"WDJB-MJHT"` // 设备上显示的用户码，不区分大小写，可省略连字符
+	Approve  bool   `json:"approve" example:"true"`                           // 是否允许设备登录
+}
+
+// MagicLinkRequest 申请登录链接的请求
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"` // 账号邮箱
+}
+
+// MagicLinkResponse 申请登录链接的响应，邮箱不存在时同样返回，不泄露账号是否存在
+type MagicLinkResponse struct {
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T00:15:00Z"` // 登录链接的过期时间
+}
+
+// MagicLinkVerifyRequest 使用登录链接中的令牌登录
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required" example:"qL8R4QIcQ_ZsRqOAbeRfcZhsWtF0hcd1nWNNi7kkmc8"` // 登录链接中的令牌
+}
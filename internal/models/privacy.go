@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PersonalDataArchiveVersion 个人数据导出的格式版本，字段发生不兼容的变化时递增
+const PersonalDataArchiveVersion = 1
+
+// PersonalDataArchive 用户个人数据的完整导出，包含资料、站内信和通知偏好
+type PersonalDataArchive struct {
+	Version                 int                      `json:"version" example:"1"`      // 格式版本
+	GeneratedAt             time.Time                `json:"generated_at"`             // 生成时间
+	User                    SafeUser                 `json:"user"`                     // 用户资料
+	Notifications           []Notification           `json:"notifications"`            // 站内信，按创建时间倒序
+	NotificationPreferences []NotificationPreference `json:"notification_preferences"` // 各通知类型在各渠道上的投递偏好
+}
+
+// ErasureReport 抹除用户个人数据的结果
+type ErasureReport struct {
+	UserID               string    `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"` // 被抹除的用户ID
+	ErasedAt             time.Time `json:"erased_at"`                                              // 抹除时间
+	NotificationsDeleted int64     `json:"notifications_deleted" example:"12"`                     // 删除的站内信数
+	AvatarDeleted        bool      `json:"avatar_deleted" example:"true"`                          // 是否删除了头像文件
+	TokensRevoked        bool      `json:"tokens_revoked" example:"true"`                          // 是否吊销了已签发的令牌
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tenant 租户，多租户部署中隔离用户、缓存和限流的单位
+type Tenant struct {
+	ID                 string         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"` // 租户ID
+	Slug               string         `json:"slug" gorm:"type:varchar(63);uniqueIndex;not null"`         // 租户标识，用作子域名和请求头的值
+	Name               string         `json:"name" gorm:"type:varchar(100);not null"`                    // 租户名称
+	IsActive           bool           `json:"is_active" gorm:"default:true"`                             // 是否激活，停用的租户无法访问
+	RateLimit          int            `json:"rate_limit" gorm:"default:0"`                               // 认证用户在限流窗口内的请求数，0 表示使用全局配置
+	AnonymousRateLimit int            `json:"anonymous_rate_limit" gorm:"default:0"`                     // 匿名用户在限流窗口内的请求数，0 表示使用全局配置
+	CreatedAt          time.Time      `json:"created_at"`                                                // 创建时间
+	UpdatedAt          time.Time      `json:"updated_at"`                                                // 更新时间
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`                                            // 删除时间（软删除）
+}
+
+// TableName 返回Tenant模型的表名
+func (Tenant) TableName() string {
+	return "tenants"
+}
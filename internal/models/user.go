@@ -4,24 +4,29 @@ import (
 	"regexp"
 	"time"
 
+	"go-server/pkg/fieldcrypt"
+
 	"gorm.io/gorm"
 )
 
 // User 系统中的用户模型，包含GORM注解
 type User struct {
-	ID        string         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"` // 用户ID
-	Username  string         `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`    // 用户名
-	Email     string         `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`      // 邮箱地址
-	Password  string         `json:"-" gorm:"type:varchar(255);not null"`                      // 密码（不序列化）
-	FirstName string         `json:"first_name" gorm:"type:varchar(50)"`                        // 名
-	LastName  string         `json:"last_name" gorm:"type:varchar(50)"`                         // 姓
-	Avatar    string         `json:"avatar" gorm:"type:varchar(255)"`                            // 头像URL
-	IsActive  bool           `json:"is_active" gorm:"default:true"`                              // 是否激活
-	IsAdmin   bool           `json:"is_admin" gorm:"default:false"`                             // 是否为管理员
-	LastLogin *time.Time     `json:"last_login"`                                                // 最后登录时间
-	CreatedAt time.Time      `json:"created_at"`                                                // 创建时间
-	UpdatedAt time.Time      `json:"updated_at"`                                                // 更新时间
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                                            // 删除时间（软删除）
+	ID         string         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`        // 用户ID
+	Username   string         `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`            // 用户名
+	Email      string         `json:"email" gorm:"type:text;uniqueIndex;not null;serializer:encrypted"` // 邮箱地址，启用字段加密时保存密文
+	EmailIndex *string        `json:"-" gorm:"type:varchar(64);uniqueIndex"`                            // 邮箱的盲索引，启用字段加密时用于按邮箱查询
+	Password   string         `json:"-" gorm:"type:varchar(255);not null"`                              // 密码（不序列化）
+	FirstName  string         `json:"first_name" gorm:"type:text;serializer:encrypted"`                 // 名，启用字段加密时保存密文
+	LastName   string         `json:"last_name" gorm:"type:text;serializer:encrypted"`                  // 姓，启用字段加密时保存密文
+	Avatar     string         `json:"avatar" gorm:"type:varchar(255)"`                                  // 头像URL
+	AvatarKey  string         `json:"-" gorm:"type:varchar(255)"`                                       // 头像对象存储键
+	TenantID   *string        `json:"tenant_id,omitempty" gorm:"type:uuid;index"`                       // 所属租户ID，未启用多租户时为空
+	IsActive   bool           `json:"is_active" gorm:"default:true"`                                    // 是否激活
+	IsAdmin    bool           `json:"is_admin" gorm:"default:false"`                                    // 是否为管理员
+	LastLogin  *time.Time     `json:"last_login"`                                                       // 最后登录时间
+	CreatedAt  time.Time      `json:"created_at"`                                                       // 创建时间
+	UpdatedAt  time.Time      `json:"updated_at"`                                                       // 更新时间
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`                                                   // 删除时间（软删除）
 }
 
 // TableName 返回User模型的表名
@@ -38,6 +43,18 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave 保存前钩子，启用字段加密时按邮箱计算盲索引
+// 按列更新（Updates 传入 map）时钩子中取不到新的邮箱，须同时写入 email_index
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	if u.Email == "" {
+		return nil
+	}
+	if index, ok := fieldcrypt.BlindIndex(u.Email); ok {
+		tx.Statement.SetColumn("email_index", &index)
+	}
+	return nil
+}
+
 // GetFullName 返回用户全名
 func (u *User) GetFullName() string {
 	if u.FirstName != "" && u.LastName != "" {
@@ -56,6 +73,7 @@ func (u *User) GetFullName() string {
 func (u *User) ToSafeUser() SafeUser {
 	return SafeUser{
 		ID:        u.ID,
+		TenantID:  u.TenantID,
 		Username:  u.Username,
 		Email:     u.Email,
 		FirstName: u.FirstName,
@@ -96,11 +114,17 @@ func (u *User) IsActiveUser() bool {
 	return u.IsActive
 }
 
+// 用户角色，admin 角色对应 IsAdmin
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // GetRoles 返回用户角色列表
 func (u *User) GetRoles() []string {
-	roles := []string{"user"}
+	roles := []string{RoleUser}
 	if u.IsAdmin {
-		roles = append(roles, "admin")
+		roles = append(roles, RoleAdmin)
 	}
 	return roles
 }
@@ -117,15 +141,16 @@ func (e *ValidationError) Error() string {
 
 // SafeUser 不包含敏感信息的用户对象
 type SafeUser struct {
-	ID        string     `json:"id"`         // 用户ID
-	Username  string     `json:"username"`   // 用户名
-	Email     string     `json:"email"`      // 邮箱地址
-	FirstName string     `json:"first_name"` // 名
-	LastName  string     `json:"last_name"`  // 姓
-	Avatar    string     `json:"avatar"`     // 头像URL
-	IsActive  bool       `json:"is_active"`  // 是否激活
-	IsAdmin   bool       `json:"is_admin"`   // 是否为管理员
-	LastLogin *time.Time `json:"last_login"` // 最后登录时间
-	CreatedAt time.Time  `json:"created_at"` // 创建时间
-	UpdatedAt time.Time  `json:"updated_at"` // 更新时间
-}
\ No newline at end of file
+	ID        string     `json:"id"`                  // 用户ID
+	TenantID  *string    `json:"tenant_id,omitempty"` // 所属租户ID
+	Username  string     `json:"username"`            // 用户名
+	Email     string     `json:"email"`               // 邮箱地址
+	FirstName string     `json:"first_name"`          // 名
+	LastName  string     `json:"last_name"`           // 姓
+	Avatar    string     `json:"avatar"`              // 头像URL
+	IsActive  bool       `json:"is_active"`           // 是否激活
+	IsAdmin   bool       `json:"is_admin"`            // 是否为管理员
+	LastLogin *time.Time `json:"last_login"`          // 最后登录时间
+	CreatedAt time.Time  `json:"created_at"`          // 创建时间
+	UpdatedAt time.Time  `json:"updated_at"`          // 更新时间
+}
@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"context"
+
+	"go-server/pkg/events"
+)
+
+// 邮件、短信和推送渠道发布的集成事件类型，外部网关订阅后发送
+const (
+	EventEmail = "notification." + ChannelEmail
+	EventSMS   = "notification." + ChannelSMS
+	EventPush  = "notification." + ChannelPush
+)
+
+// OutboundMessage 外发渠道发布的事件负载
+// 网关按 user_id 查找手机号或设备令牌，本服务不保存这些信息
+type OutboundMessage struct {
+	Recipient Recipient `json:"recipient"`
+	Message   Message   `json:"message"`
+}
+
+// PublishChannel 把消息作为 notification.<渠道> 事件发布到事件总线，由外部网关发送
+type PublishChannel struct {
+	name      string
+	publisher events.Publisher
+}
+
+// NewPublishChannel 创建发布到事件总线的渠道
+func NewPublishChannel(name string, publisher events.Publisher) *PublishChannel {
+	return &PublishChannel{name: name, publisher: publisher}
+}
+
+// NewEmailChannel 创建邮件渠道
+func NewEmailChannel(publisher events.Publisher) *PublishChannel {
+	return NewPublishChannel(ChannelEmail, publisher)
+}
+
+// NewSMSChannel 创建短信渠道
+func NewSMSChannel(publisher events.Publisher) *PublishChannel {
+	return NewPublishChannel(ChannelSMS, publisher)
+}
+
+// NewPushChannel 创建移动推送渠道
+func NewPushChannel(publisher events.Publisher) *PublishChannel {
+	return NewPublishChannel(ChannelPush, publisher)
+}
+
+// Name 返回渠道名称
+func (c *PublishChannel) Name() string {
+	return c.name
+}
+
+// Send 发布 notification.<渠道> 事件
+func (c *PublishChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	return events.PublishEvent(ctx, c.publisher, "notification."+c.name, OutboundMessage{
+		Recipient: recipient,
+		Message:   message,
+	})
+}
@@ -0,0 +1,71 @@
+// Package notifications 多渠道用户通知
+//
+// 业务代码调用 Service.Notify 提交通知，通知作为投递任务发布到事件总线的 notifications.deliver 主题，
+// 由订阅该主题的实例（NATS 队列组或 Kafka 消费者组中的一个）渲染模板并按用户偏好投递到各渠道：
+// 站内信写入数据库，通过 GET /api/v1/users/me/notifications 查看，未读数缓存在 Redis 中；
+// 邮件、短信和推送发布为 notification.<渠道> 集成事件，由外部网关查找手机号或设备令牌后发送。
+//
+// 新的通知类型只需注册模板，新的渠道实现 Channel 接口后通过 RegisterChannel 注册：
+//
+//	templates.Register(notifications.Template{Type: "billing.invoice_paid", Subject: "...", Body: "...", Channels: []string{"email"}})
+//	service.Notify(ctx, userID, "billing.invoice_paid", map[string]string{"amount": "9.90"})
+package notifications
+
+import (
+	"context"
+	"errors"
+)
+
+// 内置渠道
+const (
+	ChannelEmail = "email"  // 邮件，由外部邮件网关发送
+	ChannelSMS   = "sms"    // 短信，由外部短信网关发送
+	ChannelPush  = "push"   // 移动推送，由外部推送网关发送
+	ChannelInApp = "in_app" // 站内信，写入通知收件箱
+)
+
+// AllChannels 内置渠道，也是偏好列表中渠道的排列顺序
+var AllChannels = []string{ChannelEmail, ChannelSMS, ChannelPush, ChannelInApp}
+
+// TopicDeliver 投递任务的主题
+const TopicDeliver = "notifications.deliver"
+
+var (
+	// ErrUnknownType 通知类型没有注册模板
+	ErrUnknownType = errors.New("notifications: unknown notification type")
+	// ErrUnknownChannel 渠道未注册或未启用
+	ErrUnknownChannel = errors.New("notifications: unknown channel")
+	// ErrNotFound 通知不存在或不属于当前用户
+	ErrNotFound = errors.New("notifications: notification not found")
+)
+
+// Recipient 通知接收者
+type Recipient struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// Message 按模板渲染后的通知
+type Message struct {
+	Type    string            `json:"type"`
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"` // 提交通知时的模板数据，供网关构造链接等
+}
+
+// Channel 通知投递渠道
+type Channel interface {
+	// Name 返回渠道名称，如 email
+	Name() string
+
+	// Send 将消息投递给接收者，返回的错误只记录，不会重试
+	Send(ctx context.Context, recipient Recipient, message Message) error
+}
+
+// Job 投递任务，作为事件负载经事件总线传递
+type Job struct {
+	UserID string            `json:"user_id"`
+	Type   string            `json:"type"`
+	Data   map[string]string `json:"data,omitempty"`
+}
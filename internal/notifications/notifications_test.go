@@ -0,0 +1,353 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domainevents "go-server/internal/events"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingChannel 记录收到的消息，err 非空时发送失败
+type recordingChannel struct {
+	name string
+	err  error
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, message)
+	return c.err
+}
+
+func (c *recordingChannel) received() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// newService 创建使用内存仓库和内置模板的通知服务，并创建接收通知的用户 alice
+// queue 为 nil 时不能调用 Notify 和 Start，c 为 nil 时不缓存未读数
+func newService(t *testing.T, queue Queue, c cache.Cache, config Config) (*Service, *models.User) {
+	t.Helper()
+
+	users := repositories.NewMemoryUserRepository()
+	user := factory.User().WithUsername("alice").WithEmail("alice@example.com").Build()
+	require.NoError(t, users.Create(context.Background(), user))
+
+	templates, err := NewTemplates(DefaultTemplates...)
+	require.NoError(t, err)
+
+	return NewService(repositories.NewMemoryNotificationRepository(), users, queue, c, templates, config), user
+}
+
+// startService 创建经内存事件总线投递任务的通知服务并启动，测试结束时停止
+func startService(t *testing.T, config Config, channels ...Channel) (*Service, *models.User) {
+	t.Helper()
+
+	bus := events.NewMemoryBus()
+	t.Cleanup(func() { bus.Close() })
+
+	service, user := newService(t, bus, nil, config)
+	for _, channel := range channels {
+		service.RegisterChannel(channel)
+	}
+	require.NoError(t, service.Start(context.Background()))
+	t.Cleanup(func() { service.Stop(context.Background()) })
+	return service, user
+}
+
+// inbox 返回用户的全部站内信
+func inbox(t *testing.T, service *Service, userID string) []*models.Notification {
+	t.Helper()
+	notifications, _, err := service.List(context.Background(), userID, false, 1, 100)
+	require.NoError(t, err)
+	return notifications
+}
+
+// TestNotifyDeliversThroughQueue 测试通知经事件总线投递到模板的默认渠道
+func TestNotifyDeliversThroughQueue(t *testing.T) {
+	email := &recordingChannel{name: ChannelEmail}
+	push := &recordingChannel{name: ChannelPush}
+	service, user := startService(t, Config{}, email, push)
+	data := map[string]string{"time": "2026-01-02T03:04:05Z"}
+
+	require.NoError(t, service.Notify(context.Background(), user.ID, TypePasswordChanged, data))
+
+	require.Eventually(t, func() bool { return len(inbox(t, service, user.ID)) == 1 }, time.Second, 5*time.Millisecond)
+	notification := inbox(t, service, user.ID)[0]
+	assert.Equal(t, TypePasswordChanged, notification.Type)
+	assert.Equal(t, "密码已修改", notification.Subject)
+	assert.Contains(t, notification.Body, data["time"])
+	assert.Nil(t, notification.ReadAt)
+
+	require.Len(t, email.received(), 1)
+	require.Len(t, push.received(), 1)
+	assert.Equal(t, data, email.received()[0].Data)
+}
+
+// TestNotifyUnknownType 测试未注册的通知类型在提交时即被拒绝
+func TestNotifyUnknownType(t *testing.T) {
+	service, user := newService(t, nil, nil, Config{})
+
+	err := service.Notify(context.Background(), user.ID, "billing.unknown", nil)
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+// TestDeliver 测试按用户偏好选择渠道，渠道和渲染的错误只报告不返回
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		preferences  []models.NotificationPreference
+		emailErr     error
+		missingUser  bool
+		job          Job
+		wantEmail    int
+		wantPush     int
+		wantInbox    int
+		wantReported []string
+	}{
+		{
+			name:      "模板的默认渠道",
+			job:       Job{Type: TypeWelcome},
+			wantEmail: 1,
+			wantInbox: 1,
+		},
+		{
+			name: "关闭的渠道不投递，打开的渠道投递",
+			preferences: []models.NotificationPreference{
+				{Type: TypeWelcome, Channel: ChannelEmail, Enabled: false},
+				{Type: TypeWelcome, Channel: ChannelPush, Enabled: true},
+			},
+			job:       Job{Type: TypeWelcome},
+			wantPush:  1,
+			wantInbox: 1,
+		},
+		{
+			name:         "一个渠道失败时其他渠道仍然投递",
+			emailErr:     errors.New("gateway down"),
+			job:          Job{Type: TypeWelcome},
+			wantEmail:    1,
+			wantInbox:    1,
+			wantReported: []string{"通过 email 发送"},
+		},
+		{
+			name:        "用户已删除时丢弃任务",
+			missingUser: true,
+			job:         Job{Type: TypeWelcome},
+		},
+		{
+			name:         "模板引用的数据缺失",
+			job:          Job{Type: TypePasswordChanged},
+			wantReported: []string{"渲染"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var reported []string
+			service, user := newService(t, nil, nil, Config{OnError: func(operation string, err error) {
+				reported = append(reported, operation)
+			}})
+			email := &recordingChannel{name: ChannelEmail, err: tt.emailErr}
+			push := &recordingChannel{name: ChannelPush}
+			service.RegisterChannel(email)
+			service.RegisterChannel(push)
+
+			if tt.preferences != nil {
+				require.NoError(t, service.UpdatePreferences(ctx, user.ID, tt.preferences))
+			}
+			job := tt.job
+			job.UserID = user.ID
+			if tt.missingUser {
+				job.UserID = "missing"
+			}
+
+			require.NoError(t, service.Deliver(ctx, job))
+			assert.Len(t, email.received(), tt.wantEmail)
+			assert.Len(t, push.received(), tt.wantPush)
+			assert.Len(t, inbox(t, service, user.ID), tt.wantInbox)
+			assert.Equal(t, tt.wantReported, reported)
+		})
+	}
+}
+
+// TestPreferences 测试偏好覆盖全部类型和已启用渠道，未设置时使用默认渠道
+func TestPreferences(t *testing.T) {
+	ctx := context.Background()
+	service, user := newService(t, nil, nil, Config{Channels: []string{ChannelEmail, ChannelInApp}})
+	service.RegisterChannel(&recordingChannel{name: ChannelEmail})
+	service.RegisterChannel(&recordingChannel{name: ChannelPush})
+
+	assert.Equal(t, []string{ChannelEmail, ChannelInApp}, service.Channels(), "push 未启用")
+
+	preferences, err := service.Preferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, preferences, len(DefaultTemplates)*2)
+	assert.Contains(t, preferences, models.NotificationPreference{UserID: user.ID, Type: TypeWelcome, Channel: ChannelEmail, Enabled: true})
+
+	require.NoError(t, service.UpdatePreferences(ctx, user.ID, []models.NotificationPreference{{Type: TypeWelcome, Channel: ChannelEmail, Enabled: false}}))
+	preferences, err = service.Preferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, preferences, models.NotificationPreference{UserID: user.ID, Type: TypeWelcome, Channel: ChannelEmail, Enabled: false})
+}
+
+// TestUpdatePreferencesErrors 测试拒绝未启用的渠道和未知的通知类型
+func TestUpdatePreferencesErrors(t *testing.T) {
+	service, user := newService(t, nil, nil, Config{Channels: []string{ChannelEmail, ChannelInApp}})
+	service.RegisterChannel(&recordingChannel{name: ChannelEmail})
+
+	tests := []struct {
+		name       string
+		preference models.NotificationPreference
+		wantErr    error
+	}{
+		{"未启用的渠道", models.NotificationPreference{Type: TypeWelcome, Channel: ChannelPush, Enabled: true}, ErrUnknownChannel},
+		{"未知的通知类型", models.NotificationPreference{Type: "unknown", Channel: ChannelEmail}, ErrUnknownType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.UpdatePreferences(context.Background(), user.ID, []models.NotificationPreference{tt.preference})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestUnreadCountCache 测试未读数缓存在新通知和标记已读时失效
+func TestUnreadCountCache(t *testing.T) {
+	ctx := context.Background()
+	memoryCache := cache.NewMemoryCache()
+	service, user := newService(t, nil, memoryCache, Config{})
+	job := Job{UserID: user.ID, Type: TypeWelcome}
+
+	require.NoError(t, service.Deliver(ctx, job))
+	require.NoError(t, service.Deliver(ctx, job))
+	count, err := service.UnreadCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	cached, ok := memoryCache.GetBytes(ctx, unreadCountKeyPrefix+user.ID)
+	require.True(t, ok)
+	assert.Equal(t, "2", string(cached))
+
+	require.NoError(t, service.Deliver(ctx, job))
+	count, err = service.UnreadCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count, "新通知使缓存失效")
+
+	require.NoError(t, service.MarkRead(ctx, user.ID, inbox(t, service, user.ID)[0].ID))
+	count, err = service.UnreadCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	marked, err := service.MarkAllRead(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), marked)
+	count, err = service.UnreadCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	assert.ErrorIs(t, service.MarkRead(ctx, "someone-else", inbox(t, service, user.ID)[0].ID), ErrNotFound)
+}
+
+// TestSubscribeUserEvents 测试用户生命周期事件触发内置通知
+func TestSubscribeUserEvents(t *testing.T) {
+	ctx := context.Background()
+	service, user := startService(t, Config{})
+	dispatcher := domainevents.NewDispatcher(1, 10)
+	service.SubscribeUserEvents(dispatcher)
+
+	dispatcher.Emit(ctx, domainevents.UserCreated{UserID: user.ID, OccurredAt: time.Now()})
+	dispatcher.Emit(ctx, domainevents.UserUpdated{UserID: user.ID, Fields: []string{domainevents.FieldPassword, domainevents.FieldEmail}, OccurredAt: time.Now()})
+	dispatcher.Emit(ctx, domainevents.UserUpdated{UserID: user.ID, Fields: []string{domainevents.FieldFirstName}, OccurredAt: time.Now()})
+	require.NoError(t, dispatcher.Close(ctx))
+
+	require.Eventually(t, func() bool { return len(inbox(t, service, user.ID)) == 3 }, time.Second, 5*time.Millisecond)
+	var types []string
+	for _, notification := range inbox(t, service, user.ID) {
+		types = append(types, notification.Type)
+	}
+	assert.ElementsMatch(t, []string{TypeWelcome, TypePasswordChanged, TypeEmailChanged}, types)
+}
+
+// TestPublishChannel 测试外发渠道发布 notification.<渠道> 事件
+func TestPublishChannel(t *testing.T) {
+	bus := events.NewMemoryBus()
+	defer bus.Close()
+	received := make(chan *events.Event, 1)
+	_, err := bus.Subscribe(context.Background(), EventSMS, func(ctx context.Context, event *events.Event) error {
+		received <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	channel := NewSMSChannel(bus)
+	assert.Equal(t, ChannelSMS, channel.Name())
+	require.NoError(t, channel.Send(context.Background(), Recipient{UserID: "u1"}, Message{Type: TypeWelcome, Subject: "hi"}))
+
+	select {
+	case event := <-received:
+		var payload OutboundMessage
+		require.NoError(t, event.Decode(&payload))
+		assert.Equal(t, "u1", payload.Recipient.UserID)
+		assert.Equal(t, "hi", payload.Message.Subject)
+	case <-time.After(time.Second):
+		t.Fatal("事件未发布")
+	}
+}
+
+// TestTemplates 测试模板注册和渲染
+func TestTemplates(t *testing.T) {
+	_, err := NewTemplates(Template{Type: "broken", Subject: "{{.name"})
+	assert.Error(t, err)
+
+	templates, err := NewTemplates(Template{Type: "b"}, Template{Type: "a", Subject: "Hi {{.name}}", Body: "Body"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", templates.List()[0].Type)
+
+	tests := []struct {
+		name         string
+		templateType string
+		data         map[string]string
+		wantSubject  string
+		wantErr      bool
+		wantErrIs    error
+	}{
+		{name: "渲染", templateType: "a", data: map[string]string{"name": "Bob"}, wantSubject: "Hi Bob"},
+		{name: "缺少数据", templateType: "a", wantErr: true},
+		{name: "未知类型", templateType: "c", wantErr: true, wantErrIs: ErrUnknownType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := templates.Render(tt.templateType, tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSubject, message.Subject)
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package notifications
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domainevents "go-server/internal/events"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+)
+
+// DefaultUnreadCountTTL 未读数的默认缓存时间
+const DefaultUnreadCountTTL = 5 * time.Minute
+
+// unreadCountKeyPrefix 未读数的缓存键前缀，后接用户ID
+const unreadCountKeyPrefix = "notifications:unread:"
+
+// UserLookup 按ID查找接收者，用户服务实现了该接口
+type UserLookup interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// Queue 投递任务队列，事件总线实现了该接口
+type Queue interface {
+	events.Publisher
+	events.Subscriber
+}
+
+// Config 通知服务配置
+type Config struct {
+	// Channels 启用的渠道，为空时启用全部已注册的渠道；未启用的渠道不投递，也不出现在偏好中
+	Channels []string
+	// UnreadCountTTL 未读数缓存时间，<=0 时使用 DefaultUnreadCountTTL
+	UnreadCountTTL time.Duration
+	// OnError 投递失败时调用，operation 描述失败的步骤，为 nil 时忽略
+	OnError func(operation string, err error)
+}
+
+// Service 通知服务：提交通知、投递任务、站内信收件箱和用户偏好
+type Service struct {
+	repo      repositories.NotificationRepository
+	users     UserLookup
+	queue     Queue
+	cache     cache.Cache
+	templates *Templates
+	config    Config
+
+	channels map[string]Channel
+
+	mu           sync.Mutex
+	subscription events.Subscription
+}
+
+// NewService 创建通知服务并注册站内信渠道
+// cache 为 nil 时未读数每次从数据库统计；其他渠道通过 RegisterChannel 注册
+func NewService(repo repositories.NotificationRepository, users UserLookup, queue Queue, c cache.Cache, templates *Templates, config Config) *Service {
+	if config.UnreadCountTTL <= 0 {
+		config.UnreadCountTTL = DefaultUnreadCountTTL
+	}
+	s := &Service{
+		repo:      repo,
+		users:     users,
+		queue:     queue,
+		cache:     c,
+		templates: templates,
+		config:    config,
+		channels:  make(map[string]Channel),
+	}
+	s.RegisterChannel(inAppChannel{s: s})
+	return s
+}
+
+// RegisterChannel 注册渠道，同名渠道会被替换，须在 Start 之前调用
+// 渠道不在 Config.Channels 中时忽略
+func (s *Service) RegisterChannel(channel Channel) {
+	if len(s.config.Channels) > 0 && !slices.Contains(s.config.Channels, channel.Name()) {
+		return
+	}
+	s.channels[channel.Name()] = channel
+}
+
+// Channels 返回已启用的渠道名称，内置渠道按 AllChannels 的顺序排在前面
+func (s *Service) Channels() []string {
+	names := make([]string, 0, len(s.channels))
+	for name := range s.channels {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(channelRank(a), channelRank(b)), strings.Compare(a, b))
+	})
+	return names
+}
+
+// channelRank 返回渠道在 AllChannels 中的位置，自定义渠道排在最后
+func channelRank(name string) int {
+	if i := slices.Index(AllChannels, name); i >= 0 {
+		return i
+	}
+	return len(AllChannels)
+}
+
+// Notify 提交通知，发布投递任务后立即返回，不等待投递完成
+func (s *Service) Notify(ctx context.Context, userID, notificationType string, data map[string]string) error {
+	if _, ok := s.templates.Get(notificationType); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, notificationType)
+	}
+	if err := events.PublishEvent(ctx, s.queue, TopicDeliver, Job{UserID: userID, Type: notificationType, Data: data}); err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+	return nil
+}
+
+// Start 订阅投递任务；多实例部署时由事件总线的队列组或消费者组保证每个任务只被一个实例处理
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscription != nil {
+		return nil
+	}
+
+	subscription, err := s.queue.Subscribe(ctx, TopicDeliver, s.handle)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", TopicDeliver, err)
+	}
+	s.subscription = subscription
+	return nil
+}
+
+// Stop 取消订阅投递任务，处理中的任务由事件总线排空
+func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscription == nil {
+		return nil
+	}
+
+	err := s.subscription.Unsubscribe()
+	s.subscription = nil
+	return err
+}
+
+// handle 处理一个投递任务
+// 只有查找接收者失败时返回错误由事件总线重试；渲染和渠道的错误重试也不会成功，
+// 或会让已成功的渠道重复投递，因此只报告不返回
+func (s *Service) handle(ctx context.Context, event *events.Event) error {
+	var job Job
+	if err := event.Decode(&job); err != nil {
+		s.report("解析投递任务", err)
+		return nil
+	}
+	return s.Deliver(ctx, job)
+}
+
+// Deliver 渲染通知并按用户偏好投递到已启用的渠道，通常由投递任务调用
+func (s *Service) Deliver(ctx context.Context, job Job) error {
+	user, err := s.users.GetByID(ctx, job.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		// 用户在任务排队期间被删除
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up notification recipient: %w", err)
+	}
+	recipient := Recipient{UserID: user.ID, Username: user.Username, Email: user.Email}
+
+	data := make(map[string]string, len(job.Data)+2)
+	data["username"] = recipient.Username
+	data["email"] = recipient.Email
+	for key, value := range job.Data {
+		data[key] = value
+	}
+	message, err := s.templates.Render(job.Type, data)
+	if err != nil {
+		s.report("渲染", err)
+		return nil
+	}
+	message.Data = job.Data
+
+	channels, err := s.enabledChannels(ctx, job.UserID, job.Type)
+	if err != nil {
+		return err
+	}
+	for _, name := range channels {
+		if err := s.channels[name].Send(ctx, recipient, message); err != nil {
+			s.report("通过 "+name+" 发送", err)
+		}
+	}
+	return nil
+}
+
+// enabledChannels 返回用户对通知类型开启的渠道
+func (s *Service) enabledChannels(ctx context.Context, userID, notificationType string) ([]string, error) {
+	preferences, err := s.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var channels []string
+	for _, preference := range preferences {
+		if preference.Type == notificationType && preference.Enabled {
+			channels = append(channels, preference.Channel)
+		}
+	}
+	return channels, nil
+}
+
+// report 报告投递失败
+func (s *Service) report(operation string, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(operation, err)
+	}
+}
+
+// Preferences 返回用户对每种通知类型在每个已启用渠道上的偏好，没有设置的使用模板的默认渠道
+func (s *Service) Preferences(ctx context.Context, userID string) ([]models.NotificationPreference, error) {
+	stored, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[[2]string]bool, len(stored))
+	for _, preference := range stored {
+		overrides[[2]string{preference.Type, preference.Channel}] = preference.Enabled
+	}
+
+	channels := s.Channels()
+	var preferences []models.NotificationPreference
+	for _, tpl := range s.templates.List() {
+		for _, channel := range channels {
+			enabled, ok := overrides[[2]string{tpl.Type, channel}]
+			if !ok {
+				enabled = slices.Contains(tpl.Channels, channel)
+			}
+			preferences = append(preferences, models.NotificationPreference{
+				UserID:  userID,
+				Type:    tpl.Type,
+				Channel: channel,
+				Enabled: enabled,
+			})
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences 保存用户的偏好，通知类型须已注册、渠道须已启用
+func (s *Service) UpdatePreferences(ctx context.Context, userID string, preferences []models.NotificationPreference) error {
+	updates := make([]*models.NotificationPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		if _, ok := s.templates.Get(preference.Type); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownType, preference.Type)
+		}
+		if _, ok := s.channels[preference.Channel]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownChannel, preference.Channel)
+		}
+		updates = append(updates, &models.NotificationPreference{
+			UserID:  userID,
+			Type:    preference.Type,
+			Channel: preference.Channel,
+			Enabled: preference.Enabled,
+		})
+	}
+	return s.repo.SavePreferences(ctx, updates)
+}
+
+// List 返回用户的一页站内信，按创建时间倒序，以及符合条件的总数
+func (s *Service) List(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]*models.Notification, int64, error) {
+	return s.repo.List(ctx, userID, unreadOnly, (page-1)*limit, limit)
+}
+
+// UnreadCount 返回用户的未读站内信数，优先读取缓存
+// 新通知和标记已读时删除缓存，缓存时间只是多实例间的兜底
+func (s *Service) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	key := unreadCountKeyPrefix + userID
+	if s.cache != nil {
+		if data, ok := s.cache.GetBytes(ctx, key); ok {
+			if count, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+				return count, nil
+			}
+		}
+	}
+
+	count, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if s.cache != nil {
+		// 写入失败只会让下次请求重新统计
+		_ = s.cache.Set(ctx, key, count, s.config.UnreadCountTTL)
+	}
+	return count, nil
+}
+
+// MarkRead 把用户的一条站内信标记为已读
+func (s *Service) MarkRead(ctx context.Context, userID, id string) error {
+	err := s.repo.MarkRead(ctx, userID, id, time.Now().UTC())
+	if errors.Is(err, repositories.ErrNotificationNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.invalidateUnreadCount(ctx, userID)
+	return nil
+}
+
+// MarkAllRead 把用户的全部站内信标记为已读，返回本次标记的数量
+func (s *Service) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	marked, err := s.repo.MarkAllRead(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	s.invalidateUnreadCount(ctx, userID)
+	return marked, nil
+}
+
+// DeleteUserData 删除用户的全部站内信和通知偏好，返回删除的站内信数，用于抹除用户的个人数据
+func (s *Service) DeleteUserData(ctx context.Context, userID string) (int64, error) {
+	deleted, err := s.repo.DeleteUserData(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidateUnreadCount(ctx, userID)
+	return deleted, nil
+}
+
+// invalidateUnreadCount 删除未读数缓存
+func (s *Service) invalidateUnreadCount(ctx context.Context, userID string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Delete(ctx, unreadCountKeyPrefix+userID); err != nil {
+		s.report("删除未读数缓存", err)
+	}
+}
+
+// inAppChannel 站内信渠道，写入通知收件箱
+type inAppChannel struct {
+	s *Service
+}
+
+// Name 返回渠道名称
+func (c inAppChannel) Name() string {
+	return ChannelInApp
+}
+
+// Send 写入站内信并使未读数缓存失效
+func (c inAppChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	err := c.s.repo.Create(ctx, &models.Notification{
+		UserID:  recipient.UserID,
+		Type:    message.Type,
+		Subject: message.Subject,
+		Body:    message.Body,
+	})
+	if err != nil {
+		return err
+	}
+	c.s.invalidateUnreadCount(ctx, recipient.UserID)
+	return nil
+}
+
+// SubscribeUserEvents 在用户生命周期事件发生时提交内置类型的通知：注册后欢迎，修改密码或邮箱后提醒
+func (s *Service) SubscribeUserEvents(dispatcher *domainevents.Dispatcher) {
+	domainevents.On(dispatcher, func(ctx context.Context, e domainevents.UserCreated) error {
+		return s.Notify(ctx, e.UserID, TypeWelcome, nil)
+	})
+	domainevents.On(dispatcher, func(ctx context.Context, e domainevents.UserUpdated) error {
+		data := map[string]string{"time": e.OccurredAt.UTC().Format(time.RFC3339)}
+		var errs []error
+		if slices.Contains(e.Fields, domainevents.FieldPassword) {
+			errs = append(errs, s.Notify(ctx, e.UserID, TypePasswordChanged, data))
+		}
+		if slices.Contains(e.Fields, domainevents.FieldEmail) {
+			errs = append(errs, s.Notify(ctx, e.UserID, TypeEmailChanged, data))
+		}
+		return errors.Join(errs...)
+	})
+}
@@ -0,0 +1,136 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+)
+
+// 内置通知类型
+const (
+	TypeWelcome         = "account.welcome"
+	TypePasswordChanged = "account.password_changed"
+	TypeEmailChanged    = "account.email_changed"
+)
+
+// Template 一种通知类型的消息模板
+// Subject 和 Body 使用 text/template 语法，数据为提交通知时的 Data，另外总是可以使用接收者的 username 和 email
+type Template struct {
+	Type     string   // 通知类型
+	Subject  string   // 标题模板
+	Body     string   // 正文模板
+	Channels []string // 用户没有设置偏好时投递的渠道
+}
+
+// DefaultTemplates 内置通知类型的模板，由用户生命周期事件触发
+var DefaultTemplates = []Template{
+	{
+		Type:     TypeWelcome,
+		Subject:  "欢迎加入，{{.username}}",
+		Body:     "你的账号 {{.email}} 已创建成功。",
+		Channels: []string{ChannelEmail, ChannelInApp},
+	},
+	{
+		Type:     TypePasswordChanged,
+		Subject:  "密码已修改",
+		Body:     "你的账号密码已于 {{.time}} 修改。如果不是你本人操作，请立即重置密码。",
+		Channels: []string{ChannelEmail, ChannelPush, ChannelInApp},
+	},
+	{
+		Type:     TypeEmailChanged,
+		Subject:  "邮箱已变更",
+		Body:     "你的账号邮箱已于 {{.time}} 变更为 {{.email}}。如果不是你本人操作，请立即联系管理员。",
+		Channels: []string{ChannelEmail, ChannelInApp},
+	},
+}
+
+// parsedTemplate 解析后的模板
+type parsedTemplate struct {
+	Template
+	subject *template.Template
+	body    *template.Template
+}
+
+// Templates 按通知类型注册的模板，可并发使用
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]*parsedTemplate
+}
+
+// NewTemplates 创建模板集合并注册给定的模板
+func NewTemplates(templates ...Template) (*Templates, error) {
+	t := &Templates{templates: make(map[string]*parsedTemplate)}
+	for _, tpl := range templates {
+		if err := t.Register(tpl); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Register 解析并注册模板，已注册的类型会被替换
+// 模板引用了数据中不存在的键时渲染失败，而不是输出 <no value>
+func (t *Templates) Register(tpl Template) error {
+	if tpl.Type == "" {
+		return fmt.Errorf("notifications: template type is required")
+	}
+	subject, err := template.New(tpl.Type + ".subject").Option("missingkey=error").Parse(tpl.Subject)
+	if err != nil {
+		return fmt.Errorf("notifications: parse subject of %s: %w", tpl.Type, err)
+	}
+	body, err := template.New(tpl.Type + ".body").Option("missingkey=error").Parse(tpl.Body)
+	if err != nil {
+		return fmt.Errorf("notifications: parse body of %s: %w", tpl.Type, err)
+	}
+
+	tpl.Channels = append([]string(nil), tpl.Channels...)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[tpl.Type] = &parsedTemplate{Template: tpl, subject: subject, body: body}
+	return nil
+}
+
+// Get 返回通知类型的模板
+func (t *Templates) Get(notificationType string) (Template, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	parsed, ok := t.templates[notificationType]
+	if !ok {
+		return Template{}, false
+	}
+	return parsed.Template, true
+}
+
+// List 返回所有模板，按类型排序
+func (t *Templates) List() []Template {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	list := make([]Template, 0, len(t.templates))
+	for _, parsed := range t.templates {
+		list = append(list, parsed.Template)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
+
+// Render 用数据渲染通知类型的标题和正文
+func (t *Templates) Render(notificationType string, data map[string]string) (Message, error) {
+	t.mu.RLock()
+	parsed, ok := t.templates[notificationType]
+	t.mu.RUnlock()
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownType, notificationType)
+	}
+
+	var subject, body bytes.Buffer
+	if err := parsed.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("notifications: render subject of %s: %w", notificationType, err)
+	}
+	if err := parsed.body.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("notifications: render body of %s: %w", notificationType, err)
+	}
+	return Message{Type: notificationType, Subject: subject.String(), Body: body.String(), Data: data}, nil
+}
@@ -0,0 +1,497 @@
+// Package oidc 最小化的 OpenID Connect 提供方，使本服务成为一组内部应用的身份中心
+//
+// 只支持授权码 + PKCE（S256）流程。依赖方把浏览器重定向到前端的授权同意页面，页面携带用户的访问令牌
+// 调用授权接口读取同意信息并提交用户的决定，得到带有授权码的回调地址；依赖方再在令牌接口用授权码和
+// code_verifier 换取访问令牌和 ID 令牌。授权码保存在共享缓存中，只能兑换一次。
+// ID 令牌使用访问令牌的签名密钥签发，依赖方通过 JWKS 验证，因此须使用非对称签名算法。
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 默认有效期
+const (
+	DefaultCodeTTL    = time.Minute
+	DefaultIDTokenTTL = time.Hour
+)
+
+// codeKeyPrefix 授权码在缓存中的键前缀，键中只保存授权码的哈希
+const codeKeyPrefix = "oidc:code:"
+
+// OAuth 2.0 错误码（RFC 6749 第 4.1.2.1 节和第 5.2 节）
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorAccessDenied            = "access_denied"
+)
+
+// supportedScopes 支持的范围，授予的范围按此顺序排列
+var supportedScopes = []string{models.ScopeOpenID, models.ScopeProfile, models.ScopeEmail}
+
+var (
+	// ErrClientNotFound 客户端不存在
+	ErrClientNotFound = errors.New("oauth client not found")
+	// ErrInvalidRedirectURI 回调地址不是不带片段的 http 或 https 绝对地址
+	ErrInvalidRedirectURI = errors.New("redirect uri must be an absolute http or https URL without a fragment")
+)
+
+// Error OAuth 2.0 协议错误，Code 为标准错误码，Param 为出错的请求参数
+type Error struct {
+	Code        string
+	Param       string
+	Description string
+}
+
+// Error 返回错误码和描述
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// protocolError 创建协议错误
+func protocolError(code, param, description string) *Error {
+	return &Error{Code: code, Param: param, Description: description}
+}
+
+// UserLookup 按ID查找用户，用户服务实现了该接口
+type UserLookup interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// Config OpenID Connect 提供方配置
+type Config struct {
+	// Issuer 签发者，本服务对外的根地址，发现文档位于 Issuer + /.well-known/openid-configuration
+	Issuer string
+	// ConsentURL 前端授权同意页面的地址，作为发现文档中的 authorization_endpoint
+	ConsentURL string
+	// CodeTTL 授权码的有效期，<=0 时使用 DefaultCodeTTL
+	CodeTTL time.Duration
+	// IDTokenTTL ID 令牌的有效期，<=0 时使用 DefaultIDTokenTTL
+	IDTokenTTL time.Duration
+}
+
+// Service OpenID Connect 提供方：管理客户端、签发授权码并兑换令牌
+type Service struct {
+	clients repositories.OAuthClientRepository
+	users   UserLookup
+	cache   cache.Cache
+	jwt     *auth.JWTManager
+	config  Config
+	now     func() time.Time
+}
+
+// grant 授权码对应的授权，兑换时校验客户端、回调地址和 PKCE
+type grant struct {
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	UserID        string `json:"user_id"`
+	Scope         string `json:"scope"`
+	Nonce         string `json:"nonce,omitempty"`
+	CodeChallenge string `json:"code_challenge"`
+	AuthTime      int64  `json:"auth_time"`
+}
+
+// TokenRequest 令牌接口的请求参数，ClientSecret 可以来自 HTTP Basic 认证或请求体
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+}
+
+// NewService 创建 OpenID Connect 提供方
+func NewService(clients repositories.OAuthClientRepository, users UserLookup, c cache.Cache, jwtManager *auth.JWTManager, config Config) *Service {
+	if config.CodeTTL <= 0 {
+		config.CodeTTL = DefaultCodeTTL
+	}
+	if config.IDTokenTTL <= 0 {
+		config.IDTokenTTL = DefaultIDTokenTTL
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &Service{
+		clients: clients,
+		users:   users,
+		cache:   c,
+		jwt:     jwtManager,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// Issuer 返回签发者
+func (s *Service) Issuer() string {
+	return s.config.Issuer
+}
+
+// RegisterClient 注册客户端，机密客户端同时返回客户端密钥，密钥只保存哈希，之后无法再次读取
+func (s *Service) RegisterClient(ctx context.Context, req *models.OAuthClientRequest, createdBy string) (*models.OAuthClient, string, error) {
+	for _, uri := range req.RedirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || parsed.Fragment != "" {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidRedirectURI, uri)
+		}
+	}
+
+	client := &models.OAuthClient{
+		Name:         req.Name,
+		Confidential: req.Confidential,
+		RedirectURIs: slices.Clone(req.RedirectURIs),
+		CreatedBy:    createdBy,
+	}
+
+	var secret string
+	if req.Confidential {
+		var err error
+		if secret, err = randomToken(); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = hashSecret(secret)
+	}
+
+	if err := s.clients.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+// ListClients 返回所有客户端，按创建时间倒序
+func (s *Service) ListClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	return s.clients.List(ctx)
+}
+
+// DeleteClient 删除客户端，已签发的令牌在过期前仍然有效
+func (s *Service) DeleteClient(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrClientNotFound
+	}
+	err := s.clients.Delete(ctx, id)
+	if errors.Is(err, repositories.ErrOAuthClientNotFound) {
+		return ErrClientNotFound
+	}
+	return err
+}
+
+// Consent 校验授权请求，返回授权同意页面需要展示的客户端和范围
+func (s *Service) Consent(ctx context.Context, req *models.OAuthAuthorizeRequest) (*models.OAuthConsentResponse, error) {
+	client, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OAuthConsentResponse{
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		Scopes:     scopes,
+	}, nil
+}
+
+// Decide 处理用户的授权决定，返回带有授权码或 access_denied 错误的回调地址
+func (s *Service) Decide(ctx context.Context, req *models.OAuthAuthorizeRequest, userID string, approve bool) (string, error) {
+	_, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	if !approve {
+		params.Set("error", ErrorAccessDenied)
+		params.Set("error_description", "the user denied the request")
+		return appendQuery(req.RedirectURI, params), nil
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	granted := grant{
+		ClientID:      req.ClientID,
+		RedirectURI:   req.RedirectURI,
+		UserID:        userID,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      s.now().Unix(),
+	}
+	if err := s.cache.Set(ctx, codeKey(code), granted, s.config.CodeTTL); err != nil {
+		return "", fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	params.Set("code", code)
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+// validateAuthorization 校验授权请求，返回客户端和授予的范围
+// 客户端或回调地址无效时不能重定向到回调地址，所有错误都直接返回给授权同意页面
+func (s *Service) validateAuthorization(ctx context.Context, req *models.OAuthAuthorizeRequest) (*models.OAuthClient, []string, error) {
+	client, err := s.client(ctx, req.ClientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return nil, nil, protocolError(ErrorInvalidRequest, "client_id", "unknown client")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return nil, nil, protocolError(ErrorInvalidRequest, "redirect_uri", "redirect_uri is not registered for the client")
+	}
+	if req.ResponseType != "code" {
+		return nil, nil, protocolError(ErrorUnsupportedResponseType, "response_type", "only the code response type is supported")
+	}
+	if req.CodeChallengeMethod != "S256" {
+		return nil, nil, protocolError(ErrorInvalidRequest, "code_challenge_method", "code_challenge_method must be S256")
+	}
+	if len(req.CodeChallenge) != 43 {
+		return nil, nil, protocolError(ErrorInvalidRequest, "code_challenge", "code_challenge must be a base64url encoded SHA-256 digest")
+	}
+
+	scopes := grantedScopes(req.Scope)
+	if !slices.Contains(scopes, models.ScopeOpenID) {
+		return nil, nil, protocolError(ErrorInvalidScope, "scope", "scope must include openid")
+	}
+	return client, scopes, nil
+}
+
+// Exchange 用授权码兑换访问令牌和 ID 令牌
+func (s *Service) Exchange(ctx context.Context, req TokenRequest) (*models.OAuthTokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return nil, protocolError(ErrorUnsupportedGrantType, "grant_type", "only the authorization_code grant type is supported")
+	}
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, protocolError(ErrorInvalidRequest, "code", "code and code_verifier are required")
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	granted, err := s.redeem(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if granted.ClientID != client.ID || granted.RedirectURI != req.RedirectURI {
+		return nil, protocolError(ErrorInvalidGrant, "code", "authorization code was issued to another client or redirect_uri")
+	}
+	if !verifyPKCE(req.CodeVerifier, granted.CodeChallenge) {
+		return nil, protocolError(ErrorInvalidGrant, "code_verifier", "code_verifier does not match the code_challenge")
+	}
+
+	user, err := s.users.GetByID(ctx, granted.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, protocolError(ErrorInvalidGrant, "code", "the user no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, protocolError(ErrorInvalidGrant, "code", "the user is deactivated")
+	}
+
+	var tenantID string
+	if user.TenantID != nil {
+		tenantID = *user.TenantID
+	}
+	accessToken, err := s.jwt.GenerateTenantToken(user.ID, user.Username, user.Email, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+	idToken, err := s.jwt.GenerateIDToken(s.idTokenClaims(client, user, granted), s.config.IDTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue id token: %w", err)
+	}
+
+	return &models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.jwt.ExpiresIn().Seconds()),
+		IDToken:     idToken,
+		Scope:       granted.Scope,
+	}, nil
+}
+
+// authenticateClient 校验客户端，机密客户端须提供正确的密钥
+func (s *Service) authenticateClient(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	client, err := s.client(ctx, clientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return nil, protocolError(ErrorInvalidClient, "client_id", "client authentication failed")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Confidential && subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, protocolError(ErrorInvalidClient, "client_secret", "client authentication failed")
+	}
+	return client, nil
+}
+
+// redeem 兑换授权码，授权码只能兑换一次，并发兑换时只有一个请求成功
+func (s *Service) redeem(ctx context.Context, code string) (*grant, error) {
+	key := codeKey(code)
+	claimed, err := s.cache.SetIfNotExists(ctx, key+":redeemed", true, s.config.CodeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	if !claimed {
+		return nil, protocolError(ErrorInvalidGrant, "code", "authorization code has already been used")
+	}
+
+	granted, found := cache.GetAs[grant](ctx, s.cache, key)
+	if !found {
+		return nil, protocolError(ErrorInvalidGrant, "code", "authorization code is invalid or expired")
+	}
+	_ = s.cache.Delete(ctx, key)
+	return &granted, nil
+}
+
+// idTokenClaims 按授予的范围填充 ID 令牌声明
+func (s *Service) idTokenClaims(client *models.OAuthClient, user *models.User, granted *grant) *auth.IDTokenClaims {
+	claims := &auth.IDTokenClaims{
+		Nonce:           granted.Nonce,
+		AuthTime:        granted.AuthTime,
+		AuthorizedParty: client.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   s.config.Issuer,
+			Subject:  user.ID,
+			Audience: jwt.ClaimStrings{client.ID},
+		},
+	}
+	scopes := strings.Fields(granted.Scope)
+	if slices.Contains(scopes, models.ScopeProfile) {
+		claims.Name = user.GetFullName()
+		claims.GivenName = user.FirstName
+		claims.FamilyName = user.LastName
+		claims.PreferredUsername = user.Username
+	}
+	if slices.Contains(scopes, models.ScopeEmail) {
+		claims.Email = user.Email
+	}
+	return claims
+}
+
+// UserInfo 返回访问令牌所属用户的标准声明
+func (s *Service) UserInfo(ctx context.Context, userID string) (*models.OIDCUserInfo, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OIDCUserInfo{
+		Subject:           user.ID,
+		Name:              user.GetFullName(),
+		GivenName:         user.FirstName,
+		FamilyName:        user.LastName,
+		PreferredUsername: user.Username,
+		Email:             user.Email,
+	}, nil
+}
+
+// Discovery 返回发现文档，ID 令牌的签名算法取自当前活动密钥
+func (s *Service) Discovery() (*models.OIDCDiscovery, error) {
+	alg, err := s.jwt.SigningAlgorithm()
+	if err != nil {
+		return nil, err
+	}
+	return &models.OIDCDiscovery{
+		Issuer:                            s.config.Issuer,
+		AuthorizationEndpoint:             s.config.ConsentURL,
+		TokenEndpoint:                     s.config.Issuer + "/api/v1/oauth/token",
+		UserinfoEndpoint:                  s.config.Issuer + "/api/v1/oauth/userinfo",
+		JWKSURI:                           s.config.Issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{alg},
+		ScopesSupported:                   slices.Clone(supportedScopes),
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "azp", "name", "given_name", "family_name", "preferred_username", "email"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}, nil
+}
+
+// client 按客户端ID查找客户端，ID 不是 UUID 时按不存在处理
+func (s *Service) client(ctx context.Context, id string) (*models.OAuthClient, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrClientNotFound
+	}
+	client, err := s.clients.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrOAuthClientNotFound) {
+		return nil, ErrClientNotFound
+	}
+	return client, err
+}
+
+// grantedScopes 返回请求的范围中支持的部分，忽略不支持的范围
+func grantedScopes(scope string) []string {
+	requested := strings.Fields(scope)
+	var granted []string
+	for _, supported := range supportedScopes {
+		if slices.Contains(requested, supported) {
+			granted = append(granted, supported)
+		}
+	}
+	return granted
+}
+
+// verifyPKCE 校验 code_verifier（RFC 7636 第 4.6 节，S256）
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// codeKey 返回授权码的缓存键
+func codeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return codeKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// hashSecret 返回客户端密钥的 SHA-256，客户端密钥是随机生成的高熵字符串，无需慢哈希
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken 生成 256 位随机字符串，用作授权码和客户端密钥
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// appendQuery 在回调地址上追加查询参数，保留回调地址原有的参数
+func appendQuery(redirectURI string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+	return redirectURI + separator + params.Encode()
+}
@@ -0,0 +1,258 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	issuer      = "https://auth.example.com"
+	redirectURI = "https://app.example.com/callback"
+	verifier    = "dBjftJeZ4CVP-mJ92K9ZD7-bmx4qPbNhv3uM3fK0Wt7Ft"
+)
+
+// newJWT 创建使用 RS256 签名密钥的令牌管理器
+func newJWT(t *testing.T) *auth.JWTManager {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys, err := auth.NewKeySet(auth.NewRSAKey("rsa-1", key))
+	require.NoError(t, err)
+	return auth.NewJWTManagerWithKeys(keys, 1, nil)
+}
+
+// newService 创建使用内存仓库和缓存的提供方，users 为 nil 时不查询用户
+func newService(jwt *auth.JWTManager, users UserLookup) *Service {
+	return NewService(repositories.NewMemoryOAuthClientRepository(), users, cache.NewMemoryCache(), jwt, Config{
+		Issuer:     issuer + "/",
+		ConsentURL: "https://app.example.com/oauth/consent",
+	})
+}
+
+// newUser 创建授权的用户，返回包含该用户的内存仓库
+func newUser(t *testing.T) (*repositories.MemoryUserRepository, *models.User) {
+	t.Helper()
+	users := repositories.NewMemoryUserRepository()
+	user := factory.User().WithUsername("alice").WithEmail("alice@example.com").WithName("Alice", "Liddell").Build()
+	require.NoError(t, users.Create(context.Background(), user))
+	return users, user
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func register(t *testing.T, s *Service, confidential bool) (*models.OAuthClient, string) {
+	t.Helper()
+	client, secret, err := s.RegisterClient(context.Background(), &models.OAuthClientRequest{
+		Name:         "工单系统",
+		RedirectURIs: []string{redirectURI},
+		Confidential: confidential,
+	}, "admin")
+	require.NoError(t, err)
+	return client, secret
+}
+
+func authorizeRequest(clientID string) *models.OAuthAuthorizeRequest {
+	return &models.OAuthAuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		Scope:               "openid email offline_access",
+		State:               "xyz",
+		Nonce:               "n-0S6",
+		CodeChallenge:       challenge(verifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// authorize 同意授权并返回回调地址中的授权码
+func authorize(t *testing.T, s *Service, clientID, userID string) string {
+	t.Helper()
+	redirect, err := s.Decide(context.Background(), authorizeRequest(clientID), userID, true)
+	require.NoError(t, err)
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", parsed.Query().Get("state"))
+	return parsed.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	jwt := newJWT(t)
+	users, user := newUser(t)
+	service := newService(jwt, users)
+	client, secret := register(t, service, true)
+	require.NotEmpty(t, secret)
+	assert.NotEqual(t, secret, client.SecretHash, "只保存密钥的哈希")
+
+	consent, err := service.Consent(ctx, authorizeRequest(client.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "工单系统", consent.ClientName)
+	assert.Equal(t, []string{"openid", "email"}, consent.Scopes, "忽略不支持的范围")
+
+	code := authorize(t, service, client.ID, user.ID)
+	require.NotEmpty(t, code)
+
+	tokens, err := service.Exchange(ctx, TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         code,
+		RedirectURI:  redirectURI,
+		ClientID:     client.ID,
+		ClientSecret: secret,
+		CodeVerifier: verifier,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, "openid email", tokens.Scope)
+
+	claims, err := jwt.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	idClaims, err := jwt.ValidateIDToken(tokens.IDToken, issuer, client.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, idClaims.Subject)
+	assert.Equal(t, "n-0S6", idClaims.Nonce)
+	assert.Equal(t, "alice@example.com", idClaims.Email)
+	assert.Empty(t, idClaims.PreferredUsername, "未授予 profile 范围")
+
+	_, err = service.Exchange(ctx, TokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: redirectURI,
+		ClientID: client.ID, ClientSecret: secret, CodeVerifier: verifier,
+	})
+	var protoErr *Error
+	require.ErrorAs(t, err, &protoErr)
+	assert.Equal(t, ErrorInvalidGrant, protoErr.Code, "授权码只能兑换一次")
+}
+
+func TestExchangeRejectsInvalidRequests(t *testing.T) {
+	ctx := context.Background()
+	users, user := newUser(t)
+	service := newService(newJWT(t), users)
+	confidential, _ := register(t, service, true)
+	public, _ := register(t, service, false)
+
+	tests := []struct {
+		name string
+		req  func() TokenRequest
+		code string
+	}{
+		{
+			name: "错误的客户端密钥",
+			req: func() TokenRequest {
+				return TokenRequest{GrantType: "authorization_code", Code: authorize(t, service, confidential.ID, user.ID), RedirectURI: redirectURI,
+					ClientID: confidential.ID, ClientSecret: "wrong", CodeVerifier: verifier}
+			},
+			code: ErrorInvalidClient,
+		},
+		{
+			name: "错误的 code_verifier",
+			req: func() TokenRequest {
+				return TokenRequest{GrantType: "authorization_code", Code: authorize(t, service, public.ID, user.ID), RedirectURI: redirectURI,
+					ClientID: public.ID, CodeVerifier: verifier + "x"}
+			},
+			code: ErrorInvalidGrant,
+		},
+		{
+			name: "授权码签发给其他客户端",
+			req: func() TokenRequest {
+				return TokenRequest{GrantType: "authorization_code", Code: authorize(t, service, confidential.ID, user.ID), RedirectURI: redirectURI,
+					ClientID: public.ID, CodeVerifier: verifier}
+			},
+			code: ErrorInvalidGrant,
+		},
+		{
+			name: "回调地址不一致",
+			req: func() TokenRequest {
+				return TokenRequest{GrantType: "authorization_code", Code: authorize(t, service, public.ID, user.ID), RedirectURI: redirectURI + "/other",
+					ClientID: public.ID, CodeVerifier: verifier}
+			},
+			code: ErrorInvalidGrant,
+		},
+		{
+			name: "不支持的授权类型",
+			req: func() TokenRequest {
+				return TokenRequest{GrantType: "password", ClientID: public.ID}
+			},
+			code: ErrorUnsupportedGrantType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Exchange(ctx, tt.req())
+			var protoErr *Error
+			require.ErrorAs(t, err, &protoErr)
+			assert.Equal(t, tt.code, protoErr.Code)
+		})
+	}
+}
+
+func TestAuthorizationValidation(t *testing.T) {
+	ctx := context.Background()
+	// 校验授权请求不需要查询用户
+	service := newService(newJWT(t), nil)
+	client, _ := register(t, service, false)
+
+	tests := []struct {
+		name   string
+		modify func(req *models.OAuthAuthorizeRequest)
+		code   string
+		param  string
+	}{
+		{"未知客户端", func(req *models.OAuthAuthorizeRequest) { req.ClientID = "not-a-client" }, ErrorInvalidRequest, "client_id"},
+		{"未注册的回调地址", func(req *models.OAuthAuthorizeRequest) { req.RedirectURI = "https://evil.example.com/callback" }, ErrorInvalidRequest, "redirect_uri"},
+		{"不支持的响应类型", func(req *models.OAuthAuthorizeRequest) { req.ResponseType = "token" }, ErrorUnsupportedResponseType, "response_type"},
+		{"PKCE 方法必须为 S256", func(req *models.OAuthAuthorizeRequest) { req.CodeChallengeMethod = "plain" }, ErrorInvalidRequest, "code_challenge_method"},
+		{"范围必须包含 openid", func(req *models.OAuthAuthorizeRequest) { req.Scope = "profile" }, ErrorInvalidScope, "scope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := authorizeRequest(client.ID)
+			tt.modify(req)
+			_, err := service.Consent(ctx, req)
+			var protoErr *Error
+			require.ErrorAs(t, err, &protoErr)
+			assert.Equal(t, tt.code, protoErr.Code)
+			assert.Equal(t, tt.param, protoErr.Param)
+		})
+	}
+
+	t.Run("拒绝授权时回调地址带有 access_denied", func(t *testing.T) {
+		redirect, err := service.Decide(ctx, authorizeRequest(client.ID), "00000000-0000-4000-8000-000000000000", false)
+		require.NoError(t, err)
+		assert.Equal(t, redirectURI+"?error=access_denied&error_description=the+user+denied+the+request&state=xyz", redirect)
+	})
+
+	t.Run("注册时拒绝带片段的回调地址", func(t *testing.T) {
+		_, _, err := service.RegisterClient(ctx, &models.OAuthClientRequest{Name: "x", RedirectURIs: []string{"https://app.example.com/cb#frag"}}, "admin")
+		assert.ErrorIs(t, err, ErrInvalidRedirectURI)
+	})
+}
+
+func TestDiscovery(t *testing.T) {
+	service := newService(newJWT(t), nil)
+
+	doc, err := service.Discovery()
+	require.NoError(t, err)
+	assert.Equal(t, issuer, doc.Issuer, "去掉签发者末尾的斜杠")
+	assert.Equal(t, "https://app.example.com/oauth/consent", doc.AuthorizationEndpoint)
+	assert.Equal(t, issuer+"/api/v1/oauth/token", doc.TokenEndpoint)
+	assert.Equal(t, []string{auth.AlgorithmRS256}, doc.IDTokenSigningAlgValuesSupported)
+	assert.Equal(t, []string{"S256"}, doc.CodeChallengeMethodsSupported)
+}
@@ -0,0 +1,433 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Annotation 处理器函数上的接口注释，语法与 swag 相同，另外支持列表接口的共享注释（见 list.go）
+type Annotation struct {
+	Handler     string // 与 gin 路由表一致的处理函数名，如 go-server/internal/handlers.(*UserHandler).GetUser-fm
+	Func        string // 接收者和函数名，如 UserHandler.GetUser
+	Position    string // 注释所在的文件和行号
+	Summary     string
+	Description string
+	Tags        []string
+	Accept      []string
+	Produce     []string
+	Security    []string
+	Params      []ParamAnnotation
+	Responses   []ResponseAnnotation
+	Headers     []HeaderAnnotation
+	Routes      []RouteAnnotation
+
+	imports map[string]string // 注释所在文件的导入，用于解析类型
+}
+
+// ParamAnnotation @Param 注释
+type ParamAnnotation struct {
+	Name        string
+	In          string // path、query、header、body 或 formData
+	Type        string
+	Required    bool
+	Description string
+	Attributes  map[string]string // default(1)、minimum(1)、maximum(100)、Enums(a,b) 等
+
+	shared bool // 由 @Paginate、@Sort 等列表接口的共享注释生成
+}
+
+// ResponseAnnotation @Success 或 @Failure 注释
+type ResponseAnnotation struct {
+	Code        string
+	Kind        string // object、array、string，未声明响应体时为空
+	Type        string // 如 models.SuccessResponse{data=models.SafeUser}
+	Description string
+}
+
+// HeaderAnnotation @Header 注释
+type HeaderAnnotation struct {
+	Codes       []string // 状态码列表，all 表示所有响应
+	Type        string
+	Name        string
+	Description string
+}
+
+// RouteAnnotation @Router 注释，一个处理器可以挂载在多个路由上
+type RouteAnnotation struct {
+	Path   string // OpenAPI 形式的路径，如 /api/v1/users/{id}
+	Method string // 小写的请求方法
+}
+
+// ParseHandlers 解析目录下所有处理器函数的接口注释，importPath 为该目录的包导入路径
+func ParseHandlers(dir, importPath string) ([]*Annotation, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	// 与 swag 一样，注释中的类型可以引用同一个包中其他文件导入的包
+	parsedFiles := make([]*ast.File, 0, len(files))
+	packageImports := make(map[string]string)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		parsedFiles = append(parsedFiles, parsed)
+		for alias, path := range FileImports(parsed) {
+			packageImports[alias] = path
+		}
+	}
+
+	var annotations []*Annotation
+	for _, parsed := range parsedFiles {
+		imports := make(map[string]string, len(packageImports))
+		for alias, path := range packageImports {
+			imports[alias] = path
+		}
+		for alias, path := range FileImports(parsed) {
+			imports[alias] = path
+		}
+
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			annotation, err := parseAnnotation(fn, importPath)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fset.Position(fn.Pos()), err)
+			}
+			if annotation != nil {
+				annotation.Position = fset.Position(fn.Doc.Pos()).String()
+				annotation.imports = imports
+				annotations = append(annotations, annotation)
+			}
+		}
+	}
+	return annotations, nil
+}
+
+// parseAnnotation 解析单个函数的注释，没有 @Router 时返回 nil
+func parseAnnotation(fn *ast.FuncDecl, importPath string) (*Annotation, error) {
+	annotation := &Annotation{}
+	var (
+		description []string
+		list        bool
+	)
+
+	for _, line := range strings.Split(fn.Doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		attribute, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(attribute) {
+		case "@summary":
+			annotation.Summary = value
+		case "@description":
+			description = append(description, value)
+		case "@tags":
+			annotation.Tags = append(annotation.Tags, splitList(value)...)
+		case "@accept":
+			annotation.Accept = append(annotation.Accept, splitList(value)...)
+		case "@produce":
+			annotation.Produce = append(annotation.Produce, splitList(value)...)
+		case "@security":
+			annotation.Security = append(annotation.Security, value)
+		case "@param":
+			param, err := parseParam(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Params = append(annotation.Params, param)
+		case "@paginate", "@sort":
+			expand := paginateParams
+			if strings.EqualFold(attribute, "@sort") {
+				expand = sortParams
+			}
+			params, err := expand(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Params = append(annotation.Params, params...)
+			list = true
+		case "@paginatedsuccess":
+			response, err := paginatedSuccess(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Responses = append(annotation.Responses, response)
+		case "@success", "@failure":
+			response, err := parseResponse(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Responses = append(annotation.Responses, response)
+		case "@header":
+			header, err := parseHeader(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Headers = append(annotation.Headers, header)
+		case "@router":
+			route, err := parseRoute(value)
+			if err != nil {
+				return nil, err
+			}
+			annotation.Routes = append(annotation.Routes, route)
+		}
+	}
+
+	if len(annotation.Routes) == 0 {
+		return nil, nil
+	}
+	if list {
+		addListFailure(annotation)
+	}
+	annotation.Description = strings.Join(description, "\n")
+	annotation.Func, annotation.Handler = handlerName(fn, importPath)
+	return annotation, nil
+}
+
+// handlerName 返回函数的接收者和函数名，以及 gin 路由表中方法值的名称
+func handlerName(fn *ast.FuncDecl, importPath string) (string, string) {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name, importPath + "." + fn.Name.Name
+	}
+
+	switch recv := fn.Recv.List[0].Type.(type) {
+	case *ast.StarExpr:
+		if ident, ok := recv.X.(*ast.Ident); ok {
+			return ident.Name + "." + fn.Name.Name, fmt.Sprintf("%s.(*%s).%s-fm", importPath, ident.Name, fn.Name.Name)
+		}
+	case *ast.Ident:
+		return recv.Name + "." + fn.Name.Name, fmt.Sprintf("%s.%s.%s-fm", importPath, recv.Name, fn.Name.Name)
+	}
+	return fn.Name.Name, importPath + "." + fn.Name.Name
+}
+
+// parseParam 解析 @Param name in type required "description" [attributes...]
+func parseParam(value string) (ParamAnnotation, error) {
+	fields := splitFields(value)
+	if len(fields) < 4 {
+		return ParamAnnotation{}, fmt.Errorf("invalid @Param %q", value)
+	}
+
+	param := ParamAnnotation{
+		Name:       fields[0],
+		In:         fields[1],
+		Type:       fields[2],
+		Required:   fields[3] == "true",
+		Attributes: make(map[string]string),
+	}
+	for _, field := range fields[4:] {
+		if isQuoted(field) {
+			param.Description = unquote(field)
+			continue
+		}
+		if name, arg, ok := strings.Cut(field, "("); ok && strings.HasSuffix(arg, ")") {
+			param.Attributes[strings.ToLower(name)] = strings.TrimSuffix(arg, ")")
+		}
+	}
+	return param, nil
+}
+
+// parseResponse 解析 @Success/@Failure code {kind} type "description" 或 code "description"
+func parseResponse(value string) (ResponseAnnotation, error) {
+	fields := splitFields(value)
+	if len(fields) < 2 {
+		return ResponseAnnotation{}, fmt.Errorf("invalid response annotation %q", value)
+	}
+
+	response := ResponseAnnotation{Code: fields[0]}
+	rest := fields[1:]
+	if strings.HasPrefix(rest[0], "{") {
+		if len(rest) < 2 {
+			return ResponseAnnotation{}, fmt.Errorf("invalid response annotation %q", value)
+		}
+		response.Kind = strings.Trim(rest[0], "{}")
+		response.Type = rest[1]
+		rest = rest[2:]
+	}
+	if len(rest) > 0 && isQuoted(rest[0]) {
+		response.Description = unquote(rest[0])
+	}
+	return response, nil
+}
+
+// parseHeader 解析 @Header codes {type} name "description"
+func parseHeader(value string) (HeaderAnnotation, error) {
+	fields := splitFields(value)
+	if len(fields) < 3 {
+		return HeaderAnnotation{}, fmt.Errorf("invalid @Header %q", value)
+	}
+
+	header := HeaderAnnotation{
+		Codes: splitList(fields[0]),
+		Type:  strings.Trim(fields[1], "{}"),
+		Name:  fields[2],
+	}
+	if len(fields) > 3 {
+		header.Description = unquote(fields[3])
+	}
+	return header, nil
+}
+
+// parseRoute 解析 @Router /path [method]
+func parseRoute(value string) (RouteAnnotation, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "[") || !strings.HasSuffix(fields[1], "]") {
+		return RouteAnnotation{}, fmt.Errorf("invalid @Router %q", value)
+	}
+	return RouteAnnotation{
+		Path:   fields[0],
+		Method: strings.ToLower(strings.Trim(fields[1], "[]")),
+	}, nil
+}
+
+// splitFields 按空白分割注释参数，引号、花括号和圆括号内的空白不分割
+func splitFields(value string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		quoted  bool
+		depth   int
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			fields = append(fields, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '{' || r == '(':
+			depth++
+		case r == '}' || r == ')':
+			depth--
+		case (r == ' ' || r == '\t') && depth == 0:
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return fields
+}
+
+// splitList 分割逗号分隔的列表
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isQuoted(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+}
+
+func unquote(value string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+}
+
+// GeneralInfo main 包上的文档基本信息注释
+type GeneralInfo struct {
+	Info            Info
+	Servers         []Server
+	SecuritySchemes map[string]*SecurityScheme
+}
+
+// ParseGeneralInfo 解析文件中的 @title、@version、@description、@host、@BasePath 和
+// @securityDefinitions.bearer 等文档基本信息注释
+func ParseGeneralInfo(file string) (*GeneralInfo, error) {
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	general := &GeneralInfo{SecuritySchemes: make(map[string]*SecurityScheme)}
+	var (
+		host, basePath string
+		scheme         *SecurityScheme
+	)
+	for _, group := range parsed.Comments {
+		for _, line := range strings.Split(group.Text(), "\n") {
+			attribute, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			value = strings.TrimSpace(value)
+
+			switch strings.ToLower(attribute) {
+			case "@title":
+				general.Info.Title = value
+			case "@version":
+				general.Info.Version = value
+			case "@description":
+				if scheme != nil {
+					scheme.Description = value
+				} else {
+					general.Info.Description = value
+				}
+			case "@termsofservice":
+				general.Info.TermsOfService = value
+			case "@contact.name", "@contact.url", "@contact.email":
+				if general.Info.Contact == nil {
+					general.Info.Contact = &Contact{}
+				}
+				switch strings.ToLower(attribute) {
+				case "@contact.name":
+					general.Info.Contact.Name = value
+				case "@contact.url":
+					general.Info.Contact.URL = value
+				default:
+					general.Info.Contact.Email = value
+				}
+			case "@license.name":
+				if general.Info.License == nil {
+					general.Info.License = &License{}
+				}
+				general.Info.License.Name = value
+			case "@license.url":
+				if general.Info.License == nil {
+					general.Info.License = &License{}
+				}
+				general.Info.License.URL = value
+			case "@host":
+				host = value
+			case "@basepath":
+				basePath = value
+			case "@securitydefinitions.bearer":
+				scheme = &SecurityScheme{Type: "http", Scheme: "bearer"}
+				general.SecuritySchemes[value] = scheme
+			case "@bearerformat":
+				if scheme != nil {
+					scheme.BearerFormat = value
+				}
+			}
+		}
+	}
+
+	if host != "" {
+		general.Servers = []Server{{URL: "http://" + host + strings.TrimSuffix(basePath, "/")}}
+	}
+	return general, nil
+}
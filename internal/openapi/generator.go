@@ -0,0 +1,362 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route gin 路由表中的一条路由
+type Route struct {
+	Method  string // 大写的请求方法
+	Path    string // gin 形式的路径，如 /api/v1/users/:id
+	Handler string // 处理函数名，与 gin.RouteInfo.Handler 相同
+}
+
+// Options 文档生成选项
+type Options struct {
+	Root        string   // 模块根目录
+	Module      string   // 模块路径，如 go-server
+	HandlersDir string   // 处理器目录，相对于 Root
+	MainFile    string   // 文档基本信息注释所在的文件，相对于 Root
+	Routes      []Route  // 实际注册的路由
+	IgnorePaths []string // 不需要文档的路由（gin 形式），如 Swagger UI
+}
+
+// mimeTypes @Accept 和 @Produce 中的简写对应的内容类型
+var mimeTypes = map[string]string{
+	"json":                  "application/json",
+	"xml":                   "application/xml",
+	"plain":                 "text/plain",
+	"html":                  "text/html",
+	"mpfd":                  "multipart/form-data",
+	"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+	"octet-stream":          "application/octet-stream",
+	"event-stream":          "text/event-stream",
+}
+
+// Generate 根据处理器注释生成实际注册的路由的文档
+// 返回的问题列表记录路由表与注释不一致之处：注册了但没有注释的路由，以及注释了但没有注册的路由；
+// 另外记录用 @Param 单独声明分页或排序参数、没有使用 @Paginate 和 @Sort 的列表接口
+func Generate(opts Options) (*Document, []string, error) {
+	general, err := ParseGeneralInfo(filepath.Join(opts.Root, opts.MainFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handlersImportPath := opts.Module + "/" + filepath.ToSlash(opts.HandlersDir)
+	annotations, err := ParseHandlers(filepath.Join(opts.Root, opts.HandlersDir), handlersImportPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	byHandler := make(map[string]*Annotation, len(annotations))
+	for _, annotation := range annotations {
+		byHandler[annotation.Handler] = annotation
+	}
+
+	resolver := NewSchemaResolver(opts.Root, opts.Module)
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    general.Info,
+		Servers: general.Servers,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: general.SecuritySchemes,
+		},
+	}
+
+	routes := slices.Clone(opts.Routes)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	var problems []string
+	registered := make(map[string]bool)
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		if slices.Contains(opts.IgnorePaths, route.Path) {
+			continue
+		}
+		method, path := strings.ToLower(route.Method), openAPIPath(route.Path)
+		registered[method+" "+path] = true
+
+		annotation, ok := byHandler[route.Handler]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s %s: handler %s has no annotations", route.Method, path, route.Handler))
+			continue
+		}
+		index := slices.Index(annotation.Routes, RouteAnnotation{Path: path, Method: method})
+		if index < 0 {
+			problems = append(problems, fmt.Sprintf("%s %s: %s (%s) has no @Router annotation for this route",
+				route.Method, path, annotation.Func, annotation.Position))
+			continue
+		}
+
+		op, err := buildOperation(annotation, index, resolver)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s (%s): %w", annotation.Func, annotation.Position, err)
+		}
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		item.set(method, op)
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+	}
+
+	// 已注册的处理器上注释了、但没有注册的路由，以及没有使用共享注释的列表参数
+	for _, annotation := range annotations {
+		if !handlerRegistered(routes, annotation.Handler) {
+			continue
+		}
+		if problem := listProblem(annotation); problem != "" {
+			problems = append(problems, problem)
+		}
+		for _, route := range annotation.Routes {
+			if !registered[route.Method+" "+route.Path] {
+				problems = append(problems, fmt.Sprintf("%s %s: documented by %s (%s) but not registered",
+					strings.ToUpper(route.Method), route.Path, annotation.Func, annotation.Position))
+			}
+		}
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = resolver.Schemas()
+
+	return doc, problems, nil
+}
+
+// buildOperation 将注释转换为接口文档，index 为当前路由在注释中的位置
+func buildOperation(annotation *Annotation, index int, resolver *SchemaResolver) (*Operation, error) {
+	op := &Operation{
+		OperationID: operationID(annotation.Func, index),
+		Summary:     annotation.Summary,
+		Description: annotation.Description,
+		Tags:        annotation.Tags,
+		Responses:   make(map[string]*Response),
+	}
+	for _, name := range annotation.Security {
+		op.Security = append(op.Security, SecurityRequirement{name: {}})
+	}
+
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, param := range annotation.Params {
+		schema, err := resolver.Resolve(param.Type, annotation.imports)
+		if err != nil {
+			return nil, fmt.Errorf("@Param %s: %w", param.Name, err)
+		}
+		applyParamAttributes(schema, param.Attributes)
+
+		switch param.In {
+		case "body":
+			op.RequestBody = &RequestBody{
+				Description: param.Description,
+				Required:    param.Required,
+				Content:     contentFor(annotation.Accept, schema),
+			}
+		case "formData":
+			schema.Description = param.Description
+			form.Properties[param.Name] = schema
+			if param.Required {
+				form.Required = append(form.Required, param.Name)
+			}
+		default:
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:        param.Name,
+				In:          param.In,
+				Description: param.Description,
+				Required:    param.Required || param.In == "path",
+				Schema:      schema,
+			})
+		}
+	}
+	if len(form.Properties) > 0 {
+		// 表单参数默认使用 multipart/form-data，@Accept x-www-form-urlencoded 时使用 URL 编码表单
+		formTypes := []string{"multipart/form-data"}
+		if slices.Contains(annotation.Accept, "x-www-form-urlencoded") {
+			formTypes = []string{"x-www-form-urlencoded"}
+		}
+		op.RequestBody = &RequestBody{
+			Required: len(form.Required) > 0,
+			Content:  contentFor(formTypes, form),
+		}
+	}
+
+	for _, annotated := range annotation.Responses {
+		response := responseFor(op, annotated.Code)
+		if annotated.Description != "" {
+			if response.Description == statusText(annotated.Code) {
+				response.Description = annotated.Description
+			} else {
+				response.Description += "; " + annotated.Description
+			}
+		}
+		if annotated.Kind == "" || response.Content != nil {
+			continue
+		}
+
+		schema, err := resolver.Resolve(annotated.Type, annotation.imports)
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", annotated.Code, err)
+		}
+		if annotated.Kind == "array" {
+			schema = &Schema{Type: "array", Items: schema}
+		}
+		response.Content = contentFor(producedFor(annotation.Produce, annotated.Kind), schema)
+	}
+
+	for _, header := range annotation.Headers {
+		codes := header.Codes
+		if slices.Contains(codes, "all") {
+			codes = make([]string, 0, len(op.Responses))
+			for code := range op.Responses {
+				codes = append(codes, code)
+			}
+		}
+		for _, code := range codes {
+			response := responseFor(op, code)
+			if response.Headers == nil {
+				response.Headers = make(map[string]*Header)
+			}
+			schema, ok := primitiveSchema(header.Type)
+			if !ok {
+				schema = &Schema{Type: "string"}
+			}
+			response.Headers[header.Name] = &Header{Description: header.Description, Schema: schema}
+		}
+	}
+	return op, nil
+}
+
+// responseFor 返回状态码对应的响应，不存在时创建
+func responseFor(op *Operation, code string) *Response {
+	response, ok := op.Responses[code]
+	if !ok {
+		response = &Response{Description: statusText(code)}
+		op.Responses[code] = response
+	}
+	return response
+}
+
+// contentFor 按内容类型简写生成响应或请求体内容，未声明时使用 JSON
+func contentFor(types []string, schema *Schema) map[string]*MediaType {
+	if len(types) == 0 {
+		types = []string{"json"}
+	}
+	content := make(map[string]*MediaType, len(types))
+	for _, short := range types {
+		mime, ok := mimeTypes[short]
+		if !ok {
+			mime = short
+		}
+		content[mime] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+// producedFor 返回响应体使用的内容类型：@Produce 同时声明了 JSON 和其他类型时，
+// 对象和数组响应（如错误响应）只使用 JSON 类型，字符串响应（如文件和事件流）只使用其他类型
+func producedFor(types []string, kind string) []string {
+	var json, other []string
+	for _, short := range types {
+		mime, ok := mimeTypes[short]
+		if !ok {
+			mime = short
+		}
+		if mime == "application/json" || strings.HasSuffix(mime, "+json") {
+			json = append(json, short)
+		} else {
+			other = append(other, short)
+		}
+	}
+	if len(json) == 0 || len(other) == 0 {
+		return types
+	}
+	if kind == "string" {
+		return other
+	}
+	return json
+}
+
+// applyParamAttributes 将 default、minimum、maximum、enums 等属性写入参数结构
+func applyParamAttributes(schema *Schema, attributes map[string]string) {
+	for name, value := range attributes {
+		switch name {
+		case "default":
+			schema.Default = exampleValue(value, schema)
+		case "minimum":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				schema.Minimum = &f
+			}
+		case "maximum":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				schema.Maximum = &f
+			}
+		case "enums":
+			for _, item := range splitList(value) {
+				schema.Enum = append(schema.Enum, exampleValue(item, schema))
+			}
+		}
+	}
+}
+
+// operationID 根据处理函数生成接口ID，如 UserHandler.GetUser 对应 userGetUser
+func operationID(fn string, index int) string {
+	receiver, name, ok := strings.Cut(fn, ".")
+	if !ok {
+		receiver, name = "", fn
+	}
+	receiver = strings.TrimSuffix(receiver, "Handler")
+	id := name
+	if receiver != "" {
+		id = strings.ToLower(receiver[:1]) + receiver[1:] + name
+	}
+	if index > 0 {
+		id += strconv.Itoa(index + 1)
+	}
+	return id
+}
+
+// openAPIPath 将 gin 路径参数 :id 和 *path 转换为 {id} 和 {path}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// handlerRegistered 判断处理函数是否注册在某个路由上
+func handlerRegistered(routes []Route, handler string) bool {
+	for _, route := range routes {
+		if route.Handler == handler {
+			return true
+		}
+	}
+	return false
+}
+
+// statusText 返回状态码的默认描述
+func statusText(code string) string {
+	if n, err := strconv.Atoi(code); err == nil {
+		if text := http.StatusText(n); text != "" {
+			return text
+		}
+	}
+	return "Response"
+}
@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 列表接口的共享注释，展开为统一的参数和响应，新增列表接口时文档保持一致：
+//
+//	@Paginate [default(20)] [maximum(100)]     分页参数 page 和 limit，并在未声明 400 响应时补充参数错误响应
+//	@Sort username,created_at [default(name)]  排序参数 sort（可选字段）和 order（asc 或 desc，默认 desc）
+//	@PaginatedSuccess models.SafeUser "说明"    200 响应：统一响应结构中的分页结构，data 为该类型的数组
+//
+// 筛选条件仍用 @Param 声明为普通查询参数
+const (
+	// DefaultPageLimit @Paginate 未指定 default 时的每页数量
+	DefaultPageLimit = 10
+
+	// MaxPageLimit @Paginate 未指定 maximum 时的每页数量上限，与处理器的校验一致
+	MaxPageLimit = 100
+
+	// paginatedEnvelope 分页列表的 200 响应结构
+	paginatedEnvelope = "models.SuccessResponse{data=models.PaginatedResponse{data=[]%s}}"
+
+	// listValidationFailure 分页、排序或筛选参数错误时的响应结构
+	listValidationFailure = "models.ErrorResponse{error=models.EnhancedErrorResponse}"
+)
+
+// listParamNames 列表接口不应再用 @Param 单独声明的查询参数
+// 单独的 limit（如返回前 N 项）不是分页参数，不做检查
+var listParamNames = []string{"page", "sort", "order"}
+
+// paginateParams 展开 @Paginate 为 page 和 limit 参数
+func paginateParams(value string) ([]ParamAnnotation, error) {
+	attributes, err := listAttributes("@Paginate", value)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intAttribute(attributes, "default", DefaultPageLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid @Paginate %q: %w", value, err)
+	}
+	maximum, err := intAttribute(attributes, "maximum", MaxPageLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid @Paginate %q: %w", value, err)
+	}
+	if limit < 1 || limit > maximum {
+		return nil, fmt.Errorf("invalid @Paginate %q: default must be between 1 and %d", value, maximum)
+	}
+
+	return []ParamAnnotation{
+		{
+			Name:        "page",
+			In:          "query",
+			Type:        "int",
+			Description: "页码",
+			shared:      true,
+			Attributes:  map[string]string{"default": "1", "minimum": "1"},
+		},
+		{
+			Name:        "limit",
+			In:          "query",
+			Type:        "int",
+			Description: "每页项目数量",
+			shared:      true,
+			Attributes: map[string]string{
+				"default": strconv.Itoa(limit),
+				"minimum": "1",
+				"maximum": strconv.Itoa(maximum),
+			},
+		},
+	}, nil
+}
+
+// sortParams 展开 @Sort 为 sort 和 order 参数，未指定 default 时按第一个字段排序
+func sortParams(value string) ([]ParamAnnotation, error) {
+	fields := splitFields(value)
+	if len(fields) == 0 || strings.Contains(fields[0], "(") {
+		return nil, fmt.Errorf("invalid @Sort %q: sortable fields are required", value)
+	}
+	sortable := splitList(fields[0])
+	attributes, err := listAttributes("@Sort", strings.Join(fields[1:], " "))
+	if err != nil {
+		return nil, err
+	}
+	defaultField := sortable[0]
+	if field, ok := attributes["default"]; ok {
+		if !slices.Contains(sortable, field) {
+			return nil, fmt.Errorf("invalid @Sort %q: default %q is not sortable", value, field)
+		}
+		defaultField = field
+	}
+
+	return []ParamAnnotation{
+		{
+			Name:        "sort",
+			In:          "query",
+			Type:        "string",
+			Description: "排序字段",
+			shared:      true,
+			Attributes:  map[string]string{"default": defaultField, "enums": strings.Join(sortable, ",")},
+		},
+		{
+			Name:        "order",
+			In:          "query",
+			Type:        "string",
+			Description: "排序方向",
+			shared:      true,
+			Attributes:  map[string]string{"default": "desc", "enums": "asc,desc"},
+		},
+	}, nil
+}
+
+// paginatedSuccess 展开 @PaginatedSuccess 为 200 响应
+func paginatedSuccess(value string) (ResponseAnnotation, error) {
+	fields := splitFields(value)
+	if len(fields) == 0 || isQuoted(fields[0]) {
+		return ResponseAnnotation{}, fmt.Errorf("invalid @PaginatedSuccess %q: item type is required", value)
+	}
+
+	response := ResponseAnnotation{
+		Code: "200",
+		Kind: "object",
+		Type: fmt.Sprintf(paginatedEnvelope, fields[0]),
+	}
+	if len(fields) > 1 && isQuoted(fields[1]) {
+		response.Description = unquote(fields[1])
+	}
+	return response, nil
+}
+
+// addListFailure 列表接口未声明 400 响应时补充参数错误响应
+func addListFailure(annotation *Annotation) {
+	for _, response := range annotation.Responses {
+		if response.Code == "400" {
+			return
+		}
+	}
+	annotation.Responses = append(annotation.Responses, ResponseAnnotation{
+		Code:        "400",
+		Kind:        "object",
+		Type:        listValidationFailure,
+		Description: "查询参数错误",
+	})
+}
+
+// listProblem 检查列表接口是否绕过共享注释，单独声明了分页或排序参数
+func listProblem(annotation *Annotation) string {
+	for _, param := range annotation.Params {
+		if param.In == "query" && slices.Contains(listParamNames, param.Name) && !param.shared {
+			return fmt.Sprintf("%s (%s) declares query parameter %q with @Param, use @Paginate or @Sort",
+				annotation.Func, annotation.Position, param.Name)
+		}
+	}
+	return ""
+}
+
+// listAttributes 解析共享注释的 name(value) 属性
+func listAttributes(directive, value string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, field := range splitFields(value) {
+		name, arg, ok := strings.Cut(field, "(")
+		if !ok || !strings.HasSuffix(arg, ")") {
+			return nil, fmt.Errorf("invalid %s %q: unexpected %q", directive, value, field)
+		}
+		attributes[strings.ToLower(name)] = strings.TrimSuffix(arg, ")")
+	}
+	return attributes, nil
+}
+
+// intAttribute 读取整数属性，未指定时返回默认值
+func intAttribute(attributes map[string]string, name string, fallback int) (int, error) {
+	value, ok := attributes[name]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
@@ -0,0 +1,402 @@
+package openapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModels = `package models
+
+// Status 账户状态
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusDisabled Status = "disabled"
+)
+
+// Envelope 统一响应
+type Envelope struct {
+	Success bool        ` + "`json:\"success\"`" + `
+	Data    interface{} ` + "`json:\"data,omitempty\"`" + `
+}
+
+// Base 公共字段
+type Base struct {
+	ID string ` + "`json:\"id\" binding:\"required,uuid\"`" + `
+}
+
+// CreateRequest 创建请求
+type CreateRequest struct {
+	Base
+	Name   string   ` + "`json:\"name\" binding:\"required,min=3,max=20\" example:\"alice\"`" + `
+	Status Status   ` + "`json:\"status\"`" + `
+	Role   string   ` + "`json:\"role\" binding:\"omitempty,oneof=user admin\"`" + `
+	Tags   []string ` + "`json:\"tags\" binding:\"max=5\"`" + `
+	Note   *string  ` + "`json:\"note\"`" + `
+	Secret string   ` + "`json:\"-\"`" + `
+}
+`
+
+const testHandlers = `package handlers
+
+import (
+	"example.com/app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ItemHandler struct{}
+
+// Create godoc
+// @Summary Create item
+// @Description Create an item.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Param limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param request body models.CreateRequest true "Item"
+// @Success 201 {object} models.Envelope{data=models.CreateRequest} "Created"
+// @Failure 400 {object} models.Envelope "Invalid input"
+// @Failure 304 "Not modified"
+// @Header 201,400 {string} X-Correlation-ID "Correlation ID"
+// @Router /items/{id} [post]
+// @Router /v2/items/{id} [post]
+func (h *ItemHandler) Create(c *gin.Context) {}
+
+// Undocumented 没有 @Router 的函数不是接口
+func (h *ItemHandler) Undocumented(c *gin.Context) {}
+
+// Stale godoc
+// @Summary Removed route
+// @Router /stale [get]
+func (h *ItemHandler) Stale(c *gin.Context) {}
+
+// Download godoc
+// @Summary Download item
+// @Produce json,octet-stream
+// @Success 200 {string} string "File"
+// @Failure 404 {object} models.Envelope "Not found"
+// @Router /items/{id}/file [get]
+func (h *ItemHandler) Download(c *gin.Context) {}
+
+// Token godoc
+// @Summary Exchange token
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param code formData string true "Code"
+// @Success 200 {object} models.Envelope
+// @Router /token [post]
+func (h *ItemHandler) Token(c *gin.Context) {}
+`
+
+const testMain = `package main
+
+// @title Test API
+// @version 2.0
+// @description Test description.
+// @host localhost:9000
+// @BasePath /
+// @securityDefinitions.bearer BearerAuth
+// @bearerFormat JWT
+// @description JWT access token.
+func main() {}
+`
+
+// writeTestModule 在临时目录中创建测试用的模块源码
+func writeTestModule(t *testing.T) string {
+	root := t.TempDir()
+	files := map[string]string{
+		"models/models.go":     testModels,
+		"handlers/handlers.go": testHandlers,
+		"main.go":              testMain,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+// TestParseHandlers 测试解析处理器注释
+func TestParseHandlers(t *testing.T) {
+	root := writeTestModule(t)
+
+	annotations, err := ParseHandlers(filepath.Join(root, "handlers"), "example.com/app/handlers")
+	require.NoError(t, err)
+	require.Len(t, annotations, 4)
+
+	create := annotations[0]
+	assert.Equal(t, "ItemHandler.Create", create.Func)
+	assert.Equal(t, "example.com/app/handlers.(*ItemHandler).Create-fm", create.Handler)
+	assert.Equal(t, []string{"items"}, create.Tags)
+	assert.Equal(t, []string{"BearerAuth"}, create.Security)
+	assert.Equal(t, []RouteAnnotation{{Path: "/items/{id}", Method: "post"}, {Path: "/v2/items/{id}", Method: "post"}}, create.Routes)
+
+	require.Len(t, create.Params, 3)
+	assert.Equal(t, ParamAnnotation{
+		Name:        "limit",
+		In:          "query",
+		Type:        "int",
+		Description: "Page size",
+		Attributes:  map[string]string{"default": "10", "minimum": "1", "maximum": "100"},
+	}, create.Params[1])
+
+	assert.Equal(t, []ResponseAnnotation{
+		{Code: "201", Kind: "object", Type: "models.Envelope{data=models.CreateRequest}", Description: "Created"},
+		{Code: "400", Kind: "object", Type: "models.Envelope", Description: "Invalid input"},
+		{Code: "304", Description: "Not modified"},
+	}, create.Responses)
+	assert.Equal(t, []HeaderAnnotation{{Codes: []string{"201", "400"}, Type: "string", Name: "X-Correlation-ID", Description: "Correlation ID"}}, create.Headers)
+}
+
+// TestSchemaResolver 测试将结构体、校验规则和类型化常量解析为 JSON Schema
+func TestSchemaResolver(t *testing.T) {
+	root := writeTestModule(t)
+	resolver := NewSchemaResolver(root, "example.com/app")
+	imports := map[string]string{"models": "example.com/app/models"}
+
+	schema, err := resolver.Resolve("models.Envelope{data=[]models.CreateRequest}", imports)
+	require.NoError(t, err)
+	require.Len(t, schema.AllOf, 2)
+	assert.Equal(t, "#/components/schemas/models.Envelope", schema.AllOf[0].Ref)
+	data := schema.AllOf[1].Properties["data"]
+	assert.Equal(t, "array", data.Type)
+	assert.Equal(t, "#/components/schemas/models.CreateRequest", data.Items.Ref)
+
+	request := resolver.Schemas()["models.CreateRequest"]
+	require.NotNil(t, request)
+	assert.Equal(t, "创建请求", request.Description)
+	assert.ElementsMatch(t, []string{"id", "name"}, request.Required)
+	assert.NotContains(t, request.Properties, "Secret")
+	assert.NotContains(t, request.Properties, "-")
+
+	assert.Equal(t, "uuid", request.Properties["id"].Format)
+	name := request.Properties["name"]
+	assert.Equal(t, 3, *name.MinLength)
+	assert.Equal(t, 20, *name.MaxLength)
+	assert.Equal(t, []interface{}{"alice"}, name.Examples)
+	assert.Equal(t, []interface{}{"active", "disabled"}, request.Properties["status"].Enum)
+	assert.Equal(t, []interface{}{"user", "admin"}, request.Properties["role"].Enum)
+	assert.Equal(t, 5, *request.Properties["tags"].MaxItems)
+	assert.Equal(t, []string{"string", "null"}, request.Properties["note"].Type)
+}
+
+// TestGenerate 测试生成文档以及路由表与注释不一致的检查
+func TestGenerate(t *testing.T) {
+	root := writeTestModule(t)
+	handler := "example.com/app/handlers.(*ItemHandler).Create-fm"
+
+	doc, problems, err := Generate(Options{
+		Root:        root,
+		Module:      "example.com/app",
+		HandlersDir: "handlers",
+		MainFile:    "main.go",
+		Routes: []Route{
+			{Method: "POST", Path: "/items/:id", Handler: handler},
+			{Method: "PUT", Path: "/items/:id", Handler: handler},
+			{Method: "GET", Path: "/stale", Handler: "example.com/app/handlers.(*ItemHandler).Stale-fm"},
+			{Method: "GET", Path: "/items/:id/file", Handler: "example.com/app/handlers.(*ItemHandler).Download-fm"},
+			{Method: "POST", Path: "/token", Handler: "example.com/app/handlers.(*ItemHandler).Token-fm"},
+			{Method: "GET", Path: "/anonymous", Handler: "example.com/app/routes.func1"},
+			{Method: "GET", Path: "/swagger/*any", Handler: "ignored"},
+		},
+		IgnorePaths: []string{"/swagger/*any"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, "Test API", doc.Info.Title)
+	assert.Equal(t, "Test description.", doc.Info.Description)
+	assert.Equal(t, []Server{{URL: "http://localhost:9000"}}, doc.Servers)
+	assert.Equal(t, &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "JWT access token."},
+		doc.Components.SecuritySchemes["BearerAuth"])
+
+	op := doc.Paths["/items/{id}"].Post
+	require.NotNil(t, op)
+	assert.Equal(t, "itemCreate", op.OperationID)
+	assert.Equal(t, []SecurityRequirement{{"BearerAuth": {}}}, op.Security)
+	require.Len(t, op.Parameters, 2)
+	assert.True(t, op.Parameters[0].Required)
+	assert.Equal(t, int64(10), op.Parameters[1].Schema.Default)
+	assert.Equal(t, "#/components/schemas/models.CreateRequest", op.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, op.Responses["201"].Headers, "X-Correlation-ID")
+	assert.Equal(t, "Not modified", op.Responses["304"].Description)
+	assert.Nil(t, op.Responses["304"].Content)
+	assert.Contains(t, doc.Components.Schemas, "models.Envelope")
+
+	// 同时声明 JSON 和其他内容类型时，字符串响应使用其他类型，对象响应使用 JSON
+	download := doc.Paths["/items/{id}/file"].Get
+	require.NotNil(t, download)
+	assert.Equal(t, []string{"application/octet-stream"}, sortedContentTypes(download.Responses["200"].Content))
+	assert.Equal(t, []string{"application/json"}, sortedContentTypes(download.Responses["404"].Content))
+
+	// @Accept x-www-form-urlencoded 的表单参数使用 URL 编码表单
+	token := doc.Paths["/token"].Post
+	require.NotNil(t, token)
+	assert.Equal(t, []string{"application/x-www-form-urlencoded"}, sortedContentTypes(token.RequestBody.Content))
+	assert.Equal(t, []string{"code"}, token.RequestBody.Content["application/x-www-form-urlencoded"].Schema.Required)
+
+	position := filepath.Join(root, "handlers", "handlers.go") + ":11:1"
+	assert.Equal(t, []string{
+		"GET /anonymous: handler example.com/app/routes.func1 has no annotations",
+		"PUT /items/{id}: ItemHandler.Create (" + position + ") has no @Router annotation for this route",
+		"POST /v2/items/{id}: documented by ItemHandler.Create (" + position + ") but not registered",
+	}, problems)
+}
+
+const listTestHandlers = `package handlers
+
+import "github.com/gin-gonic/gin"
+
+type OrderHandler struct{}
+
+// List godoc
+// @Summary List orders
+// @Param status query string false "Status"
+// @Paginate default(20)
+// @Sort created_at,total default(total)
+// @PaginatedSuccess models.Order "Orders"
+// @Router /orders [get]
+func (h *OrderHandler) List(c *gin.Context) {}
+
+// Legacy godoc
+// @Summary Legacy list
+// @Param page query int false "Page" default(1)
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope "Bad query"
+// @Router /legacy [get]
+func (h *OrderHandler) Legacy(c *gin.Context) {}
+`
+
+// TestListAnnotations 测试列表接口的共享注释展开为统一的参数和响应
+func TestListAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "handlers.go"), []byte(listTestHandlers), 0644))
+
+	annotations, err := ParseHandlers(dir, "example.com/app/handlers")
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+
+	list := annotations[0]
+	names := make([]string, len(list.Params))
+	for i, param := range list.Params {
+		names[i] = param.Name
+	}
+	assert.Equal(t, []string{"status", "page", "limit", "sort", "order"}, names)
+	assert.Equal(t, map[string]string{"default": "20", "minimum": "1", "maximum": "100"}, list.Params[2].Attributes)
+	assert.Equal(t, map[string]string{"default": "total", "enums": "created_at,total"}, list.Params[3].Attributes)
+	assert.Equal(t, []ResponseAnnotation{
+		{Code: "200", Kind: "object", Type: "models.SuccessResponse{data=models.PaginatedResponse{data=[]models.Order}}", Description: "Orders"},
+		{Code: "400", Kind: "object", Type: "models.ErrorResponse{error=models.EnhancedErrorResponse}", Description: "查询参数错误"},
+	}, list.Responses)
+	assert.Empty(t, listProblem(list))
+
+	// 单独声明分页参数的列表接口报告为不一致
+	assert.Contains(t, listProblem(annotations[1]), `declares query parameter "page" with @Param`)
+
+	_, err = sortParams("created_at,total default(name)")
+	assert.Error(t, err, "默认排序字段必须可排序")
+	_, err = paginateParams("default(500)")
+	assert.Error(t, err, "默认每页数量不能超过上限")
+}
+
+// sortedContentTypes 返回排序后的内容类型
+func sortedContentTypes(content map[string]*MediaType) []string {
+	types := make([]string, 0, len(content))
+	for mime := range content {
+		types = append(types, mime)
+	}
+	sort.Strings(types)
+	return types
+}
+
+const responseTestSpec = `{
+  "openapi": "3.1.0",
+  "info": {"title": "test", "version": "1"},
+  "paths": {
+    "/items/{id}": {
+      "get": {
+        "operationId": "getItem",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {"X-Count": {"schema": {"type": "integer", "minimum": 0}}},
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}
+          },
+          "304": {"description": "Not modified"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Item": {
+        "type": "object",
+        "required": ["id", "name"],
+        "properties": {"id": {"type": "string", "format": "uuid"}, "name": {"type": "string"}}
+      }
+    }
+  }
+}`
+
+// TestValidateResponse 测试按文档校验响应
+func TestValidateResponse(t *testing.T) {
+	validator, err := NewRequestValidator([]byte(responseTestSpec))
+	require.NoError(t, err)
+	op, ok := validator.Operation("GET", "/items/:id")
+	require.True(t, ok)
+
+	jsonHeader := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	valid := []byte(`{"id":"0b7e6c4e-6f0e-4c55-9a43-4bd0f4f6d6a1","name":"item"}`)
+
+	t.Run("符合文档", func(t *testing.T) {
+		assert.Empty(t, validator.ValidateResponse(op, http.StatusOK, jsonHeader, valid, true))
+		assert.Empty(t, validator.ValidateResponse(op, http.StatusNotModified, http.Header{}, nil, true))
+	})
+
+	t.Run("未声明的状态码", func(t *testing.T) {
+		errs := validator.ValidateResponse(op, http.StatusNotFound, jsonHeader, nil, true)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "status", errs[0].Field)
+		assert.Equal(t, "status=200,304", errs[0].Constraint)
+	})
+
+	t.Run("Content-Type 不符", func(t *testing.T) {
+		errs := validator.ValidateResponse(op, http.StatusOK, http.Header{"Content-Type": {"text/plain"}}, valid, true)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "header.Content-Type", errs[0].Field)
+	})
+
+	t.Run("响应体与结构不符", func(t *testing.T) {
+		errs := validator.ValidateResponse(op, http.StatusOK, jsonHeader, []byte(`{"id":"1","extra":true}`), true)
+		fields := make([]string, len(errs))
+		for i, e := range errs {
+			fields[i] = e.Field + ":" + e.Keyword
+		}
+		assert.ElementsMatch(t, []string{"name:required", "id:format", "extra:additionalProperties"}, fields)
+
+		errs = validator.ValidateResponse(op, http.StatusOK, jsonHeader, []byte(`{"id":"1","extra":true}`), false)
+		assert.Len(t, errs, 2, "非严格模式不检查未声明的字段")
+	})
+
+	t.Run("响应头与空响应体", func(t *testing.T) {
+		header := http.Header{"Content-Type": {"application/json"}, "X-Count": {"-1"}}
+		errs := validator.ValidateResponse(op, http.StatusOK, header, valid, true)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "header.X-Count", errs[0].Field)
+
+		errs = validator.ValidateResponse(op, http.StatusNotModified, http.Header{}, []byte("{}"), true)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "body", errs[0].Field)
+	})
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateResponse 按接口文档校验响应的状态码、Content-Type、响应头和响应体
+// 状态码未声明时（也没有 default 响应）只报告状态码错误；未声明响应体的状态码要求响应体为空
+// 严格模式下文档未声明的响应体字段也视为错误，用于在测试中发现文档与实现的偏差
+func (v *RequestValidator) ValidateResponse(op *Operation, status int, header http.Header, body []byte, strict bool) []FieldError {
+	validation := &schemaValidation{doc: v.doc, strict: strict, subject: "response body"}
+
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		declared := make([]string, 0, len(op.Responses))
+		for code := range op.Responses {
+			declared = append(declared, code)
+		}
+		sort.Strings(declared)
+		validation.fail("status", "undocumented", "status="+strings.Join(declared, ","), status,
+			"must be one of [%s]", strings.Join(declared, ", "))
+		return validation.errors
+	}
+
+	for name, h := range response.Headers {
+		if values := header.Values(name); len(values) > 0 {
+			validation.validate(h.Schema, validation.coerce(h.Schema, values), "header."+name, true)
+		}
+	}
+	validation.responseBody(response, header, body)
+	return validation.errors
+}
+
+// responseBody 校验响应体，JSON 响应体按结构完整校验，其他类型只校验 Content-Type
+func (s *schemaValidation) responseBody(response *Response, header http.Header, body []byte) {
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			s.fail("body", "undocumented", "undocumented", nil, "is not documented for this status")
+		}
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	content, ok := response.Content[mediaType]
+	if !ok {
+		declared := make([]string, 0, len(response.Content))
+		for name := range response.Content {
+			declared = append(declared, name)
+		}
+		sort.Strings(declared)
+		s.fail("header.Content-Type", "contentType", "content-type="+strings.Join(declared, ","), mediaType,
+			"must be one of [%s]", strings.Join(declared, ", "))
+		return
+	}
+	if mediaType != "application/json" {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		s.fail("body", "format", "format=json", nil, "is not valid JSON")
+		return
+	}
+	s.validate(content.Schema, value, "", true)
+}
@@ -0,0 +1,580 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// sourceType 源码中声明的类型
+type sourceType struct {
+	spec    *ast.TypeSpec
+	doc     string
+	imports map[string]string // 声明所在文件的导入，别名到导入路径
+}
+
+// sourcePackage 解析后的包
+type sourcePackage struct {
+	name  string
+	types map[string]*sourceType
+	enums map[string][]interface{} // 类型名到该类型常量值的映射
+}
+
+// SchemaResolver 读取模块内包的源码，将类型解析为 JSON Schema，结构体注册为 components.schemas 中的组件
+type SchemaResolver struct {
+	root      string // 模块根目录
+	module    string // 模块路径
+	packages  map[string]*sourcePackage
+	schemas   map[string]*Schema
+	resolving map[string]bool
+}
+
+// NewSchemaResolver 创建类型解析器
+func NewSchemaResolver(root, module string) *SchemaResolver {
+	return &SchemaResolver{
+		root:      root,
+		module:    module,
+		packages:  make(map[string]*sourcePackage),
+		schemas:   make(map[string]*Schema),
+		resolving: make(map[string]bool),
+	}
+}
+
+// Schemas 返回已解析的组件
+func (r *SchemaResolver) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// Resolve 解析注释中的类型表达式，如 string、[]models.SafeUser 或
+// models.SuccessResponse{data=models.SafeUser}，imports 为注释所在文件的导入
+func (r *SchemaResolver) Resolve(expr string, imports map[string]string) (*Schema, error) {
+	if strings.HasPrefix(expr, "[]") {
+		items, err := r.Resolve(expr[2:], imports)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	}
+
+	name, overrides, err := splitOverrides(expr)
+	if err != nil {
+		return nil, err
+	}
+	base, err := r.resolveName(name, imports)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		return base, nil
+	}
+
+	properties := make(map[string]*Schema, len(overrides))
+	for field, fieldExpr := range overrides {
+		schema, err := r.Resolve(fieldExpr, imports)
+		if err != nil {
+			return nil, err
+		}
+		properties[field] = schema
+	}
+	return &Schema{AllOf: []*Schema{base, {Type: "object", Properties: properties}}}, nil
+}
+
+// resolveName 解析基本类型名或 包名.类型名
+func (r *SchemaResolver) resolveName(name string, imports map[string]string) (*Schema, error) {
+	if schema, ok := primitiveSchema(name); ok {
+		return schema, nil
+	}
+
+	alias, typeName, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", name)
+	}
+	importPath, ok := imports[alias]
+	if !ok {
+		return nil, fmt.Errorf("unknown package %q in type %q", alias, name)
+	}
+	return r.namedSchema(importPath, typeName)
+}
+
+// namedSchema 解析包中声明的类型，结构体返回组件引用，其他类型返回内联结构
+func (r *SchemaResolver) namedSchema(importPath, typeName string) (*Schema, error) {
+	switch importPath + "." + typeName {
+	case "time.Time":
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case "time.Duration":
+		return &Schema{Type: "integer", Description: "纳秒"}, nil
+	}
+	if importPath != r.module && !strings.HasPrefix(importPath, r.module+"/") {
+		// 模块外的类型无法读取源码，不限制结构
+		return &Schema{}, nil
+	}
+
+	pkg, err := r.loadPackage(importPath)
+	if err != nil {
+		return nil, err
+	}
+	declared, ok := pkg.types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", typeName, importPath)
+	}
+
+	structType, isStruct := declared.spec.Type.(*ast.StructType)
+	if !isStruct {
+		schema, err := r.exprSchema(declared.spec.Type, importPath, declared.imports)
+		if err != nil {
+			return nil, err
+		}
+		if values := pkg.enums[typeName]; len(values) > 0 {
+			schema.Enum = values
+		}
+		if schema.Description == "" {
+			schema.Description = declared.doc
+		}
+		return schema, nil
+	}
+
+	key := pkg.name + "." + typeName
+	if _, exists := r.schemas[key]; exists || r.resolving[key] {
+		return refSchema(key), nil
+	}
+
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+
+	schema, err := r.structSchema(structType, importPath, declared.imports)
+	if err != nil {
+		return nil, err
+	}
+	schema.Description = declared.doc
+	r.schemas[key] = schema
+	return refSchema(key), nil
+}
+
+// exprSchema 解析源码中的类型表达式
+func (r *SchemaResolver) exprSchema(expr ast.Expr, importPath string, imports map[string]string) (*Schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema, ok := primitiveSchema(t.Name); ok {
+			return schema, nil
+		}
+		return r.namedSchema(importPath, t.Name)
+	case *ast.SelectorExpr:
+		alias, ok := t.X.(*ast.Ident)
+		if !ok {
+			return &Schema{}, nil
+		}
+		path, ok := imports[alias.Name]
+		if !ok {
+			return &Schema{}, nil
+		}
+		return r.namedSchema(path, t.Sel.Name)
+	case *ast.StarExpr:
+		schema, err := r.exprSchema(t.X, importPath, imports)
+		if err != nil {
+			return nil, err
+		}
+		if typeName, ok := schema.Type.(string); ok {
+			schema.Type = []string{typeName, "null"}
+		}
+		return schema, nil
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" && t.Len == nil {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := r.exprSchema(t.Elt, importPath, imports)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := r.exprSchema(t.Value, importPath, imports)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.StructType:
+		return r.structSchema(t, importPath, imports)
+	default:
+		// interface{}、函数等类型不限制结构
+		return &Schema{}, nil
+	}
+}
+
+// structSchema 按 json 标签解析结构体字段，嵌入的结构体字段展开到外层
+func (r *SchemaResolver) structSchema(st *ast.StructType, importPath string, imports map[string]string) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			if jsonName == "" {
+				if err := r.mergeEmbedded(schema, field.Type, importPath, imports); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			field.Names = []*ast.Ident{embeddedName(field.Type)}
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propertyName := jsonName
+			if propertyName == "" {
+				propertyName = name.Name
+			}
+
+			property, err := r.exprSchema(field.Type, importPath, imports)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			if property.Ref != "" && (field.Comment != nil || tag.Get("example") != "") {
+				// $ref 旁的关键字在 3.1 中有效，但复制一份避免修改组件本身
+				property = &Schema{AllOf: []*Schema{property}}
+			}
+			if field.Comment != nil {
+				property.Description = strings.TrimSpace(field.Comment.Text())
+			}
+			if example := tag.Get("example"); example != "" {
+				property.Examples = []interface{}{exampleValue(example, property)}
+			}
+
+			rules := tag.Get("binding")
+			if rules == "" {
+				rules = tag.Get("validate")
+			}
+			if applyValidationRules(property, rules) {
+				schema.Required = append(schema.Required, propertyName)
+			}
+			schema.Properties[propertyName] = property
+		}
+	}
+	return schema, nil
+}
+
+// mergeEmbedded 将嵌入结构体的字段合并到外层结构
+func (r *SchemaResolver) mergeEmbedded(schema *Schema, expr ast.Expr, importPath string, imports map[string]string) error {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	targetPath, typeName := importPath, ""
+	switch t := expr.(type) {
+	case *ast.Ident:
+		typeName = t.Name
+	case *ast.SelectorExpr:
+		alias, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		targetPath, typeName = imports[alias.Name], t.Sel.Name
+	default:
+		return nil
+	}
+	if targetPath != r.module && !strings.HasPrefix(targetPath, r.module+"/") {
+		return nil
+	}
+
+	pkg, err := r.loadPackage(targetPath)
+	if err != nil {
+		return err
+	}
+	declared, ok := pkg.types[typeName]
+	if !ok {
+		return nil
+	}
+	st, ok := declared.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+
+	embedded, err := r.structSchema(st, targetPath, declared.imports)
+	if err != nil {
+		return err
+	}
+	for name, property := range embedded.Properties {
+		if _, exists := schema.Properties[name]; !exists {
+			schema.Properties[name] = property
+		}
+	}
+	schema.Required = append(schema.Required, embedded.Required...)
+	return nil
+}
+
+// loadPackage 解析模块内包的源码
+func (r *SchemaResolver) loadPackage(importPath string) (*sourcePackage, error) {
+	if pkg, ok := r.packages[importPath]; ok {
+		return pkg, nil
+	}
+
+	dir := filepath.Join(r.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, r.module), "/")))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package %s: %w", importPath, err)
+	}
+
+	pkg := &sourcePackage{
+		types: make(map[string]*sourceType),
+		enums: make(map[string][]interface{}),
+	}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg.name = file.Name.Name
+		collectDeclarations(pkg, file)
+	}
+
+	r.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// collectDeclarations 收集文件中的类型声明和带类型的字符串常量
+func collectDeclarations(pkg *sourcePackage, file *ast.File) {
+	imports := FileImports(file)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				doc := s.Doc
+				if doc == nil {
+					doc = gen.Doc
+				}
+				description := strings.TrimPrefix(firstLine(doc), s.Name.Name+" ")
+				pkg.types[s.Name.Name] = &sourceType{spec: s, doc: description, imports: imports}
+			case *ast.ValueSpec:
+				ident, ok := s.Type.(*ast.Ident)
+				if gen.Tok != token.CONST || !ok {
+					continue
+				}
+				for _, value := range s.Values {
+					if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						if unquoted, err := strconv.Unquote(lit.Value); err == nil {
+							pkg.enums[ident.Name] = append(pkg.enums[ident.Name], unquoted)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// FileImports 返回文件的导入，别名到导入路径，未指定别名时使用路径的最后一段
+func FileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		alias := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		imports[alias] = path
+	}
+	return imports
+}
+
+// splitOverrides 拆分 Type{field=Type,...} 形式的字段覆盖
+func splitOverrides(expr string) (string, map[string]string, error) {
+	open := strings.Index(expr, "{")
+	if open < 0 {
+		return expr, nil, nil
+	}
+	if !strings.HasSuffix(expr, "}") {
+		return "", nil, fmt.Errorf("invalid type %q", expr)
+	}
+
+	overrides := make(map[string]string)
+	body := expr[open+1 : len(expr)-1]
+	depth, start := 0, 0
+	for i := 0; i <= len(body); i++ {
+		if i < len(body) {
+			switch body[i] {
+			case '{':
+				depth++
+				continue
+			case '}':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		field, fieldType, ok := strings.Cut(body[start:i], "=")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid field override in %q", expr)
+		}
+		overrides[strings.TrimSpace(field)] = strings.TrimSpace(fieldType)
+		start = i + 1
+	}
+	return expr[:open], overrides, nil
+}
+
+// primitiveSchema 返回基本类型对应的结构
+func primitiveSchema(name string) (*Schema, bool) {
+	switch name {
+	case "string":
+		return &Schema{Type: "string"}, true
+	case "bool", "boolean":
+		return &Schema{Type: "boolean"}, true
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "integer":
+		return &Schema{Type: "integer"}, true
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}, true
+	case "float32", "float64", "number":
+		return &Schema{Type: "number"}, true
+	case "file":
+		return &Schema{Type: "string", Format: "binary"}, true
+	case "object":
+		return &Schema{Type: "object"}, true
+	case "any", "interface{}", "error":
+		return &Schema{}, true
+	}
+	return nil, false
+}
+
+// applyValidationRules 将 binding/validate 标签中的常用规则写入结构，返回字段是否必填
+func applyValidationRules(schema *Schema, rules string) bool {
+	required := false
+	target := schema
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			// dive 之后的规则作用于数组元素
+			if schema.Items == nil {
+				return required
+			}
+			target = schema.Items
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		case "oneof":
+			target.Enum = nil
+			for _, value := range strings.Fields(arg) {
+				target.Enum = append(target.Enum, value)
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			setLength(target, name, n)
+		}
+	}
+	return required
+}
+
+// setLength 按结构的类型设置长度或数量限制
+func setLength(schema *Schema, rule string, n int) {
+	value := float64(n)
+	switch schemaType(schema) {
+	case "string":
+		if rule == "min" {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if rule == "min" {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		if rule == "min" {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
+
+// schemaType 返回结构的主类型，可为 null 的类型返回非 null 的部分
+func schemaType(schema *Schema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []string:
+		if len(t) > 0 {
+			return t[0]
+		}
+	}
+	return ""
+}
+
+// exampleValue 按结构的类型转换 example 标签的值
+func exampleValue(example string, schema *Schema) interface{} {
+	switch schemaType(schema) {
+	case "integer":
+		if n, err := strconv.ParseInt(example, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(example, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(example); err == nil {
+			return b
+		}
+	case "array":
+		return strings.Split(example, ",")
+	}
+	return example
+}
+
+// embeddedName 返回嵌入字段的字段名
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.Ident:
+		return t
+	}
+	return ast.NewIdent("")
+}
+
+// firstLine 返回注释的第一行
+func firstLine(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(doc.Text()), "\n")
+	return line
+}
@@ -0,0 +1,506 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// multipartMemory 解析 multipart 请求体时保存在内存中的最大字节数，与 gin 的默认值相同
+const multipartMemory = 32 << 20
+
+// uuidRegex uuid 格式
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// FieldError 请求或响应中不符合文档的字段
+type FieldError struct {
+	Field      string      // 请求体字段路径（如 items[0].name），参数为 位置.名称（如 query.limit）
+	Keyword    string      // 违反的 JSON Schema 关键字，如 required、minLength、additionalProperties
+	Constraint string      // 违反的约束，如 minLength=3
+	Message    string      // 英文错误消息
+	Value      interface{} // 导致错误的值
+}
+
+// RequestValidator 按 OpenAPI 文档校验请求参数和请求体，也可用于在测试中校验响应
+type RequestValidator struct {
+	doc        *Document
+	operations map[string]*Operation // 小写方法 + 空格 + OpenAPI 路径
+}
+
+// NewRequestValidator 从 JSON 格式的文档创建请求校验器
+func NewRequestValidator(spec []byte) (*RequestValidator, error) {
+	var doc Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	operations := make(map[string]*Operation)
+	for path, item := range doc.Paths {
+		for method, op := range map[string]*Operation{
+			"get": item.Get, "put": item.Put, "post": item.Post,
+			"delete": item.Delete, "patch": item.Patch, "head": item.Head,
+		} {
+			if op != nil {
+				operations[method+" "+path] = op
+			}
+		}
+	}
+	return &RequestValidator{doc: &doc, operations: operations}, nil
+}
+
+// Operation 返回 gin 路由（如 /api/v1/users/:id）对应的接口文档
+func (v *RequestValidator) Operation(method, route string) (*Operation, bool) {
+	op, ok := v.operations[strings.ToLower(method)+" "+openAPIPath(route)]
+	return op, ok
+}
+
+// ValidateRequest 按接口文档校验请求，pathParams 为路由参数
+// 严格模式下文档未声明的请求体字段、查询参数和请求体也视为错误
+// 读取请求体失败（如超过大小限制）时返回 error；JSON 请求体读取后会重新放回请求中
+func (v *RequestValidator) ValidateRequest(op *Operation, req *http.Request, pathParams map[string]string, strict bool) ([]FieldError, error) {
+	validation := &schemaValidation{doc: v.doc, strict: strict, subject: "request body"}
+	validation.parameters(op.Parameters, req, pathParams)
+	if err := validation.body(op.RequestBody, req); err != nil {
+		return nil, err
+	}
+	return validation.errors, nil
+}
+
+// schemaValidation 一次请求或响应校验的状态
+type schemaValidation struct {
+	doc     *Document
+	strict  bool
+	subject string // 字段路径为空时错误消息中的名称
+	errors  []FieldError
+}
+
+// fail 记录一个字段错误
+func (s *schemaValidation) fail(field, keyword, constraint string, value interface{}, format string, args ...interface{}) {
+	name := field
+	if name == "" {
+		name = s.subject
+	}
+	s.errors = append(s.errors, FieldError{
+		Field:      field,
+		Keyword:    keyword,
+		Constraint: constraint,
+		Message:    name + " " + fmt.Sprintf(format, args...),
+		Value:      value,
+	})
+}
+
+// parameters 校验路径参数、查询参数和请求头
+func (s *schemaValidation) parameters(params []*Parameter, req *http.Request, pathParams map[string]string) {
+	query := req.URL.Query()
+	documented := make(map[string]bool)
+
+	for _, param := range params {
+		field := param.In + "." + param.Name
+		var raw []string
+		switch param.In {
+		case "path":
+			if value, ok := pathParams[param.Name]; ok {
+				raw = []string{value}
+			}
+		case "query":
+			documented[param.Name] = true
+			raw = query[param.Name]
+		case "header":
+			raw = req.Header.Values(param.Name)
+		}
+
+		if len(raw) == 0 {
+			if param.Required {
+				s.fail(field, "required", "required", nil, "is required")
+			}
+			continue
+		}
+		s.validate(param.Schema, s.coerce(param.Schema, raw), field, true)
+	}
+
+	if !s.strict {
+		return
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if !documented[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.fail("query."+name, "undocumented", "undocumented", query.Get(name), "is not a documented query parameter")
+	}
+}
+
+// body 校验请求体，JSON 请求体按结构完整校验，multipart 和 URL 编码表单只校验字段是否存在
+func (s *schemaValidation) body(requestBody *RequestBody, req *http.Request) error {
+	hasBody := req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+	if requestBody == nil {
+		if s.strict && hasBody {
+			s.fail("body", "undocumented", "undocumented", nil, "is not documented for this operation")
+		}
+		return nil
+	}
+	if !hasBody {
+		if requestBody.Required {
+			s.fail("body", "required", "required", nil, "is required")
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	content, ok := requestBody.Content[mediaType]
+	if !ok {
+		accepted := make([]string, 0, len(requestBody.Content))
+		for name := range requestBody.Content {
+			accepted = append(accepted, name)
+		}
+		sort.Strings(accepted)
+		s.fail("header.Content-Type", "contentType", "content-type="+strings.Join(accepted, ","), mediaType,
+			"must be one of [%s]", strings.Join(accepted, ", "))
+		return nil
+	}
+
+	switch {
+	case mediaType == "application/json":
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		if len(bytes.TrimSpace(data)) == 0 {
+			if requestBody.Required {
+				s.fail("body", "required", "required", nil, "is required")
+			}
+			return nil
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			s.fail("body", "format", "format=json", nil, "is not valid JSON")
+			return nil
+		}
+		s.validate(content.Schema, value, "", true)
+
+	case mediaType == "multipart/form-data":
+		if err := req.ParseMultipartForm(multipartMemory); err != nil {
+			return err
+		}
+		fields := make(map[string]bool)
+		for name := range req.MultipartForm.Value {
+			fields[name] = true
+		}
+		for name := range req.MultipartForm.File {
+			fields[name] = true
+		}
+		s.formFields(content.Schema, fields)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := req.ParseForm(); err != nil {
+			return err
+		}
+		fields := make(map[string]bool)
+		for name := range req.PostForm {
+			fields[name] = true
+		}
+		s.formFields(content.Schema, fields)
+	}
+	return nil
+}
+
+// formFields 校验表单的必填字段，严格模式下拒绝未声明的字段
+func (s *schemaValidation) formFields(schema *Schema, fields map[string]bool) {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return
+	}
+	for _, name := range schema.Required {
+		if !fields[name] {
+			s.fail(name, "required", "required", nil, "is required")
+		}
+	}
+	if !s.strict {
+		return
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if _, ok := schema.Properties[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.fail(name, "additionalProperties", "additionalProperties=false", nil, "is not a documented field")
+	}
+}
+
+// coerce 按参数类型转换字符串参数值，无法转换时保留字符串以便报告类型错误
+func (s *schemaValidation) coerce(schema *Schema, raw []string) interface{} {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return raw[0]
+	}
+	types := schemaTypes(schema)
+	if slices.Contains(types, "array") {
+		items := make([]interface{}, 0, len(raw))
+		for _, value := range raw {
+			items = append(items, s.coerce(schema.Items, []string{value}))
+		}
+		return items
+	}
+
+	value := raw[0]
+	switch {
+	case slices.Contains(types, "integer"):
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return float64(n)
+		}
+	case slices.Contains(types, "number"):
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case slices.Contains(types, "boolean"):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validate 按结构校验 JSON 值，checkUnknown 为 false 时不检查未声明的字段（用于 allOf 的各个分支）
+func (s *schemaValidation) validate(schema *Schema, value interface{}, field string, checkUnknown bool) {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return
+	}
+	for _, sub := range schema.AllOf {
+		s.validate(sub, value, field, false)
+	}
+
+	types := schemaTypes(schema)
+	actual := jsonType(value)
+	if len(types) > 0 && !typeMatches(types, actual) {
+		s.fail(field, "type", "type="+strings.Join(types, ","), value, "must be of type %s", strings.Join(types, " or "))
+		return
+	}
+	if value == nil {
+		return
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		options := make([]string, len(schema.Enum))
+		for i, option := range schema.Enum {
+			options[i] = fmt.Sprint(option)
+		}
+		s.fail(field, "enum", "enum="+strings.Join(options, ","), value, "must be one of [%s]", strings.Join(options, ", "))
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(schema, v, field)
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			s.fail(field, "minimum", fmt.Sprintf("minimum=%v", *schema.Minimum), value, "must be greater than or equal to %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			s.fail(field, "maximum", fmt.Sprintf("maximum=%v", *schema.Maximum), value, "must be less than or equal to %v", *schema.Maximum)
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			s.fail(field, "minItems", fmt.Sprintf("minItems=%d", *schema.MinItems), nil, "must contain at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			s.fail(field, "maxItems", fmt.Sprintf("maxItems=%d", *schema.MaxItems), nil, "must contain at most %d items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range v {
+				s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), true)
+			}
+		}
+	case map[string]interface{}:
+		s.validateObject(schema, v, field, checkUnknown)
+	}
+}
+
+// validateString 校验字符串的长度和格式
+func (s *schemaValidation) validateString(schema *Schema, value, field string) {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		s.fail(field, "minLength", fmt.Sprintf("minLength=%d", *schema.MinLength), value, "must be at least %d characters long", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		s.fail(field, "maxLength", fmt.Sprintf("maxLength=%d", *schema.MaxLength), value, "must be at most %d characters long", *schema.MaxLength)
+	}
+
+	var valid bool
+	switch schema.Format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		valid = err == nil && address.Address == value
+	case "uuid":
+		valid = uuidRegex.MatchString(value)
+	case "uri":
+		u, err := url.ParseRequestURI(value)
+		valid = err == nil && u.Scheme != ""
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		valid = err == nil
+	default:
+		return
+	}
+	if !valid {
+		s.fail(field, "format", "format="+schema.Format, value, "must be a valid %s", schema.Format)
+	}
+}
+
+// validateObject 校验对象的必填字段、各字段结构以及未声明的字段
+func (s *schemaValidation) validateObject(schema *Schema, value map[string]interface{}, field string, checkUnknown bool) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			s.fail(joinField(field, name), "required", "required", nil, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if property, ok := schema.Properties[name]; ok {
+			s.validate(property, value[name], joinField(field, name), true)
+		} else if schema.AdditionalProperties != nil {
+			s.validate(schema.AdditionalProperties, value[name], joinField(field, name), true)
+		}
+	}
+
+	if !s.strict || !checkUnknown {
+		return
+	}
+	properties, open := s.properties(schema)
+	if open {
+		return
+	}
+	for _, name := range names {
+		if !properties[name] {
+			s.fail(joinField(field, name), "additionalProperties", "additionalProperties=false", nil, "is not a documented field")
+		}
+	}
+}
+
+// properties 收集对象结构（包括 allOf 各分支）声明的字段
+// 没有声明任何字段或允许任意字段时 open 为 true，此时不检查未声明的字段
+func (s *schemaValidation) properties(schema *Schema) (map[string]bool, bool) {
+	properties := make(map[string]bool)
+	var open bool
+	var collect func(schema *Schema)
+	collect = func(schema *Schema) {
+		schema = s.resolve(schema)
+		if schema == nil {
+			return
+		}
+		if schema.AdditionalProperties != nil {
+			open = true
+		}
+		for name := range schema.Properties {
+			properties[name] = true
+		}
+		for _, sub := range schema.AllOf {
+			collect(sub)
+		}
+	}
+	collect(schema)
+	return properties, open || len(properties) == 0
+}
+
+// resolve 解析组件引用
+func (s *schemaValidation) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// schemaTypes 返回结构允许的类型，Type 可以是字符串或字符串数组
+func schemaTypes(schema *Schema) []string {
+	switch t := schema.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// jsonType 返回解码后的 JSON 值的类型
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return ""
+}
+
+// typeMatches 判断值的类型是否为允许的类型之一，整数也是 number
+func typeMatches(types []string, actual string) bool {
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// enumContains 判断值是否为枚举值之一
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, option := range enum {
+		if reflect.DeepEqual(option, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinField 拼接字段路径
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,437 @@
+// Package passwordless 无密码登录：设备授权流程和邮件登录链接
+//
+// 设备授权流程（RFC 8628）用于电视、命令行工具等不便输入密码的设备：设备申请设备码和用户码，
+// 显示用户码和验证页面地址，用户在手机或电脑上登录后输入用户码并允许，设备轮询令牌接口得到访问令牌。
+// 登录链接通过事件发送到用户邮箱，打开链接后前端用链接中的令牌换取访问令牌。
+// 设备码、用户码和链接令牌都保存在共享缓存中（键中只有哈希），只能使用一次。两种方式签发的令牌与密码登录相同。
+package passwordless
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+)
+
+// 默认有效期和轮询间隔
+const (
+	DefaultDeviceCodeTTL = 10 * time.Minute
+	DefaultPollInterval  = 5 * time.Second
+	DefaultMagicLinkTTL  = 15 * time.Minute
+)
+
+// 缓存键前缀，键中只保存设备码、用户码和链接令牌的哈希
+const (
+	deviceKeyPrefix    = "passwordless:device:"
+	userCodeKeyPrefix  = "passwordless:user_code:"
+	magicLinkKeyPrefix = "passwordless:magic_link:"
+)
+
+// EventMagicLinkRequested 用户申请登录链接后发布的领域事件类型，由通知服务向用户邮箱发送登录链接
+const EventMagicLinkRequested = "user.magic_link_requested"
+
+// 设备轮询令牌接口的错误码（RFC 8628 第 3.5 节）
+const (
+	ErrorAuthorizationPending = "authorization_pending"
+	ErrorSlowDown             = "slow_down"
+	ErrorAccessDenied         = "access_denied"
+	ErrorExpiredToken         = "expired_token"
+)
+
+// userCodeAlphabet 用户码使用的字符，去掉元音和容易混淆的字符（RFC 8628 第 6.1 节）
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// userCodeLength 用户码的字符数，显示时在中间加连字符
+const userCodeLength = 8
+
+var (
+	// ErrInvalidUserCode 用户码不存在、已过期或已被使用
+	ErrInvalidUserCode = errors.New("invalid or expired user code")
+	// ErrInvalidMagicLink 登录链接令牌无效、已过期或已被使用
+	ErrInvalidMagicLink = errors.New("invalid or expired magic link")
+	// ErrUserInactive 用户已停用
+	ErrUserInactive = errors.New("user is deactivated")
+	// ErrMagicLinkUnavailable 没有事件发布器，无法发送登录链接
+	ErrMagicLinkUnavailable = errors.New("magic link login is not available")
+)
+
+// DeviceError 设备轮询令牌时的协议错误，Code 为标准错误码
+type DeviceError struct {
+	Code        string
+	Description string
+}
+
+// Error 返回错误码和描述
+func (e *DeviceError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// UserStore 查找用户并记录登录时间，用户服务实现了该接口
+type UserStore interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateLastLogin(ctx context.Context, id string) error
+}
+
+// Config 无密码登录配置
+type Config struct {
+	// VerificationURL 前端设备验证页面的地址，用户在此页面输入用户码
+	VerificationURL string
+	// MagicLinkURL 前端登录链接页面的地址，链接令牌以 token 查询参数附加在其后
+	MagicLinkURL string
+	// DeviceCodeTTL 设备码和用户码的有效期，<=0 时使用 DefaultDeviceCodeTTL
+	DeviceCodeTTL time.Duration
+	// PollInterval 设备轮询令牌接口的最小间隔，<=0 时使用 DefaultPollInterval
+	PollInterval time.Duration
+	// MagicLinkTTL 登录链接的有效期，<=0 时使用 DefaultMagicLinkTTL
+	MagicLinkTTL time.Duration
+}
+
+// Service 无密码登录服务
+type Service struct {
+	users     UserStore
+	cache     cache.Cache
+	jwt       *auth.JWTManager
+	publisher events.Publisher
+	config    Config
+	now       func() time.Time
+}
+
+// deviceGrant 设备授权请求，创建后不再修改；用户的决定和轮询时间保存在单独的键中，避免并发写入互相覆盖
+type deviceGrant struct {
+	UserCode  string `json:"user_code"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// deviceDecision 用户对设备登录的决定
+type deviceDecision struct {
+	Approved bool   `json:"approved"`
+	UserID   string `json:"user_id"`
+}
+
+// magicLink 登录链接对应的用户
+type magicLink struct {
+	UserID string `json:"user_id"`
+}
+
+// MagicLinkRequestedEvent user.magic_link_requested 事件负载
+type MagicLinkRequestedEvent struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Link      string    `json:"link"`  // 登录链接，仅用于发送给用户邮箱
+	Token     string    `json:"token"` // 链接中的令牌
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewService 创建无密码登录服务，publisher 为 nil 时不能申请登录链接
+func NewService(users UserStore, c cache.Cache, jwtManager *auth.JWTManager, publisher events.Publisher, config Config) *Service {
+	if config.DeviceCodeTTL <= 0 {
+		config.DeviceCodeTTL = DefaultDeviceCodeTTL
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MagicLinkTTL <= 0 {
+		config.MagicLinkTTL = DefaultMagicLinkTTL
+	}
+	return &Service{
+		users:     users,
+		cache:     c,
+		jwt:       jwtManager,
+		publisher: publisher,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// StartDevice 为设备创建设备码和用户码
+func (s *Service) StartDevice(ctx context.Context) (*models.DeviceCodeResponse, error) {
+	deviceCode, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.config.DeviceCodeTTL
+	grant := deviceGrant{ExpiresAt: s.now().Add(ttl).Unix()}
+	// 用户码空间较小，偶尔与未过期的用户码冲突时重新生成
+	for attempt := 0; ; attempt++ {
+		if grant.UserCode, err = newUserCode(); err != nil {
+			return nil, err
+		}
+		claimed, err := s.cache.SetIfNotExists(ctx, userCodeKey(grant.UserCode), hashToken(deviceCode), ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save user code: %w", err)
+		}
+		if claimed {
+			break
+		}
+		if attempt == 4 {
+			return nil, errors.New("failed to allocate a unique user code")
+		}
+	}
+	if err := s.cache.Set(ctx, deviceKey(deviceCode), grant, ttl); err != nil {
+		_ = s.cache.Delete(ctx, userCodeKey(grant.UserCode))
+		return nil, fmt.Errorf("failed to save device code: %w", err)
+	}
+
+	display := formatUserCode(grant.UserCode)
+	return &models.DeviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                display,
+		VerificationURI:         s.config.VerificationURL,
+		VerificationURIComplete: appendQuery(s.config.VerificationURL, url.Values{"user_code": {display}}),
+		ExpiresIn:               int(ttl.Seconds()),
+		Interval:                int(s.config.PollInterval.Seconds()),
+	}, nil
+}
+
+// VerifyDevice 记录已登录用户对设备登录的决定，用户码只能使用一次
+func (s *Service) VerifyDevice(ctx context.Context, userCode, userID string, approve bool) error {
+	code := normalizeUserCode(userCode)
+	deviceHash, found := cache.GetAs[string](ctx, s.cache, userCodeKey(code))
+	if !found {
+		return ErrInvalidUserCode
+	}
+	grant, found := cache.GetAs[deviceGrant](ctx, s.cache, deviceHashKey(deviceHash))
+	if !found || grant.UserCode != code {
+		return ErrInvalidUserCode
+	}
+
+	remaining := time.Unix(grant.ExpiresAt, 0).Sub(s.now())
+	if remaining <= 0 {
+		return ErrInvalidUserCode
+	}
+	decided, err := s.cache.SetIfNotExists(ctx, deviceHashKey(deviceHash)+":decision", deviceDecision{Approved: approve, UserID: userID}, remaining)
+	if err != nil {
+		return fmt.Errorf("failed to save device decision: %w", err)
+	}
+	if !decided {
+		return ErrInvalidUserCode
+	}
+	_ = s.cache.Delete(ctx, userCodeKey(code))
+	return nil
+}
+
+// PollDevice 设备轮询令牌：用户允许后签发访问令牌，设备码只能兑换一次
+func (s *Service) PollDevice(ctx context.Context, deviceCode string) (*models.LoginResponse, error) {
+	key := deviceKey(deviceCode)
+	if _, found := cache.GetAs[deviceGrant](ctx, s.cache, key); !found {
+		return nil, &DeviceError{Code: ErrorExpiredToken, Description: "device code is invalid or expired"}
+	}
+
+	decision, decided := cache.GetAs[deviceDecision](ctx, s.cache, key+":decision")
+	if !decided {
+		// 轮询间隔内再次轮询的设备须放慢（RFC 8628 第 3.5 节）
+		polled, err := s.cache.SetIfNotExists(ctx, key+":poll", true, s.config.PollInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record device poll: %w", err)
+		}
+		if !polled {
+			return nil, &DeviceError{Code: ErrorSlowDown, Description: "polling too frequently"}
+		}
+		return nil, &DeviceError{Code: ErrorAuthorizationPending, Description: "the user has not approved the device yet"}
+	}
+
+	claimed, err := s.cache.SetIfNotExists(ctx, key+":redeemed", true, s.config.DeviceCodeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem device code: %w", err)
+	}
+	if !claimed {
+		return nil, &DeviceError{Code: ErrorExpiredToken, Description: "device code has already been used"}
+	}
+	_ = s.cache.Delete(ctx, key)
+	_ = s.cache.Delete(ctx, key+":decision")
+
+	if !decision.Approved {
+		return nil, &DeviceError{Code: ErrorAccessDenied, Description: "the user denied the device"}
+	}
+	tokens, err := s.login(ctx, decision.UserID)
+	if errors.Is(err, ErrUserInactive) || errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, &DeviceError{Code: ErrorAccessDenied, Description: "the user is deactivated"}
+	}
+	return tokens, err
+}
+
+// RequestMagicLink 为邮箱对应的用户生成登录链接并发布事件发送到该邮箱，返回链接的过期时间
+// 邮箱不存在或用户已停用时同样返回成功，不泄露账号是否存在
+func (s *Service) RequestMagicLink(ctx context.Context, email string) (time.Time, error) {
+	if s.publisher == nil {
+		return time.Time{}, ErrMagicLinkUnavailable
+	}
+
+	expiresAt := s.now().Add(s.config.MagicLinkTTL)
+	user, err := s.users.GetByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return expiresAt, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !user.IsActive {
+		return expiresAt, nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := s.cache.Set(ctx, magicLinkKey(token), magicLink{UserID: user.ID}, s.config.MagicLinkTTL); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save magic link: %w", err)
+	}
+
+	payload := MagicLinkRequestedEvent{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Link:      appendQuery(s.config.MagicLinkURL, url.Values{"token": {token}}),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}
+	if err := events.PublishEvent(ctx, s.publisher, EventMagicLinkRequested, payload); err != nil {
+		_ = s.cache.Delete(ctx, magicLinkKey(token))
+		return time.Time{}, fmt.Errorf("failed to send magic link: %w", err)
+	}
+	return expiresAt, nil
+}
+
+// RedeemMagicLink 使用登录链接令牌登录，令牌只能使用一次，并发使用时只有一个请求成功
+func (s *Service) RedeemMagicLink(ctx context.Context, token string) (*models.LoginResponse, error) {
+	key := magicLinkKey(token)
+	claimed, err := s.cache.SetIfNotExists(ctx, key+":redeemed", true, s.config.MagicLinkTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem magic link: %w", err)
+	}
+	if !claimed {
+		return nil, ErrInvalidMagicLink
+	}
+
+	link, found := cache.GetAs[magicLink](ctx, s.cache, key)
+	if !found {
+		return nil, ErrInvalidMagicLink
+	}
+	_ = s.cache.Delete(ctx, key)
+
+	tokens, err := s.login(ctx, link.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, ErrInvalidMagicLink
+	}
+	return tokens, err
+}
+
+// login 为用户签发与密码登录相同的访问令牌并记录登录时间
+func (s *Service) login(ctx context.Context, userID string) (*models.LoginResponse, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	var tenantID string
+	if user.TenantID != nil {
+		tenantID = *user.TenantID
+	}
+	token, err := s.jwt.GenerateTenantToken(user.ID, user.Username, user.Email, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+	if err := s.users.UpdateLastLogin(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to update last login: %w", err)
+	}
+	return &models.LoginResponse{Token: token, User: user.ToSafeUser()}, nil
+}
+
+// newUserCode 生成用户码，每个字符从 userCodeAlphabet 中均匀选取
+func newUserCode() (string, error) {
+	buf := make([]byte, userCodeLength)
+	code := make([]byte, userCodeLength)
+	for i := 0; i < userCodeLength; {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate user code: %w", err)
+		}
+		for _, b := range buf {
+			// 丢弃超出字母表整数倍的值，避免取模偏差
+			if int(b) >= 256/len(userCodeAlphabet)*len(userCodeAlphabet) {
+				continue
+			}
+			code[i] = userCodeAlphabet[int(b)%len(userCodeAlphabet)]
+			if i++; i == userCodeLength {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// formatUserCode 在用户码中间加连字符，便于用户阅读，如 WDJBMJHT 显示为 WDJB-MJHT
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// normalizeUserCode 转为大写并去掉连字符和空白，用户输入时可以不区分大小写
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// deviceKey 返回设备码的缓存键
+func deviceKey(deviceCode string) string {
+	return deviceHashKey(hashToken(deviceCode))
+}
+
+// deviceHashKey 返回设备码哈希的缓存键，用户码的缓存值即为设备码的哈希
+func deviceHashKey(hash string) string {
+	return deviceKeyPrefix + hash
+}
+
+// userCodeKey 返回规范化用户码的缓存键
+func userCodeKey(code string) string {
+	return userCodeKeyPrefix + hashToken(code)
+}
+
+// magicLinkKey 返回登录链接令牌的缓存键
+func magicLinkKey(token string) string {
+	return magicLinkKeyPrefix + hashToken(token)
+}
+
+// hashToken 返回令牌的 SHA-256，令牌是随机生成的高熵字符串或短期有效的用户码，无需慢哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken 生成 256 位随机字符串，用作设备码和登录链接令牌
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// appendQuery 在地址上追加查询参数，保留地址原有的参数
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
@@ -0,0 +1,297 @@
+package passwordless
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	verificationURL = "https://app.example.com/device"
+	magicLinkURL    = "https://app.example.com/login/magic?lang=zh"
+)
+
+// recordingPublisher 记录发布的事件
+type recordingPublisher struct {
+	events []*events.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, event *events.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// newUser 创建登录的用户，返回包含该用户的内存仓库
+func newUser(t *testing.T) (*repositories.MemoryUserRepository, *models.User) {
+	t.Helper()
+	users := repositories.NewMemoryUserRepository()
+	user := factory.User().WithUsername("alice").WithEmail("alice@example.com").Build()
+	require.NoError(t, users.Create(context.Background(), user))
+	return users, user
+}
+
+// newService 创建使用内存缓存的无密码登录服务，publisher 为 nil 时不能发送登录链接
+func newService(users UserStore, jwt *auth.JWTManager, publisher events.Publisher) *Service {
+	return NewService(users, cache.NewMemoryCache(), jwt, publisher, Config{
+		VerificationURL: verificationURL,
+		MagicLinkURL:    magicLinkURL,
+		PollInterval:    time.Hour,
+	})
+}
+
+// deviceErrorCode 返回设备轮询错误的错误码
+func deviceErrorCode(t *testing.T, err error) string {
+	t.Helper()
+	var deviceErr *DeviceError
+	require.True(t, errors.As(err, &deviceErr), "want *DeviceError, got %v", err)
+	return deviceErr.Code
+}
+
+func TestDeviceFlow(t *testing.T) {
+	ctx := context.Background()
+	users, user := newUser(t)
+	jwt := auth.NewJWTManager("test-secret", 1)
+	service := newService(users, jwt, nil)
+
+	device, err := service.StartDevice(ctx)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$`, device.UserCode)
+	assert.Equal(t, verificationURL, device.VerificationURI)
+	assert.Equal(t, verificationURL+"?user_code="+url.QueryEscape(device.UserCode), device.VerificationURIComplete)
+	assert.Equal(t, 600, device.ExpiresIn)
+	assert.Equal(t, 3600, device.Interval)
+
+	_, err = service.PollDevice(ctx, device.DeviceCode)
+	assert.Equal(t, ErrorAuthorizationPending, deviceErrorCode(t, err))
+	_, err = service.PollDevice(ctx, device.DeviceCode)
+	assert.Equal(t, ErrorSlowDown, deviceErrorCode(t, err), "轮询间隔内再次轮询")
+
+	// 用户码不区分大小写，可省略连字符
+	entered := strings.ToLower(strings.ReplaceAll(device.UserCode, "-", ""))
+	require.NoError(t, service.VerifyDevice(ctx, entered, user.ID, true))
+	assert.ErrorIs(t, service.VerifyDevice(ctx, device.UserCode, user.ID, true), ErrInvalidUserCode, "用户码只能使用一次")
+
+	tokens, err := service.PollDevice(ctx, device.DeviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, tokens.User.ID)
+	claims, err := jwt.ValidateToken(tokens.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	stored, err := users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastLogin)
+
+	_, err = service.PollDevice(ctx, device.DeviceCode)
+	assert.Equal(t, ErrorExpiredToken, deviceErrorCode(t, err), "设备码只能兑换一次")
+}
+
+func TestPollDeviceErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		unknown    bool // 轮询未签发的设备码
+		approve    bool
+		deactivate bool // 确认后停用用户
+		wantCodes  []string
+	}{
+		{
+			name:      "用户拒绝",
+			wantCodes: []string{ErrorAccessDenied, ErrorExpiredToken},
+		},
+		{
+			name:       "用户已停用",
+			approve:    true,
+			deactivate: true,
+			wantCodes:  []string{ErrorAccessDenied},
+		},
+		{
+			name:      "未知设备码",
+			unknown:   true,
+			wantCodes: []string{ErrorExpiredToken},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, user := newUser(t)
+			service := newService(users, auth.NewJWTManager("test-secret", 1), nil)
+			deviceCode := "unknown"
+			if !tt.unknown {
+				device, err := service.StartDevice(ctx)
+				require.NoError(t, err)
+				require.NoError(t, service.VerifyDevice(ctx, device.UserCode, user.ID, tt.approve))
+				deviceCode = device.DeviceCode
+			}
+			if tt.deactivate {
+				user.IsActive = false
+				require.NoError(t, users.Update(ctx, user))
+			}
+
+			for _, want := range tt.wantCodes {
+				_, err := service.PollDevice(ctx, deviceCode)
+				assert.Equal(t, want, deviceErrorCode(t, err))
+			}
+		})
+	}
+}
+
+func TestVerifyDeviceErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		userCode func(device *models.DeviceCodeResponse) string
+		later    time.Duration // 确认前时钟前进的时长
+	}{
+		{
+			name:     "未知用户码",
+			userCode: func(*models.DeviceCodeResponse) string { return "BCDF-GHJK" },
+		},
+		{
+			name:     "过期后不能确认",
+			userCode: func(device *models.DeviceCodeResponse) string { return device.UserCode },
+			later:    DefaultDeviceCodeTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, user := newUser(t)
+			service := newService(users, auth.NewJWTManager("test-secret", 1), nil)
+			device, err := service.StartDevice(ctx)
+			require.NoError(t, err)
+
+			service.now = func() time.Time { return time.Now().Add(tt.later) }
+			assert.ErrorIs(t, service.VerifyDevice(ctx, tt.userCode(device), user.ID, true), ErrInvalidUserCode)
+		})
+	}
+}
+
+func TestMagicLink(t *testing.T) {
+	ctx := context.Background()
+	users, user := newUser(t)
+	publisher := &recordingPublisher{}
+	service := newService(users, auth.NewJWTManager("test-secret", 1), publisher)
+
+	expiresAt, err := service.RequestMagicLink(ctx, " alice@example.com ")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultMagicLinkTTL), expiresAt, time.Minute)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, EventMagicLinkRequested, publisher.events[0].Type)
+	var payload MagicLinkRequestedEvent
+	require.NoError(t, publisher.events[0].Decode(&payload))
+	assert.Equal(t, "alice@example.com", payload.Email)
+	assert.Equal(t, magicLinkURL+"&token="+payload.Token, payload.Link)
+
+	tokens, err := service.RedeemMagicLink(ctx, payload.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, tokens.User.ID)
+
+	_, err = service.RedeemMagicLink(ctx, payload.Token)
+	assert.ErrorIs(t, err, ErrInvalidMagicLink, "链接只能使用一次")
+	_, err = service.RedeemMagicLink(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrInvalidMagicLink)
+
+	t.Run("用户停用后链接失效", func(t *testing.T) {
+		_, err := service.RequestMagicLink(ctx, "alice@example.com")
+		require.NoError(t, err)
+		require.NoError(t, publisher.events[len(publisher.events)-1].Decode(&payload))
+		user.IsActive = false
+		require.NoError(t, users.Update(ctx, user))
+		_, err = service.RedeemMagicLink(ctx, payload.Token)
+		assert.ErrorIs(t, err, ErrInvalidMagicLink)
+	})
+}
+
+func TestRequestMagicLinkErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		publisher events.Publisher
+		email     string
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name:      "邮箱不存在时不发送也不报错",
+			publisher: &recordingPublisher{},
+			email:     "nobody@example.com",
+		},
+		{
+			name:      "发送失败时删除链接",
+			publisher: &recordingPublisher{err: errors.New("bus down")},
+			email:     "alice@example.com",
+			wantErr:   true,
+		},
+		{
+			name:      "没有事件发布器",
+			email:     "alice@example.com",
+			wantErr:   true,
+			wantErrIs: ErrMagicLinkUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, _ := newUser(t)
+			service := newService(users, auth.NewJWTManager("test-secret", 1), tt.publisher)
+
+			_, err := service.RequestMagicLink(ctx, tt.email)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Empty(t, tt.publisher.(*recordingPublisher).events)
+				return
+			}
+			assert.Error(t, err)
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+			}
+		})
+	}
+}
+
+func TestUserCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newUserCode()
+		require.NoError(t, err)
+		require.Len(t, code, userCodeLength)
+		seen[code] = true
+	}
+	assert.Greater(t, len(seen), 90)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"去掉空白和连字符并转为大写", " wdjb-mjht ", "WDJBMJHT"},
+		{"已规范的用户码不变", "WDJBMJHT", "WDJBMJHT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeUserCode(tt.input))
+		})
+	}
+	assert.Equal(t, "WDJB-MJHT", formatUserCode("WDJBMJHT"))
+}
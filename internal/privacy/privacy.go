@@ -0,0 +1,199 @@
+// Package privacy 处理用户的个人数据请求：导出个人数据的完整副本，以及抹除账户中的个人数据
+//
+// 抹除依次吊销用户已签发的令牌、删除站内信和通知偏好、删除头像文件、将用户行中的个人信息替换为占位值并软删除、
+// 删除待验证的邮箱变更。每一步都可以重复执行，某一步失败时返回错误，重试会从头执行并跳过已完成的效果。
+// 用户行保留以使引用用户ID的记录（如导出任务和公告的创建者）仍然有效。
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/pkg/cache"
+	"go-server/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// notificationsPageSize 导出时每次读取的站内信数
+const notificationsPageSize = 100
+
+// erasedDomain 抹除后的占位邮箱所在的域名，.invalid 顶级域名保证不会被投递
+const erasedDomain = "erased.invalid"
+
+// erasedPassword 抹除后的密码，不是任何支持算法的有效哈希，任何密码都无法匹配，登录时也不会重新哈希
+const erasedPassword = "!"
+
+// Inbox 用户的站内信和通知偏好，由通知服务实现
+type Inbox interface {
+	List(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]*models.Notification, int64, error)
+	Preferences(ctx context.Context, userID string) ([]models.NotificationPreference, error)
+	DeleteUserData(ctx context.Context, userID string) (int64, error)
+}
+
+// repositoryInbox 直接读写通知表的 Inbox，用于通知服务未启用时，之前写入的站内信同样属于个人数据
+type repositoryInbox struct {
+	repo repositories.NotificationRepository
+}
+
+// NewRepositoryInbox 返回直接读写通知仓库的 Inbox，通知偏好只包含用户保存过的记录
+func NewRepositoryInbox(repo repositories.NotificationRepository) Inbox {
+	return repositoryInbox{repo: repo}
+}
+
+// List 返回用户的一页站内信
+func (i repositoryInbox) List(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]*models.Notification, int64, error) {
+	return i.repo.List(ctx, userID, unreadOnly, (page-1)*limit, limit)
+}
+
+// Preferences 返回用户保存过的通知偏好
+func (i repositoryInbox) Preferences(ctx context.Context, userID string) ([]models.NotificationPreference, error) {
+	stored, err := i.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences := make([]models.NotificationPreference, len(stored))
+	for j, preference := range stored {
+		preferences[j] = *preference
+	}
+	return preferences, nil
+}
+
+// DeleteUserData 删除用户的全部站内信和通知偏好
+func (i repositoryInbox) DeleteUserData(ctx context.Context, userID string) (int64, error) {
+	return i.repo.DeleteUserData(ctx, userID)
+}
+
+// Service 个人数据服务
+type Service struct {
+	users     repositories.UserEraser
+	inbox     Inbox
+	storage   storage.Storage
+	cache     cache.Cache
+	blacklist *cache.BlacklistService
+	now       func() time.Time
+}
+
+// NewService 创建个人数据服务
+// inbox 为 nil 时不导出也不删除站内信，store 为 nil 时不删除头像文件，userCache 为 nil 时不删除待验证的邮箱变更，
+// blacklist 为 nil 时不吊销令牌
+func NewService(users repositories.UserEraser, inbox Inbox, store storage.Storage, userCache cache.Cache, blacklist *cache.BlacklistService) *Service {
+	return &Service{
+		users:     users,
+		inbox:     inbox,
+		storage:   store,
+		cache:     userCache,
+		blacklist: blacklist,
+		now:       time.Now,
+	}
+}
+
+// Export 返回用户个人数据的完整副本，已停用的用户也可以导出
+func (s *Service) Export(ctx context.Context, userID string) (*models.PersonalDataArchive, error) {
+	user, err := s.users.GetAnyByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &models.PersonalDataArchive{
+		Version:                 models.PersonalDataArchiveVersion,
+		GeneratedAt:             s.now().UTC(),
+		User:                    user.ToSafeUser(),
+		Notifications:           []models.Notification{},
+		NotificationPreferences: []models.NotificationPreference{},
+	}
+	if s.inbox == nil {
+		return archive, nil
+	}
+
+	for page := 1; ; page++ {
+		notifications, total, err := s.inbox.List(ctx, userID, false, page, notificationsPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notifications: %w", err)
+		}
+		for _, notification := range notifications {
+			archive.Notifications = append(archive.Notifications, *notification)
+		}
+		if len(notifications) < notificationsPageSize || int64(len(archive.Notifications)) >= total {
+			break
+		}
+	}
+
+	preferences, err := s.inbox.Preferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+	archive.NotificationPreferences = append(archive.NotificationPreferences, preferences...)
+	return archive, nil
+}
+
+// Erase 抹除用户的个人数据并软删除账户，已注销的账户也可以抹除
+func (s *Service) Erase(ctx context.Context, userID string) (*models.ErasureReport, error) {
+	user, err := s.users.GetAnyByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	report := &models.ErasureReport{UserID: userID, ErasedAt: now}
+
+	// 先吊销令牌，抹除过程中用户无法继续访问
+	if s.blacklist != nil {
+		if err := s.blacklist.RevokeUserTokens(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to revoke tokens: %w", err)
+		}
+		report.TokensRevoked = true
+	}
+
+	if s.inbox != nil {
+		deleted, err := s.inbox.DeleteUserData(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete notifications: %w", err)
+		}
+		report.NotificationsDeleted = deleted
+	}
+
+	// 头像文件在用户行之前删除，否则抹除后对象键丢失，文件无法再被找到
+	if user.AvatarKey != "" && s.storage != nil {
+		if err := s.storage.Delete(ctx, user.AvatarKey); err != nil {
+			return nil, fmt.Errorf("failed to delete avatar: %w", err)
+		}
+		report.AvatarDeleted = true
+	}
+
+	if err := s.users.Anonymize(ctx, anonymize(user, now)); err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Delete(ctx, services.EmailChangeKey(userID)); err != nil {
+			return nil, fmt.Errorf("failed to delete pending email change: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// anonymize 返回抹除个人数据后的用户：用户名和邮箱替换为由用户ID派生的占位值以保持唯一，
+// 姓名、头像和登录时间清空，账户停用并软删除，租户和创建时间保留
+func anonymize(user *models.User, at time.Time) *models.User {
+	id := strings.ReplaceAll(user.ID, "-", "")
+	erased := &models.User{
+		ID:        user.ID,
+		Username:  "erased_" + id,
+		Email:     id + "@" + erasedDomain,
+		Password:  erasedPassword,
+		TenantID:  user.TenantID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: at,
+		DeletedAt: user.DeletedAt,
+	}
+	if !erased.DeletedAt.Valid {
+		erased.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	}
+	return erased
+}
@@ -0,0 +1,208 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/storage"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUsers 创建包含 users 的内存用户仓库
+func newUsers(t *testing.T, users ...*models.User) *repositories.MemoryUserRepository {
+	t.Helper()
+	repo := repositories.NewMemoryUserRepository()
+	for _, user := range users {
+		require.NoError(t, repo.Create(context.Background(), user))
+	}
+	return repo
+}
+
+// notify 为用户创建 n 条站内信
+func notify(t *testing.T, repo *repositories.MemoryNotificationRepository, userID string, n int) {
+	t.Helper()
+	for range n {
+		require.NoError(t, repo.Create(context.Background(), &models.Notification{
+			UserID: userID, Type: "account.welcome", Subject: "欢迎", Body: "你好",
+		}))
+	}
+}
+
+func TestExport(t *testing.T) {
+	alice := factory.User().WithUsername("alice").WithName("Alice", "Liddell").Build()
+	bob := factory.User().WithUsername("bob").Build()
+	carol := factory.User().WithUsername("carol").WithName("Carol", "Jones").Inactive().Build()
+	notifications := repositories.NewMemoryNotificationRepository()
+	notify(t, notifications, alice.ID, notificationsPageSize+5)
+	notify(t, notifications, bob.ID, 1)
+	require.NoError(t, notifications.SavePreferences(context.Background(), []*models.NotificationPreference{
+		{UserID: alice.ID, Type: "account.welcome", Channel: "email", Enabled: false},
+	}))
+	// 导出只读取用户和站内信，不需要存储、缓存和令牌黑名单
+	service := NewService(newUsers(t, alice, bob, carol), NewRepositoryInbox(notifications), nil, nil, nil)
+
+	tests := []struct {
+		name              string
+		userID            string
+		wantErr           error
+		wantLastName      string
+		wantNotifications int
+		wantPreferences   int
+	}{
+		{
+			name:              "读取全部分页",
+			userID:            alice.ID,
+			wantLastName:      "Liddell",
+			wantNotifications: notificationsPageSize + 5,
+			wantPreferences:   1,
+		},
+		{
+			name:         "已停用的用户也可以导出",
+			userID:       carol.ID,
+			wantLastName: "Jones",
+		},
+		{
+			name:    "用户不存在",
+			userID:  "00000000-0000-4000-8000-000000000000",
+			wantErr: repositories.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := service.Export(context.Background(), tt.userID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.PersonalDataArchiveVersion, archive.Version)
+			assert.Equal(t, tt.userID, archive.User.ID)
+			assert.Equal(t, tt.wantLastName, archive.User.LastName)
+			assert.NotNil(t, archive.Notifications)
+			assert.Len(t, archive.Notifications, tt.wantNotifications)
+			assert.NotNil(t, archive.NotificationPreferences)
+			assert.Len(t, archive.NotificationPreferences, tt.wantPreferences)
+		})
+	}
+}
+
+func TestErase(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "avatars/alice.png", bytes.NewReader([]byte("png")), 3, "image/png")
+	require.NoError(t, err)
+
+	alice := factory.User().WithUsername("alice").WithEmail("alice@example.com").WithName("Alice", "Liddell").Build()
+	alice.AvatarKey, alice.Avatar = "avatars/alice.png", "/uploads/avatars/alice.png"
+	bob := factory.User().WithUsername("bob").Build()
+	users := newUsers(t, alice, bob)
+	notifications := repositories.NewMemoryNotificationRepository()
+	notify(t, notifications, alice.ID, 3)
+	notify(t, notifications, bob.ID, 1)
+
+	memoryCache := cache.NewMemoryCache()
+	jwt := auth.NewJWTManager("privacy-test-secret-key-at-least-32-bytes", 3600)
+	blacklist := cache.NewBlacklistService(memoryCache, jwt, nil)
+	cached := repositories.NewCachedUserRepository(users, memoryCache)
+	service := NewService(cached.(repositories.UserEraser), NewRepositoryInbox(notifications), store, memoryCache, blacklist)
+	require.NoError(t, memoryCache.Set(ctx, services.EmailChangeKey(alice.ID), "pending", time.Hour))
+
+	// 抹除前缓存的用户记录须失效，否则仍能按原邮箱查到用户
+	_, err = cached.GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	token, err := jwt.GenerateToken(alice.ID, alice.Username, alice.Email)
+	require.NoError(t, err)
+
+	report, err := service.Erase(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, report.UserID)
+	assert.Equal(t, int64(3), report.NotificationsDeleted)
+	assert.True(t, report.AvatarDeleted)
+	assert.True(t, report.TokensRevoked)
+
+	_, err = cached.GetByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+	_, err = users.GetAnyByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound, "内存仓库不保留已删除的用户")
+	exists, err := store.Exists(ctx, "avatars/alice.png")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	revoked, err := blacklist.IsBlacklisted(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	pending, err := memoryCache.Exists(ctx, services.EmailChangeKey(alice.ID))
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	inbox, total, err := notifications.List(ctx, bob.ID, false, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "其他用户的站内信不受影响")
+	assert.Len(t, inbox, 1)
+
+	_, err = service.Erase(ctx, alice.ID)
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+}
+
+func TestAnonymize(t *testing.T) {
+	at := time.Now().UTC().Truncate(time.Second)
+	tenantID := "tenant-1"
+
+	tests := []struct {
+		name          string
+		deletedAt     *time.Time
+		wantDeletedAt time.Time
+	}{
+		{
+			name:          "使用抹除时间作为注销时间",
+			wantDeletedAt: at,
+		},
+		{
+			name:          "保留原注销时间",
+			deletedAt:     ptrTime(at.Add(-24 * time.Hour)),
+			wantDeletedAt: at.Add(-24 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := factory.User().WithID("123e4567-e89b-12d3-a456-426614174000").WithName("Alice", "Liddell").WithTenant(tenantID).Admin().Build()
+			user.LastLogin = ptrTime(at.Add(-time.Hour))
+			user.AvatarKey = "avatars/alice.png"
+			if tt.deletedAt != nil {
+				user.DeletedAt.Time, user.DeletedAt.Valid = *tt.deletedAt, true
+			}
+
+			erased := anonymize(user, at)
+			assert.Equal(t, user.ID, erased.ID)
+			assert.Equal(t, "erased_123e4567e89b12d3a456426614174000", erased.Username)
+			assert.Equal(t, "123e4567e89b12d3a456426614174000@erased.invalid", erased.Email)
+			assert.Equal(t, erasedPassword, erased.Password)
+			assert.Empty(t, erased.FirstName)
+			assert.Empty(t, erased.LastName)
+			assert.Empty(t, erased.AvatarKey)
+			assert.Nil(t, erased.LastLogin)
+			assert.False(t, erased.IsActive)
+			assert.False(t, erased.IsAdmin)
+			assert.Equal(t, &tenantID, erased.TenantID)
+			assert.Equal(t, user.CreatedAt, erased.CreatedAt)
+			assert.Equal(t, tt.wantDeletedAt, erased.DeletedAt.Time)
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrAnnouncementNotFound is returned when an announcement does not exist
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+// AnnouncementRepository defines the interface for admin-managed announcements.
+// Announcements carry an explicit target tenant, so every method works across tenants.
+type AnnouncementRepository interface {
+	// Create stores an announcement, assigning its ID and timestamps
+	Create(ctx context.Context, announcement *models.Announcement) error
+	// GetByID returns an announcement by ID
+	GetByID(ctx context.Context, id string) (*models.Announcement, error)
+	// List returns a page of announcements, newest first, and the total number of announcements
+	List(ctx context.Context, offset, limit int) ([]*models.Announcement, int64, error)
+	// ListUnexpired returns all announcements that have not expired at the given time, including scheduled ones, newest first
+	ListUnexpired(ctx context.Context, at time.Time) ([]*models.Announcement, error)
+	// Update replaces an announcement's definition, keeping its creator and creation time
+	Update(ctx context.Context, announcement *models.Announcement) error
+	// Delete removes an announcement
+	Delete(ctx context.Context, id string) error
+}
+
+type announcementRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnouncementRepository creates a new announcement repository
+func NewAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+// Create inserts an announcement
+func (r *announcementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	if err := r.db.WithContext(ctx).Create(announcement).Error; err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return nil
+}
+
+// GetByID finds an announcement by primary key
+func (r *announcementRepository) GetByID(ctx context.Context, id string) (*models.Announcement, error) {
+	var announcement models.Announcement
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&announcement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAnnouncementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+	return &announcement, nil
+}
+
+// List returns a page of announcements ordered by creation time
+func (r *announcementRepository) List(ctx context.Context, offset, limit int) ([]*models.Announcement, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Announcement{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+
+	var announcements []*models.Announcement
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(limit).Find(&announcements).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return announcements, total, nil
+}
+
+// ListUnexpired returns announcements without an end time or ending after at, using idx_announcements_ends_at
+func (r *announcementRepository) ListUnexpired(ctx context.Context, at time.Time) ([]*models.Announcement, error) {
+	var announcements []*models.Announcement
+	err := r.db.WithContext(ctx).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("created_at DESC").Order("id DESC").
+		Find(&announcements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unexpired announcements: %w", err)
+	}
+	return announcements, nil
+}
+
+// Update overwrites the editable columns of an announcement
+func (r *announcementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	result := r.db.WithContext(ctx).Model(&models.Announcement{}).
+		Where("id = ?", announcement.ID).
+		Select("title", "body", "level", "roles", "target_tenant_id", "starts_at", "ends_at", "updated_at").
+		Updates(announcement)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
+
+// Delete removes an announcement by primary key
+func (r *announcementRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Announcement{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
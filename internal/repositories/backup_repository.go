@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrBackupNotFound is returned when a backup does not exist
+var ErrBackupNotFound = errors.New("backup not found")
+
+// BackupRepository defines the interface for database backup jobs.
+// Backups describe the whole database, so they are not scoped to a tenant.
+type BackupRepository interface {
+	// Create stores a new backup job, assigning its ID and timestamps
+	Create(ctx context.Context, backup *models.Backup) error
+	// CreateScheduled stores a new scheduled backup unless another scheduled backup was created after since,
+	// so that only one of several instances checking the schedule at the same time starts a backup
+	CreateScheduled(ctx context.Context, backup *models.Backup, since time.Time) (bool, error)
+	// GetByID returns a backup by ID
+	GetByID(ctx context.Context, id string) (*models.Backup, error)
+	// List returns backups newest first, with the total count
+	List(ctx context.Context, offset, limit int) ([]*models.Backup, int64, error)
+	// MarkRunning records that a backup has started writing its archive
+	MarkRunning(ctx context.Context, id string) error
+	// Finish records the final state of a backup: its status, archive, error and expiry
+	Finish(ctx context.Context, backup *models.Backup) error
+	// ListExpired returns up to limit backups whose expiry time is before at
+	ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Backup, error)
+	// Delete removes a backup
+	Delete(ctx context.Context, id string) error
+}
+
+type backupRepository struct {
+	db *gorm.DB
+}
+
+// NewBackupRepository creates a new backup repository
+func NewBackupRepository(db *gorm.DB) BackupRepository {
+	return &backupRepository{db: db}
+}
+
+// Create inserts a backup job
+func (r *backupRepository) Create(ctx context.Context, backup *models.Backup) error {
+	if err := r.db.WithContext(ctx).Create(backup).Error; err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	return nil
+}
+
+// CreateScheduled inserts a scheduled backup with a single INSERT ... SELECT ... WHERE NOT EXISTS,
+// which narrows the window in which two instances can both claim the same slot to one statement
+func (r *backupRepository) CreateScheduled(ctx context.Context, backup *models.Backup, since time.Time) (bool, error) {
+	if backup.ID == "" {
+		backup.ID = uuid.NewString()
+	}
+	now := time.Now()
+	backup.Trigger = models.BackupTriggerScheduled
+	backup.Status = models.BackupStatusPending
+	backup.CreatedAt, backup.UpdatedAt = now, now
+
+	result := r.db.WithContext(ctx).Exec(
+		`INSERT INTO backups (id, trigger, status, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM backups WHERE trigger = ? AND created_at > ?)`,
+		backup.ID, backup.Trigger, backup.Status, now, now,
+		models.BackupTriggerScheduled, since,
+	)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create scheduled backup: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetByID finds a backup by primary key
+func (r *backupRepository) GetByID(ctx context.Context, id string) (*models.Backup, error) {
+	var backup models.Backup
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&backup).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBackupNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	return &backup, nil
+}
+
+// List returns a page of backups ordered by creation time, newest first
+func (r *backupRepository) List(ctx context.Context, offset, limit int) ([]*models.Backup, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Backup{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count backups: %w", err)
+	}
+
+	var backups []*models.Backup
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&backups).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return backups, total, nil
+}
+
+// MarkRunning moves a backup to the running state
+func (r *backupRepository) MarkRunning(ctx context.Context, id string) error {
+	return r.update(ctx, id, map[string]interface{}{"status": models.BackupStatusRunning})
+}
+
+// Finish overwrites the result columns of a backup
+func (r *backupRepository) Finish(ctx context.Context, backup *models.Backup) error {
+	return r.update(ctx, backup.ID, map[string]interface{}{
+		"status":       backup.Status,
+		"size":         backup.Size,
+		"checksum":     backup.Checksum,
+		"object_key":   backup.ObjectKey,
+		"error":        backup.Error,
+		"completed_at": backup.CompletedAt,
+		"expires_at":   backup.ExpiresAt,
+	})
+}
+
+// update writes columns explicitly, since Updates with a struct skips zero values
+func (r *backupRepository) update(ctx context.Context, id string, columns map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&models.Backup{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update backup: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrBackupNotFound
+	}
+	return nil
+}
+
+// ListExpired returns backups past their expiry time, oldest first, using idx_backups_expires_at
+func (r *backupRepository) ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Backup, error) {
+	var backups []*models.Backup
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", at).
+		Order("expires_at").
+		Limit(limit).
+		Find(&backups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired backups: %w", err)
+	}
+	return backups, nil
+}
+
+// Delete removes a backup by primary key
+func (r *backupRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Backup{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete backup: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrBackupNotFound
+	}
+	return nil
+}
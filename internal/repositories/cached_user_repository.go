@@ -2,286 +2,643 @@ package repositories
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"go-server/internal/cache_manager"
+	"go-server/internal/metrics"
 	"go-server/internal/models"
+	"go-server/internal/tenancy"
 	"go-server/pkg/cache"
 )
 
+// DefaultCacheTTL default time-to-live for cached user data
+const DefaultCacheTTL = 5 * time.Minute
+
+// DefaultNegativeCacheTTL default time-to-live for cached "not found" results
+// Kept short so that a user created through another instance becomes visible quickly
+const DefaultNegativeCacheTTL = 30 * time.Second
+
+// negativeCacheSentinel is cached in place of a user when the lookup found nothing
+const negativeCacheSentinel = "__not_found__"
+
+// UserListCacheTag tags every cached user list and count so they can be invalidated together
+// without scanning the key space
+const UserListCacheTag = cache_manager.UserListCacheTag
+
+// UserCacheTag tags caches derived from a single user, such as cached HTTP responses
+func UserCacheTag(userID string) string {
+	return cache_manager.UserCacheTag(userID)
+}
+
+// Cache methods whose TTL can be overridden with SetMethodTTLs
+const (
+	CacheMethodGetByID       = "get_by_id"
+	CacheMethodGetByEmail    = "get_by_email"
+	CacheMethodGetByUsername = "get_by_username"
+	CacheMethodGetAll        = "get_all"
+	CacheMethodExists        = "exists"
+	CacheMethodCount         = "count"
+)
+
+// cacheTTLJitter spreads expirations by ±10% so entries cached together don't expire together
+const cacheTTLJitter = 0.1
+
 // CachedUserRepository implements the UserRepository interface with caching support
-// It follows the decorator pattern, wrapping an existing UserRepository instance
+// It follows the decorator pattern, wrapping an existing UserRepository instance.
+// Cache keys are scoped to the tenant in the caller's context, so lookups made for one tenant
+// never return users or lists cached for another
 type CachedUserRepository struct {
 	repo  UserRepository
 	cache cache.Cache
-	ttl   time.Duration
+	ttl   atomic.Int64 // 缓存过期时间，支持配置热重载
+	group cache.Group  // 合并同一缓存键的并发未命中，避免热点键过期时大量请求同时访问数据库
+
+	negativeTTL atomic.Int64                             // 未找到结果的缓存过期时间，0 表示不缓存
+	methodTTLs  atomic.Pointer[map[string]time.Duration] // 按方法覆盖的缓存过期时间，支持配置热重载
+	codec       cache.Codec                              // 缓存值的序列化方式
+	metrics     *metrics.CacheMetrics                    // 查询的命中、负缓存命中、未命中和数据库加载统计
+
+	// 缓冲的最后登录时间，为 nil 时每次登录直接更新数据库
+	lastLogins LastLoginStore
+
+	// 写入缓存失败时调用，operation 为"序列化"或"写入"；缓存失败不影响查询结果
+	onError func(operation string, err error)
+}
+
+// CachedUserRepositoryOption configures a CachedUserRepository
+type CachedUserRepositoryOption func(*CachedUserRepository)
+
+// WithCacheCodec sets the codec used to serialize cached entries; JSON is used by default
+func WithCacheCodec(codec cache.Codec) CachedUserRepositoryOption {
+	return func(c *CachedUserRepository) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
+
+// WithCacheErrorHandler reports cache entries that could not be serialized or written.
+// Lookups still return the loaded result, so without a handler these failures only show up as cache misses
+func WithCacheErrorHandler(onError func(operation string, err error)) CachedUserRepositoryOption {
+	return func(c *CachedUserRepository) {
+		c.onError = onError
+	}
+}
+
+// WithLastLoginStore buffers UpdateLastLogin in the store instead of updating the database on every login.
+// The buffered logins must be flushed to the database, see services.LastLoginFlusher; users loaded into the
+// cache carry the buffered time when it is later than the stored one
+func WithLastLoginStore(store LastLoginStore) CachedUserRepositoryOption {
+	return func(c *CachedUserRepository) {
+		c.lastLogins = store
+	}
 }
 
 // NewCachedUserRepository creates a new cached user repository decorator
 // It wraps the provided user repository with caching functionality
-func NewCachedUserRepository(repo UserRepository, cache cache.Cache) UserRepository {
-	return &CachedUserRepository{
-		repo:  repo,
-		cache: cache,
-		ttl:   5 * time.Minute, // 5-minute TTL as specified
+func NewCachedUserRepository(repo UserRepository, appCache cache.Cache, opts ...CachedUserRepositoryOption) UserRepository {
+	cached := &CachedUserRepository{
+		repo:    repo,
+		cache:   appCache,
+		codec:   cache.JSONCodec{},
+		metrics: metrics.NewCacheMetrics(),
+	}
+	cached.ttl.Store(int64(DefaultCacheTTL))
+	cached.negativeTTL.Store(int64(DefaultNegativeCacheTTL))
+	for _, opt := range opts {
+		opt(cached)
+	}
+	return cached
+}
+
+// SetTTL updates the time-to-live used for newly cached entries
+// Entries already in the cache keep their original expiration
+func (c *CachedUserRepository) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
 	}
+	c.ttl.Store(int64(ttl))
+}
+
+// TTL returns the current time-to-live for cached entries
+func (c *CachedUserRepository) TTL() time.Duration {
+	return time.Duration(c.ttl.Load())
+}
+
+// SetMethodTTLs replaces the per-method TTL overrides, keyed by the CacheMethod constants
+// Methods without a positive override use TTL
+func (c *CachedUserRepository) SetMethodTTLs(ttls map[string]time.Duration) {
+	overrides := make(map[string]time.Duration, len(ttls))
+	for method, ttl := range ttls {
+		if ttl > 0 {
+			overrides[method] = ttl
+		}
+	}
+	c.methodTTLs.Store(&overrides)
+}
+
+// MethodTTL returns the time-to-live used for entries cached by the given method
+func (c *CachedUserRepository) MethodTTL(method string) time.Duration {
+	if overrides := c.methodTTLs.Load(); overrides != nil {
+		if ttl, ok := (*overrides)[method]; ok {
+			return ttl
+		}
+	}
+	return c.TTL()
+}
+
+// Codec returns the codec used to serialize cached entries
+func (c *CachedUserRepository) Codec() cache.Codec {
+	return c.codec
+}
+
+// SetNegativeTTL updates the time-to-live used for cached "not found" results
+// A non-positive TTL disables negative caching
+func (c *CachedUserRepository) SetNegativeTTL(ttl time.Duration) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.negativeTTL.Store(int64(ttl))
+}
+
+// NegativeTTL returns the current time-to-live for cached "not found" results
+func (c *CachedUserRepository) NegativeTTL() time.Duration {
+	return time.Duration(c.negativeTTL.Load())
+}
+
+// Metrics returns the hit, negative hit, miss and load statistics of cached lookups
+func (c *CachedUserRepository) Metrics() metrics.CacheStats {
+	return c.metrics.GetStats()
+}
+
+// MetricsCollector returns the collector behind Metrics, for exporting to Prometheus
+func (c *CachedUserRepository) MetricsCollector() *metrics.CacheMetrics {
+	return c.metrics
 }
 
 // Create creates a new user and invalidates relevant cache entries
-func (c *CachedUserRepository) Create(user *models.User) error {
-	err := c.repo.Create(user)
+func (c *CachedUserRepository) Create(ctx context.Context, user *models.User) error {
+	err := c.repo.Create(ctx, user)
 	if err != nil {
 		return err
 	}
 
 	// Invalidate cache entries that might be affected
-	ctx := context.Background()
 	c.invalidateUserCache(ctx, user)
 
 	return nil
 }
 
 // GetByID gets a user by ID with caching
-func (c *CachedUserRepository) GetByID(id string) (*models.User, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("user:id:%s", id)
+func (c *CachedUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("user:id:%s", id))
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if user, ok := c.unmarshalUser(cachedValue); ok {
-			return user, nil
-		}
+	if user, err, found := c.getCachedUser(ctx, cacheKey); found {
+		return user, err
 	}
 
-	// Cache miss or error, get from database
-	user, err := c.repo.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Cache the result
-	if user != nil {
-		if err := c.cache.Set(ctx, cacheKey, user, c.ttl); err != nil {
-			// Log error but don't fail the operation
-			// In a real application, you'd want to log this error
-		}
-	}
-
-	return user, nil
+	// Cache miss or error, get from database; concurrent misses share a single query
+	return c.loadUser(ctx, CacheMethodGetByID, cacheKey, func() (*models.User, error) {
+		return c.repo.GetByID(ctx, id)
+	})
 }
 
 // GetByEmail gets a user by email with caching
-func (c *CachedUserRepository) GetByEmail(email string) (*models.User, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("user:email:%s", email)
+func (c *CachedUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("user:email:%s", email))
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if user, ok := c.unmarshalUser(cachedValue); ok {
-			return user, nil
-		}
-	}
-
-	// Cache miss or error, get from database
-	user, err := c.repo.GetByEmail(email)
-	if err != nil {
-		return nil, err
-	}
-
-	// Cache the result
-	if user != nil {
-		if err := c.cache.Set(ctx, cacheKey, user, c.ttl); err != nil {
-			// Log error but don't fail the operation
-		}
+	if user, err, found := c.getCachedUser(ctx, cacheKey); found {
+		return user, err
 	}
 
-	return user, nil
+	// Cache miss or error, get from database; concurrent misses share a single query
+	return c.loadUser(ctx, CacheMethodGetByEmail, cacheKey, func() (*models.User, error) {
+		return c.repo.GetByEmail(ctx, email)
+	})
 }
 
 // GetByUsername gets a user by username with caching
-func (c *CachedUserRepository) GetByUsername(username string) (*models.User, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("user:username:%s", username)
+func (c *CachedUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("user:username:%s", username))
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if user, ok := c.unmarshalUser(cachedValue); ok {
-			return user, nil
-		}
-	}
-
-	// Cache miss or error, get from database
-	user, err := c.repo.GetByUsername(username)
-	if err != nil {
-		return nil, err
+	if user, err, found := c.getCachedUser(ctx, cacheKey); found {
+		return user, err
 	}
 
-	// Cache the result
-	if user != nil {
-		if err := c.cache.Set(ctx, cacheKey, user, c.ttl); err != nil {
-			// Log error but don't fail the operation
-		}
-	}
-
-	return user, nil
+	// Cache miss or error, get from database; concurrent misses share a single query
+	return c.loadUser(ctx, CacheMethodGetByUsername, cacheKey, func() (*models.User, error) {
+		return c.repo.GetByUsername(ctx, username)
+	})
 }
 
 // GetAll gets all users with pagination and caching
-func (c *CachedUserRepository) GetAll(offset, limit int) ([]*models.User, int64, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("users:all:%d:%d", offset, limit)
+func (c *CachedUserRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("users:all:%d:%d", offset, limit))
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if result, ok := c.unmarshalUserListResult(cachedValue); ok {
-			return result.Users, result.Total, nil
-		}
+	if entry, found := cache.GetAsWith[cachedUserList](ctx, c.cache, c.codec, cacheKey); found && entry.Version == cacheEntryVersion {
+		c.metrics.RecordKeyHit(cacheKey)
+		return entry.toModels(), entry.Total, nil
 	}
+	c.metrics.RecordKeyMiss(cacheKey)
+
+	// Cache miss or error, get from database; concurrent misses share a single query
+	value, err, shared := c.load(cacheKey, func() (interface{}, error) {
+		users, total, err := c.repo.GetAll(ctx, offset, limit)
+		if err != nil {
+			return nil, err
+		}
 
-	// Cache miss or error, get from database
-	users, total, err := c.repo.GetAll(offset, limit)
+		// Cache the result
+		c.setCache(ctx, CacheMethodGetAll, cacheKey, newCachedUserList(users, total), UserListCacheTag)
+		return UserListResult{
+			Users: users,
+			Total: total,
+		}, nil
+	})
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Cache the result
-	result := UserListResult{
-		Users: users,
-		Total: total,
-	}
-	if err := c.cache.Set(ctx, cacheKey, result, c.ttl); err != nil {
-		// Log error but don't fail the operation
+	result := value.(UserListResult)
+	if shared {
+		users := make([]*models.User, len(result.Users))
+		for i, user := range result.Users {
+			users[i] = copyUser(user)
+		}
+		return users, result.Total, nil
 	}
-
-	return users, total, nil
+	return result.Users, result.Total, nil
 }
 
 // Update updates a user and invalidates relevant cache entries
-func (c *CachedUserRepository) Update(user *models.User) error {
-	err := c.repo.Update(user)
+func (c *CachedUserRepository) Update(ctx context.Context, user *models.User) error {
+	err := c.repo.Update(ctx, user)
 	if err != nil {
 		return err
 	}
 
 	// Invalidate cache entries that might be affected
-	ctx := context.Background()
 	c.invalidateUserCache(ctx, user)
 
 	return nil
 }
 
 // Delete soft deletes a user and invalidates relevant cache entries
-func (c *CachedUserRepository) Delete(id string) error {
+func (c *CachedUserRepository) Delete(ctx context.Context, id string) error {
 	// Get the user before deletion to invalidate proper cache keys
-	user, err := c.repo.GetByID(id)
+	user, err := c.repo.GetByID(ctx, id)
 	if err != nil {
 		// Continue with deletion even if we can't get the user
 		user = &models.User{ID: id}
 	}
 
-	err = c.repo.Delete(id)
+	err = c.repo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Invalidate cache entries that might be affected
-	ctx := context.Background()
 	c.invalidateUserCache(ctx, user)
 
 	return nil
 }
 
 // UpdateLastLogin updates the last login time for a user and invalidates cache
-func (c *CachedUserRepository) UpdateLastLogin(id string) error {
-	err := c.repo.UpdateLastLogin(id)
-	if err != nil {
-		return err
+// With a last login store the login is buffered, falling back to the database when the store fails
+func (c *CachedUserRepository) UpdateLastLogin(ctx context.Context, id string) error {
+	if c.lastLogins == nil || c.lastLogins.Record(ctx, id, time.Now().UTC()) != nil {
+		if err := c.repo.UpdateLastLogin(ctx, id); err != nil {
+			return err
+		}
 	}
 
 	// Invalidate cache entries that might be affected
-	ctx := context.Background()
 	c.invalidateUserCacheByID(ctx, id)
 
 	return nil
 }
 
-// ExistsByEmail checks if a user exists by email with caching
-func (c *CachedUserRepository) ExistsByEmail(email string) (bool, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("user:exists:email:%s", email)
+// errImportUnsupported is returned by the bulk import methods when the wrapped repository does not implement UserImporter
+var errImportUnsupported = errors.New("user repository does not support bulk imports")
 
-	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if exists, ok := cachedValue.(bool); ok {
-			return exists, nil
-		}
+// FindConflicts looks up conflicting users in the wrapped repository without caching
+func (c *CachedUserRepository) FindConflicts(ctx context.Context, emails, usernames []string) ([]*models.User, error) {
+	importer, ok := c.repo.(UserImporter)
+	if !ok {
+		return nil, errImportUnsupported
 	}
+	return importer.FindConflicts(ctx, emails, usernames)
+}
 
-	// Cache miss or error, get from database
-	exists, err := c.repo.ExistsByEmail(email)
-	if err != nil {
-		return false, err
+// CreateBatch creates the users through the wrapped repository and invalidates the entries cached for them,
+// including negative entries cached while their emails and usernames were unknown
+func (c *CachedUserRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+	importer, ok := c.repo.(UserImporter)
+	if !ok {
+		return errImportUnsupported
+	}
+	if err := importer.CreateBatch(ctx, users); err != nil {
+		return err
 	}
 
-	// Cache the result
-	if err := c.cache.Set(ctx, cacheKey, exists, c.ttl); err != nil {
+	keys := make([]string, 0, len(users)*4)
+	for _, user := range users {
+		keys = append(keys,
+			fmt.Sprintf("user:email:%s", user.Email),
+			fmt.Sprintf("user:username:%s", user.Username),
+			fmt.Sprintf("user:exists:email:%s", user.Email),
+			fmt.Sprintf("user:exists:username:%s", user.Username),
+		)
+	}
+	if err := cache.Invalidate(ctx, c.cache, scopedKeys(ctx, tenancy.ID(ctx), keys), UserListCacheTag); err != nil {
 		// Log error but don't fail the operation
 	}
+	return nil
+}
+
+// errErasureUnsupported is returned by the erasure methods when the wrapped repository does not implement UserEraser
+var errErasureUnsupported = errors.New("user repository does not support erasure")
 
-	return exists, nil
+// GetAnyByID gets a user, including deactivated and deleted users, from the wrapped repository without caching
+func (c *CachedUserRepository) GetAnyByID(ctx context.Context, id string) (*models.User, error) {
+	eraser, ok := c.repo.(UserEraser)
+	if !ok {
+		return nil, errErasureUnsupported
+	}
+	return eraser.GetAnyByID(ctx, id)
 }
 
-// ExistsByUsername checks if a user exists by username with caching
-func (c *CachedUserRepository) ExistsByUsername(username string) (bool, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("user:exists:username:%s", username)
+// Anonymize anonymizes the user through the wrapped repository and invalidates the entries cached
+// under its old email and username as well as responses cached for it
+func (c *CachedUserRepository) Anonymize(ctx context.Context, user *models.User) error {
+	eraser, ok := c.repo.(UserEraser)
+	if !ok {
+		return errErasureUnsupported
+	}
+	previous, err := eraser.GetAnyByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := eraser.Anonymize(ctx, user); err != nil {
+		return err
+	}
+
+	c.invalidateUserCache(ctx, previous)
+	c.invalidateUserCache(ctx, user)
+	return nil
+}
+
+// Search lists users matching the filter. Filtered admin listings are not cached.
+func (c *CachedUserRepository) Search(ctx context.Context, filter UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	return c.repo.Search(ctx, filter, offset, limit)
+}
+
+// errStreamUnsupported is returned by StreamUsers when the wrapped repository does not implement UserStreamer
+var errStreamUnsupported = errors.New("user repository does not support streaming")
+
+// StreamUsers streams users from the wrapped repository without caching
+func (c *CachedUserRepository) StreamUsers(ctx context.Context, filter UserFilter, fn func(user *models.User) error) error {
+	streamer, ok := c.repo.(UserStreamer)
+	if !ok {
+		return errStreamUnsupported
+	}
+	return streamer.StreamUsers(ctx, filter, fn)
+}
+
+// SetActive activates or deactivates a user and invalidates its cache entries,
+// including negative entries left behind while the user was inactive
+func (c *CachedUserRepository) SetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	user, err := c.repo.SetActive(ctx, id, active)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidateUserCache(ctx, user)
+	return user, nil
+}
+
+// SetAdmin grants or revokes the admin role and invalidates cache entries
+func (c *CachedUserRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error) {
+	user, err := c.repo.SetAdmin(ctx, id, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidateUserCache(ctx, user)
+	return user, nil
+}
+
+// ExistsByEmail checks if a user exists by email with caching
+func (c *CachedUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("user:exists:email:%s", email))
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if exists, ok := cachedValue.(bool); ok {
-			return exists, nil
-		}
+	if exists, found := cache.GetAsWith[bool](ctx, c.cache, c.codec, cacheKey); found {
+		c.metrics.RecordKeyHit(cacheKey)
+		return exists, nil
 	}
+	c.metrics.RecordKeyMiss(cacheKey)
 
-	// Cache miss or error, get from database
-	exists, err := c.repo.ExistsByUsername(username)
+	// Cache miss or error, get from database; concurrent misses share a single query
+	value, err, _ := c.load(cacheKey, func() (interface{}, error) {
+		exists, err := c.repo.ExistsByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		c.setCache(ctx, CacheMethodExists, cacheKey, exists)
+		return exists, nil
+	})
 	if err != nil {
 		return false, err
 	}
 
-	// Cache the result
-	if err := c.cache.Set(ctx, cacheKey, exists, c.ttl); err != nil {
-		// Log error but don't fail the operation
+	return value.(bool), nil
+}
+
+// ExistsByUsername checks if a user exists by username with caching
+func (c *CachedUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	cacheKey := tenancy.CacheKey(ctx, fmt.Sprintf("user:exists:username:%s", username))
+
+	// Try to get from cache first
+	if exists, found := cache.GetAsWith[bool](ctx, c.cache, c.codec, cacheKey); found {
+		c.metrics.RecordKeyHit(cacheKey)
+		return exists, nil
+	}
+	c.metrics.RecordKeyMiss(cacheKey)
+
+	// Cache miss or error, get from database; concurrent misses share a single query
+	value, err, _ := c.load(cacheKey, func() (interface{}, error) {
+		exists, err := c.repo.ExistsByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		c.setCache(ctx, CacheMethodExists, cacheKey, exists)
+		return exists, nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return exists, nil
+	return value.(bool), nil
 }
 
 // Count returns the total number of active users with caching
-func (c *CachedUserRepository) Count() (int64, error) {
-	ctx := context.Background()
-	cacheKey := "users:count"
+func (c *CachedUserRepository) Count(ctx context.Context) (int64, error) {
+	cacheKey := tenancy.CacheKey(ctx, "users:count")
 
 	// Try to get from cache first
-	if cachedValue, found := c.cache.Get(ctx, cacheKey); found {
-		if count, ok := cachedValue.(int64); ok {
-			return count, nil
-		}
+	if count, found := cache.GetAsWith[int64](ctx, c.cache, c.codec, cacheKey); found {
+		c.metrics.RecordKeyHit(cacheKey)
+		return count, nil
 	}
+	c.metrics.RecordKeyMiss(cacheKey)
 
-	// Cache miss or error, get from database
-	count, err := c.repo.Count()
+	// Cache miss or error, get from database; concurrent misses share a single query
+	value, err, _ := c.load(cacheKey, func() (interface{}, error) {
+		count, err := c.repo.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		c.setCache(ctx, CacheMethodCount, cacheKey, count, UserListCacheTag)
+		return count, nil
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	// Cache the result
-	if err := c.cache.Set(ctx, cacheKey, count, c.ttl); err != nil {
+	return value.(int64), nil
+}
+
+// getCachedUser looks up a single user in the cache
+// found is true for both cached users and cached "not found" results; the latter return ErrUserNotFound
+func (c *CachedUserRepository) getCachedUser(ctx context.Context, cacheKey string) (*models.User, error, bool) {
+	if data, found := c.cache.GetBytes(ctx, cacheKey); found {
+		if string(data) == negativeCacheSentinel {
+			c.metrics.RecordKeyNegativeHit(cacheKey)
+			return nil, ErrUserNotFound, true
+		}
+		var entry cachedUser
+		if err := c.codec.Unmarshal(data, &entry); err == nil && entry.Version == cacheEntryVersion {
+			c.metrics.RecordKeyHit(cacheKey)
+			return entry.User.toModel(), nil, true
+		}
+	}
+
+	c.metrics.RecordKeyMiss(cacheKey)
+	return nil, nil, false
+}
+
+// loadUser loads a single user on a cache miss and caches it
+// Concurrent callers for the same key share one database query; each caller gets its own copy
+// so that callers modifying the returned user don't affect each other.
+// A "not found" result is cached as a sentinel with the negative TTL so that repeated lookups
+// of missing users don't reach the database
+func (c *CachedUserRepository) loadUser(ctx context.Context, method, cacheKey string, load func() (*models.User, error)) (*models.User, error) {
+	value, err, shared := c.load(cacheKey, func() (interface{}, error) {
+		user, err := load()
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				c.setNegativeCache(ctx, cacheKey)
+			}
+			return nil, err
+		}
+
+		// Cache the result
+		if user != nil {
+			c.applyBufferedLogin(ctx, user)
+			c.setCache(ctx, method, cacheKey, cachedUser{Version: cacheEntryVersion, User: newUserRecord(user)})
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user := value.(*models.User)
+	if shared {
+		return copyUser(user), nil
+	}
+	return user, nil
+}
+
+// applyBufferedLogin sets the user's last login to the buffered one when it is later than the stored one
+func (c *CachedUserRepository) applyBufferedLogin(ctx context.Context, user *models.User) {
+	if c.lastLogins == nil {
+		return
+	}
+	at, found, err := c.lastLogins.Get(ctx, user.ID)
+	if err != nil || !found {
+		return
+	}
+	if user.LastLogin == nil || user.LastLogin.Before(at) {
+		user.LastLogin = &at
+	}
+}
+
+// load runs fn through the single-flight group, recording how long the shared load took
+// A "not found" result is a successful load
+func (c *CachedUserRepository) load(cacheKey string, fn func() (interface{}, error)) (interface{}, error, bool) {
+	return c.group.Do(cacheKey, func() (interface{}, error) {
+		start := time.Now()
+		value, err := fn()
+		c.metrics.RecordLoad(cacheKey, time.Since(start), err == nil || errors.Is(err, ErrUserNotFound))
+		return value, err
+	})
+}
+
+// setCache encodes a value with the configured codec and caches it with the method's TTL plus jitter,
+// associating it with the given tags
+func (c *CachedUserRepository) setCache(ctx context.Context, method, cacheKey string, value interface{}, tags ...string) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		c.reportError("序列化", fmt.Errorf("%s (codec %s): %w", method, c.codec.Name(), err))
+		return
+	}
+	if err := c.cache.SetWithTags(ctx, cacheKey, data, cache.JitterTTL(c.MethodTTL(method), cacheTTLJitter), tags...); err != nil {
+		// Don't fail the operation, the next lookup loads from the database again
+		c.reportError("写入", fmt.Errorf("%s: %w", method, err))
+	}
+}
+
+// reportError passes a cache write failure to the error handler, if set
+func (c *CachedUserRepository) reportError(operation string, err error) {
+	if c.onError != nil {
+		c.onError(operation, err)
+	}
+}
+
+// setNegativeCache caches the "not found" sentinel with the negative TTL, if enabled
+func (c *CachedUserRepository) setNegativeCache(ctx context.Context, cacheKey string) {
+	ttl := c.NegativeTTL()
+	if ttl <= 0 {
+		return
+	}
+	if err := c.cache.Set(ctx, cacheKey, negativeCacheSentinel, cache.JitterTTL(ttl, cacheTTLJitter)); err != nil {
 		// Log error but don't fail the operation
 	}
+}
 
-	return count, nil
+// copyUser returns a shallow copy of a user shared between concurrent callers
+func copyUser(user *models.User) *models.User {
+	if user == nil {
+		return nil
+	}
+	copied := *user
+	return &copied
 }
 
 // invalidateUserCache invalidates all cache entries related to a user
@@ -298,91 +655,44 @@ func (c *CachedUserRepository) invalidateUserCache(ctx context.Context, user *mo
 		fmt.Sprintf("user:exists:email:%s", user.Email),
 		fmt.Sprintf("user:exists:username:%s", user.Username),
 	}
+	tenantID := ""
+	if user.TenantID != nil {
+		tenantID = *user.TenantID
+	}
 
-	// Delete keys in batch
-	if err := c.cache.DeleteMultiple(ctx, keys); err != nil {
+	// Delete the keys together with other caches derived from this user, e.g. cached responses,
+	// and the list caches that might contain this user, in a single batch
+	if err := cache.Invalidate(ctx, c.cache, scopedKeys(ctx, tenantID, keys), UserCacheTag(user.ID), UserListCacheTag); err != nil {
 		// Log error but don't fail the operation
 	}
-
-	// Invalidate list caches (they might contain this user)
-	c.invalidateUserListCaches(ctx)
 }
 
 // invalidateUserCacheByID invalidates cache entries by user ID
 func (c *CachedUserRepository) invalidateUserCacheByID(ctx context.Context, id string) {
-	// Invalidate by ID
-	if err := c.cache.Delete(ctx, fmt.Sprintf("user:id:%s", id)); err != nil {
+	// Invalidate by ID, together with derived caches and the list caches as they might be affected
+	if err := cache.Invalidate(ctx, c.cache, scopedKeys(ctx, "", []string{fmt.Sprintf("user:id:%s", id)}), UserCacheTag(id), UserListCacheTag); err != nil {
 		// Log error but don't fail the operation
 	}
-
-	// Invalidate list caches as they might be affected
-	c.invalidateUserListCaches(ctx)
 }
 
-// invalidateUserListCaches invalidates all user list caches
-func (c *CachedUserRepository) invalidateUserListCaches(ctx context.Context) {
-	// Get all keys matching user list patterns
-	patterns := []string{"users:all:*", "users:count"}
-
-	for _, pattern := range patterns {
-		keys, err := c.cache.Keys(ctx, pattern)
-		if err != nil {
-			// Log error but continue
-			continue
-		}
-
-		if len(keys) > 0 {
-			if err := c.cache.DeleteMultiple(ctx, keys); err != nil {
-				// Log error but continue
-			}
+// scopedKeys returns the keys under every scope a user's entries may have been cached in:
+// without a tenant (background jobs, single-tenant deployments), for the tenant in ctx
+// and for the user's own tenant
+func scopedKeys(ctx context.Context, tenantID string, keys []string) []string {
+	scopes := []string{""}
+	for _, scope := range []string{tenancy.ID(ctx), tenantID} {
+		if scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
 		}
 	}
-}
 
-// unmarshalUser attempts to unmarshal a cached value to a User model
-func (c *CachedUserRepository) unmarshalUser(value interface{}) (*models.User, bool) {
-	if value == nil {
-		return nil, false
-	}
-
-	// Try to unmarshal as JSON
-	switch v := value.(type) {
-	case []byte:
-		var user models.User
-		if err := json.Unmarshal(v, &user); err == nil {
-			return &user, true
-		}
-	case string:
-		var user models.User
-		if err := json.Unmarshal([]byte(v), &user); err == nil {
-			return &user, true
+	scoped := make([]string, 0, len(keys)*len(scopes))
+	for _, scope := range scopes {
+		for _, key := range keys {
+			scoped = append(scoped, tenancy.KeyFor(scope, key))
 		}
 	}
-
-	return nil, false
-}
-
-// unmarshalUserListResult attempts to unmarshal a cached value to a UserListResult
-func (c *CachedUserRepository) unmarshalUserListResult(value interface{}) (UserListResult, bool) {
-	var result UserListResult
-
-	if value == nil {
-		return result, false
-	}
-
-	// Try to unmarshal as JSON
-	switch v := value.(type) {
-	case []byte:
-		if err := json.Unmarshal(v, &result); err == nil {
-			return result, true
-		}
-	case string:
-		if err := json.Unmarshal([]byte(v), &result); err == nil {
-			return result, true
-		}
-	}
-
-	return result, false
+	return scoped
 }
 
 // UserListResult represents the result of GetAll operation for caching
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/pkg/cache"
+)
+
+// benchmarkRoundTrip is the simulated network latency of one call to the cache server
+const benchmarkRoundTrip = 200 * time.Microsecond
+
+// roundTripCache adds one simulated round trip to every invalidation call. Embedding the Cache
+// interface hides the batch Invalidate of the wrapped cache, so invalidation falls back to
+// DeleteMultiple followed by one InvalidateTag per tag
+type roundTripCache struct {
+	cache.Cache
+	roundTrips atomic.Int64
+}
+
+func (c *roundTripCache) roundTrip() {
+	c.roundTrips.Add(1)
+	time.Sleep(benchmarkRoundTrip)
+}
+
+func (c *roundTripCache) DeleteMultiple(ctx context.Context, keys []string) error {
+	c.roundTrip()
+	return c.Cache.DeleteMultiple(ctx, keys)
+}
+
+func (c *roundTripCache) InvalidateTag(ctx context.Context, tag string) error {
+	c.roundTrip()
+	return c.Cache.InvalidateTag(ctx, tag)
+}
+
+// pipelinedCache sends keys and tags in one pipelined round trip, like RedisCache.Invalidate
+type pipelinedCache struct {
+	roundTripCache
+}
+
+func (c *pipelinedCache) Invalidate(ctx context.Context, keys []string, tags ...string) error {
+	c.roundTrip()
+	return c.Cache.(cache.Invalidator).Invalidate(ctx, keys, tags...)
+}
+
+// BenchmarkCachedUserRepository_Update measures the write path of Update, whose invalidation
+// deletes the user's keys and the user and list tags, with and without pipelining
+func BenchmarkCachedUserRepository_Update(b *testing.B) {
+	sequential := &roundTripCache{Cache: cache.NewMemoryCache()}
+	pipelined := &pipelinedCache{roundTripCache{Cache: cache.NewMemoryCache()}}
+
+	for _, bc := range []struct {
+		name       string
+		cache      cache.Cache
+		roundTrips *atomic.Int64
+	}{
+		{"sequential", sequential, &sequential.roundTrips},
+		{"pipelined", pipelined, &pipelined.roundTrips},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ctx := context.Background()
+			repo := NewCachedUserRepository(NewMemoryUserRepository(), bc.cache)
+			user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+			if err := repo.Create(ctx, user); err != nil {
+				b.Fatalf("Failed to create user: %v", err)
+			}
+			bc.roundTrips.Store(0)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				// Cache the user and a list page so the invalidation has entries to remove
+				if _, err := repo.GetByID(ctx, user.ID); err != nil {
+					b.Fatalf("Failed to get user: %v", err)
+				}
+				if _, _, err := repo.GetAll(ctx, 0, 10); err != nil {
+					b.Fatalf("Failed to list users: %v", err)
+				}
+				if err := repo.Update(ctx, user); err != nil {
+					b.Fatalf("Failed to update user: %v", err)
+				}
+			}
+			b.ReportMetric(float64(bc.roundTrips.Load())/float64(b.N), "round-trips/op")
+		})
+	}
+}
@@ -2,7 +2,11 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +15,7 @@ import (
 	"go-server/internal/logger"
 	"go-server/internal/models"
 	"go-server/pkg/cache"
+	"go-server/pkg/testutil/factory"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -121,33 +126,19 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) SetupTest() {
 	}
 }
 
-// createTestUser creates a test user for testing purposes
-func (suite *CachedUserRepositoryIntegrationTestSuite) createTestUser(email, username string) *models.User {
-	user := &models.User{
-		Username:  username,
-		Email:     email,
-		FirstName: "Test",
-		LastName:  "User",
-		Password:  "hashedpassword",
-		IsActive:  true,
-		IsAdmin:   false,
-	}
-	return user
-}
-
 // TestCachedUserRepository_GetByID_CacheHitMiss tests cache hit and miss scenarios
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_GetByID_CacheHitMiss() {
+	ctx := context.Background()
+
 	// Create a test user
-	user := suite.createTestUser("cache@test.com", "cachetestuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("cache@test.com").WithUsername("cachetestuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 	require.NotEmpty(suite.T(), user.ID)
 
-	ctx := context.Background()
-
 	// First call should be a cache miss (database query)
 	start := time.Now()
-	userFromDB, err := suite.cachedRepo.GetByID(user.ID)
+	userFromDB, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), userFromDB)
 	dbCallDuration := time.Since(start)
@@ -165,7 +156,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 	// Second call should be a cache hit (no database query)
 	start = time.Now()
-	userFromCache, err := suite.cachedRepo.GetByID(user.ID)
+	userFromCache, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), userFromCache)
 	cacheCallDuration := time.Since(start)
@@ -182,14 +173,14 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_GetByEmail_CacheHitMiss tests email-based caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_GetByEmail_CacheHitMiss() {
-	user := suite.createTestUser("emailcache@test.com", "emailcacheuser")
-	err := suite.cachedRepo.Create(user)
-	require.NoError(suite.T(), err)
-
 	ctx := context.Background()
 
+	user := factory.User().WithEmail("emailcache@test.com").WithUsername("emailcacheuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
+	require.NoError(suite.T(), err)
+
 	// First call - cache miss
-	user1, err := suite.cachedRepo.GetByEmail(user.Email)
+	user1, err := suite.cachedRepo.GetByEmail(ctx, user.Email)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user1)
 
@@ -200,7 +191,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.NotNil(suite.T(), cachedValue)
 
 	// Second call - cache hit
-	user2, err := suite.cachedRepo.GetByEmail(user.Email)
+	user2, err := suite.cachedRepo.GetByEmail(ctx, user.Email)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user2)
 
@@ -211,14 +202,14 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_GetByUsername_CacheHitMiss tests username-based caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_GetByUsername_CacheHitMiss() {
-	user := suite.createTestUser("usercache@test.com", "usercacheusername")
-	err := suite.cachedRepo.Create(user)
-	require.NoError(suite.T(), err)
-
 	ctx := context.Background()
 
+	user := factory.User().WithEmail("usercache@test.com").WithUsername("usercacheusername").Build()
+	err := suite.cachedRepo.Create(ctx, user)
+	require.NoError(suite.T(), err)
+
 	// First call - cache miss
-	user1, err := suite.cachedRepo.GetByUsername(user.Username)
+	user1, err := suite.cachedRepo.GetByUsername(ctx, user.Username)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user1)
 
@@ -229,7 +220,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.NotNil(suite.T(), cachedValue)
 
 	// Second call - cache hit
-	user2, err := suite.cachedRepo.GetByUsername(user.Username)
+	user2, err := suite.cachedRepo.GetByUsername(ctx, user.Username)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user2)
 
@@ -240,26 +231,23 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_GetAll_PaginatedCaching tests paginated list caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_GetAll_PaginatedCaching() {
+	ctx := context.Background()
+
 	// Create multiple test users
 	users := make([]*models.User, 5)
 	for i := 0; i < 5; i++ {
-		user := suite.createTestUser(
-			fmt.Sprintf("listuser%d@test.com", i),
-			fmt.Sprintf("listuser%d", i),
-		)
-		err := suite.cachedRepo.Create(user)
+		user := factory.User().WithEmail(fmt.Sprintf("listuser%d@test.com", i)).WithUsername(fmt.Sprintf("listuser%d", i)).Build()
+		err := suite.cachedRepo.Create(ctx, user)
 		require.NoError(suite.T(), err)
 		users[i] = user
 	}
 
-	ctx := context.Background()
-
 	// Test first page
 	offset, limit := 0, 3
 	cacheKey := fmt.Sprintf("users:all:%d:%d", offset, limit)
 
 	// First call - cache miss
-	users1, total1, err := suite.cachedRepo.GetAll(offset, limit)
+	users1, total1, err := suite.cachedRepo.GetAll(ctx, offset, limit)
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), users1, 3)
 	assert.Equal(suite.T(), int64(5), total1)
@@ -270,7 +258,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.NotNil(suite.T(), cachedValue)
 
 	// Second call - cache hit
-	users2, total2, err := suite.cachedRepo.GetAll(offset, limit)
+	users2, total2, err := suite.cachedRepo.GetAll(ctx, offset, limit)
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), users2, 3)
 	assert.Equal(suite.T(), total1, total2)
@@ -286,7 +274,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	cacheKey2 := fmt.Sprintf("users:all:%d:%d", offset2, limit)
 
 	// First call for second page - cache miss
-	users3, total3, err := suite.cachedRepo.GetAll(offset2, limit)
+	users3, total3, err := suite.cachedRepo.GetAll(ctx, offset2, limit)
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), users3, 2)
 	assert.Equal(suite.T(), int64(5), total3)
@@ -299,15 +287,15 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_ExistsByEmail_Caching tests existence check caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_ExistsByEmail_Caching() {
+	ctx := context.Background()
+
 	email := "exists@test.com"
-	user := suite.createTestUser(email, "existsuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail(email).WithUsername("existsuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
-	ctx := context.Background()
-
 	// First call - cache miss
-	exists1, err := suite.cachedRepo.ExistsByEmail(email)
+	exists1, err := suite.cachedRepo.ExistsByEmail(ctx, email)
 	require.NoError(suite.T(), err)
 	assert.True(suite.T(), exists1)
 
@@ -318,13 +306,13 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Equal(suite.T(), true, cachedValue)
 
 	// Second call - cache hit
-	exists2, err := suite.cachedRepo.ExistsByEmail(email)
+	exists2, err := suite.cachedRepo.ExistsByEmail(ctx, email)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), exists1, exists2)
 
 	// Test non-existent email
 	nonExistentEmail := "nonexistent@test.com"
-	exists3, err := suite.cachedRepo.ExistsByEmail(nonExistentEmail)
+	exists3, err := suite.cachedRepo.ExistsByEmail(ctx, nonExistentEmail)
 	require.NoError(suite.T(), err)
 	assert.False(suite.T(), exists3)
 
@@ -337,15 +325,15 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_ExistsByUsername_Caching tests username existence caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_ExistsByUsername_Caching() {
+	ctx := context.Background()
+
 	username := "existsusername"
-	user := suite.createTestUser("exists2@test.com", username)
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("exists2@test.com").WithUsername(username).Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
-	ctx := context.Background()
-
 	// First call - cache miss
-	exists1, err := suite.cachedRepo.ExistsByUsername(username)
+	exists1, err := suite.cachedRepo.ExistsByUsername(ctx, username)
 	require.NoError(suite.T(), err)
 	assert.True(suite.T(), exists1)
 
@@ -356,27 +344,24 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Equal(suite.T(), true, cachedValue)
 
 	// Second call - cache hit
-	exists2, err := suite.cachedRepo.ExistsByUsername(username)
+	exists2, err := suite.cachedRepo.ExistsByUsername(ctx, username)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), exists1, exists2)
 }
 
 // TestCachedUserRepository_Count_Caching tests count caching
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_Count_Caching() {
+	ctx := context.Background()
+
 	// Create test users
 	for i := 0; i < 3; i++ {
-		user := suite.createTestUser(
-			fmt.Sprintf("countuser%d@test.com", i),
-			fmt.Sprintf("countuser%d", i),
-		)
-		err := suite.cachedRepo.Create(user)
+		user := factory.User().WithEmail(fmt.Sprintf("countuser%d@test.com", i)).WithUsername(fmt.Sprintf("countuser%d", i)).Build()
+		err := suite.cachedRepo.Create(ctx, user)
 		require.NoError(suite.T(), err)
 	}
 
-	ctx := context.Background()
-
 	// First call - cache miss
-	count1, err := suite.cachedRepo.Count()
+	count1, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(3), count1)
 
@@ -386,7 +371,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Equal(suite.T(), int64(3), cachedValue)
 
 	// Second call - cache hit
-	count2, err := suite.cachedRepo.Count()
+	count2, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), count1, count2)
 }
@@ -396,12 +381,12 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	ctx := context.Background()
 
 	// Create a user and cache the count
-	user1 := suite.createTestUser("invalidate1@test.com", "invalidateuser1")
-	err := suite.cachedRepo.Create(user1)
+	user1 := factory.User().WithEmail("invalidate1@test.com").WithUsername("invalidateuser1").Build()
+	err := suite.cachedRepo.Create(ctx, user1)
 	require.NoError(suite.T(), err)
 
 	// Get initial count and verify it's cached
-	count1, err := suite.cachedRepo.Count()
+	count1, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(1), count1)
 
@@ -409,8 +394,8 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.True(suite.T(), found)
 
 	// Create another user
-	user2 := suite.createTestUser("invalidate2@test.com", "invalidateuser2")
-	err = suite.cachedRepo.Create(user2)
+	user2 := factory.User().WithEmail("invalidate2@test.com").WithUsername("invalidateuser2").Build()
+	err = suite.cachedRepo.Create(ctx, user2)
 	require.NoError(suite.T(), err)
 
 	// Verify count cache was invalidated
@@ -427,7 +412,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.False(suite.T(), found, "Username existence cache should be invalidated")
 
 	// Get updated count
-	count2, err := suite.cachedRepo.Count()
+	count2, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(2), count2)
 }
@@ -436,12 +421,12 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_Update_CacheInvalidation() {
 	ctx := context.Background()
 
-	user := suite.createTestUser("update@test.com", "updateuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("update@test.com").WithUsername("updateuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Cache the user
-	user1, err := suite.cachedRepo.GetByID(user.ID)
+	user1, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user1)
 
@@ -460,7 +445,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	// Update user
 	user.FirstName = "Updated"
 	user.LastName = "Name"
-	err = suite.cachedRepo.Update(user)
+	err = suite.cachedRepo.Update(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Verify all user-related caches are invalidated
@@ -477,7 +462,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Empty(suite.T(), keys, "List caches should be invalidated after update")
 
 	// Get updated user (should be re-cached)
-	user2, err := suite.cachedRepo.GetByID(user.ID)
+	user2, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "Updated", user2.FirstName)
 	assert.Equal(suite.T(), "Name", user2.LastName)
@@ -491,14 +476,14 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_Delete_CacheInvalidation() {
 	ctx := context.Background()
 
-	user := suite.createTestUser("delete@test.com", "deleteuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("delete@test.com").WithUsername("deleteuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Cache the user and count
-	_, err = suite.cachedRepo.GetByID(user.ID)
+	_, err = suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
-	count1, err := suite.cachedRepo.Count()
+	count1, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 
 	// Verify caches exist
@@ -512,7 +497,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.True(suite.T(), found)
 
 	// Delete user
-	err = suite.cachedRepo.Delete(user.ID)
+	err = suite.cachedRepo.Delete(ctx, user.ID)
 	require.NoError(suite.T(), err)
 
 	// Verify all user-related caches are invalidated
@@ -526,12 +511,12 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.False(suite.T(), found, "Count cache should be invalidated after delete")
 
 	// Verify user is deleted
-	user2, err := suite.cachedRepo.GetByID(user.ID)
+	user2, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.Error(suite.T(), err)
 	require.Nil(suite.T(), user2)
 
 	// Verify count is updated
-	count2, err := suite.cachedRepo.Count()
+	count2, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), count1-1, count2)
 }
@@ -540,12 +525,12 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_UpdateLastLogin_CacheInvalidation() {
 	ctx := context.Background()
 
-	user := suite.createTestUser("login@test.com", "loginuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("login@test.com").WithUsername("loginuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Cache the user
-	user1, err := suite.cachedRepo.GetByID(user.ID)
+	user1, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	assert.Nil(suite.T(), user1.LastLogin)
 
@@ -555,7 +540,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.True(suite.T(), found)
 
 	// Update last login
-	err = suite.cachedRepo.UpdateLastLogin(user.ID)
+	err = suite.cachedRepo.UpdateLastLogin(ctx, user.ID)
 	require.NoError(suite.T(), err)
 
 	// Verify cache is invalidated
@@ -563,7 +548,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.False(suite.T(), found, "Cache should be invalidated after last login update")
 
 	// Get updated user
-	user2, err := suite.cachedRepo.GetByID(user.ID)
+	user2, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user2.LastLogin)
 
@@ -574,21 +559,18 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_TTL_Expiration tests TTL expiration behavior
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_TTL_Expiration() {
-	user := suite.createTestUser("ttl@test.com", "ttluser")
-	err := suite.cachedRepo.Create(user)
-	require.NoError(suite.T(), err)
-
 	ctx := context.Background()
 
+	user := factory.User().WithEmail("ttl@test.com").WithUsername("ttluser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
+	require.NoError(suite.T(), err)
+
 	// Create a cached repository with short TTL for testing
-	shortTTLRepo := &CachedUserRepository{
-		repo:  suite.baseRepo,
-		cache: suite.cache,
-		ttl:   100 * time.Millisecond, // Very short TTL
-	}
+	shortTTLRepo := NewCachedUserRepository(suite.baseRepo, suite.cache).(*CachedUserRepository)
+	shortTTLRepo.SetTTL(100 * time.Millisecond) // Very short TTL
 
 	// Cache the user
-	user1, err := shortTTLRepo.GetByID(user.ID)
+	user1, err := shortTTLRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user1)
 
@@ -606,7 +588,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.False(suite.T(), found, "Cache should have expired")
 
 	// Next call should be cache miss
-	user2, err := shortTTLRepo.GetByID(user.ID)
+	user2, err := shortTTLRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user2)
 
@@ -622,8 +604,10 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_ConcurrentAccess tests concurrent access to cached repository
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_ConcurrentAccess() {
-	user := suite.createTestUser("concurrent@test.com", "concurrentuser")
-	err := suite.cachedRepo.Create(user)
+	ctx := context.Background()
+
+	user := factory.User().WithEmail("concurrent@test.com").WithUsername("concurrentuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	const numGoroutines = 10
@@ -638,13 +622,13 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 				// Test different operations
 				switch j % 3 {
 				case 0:
-					_, err := suite.cachedRepo.GetByID(user.ID)
+					_, err := suite.cachedRepo.GetByID(ctx, user.ID)
 					assert.NoError(suite.T(), err)
 				case 1:
-					_, err := suite.cachedRepo.GetByEmail(user.Email)
+					_, err := suite.cachedRepo.GetByEmail(ctx, user.Email)
 					assert.NoError(suite.T(), err)
 				case 2:
-					_, err := suite.cachedRepo.GetByUsername(user.Username)
+					_, err := suite.cachedRepo.GetByUsername(ctx, user.Username)
 					assert.NoError(suite.T(), err)
 				}
 			}
@@ -658,7 +642,6 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	}
 
 	// Verify user is properly cached
-	ctx := context.Background()
 	cacheKey := fmt.Sprintf("user:id:%s", user.ID)
 	cachedValue, found := suite.cache.Get(ctx, cacheKey)
 	require.True(suite.T(), found)
@@ -667,12 +650,13 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 // TestCachedUserRepository_CacheFallbackBehavior tests fallback behavior when cache operations fail
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_CacheFallbackBehavior() {
-	user := suite.createTestUser("fallback@test.com", "fallbackuser")
-	err := suite.cachedRepo.Create(user)
+	ctx := context.Background()
+	user := factory.User().WithEmail("fallback@test.com").WithUsername("fallbackuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// First retrieval should work and cache the user
-	user1, err := suite.cachedRepo.GetByID(user.ID)
+	user1, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user1)
 
@@ -681,7 +665,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.NoError(suite.T(), err)
 
 	// Operations should still work by falling back to database
-	user2, err := suite.cachedRepo.GetByID(user.ID)
+	user2, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), user2)
 
@@ -690,11 +674,11 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Equal(suite.T(), user1.Email, user2.Email)
 
 	// Other operations should also work
-	exists, err := suite.cachedRepo.ExistsByEmail(user.Email)
+	exists, err := suite.cachedRepo.ExistsByEmail(ctx, user.Email)
 	require.NoError(suite.T(), err)
 	assert.True(suite.T(), exists)
 
-	count, err := suite.cachedRepo.Count()
+	count, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(1), count)
 }
@@ -706,13 +690,10 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	// Create multiple users
 	users := make([]*models.User, 3)
 	for i := 0; i < 3; i++ {
-		user := suite.createTestUser(
-			fmt.Sprintf("realuser%d@test.com", i),
-			fmt.Sprintf("realuser%d", i),
-		)
+		user := factory.User().WithEmail(fmt.Sprintf("realuser%d@test.com", i)).WithUsername(fmt.Sprintf("realuser%d", i)).Build()
 		user.FirstName = fmt.Sprintf("User%d", i)
 		user.LastName = "Test"
-		err := suite.cachedRepo.Create(user)
+		err := suite.cachedRepo.Create(ctx, user)
 		require.NoError(suite.T(), err)
 		users[i] = user
 	}
@@ -721,21 +702,21 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 	// 1. User login (cache miss)
 	start := time.Now()
-	loginUser, err := suite.cachedRepo.GetByEmail(users[0].Email)
+	loginUser, err := suite.cachedRepo.GetByEmail(ctx, users[0].Email)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), loginUser)
 	loginDuration := time.Since(start)
 
 	// 2. Subsequent user data access (cache hit)
 	start = time.Now()
-	userProfile, err := suite.cachedRepo.GetByID(loginUser.ID)
+	userProfile, err := suite.cachedRepo.GetByID(ctx, loginUser.ID)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), userProfile)
 	profileDuration := time.Since(start)
 
 	// 3. Admin user list (cache miss)
 	start = time.Now()
-	allUsers, total, err := suite.cachedRepo.GetAll(0, 10)
+	allUsers, total, err := suite.cachedRepo.GetAll(ctx, 0, 10)
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), allUsers, 3)
 	assert.Equal(suite.T(), int64(3), total)
@@ -743,14 +724,14 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 	// 4. Check user existence (cache miss)
 	start = time.Now()
-	exists, err := suite.cachedRepo.ExistsByEmail(users[1].Email)
+	exists, err := suite.cachedRepo.ExistsByEmail(ctx, users[1].Email)
 	require.NoError(suite.T(), err)
 	assert.True(suite.T(), exists)
 	_ = time.Since(start) // existsDuration unused but time measured for performance context
 
 	// 5. Another admin user list (cache hit)
 	start = time.Now()
-	allUsers2, total2, err := suite.cachedRepo.GetAll(0, 10)
+	allUsers2, total2, err := suite.cachedRepo.GetAll(ctx, 0, 10)
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), allUsers2, 3)
 	assert.Equal(suite.T(), int64(3), total2)
@@ -764,11 +745,11 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 	// 6. User update (should invalidate caches)
 	loginUser.FirstName = "UpdatedName"
-	err = suite.cachedRepo.Update(loginUser)
+	err = suite.cachedRepo.Update(ctx, loginUser)
 	require.NoError(suite.T(), err)
 
 	// 7. Get updated user (cache miss due to invalidation)
-	updatedUser, err := suite.cachedRepo.GetByID(loginUser.ID)
+	updatedUser, err := suite.cachedRepo.GetByID(ctx, loginUser.ID)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "UpdatedName", updatedUser.FirstName)
 
@@ -778,7 +759,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	assert.Empty(suite.T(), keys, "List caches should be invalidated after user update")
 
 	// 9. User count (should work correctly after updates)
-	count, err := suite.cachedRepo.Count()
+	count, err := suite.cachedRepo.Count(ctx)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(3), count)
 }
@@ -787,18 +768,18 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_CacheConsistency() {
 	ctx := context.Background()
 
-	user := suite.createTestUser("consistency@test.com", "consistencyuser")
-	err := suite.cachedRepo.Create(user)
+	user := factory.User().WithEmail("consistency@test.com").WithUsername("consistencyuser").Build()
+	err := suite.cachedRepo.Create(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Access user through different methods to populate various cache keys
-	userByID, err := suite.cachedRepo.GetByID(user.ID)
+	userByID, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 
-	userByEmail, err := suite.cachedRepo.GetByEmail(user.Email)
+	userByEmail, err := suite.cachedRepo.GetByEmail(ctx, user.Email)
 	require.NoError(suite.T(), err)
 
-	userByUsername, err := suite.cachedRepo.GetByUsername(user.Username)
+	userByUsername, err := suite.cachedRepo.GetByUsername(ctx, user.Username)
 	require.NoError(suite.T(), err)
 
 	// Verify all methods return the same data
@@ -822,7 +803,7 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 
 	// Update user
 	user.FirstName = "Consistency Test"
-	err = suite.cachedRepo.Update(user)
+	err = suite.cachedRepo.Update(ctx, user)
 	require.NoError(suite.T(), err)
 
 	// Verify all cache keys are invalidated
@@ -834,13 +815,13 @@ func (suite *CachedUserRepositoryIntegrationTestSuite) TestCachedUserRepository_
 	require.False(suite.T(), found)
 
 	// Re-access user through different methods
-	updatedUserByID, err := suite.cachedRepo.GetByID(user.ID)
+	updatedUserByID, err := suite.cachedRepo.GetByID(ctx, user.ID)
 	require.NoError(suite.T(), err)
 
-	updatedUserByEmail, err := suite.cachedRepo.GetByEmail(user.Email)
+	updatedUserByEmail, err := suite.cachedRepo.GetByEmail(ctx, user.Email)
 	require.NoError(suite.T(), err)
 
-	updatedUserByUsername, err := suite.cachedRepo.GetByUsername(user.Username)
+	updatedUserByUsername, err := suite.cachedRepo.GetByUsername(ctx, user.Username)
 	require.NoError(suite.T(), err)
 
 	// Verify consistency after update
@@ -858,6 +839,7 @@ func TestCachedUserRepository_Integration(t *testing.T) {
 
 // TestCachedUserRepository_Unit_Tests provides some unit-level tests for specific edge cases
 func TestCachedUserRepository_Unit_Tests(t *testing.T) {
+	ctx := context.Background()
 	t.Run("NewCachedUserRepository_Creation", func(t *testing.T) {
 		// Create a mock base repository
 		mockRepo := &MockUserRepository{}
@@ -880,8 +862,350 @@ func TestCachedUserRepository_Unit_Tests(t *testing.T) {
 		repo := cachedRepo.(*CachedUserRepository)
 
 		// Verify default TTL is 5 minutes
-		assert.Equal(t, 5*time.Minute, repo.ttl)
+		assert.Equal(t, 5*time.Minute, repo.TTL())
+	})
+
+	t.Run("CachedUserRepository_SetTTL", func(t *testing.T) {
+		repo := NewCachedUserRepository(&MockUserRepository{}, &MockCache{}).(*CachedUserRepository)
+
+		repo.SetTTL(30 * time.Second)
+		assert.Equal(t, 30*time.Second, repo.TTL())
+
+		// Non-positive TTL falls back to the default
+		repo.SetTTL(0)
+		assert.Equal(t, DefaultCacheTTL, repo.TTL())
+	})
+
+	t.Run("CachedUserRepository_CoalescesConcurrentMisses", func(t *testing.T) {
+		base := &slowUserRepository{
+			MockUserRepository: MockUserRepository{users: map[string]*models.User{
+				"user-1": {ID: "user-1", Email: "hot@example.com", Username: "hot"},
+			}},
+			release: make(chan struct{}),
+		}
+		repo := NewCachedUserRepository(base, &lockedCache{})
+
+		const callers = 20
+		var wg sync.WaitGroup
+		users := make([]*models.User, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				user, err := repo.GetByID(ctx, "user-1")
+				assert.NoError(t, err)
+				users[i] = user
+			}(i)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(base.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), base.calls.Load(), "concurrent misses should hit the database once")
+		for _, user := range users {
+			require.NotNil(t, user)
+			assert.Equal(t, "user-1", user.ID)
+		}
+
+		// Callers sharing a result get independent copies
+		users[0].FirstName = "Changed"
+		assert.Empty(t, users[1].FirstName)
+	})
+
+	t.Run("CachedUserRepository_InvalidatesListsByTag", func(t *testing.T) {
+		base := &MockUserRepository{users: map[string]*models.User{
+			"user-1": {ID: "user-1", Email: "one@example.com", Username: "one"},
+		}}
+		mockCache := &MockCache{}
+		repo := NewCachedUserRepository(base, mockCache)
+
+		_, _, err := repo.GetAll(ctx, 0, 10)
+		require.NoError(t, err)
+		_, err = repo.Count(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"users:all:0:10", "users:count"}, mockCache.tags[UserListCacheTag])
+
+		// Unrelated entries survive list invalidation
+		require.NoError(t, mockCache.Set(context.Background(), "other:key", "value", 0))
+
+		require.NoError(t, repo.Update(ctx, &models.User{ID: "user-1", Email: "one@example.com", Username: "one"}))
+		assert.NotContains(t, mockCache.data, "users:all:0:10")
+		assert.NotContains(t, mockCache.data, "users:count")
+		assert.Contains(t, mockCache.data, "other:key")
+		assert.Empty(t, mockCache.tags[UserListCacheTag])
+	})
+
+	t.Run("CachedUserRepository_NegativeCaching", func(t *testing.T) {
+		base := &countingUserRepository{}
+		mockCache := &MockCache{}
+		repo := NewCachedUserRepository(base, mockCache).(*CachedUserRepository)
+		assert.Equal(t, DefaultNegativeCacheTTL, repo.NegativeTTL())
+
+		_, err := repo.GetByEmail(ctx, "missing@example.com")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		// The second lookup is served from the negative cache
+		_, err = repo.GetByEmail(ctx, "missing@example.com")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.Equal(t, int32(1), base.calls.Load())
+
+		stats := repo.Metrics()
+		assert.Equal(t, uint64(1), stats.CacheHits)
+		assert.Equal(t, uint64(1), stats.NegativeHits)
+		assert.Equal(t, uint64(1), stats.CacheMisses)
+
+		// Creating the user clears the negative entry
+		user := &models.User{ID: "user-1", Email: "missing@example.com", Username: "found"}
+		require.NoError(t, repo.Create(ctx, user))
+		found, err := repo.GetByEmail(ctx, "missing@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", found.ID)
+	})
+
+	t.Run("CachedUserRepository_SetActiveInvalidatesCache", func(t *testing.T) {
+		base := &MockUserRepository{users: map[string]*models.User{
+			"user-1": {ID: "user-1", Email: "active@example.com", Username: "active", IsActive: true},
+		}}
+		repo := NewCachedUserRepository(base, &MockCache{})
+
+		cached, err := repo.GetByEmail(ctx, "active@example.com")
+		require.NoError(t, err)
+		assert.True(t, cached.IsActive)
+
+		_, err = repo.SetActive(ctx, "user-1", false)
+		require.NoError(t, err)
+
+		// The cached copy is gone, so the lookup sees the deactivated user
+		found, err := repo.GetByEmail(ctx, "active@example.com")
+		require.NoError(t, err)
+		assert.False(t, found.IsActive)
+	})
+
+	t.Run("CachedUserRepository_UpdateInvalidatesUserTag", func(t *testing.T) {
+		user := &models.User{ID: "user-1", Email: "tagged@example.com", Username: "tagged", IsActive: true}
+		base := &MockUserRepository{users: map[string]*models.User{"user-1": user}}
+		mockCache := &MockCache{}
+		repo := NewCachedUserRepository(base, mockCache)
+
+		// An entry derived from the user, e.g. a cached HTTP response
+		ctx := context.Background()
+		require.NoError(t, mockCache.SetWithTags(ctx, "response_cache:shared:abc", "body", time.Minute, UserCacheTag("user-1")))
+
+		require.NoError(t, repo.Update(ctx, user))
+
+		exists, err := mockCache.Exists(ctx, "response_cache:shared:abc")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("CachedUserRepository_NegativeCachingDisabled", func(t *testing.T) {
+		base := &countingUserRepository{}
+		repo := NewCachedUserRepository(base, &MockCache{}).(*CachedUserRepository)
+		repo.SetNegativeTTL(0)
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetByID(ctx, "missing")
+			assert.ErrorIs(t, err, ErrUserNotFound)
+		}
+		assert.Equal(t, int32(2), base.calls.Load())
+		assert.Equal(t, uint64(0), repo.Metrics().NegativeHits)
+	})
+
+	t.Run("CachedUserRepository_OtherErrorsNotCached", func(t *testing.T) {
+		base := &countingUserRepository{err: errors.New("connection refused")}
+		repo := NewCachedUserRepository(base, &MockCache{})
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetByUsername(ctx, "someone")
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrUserNotFound)
+		}
+		assert.Equal(t, int32(2), base.calls.Load())
+	})
+
+	t.Run("CachedUserRepository_MethodTTLs", func(t *testing.T) {
+		repo := NewCachedUserRepository(&MockUserRepository{}, &MockCache{}).(*CachedUserRepository)
+		repo.SetTTL(time.Minute)
+		repo.SetMethodTTLs(map[string]time.Duration{
+			CacheMethodGetAll: 10 * time.Second,
+			CacheMethodCount:  0,
+		})
+
+		assert.Equal(t, 10*time.Second, repo.MethodTTL(CacheMethodGetAll))
+		// Methods without a positive override use the default TTL
+		assert.Equal(t, time.Minute, repo.MethodTTL(CacheMethodCount))
+		assert.Equal(t, time.Minute, repo.MethodTTL(CacheMethodGetByID))
+	})
+
+	for _, codec := range []cache.Codec{cache.JSONCodec{}, cache.GobCodec{}, cache.MsgpackCodec{}} {
+		t.Run("CachedUserRepository_CacheHitKeepsTypes_"+codec.Name(), func(t *testing.T) {
+			lastLogin := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			base := &countingUserRepository{MockUserRepository: MockUserRepository{users: map[string]*models.User{
+				"user-1": {
+					ID:        "user-1",
+					Email:     "typed@example.com",
+					Username:  "typed",
+					Password:  "hashed-password",
+					AvatarKey: "avatars/user-1/a.png",
+					IsAdmin:   true,
+					LastLogin: &lastLogin,
+				},
+			}}}
+			repo := NewCachedUserRepository(base, &MockCache{}, WithCacheCodec(codec))
+			assert.Equal(t, codec.Name(), repo.(*CachedUserRepository).Codec().Name())
+
+			_, err := repo.GetByID(ctx, "user-1")
+			require.NoError(t, err)
+			_, _, err = repo.GetAll(ctx, 0, 10)
+			require.NoError(t, err)
+			_, err = repo.Count(ctx)
+			require.NoError(t, err)
+
+			// Serve everything from the cache from now on
+			base.users = nil
+
+			user, err := repo.GetByID(ctx, "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), base.calls.Load())
+			assert.Equal(t, "hashed-password", user.Password, "cached users keep the fields hidden from JSON responses")
+			assert.Equal(t, "avatars/user-1/a.png", user.AvatarKey)
+			assert.True(t, user.IsAdmin)
+			require.NotNil(t, user.LastLogin)
+			assert.True(t, lastLogin.Equal(*user.LastLogin))
+
+			users, total, err := repo.GetAll(ctx, 0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			require.Len(t, users, 1)
+			assert.Equal(t, "hashed-password", users[0].Password)
+
+			count, err := repo.Count(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+		})
+	}
+
+	t.Run("CheckCacheCodec", func(t *testing.T) {
+		tests := []struct {
+			codec   cache.Codec
+			wantErr bool
+		}{
+			{cache.JSONCodec{}, false},
+			{cache.GobCodec{}, false},
+			{cache.MsgpackCodec{}, false},
+			{cache.ProtobufCodec{}, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.codec.Name(), func(t *testing.T) {
+				err := CheckCacheCodec(tt.codec)
+				if tt.wantErr {
+					assert.ErrorIs(t, err, cache.ErrNotSupported)
+					return
+				}
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("CachedUserRepository_ReportsUnencodableEntries", func(t *testing.T) {
+		base := &countingUserRepository{MockUserRepository: MockUserRepository{users: map[string]*models.User{
+			"user-1": {ID: "user-1", Email: "proto@example.com", Username: "proto"},
+		}}}
+		var operations []string
+		repo := NewCachedUserRepository(base, &MockCache{},
+			WithCacheCodec(cache.ProtobufCodec{}),
+			WithCacheErrorHandler(func(operation string, err error) {
+				assert.ErrorIs(t, err, cache.ErrNotSupported)
+				operations = append(operations, operation)
+			}))
+
+		user, err := repo.GetByID(ctx, "user-1")
+		require.NoError(t, err, "cache failures don't fail the lookup")
+		assert.Equal(t, "proto", user.Username)
+		assert.Equal(t, []string{"序列化"}, operations)
 	})
+
+	t.Run("CachedUserRepository_IgnoresEntriesFromOlderReleases", func(t *testing.T) {
+		base := &countingUserRepository{MockUserRepository: MockUserRepository{users: map[string]*models.User{
+			"user-1": {ID: "user-1", Email: "old@example.com", Username: "old", Password: "hashed-password"},
+		}}}
+		mockCache := &MockCache{}
+		repo := NewCachedUserRepository(base, mockCache)
+
+		// Older releases cached the model itself, which drops the password hash
+		stale, err := json.Marshal(base.users["user-1"])
+		require.NoError(t, err)
+		require.NoError(t, mockCache.Set(context.Background(), "user:id:user-1", stale, 0))
+
+		user, err := repo.GetByID(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), base.calls.Load())
+		assert.Equal(t, "hashed-password", user.Password)
+	})
+}
+
+// slowUserRepository blocks GetByID until released and counts database queries
+type slowUserRepository struct {
+	MockUserRepository
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *slowUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.calls.Add(1)
+	<-r.release
+	return r.MockUserRepository.GetByID(ctx, id)
+}
+
+// countingUserRepository counts single-user lookups and returns ErrUserNotFound,
+// or err if set, for users that don't exist
+type countingUserRepository struct {
+	MockUserRepository
+	err   error
+	calls atomic.Int32
+}
+
+func (r *countingUserRepository) lookup(find func() (*models.User, error)) (*models.User, error) {
+	r.calls.Add(1)
+	if r.err != nil {
+		return nil, r.err
+	}
+	user, err := find()
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.lookup(func() (*models.User, error) { return r.MockUserRepository.GetByID(ctx, id) })
+}
+
+func (r *countingUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.lookup(func() (*models.User, error) { return r.MockUserRepository.GetByEmail(ctx, email) })
+}
+
+func (r *countingUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.lookup(func() (*models.User, error) { return r.MockUserRepository.GetByUsername(ctx, username) })
+}
+
+// lockedCache makes MockCache safe for the concurrent reads and writes used by GetByID
+type lockedCache struct {
+	MockCache
+	mu sync.Mutex
+}
+
+func (c *lockedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.MockCache.Get(ctx, key)
+}
+
+func (c *lockedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.MockCache.Set(ctx, key, value, ttl)
 }
 
 // MockUserRepository is a mock implementation for unit testing
@@ -889,7 +1213,7 @@ type MockUserRepository struct {
 	users map[string]*models.User
 }
 
-func (m *MockUserRepository) Create(user *models.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
 	if m.users == nil {
 		m.users = make(map[string]*models.User)
 	}
@@ -897,14 +1221,14 @@ func (m *MockUserRepository) Create(user *models.User) error {
 	return nil
 }
 
-func (m *MockUserRepository) GetByID(id string) (*models.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	if user, exists := m.users[id]; exists {
 		return user, nil
 	}
 	return nil, fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	for _, user := range m.users {
 		if user.Email == email {
 			return user, nil
@@ -913,7 +1237,7 @@ func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
 	return nil, fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) GetByUsername(username string) (*models.User, error) {
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	for _, user := range m.users {
 		if user.Username == username {
 			return user, nil
@@ -922,7 +1246,7 @@ func (m *MockUserRepository) GetByUsername(username string) (*models.User, error
 	return nil, fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) GetAll(offset, limit int) ([]*models.User, int64, error) {
+func (m *MockUserRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
 	users := make([]*models.User, 0, len(m.users))
 	for _, user := range m.users {
 		users = append(users, user)
@@ -930,7 +1254,7 @@ func (m *MockUserRepository) GetAll(offset, limit int) ([]*models.User, int64, e
 	return users, int64(len(users)), nil
 }
 
-func (m *MockUserRepository) Update(user *models.User) error {
+func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
 	if _, exists := m.users[user.ID]; exists {
 		m.users[user.ID] = user
 		return nil
@@ -938,7 +1262,7 @@ func (m *MockUserRepository) Update(user *models.User) error {
 	return fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) Delete(id string) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	if _, exists := m.users[id]; exists {
 		delete(m.users, id)
 		return nil
@@ -946,7 +1270,7 @@ func (m *MockUserRepository) Delete(id string) error {
 	return fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) UpdateLastLogin(id string) error {
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id string) error {
 	if user, exists := m.users[id]; exists {
 		now := time.Now()
 		user.LastLogin = &now
@@ -955,7 +1279,7 @@ func (m *MockUserRepository) UpdateLastLogin(id string) error {
 	return fmt.Errorf("user not found")
 }
 
-func (m *MockUserRepository) ExistsByEmail(email string) (bool, error) {
+func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	for _, user := range m.users {
 		if user.Email == email {
 			return true, nil
@@ -964,7 +1288,7 @@ func (m *MockUserRepository) ExistsByEmail(email string) (bool, error) {
 	return false, nil
 }
 
-func (m *MockUserRepository) ExistsByUsername(username string) (bool, error) {
+func (m *MockUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	for _, user := range m.users {
 		if user.Username == username {
 			return true, nil
@@ -973,13 +1297,44 @@ func (m *MockUserRepository) ExistsByUsername(username string) (bool, error) {
 	return false, nil
 }
 
-func (m *MockUserRepository) Count() (int64, error) {
+func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	return int64(len(m.users)), nil
 }
 
+func (m *MockUserRepository) Search(ctx context.Context, filter UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	var users []*models.User
+	for _, user := range m.users {
+		if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+			continue
+		}
+		if filter.IsAdmin != nil && user.IsAdmin != *filter.IsAdmin {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, int64(len(users)), nil
+}
+
+func (m *MockUserRepository) SetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	if user, exists := m.users[id]; exists {
+		user.IsActive = active
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (m *MockUserRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error) {
+	if user, exists := m.users[id]; exists {
+		user.IsAdmin = isAdmin
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
 // MockCache is a mock implementation for unit testing
 type MockCache struct {
 	data map[string]interface{}
+	tags map[string][]string
 }
 
 func (m *MockCache) Get(ctx context.Context, key string) (interface{}, bool) {
@@ -990,6 +1345,22 @@ func (m *MockCache) Get(ctx context.Context, key string) (interface{}, bool) {
 	return value, exists
 }
 
+func (m *MockCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	value, exists := m.Get(ctx, key)
+	if !exists {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		data, err := json.Marshal(v)
+		return data, err == nil
+	}
+}
+
 func (m *MockCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
 	value, exists := m.Get(ctx, key)
 	return value, 0, exists
@@ -1029,6 +1400,24 @@ func (m *MockCache) DeleteMultiple(ctx context.Context, keys []string) error {
 	return nil
 }
 
+func (m *MockCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if m.tags == nil {
+		m.tags = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		m.tags[tag] = append(m.tags[tag], key)
+	}
+	return m.Set(ctx, key, value, ttl)
+}
+
+func (m *MockCache) InvalidateTag(ctx context.Context, tag string) error {
+	if err := m.DeleteMultiple(ctx, m.tags[tag]); err != nil {
+		return err
+	}
+	delete(m.tags, tag)
+	return nil
+}
+
 func (m *MockCache) Exists(ctx context.Context, key string) (bool, error) {
 	_, exists := m.Get(ctx, key)
 	return exists, nil
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrExportNotFound is returned when an export does not exist
+var ErrExportNotFound = errors.New("export not found")
+
+// ExportRepository defines the interface for background data export jobs.
+// Exports carry a tenant_id column, so reads and writes are scoped to the caller's tenant by the tenancy plugin.
+type ExportRepository interface {
+	// Create stores a new export job, assigning its ID and timestamps
+	Create(ctx context.Context, export *models.Export) error
+	// GetByID returns an export by ID
+	GetByID(ctx context.Context, id string) (*models.Export, error)
+	// UpdateProgress records the total and processed row counts of a running export
+	UpdateProgress(ctx context.Context, id string, total, processed int64) error
+	// Finish records the final state of an export: its status, file, error and expiry
+	Finish(ctx context.Context, export *models.Export) error
+	// ListExpired returns up to limit exports whose expiry time is before at
+	ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Export, error)
+	// Delete removes an export
+	Delete(ctx context.Context, id string) error
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository creates a new export repository
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// Create inserts an export job
+func (r *exportRepository) Create(ctx context.Context, export *models.Export) error {
+	if err := r.db.WithContext(ctx).Create(export).Error; err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+// GetByID finds an export by primary key
+func (r *exportRepository) GetByID(ctx context.Context, id string) (*models.Export, error) {
+	var export models.Export
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	return &export, nil
+}
+
+// UpdateProgress marks an export as running and stores its row counts
+func (r *exportRepository) UpdateProgress(ctx context.Context, id string, total, processed int64) error {
+	return r.update(ctx, id, map[string]interface{}{
+		"status":         models.ExportStatusRunning,
+		"total_rows":     total,
+		"processed_rows": processed,
+	})
+}
+
+// Finish overwrites the result columns of an export
+func (r *exportRepository) Finish(ctx context.Context, export *models.Export) error {
+	return r.update(ctx, export.ID, map[string]interface{}{
+		"status":         export.Status,
+		"total_rows":     export.TotalRows,
+		"processed_rows": export.ProcessedRows,
+		"size":           export.Size,
+		"object_key":     export.ObjectKey,
+		"error":          export.Error,
+		"completed_at":   export.CompletedAt,
+		"expires_at":     export.ExpiresAt,
+	})
+}
+
+// update writes columns explicitly, since Updates with a struct skips zero values
+func (r *exportRepository) update(ctx context.Context, id string, columns map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&models.Export{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update export: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExportNotFound
+	}
+	return nil
+}
+
+// ListExpired returns exports past their expiry time, oldest first, using idx_exports_expires_at
+func (r *exportRepository) ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Export, error) {
+	var exports []*models.Export
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", at).
+		Order("expires_at").
+		Limit(limit).
+		Find(&exports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired exports: %w", err)
+	}
+	return exports, nil
+}
+
+// Delete removes an export by primary key
+func (r *exportRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Export{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete export: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExportNotFound
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LastLoginStore buffers last login times so logins don't each issue an UPDATE;
+// a flusher takes the buffered times in batches and writes them to the database
+type LastLoginStore interface {
+	// Record buffers a login, keeping the later time when the user already has a buffered login
+	Record(ctx context.Context, userID string, at time.Time) error
+
+	// Get returns the buffered login of a user, found is false when none is buffered
+	Get(ctx context.Context, userID string) (at time.Time, found bool, err error)
+
+	// Take removes and returns up to limit buffered logins
+	Take(ctx context.Context, limit int) (map[string]time.Time, error)
+
+	// Pending returns the number of buffered logins
+	Pending(ctx context.Context) (int64, error)
+}
+
+// LastLoginWriter is implemented by user repositories that can write buffered last login times in batches
+type LastLoginWriter interface {
+	// UpdateLastLogins sets the last login of each user, skipping users whose stored last login is later.
+	// Unknown users are ignored
+	UpdateLastLogins(ctx context.Context, logins map[string]time.Time) error
+}
+
+// MemoryLastLoginStore buffers logins in process memory; buffered logins are visible to this instance only
+// and are lost if the process exits without flushing
+type MemoryLastLoginStore struct {
+	mu     sync.Mutex
+	logins map[string]time.Time
+}
+
+// NewMemoryLastLoginStore creates an empty in-memory last login store
+func NewMemoryLastLoginStore() *MemoryLastLoginStore {
+	return &MemoryLastLoginStore{logins: make(map[string]time.Time)}
+}
+
+// Record buffers a login, keeping the later time
+func (s *MemoryLastLoginStore) Record(ctx context.Context, userID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.logins[userID]; !ok || at.After(current) {
+		s.logins[userID] = at
+	}
+	return nil
+}
+
+// Get returns the buffered login of a user
+func (s *MemoryLastLoginStore) Get(ctx context.Context, userID string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.logins[userID]
+	return at, ok, nil
+}
+
+// Take removes and returns up to limit buffered logins
+func (s *MemoryLastLoginStore) Take(ctx context.Context, limit int) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taken := make(map[string]time.Time, min(limit, len(s.logins)))
+	for userID, at := range s.logins {
+		if len(taken) >= limit {
+			break
+		}
+		taken[userID] = at
+		delete(s.logins, userID)
+	}
+	return taken, nil
+}
+
+// Pending returns the number of buffered logins
+func (s *MemoryLastLoginStore) Pending(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.logins)), nil
+}
+
+// recordLastLoginScript stores the login time unless a later one is already buffered
+// KEYS[1] is the hash of buffered logins; ARGV[1] is the user ID, ARGV[2] the login time in Unix microseconds
+var recordLastLoginScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current or tonumber(current) < tonumber(ARGV[2]) then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return 1
+`)
+
+// takeLastLoginsScript atomically removes and returns up to ARGV[1] buffered logins as a flat list
+// of user IDs and times, so a login recorded while a batch is being taken is never lost.
+// A single HSCAN call may return fewer entries than COUNT, so it keeps scanning until the batch
+// is full or the cursor returns to 0
+var takeLastLoginsScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local cursor = '0'
+local taken = {}
+repeat
+	local scanned = redis.call('HSCAN', KEYS[1], cursor, 'COUNT', limit)
+	cursor = scanned[1]
+	local entries = scanned[2]
+	for i = 1, #entries, 2 do
+		if #taken >= limit * 2 then
+			break
+		end
+		redis.call('HDEL', KEYS[1], entries[i])
+		taken[#taken + 1] = entries[i]
+		taken[#taken + 1] = entries[i + 1]
+	end
+until cursor == '0' or #taken >= limit * 2
+return taken
+`)
+
+// RedisLastLoginStore buffers logins in a Redis hash shared by all instances, keyed <prefix>:last_login,
+// with user IDs as fields and login times in Unix microseconds as values
+type RedisLastLoginStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisLastLoginStore creates a Redis last login store; prefix is the key prefix, such as users
+func NewRedisLastLoginStore(client *redis.Client, prefix string) *RedisLastLoginStore {
+	return &RedisLastLoginStore{client: client, key: prefix + ":last_login"}
+}
+
+// Record buffers a login, keeping the later time
+func (s *RedisLastLoginStore) Record(ctx context.Context, userID string, at time.Time) error {
+	if err := recordLastLoginScript.Run(ctx, s.client, []string{s.key}, userID, at.UnixMicro()).Err(); err != nil {
+		return fmt.Errorf("failed to record last login of %s: %w", userID, err)
+	}
+	return nil
+}
+
+// Get returns the buffered login of a user
+func (s *RedisLastLoginStore) Get(ctx context.Context, userID string) (time.Time, bool, error) {
+	value, err := s.client.HGet(ctx, s.key, userID).Int64()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get last login of %s: %w", userID, err)
+	}
+	return time.UnixMicro(value).UTC(), true, nil
+}
+
+// Take removes and returns up to limit buffered logins
+func (s *RedisLastLoginStore) Take(ctx context.Context, limit int) (map[string]time.Time, error) {
+	entries, err := takeLastLoginsScript.Run(ctx, s.client, []string{s.key}, limit).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take last logins: %w", err)
+	}
+
+	taken := make(map[string]time.Time, len(entries)/2)
+	for i := 0; i+1 < len(entries); i += 2 {
+		micros, err := strconv.ParseInt(entries[i+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		taken[entries[i]] = time.UnixMicro(micros).UTC()
+	}
+	return taken, nil
+}
+
+// Pending returns the number of buffered logins
+func (s *RedisLastLoginStore) Pending(ctx context.Context) (int64, error) {
+	pending, err := s.client.HLen(ctx, s.key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count last logins: %w", err)
+	}
+	return pending, nil
+}
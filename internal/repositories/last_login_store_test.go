@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLastLoginStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryLastLoginStore()
+	loginAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, store.Record(ctx, "user-1", loginAt))
+	require.NoError(t, store.Record(ctx, "user-1", loginAt.Add(-time.Minute)))
+	require.NoError(t, store.Record(ctx, "user-2", loginAt))
+	require.NoError(t, store.Record(ctx, "user-3", loginAt))
+
+	at, found, err := store.Get(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, at.Equal(loginAt), "an earlier login should not replace a buffered one")
+
+	_, found, err = store.Get(ctx, "unknown")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	taken, err := store.Take(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, taken, 2)
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	taken, err = store.Take(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, taken, 1)
+}
+
+func TestMemoryUserRepository_UpdateLastLogins(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.UpdateLastLogin(ctx, user.ID))
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	current := *stored.LastLogin
+
+	// Earlier buffered logins and unknown users are ignored
+	require.NoError(t, repo.UpdateLastLogins(ctx, map[string]time.Time{
+		user.ID:   current.Add(-time.Hour),
+		"unknown": current,
+	}))
+	stored, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.LastLogin.Equal(current))
+
+	require.NoError(t, repo.UpdateLastLogins(ctx, map[string]time.Time{user.ID: current.Add(time.Hour)}))
+	stored, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.LastLogin.Equal(current.Add(time.Hour)))
+}
+
+func TestCachedUserRepository_BufferedLastLogin(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryUserRepository()
+	store := NewMemoryLastLoginStore()
+	repo := NewCachedUserRepository(base, cache.NewMemoryCache(), WithLastLoginStore(store))
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	// Cache the user before the login
+	cached, err := repo.GetByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Nil(t, cached.LastLogin)
+
+	require.NoError(t, repo.UpdateLastLogin(ctx, user.ID))
+
+	stored, err := base.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.LastLogin, "the login should only be buffered")
+
+	buffered, found, err := store.Get(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	// The invalidated entry is reloaded with the buffered login
+	cached, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, cached.LastLogin)
+	assert.True(t, cached.LastLogin.Equal(buffered))
+}
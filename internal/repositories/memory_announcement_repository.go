@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryAnnouncementRepository is an in-process AnnouncementRepository for tests and local tooling.
+// Announcements are copied on the way in and out so callers cannot mutate stored state.
+type MemoryAnnouncementRepository struct {
+	mu            sync.RWMutex
+	announcements []*models.Announcement
+	now           func() time.Time
+}
+
+// NewMemoryAnnouncementRepository creates an empty in-memory announcement repository
+func NewMemoryAnnouncementRepository() *MemoryAnnouncementRepository {
+	return &MemoryAnnouncementRepository{now: time.Now}
+}
+
+// Create stores an announcement, assigning an ID and timestamps like the database defaults would
+func (r *MemoryAnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if announcement.ID == "" {
+		announcement.ID = uuid.NewString()
+	}
+	now := r.now()
+	if announcement.CreatedAt.IsZero() {
+		announcement.CreatedAt = now
+	}
+	announcement.UpdatedAt = now
+	r.announcements = append(r.announcements, copyAnnouncement(announcement))
+	return nil
+}
+
+// GetByID returns a copy of an announcement
+func (r *MemoryAnnouncementRepository) GetByID(ctx context.Context, id string) (*models.Announcement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.index(id); i >= 0 {
+		return copyAnnouncement(r.announcements[i]), nil
+	}
+	return nil, ErrAnnouncementNotFound
+}
+
+// List returns a page of announcements, newest first
+func (r *MemoryAnnouncementRepository) List(ctx context.Context, offset, limit int) ([]*models.Announcement, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := r.sorted(func(*models.Announcement) bool { return true })
+	total := int64(len(all))
+	if offset >= len(all) {
+		return []*models.Announcement{}, total, nil
+	}
+	end := min(offset+limit, len(all))
+	return all[offset:end], total, nil
+}
+
+// ListUnexpired returns announcements without an end time or ending after at, newest first
+func (r *MemoryAnnouncementRepository) ListUnexpired(ctx context.Context, at time.Time) ([]*models.Announcement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(func(announcement *models.Announcement) bool {
+		return announcement.EndsAt == nil || announcement.EndsAt.After(at)
+	}), nil
+}
+
+// Update replaces an announcement, keeping its creator and creation time
+func (r *MemoryAnnouncementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(announcement.ID)
+	if i < 0 {
+		return ErrAnnouncementNotFound
+	}
+	stored := copyAnnouncement(announcement)
+	stored.CreatedBy = r.announcements[i].CreatedBy
+	stored.CreatedAt = r.announcements[i].CreatedAt
+	stored.UpdatedAt = r.now()
+	r.announcements[i] = stored
+	return nil
+}
+
+// Delete removes an announcement
+func (r *MemoryAnnouncementRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return ErrAnnouncementNotFound
+	}
+	r.announcements = slices.Delete(r.announcements, i, i+1)
+	return nil
+}
+
+// index returns the position of an announcement, or -1; callers hold the lock
+func (r *MemoryAnnouncementRepository) index(id string) int {
+	return slices.IndexFunc(r.announcements, func(announcement *models.Announcement) bool {
+		return announcement.ID == id
+	})
+}
+
+// sorted returns copies of the matching announcements, newest first; callers hold the lock
+func (r *MemoryAnnouncementRepository) sorted(match func(*models.Announcement) bool) []*models.Announcement {
+	var matched []*models.Announcement
+	for _, announcement := range r.announcements {
+		if match(announcement) {
+			matched = append(matched, copyAnnouncement(announcement))
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	return matched
+}
+
+// copyAnnouncement copies an announcement including its role list
+func copyAnnouncement(announcement *models.Announcement) *models.Announcement {
+	copied := *announcement
+	copied.Roles = slices.Clone(announcement.Roles)
+	return &copied
+}
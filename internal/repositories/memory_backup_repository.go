@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryBackupRepository is an in-process BackupRepository for tests and local tooling.
+// Backups are copied on the way in and out so callers cannot mutate stored state.
+type MemoryBackupRepository struct {
+	mu      sync.RWMutex
+	backups map[string]*models.Backup
+	now     func() time.Time
+}
+
+// NewMemoryBackupRepository creates an empty in-memory backup repository
+func NewMemoryBackupRepository() *MemoryBackupRepository {
+	return &MemoryBackupRepository{
+		backups: make(map[string]*models.Backup),
+		now:     time.Now,
+	}
+}
+
+// Create stores a backup, assigning an ID and timestamps like the database defaults would
+func (r *MemoryBackupRepository) Create(ctx context.Context, backup *models.Backup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.create(backup)
+	return nil
+}
+
+// create stores a backup under the write lock
+func (r *MemoryBackupRepository) create(backup *models.Backup) {
+	if backup.ID == "" {
+		backup.ID = uuid.NewString()
+	}
+	if backup.Status == "" {
+		backup.Status = models.BackupStatusPending
+	}
+	now := r.now()
+	if backup.CreatedAt.IsZero() {
+		backup.CreatedAt = now
+	}
+	backup.UpdatedAt = now
+	stored := *backup
+	r.backups[backup.ID] = &stored
+}
+
+// CreateScheduled stores a scheduled backup unless another scheduled backup was created after since
+func (r *MemoryBackupRepository) CreateScheduled(ctx context.Context, backup *models.Backup, since time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.backups {
+		if existing.Trigger == models.BackupTriggerScheduled && existing.CreatedAt.After(since) {
+			return false, nil
+		}
+	}
+	backup.Trigger = models.BackupTriggerScheduled
+	backup.Status = models.BackupStatusPending
+	r.create(backup)
+	return true, nil
+}
+
+// GetByID returns a copy of a backup
+func (r *MemoryBackupRepository) GetByID(ctx context.Context, id string) (*models.Backup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	backup, ok := r.backups[id]
+	if !ok {
+		return nil, ErrBackupNotFound
+	}
+	found := *backup
+	return &found, nil
+}
+
+// List returns a page of backups, newest first
+func (r *MemoryBackupRepository) List(ctx context.Context, offset, limit int) ([]*models.Backup, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	backups := make([]*models.Backup, 0, len(r.backups))
+	for _, backup := range r.backups {
+		found := *backup
+		backups = append(backups, &found)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	total := int64(len(backups))
+	if offset >= len(backups) {
+		return []*models.Backup{}, total, nil
+	}
+	backups = backups[offset:]
+	if limit > 0 && limit < len(backups) {
+		backups = backups[:limit]
+	}
+	return backups, total, nil
+}
+
+// MarkRunning moves a backup to the running state
+func (r *MemoryBackupRepository) MarkRunning(ctx context.Context, id string) error {
+	return r.update(id, func(backup *models.Backup) {
+		backup.Status = models.BackupStatusRunning
+	})
+}
+
+// Finish overwrites the result fields of a backup
+func (r *MemoryBackupRepository) Finish(ctx context.Context, backup *models.Backup) error {
+	return r.update(backup.ID, func(stored *models.Backup) {
+		stored.Status = backup.Status
+		stored.Size, stored.Checksum = backup.Size, backup.Checksum
+		stored.ObjectKey, stored.Error = backup.ObjectKey, backup.Error
+		stored.CompletedAt, stored.ExpiresAt = backup.CompletedAt, backup.ExpiresAt
+	})
+}
+
+// update applies fn to a stored backup under the write lock
+func (r *MemoryBackupRepository) update(id string, fn func(backup *models.Backup)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backup, ok := r.backups[id]
+	if !ok {
+		return ErrBackupNotFound
+	}
+	fn(backup)
+	backup.UpdatedAt = r.now()
+	return nil
+}
+
+// ListExpired returns up to limit backups past their expiry time, oldest first
+func (r *MemoryBackupRepository) ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Backup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var expired []*models.Backup
+	for _, backup := range r.backups {
+		if backup.ExpiresAt != nil && backup.ExpiresAt.Before(at) {
+			found := *backup
+			expired = append(expired, &found)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt)
+	})
+	if limit > 0 && limit < len(expired) {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+// Delete removes a backup
+func (r *MemoryBackupRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.backups[id]; !ok {
+		return ErrBackupNotFound
+	}
+	delete(r.backups, id)
+	return nil
+}
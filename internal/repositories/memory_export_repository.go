@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryExportRepository is an in-process ExportRepository for tests and local tooling.
+// Exports are copied on the way in and out so callers cannot mutate stored state.
+type MemoryExportRepository struct {
+	mu      sync.RWMutex
+	exports map[string]*models.Export
+	now     func() time.Time
+}
+
+// NewMemoryExportRepository creates an empty in-memory export repository
+func NewMemoryExportRepository() *MemoryExportRepository {
+	return &MemoryExportRepository{
+		exports: make(map[string]*models.Export),
+		now:     time.Now,
+	}
+}
+
+// Create stores an export, assigning an ID and timestamps like the database defaults would
+func (r *MemoryExportRepository) Create(ctx context.Context, export *models.Export) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if export.ID == "" {
+		export.ID = uuid.NewString()
+	}
+	if export.Status == "" {
+		export.Status = models.ExportStatusPending
+	}
+	now := r.now()
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = now
+	}
+	export.UpdatedAt = now
+	stored := *export
+	r.exports[export.ID] = &stored
+	return nil
+}
+
+// GetByID returns a copy of an export
+func (r *MemoryExportRepository) GetByID(ctx context.Context, id string) (*models.Export, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	export, ok := r.exports[id]
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+	found := *export
+	return &found, nil
+}
+
+// UpdateProgress marks an export as running and stores its row counts
+func (r *MemoryExportRepository) UpdateProgress(ctx context.Context, id string, total, processed int64) error {
+	return r.update(id, func(export *models.Export) {
+		export.Status = models.ExportStatusRunning
+		export.TotalRows, export.ProcessedRows = total, processed
+	})
+}
+
+// Finish overwrites the result fields of an export
+func (r *MemoryExportRepository) Finish(ctx context.Context, export *models.Export) error {
+	return r.update(export.ID, func(stored *models.Export) {
+		stored.Status = export.Status
+		stored.TotalRows, stored.ProcessedRows = export.TotalRows, export.ProcessedRows
+		stored.Size, stored.ObjectKey, stored.Error = export.Size, export.ObjectKey, export.Error
+		stored.CompletedAt, stored.ExpiresAt = export.CompletedAt, export.ExpiresAt
+	})
+}
+
+// update applies fn to a stored export under the write lock
+func (r *MemoryExportRepository) update(id string, fn func(export *models.Export)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.exports[id]
+	if !ok {
+		return ErrExportNotFound
+	}
+	fn(export)
+	export.UpdatedAt = r.now()
+	return nil
+}
+
+// ListExpired returns up to limit exports past their expiry time, oldest first
+func (r *MemoryExportRepository) ListExpired(ctx context.Context, at time.Time, limit int) ([]*models.Export, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var expired []*models.Export
+	for _, export := range r.exports {
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(at) {
+			found := *export
+			expired = append(expired, &found)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt)
+	})
+	if limit > 0 && limit < len(expired) {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+// Delete removes an export
+func (r *MemoryExportRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.exports[id]; !ok {
+		return ErrExportNotFound
+	}
+	delete(r.exports, id)
+	return nil
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryNotificationRepository is an in-process NotificationRepository for tests and local tooling.
+// Notifications and preferences are copied on the way in and out so callers cannot mutate stored state.
+type MemoryNotificationRepository struct {
+	mu            sync.RWMutex
+	notifications []*models.Notification
+	preferences   map[preferenceKey]*models.NotificationPreference
+	now           func() time.Time
+}
+
+// preferenceKey mirrors the (user_id, type, channel) primary key of notification_preferences
+type preferenceKey struct {
+	userID, notificationType, channel string
+}
+
+// NewMemoryNotificationRepository creates an empty in-memory notification repository
+func NewMemoryNotificationRepository() *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{
+		preferences: make(map[preferenceKey]*models.NotificationPreference),
+		now:         time.Now,
+	}
+}
+
+// Create stores a notification, assigning an ID and creation time like the database defaults would
+func (r *MemoryNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if notification.ID == "" {
+		notification.ID = uuid.NewString()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = r.now()
+	}
+	stored := *notification
+	r.notifications = append(r.notifications, &stored)
+	return nil
+}
+
+// List returns a page of a user's notifications, newest first
+func (r *MemoryNotificationRepository) List(ctx context.Context, userID string, unreadOnly bool, offset, limit int) ([]*models.Notification, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*models.Notification
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && (!unreadOnly || notification.ReadAt == nil) {
+			found := *notification
+			matched = append(matched, &found)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []*models.Notification{}, total, nil
+	}
+	end := min(offset+limit, len(matched))
+	return matched[offset:end], total, nil
+}
+
+// CountUnread counts a user's notifications without a read time
+func (r *MemoryNotificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && notification.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead sets the read time of an unread notification
+func (r *MemoryNotificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notification := range r.notifications {
+		if notification.ID == id && notification.UserID == userID {
+			if notification.ReadAt == nil {
+				readAt := at
+				notification.ReadAt = &readAt
+			}
+			return nil
+		}
+	}
+	return ErrNotificationNotFound
+}
+
+// MarkAllRead sets the read time of all unread notifications of a user
+func (r *MemoryNotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var marked int64
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && notification.ReadAt == nil {
+			readAt := at
+			notification.ReadAt = &readAt
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+// ListPreferences returns a user's stored preferences
+func (r *MemoryNotificationRepository) ListPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var preferences []*models.NotificationPreference
+	for key, preference := range r.preferences {
+		if key.userID == userID {
+			found := *preference
+			preferences = append(preferences, &found)
+		}
+	}
+	return preferences, nil
+}
+
+// SavePreferences upserts preferences on (user_id, type, channel)
+func (r *MemoryNotificationRepository) SavePreferences(ctx context.Context, preferences []*models.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, preference := range preferences {
+		stored := *preference
+		stored.UpdatedAt = now
+		r.preferences[preferenceKey{preference.UserID, preference.Type, preference.Channel}] = &stored
+	}
+	return nil
+}
+
+// DeleteUserData removes all notifications and preferences of a user
+func (r *MemoryNotificationRepository) DeleteUserData(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.notifications[:0]
+	for _, notification := range r.notifications {
+		if notification.UserID != userID {
+			kept = append(kept, notification)
+		}
+	}
+	deleted := int64(len(r.notifications) - len(kept))
+	clear(r.notifications[len(kept):])
+	r.notifications = kept
+
+	for key := range r.preferences {
+		if key.userID == userID {
+			delete(r.preferences, key)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryNotificationRepository checks that the in-memory repository follows the GORM repository's semantics
+func TestMemoryNotificationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryNotificationRepository()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	first := &models.Notification{UserID: "alice", Type: "account.welcome", Subject: "Welcome"}
+	second := &models.Notification{UserID: "alice", Type: "account.password_changed", Subject: "Password changed"}
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Create(ctx, second))
+	require.NoError(t, repo.Create(ctx, &models.Notification{UserID: "bob", Type: "account.welcome"}))
+	assert.NotEmpty(t, first.ID)
+
+	notifications, total, err := repo.List(ctx, "alice", false, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, second.ID, notifications[0].ID, "newest notifications come first")
+
+	// Marking is scoped to the owner and keeps the first read time
+	assert.ErrorIs(t, repo.MarkRead(ctx, "bob", first.ID, now), ErrNotificationNotFound)
+	readAt := now
+	require.NoError(t, repo.MarkRead(ctx, "alice", first.ID, readAt))
+	require.NoError(t, repo.MarkRead(ctx, "alice", first.ID, readAt.Add(time.Hour)))
+	notifications, total, err = repo.List(ctx, "alice", false, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.NotNil(t, notifications[0].ReadAt)
+	assert.Equal(t, readAt, *notifications[0].ReadAt)
+
+	unread, total, err := repo.List(ctx, "alice", true, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, second.ID, unread[0].ID)
+
+	marked, err := repo.MarkAllRead(ctx, "alice", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+	count, err := repo.CountUnread(ctx, "alice")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = repo.CountUnread(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Preferences are upserted on (user, type, channel)
+	require.NoError(t, repo.SavePreferences(ctx, []*models.NotificationPreference{
+		{UserID: "alice", Type: "account.welcome", Channel: "email", Enabled: false},
+	}))
+	require.NoError(t, repo.SavePreferences(ctx, []*models.NotificationPreference{
+		{UserID: "alice", Type: "account.welcome", Channel: "email", Enabled: true},
+	}))
+	preferences, err := repo.ListPreferences(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+	assert.True(t, preferences[0].Enabled)
+	preferences, err = repo.ListPreferences(ctx, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, preferences)
+
+	// Deleting a user's data leaves other users' notifications alone
+	deleted, err := repo.DeleteUserData(ctx, "alice")
+	require.NoError(t, err)
+	assert.Positive(t, deleted)
+	_, total, err = repo.List(ctx, "alice", false, 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	preferences, err = repo.ListPreferences(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, preferences)
+	count, err = repo.CountUnread(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryOAuthClientRepository is an in-process OAuthClientRepository for tests and local tooling.
+// Clients are copied on the way in and out so callers cannot mutate stored state.
+type MemoryOAuthClientRepository struct {
+	mu      sync.RWMutex
+	clients map[string]*models.OAuthClient
+	now     func() time.Time
+}
+
+// NewMemoryOAuthClientRepository creates an empty in-memory client repository
+func NewMemoryOAuthClientRepository() *MemoryOAuthClientRepository {
+	return &MemoryOAuthClientRepository{
+		clients: make(map[string]*models.OAuthClient),
+		now:     time.Now,
+	}
+}
+
+// Create stores a client, assigning an ID and timestamps like the database defaults would
+func (r *MemoryOAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client.ID == "" {
+		client.ID = uuid.NewString()
+	}
+	now := r.now()
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = now
+	}
+	client.UpdatedAt = now
+	r.clients[client.ID] = copyOAuthClient(client)
+	return nil
+}
+
+// GetByID returns a copy of a client
+func (r *MemoryOAuthClientRepository) GetByID(ctx context.Context, id string) (*models.OAuthClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, ErrOAuthClientNotFound
+	}
+	return copyOAuthClient(client), nil
+}
+
+// List returns copies of all clients, newest first
+func (r *MemoryOAuthClientRepository) List(ctx context.Context) ([]*models.OAuthClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]*models.OAuthClient, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, copyOAuthClient(client))
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if !clients[i].CreatedAt.Equal(clients[j].CreatedAt) {
+			return clients[i].CreatedAt.After(clients[j].CreatedAt)
+		}
+		return clients[i].ID > clients[j].ID
+	})
+	return clients, nil
+}
+
+// Delete removes a client
+func (r *MemoryOAuthClientRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[id]; !ok {
+		return ErrOAuthClientNotFound
+	}
+	delete(r.clients, id)
+	return nil
+}
+
+// copyOAuthClient copies a client including its redirect URIs
+func copyOAuthClient(client *models.OAuthClient) *models.OAuthClient {
+	copied := *client
+	copied.RedirectURIs = slices.Clone(client.RedirectURIs)
+	return &copied
+}
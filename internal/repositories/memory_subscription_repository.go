@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemorySubscriptionRepository is an in-process SubscriptionRepository for tests and local tooling.
+// Subscriptions are copied on the way in and out so callers cannot mutate stored state.
+type MemorySubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*models.Subscription // keyed by user ID
+	now           func() time.Time
+}
+
+// NewMemorySubscriptionRepository creates an empty in-memory subscription repository
+func NewMemorySubscriptionRepository() *MemorySubscriptionRepository {
+	return &MemorySubscriptionRepository{
+		subscriptions: make(map[string]*models.Subscription),
+		now:           time.Now,
+	}
+}
+
+// GetByUserID returns a copy of the user's subscription
+func (r *MemorySubscriptionRepository) GetByUserID(ctx context.Context, userID string) (*models.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[userID]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return copySubscription(subscription), nil
+}
+
+// GetByStripeCustomerID returns a copy of the Stripe customer's subscription
+func (r *MemorySubscriptionRepository) GetByStripeCustomerID(ctx context.Context, customerID string) (*models.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+		if subscription.StripeCustomerID == customerID {
+			return copySubscription(subscription), nil
+		}
+	}
+	return nil, ErrSubscriptionNotFound
+}
+
+// Save stores the subscription, keeping the ID and creation time of an existing one like the upsert does
+func (r *MemorySubscriptionRepository) Save(ctx context.Context, subscription *models.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if existing, ok := r.subscriptions[subscription.UserID]; ok {
+		subscription.ID = existing.ID
+		subscription.CreatedAt = existing.CreatedAt
+	} else {
+		if subscription.ID == "" {
+			subscription.ID = uuid.NewString()
+		}
+		subscription.CreatedAt = now
+	}
+	subscription.UpdatedAt = now
+	r.subscriptions[subscription.UserID] = copySubscription(subscription)
+	return nil
+}
+
+// copySubscription copies a subscription including its period end
+func copySubscription(subscription *models.Subscription) *models.Subscription {
+	copied := *subscription
+	if subscription.CurrentPeriodEnd != nil {
+		end := *subscription.CurrentPeriodEnd
+		copied.CurrentPeriodEnd = &end
+	}
+	return &copied
+}
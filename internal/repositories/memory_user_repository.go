@@ -0,0 +1,379 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicateUser is returned by the in-memory repository when the email or username is taken,
+// standing in for the unique index violation reported by the database
+var ErrDuplicateUser = errors.New("user with this email or username already exists")
+
+// MemoryUserRepository is an in-process UserRepository for tests and local tooling.
+// It mirrors the GORM repository's semantics: lookups and GetAll only see active users,
+// Search includes deactivated ones, and deleted users disappear from every query.
+// Users are copied on the way in and out so callers cannot mutate stored state.
+type MemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*models.User
+	now   func() time.Time
+}
+
+// NewMemoryUserRepository creates an empty in-memory user repository
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: make(map[string]*models.User),
+		now:   time.Now,
+	}
+}
+
+// Create stores a new user, assigning an ID and timestamps like the database defaults would
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email || existing.Username == user.Username {
+			return ErrDuplicateUser
+		}
+	}
+	r.insert(user)
+	return nil
+}
+
+// CreateBatch stores all users or, if any email or username is taken, none of them
+func (r *MemoryUserRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emails := make(map[string]bool, len(r.users)+len(users))
+	usernames := make(map[string]bool, len(r.users)+len(users))
+	for _, existing := range r.users {
+		emails[existing.Email], usernames[existing.Username] = true, true
+	}
+	for _, user := range users {
+		if emails[user.Email] || usernames[user.Username] {
+			return ErrDuplicateUser
+		}
+		emails[user.Email], usernames[user.Username] = true, true
+	}
+	for _, user := range users {
+		r.insert(user)
+	}
+	return nil
+}
+
+// insert stores a copy of user, assigning an ID and timestamps like the database defaults would; callers hold the lock
+func (r *MemoryUserRepository) insert(user *models.User) {
+	if user.ID == "" {
+		user.ID = uuid.NewString()
+	}
+	now := r.now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	stored := *user
+	r.users[user.ID] = &stored
+}
+
+// FindConflicts returns copies of the users whose email (case-insensitively) or username is in the given lists
+func (r *MemoryUserRepository) FindConflicts(ctx context.Context, emails, usernames []string) ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var conflicts []*models.User
+	for _, user := range r.users {
+		if slices.ContainsFunc(emails, func(email string) bool { return strings.EqualFold(email, user.Email) }) ||
+			slices.Contains(usernames, user.Username) {
+			found := *user
+			conflicts = append(conflicts, &found)
+		}
+	}
+	return conflicts, nil
+}
+
+// GetAnyByID gets a user by ID, active or not; deleted users are not kept
+func (r *MemoryUserRepository) GetAnyByID(ctx context.Context, id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+// Anonymize replaces the stored user with a copy of user, or removes it when user.DeletedAt is set
+func (r *MemoryUserRepository) Anonymize(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return ErrUserNotFound
+	}
+	if user.DeletedAt.Valid {
+		delete(r.users, user.ID)
+		return nil
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+// CountDeletedBefore always returns 0: deleted users are removed immediately and not kept
+func (r *MemoryUserRepository) CountDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// PurgeDeletedBefore always returns 0: deleted users are removed immediately and not kept
+func (r *MemoryUserRepository) PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+// GetByID gets an active user by ID
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.ID == id })
+}
+
+// GetByEmail gets an active user by email
+func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Email == email })
+}
+
+// GetByUsername gets an active user by username
+func (r *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Username == username })
+}
+
+// find returns a copy of the first active user matching the predicate
+func (r *MemoryUserRepository) find(match func(user *models.User) bool) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.IsActive && match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetAll gets active users with pagination, newest first
+func (r *MemoryUserRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
+	active := true
+	return r.Search(ctx, UserFilter{IsActive: &active}, offset, limit)
+}
+
+// Update replaces the stored user; unlike GORM's Updates, zero values are written as well
+func (r *MemoryUserRepository) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.CreatedAt = existing.CreatedAt
+	user.UpdatedAt = r.now()
+
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+// Delete removes a user
+func (r *MemoryUserRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// UpdateLastLogin updates the last login time for a user
+func (r *MemoryUserRepository) UpdateLastLogin(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	now := r.now()
+	user.LastLogin = &now
+	return nil
+}
+
+// UpdateLastLogins sets buffered last login times, keeping later stored times and ignoring unknown users
+func (r *MemoryUserRepository) UpdateLastLogins(ctx context.Context, logins map[string]time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, at := range logins {
+		user, ok := r.users[id]
+		if !ok || (user.LastLogin != nil && !user.LastLogin.Before(at)) {
+			continue
+		}
+		at := at
+		user.LastLogin = &at
+	}
+	return nil
+}
+
+// ExistsByEmail checks if a user, active or not, exists by email
+func (r *MemoryUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	return r.exists(func(user *models.User) bool { return user.Email == email }), nil
+}
+
+// ExistsByUsername checks if a user, active or not, exists by username
+func (r *MemoryUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	return r.exists(func(user *models.User) bool { return user.Username == username }), nil
+}
+
+// exists reports whether any stored user matches the predicate
+func (r *MemoryUserRepository) exists(match func(user *models.User) bool) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if match(user) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the total number of active users
+func (r *MemoryUserRepository) Count(ctx context.Context) (int64, error) {
+	_, total, err := r.GetAll(ctx, 0, 0)
+	return total, err
+}
+
+// Search filters users including deactivated ones, newest first; limit <= 0 returns all matches
+func (r *MemoryUserRepository) Search(ctx context.Context, filter UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.matching(filter)
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	total := int64(len(matches))
+	offset = min(max(offset, 0), len(matches))
+	matches = matches[offset:]
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, total, nil
+}
+
+// ScanUsers returns up to limit users matching filter with IDs after afterID, ordered by ID
+func (r *MemoryUserRepository) ScanUsers(ctx context.Context, filter UserFilter, afterID string, limit int) ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []*models.User
+	for _, user := range r.matching(filter) {
+		if user.ID > afterID {
+			matches = append(matches, user)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].ID < matches[j].ID
+	})
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// StreamUsers calls fn for each user matching filter, ordered by ID.
+// The matching users are copied under the lock and fn is called after it is released
+func (r *MemoryUserRepository) StreamUsers(ctx context.Context, filter UserFilter, fn func(user *models.User) error) error {
+	r.mu.RLock()
+	matches := r.matching(filter)
+	r.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].ID < matches[j].ID
+	})
+	for _, user := range matches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matching returns copies of the users matching filter in no particular order; callers hold the lock
+func (r *MemoryUserRepository) matching(filter UserFilter) []*models.User {
+	query := strings.ToLower(filter.Query)
+	var matches []*models.User
+	for _, user := range r.users {
+		if query != "" && !strings.Contains(strings.ToLower(user.Username), query) &&
+			!strings.Contains(strings.ToLower(user.Email), query) &&
+			!strings.Contains(strings.ToLower(user.FirstName), query) &&
+			!strings.Contains(strings.ToLower(user.LastName), query) {
+			continue
+		}
+		if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+			continue
+		}
+		if filter.IsAdmin != nil && user.IsAdmin != *filter.IsAdmin {
+			continue
+		}
+		found := *user
+		matches = append(matches, &found)
+	}
+	return matches
+}
+
+// SetActive activates or deactivates a user and returns the updated user
+func (r *MemoryUserRepository) SetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	return r.updateFlag(id, func(user *models.User) { user.IsActive = active })
+}
+
+// SetAdmin grants or revokes the admin role and returns the updated user
+func (r *MemoryUserRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error) {
+	return r.updateFlag(id, func(user *models.User) { user.IsAdmin = isAdmin })
+}
+
+// updateFlag applies a flag change to a user regardless of whether it is active
+func (r *MemoryUserRepository) updateFlag(id string, apply func(user *models.User)) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	apply(user)
+	user.UpdatedAt = r.now()
+
+	updated := *user
+	return &updated, nil
+}
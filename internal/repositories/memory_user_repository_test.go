@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryUserRepository checks that the in-memory repository follows the GORM repository's semantics
+func TestMemoryUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	alice := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	bob := &models.User{Username: "bob", Email: "bob@example.com", IsActive: true, IsAdmin: true}
+	require.NoError(t, repo.Create(ctx, alice))
+	require.NoError(t, repo.Create(ctx, bob))
+	assert.NotEmpty(t, alice.ID)
+	assert.ErrorIs(t, repo.Create(ctx, &models.User{Username: "alice", Email: "other@example.com"}), ErrDuplicateUser)
+
+	users, total, err := repo.GetAll(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "bob", users[0].Username, "newest users come first")
+
+	// Deactivated users are hidden from lookups but still found by Search and existence checks
+	_, err = repo.SetActive(ctx, alice.ID, false)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	exists, err := repo.ExistsByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	inactive := false
+	users, total, err = repo.Search(ctx, UserFilter{Query: "ALI", IsActive: &inactive}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, alice.ID, users[0].ID)
+
+	// Returned users are copies
+	found, err := repo.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+	found.Username = "mallory"
+	found, err = repo.GetByUsername(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, found.ID)
+
+	require.NoError(t, repo.UpdateLastLogin(ctx, bob.ID))
+	found, err = repo.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastLogin)
+
+	require.NoError(t, repo.Delete(ctx, bob.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, bob.ID), ErrUserNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, bob), ErrUserNotFound)
+}
+
+// TestMemoryUserRepositoryImport checks batch creation and conflict lookup used by bulk imports
+func TestMemoryUserRepositoryImport(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	require.NoError(t, repo.Create(ctx, &models.User{Username: "alice", Email: "Alice@example.com", IsActive: true}))
+
+	conflicts, err := repo.FindConflicts(ctx, []string{"alice@EXAMPLE.com"}, []string{"bob"})
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1, "emails match case-insensitively")
+	assert.Equal(t, "alice", conflicts[0].Username)
+
+	err = repo.CreateBatch(ctx, []*models.User{
+		{Username: "bob", Email: "bob@example.com"},
+		{Username: "alice", Email: "other@example.com"},
+	})
+	assert.ErrorIs(t, err, ErrDuplicateUser)
+	_, err = repo.GetByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, ErrUserNotFound, "a failed batch creates nothing")
+
+	batch := []*models.User{
+		{Username: "bob", Email: "bob@example.com", IsActive: true},
+		{Username: "carol", Email: "carol@example.com", IsActive: true},
+	}
+	require.NoError(t, repo.CreateBatch(ctx, batch))
+	assert.NotEmpty(t, batch[0].ID)
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+// TestMemoryUserRepositoryAnonymize checks that anonymization overwrites zero values and deleted users disappear
+func TestMemoryUserRepositoryAnonymize(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	alice := &models.User{Username: "alice", Email: "alice@example.com", FirstName: "Alice", IsActive: true}
+	require.NoError(t, repo.Create(ctx, alice))
+	_, err := repo.SetActive(ctx, alice.ID, false)
+	require.NoError(t, err)
+
+	found, err := repo.GetAnyByID(ctx, alice.ID)
+	require.NoError(t, err, "deactivated users are found")
+	assert.Equal(t, "Alice", found.FirstName)
+
+	require.NoError(t, repo.Anonymize(ctx, &models.User{ID: alice.ID, Username: "erased", Email: "erased@example.invalid"}))
+	found, err = repo.GetAnyByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found.FirstName)
+	assert.Equal(t, "erased", found.Username)
+
+	erased := &models.User{ID: alice.ID, Username: "erased", Email: "erased@example.invalid"}
+	erased.DeletedAt.Valid = true
+	require.NoError(t, repo.Anonymize(ctx, erased))
+	_, err = repo.GetAnyByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.ErrorIs(t, repo.Anonymize(ctx, erased), ErrUserNotFound)
+}
+
+// TestMemoryUserRepositoryStreamUsers checks that streaming follows the filter, orders by ID and stops on errors
+func TestMemoryUserRepositoryStreamUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	for _, name := range []string{"carol", "alice", "bob"} {
+		require.NoError(t, repo.Create(ctx, &models.User{Username: name, Email: name + "@example.com", IsActive: true}))
+	}
+	_, err := repo.SetActive(ctx, mustGetByUsername(t, repo, "bob").ID, false)
+	require.NoError(t, err)
+
+	var ids []string
+	require.NoError(t, repo.StreamUsers(ctx, UserFilter{}, func(user *models.User) error {
+		ids = append(ids, user.ID)
+		return nil
+	}))
+	assert.Len(t, ids, 3, "deactivated users are included")
+	assert.IsIncreasing(t, ids)
+
+	active := true
+	var names []string
+	require.NoError(t, repo.StreamUsers(ctx, UserFilter{IsActive: &active}, func(user *models.User) error {
+		names = append(names, user.Username)
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"alice", "carol"}, names)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.StreamUsers(ctx, UserFilter{}, func(user *models.User) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func mustGetByUsername(t *testing.T, repo *MemoryUserRepository, username string) *models.User {
+	t.Helper()
+	user, err := repo.GetByUsername(context.Background(), username)
+	require.NoError(t, err)
+	return user
+}
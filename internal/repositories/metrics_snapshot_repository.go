@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MetricsSnapshotRepository defines the interface for persisted metrics snapshots
+type MetricsSnapshotRepository interface {
+	// Save stores a snapshot, replacing the counters of an existing snapshot for the same instance, resolution and bucket
+	Save(ctx context.Context, snapshot *models.MetricsSnapshot) error
+	// Add stores a snapshot, adding its counters to an existing snapshot for the same instance, resolution and bucket
+	// and keeping the larger in-flight value
+	Add(ctx context.Context, snapshot *models.MetricsSnapshot) error
+	// List returns the snapshots of all instances at the given resolution with buckets in [from, to), oldest first
+	List(ctx context.Context, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error)
+	// ListForInstance returns the snapshots of one instance at the given resolution with buckets in [from, to), oldest first
+	ListForInstance(ctx context.Context, instance string, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error)
+	// Latest returns the most recent snapshot of an instance at the given resolution, or nil if there is none
+	Latest(ctx context.Context, instance string, resolution int) (*models.MetricsSnapshot, error)
+	// DeleteBefore deletes the snapshots at the given resolution with buckets before the cutoff
+	DeleteBefore(ctx context.Context, resolution int, before time.Time) (int64, error)
+}
+
+// MetricsSnapshotPurger is implemented by metrics snapshot repositories that can remove the snapshots of every
+// instance and resolution, including instances that no longer run and resolutions that are no longer configured
+type MetricsSnapshotPurger interface {
+	// CountAllBefore returns the number of snapshots with buckets before the cutoff
+	CountAllBefore(ctx context.Context, before time.Time) (int64, error)
+	// DeleteAllBefore deletes at most limit snapshots with buckets before the cutoff and returns the number deleted
+	DeleteAllBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type metricsSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewMetricsSnapshotRepository creates a new metrics snapshot repository
+func NewMetricsSnapshotRepository(db *gorm.DB) MetricsSnapshotRepository {
+	return &metricsSnapshotRepository{db: db}
+}
+
+// Save upserts a snapshot on (instance, resolution, bucket_start)
+func (r *metricsSnapshotRepository) Save(ctx context.Context, snapshot *models.MetricsSnapshot) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "instance"}, {Name: "resolution"}, {Name: "bucket_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"http_requests", "http_server_errors", "http_duration_us", "http_in_flight",
+			"rate_limit_requests", "rate_limit_throttled",
+			"cache_hits", "cache_misses", "cache_loads",
+		}),
+	}).Create(snapshot).Error
+	if err != nil {
+		return fmt.Errorf("failed to save metrics snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotCounterColumns are the counter columns that Add sums on conflict
+var snapshotCounterColumns = []string{
+	"http_requests", "http_server_errors", "http_duration_us",
+	"rate_limit_requests", "rate_limit_throttled",
+	"cache_hits", "cache_misses", "cache_loads",
+}
+
+// Add upserts a snapshot on (instance, resolution, bucket_start), accumulating counters
+// A bucket can receive several partial snapshots, e.g. the final one written on shutdown and the first one after a restart
+func (r *metricsSnapshotRepository) Add(ctx context.Context, snapshot *models.MetricsSnapshot) error {
+	assignments := make(map[string]interface{}, len(snapshotCounterColumns)+1)
+	for _, column := range snapshotCounterColumns {
+		assignments[column] = gorm.Expr(fmt.Sprintf("metrics_snapshots.%s + excluded.%s", column, column))
+	}
+	assignments["http_in_flight"] = gorm.Expr("CASE WHEN excluded.http_in_flight > metrics_snapshots.http_in_flight " +
+		"THEN excluded.http_in_flight ELSE metrics_snapshots.http_in_flight END")
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instance"}, {Name: "resolution"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(assignments),
+	}).Create(snapshot).Error
+	if err != nil {
+		return fmt.Errorf("failed to add metrics snapshot: %w", err)
+	}
+	return nil
+}
+
+// List returns the snapshots of all instances in the time range
+func (r *metricsSnapshotRepository) List(ctx context.Context, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error) {
+	var snapshots []*models.MetricsSnapshot
+	err := r.db.WithContext(ctx).
+		Where("resolution = ? AND bucket_start >= ? AND bucket_start < ?", resolution, from, to).
+		Order("bucket_start, instance").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metrics snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// ListForInstance returns the snapshots of one instance in the time range
+func (r *metricsSnapshotRepository) ListForInstance(ctx context.Context, instance string, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error) {
+	var snapshots []*models.MetricsSnapshot
+	err := r.db.WithContext(ctx).
+		Where("instance = ? AND resolution = ? AND bucket_start >= ? AND bucket_start < ?", instance, resolution, from, to).
+		Order("bucket_start").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metrics snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot of an instance
+func (r *metricsSnapshotRepository) Latest(ctx context.Context, instance string, resolution int) (*models.MetricsSnapshot, error) {
+	var snapshot models.MetricsSnapshot
+	err := r.db.WithContext(ctx).
+		Where("instance = ? AND resolution = ?", instance, resolution).
+		Order("bucket_start DESC").
+		First(&snapshot).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest metrics snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// DeleteBefore deletes expired snapshots
+func (r *metricsSnapshotRepository) DeleteBefore(ctx context.Context, resolution int, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("resolution = ? AND bucket_start < ?", resolution, before).
+		Delete(&models.MetricsSnapshot{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete metrics snapshots: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CountAllBefore counts the snapshots of every instance and resolution older than the cutoff
+func (r *metricsSnapshotRepository) CountAllBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MetricsSnapshot{}).
+		Where("bucket_start < ?", before).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count metrics snapshots: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteAllBefore deletes a batch of the oldest snapshots of every instance and resolution older than the cutoff
+func (r *metricsSnapshotRepository) DeleteAllBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	db := r.db.WithContext(ctx)
+	batch := db.Model(&models.MetricsSnapshot{}).Select("id").
+		Where("bucket_start < ?", before).
+		Order("bucket_start").
+		Limit(limit)
+	result := db.Where("id IN (?)", batch).Delete(&models.MetricsSnapshot{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete metrics snapshots: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotificationNotFound is returned when a notification does not exist or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationRepository defines the interface for the in-app notification inbox and notification preferences
+type NotificationRepository interface {
+	// Create stores a notification, assigning its ID and creation time
+	Create(ctx context.Context, notification *models.Notification) error
+	// List returns a page of a user's notifications, newest first, and the total number of matching notifications
+	List(ctx context.Context, userID string, unreadOnly bool, offset, limit int) ([]*models.Notification, int64, error)
+	// CountUnread returns the number of unread notifications of a user
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	// MarkRead marks one of a user's notifications as read; marking a read notification again is not an error
+	MarkRead(ctx context.Context, userID, id string, at time.Time) error
+	// MarkAllRead marks all unread notifications of a user as read and returns how many were marked
+	MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error)
+	// ListPreferences returns the preferences a user has stored
+	ListPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error)
+	// SavePreferences upserts preferences on (user_id, type, channel)
+	SavePreferences(ctx context.Context, preferences []*models.NotificationPreference) error
+	// DeleteUserData removes all notifications and preferences of a user and returns how many notifications were removed
+	DeleteUserData(ctx context.Context, userID string) (int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create inserts a notification
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	if err := r.db.WithContext(ctx).Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of a user's notifications using idx_notifications_user_created
+func (r *notificationRepository) List(ctx context.Context, userID string, unreadOnly bool, offset, limit int) ([]*models.Notification, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	var notifications []*models.Notification
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, total, nil
+}
+
+// CountUnread counts a user's notifications without a read time
+func (r *notificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead sets the read time of an unread notification, keeping the original time of a read one
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) error {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).Select("id").Where("id = ? AND user_id = ?", id, userID).First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find notification: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead sets the read time of all unread notifications of a user
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListPreferences returns a user's stored preferences
+func (r *notificationRepository) ListPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error) {
+	var preferences []*models.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// SavePreferences upserts preferences in a single statement
+func (r *notificationRepository) SavePreferences(ctx context.Context, preferences []*models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserData removes a user's notifications and preferences in a single transaction
+func (r *notificationRepository) DeleteUserData(ctx context.Context, userID string) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&models.Notification{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return tx.Where("user_id = ?", userID).Delete(&models.NotificationPreference{}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete notification data: %w", err)
+	}
+	return deleted, nil
+}
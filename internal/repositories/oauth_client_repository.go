@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrOAuthClientNotFound is returned when an OpenID Connect client does not exist
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+// OAuthClientRepository defines the interface for registered OpenID Connect clients.
+// Clients identify applications rather than tenants, so every method works across tenants.
+type OAuthClientRepository interface {
+	// Create stores a client, assigning its ID and timestamps
+	Create(ctx context.Context, client *models.OAuthClient) error
+	// GetByID returns a client by client ID
+	GetByID(ctx context.Context, id string) (*models.OAuthClient, error)
+	// List returns all clients, newest first
+	List(ctx context.Context) ([]*models.OAuthClient, error)
+	// Delete removes a client
+	Delete(ctx context.Context, id string) error
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository creates a new OpenID Connect client repository
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+// Create inserts a client
+func (r *oauthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	if err := r.db.WithContext(ctx).Create(client).Error; err != nil {
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+	return nil
+}
+
+// GetByID finds a client by primary key
+func (r *oauthClientRepository) GetByID(ctx context.Context, id string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOAuthClientNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+	return &client, nil
+}
+
+// List returns all clients ordered by creation time
+func (r *oauthClientRepository) List(ctx context.Context) ([]*models.OAuthClient, error) {
+	var clients []*models.OAuthClient
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Order("id DESC").Find(&clients).Error; err != nil {
+		return nil, fmt.Errorf("failed to list oauth clients: %w", err)
+	}
+	return clients, nil
+}
+
+// Delete removes a client by primary key
+func (r *oauthClientRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.OAuthClient{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete oauth client: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
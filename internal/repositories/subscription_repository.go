@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-server/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSubscriptionNotFound is returned when a user has never subscribed
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// SubscriptionRepository defines the interface for users' Stripe subscriptions.
+// Each user has at most one subscription, written only by the Stripe webhook.
+type SubscriptionRepository interface {
+	// GetByUserID returns the subscription of a user
+	GetByUserID(ctx context.Context, userID string) (*models.Subscription, error)
+	// GetByStripeCustomerID returns the subscription of a Stripe customer
+	GetByStripeCustomerID(ctx context.Context, customerID string) (*models.Subscription, error)
+	// Save inserts the subscription or replaces the existing one of the same user
+	Save(ctx context.Context, subscription *models.Subscription) error
+}
+
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriptionRepository creates a new subscription repository
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+// GetByUserID finds a subscription by user ID
+func (r *subscriptionRepository) GetByUserID(ctx context.Context, userID string) (*models.Subscription, error) {
+	return r.first(ctx, "user_id = ?", userID)
+}
+
+// GetByStripeCustomerID finds a subscription by Stripe customer ID
+func (r *subscriptionRepository) GetByStripeCustomerID(ctx context.Context, customerID string) (*models.Subscription, error) {
+	return r.first(ctx, "stripe_customer_id = ?", customerID)
+}
+
+// Save upserts on user_id so a user switching Stripe subscriptions keeps a single row
+func (r *subscriptionRepository) Save(ctx context.Context, subscription *models.Subscription) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"plan_id", "status", "stripe_customer_id", "stripe_subscription_id",
+			"current_period_end", "cancel_at_period_end", "last_event_at", "updated_at",
+		}),
+	}).Create(subscription).Error
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+// first returns the subscription matching the condition
+func (r *subscriptionRepository) first(ctx context.Context, query string, arg string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.db.WithContext(ctx).Where(query, arg).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &subscription, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"go-server/internal/models"
+	"go-server/internal/tenancy"
+
+	"gorm.io/gorm"
+)
+
+// ErrTenantNotFound is returned when no tenant matches the lookup
+var ErrTenantNotFound = tenancy.ErrTenantNotFound
+
+// TenantRepository defines the interface for tenant database operations
+type TenantRepository interface {
+	Create(tenant *models.Tenant) error
+	GetByID(id string) (*models.Tenant, error)
+	GetBySlug(slug string) (*models.Tenant, error)
+	List() ([]*models.Tenant, error)
+	Update(tenant *models.Tenant) error
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+// Create creates a new tenant
+func (r *tenantRepository) Create(tenant *models.Tenant) error {
+	if err := r.db.Create(tenant).Error; err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets a tenant by ID
+func (r *tenantRepository) GetByID(id string) (*models.Tenant, error) {
+	return r.first("id = ?", id)
+}
+
+// GetBySlug gets a tenant by slug
+func (r *tenantRepository) GetBySlug(slug string) (*models.Tenant, error) {
+	return r.first("slug = ?", slug)
+}
+
+// List returns all tenants ordered by slug
+func (r *tenantRepository) List() ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	if err := r.db.Order("slug").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// Update saves all fields of the tenant
+func (r *tenantRepository) Update(tenant *models.Tenant) error {
+	if err := r.db.Save(tenant).Error; err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
+}
+
+// first returns the first tenant matching the condition
+func (r *tenantRepository) first(query string, args ...interface{}) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.Where(query, args...).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &tenant, nil
+}
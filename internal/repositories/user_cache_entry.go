@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+// cacheEntryVersion is stored with cached users and user lists
+// Bump it when the cached representation changes so entries written by older releases are treated as misses
+const cacheEntryVersion = 3
+
+// userRecord is the cached representation of models.User
+// Unlike models.User it serializes every column, including the password hash, so that a cache hit
+// returns the same record as the database and callers can safely update it.
+// It must keep the same fields as models.User; the conversions below stop compiling otherwise
+type userRecord struct {
+	ID         string         `json:"id"`
+	Username   string         `json:"username"`
+	Email      string         `json:"email"`
+	EmailIndex *string        `json:"email_index"`
+	Password   string         `json:"password"`
+	FirstName  string         `json:"first_name"`
+	LastName   string         `json:"last_name"`
+	Avatar     string         `json:"avatar"`
+	AvatarKey  string         `json:"avatar_key"`
+	TenantID   *string        `json:"tenant_id"`
+	IsActive   bool           `json:"is_active"`
+	IsAdmin    bool           `json:"is_admin"`
+	LastLogin  *time.Time     `json:"last_login"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at"`
+}
+
+// newUserRecord converts a user to its cached representation
+func newUserRecord(user *models.User) userRecord {
+	return userRecord(*user)
+}
+
+// toModel converts a cached user back to the model
+func (r userRecord) toModel() *models.User {
+	user := models.User(r)
+	return &user
+}
+
+// cachedUser is the cache entry for a single user
+type cachedUser struct {
+	Version int        `json:"v"`
+	User    userRecord `json:"user"`
+}
+
+// cachedUserList is the cache entry for a page of users
+type cachedUserList struct {
+	Version int          `json:"v"`
+	Users   []userRecord `json:"users"`
+	Total   int64        `json:"total"`
+}
+
+// newCachedUserList converts a page of users to its cache entry
+func newCachedUserList(users []*models.User, total int64) cachedUserList {
+	records := make([]userRecord, len(users))
+	for i, user := range users {
+		records[i] = newUserRecord(user)
+	}
+	return cachedUserList{Version: cacheEntryVersion, Users: records, Total: total}
+}
+
+// toModels converts a cached page of users back to models
+func (l cachedUserList) toModels() []*models.User {
+	users := make([]*models.User, len(l.Users))
+	for i, record := range l.Users {
+		users[i] = record.toModel()
+	}
+	return users
+}
+
+// CheckCacheCodec reports whether codec can serialize the entries stored by CachedUserRepository.
+// Codecs limited to specific value types, such as protobuf, fail here; used at startup so that
+// such a codec is rejected instead of silently disabling the user cache
+func CheckCacheCodec(codec cache.Codec) error {
+	user := &models.User{ID: "00000000-0000-0000-0000-000000000000", Username: "codec_check"}
+	entries := []interface{}{
+		cachedUser{Version: cacheEntryVersion, User: newUserRecord(user)},
+		newCachedUserList([]*models.User{user}, 1),
+		true,
+		int64(1),
+	}
+	for _, entry := range entries {
+		if _, err := codec.Marshal(entry); err != nil {
+			return fmt.Errorf("cache codec %s cannot encode user cache entries: %w", codec.Name(), err)
+		}
+	}
+	return nil
+}
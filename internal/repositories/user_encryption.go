@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-server/internal/models"
+	"go-server/internal/tenancy"
+	"go-server/pkg/fieldcrypt"
+
+	"gorm.io/gorm"
+)
+
+// ErrEncryptionDisabled is returned by ReencryptUsers when no field encryption keyring is installed
+var ErrEncryptionDisabled = errors.New("field encryption is not enabled")
+
+// encryptedUserColumns are the users columns written by ReencryptUsers, read without the encrypted serializer
+type encryptedUserColumns struct {
+	ID         string
+	Email      string
+	FirstName  *string
+	LastName   *string
+	EmailIndex *string
+}
+
+// ReencryptResult reports the progress of ReencryptUsers
+type ReencryptResult struct {
+	Scanned int64 // Users read
+	Updated int64 // Users whose columns were rewritten
+}
+
+// ReencryptUsers encrypts the personal data columns of every user, including deleted users and users of all
+// tenants, with the active key of the installed keyring and fills in missing or stale blind indexes.
+// Run it after enabling field encryption, after adding a new key version and before removing an old one.
+// Users already encrypted with the active key are skipped, so it can be stopped and resumed at any time.
+// progress, if not nil, is called after each batch
+func ReencryptUsers(ctx context.Context, db *gorm.DB, batchSize int, progress func(ReencryptResult)) (ReencryptResult, error) {
+	var result ReencryptResult
+	keyring := fieldcrypt.Current()
+	if keyring == nil {
+		return result, ErrEncryptionDisabled
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	table := models.User{}.TableName()
+	db = db.WithContext(tenancy.Unscoped(ctx))
+
+	afterID := ""
+	for {
+		var rows []encryptedUserColumns
+		query := db.Table(table).Select("id", "email", "first_name", "last_name", "email_index").Order("id").Limit(batchSize)
+		if afterID != "" {
+			query = query.Where("id > ?", afterID)
+		}
+		if err := query.Find(&rows).Error; err != nil {
+			return result, fmt.Errorf("failed to read users: %w", err)
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+
+		for _, row := range rows {
+			updates, err := reencryptedColumns(keyring, table, row)
+			if err != nil {
+				return result, fmt.Errorf("failed to re-encrypt user %s: %w", row.ID, err)
+			}
+			if len(updates) > 0 {
+				// Table instead of Model skips the hooks and the serializer, the values are already encrypted
+				if err := db.Table(table).Where("id = ?", row.ID).Updates(updates).Error; err != nil {
+					return result, fmt.Errorf("failed to update user %s: %w", row.ID, err)
+				}
+				result.Updated++
+			}
+			result.Scanned++
+		}
+		afterID = rows[len(rows)-1].ID
+
+		if progress != nil {
+			progress(result)
+		}
+	}
+}
+
+// reencryptedColumns returns the columns of row that are not encrypted with the active key, re-encrypted,
+// and the blind index of the email if it is missing or was computed with another index key
+func reencryptedColumns(keyring *fieldcrypt.Keyring, table string, row encryptedUserColumns) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+	columns := []struct {
+		name  string
+		value string
+	}{
+		{"email", row.Email},
+		{"first_name", deref(row.FirstName)},
+		{"last_name", deref(row.LastName)},
+	}
+
+	for _, column := range columns {
+		aad := fieldcrypt.Column(table, column.name)
+		plaintext, err := keyring.Decrypt(column.value, aad)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.name, err)
+		}
+		if column.name == "email" {
+			if index := keyring.BlindIndex(plaintext); row.EmailIndex == nil || *row.EmailIndex != index {
+				updates["email_index"] = index
+			}
+		}
+		if keyring.IsCurrent(column.value) {
+			continue
+		}
+		ciphertext, err := keyring.Encrypt(plaintext, aad)
+		if err != nil {
+			return nil, err
+		}
+		updates[column.name] = ciphertext
+	}
+	return updates, nil
+}
+
+// deref returns the value of a nullable column, empty for NULL
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
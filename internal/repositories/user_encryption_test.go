@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go-server/pkg/fieldcrypt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptedColumns(t *testing.T) {
+	indexKey := []byte("blind-index-key-for-tests-32-bytes")
+	v1, err := fieldcrypt.NewKeyring(map[uint32][]byte{1: bytes.Repeat([]byte{1}, fieldcrypt.KeySize)}, 0, indexKey)
+	require.NoError(t, err)
+	v2, err := v1.WithKeys(map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, fieldcrypt.KeySize),
+		2: bytes.Repeat([]byte{2}, fieldcrypt.KeySize),
+	}, 0)
+	require.NoError(t, err)
+	first := "Alice"
+
+	// A row written before encryption was enabled is encrypted and indexed
+	plain := encryptedUserColumns{ID: "1", Email: "alice@example.com", FirstName: &first}
+	updates, err := reencryptedColumns(v1, "users", plain)
+	require.NoError(t, err)
+	assert.Equal(t, v1.BlindIndex("alice@example.com"), updates["email_index"])
+	assert.NotContains(t, updates, "last_name", "NULL columns stay empty")
+	email, err := v1.Decrypt(updates["email"].(string), fieldcrypt.Column("users", "email"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", email)
+	name, err := v1.Decrypt(updates["first_name"].(string), fieldcrypt.Column("users", "first_name"))
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+
+	// A row encrypted with the active key is left alone
+	index := updates["email_index"].(string)
+	encryptedName := updates["first_name"].(string)
+	current := encryptedUserColumns{ID: "1", Email: updates["email"].(string), FirstName: &encryptedName, EmailIndex: &index}
+	updates, err = reencryptedColumns(v1, "users", current)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+
+	// After a new key version is added the row is re-encrypted with it, the index is unchanged
+	updates, err = reencryptedColumns(v2, "users", current)
+	require.NoError(t, err)
+	assert.NotContains(t, updates, "email_index")
+	version, ok := fieldcrypt.KeyVersion(updates["email"].(string))
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2), version)
+
+	// Values that cannot be decrypted fail the run instead of being overwritten
+	garbage := "enc:v1:AAAA"
+	_, err = reencryptedColumns(v1, "users", encryptedUserColumns{ID: "1", Email: "a@example.com", LastName: &garbage})
+	assert.Error(t, err)
+}
+
+func TestReencryptUsersRequiresKeyring(t *testing.T) {
+	_, err := ReencryptUsers(context.Background(), nil, 0, nil)
+	assert.ErrorIs(t, err, ErrEncryptionDisabled)
+}
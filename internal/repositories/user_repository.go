@@ -2,36 +2,96 @@ package repositories
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"go-server/internal/database"
 	"go-server/internal/models"
+	"go-server/internal/tenancy"
 	"go-server/pkg/cache"
+	"go-server/pkg/fieldcrypt"
 
 	"gorm.io/gorm"
 )
 
+// ErrUserNotFound is returned when no active user matches the lookup
+var ErrUserNotFound = errors.New("user not found")
+
 // UserRepository defines the interface for user database operations
+// Every method takes the caller's context so cancellation, deadlines and tracing reach GORM and the cache
 type UserRepository interface {
-	Create(user *models.User) error
-	GetByID(id string) (*models.User, error)
-	GetByEmail(email string) (*models.User, error)
-	GetByUsername(username string) (*models.User, error)
-	GetAll(offset, limit int) ([]*models.User, int64, error)
-	Update(user *models.User) error
-	Delete(id string) error
-	UpdateLastLogin(id string) error
-	ExistsByEmail(email string) (bool, error)
-	ExistsByUsername(username string) (bool, error)
-	Count() (int64, error)
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id string) error
+	UpdateLastLogin(ctx context.Context, id string) error
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	Count(ctx context.Context) (int64, error)
+	Search(ctx context.Context, filter UserFilter, offset, limit int) ([]*models.User, int64, error)
+	SetActive(ctx context.Context, id string, active bool) (*models.User, error)
+	SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error)
+}
+
+// UserImporter is implemented by user repositories that support bulk imports
+type UserImporter interface {
+	// FindConflicts returns users whose email (case-insensitively) or username is in the given lists.
+	// Both columns are unique across the whole table, so deleted users and users of other tenants are included.
+	FindConflicts(ctx context.Context, emails, usernames []string) ([]*models.User, error)
+	// CreateBatch creates the users in a single transaction: either all of them are created or none is
+	CreateBatch(ctx context.Context, users []*models.User) error
+}
+
+// UserEraser is implemented by user repositories that support erasing a user's personal data
+type UserEraser interface {
+	// GetAnyByID gets a user by ID, including deactivated and deleted users of the caller's tenant
+	GetAnyByID(ctx context.Context, id string) (*models.User, error)
+	// Anonymize overwrites the personal data columns of a user with the values in user, zero values included.
+	// The anonymized row is kept, soft-deleted when user.DeletedAt is set, so references to the user ID stay valid.
+	Anonymize(ctx context.Context, user *models.User) error
+}
+
+// UserStreamer is implemented by user repositories that can stream large listings row by row
+type UserStreamer interface {
+	// StreamUsers calls fn for each user matching filter, ordered by ID, as the rows are read, so memory use
+	// does not grow with the number of users. The user passed to fn is reused for the next row; callers must
+	// copy it to keep it. An error returned by fn stops the stream and is returned as is
+	StreamUsers(ctx context.Context, filter UserFilter, fn func(user *models.User) error) error
+}
+
+// UserPurger is implemented by user repositories that can permanently remove soft-deleted users.
+// Both methods look at the users of every tenant
+type UserPurger interface {
+	// CountDeletedBefore returns the number of users soft-deleted before the cutoff
+	CountDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// PurgeDeletedBefore permanently deletes at most limit users soft-deleted before the cutoff and returns
+	// the number of deleted users. Rows referencing the users are removed by their ON DELETE CASCADE constraints
+	PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// RetryPolicySetter is implemented by user repositories that retry transient database errors
+type RetryPolicySetter interface {
+	SetRetryPolicy(policy database.RetryPolicy)
+}
+
+// UserFilter filters the admin user listing; zero-value fields are ignored.
+// Unlike GetAll, Search includes deactivated users.
+type UserFilter struct {
+	Query    string // case-insensitive match on username, email, first or last name; only exact emails match when field encryption is enabled
+	IsActive *bool
+	IsAdmin  *bool
 }
 
 type userRepository struct {
 	db    *gorm.DB
 	cache cache.Cache
 	ttl   time.Duration
+	retry database.RetryPolicy
 }
 
 // NewUserRepository creates a new user repository
@@ -40,6 +100,7 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 		db:    db,
 		cache: nil,
 		ttl:   0,
+		retry: database.DefaultRetryPolicy,
 	}
 }
 
@@ -49,19 +110,35 @@ func NewUserRepositoryWithCache(db *gorm.DB, cache cache.Cache, ttl time.Duratio
 		db:    db,
 		cache: cache,
 		ttl:   ttl,
+		retry: database.DefaultRetryPolicy,
 	}
 }
 
+// SetRetryPolicy sets how transient database errors are retried; it must be called before the repository is used
+func (r *userRepository) SetRetryPolicy(policy database.RetryPolicy) {
+	r.retry = policy
+}
+
+// withRetry runs fn with a database handle bound to ctx, retrying it on transient errors.
+// A transient error that persists is returned as a *database.TransientError
+func (r *userRepository) withRetry(ctx context.Context, fn func(db *gorm.DB) error) error {
+	return database.Retry(ctx, r.retry, func(ctx context.Context) error {
+		return fn(r.db.WithContext(ctx))
+	})
+}
+
 // Create creates a new user
-func (r *userRepository) Create(user *models.User) error {
-	if err := r.db.Create(user).Error; err != nil {
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Create(user).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// Invalidate cache entries that might be affected by user creation
 	if r.cache != nil {
 		// Invalidate list caches and existence checks
-		ctx := context.Background()
 		r.invalidateUserListCaches(ctx)
 
 		// Invalidate existence checks for this user's email and username
@@ -78,21 +155,23 @@ func (r *userRepository) Create(user *models.User) error {
 }
 
 // GetByID gets a user by ID
-func (r *userRepository) GetByID(id string) (*models.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	// Try cache first if available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:id:%s", id)
-		if user, found := r.getUserFromCache(cacheKey); found {
+		if user, found := r.getUserFromCache(ctx, cacheKey); found {
 			return user, nil
 		}
 	}
 
 	// Cache miss or no cache available, get from database
 	var user models.User
-	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&user).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Where("id = ? AND is_active = ?", id, true).First(&user).Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -100,28 +179,30 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 	// Cache the result if cache is available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:id:%s", id)
-		r.setUserCache(cacheKey, &user)
+		r.setUserCache(ctx, cacheKey, &user)
 	}
 
 	return &user, nil
 }
 
 // GetByEmail gets a user by email
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	// Try cache first if available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:email:%s", email)
-		if user, found := r.getUserFromCache(cacheKey); found {
+		if user, found := r.getUserFromCache(ctx, cacheKey); found {
 			return user, nil
 		}
 	}
 
 	// Cache miss or no cache available, get from database
 	var user models.User
-	err := r.db.Where("email = ? AND is_active = ?", email, true).First(&user).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return whereEmail(db, email).Where("is_active = ?", true).First(&user).Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -129,28 +210,30 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	// Cache the result if cache is available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:email:%s", email)
-		r.setUserCache(cacheKey, &user)
+		r.setUserCache(ctx, cacheKey, &user)
 	}
 
 	return &user, nil
 }
 
 // GetByUsername gets a user by username
-func (r *userRepository) GetByUsername(username string) (*models.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	// Try cache first if available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:username:%s", username)
-		if user, found := r.getUserFromCache(cacheKey); found {
+		if user, found := r.getUserFromCache(ctx, cacheKey); found {
 			return user, nil
 		}
 	}
 
 	// Cache miss or no cache available, get from database
 	var user models.User
-	err := r.db.Where("username = ? AND is_active = ?", username, true).First(&user).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Where("username = ? AND is_active = ?", username, true).First(&user).Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -158,23 +241,20 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	// Cache the result if cache is available
 	if r.cache != nil {
 		cacheKey := fmt.Sprintf("user:username:%s", username)
-		r.setUserCache(cacheKey, &user)
+		r.setUserCache(ctx, cacheKey, &user)
 	}
 
 	return &user, nil
 }
 
 // GetAll gets all users with pagination
-func (r *userRepository) GetAll(offset, limit int) ([]*models.User, int64, error) {
+func (r *userRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
 	// Try cache first if available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("users:all:%d:%d", offset, limit)
 
-		if cachedValue, found := r.cache.Get(ctx, cacheKey); found {
-			if result, ok := r.unmarshalUserListResult(cachedValue); ok {
-				return result.Users, result.Total, nil
-			}
+		if entry, found := cache.GetAs[cachedUserList](ctx, r.cache, cacheKey); found && entry.Version == cacheEntryVersion {
+			return entry.toModels(), entry.Total, nil
 		}
 	}
 
@@ -183,29 +263,29 @@ func (r *userRepository) GetAll(offset, limit int) ([]*models.User, int64, error
 	var total int64
 
 	// Get total count
-	if err := r.db.Model(&models.User{}).Where("is_active = ?", true).Count(&total).Error; err != nil {
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Model(&models.User{}).Where("is_active = ?", true).Count(&total).Error
+	})
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
 	// Get users with pagination
-	err := r.db.Where("is_active = ?", true).
-		Offset(offset).
-		Limit(limit).
-		Order("created_at DESC").
-		Find(&users).Error
+	err = r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Where("is_active = ?", true).
+			Offset(offset).
+			Limit(limit).
+			Order("created_at DESC").
+			Find(&users).Error
+	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get users: %w", err)
 	}
 
 	// Cache the result if cache is available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("users:all:%d:%d", offset, limit)
-		result := UserListResult{
-			Users: users,
-			Total: total,
-		}
-		if err := r.cache.Set(ctx, cacheKey, result, r.ttl); err != nil {
+		if err := r.cache.SetWithTags(ctx, cacheKey, newCachedUserList(users, total), r.ttl, UserListCacheTag); err != nil {
 			// Log error but don't fail the operation
 		}
 	}
@@ -214,50 +294,63 @@ func (r *userRepository) GetAll(offset, limit int) ([]*models.User, int64, error
 }
 
 // Update updates a user
-func (r *userRepository) Update(user *models.User) error {
-	result := r.db.Where("id = ?", user.ID).Updates(user)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update user: %w", result.Error)
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	var rowsAffected int64
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		result := db.Where("id = ?", user.ID).Updates(user)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	if rowsAffected == 0 {
+		return ErrUserNotFound
 	}
 
 	// Invalidate cache entries that might be affected by user update
 	if r.cache != nil {
-		r.invalidateUserCache(user)
+		r.invalidateUserCache(ctx, user)
 	}
 
 	return nil
 }
 
 // Delete soft deletes a user
-func (r *userRepository) Delete(id string) error {
+func (r *userRepository) Delete(ctx context.Context, id string) error {
 	// Get the user before deletion to invalidate proper cache keys
 	var user models.User
-	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Where("id = ?", id).First(&user).Error
+	})
+	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("failed to get user for cache invalidation: %w", err)
 		}
 		// User doesn't exist, but we still need to try deletion
 	}
 
-	result := r.db.Where("id = ?", id).Delete(&models.User{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+	var rowsAffected int64
+	err = r.withRetry(ctx, func(db *gorm.DB) error {
+		result := db.Where("id = ?", id).Delete(&models.User{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	if rowsAffected == 0 {
+		return ErrUserNotFound
 	}
 
 	// Invalidate cache entries that might be affected by user deletion
 	if r.cache != nil {
 		if user.ID != "" {
 			// We have the user data, invalidate all related caches
-			r.invalidateUserCache(&user)
+			r.invalidateUserCache(ctx, &user)
 		} else {
 			// We don't have user data, invalidate by ID at minimum
-			r.invalidateUserCacheByID(id)
+			r.invalidateUserCacheByID(ctx, id)
 		}
 	}
 
@@ -265,40 +358,362 @@ func (r *userRepository) Delete(id string) error {
 }
 
 // UpdateLastLogin updates the last login time for a user
-func (r *userRepository) UpdateLastLogin(id string) error {
-	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("last_login", "NOW()")
-	if result.Error != nil {
-		return fmt.Errorf("failed to update last login: %w", result.Error)
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id string) error {
+	var rowsAffected int64
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		result := db.Model(&models.User{}).Where("id = ?", id).Update("last_login", "NOW()")
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	if rowsAffected == 0 {
+		return ErrUserNotFound
 	}
 
 	// Invalidate cache entries that might be affected by last login update
 	if r.cache != nil {
-		r.invalidateUserCacheByID(id)
+		r.invalidateUserCacheByID(ctx, id)
+	}
+
+	return nil
+}
+
+// UpdateLastLogins writes buffered last login times in a single statement, keeping later stored times
+func (r *userRepository) UpdateLastLogins(ctx context.Context, logins map[string]time.Time) error {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(logins))
+	args := make([]interface{}, 0, len(logins)*2)
+	for id, at := range logins {
+		values = append(values, "(CAST(? AS uuid), CAST(? AS timestamptz))")
+		args = append(args, id, at)
+	}
+
+	query := `UPDATE users SET last_login = v.last_login
+		FROM (VALUES ` + strings.Join(values, ", ") + `) AS v(id, last_login)
+		WHERE users.id = v.id AND (users.last_login IS NULL OR users.last_login < v.last_login)`
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Exec(query, args...).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update last logins: %w", err)
+	}
+
+	if r.cache != nil {
+		for id := range logins {
+			r.invalidateUserCacheByID(ctx, id)
+		}
+	}
+
+	return nil
+}
+
+// Search lists users matching the filter, including deactivated users. Results are not cached.
+func (r *userRepository) Search(ctx context.Context, filter UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	var total int64
+	err := database.Retry(ctx, r.retry, func(ctx context.Context) error {
+		return r.filtered(ctx, filter).Count(&total).Error
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []*models.User
+	err = database.Retry(ctx, r.retry, func(ctx context.Context) error {
+		return r.filtered(ctx, filter).Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// ScanUsers returns up to limit users matching filter with IDs after afterID, ordered by ID.
+// Unlike Search's offset pagination, keyset pagination stays fast and skips no rows when walking a large table.
+func (r *userRepository) ScanUsers(ctx context.Context, filter UserFilter, afterID string, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := database.Retry(ctx, r.retry, func(ctx context.Context) error {
+		query := r.filtered(ctx, filter)
+		if afterID != "" {
+			query = query.Where("id > ?", afterID)
+		}
+		return query.Order("id").Limit(limit).Find(&users).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan users: %w", err)
 	}
+	return users, nil
+}
 
+// StreamUsers reads the users matching filter through a single cursor and scans them one row at a time
+func (r *userRepository) StreamUsers(ctx context.Context, filter UserFilter, fn func(user *models.User) error) error {
+	db := r.filtered(ctx, filter).Order("id")
+	rows, err := db.Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream users: %w", err)
+	}
+	defer rows.Close()
+
+	var user models.User
+	for rows.Next() {
+		user = models.User{}
+		if err := db.ScanRows(rows, &user); err != nil {
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to stream users: %w", err)
+	}
+	return nil
+}
+
+// FindConflicts returns users, including deleted users and users of other tenants, that share an email or username
+func (r *userRepository) FindConflicts(ctx context.Context, emails, usernames []string) ([]*models.User, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	emailCondition := r.db.Where("LOWER(email) IN ?", lowered)
+	if keyring := fieldcrypt.Current(); keyring != nil {
+		indexes := make([]string, len(emails))
+		for i, email := range emails {
+			indexes[i] = keyring.BlindIndex(email)
+		}
+		emailCondition = r.db.Where("email_index IN ?", indexes).Or("email_index IS NULL AND LOWER(email) IN ?", lowered)
+	}
+
+	var users []*models.User
+	err := r.withRetry(tenancy.Unscoped(ctx), func(db *gorm.DB) error {
+		return db.Unscoped().Where(emailCondition).Or("username IN ?", usernames).Find(&users).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find conflicting users: %w", err)
+	}
+	return users, nil
+}
+
+// CreateBatch creates the users in a single transaction
+func (r *userRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+	// The whole transaction is retried, since a serialization failure or deadlock rolls all of it back
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&users).Error
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+
+	if r.cache != nil {
+		r.invalidateUserListCaches(ctx)
+	}
 	return nil
 }
 
+// GetAnyByID gets a user by ID, including deactivated and deleted users. Results are not cached.
+func (r *userRepository) GetAnyByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Unscoped().Where("id = ?", id).First(&user).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// Anonymize overwrites the personal data columns of a user, which may already be deleted
+func (r *userRepository) Anonymize(ctx context.Context, user *models.User) error {
+	// Get the user before anonymization to invalidate the cache keys of its old email and username.
+	// Column updates bypass the encrypted serializer, which is fine as the anonymized values are not personal data
+	var previous *models.User
+	if r.cache != nil {
+		previous, _ = r.GetAnyByID(ctx, user.ID)
+	}
+
+	columns := map[string]interface{}{
+		"username":    user.Username,
+		"email":       user.Email,
+		"email_index": emailIndex(user.Email),
+		"password":    user.Password,
+		"first_name":  user.FirstName,
+		"last_name":   user.LastName,
+		"avatar":      user.Avatar,
+		"avatar_key":  user.AvatarKey,
+		"last_login":  user.LastLogin,
+		"is_active":   user.IsActive,
+		"is_admin":    user.IsAdmin,
+		"updated_at":  user.UpdatedAt,
+		"deleted_at":  user.DeletedAt,
+	}
+	var rowsAffected int64
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		result := db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Updates(columns)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	if r.cache != nil {
+		r.invalidateUserCache(ctx, previous)
+		r.invalidateUserCache(ctx, user)
+	}
+	return nil
+}
+
+// CountDeletedBefore counts the users of all tenants soft-deleted before the cutoff
+func (r *userRepository) CountDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := r.withRetry(tenancy.Unscoped(ctx), func(db *gorm.DB) error {
+		return db.Unscoped().Model(&models.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Count(&count).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted users: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeDeletedBefore hard deletes a batch of users of all tenants soft-deleted before the cutoff.
+// Deleted users are no longer cached, so no cache entries need to be invalidated
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var rowsAffected int64
+	err := r.withRetry(tenancy.Unscoped(ctx), func(db *gorm.DB) error {
+		batch := db.Unscoped().Model(&models.User{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("deleted_at").
+			Limit(limit)
+		result := db.Unscoped().Where("id IN (?)", batch).Delete(&models.User{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// whereEmail restricts query to the user with the given email. When field encryption is enabled the email column
+// holds ciphertext, so the user is looked up by the blind index of the email instead, which is case-insensitive.
+// Rows written before encryption was enabled have no index yet and still hold the plaintext email.
+func whereEmail(query *gorm.DB, email string) *gorm.DB {
+	index, ok := fieldcrypt.BlindIndex(email)
+	if !ok {
+		return query.Where("email = ?", email)
+	}
+	return query.Where("(email_index = ? OR (email_index IS NULL AND email = ?))", index, email)
+}
+
+// emailIndex returns the value of the email_index column for column updates, nil when field encryption is disabled
+func emailIndex(email string) *string {
+	index, ok := fieldcrypt.BlindIndex(email)
+	if !ok {
+		return nil
+	}
+	return &index
+}
+
+// filtered returns a users query restricted by filter
+func (r *userRepository) filtered(ctx context.Context, filter UserFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Query != "" {
+		pattern := "%" + strings.ToLower(filter.Query) + "%"
+		if index, ok := fieldcrypt.BlindIndex(filter.Query); ok {
+			// Encrypted columns can only be matched exactly through the blind index,
+			// except in rows written before encryption was enabled
+			query = query.Where(
+				"LOWER(username) LIKE ? OR email_index = ? OR (email_index IS NULL AND (LOWER(email) LIKE ? OR LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?))",
+				pattern, index, pattern, pattern, pattern)
+		} else {
+			query = query.Where(
+				"LOWER(username) LIKE ? OR LOWER(email) LIKE ? OR LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?",
+				pattern, pattern, pattern, pattern)
+		}
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.IsAdmin != nil {
+		query = query.Where("is_admin = ?", *filter.IsAdmin)
+	}
+	return query
+}
+
+// SetActive activates or deactivates a user and returns the updated user
+func (r *userRepository) SetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	return r.updateFlag(ctx, id, "is_active", active)
+}
+
+// SetAdmin grants or revokes the admin role and returns the updated user
+func (r *userRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error) {
+	return r.updateFlag(ctx, id, "is_admin", isAdmin)
+}
+
+// updateFlag updates a boolean column explicitly, since Updates skips false values
+func (r *userRepository) updateFlag(ctx context.Context, id, column string, value bool) (*models.User, error) {
+	var rowsAffected int64
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		result := db.Model(&models.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{column: value, "updated_at": time.Now()})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", column, err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	var user models.User
+	err = r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Where("id = ?", id).First(&user).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if r.cache != nil {
+		r.invalidateUserCache(ctx, &user)
+	}
+
+	return &user, nil
+}
+
 // ExistsByEmail checks if a user exists by email
-func (r *userRepository) ExistsByEmail(email string) (bool, error) {
+func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	// Try cache first if available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("user:exists:email:%s", email)
 
-		if cachedValue, found := r.cache.Get(ctx, cacheKey); found {
-			if exists, ok := cachedValue.(bool); ok {
-				return exists, nil
-			}
+		if exists, found := cache.GetAs[bool](ctx, r.cache, cacheKey); found {
+			return exists, nil
 		}
 	}
 
 	// Cache miss or no cache available, get from database
 	var count int64
-	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return whereEmail(db.Model(&models.User{}), email).Count(&count).Error
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to check if user exists by email: %w", err)
 	}
@@ -306,7 +721,6 @@ func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 
 	// Cache the result if cache is available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("user:exists:email:%s", email)
 		if err := r.cache.Set(ctx, cacheKey, exists, r.ttl); err != nil {
 			// Log error but don't fail the operation
@@ -317,22 +731,21 @@ func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 }
 
 // ExistsByUsername checks if a user exists by username
-func (r *userRepository) ExistsByUsername(username string) (bool, error) {
+func (r *userRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	// Try cache first if available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("user:exists:username:%s", username)
 
-		if cachedValue, found := r.cache.Get(ctx, cacheKey); found {
-			if exists, ok := cachedValue.(bool); ok {
-				return exists, nil
-			}
+		if exists, found := cache.GetAs[bool](ctx, r.cache, cacheKey); found {
+			return exists, nil
 		}
 	}
 
 	// Cache miss or no cache available, get from database
 	var count int64
-	err := r.db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to check if user exists by username: %w", err)
 	}
@@ -340,7 +753,6 @@ func (r *userRepository) ExistsByUsername(username string) (bool, error) {
 
 	// Cache the result if cache is available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := fmt.Sprintf("user:exists:username:%s", username)
 		if err := r.cache.Set(ctx, cacheKey, exists, r.ttl); err != nil {
 			// Log error but don't fail the operation
@@ -351,28 +763,27 @@ func (r *userRepository) ExistsByUsername(username string) (bool, error) {
 }
 
 // Count returns the total number of active users
-func (r *userRepository) Count() (int64, error) {
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
 
 	// Try cache first if available
 	if r.cache != nil {
-		ctx := context.Background()
 		cacheKey := "users:count"
 
-		if cachedValue, found := r.cache.Get(ctx, cacheKey); found {
-			if cachedCount, ok := cachedValue.(int64); ok {
-				return cachedCount, nil
-			}
+		if cachedCount, found := cache.GetAs[int64](ctx, r.cache, cacheKey); found {
+			return cachedCount, nil
 		}
 
 		// Cache miss, get from database
-		err := r.db.Model(&models.User{}).Where("is_active = ?", true).Count(&count).Error
+		err := r.withRetry(ctx, func(db *gorm.DB) error {
+			return db.Model(&models.User{}).Where("is_active = ?", true).Count(&count).Error
+		})
 		if err != nil {
 			return 0, fmt.Errorf("failed to count users: %w", err)
 		}
 
 		// Cache the result
-		if err := r.cache.Set(ctx, cacheKey, count, r.ttl); err != nil {
+		if err := r.cache.SetWithTags(ctx, cacheKey, count, r.ttl, UserListCacheTag); err != nil {
 			// Log error but don't fail the operation
 		}
 
@@ -380,7 +791,9 @@ func (r *userRepository) Count() (int64, error) {
 	}
 
 	// No cache available, get from database directly
-	err := r.db.Model(&models.User{}).Where("is_active = ?", true).Count(&count).Error
+	err := r.withRetry(ctx, func(db *gorm.DB) error {
+		return db.Model(&models.User{}).Where("is_active = ?", true).Count(&count).Error
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -405,12 +818,11 @@ func (r *userRepository) generateUserCacheKeys(user *models.User) []string {
 }
 
 // invalidateUserCache invalidates all cache entries related to a user
-func (r *userRepository) invalidateUserCache(user *models.User) {
+func (r *userRepository) invalidateUserCache(ctx context.Context, user *models.User) {
 	if r.cache == nil || user == nil {
 		return
 	}
 
-	ctx := context.Background()
 	keys := r.generateUserCacheKeys(user)
 
 	// Delete user-specific cache keys
@@ -423,13 +835,11 @@ func (r *userRepository) invalidateUserCache(user *models.User) {
 }
 
 // invalidateUserCacheByID invalidates cache entries by user ID
-func (r *userRepository) invalidateUserCacheByID(id string) {
+func (r *userRepository) invalidateUserCacheByID(ctx context.Context, id string) {
 	if r.cache == nil || id == "" {
 		return
 	}
 
-	ctx := context.Background()
-
 	// Delete user-specific cache by ID
 	if err := r.cache.Delete(ctx, fmt.Sprintf("user:id:%s", id)); err != nil {
 		// Log error but don't fail the operation
@@ -445,97 +855,34 @@ func (r *userRepository) invalidateUserListCaches(ctx context.Context) {
 		return
 	}
 
-	// Invalidate list caches with common patterns
-	patterns := []string{"users:all:*", "users:count"}
-
-	for _, pattern := range patterns {
-		keys, err := r.cache.Keys(ctx, pattern)
-		if err != nil {
-			// Log error but continue
-			continue
-		}
-
-		if len(keys) > 0 {
-			if err := r.cache.DeleteMultiple(ctx, keys); err != nil {
-				// Log error but continue
-			}
-		}
+	// Invalidate list and count caches via their tag
+	if err := r.cache.InvalidateTag(ctx, UserListCacheTag); err != nil {
+		// Log error but continue
 	}
 }
 
 // getUserFromCache attempts to get a user from cache
-func (r *userRepository) getUserFromCache(cacheKey string) (*models.User, bool) {
+func (r *userRepository) getUserFromCache(ctx context.Context, cacheKey string) (*models.User, bool) {
 	if r.cache == nil {
 		return nil, false
 	}
 
-	ctx := context.Background()
-	cachedValue, found := r.cache.Get(ctx, cacheKey)
-	if !found {
+	entry, found := cache.GetAs[cachedUser](ctx, r.cache, cacheKey)
+	if !found || entry.Version != cacheEntryVersion {
 		return nil, false
 	}
 
-	return r.unmarshalUser(cachedValue)
+	return entry.User.toModel(), true
 }
 
 // setUserCache sets a user in cache
-func (r *userRepository) setUserCache(cacheKey string, user *models.User) {
+func (r *userRepository) setUserCache(ctx context.Context, cacheKey string, user *models.User) {
 	if r.cache == nil || user == nil {
 		return
 	}
 
-	ctx := context.Background()
-	if err := r.cache.Set(ctx, cacheKey, user, r.ttl); err != nil {
+	entry := cachedUser{Version: cacheEntryVersion, User: newUserRecord(user)}
+	if err := r.cache.Set(ctx, cacheKey, entry, r.ttl); err != nil {
 		// Log error but don't fail the operation
 	}
 }
-
-// unmarshalUser attempts to unmarshal a cached value to a User model
-func (r *userRepository) unmarshalUser(value interface{}) (*models.User, bool) {
-	if value == nil {
-		return nil, false
-	}
-
-	// Try to unmarshal as JSON
-	switch v := value.(type) {
-	case []byte:
-		var user models.User
-		if err := json.Unmarshal(v, &user); err == nil {
-			return &user, true
-		}
-	case string:
-		var user models.User
-		if err := json.Unmarshal([]byte(v), &user); err == nil {
-			return &user, true
-		}
-	case *models.User:
-		return v, true
-	}
-
-	return nil, false
-}
-
-// unmarshalUserListResult attempts to unmarshal a cached value to a UserListResult
-func (r *userRepository) unmarshalUserListResult(value interface{}) (UserListResult, bool) {
-	var result UserListResult
-
-	if value == nil {
-		return result, false
-	}
-
-	// Try to unmarshal as JSON
-	switch v := value.(type) {
-	case []byte:
-		if err := json.Unmarshal(v, &result); err == nil {
-			return result, true
-		}
-	case string:
-		if err := json.Unmarshal([]byte(v), &result); err == nil {
-			return result, true
-		}
-	case UserListResult:
-		return v, true
-	}
-
-	return result, false
-}
@@ -72,7 +72,7 @@ func (r *userRepositoryV2) GetByID(id string) (*models.User, error) {
 	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -98,10 +98,10 @@ func (r *userRepositoryV2) GetByEmail(email string) (*models.User, error) {
 
 	// 从数据库获取
 	var user models.User
-	err := r.db.Where("email = ? AND is_active = ?", email, true).First(&user).Error
+	err := whereEmail(r.db, email).Where("is_active = ?", true).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -130,7 +130,7 @@ func (r *userRepositoryV2) GetByUsername(username string) (*models.User, error)
 	err := r.db.Where("username = ? AND is_active = ?", username, true).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -189,7 +189,7 @@ func (r *userRepositoryV2) Update(user *models.User) error {
 		return fmt.Errorf("failed to update user: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	// 使用统一的缓存管理器失效用户相关缓存
@@ -207,7 +207,7 @@ func (r *userRepositoryV2) Delete(id string) error {
 		return fmt.Errorf("failed to delete user: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	// 使用统一的缓存管理器失效用户相关缓存
@@ -225,7 +225,7 @@ func (r *userRepositoryV2) UpdateLastLogin(id string) error {
 		return fmt.Errorf("failed to update last login: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	// 使用统一的缓存管理器失效用户相关缓存
@@ -239,7 +239,7 @@ func (r *userRepositoryV2) UpdateLastLogin(id string) error {
 // ExistsByEmail 检查邮箱是否存在
 func (r *userRepositoryV2) ExistsByEmail(email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	err := whereEmail(r.db.Model(&models.User{}), email).Count(&count).Error
 	if err != nil {
 		return false, fmt.Errorf("failed to check if email exists: %w", err)
 	}
@@ -0,0 +1,308 @@
+// Package retention 按保留策略定期清理过期数据
+//
+// 每张表对应一条策略：保留期限和清理目标（Target）。后台任务按间隔执行清理，删除早于"当前时间 - 保留期限"的行，
+// 每次最多删除一批，直到没有可删除的行；每张表的清理结果记录一条审计事件。
+// 试运行（dry run）只统计可删除的行数，不删除数据也不记录审计事件，可用于上线前核对策略。
+//
+// 清理在每个实例上独立执行，删除是幂等的，多个实例同时清理同一张表只会使部分批次删除 0 行。
+package retention
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-server/internal/audit"
+)
+
+// 默认配置
+const (
+	DefaultInterval  = time.Hour
+	DefaultBatchSize = 1000
+)
+
+// 支持清理的表
+const (
+	TableUsers            = "users"             // 软删除超过宽限期的用户
+	TableMetricsSnapshots = "metrics_snapshots" // 所有实例和精度的过期指标快照
+)
+
+// Tables 支持配置保留策略的表
+var Tables = []string{TableUsers, TableMetricsSnapshots}
+
+// ErrRunning 清理正在执行
+var ErrRunning = errors.New("retention run already in progress")
+
+// Target 一张表的清理实现
+type Target interface {
+	// Count 返回早于 before 的可删除行数，用于试运行
+	Count(ctx context.Context, before time.Time) (int64, error)
+	// Purge 删除最多 limit 行早于 before 的数据，返回删除的行数
+	Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Policy 一张表的保留策略
+type Policy struct {
+	Table     string        // 表名，用于报告和审计事件
+	Retention time.Duration // 保留期限，更早的行被删除
+	Target    Target
+}
+
+// Config 清理任务配置
+type Config struct {
+	// Interval 定期清理的间隔，<=0 时使用 DefaultInterval
+	Interval time.Duration
+	// BatchSize 每批删除的行数，<=0 时使用 DefaultBatchSize
+	BatchSize int
+	// DryRun 定期清理只统计可删除的行数，不删除数据
+	DryRun bool
+	// OnReport 每次定期清理结束后调用，为 nil 时忽略
+	OnReport func(report *Report)
+}
+
+// RunOptions 一次清理的选项
+type RunOptions struct {
+	DryRun  bool   // 只统计可删除的行数，不删除数据
+	ActorID string // 手动触发清理的管理员ID，定期清理时为空
+}
+
+// Result 一张表的清理结果
+type Result struct {
+	Table     string    `json:"table"`
+	Retention string    `json:"retention"`       // 保留期限，如 720h0m0s
+	Cutoff    time.Time `json:"cutoff"`          // 早于该时间的行被删除
+	Rows      int64     `json:"rows"`            // 删除的行数，试运行时为可删除的行数
+	Error     string    `json:"error,omitempty"` // 清理失败的原因，失败前已删除的行数计入 Rows
+}
+
+// Report 一次清理的报告
+type Report struct {
+	DryRun     bool      `json:"dry_run"`
+	ActorID    string    `json:"actor_id,omitempty"` // 手动触发清理的管理员ID
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Results    []Result  `json:"results"`
+}
+
+// Failed 判断是否有表清理失败
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 保留策略和最近一次清理的报告
+type Status struct {
+	Interval   string         `json:"interval"` // 定期清理的间隔，如 1h0m0s
+	DryRun     bool           `json:"dry_run"`  // 定期清理是否为试运行
+	Policies   []PolicyStatus `json:"policies"`
+	LastReport *Report        `json:"last_report,omitempty"` // 最近一次清理（定期或手动）的报告，本实例尚未清理时为空
+}
+
+// PolicyStatus 一张表的保留策略
+type PolicyStatus struct {
+	Table     string `json:"table"`
+	Retention string `json:"retention"`
+}
+
+// Engine 按保留策略清理过期数据
+type Engine struct {
+	policies []Policy
+	audit    audit.Recorder
+	config   Config
+	now      func() time.Time
+
+	running sync.Mutex // 同一时间只执行一次清理
+
+	mu         sync.Mutex
+	lastReport *Report
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewEngine 创建清理任务，recorder 为 nil 时不记录审计事件，调用 Start 后开始定期清理
+func NewEngine(policies []Policy, recorder audit.Recorder, config Config) *Engine {
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	return &Engine{
+		policies: append([]Policy(nil), policies...),
+		audit:    recorder,
+		config:   config,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 启动定期清理，第一次清理在一个间隔之后执行，避免与启动时的其他任务争用数据库
+func (e *Engine) Start() {
+	e.startOnce.Do(func() {
+		go e.run()
+	})
+}
+
+// Stop 停止定期清理，等待正在执行的清理结束
+func (e *Engine) Stop(ctx context.Context) error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	e.startOnce.Do(func() {
+		close(e.done)
+	})
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Engine) run() {
+	defer close(e.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-e.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		report, err := e.Run(ctx, RunOptions{DryRun: e.config.DryRun})
+		if err != nil || ctx.Err() != nil {
+			// 管理员手动触发的清理仍在执行，或正在停止
+			continue
+		}
+		if e.config.OnReport != nil {
+			e.config.OnReport(report)
+		}
+	}
+}
+
+// Run 按所有策略执行一次清理，返回报告；一张表清理失败时继续清理其余的表，错误记录在报告中
+// 已有清理正在执行时返回 ErrRunning
+func (e *Engine) Run(ctx context.Context, opts RunOptions) (*Report, error) {
+	if !e.running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer e.running.Unlock()
+
+	report := &Report{
+		DryRun:    opts.DryRun,
+		ActorID:   opts.ActorID,
+		StartedAt: e.now().UTC(),
+		Results:   make([]Result, 0, len(e.policies)),
+	}
+	for _, policy := range e.policies {
+		result := e.apply(ctx, policy, opts.DryRun)
+		if !opts.DryRun {
+			e.record(ctx, result, opts.ActorID)
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.FinishedAt = e.now().UTC()
+
+	e.mu.Lock()
+	e.lastReport = report
+	e.mu.Unlock()
+	return report, nil
+}
+
+// apply 按一条策略统计或删除过期的行
+func (e *Engine) apply(ctx context.Context, policy Policy, dryRun bool) Result {
+	result := Result{
+		Table:     policy.Table,
+		Retention: policy.Retention.String(),
+		Cutoff:    e.now().Add(-policy.Retention).UTC(),
+	}
+
+	if dryRun {
+		count, err := policy.Target.Count(ctx, result.Cutoff)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Rows = count
+		return result
+	}
+
+	for {
+		deleted, err := policy.Target.Purge(ctx, result.Cutoff, e.config.BatchSize)
+		result.Rows += deleted
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if deleted < int64(e.config.BatchSize) {
+			return result
+		}
+		if err := ctx.Err(); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+}
+
+// record 记录一张表的清理结果，没有删除任何行且没有失败时不记录
+func (e *Engine) record(ctx context.Context, result Result, actorID string) {
+	if result.Rows == 0 && result.Error == "" {
+		return
+	}
+	event := audit.Event{
+		Action:  audit.ActionRetentionPurged,
+		ActorID: actorID,
+		Outcome: audit.OutcomeSuccess,
+		Details: map[string]interface{}{
+			"table":     result.Table,
+			"retention": result.Retention,
+			"cutoff":    result.Cutoff,
+			"rows":      result.Rows,
+		},
+	}
+	if result.Error != "" {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = result.Error
+	}
+	e.audit.Record(ctx, event)
+}
+
+// Status 返回保留策略和最近一次清理的报告
+func (e *Engine) Status() Status {
+	status := Status{
+		Interval: e.config.Interval.String(),
+		DryRun:   e.config.DryRun,
+		Policies: make([]PolicyStatus, 0, len(e.policies)),
+	}
+	for _, policy := range e.policies {
+		status.Policies = append(status.Policies, PolicyStatus{Table: policy.Table, Retention: policy.Retention.String()})
+	}
+
+	e.mu.Lock()
+	status.LastReport = e.lastReport
+	e.mu.Unlock()
+	return status
+}
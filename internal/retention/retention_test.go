@@ -0,0 +1,202 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/internal/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTarget 按时间保存行的清理目标
+type memoryTarget struct {
+	mu      sync.Mutex
+	rows    []time.Time
+	batches int
+	err     error // 第二批起返回的错误
+}
+
+func (t *memoryTarget) Count(ctx context.Context, before time.Time) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var count int64
+	for _, row := range t.rows {
+		if row.Before(before) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (t *memoryTarget) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.batches++
+	if t.err != nil && t.batches > 1 {
+		return 0, t.err
+	}
+	var kept []time.Time
+	var deleted int64
+	for _, row := range t.rows {
+		if row.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	t.rows = kept
+	return deleted, nil
+}
+
+// recordingAuditor 保存审计事件
+type recordingAuditor struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (r *recordingAuditor) Record(ctx context.Context, event audit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// rowsAged 返回距 now 分别为 ages 天的行
+func rowsAged(now time.Time, ages ...int) []time.Time {
+	rows := make([]time.Time, 0, len(ages))
+	for _, age := range ages {
+		rows = append(rows, now.Add(-time.Duration(age)*24*time.Hour))
+	}
+	return rows
+}
+
+func newTestEngine(now time.Time, auditor audit.Recorder, policies ...Policy) *Engine {
+	engine := NewEngine(policies, auditor, Config{BatchSize: 2})
+	engine.now = func() time.Time { return now }
+	return engine
+}
+
+func TestEngineDryRun(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	users := &memoryTarget{rows: rowsAged(now, 1, 31, 45, 60)}
+	auditor := &recordingAuditor{}
+	engine := newTestEngine(now, auditor, Policy{Table: TableUsers, Retention: 30 * 24 * time.Hour, Target: users})
+
+	report, err := engine.Run(context.Background(), RunOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, int64(3), report.Results[0].Rows)
+	assert.Equal(t, now.Add(-30*24*time.Hour), report.Results[0].Cutoff)
+
+	// 试运行不删除数据，也不记录审计事件
+	assert.Len(t, users.rows, 4)
+	assert.Empty(t, auditor.events)
+	assert.Same(t, report, engine.Status().LastReport)
+}
+
+func TestEnginePurgeInBatches(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	users := &memoryTarget{rows: rowsAged(now, 1, 31, 45, 60, 90)}
+	snapshots := &memoryTarget{rows: rowsAged(now, 1, 2)}
+	auditor := &recordingAuditor{}
+	engine := newTestEngine(now, auditor,
+		Policy{Table: TableUsers, Retention: 30 * 24 * time.Hour, Target: users},
+		Policy{Table: TableMetricsSnapshots, Retention: 90 * 24 * time.Hour, Target: snapshots},
+	)
+
+	report, err := engine.Run(context.Background(), RunOptions{ActorID: "admin-1"})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, int64(4), report.Results[0].Rows)
+	assert.Equal(t, int64(0), report.Results[1].Rows)
+	assert.False(t, report.Failed())
+	assert.Len(t, users.rows, 1)
+	// 4 行按每批 2 行删除，第三批删除 0 行后结束
+	assert.Equal(t, 3, users.batches)
+
+	// 没有删除任何行的表不记录审计事件
+	require.Len(t, auditor.events, 1)
+	event := auditor.events[0]
+	assert.Equal(t, audit.ActionRetentionPurged, event.Action)
+	assert.Equal(t, "admin-1", event.ActorID)
+	assert.Equal(t, audit.OutcomeSuccess, event.Outcome)
+	assert.Equal(t, TableUsers, event.Details["table"])
+	assert.Equal(t, int64(4), event.Details["rows"])
+}
+
+func TestEnginePurgeFailure(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	users := &memoryTarget{rows: rowsAged(now, 31, 32, 33), err: errors.New("database unavailable")}
+	snapshots := &memoryTarget{rows: rowsAged(now, 100)}
+	auditor := &recordingAuditor{}
+	engine := newTestEngine(now, auditor,
+		Policy{Table: TableUsers, Retention: 30 * 24 * time.Hour, Target: users},
+		Policy{Table: TableMetricsSnapshots, Retention: 90 * 24 * time.Hour, Target: snapshots},
+	)
+
+	report, err := engine.Run(context.Background(), RunOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Failed())
+	// 失败前删除的行计入结果，其余的表继续清理
+	assert.Equal(t, int64(2), report.Results[0].Rows)
+	assert.Equal(t, "database unavailable", report.Results[0].Error)
+	assert.Equal(t, int64(1), report.Results[1].Rows)
+
+	require.Len(t, auditor.events, 2)
+	assert.Equal(t, audit.OutcomeFailure, auditor.events[0].Outcome)
+	assert.Equal(t, "database unavailable", auditor.events[0].Reason)
+	assert.Equal(t, audit.OutcomeSuccess, auditor.events[1].Outcome)
+}
+
+func TestEngineRunExclusive(t *testing.T) {
+	engine := NewEngine(nil, nil, Config{})
+	engine.running.Lock()
+	_, err := engine.Run(context.Background(), RunOptions{})
+	assert.ErrorIs(t, err, ErrRunning)
+	engine.running.Unlock()
+
+	_, err = engine.Run(context.Background(), RunOptions{})
+	assert.NoError(t, err)
+}
+
+func TestEngineScheduledRun(t *testing.T) {
+	now := time.Now()
+	users := &memoryTarget{rows: rowsAged(now, 31)}
+	reports := make(chan *Report, 1)
+	engine := NewEngine([]Policy{{Table: TableUsers, Retention: 30 * 24 * time.Hour, Target: users}}, nil, Config{
+		Interval: 10 * time.Millisecond,
+		DryRun:   true,
+		OnReport: func(report *Report) {
+			select {
+			case reports <- report:
+			default:
+			}
+		},
+	})
+	engine.Start()
+
+	select {
+	case report := <-reports:
+		assert.True(t, report.DryRun)
+		assert.Equal(t, int64(1), report.Results[0].Rows)
+	case <-time.After(time.Second):
+		t.Fatal("scheduled run did not happen")
+	}
+	require.NoError(t, engine.Stop(context.Background()))
+	assert.Len(t, users.rows, 1)
+
+	status := engine.Status()
+	assert.Equal(t, "10ms", status.Interval)
+	assert.True(t, status.DryRun)
+	assert.Equal(t, []PolicyStatus{{Table: TableUsers, Retention: "720h0m0s"}}, status.Policies)
+}
+
+func TestEngineStopWithoutStart(t *testing.T) {
+	engine := NewEngine(nil, nil, Config{})
+	require.NoError(t, engine.Stop(context.Background()))
+}
@@ -0,0 +1,45 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"go-server/internal/repositories"
+)
+
+// deletedUsers 软删除超过宽限期的用户，删除后通知、订阅等引用该用户的行随之级联删除
+type deletedUsers struct {
+	users repositories.UserPurger
+}
+
+// DeletedUsers 返回清理软删除用户的目标，保留期限即账户删除后可恢复的宽限期
+func DeletedUsers(users repositories.UserPurger) Target {
+	return deletedUsers{users: users}
+}
+
+func (t deletedUsers) Count(ctx context.Context, before time.Time) (int64, error) {
+	return t.users.CountDeletedBefore(ctx, before)
+}
+
+func (t deletedUsers) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return t.users.PurgeDeletedBefore(ctx, before, limit)
+}
+
+// metricsSnapshots 所有实例和精度的过期指标快照
+type metricsSnapshots struct {
+	snapshots repositories.MetricsSnapshotPurger
+}
+
+// MetricsSnapshots 返回清理指标快照的目标
+// 指标快照任务只清理本实例当前配置的精度，已下线的实例、修改前的精度以及停用快照后遗留的行由该目标清理
+func MetricsSnapshots(snapshots repositories.MetricsSnapshotPurger) Target {
+	return metricsSnapshots{snapshots: snapshots}
+}
+
+func (t metricsSnapshots) Count(ctx context.Context, before time.Time) (int64, error) {
+	return t.snapshots.CountAllBefore(ctx, before)
+}
+
+func (t metricsSnapshots) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return t.snapshots.DeleteAllBefore(ctx, before, limit)
+}
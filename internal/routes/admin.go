@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupAdminRoutes() {
+	adminGroup := r.engine.Group("/api/v1/admin")
+	adminGroup.Use(middleware.AuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList))
+	adminGroup.Use(middleware.AdminOnlyMiddleware(r.userRepository))
+	{
+		// System overview for dashboards
+		adminGroup.GET("/overview", r.coalescer.Middleware(), r.overviewHandler.Overview)
+
+		// Runtime metrics
+		adminGroup.GET("/metrics/http", r.metricsHandler.HTTPMetrics)
+		adminGroup.GET("/metrics/history", r.coalescer.Middleware(), r.metricsHandler.History)
+		adminGroup.GET("/metrics/rate-limit", r.coalescer.Middleware(), r.metricsHandler.RateLimitTimeSeries)
+		adminGroup.GET("/metrics/countries", r.coalescer.Middleware(), r.metricsHandler.Countries)
+		adminGroup.GET("/metrics/queries", r.metricsHandler.QueryOffenders)
+
+		// User management
+		adminGroup.GET("/users", r.coalescer.Middleware(), r.adminUserHandler.ListUsers)
+		adminGroup.GET("/users/stream", r.adminUserHandler.StreamUsers)
+		adminGroup.POST("/users/:id/activate", r.adminUserHandler.ActivateUser)
+		adminGroup.POST("/users/:id/deactivate", r.adminUserHandler.DeactivateUser)
+		adminGroup.POST("/users/:id/password-reset", r.adminUserHandler.ResetPassword)
+		adminGroup.PUT("/users/:id/roles", r.adminUserHandler.AssignRoles)
+		adminGroup.POST("/users/:id/impersonate", r.adminUserHandler.Impersonate)
+		adminGroup.POST("/users/:id/erasure", r.privacyHandler.EraseUser)
+
+		// Runtime log level management
+		adminGroup.GET("/logging/level", r.loggingHandler.GetLevels)
+		adminGroup.PUT("/logging/level", r.loggingHandler.UpdateLevel)
+
+		// Maintenance mode
+		adminGroup.GET("/maintenance", r.maintenanceHandler.GetMaintenance)
+		adminGroup.PUT("/maintenance", r.maintenanceHandler.EnableMaintenance)
+		adminGroup.DELETE("/maintenance", r.maintenanceHandler.DisableMaintenance)
+
+		// Instance draining before deploys
+		adminGroup.GET("/drain", r.drainHandler.GetDrain)
+		adminGroup.POST("/drain", r.drainHandler.StartDrain)
+
+		// Data retention policies
+		adminGroup.GET("/retention", r.retentionHandler.GetRetention)
+		adminGroup.POST("/retention/run", r.retentionHandler.RunRetention)
+
+		// Database backups and restores
+		adminGroup.GET("/backups", r.backupHandler.ListBackups)
+		adminGroup.POST("/backups", r.backupHandler.CreateBackup)
+		adminGroup.GET("/backups/:id", r.backupHandler.GetBackup)
+		adminGroup.GET("/backups/:id/download", r.backupHandler.DownloadBackup)
+		adminGroup.POST("/backups/:id/restore", r.backupHandler.RestoreBackup)
+		adminGroup.GET("/restore", r.backupHandler.GetRestoreStatus)
+
+		// Cache inspection and maintenance
+		adminGroup.GET("/cache/stats", r.cacheHandler.GetStats)
+		adminGroup.GET("/cache/keys", r.cacheHandler.ListKeys)
+		adminGroup.DELETE("/cache/keys/:key", r.cacheHandler.DeleteKey)
+		adminGroup.POST("/cache/flush", r.cacheHandler.Flush)
+
+		// Cache warm-up
+		adminGroup.POST("/cache/warm", r.cacheHandler.Warm)
+		adminGroup.GET("/cache/warm", r.cacheHandler.WarmStatus)
+
+		// Feature flags
+		adminGroup.GET("/feature-flags", r.featureFlagHandler.ListFeatureFlags)
+		adminGroup.POST("/feature-flags", r.featureFlagHandler.CreateFeatureFlag)
+		adminGroup.GET("/feature-flags/:key", r.featureFlagHandler.GetFeatureFlag)
+		adminGroup.PUT("/feature-flags/:key", r.featureFlagHandler.UpdateFeatureFlag)
+		adminGroup.DELETE("/feature-flags/:key", r.featureFlagHandler.DeleteFeatureFlag)
+
+		// Runtime rate limit tuning
+		adminGroup.GET("/rate-limits", r.rateLimitHandler.GetRateLimits)
+		adminGroup.PUT("/rate-limits", r.rateLimitHandler.UpdateRateLimits)
+
+		// Rate limit auto-ban list
+		adminGroup.GET("/bans", r.banListHandler.ListBans)
+		adminGroup.DELETE("/bans/:type/:identifier", r.banListHandler.Unban)
+
+		// Announcements
+		adminGroup.GET("/announcements", r.announcementHandler.AdminListAnnouncements)
+		adminGroup.POST("/announcements", r.announcementHandler.CreateAnnouncement)
+		adminGroup.GET("/announcements/:id", r.announcementHandler.AdminGetAnnouncement)
+		adminGroup.PUT("/announcements/:id", r.announcementHandler.UpdateAnnouncement)
+		adminGroup.DELETE("/announcements/:id", r.announcementHandler.DeleteAnnouncement)
+
+		// User data exports
+		adminGroup.POST("/exports", r.exportHandler.CreateExport)
+		adminGroup.GET("/exports/:id", r.exportHandler.GetExport)
+
+		// Bulk user imports
+		adminGroup.POST("/imports", r.importHandler.ImportUsers)
+
+		// OpenID Connect clients
+		adminGroup.GET("/oauth-clients", r.oidcHandler.ListClients)
+		adminGroup.POST("/oauth-clients", r.oidcHandler.CreateClient)
+		adminGroup.DELETE("/oauth-clients/:id", r.oidcHandler.DeleteClient)
+	}
+
+	// Impersonation sessions are ended with the impersonation token itself,
+	// whose subject is never an admin, so this route skips AdminOnlyMiddleware
+	impersonationGroup := r.engine.Group("/api/v1/admin/impersonation")
+	impersonationGroup.Use(middleware.AuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList))
+	{
+		impersonationGroup.POST("/stop", r.adminUserHandler.StopImpersonation)
+	}
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupAnnouncementRoutes() {
+	// Announcements are visible to anonymous clients too; a valid token adds role and tenant targeting
+	announcementGroup := r.engine.Group("/api/v1/announcements")
+	announcementGroup.Use(middleware.OptionalAuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList))
+	{
+		announcementGroup.GET("", r.announcementHandler.ListAnnouncements)
+		announcementGroup.GET("/stream", r.announcementHandler.StreamAnnouncements)
+	}
+}
@@ -4,25 +4,30 @@ import (
 	"go-server/internal/handlers"
 	"go-server/internal/middleware"
 	"go-server/pkg/auth"
+	"go-server/pkg/banlist"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, jwtManager *auth.JWTManager) {
-	// Swagger documentation
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, jwtManager *auth.JWTManager, banList *banlist.BanList) {
+	// Swagger UI rendering the generated OpenAPI document
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/openapi.json")))
+
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	authGroup := router.Group("/api/v1/auth")
 	{
 		authGroup.POST("/login", authHandler.Login)
 		authGroup.POST("/register", authHandler.Register)
+		authGroup.POST("/refresh", authHandler.Refresh)
 		authGroup.POST("/change-password", authHandler.ChangePassword)
 
 		// Protected routes
 		protected := authGroup.Group("")
-		protected.Use(middleware.AuthMiddleware(jwtManager))
+		protected.Use(middleware.AuthMiddleware(jwtManager), middleware.BanListMiddleware(banList))
 		{
 			protected.GET("/me", authHandler.Me)
 			protected.POST("/logout", authHandler.Logout)
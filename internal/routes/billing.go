@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupBillingRoutes() {
+	billingGroup := r.engine.Group("/api/v1/billing")
+	{
+		billingGroup.GET("/plans", r.billingHandler.ListPlans)
+		// Stripe authenticates with the Stripe-Signature header instead of a bearer token
+		billingGroup.POST("/webhooks/stripe", r.billingHandler.StripeWebhook)
+	}
+
+	// Kept outside the users group so that checking the subscription never consumes quota
+	subscriptionGroup := r.engine.Group("/api/v1/users/me/subscription")
+	subscriptionGroup.Use(middleware.AuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList))
+	{
+		subscriptionGroup.GET("", r.billingHandler.GetMySubscription)
+	}
+}
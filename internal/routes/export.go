@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupExportRoutes() {
+	// Download links are opened directly by browsers, so the signature in the query string is the only credential
+	exportGroup := r.engine.Group("/api/v1/exports")
+	exportGroup.Use(middleware.BanListMiddleware(r.banList))
+	{
+		exportGroup.GET("/:id/download", r.exportHandler.DownloadExport)
+	}
+}
@@ -7,7 +7,13 @@ import (
 )
 
 func SetupHealthRoutes(router *gin.Engine, healthHandler *handlers.HealthHandler) {
+	// Kubernetes 探针
+	router.GET("/healthz", healthHandler.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
+
 	router.GET("/api/v1/health", healthHandler.Health)
-	router.GET("/api/v1/ready", healthHandler.Ready)
-	router.GET("/api/v1/live", healthHandler.Live)
+	router.GET("/api/v1/ready", healthHandler.Readyz)
+	router.GET("/api/v1/live", healthHandler.Healthz)
+	router.GET("/api/v1/metrics", healthHandler.Metrics)
+	router.GET("/api/v1/metrics/prometheus", healthHandler.Prometheus)
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"go-server/internal/handlers"
+	"go-server/internal/middleware"
+	"go-server/pkg/mtls"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInternalRoutes 注册供内部服务调用的路由，只在启用 mTLS 时注册
+// 调用方通过客户端证书认证，只有经过 mTLS 监听器的请求带有证书身份，其他端口的请求返回 401
+func SetupInternalRoutes(router *gin.Engine, authorizer *mtls.Authorizer, userHandler *handlers.UserHandler) {
+	internalGroup := router.Group("/internal/v1")
+	internalGroup.Use(middleware.ServiceAuthMiddleware(authorizer))
+	{
+		internalGroup.GET("/users/:id", middleware.RequireServicePermission("users:read"), userHandler.GetUser)
+	}
+}
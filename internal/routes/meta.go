@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"go-server/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMetaRoutes(router *gin.Engine, metaHandler *handlers.MetaHandler) {
+	// Generated OpenAPI 3.1 document
+	router.GET("/openapi.json", metaHandler.OpenAPISpec)
+
+	metaGroup := router.Group("/api/v1/meta")
+	{
+		// Machine-readable error code registry
+		metaGroup.GET("/errors", metaHandler.ListErrorCodes)
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupOAuthRoutes() {
+	r.engine.GET("/.well-known/openid-configuration", r.oidcHandler.Discovery)
+
+	oauthGroup := r.engine.Group("/api/v1/oauth")
+	oauthGroup.Use(middleware.BanListMiddleware(r.banList))
+	{
+		// Relying parties authenticate to the token endpoint with their client credentials, not a user token
+		oauthGroup.POST("/token", r.oidcHandler.Token)
+
+		// The consent page and relying parties call these with the user's access token
+		userGroup := oauthGroup.Group("")
+		userGroup.Use(middleware.AuthMiddleware(r.jwtManager))
+		{
+			userGroup.GET("/authorize", r.oidcHandler.Authorize)
+			userGroup.POST("/authorize", r.oidcHandler.Approve)
+			userGroup.GET("/userinfo", r.oidcHandler.UserInfo)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupPasswordlessRoutes() {
+	authGroup := r.engine.Group("/api/v1/auth")
+	authGroup.Use(middleware.BanListMiddleware(r.banList))
+	{
+		// Devices and email recipients are not signed in yet
+		authGroup.POST("/device/code", r.passwordlessHandler.StartDevice)
+		authGroup.POST("/device/token", r.passwordlessHandler.DeviceToken)
+		authGroup.POST("/magic-link", r.passwordlessHandler.RequestMagicLink)
+		authGroup.POST("/magic-link/verify", r.passwordlessHandler.RedeemMagicLink)
+
+		// The verification page approves a device with the signed-in user's token
+		authGroup.POST("/device/verify", middleware.AuthMiddleware(r.jwtManager), r.passwordlessHandler.VerifyDevice)
+	}
+}
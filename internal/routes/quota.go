@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"go-server/internal/middleware"
+)
+
+func (r *Router) SetupQuotaRoutes() {
+	// Kept outside the users group so that checking usage never consumes quota
+	quotaGroup := r.engine.Group("/api/v1/users/me/quota")
+	quotaGroup.Use(middleware.AuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList))
+	{
+		quotaGroup.GET("", r.quotaHandler.GetMyQuota)
+	}
+}
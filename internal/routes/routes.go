@@ -2,25 +2,86 @@ package routes
 
 import (
 	"go-server/internal/handlers"
+	"go-server/internal/middleware"
 	"go-server/internal/repositories"
 	"go-server/pkg/auth"
+	"go-server/pkg/banlist"
+	"go-server/pkg/quota"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
-	engine         *gin.Engine
-	authHandler    *handlers.AuthHandler
-	userHandler    *handlers.UserHandler
-	healthHandler  *handlers.HealthHandler
-	jwtManager     *auth.JWTManager
-	userRepository repositories.UserRepository
+	engine              *gin.Engine
+	authHandler         *handlers.AuthHandler
+	userHandler         *handlers.UserHandler
+	healthHandler       *handlers.HealthHandler
+	avatarHandler       *handlers.AvatarHandler
+	profileHandler      *handlers.ProfileHandler
+	adminUserHandler    *handlers.AdminUserHandler
+	overviewHandler     *handlers.AdminOverviewHandler
+	metricsHandler      *handlers.MetricsHandler
+	loggingHandler      *handlers.LoggingHandler
+	metaHandler         *handlers.MetaHandler
+	cacheHandler        *handlers.CacheHandler
+	featureFlagHandler  *handlers.FeatureFlagHandler
+	banListHandler      *handlers.BanListHandler
+	notificationHandler *handlers.NotificationHandler
+	announcementHandler *handlers.AnnouncementHandler
+	exportHandler       *handlers.ExportHandler
+	importHandler       *handlers.ImportHandler
+	privacyHandler      *handlers.PrivacyHandler
+	oidcHandler         *handlers.OIDCHandler
+	samlHandler         *handlers.SAMLHandler
+	passwordlessHandler *handlers.PasswordlessHandler
+	quotaHandler        *handlers.QuotaHandler
+	billingHandler      *handlers.BillingHandler
+	maintenanceHandler  *handlers.MaintenanceHandler
+	drainHandler        *handlers.DrainHandler
+	rateLimitHandler    *handlers.RateLimitHandler
+	retentionHandler    *handlers.RetentionHandler
+	backupHandler       *handlers.BackupHandler
+	responseCache       *middleware.ResponseCache
+	coalescer           *middleware.RequestCoalescer
+	banList             *banlist.BanList
+	quotaManager        *quota.Manager
+	jwtManager          *auth.JWTManager
+	userRepository      repositories.UserRepository
 }
 
 func NewRouter(
 	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
 	healthHandler *handlers.HealthHandler,
+	avatarHandler *handlers.AvatarHandler,
+	profileHandler *handlers.ProfileHandler,
+	adminUserHandler *handlers.AdminUserHandler,
+	overviewHandler *handlers.AdminOverviewHandler,
+	metricsHandler *handlers.MetricsHandler,
+	loggingHandler *handlers.LoggingHandler,
+	metaHandler *handlers.MetaHandler,
+	cacheHandler *handlers.CacheHandler,
+	featureFlagHandler *handlers.FeatureFlagHandler,
+	banListHandler *handlers.BanListHandler,
+	notificationHandler *handlers.NotificationHandler,
+	announcementHandler *handlers.AnnouncementHandler,
+	exportHandler *handlers.ExportHandler,
+	importHandler *handlers.ImportHandler,
+	privacyHandler *handlers.PrivacyHandler,
+	oidcHandler *handlers.OIDCHandler,
+	samlHandler *handlers.SAMLHandler,
+	passwordlessHandler *handlers.PasswordlessHandler,
+	quotaHandler *handlers.QuotaHandler,
+	billingHandler *handlers.BillingHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	drainHandler *handlers.DrainHandler,
+	rateLimitHandler *handlers.RateLimitHandler,
+	retentionHandler *handlers.RetentionHandler,
+	backupHandler *handlers.BackupHandler,
+	responseCache *middleware.ResponseCache,
+	coalescer *middleware.RequestCoalescer,
+	banList *banlist.BanList,
+	quotaManager *quota.Manager,
 	jwtManager *auth.JWTManager,
 	userRepository repositories.UserRepository,
 	middlewares []gin.HandlerFunc,
@@ -40,12 +101,41 @@ func NewRouter(
 	engine.Use(middlewares...)
 
 	return &Router{
-		engine:         engine,
-		authHandler:    authHandler,
-		userHandler:    userHandler,
-		healthHandler:  healthHandler,
-		jwtManager:     jwtManager,
-		userRepository: userRepository,
+		engine:              engine,
+		authHandler:         authHandler,
+		userHandler:         userHandler,
+		healthHandler:       healthHandler,
+		avatarHandler:       avatarHandler,
+		profileHandler:      profileHandler,
+		adminUserHandler:    adminUserHandler,
+		overviewHandler:     overviewHandler,
+		metricsHandler:      metricsHandler,
+		loggingHandler:      loggingHandler,
+		metaHandler:         metaHandler,
+		cacheHandler:        cacheHandler,
+		featureFlagHandler:  featureFlagHandler,
+		banListHandler:      banListHandler,
+		notificationHandler: notificationHandler,
+		announcementHandler: announcementHandler,
+		exportHandler:       exportHandler,
+		importHandler:       importHandler,
+		privacyHandler:      privacyHandler,
+		oidcHandler:         oidcHandler,
+		samlHandler:         samlHandler,
+		passwordlessHandler: passwordlessHandler,
+		quotaHandler:        quotaHandler,
+		billingHandler:      billingHandler,
+		maintenanceHandler:  maintenanceHandler,
+		drainHandler:        drainHandler,
+		rateLimitHandler:    rateLimitHandler,
+		retentionHandler:    retentionHandler,
+		backupHandler:       backupHandler,
+		responseCache:       responseCache,
+		coalescer:           coalescer,
+		banList:             banList,
+		quotaManager:        quotaManager,
+		jwtManager:          jwtManager,
+		userRepository:      userRepository,
 	}
 }
 
@@ -54,11 +144,38 @@ func (r *Router) SetupRoutes() {
 	SetupHealthRoutes(r.engine, r.healthHandler)
 
 	// Auth routes
-	SetupAuthRoutes(r.engine, r.authHandler, r.jwtManager)
+	SetupAuthRoutes(r.engine, r.authHandler, r.jwtManager, r.banList)
 
 	// User routes
 	r.SetupUserRoutes()
 
+	// Admin routes
+	r.SetupAdminRoutes()
+
+	// Announcement routes (optional auth)
+	r.SetupAnnouncementRoutes()
+
+	// Export download routes (signed links, no auth required)
+	r.SetupExportRoutes()
+
+	// OpenID Connect provider routes
+	r.SetupOAuthRoutes()
+
+	// SAML single sign-on routes
+	r.SetupSAMLRoutes()
+
+	// Passwordless login routes (device flow and magic links)
+	r.SetupPasswordlessRoutes()
+
+	// Usage quota routes
+	r.SetupQuotaRoutes()
+
+	// Subscription plan and Stripe webhook routes
+	r.SetupBillingRoutes()
+
+	// API metadata routes (no auth required)
+	SetupMetaRoutes(r.engine, r.metaHandler)
+
 	// Welcome route with enhanced middleware integration
 	r.engine.GET("/", func(c *gin.Context) {
 		// Demonstrate correlation ID from structured logging middleware (REQ-MW-003)
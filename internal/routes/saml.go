@@ -0,0 +1,11 @@
+package routes
+
+func (r *Router) SetupSAMLRoutes() {
+	// The IdP posts the response through the user's browser, so none of these carry a user token
+	samlGroup := r.engine.Group("/api/v1/auth/saml/:tenant")
+	{
+		samlGroup.GET("/metadata", r.samlHandler.Metadata)
+		samlGroup.GET("/login", r.samlHandler.Login)
+		samlGroup.POST("/acs", r.samlHandler.ACS)
+	}
+}
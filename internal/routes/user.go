@@ -5,17 +5,36 @@ import (
 )
 
 func (r *Router) SetupUserRoutes() {
+	// Public avatar redirect, usable directly from img tags without an access token
+	r.engine.GET("/api/v1/users/:id/avatar", r.avatarHandler.GetAvatar)
+
 	userGroup := r.engine.Group("/api/v1/users")
-	userGroup.Use(middleware.AuthMiddleware(r.jwtManager))
+	userGroup.Use(middleware.AuthMiddleware(r.jwtManager), middleware.BanListMiddleware(r.banList), middleware.QuotaMiddleware(r.quotaManager))
 	{
+		// Self-service routes for the current user
+		userGroup.GET("/me", r.profileHandler.GetProfile)
+		userGroup.PUT("/me", r.profileHandler.UpdateProfile)
+		userGroup.DELETE("/me", r.profileHandler.DeleteAccount)
+		userGroup.POST("/me/password", r.profileHandler.ChangePassword)
+		userGroup.POST("/me/email", r.profileHandler.RequestEmailChange)
+		userGroup.POST("/me/email/verify", r.profileHandler.ConfirmEmailChange)
+		userGroup.POST("/me/avatar", r.avatarHandler.UploadAvatar)
+		userGroup.GET("/me/notifications", r.notificationHandler.ListNotifications)
+		userGroup.POST("/me/notifications/read", r.notificationHandler.MarkAllNotificationsRead)
+		userGroup.POST("/me/notifications/:id/read", r.notificationHandler.MarkNotificationRead)
+		userGroup.GET("/me/notification-preferences", r.notificationHandler.GetNotificationPreferences)
+		userGroup.PUT("/me/notification-preferences", r.notificationHandler.UpdateNotificationPreferences)
+		userGroup.POST("/me/data-export", r.privacyHandler.ExportMyData)
+		userGroup.POST("/me/erasure", r.privacyHandler.EraseMyAccount)
+
 		// Routes available to any authenticated user
-		userGroup.GET("/:id", r.userHandler.GetUser)
+		userGroup.GET("/:id", r.responseCache.Middleware(), r.coalescer.Middleware(), r.userHandler.GetUser)
 
 		// Routes available only to admins
 		adminGroup := userGroup.Group("")
 		adminGroup.Use(middleware.AdminOnlyMiddleware(r.userRepository))
 		{
-			adminGroup.GET("", r.userHandler.GetUsers)
+			adminGroup.GET("", r.responseCache.Middleware(), r.coalescer.Middleware(), r.userHandler.GetUsers)
 			adminGroup.PUT("/:id", r.userHandler.UpdateUser)
 			adminGroup.DELETE("/:id", r.userHandler.DeleteUser)
 		}
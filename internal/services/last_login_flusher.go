@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go-server/internal/repositories"
+)
+
+// 最后登录时间批量写入的默认值
+const (
+	DefaultLastLoginFlushInterval  = 10 * time.Second
+	DefaultLastLoginFlushBatchSize = 500
+
+	// lastLoginFlushTimeout 单次写入的超时时间
+	lastLoginFlushTimeout = 30 * time.Second
+)
+
+// LastLoginFlushConfig 最后登录时间批量写入配置
+type LastLoginFlushConfig struct {
+	Interval  time.Duration // 写入间隔
+	BatchSize int           // 每条 UPDATE 语句写入的最大用户数
+
+	// OnError 后台写入失败时调用，为 nil 时写入标准日志
+	OnError func(err error)
+}
+
+// LastLoginFlusher 定期把缓冲的最后登录时间批量写入数据库
+// 登录只记录到 LastLoginStore，每个写入间隔按批次取出并用一条语句更新，避免每次登录执行一条 UPDATE；
+// 写入失败的批次放回存储，下次写入时重试
+type LastLoginFlusher struct {
+	store  repositories.LastLoginStore
+	writer repositories.LastLoginWriter
+	config LastLoginFlushConfig
+
+	mu sync.Mutex // 串行化写入，避免后台写入与停止时的最后一次写入并发
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewLastLoginFlusher 创建最后登录时间写入任务，调用 Start 后开始定期写入
+func NewLastLoginFlusher(store repositories.LastLoginStore, writer repositories.LastLoginWriter, config LastLoginFlushConfig) *LastLoginFlusher {
+	if config.Interval <= 0 {
+		config.Interval = DefaultLastLoginFlushInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultLastLoginFlushBatchSize
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) {
+			log.Printf("警告：写入最后登录时间失败: %v", err)
+		}
+	}
+
+	return &LastLoginFlusher{
+		store:  store,
+		writer: writer,
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start 启动后台写入
+func (f *LastLoginFlusher) Start() {
+	f.startOnce.Do(func() {
+		go f.run()
+	})
+}
+
+// Stop 停止后台写入，并写入剩余的缓冲登录
+func (f *LastLoginFlusher) Stop(ctx context.Context) error {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+	})
+	f.startOnce.Do(func() {
+		close(f.done)
+	})
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	_, err := f.Flush(ctx)
+	return err
+}
+
+func (f *LastLoginFlusher) run() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(f.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), lastLoginFlushTimeout)
+		if _, err := f.Flush(ctx); err != nil {
+			f.config.OnError(err)
+		}
+		cancel()
+	}
+}
+
+// Flush 按批次取出缓冲的登录并写入数据库，直到缓冲为空，返回写入的用户数
+// 写入失败时该批次放回存储，已经写入的批次不受影响
+func (f *LastLoginFlusher) Flush(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	flushed := 0
+	for {
+		logins, err := f.store.Take(ctx, f.config.BatchSize)
+		if err != nil {
+			return flushed, err
+		}
+		if len(logins) == 0 {
+			return flushed, nil
+		}
+
+		if err := f.writer.UpdateLastLogins(ctx, logins); err != nil {
+			return flushed, errors.Join(err, f.restore(logins))
+		}
+		flushed += len(logins)
+
+		// 存储可能返回不足一批的登录，缓冲为空时才结束
+		if len(logins) < f.config.BatchSize {
+			pending, err := f.store.Pending(ctx)
+			if err != nil {
+				return flushed, err
+			}
+			if pending == 0 {
+				return flushed, nil
+			}
+		}
+	}
+}
+
+// restore 把写入失败的批次放回存储，期间记录的更晚登录保持不变
+func (f *LastLoginFlusher) restore(logins map[string]time.Time) error {
+	// 使用独立的上下文，写入超时后仍能放回
+	ctx, cancel := context.WithTimeout(context.Background(), lastLoginFlushTimeout)
+	defer cancel()
+
+	var errs []error
+	for userID, at := range logins {
+		if err := f.store.Record(ctx, userID, at); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLastLoginWriter 写入总是失败的最后登录时间写入方
+type failingLastLoginWriter struct {
+	calls int
+}
+
+func (w *failingLastLoginWriter) UpdateLastLogins(ctx context.Context, logins map[string]time.Time) error {
+	w.calls++
+	return errors.New("database unavailable")
+}
+
+// shortBatchLastLoginStore 每次最多取出一个登录的存储，模拟 HSCAN 返回不足一批的情况
+type shortBatchLastLoginStore struct {
+	*repositories.MemoryLastLoginStore
+}
+
+func (s shortBatchLastLoginStore) Take(ctx context.Context, limit int) (map[string]time.Time, error) {
+	return s.MemoryLastLoginStore.Take(ctx, 1)
+}
+
+func TestLastLoginFlusher_Flush(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	store := repositories.NewMemoryLastLoginStore()
+
+	var users []*models.User
+	for i := 0; i < 5; i++ {
+		user := &models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i), IsActive: true}
+		require.NoError(t, repo.Create(ctx, user))
+		users = append(users, user)
+	}
+
+	loginAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, user := range users {
+		require.NoError(t, store.Record(ctx, user.ID, loginAt))
+	}
+	// 更早的登录不覆盖已缓冲的登录
+	require.NoError(t, store.Record(ctx, users[0].ID, loginAt.Add(-time.Hour)))
+
+	flusher := NewLastLoginFlusher(store, repo, LastLoginFlushConfig{BatchSize: 2})
+	flushed, err := flusher.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, flushed)
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+
+	for _, user := range users {
+		stored, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.LastLogin)
+		assert.True(t, stored.LastLogin.Equal(loginAt))
+	}
+}
+
+func TestLastLoginFlusher_FlushShortBatches(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	store := shortBatchLastLoginStore{repositories.NewMemoryLastLoginStore()}
+
+	loginAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		user := &models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i), IsActive: true}
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, store.Record(ctx, user.ID, loginAt))
+	}
+
+	flusher := NewLastLoginFlusher(store, repo, LastLoginFlushConfig{BatchSize: 2})
+	flushed, err := flusher.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, flushed, "a short batch should not end the flush while logins are pending")
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestLastLoginFlusher_RestoresFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryLastLoginStore()
+	writer := &failingLastLoginWriter{}
+
+	loginAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, store.Record(ctx, "user-1", loginAt))
+
+	flusher := NewLastLoginFlusher(store, writer, LastLoginFlushConfig{})
+	flushed, err := flusher.Flush(ctx)
+	assert.Error(t, err)
+	assert.Zero(t, flushed)
+	assert.Equal(t, 1, writer.calls)
+
+	at, found, err := store.Get(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, found, "failed batch should be put back for the next flush")
+	assert.True(t, at.Equal(loginAt))
+}
+
+func TestLastLoginFlusher_StopFlushesRemaining(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	store := repositories.NewMemoryLastLoginStore()
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	flusher := NewLastLoginFlusher(store, repo, LastLoginFlushConfig{Interval: time.Hour})
+	flusher.Start()
+	require.NoError(t, store.Record(ctx, user.ID, time.Now().UTC()))
+	require.NoError(t, flusher.Stop(ctx))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastLogin)
+}
+
+func TestUserService_BufferedLastLogin(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	store := repositories.NewMemoryLastLoginStore()
+	service := NewUserServiceWithCache(repo, cache.NewMemoryCache(), WithLastLoginStore(store))
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	require.NoError(t, service.UpdateLastLogin(ctx, user.ID))
+
+	// 登录只写入缓冲，数据库尚未更新
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.LastLogin)
+
+	// 通过缓存读取时带有缓冲的登录时间
+	profile, err := service.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, profile.LastLogin)
+
+	_, err = NewLastLoginFlusher(store, repo, LastLoginFlushConfig{}).Flush(ctx)
+	require.NoError(t, err)
+	stored, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastLogin)
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+)
+
+// 指标快照的默认值
+const (
+	DefaultSnapshotInterval        = time.Minute
+	DefaultSnapshotRawRetention    = 24 * time.Hour
+	DefaultSnapshotRollupInterval  = time.Hour
+	DefaultSnapshotRollupRetention = 30 * 24 * time.Hour
+
+	// snapshotTimeout 单次采集、汇总或清理的超时时间
+	snapshotTimeout = 30 * time.Second
+)
+
+// MetricsSnapshotConfig 指标快照配置
+type MetricsSnapshotConfig struct {
+	Instance        string        // 实例名称，为空时使用主机名
+	Interval        time.Duration // 采集间隔，也是原始快照的时间桶长度
+	RawRetention    time.Duration // 原始快照保留时长
+	RollupInterval  time.Duration // 汇总快照的时间桶长度，须为采集间隔的整数倍
+	RollupRetention time.Duration // 汇总快照保留时长
+
+	// OnError 后台采集、汇总或清理失败时调用，为 nil 时写入标准日志
+	OnError func(operation string, err error)
+}
+
+// MetricsSources 快照读取的指标来源，为 nil 的来源对应的列记为 0
+type MetricsSources struct {
+	HTTP      *metrics.HTTPMetrics
+	Cache     *metrics.CacheMetrics // 用户缓存的应用层指标
+	RateLimit func() (metrics.RateLimitStats, bool)
+}
+
+// metricsCounters 指标来源自进程启动以来的累计值
+type metricsCounters struct {
+	httpRequests       int64
+	httpServerErrors   int64
+	httpDurationMicros int64
+	rateLimitRequests  int64
+	rateLimitThrottled int64
+	cacheHits          int64
+	cacheMisses        int64
+	cacheLoads         int64
+}
+
+// since 返回相对于上次采集的增量；计数器被重置（小于上次的值）时使用当前值
+func (c metricsCounters) since(previous metricsCounters) metricsCounters {
+	delta := func(current, previous int64) int64 {
+		if current < previous {
+			return current
+		}
+		return current - previous
+	}
+	return metricsCounters{
+		httpRequests:       delta(c.httpRequests, previous.httpRequests),
+		httpServerErrors:   delta(c.httpServerErrors, previous.httpServerErrors),
+		httpDurationMicros: delta(c.httpDurationMicros, previous.httpDurationMicros),
+		rateLimitRequests:  delta(c.rateLimitRequests, previous.rateLimitRequests),
+		rateLimitThrottled: delta(c.rateLimitThrottled, previous.rateLimitThrottled),
+		cacheHits:          delta(c.cacheHits, previous.cacheHits),
+		cacheMisses:        delta(c.cacheMisses, previous.cacheMisses),
+		cacheLoads:         delta(c.cacheLoads, previous.cacheLoads),
+	}
+}
+
+// MetricsSnapshotter 定期把进程内的指标增量写入 metrics_snapshots 表
+// 进程内计数器在重启后归零，快照保存每个时间桶内的增量，管理后台因此可以查询重启前的历史。
+// 原始快照按采集间隔写入并保留 RawRetention；每个汇总间隔结束后把本实例的原始快照汇总为一行，
+// 汇总快照保留 RollupRetention，用于查询超出原始快照保留时长的范围
+type MetricsSnapshotter struct {
+	repo    repositories.MetricsSnapshotRepository
+	sources MetricsSources
+	config  MetricsSnapshotConfig
+	now     func() time.Time
+
+	mu         sync.Mutex
+	previous   metricsCounters
+	since      time.Time // 上次采集的时间，本次增量计入该时间所在的时间桶
+	nextRollup time.Time // 下一个待汇总时间桶的结束时间，为零时从数据库中的最新汇总快照恢复
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewMetricsSnapshotter 创建指标快照任务，调用 Start 后开始定期采集
+func NewMetricsSnapshotter(repo repositories.MetricsSnapshotRepository, sources MetricsSources, config MetricsSnapshotConfig) *MetricsSnapshotter {
+	if config.Instance == "" {
+		config.Instance, _ = os.Hostname()
+		if config.Instance == "" {
+			config.Instance = "unknown"
+		}
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultSnapshotInterval
+	}
+	if config.RawRetention <= 0 {
+		config.RawRetention = DefaultSnapshotRawRetention
+	}
+	if config.RollupInterval <= 0 {
+		config.RollupInterval = DefaultSnapshotRollupInterval
+	}
+	if config.RollupRetention <= 0 {
+		config.RollupRetention = DefaultSnapshotRollupRetention
+	}
+	if config.OnError == nil {
+		config.OnError = func(operation string, err error) {
+			log.Printf("警告：指标快照%s失败: %v", operation, err)
+		}
+	}
+
+	return &MetricsSnapshotter{
+		repo:    repo,
+		sources: sources,
+		config:  config,
+		now:     time.Now,
+		since:   time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Instance 返回写入快照的实例名称
+func (s *MetricsSnapshotter) Instance() string {
+	return s.config.Instance
+}
+
+// Start 启动后台采集，采集时间与采集间隔的整数倍对齐
+func (s *MetricsSnapshotter) Start() {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Stop 停止后台采集，并写入最后一个不完整时间桶的快照
+func (s *MetricsSnapshotter) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.startOnce.Do(func() {
+		close(s.done)
+	})
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.Snapshot(ctx)
+}
+
+func (s *MetricsSnapshotter) run() {
+	defer close(s.done)
+
+	for {
+		now := s.now()
+		timer := time.NewTimer(now.Truncate(s.config.Interval).Add(s.config.Interval).Sub(now))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		if err := s.Snapshot(ctx); err != nil {
+			s.config.OnError("采集", err)
+		}
+		if err := s.Rollup(ctx); err != nil {
+			s.config.OnError("汇总", err)
+		}
+		cancel()
+	}
+}
+
+// Snapshot 采集自上次采集以来的指标增量，写入上次采集时间所在的原始时间桶
+// 同一时间桶的多次写入会累加，写入失败时增量保留到下次采集
+func (s *MetricsSnapshotter) Snapshot(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	current, inFlight := s.read()
+	delta := current.since(s.previous)
+
+	snapshot := &models.MetricsSnapshot{
+		Instance:           s.config.Instance,
+		Resolution:         int(s.config.Interval / time.Second),
+		BucketStart:        s.since.Truncate(s.config.Interval).UTC(),
+		HTTPRequests:       delta.httpRequests,
+		HTTPServerErrors:   delta.httpServerErrors,
+		HTTPDurationMicros: delta.httpDurationMicros,
+		HTTPInFlight:       inFlight,
+		RateLimitRequests:  delta.rateLimitRequests,
+		RateLimitThrottled: delta.rateLimitThrottled,
+		CacheHits:          delta.cacheHits,
+		CacheMisses:        delta.cacheMisses,
+		CacheLoads:         delta.cacheLoads,
+	}
+	if err := s.repo.Add(ctx, snapshot); err != nil {
+		return err
+	}
+
+	s.previous = current
+	s.since = now
+	return nil
+}
+
+// read 读取各指标来源的累计值和当前处理中的请求数
+func (s *MetricsSnapshotter) read() (metricsCounters, int64) {
+	var counters metricsCounters
+	var inFlight int64
+
+	if s.sources.HTTP != nil {
+		stats := s.sources.HTTP.GetStats()
+		inFlight = stats.InFlight
+		for _, route := range stats.Routes {
+			counters.httpRequests += int64(route.Requests)
+			counters.httpDurationMicros += route.Latency.Sum.Microseconds()
+			if route.Status >= 500 {
+				counters.httpServerErrors += int64(route.Requests)
+			}
+		}
+	}
+	if s.sources.RateLimit != nil {
+		if stats, ok := s.sources.RateLimit(); ok {
+			counters.rateLimitRequests = int64(stats.TotalRequests)
+			counters.rateLimitThrottled = int64(stats.ThrottledRequests)
+		}
+	}
+	if s.sources.Cache != nil {
+		stats := s.sources.Cache.Summary()
+		counters.cacheHits = int64(stats.CacheHits)
+		counters.cacheMisses = int64(stats.CacheMisses)
+		counters.cacheLoads = int64(stats.Loads)
+	}
+	return counters, inFlight
+}
+
+// Rollup 把已结束的汇总时间桶内本实例的原始快照汇总为一行，并清理超出保留时长的快照
+// 首次调用时从最新的汇总快照继续，停机期间的时间桶只要原始快照仍在保留期内就会补齐
+func (s *MetricsSnapshotter) Rollup(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	rawResolution := int(s.config.Interval / time.Second)
+	rollupResolution := int(s.config.RollupInterval / time.Second)
+
+	if s.nextRollup.IsZero() {
+		// 从原始快照完整保留的第一个时间桶开始，更早的时间桶中部分原始快照已被清理
+		earliest := now.Add(-s.config.RawRetention).Truncate(s.config.RollupInterval).Add(2 * s.config.RollupInterval)
+		latest, err := s.repo.Latest(ctx, s.config.Instance, rollupResolution)
+		if err != nil {
+			return err
+		}
+		s.nextRollup = earliest
+		if latest != nil && latest.BucketStart.Add(2*s.config.RollupInterval).After(earliest) {
+			s.nextRollup = latest.BucketStart.Add(2 * s.config.RollupInterval)
+		}
+	}
+
+	rolledUp := false
+	for !s.nextRollup.After(now) {
+		start := s.nextRollup.Add(-s.config.RollupInterval)
+		raw, err := s.repo.ListForInstance(ctx, s.config.Instance, rawResolution, start, s.nextRollup)
+		if err != nil {
+			return err
+		}
+		if len(raw) > 0 {
+			rollup := sumSnapshots(raw)
+			rollup.Instance = s.config.Instance
+			rollup.Resolution = rollupResolution
+			rollup.BucketStart = start.UTC()
+			if err := s.repo.Save(ctx, rollup); err != nil {
+				return err
+			}
+		}
+		s.nextRollup = s.nextRollup.Add(s.config.RollupInterval)
+		rolledUp = true
+	}
+	if !rolledUp {
+		return nil
+	}
+
+	if _, err := s.repo.DeleteBefore(ctx, rawResolution, now.Add(-s.config.RawRetention)); err != nil {
+		return fmt.Errorf("清理原始快照失败: %w", err)
+	}
+	if _, err := s.repo.DeleteBefore(ctx, rollupResolution, now.Add(-s.config.RollupRetention)); err != nil {
+		return fmt.Errorf("清理汇总快照失败: %w", err)
+	}
+	return nil
+}
+
+// History 返回最近 window 时长内所有实例的指标，按时间桶汇总
+// window 不超过原始快照保留时长时使用原始快照，否则使用汇总快照，此时尚未结束的汇总时间桶不包含在结果中
+func (s *MetricsSnapshotter) History(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error) {
+	resolution := s.config.Interval
+	if window > s.config.RawRetention {
+		resolution = s.config.RollupInterval
+	}
+
+	to := s.now().UTC()
+	from := to.Add(-window).Truncate(resolution)
+	snapshots, err := s.repo.List(ctx, int(resolution/time.Second), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[time.Time][]*models.MetricsSnapshot)
+	for _, snapshot := range snapshots {
+		bucket := snapshot.BucketStart.UTC()
+		buckets[bucket] = append(buckets[bucket], snapshot)
+	}
+
+	history := &models.MetricsHistoryResponse{
+		Resolution: int(resolution / time.Second),
+		From:       from,
+		To:         to,
+		Points:     make([]models.MetricsHistoryPoint, 0, len(buckets)),
+	}
+	for bucket, instances := range buckets {
+		total := sumSnapshots(instances)
+		// 各实例的处理中请求数相加，近似整个集群的并发量
+		total.HTTPInFlight = 0
+		for _, snapshot := range instances {
+			total.HTTPInFlight += snapshot.HTTPInFlight
+		}
+
+		point := models.MetricsHistoryPoint{
+			Time:               bucket,
+			Instances:          len(instances),
+			HTTPRequests:       total.HTTPRequests,
+			HTTPServerErrors:   total.HTTPServerErrors,
+			HTTPInFlight:       total.HTTPInFlight,
+			RateLimitRequests:  total.RateLimitRequests,
+			RateLimitThrottled: total.RateLimitThrottled,
+			CacheHits:          total.CacheHits,
+			CacheMisses:        total.CacheMisses,
+			CacheLoads:         total.CacheLoads,
+		}
+		if total.HTTPRequests > 0 {
+			point.HTTPAvgLatencyMs = float64(total.HTTPDurationMicros) / float64(total.HTTPRequests) / 1000
+		}
+		if lookups := total.CacheHits + total.CacheMisses; lookups > 0 {
+			point.CacheHitRate = float64(total.CacheHits) / float64(lookups)
+		}
+		history.Points = append(history.Points, point)
+	}
+	sort.Slice(history.Points, func(i, j int) bool {
+		return history.Points[i].Time.Before(history.Points[j].Time)
+	})
+	return history, nil
+}
+
+// sumSnapshots 累加快照的计数器，处理中的请求数取最大值
+func sumSnapshots(snapshots []*models.MetricsSnapshot) *models.MetricsSnapshot {
+	total := &models.MetricsSnapshot{}
+	for _, snapshot := range snapshots {
+		total.HTTPRequests += snapshot.HTTPRequests
+		total.HTTPServerErrors += snapshot.HTTPServerErrors
+		total.HTTPDurationMicros += snapshot.HTTPDurationMicros
+		total.RateLimitRequests += snapshot.RateLimitRequests
+		total.RateLimitThrottled += snapshot.RateLimitThrottled
+		total.CacheHits += snapshot.CacheHits
+		total.CacheMisses += snapshot.CacheMisses
+		total.CacheLoads += snapshot.CacheLoads
+		if snapshot.HTTPInFlight > total.HTTPInFlight {
+			total.HTTPInFlight = snapshot.HTTPInFlight
+		}
+	}
+	return total
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/internal/metrics"
+	"go-server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMetricsSnapshotRepository 内存中的指标快照仓储
+type memoryMetricsSnapshotRepository struct {
+	mu        sync.Mutex
+	snapshots []*models.MetricsSnapshot
+}
+
+func (r *memoryMetricsSnapshotRepository) find(snapshot *models.MetricsSnapshot) *models.MetricsSnapshot {
+	for _, existing := range r.snapshots {
+		if existing.Instance == snapshot.Instance && existing.Resolution == snapshot.Resolution &&
+			existing.BucketStart.Equal(snapshot.BucketStart) {
+			return existing
+		}
+	}
+	return nil
+}
+
+func (r *memoryMetricsSnapshotRepository) Save(ctx context.Context, snapshot *models.MetricsSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *snapshot
+	if existing := r.find(snapshot); existing != nil {
+		*existing = copied
+		return nil
+	}
+	r.snapshots = append(r.snapshots, &copied)
+	return nil
+}
+
+func (r *memoryMetricsSnapshotRepository) Add(ctx context.Context, snapshot *models.MetricsSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := r.find(snapshot)
+	if existing == nil {
+		copied := *snapshot
+		r.snapshots = append(r.snapshots, &copied)
+		return nil
+	}
+	total := sumSnapshots([]*models.MetricsSnapshot{existing, snapshot})
+	total.Instance, total.Resolution, total.BucketStart = existing.Instance, existing.Resolution, existing.BucketStart
+	*existing = *total
+	return nil
+}
+
+func (r *memoryMetricsSnapshotRepository) list(instance string, resolution int, from, to time.Time) []*models.MetricsSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*models.MetricsSnapshot
+	for _, snapshot := range r.snapshots {
+		if (instance == "" || snapshot.Instance == instance) && snapshot.Resolution == resolution &&
+			!snapshot.BucketStart.Before(from) && snapshot.BucketStart.Before(to) {
+			result = append(result, snapshot)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BucketStart.Before(result[j].BucketStart) })
+	return result
+}
+
+func (r *memoryMetricsSnapshotRepository) List(ctx context.Context, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error) {
+	return r.list("", resolution, from, to), nil
+}
+
+func (r *memoryMetricsSnapshotRepository) ListForInstance(ctx context.Context, instance string, resolution int, from, to time.Time) ([]*models.MetricsSnapshot, error) {
+	return r.list(instance, resolution, from, to), nil
+}
+
+func (r *memoryMetricsSnapshotRepository) Latest(ctx context.Context, instance string, resolution int) (*models.MetricsSnapshot, error) {
+	all := r.list(instance, resolution, time.Time{}, time.Unix(1<<40, 0))
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all[len(all)-1], nil
+}
+
+func (r *memoryMetricsSnapshotRepository) DeleteBefore(ctx context.Context, resolution int, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.snapshots[:0]
+	var deleted int64
+	for _, snapshot := range r.snapshots {
+		if snapshot.Resolution == resolution && snapshot.BucketStart.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, snapshot)
+	}
+	r.snapshots = kept
+	return deleted, nil
+}
+
+func newTestSnapshotter(repo *memoryMetricsSnapshotRepository, sources MetricsSources, now *time.Time) *MetricsSnapshotter {
+	s := NewMetricsSnapshotter(repo, sources, MetricsSnapshotConfig{
+		Instance:        "node-1",
+		Interval:        time.Minute,
+		RawRetention:    24 * time.Hour,
+		RollupInterval:  time.Hour,
+		RollupRetention: 30 * 24 * time.Hour,
+	})
+	s.now = func() time.Time { return *now }
+	s.since = *now
+	return s
+}
+
+func TestMetricsSnapshotter_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryMetricsSnapshotRepository{}
+	httpMetrics := metrics.NewHTTPMetrics()
+	cacheMetrics := metrics.NewCacheMetrics()
+	rateLimit := metrics.RateLimitStats{TotalRequests: 10, ThrottledRequests: 2}
+
+	now := time.Date(2024, 1, 1, 12, 0, 5, 0, time.UTC)
+	s := newTestSnapshotter(repo, MetricsSources{
+		HTTP:      httpMetrics,
+		Cache:     cacheMetrics,
+		RateLimit: func() (metrics.RateLimitStats, bool) { return rateLimit, true },
+	}, &now)
+
+	httpMetrics.RequestStarted()
+	httpMetrics.RequestFinished("GET", "/api/v1/users", 200, 10*time.Millisecond, 0, 0)
+	httpMetrics.RequestStarted()
+	httpMetrics.RequestFinished("GET", "/api/v1/users", 500, 30*time.Millisecond, 0, 0)
+	httpMetrics.RequestStarted() // 采集时仍在处理
+	cacheMetrics.RecordKeyHit("user:id:1")
+	cacheMetrics.RecordKeyMiss("user:id:2")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, s.Snapshot(ctx))
+	require.Len(t, repo.snapshots, 1)
+	first := repo.snapshots[0]
+	assert.Equal(t, "node-1", first.Instance)
+	assert.Equal(t, 60, first.Resolution)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), first.BucketStart)
+	assert.Equal(t, int64(2), first.HTTPRequests)
+	assert.Equal(t, int64(1), first.HTTPServerErrors)
+	assert.Equal(t, int64(40000), first.HTTPDurationMicros)
+	assert.Equal(t, int64(1), first.HTTPInFlight)
+	assert.Equal(t, int64(10), first.RateLimitRequests)
+	assert.Equal(t, int64(2), first.RateLimitThrottled)
+	assert.Equal(t, int64(1), first.CacheHits)
+	assert.Equal(t, int64(1), first.CacheMisses)
+
+	// 第二次采集只写入增量，计数器被重置时使用当前值
+	httpMetrics.RequestFinished("GET", "/api/v1/users", 200, 10*time.Millisecond, 0, 0)
+	rateLimit = metrics.RateLimitStats{TotalRequests: 3}
+	now = now.Add(time.Minute)
+	require.NoError(t, s.Snapshot(ctx))
+	require.Len(t, repo.snapshots, 2)
+	second := repo.snapshots[1]
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC), second.BucketStart)
+	assert.Equal(t, int64(1), second.HTTPRequests)
+	assert.Equal(t, int64(0), second.HTTPServerErrors)
+	assert.Equal(t, int64(3), second.RateLimitRequests)
+	assert.Equal(t, int64(0), second.CacheHits)
+}
+
+func TestMetricsSnapshotter_SnapshotAccumulatesPartialBuckets(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryMetricsSnapshotRepository{}
+	total := metrics.RateLimitStats{}
+	now := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	s := newTestSnapshotter(repo, MetricsSources{
+		RateLimit: func() (metrics.RateLimitStats, bool) { return total, true },
+	}, &now)
+
+	// 关闭时写入的不完整时间桶和重启后的第一次采集落在同一个时间桶
+	total.TotalRequests = 4
+	now = now.Add(20 * time.Second)
+	require.NoError(t, s.Snapshot(ctx))
+
+	restarted := newTestSnapshotter(repo, MetricsSources{
+		RateLimit: func() (metrics.RateLimitStats, bool) { return total, true },
+	}, &now)
+	total.TotalRequests = 6
+	now = now.Add(30 * time.Second)
+	require.NoError(t, restarted.Snapshot(ctx))
+
+	require.Len(t, repo.snapshots, 1)
+	assert.Equal(t, int64(10), repo.snapshots[0].RateLimitRequests)
+}
+
+func TestMetricsSnapshotter_Rollup(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryMetricsSnapshotRepository{}
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	s := newTestSnapshotter(repo, MetricsSources{}, &now)
+
+	raw := func(instance string, bucket time.Time, requests, inFlight int64) {
+		require.NoError(t, repo.Save(ctx, &models.MetricsSnapshot{
+			Instance: instance, Resolution: 60, BucketStart: bucket, HTTPRequests: requests, HTTPInFlight: inFlight,
+		}))
+	}
+	raw("node-1", now.Add(-90*time.Minute), 5, 2)
+	raw("node-1", now.Add(-30*time.Minute), 7, 1)
+	raw("node-1", now.Add(-time.Minute), 3, 4)
+	raw("node-2", now.Add(-time.Minute), 100, 9) // 其他实例的快照由该实例自己汇总
+	raw("node-1", now.Add(-25*time.Hour), 1, 0)  // 超出原始快照保留时长
+
+	require.NoError(t, s.Rollup(ctx))
+
+	rollups := repo.list("node-1", 3600, time.Time{}, now)
+	require.Len(t, rollups, 2)
+	assert.Equal(t, now.Add(-2*time.Hour), rollups[0].BucketStart)
+	assert.Equal(t, int64(5), rollups[0].HTTPRequests)
+	assert.Equal(t, now.Add(-time.Hour), rollups[1].BucketStart)
+	assert.Equal(t, int64(10), rollups[1].HTTPRequests)
+	assert.Equal(t, int64(4), rollups[1].HTTPInFlight)
+	assert.Empty(t, repo.list("node-2", 3600, time.Time{}, now))
+	assert.Empty(t, repo.list("node-1", 60, time.Time{}, now.Add(-24*time.Hour)))
+
+	// 汇总间隔结束前不再重复汇总
+	raw("node-1", now.Add(time.Minute), 2, 0)
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, s.Rollup(ctx))
+	assert.Len(t, repo.list("node-1", 3600, time.Time{}, now), 2)
+
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, s.Rollup(ctx))
+	rollups = repo.list("node-1", 3600, time.Time{}, now)
+	require.Len(t, rollups, 3)
+	assert.Equal(t, int64(2), rollups[2].HTTPRequests)
+
+	// 重启后从最新的汇总快照继续
+	restarted := newTestSnapshotter(repo, MetricsSources{}, &now)
+	require.NoError(t, restarted.Rollup(ctx))
+	assert.Len(t, repo.list("node-1", 3600, time.Time{}, now), 3)
+}
+
+func TestMetricsSnapshotter_History(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryMetricsSnapshotRepository{}
+	now := time.Date(2024, 1, 2, 12, 0, 30, 0, time.UTC)
+	s := newTestSnapshotter(repo, MetricsSources{}, &now)
+
+	bucket := time.Date(2024, 1, 2, 11, 58, 0, 0, time.UTC)
+	for _, snapshot := range []*models.MetricsSnapshot{
+		{Instance: "node-1", Resolution: 60, BucketStart: bucket, HTTPRequests: 10, HTTPDurationMicros: 50000, HTTPInFlight: 2, CacheHits: 9, CacheMisses: 1},
+		{Instance: "node-2", Resolution: 60, BucketStart: bucket, HTTPRequests: 30, HTTPDurationMicros: 350000, HTTPInFlight: 3, CacheHits: 6, CacheMisses: 4},
+		{Instance: "node-1", Resolution: 60, BucketStart: bucket.Add(time.Minute), HTTPRequests: 1},
+		{Instance: "node-1", Resolution: 3600, BucketStart: bucket.Truncate(time.Hour).Add(-time.Hour), HTTPRequests: 500},
+	} {
+		require.NoError(t, repo.Save(ctx, snapshot))
+	}
+
+	history, err := s.History(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 60, history.Resolution)
+	require.Len(t, history.Points, 2)
+	point := history.Points[0]
+	assert.Equal(t, bucket, point.Time)
+	assert.Equal(t, 2, point.Instances)
+	assert.Equal(t, int64(40), point.HTTPRequests)
+	assert.Equal(t, int64(5), point.HTTPInFlight)
+	assert.InDelta(t, 10.0, point.HTTPAvgLatencyMs, 0.001)
+	assert.InDelta(t, 0.75, point.CacheHitRate, 0.001)
+	assert.Equal(t, int64(1), history.Points[1].HTTPRequests)
+
+	// 超出原始快照保留时长时使用汇总快照
+	history, err = s.History(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 3600, history.Resolution)
+	require.Len(t, history.Points, 1)
+	assert.Equal(t, int64(500), history.Points[0].HTTPRequests)
+}
+
+func TestMetricsSnapshotter_StopWritesFinalSnapshot(t *testing.T) {
+	repo := &memoryMetricsSnapshotRepository{}
+	total := metrics.RateLimitStats{TotalRequests: 7}
+	s := NewMetricsSnapshotter(repo, MetricsSources{
+		RateLimit: func() (metrics.RateLimitStats, bool) { return total, true },
+	}, MetricsSnapshotConfig{Instance: "node-1"})
+
+	s.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Stop(ctx))
+
+	require.Len(t, repo.snapshots, 1)
+	assert.Equal(t, int64(7), repo.snapshots[0].RateLimitRequests)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	domainevents "go-server/internal/events"
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+)
+
+var (
+	// ErrCannotModifySelf 管理员不能停用自己或撤销自己的管理员角色，避免失去管理权限
+	ErrCannotModifySelf = errors.New("you cannot deactivate yourself or revoke your own admin role")
+
+	// ErrInvalidRole 未知的角色
+	ErrInvalidRole = errors.New("invalid role")
+
+	// ErrStreamingUnsupported 用户仓库不支持逐行读取
+	ErrStreamingUnsupported = errors.New("user repository does not support streaming")
+)
+
+// ListUsers 按条件分页列出用户，包含已停用的用户，供管理员使用
+func (s *userService) ListUsers(ctx context.Context, filter repositories.UserFilter, page, limit int) ([]*models.User, int64, error) {
+	offset := (page - 1) * limit
+	users, total, err := s.userRepo.Search(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, user := range users {
+		user.Password = ""
+	}
+	return users, total, nil
+}
+
+// StreamUsers 按主键顺序逐个回调匹配条件的用户，包含已停用的用户，供管理员一次导出全部用户
+// 传给 fn 的用户在下一行时会被复用，需要保留时由调用方复制
+func (s *userService) StreamUsers(ctx context.Context, filter repositories.UserFilter, fn func(user *models.User) error) error {
+	streamer, ok := s.userRepo.(repositories.UserStreamer)
+	if !ok {
+		return ErrStreamingUnsupported
+	}
+	return streamer.StreamUsers(ctx, filter, func(user *models.User) error {
+		user.Password = ""
+		return fn(user)
+	})
+}
+
+// SetActive 激活或停用用户，停用的用户无法登录
+func (s *userService) SetActive(ctx context.Context, id string, active bool, requesterID string) (*models.User, error) {
+	if id == requesterID && !active {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.SetActive(ctx, id, active)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, requesterID, domainevents.FieldIsActive)
+
+	user.Password = ""
+	return user, nil
+}
+
+// ResetPassword 将用户密码重置为随机临时密码并返回，临时密码只返回一次
+func (s *userService) ResetPassword(ctx context.Context, id string) (string, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := newTemporaryPassword()
+	if err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := s.hasher().Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash temporary password: %w", err)
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return "", fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, "", domainevents.FieldPassword)
+	return password, nil
+}
+
+// AssignRoles 设置用户的完整角色集合，包含 admin 时授予管理员权限，否则撤销
+func (s *userService) AssignRoles(ctx context.Context, id string, roles []string, requesterID string) (*models.User, error) {
+	for _, role := range roles {
+		if role != models.RoleUser && role != models.RoleAdmin {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRole, role)
+		}
+	}
+
+	isAdmin := slices.Contains(roles, models.RoleAdmin)
+	if id == requesterID && !isAdmin {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.SetAdmin(ctx, id, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, requesterID, domainevents.FieldRoles)
+
+	user.Password = ""
+	return user, nil
+}
+
+// newTemporaryPassword 生成随机临时密码
+func newTemporaryPassword() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate temporary password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+
+	active := false
+	filter := repositories.UserFilter{Query: "john", IsActive: &active}
+	user := factory.User().WithEmail("john@example.com").WithUsername("john").Build()
+	mockRepo.On("Search", filter, 10, 10).Return([]*models.User{user}, int64(11), nil)
+
+	users, total, err := service.ListUsers(ctx, filter, 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), total)
+	require.Len(t, users, 1)
+	assert.Empty(t, users[0].Password)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_SetActive(t *testing.T) {
+	ctx := context.Background()
+	t.Run("停用用户", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		user := factory.User().WithEmail("john@example.com").WithUsername("john").Build()
+		user.IsActive = false
+		mockRepo.On("SetActive", user.ID, false).Return(user, nil)
+
+		updated, err := service.SetActive(ctx, user.ID, false, "admin-id")
+		require.NoError(t, err)
+		assert.False(t, updated.IsActive)
+		assert.Empty(t, updated.Password)
+	})
+
+	t.Run("管理员不能停用自己", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		_, err := service.SetActive(ctx, "admin-id", false, "admin-id")
+		assert.ErrorIs(t, err, ErrCannotModifySelf)
+		mockRepo.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+	user := factory.User().WithEmail("john@example.com").WithUsername("john").Build()
+
+	var saved *models.User
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.User")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.User)
+	}).Return(nil)
+
+	password, err := service.ResetPassword(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, password, 16)
+	require.NotNil(t, saved)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(saved.Password), []byte(password)))
+}
+
+func TestUserService_AssignRoles(t *testing.T) {
+	ctx := context.Background()
+	t.Run("授予管理员角色", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		user := factory.User().WithEmail("john@example.com").WithUsername("john").Build()
+		user.IsAdmin = true
+		mockRepo.On("SetAdmin", user.ID, true).Return(user, nil)
+
+		updated, err := service.AssignRoles(ctx, user.ID, []string{models.RoleUser, models.RoleAdmin}, "admin-id")
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.RoleUser, models.RoleAdmin}, updated.GetRoles())
+	})
+
+	t.Run("未知角色", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository))
+
+		_, err := service.AssignRoles(ctx, "user-id", []string{"superuser"}, "admin-id")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("管理员不能撤销自己的管理员角色", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository))
+
+		_, err := service.AssignRoles(ctx, "admin-id", []string{models.RoleUser}, "admin-id")
+		assert.ErrorIs(t, err, ErrCannotModifySelf)
+	})
+}
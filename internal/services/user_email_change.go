@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domainevents "go-server/internal/events"
+	"go-server/internal/models"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+)
+
+// EmailChangeTTL 邮箱变更验证令牌的有效期
+const EmailChangeTTL = 24 * time.Hour
+
+// EventEmailChangeRequested 用户申请变更邮箱后发布的领域事件类型，由通知服务向新邮箱发送验证邮件
+const EventEmailChangeRequested = "user.email_change_requested"
+
+var (
+	// ErrPasswordIncorrect 当前密码错误
+	ErrPasswordIncorrect = errors.New("password is incorrect")
+
+	// ErrEmailUnchanged 新邮箱与当前邮箱相同
+	ErrEmailUnchanged = errors.New("new email is the same as the current email")
+
+	// ErrEmailChangeUnavailable 未配置缓存或事件发布器，无法保存验证令牌或发送验证邮件
+	ErrEmailChangeUnavailable = errors.New("email change is not available")
+
+	// ErrInvalidEmailChangeToken 验证令牌无效或已过期
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email verification token")
+)
+
+// EmailChangeRequestedEvent user.email_change_requested 事件负载
+type EmailChangeRequestedEvent struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	NewEmail  string    `json:"new_email"`
+	Token     string    `json:"token"` // 验证令牌，仅用于发送给新邮箱
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// pendingEmailChange 缓存中待验证的邮箱变更，只保存令牌的哈希
+type pendingEmailChange struct {
+	TokenHash string `json:"token_hash"`
+	NewEmail  string `json:"new_email"`
+}
+
+// EmailChangeKey 返回用户待验证邮箱变更的缓存键，每个用户同时只有一个待验证的变更
+func EmailChangeKey(userID string) string {
+	return "user:email_change:" + userID
+}
+
+// RequestEmailChange 校验当前密码后为新邮箱生成验证令牌，新邮箱在 ConfirmEmailChange 后才生效
+func (s *userService) RequestEmailChange(ctx context.Context, id string, req *models.ChangeEmailRequest) (time.Time, error) {
+	if s.cache == nil || s.publisher == nil {
+		return time.Time{}, ErrEmailChangeUnavailable
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := s.hasher().Verify(user.Password, req.Password); err != nil {
+		return time.Time{}, ErrPasswordIncorrect
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return time.Time{}, ErrEmailUnchanged
+	}
+
+	exists, err := s.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to check if user exists: %w", err)
+	}
+	if exists {
+		return time.Time{}, errors.New("user with this email already exists")
+	}
+
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	pending := pendingEmailChange{TokenHash: hashEmailChangeToken(token), NewEmail: newEmail}
+	if err := s.cache.Set(ctx, EmailChangeKey(id), pending, EmailChangeTTL); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	expiresAt := time.Now().Add(EmailChangeTTL)
+	payload := EmailChangeRequestedEvent{
+		UserID:    user.ID,
+		Username:  user.Username,
+		NewEmail:  newEmail,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}
+	if err := events.PublishEvent(ctx, s.publisher, EventEmailChangeRequested, payload); err != nil {
+		_ = s.cache.Delete(ctx, EmailChangeKey(id))
+		return time.Time{}, fmt.Errorf("failed to send email verification: %w", err)
+	}
+
+	return expiresAt, nil
+}
+
+// ConfirmEmailChange 使用验证令牌确认邮箱变更，令牌只能使用一次
+func (s *userService) ConfirmEmailChange(ctx context.Context, id, token string) (*models.User, error) {
+	if s.cache == nil {
+		return nil, ErrEmailChangeUnavailable
+	}
+
+	pending, found := cache.GetAs[pendingEmailChange](ctx, s.cache, EmailChangeKey(id))
+	if !found || subtle.ConstantTimeCompare([]byte(pending.TokenHash), []byte(hashEmailChangeToken(token))) != 1 {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 申请后新邮箱可能已被其他用户注册
+	exists, err := s.userRepo.ExistsByEmail(ctx, pending.NewEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if user exists: %w", err)
+	}
+	if exists {
+		return nil, errors.New("user with this email already exists")
+	}
+
+	previous := *user
+	user.Email = pending.NewEmail
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update email: %w", err)
+	}
+
+	if err := s.cache.Delete(ctx, EmailChangeKey(id)); err != nil {
+		return nil, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	// 旧邮箱和新邮箱相关的缓存条目都需要失效
+	s.invalidateUserCaches(ctx, &previous)
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, user.ID, domainevents.FieldEmail)
+
+	user.Password = ""
+	return user, nil
+}
+
+// newEmailChangeToken 生成随机验证令牌
+func newEmailChangeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate email verification token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashEmailChangeToken 返回令牌的 SHA-256 哈希
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
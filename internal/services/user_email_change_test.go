@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/pkg/cache"
+	"go-server/pkg/events"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache 只实现邮箱变更流程用到的缓存方法，调用其他方法会 panic
+type memoryCache struct {
+	cache.Cache
+	data map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: make(map[string][]byte)}
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.data[key] = data
+	return nil
+}
+
+func (m *memoryCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	data, found := m.data[key]
+	return data, found
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryCache) DeleteMultiple(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *memoryCache) InvalidateTag(ctx context.Context, tag string) error {
+	return nil
+}
+
+func TestUserService_EmailChange(t *testing.T) {
+	ctx := context.Background()
+	newService := func(t *testing.T) (*userService, *MockUserRepository, *memoryCache, chan EmailChangeRequestedEvent) {
+		mockRepo := new(MockUserRepository)
+		store := newMemoryCache()
+		bus := events.NewMemoryBus()
+
+		received := make(chan EmailChangeRequestedEvent, 1)
+		_, err := bus.Subscribe(context.Background(), EventEmailChangeRequested, func(ctx context.Context, event *events.Event) error {
+			var payload EmailChangeRequestedEvent
+			if err := event.Decode(&payload); err != nil {
+				return err
+			}
+			received <- payload
+			return nil
+		})
+		require.NoError(t, err)
+
+		return &userService{userRepo: mockRepo, cache: store, publisher: bus}, mockRepo, store, received
+	}
+
+	waitForToken := func(t *testing.T, received chan EmailChangeRequestedEvent) EmailChangeRequestedEvent {
+		select {
+		case payload := <-received:
+			return payload
+		case <-time.After(time.Second):
+			t.Fatal("user.email_change_requested event was not published")
+			return EmailChangeRequestedEvent{}
+		}
+	}
+
+	t.Run("申请并确认邮箱变更", func(t *testing.T) {
+		service, mockRepo, store, received := newService(t)
+		user := factory.User().WithEmail("old@example.com").WithUsername("emailuser").Build()
+
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+		mockRepo.On("ExistsByEmail", "new@example.com").Return(false, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+			return u.Email == "new@example.com"
+		})).Return(nil).Once()
+
+		expiresAt, err := service.RequestEmailChange(ctx, user.ID, &models.ChangeEmailRequest{
+			NewEmail: "new@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(EmailChangeTTL), expiresAt, time.Minute)
+
+		payload := waitForToken(t, received)
+		assert.Equal(t, "new@example.com", payload.NewEmail)
+		assert.NotEmpty(t, payload.Token)
+
+		// 缓存中只保存令牌的哈希
+		assert.NotContains(t, string(store.data[EmailChangeKey(user.ID)]), payload.Token)
+
+		_, err = service.ConfirmEmailChange(ctx, user.ID, "wrong-token")
+		assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+
+		updated, err := service.ConfirmEmailChange(ctx, user.ID, payload.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", updated.Email)
+		assert.Empty(t, updated.Password)
+
+		// 令牌只能使用一次
+		_, err = service.ConfirmEmailChange(ctx, user.ID, payload.Token)
+		assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("当前密码错误", func(t *testing.T) {
+		service, mockRepo, _, _ := newService(t)
+		user := factory.User().WithEmail("old@example.com").WithUsername("emailuser").Build()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+		_, err := service.RequestEmailChange(ctx, user.ID, &models.ChangeEmailRequest{
+			NewEmail: "new@example.com",
+			Password: "wrongpassword",
+		})
+		assert.ErrorIs(t, err, ErrPasswordIncorrect)
+	})
+
+	t.Run("新邮箱与当前邮箱相同", func(t *testing.T) {
+		service, mockRepo, _, _ := newService(t)
+		user := factory.User().WithEmail("old@example.com").WithUsername("emailuser").Build()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+		_, err := service.RequestEmailChange(ctx, user.ID, &models.ChangeEmailRequest{
+			NewEmail: "OLD@example.com",
+			Password: "password123",
+		})
+		assert.ErrorIs(t, err, ErrEmailUnchanged)
+	})
+
+	t.Run("新邮箱已被占用", func(t *testing.T) {
+		service, mockRepo, _, _ := newService(t)
+		user := factory.User().WithEmail("old@example.com").WithUsername("emailuser").Build()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+		mockRepo.On("ExistsByEmail", "taken@example.com").Return(true, nil)
+
+		_, err := service.RequestEmailChange(ctx, user.ID, &models.ChangeEmailRequest{
+			NewEmail: "taken@example.com",
+			Password: "password123",
+		})
+		require.Error(t, err)
+		assert.Equal(t, "user with this email already exists", err.Error())
+	})
+
+	t.Run("未配置缓存时不可用", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository))
+
+		_, err := service.RequestEmailChange(ctx, "1", &models.ChangeEmailRequest{
+			NewEmail: "new@example.com",
+			Password: "password123",
+		})
+		assert.ErrorIs(t, err, ErrEmailChangeUnavailable)
+	})
+}
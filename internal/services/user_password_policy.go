@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"go-server/pkg/auth/password"
+)
+
+// PasswordPolicyError 新密码不满足密码策略，Violations 列出违反的全部规则
+type PasswordPolicyError struct {
+	Violations []password.Violation
+}
+
+// Error 返回违反规则的英文描述
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.String())
+	}
+	return "password does not meet the password policy: " + strings.Join(messages, "; ")
+}
+
+// WithPasswordChecker 设置注册和修改密码时检查新密码的密码策略，未设置时不检查
+func WithPasswordChecker(checker *password.Checker) UserServiceOption {
+	return func(s *userService) {
+		s.passwordChecker = checker
+	}
+}
+
+// checkNewPassword 按密码策略检查新密码，userInfo 为不应出现在密码中的用户名、邮箱等
+// 泄露库查询失败时记录日志并放行，外部服务不可用不应阻止注册和修改密码
+func (s *userService) checkNewPassword(ctx context.Context, newPassword string, userInfo ...string) error {
+	if s.passwordChecker == nil {
+		return nil
+	}
+
+	violations, err := s.passwordChecker.Check(ctx, newPassword, userInfo...)
+	if err != nil {
+		log.Printf("警告：泄露密码查询失败，跳过检查: %v", err)
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-server/internal/models"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// failingBreaches 查询总是失败的泄露库
+type failingBreaches struct{}
+
+func (failingBreaches) Breached(ctx context.Context, password string) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestUserService_PasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	rules := password.Rules{MinLength: 10, Denylist: []string{"letmein12345"}, RejectUserInfo: true}
+
+	t.Run("注册时拒绝不符合策略的密码", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithPasswordChecker(password.NewChecker(rules, nil)))
+
+		_, err := service.Register(ctx, &models.RegisterRequest{
+			Username: "alice",
+			Email:    "alice@example.com",
+			Password: "alice1",
+		})
+		var policyErr *PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []password.Violation{
+			{Rule: password.RuleMinLength, Limit: 10},
+			{Rule: password.RuleUserInfo},
+		}, policyErr.Violations)
+		assert.Contains(t, err.Error(), "password must be at least 10 characters")
+		mockRepo.AssertNotCalled(t, "ExistsByEmail", mock.Anything)
+	})
+
+	t.Run("修改密码时拒绝不符合策略的新密码", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo,
+			WithPasswordHasher(password.Bcrypt{Cost: bcrypt.MinCost}),
+			WithPasswordChecker(password.NewChecker(rules, nil)))
+		user := factory.User().Build()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+		err := service.ChangePassword(ctx, user.ID, &models.ChangePasswordRequest{
+			OldPassword: factory.DefaultPassword,
+			NewPassword: "LetMeIn12345",
+		})
+		var policyErr *PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []password.Violation{{Rule: password.RuleDenylist}}, policyErr.Violations)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("泄露库查询失败时放行", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo,
+			WithPasswordHasher(password.Bcrypt{Cost: bcrypt.MinCost}),
+			WithPasswordChecker(password.NewChecker(rules, failingBreaches{})))
+		req := &models.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "correct-horse-42"}
+		mockRepo.On("ExistsByEmail", req.Email).Return(false, nil).Once()
+		mockRepo.On("ExistsByUsername", req.Username).Return(false, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil).Once()
+
+		user, err := service.Register(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.Username, user.Username)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -7,47 +7,213 @@ import (
 	"log"
 	"time"
 
+	domainevents "go-server/internal/events"
+	"go-server/internal/metrics"
 	"go-server/internal/models"
 	"go-server/internal/repositories"
+	"go-server/pkg/auth/password"
 	"go-server/pkg/cache"
+	"go-server/pkg/events"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService defines the interface for user business logic
 type UserService interface {
-	Register(req *models.RegisterRequest) (*models.User, error)
-	Login(req *models.LoginRequest) (*models.User, error)
-	GetByID(id string) (*models.User, error)
-	GetByEmail(email string) (*models.User, error)
-	GetAll(page, limit int) ([]*models.User, int64, error)
-	Update(id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error)
-	Delete(id string, requesterID string) error
-	ChangePassword(id string, req *models.ChangePasswordRequest) error
-	UpdateLastLogin(id string) error
-	ValidateCredentials(email, password string) (*models.User, error)
+	Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error)
+	Login(ctx context.Context, req *models.LoginRequest) (*models.User, error)
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetAll(ctx context.Context, page, limit int) ([]*models.User, int64, error)
+	Update(ctx context.Context, id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error)
+	Delete(ctx context.Context, id string, requesterID string) error
+	ChangePassword(ctx context.Context, id string, req *models.ChangePasswordRequest) error
+	UpdateLastLogin(ctx context.Context, id string) error
+	UpdateAvatar(ctx context.Context, id, avatarKey, avatarURL string) (*models.User, string, error)
+	ValidateCredentials(ctx context.Context, email, password string) (*models.User, error)
+	RequestEmailChange(ctx context.Context, id string, req *models.ChangeEmailRequest) (time.Time, error)
+	ConfirmEmailChange(ctx context.Context, id, token string) (*models.User, error)
+	ListUsers(ctx context.Context, filter repositories.UserFilter, page, limit int) ([]*models.User, int64, error)
+	StreamUsers(ctx context.Context, filter repositories.UserFilter, fn func(user *models.User) error) error
+	SetActive(ctx context.Context, id string, active bool, requesterID string) (*models.User, error)
+	ResetPassword(ctx context.Context, id string) (string, error)
+	AssignRoles(ctx context.Context, id string, roles []string, requesterID string) (*models.User, error)
+}
+
+// EventUserCreated 用户注册成功后发布的领域事件类型
+const EventUserCreated = "user.created"
+
+// UserCreatedEvent user.created 事件负载
+type UserCreatedEvent struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
-	cache    cache.Cache // 缓存实例用于显式缓存失效
+	userRepo  repositories.UserRepository
+	cache     cache.Cache          // 缓存实例用于显式缓存失效
+	publisher events.Publisher     // 集成事件发布器，与具体传输无关
+	emitter   domainevents.Emitter // 进程内领域事件，为空时不发出
+	passwords password.Hasher      // 密码哈希，为空时使用默认策略
+
+	passwordChecker *password.Checker // 新密码的密码策略，为空时不检查
+
+	cacheCodec cache.Codec                       // 缓存仓库的序列化方式，为空时使用 JSON
+	lastLogins repositories.LastLoginStore       // 缓冲最后登录时间，为空时每次登录直接更新数据库
+	onCacheErr func(operation string, err error) // 缓存仓库写入缓存失败时调用
+}
+
+// defaultPasswordHasher 未设置密码哈希时使用的默认策略
+var defaultPasswordHasher = password.Default()
+
+// UserServiceOption 用户服务可选配置
+type UserServiceOption func(*userService)
+
+// WithEventPublisher 设置集成事件发布器，事件通过消息总线投递给其他服务
+func WithEventPublisher(publisher events.Publisher) UserServiceOption {
+	return func(s *userService) {
+		s.publisher = publisher
+	}
+}
+
+// WithDomainEvents 设置进程内领域事件的接收方，用户创建、修改、删除和登录时发出事件
+func WithDomainEvents(emitter domainevents.Emitter) UserServiceOption {
+	return func(s *userService) {
+		s.emitter = emitter
+	}
+}
+
+// WithPasswordHasher 设置密码哈希，登录成功时弱于其策略的密码哈希会重新计算
+func WithPasswordHasher(hasher password.Hasher) UserServiceOption {
+	return func(s *userService) {
+		s.passwords = hasher
+	}
+}
+
+// hasher 返回密码哈希
+func (s *userService) hasher() password.Hasher {
+	if s.passwords == nil {
+		return defaultPasswordHasher
+	}
+	return s.passwords
+}
+
+// emit 发出领域事件
+func (s *userService) emit(ctx context.Context, event domainevents.Event) {
+	if s.emitter == nil {
+		return
+	}
+	s.emitter.Emit(ctx, event)
+}
+
+// emitUpdated 发出 user.updated 领域事件，requesterID 为用户本人时不记录执行者
+func (s *userService) emitUpdated(ctx context.Context, userID, requesterID string, fields ...string) {
+	if len(fields) == 0 {
+		return
+	}
+	if requesterID == userID {
+		requesterID = ""
+	}
+	s.emit(ctx, domainevents.UserUpdated{UserID: userID, ActorID: requesterID, Fields: fields, OccurredAt: time.Now().UTC()})
+}
+
+// applyOptions 应用可选配置
+func (s *userService) applyOptions(opts []UserServiceOption) *userService {
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithCacheCodec 设置缓存仓库的序列化方式，仅对带缓存的用户服务生效
+func WithCacheCodec(codec cache.Codec) UserServiceOption {
+	return func(s *userService) {
+		s.cacheCodec = codec
+	}
+}
+
+// WithLastLoginStore 将最后登录时间缓冲到存储中，由 LastLoginFlusher 批量写入数据库，仅对带缓存的用户服务生效
+func WithLastLoginStore(store repositories.LastLoginStore) UserServiceOption {
+	return func(s *userService) {
+		s.lastLogins = store
+	}
+}
+
+// WithCacheErrorHandler 设置缓存仓库序列化或写入缓存失败时的回调，仅对带缓存的用户服务生效
+func WithCacheErrorHandler(onError func(operation string, err error)) UserServiceOption {
+	return func(s *userService) {
+		s.onCacheErr = onError
+	}
+}
+
+// CacheTTLSetter 支持运行时调整缓存过期时间的服务，用于配置热重载
+type CacheTTLSetter interface {
+	SetCacheTTL(ttl time.Duration)
+}
+
+// SetCacheTTL 更新缓存仓库的过期时间，未启用缓存时为空操作
+func (s *userService) SetCacheTTL(ttl time.Duration) {
+	if cachedRepo, ok := s.userRepo.(*repositories.CachedUserRepository); ok {
+		cachedRepo.SetTTL(ttl)
+	}
+}
+
+// NegativeCacheTTLSetter 支持运行时调整“用户不存在”结果缓存时间的服务，用于配置热重载
+type NegativeCacheTTLSetter interface {
+	SetNegativeCacheTTL(ttl time.Duration)
+}
+
+// SetNegativeCacheTTL 更新缓存仓库中“用户不存在”结果的过期时间，0 表示不缓存，未启用缓存时为空操作
+func (s *userService) SetNegativeCacheTTL(ttl time.Duration) {
+	if cachedRepo, ok := s.userRepo.(*repositories.CachedUserRepository); ok {
+		cachedRepo.SetNegativeTTL(ttl)
+	}
+}
+
+// CacheMethodTTLSetter 支持运行时按仓储方法调整缓存过期时间的服务，用于配置热重载
+type CacheMethodTTLSetter interface {
+	SetCacheMethodTTLs(ttls map[string]time.Duration)
+}
+
+// SetCacheMethodTTLs 替换缓存仓库按方法覆盖的过期时间，未启用缓存时为空操作
+func (s *userService) SetCacheMethodTTLs(ttls map[string]time.Duration) {
+	if cachedRepo, ok := s.userRepo.(*repositories.CachedUserRepository); ok {
+		cachedRepo.SetMethodTTLs(ttls)
+	}
+}
+
+// CacheMetricsProvider 提供缓存仓库指标收集器的服务，用于导出到 Prometheus
+type CacheMetricsProvider interface {
+	CacheMetrics() *metrics.CacheMetrics
+}
+
+// CacheMetrics 返回缓存仓库的指标收集器，未启用缓存时返回 nil
+func (s *userService) CacheMetrics() *metrics.CacheMetrics {
+	if cachedRepo, ok := s.userRepo.(*repositories.CachedUserRepository); ok {
+		return cachedRepo.MetricsCollector()
+	}
+	return nil
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repositories.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repositories.UserRepository, opts ...UserServiceOption) UserService {
+	return (&userService{userRepo: userRepo}).applyOptions(opts)
 }
 
 // NewUserServiceWithCache creates a new user service with caching support
 // 使用缓存仓库装饰器包装基础仓库以提供缓存功能，并注入缓存实例用于显式失效
-func NewUserServiceWithCache(baseRepo repositories.UserRepository, cache cache.Cache) UserService {
+func NewUserServiceWithCache(baseRepo repositories.UserRepository, cache cache.Cache, opts ...UserServiceOption) UserService {
+	s := (&userService{cache: cache}).applyOptions(opts)
+
 	// 使用缓存仓库装饰器包装基础仓库
-	cachedRepo := repositories.NewCachedUserRepository(baseRepo, cache)
-	return &userService{
-		userRepo: cachedRepo,
-		cache:    cache,
-	}
+	s.userRepo = repositories.NewCachedUserRepository(baseRepo, cache,
+		repositories.WithCacheCodec(s.cacheCodec),
+		repositories.WithLastLoginStore(s.lastLogins),
+		repositories.WithCacheErrorHandler(s.onCacheErr),
+	)
+	return s
 }
 
 // NewUserServiceWithCacheAndExplicitInvalidation 创建一个带有缓存支持和显式缓存失效的用户服务
@@ -62,10 +228,14 @@ func NewUserServiceWithCacheAndExplicitInvalidation(baseRepo repositories.UserRe
 }
 
 // Register creates a new user
-func (s *userService) Register(req *models.RegisterRequest) (*models.User, error) {
+func (s *userService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+	if err := s.checkNewPassword(ctx, req.Password, req.Username, req.Email, req.FirstName, req.LastName); err != nil {
+		return nil, err
+	}
+
 	// 检查邮箱是否已存在 - 如果使用缓存仓库，此操作将被缓存
 	// Check if user already exists by email - this operation will be cached if using cached repository
-	exists, err := s.userRepo.ExistsByEmail(req.Email)
+	exists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if user exists: %w", err)
 	}
@@ -75,7 +245,7 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.User, error
 
 	// 检查用户名是否已存在 - 如果使用缓存仓库，此操作将被缓存
 	// Check if username already exists - this operation will be cached if using cached repository
-	exists, err = s.userRepo.ExistsByUsername(req.Username)
+	exists, err = s.userRepo.ExistsByUsername(ctx, req.Username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if username exists: %w", err)
 	}
@@ -84,7 +254,7 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.User, error
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher().Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -94,7 +264,7 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.User, error
 		ID:        uuid.New().String(),
 		Username:  req.Username,
 		Email:     req.Email,
-		Password:  string(hashedPassword),
+		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		IsActive:  true,
@@ -105,47 +275,76 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.User, error
 
 	// 创建用户 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Create user - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// 显式缓存失效 - 确保所有相关缓存条目都被立即失效
 	// Explicit cache invalidation - ensure all related cache entries are invalidated immediately
-	s.invalidateUserCaches(user)
+	s.invalidateUserCaches(ctx, user)
+
+	// 发布用户创建事件 - 发布失败不影响注册结果
+	s.publishUserCreated(ctx, user)
+	s.emit(ctx, domainevents.UserCreated{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		OccurredAt: user.CreatedAt.UTC(),
+	})
 
 	// Clear password before returning
 	user.Password = ""
 	return user, nil
 }
 
+// publishUserCreated 发布 user.created 领域事件
+func (s *userService) publishUserCreated(ctx context.Context, user *models.User) {
+	if s.publisher == nil {
+		return
+	}
+
+	payload := UserCreatedEvent{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}
+
+	if err := events.PublishEvent(ctx, s.publisher, EventUserCreated, payload); err != nil {
+		log.Printf("警告：发布用户创建事件失败 (用户ID: %s): %v", user.ID, err)
+	}
+}
+
 // Login validates user credentials
-func (s *userService) Login(req *models.LoginRequest) (*models.User, error) {
-	user, err := s.ValidateCredentials(req.Email, req.Password)
+func (s *userService) Login(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
+	user, err := s.ValidateCredentials(ctx, req.Email, req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	// 更新最后登录时间 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Update last login - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
 		// Log error but don't fail login
 		fmt.Printf("Warning: Failed to update last login: %v\n", err)
 	} else {
 		// 显式缓存失效 - 登录成功后失效用户的缓存条目以确保登录状态更新
 		// Explicit cache invalidation - invalidate user's cache entries after successful login to ensure login status is updated
-		s.invalidateUserCachesByID(user.ID)
+		s.invalidateUserCachesByID(ctx, user.ID)
 	}
 
+	s.emit(ctx, domainevents.UserLoggedIn{UserID: user.ID, OccurredAt: time.Now().UTC()})
+
 	// Clear password before returning
 	user.Password = ""
 	return user, nil
 }
 
 // ValidateCredentials validates user credentials
-func (s *userService) ValidateCredentials(email, password string) (*models.User, error) {
+func (s *userService) ValidateCredentials(ctx context.Context, email, password string) (*models.User, error) {
 	// 通过邮箱获取用户 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get user by email - if using cached repository, this operation will retrieve user data from cache
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, errors.New("invalid credentials")
 	}
@@ -156,18 +355,40 @@ func (s *userService) ValidateCredentials(email, password string) (*models.User,
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	if err := s.hasher().Verify(user.Password, password); err != nil {
 		return nil, errors.New("invalid credentials")
 	}
 
+	s.rehashPassword(ctx, user, password)
 	return user, nil
 }
 
+// rehashPassword 密码哈希的算法或参数弱于当前策略时，用刚验证通过的明文密码按当前策略重新计算并保存
+// 失败只记录日志，不影响本次验证，下次登录时重试
+func (s *userService) rehashPassword(ctx context.Context, user *models.User, plaintext string) {
+	hasher := s.hasher()
+	if !hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := hasher.Hash(plaintext)
+	if err != nil {
+		log.Printf("警告：重新计算密码哈希失败 (用户ID: %s): %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		log.Printf("警告：保存重新计算的密码哈希失败 (用户ID: %s): %v", user.ID, err)
+		return
+	}
+	s.invalidateUserCaches(ctx, user)
+}
+
 // GetByID gets a user by ID
-func (s *userService) GetByID(id string) (*models.User, error) {
+func (s *userService) GetByID(ctx context.Context, id string) (*models.User, error) {
 	// 通过ID获取用户 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get user by ID - if using cached repository, this operation will retrieve user data from cache
-	user, err := s.userRepo.GetByID(id)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -178,10 +399,10 @@ func (s *userService) GetByID(id string) (*models.User, error) {
 }
 
 // GetByEmail gets a user by email
-func (s *userService) GetByEmail(email string) (*models.User, error) {
+func (s *userService) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	// 通过邮箱获取用户 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get user by email - if using cached repository, this operation will retrieve user data from cache
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -192,11 +413,11 @@ func (s *userService) GetByEmail(email string) (*models.User, error) {
 }
 
 // GetAll gets all users with pagination
-func (s *userService) GetAll(page, limit int) ([]*models.User, int64, error) {
+func (s *userService) GetAll(ctx context.Context, page, limit int) ([]*models.User, int64, error) {
 	offset := (page - 1) * limit
 	// 获取所有用户（带分页）- 如果使用缓存仓库，此操作将从缓存中获取用户列表数据
 	// Get all users with pagination - if using cached repository, this operation will retrieve user list data from cache
-	users, total, err := s.userRepo.GetAll(offset, limit)
+	users, total, err := s.userRepo.GetAll(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -210,10 +431,10 @@ func (s *userService) GetAll(page, limit int) ([]*models.User, int64, error) {
 }
 
 // Update updates a user
-func (s *userService) Update(id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error) {
+func (s *userService) Update(ctx context.Context, id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error) {
 	// 获取现有用户 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get existing user - if using cached repository, this operation will retrieve user data from cache
-	user, err := s.userRepo.GetByID(id)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +444,7 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, requester
 	if id != requesterID {
 		// 获取请求者信息 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 		// Get requester info - if using cached repository, this operation will retrieve user data from cache
-		requester, err := s.userRepo.GetByID(requesterID)
+		requester, err := s.userRepo.GetByID(ctx, requesterID)
 		if err != nil {
 			return nil, errors.New("unauthorized")
 		}
@@ -234,8 +455,9 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, requester
 
 	// 检查新用户名是否已被占用 - 如果使用缓存仓库，此操作将被缓存
 	// Check if new username is taken - this operation will be cached if using cached repository
+	var changed []string
 	if req.Username != "" && req.Username != user.Username {
-		exists, err := s.userRepo.ExistsByUsername(req.Username)
+		exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check if username exists: %w", err)
 		}
@@ -243,41 +465,69 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, requester
 			return nil, errors.New("username already taken")
 		}
 		user.Username = req.Username
+		changed = append(changed, domainevents.FieldUsername)
 	}
 
 	// Update fields
-	if req.FirstName != "" {
+	if req.FirstName != "" && req.FirstName != user.FirstName {
 		user.FirstName = req.FirstName
+		changed = append(changed, domainevents.FieldFirstName)
 	}
-	if req.LastName != "" {
+	if req.LastName != "" && req.LastName != user.LastName {
 		user.LastName = req.LastName
+		changed = append(changed, domainevents.FieldLastName)
 	}
-	if req.Avatar != "" {
+	if req.Avatar != "" && req.Avatar != user.Avatar {
 		user.Avatar = req.Avatar
+		changed = append(changed, domainevents.FieldAvatar)
 	}
 
 	user.UpdatedAt = time.Now()
 
 	// 更新用户 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Update user - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
 	// 显式缓存失效 - 确保所有相关缓存条目都被立即失效
 	// Explicit cache invalidation - ensure all related cache entries are invalidated immediately
-	s.invalidateUserCaches(user)
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, requesterID, changed...)
 
 	// Clear password before returning
 	user.Password = ""
 	return user, nil
 }
 
+// UpdateAvatar 更新用户头像，返回更新后的用户和旧的头像对象键（便于调用方清理旧对象）
+func (s *userService) UpdateAvatar(ctx context.Context, id, avatarKey, avatarURL string) (*models.User, string, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	previousKey := user.AvatarKey
+	user.AvatarKey = avatarKey
+	user.Avatar = avatarURL
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, "", fmt.Errorf("failed to update avatar: %w", err)
+	}
+
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, user.ID, domainevents.FieldAvatar)
+
+	user.Password = ""
+	return user, previousKey, nil
+}
+
 // Delete deletes a user
-func (s *userService) Delete(id string, requesterID string) error {
+func (s *userService) Delete(ctx context.Context, id string, requesterID string) error {
 	// 获取请求者以检查权限 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get requester to check permissions - if using cached repository, this operation will retrieve user data from cache
-	requester, err := s.userRepo.GetByID(requesterID)
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
 	if err != nil {
 		return errors.New("unauthorized")
 	}
@@ -289,7 +539,7 @@ func (s *userService) Delete(id string, requesterID string) error {
 
 	// 删除用户 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Delete user - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.Delete(id); err != nil {
+	if err := s.userRepo.Delete(ctx, id); err != nil {
 		return err
 	}
 
@@ -297,20 +547,24 @@ func (s *userService) Delete(id string, requesterID string) error {
 	// 由于用户已被删除，我们只能通过ID来失效缓存
 	// Explicit cache invalidation - ensure all related cache entries are invalidated immediately
 	// Since user is deleted, we can only invalidate cache by ID
-	s.invalidateUserCachesByID(id)
+	s.invalidateUserCachesByID(ctx, id)
+
+	actorID := requesterID
+	if actorID == id {
+		actorID = ""
+	}
+	s.emit(ctx, domainevents.UserDeleted{UserID: id, ActorID: actorID, OccurredAt: time.Now().UTC()})
 
 	return nil
 }
 
 // invalidateUserCaches 失效与用户相关的所有缓存条目
 // Invalidate all cache entries related to a user
-func (s *userService) invalidateUserCaches(user *models.User) {
+func (s *userService) invalidateUserCaches(ctx context.Context, user *models.User) {
 	if s.cache == nil {
 		return // 如果没有缓存实例，跳过缓存失效
 	}
 
-	ctx := context.Background()
-
 	// 构建需要失效的缓存键列表
 	// Build list of cache keys to invalidate
 	keys := []string{
@@ -340,13 +594,11 @@ func (s *userService) invalidateUserCaches(user *models.User) {
 // 当用户对象不可用时使用（如删除操作）
 // Invalidate cache entries by user ID
 // Used when user object is not available (e.g., in delete operations)
-func (s *userService) invalidateUserCachesByID(userID string) {
+func (s *userService) invalidateUserCachesByID(ctx context.Context, userID string) {
 	if s.cache == nil {
 		return // 如果没有缓存实例，跳过缓存失效
 	}
 
-	ctx := context.Background()
-
 	// 通过ID失效用户缓存
 	// Invalidate user cache by ID
 	if err := s.cache.Delete(ctx, fmt.Sprintf("user:id:%s", userID)); err != nil {
@@ -369,57 +621,48 @@ func (s *userService) invalidateUserListCaches(ctx context.Context) {
 		return
 	}
 
-	// 失效用户列表缓存模式
-	// Invalidate user list cache patterns
-	patterns := []string{"users:all:*", "users:count"}
-
-	for _, pattern := range patterns {
-		keys, err := s.cache.Keys(ctx, pattern)
-		if err != nil {
-			log.Printf("警告：获取缓存键失败 (模式: %s): %v", pattern, err)
-			// Warning: Failed to get cache keys (Pattern: %s): %v
-			continue
-		}
-
-		if len(keys) > 0 {
-			if err := s.cache.DeleteMultiple(ctx, keys); err != nil {
-				log.Printf("警告：用户列表缓存失效失败 (模式: %s, 键数量: %d): %v", pattern, len(keys), err)
-				// Warning: Failed to invalidate user list cache (Pattern: %s, Key count: %d): %v
-			} else {
-				log.Printf("成功失效用户列表缓存 (模式: %s, 键数量: %d)", pattern, len(keys))
-				// Successfully invalidated user list cache (Pattern: %s, Key count: %d)
-			}
-		}
+	// 按标签失效用户列表和计数缓存，无需扫描键空间
+	// Invalidate user list and count caches by tag without scanning the key space
+	if err := s.cache.InvalidateTag(ctx, repositories.UserListCacheTag); err != nil {
+		log.Printf("警告：用户列表缓存失效失败 (标签: %s): %v", repositories.UserListCacheTag, err)
+		// Warning: Failed to invalidate user list cache (Tag: %s): %v
+	} else {
+		log.Printf("成功失效用户列表缓存 (标签: %s)", repositories.UserListCacheTag)
+		// Successfully invalidated user list cache (Tag: %s)
 	}
 }
 
 // ChangePassword changes a user's password
-func (s *userService) ChangePassword(id string, req *models.ChangePasswordRequest) error {
+func (s *userService) ChangePassword(ctx context.Context, id string, req *models.ChangePasswordRequest) error {
 	// 获取用户 - 如果使用缓存仓库，此操作将从缓存中获取用户数据
 	// Get user - if using cached repository, this operation will retrieve user data from cache
-	user, err := s.userRepo.GetByID(id)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Verify old password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if err := s.hasher().Verify(user.Password, req.OldPassword); err != nil {
 		return errors.New("old password is incorrect")
 	}
 
+	if err := s.checkNewPassword(ctx, req.NewPassword, user.Username, user.Email, user.FirstName, user.LastName); err != nil {
+		return err
+	}
+
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher().Hash(req.NewPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash new password: %w", err)
 	}
 
 	// Update password
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 	user.UpdatedAt = time.Now()
 
 	// 更新用户密码 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Update user password - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
@@ -427,22 +670,23 @@ func (s *userService) ChangePassword(id string, req *models.ChangePasswordReques
 	// 这是重要的安全措施，确保密码更改立即生效
 	// Explicit cache invalidation - invalidate all related cache entries immediately after password change
 	// This is an important security measure to ensure password changes take effect immediately
-	s.invalidateUserCaches(user)
+	s.invalidateUserCaches(ctx, user)
+	s.emitUpdated(ctx, user.ID, user.ID, domainevents.FieldPassword)
 
 	return nil
 }
 
 // UpdateLastLogin updates the last login time for a user
-func (s *userService) UpdateLastLogin(id string) error {
+func (s *userService) UpdateLastLogin(ctx context.Context, id string) error {
 	// 更新用户最后登录时间 - 如果使用缓存仓库，相关的缓存条目将被自动失效
 	// Update user last login time - if using cached repository, related cache entries will be automatically invalidated
-	if err := s.userRepo.UpdateLastLogin(id); err != nil {
+	if err := s.userRepo.UpdateLastLogin(ctx, id); err != nil {
 		return err
 	}
 
 	// 显式缓存失效 - 最后登录时间更新后立即失效相关缓存条目
 	// Explicit cache invalidation - invalidate related cache entries immediately after last login time update
-	s.invalidateUserCachesByID(id)
+	s.invalidateUserCachesByID(ctx, id)
 
 	return nil
 }
@@ -1,11 +1,17 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/events"
+	"go-server/pkg/testutil/factory"
+	domainevents "go-server/internal/events"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -19,12 +25,12 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *models.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetByID(id string) (*models.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -32,7 +38,7 @@ func (m *MockUserRepository) GetByID(id string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -40,7 +46,7 @@ func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByUsername(username string) (*models.User, error) {
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	args := m.Called(username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -48,7 +54,7 @@ func (m *MockUserRepository) GetByUsername(username string) (*models.User, error
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetAll(offset, limit int) ([]*models.User, int64, error) {
+func (m *MockUserRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.User, int64, error) {
 	args := m.Called(offset, limit)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
@@ -56,55 +62,61 @@ func (m *MockUserRepository) GetAll(offset, limit int) ([]*models.User, int64, e
 	return args.Get(0).([]*models.User), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockUserRepository) Update(user *models.User) error {
+func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Delete(id string) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) UpdateLastLogin(id string) error {
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) ExistsByEmail(email string) (bool, error) {
+func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) ExistsByUsername(username string) (bool, error) {
+func (m *MockUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	args := m.Called(username)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) Count() (int64, error) {
+func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called()
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockUserRepository) Search(ctx context.Context, filter repositories.UserFilter, offset, limit int) ([]*models.User, int64, error) {
+	args := m.Called(filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*models.User), args.Get(1).(int64), args.Error(2)
+}
 
-// 创建测试用户的辅助函数
-func createTestUser(email, username string) *models.User {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+func (m *MockUserRepository) SetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	args := m.Called(id, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
 
-	return &models.User{
-		ID:        uuid.New().String(),
-		Username:  username,
-		Email:     email,
-		FirstName: "Test",
-		LastName:  "User",
-		Password:  string(hashedPassword),
-		IsActive:  true,
-		IsAdmin:   false,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+func (m *MockUserRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) (*models.User, error) {
+	args := m.Called(id, isAdmin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
+	return args.Get(0).(*models.User), args.Error(1)
 }
 
+
 func TestNewUserService(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
@@ -134,7 +146,49 @@ func TestNewUserServiceWithCache(t *testing.T) {
 	assert.Nil(t, userService.cache)
 }
 
+func TestUserService_Register_PublishesUserCreatedEvent(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	bus := events.NewMemoryBus()
+	service := NewUserService(mockRepo, WithEventPublisher(bus))
+
+	received := make(chan *events.Event, 1)
+	_, err := bus.Subscribe(context.Background(), EventUserCreated, func(ctx context.Context, event *events.Event) error {
+		received <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	req := &models.RegisterRequest{
+		Username: "eventuser",
+		Email:    "event@example.com",
+		Password: "password123",
+	}
+	mockRepo.On("ExistsByEmail", req.Email).Return(false, nil).Once()
+	mockRepo.On("ExistsByUsername", req.Username).Return(false, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil).Once()
+
+	user, err := service.Register(ctx, req)
+	require.NoError(t, err)
+
+	select {
+	case event := <-received:
+		assert.Equal(t, EventUserCreated, event.Type)
+
+		var payload UserCreatedEvent
+		require.NoError(t, event.Decode(&payload))
+		assert.Equal(t, user.ID, payload.UserID)
+		assert.Equal(t, req.Email, payload.Email)
+		assert.Equal(t, req.Username, payload.Username)
+	case <-time.After(time.Second):
+		t.Fatal("user.created event was not published")
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUserService_Register(t *testing.T) {
+	ctx := context.Background()
 	t.Run("成功注册用户", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		service := NewUserService(mockRepo)
@@ -158,7 +212,7 @@ func TestUserService_Register(t *testing.T) {
 			user.UpdatedAt = time.Now()
 		})
 
-		user, err := service.Register(req)
+		user, err := service.Register(ctx, req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -181,7 +235,7 @@ func TestUserService_Register(t *testing.T) {
 
 		mockRepo.On("ExistsByEmail", req.Email).Return(true, nil).Once()
 
-		user, err := service.Register(req)
+		user, err := service.Register(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -202,7 +256,7 @@ func TestUserService_Register(t *testing.T) {
 		mockRepo.On("ExistsByEmail", req.Email).Return(false, nil).Once()
 		mockRepo.On("ExistsByUsername", req.Username).Return(true, nil).Once()
 
-		user, err := service.Register(req)
+		user, err := service.Register(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -224,7 +278,7 @@ func TestUserService_Register(t *testing.T) {
 		mockRepo.On("ExistsByUsername", req.Username).Return(false, nil).Once()
 		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(errors.New("database error")).Once()
 
-		user, err := service.Register(req)
+		user, err := service.Register(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -235,6 +289,7 @@ func TestUserService_Register(t *testing.T) {
 }
 
 func TestUserService_Login(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
@@ -244,11 +299,11 @@ func TestUserService_Login(t *testing.T) {
 			Password: "password123",
 		}
 
-		user := createTestUser(req.Email, "testuser")
+		user := factory.User().WithEmail(req.Email).WithUsername("testuser").Build()
 
 		mockRepo.On("GetByEmail", req.Email).Return(user, nil)
 
-		loggedInUser, err := service.Login(req)
+		loggedInUser, err := service.Login(ctx, req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, loggedInUser)
@@ -266,7 +321,7 @@ func TestUserService_Login(t *testing.T) {
 
 		mockRepo.On("GetByEmail", req.Email).Return(nil, errors.New("用户不存在"))
 
-		user, err := service.Login(req)
+		user, err := service.Login(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -281,11 +336,11 @@ func TestUserService_Login(t *testing.T) {
 			Password: "wrongpassword",
 		}
 
-		user := createTestUser(req.Email, "testuser")
+		user := factory.User().WithEmail(req.Email).WithUsername("testuser").Build()
 
 		mockRepo.On("GetByEmail", req.Email).Return(user, nil)
 
-		loggedInUser, err := service.Login(req)
+		loggedInUser, err := service.Login(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, loggedInUser)
@@ -300,12 +355,12 @@ func TestUserService_Login(t *testing.T) {
 			Password: "password123",
 		}
 
-		user := createTestUser(req.Email, "testuser")
+		user := factory.User().WithEmail(req.Email).WithUsername("testuser").Build()
 		user.IsActive = false
 
 		mockRepo.On("GetByEmail", req.Email).Return(user, nil)
 
-		loggedInUser, err := service.Login(req)
+		loggedInUser, err := service.Login(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, loggedInUser)
@@ -316,17 +371,18 @@ func TestUserService_Login(t *testing.T) {
 }
 
 func TestUserService_GetByID(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
 	t.Run("成功获取用户", func(t *testing.T) {
 		userID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		mockRepo.On("GetByID", userID).Return(user, nil)
 
-		result, err := service.GetByID(userID)
+		result, err := service.GetByID(ctx, userID)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -340,7 +396,7 @@ func TestUserService_GetByID(t *testing.T) {
 
 		mockRepo.On("GetByID", userID).Return(nil, errors.New("用户不存在"))
 
-		result, err := service.GetByID(userID)
+		result, err := service.GetByID(ctx, userID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -350,6 +406,7 @@ func TestUserService_GetByID(t *testing.T) {
 }
 
 func TestUserService_GetAll(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
@@ -358,14 +415,14 @@ func TestUserService_GetAll(t *testing.T) {
 		offset := (page - 1) * limit
 
 		users := []*models.User{
-			createTestUser("user1@example.com", "user1"),
-			createTestUser("user2@example.com", "user2"),
+			factory.User().WithEmail("user1@example.com").WithUsername("user1").Build(),
+			factory.User().WithEmail("user2@example.com").WithUsername("user2").Build(),
 		}
 		total := int64(2)
 
 		mockRepo.On("GetAll", offset, limit).Return(users, total, nil)
 
-		result, totalResult, err := service.GetAll(page, limit)
+		result, totalResult, err := service.GetAll(ctx, page, limit)
 
 		assert.NoError(t, err)
 		assert.Len(t, result, 2)
@@ -380,7 +437,7 @@ func TestUserService_GetAll(t *testing.T) {
 
 		mockRepo.On("GetAll", offset, limit).Return(nil, int64(0), errors.New("数据库错误"))
 
-		result, totalResult, err := service.GetAll(page, limit)
+		result, totalResult, err := service.GetAll(ctx, page, limit)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -391,12 +448,13 @@ func TestUserService_GetAll(t *testing.T) {
 }
 
 func TestUserService_Update(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
 	t.Run("成功更新用户", func(t *testing.T) {
 		userID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		req := &models.UpdateUserRequest{
@@ -407,7 +465,7 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.On("GetByID", userID).Return(user, nil)
 		mockRepo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
 
-		result, err := service.Update(userID, req, userID)
+		result, err := service.Update(ctx, userID, req, userID)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -425,7 +483,7 @@ func TestUserService_Update(t *testing.T) {
 
 		mockRepo.On("GetByID", userID).Return(nil, errors.New("用户不存在"))
 
-		result, err := service.Update(userID, req, userID)
+		result, err := service.Update(ctx, userID, req, userID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -436,7 +494,7 @@ func TestUserService_Update(t *testing.T) {
 	t.Run("权限不足", func(t *testing.T) {
 		userID := uuid.New().String()
 		requesterID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		req := &models.UpdateUserRequest{
@@ -445,7 +503,7 @@ func TestUserService_Update(t *testing.T) {
 
 		mockRepo.On("GetByID", userID).Return(user, nil)
 
-		result, err := service.Update(userID, req, requesterID)
+		result, err := service.Update(ctx, userID, req, requesterID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -456,18 +514,19 @@ func TestUserService_Update(t *testing.T) {
 }
 
 func TestUserService_Delete(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
 	t.Run("成功删除用户", func(t *testing.T) {
 		userID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		mockRepo.On("GetByID", userID).Return(user, nil)
 		mockRepo.On("Delete", userID).Return(nil)
 
-		err := service.Delete(userID, userID)
+		err := service.Delete(ctx, userID, userID)
 
 		assert.NoError(t, err)
 
@@ -479,7 +538,7 @@ func TestUserService_Delete(t *testing.T) {
 
 		mockRepo.On("GetByID", userID).Return(nil, errors.New("用户不存在"))
 
-		err := service.Delete(userID, userID)
+		err := service.Delete(ctx, userID, userID)
 
 		assert.Error(t, err)
 
@@ -489,12 +548,12 @@ func TestUserService_Delete(t *testing.T) {
 	t.Run("权限不足", func(t *testing.T) {
 		userID := uuid.New().String()
 		requesterID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		mockRepo.On("GetByID", userID).Return(user, nil)
 
-		err := service.Delete(userID, requesterID)
+		err := service.Delete(ctx, userID, requesterID)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "权限不足")
@@ -504,12 +563,13 @@ func TestUserService_Delete(t *testing.T) {
 }
 
 func TestUserService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
 	t.Run("成功修改密码", func(t *testing.T) {
 		userID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		req := &models.ChangePasswordRequest{
@@ -520,7 +580,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		mockRepo.On("GetByID", userID).Return(user, nil)
 		mockRepo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
 
-		err := service.ChangePassword(userID, req)
+		err := service.ChangePassword(ctx, userID, req)
 
 		assert.NoError(t, err)
 
@@ -529,7 +589,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 	t.Run("当前密码错误", func(t *testing.T) {
 		userID := uuid.New().String()
-		user := createTestUser("test@example.com", "testuser")
+		user := factory.User().WithEmail("test@example.com").WithUsername("testuser").Build()
 		user.ID = userID
 
 		req := &models.ChangePasswordRequest{
@@ -539,7 +599,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 		mockRepo.On("GetByID", userID).Return(user, nil)
 
-		err := service.ChangePassword(userID, req)
+		err := service.ChangePassword(ctx, userID, req)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "当前密码错误")
@@ -549,6 +609,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 }
 
 func TestUserService_UpdateLastLogin(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
 
@@ -557,7 +618,7 @@ func TestUserService_UpdateLastLogin(t *testing.T) {
 
 		mockRepo.On("UpdateLastLogin", userID).Return(nil)
 
-		err := service.UpdateLastLogin(userID)
+		err := service.UpdateLastLogin(ctx, userID)
 
 		assert.NoError(t, err)
 
@@ -569,7 +630,7 @@ func TestUserService_UpdateLastLogin(t *testing.T) {
 
 		mockRepo.On("UpdateLastLogin", userID).Return(errors.New("数据库错误"))
 
-		err := service.UpdateLastLogin(userID)
+		err := service.UpdateLastLogin(ctx, userID)
 
 		assert.Error(t, err)
 
@@ -578,17 +639,19 @@ func TestUserService_UpdateLastLogin(t *testing.T) {
 }
 
 func TestUserService_ValidateCredentials(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	// 与测试数据的密码哈希成本相同，验证成功时不重新哈希
+	service := NewUserService(mockRepo, WithPasswordHasher(password.Bcrypt{Cost: bcrypt.MinCost}))
 
 	t.Run("验证成功", func(t *testing.T) {
 		email := "test@example.com"
 		password := "password123"
-		user := createTestUser(email, "testuser")
+		user := factory.User().WithEmail(email).WithUsername("testuser").Build()
 
 		mockRepo.On("GetByEmail", email).Return(user, nil)
 
-		result, err := service.ValidateCredentials(email, password)
+		result, err := service.ValidateCredentials(ctx, email, password)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -603,7 +666,7 @@ func TestUserService_ValidateCredentials(t *testing.T) {
 
 		mockRepo.On("GetByEmail", email).Return(nil, errors.New("用户不存在"))
 
-		result, err := service.ValidateCredentials(email, password)
+		result, err := service.ValidateCredentials(ctx, email, password)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -614,11 +677,11 @@ func TestUserService_ValidateCredentials(t *testing.T) {
 	t.Run("密码错误", func(t *testing.T) {
 		email := "test@example.com"
 		password := "wrongpassword"
-		user := createTestUser(email, "testuser")
+		user := factory.User().WithEmail(email).WithUsername("testuser").Build()
 
 		mockRepo.On("GetByEmail", email).Return(user, nil)
 
-		result, err := service.ValidateCredentials(email, password)
+		result, err := service.ValidateCredentials(ctx, email, password)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -627,3 +690,149 @@ func TestUserService_ValidateCredentials(t *testing.T) {
 	})
 }
 
+func TestUserService_ValidateCredentials_RehashesPassword(t *testing.T) {
+	ctx := context.Background()
+	hasher, err := password.NewManager(password.Policy{
+		Algorithm: password.AlgorithmArgon2id,
+		Argon2:    password.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1},
+	})
+	require.NoError(t, err)
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, WithPasswordHasher(hasher))
+	isArgon2id := mock.MatchedBy(func(u *models.User) bool {
+		return password.Identify(u.Password) == password.AlgorithmArgon2id
+	})
+
+	t.Run("旧算法的哈希在验证成功后按当前策略重新计算", func(t *testing.T) {
+		user := factory.User().Build()
+		mockRepo.On("GetByEmail", user.Email).Return(user, nil).Once()
+		mockRepo.On("Update", isArgon2id).Return(nil).Once()
+
+		result, err := service.ValidateCredentials(ctx, user.Email, factory.DefaultPassword)
+		require.NoError(t, err)
+		assert.NoError(t, hasher.Verify(result.Password, factory.DefaultPassword))
+		assert.False(t, hasher.NeedsRehash(result.Password))
+
+		// 已符合当前策略的哈希不再重新计算
+		mockRepo.On("GetByEmail", user.Email).Return(result, nil).Once()
+		_, err = service.ValidateCredentials(ctx, user.Email, factory.DefaultPassword)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("密码错误时不重新计算", func(t *testing.T) {
+		user := factory.User().Build()
+		mockRepo.On("GetByEmail", user.Email).Return(user, nil).Once()
+
+		_, err := service.ValidateCredentials(ctx, user.Email, "wrongpassword")
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("保存失败不影响验证", func(t *testing.T) {
+		user := factory.User().Build()
+		mockRepo.On("GetByEmail", user.Email).Return(user, nil).Once()
+		mockRepo.On("Update", isArgon2id).Return(errors.New("database unavailable")).Once()
+
+		result, err := service.ValidateCredentials(ctx, user.Email, factory.DefaultPassword)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// recordingEmitter 同步记录领域事件
+type recordingEmitter struct {
+	events []domainevents.Event
+}
+
+func (r *recordingEmitter) Emit(ctx context.Context, event domainevents.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestUserService_EmitsDomainEvents(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	newUser := func() *models.User {
+		return &models.User{
+			ID:        "user-1",
+			Username:  "alice",
+			Email:     "alice@example.com",
+			Password:  string(hashedPassword),
+			FirstName: "Alice",
+			IsActive:  true,
+		}
+	}
+
+	t.Run("注册发出 user.created", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		emitter := &recordingEmitter{}
+		service := NewUserService(mockRepo, WithDomainEvents(emitter))
+
+		req := &models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "password123"}
+		mockRepo.On("ExistsByEmail", req.Email).Return(false, nil).Once()
+		mockRepo.On("ExistsByUsername", req.Username).Return(false, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil).Once()
+
+		user, err := service.Register(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, emitter.events, 1)
+		created, ok := emitter.events[0].(domainevents.UserCreated)
+		require.True(t, ok)
+		assert.Equal(t, user.ID, created.UserID)
+		assert.Equal(t, req.Email, created.Email)
+	})
+
+	t.Run("登录发出 user.logged_in", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		emitter := &recordingEmitter{}
+		service := NewUserService(mockRepo, WithDomainEvents(emitter))
+
+		mockRepo.On("GetByEmail", "alice@example.com").Return(newUser(), nil).Once()
+		mockRepo.On("UpdateLastLogin", "user-1").Return(nil).Once()
+
+		_, err := service.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.Len(t, emitter.events, 1)
+		assert.Equal(t, domainevents.EventUserLoggedIn, emitter.events[0].EventName())
+
+		// 登录失败不发出事件
+		mockRepo.On("GetByEmail", "alice@example.com").Return(newUser(), nil).Once()
+		_, err = service.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "wrong"})
+		require.Error(t, err)
+		assert.Len(t, emitter.events, 1)
+	})
+
+	t.Run("更新只列出实际修改的字段", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		emitter := &recordingEmitter{}
+		service := NewUserService(mockRepo, WithDomainEvents(emitter))
+
+		mockRepo.On("GetByID", "user-1").Return(newUser(), nil).Once()
+		mockRepo.On("Update", mock.AnythingOfType("*models.User")).Return(nil).Once()
+
+		_, err := service.Update(ctx, "user-1", &models.UpdateUserRequest{FirstName: "Alice", LastName: "Smith"}, "user-1")
+		require.NoError(t, err)
+		require.Len(t, emitter.events, 1)
+		updated, ok := emitter.events[0].(domainevents.UserUpdated)
+		require.True(t, ok)
+		assert.Equal(t, []string{domainevents.FieldLastName}, updated.Fields)
+		assert.Empty(t, updated.ActorID, "用户本人修改时不记录执行者")
+	})
+
+	t.Run("管理员删除发出 user.deleted", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		emitter := &recordingEmitter{}
+		service := NewUserService(mockRepo, WithDomainEvents(emitter))
+
+		mockRepo.On("GetByID", "admin-1").Return(&models.User{ID: "admin-1", IsAdmin: true}, nil).Once()
+		mockRepo.On("Delete", "user-1").Return(nil).Once()
+
+		require.NoError(t, service.Delete(ctx, "user-1", "admin-1"))
+		require.Len(t, emitter.events, 1)
+		deleted, ok := emitter.events[0].(domainevents.UserDeleted)
+		require.True(t, ok)
+		assert.Equal(t, "user-1", deleted.UserID)
+		assert.Equal(t, "admin-1", deleted.ActorID)
+	})
+}
@@ -0,0 +1,341 @@
+// Package sso 企业单点登录：每个租户对接自己的 SAML 2.0 身份提供方
+//
+// 登录由本服务发起：浏览器访问租户的登录地址后被重定向到 IdP，IdP 认证后把签名的断言提交到断言消费服务，
+// 验证通过后按属性映射找到或创建本地用户，签发与密码登录相同的访问令牌，并重定向到前端的登录完成页面。
+// 发出的 AuthnRequest ID 保存在共享缓存中，每个只能被一个响应使用，拒绝未经请求和重放的响应。
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/tenancy"
+	"go-server/pkg/auth"
+	"go-server/pkg/cache"
+	"go-server/pkg/saml"
+)
+
+// DefaultRequestTTL AuthnRequest 的默认有效期，用户须在此时间内完成 IdP 登录
+const DefaultRequestTTL = 10 * time.Minute
+
+// requestKeyPrefix 未使用的 AuthnRequest ID 在缓存中的键前缀
+const requestKeyPrefix = "saml:request:"
+
+var (
+	// ErrProviderNotFound 租户没有配置身份提供方
+	ErrProviderNotFound = errors.New("saml provider not found")
+	// ErrUnknownRequest 响应对应的请求不是本服务发出的、已过期或已被使用
+	ErrUnknownRequest = errors.New("saml response does not answer a pending request")
+	// ErrTenantInactive 租户已停用
+	ErrTenantInactive = errors.New("tenant is inactive")
+	// ErrUserInactive 用户已停用
+	ErrUserInactive = errors.New("user is deactivated")
+	// ErrUserNotProvisioned 用户不存在且未启用自动创建
+	ErrUserNotProvisioned = errors.New("user does not exist and auto provisioning is disabled")
+	// ErrTenantMismatch 用户属于其他租户
+	ErrTenantMismatch = errors.New("user belongs to another tenant")
+	// ErrUsernameConflict 自动创建用户时用户名已被占用
+	ErrUsernameConflict = errors.New("username derived from the assertion is already taken")
+)
+
+// usernameInvalidChars 用户名中不允许的字符，与注册接口的 username_charset 校验一致
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// AttributeMapping 断言属性到用户字段的映射，值为 Attribute 的 Name
+type AttributeMapping struct {
+	// Email 邮箱属性，为空或断言中没有该属性时使用 NameID
+	Email string
+	// Username 自动创建用户时的用户名属性，为空或断言中没有该属性时使用邮箱的本地部分
+	Username  string
+	FirstName string
+	LastName  string
+}
+
+// Provider 租户的身份提供方
+type Provider struct {
+	// Tenant 租户标识，出现在登录、元数据和断言消费服务的路径中
+	Tenant string
+	// SP 该租户的服务提供方，实体ID和 ACS 地址包含租户标识
+	SP *saml.ServiceProvider
+	// AutoProvision 是否为不存在的用户自动创建账户
+	AutoProvision bool
+	// Attributes 属性映射
+	Attributes AttributeMapping
+}
+
+// UserStore 单点登录需要的用户操作，用户服务实现了该接口
+type UserStore interface {
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error)
+	Update(ctx context.Context, id string, req *models.UpdateUserRequest, requesterID string) (*models.User, error)
+	UpdateLastLogin(ctx context.Context, id string) error
+}
+
+// TenantLookup 按标识查找租户，*tenancy.Resolver 实现了该接口
+type TenantLookup interface {
+	Resolve(identifier string) (*models.Tenant, error)
+}
+
+// Config 单点登录配置
+type Config struct {
+	// SuccessURL 前端的登录完成页面，访问令牌放在片段 #token= 中
+	SuccessURL string
+	// RequestTTL AuthnRequest 的有效期，<=0 时使用 DefaultRequestTTL
+	RequestTTL time.Duration
+}
+
+// Service 处理 SAML 登录
+type Service struct {
+	providers map[string]*Provider
+	users     UserStore
+	tenants   TenantLookup
+	cache     cache.Cache
+	jwt       *auth.JWTManager
+	config    Config
+}
+
+// Result 单点登录的结果
+type Result struct {
+	User *models.User
+	// Token 访问令牌
+	Token string
+	// Created 用户是否在本次登录中自动创建
+	Created bool
+	// RedirectURL 带有访问令牌的登录完成页面地址
+	RedirectURL string
+}
+
+// NewService 创建单点登录服务，tenants 为 nil 时不解析租户（单租户部署），租户标识只用于区分身份提供方
+func NewService(providers []*Provider, users UserStore, tenants TenantLookup, c cache.Cache, jwtManager *auth.JWTManager, config Config) *Service {
+	if config.RequestTTL <= 0 {
+		config.RequestTTL = DefaultRequestTTL
+	}
+	byTenant := make(map[string]*Provider, len(providers))
+	for _, provider := range providers {
+		byTenant[provider.Tenant] = provider
+	}
+	return &Service{
+		providers: byTenant,
+		users:     users,
+		tenants:   tenants,
+		cache:     c,
+		jwt:       jwtManager,
+		config:    config,
+	}
+}
+
+// Metadata 返回租户的 SP 元数据
+func (s *Service) Metadata(tenant string) ([]byte, error) {
+	provider, err := s.provider(tenant)
+	if err != nil {
+		return nil, err
+	}
+	return provider.SP.Metadata()
+}
+
+// LoginURL 创建 AuthnRequest 并返回 IdP 的登录地址
+func (s *Service) LoginURL(ctx context.Context, tenant string) (string, error) {
+	provider, err := s.provider(tenant)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.resolveTenant(tenant); err != nil {
+		return "", err
+	}
+
+	request, err := provider.SP.NewAuthnRequest("")
+	if err != nil {
+		return "", fmt.Errorf("failed to create authn request: %w", err)
+	}
+	if err := s.cache.Set(ctx, requestKeyPrefix+request.ID, tenant, s.config.RequestTTL); err != nil {
+		return "", fmt.Errorf("failed to store authn request: %w", err)
+	}
+	return request.URL, nil
+}
+
+// Login 验证 IdP 提交的 SAMLResponse，找到或创建用户并签发访问令牌
+func (s *Service) Login(ctx context.Context, tenant, samlResponse string) (*Result, error) {
+	provider, err := s.provider(tenant)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := s.resolveTenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := provider.SP.ParseResponse(samlResponse)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redeem(ctx, tenant, assertion.InResponseTo); err != nil {
+		return nil, err
+	}
+
+	var tenantID string
+	if resolved != nil {
+		tenantID = resolved.ID
+		ctx = tenancy.WithTenant(ctx, resolved)
+	}
+	user, created, err := s.user(ctx, provider, assertion)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	if err := s.users.UpdateLastLogin(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to update last login: %w", err)
+	}
+	token, err := s.jwt.GenerateTenantToken(user.ID, user.Username, user.Email, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+	return &Result{
+		User:        user,
+		Token:       token,
+		Created:     created,
+		RedirectURL: s.config.SuccessURL + "#" + url.Values{"token": {token}}.Encode(),
+	}, nil
+}
+
+// redeem 使用响应对应的 AuthnRequest，每个请求只能被使用一次，且必须是为同一个租户发出的
+func (s *Service) redeem(ctx context.Context, tenant, requestID string) error {
+	key := requestKeyPrefix + requestID
+	claimed, err := s.cache.SetIfNotExists(ctx, key+":redeemed", true, s.config.RequestTTL)
+	if err != nil {
+		return fmt.Errorf("failed to redeem authn request: %w", err)
+	}
+	if !claimed {
+		return ErrUnknownRequest
+	}
+	issuedFor, found := cache.GetAs[string](ctx, s.cache, key)
+	if !found || issuedFor != tenant {
+		return ErrUnknownRequest
+	}
+	_ = s.cache.Delete(ctx, key)
+	return nil
+}
+
+// user 按邮箱找到用户并同步姓名，用户不存在时按配置自动创建
+func (s *Service) user(ctx context.Context, provider *Provider, assertion *saml.Assertion) (*models.User, bool, error) {
+	email := attribute(assertion, provider.Attributes.Email, assertion.NameID)
+	if !strings.Contains(email, "@") {
+		return nil, false, fmt.Errorf("%w: assertion does not contain an email address", saml.ErrInvalidResponse)
+	}
+	firstName := attribute(assertion, provider.Attributes.FirstName, "")
+	lastName := attribute(assertion, provider.Attributes.LastName, "")
+
+	user, err := s.users.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		// 租户过滤之外的用户（如未分配租户的用户）不能通过租户的身份提供方登录
+		if tenantID := tenancy.ID(ctx); tenantID != "" && (user.TenantID == nil || *user.TenantID != tenantID) {
+			return nil, false, ErrTenantMismatch
+		}
+		if (firstName != "" && firstName != user.FirstName) || (lastName != "" && lastName != user.LastName) {
+			user, err = s.users.Update(ctx, user.ID, &models.UpdateUserRequest{FirstName: firstName, LastName: lastName}, user.ID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to sync user profile: %w", err)
+			}
+		}
+		return user, false, nil
+	case !errors.Is(err, repositories.ErrUserNotFound):
+		return nil, false, err
+	case !provider.AutoProvision:
+		return nil, false, ErrUserNotProvisioned
+	}
+
+	// 通过单点登录创建的用户不知道自己的密码，需要密码登录时可以重置密码
+	password, err := randomPassword()
+	if err != nil {
+		return nil, false, err
+	}
+	user, err = s.users.Register(ctx, &models.RegisterRequest{
+		Username:  username(attribute(assertion, provider.Attributes.Username, ""), email),
+		Email:     email,
+		Password:  password,
+		FirstName: firstName,
+		LastName:  lastName,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "username already taken":
+			return nil, false, ErrUsernameConflict
+		case "user with this email already exists":
+			// 按邮箱只能找到激活的用户，邮箱已存在说明该账户已停用
+			return nil, false, ErrUserInactive
+		}
+		return nil, false, fmt.Errorf("failed to provision user: %w", err)
+	}
+	return user, true, nil
+}
+
+// provider 返回租户的身份提供方
+func (s *Service) provider(tenant string) (*Provider, error) {
+	provider, ok := s.providers[tenant]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return provider, nil
+}
+
+// resolveTenant 启用多租户时查找租户，未启用时返回 nil
+func (s *Service) resolveTenant(tenant string) (*models.Tenant, error) {
+	if s.tenants == nil {
+		return nil, nil
+	}
+	resolved, err := s.tenants.Resolve(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return resolved, nil
+}
+
+// attribute 返回映射的属性值，没有映射或断言中没有该属性时返回 fallback
+func attribute(assertion *saml.Assertion, name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	if value := strings.TrimSpace(assertion.Attribute(name)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// username 返回自动创建用户的用户名，去掉不允许的字符并截断到 50 个字符，过短时补齐
+func username(preferred, email string) string {
+	if preferred == "" {
+		preferred, _, _ = strings.Cut(email, "@")
+	}
+	name := usernameInvalidChars.ReplaceAllString(preferred, "_")
+	if len(name) > 50 {
+		name = name[:50]
+	}
+	for len(name) < 3 {
+		name += "_"
+	}
+	return name
+}
+
+// randomPassword 生成随机密码，末尾的固定字符满足密码策略的字符类别要求
+func randomPassword() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf) + "Aa1!", nil
+}
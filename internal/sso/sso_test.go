@@ -0,0 +1,339 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/internal/tenancy"
+	"go-server/pkg/auth"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/cache"
+	"go-server/pkg/saml"
+	"go-server/pkg/saml/samltest"
+	"go-server/pkg/testutil/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	baseURL    = "https://sp.example.com/api/v1/auth/saml/"
+	successURL = "https://app.example.com/sso/complete"
+)
+
+// tenantUsers 模拟租户插件：创建用户时填充上下文中的租户ID
+type tenantUsers struct {
+	services.UserService
+	repo *repositories.MemoryUserRepository
+}
+
+func (u tenantUsers) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+	user, err := u.UserService.Register(ctx, req)
+	if err != nil || tenancy.ID(ctx) == "" {
+		return user, err
+	}
+	tenantID := tenancy.ID(ctx)
+	user.TenantID = &tenantID
+	return user, u.repo.Update(ctx, user)
+}
+
+// stubTenants 按标识查找租户
+type stubTenants map[string]*models.Tenant
+
+func (s stubTenants) Resolve(identifier string) (*models.Tenant, error) {
+	if tenant, ok := s[identifier]; ok {
+		return tenant, nil
+	}
+	return nil, tenancy.ErrTenantNotFound
+}
+
+// newIdP 创建测试身份提供方
+func newIdP(t *testing.T) *samltest.IdentityProvider {
+	t.Helper()
+	idp, err := samltest.NewIdentityProvider("https://idp.example.com/metadata")
+	require.NoError(t, err)
+	return idp
+}
+
+// newProvider 创建信任 idp 的租户服务提供方配置
+func newProvider(idp *samltest.IdentityProvider, tenant string) *Provider {
+	return &Provider{
+		Tenant: tenant,
+		SP: &saml.ServiceProvider{
+			EntityID: baseURL + tenant + "/metadata",
+			ACSURL:   baseURL + tenant + "/acs",
+			IdP: saml.IdentityProvider{
+				EntityID:    idp.EntityID,
+				SSOURL:      "https://idp.example.com/sso",
+				Certificate: idp.Certificate,
+			},
+		},
+		AutoProvision: true,
+		Attributes:    AttributeMapping{Email: "email", FirstName: "firstName", LastName: "lastName"},
+	}
+}
+
+// newService 创建使用内存缓存的单点登录服务，用户保存在 users 中
+func newService(users *repositories.MemoryUserRepository, tenants TenantLookup, jwt *auth.JWTManager, providers ...*Provider) *Service {
+	userService := services.NewUserService(users, services.WithPasswordHasher(password.Bcrypt{Cost: bcrypt.MinCost}))
+	return NewService(providers, tenantUsers{UserService: userService, repo: users}, tenants, cache.NewMemoryCache(), jwt, Config{
+		SuccessURL: successURL,
+	})
+}
+
+// login 发起登录，返回 AuthnRequest ID
+func login(t *testing.T, s *Service, tenant string) string {
+	t.Helper()
+	loginURL, err := s.LoginURL(context.Background(), tenant)
+	require.NoError(t, err)
+	parsed, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	compressed, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	body, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	match := regexp.MustCompile(` ID="([^"]+)"`).FindSubmatch(body)
+	require.NotNil(t, match)
+	return string(match[1])
+}
+
+// respond 签发对应请求的响应
+func respond(t *testing.T, idp *samltest.IdentityProvider, provider *Provider, requestID string, attributes map[string][]string) string {
+	t.Helper()
+	response, err := idp.Sign(samltest.Response{
+		InResponseTo: requestID,
+		Audience:     provider.SP.EntityID,
+		ACSURL:       provider.SP.ACSURL,
+		NameID:       "00u1abcd",
+		Attributes:   attributes,
+		IssueInstant: time.Now(),
+	})
+	require.NoError(t, err)
+	return response
+}
+
+var aliceAttributes = map[string][]string{
+	"email":     {"alice@acme.example.com"},
+	"firstName": {"Alice"},
+	"lastName":  {"Liddell"},
+}
+
+func TestLoginProvisionsUser(t *testing.T) {
+	ctx := context.Background()
+	idp := newIdP(t)
+	acme := newProvider(idp, "acme")
+	users := repositories.NewMemoryUserRepository()
+	jwt := auth.NewJWTManager("test-secret", 1)
+	service := newService(users, nil, jwt, acme)
+
+	result, err := service.Login(ctx, "acme", respond(t, idp, acme, login(t, service, "acme"), aliceAttributes))
+	require.NoError(t, err)
+	assert.True(t, result.Created)
+	assert.Equal(t, "alice@acme.example.com", result.User.Email)
+	assert.Equal(t, "alice", result.User.Username)
+	assert.Equal(t, "Liddell", result.User.LastName)
+	assert.Equal(t, successURL+"#token="+result.Token, result.RedirectURL)
+
+	claims, err := jwt.ValidateToken(result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, result.User.ID, claims.UserID)
+
+	stored, err := users.GetByID(ctx, result.User.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastLogin)
+
+	t.Run("再次登录使用已有用户并同步姓名", func(t *testing.T) {
+		renamed := map[string][]string{"email": {"alice@acme.example.com"}, "lastName": {"Hargreaves"}}
+		again, err := service.Login(ctx, "acme", respond(t, idp, acme, login(t, service, "acme"), renamed))
+		require.NoError(t, err)
+		assert.False(t, again.Created)
+		assert.Equal(t, result.User.ID, again.User.ID)
+		assert.Equal(t, "Alice", again.User.FirstName)
+		assert.Equal(t, "Hargreaves", again.User.LastName)
+	})
+}
+
+func TestLoginRejectsUnknownRequests(t *testing.T) {
+	ctx := context.Background()
+	idp := newIdP(t)
+	acme, globex := newProvider(idp, "acme"), newProvider(idp, "globex")
+	service := newService(repositories.NewMemoryUserRepository(), nil, auth.NewJWTManager("test-secret", 1), acme, globex)
+
+	replayed := respond(t, idp, acme, login(t, service, "acme"), aliceAttributes)
+	_, err := service.Login(ctx, "acme", replayed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tenant   string
+		response func() string
+		wantErr  error
+	}{
+		{
+			name:     "响应不能重放",
+			tenant:   "acme",
+			response: func() string { return replayed },
+			wantErr:  ErrUnknownRequest,
+		},
+		{
+			name:     "未发出的请求",
+			tenant:   "acme",
+			response: func() string { return respond(t, idp, acme, "_never_issued", aliceAttributes) },
+			wantErr:  ErrUnknownRequest,
+		},
+		{
+			name:     "为其他租户发出的请求",
+			tenant:   "globex",
+			response: func() string { return respond(t, idp, globex, login(t, service, "acme"), aliceAttributes) },
+			wantErr:  ErrUnknownRequest,
+		},
+		{
+			name:     "受众是其他租户",
+			tenant:   "acme",
+			response: func() string { return respond(t, idp, globex, login(t, service, "acme"), aliceAttributes) },
+			wantErr:  saml.ErrInvalidResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Login(ctx, tt.tenant, tt.response())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	ctx := context.Background()
+	idp := newIdP(t)
+
+	tests := []struct {
+		name       string
+		manual     bool         // 关闭自动创建用户
+		existing   *models.User // 登录前已存在的用户
+		attributes map[string][]string
+		wantErr    error
+	}{
+		{
+			name:       "未启用自动创建",
+			manual:     true,
+			attributes: aliceAttributes,
+			wantErr:    ErrUserNotProvisioned,
+		},
+		{
+			name:       "用户已停用",
+			existing:   factory.User().WithEmail("alice@acme.example.com").Inactive().Build(),
+			attributes: aliceAttributes,
+			wantErr:    ErrUserInactive,
+		},
+		{
+			name:       "用户名已被占用",
+			existing:   factory.User().WithUsername("alice").Build(),
+			attributes: aliceAttributes,
+			wantErr:    ErrUsernameConflict,
+		},
+		{
+			name:    "断言中没有邮箱",
+			wantErr: saml.ErrInvalidResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := repositories.NewMemoryUserRepository()
+			if tt.existing != nil {
+				require.NoError(t, users.Create(ctx, tt.existing))
+			}
+			acme := newProvider(idp, "acme")
+			acme.AutoProvision = !tt.manual
+			service := newService(users, nil, auth.NewJWTManager("test-secret", 1), acme)
+
+			_, err := service.Login(ctx, "acme", respond(t, idp, acme, login(t, service, "acme"), tt.attributes))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("未配置身份提供方", func(t *testing.T) {
+		service := newService(repositories.NewMemoryUserRepository(), nil, auth.NewJWTManager("test-secret", 1), newProvider(idp, "acme"))
+		_, err := service.LoginURL(ctx, "initech")
+		assert.ErrorIs(t, err, ErrProviderNotFound)
+		_, err = service.Metadata("initech")
+		assert.ErrorIs(t, err, ErrProviderNotFound)
+	})
+}
+
+func TestLoginWithTenancy(t *testing.T) {
+	ctx := context.Background()
+	idp := newIdP(t)
+	acmeProvider := newProvider(idp, "acme")
+	acme := &models.Tenant{ID: "3f1c2f0e-4a5b-4c6d-8e9f-0a1b2c3d4e5f", Slug: "acme", IsActive: true}
+	globex := &models.Tenant{ID: "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", Slug: "globex", IsActive: false}
+	users := repositories.NewMemoryUserRepository()
+	jwt := auth.NewJWTManager("test-secret", 1)
+	service := newService(users, stubTenants{"acme": acme, "globex": globex}, jwt, acmeProvider, newProvider(idp, "globex"))
+
+	result, err := service.Login(ctx, "acme", respond(t, idp, acmeProvider, login(t, service, "acme"), aliceAttributes))
+	require.NoError(t, err)
+	require.NotNil(t, result.User.TenantID)
+	assert.Equal(t, acme.ID, *result.User.TenantID)
+	claims, err := jwt.ValidateToken(result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, claims.TenantID)
+
+	t.Run("用户属于其他租户", func(t *testing.T) {
+		_, err := factory.User().WithEmail("bob@acme.example.com").WithTenant(globex.ID).Save(ctx, users)
+		require.NoError(t, err)
+		bob := map[string][]string{"email": {"bob@acme.example.com"}}
+		_, err = service.Login(ctx, "acme", respond(t, idp, acmeProvider, login(t, service, "acme"), bob))
+		assert.ErrorIs(t, err, ErrTenantMismatch)
+	})
+
+	tests := []struct {
+		name    string
+		tenants stubTenants
+		tenant  string
+		wantErr error
+	}{
+		{"租户已停用", stubTenants{"globex": globex}, "globex", ErrTenantInactive},
+		{"租户不存在", stubTenants{}, "acme", tenancy.ErrTenantNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newService(users, tt.tenants, jwt, acmeProvider, newProvider(idp, "globex"))
+			_, err := service.LoginURL(ctx, tt.tenant)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUsername(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred string
+		email     string
+		want      string
+	}{
+		{"使用邮箱本地部分", "", "alice@example.com", "alice"},
+		{"替换不允许的字符", "a.b+c", "x@example.com", "a_b_c"},
+		{"补足最短长度", "", "jo@example.com", "jo_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, username(tt.preferred, tt.email))
+		})
+	}
+
+	assert.Len(t, username(strings.Repeat("a", 80), "x@example.com"), 50, "截断到最大长度")
+}
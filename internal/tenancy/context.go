@@ -0,0 +1,74 @@
+// Package tenancy 多租户支持
+//
+// 租户由中间件从请求头、子域名或令牌声明中解析后写入请求上下文。Plugin 按上下文中的租户为包含 tenant_id 列的模型
+// 自动添加过滤条件并在创建时填充租户ID，CacheKey 为缓存键添加租户前缀。上下文中没有租户时（单租户部署、后台任务）
+// 不做任何处理，跨租户的管理和系统任务可通过 Unscoped 显式跳过过滤。
+package tenancy
+
+import (
+	"context"
+
+	"go-server/internal/models"
+)
+
+type (
+	tenantKey   struct{}
+	unscopedKey struct{}
+)
+
+// WithTenant 将租户写入上下文
+func WithTenant(ctx context.Context, tenant *models.Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext 返回上下文中的租户
+func FromContext(ctx context.Context) (*models.Tenant, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenant, ok := ctx.Value(tenantKey{}).(*models.Tenant)
+	return tenant, ok && tenant != nil
+}
+
+// ID 返回上下文中的租户ID，没有租户时返回空字符串
+func ID(ctx context.Context) string {
+	if tenant, ok := FromContext(ctx); ok {
+		return tenant.ID
+	}
+	return ""
+}
+
+// Unscoped 返回跳过租户过滤的上下文，用于跨租户的管理和系统任务
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped 判断上下文是否跳过租户过滤
+func IsUnscoped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// scopedID 返回需要过滤的租户ID，没有租户或跳过过滤时返回空字符串
+func scopedID(ctx context.Context) string {
+	if IsUnscoped(ctx) {
+		return ""
+	}
+	return ID(ctx)
+}
+
+// CacheKey 为缓存键添加租户前缀，避免不同租户的缓存互相命中；上下文中没有租户时原样返回
+func CacheKey(ctx context.Context, key string) string {
+	return KeyFor(ID(ctx), key)
+}
+
+// KeyFor 为缓存键添加指定租户的前缀，用于失效其他上下文中写入的缓存；tenantID 为空时原样返回
+func KeyFor(tenantID, key string) string {
+	if tenantID == "" {
+		return key
+	}
+	return "tenant:" + tenantID + ":" + key
+}
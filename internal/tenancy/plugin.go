@@ -0,0 +1,91 @@
+package tenancy
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column 租户ID列名，包含该列的模型自动按租户过滤
+const Column = "tenant_id"
+
+// Plugin GORM 租户插件
+// 查询、更新、删除时为包含 tenant_id 列的模型添加 tenant_id = 当前租户 的条件，创建时填充未设置的租户ID。
+// 租户从语句的上下文中读取，因此需要以 db.WithContext(ctx) 执行；原生 SQL（Raw/Exec）不会被过滤
+type Plugin struct{}
+
+// NewPlugin 创建租户插件
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *Plugin) Name() string {
+	return "tenancy"
+}
+
+// Initialize 实现 gorm.Plugin 接口，向各类操作注册前置回调
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().Before("gorm:create").Register("tenancy:create", p.assign); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tenancy:query", p.scope); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenancy:update", p.scope); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenancy:delete", p.scope); err != nil {
+		return err
+	}
+	return callback.Row().Before("gorm:row").Register("tenancy:row", p.scope)
+}
+
+// scope 为语句添加租户条件
+func (p *Plugin) scope(db *gorm.DB) {
+	tenantID := scopedID(db.Statement.Context)
+	if tenantID == "" || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(Column)
+	if field == nil {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// assign 创建记录时填充租户ID，已设置租户ID的记录保持不变
+func (p *Plugin) assign(db *gorm.DB) {
+	tenantID := scopedID(db.Statement.Context)
+	if tenantID == "" || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(Column)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	set := func(value reflect.Value) {
+		if _, isZero := field.ValueOf(ctx, value); isZero {
+			if err := field.Set(ctx, value, &tenantID); err != nil {
+				_ = db.AddError(err)
+			}
+		}
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			set(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		set(value)
+	}
+}
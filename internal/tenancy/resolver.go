@@ -0,0 +1,84 @@
+package tenancy
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrTenantNotFound 租户不存在或已删除
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// Lookup 按ID或标识查找租户，不存在时返回 ErrTenantNotFound
+type Lookup interface {
+	GetByID(id string) (*models.Tenant, error)
+	GetBySlug(slug string) (*models.Tenant, error)
+}
+
+// resolvedTenant 缓存的租户及其过期时间
+type resolvedTenant struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// Resolver 带本地缓存的租户查找，避免每个请求都查询数据库
+// 只缓存找到的租户，租户停用后在缓存过期前仍可能被使用
+type Resolver struct {
+	lookup Lookup
+	ttl    time.Duration
+
+	mu      sync.RWMutex
+	tenants map[string]resolvedTenant
+}
+
+// NewResolver 创建租户查找，ttl 为本地缓存的有效期，<=0 时不缓存
+func NewResolver(lookup Lookup, ttl time.Duration) *Resolver {
+	return &Resolver{
+		lookup:  lookup,
+		ttl:     ttl,
+		tenants: make(map[string]resolvedTenant),
+	}
+}
+
+// Resolve 按租户ID或标识查找租户，UUID 格式的值按ID查找，其他按标识查找
+func (r *Resolver) Resolve(identifier string) (*models.Tenant, error) {
+	now := time.Now()
+
+	r.mu.RLock()
+	cached, ok := r.tenants[identifier]
+	r.mu.RUnlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.tenant, nil
+	}
+
+	var (
+		tenant *models.Tenant
+		err    error
+	)
+	if _, parseErr := uuid.Parse(identifier); parseErr == nil {
+		tenant, err = r.lookup.GetByID(identifier)
+	} else {
+		tenant, err = r.lookup.GetBySlug(identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.tenants[identifier] = resolvedTenant{tenant: tenant, expiresAt: now.Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return tenant, nil
+}
+
+// Invalidate 清除本地缓存，租户修改后调用
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	r.tenants = make(map[string]resolvedTenant)
+	r.mu.Unlock()
+}
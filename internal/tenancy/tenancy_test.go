@@ -0,0 +1,145 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const testTenantID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", ID(ctx))
+	assert.Equal(t, "users:1", CacheKey(ctx, "users:1"))
+
+	ctx = WithTenant(ctx, &models.Tenant{ID: testTenantID, Slug: "acme"})
+	assert.Equal(t, testTenantID, ID(ctx))
+	assert.Equal(t, "tenant:"+testTenantID+":users:1", CacheKey(ctx, "users:1"))
+	assert.Equal(t, testTenantID, scopedID(ctx))
+
+	// 跳过过滤不影响缓存键，避免跨租户任务读到其他租户的缓存
+	unscoped := Unscoped(ctx)
+	assert.True(t, IsUnscoped(unscoped))
+	assert.Equal(t, "", scopedID(unscoped))
+	assert.Equal(t, "tenant:"+testTenantID+":users:1", CacheKey(unscoped, "users:1"))
+}
+
+// fakeLookup 记录查找次数的租户查找
+type fakeLookup struct {
+	tenants map[string]*models.Tenant
+	calls   int
+}
+
+func (f *fakeLookup) GetByID(id string) (*models.Tenant, error) {
+	f.calls++
+	for _, tenant := range f.tenants {
+		if tenant.ID == id {
+			return tenant, nil
+		}
+	}
+	return nil, ErrTenantNotFound
+}
+
+func (f *fakeLookup) GetBySlug(slug string) (*models.Tenant, error) {
+	f.calls++
+	if tenant, ok := f.tenants[slug]; ok {
+		return tenant, nil
+	}
+	return nil, ErrTenantNotFound
+}
+
+func TestResolver(t *testing.T) {
+	lookup := &fakeLookup{tenants: map[string]*models.Tenant{
+		"acme": {ID: testTenantID, Slug: "acme"},
+	}}
+	resolver := NewResolver(lookup, time.Minute)
+
+	tenant, err := resolver.Resolve("acme")
+	require.NoError(t, err)
+	assert.Equal(t, testTenantID, tenant.ID)
+
+	tenant, err = resolver.Resolve(testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", tenant.Slug)
+
+	_, err = resolver.Resolve("acme")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookup.calls, "缓存有效期内不重复查找")
+
+	// 不存在的租户不缓存
+	_, err = resolver.Resolve("missing")
+	assert.True(t, errors.Is(err, ErrTenantNotFound))
+	_, _ = resolver.Resolve("missing")
+	assert.Equal(t, 4, lookup.calls)
+
+	resolver.Invalidate()
+	_, err = resolver.Resolve("acme")
+	require.NoError(t, err)
+	assert.Equal(t, 5, lookup.calls)
+}
+
+// newDryRunDB 创建只生成 SQL 不连接数据库的实例
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(NewPlugin()))
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := WithTenant(context.Background(), &models.Tenant{ID: testTenantID})
+
+	t.Run("查询添加租户条件", func(t *testing.T) {
+		stmt := db.WithContext(ctx).Where("username = ?", "alice").Find(&[]models.User{}).Statement
+		assert.Contains(t, stmt.SQL.String(), `"users"."tenant_id" = $`)
+		assert.Contains(t, stmt.Vars, testTenantID)
+	})
+
+	t.Run("更新和删除添加租户条件", func(t *testing.T) {
+		stmt := db.WithContext(ctx).Model(&models.User{}).Where("id = ?", "u1").Update("is_active", false).Statement
+		assert.Contains(t, stmt.SQL.String(), `"users"."tenant_id" = $`)
+
+		stmt = db.WithContext(ctx).Where("id = ?", "u1").Delete(&models.User{}).Statement
+		assert.Contains(t, stmt.SQL.String(), `"users"."tenant_id" = $`)
+	})
+
+	t.Run("创建时填充租户ID", func(t *testing.T) {
+		user := &models.User{Username: "alice"}
+		db.WithContext(ctx).Create(user)
+		require.NotNil(t, user.TenantID)
+		assert.Equal(t, testTenantID, *user.TenantID)
+
+		other := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+		users := []*models.User{{Username: "bob"}, {Username: "carol", TenantID: &other}}
+		db.WithContext(ctx).Create(&users)
+		assert.Equal(t, testTenantID, *users[0].TenantID)
+		assert.Equal(t, other, *users[1].TenantID)
+	})
+
+	t.Run("没有租户或跳过过滤时不处理", func(t *testing.T) {
+		stmt := db.WithContext(context.Background()).Find(&[]models.User{}).Statement
+		assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+
+		stmt = db.WithContext(Unscoped(ctx)).Find(&[]models.User{}).Statement
+		assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+	})
+
+	t.Run("没有租户列的模型不处理", func(t *testing.T) {
+		stmt := db.WithContext(ctx).Find(&[]models.Tenant{}).Statement
+		assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+	})
+}
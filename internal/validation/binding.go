@@ -0,0 +1,310 @@
+package validation
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go-server/pkg/errors"
+	"go-server/pkg/i18n"
+	"go-server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	govalidator "github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// 支持的错误消息语言
+const (
+	LanguageEnglish = "en"
+	LanguageChinese = "zh"
+)
+
+// DefaultLanguage 请求未指定或指定了不支持的语言时使用的语言
+const DefaultLanguage = i18n.DefaultLocale
+
+// 自定义校验标签
+const (
+	TagPasswordStrength = "password_strength" // 密码至少包含一个字母和一个数字
+	TagUsernameCharset  = "username_charset"  // 用户名只能包含字母、数字、下划线和连字符
+)
+
+// usernameCharsetRegex 用户名允许的字符
+var usernameCharsetRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validationMessages 请求校验失败时的整体错误消息
+var validationMessages = i18n.Default().Messages("validation.failed")
+
+// invalidFormatMessages 请求体无法解析时的整体错误消息
+var invalidFormatMessages = i18n.Default().Messages("validation.invalid_format")
+
+// customTranslations 自定义校验标签的错误消息在消息目录中的键
+var customTranslations = map[string]string{
+	TagPasswordStrength: "validation.tag.password_strength",
+	TagUsernameCharset:  "validation.tag.username_charset",
+}
+
+var (
+	setupOnce   sync.Once
+	setupErr    error
+	translators map[string]ut.Translator
+)
+
+// Setup 在 gin 的校验器上注册自定义规则和多语言错误消息，重复调用只会执行一次
+// 使用了自定义标签的结构体在绑定前必须先调用 Setup
+func Setup() error {
+	setupOnce.Do(func() {
+		setupErr = setup()
+	})
+	return setupErr
+}
+
+// setup 注册字段名、自定义规则和翻译
+func setup() error {
+	v, ok := binding.Validator.Engine().(*govalidator.Validate)
+	if !ok {
+		return fmt.Errorf("unsupported validator engine %T", binding.Validator.Engine())
+	}
+
+	// 错误中的字段名使用 JSON 字段名
+	v.RegisterTagNameFunc(fieldName)
+
+	if err := v.RegisterValidation(TagPasswordStrength, validatePasswordStrength); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation(TagUsernameCharset, validateUsernameCharset); err != nil {
+		return err
+	}
+
+	uni := ut.New(en.New(), en.New(), zh.New())
+	enTrans, _ := uni.GetTranslator(LanguageEnglish)
+	zhTrans, _ := uni.GetTranslator(LanguageChinese)
+
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return err
+	}
+	if err := zhTranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+		return err
+	}
+
+	translators = map[string]ut.Translator{
+		LanguageEnglish: enTrans,
+		LanguageChinese: zhTrans,
+	}
+
+	for tag, key := range customTranslations {
+		for lang, trans := range translators {
+			message := i18n.Default().Translate(lang, key)
+			if err := v.RegisterTranslation(tag, trans, registerMessage(tag, message), translateMessage); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldName 返回结构体字段的 JSON 名称，没有 json 标签时使用 form 标签
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// validatePasswordStrength 密码至少包含一个字母和一个数字
+func validatePasswordStrength(fl govalidator.FieldLevel) bool {
+	var hasLetter, hasNumber bool
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		}
+	}
+	return hasLetter && hasNumber
+}
+
+// validateUsernameCharset 用户名只能包含字母、数字、下划线和连字符
+func validateUsernameCharset(fl govalidator.FieldLevel) bool {
+	return usernameCharsetRegex.MatchString(fl.Field().String())
+}
+
+// registerMessage 返回注册自定义错误消息的函数
+func registerMessage(tag, message string) govalidator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+// translateMessage 使用字段名渲染自定义错误消息
+func translateMessage(trans ut.Translator, fe govalidator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// Language 返回错误消息语言，优先使用语言协商中间件写入请求上下文的语言，
+// 未经过该中间件时根据 Accept-Language 请求头从消息目录支持的语言中选择
+func Language(c *gin.Context) string {
+	if c.Request != nil {
+		if locale, ok := i18n.Locale(c.Request.Context()); ok {
+			return locale
+		}
+	}
+	return i18n.Default().Match(c.GetHeader("Accept-Language"))
+}
+
+// Localize 为错误添加各语言的消息，UserMessage 使用 lang 对应的消息，不支持的语言使用英文
+func Localize(appErr *errors.AppError, messages map[string]string, lang string) *errors.AppError {
+	appErr.AddInternationalizedMessages(messages)
+	return appErr.WithUserMessage(appErr.GetLocalizedMessage(lang))
+}
+
+// BindJSON 绑定并校验 JSON 请求体，失败时写入 400 响应并返回 false
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	return bindWith(c, obj, binding.JSON)
+}
+
+// BindQuery 绑定并校验查询参数，失败时写入 400 响应并返回 false
+func BindQuery(c *gin.Context, obj interface{}) bool {
+	return bindWith(c, obj, binding.Query)
+}
+
+// bindWith 使用指定的绑定方式绑定并校验请求
+func bindWith(c *gin.Context, obj interface{}, b binding.Binding) bool {
+	if err := Setup(); err != nil {
+		response.InternalServerErrorWithCause(c, "Request validation is not available", err)
+		return false
+	}
+
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		response.ErrorWithAppError(c, TranslateError(err, Language(c)))
+		return false
+	}
+	return true
+}
+
+// ValidateStruct 使用与请求绑定相同的规则校验结构体，返回各字段的错误，校验通过时返回 nil
+// 用于校验不经过请求绑定的数据，如批量导入文件中的行
+func ValidateStruct(obj interface{}, lang string) ([]errors.ErrorDetails, error) {
+	if err := Setup(); err != nil {
+		return nil, err
+	}
+
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return nil, nil
+	}
+	var fieldErrors govalidator.ValidationErrors
+	if !stderrors.As(err, &fieldErrors) {
+		return nil, err
+	}
+
+	details := make([]errors.ErrorDetails, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		details = append(details, fieldErrorDetails(fe, lang))
+	}
+	return details, nil
+}
+
+// TranslateError 将绑定或校验错误转换为验证错误，字段错误使用 ErrorDetails 表示
+// Message 为英文消息，UserMessage 为指定语言的消息；请求体超过大小限制时返回 413 错误
+func TranslateError(err error, lang string) *errors.AppError {
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		return errors.NewPayloadTooLargeError(maxBytesErr.Limit)
+	}
+
+	var fieldErrors govalidator.ValidationErrors
+	if !stderrors.As(err, &fieldErrors) {
+		appErr := errors.NewValidationError(invalidFormatMessages[LanguageEnglish], errors.ErrorDetails{
+			Message:   formatErrorMessage(err),
+			ErrorCode: "INVALID_FORMAT",
+		})
+		return Localize(appErr, invalidFormatMessages, lang)
+	}
+
+	details := make([]errors.ErrorDetails, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		details = append(details, fieldErrorDetails(fe, lang))
+	}
+
+	appErr := errors.NewValidationError(validationMessages[LanguageEnglish], details...)
+	return Localize(appErr, validationMessages, lang)
+}
+
+// fieldErrorDetails 将单个字段错误转换为 ErrorDetails
+func fieldErrorDetails(fe govalidator.FieldError, lang string) errors.ErrorDetails {
+	constraint := fe.Tag()
+	if fe.Param() != "" {
+		constraint += "=" + fe.Param()
+	}
+
+	detail := errors.ErrorDetails{
+		Field:       fe.Field(),
+		Message:     translate(fe, LanguageEnglish),
+		UserMessage: translate(fe, lang),
+		Constraint:  constraint,
+		ErrorCode:   strings.ToUpper(fe.Tag()),
+	}
+
+	// 不回显密码等敏感字段的值
+	if !strings.Contains(strings.ToLower(fe.Field()), "password") {
+		detail.Value = fe.Value()
+	}
+	return detail
+}
+
+// translate 返回字段错误在指定语言下的消息
+func translate(fe govalidator.FieldError, lang string) string {
+	if Setup() != nil {
+		return fe.Error()
+	}
+
+	return fe.Translate(translator(lang))
+}
+
+// translator 按回退链返回语言的校验错误翻译器，如 zh-tw 使用 zh 的翻译器，都没有时使用默认语言
+func translator(lang string) ut.Translator {
+	for _, locale := range i18n.Fallbacks(lang, DefaultLanguage) {
+		if trans, ok := translators[locale]; ok {
+			return trans
+		}
+	}
+	return translators[DefaultLanguage]
+}
+
+// formatErrorMessage 返回请求体解析错误的描述
+func formatErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case stderrors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)
+	case stderrors.As(err, &typeErr):
+		return fmt.Sprintf("field %s must be of type %s", typeErr.Field, typeErr.Type)
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,165 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorBody 校验失败时的响应结构
+type errorBody struct {
+	Success bool `json:"success"`
+	Error   struct {
+		Code        string `json:"code"`
+		Message     string `json:"message"`
+		UserMessage string `json:"user_message"`
+		Details     struct {
+			ValidationErrors []struct {
+				Field       string      `json:"field"`
+				Message     string      `json:"message"`
+				UserMessage string      `json:"user_message"`
+				Value       interface{} `json:"value"`
+				Constraint  string      `json:"constraint"`
+				ErrorCode   string      `json:"error_code"`
+			} `json:"validation_errors"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func bindRegister(t *testing.T, body, language string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	require.NoError(t, Setup())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if language != "" {
+		c.Request.Header.Set("Accept-Language", language)
+	}
+
+	var req models.RegisterRequest
+	return w, BindJSON(c, &req)
+}
+
+func TestBindJSON_Valid(t *testing.T) {
+	w, ok := bindRegister(t, `{"username":"john_doe","email":"john@example.com","password":"password123"}`, "")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, w.Body.Len())
+}
+
+func TestBindJSON_FieldErrors(t *testing.T) {
+	w, ok := bindRegister(t, `{"username":"john doe","email":"not-an-email","password":"password"}`, "")
+	require.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Success)
+	assert.Equal(t, "VALIDATION_ERROR", body.Error.Code)
+	assert.Equal(t, "Request validation failed", body.Error.UserMessage)
+
+	errs := body.Error.Details.ValidationErrors
+	require.Len(t, errs, 3)
+
+	assert.Equal(t, "username", errs[0].Field)
+	assert.Equal(t, "username_charset", errs[0].Constraint)
+	assert.Equal(t, "USERNAME_CHARSET", errs[0].ErrorCode)
+	assert.Equal(t, "username can only contain letters, numbers, underscores and hyphens", errs[0].Message)
+	assert.Equal(t, "john doe", errs[0].Value)
+
+	assert.Equal(t, "email", errs[1].Field)
+	assert.Equal(t, "email", errs[1].Constraint)
+
+	// 密码不回显
+	assert.Equal(t, "password", errs[2].Field)
+	assert.Equal(t, "password_strength", errs[2].Constraint)
+	assert.Nil(t, errs[2].Value)
+}
+
+func TestBindJSON_LocalizedMessages(t *testing.T) {
+	w, ok := bindRegister(t, `{"username":"jo","email":"john@example.com","password":"password123"}`, "zh-CN,zh;q=0.9,en;q=0.8")
+	require.False(t, ok)
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Request validation failed", body.Error.Message)
+	assert.Equal(t, "请求参数校验失败", body.Error.UserMessage)
+
+	errs := body.Error.Details.ValidationErrors
+	require.Len(t, errs, 1)
+	assert.Equal(t, "min=3", errs[0].Constraint)
+	assert.Equal(t, "username must be at least 3 characters in length", errs[0].Message)
+	assert.Equal(t, "username长度必须至少为3个字符", errs[0].UserMessage)
+}
+
+func TestBindJSON_InvalidFormat(t *testing.T) {
+	w, ok := bindRegister(t, `{"username":`, "zh")
+	require.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Invalid request format", body.Error.Message)
+	assert.Equal(t, "请求格式错误", body.Error.UserMessage)
+}
+
+func TestBindJSON_PayloadTooLarge(t *testing.T) {
+	require.NoError(t, Setup())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"john_doe"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Body = http.MaxBytesReader(w, c.Request.Body, 8)
+
+	var req models.RegisterRequest
+	require.False(t, BindJSON(c, &req))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", body.Error.Code)
+}
+
+func TestValidateStruct(t *testing.T) {
+	details, err := ValidateStruct(&models.RegisterRequest{Username: "john_doe", Email: "john@example.com", Password: "password1"}, LanguageEnglish)
+	require.NoError(t, err)
+	assert.Empty(t, details)
+
+	details, err = ValidateStruct(&models.RegisterRequest{Username: "john_doe", Email: "invalid", Password: "password1"}, LanguageChinese)
+	require.NoError(t, err)
+	require.Len(t, details, 1)
+	assert.Equal(t, "email", details[0].Field)
+	assert.Equal(t, "invalid", details[0].Value)
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", LanguageEnglish},
+		{"zh-CN", LanguageChinese},
+		{"fr-FR,zh;q=0.8", LanguageChinese},
+		{"en-US,en;q=0.9", LanguageEnglish},
+		{"fr", DefaultLanguage},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Accept-Language", tt.header)
+		assert.Equal(t, tt.expected, Language(c), tt.header)
+	}
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+
+	"go-server/pkg/auth/password"
+	"go-server/pkg/errors"
+	"go-server/pkg/i18n"
+)
+
+// passwordPolicyMessages 新密码不满足密码策略时的整体错误消息
+var passwordPolicyMessages = i18n.Default().Messages("password.policy_failed")
+
+// passwordRuleMessage 密码规则的错误消息和修复建议
+type passwordRuleMessage struct {
+	message    string
+	suggestion string
+}
+
+// PasswordPolicyError 将密码违反的规则转换为验证错误，每条规则对应一个 ErrorDetails，
+// Message 为英文消息，UserMessage 和 Suggestions 为指定语言的消息和修复建议
+func PasswordPolicyError(field string, violations []password.Violation, lang string) *errors.AppError {
+	details := make([]errors.ErrorDetails, 0, len(violations))
+	for _, v := range violations {
+		detail := errors.ErrorDetails{
+			Field:      field,
+			Message:    passwordRuleText(v, field, LanguageEnglish).message,
+			Constraint: v.Rule,
+			ErrorCode:  "PASSWORD_" + strings.ToUpper(v.Rule),
+		}
+		if v.Limit != 0 {
+			detail.Constraint += "=" + strconv.FormatFloat(v.Limit, 'f', -1, 64)
+		}
+		localized := passwordRuleText(v, field, lang)
+		detail.UserMessage = localized.message
+		detail.Suggestions = []string{localized.suggestion}
+		details = append(details, detail)
+	}
+
+	appErr := errors.NewValidationError(passwordPolicyMessages[LanguageEnglish], details...)
+	return Localize(appErr, passwordPolicyMessages, lang)
+}
+
+// passwordRuleText 返回规则在指定语言下的错误消息和修复建议，消息目录中的键为 password.rule.<规则>.message
+// 和 password.rule.<规则>.suggestion，{0} 为字段名，{1} 为规则的限制值；目录中没有的规则使用违规描述
+func passwordRuleText(v password.Violation, field, lang string) passwordRuleMessage {
+	bundle := i18n.Default()
+	key := "password.rule." + v.Rule
+	message, ok := bundle.Lookup(lang, key+".message")
+	if !ok {
+		return passwordRuleMessage{message: v.String()}
+	}
+	suggestion, _ := bundle.Lookup(lang, key+".suggestion")
+
+	limit := strconv.FormatFloat(v.Limit, 'f', -1, 64)
+	return passwordRuleMessage{
+		message:    i18n.Format(message, field, limit),
+		suggestion: i18n.Format(suggestion, field, limit),
+	}
+}
@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	"go-server/pkg/auth/password"
+	"go-server/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicyError(t *testing.T) {
+	violations := []password.Violation{
+		{Rule: password.RuleMinLength, Limit: 12},
+		{Rule: password.RuleBreached},
+	}
+
+	appErr := PasswordPolicyError("new_password", violations, LanguageChinese)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	assert.Equal(t, "Password does not meet the password policy", appErr.Message)
+	assert.Equal(t, "密码不符合密码策略", appErr.UserMessage)
+
+	details, ok := appErr.Details["validation_errors"].([]errors.ErrorDetails)
+	require.True(t, ok)
+	require.Len(t, details, 2)
+
+	assert.Equal(t, errors.ErrorDetails{
+		Field:       "new_password",
+		Message:     "new_password must be at least 12 characters long",
+		UserMessage: "new_password长度不能少于12个字符",
+		Constraint:  "min_length=12",
+		ErrorCode:   "PASSWORD_MIN_LENGTH",
+		Suggestions: []string{"使用至少12个字符，由几个单词组成的口令短语足够长且容易记住"},
+	}, details[0])
+	assert.Equal(t, "breached", details[1].Constraint)
+	assert.Equal(t, "PASSWORD_BREACHED", details[1].ErrorCode)
+	assert.Nil(t, details[1].Value, "不回显密码")
+
+	english := PasswordPolicyError("password", violations[1:], "fr")
+	details = english.Details["validation_errors"].([]errors.ErrorDetails)
+	assert.Equal(t, "password has appeared in a known data breach", details[0].UserMessage, "不支持的语言使用默认语言")
+	assert.Equal(t, []string{"Choose a password you have never used on any other site"}, details[0].Suggestions)
+}
@@ -0,0 +1,5 @@
+-- Migration: 002_add_users_avatar_key_down
+-- Description: Remove avatar_key column from users table
+-- Version: 002_add_users_avatar_key_down
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
\ No newline at end of file
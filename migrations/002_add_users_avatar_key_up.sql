@@ -0,0 +1,5 @@
+-- Migration: 002_add_users_avatar_key_up
+-- Description: Add avatar_key column storing the object storage key of the user's avatar
+-- Version: 002_add_users_avatar_key_up
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255);
\ No newline at end of file
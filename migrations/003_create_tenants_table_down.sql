@@ -0,0 +1,7 @@
+-- Migration: 003_create_tenants_table_down
+-- Description: Remove tenant_id from users and drop the tenants table
+-- Version: 003_create_tenants_table_down
+
+DROP INDEX IF EXISTS idx_users_tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS tenants;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- Migration: 003_create_tenants_table_up
+-- Description: Create tenants table and add the tenant_id column to users
+-- Version: 003_create_tenants_table_up
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN DEFAULT true NOT NULL,
+    rate_limit INTEGER DEFAULT 0 NOT NULL,
+    anonymous_rate_limit INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants(deleted_at);
+
+-- Users without a tenant belong to single-tenant deployments
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Migration: 004_create_metrics_snapshots_table_down
+-- Description: Drop the metrics_snapshots table
+-- Version: 004_create_metrics_snapshots_table_down
+
+DROP TABLE IF EXISTS metrics_snapshots;
\ No newline at end of file
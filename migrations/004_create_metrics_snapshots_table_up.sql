@@ -0,0 +1,24 @@
+-- Migration: 004_create_metrics_snapshots_table_up
+-- Description: Create metrics_snapshots table for persisted rate limit, cache and HTTP metrics
+-- Version: 004_create_metrics_snapshots_table_up
+
+-- Each row holds the counter deltas of one instance over one bucket; resolution is the bucket length in seconds
+CREATE TABLE IF NOT EXISTS metrics_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    instance VARCHAR(255) NOT NULL,
+    resolution INTEGER NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    http_requests BIGINT DEFAULT 0 NOT NULL,
+    http_server_errors BIGINT DEFAULT 0 NOT NULL,
+    http_duration_us BIGINT DEFAULT 0 NOT NULL,
+    http_in_flight BIGINT DEFAULT 0 NOT NULL,
+    rate_limit_requests BIGINT DEFAULT 0 NOT NULL,
+    rate_limit_throttled BIGINT DEFAULT 0 NOT NULL,
+    cache_hits BIGINT DEFAULT 0 NOT NULL,
+    cache_misses BIGINT DEFAULT 0 NOT NULL,
+    cache_loads BIGINT DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metrics_snapshots_bucket ON metrics_snapshots(instance, resolution, bucket_start);
+CREATE INDEX IF NOT EXISTS idx_metrics_snapshots_resolution_bucket_start ON metrics_snapshots(resolution, bucket_start);
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Migration: 005_create_notifications_tables_down
+-- Description: Drop the notifications and notification_preferences tables
+-- Version: 005_create_notifications_tables_down
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,5 @@
+-- Migration: 006_create_announcements_table_down
+-- Description: Drop the announcements table
+-- Version: 006_create_announcements_table_down
+
+DROP TABLE IF EXISTS announcements;
@@ -0,0 +1,22 @@
+-- Migration: 006_create_announcements_table_up
+-- Description: Create announcements table for admin-managed broadcasts targeted by role and tenant
+-- Version: 006_create_announcements_table_up
+
+-- roles is a JSON array of role names; an empty array makes the announcement visible to everyone.
+-- target_tenant_id is deliberately not named tenant_id so the tenancy plugin does not scope announcements to the admin's tenant.
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    level VARCHAR(16) NOT NULL DEFAULT 'info',
+    roles JSONB NOT NULL DEFAULT '[]',
+    target_tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_target_tenant_id ON announcements(target_tenant_id);
+CREATE INDEX IF NOT EXISTS idx_announcements_ends_at ON announcements(ends_at);
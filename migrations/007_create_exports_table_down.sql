@@ -0,0 +1,5 @@
+-- Migration: 007_create_exports_table_down
+-- Description: Drop the exports table
+-- Version: 007_create_exports_table_down
+
+DROP TABLE IF EXISTS exports;
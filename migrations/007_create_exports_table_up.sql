@@ -0,0 +1,25 @@
+-- Migration: 007_create_exports_table_up
+-- Description: Create exports table tracking background user data exports and their stored files
+-- Version: 007_create_exports_table_up
+
+-- object_key points into the configured object storage; rows and files are removed together once expires_at passes.
+CREATE TABLE IF NOT EXISTS exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    format VARCHAR(8) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    filter JSONB NOT NULL DEFAULT '{}',
+    total_rows BIGINT NOT NULL DEFAULT 0,
+    processed_rows BIGINT NOT NULL DEFAULT 0,
+    size BIGINT NOT NULL DEFAULT 0,
+    object_key VARCHAR(255),
+    error TEXT,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_exports_tenant_id ON exports(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_exports_expires_at ON exports(expires_at);
@@ -0,0 +1,6 @@
+-- Migration: 008_encrypt_users_personal_data_down
+-- Description: Drop the email blind index column
+-- Version: 008_encrypt_users_personal_data_down
+
+-- The columns stay TEXT: encrypted values would not fit the previous VARCHAR lengths
+ALTER TABLE users DROP COLUMN IF EXISTS email_index;
@@ -0,0 +1,13 @@
+-- Migration: 008_encrypt_users_personal_data_up
+-- Description: Widen the encrypted users columns and add the email blind index column
+-- Version: 008_encrypt_users_personal_data_up
+
+-- With field encryption enabled email, first_name and last_name hold AES-GCM ciphertext, which is longer than the plaintext.
+-- VARCHAR to TEXT is binary compatible in PostgreSQL and does not rewrite the table.
+-- lint:ignore column_type_change
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users ALTER COLUMN first_name TYPE TEXT;
+ALTER TABLE users ALTER COLUMN last_name TYPE TEXT;
+
+-- HMAC-SHA256 of the lowercased email, used to look users up by email; NULL until written with encryption enabled
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_index VARCHAR(64);
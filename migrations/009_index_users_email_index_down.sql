@@ -0,0 +1,5 @@
+-- Migration: 009_index_users_email_index_down
+-- Description: Drop the unique index on the email blind index
+-- Version: 009_index_users_email_index_down
+
+DROP INDEX IF EXISTS idx_users_email_index;
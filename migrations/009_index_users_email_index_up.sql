@@ -0,0 +1,5 @@
+-- Migration: 009_index_users_email_index_up
+-- Description: Add a unique index on the email blind index
+-- Version: 009_index_users_email_index_up
+
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email_index ON users(email_index);
@@ -0,0 +1,5 @@
+-- Migration: 010_create_oauth_clients_table_down
+-- Description: Drop the oauth_clients table
+-- Version: 010_create_oauth_clients_table_down
+
+DROP TABLE IF EXISTS oauth_clients;
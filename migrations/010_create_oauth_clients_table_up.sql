@@ -0,0 +1,16 @@
+-- Migration: 010_create_oauth_clients_table_up
+-- Description: Create oauth_clients table for OpenID Connect relying parties registered by admins
+-- Version: 010_create_oauth_clients_table_up
+
+-- secret_hash is the hex SHA-256 of the client secret and is empty for public clients, which rely on PKCE alone.
+-- redirect_uris is a JSON array matched exactly against the redirect_uri of each authorization request.
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(64),
+    confidential BOOLEAN NOT NULL DEFAULT FALSE,
+    redirect_uris JSONB NOT NULL DEFAULT '[]',
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
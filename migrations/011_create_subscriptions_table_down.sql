@@ -0,0 +1,5 @@
+-- Migration: 011_create_subscriptions_table_down
+-- Description: Drop the subscriptions table
+-- Version: 011_create_subscriptions_table_down
+
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,5 @@
+-- Migration: 012_create_backups_table_down
+-- Description: Drop the backups table
+-- Version: 012_create_backups_table_down
+
+DROP TABLE IF EXISTS backups;
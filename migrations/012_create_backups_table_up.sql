@@ -0,0 +1,23 @@
+-- Migration: 012_create_backups_table_up
+-- Description: Create backups table tracking logical database backups and their encrypted archives
+-- Version: 012_create_backups_table_up
+
+-- object_key points into the configured object storage; archives are encrypted before upload.
+-- The table is excluded from the dumps themselves, so restoring a backup keeps the backup list intact.
+CREATE TABLE IF NOT EXISTS backups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64),
+    object_key VARCHAR(255),
+    error TEXT,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_backups_trigger ON backups(trigger);
+CREATE INDEX IF NOT EXISTS idx_backups_expires_at ON backups(expires_at);
@@ -0,0 +1,726 @@
+// Package apitest 在内存中启动完整的路由，按 OpenAPI 文档校验每个接口的响应
+//
+// Server 使用内存用户仓库和内存缓存装配全部处理器和路由，不需要数据库或 Redis。
+// Run 发送请求并断言状态码，同时按文档严格校验响应（状态码是否声明、Content-Type、响应体结构），
+// AssertCoverage 检查文档中每个接口的每个状态码是否都被测试用例覆盖或明确跳过。
+// 衍生项目新增接口后补充用例即可发现实现与文档的偏差：
+//
+//	s := apitest.New(t)
+//	s.Run(t, apitest.Case{Method: "GET", Path: "/api/v1/auth/me", As: s.User, Status: http.StatusOK})
+//	apitest.AssertCoverage(t, skips, s)
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/docs"
+	"go-server/internal/announcements"
+	"go-server/internal/audit"
+	"go-server/internal/backups"
+	"go-server/internal/billing"
+	"go-server/internal/config"
+	"go-server/internal/exports"
+	"go-server/internal/handlers"
+	"go-server/internal/imports"
+	"go-server/internal/logger"
+	"go-server/internal/metrics"
+	"go-server/internal/middleware"
+	"go-server/internal/models"
+	"go-server/internal/notifications"
+	"go-server/internal/oidc"
+	"go-server/internal/openapi"
+	"go-server/internal/passwordless"
+	"go-server/internal/privacy"
+	"go-server/internal/repositories"
+	"go-server/internal/retention"
+	"go-server/internal/routes"
+	"go-server/internal/services"
+	"go-server/internal/sso"
+	"go-server/internal/validation"
+	"go-server/pkg/auth"
+	"go-server/pkg/auth/password"
+	"go-server/pkg/banlist"
+	"go-server/pkg/cache"
+	"go-server/pkg/drain"
+	"go-server/pkg/events"
+	"go-server/pkg/featureflags"
+	"go-server/pkg/health"
+	"go-server/pkg/maintenance"
+	"go-server/pkg/quota"
+	"go-server/pkg/saml"
+	"go-server/pkg/saml/samltest"
+	"go-server/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultPassword 预置账号的密码
+const DefaultPassword = "Passw0rd!"
+
+// MaxUploadSize 头像上传的大小限制，足够小以便测试 413 响应
+const MaxUploadSize = 64 << 10
+
+// StripeWebhookSecret 订阅服务校验 Stripe webhook 签名的密钥
+const StripeWebhookSecret = "whsec_apitest"
+
+// BackupContent 备份服务导出的数据库内容
+const BackupContent = "PGDMP apitest"
+
+// MaintenanceBypassToken 维护期间放行请求的旁路令牌
+const MaintenanceBypassToken = "apitest-bypass"
+
+// Account 预置的账号及其访问令牌
+type Account struct {
+	User     *models.User
+	Password string
+	Token    string
+}
+
+// Server 装配好的路由及其内存依赖，测试可以直接读写依赖来准备数据
+// 以 Degraded 创建时 HTTP 指标、请求查询计数、缓存预热、对象存储、功能开关、自动封禁、通知、公告、导出、导入、个人数据、用量配额、订阅、维护模式、数据保留策略、数据库备份、指标历史和限流统计均为 nil，
+// 处理器也看不到缓存（用户服务和令牌黑名单仍使用缓存）
+type Server struct {
+	Engine       *gin.Engine
+	Users        *repositories.MemoryUserRepository
+	UserService  services.UserService
+	Cache        *cache.MemoryCache
+	Warmer       *cache.Warmer
+	JWT          *auth.JWTManager
+	Health       *health.Registry
+	HTTPMetrics  *metrics.HTTPMetrics
+	AuthMetrics  *metrics.AuthMetrics
+	RateLimits   *metrics.RateLimitMetrics
+	FeatureFlags *featureflags.Manager
+	Bans         *banlist.MemoryStore
+	BanList      *banlist.BanList
+	Storage      storage.Storage
+	// RequestQueries 每个请求的查询计数，内存仓库不执行SQL，测试可以直接调用 Begin、Record 和 End 准备数据
+	RequestQueries *metrics.RequestQueryMetrics
+	// Notifications 通知服务，投递任务经进程内事件总线处理；测试可以调用 Deliver 同步写入站内信
+	Notifications *notifications.Service
+	// Announcements 公告服务，使用内存仓库
+	Announcements *announcements.Service
+	// Exports 导出服务，使用内存仓库和对象存储，导出任务在后台执行
+	Exports *exports.Service
+	// Imports 导入服务，经缓存仓库写入内存用户仓库
+	Imports *imports.Service
+	// Privacy 个人数据服务，经缓存仓库抹除内存用户仓库中的用户
+	Privacy *privacy.Service
+	// OIDC OpenID Connect 提供方，客户端保存在内存仓库中
+	OIDC *oidc.Service
+	// SAML 单点登录服务，租户 acme 自动创建用户，租户 globex 不自动创建，两者都信任 IdP
+	SAML *sso.Service
+	// IdP 测试身份提供方，签发提交到断言消费服务的响应
+	IdP *samltest.IdentityProvider
+	// Passwordless 设备授权流程和登录链接，登录链接事件可通过 Events 读取
+	Passwordless *passwordless.Service
+	// Quota 用量配额，使用进程内存储；api_calls 的限制足够大，其他用例不会触发 429
+	Quota *quota.Manager
+	// Billing 订阅服务，默认套餐 free，Stripe 价格 price_pro 对应包含 advanced_search 权益的 pro 套餐
+	Billing *billing.Service
+	// Subscriptions 订阅服务使用的内存仓库
+	Subscriptions *repositories.MemorySubscriptionRepository
+	// Maintenance 维护模式开关，使用进程内存储；维护期间放行管理接口和健康检查，旁路令牌为 MaintenanceBypassToken
+	Maintenance *maintenance.Mode
+	// Drainer 排空实例，停止接收请求时将就绪检查标记为失败，没有后台任务需要停止；每个 Server 只能排空一次
+	Drainer *drain.Drainer
+	// DrainTracker 统计处理中的请求，不含排空接口本身
+	DrainTracker *drain.Tracker
+	// RateLimiter 速率限制器，不安装为中间件，运行时覆盖只保存在进程内
+	RateLimiter *middleware.DistributedRateLimiter
+	// Retention 数据保留策略，只清理内存用户仓库中软删除的用户，不启动定期清理
+	Retention *retention.Engine
+	// Backups 数据库备份服务，使用内存仓库和加密后的对象存储，导出内容为 BackupContent，不启动自动备份
+	Backups *backups.Service
+	// BackupRepository 备份服务使用的内存仓库
+	BackupRepository *repositories.MemoryBackupRepository
+
+	// MetricsHistory 指标历史接口的数据来源，可在测试中替换以返回错误
+	MetricsHistory func(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error)
+
+	Admin *Account // 管理员
+	User  *Account // 普通用户
+
+	validator *openapi.RequestValidator
+	doc       *openapi.Document
+
+	mu      sync.Mutex
+	covered map[string]bool // 操作ID + 空格 + 状态码
+	events  []*events.Event
+}
+
+// options New 的选项
+type options struct {
+	spec        []byte
+	degraded    bool
+	middlewares []gin.HandlerFunc
+}
+
+// Option 配置 New
+type Option func(*options)
+
+// WithSpec 使用给定的 OpenAPI 文档校验响应，默认使用 docs.OpenAPI
+func WithSpec(spec []byte) Option {
+	return func(o *options) {
+		o.spec = spec
+	}
+}
+
+// WithMiddlewares 在默认中间件之后追加中间件
+func WithMiddlewares(middlewares ...gin.HandlerFunc) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// Degraded 不装配可选依赖，用于覆盖依赖不可用时的 503 响应
+func Degraded() Option {
+	return func(o *options) {
+		o.degraded = true
+	}
+}
+
+// New 创建测试服务器并预置一个管理员和一个普通用户
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	o := &options{spec: docs.OpenAPI}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := validation.Setup(); err != nil {
+		t.Fatalf("apitest: failed to set up validation: %v", err)
+	}
+	validator, err := openapi.NewRequestValidator(o.spec)
+	if err != nil {
+		t.Fatalf("apitest: %v", err)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(o.spec, &doc); err != nil {
+		t.Fatalf("apitest: failed to parse OpenAPI document: %v", err)
+	}
+
+	s := &Server{
+		Users:     repositories.NewMemoryUserRepository(),
+		Cache:     cache.NewMemoryCache(),
+		JWT:       auth.NewJWTManager("apitest-secret-key-at-least-32-bytes", 3600),
+		Health:    health.NewRegistry(time.Second),
+		validator: validator,
+		doc:       &doc,
+		covered:   make(map[string]bool),
+	}
+	// 与测试数据的密码哈希成本相同，登录时不重新哈希
+	serviceOpts := []services.UserServiceOption{
+		services.WithPasswordHasher(password.Bcrypt{Cost: bcrypt.MinCost}),
+		services.WithPasswordChecker(password.NewChecker(password.Rules{MinLength: 8, MaxLength: 72, RejectUserInfo: true}, nil)),
+	}
+	if !o.degraded {
+		serviceOpts = append(serviceOpts, services.WithEventPublisher(eventRecorder{s}))
+	}
+	s.UserService = services.NewUserServiceWithCache(s.Users, s.Cache, serviceOpts...)
+	s.Health.RegisterReadiness("cache", health.CheckerFunc(s.Cache.Health))
+
+	if !o.degraded {
+		s.HTTPMetrics = metrics.NewHTTPMetrics()
+		s.AuthMetrics = metrics.NewAuthMetrics()
+		s.RequestQueries = metrics.NewRequestQueryMetrics(0, 0, 0)
+		s.Warmer = cache.NewWarmer(time.Minute)
+		s.Warmer.Register("users", func(ctx context.Context, progress cache.WarmProgress) error { return nil })
+		s.RateLimits = metrics.NewRateLimitMetrics()
+		s.FeatureFlags = featureflags.NewManager(featureflags.NewMemoryStore(), 0)
+		s.Bans = banlist.NewMemoryStore()
+		s.BanList = banlist.New(s.Bans, func() []banlist.Offender { return nil }, banlist.Config{})
+		local, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+		if err != nil {
+			t.Fatalf("apitest: %v", err)
+		}
+		s.Storage = local
+		s.Notifications = newNotifications(t, s.UserService, s.Cache)
+		s.Announcements = announcements.NewService(repositories.NewMemoryAnnouncementRepository(), s.UserService, s.Cache, announcements.Config{})
+		s.Exports = exports.NewService(repositories.NewMemoryExportRepository(), s.Users, s.Storage, exports.Config{SigningKey: []byte("apitest-export-key")})
+		t.Cleanup(func() { s.Exports.Stop(context.Background()) })
+		s.Imports = imports.NewService(repositories.NewCachedUserRepository(s.Users, s.Cache).(repositories.UserImporter),
+			imports.Config{BatchSize: 2, MaxRows: 10, Hasher: password.Bcrypt{Cost: bcrypt.MinCost}})
+		s.Privacy = privacy.NewService(repositories.NewCachedUserRepository(s.Users, s.Cache).(repositories.UserEraser),
+			s.Notifications, s.Storage, s.Cache, cache.NewBlacklistService(s.Cache, s.JWT, nil))
+		s.OIDC = oidc.NewService(repositories.NewMemoryOAuthClientRepository(), s.UserService, s.Cache, s.JWT,
+			oidc.Config{Issuer: "http://localhost", ConsentURL: "http://localhost/oauth/consent"})
+		s.IdP, err = samltest.NewIdentityProvider("https://idp.example.com/metadata")
+		if err != nil {
+			t.Fatalf("apitest: %v", err)
+		}
+		s.SAML = sso.NewService([]*sso.Provider{s.samlProvider("acme", true), s.samlProvider("globex", false)},
+			s.UserService, nil, s.Cache, s.JWT, sso.Config{SuccessURL: "http://localhost/sso/complete"})
+		s.Passwordless = passwordless.NewService(s.UserService, s.Cache, s.JWT, eventRecorder{s}, passwordless.Config{
+			VerificationURL: "http://localhost/device",
+			MagicLinkURL:    "http://localhost/login/magic",
+		})
+		s.Quota = quota.New(quota.NewMemoryStore(), []quota.Limit{{Name: quota.APICalls, Soft: 50000, Hard: 100000}}, quota.Config{})
+		s.Subscriptions = repositories.NewMemorySubscriptionRepository()
+		s.Billing, err = billing.NewService(s.Subscriptions, s.UserService, s.Cache, billing.Config{
+			Plans: []models.Plan{
+				{ID: "free", Name: "免费版", Entitlements: []string{}, Quotas: []models.PlanQuota{}},
+				{ID: "pro", Name: "专业版", Entitlements: []string{"advanced_search"}, RateLimit: 600, Quotas: []models.PlanQuota{}, StripePriceIDs: []string{"price_pro"}},
+			},
+			DefaultPlan:   "free",
+			WebhookSecret: StripeWebhookSecret,
+		})
+		if err != nil {
+			t.Fatalf("apitest: %v", err)
+		}
+		s.Maintenance = maintenance.New(maintenance.NewMemoryStore(), time.Minute)
+		s.Health.SetMaintenance(s.Maintenance.Countdown)
+		s.DrainTracker = drain.NewTracker()
+		s.Drainer = drain.New(s.DrainTracker, drain.Config{
+			StopAccepting: func(ctx context.Context) error {
+				s.Health.SetShuttingDown(true)
+				return nil
+			},
+			PollInterval: time.Millisecond,
+		})
+		s.RateLimiter = middleware.NewDistributedRateLimiter(middleware.RateLimiterConfig{
+			RedisAddr:             "127.0.0.1:1",
+			AnonymousRequests:     100,
+			AuthenticatedRequests: 200,
+			WindowDuration:        time.Minute,
+			KeyPrefix:             "apitest:rate_limit",
+		})
+		t.Cleanup(func() { s.RateLimiter.Close() })
+		s.Retention = retention.NewEngine([]retention.Policy{
+			{Table: retention.TableUsers, Retention: 30 * 24 * time.Hour, Target: retention.DeletedUsers(s.Users)},
+		}, nil, retention.Config{})
+		encrypted, err := storage.NewEncryptedStorage(s.Storage, map[uint32][]byte{1: bytes.Repeat([]byte{1}, storage.EncryptedKeySize)}, 0)
+		if err != nil {
+			t.Fatalf("apitest: %v", err)
+		}
+		s.BackupRepository = repositories.NewMemoryBackupRepository()
+		s.Backups = backups.NewService(s.BackupRepository, encrypted, backupDumper{}, backups.Config{})
+		t.Cleanup(func() { s.Backups.Stop(context.Background()) })
+		s.MetricsHistory = func(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error) {
+			now := time.Now().UTC()
+			return &models.MetricsHistoryResponse{Resolution: 60, From: now.Add(-window), To: now, Points: []models.MetricsHistoryPoint{}}, nil
+		}
+	}
+
+	s.Admin = s.CreateAccount(t, "admin", true)
+	s.User = s.CreateAccount(t, "user", false)
+	s.Engine = s.router(t, o.middlewares)
+	return s
+}
+
+// router 按 bootstrap 的方式装配处理器和路由
+func (s *Server) router(t testing.TB, extra []gin.HandlerFunc) *gin.Engine {
+	logManager, err := logger.NewManager(config.LoggingConfig{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("apitest: %v", err)
+	}
+	nop := logger.NewZapLogger(zap.NewNop())
+	recorder := audit.NopRecorder{}
+	blacklist := cache.NewBlacklistService(s.Cache, s.JWT, nil)
+
+	// 保持接口值为 nil，处理器据此返回服务不可用
+	var (
+		appCache    cache.Cache
+		fileStorage storage.Storage
+		history     handlers.MetricsHistorySource
+		series      handlers.RateLimitTimeSeriesFunc
+	)
+	if s.Warmer != nil {
+		appCache = s.Cache
+	}
+	if s.Storage != nil {
+		fileStorage = s.Storage
+	}
+	if s.MetricsHistory != nil {
+		history = metricsHistoryFunc(func(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error) {
+			return s.MetricsHistory(ctx, window)
+		})
+	}
+	if s.RateLimits != nil {
+		series = func(window, resolution time.Duration) ([]metrics.RateLimitTimeSeriesPoint, bool) {
+			return s.RateLimits.GetTimeSeries(window, resolution), true
+		}
+	}
+
+	middlewares := []gin.HandlerFunc{recordRoute}
+	if s.HTTPMetrics != nil {
+		middlewares = append(middlewares, middleware.HTTPMetricsMiddleware(s.HTTPMetrics))
+	}
+	if s.DrainTracker != nil {
+		middlewares = append(middlewares, middleware.DrainTrackerMiddleware(s.DrainTracker, "/api/v1/admin/drain"))
+	}
+	middlewares = append(middlewares,
+		middleware.RecoveryMiddleware(nop, nil, nil),
+		middleware.NewETag(config.ETagConfig{Enabled: true, MaxBodySize: 1 << 20}).Middleware())
+	if s.Maintenance != nil {
+		middlewares = append(middlewares, middleware.NewMaintenance(config.MaintenanceConfig{
+			BypassTokens: MaintenanceBypassToken,
+			AllowPaths:   []string{"/healthz", "/readyz", "/api/v1/health", "/api/v1/ready", "/api/v1/live", "/api/v1/admin/maintenance", "/api/v1/admin/drain"},
+		}, s.Maintenance).Middleware())
+	}
+	if s.Billing != nil {
+		middlewares = append(middlewares, middleware.PlanMiddleware(s.Billing, s.JWT))
+	}
+	middlewares = append(middlewares, extra...)
+
+	router := routes.NewRouter(
+		handlers.NewAuthHandler(s.JWT, s.UserService, blacklist, s.AuthMetrics),
+		handlers.NewUserHandler(s.UserService),
+		handlers.NewHealthHandler(nil, appCache, s.Health, blacklist, metrics.NewRegistry()),
+		handlers.NewAvatarHandler(s.UserService, fileStorage, MaxUploadSize, []string{"image/jpeg", "image/png", "image/gif", "image/webp"}, nil),
+		handlers.NewProfileHandler(s.UserService, blacklist, recorder),
+		handlers.NewAdminUserHandler(s.UserService, s.JWT, blacklist, recorder),
+		handlers.NewAdminOverviewHandler(s.Users, nil, appCache, "memory", nil),
+		handlers.NewMetricsHandler(handlers.MetricsHandlerOptions{
+			HTTP:            s.HTTPMetrics,
+			History:         history,
+			RateLimitSeries: series,
+			RequestQueries:  s.RequestQueries,
+			GeoIP:           s.Warmer != nil,
+		}),
+		handlers.NewLoggingHandler(logManager),
+		handlers.NewMetaHandler(),
+		handlers.NewCacheHandler(appCache, "memory", s.Warmer),
+		handlers.NewFeatureFlagHandler(s.FeatureFlags, recorder),
+		handlers.NewBanListHandler(s.BanList, recorder),
+		handlers.NewNotificationHandler(s.Notifications),
+		handlers.NewAnnouncementHandler(s.Announcements, recorder, time.Second),
+		handlers.NewExportHandler(s.Exports, recorder),
+		handlers.NewImportHandler(s.Imports, recorder),
+		handlers.NewPrivacyHandler(s.Privacy, s.UserService, recorder),
+		handlers.NewOIDCHandler(s.OIDC, recorder),
+		handlers.NewSAMLHandler(s.SAML, recorder),
+		handlers.NewPasswordlessHandler(s.Passwordless, recorder),
+		handlers.NewQuotaHandler(s.Quota),
+		handlers.NewBillingHandler(s.Billing),
+		handlers.NewMaintenanceHandler(s.Maintenance, recorder),
+		handlers.NewDrainHandler(s.Drainer, recorder),
+		handlers.NewRateLimitHandler(s.RateLimiter, recorder),
+		handlers.NewRetentionHandler(s.Retention),
+		handlers.NewBackupHandler(s.Backups, recorder),
+		middleware.NewResponseCache(config.ResponseCacheConfig{}, appCache),
+		middleware.NewRequestCoalescer(config.CoalescingConfig{MaxBodySize: 1 << 20}),
+		s.BanList,
+		s.Quota,
+		s.JWT,
+		s.Users,
+		middlewares,
+	)
+	router.SetupRoutes()
+	return router.GetEngine()
+}
+
+// samlProvider 返回信任测试身份提供方的租户配置，SP 地址与 bootstrap 生成的格式相同
+func (s *Server) samlProvider(tenant string, autoProvision bool) *sso.Provider {
+	prefix := "http://localhost/api/v1/auth/saml/" + tenant
+	return &sso.Provider{
+		Tenant: tenant,
+		SP: &saml.ServiceProvider{
+			EntityID: prefix + "/metadata",
+			ACSURL:   prefix + "/acs",
+			IdP: saml.IdentityProvider{
+				EntityID:    s.IdP.EntityID,
+				SSOURL:      "https://idp.example.com/sso",
+				Certificate: s.IdP.Certificate,
+			},
+		},
+		AutoProvision: autoProvision,
+		Attributes:    sso.AttributeMapping{Email: "email", FirstName: "firstName", LastName: "lastName"},
+	}
+}
+
+// newNotifications 创建使用内存仓库和进程内事件总线的通知服务，只启用站内信渠道
+func newNotifications(t testing.TB, users notifications.UserLookup, c cache.Cache) *notifications.Service {
+	templates, err := notifications.NewTemplates(notifications.DefaultTemplates...)
+	if err != nil {
+		t.Fatalf("apitest: %v", err)
+	}
+	bus := events.NewMemoryBus()
+	service := notifications.NewService(repositories.NewMemoryNotificationRepository(), users, bus, c, templates, notifications.Config{})
+	if err := service.Start(context.Background()); err != nil {
+		t.Fatalf("apitest: %v", err)
+	}
+	t.Cleanup(func() {
+		service.Stop(context.Background())
+		bus.Close()
+	})
+	return service
+}
+
+// metricsHistoryFunc 将函数适配为 MetricsHistorySource
+type metricsHistoryFunc func(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error)
+
+// History 调用函数本身
+func (f metricsHistoryFunc) History(ctx context.Context, window time.Duration) (*models.MetricsHistoryResponse, error) {
+	return f(ctx, window)
+}
+
+// backupDumper 导出固定内容并丢弃导入的内容，代替 pg_dump 和 pg_restore
+type backupDumper struct{}
+
+// Dump 写入 BackupContent
+func (backupDumper) Dump(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, BackupContent)
+	return err
+}
+
+// Restore 读取并丢弃备份内容
+func (backupDumper) Restore(ctx context.Context, r io.Reader) error {
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+// eventRecorder 同步记录用户服务发布的事件，代替事件总线
+type eventRecorder struct {
+	s *Server
+}
+
+// Publish 记录事件
+func (r eventRecorder) Publish(ctx context.Context, topic string, event *events.Event) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.events = append(r.s.events, event)
+	return nil
+}
+
+// Events 返回用户服务发布的给定类型的事件，按发布顺序排列
+func (s *Server) Events(eventType string) []*events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*events.Event
+	for _, event := range s.events {
+		if event.Type == eventType {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// CreateAccount 注册账号（邮箱为 name@example.com，密码为 DefaultPassword）并签发访问令牌
+func (s *Server) CreateAccount(t testing.TB, name string, admin bool) *Account {
+	t.Helper()
+	ctx := context.Background()
+	user, err := s.UserService.Register(ctx, &models.RegisterRequest{
+		Username:  name,
+		Email:     name + "@example.com",
+		Password:  DefaultPassword,
+		FirstName: strings.ToUpper(name[:1]) + name[1:],
+		LastName:  "Test",
+	})
+	if err != nil {
+		t.Fatalf("apitest: failed to seed %s: %v", name, err)
+	}
+	if admin {
+		if user, err = s.Users.SetAdmin(ctx, user.ID, true); err != nil {
+			t.Fatalf("apitest: failed to grant admin to %s: %v", name, err)
+		}
+	}
+	return &Account{User: user, Password: DefaultPassword, Token: s.Token(t, user)}
+}
+
+// Token 为用户签发访问令牌
+func (s *Server) Token(t testing.TB, user *models.User) string {
+	t.Helper()
+	token, err := s.JWT.GenerateToken(user.ID, user.Username, user.Email)
+	if err != nil {
+		t.Fatalf("apitest: failed to generate token: %v", err)
+	}
+	return token
+}
+
+// routeKey 请求上下文中记录匹配路由的键
+type routeKey struct{}
+
+// recordRoute 把匹配到的路由模板写回请求上下文，Run 据此找到接口文档
+func recordRoute(c *gin.Context) {
+	if route, ok := c.Request.Context().Value(routeKey{}).(*string); ok {
+		*route = c.FullPath()
+	}
+	c.Next()
+}
+
+// Case 一个请求及其期望的状态码
+type Case struct {
+	Name   string      // 子测试名称，为空时使用 方法 路径 状态码
+	Method string      // 请求方法
+	Path   string      // 请求路径，可包含查询参数
+	As     *Account    // 以该账号的令牌发送请求
+	Token  string      // 直接指定访问令牌，优先于 As
+	Header http.Header // 额外的请求头
+	Body   interface{} // 请求体：[]byte 和 io.Reader 原样发送，其他值编码为 JSON
+	Status int         // 期望的状态码
+
+	// Context 请求的上下文，为 nil 时使用 context.Background；流式接口在上下文结束后才返回
+	Context context.Context
+
+	// Setup 发送请求前调用，可准备数据或通过 t.Cleanup 恢复状态
+	Setup func(t testing.TB, s *Server)
+	// Check 响应通过文档校验后调用，用于补充断言
+	Check func(t testing.TB, resp *httptest.ResponseRecorder)
+}
+
+// name 返回子测试名称
+func (c Case) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s %s %d", c.Method, c.Path, c.Status)
+}
+
+// Run 依次执行用例：断言状态码并按文档严格校验响应
+func (s *Server) Run(t *testing.T, cases ...Case) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name(), func(t *testing.T) {
+			if tc.Setup != nil {
+				tc.Setup(t, s)
+			}
+			resp, route := s.Do(t, tc)
+			if resp.Code != tc.Status {
+				t.Fatalf("status = %d, want %d; body: %s", resp.Code, tc.Status, resp.Body.String())
+			}
+			s.Validate(t, tc.Method, route, resp)
+			if tc.Check != nil {
+				tc.Check(t, resp)
+			}
+		})
+	}
+}
+
+// Do 发送用例描述的请求，返回响应和匹配的路由模板
+func (s *Server) Do(t testing.TB, tc Case) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	var body io.Reader
+	var isJSON bool
+	switch b := tc.Body.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(b)
+	case io.Reader:
+		body = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("apitest: failed to encode request body: %v", err)
+		}
+		body, isJSON = bytes.NewReader(data), true
+	}
+
+	parent := tc.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	var route string
+	ctx := context.WithValue(parent, routeKey{}, &route)
+	req := httptest.NewRequest(tc.Method, tc.Path, body).WithContext(ctx)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range tc.Header {
+		req.Header[name] = values
+	}
+	token := tc.Token
+	if token == "" && tc.As != nil {
+		token = tc.As.Token
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp := httptest.NewRecorder()
+	s.Engine.ServeHTTP(resp, req)
+	return resp, route
+}
+
+// Validate 按文档校验响应并记录覆盖的状态码，route 为 gin 路由模板
+func (s *Server) Validate(t testing.TB, method, route string, resp *httptest.ResponseRecorder) {
+	t.Helper()
+
+	op, ok := s.validator.Operation(method, route)
+	if !ok {
+		t.Errorf("%s %q is not documented", method, route)
+		return
+	}
+	for _, fieldError := range s.validator.ValidateResponse(op, resp.Code, resp.Header(), resp.Body.Bytes(), true) {
+		t.Errorf("%s %d: %s (value: %v)", op.OperationID, resp.Code, fieldError.Message, fieldError.Value)
+	}
+
+	s.mu.Lock()
+	s.covered[op.OperationID+" "+strconv.Itoa(resp.Code)] = true
+	s.mu.Unlock()
+}
+
+// Skip 不要求覆盖的接口状态码，如需要外部依赖才能触发的错误
+type Skip struct {
+	OperationID string
+	Status      int
+	Reason      string
+}
+
+// AssertCoverage 断言文档中每个接口的每个状态码都被某个服务器的用例覆盖或在 skips 中跳过
+// 不存在的跳过项也视为错误，避免文档变更后跳过项失效
+func AssertCoverage(t testing.TB, skips []Skip, servers ...*Server) {
+	t.Helper()
+	if len(servers) == 0 {
+		t.Fatal("apitest: AssertCoverage needs at least one server")
+	}
+
+	documented := make(map[string]bool)
+	for _, item := range servers[0].doc.Paths {
+		for _, op := range []*openapi.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch, item.Head} {
+			if op == nil {
+				continue
+			}
+			for status := range op.Responses {
+				documented[op.OperationID+" "+status] = true
+			}
+		}
+	}
+
+	covered := make(map[string]bool)
+	for _, s := range servers {
+		s.mu.Lock()
+		for key := range s.covered {
+			covered[key] = true
+		}
+		s.mu.Unlock()
+	}
+	for _, skip := range skips {
+		key := skip.OperationID + " " + strconv.Itoa(skip.Status)
+		if !documented[key] {
+			t.Errorf("skipped %s is not documented", key)
+		}
+		if covered[key] {
+			t.Errorf("skipped %s is covered, remove the skip", key)
+		}
+		covered[key] = true
+	}
+
+	var missing []string
+	for key := range documented {
+		if !covered[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		t.Errorf("documented response %s is not covered", key)
+	}
+}
@@ -0,0 +1,1437 @@
+package apitest
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go-server/internal/middleware"
+	"go-server/internal/models"
+	"go-server/internal/notifications"
+	"go-server/internal/passwordless"
+	"go-server/internal/repositories"
+	"go-server/internal/services"
+	"go-server/pkg/auth"
+	"go-server/pkg/banlist"
+	"go-server/pkg/cache"
+	"go-server/pkg/drain"
+	"go-server/pkg/health"
+	"go-server/pkg/maintenance"
+	"go-server/pkg/quota"
+	"go-server/pkg/saml"
+	"go-server/pkg/saml/samltest"
+	"go-server/pkg/stripe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingID 不存在的用户ID
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+// pngHeader PNG 文件头，足以让内容类型探测识别为 image/png
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// skips 内存环境中无法触发的响应
+var skips = []Skip{
+	{OperationID: "authChangePassword", Status: http.StatusOK, Reason: "路由没有挂载认证中间件，处理器取不到当前用户，改用 POST /api/v1/users/me/password"},
+	{OperationID: "healthMetrics", Status: http.StatusOK, Reason: "需要数据库连接池统计"},
+	{OperationID: "cacheFlush", Status: http.StatusBadRequest, Reason: "只有不支持按前缀清空的驱动（memcached）要求 force=true"},
+	{OperationID: "exportCreateExport", Status: http.StatusConflict, Reason: "内存仓库中导出任务立即完成，无法稳定占满执行名额"},
+	{OperationID: "backupCreateBackup", Status: http.StatusConflict, Reason: "测试用的导出立即完成，无法稳定占用本实例的执行名额"},
+	{OperationID: "retentionRunRetention", Status: http.StatusConflict, Reason: "清理在请求中同步执行，内存仓库中立即完成，无法稳定构造并发清理"},
+	{OperationID: "importImportUsers", Status: http.StatusRequestEntityTooLarge, Reason: "请求体大小由全局 body_limit 中间件限制，测试路由未挂载该中间件"},
+	{OperationID: "sAMLLogin", Status: http.StatusForbidden, Reason: "租户停用需要启用多租户，测试服务器不查询租户"},
+	{OperationID: "userGetUser", Status: http.StatusServiceUnavailable, Reason: "重试用尽的数据库暂时性错误需要 PostgreSQL，测试服务器使用内存仓库"},
+}
+
+// multipartAvatar 构造头像上传请求体
+func multipartAvatar(t testing.TB, field string, content []byte) ([]byte, http.Header) {
+	return multipartFile(t, field, "avatar.png", content)
+}
+
+// multipartFile 构造只包含一个文件的 multipart 请求体
+func multipartFile(t testing.TB, field, filename string, content []byte) ([]byte, http.Header) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), http.Header{"Content-Type": {writer.FormDataContentType()}}
+}
+
+// adminOnly 为管理员接口生成未认证和非管理员的用例
+func adminOnly(s *Server, method, path string) []Case {
+	return []Case{
+		{Method: method, Path: path, Status: http.StatusUnauthorized},
+		{Method: method, Path: path, As: s.User, Status: http.StatusForbidden},
+	}
+}
+
+func TestAPI(t *testing.T) {
+	s := New(t)
+	degraded := New(t, Degraded())
+	broken := New(t)
+	require.NoError(t, broken.Cache.Close())
+
+	t.Run("public", func(t *testing.T) {
+		s.Run(t,
+			Case{Method: "GET", Path: "/.well-known/jwks.json", Status: http.StatusOK},
+			Case{Method: "GET", Path: "/openapi.json", Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/meta/errors", Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/health", Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/metrics", Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: "/api/v1/metrics/prometheus", Status: http.StatusOK},
+		)
+
+		for _, path := range []string{"/healthz", "/api/v1/live"} {
+			s.Run(t,
+				Case{Method: "GET", Path: path, Status: http.StatusOK},
+				Case{Method: "GET", Path: path, Status: http.StatusServiceUnavailable, Setup: func(t testing.TB, s *Server) {
+					s.Health.RegisterLiveness("broken", health.CheckerFunc(func(context.Context) error { return errors.New("broken") }))
+					t.Cleanup(func() { s.Health.Unregister("broken") })
+				}},
+			)
+		}
+		for _, path := range []string{"/readyz", "/api/v1/ready"} {
+			s.Run(t,
+				Case{Method: "GET", Path: path, Status: http.StatusOK},
+				Case{Method: "GET", Path: path, Status: http.StatusServiceUnavailable, Setup: func(t testing.TB, s *Server) {
+					s.Health.SetShuttingDown(true)
+					t.Cleanup(func() { s.Health.SetShuttingDown(false) })
+				}},
+			)
+		}
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		logout := s.CreateAccount(t, "logout", false)
+		revoking := s.CreateAccount(t, "revoking", false)
+		refresher := s.CreateAccount(t, "refresher", false)
+		refreshToken := func(t testing.TB, s *Server, account *Account) string {
+			token, err := s.JWT.GenerateRefreshToken(account.User.ID, account.User.Username, account.User.Email, "")
+			require.NoError(t, err)
+			return token
+		}
+		rotated := refreshToken(t, s, refresher)
+		revoked := refreshToken(t, s, revoking)
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/auth/register", Status: http.StatusCreated,
+				Body: models.RegisterRequest{Username: "newcomer", Email: "newcomer@example.com", Password: DefaultPassword}},
+			Case{Method: "POST", Path: "/api/v1/auth/register", Status: http.StatusBadRequest,
+				Body: models.RegisterRequest{Username: "x", Email: "not-an-email", Password: DefaultPassword}},
+			Case{Method: "POST", Path: "/api/v1/auth/register", Status: http.StatusBadRequest,
+				Name: "密码不符合密码策略",
+				Body: models.RegisterRequest{Username: "policyuser", Email: "policyuser@example.com", Password: "policyuser1"},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"error_code":"PASSWORD_USER_INFO"`)
+					assert.Contains(t, resp.Body.String(), `"suggestions":[`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/auth/register", Status: http.StatusConflict,
+				Body: models.RegisterRequest{Username: "another", Email: s.User.User.Email, Password: DefaultPassword}},
+
+			Case{Method: "POST", Path: "/api/v1/auth/login", Status: http.StatusOK,
+				Body: models.LoginRequest{Email: s.User.User.Email, Password: DefaultPassword},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"refresh_token":"`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/auth/login", Status: http.StatusBadRequest,
+				Body: map[string]string{"email": s.User.User.Email}},
+			Case{Method: "POST", Path: "/api/v1/auth/login", Status: http.StatusUnauthorized,
+				Body: models.LoginRequest{Email: s.User.User.Email, Password: "wrong-password"}},
+			Case{Method: "POST", Path: "/api/v1/auth/login", Status: http.StatusUnauthorized,
+				Name:   "按 Accept-Language 返回本地化消息",
+				Header: http.Header{"Accept-Language": {"zh-CN,zh;q=0.9"}},
+				Body:   models.LoginRequest{Email: s.User.User.Email, Password: "wrong-password"},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"user_message":"邮箱或密码错误"`)
+				}},
+
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusOK,
+				Body: models.RefreshTokenRequest{RefreshToken: rotated},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"refresh_token":"`)
+					assert.NotContains(t, resp.Body.String(), rotated)
+				}},
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusUnauthorized,
+				Name: "轮换后的刷新令牌不能再次使用",
+				Body: models.RefreshTokenRequest{RefreshToken: rotated}},
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusUnauthorized,
+				Name:   "访问令牌不能用于刷新",
+				Header: http.Header{"Accept-Language": {"zh"}},
+				Body:   models.RefreshTokenRequest{RefreshToken: refresher.Token},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), "刷新令牌无效或已过期")
+				}},
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusBadRequest, Body: map[string]string{}},
+
+			Case{Method: "POST", Path: "/api/v1/auth/change-password", Status: http.StatusBadRequest, Body: map[string]string{}},
+			Case{Method: "POST", Path: "/api/v1/auth/change-password", Status: http.StatusUnauthorized,
+				Body: models.ChangePasswordRequest{OldPassword: DefaultPassword, NewPassword: "N3wPassw0rd!"}},
+
+			Case{Method: "GET", Path: "/api/v1/auth/me", As: s.User, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/auth/me", Status: http.StatusUnauthorized},
+
+			Case{Method: "POST", Path: "/api/v1/auth/logout", As: logout, Status: http.StatusOK},
+			Case{Method: "POST", Path: "/api/v1/auth/logout", Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/auth/logout", As: s.User, Status: http.StatusBadRequest, Body: []byte("{")},
+			Case{Method: "POST", Path: "/api/v1/auth/logout", As: s.User, Status: http.StatusUnauthorized,
+				Name: "不能吊销其他用户的刷新令牌",
+				Body: models.LogoutRequest{RefreshToken: revoked}},
+			Case{Method: "POST", Path: "/api/v1/auth/logout", As: revoking, Status: http.StatusOK,
+				Name: "登出时同时吊销刷新令牌",
+				Body: models.LogoutRequest{RefreshToken: revoked}},
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusUnauthorized,
+				Name: "登出时吊销的刷新令牌不能使用",
+				Body: models.RefreshTokenRequest{RefreshToken: revoked}},
+		)
+		broken.Run(t,
+			Case{Method: "POST", Path: "/api/v1/auth/logout", As: broken.User, Status: http.StatusInternalServerError},
+			Case{Method: "POST", Path: "/api/v1/auth/refresh", Status: http.StatusInternalServerError,
+				Body: models.RefreshTokenRequest{RefreshToken: refreshToken(t, broken, broken.User)}},
+		)
+
+		attempts := make(map[string]uint64)
+		for _, a := range s.AuthMetrics.Attempts() {
+			attempts[a.Operation+" "+a.Outcome] = a.Count
+		}
+		assert.NotZero(t, attempts["login success"])
+		assert.NotZero(t, attempts["login invalid_credentials"])
+		assert.NotZero(t, attempts["refresh invalid_token"])
+		assert.NotZero(t, attempts["logout success"])
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		resp, _ := s.Do(t, Case{Method: "GET", Path: "/api/v1/users/me", As: s.User})
+		etag := resp.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		deleted := s.CreateAccount(t, "deleted", false)
+		require.NoError(t, s.Users.Delete(context.Background(), deleted.User.ID))
+		leaving := s.CreateAccount(t, "leaving", false)
+		rotating := s.CreateAccount(t, "rotating", false)
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/users/me", As: s.User, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/users/me", As: s.User, Header: http.Header{"If-None-Match": {etag}}, Status: http.StatusNotModified},
+			Case{Method: "GET", Path: "/api/v1/users/me", Status: http.StatusUnauthorized},
+			Case{Method: "GET", Path: "/api/v1/users/me", As: deleted, Status: http.StatusNotFound},
+
+			Case{Method: "PUT", Path: "/api/v1/users/me", As: s.User, Status: http.StatusOK,
+				Body: models.UpdateUserRequest{FirstName: "Updated"}},
+			Case{Method: "PUT", Path: "/api/v1/users/me", As: s.User, Status: http.StatusBadRequest,
+				Body: models.UpdateUserRequest{Username: "x"}},
+			Case{Method: "PUT", Path: "/api/v1/users/me", Status: http.StatusUnauthorized,
+				Body: models.UpdateUserRequest{FirstName: "Updated"}},
+			Case{Method: "PUT", Path: "/api/v1/users/me", As: s.User, Status: http.StatusConflict,
+				Body: models.UpdateUserRequest{Username: s.Admin.User.Username}},
+			Case{Method: "PUT", Path: "/api/v1/users/me", As: s.User, Status: http.StatusPreconditionFailed,
+				Header: http.Header{"If-Match": {`"stale"`}}, Body: models.UpdateUserRequest{FirstName: "Stale"}},
+
+			Case{Method: "POST", Path: "/api/v1/users/me/password", As: rotating, Status: http.StatusOK,
+				Body: models.ChangePasswordRequest{OldPassword: DefaultPassword, NewPassword: "N3wPassw0rd!"}},
+			Case{Method: "POST", Path: "/api/v1/users/me/password", As: s.User, Status: http.StatusBadRequest,
+				Body: models.ChangePasswordRequest{OldPassword: "wrong-password", NewPassword: "N3wPassw0rd!"}},
+			Case{Method: "POST", Path: "/api/v1/users/me/password", As: s.User, Status: http.StatusBadRequest,
+				Name: "新密码不符合密码策略",
+				Body: models.ChangePasswordRequest{OldPassword: DefaultPassword, NewPassword: "short1"},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"field":"new_password"`)
+					assert.Contains(t, resp.Body.String(), `"constraint":"min_length=8"`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/users/me/password", Status: http.StatusUnauthorized,
+				Body: models.ChangePasswordRequest{OldPassword: DefaultPassword, NewPassword: "N3wPassw0rd!"}},
+
+			Case{Method: "DELETE", Path: "/api/v1/users/me", As: leaving, Status: http.StatusOK,
+				Body: models.DeleteAccountRequest{Password: DefaultPassword}},
+			Case{Method: "DELETE", Path: "/api/v1/users/me", As: s.User, Status: http.StatusBadRequest,
+				Body: models.DeleteAccountRequest{Password: "wrong-password"}},
+			Case{Method: "DELETE", Path: "/api/v1/users/me", Status: http.StatusUnauthorized,
+				Body: models.DeleteAccountRequest{Password: DefaultPassword}},
+		)
+	})
+
+	t.Run("email change", func(t *testing.T) {
+		changing := s.CreateAccount(t, "changing", false)
+		racing := s.CreateAccount(t, "racing", false)
+		var token string
+
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/users/me/email", As: changing, Status: http.StatusAccepted,
+				Body: models.ChangeEmailRequest{NewEmail: "changed@example.com", Password: DefaultPassword},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					sent := s.Events(services.EventEmailChangeRequested)
+					require.NotEmpty(t, sent)
+					var payload services.EmailChangeRequestedEvent
+					require.NoError(t, sent[len(sent)-1].Decode(&payload))
+					token = payload.Token
+				}},
+			Case{Method: "POST", Path: "/api/v1/users/me/email", As: s.User, Status: http.StatusBadRequest,
+				Body: models.ChangeEmailRequest{NewEmail: "other@example.com", Password: "wrong-password"}},
+			Case{Method: "POST", Path: "/api/v1/users/me/email", Status: http.StatusUnauthorized,
+				Body: models.ChangeEmailRequest{NewEmail: "other@example.com", Password: DefaultPassword}},
+			Case{Method: "POST", Path: "/api/v1/users/me/email", As: s.User, Status: http.StatusConflict,
+				Body: models.ChangeEmailRequest{NewEmail: s.Admin.User.Email, Password: DefaultPassword}},
+		)
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/users/me/email/verify", As: changing, Status: http.StatusOK,
+				Body: models.ConfirmEmailChangeRequest{Token: token}},
+			Case{Method: "POST", Path: "/api/v1/users/me/email/verify", As: s.User, Status: http.StatusBadRequest,
+				Body: models.ConfirmEmailChangeRequest{Token: "invalid"}},
+			Case{Method: "POST", Path: "/api/v1/users/me/email/verify", Status: http.StatusUnauthorized,
+				Body: models.ConfirmEmailChangeRequest{Token: "invalid"}},
+		)
+
+		// 申请后新邮箱被其他用户注册
+		s.Run(t, Case{Method: "POST", Path: "/api/v1/users/me/email", As: racing, Status: http.StatusAccepted,
+			Body: models.ChangeEmailRequest{NewEmail: "raced@example.com", Password: DefaultPassword}})
+		sent := s.Events(services.EventEmailChangeRequested)
+		var payload services.EmailChangeRequestedEvent
+		require.NoError(t, sent[len(sent)-1].Decode(&payload))
+		s.CreateAccount(t, "raced", false)
+		s.Run(t, Case{Method: "POST", Path: "/api/v1/users/me/email/verify", As: racing, Status: http.StatusConflict,
+			Body: models.ConfirmEmailChangeRequest{Token: payload.Token}})
+
+		degraded.Run(t, Case{Method: "POST", Path: "/api/v1/users/me/email", As: degraded.User, Status: http.StatusServiceUnavailable,
+			Body: models.ChangeEmailRequest{NewEmail: "other@example.com", Password: DefaultPassword}})
+	})
+
+	t.Run("avatar", func(t *testing.T) {
+		valid, validHeader := multipartAvatar(t, "avatar", append(pngHeader, make([]byte, 64)...))
+		large, largeHeader := multipartAvatar(t, "avatar", append(pngHeader, make([]byte, MaxUploadSize)...))
+		text, textHeader := multipartAvatar(t, "avatar", []byte("plain text, not an image"))
+		missing, missingHeader := multipartAvatar(t, "other", pngHeader)
+
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/users/me/avatar", As: s.User, Body: valid, Header: validHeader, Status: http.StatusOK},
+			Case{Method: "POST", Path: "/api/v1/users/me/avatar", As: s.User, Body: missing, Header: missingHeader, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/users/me/avatar", Body: valid, Header: validHeader, Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/users/me/avatar", As: s.User, Body: large, Header: largeHeader, Status: http.StatusRequestEntityTooLarge},
+			Case{Method: "POST", Path: "/api/v1/users/me/avatar", As: s.User, Body: text, Header: textHeader, Status: http.StatusUnsupportedMediaType},
+		)
+		degraded.Run(t, Case{Method: "POST", Path: "/api/v1/users/me/avatar", As: degraded.User, Body: valid, Header: validHeader, Status: http.StatusServiceUnavailable})
+
+		// 头像跳转不需要访问令牌
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/users/" + s.User.User.ID + "/avatar", Status: http.StatusFound},
+			Case{Method: "GET", Path: "/api/v1/users/" + s.Admin.User.ID + "/avatar", Status: http.StatusNotFound},
+		)
+		degraded.Run(t, Case{Method: "GET", Path: "/api/v1/users/" + degraded.User.User.ID + "/avatar", Status: http.StatusServiceUnavailable})
+	})
+
+	t.Run("users", func(t *testing.T) {
+		target := s.CreateAccount(t, "target", false)
+		removed := s.CreateAccount(t, "removed", false)
+		userPath := "/api/v1/users/" + target.User.ID
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/users?page=1&limit=10", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/users?limit=500", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "GET", Path: "/api/v1/users", Status: http.StatusUnauthorized},
+			Case{Method: "GET", Path: "/api/v1/users", As: s.User, Status: http.StatusForbidden},
+
+			Case{Method: "GET", Path: userPath, As: s.User, Status: http.StatusOK},
+			Case{Method: "GET", Path: userPath, Status: http.StatusUnauthorized},
+			Case{Method: "GET", Path: "/api/v1/users/" + missingID, As: s.User, Status: http.StatusNotFound},
+
+			Case{Method: "PUT", Path: userPath, As: s.Admin, Status: http.StatusOK, Body: models.UpdateUserRequest{LastName: "Renamed"}},
+			Case{Method: "PUT", Path: userPath, As: s.Admin, Status: http.StatusBadRequest, Body: models.UpdateUserRequest{Username: "x"}},
+			Case{Method: "PUT", Path: userPath, Status: http.StatusUnauthorized, Body: models.UpdateUserRequest{LastName: "Renamed"}},
+			Case{Method: "PUT", Path: "/api/v1/users/" + missingID, As: s.Admin, Status: http.StatusNotFound, Body: models.UpdateUserRequest{LastName: "Renamed"}},
+			Case{Method: "PUT", Path: userPath, As: s.Admin, Status: http.StatusPreconditionFailed,
+				Header: http.Header{"If-Match": {`"stale"`}}, Body: models.UpdateUserRequest{LastName: "Stale"}},
+
+			Case{Method: "DELETE", Path: "/api/v1/users/" + removed.User.ID, As: s.Admin, Status: http.StatusOK},
+			Case{Method: "DELETE", Path: userPath, Status: http.StatusUnauthorized},
+			Case{Method: "DELETE", Path: userPath, As: s.User, Status: http.StatusForbidden},
+			Case{Method: "DELETE", Path: "/api/v1/users/" + missingID, As: s.Admin, Status: http.StatusNotFound},
+		)
+	})
+
+	t.Run("admin users", func(t *testing.T) {
+		target := s.CreateAccount(t, "managed", false)
+		otherAdmin := s.CreateAccount(t, "otheradmin", true)
+		base := "/api/v1/admin/users/" + target.User.ID
+		missing := "/api/v1/admin/users/" + missingID
+
+		var cases []Case
+		cases = append(cases, adminOnly(s, "GET", "/api/v1/admin/users")...)
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/users?q=man&active=true&role=user", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/users?role=owner", As: s.Admin, Status: http.StatusBadRequest},
+		)
+		cases = append(cases, adminOnly(s, "GET", "/api/v1/admin/users/stream")...)
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/users/stream?active=true", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/users/stream?active=maybe", As: s.Admin, Status: http.StatusBadRequest},
+		)
+		for _, action := range []string{"deactivate", "activate", "password-reset"} {
+			cases = append(cases, adminOnly(s, "POST", base+"/"+action)...)
+			cases = append(cases,
+				Case{Method: "POST", Path: base + "/" + action, As: s.Admin, Status: http.StatusOK},
+				Case{Method: "POST", Path: missing + "/" + action, As: s.Admin, Status: http.StatusNotFound},
+			)
+		}
+		cases = append(cases, adminOnly(s, "PUT", base+"/roles")...)
+		cases = append(cases,
+			Case{Method: "PUT", Path: base + "/roles", As: s.Admin, Status: http.StatusOK, Body: models.AssignRolesRequest{Roles: []string{models.RoleUser}}},
+			Case{Method: "PUT", Path: base + "/roles", As: s.Admin, Status: http.StatusBadRequest, Body: models.AssignRolesRequest{Roles: []string{"owner"}}},
+			Case{Method: "PUT", Path: missing + "/roles", As: s.Admin, Status: http.StatusNotFound, Body: models.AssignRolesRequest{Roles: []string{models.RoleUser}}},
+		)
+		cases = append(cases, adminOnly(s, "POST", base+"/impersonate")...)
+		cases = append(cases,
+			Case{Method: "POST", Path: base + "/impersonate", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "POST", Path: "/api/v1/admin/users/" + s.Admin.User.ID + "/impersonate", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/admin/users/" + otherAdmin.User.ID + "/impersonate", As: s.Admin, Status: http.StatusForbidden},
+			Case{Method: "POST", Path: missing + "/impersonate", As: s.Admin, Status: http.StatusNotFound},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("impersonation sessions", func(t *testing.T) {
+		target := s.CreateAccount(t, "impersonated", false)
+		token, err := s.JWT.GenerateImpersonationToken(target.User.ID, target.User.Username, target.User.Email, s.Admin.User.ID, time.Minute)
+		require.NoError(t, err)
+		session := &Account{User: target.User, Token: token}
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/auth/me", As: session, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, auth.ImpersonationBanner(target.User.Username, s.Admin.User.ID), resp.Header().Get(middleware.ImpersonationBannerHeader))
+				}},
+			Case{Method: "GET", Path: "/api/v1/auth/me", As: target, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Empty(t, resp.Header().Get(middleware.ImpersonationBannerHeader))
+				}},
+			Case{Method: "POST", Path: "/api/v1/admin/impersonation/stop", Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/admin/impersonation/stop", As: target, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/admin/impersonation/stop", As: session, Status: http.StatusOK},
+		)
+
+		brokenToken, err := broken.JWT.GenerateImpersonationToken(broken.User.User.ID, broken.User.User.Username, broken.User.User.Email, broken.Admin.User.ID, time.Minute)
+		require.NoError(t, err)
+		broken.Run(t, Case{Method: "POST", Path: "/api/v1/admin/impersonation/stop", As: &Account{User: broken.User.User, Token: brokenToken}, Status: http.StatusInternalServerError})
+	})
+
+	t.Run("admin overview and metrics", func(t *testing.T) {
+		var cases []Case
+		for _, path := range []string{
+			"/api/v1/admin/overview", "/api/v1/admin/metrics/http", "/api/v1/admin/metrics/history",
+			"/api/v1/admin/metrics/rate-limit", "/api/v1/admin/metrics/countries", "/api/v1/admin/metrics/queries",
+		} {
+			cases = append(cases, adminOnly(s, "GET", path)...)
+			cases = append(cases, Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK})
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/metrics/history?hours=0", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "GET", Path: "/api/v1/admin/metrics/history", As: s.Admin, Status: http.StatusInternalServerError,
+				Setup: func(t testing.TB, s *Server) {
+					previous := s.MetricsHistory
+					s.MetricsHistory = func(context.Context, time.Duration) (*models.MetricsHistoryResponse, error) {
+						return nil, errors.New("snapshot store unavailable")
+					}
+					t.Cleanup(func() { s.MetricsHistory = previous })
+				}},
+			Case{Method: "GET", Path: "/api/v1/admin/metrics/rate-limit?window=1s", As: s.Admin, Status: http.StatusBadRequest},
+		)
+		s.Run(t, cases...)
+
+		for _, path := range []string{"/api/v1/admin/metrics/http", "/api/v1/admin/metrics/history", "/api/v1/admin/metrics/rate-limit", "/api/v1/admin/metrics/countries", "/api/v1/admin/metrics/queries"} {
+			degraded.Run(t, Case{Method: "GET", Path: path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+	})
+
+	t.Run("logging", func(t *testing.T) {
+		var cases []Case
+		cases = append(cases, adminOnly(s, "GET", "/api/v1/admin/logging/level")...)
+		cases = append(cases, adminOnly(s, "PUT", "/api/v1/admin/logging/level")...)
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/logging/level", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "PUT", Path: "/api/v1/admin/logging/level", As: s.Admin, Status: http.StatusOK, Body: models.UpdateLogLevelRequest{Level: "warn"}},
+			Case{Method: "PUT", Path: "/api/v1/admin/logging/level", As: s.Admin, Status: http.StatusBadRequest, Body: map[string]string{}},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/api/v1/admin/cache/stats"}, {"GET", "/api/v1/admin/cache/keys"}, {"DELETE", "/api/v1/admin/cache/keys/session"},
+			{"POST", "/api/v1/admin/cache/flush"}, {"GET", "/api/v1/admin/cache/warm"}, {"POST", "/api/v1/admin/cache/warm"},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/cache/stats", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/cache/keys?pattern=user*&limit=10", As: s.Admin, Status: http.StatusOK},
+			Case{Name: "分页", Method: "GET", Path: "/api/v1/admin/cache/keys?pattern=page:*&limit=2", As: s.Admin, Status: http.StatusOK,
+				Setup: func(t testing.TB, s *Server) {
+					for _, key := range []string{"page:1", "page:2", "page:3"} {
+						require.NoError(t, s.Cache.Set(context.Background(), key, "value", time.Minute))
+					}
+				},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data models.CacheKeysResponse `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					assert.Equal(t, []string{"page:1", "page:2"}, body.Data.Keys)
+					require.True(t, body.Data.Truncated)
+
+					next, _ := s.Do(t, Case{Method: "GET", Path: "/api/v1/admin/cache/keys?pattern=page:*&limit=2&cursor=" + body.Data.Cursor, As: s.Admin})
+					assert.Contains(t, next.Body.String(), `"keys":["page:3"]`)
+					assert.Contains(t, next.Body.String(), `"truncated":false`)
+				}},
+			Case{Method: "GET", Path: "/api/v1/admin/cache/keys?limit=0", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "GET", Path: "/api/v1/admin/cache/keys?cursor=abc", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "DELETE", Path: "/api/v1/admin/cache/keys/session", As: s.Admin, Status: http.StatusOK,
+				Setup: func(t testing.TB, s *Server) {
+					require.NoError(t, s.Cache.Set(context.Background(), "session", "value", time.Minute))
+				}},
+			Case{Method: "DELETE", Path: "/api/v1/admin/cache/keys/session", As: s.Admin, Status: http.StatusNotFound},
+
+			Case{Method: "GET", Path: "/api/v1/admin/cache/warm", As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "POST", Path: "/api/v1/admin/cache/warm", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.WarmCacheRequest{Datasets: []string{"unknown"}}},
+			Case{Method: "POST", Path: "/api/v1/admin/cache/warm", As: s.Admin, Status: http.StatusAccepted,
+				Setup: func(t testing.TB, s *Server) {
+					s.Warmer.Register("slow", func(ctx context.Context, progress cache.WarmProgress) error {
+						select {
+						case <-release:
+						case <-ctx.Done():
+						}
+						return nil
+					})
+				}},
+			Case{Method: "POST", Path: "/api/v1/admin/cache/warm", As: s.Admin, Status: http.StatusConflict},
+			Case{Method: "GET", Path: "/api/v1/admin/cache/warm", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "POST", Path: "/api/v1/admin/cache/flush", As: s.Admin, Status: http.StatusOK},
+		)
+		s.Run(t, cases...)
+		broken.Run(t, Case{Method: "GET", Path: "/api/v1/admin/cache/stats", As: broken.Admin, Status: http.StatusInternalServerError})
+	})
+
+	t.Run("feature flags", func(t *testing.T) {
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/api/v1/admin/feature-flags"}, {"POST", "/api/v1/admin/feature-flags"},
+			{"GET", "/api/v1/admin/feature-flags/checkout"}, {"PUT", "/api/v1/admin/feature-flags/checkout"},
+			{"DELETE", "/api/v1/admin/feature-flags/checkout"},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+		flag := models.CreateFeatureFlagRequest{Key: "checkout", Enabled: true, Percentage: 50, Users: []string{}}
+		cases = append(cases,
+			Case{Method: "POST", Path: "/api/v1/admin/feature-flags", As: s.Admin, Status: http.StatusCreated, Body: flag},
+			Case{Method: "POST", Path: "/api/v1/admin/feature-flags", As: s.Admin, Status: http.StatusConflict, Body: flag},
+			Case{Method: "POST", Path: "/api/v1/admin/feature-flags", As: s.Admin, Status: http.StatusBadRequest, Body: map[string]string{}},
+			Case{Method: "GET", Path: "/api/v1/admin/feature-flags", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/feature-flags/checkout", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/feature-flags/missing", As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "PUT", Path: "/api/v1/admin/feature-flags/checkout", As: s.Admin, Status: http.StatusOK,
+				Body: models.UpdateFeatureFlagRequest{Enabled: true, Percentage: 100, Users: []string{s.User.User.ID}}},
+			Case{Method: "PUT", Path: "/api/v1/admin/feature-flags/checkout", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.UpdateFeatureFlagRequest{Percentage: 150}},
+			Case{Method: "PUT", Path: "/api/v1/admin/feature-flags/missing", As: s.Admin, Status: http.StatusNotFound,
+				Body: models.UpdateFeatureFlagRequest{Users: []string{}}},
+			Case{Method: "DELETE", Path: "/api/v1/admin/feature-flags/checkout", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "DELETE", Path: "/api/v1/admin/feature-flags/checkout", As: s.Admin, Status: http.StatusNotFound},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("bans", func(t *testing.T) {
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/api/v1/admin/bans"}, {"DELETE", "/api/v1/admin/bans/ip/203.0.113.7"},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/bans", As: s.Admin, Status: http.StatusOK,
+				Setup: func(t testing.TB, s *Server) {
+					now := time.Now()
+					require.NoError(t, s.Bans.Add(context.Background(), &banlist.Ban{
+						Type: "ip", Identifier: "203.0.113.7", Reason: "rate_limit_violations",
+						Violations: 120, ViolationRate: 85.5, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+					}))
+				}},
+			Case{Method: "DELETE", Path: "/api/v1/admin/bans/ip/203.0.113.7", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "DELETE", Path: "/api/v1/admin/bans/ip/203.0.113.7", As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "DELETE", Path: "/api/v1/admin/bans/device/abc", As: s.Admin, Status: http.StatusBadRequest},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, s.Notifications.Deliver(ctx, notifications.Job{UserID: s.User.User.ID, Type: notifications.TypeWelcome}))
+		require.NoError(t, s.Notifications.Deliver(ctx, notifications.Job{UserID: s.User.User.ID, Type: notifications.TypePasswordChanged,
+			Data: map[string]string{"time": "2026-01-02T03:04:05Z"}}))
+		inbox, _, err := s.Notifications.List(ctx, s.User.User.ID, false, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, inbox, 2)
+		readPath := "/api/v1/users/me/notifications/" + inbox[0].ID + "/read"
+
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/api/v1/users/me/notifications"}, {"POST", "/api/v1/users/me/notifications/read"}, {"POST", readPath},
+			{"GET", "/api/v1/users/me/notification-preferences"}, {"PUT", "/api/v1/users/me/notification-preferences"},
+		} {
+			cases = append(cases, Case{Method: route.method, Path: route.path, Status: http.StatusUnauthorized})
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.User, Status: http.StatusServiceUnavailable})
+		}
+		unreadCount := func(want int64) func(t testing.TB, resp *httptest.ResponseRecorder) {
+			return func(t testing.TB, resp *httptest.ResponseRecorder) {
+				var body struct {
+					Data models.NotificationListResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.Equal(t, want, body.Data.UnreadCount)
+			}
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/users/me/notifications", As: s.User, Status: http.StatusOK, Check: unreadCount(2)},
+			Case{Method: "GET", Path: "/api/v1/users/me/notifications?unread=maybe", As: s.User, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: readPath, As: s.User, Status: http.StatusOK},
+			Case{Method: "POST", Path: readPath, As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "GET", Path: "/api/v1/users/me/notifications?unread=true", As: s.User, Status: http.StatusOK, Check: unreadCount(1)},
+			Case{Method: "POST", Path: "/api/v1/users/me/notifications/read", As: s.User, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/users/me/notifications", As: s.User, Status: http.StatusOK, Check: unreadCount(0)},
+
+			Case{Method: "GET", Path: "/api/v1/users/me/notification-preferences", As: s.User, Status: http.StatusOK},
+			Case{Method: "PUT", Path: "/api/v1/users/me/notification-preferences", As: s.User, Status: http.StatusOK,
+				Body: models.UpdateNotificationPreferencesRequest{Preferences: []models.NotificationPreferenceRequest{
+					{Type: notifications.TypeWelcome, Channel: notifications.ChannelInApp, Enabled: false},
+				}}},
+			Case{Method: "PUT", Path: "/api/v1/users/me/notification-preferences", As: s.User, Status: http.StatusBadRequest,
+				Body: models.UpdateNotificationPreferencesRequest{Preferences: []models.NotificationPreferenceRequest{
+					{Type: "unknown", Channel: notifications.ChannelInApp},
+				}}},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("announcements", func(t *testing.T) {
+		announcement := &models.Announcement{Title: "计划维护", Body: "今晚维护", Roles: []string{"user"}}
+		require.NoError(t, s.Announcements.Create(context.Background(), announcement))
+		path := "/api/v1/admin/announcements/" + announcement.ID
+		missing := "/api/v1/admin/announcements/" + missingID
+
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", "/api/v1/admin/announcements"}, {"POST", "/api/v1/admin/announcements"},
+			{"GET", path}, {"PUT", path}, {"DELETE", path},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+		for _, route := range []string{"/api/v1/announcements", "/api/v1/announcements/stream"} {
+			degraded.Run(t, Case{Method: "GET", Path: route, Status: http.StatusServiceUnavailable})
+		}
+
+		titles := func(want ...string) func(t testing.TB, resp *httptest.ResponseRecorder) {
+			return func(t testing.TB, resp *httptest.ResponseRecorder) {
+				var body struct {
+					Data []models.Announcement `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				got := []string{}
+				for _, announcement := range body.Data {
+					got = append(got, announcement.Title)
+				}
+				assert.ElementsMatch(t, want, got)
+			}
+		}
+		streamCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		starts, ends := time.Now().Add(time.Hour), time.Now()
+		cases = append(cases,
+			Case{Method: "GET", Path: "/api/v1/admin/announcements", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/admin/announcements?limit=0", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/admin/announcements", As: s.Admin, Status: http.StatusCreated,
+				Body: models.AnnouncementRequest{Title: "管理员须知", Body: "请检查告警", Level: models.AnnouncementLevelWarning, Roles: []string{"admin"}}},
+			Case{Method: "POST", Path: "/api/v1/admin/announcements", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.AnnouncementRequest{Title: "无效", Body: "过期时间早于生效时间", StartsAt: &starts, EndsAt: &ends}},
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK},
+			Case{Method: "GET", Path: missing, As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusOK,
+				Body: models.AnnouncementRequest{Title: "维护延期", Body: "改为明晚维护", Roles: []string{"user"}}},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusBadRequest, Body: models.AnnouncementRequest{}},
+			Case{Method: "PUT", Path: missing, As: s.Admin, Status: http.StatusNotFound,
+				Body: models.AnnouncementRequest{Title: "不存在", Body: "不存在"}},
+
+			Case{Name: "anonymous sees untargeted announcements", Method: "GET", Path: "/api/v1/announcements", Status: http.StatusOK, Check: titles()},
+			Case{Name: "users see user announcements", Method: "GET", Path: "/api/v1/announcements", As: s.User, Status: http.StatusOK, Check: titles("维护延期")},
+			Case{Name: "admins see admin announcements", Method: "GET", Path: "/api/v1/announcements", As: s.Admin, Status: http.StatusOK, Check: titles("管理员须知", "维护延期")},
+			Case{Method: "GET", Path: "/api/v1/announcements/stream", As: s.User, Status: http.StatusOK, Context: streamCtx,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+					assert.Contains(t, resp.Body.String(), "event: announcements\ndata: [")
+					assert.Contains(t, resp.Body.String(), "维护延期")
+				}},
+
+			Case{Method: "DELETE", Path: path, As: s.Admin, Status: http.StatusOK},
+			Case{Method: "DELETE", Path: path, As: s.Admin, Status: http.StatusNotFound},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("exports", func(t *testing.T) {
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"POST", "/api/v1/admin/exports"}, {"GET", "/api/v1/admin/exports/" + missingID},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable,
+				Body: models.ExportRequest{Format: models.ExportFormatCSV}})
+		}
+		degraded.Run(t, Case{Method: "GET", Path: "/api/v1/exports/" + missingID + "/download?expires=1&signature=invalid", Status: http.StatusServiceUnavailable})
+
+		var exportID string
+		cases = append(cases,
+			Case{Method: "POST", Path: "/api/v1/admin/exports", As: s.Admin, Status: http.StatusAccepted,
+				Body: models.ExportRequest{Format: models.ExportFormatCSV},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data models.Export `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					exportID = body.Data.ID
+				}},
+			Case{Method: "POST", Path: "/api/v1/admin/exports", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.ExportRequest{Format: "pdf"}},
+			Case{Method: "GET", Path: "/api/v1/admin/exports/" + missingID, As: s.Admin, Status: http.StatusNotFound},
+		)
+		s.Run(t, cases...)
+		require.NotEmpty(t, exportID)
+
+		var export *models.Export
+		require.Eventually(t, func() bool {
+			var err error
+			export, err = s.Exports.Get(context.Background(), exportID)
+			return err == nil && export.Finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, models.ExportStatusCompleted, export.Status, export.Error)
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/admin/exports/" + exportID, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"download_url":"/api/v1/exports/`+exportID+`/download?`)
+				}},
+			Case{Method: "GET", Path: export.DownloadURL, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Header().Get("Content-Disposition"), "attachment")
+					assert.Contains(t, resp.Body.String(), s.Admin.User.Email)
+					assert.Contains(t, resp.Body.String(), s.User.User.Email)
+				}},
+			Case{Name: "tampered signature", Method: "GET", Path: "/api/v1/exports/" + exportID + "/download?expires=9999999999&signature=invalid", Status: http.StatusForbidden},
+			Case{Name: "expired link", Method: "GET", Path: "/api/v1/exports/" + exportID + "/download?expires=1&signature=invalid", Status: http.StatusForbidden},
+			Case{Name: "deleted file", Method: "GET", Path: export.DownloadURL, Status: http.StatusNotFound,
+				Setup: func(t testing.TB, s *Server) {
+					require.NoError(t, s.Storage.Delete(context.Background(), export.ObjectKey))
+				}},
+		)
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		csvFile, csvHeader := multipartFile(t, "file", "users.csv",
+			[]byte("username,email,password\nimported1,imported1@example.com,password1\nimported2,imported2@example.com,short\n"))
+		jsonFile, jsonHeader := multipartFile(t, "file", "users.json",
+			[]byte(`[{"username":"imported3","email":"imported3@example.com","password":"password1"}]`))
+		textFile, textHeader := multipartFile(t, "file", "users.txt", []byte("username,email,password\n"))
+		largeFile, largeHeader := multipartFile(t, "file", "users.csv",
+			[]byte("username,email,password\n"+strings.Repeat("a,b,c\n", 11)))
+		missing, missingHeader := multipartFile(t, "other", "users.csv", []byte("username,email,password\n"))
+
+		s.Run(t, append(adminOnly(s, "POST", "/api/v1/admin/imports"),
+			Case{Name: "dry run", Method: "POST", Path: "/api/v1/admin/imports?dry_run=true", As: s.Admin, Body: csvFile, Header: csvHeader, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"valid_rows":1`)
+					assert.Contains(t, resp.Body.String(), `"created":0`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/admin/imports", As: s.Admin, Body: csvFile, Header: csvHeader, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"created":1`)
+					assert.Contains(t, resp.Body.String(), `"code":"VALIDATION_FAILED"`)
+				}},
+			Case{Name: "json", Method: "POST", Path: "/api/v1/admin/imports?format=json", As: s.Admin, Body: jsonFile, Header: jsonHeader, Status: http.StatusOK},
+			Case{Name: "invalid dry_run", Method: "POST", Path: "/api/v1/admin/imports?dry_run=maybe", As: s.Admin, Body: csvFile, Header: csvHeader, Status: http.StatusBadRequest},
+			Case{Name: "unknown format", Method: "POST", Path: "/api/v1/admin/imports", As: s.Admin, Body: textFile, Header: textHeader, Status: http.StatusBadRequest},
+			Case{Name: "missing file", Method: "POST", Path: "/api/v1/admin/imports", As: s.Admin, Body: missing, Header: missingHeader, Status: http.StatusBadRequest},
+			Case{Name: "too many rows", Method: "POST", Path: "/api/v1/admin/imports", As: s.Admin, Body: largeFile, Header: largeHeader, Status: http.StatusBadRequest},
+		)...)
+
+		user, err := s.Users.GetByUsername(context.Background(), "imported1")
+		require.NoError(t, err)
+		assert.True(t, user.IsActive)
+		_, err = s.Users.GetByUsername(context.Background(), "imported3")
+		assert.NoError(t, err)
+
+		degraded.Run(t, Case{Method: "POST", Path: "/api/v1/admin/imports", As: degraded.Admin, Body: csvFile, Header: csvHeader, Status: http.StatusServiceUnavailable})
+	})
+
+	t.Run("privacy", func(t *testing.T) {
+		deleted := s.CreateAccount(t, "privacy_deleted", false)
+		require.NoError(t, s.Users.Delete(context.Background(), deleted.User.ID))
+		erasing := s.CreateAccount(t, "erasing", false)
+		target := s.CreateAccount(t, "erase_target", false)
+		password := models.DeleteAccountRequest{Password: DefaultPassword}
+
+		s.Run(t, append(adminOnly(s, "POST", "/api/v1/admin/users/"+target.User.ID+"/erasure"),
+			Case{Method: "POST", Path: "/api/v1/users/me/data-export", As: s.User, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"email":"user@example.com"`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/users/me/data-export", Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/users/me/data-export", As: deleted, Status: http.StatusNotFound},
+
+			Case{Method: "POST", Path: "/api/v1/users/me/erasure", As: erasing, Body: password, Status: http.StatusOK},
+			Case{Method: "POST", Path: "/api/v1/users/me/erasure", As: s.User, Status: http.StatusBadRequest,
+				Body: models.DeleteAccountRequest{Password: "wrong-password"}},
+			Case{Method: "POST", Path: "/api/v1/users/me/erasure", Body: password, Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/users/me/erasure", As: deleted, Body: password, Status: http.StatusNotFound},
+
+			Case{Method: "POST", Path: "/api/v1/admin/users/" + target.User.ID + "/erasure", As: s.Admin, Status: http.StatusOK},
+			Case{Name: "self", Method: "POST", Path: "/api/v1/admin/users/" + s.Admin.User.ID + "/erasure", As: s.Admin, Status: http.StatusForbidden},
+			Case{Method: "POST", Path: "/api/v1/admin/users/" + missingID + "/erasure", As: s.Admin, Status: http.StatusNotFound},
+		)...)
+
+		_, err := s.Users.GetByEmail(context.Background(), "erasing@example.com")
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound, "原邮箱可以重新注册")
+
+		degraded.Run(t,
+			Case{Method: "POST", Path: "/api/v1/users/me/data-export", As: degraded.User, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/users/me/erasure", As: degraded.User, Body: password, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/admin/users/" + degraded.User.User.ID + "/erasure", As: degraded.Admin, Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("oauth", func(t *testing.T) {
+		const (
+			redirectURI = "https://app.example.com/callback"
+			verifier    = "dBjftJeZ4CVP-mJ92K9ZD7-bmx4qPbNhv3uM3fK0Wt7Ft"
+		)
+		sum := sha256.Sum256([]byte(verifier))
+		authorize := models.OAuthAuthorizeRequest{
+			ResponseType:        "code",
+			RedirectURI:         redirectURI,
+			Scope:               "openid profile email",
+			State:               "xyz",
+			CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+			CodeChallengeMethod: "S256",
+		}
+		authorizePath := func(req models.OAuthAuthorizeRequest) string {
+			return "/api/v1/oauth/authorize?" + url.Values{
+				"response_type": {req.ResponseType}, "client_id": {req.ClientID}, "redirect_uri": {req.RedirectURI},
+				"scope": {req.Scope}, "state": {req.State}, "code_challenge": {req.CodeChallenge},
+				"code_challenge_method": {req.CodeChallengeMethod},
+			}.Encode()
+		}
+		form := func(values url.Values) ([]byte, http.Header) {
+			return []byte(values.Encode()), http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+		}
+		clientRequest := models.OAuthClientRequest{Name: "工单系统", RedirectURIs: []string{redirectURI}, Confidential: true}
+
+		var client models.OAuthClientCreatedResponse
+		cases := append(adminOnly(s, "GET", "/api/v1/admin/oauth-clients"), adminOnly(s, "POST", "/api/v1/admin/oauth-clients")...)
+		cases = append(cases, adminOnly(s, "DELETE", "/api/v1/admin/oauth-clients/"+missingID)...)
+		s.Run(t, append(cases,
+			Case{Method: "GET", Path: "/.well-known/openid-configuration", Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"token_endpoint":"http://localhost/api/v1/oauth/token"`)
+				}},
+			Case{Method: "POST", Path: "/api/v1/admin/oauth-clients", As: s.Admin, Body: clientRequest, Status: http.StatusCreated,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data models.OAuthClientCreatedResponse `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					client = body.Data
+				}},
+			Case{Name: "invalid redirect uri", Method: "POST", Path: "/api/v1/admin/oauth-clients", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.OAuthClientRequest{Name: "x", RedirectURIs: []string{"not a url"}}},
+			Case{Method: "GET", Path: "/api/v1/admin/oauth-clients", As: s.Admin, Status: http.StatusOK},
+			Case{Method: "DELETE", Path: "/api/v1/admin/oauth-clients/" + missingID, As: s.Admin, Status: http.StatusNotFound},
+		)...)
+		require.NotEmpty(t, client.ClientSecret)
+		authorize.ClientID = client.Client.ID
+
+		var code string
+		unknownClient := authorize
+		unknownClient.ClientID = missingID
+		s.Run(t,
+			Case{Method: "GET", Path: authorizePath(authorize), As: s.User, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"client_name":"工单系统"`)
+				}},
+			Case{Method: "GET", Path: authorizePath(unknownClient), As: s.User, Status: http.StatusBadRequest},
+			Case{Method: "GET", Path: authorizePath(authorize), Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/oauth/authorize", As: s.User, Status: http.StatusOK,
+				Body: models.OAuthConsentRequest{OAuthAuthorizeRequest: authorize, Approve: true},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data models.OAuthRedirectResponse `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					redirect, err := url.Parse(body.Data.RedirectTo)
+					require.NoError(t, err)
+					assert.Equal(t, "xyz", redirect.Query().Get("state"))
+					code = redirect.Query().Get("code")
+				}},
+			Case{Method: "POST", Path: "/api/v1/oauth/authorize", As: s.User, Status: http.StatusBadRequest,
+				Body: models.OAuthConsentRequest{OAuthAuthorizeRequest: unknownClient, Approve: true}},
+			Case{Method: "POST", Path: "/api/v1/oauth/authorize", Status: http.StatusUnauthorized,
+				Body: models.OAuthConsentRequest{OAuthAuthorizeRequest: authorize, Approve: true}},
+		)
+		require.NotEmpty(t, code)
+
+		exchange := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}, "code_verifier": {verifier}}
+		exchangeBody, formHeader := form(exchange)
+		basicHeader := formHeader.Clone()
+		basicHeader.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(client.Client.ID+":"+client.ClientSecret)))
+		wrongSecret := formHeader.Clone()
+		wrongSecret.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(client.Client.ID+":wrong")))
+
+		var accessToken string
+		s.Run(t,
+			Case{Name: "wrong client secret", Method: "POST", Path: "/api/v1/oauth/token", Body: exchangeBody, Header: wrongSecret, Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/oauth/token", Body: exchangeBody, Header: basicHeader, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+					var tokens models.OAuthTokenResponse
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tokens))
+					assert.NotEmpty(t, tokens.IDToken)
+					accessToken = tokens.AccessToken
+				}},
+			Case{Name: "code reused", Method: "POST", Path: "/api/v1/oauth/token", Body: exchangeBody, Header: basicHeader, Status: http.StatusBadRequest},
+		)
+		require.NotEmpty(t, accessToken)
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/oauth/userinfo", As: &Account{Token: accessToken}, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"preferred_username":"user"`)
+					assert.Contains(t, resp.Body.String(), `"email":"user@example.com"`)
+				}},
+			Case{Method: "GET", Path: "/api/v1/oauth/userinfo", Status: http.StatusUnauthorized},
+			Case{Method: "DELETE", Path: "/api/v1/admin/oauth-clients/" + client.Client.ID, As: s.Admin, Status: http.StatusOK},
+		)
+
+		degraded.Run(t,
+			Case{Method: "GET", Path: "/.well-known/openid-configuration", Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: authorizePath(authorize), As: degraded.User, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/oauth/authorize", As: degraded.User, Status: http.StatusServiceUnavailable,
+				Body: models.OAuthConsentRequest{OAuthAuthorizeRequest: authorize, Approve: true}},
+			Case{Method: "POST", Path: "/api/v1/oauth/token", Body: exchangeBody, Header: formHeader, Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: "/api/v1/oauth/userinfo", As: degraded.User, Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: "/api/v1/admin/oauth-clients", As: degraded.Admin, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/admin/oauth-clients", As: degraded.Admin, Body: clientRequest, Status: http.StatusServiceUnavailable},
+			Case{Method: "DELETE", Path: "/api/v1/admin/oauth-clients/" + missingID, As: degraded.Admin, Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("saml", func(t *testing.T) {
+		const prefix = "/api/v1/auth/saml/"
+		form := func(values url.Values) ([]byte, http.Header) {
+			return []byte(values.Encode()), http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+		}
+		// login 发起登录，从重定向地址中的 SAMLRequest 取出 AuthnRequest ID
+		login := func(tenant string) string {
+			var requestID string
+			s.Run(t, Case{Method: "GET", Path: prefix + tenant + "/login", Status: http.StatusFound,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					location, err := url.Parse(resp.Header().Get("Location"))
+					require.NoError(t, err)
+					assert.Equal(t, "idp.example.com", location.Host)
+					compressed, err := base64.StdEncoding.DecodeString(location.Query().Get("SAMLRequest"))
+					require.NoError(t, err)
+					request, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+					require.NoError(t, err)
+					match := regexp.MustCompile(` ID="([^"]+)"`).FindSubmatch(request)
+					require.NotNil(t, match)
+					requestID = string(match[1])
+				}})
+			require.NotEmpty(t, requestID)
+			return requestID
+		}
+		// respond 签发对应请求的响应，返回断言消费服务的表单
+		respond := func(tenant, requestID, email string) url.Values {
+			response, err := s.IdP.Sign(samltest.Response{
+				InResponseTo: requestID,
+				Audience:     "http://localhost" + prefix + tenant + "/metadata",
+				ACSURL:       "http://localhost" + prefix + tenant + "/acs",
+				NameID:       email,
+				Attributes:   map[string][]string{"email": {email}, "firstName": {"Carol"}},
+				IssueInstant: time.Now(),
+			})
+			require.NoError(t, err)
+			return url.Values{"SAMLResponse": {response}, "RelayState": {"/"}}
+		}
+
+		accepted, formHeader := form(respond("acme", login("acme"), "carol@acme.example.com"))
+		notProvisioned, _ := form(respond("globex", login("globex"), "dave@globex.example.com"))
+		conflict, _ := form(respond("acme", login("acme"), "user@acme.example.com"))
+		missing, _ := form(url.Values{"RelayState": {"/"}})
+		oversized, _ := form(url.Values{"SAMLResponse": {strings.Repeat("A", saml.MaxResponseSize*2)}})
+
+		s.Run(t,
+			Case{Method: "GET", Path: prefix + "acme/metadata", Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Header().Get("Content-Type"), "application/xml")
+					assert.Contains(t, resp.Body.String(), `Location="http://localhost/api/v1/auth/saml/acme/acs"`)
+				}},
+			Case{Method: "GET", Path: prefix + "initech/metadata", Status: http.StatusNotFound},
+			Case{Method: "GET", Path: prefix + "initech/login", Status: http.StatusNotFound},
+			Case{Method: "POST", Path: prefix + "acme/acs", Body: accepted, Header: formHeader, Status: http.StatusSeeOther,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					location := resp.Header().Get("Location")
+					require.True(t, strings.HasPrefix(location, "http://localhost/sso/complete#token="), location)
+					claims, err := s.JWT.ValidateToken(strings.TrimPrefix(location, "http://localhost/sso/complete#token="))
+					require.NoError(t, err)
+					assert.Equal(t, "carol@acme.example.com", claims.Email)
+				}},
+			Case{Name: "replayed response", Method: "POST", Path: prefix + "acme/acs", Body: accepted, Header: formHeader, Status: http.StatusUnauthorized},
+			Case{Name: "user not provisioned", Method: "POST", Path: prefix + "globex/acs", Body: notProvisioned, Header: formHeader, Status: http.StatusForbidden},
+			Case{Name: "username taken", Method: "POST", Path: prefix + "acme/acs", Body: conflict, Header: formHeader, Status: http.StatusConflict},
+			Case{Method: "POST", Path: prefix + "acme/acs", Body: missing, Header: formHeader, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: prefix + "initech/acs", Body: accepted, Header: formHeader, Status: http.StatusNotFound},
+			Case{Method: "POST", Path: prefix + "acme/acs", Body: oversized, Header: formHeader, Status: http.StatusRequestEntityTooLarge},
+		)
+
+		degraded.Run(t,
+			Case{Method: "GET", Path: prefix + "acme/metadata", Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: prefix + "acme/login", Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: prefix + "acme/acs", Body: accepted, Header: formHeader, Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("passwordless", func(t *testing.T) {
+		var device models.DeviceCodeResponse
+		s.Run(t, Case{Method: "POST", Path: "/api/v1/auth/device/code", Status: http.StatusOK,
+			Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+				var body struct {
+					Data models.DeviceCodeResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				device = body.Data
+				assert.Equal(t, "http://localhost/device", device.VerificationURI)
+			}})
+		require.NotEmpty(t, device.DeviceCode)
+
+		errorCode := func(want string) func(t testing.TB, resp *httptest.ResponseRecorder) {
+			return func(t testing.TB, resp *httptest.ResponseRecorder) {
+				assert.Contains(t, resp.Body.String(), `"error_code":"`+want+`"`)
+			}
+		}
+		poll := models.DeviceTokenRequest{DeviceCode: device.DeviceCode}
+		s.Run(t,
+			Case{Name: "authorization pending", Method: "POST", Path: "/api/v1/auth/device/token", Body: poll, Status: http.StatusBadRequest,
+				Check: errorCode(passwordless.ErrorAuthorizationPending)},
+			Case{Method: "POST", Path: "/api/v1/auth/device/verify", Body: models.DeviceVerifyRequest{UserCode: device.UserCode, Approve: true}, Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/auth/device/verify", As: s.User, Body: models.DeviceVerifyRequest{UserCode: device.UserCode, Approve: true}, Status: http.StatusOK},
+			Case{Name: "user code reused", Method: "POST", Path: "/api/v1/auth/device/verify", As: s.User, Body: models.DeviceVerifyRequest{UserCode: device.UserCode, Approve: true}, Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/auth/device/token", Body: poll, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"username":"user"`)
+				}},
+			Case{Name: "device code reused", Method: "POST", Path: "/api/v1/auth/device/token", Body: poll, Status: http.StatusBadRequest,
+				Check: errorCode(passwordless.ErrorExpiredToken)},
+		)
+
+		var token string
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link", Body: models.MagicLinkRequest{Email: "user@example.com"}, Status: http.StatusAccepted,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					sent := s.Events(passwordless.EventMagicLinkRequested)
+					require.NotEmpty(t, sent)
+					var payload passwordless.MagicLinkRequestedEvent
+					require.NoError(t, sent[len(sent)-1].Decode(&payload))
+					assert.Equal(t, "http://localhost/login/magic?token="+payload.Token, payload.Link)
+					token = payload.Token
+				}},
+			Case{Name: "unknown email", Method: "POST", Path: "/api/v1/auth/magic-link", Body: models.MagicLinkRequest{Email: "nobody@example.com"}, Status: http.StatusAccepted},
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link", Body: models.MagicLinkRequest{Email: "not an email"}, Status: http.StatusBadRequest},
+		)
+		require.NotEmpty(t, token)
+
+		s.Run(t,
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link/verify", Body: models.MagicLinkVerifyRequest{Token: token}, Status: http.StatusOK},
+			Case{Name: "link reused", Method: "POST", Path: "/api/v1/auth/magic-link/verify", Body: models.MagicLinkVerifyRequest{Token: token}, Status: http.StatusUnauthorized},
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link/verify", Body: []byte(`{}`), Status: http.StatusBadRequest},
+		)
+
+		degraded.Run(t,
+			Case{Method: "POST", Path: "/api/v1/auth/device/code", Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/auth/device/token", Body: poll, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/auth/device/verify", As: degraded.User, Body: models.DeviceVerifyRequest{UserCode: device.UserCode, Approve: true}, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link", Body: models.MagicLinkRequest{Email: "user@example.com"}, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/auth/magic-link/verify", Body: models.MagicLinkVerifyRequest{Token: token}, Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		metered := s.CreateAccount(t, "metered", false)
+		s.Run(t, Case{Method: "GET", Path: "/api/v1/users/me", As: metered, Status: http.StatusOK,
+			Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, "99999", resp.Header().Get("X-Quota-Remaining"))
+			}})
+
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/users/me/quota", Status: http.StatusUnauthorized},
+			Case{Method: "GET", Path: "/api/v1/users/me/quota", As: metered, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data []quota.Usage `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					require.Len(t, body.Data, 1)
+					assert.Equal(t, quota.APICalls, body.Data[0].Quota)
+					assert.Equal(t, int64(1), body.Data[0].Used, "查询用量不消耗配额")
+					assert.Empty(t, resp.Header().Get("X-Quota-Remaining"))
+				}},
+		)
+
+		degraded.Run(t,
+			Case{Method: "GET", Path: "/api/v1/users/me/quota", As: degraded.User, Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("billing", func(t *testing.T) {
+		subscriber := s.CreateAccount(t, "subscriber", false)
+		now := time.Now()
+		event := func(id, price string) []byte {
+			payload, err := json.Marshal(map[string]any{
+				"id":      id,
+				"type":    stripe.EventSubscriptionCreated,
+				"created": now.Unix(),
+				"data": map[string]any{"object": map[string]any{
+					"id":       "sub_" + id,
+					"customer": "cus_subscriber",
+					"status":   models.SubscriptionStatusActive,
+					"metadata": map[string]string{"user_id": subscriber.User.ID},
+					"items":    map[string]any{"data": []any{map[string]any{"price": map[string]any{"id": price}}}},
+				}},
+			})
+			require.NoError(t, err)
+			return payload
+		}
+		signed := func(payload []byte, secret string) http.Header {
+			return http.Header{stripe.SignatureHeaderName: {stripe.SignatureHeader(payload, secret, now)}}
+		}
+		planOf := func(want string) func(t testing.TB, resp *httptest.ResponseRecorder) {
+			return func(t testing.TB, resp *httptest.ResponseRecorder) {
+				var body struct {
+					Data models.SubscriptionResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.Equal(t, want, body.Data.Plan.ID)
+			}
+		}
+
+		created := event("evt_created", "price_pro")
+		unknown := event("evt_unknown", "price_unknown")
+		s.Run(t,
+			Case{Method: "GET", Path: "/api/v1/billing/plans", Status: http.StatusOK},
+			Case{Method: "GET", Path: "/api/v1/users/me/subscription", Status: http.StatusUnauthorized},
+			Case{Name: "before subscribing", Method: "GET", Path: "/api/v1/users/me/subscription", As: subscriber, Status: http.StatusOK,
+				Check: planOf("free")},
+			Case{Name: "bad signature", Method: "POST", Path: "/api/v1/billing/webhooks/stripe", Body: created, Header: signed(created, "whsec_other"), Status: http.StatusBadRequest},
+			Case{Name: "unknown price", Method: "POST", Path: "/api/v1/billing/webhooks/stripe", Body: unknown, Header: signed(unknown, StripeWebhookSecret), Status: http.StatusBadRequest},
+			Case{Method: "POST", Path: "/api/v1/billing/webhooks/stripe", Body: created, Header: signed(created, StripeWebhookSecret), Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"outcome":"applied"`)
+				}},
+			Case{Name: "after subscribing", Method: "GET", Path: "/api/v1/users/me/subscription", As: subscriber, Status: http.StatusOK,
+				Check: planOf("pro")},
+		)
+
+		degraded.Run(t,
+			Case{Method: "GET", Path: "/api/v1/billing/plans", Status: http.StatusServiceUnavailable},
+			Case{Method: "GET", Path: "/api/v1/users/me/subscription", As: degraded.User, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: "/api/v1/billing/webhooks/stripe", Body: created, Header: signed(created, StripeWebhookSecret), Status: http.StatusServiceUnavailable},
+		)
+	})
+
+	t.Run("maintenance", func(t *testing.T) {
+		const path = "/api/v1/admin/maintenance"
+		var cases []Case
+		for _, method := range []string{"GET", "PUT", "DELETE"} {
+			cases = append(cases, adminOnly(s, method, path)...)
+			degraded.Run(t, Case{Method: method, Path: path, As: degraded.Admin, Body: models.EnableMaintenanceRequest{}, Status: http.StatusServiceUnavailable})
+		}
+		usersDuring := func(header http.Header, want int) func(t testing.TB, resp *httptest.ResponseRecorder) {
+			return func(t testing.TB, resp *httptest.ResponseRecorder) {
+				users, _ := s.Do(t, Case{Method: "GET", Path: "/api/v1/users/me", As: s.User, Header: header})
+				assert.Equal(t, want, users.Code, users.Body.String())
+			}
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"enabled":false`)
+				}},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.EnableMaintenanceRequest{DurationSeconds: -1}},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusOK,
+				Body:  models.EnableMaintenanceRequest{Message: "数据库升级", DurationSeconds: 1800},
+				Check: usersDuring(nil, http.StatusServiceUnavailable)},
+			Case{Name: "bypass token", Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: usersDuring(http.Header{middleware.MaintenanceBypassHeader: {MaintenanceBypassToken}}, http.StatusOK)},
+			Case{Name: "health countdown", Method: "GET", Path: "/readyz", Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var report health.Report
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+					require.NotNil(t, report.Maintenance)
+					assert.Equal(t, "数据库升级", report.Maintenance.Message)
+					assert.InDelta(t, 1800, report.Maintenance.RemainingSeconds, 5)
+				}},
+			Case{Method: "DELETE", Path: path, As: s.Admin, Status: http.StatusConflict,
+				Setup: func(t testing.TB, s *Server) {
+					s.Maintenance.SetForced(&maintenance.State{Message: "迁移中"})
+					t.Cleanup(func() { s.Maintenance.SetForced(nil) })
+				}},
+			Case{Method: "DELETE", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: usersDuring(nil, http.StatusOK)},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		const path = "/api/v1/admin/rate-limits"
+		var cases []Case
+		for _, method := range []string{"GET", "PUT"} {
+			cases = append(cases, adminOnly(s, method, path)...)
+			degraded.Run(t, Case{Method: method, Path: path, As: degraded.Admin, Body: models.UpdateRateLimitsRequest{}, Status: http.StatusServiceUnavailable})
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"limits":{"anonymous":100,"authenticated":200}`)
+				}},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.UpdateRateLimitsRequest{Mode: "block"}},
+			Case{Name: "invalid window", Method: "PUT", Path: path, As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.UpdateRateLimitsRequest{Window: "soon"}},
+			Case{Method: "PUT", Path: path, As: s.Admin, Status: http.StatusOK,
+				Body: models.UpdateRateLimitsRequest{
+					Anonymous: 30,
+					Window:    "30s",
+					Mode:      "warn",
+					Routes:    map[string]models.RateLimitRouteLimit{"/api/v1/users/search": {Anonymous: 5, Authenticated: 10}},
+				},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					state := s.RateLimiter.State()
+					assert.Equal(t, 30, state.Limits.Anonymous)
+					assert.Equal(t, 200, state.Limits.Authenticated)
+					assert.Equal(t, "warn", state.Mode)
+					assert.Equal(t, s.Admin.User.ID, state.Overrides.UpdatedBy)
+					assert.Contains(t, resp.Body.String(), `"/api/v1/users/search":{"anonymous":5,"authenticated":10}`)
+				}},
+			Case{Name: "reset", Method: "PUT", Path: path, As: s.Admin, Status: http.StatusOK,
+				Body: models.UpdateRateLimitsRequest{},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, 100, s.RateLimiter.State().Limits.Anonymous)
+				}},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		const path = "/api/v1/admin/retention"
+		cases := append(adminOnly(s, "GET", path), adminOnly(s, "POST", path+"/run")...)
+		degraded.Run(t,
+			Case{Method: "GET", Path: path, As: degraded.Admin, Status: http.StatusServiceUnavailable},
+			Case{Method: "POST", Path: path + "/run", As: degraded.Admin, Status: http.StatusServiceUnavailable},
+		)
+		cases = append(cases,
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"policies":[{"table":"users","retention":"720h0m0s"}]`)
+				}},
+			Case{Method: "POST", Path: path + "/run?dry_run=maybe", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Name: "默认试运行", Method: "POST", Path: path + "/run", As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"dry_run":true`)
+					assert.Contains(t, resp.Body.String(), `"actor_id":"`+s.Admin.User.ID+`"`)
+				}},
+			Case{Method: "POST", Path: path + "/run?dry_run=false", As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"dry_run":false`)
+					assert.Contains(t, resp.Body.String(), `"table":"users"`)
+				}},
+		)
+		s.Run(t, cases...)
+	})
+
+	t.Run("backups", func(t *testing.T) {
+		const path = "/api/v1/admin/backups"
+		var cases []Case
+		for _, route := range []struct{ method, path string }{
+			{"GET", path}, {"POST", path}, {"GET", path + "/" + missingID}, {"GET", path + "/" + missingID + "/download"},
+			{"POST", path + "/" + missingID + "/restore"}, {"GET", "/api/v1/admin/restore"},
+		} {
+			cases = append(cases, adminOnly(s, route.method, route.path)...)
+			degraded.Run(t, Case{Method: route.method, Path: route.path, As: degraded.Admin, Status: http.StatusServiceUnavailable,
+				Body: models.RestoreBackupRequest{Confirm: missingID}})
+		}
+
+		var backupID string
+		cases = append(cases,
+			Case{Method: "POST", Path: path, As: s.Admin, Status: http.StatusAccepted,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					var body struct {
+						Data models.Backup `json:"data"`
+					}
+					require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+					assert.Equal(t, models.BackupTriggerManual, body.Data.Trigger)
+					backupID = body.Data.ID
+				}},
+			Case{Method: "GET", Path: path + "?limit=0", As: s.Admin, Status: http.StatusBadRequest},
+			Case{Method: "GET", Path: path + "/" + missingID, As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "GET", Path: path + "/not-a-uuid", As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "GET", Path: path + "/" + missingID + "/download", As: s.Admin, Status: http.StatusNotFound},
+			Case{Method: "POST", Path: path + "/" + missingID + "/restore", As: s.Admin, Status: http.StatusNotFound,
+				Body: models.RestoreBackupRequest{Confirm: missingID}},
+			Case{Method: "GET", Path: "/api/v1/admin/restore", As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"state":"idle"`)
+				}},
+		)
+		s.Run(t, cases...)
+		require.NotEmpty(t, backupID)
+
+		var backup *models.Backup
+		require.Eventually(t, func() bool {
+			var err error
+			backup, err = s.Backups.Get(context.Background(), backupID)
+			return err == nil && backup.Finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, models.BackupStatusCompleted, backup.Status, backup.Error)
+
+		failed := &models.Backup{Trigger: models.BackupTriggerScheduled, Status: models.BackupStatusFailed, Error: "pg_dump failed"}
+		require.NoError(t, s.BackupRepository.Create(context.Background(), failed))
+
+		s.Run(t,
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"id":"`+backupID+`"`)
+					assert.Contains(t, resp.Body.String(), `"total":2`)
+					assert.NotContains(t, resp.Body.String(), "object_key")
+				}},
+			Case{Method: "GET", Path: path + "/" + backupID, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"status":"completed"`)
+				}},
+			Case{Method: "GET", Path: path + "/" + backupID + "/download", As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Header().Get("Content-Disposition"), "attachment")
+					assert.Equal(t, "private, no-store", resp.Header().Get("Cache-Control"))
+					assert.Equal(t, BackupContent, resp.Body.String(), "下载的是解密后的备份")
+				}},
+			Case{Name: "未完成的备份不能下载", Method: "GET", Path: path + "/" + failed.ID + "/download", As: s.Admin, Status: http.StatusNotFound},
+			Case{Name: "确认的ID不一致", Method: "POST", Path: path + "/" + backupID + "/restore", As: s.Admin, Status: http.StatusBadRequest,
+				Body: models.RestoreBackupRequest{Confirm: failed.ID}},
+			Case{Method: "POST", Path: path + "/" + failed.ID + "/restore", As: s.Admin, Status: http.StatusConflict,
+				Body: models.RestoreBackupRequest{Confirm: failed.ID}},
+			Case{Method: "POST", Path: path + "/" + backupID + "/restore", As: s.Admin, Status: http.StatusAccepted,
+				Body: models.RestoreBackupRequest{Confirm: backupID},
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"state":"running"`)
+					require.Eventually(t, func() bool {
+						return s.Backups.RestoreStatus().State == models.RestoreStateCompleted
+					}, 5*time.Second, 10*time.Millisecond)
+				}},
+			Case{Method: "GET", Path: "/api/v1/admin/restore", As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"state":"completed"`)
+					assert.Contains(t, resp.Body.String(), `"backup_id":"`+backupID+`"`)
+				}},
+		)
+	})
+
+	// 排空只能执行一次，放在最后避免影响其他用例
+	t.Run("drain", func(t *testing.T) {
+		const path = "/api/v1/admin/drain"
+		var cases []Case
+		for _, method := range []string{"GET", "POST"} {
+			cases = append(cases, adminOnly(s, method, path)...)
+			degraded.Run(t, Case{Method: method, Path: path, As: degraded.Admin, Status: http.StatusServiceUnavailable})
+		}
+		cases = append(cases,
+			Case{Method: "GET", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"state":"idle"`)
+					assert.Contains(t, resp.Body.String(), `"in_flight":0`)
+				}},
+			Case{Method: "POST", Path: path, As: s.Admin, Status: http.StatusAccepted,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					require.Eventually(t, func() bool { return s.Drainer.Status().State == drain.StateDrained }, 2*time.Second, time.Millisecond)
+					ready, _ := s.Do(t, Case{Method: "GET", Path: "/readyz"})
+					assert.Equal(t, http.StatusServiceUnavailable, ready.Code, "排空后就绪检查失败")
+				}},
+			Case{Method: "POST", Path: path, As: s.Admin, Status: http.StatusOK,
+				Check: func(t testing.TB, resp *httptest.ResponseRecorder) {
+					assert.Contains(t, resp.Body.String(), `"state":"drained"`)
+				}},
+		)
+		s.Run(t, cases...)
+	})
+
+	AssertCoverage(t, skips, s, degraded, broken)
+}
+
+// errorRecorder 记录断言失败而不终止测试
+type errorRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *errorRecorder) Helper() {}
+
+func (r *errorRecorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestValidateCatchesDrift 测试响应与文档不一致时报告错误
+func TestValidateCatchesDrift(t *testing.T) {
+	s := New(t)
+
+	resp := httptest.NewRecorder()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	resp.Body.WriteString(`{"success":true,"message":"ok","timestamp":"2024-01-01T00:00:00Z","data":{"unexpected":1}}`)
+
+	recorder := &errorRecorder{TB: t}
+	s.Validate(recorder, "GET", "/api/v1/meta/errors", resp)
+	assert.NotEmpty(t, recorder.errors)
+
+	teapot := httptest.NewRecorder()
+	teapot.WriteHeader(http.StatusTeapot)
+	recorder = &errorRecorder{TB: t}
+	s.Validate(recorder, "GET", "/api/v1/meta/errors", teapot)
+	require.Len(t, recorder.errors, 1)
+	assert.Contains(t, recorder.errors[0], "418")
+}
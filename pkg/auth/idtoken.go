@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenClaims OpenID Connect ID 令牌声明，sub 为用户ID，aud 为客户端ID
+type IDTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`              // 授权请求中的随机数，客户端用于防止重放
+	AuthTime          int64  `json:"auth_time,omitempty"`          // 用户同意授权的时间（Unix 秒）
+	AuthorizedParty   string `json:"azp,omitempty"`                // 令牌签发给的客户端ID
+	Name              string `json:"name,omitempty"`               // 全名，需要 profile 范围
+	GivenName         string `json:"given_name,omitempty"`         // 名，需要 profile 范围
+	FamilyName        string `json:"family_name,omitempty"`        // 姓，需要 profile 范围
+	PreferredUsername string `json:"preferred_username,omitempty"` // 用户名，需要 profile 范围
+	Email             string `json:"email,omitempty"`              // 邮箱地址，需要 email 范围
+	jwt.RegisteredClaims
+}
+
+// GenerateIDToken 使用当前活动密钥签发 ID 令牌，claims 须包含 iss、sub 和 aud，
+// 签发时间和过期时间由 ttl 决定，ttl <= 0 时使用普通令牌的有效期
+func (j *JWTManager) GenerateIDToken(claims *IDTokenClaims, ttl time.Duration) (string, error) {
+	if claims.Issuer == "" || claims.Subject == "" || len(claims.Audience) == 0 {
+		return "", errors.New("id token requires issuer, subject and audience")
+	}
+	if ttl <= 0 {
+		ttl = j.expiresIn
+	}
+
+	now := time.Now()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	return j.sign(claims)
+}
+
+// ValidateIDToken 验证 ID 令牌的签名、有效期、签发者和受众
+func (j *JWTManager) ValidateIDToken(tokenString, issuer, audience string) (*IDTokenClaims, error) {
+	if j.err != nil {
+		return nil, j.err
+	}
+	var candidates []VerificationKey
+	token, err := jwt.ParseWithClaims(tokenString, &IDTokenClaims{}, j.keyFunc(&candidates),
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256, AlgorithmES256}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*IDTokenClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid id token")
+	}
+	return claims, nil
+}
+
+// SigningAlgorithm 返回当前活动密钥的签名算法，发现文档据此声明 ID 令牌的签名算法
+func (j *JWTManager) SigningAlgorithm() (string, error) {
+	if j.err != nil {
+		return "", j.err
+	}
+	key, err := j.keys.Active()
+	if err != nil {
+		return "", err
+	}
+	return key.Algorithm, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTManager_IDToken(t *testing.T) {
+	keys, err := NewKeySet(newTestRSAKey(t, "rsa-1"))
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+	alg, err := jwtManager.SigningAlgorithm()
+	if err != nil || alg != AlgorithmRS256 {
+		t.Fatalf("Expected signing algorithm RS256, got %q (%v)", alg, err)
+	}
+
+	token, err := jwtManager.GenerateIDToken(&IDTokenClaims{
+		Nonce: "n-0S6",
+		Email: "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   "https://auth.example.com",
+			Subject:  "user123",
+			Audience: jwt.ClaimStrings{"client1"},
+		},
+	}, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate id token: %v", err)
+	}
+
+	claims, err := jwtManager.ValidateIDToken(token, "https://auth.example.com", "client1")
+	if err != nil {
+		t.Fatalf("Failed to validate id token: %v", err)
+	}
+	if claims.Subject != "user123" || claims.Nonce != "n-0S6" || claims.Email != "test@example.com" {
+		t.Errorf("Unexpected id token claims: %+v", claims)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 5*time.Minute {
+		t.Errorf("Expected id token to expire within 5m, got %v", ttl)
+	}
+
+	if _, err := jwtManager.ValidateIDToken(token, "https://auth.example.com", "client2"); err == nil {
+		t.Error("Expected error for a different audience")
+	}
+	if _, err := jwtManager.ValidateIDToken(token, "https://evil.example.com", "client1"); err == nil {
+		t.Error("Expected error for a different issuer")
+	}
+
+	// ID 令牌没有 user_id 声明，不能用作访问令牌
+	if _, err := jwtManager.ValidateToken(token); err == nil {
+		t.Error("Expected id token to be rejected as an access token")
+	}
+
+	if _, err := jwtManager.GenerateIDToken(&IDTokenClaims{}, time.Minute); err == nil {
+		t.Error("Expected error when issuer, subject and audience are missing")
+	}
+}
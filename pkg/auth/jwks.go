@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK JSON Web Key（RFC 7517），仅包含公钥参数
+type JWK struct {
+	Kty string `json:"kty"`           // 密钥类型：RSA 或 EC
+	Use string `json:"use"`           // 用途：sig
+	Kid string `json:"kid"`           // 密钥ID
+	Alg string `json:"alg"`           // 签名算法
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 公开指数
+	Crv string `json:"crv,omitempty"` // EC 曲线
+	X   string `json:"x,omitempty"`   // EC 公钥 X 坐标
+	Y   string `json:"y,omitempty"`   // EC 公钥 Y 坐标
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回所有非对称密钥的公钥集合，HMAC 密钥不会被公开
+// 热更新后仍在宽限期内的原活动密钥在其密钥ID未被新密钥占用时一并公开
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range ks.IDs() {
+		key, ok := ks.Get(id)
+		if !ok {
+			continue
+		}
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	for _, key := range ks.retiredKeys() {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+// JWK 返回密钥的公钥表示，HMAC 密钥返回 false
+func (k *SigningKey) JWK() (JWK, bool) {
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: k.ID,
+			Alg: k.Algorithm,
+			N:   encodeBase64URL(pub.N.Bytes()),
+			E:   encodeBase64URL(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Use: "sig",
+			Kid: k.ID,
+			Alg: k.Algorithm,
+			Crv: pub.Curve.Params().Name,
+			X:   encodeBase64URL(pub.X.FillBytes(make([]byte, size))),
+			Y:   encodeBase64URL(pub.Y.FillBytes(make([]byte, size))),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// encodeBase64URL 无填充的 base64url 编码
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
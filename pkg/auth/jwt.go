@@ -2,8 +2,11 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,52 +20,227 @@ type BlacklistChecker interface {
 
 // Claims JWT声明结构
 type Claims struct {
-	UserID   string `json:"user_id"`   // 用户ID
-	Username string `json:"username"`  // 用户名
-	Email    string `json:"email"`     // 邮箱地址
-	jwt.RegisteredClaims                // JWT标准声明
+	UserID               string `json:"user_id"`              // 用户ID
+	Username             string `json:"username"`             // 用户名
+	Email                string `json:"email"`                // 邮箱地址
+	TenantID             string `json:"tenant_id,omitempty"`  // 用户所属租户ID，未启用多租户时为空
+	Act                  *Actor `json:"act,omitempty"`        // 代表该用户执行操作的主体，模拟登录时为管理员
+	Banner               string `json:"imp_banner,omitempty"` // 模拟登录横幅，客户端据此提示当前处于模拟会话
+	TokenType            string `json:"token_type,omitempty"` // 令牌类型，刷新令牌为 refresh，访问令牌为空
+	IssuedAtMillis       int64  `json:"iat_ms,omitempty"`     // 签发时间（Unix 毫秒），iat 只精确到秒
+	jwt.RegisteredClaims        // JWT标准声明
 }
 
+// TokenTypeRefresh 刷新令牌的类型声明，刷新令牌只能用于换取新的令牌，不能用作访问令牌
+const TokenTypeRefresh = "refresh"
+
+// DefaultRefreshExpiresIn 刷新令牌的默认有效期
+const DefaultRefreshExpiresIn = 7 * 24 * time.Hour
+
+// ErrWrongTokenType 令牌类型与用途不符，如用刷新令牌访问接口
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// ErrInvalidSecret 创建管理器时的 HMAC 密钥无效（如为空）
+var ErrInvalidSecret = errors.New("invalid jwt secret")
+
+// Actor 代理主体（RFC 8693 act 声明）
+type Actor struct {
+	Subject string `json:"sub"` // 实际执行操作的用户ID
+}
+
+// IssuedAtMilli 返回令牌的签发时间（Unix 毫秒），没有 iat_ms 的旧令牌按所在秒的开始计算
+func (c *Claims) IssuedAtMilli() int64 {
+	if c.IssuedAtMillis > 0 {
+		return c.IssuedAtMillis
+	}
+	if c.IssuedAt == nil {
+		return 0
+	}
+	return c.IssuedAt.Unix() * 1000
+}
+
+// IsImpersonated 判断令牌是否为模拟登录签发
+func (c *Claims) IsImpersonated() bool {
+	return c.Act != nil && c.Act.Subject != ""
+}
+
+// ImpersonationBanner 返回模拟登录横幅的文本，只包含 ASCII 字符以便写入响应头
+func ImpersonationBanner(username, actorID string) string {
+	return fmt.Sprintf("Impersonating %s on behalf of admin %s", username, actorID)
+}
+
+// DefaultKeyID 使用单个 HMAC 密钥创建管理器时的密钥ID
+const DefaultKeyID = "default"
+
 // JWTManager JWT管理器
 type JWTManager struct {
-	secretKey        string           // 密钥
+	keys             *KeySet          // 签名密钥集合
 	expiresIn        time.Duration    // 过期时间
+	refreshExpiresIn time.Duration    // 刷新令牌过期时间
 	blacklistChecker BlacklistChecker // 黑名单检查器
+	observer         VerificationObserver
+	err              error // 密钥无效时的错误，签发和验证令牌时返回
 }
 
+// VerificationObserver 令牌验证通过后调用，参数为验证所用密钥的ID和状态（active、previous 或 retired）
+type VerificationObserver func(kid, state string)
+
 // NewJWTManager 创建新的JWT管理器
 func NewJWTManager(secretKey string, expiresIn int) *JWTManager {
-	return &JWTManager{
-		secretKey:         secretKey,
-		expiresIn:         time.Duration(expiresIn) * time.Hour,
-		blacklistChecker:  nil,
-	}
+	return NewJWTManagerWithBlacklist(secretKey, expiresIn, nil)
 }
 
 // NewJWTManagerWithBlacklist 创建支持黑名单的新JWT管理器
+// 密钥为空时管理器的密钥集合为空，签发和验证令牌均返回 ErrInvalidSecret
 func NewJWTManagerWithBlacklist(secretKey string, expiresIn int, blacklistChecker BlacklistChecker) *JWTManager {
+	keys, err := NewKeySet(NewHMACKey(DefaultKeyID, secretKey))
+	if err != nil {
+		manager := NewJWTManagerWithKeys(&KeySet{keys: make(map[string]*SigningKey), now: time.Now}, expiresIn, blacklistChecker)
+		manager.err = fmt.Errorf("%w: %v", ErrInvalidSecret, err)
+		return manager
+	}
+	return NewJWTManagerWithKeys(keys, expiresIn, blacklistChecker)
+}
+
+// NewJWTManagerWithKeys 使用密钥集合创建JWT管理器，支持多密钥验证和非对称算法
+func NewJWTManagerWithKeys(keys *KeySet, expiresIn int, blacklistChecker BlacklistChecker) *JWTManager {
 	return &JWTManager{
-		secretKey:         secretKey,
-		expiresIn:         time.Duration(expiresIn) * time.Hour,
-		blacklistChecker:  blacklistChecker,
+		keys:             keys,
+		expiresIn:        time.Duration(expiresIn) * time.Hour,
+		refreshExpiresIn: DefaultRefreshExpiresIn,
+		blacklistChecker: blacklistChecker,
 	}
 }
 
+// Keys 返回签名密钥集合，可用于运行时轮换
+func (j *JWTManager) Keys() *KeySet {
+	return j.keys
+}
+
+// SetVerificationObserver 设置令牌验证通过后的回调，用于统计各密钥的使用情况；须在开始验证令牌前调用
+func (j *JWTManager) SetVerificationObserver(observer VerificationObserver) {
+	j.observer = observer
+}
+
+// ExpiresIn 返回令牌的有效期
+func (j *JWTManager) ExpiresIn() time.Duration {
+	return j.expiresIn
+}
+
+// SetRefreshExpiresIn 设置刷新令牌的有效期，小于等于 0 时使用默认值；须在开始签发令牌前调用
+func (j *JWTManager) SetRefreshExpiresIn(expiresIn time.Duration) {
+	if expiresIn <= 0 {
+		expiresIn = DefaultRefreshExpiresIn
+	}
+	j.refreshExpiresIn = expiresIn
+}
+
+// RefreshExpiresIn 返回刷新令牌的有效期
+func (j *JWTManager) RefreshExpiresIn() time.Duration {
+	return j.refreshExpiresIn
+}
+
+// JWKS 返回用于公开的公钥集合
+func (j *JWTManager) JWKS() JWKS {
+	return j.keys.JWKS()
+}
+
 // GenerateToken 生成JWT令牌
 func (j *JWTManager) GenerateToken(userID, username, email string) (string, error) {
+	return j.GenerateTenantToken(userID, username, email, "")
+}
+
+// GenerateTenantToken 生成带租户声明的JWT令牌，租户中间件据此拒绝在其他租户下使用该令牌
+func (j *JWTManager) GenerateTenantToken(userID, username, email, tenantID string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:         userID,
+		Username:       username,
+		Email:          email,
+		TenantID:       tenantID,
+		IssuedAtMillis: now.UnixMilli(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// GenerateImpersonationToken 为目标用户签发模拟登录令牌，actorID 记录在 act 声明中，
+// 同时写入模拟登录横幅声明；ttl 不超过普通令牌的有效期
+func (j *JWTManager) GenerateImpersonationToken(userID, username, email, actorID string, ttl time.Duration) (string, error) {
+	if actorID == "" {
+		return "", errors.New("impersonation requires an actor")
+	}
+	if ttl <= 0 || ttl > j.expiresIn {
+		ttl = j.expiresIn
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:         userID,
+		Username:       username,
+		Email:          email,
+		Act:            &Actor{Subject: actorID},
+		Banner:         ImpersonationBanner(username, actorID),
+		IssuedAtMillis: now.UnixMilli(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// GenerateRefreshToken 生成刷新令牌，用于在访问令牌过期后换取新的令牌
+// 每个刷新令牌带有随机的 jti，同一秒内签发的刷新令牌也互不相同，轮换后旧令牌可以单独吊销
+func (j *JWTManager) GenerateRefreshToken(userID, username, email, tenantID string) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	now := time.Now()
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
+		UserID:         userID,
+		Username:       username,
+		Email:          email,
+		TenantID:       tenantID,
+		TokenType:      TokenTypeRefresh,
+		IssuedAtMillis: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.refreshExpiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
+}
+
+// sign 使用当前活动密钥签名
+func (j *JWTManager) sign(claims jwt.Claims) (string, error) {
+	if j.err != nil {
+		return "", j.err
+	}
+	key, err := j.keys.Active()
+	if err != nil {
+		return "", err
+	}
+	signingKey, err := key.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(signingKey)
 }
 
 // ValidateToken 验证JWT令牌
@@ -70,8 +248,36 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return j.ValidateTokenWithContext(context.Background(), tokenString)
 }
 
-// ValidateTokenWithContext 验证JWT令牌，支持上下文和黑名单检查
+// ValidateTokenWithContext 验证JWT令牌，支持上下文和黑名单检查；刷新令牌不能用作访问令牌
 func (j *JWTManager) ValidateTokenWithContext(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := j.parse(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != "" {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken 验证刷新令牌，访问令牌不能用于换取新的令牌
+func (j *JWTManager) ValidateRefreshToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := j.parse(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// parse 检查黑名单并验证令牌签名和有效期
+func (j *JWTManager) parse(ctx context.Context, tokenString string) (*Claims, error) {
+	if j.err != nil {
+		return nil, j.err
+	}
+
 	// If blacklist checker is available, check if token is blacklisted
 	if j.blacklistChecker != nil {
 		blacklisted, err := j.blacklistChecker.IsBlacklisted(ctx, tokenString)
@@ -86,20 +292,74 @@ func (j *JWTManager) ValidateTokenWithContext(ctx context.Context, tokenString s
 	}
 
 	// Proceed with normal JWT validation
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(j.secretKey), nil
-	})
+	var candidates []VerificationKey
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keyFunc(&candidates),
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256, AlgorithmES256}))
 
 	if err != nil {
 		return nil, err
 	}
+	j.recordVerification(token, candidates)
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// ID 令牌与访问令牌使用同一组密钥签名，但没有 user_id 声明，不能用作访问令牌
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.UserID != "" {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// keyFunc 根据令牌头部的 kid 选择验证密钥，并要求算法与密钥一致以防止算法混淆攻击
+// 未携带 kid 的旧令牌依次尝试所有 HMAC 密钥；热更新后同一 kid 可能对应新旧两个密钥，依次尝试。
+// 选出的候选密钥写入 candidates，验证通过后据此确定所用的密钥
+func (j *JWTManager) keyFunc(candidates *[]VerificationKey) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" && token.Method.Alg() != AlgorithmHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		keys := j.keys.verificationKeys(kid)
+		if len(keys) == 0 {
+			if kid == "" {
+				return nil, ErrUnknownKeyID
+			}
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+		}
+
+		var set jwt.VerificationKeySet
+		for _, key := range keys {
+			if token.Method.Alg() == key.Key.Algorithm {
+				set.Keys = append(set.Keys, key.Key.verificationKey())
+				*candidates = append(*candidates, key)
+			}
+		}
+		switch len(set.Keys) {
+		case 0:
+			return nil, errors.New("unexpected signing method")
+		case 1:
+			return set.Keys[0], nil
+		default:
+			return set, nil
+		}
+	}
+}
+
+// recordVerification 通知验证所用的密钥；候选密钥有多个时重新验证签名确定是哪一个
+func (j *JWTManager) recordVerification(token *jwt.Token, candidates []VerificationKey) {
+	if j.observer == nil || len(candidates) == 0 {
+		return
+	}
+
+	used := candidates[0]
+	if len(candidates) > 1 {
+		signingString := token.Raw[:strings.LastIndex(token.Raw, ".")]
+		for _, candidate := range candidates {
+			if token.Method.Verify(signingString, token.Signature, candidate.Key.verificationKey()) == nil {
+				used = candidate
+				break
+			}
+		}
+	}
+	j.observer(used.Key.ID, used.State)
+}
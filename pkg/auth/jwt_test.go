@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -63,6 +64,96 @@ func TestJWTManager_WithoutBlacklist(t *testing.T) {
 	}
 }
 
+func TestJWTManager_GenerateImpersonationToken(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret-key", 24)
+
+	token, err := jwtManager.GenerateImpersonationToken("user123", "testuser", "test@example.com", "admin1", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate impersonation token: %v", err)
+	}
+
+	claims, err := jwtManager.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.UserID != "user123" {
+		t.Errorf("Expected UserID 'user123', got '%s'", claims.UserID)
+	}
+	if !claims.IsImpersonated() || claims.Act.Subject != "admin1" {
+		t.Errorf("Expected act subject 'admin1', got %+v", claims.Act)
+	}
+	if claims.Banner != "Impersonating testuser on behalf of admin admin1" {
+		t.Errorf("Unexpected impersonation banner '%s'", claims.Banner)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 15*time.Minute {
+		t.Errorf("Expected impersonation token to expire within 15m, got %v", ttl)
+	}
+
+	// 普通令牌不带 act 声明
+	token, err = jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err = jwtManager.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.IsImpersonated() {
+		t.Error("Expected regular token not to be impersonated")
+	}
+
+	if _, err := jwtManager.GenerateImpersonationToken("user123", "testuser", "test@example.com", "", time.Minute); err == nil {
+		t.Error("Expected error when actor is empty")
+	}
+}
+
+func TestJWTManager_RefreshToken(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret-key", 1)
+	jwtManager.SetRefreshExpiresIn(48 * time.Hour)
+
+	refreshToken, err := jwtManager.GenerateRefreshToken("user123", "testuser", "test@example.com", "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+
+	claims, err := jwtManager.ValidateRefreshToken(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("Failed to validate refresh token: %v", err)
+	}
+	if claims.UserID != "user123" || claims.TenantID != "tenant1" {
+		t.Errorf("Unexpected refresh token claims: %+v", claims)
+	}
+	if claims.ID == "" {
+		t.Error("Expected refresh token to carry a jti")
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl <= time.Hour || ttl > 48*time.Hour {
+		t.Errorf("Expected refresh token to expire in 48h, got %v", ttl)
+	}
+
+	// 刷新令牌不能用作访问令牌
+	if _, err := jwtManager.ValidateToken(refreshToken); err != ErrWrongTokenType {
+		t.Errorf("Expected ErrWrongTokenType for refresh token used as access token, got %v", err)
+	}
+
+	// 访问令牌不能用于换取新的令牌
+	accessToken, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := jwtManager.ValidateRefreshToken(context.Background(), accessToken); err != ErrWrongTokenType {
+		t.Errorf("Expected ErrWrongTokenType for access token used as refresh token, got %v", err)
+	}
+
+	// 同一秒内签发的刷新令牌互不相同
+	second, err := jwtManager.GenerateRefreshToken("user123", "testuser", "test@example.com", "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+	if second == refreshToken {
+		t.Error("Expected refresh tokens to be unique")
+	}
+}
+
 func TestJWTManager_WithBlacklist(t *testing.T) {
 	secretKey := "test-secret-key"
 	mockBlacklist := NewMockBlacklistChecker()
@@ -158,6 +249,23 @@ func TestJWTManager_InvalidToken(t *testing.T) {
 	}
 }
 
+func TestJWTManager_EmptySecret(t *testing.T) {
+	jwtManager := NewJWTManager("", 24)
+
+	if _, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com"); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Expected ErrInvalidSecret from GenerateToken, got %v", err)
+	}
+	if _, err := jwtManager.ValidateToken("invalid.token.here"); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Expected ErrInvalidSecret from ValidateToken, got %v", err)
+	}
+	if _, err := jwtManager.ValidateRefreshToken(context.Background(), "invalid.token.here"); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Expected ErrInvalidSecret from ValidateRefreshToken, got %v", err)
+	}
+	if keys := jwtManager.JWKS(); len(keys.Keys) != 0 {
+		t.Errorf("Expected empty JWKS, got %d keys", len(keys.Keys))
+	}
+}
+
 func TestJWTManager_ExpiredToken(t *testing.T) {
 	secretKey := "test-secret-key"
 	jwtManager := NewJWTManager(secretKey, 0) // 0 hours = immediate expiration
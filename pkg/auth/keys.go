@@ -0,0 +1,401 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256" // HMAC-SHA256，对称密钥
+	AlgorithmRS256 = "RS256" // RSA PKCS#1 v1.5 + SHA256
+	AlgorithmES256 = "ES256" // ECDSA P-256 + SHA256
+)
+
+// 验证令牌所用密钥的状态
+const (
+	KeyStateActive   = "active"   // 当前用于签发的密钥
+	KeyStatePrevious = "previous" // 配置的旧密钥
+	KeyStateRetired  = "retired"  // 热更新前的活动密钥，宽限期结束后失效
+)
+
+// 密钥相关错误
+var (
+	ErrUnknownKeyID       = errors.New("unknown signing key id")
+	ErrNoActiveKey        = errors.New("no active signing key")
+	ErrVerificationOnly   = errors.New("signing key is verification only")
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+)
+
+// SigningKey JWT签名密钥，仅有公钥时只能用于验证
+type SigningKey struct {
+	ID        string            // 密钥ID，写入令牌头部的 kid
+	Algorithm string            // 签名算法
+	secret    []byte            // HMAC 密钥
+	private   crypto.Signer     // 非对称私钥
+	public    crypto.PublicKey  // 非对称公钥
+	method    jwt.SigningMethod // 对应的签名方法
+}
+
+// NewHMACKey 创建 HS256 签名密钥
+func NewHMACKey(id, secret string) *SigningKey {
+	return &SigningKey{
+		ID:        id,
+		Algorithm: AlgorithmHS256,
+		secret:    []byte(secret),
+		method:    jwt.SigningMethodHS256,
+	}
+}
+
+// NewRSAKey 创建 RS256 签名密钥
+func NewRSAKey(id string, key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{
+		ID:        id,
+		Algorithm: AlgorithmRS256,
+		private:   key,
+		public:    &key.PublicKey,
+		method:    jwt.SigningMethodRS256,
+	}
+}
+
+// NewECDSAKey 创建 ES256 签名密钥，仅支持 P-256 曲线
+func NewECDSAKey(id string, key *ecdsa.PrivateKey) (*SigningKey, error) {
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: ES256 requires a P-256 key", ErrUnsupportedKeyType)
+	}
+	return &SigningKey{
+		ID:        id,
+		Algorithm: AlgorithmES256,
+		private:   key,
+		public:    &key.PublicKey,
+		method:    jwt.SigningMethodES256,
+	}, nil
+}
+
+// NewVerificationKey 创建仅用于验证的公钥，用于轮换后仍需验证旧令牌但已无私钥的场景
+func NewVerificationKey(id string, publicKey crypto.PublicKey) (*SigningKey, error) {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &SigningKey{ID: id, Algorithm: AlgorithmRS256, public: pub, method: jwt.SigningMethodRS256}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ES256 requires a P-256 key", ErrUnsupportedKeyType)
+		}
+		return &SigningKey{ID: id, Algorithm: AlgorithmES256, public: pub, method: jwt.SigningMethodES256}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, publicKey)
+	}
+}
+
+// ParsePrivateKeyPEM 解析 PEM 格式的私钥（PKCS#1、PKCS#8 或 SEC 1），根据密钥类型选择 RS256 或 ES256
+func ParsePrivateKeyPEM(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, k), nil
+	case *ecdsa.PrivateKey:
+		return NewECDSAKey(id, k)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+	}
+}
+
+// ParsePublicKeyPEM 解析 PEM 格式的公钥（PKIX 或 PKCS#1），返回仅用于验证的密钥
+func ParsePublicKeyPEM(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+
+	var (
+		key any
+		err error
+	)
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return NewVerificationKey(id, key)
+}
+
+// CanSign 密钥是否可用于签名
+func (k *SigningKey) CanSign() bool {
+	return len(k.secret) > 0 || k.private != nil
+}
+
+// signingKey 返回签名所需的密钥
+func (k *SigningKey) signingKey() (any, error) {
+	if len(k.secret) > 0 {
+		return k.secret, nil
+	}
+	if k.private != nil {
+		return k.private, nil
+	}
+	return nil, ErrVerificationOnly
+}
+
+// verificationKey 返回验证所需的密钥
+func (k *SigningKey) verificationKey() any {
+	if len(k.secret) > 0 {
+		return k.secret
+	}
+	return k.public
+}
+
+// sameMaterial 两个密钥的算法和密钥内容是否相同
+func (k *SigningKey) sameMaterial(other *SigningKey) bool {
+	if k.Algorithm != other.Algorithm {
+		return false
+	}
+	if len(k.secret) > 0 || len(other.secret) > 0 {
+		return subtle.ConstantTimeCompare(k.secret, other.secret) == 1
+	}
+	public, ok := k.public.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public.Equal(other.public)
+}
+
+// KeySet 签名密钥集合，包含一个用于签发的活动密钥和若干仅用于验证的旧密钥
+// 轮换时添加新密钥并设为活动密钥，旧密钥保留到其签发的令牌全部过期
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*SigningKey
+	active  string
+	retired []retiredKey
+	now     func() time.Time
+}
+
+// retiredKey 热更新时被替换的活动密钥，宽限期内仍用于验证
+type retiredKey struct {
+	key   *SigningKey
+	until time.Time
+}
+
+// VerificationKey 验证令牌的候选密钥
+type VerificationKey struct {
+	Key   *SigningKey
+	State string // active、previous 或 retired
+}
+
+// NewKeySet 创建密钥集合，第一个密钥作为活动密钥
+func NewKeySet(active *SigningKey, others ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey), now: time.Now}
+	for _, key := range append([]*SigningKey{active}, others...) {
+		if err := ks.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := ks.SetActive(active.ID); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Add 添加密钥，同 ID 的密钥将被替换
+func (ks *KeySet) Add(key *SigningKey) error {
+	if key == nil || key.ID == "" {
+		return errors.New("signing key id is required")
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+	return nil
+}
+
+// SetActive 设置用于签发新令牌的活动密钥
+func (ks *KeySet) SetActive(id string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKeyID, id)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("%w: %s", ErrVerificationOnly, id)
+	}
+	ks.active = id
+	return nil
+}
+
+// Rotate 添加新密钥并设为活动密钥，旧密钥继续用于验证
+func (ks *KeySet) Rotate(key *SigningKey) error {
+	if err := ks.Add(key); err != nil {
+		return err
+	}
+	return ks.SetActive(key.ID)
+}
+
+// Remove 移除不再需要的旧密钥，不能移除活动密钥
+func (ks *KeySet) Remove(id string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if id == ks.active {
+		return errors.New("cannot remove the active signing key")
+	}
+	delete(ks.keys, id)
+	return nil
+}
+
+// Reload 用新的活动密钥和旧密钥替换整个集合，用于配置热更新
+// 原活动密钥的内容与新活动密钥不同时，在 grace 内仍可验证它签发的令牌（即使密钥ID相同）；grace <= 0 时立即失效
+func (ks *KeySet) Reload(active *SigningKey, previous []*SigningKey, grace time.Duration) error {
+	if active == nil || active.ID == "" {
+		return errors.New("signing key id is required")
+	}
+	if !active.CanSign() {
+		return fmt.Errorf("%w: %s", ErrVerificationOnly, active.ID)
+	}
+
+	keys := make(map[string]*SigningKey, len(previous)+1)
+	for _, key := range previous {
+		if key == nil || key.ID == "" {
+			return errors.New("signing key id is required")
+		}
+		keys[key.ID] = key
+	}
+	keys[active.ID] = active
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := ks.now()
+	retired := ks.liveRetired(now)
+	if old, ok := ks.keys[ks.active]; ok && grace > 0 && !old.sameMaterial(active) {
+		retired = append(retired, retiredKey{key: old, until: now.Add(grace)})
+	}
+	ks.keys, ks.active, ks.retired = keys, active.ID, retired
+	return nil
+}
+
+// liveRetired 返回仍在宽限期内的已替换密钥，调用方须持有锁
+func (ks *KeySet) liveRetired(now time.Time) []retiredKey {
+	var live []retiredKey
+	for _, retired := range ks.retired {
+		if now.Before(retired.until) {
+			live = append(live, retired)
+		}
+	}
+	return live
+}
+
+// retiredKeys 返回宽限期内、密钥ID未被当前密钥占用的已替换密钥
+func (ks *KeySet) retiredKeys() []*SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var keys []*SigningKey
+	for _, retired := range ks.liveRetired(ks.now()) {
+		if _, ok := ks.keys[retired.key.ID]; !ok {
+			keys = append(keys, retired.key)
+		}
+	}
+	return keys
+}
+
+// Active 返回活动密钥
+func (ks *KeySet) Active() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.active]
+	if !ok {
+		return nil, ErrNoActiveKey
+	}
+	return key, nil
+}
+
+// Get 根据 ID 查找密钥
+func (ks *KeySet) Get(id string) (*SigningKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[id]
+	return key, ok
+}
+
+// IDs 返回所有密钥 ID（按字母排序）
+func (ks *KeySet) IDs() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// verificationKeys 返回验证令牌的候选密钥：kid 为空时返回所有 HMAC 密钥，用于验证未携带 kid 的旧令牌
+// 活动密钥优先，宽限期内的已替换密钥排在最后
+func (ks *KeySet) verificationKeys(kid string) []VerificationKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	state := func(id string) string {
+		if id == ks.active {
+			return KeyStateActive
+		}
+		return KeyStatePrevious
+	}
+	matches := func(key *SigningKey) bool {
+		if kid == "" {
+			return key.Algorithm == AlgorithmHS256
+		}
+		return key.ID == kid
+	}
+
+	var keys []VerificationKey
+	if active, ok := ks.keys[ks.active]; ok && matches(active) {
+		keys = append(keys, VerificationKey{Key: active, State: KeyStateActive})
+	}
+	for id, key := range ks.keys {
+		if id != ks.active && matches(key) {
+			keys = append(keys, VerificationKey{Key: key, State: state(id)})
+		}
+	}
+	for _, retired := range ks.liveRetired(ks.now()) {
+		if matches(retired.key) {
+			keys = append(keys, VerificationKey{Key: retired.key, State: KeyStateRetired})
+		}
+	}
+	return keys
+}
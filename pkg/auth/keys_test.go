@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestRSAKey(t *testing.T, id string) *SigningKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	return NewRSAKey(id, key)
+}
+
+func newTestECDSAKey(t *testing.T, id string) *SigningKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	signingKey, err := NewECDSAKey(id, key)
+	if err != nil {
+		t.Fatalf("Failed to create ECDSA signing key: %v", err)
+	}
+	return signingKey
+}
+
+func TestJWTManager_AsymmetricKeys(t *testing.T) {
+	for _, key := range []*SigningKey{newTestRSAKey(t, "rsa-1"), newTestECDSAKey(t, "ec-1")} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			keys, err := NewKeySet(key)
+			if err != nil {
+				t.Fatalf("Failed to create key set: %v", err)
+			}
+			jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+			tokenString, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+			if err != nil {
+				t.Fatalf("Failed to parse token: %v", err)
+			}
+			if token.Header["kid"] != key.ID {
+				t.Errorf("Expected kid %s, got %v", key.ID, token.Header["kid"])
+			}
+			if token.Header["alg"] != key.Algorithm {
+				t.Errorf("Expected alg %s, got %v", key.Algorithm, token.Header["alg"])
+			}
+
+			claims, err := jwtManager.ValidateToken(tokenString)
+			if err != nil {
+				t.Fatalf("Failed to validate token: %v", err)
+			}
+			if claims.UserID != "user123" {
+				t.Errorf("Expected UserID user123, got %s", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestJWTManager_KeyRotation(t *testing.T) {
+	keys, err := NewKeySet(NewHMACKey("2024-01", "old-secret"))
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+	oldToken, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// 轮换到新的 RSA 密钥
+	if err := keys.Rotate(newTestRSAKey(t, "2024-02")); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	newToken, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// 轮换前签发的令牌仍然有效
+	if _, err := jwtManager.ValidateToken(oldToken); err != nil {
+		t.Errorf("Expected old token to remain valid after rotation, got %v", err)
+	}
+	if _, err := jwtManager.ValidateToken(newToken); err != nil {
+		t.Errorf("Expected new token to be valid, got %v", err)
+	}
+
+	// 移除旧密钥后，旧令牌失效
+	if err := keys.Remove("2024-01"); err != nil {
+		t.Fatalf("Failed to remove key: %v", err)
+	}
+	if _, err := jwtManager.ValidateToken(oldToken); err == nil {
+		t.Error("Expected old token to be invalid after its key was removed")
+	}
+	if err := keys.Remove("2024-02"); err == nil {
+		t.Error("Expected removing the active key to fail")
+	}
+}
+
+func TestJWTManager_ReloadKeepsRetiredKeyDuringGrace(t *testing.T) {
+	keys := mustKeySet(t, NewHMACKey(DefaultKeyID, "old-secret"), NewHMACKey("2023-12", "previous-secret"))
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+	var used []string
+	jwtManager.SetVerificationObserver(func(kid, state string) {
+		used = append(used, kid+"/"+state)
+	})
+
+	oldToken, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// 密钥ID不变、密钥内容更换，并移除配置的旧密钥
+	if err := keys.Reload(NewHMACKey(DefaultKeyID, "new-secret"), nil, time.Hour); err != nil {
+		t.Fatalf("Failed to reload keys: %v", err)
+	}
+	if _, ok := keys.Get("2023-12"); ok {
+		t.Error("Expected keys missing from the reloaded configuration to be removed")
+	}
+	newToken, err := jwtManager.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if _, err := jwtManager.ValidateToken(newToken); err != nil {
+		t.Errorf("Expected new token to be valid, got %v", err)
+	}
+	if _, err := jwtManager.ValidateToken(oldToken); err != nil {
+		t.Errorf("Expected old token to remain valid during the grace period, got %v", err)
+	}
+	if want := []string{"default/active", "default/retired"}; !slices.Equal(used, want) {
+		t.Errorf("Expected verifications %v, got %v", want, used)
+	}
+
+	// 重新加载相同的密钥不会延长宽限期
+	if err := keys.Reload(NewHMACKey(DefaultKeyID, "new-secret"), nil, 24*time.Hour); err != nil {
+		t.Fatalf("Failed to reload keys: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := jwtManager.ValidateToken(oldToken); err == nil {
+		t.Error("Expected old token to be invalid after the grace period")
+	}
+	if _, err := jwtManager.ValidateToken(newToken); err != nil {
+		t.Errorf("Expected new token to remain valid, got %v", err)
+	}
+}
+
+func TestKeySet_ReloadPublishesRetiredKeys(t *testing.T) {
+	keys := mustKeySet(t, newTestRSAKey(t, "rsa-1"))
+	if err := keys.Reload(newTestRSAKey(t, "rsa-2"), nil, time.Hour); err != nil {
+		t.Fatalf("Failed to reload keys: %v", err)
+	}
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "rsa-2" || jwks.Keys[1].Kid != "rsa-1" {
+		t.Errorf("Expected JWKS to contain the new and the retired key, got %+v", jwks.Keys)
+	}
+
+	if err := keys.Reload(NewHMACKey("hmac-1", ""), nil, time.Hour); err == nil {
+		t.Error("Expected reloading with a key that cannot sign to fail")
+	}
+}
+
+func TestJWTManager_LegacyTokenWithoutKid(t *testing.T) {
+	secretKey := "legacy-secret"
+	claims := &Claims{
+		UserID: "user123",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+	if err != nil {
+		t.Fatalf("Failed to sign legacy token: %v", err)
+	}
+
+	keys, err := NewKeySet(newTestRSAKey(t, "rsa-1"), NewHMACKey(DefaultKeyID, secretKey))
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+	if _, err := jwtManager.ValidateToken(legacyToken); err != nil {
+		t.Errorf("Expected legacy token without kid to validate against HMAC keys, got %v", err)
+	}
+}
+
+func TestJWTManager_RejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey := newTestRSAKey(t, "rsa-1")
+	keys, err := NewKeySet(rsaKey)
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	jwtManager := NewJWTManagerWithKeys(keys, 1, nil)
+
+	// 使用公钥作为 HMAC 密钥伪造令牌
+	publicDER, err := x509.MarshalPKIXPublicKey(rsaKey.public)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID: "attacker",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	forged.Header["kid"] = "rsa-1"
+	forgedToken, err := forged.SignedString(publicPEM)
+	if err != nil {
+		t.Fatalf("Failed to sign forged token: %v", err)
+	}
+
+	if _, err := jwtManager.ValidateToken(forgedToken); err == nil {
+		t.Error("Expected token with mismatched algorithm to be rejected")
+	}
+}
+
+func TestJWTManager_UnknownKid(t *testing.T) {
+	issuer := NewJWTManagerWithKeys(mustKeySet(t, NewHMACKey("other", "test-secret-key")), 1, nil)
+	verifier := NewJWTManagerWithKeys(mustKeySet(t, NewHMACKey("current", "test-secret-key")), 1, nil)
+
+	tokenString, err := issuer.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	_, err = verifier.ValidateToken(tokenString)
+	if !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Expected ErrUnknownKeyID, got %v", err)
+	}
+}
+
+func TestParseKeysPEM(t *testing.T) {
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivate)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	signer, err := ParsePrivateKeyPEM("rsa-1", privatePEM)
+	if err != nil {
+		t.Fatalf("Failed to parse private key: %v", err)
+	}
+	if signer.Algorithm != AlgorithmRS256 || !signer.CanSign() {
+		t.Errorf("Expected signing RS256 key, got %s (can sign: %v)", signer.Algorithm, signer.CanSign())
+	}
+
+	verifier, err := ParsePublicKeyPEM("rsa-1", publicPEM)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+	if verifier.CanSign() {
+		t.Error("Expected public key to be verification only")
+	}
+	if _, err := NewKeySet(verifier); !errors.Is(err, ErrVerificationOnly) {
+		t.Errorf("Expected ErrVerificationOnly for verification-only active key, got %v", err)
+	}
+
+	// 签名方持有私钥，验证方只持有公钥
+	issuer := NewJWTManagerWithKeys(mustKeySet(t, signer), 1, nil)
+	validator := NewJWTManagerWithKeys(mustKeySet(t, NewHMACKey("local", "unused"), verifier), 1, nil)
+	tokenString, err := issuer.GenerateToken("user123", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := validator.ValidateToken(tokenString); err != nil {
+		t.Errorf("Expected token to validate with public key, got %v", err)
+	}
+
+	if _, err := ParsePrivateKeyPEM("bad", []byte("not a key")); err == nil {
+		t.Error("Expected error for invalid PEM data")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	keys := mustKeySet(t, newTestECDSAKey(t, "ec-1"), newTestRSAKey(t, "rsa-1"), NewHMACKey("hmac-1", "secret"))
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Expected 2 public keys (HMAC excluded), got %d", len(jwks.Keys))
+	}
+
+	ec, rs := jwks.Keys[0], jwks.Keys[1]
+	if ec.Kid != "ec-1" || ec.Kty != "EC" || ec.Alg != AlgorithmES256 || ec.Crv != "P-256" || ec.X == "" || ec.Y == "" {
+		t.Errorf("Unexpected EC JWK: %+v", ec)
+	}
+	if rs.Kid != "rsa-1" || rs.Kty != "RSA" || rs.Alg != AlgorithmRS256 || rs.E != "AQAB" || rs.N == "" {
+		t.Errorf("Unexpected RSA JWK: %+v", rs)
+	}
+	if ec.Use != "sig" || rs.Use != "sig" {
+		t.Error("Expected JWK use to be sig")
+	}
+}
+
+func mustKeySet(t *testing.T, active *SigningKey, others ...*SigningKey) *KeySet {
+	t.Helper()
+	keys, err := NewKeySet(active, others...)
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	return keys
+}
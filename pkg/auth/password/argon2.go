@@ -0,0 +1,140 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idPrefix argon2id 哈希的前缀
+const argon2idPrefix = "$argon2id$"
+
+// maxArgon2Memory argon2id 内存参数的上限（KiB），防止损坏的哈希在验证时占用过多内存
+const maxArgon2Memory = 4 * 1024 * 1024
+
+// 盐和哈希的默认长度（字节）
+const (
+	defaultArgon2SaltLength = 16
+	defaultArgon2KeyLength  = 32
+)
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // 内存（KiB）
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐的长度（字节），为 0 时使用 16
+	KeyLength   uint32 // 哈希的长度（字节），为 0 时使用 32
+}
+
+// DefaultArgon2Params 默认的 argon2id 参数：64 MiB 内存，3 次迭代，并行度 2
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+}
+
+func (p Argon2Params) saltLength() uint32 {
+	if p.SaltLength == 0 {
+		return defaultArgon2SaltLength
+	}
+	return p.SaltLength
+}
+
+func (p Argon2Params) keyLength() uint32 {
+	if p.KeyLength == 0 {
+		return defaultArgon2KeyLength
+	}
+	return p.KeyLength
+}
+
+// Argon2id argon2id 哈希
+type Argon2id struct {
+	Params Argon2Params
+}
+
+// validate 校验 argon2id 参数
+func (a Argon2id) validate() error {
+	p := a.Params
+	switch {
+	case p.Iterations < 1:
+		return fmt.Errorf("%w: argon2id iterations must be at least 1", ErrInvalidPolicy)
+	case p.Parallelism < 1:
+		return fmt.Errorf("%w: argon2id parallelism must be at least 1", ErrInvalidPolicy)
+	case p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory:
+		return fmt.Errorf("%w: argon2id memory must be between 8*parallelism and %d KiB", ErrInvalidPolicy, maxArgon2Memory)
+	case p.saltLength() < 8:
+		return fmt.Errorf("%w: argon2id salt must be at least 8 bytes", ErrInvalidPolicy)
+	case p.keyLength() < 16:
+		return fmt.Errorf("%w: argon2id key must be at least 16 bytes", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Hash 计算密码的 argon2id 哈希，使用随机盐
+func (a Argon2id) Hash(password string) (string, error) {
+	p := a.Params
+	salt := make([]byte, p.saltLength())
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("password: failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.keyLength())
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 按哈希中记录的参数验证密码
+func (Argon2id) Verify(hash, password string) error {
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// NeedsRehash 判断哈希不是有效的 argon2id 哈希，或任一参数低于当前参数
+func (a Argon2id) NeedsRehash(hash string) bool {
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	p := a.Params
+	return params.Memory < p.Memory || params.Iterations < p.Iterations || params.Parallelism < p.Parallelism ||
+		uint32(len(salt)) < p.saltLength() || uint32(len(key)) < p.keyLength()
+}
+
+// parseArgon2id 解析 $argon2id$v=19$m=65536,t=3,p=2$<盐>$<哈希> 格式的哈希
+func parseArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2id version", ErrUnknownFormat)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("%w: invalid argon2id parameters", ErrUnknownFormat)
+	}
+	if params.Iterations < 1 || params.Parallelism < 1 || params.Memory > maxArgon2Memory {
+		return params, nil, nil, fmt.Errorf("%w: invalid argon2id parameters", ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: invalid argon2id salt", ErrUnknownFormat)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("%w: invalid argon2id hash", ErrUnknownFormat)
+	}
+	return params, salt, key, nil
+}
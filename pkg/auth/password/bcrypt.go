@@ -0,0 +1,65 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost bcrypt 的默认计算成本
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+// Bcrypt bcrypt 哈希，只使用密码的前 72 字节
+type Bcrypt struct {
+	Cost int // 计算成本，<=0 时使用 DefaultBcryptCost
+}
+
+// cost 返回实际使用的计算成本
+func (b Bcrypt) cost() int {
+	if b.Cost <= 0 {
+		return DefaultBcryptCost
+	}
+	return b.Cost
+}
+
+// validate 校验计算成本
+func (b Bcrypt) validate() error {
+	if b.Cost < bcrypt.MinCost || b.Cost > bcrypt.MaxCost {
+		return fmt.Errorf("%w: bcrypt cost must be between %d and %d", ErrInvalidPolicy, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+// Hash 计算密码的 bcrypt 哈希
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify 验证密码与 bcrypt 哈希是否匹配
+func (Bcrypt) Verify(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	return nil
+}
+
+// NeedsRehash 判断哈希不是 bcrypt 哈希或计算成本低于当前成本
+func (b Bcrypt) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.cost()
+}
+
+// isBcrypt 判断是否为 bcrypt 哈希
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
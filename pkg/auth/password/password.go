@@ -0,0 +1,162 @@
+// Package password 密码哈希
+//
+// 支持 bcrypt 和 argon2id 两种算法，算法和参数随哈希值一起保存（bcrypt 的 $2a$<成本>$...，
+// argon2id 的 PHC 格式 $argon2id$v=19$m=<内存>,t=<迭代>,p=<并行度>$<盐>$<哈希>），
+// 因此调整策略后已有的哈希仍可验证。Manager 按当前策略计算新哈希，
+// 并通过 NeedsRehash 判断已有哈希是否弱于策略，调用方在验证成功后重新哈希即可逐步升级。
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// 密码哈希相关错误
+var (
+	ErrMismatch      = errors.New("password: hash does not match password")
+	ErrUnknownFormat = errors.New("password: unrecognized hash format")
+	ErrInvalidPolicy = errors.New("password: invalid policy")
+)
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// Hash 计算密码的哈希，结果中包含算法和参数
+	Hash(password string) (string, error)
+	// Verify 验证密码与哈希是否匹配，不匹配时返回 ErrMismatch
+	Verify(hash, password string) error
+	// NeedsRehash 判断哈希是否弱于当前参数，须用当前参数重新计算
+	NeedsRehash(hash string) bool
+}
+
+// Policy 密码哈希策略：新密码使用的算法和各算法的参数
+type Policy struct {
+	Algorithm  string       // 新哈希使用的算法
+	BcryptCost int          // bcrypt 计算成本
+	Argon2     Argon2Params // argon2id 参数
+}
+
+// DefaultPolicy 默认策略：bcrypt，默认成本
+func DefaultPolicy() Policy {
+	return Policy{Algorithm: AlgorithmBcrypt, BcryptCost: DefaultBcryptCost, Argon2: DefaultArgon2Params()}
+}
+
+// Validate 校验策略中当前算法的参数
+func (p Policy) Validate() error {
+	_, err := p.hasher()
+	return err
+}
+
+// hasher 返回策略当前算法的哈希实现
+func (p Policy) hasher() (Hasher, error) {
+	switch p.Algorithm {
+	case AlgorithmBcrypt:
+		hasher := Bcrypt{Cost: p.BcryptCost}
+		if err := hasher.validate(); err != nil {
+			return nil, err
+		}
+		return hasher, nil
+	case AlgorithmArgon2id:
+		hasher := Argon2id{Params: p.Argon2}
+		if err := hasher.validate(); err != nil {
+			return nil, err
+		}
+		return hasher, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidPolicy, p.Algorithm)
+	}
+}
+
+// Identify 返回哈希使用的算法，无法识别时返回空字符串
+func Identify(hash string) string {
+	switch {
+	case isBcrypt(hash):
+		return AlgorithmBcrypt
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return AlgorithmArgon2id
+	default:
+		return ""
+	}
+}
+
+// Manager 按策略计算密码哈希，可验证任一支持算法的哈希，策略可在运行中替换
+type Manager struct {
+	policy atomic.Pointer[managerPolicy]
+}
+
+// managerPolicy 策略及其当前算法的哈希实现
+type managerPolicy struct {
+	Policy
+	hasher Hasher
+}
+
+// NewManager 创建密码哈希管理器
+func NewManager(policy Policy) (*Manager, error) {
+	m := &Manager{}
+	if err := m.SetPolicy(policy); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Default 使用默认策略的管理器
+func Default() *Manager {
+	m, err := NewManager(DefaultPolicy())
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// SetPolicy 替换哈希策略，只影响之后计算的哈希和 NeedsRehash 的判断
+func (m *Manager) SetPolicy(policy Policy) error {
+	hasher, err := policy.hasher()
+	if err != nil {
+		return err
+	}
+	m.policy.Store(&managerPolicy{Policy: policy, hasher: hasher})
+	return nil
+}
+
+// Policy 返回当前的哈希策略
+func (m *Manager) Policy() Policy {
+	return m.policy.Load().Policy
+}
+
+// Hash 使用当前策略的算法和参数计算密码的哈希
+func (m *Manager) Hash(password string) (string, error) {
+	return m.policy.Load().hasher.Hash(password)
+}
+
+// Verify 按哈希中记录的算法和参数验证密码，与当前策略无关
+func (m *Manager) Verify(hash, password string) error {
+	switch Identify(hash) {
+	case AlgorithmBcrypt:
+		return Bcrypt{}.Verify(hash, password)
+	case AlgorithmArgon2id:
+		return Argon2id{}.Verify(hash, password)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// NeedsRehash 判断哈希是否须按当前策略重新计算：算法与策略不同，或参数弱于策略
+// 无法识别的哈希（如已抹除的密码）不重新计算
+func (m *Manager) NeedsRehash(hash string) bool {
+	current := m.policy.Load()
+	algorithm := Identify(hash)
+	if algorithm == "" {
+		return false
+	}
+	if algorithm != current.Algorithm {
+		return true
+	}
+	return current.hasher.NeedsRehash(hash)
+}
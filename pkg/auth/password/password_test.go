@@ -0,0 +1,106 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2 测试用的低成本 argon2id 参数
+var fastArgon2 = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestBcrypt(t *testing.T) {
+	hasher := Bcrypt{Cost: bcrypt.MinCost}
+	hash, err := hasher.Hash("secret123")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmBcrypt, Identify(hash))
+
+	assert.NoError(t, hasher.Verify(hash, "secret123"))
+	assert.ErrorIs(t, hasher.Verify(hash, "wrong"), ErrMismatch)
+	assert.ErrorIs(t, hasher.Verify("garbage", "secret123"), ErrUnknownFormat)
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, Bcrypt{Cost: bcrypt.MinCost + 1}.NeedsRehash(hash), "成本提高后重新哈希")
+	assert.False(t, Bcrypt{Cost: bcrypt.MinCost}.NeedsRehash(hash))
+}
+
+func TestArgon2id(t *testing.T) {
+	hasher := Argon2id{Params: fastArgon2}
+	hash, err := hasher.Hash("secret123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+	assert.Equal(t, AlgorithmArgon2id, Identify(hash))
+
+	again, err := hasher.Hash("secret123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again, "随机盐")
+
+	assert.NoError(t, hasher.Verify(hash, "secret123"))
+	assert.NoError(t, Argon2id{}.Verify(hash, "secret123"), "按哈希中记录的参数验证")
+	assert.ErrorIs(t, hasher.Verify(hash, "wrong"), ErrMismatch)
+	for _, malformed := range []string{"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5", "$argon2id$v=19$m=64$c2FsdHNhbHQ$a2V5", "$argon2id$v=19$m=64,t=1,p=1$!$a2V5", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$"} {
+		assert.ErrorIs(t, hasher.Verify(malformed, "secret123"), ErrUnknownFormat, malformed)
+	}
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	stronger := fastArgon2
+	stronger.Iterations = 2
+	assert.True(t, Argon2id{Params: stronger}.NeedsRehash(hash))
+	stronger = fastArgon2
+	stronger.Memory = 128
+	assert.True(t, Argon2id{Params: stronger}.NeedsRehash(hash))
+	assert.True(t, hasher.NeedsRehash("garbage"))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, DefaultPolicy().Validate())
+	assert.NoError(t, Policy{Algorithm: AlgorithmArgon2id, Argon2: DefaultArgon2Params()}.Validate())
+
+	for name, policy := range map[string]Policy{
+		"未知算法":       {Algorithm: "md5"},
+		"bcrypt成本过低": {Algorithm: AlgorithmBcrypt, BcryptCost: 3},
+		"迭代次数为0":     {Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 64, Parallelism: 1}},
+		"并行度为0":      {Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 64, Iterations: 1}},
+		"内存不足":       {Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 8, Iterations: 1, Parallelism: 2}},
+		"盐过短":        {Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 4}},
+	} {
+		assert.ErrorIs(t, policy.Validate(), ErrInvalidPolicy, name)
+	}
+}
+
+func TestManager(t *testing.T) {
+	manager, err := NewManager(Policy{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	bcryptHash, err := manager.Hash("secret123")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmBcrypt, Identify(bcryptHash))
+	assert.False(t, manager.NeedsRehash(bcryptHash))
+
+	// 切换到 argon2id 后，已有的 bcrypt 哈希仍可验证，但须重新哈希
+	require.NoError(t, manager.SetPolicy(Policy{Algorithm: AlgorithmArgon2id, Argon2: fastArgon2}))
+	assert.Equal(t, AlgorithmArgon2id, manager.Policy().Algorithm)
+	assert.NoError(t, manager.Verify(bcryptHash, "secret123"))
+	assert.ErrorIs(t, manager.Verify(bcryptHash, "wrong"), ErrMismatch)
+	assert.True(t, manager.NeedsRehash(bcryptHash))
+
+	argonHash, err := manager.Hash("secret123")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmArgon2id, Identify(argonHash))
+	assert.NoError(t, manager.Verify(argonHash, "secret123"))
+	assert.False(t, manager.NeedsRehash(argonHash))
+
+	// 无法识别的哈希（如已抹除的密码）验证失败，也不重新哈希
+	assert.ErrorIs(t, manager.Verify("!", "secret123"), ErrUnknownFormat)
+	assert.False(t, manager.NeedsRehash("!"))
+
+	// 无效的策略不替换当前策略
+	assert.ErrorIs(t, manager.SetPolicy(Policy{Algorithm: "md5"}), ErrInvalidPolicy)
+	assert.Equal(t, AlgorithmArgon2id, manager.Policy().Algorithm)
+
+	_, err = NewManager(Policy{Algorithm: AlgorithmBcrypt})
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
@@ -0,0 +1,73 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPwnedPasswordsURL HaveIBeenPwned 密码库 API 的地址
+const DefaultPwnedPasswordsURL = "https://api.pwnedpasswords.com"
+
+// PwnedPasswords 使用 HaveIBeenPwned 的 k-匿名范围查询检查泄露密码
+//
+// 只发送密码 SHA-1 哈希的前 5 位，服务端返回该前缀下所有哈希后缀和出现次数，在本地比对；
+// 请求带有 Add-Padding 头，响应中混入出现次数为 0 的填充项，使响应大小不泄露前缀的命中数
+type PwnedPasswords struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewPwnedPasswords 创建泄露密码查询客户端，baseURL 为空时使用 DefaultPwnedPasswordsURL
+func NewPwnedPasswords(baseURL string, timeout time.Duration) *PwnedPasswords {
+	if baseURL == "" {
+		baseURL = DefaultPwnedPasswordsURL
+	}
+	return &PwnedPasswords{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Breached 返回密码在泄露库中出现的次数
+func (p *PwnedPasswords) Breached(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "go-server")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("pwned passwords: unexpected status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, rawCount, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		count, err := strconv.Atoi(rawCount)
+		if err != nil {
+			return 0, fmt.Errorf("pwned passwords: invalid count %q", rawCount)
+		}
+		return count, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,54 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPwnedPasswords(t *testing.T) {
+	// "password" 的 SHA-1 为 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/range/5BAA6":
+			fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3730471\r\n")
+		default:
+			fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:0\r\n")
+		}
+	}))
+	defer server.Close()
+	client := NewPwnedPasswords(server.URL+"/", time.Second)
+	ctx := context.Background()
+
+	count, err := client.Breached(ctx, "password")
+	require.NoError(t, err)
+	assert.Equal(t, 3730471, count)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/range/5BAA6", requests[0].URL.Path, "只发送哈希的前 5 位")
+	assert.Equal(t, "true", requests[0].Header.Get("Add-Padding"))
+
+	count, err = client.Breached(ctx, "correct-horse-42")
+	require.NoError(t, err)
+	assert.Zero(t, count, "填充项的出现次数为 0")
+
+	_, err = NewPwnedPasswords("http://127.0.0.1:1", 100*time.Millisecond).Breached(ctx, "password")
+	assert.Error(t, err)
+}
+
+func TestPwnedPasswordsUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewPwnedPasswords(server.URL, time.Second).Breached(context.Background(), "password")
+	assert.ErrorContains(t, err, "unexpected status 503")
+}
@@ -0,0 +1,207 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码规则名称
+const (
+	RuleMinLength  = "min_length"  // 长度不足
+	RuleMaxLength  = "max_length"  // 长度超过上限
+	RuleMinEntropy = "min_entropy" // 熵不足，过于简单
+	RuleDenylist   = "denylist"    // 在禁用密码列表中
+	RuleUserInfo   = "user_info"   // 包含用户名、邮箱等个人信息
+	RuleBreached   = "breached"    // 出现在已泄露的密码库中
+)
+
+// minUserInfoLength 个人信息至少包含的字符数，更短的用户名等不参与 RuleUserInfo 检查
+const minUserInfoLength = 4
+
+// Rules 密码规则，各项为零值时不检查
+type Rules struct {
+	MinLength      int      // 最小长度（字符）
+	MaxLength      int      // 最大长度（字节），bcrypt 只使用前 72 字节
+	MinEntropy     float64  // 最小熵（比特），见 Entropy
+	Denylist       []string // 禁用的密码，不区分大小写
+	RejectUserInfo bool     // 禁止包含用户名、邮箱用户名部分等个人信息
+}
+
+// Violation 密码违反的规则
+type Violation struct {
+	Rule  string  // 规则名称，见 Rule* 常量
+	Limit float64 // 规则的限制值，如最小长度，没有限制值的规则为 0
+}
+
+// String 返回违反规则的英文描述
+func (v Violation) String() string {
+	switch v.Rule {
+	case RuleMinLength:
+		return fmt.Sprintf("password must be at least %d characters", int(v.Limit))
+	case RuleMaxLength:
+		return fmt.Sprintf("password must be at most %d bytes", int(v.Limit))
+	case RuleMinEntropy:
+		return fmt.Sprintf("password is too predictable, it needs at least %.0f bits of entropy", v.Limit)
+	case RuleDenylist:
+		return "password is too common"
+	case RuleUserInfo:
+		return "password must not contain personal information"
+	case RuleBreached:
+		return "password has appeared in a data breach"
+	default:
+		return v.Rule
+	}
+}
+
+// BreachChecker 查询密码是否出现在已泄露的密码库中
+type BreachChecker interface {
+	// Breached 返回密码在泄露库中出现的次数，未出现时为 0
+	Breached(ctx context.Context, password string) (int, error)
+}
+
+// Checker 按密码规则和泄露库检查新密码，规则可在运行中替换
+type Checker struct {
+	config atomic.Pointer[checkerConfig]
+}
+
+// checkerConfig 规则、规范化后的禁用列表和泄露库
+type checkerConfig struct {
+	rules    Rules
+	denylist map[string]struct{}
+	breaches BreachChecker
+}
+
+// NewChecker 创建密码检查器，breaches 为 nil 时不检查泄露库
+func NewChecker(rules Rules, breaches BreachChecker) *Checker {
+	c := &Checker{}
+	c.Update(rules, breaches)
+	return c
+}
+
+// Update 替换密码规则和泄露库，只影响之后的检查
+func (c *Checker) Update(rules Rules, breaches BreachChecker) {
+	denylist := make(map[string]struct{}, len(rules.Denylist))
+	for _, entry := range rules.Denylist {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			denylist[entry] = struct{}{}
+		}
+	}
+	c.config.Store(&checkerConfig{rules: rules, denylist: denylist, breaches: breaches})
+}
+
+// Rules 返回当前的密码规则
+func (c *Checker) Rules() Rules {
+	return c.config.Load().rules
+}
+
+// Check 检查密码，返回违反的全部规则，userInfo 为用户名、邮箱等不应出现在密码中的个人信息
+// 只有其他规则全部满足时才查询泄露库；查询失败时返回其他规则的检查结果和错误，由调用方决定是否放行
+func (c *Checker) Check(ctx context.Context, password string, userInfo ...string) ([]Violation, error) {
+	config := c.config.Load()
+	rules := config.rules
+	var violations []Violation
+
+	if rules.MinLength > 0 && utf8.RuneCountInString(password) < rules.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Limit: float64(rules.MinLength)})
+	}
+	if rules.MaxLength > 0 && len(password) > rules.MaxLength {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Limit: float64(rules.MaxLength)})
+	}
+	if rules.MinEntropy > 0 && Entropy(password) < rules.MinEntropy {
+		violations = append(violations, Violation{Rule: RuleMinEntropy, Limit: rules.MinEntropy})
+	}
+	if _, denied := config.denylist[strings.ToLower(password)]; denied {
+		violations = append(violations, Violation{Rule: RuleDenylist})
+	}
+	if rules.RejectUserInfo && containsUserInfo(password, userInfo) {
+		violations = append(violations, Violation{Rule: RuleUserInfo})
+	}
+
+	if len(violations) > 0 || config.breaches == nil {
+		return violations, nil
+	}
+	count, err := config.breaches.Breached(ctx, password)
+	if err != nil {
+		return nil, fmt.Errorf("password: breach check failed: %w", err)
+	}
+	if count > 0 {
+		violations = append(violations, Violation{Rule: RuleBreached})
+	}
+	return violations, nil
+}
+
+// containsUserInfo 判断密码是否包含个人信息，邮箱只取 @ 之前的部分，不区分大小写
+func containsUserInfo(password string, userInfo []string) bool {
+	lower := strings.ToLower(password)
+	for _, info := range userInfo {
+		info, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(info)), "@")
+		if utf8.RuneCountInString(info) >= minUserInfoLength && strings.Contains(lower, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy 按字符集大小估算密码的熵（比特）：字符数 × log2(用到的字符类别的字符总数)
+// 字符类别为小写字母（26）、大写字母（26）、数字（10）、ASCII 符号（33）和其他字符（100），连续重复的字符只计一次
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	var length int
+	var previous rune = -1
+	for _, r := range password {
+		if r != previous {
+			length++
+		}
+		previous = r
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(float64(pool))
+}
+
+// LoadDenylist 读取禁用密码列表，每行一个密码，忽略空行和以 # 开头的注释行
+func LoadDenylist(r io.Reader) ([]string, error) {
+	var denylist []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist = append(denylist, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return denylist, nil
+}
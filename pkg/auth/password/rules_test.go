@@ -0,0 +1,122 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticBreaches 返回固定结果的泄露库
+type staticBreaches struct {
+	count int
+	err   error
+	calls int
+}
+
+func (b *staticBreaches) Breached(ctx context.Context, password string) (int, error) {
+	b.calls++
+	return b.count, b.err
+}
+
+// rules 返回违反的规则名称
+func rules(violations []Violation) []string {
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	checker := NewChecker(Rules{
+		MinLength:      8,
+		MaxLength:      72,
+		MinEntropy:     30,
+		Denylist:       []string{" Password123 ", ""},
+		RejectUserInfo: true,
+	}, nil)
+
+	violations, err := checker.Check(ctx, "correct-horse-42", "alice", "alice@example.com")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"过短且过于简单", "aaaa", []string{RuleMinLength, RuleMinEntropy}},
+		{"超过最大字节数", strings.Repeat("ab1", 25), []string{RuleMaxLength}},
+		{"禁用列表不区分大小写", "PASSWORD123", []string{RuleDenylist}},
+		{"包含用户名", "xALICEx-2024", []string{RuleUserInfo}},
+		{"包含邮箱用户名部分", "my-alice-pass9", []string{RuleUserInfo}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := checker.Check(ctx, tt.password, "alice", "alice@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rules(violations))
+		})
+	}
+
+	t.Run("过短的个人信息不检查", func(t *testing.T) {
+		violations, err := checker.Check(ctx, "bob-secret-99", "bob")
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("替换规则", func(t *testing.T) {
+		checker := NewChecker(Rules{MinLength: 8}, nil)
+		checker.Update(Rules{MinLength: 20}, nil)
+		assert.Equal(t, 20, checker.Rules().MinLength)
+		violations, err := checker.Check(ctx, "correct-horse-42")
+		require.NoError(t, err)
+		assert.Equal(t, []Violation{{Rule: RuleMinLength, Limit: 20}}, violations)
+		assert.Equal(t, "password must be at least 20 characters", violations[0].String())
+	})
+}
+
+func TestCheckerBreaches(t *testing.T) {
+	ctx := context.Background()
+
+	breaches := &staticBreaches{count: 3}
+	checker := NewChecker(Rules{MinLength: 8}, breaches)
+	violations, err := checker.Check(ctx, "correct-horse-42")
+	require.NoError(t, err)
+	assert.Equal(t, []string{RuleBreached}, rules(violations))
+
+	// 已违反其他规则时不查询泄露库
+	violations, err = checker.Check(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, []string{RuleMinLength}, rules(violations))
+	assert.Equal(t, 1, breaches.calls)
+
+	breaches.count = 0
+	violations, err = checker.Check(ctx, "correct-horse-42")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	breaches.err = errors.New("timeout")
+	_, err = checker.Check(ctx, "correct-horse-42")
+	assert.Error(t, err)
+}
+
+func TestEntropy(t *testing.T) {
+	assert.Zero(t, Entropy(""))
+	assert.InDelta(t, 4*math.Log2(10), Entropy("1234"), 0.001)
+	assert.InDelta(t, 1*math.Log2(26), Entropy("aaaaaaaa"), 0.001, "连续重复的字符只计一次")
+	assert.InDelta(t, 4*math.Log2(26+26+10+33), Entropy("aB3!"), 0.001)
+	assert.Greater(t, Entropy("correct-horse-battery"), Entropy("password"))
+}
+
+func TestLoadDenylist(t *testing.T) {
+	denylist, err := LoadDenylist(strings.NewReader("# 常见密码\npassword\n\n  qwerty123  \n#注释\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"password", "qwerty123"}, denylist)
+}
@@ -0,0 +1,111 @@
+package autotls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go-server/pkg/storage"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// pemContentType 证书和私钥对象的内容类型
+const pemContentType = "application/x-pem-file"
+
+// StorageCache 把证书、私钥和 ACME 账户密钥保存在对象存储的 prefix 目录下，实现 autocert.Cache
+// 对象包含私钥，prefix 下的对象不能被公开访问
+type StorageCache struct {
+	store  storage.Storage
+	prefix string
+}
+
+// NewStorageCache 创建对象存储证书缓存，prefix 为对象键前缀，如 acme
+func NewStorageCache(store storage.Storage, prefix string) *StorageCache {
+	return &StorageCache{store: store, prefix: prefix}
+}
+
+// Get 读取缓存的数据，不存在时返回 autocert.ErrCacheMiss
+func (c *StorageCache) Get(ctx context.Context, name string) ([]byte, error) {
+	reader, _, err := c.store.Get(ctx, c.key(name))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Put 写入数据
+func (c *StorageCache) Put(ctx context.Context, name string, data []byte) error {
+	if _, err := c.store.Put(ctx, c.key(name), bytes.NewReader(data), int64(len(data)), pemContentType); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete 删除数据
+func (c *StorageCache) Delete(ctx context.Context, name string) error {
+	if err := c.store.Delete(ctx, c.key(name)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *StorageCache) key(name string) string {
+	return c.prefix + "/" + name
+}
+
+// RedisCache 把证书、私钥和 ACME 账户密钥保存在 Redis 的 <prefix>:<name> 键中，实现 autocert.Cache
+// 键不过期，证书续期时覆盖
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache 创建 Redis 证书缓存，prefix 为键前缀，如 acme
+func NewRedisCache(client *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+// Get 读取缓存的数据，不存在时返回 autocert.ErrCacheMiss
+func (c *RedisCache) Get(ctx context.Context, name string) ([]byte, error) {
+	data, err := c.client.Get(ctx, c.key(name)).Bytes()
+	if err == redis.Nil {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Put 写入数据
+func (c *RedisCache) Put(ctx context.Context, name string, data []byte) error {
+	if err := c.client.Set(ctx, c.key(name), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete 删除数据
+func (c *RedisCache) Delete(ctx context.Context, name string) error {
+	if err := c.client.Del(ctx, c.key(name)).Err(); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *RedisCache) key(name string) string {
+	return c.prefix + ":" + name
+}
@@ -0,0 +1,188 @@
+// Package autotls 通过 ACME（如 Let's Encrypt）自动申请和续期 TLS 证书
+// 基于 golang.org/x/crypto/acme/autocert：证书在首次握手或 Prefetch 时申请，到期前在后台续期，
+// 证书、私钥和账户密钥保存在 autocert.Cache 中，多个实例共享同一缓存时只需申请一次
+package autotls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// 验证方式
+const (
+	ChallengeHTTP01    = "http-01"     // 通过 80 端口的 HTTP 请求验证，需要监听 HTTP 端口
+	ChallengeTLSALPN01 = "tls-alpn-01" // 通过 443 端口的 TLS 握手验证，只需 HTTPS 端口
+)
+
+// Recorder 记录证书的签发、到期时间和获取失败
+type Recorder interface {
+	RecordIssued(domain string, notAfter time.Time)
+	RecordCertificate(domain string, notAfter time.Time)
+	RecordError(domain string)
+}
+
+// Config 证书管理配置
+type Config struct {
+	Domains      []string       // 允许申请证书的域名，不支持通配符
+	Email        string         // ACME 账户联系邮箱，可为空
+	DirectoryURL string         // ACME 目录地址，为空时使用 Let's Encrypt 生产环境
+	RenewBefore  time.Duration  // 证书到期前多久续期，为 0 时为 30 天
+	Cache        autocert.Cache // 证书缓存，必需
+	Recorder     Recorder       // 为 nil 时不记录指标
+}
+
+// Manager 管理 Config.Domains 的证书
+type Manager struct {
+	manager  *autocert.Manager
+	domains  []string
+	recorder Recorder
+}
+
+// New 创建证书管理器
+func New(cfg Config) (*Manager, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, errors.New("autotls: at least one domain is required")
+	}
+	if cfg.Cache == nil {
+		return nil, errors.New("autotls: a certificate cache is required")
+	}
+
+	domains := make([]string, len(cfg.Domains))
+	for i, domain := range cfg.Domains {
+		domains[i] = strings.ToLower(strings.TrimSpace(domain))
+	}
+
+	m := &Manager{domains: domains, recorder: cfg.Recorder}
+	m.manager = &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       recordingCache{Cache: cfg.Cache, recorder: cfg.Recorder},
+		HostPolicy:  autocert.HostWhitelist(domains...),
+		RenewBefore: cfg.RenewBefore,
+		Email:       cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		m.manager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return m, nil
+}
+
+// Domains 返回管理的域名
+func (m *Manager) Domains() []string {
+	return slices.Clone(m.domains)
+}
+
+// TLSConfig 返回使用自动证书的 TLS 配置，包含 tls-alpn-01 验证所需的 acme-tls/1 协议
+func (m *Manager) TLSConfig() *tls.Config {
+	config := m.manager.TLSConfig()
+	config.GetCertificate = m.GetCertificate
+	return config
+}
+
+// HTTPHandler 返回 HTTP 端口的处理器，处理 http-01 验证请求，其余请求交给 fallback；
+// fallback 为 nil 时把 GET/HEAD 请求重定向到 HTTPS，其他请求返回 400
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	return m.manager.HTTPHandler(fallback)
+}
+
+// GetCertificate 返回握手域名的证书，缓存中没有或即将到期时向 ACME 服务器申请，并记录证书到期时间
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := m.manager.GetCertificate(hello)
+	if m.recorder == nil || slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+		// tls-alpn-01 验证握手使用临时证书，不记录
+		return cert, err
+	}
+
+	domain := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if !slices.Contains(m.domains, domain) {
+		// 不记录扫描器等访问未配置域名产生的失败，避免指标标签无限增长
+		return cert, err
+	}
+	if err != nil {
+		m.recorder.RecordError(domain)
+	} else if cert.Leaf != nil {
+		m.recorder.RecordCertificate(domain, cert.Leaf.NotAfter)
+	}
+	return cert, err
+}
+
+// Prefetch 为全部域名加载或申请证书，使证书在第一个请求前就绪并开始后台续期
+// tls-alpn-01 验证需要在 HTTPS 端口开始监听后调用；每个域名最多等待 autocert 的 5 分钟申请超时，返回各域名的错误
+func (m *Manager) Prefetch() error {
+	var errs []error
+	for _, domain := range m.domains {
+		hello := &tls.ClientHelloInfo{
+			ServerName: domain,
+			// 声明支持 ECDSA，与现代客户端一样获取 ECDSA 证书
+			SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+			SupportedCurves:   []tls.CurveID{tls.CurveP256},
+			CipherSuites:      []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			SupportedVersions: []uint16{tls.VersionTLS13, tls.VersionTLS12},
+		}
+		if _, err := m.GetCertificate(hello); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", domain, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordingCache 在证书写入缓存时记录签发，缓存写入只发生在申请或续期成功后
+type recordingCache struct {
+	autocert.Cache
+	recorder Recorder
+}
+
+// Put 写入缓存，证书写入成功时记录签发
+func (c recordingCache) Put(ctx context.Context, name string, data []byte) error {
+	if err := c.Cache.Put(ctx, name, data); err != nil {
+		return err
+	}
+	if c.recorder == nil {
+		return nil
+	}
+	if domain, ok := certificateDomain(name); ok {
+		if notAfter, ok := certificateExpiry(data); ok {
+			c.recorder.RecordIssued(domain, notAfter)
+		}
+	}
+	return nil
+}
+
+// certificateDomain 返回证书缓存键对应的域名，账户密钥和验证令牌等其他键返回 false
+// autocert 的证书键为域名（ECDSA）或 域名+rsa
+func certificateDomain(name string) (string, bool) {
+	domain, suffix, found := strings.Cut(name, "+")
+	if found && suffix != "rsa" {
+		return "", false
+	}
+	return domain, domain != ""
+}
+
+// certificateExpiry 返回缓存数据（私钥和证书链的 PEM）中叶子证书的到期时间
+func certificateExpiry(data []byte) (time.Time, bool) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return time.Time{}, false
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return leaf.NotAfter, true
+	}
+}
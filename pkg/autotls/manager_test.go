@@ -0,0 +1,168 @@
+package autotls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"go-server/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// fakeRecorder 记录调用的 Recorder
+type fakeRecorder struct {
+	mu           sync.Mutex
+	issued       map[string]time.Time
+	certificates map[string]time.Time
+	errors       map[string]int
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{
+		issued:       make(map[string]time.Time),
+		certificates: make(map[string]time.Time),
+		errors:       make(map[string]int),
+	}
+}
+
+func (r *fakeRecorder) RecordIssued(domain string, notAfter time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.issued[domain] = notAfter
+}
+
+func (r *fakeRecorder) RecordCertificate(domain string, notAfter time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certificates[domain] = notAfter
+}
+
+func (r *fakeRecorder) RecordError(domain string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors[domain]++
+}
+
+// selfSignedPEM 生成 domain 的自签名 ECDSA 证书，格式与 autocert 写入缓存的数据相同：私钥后接证书链
+func selfSignedPEM(t *testing.T, domain string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+}
+
+func newTestStorageCache(t *testing.T) *StorageCache {
+	t.Helper()
+	store, err := storage.NewLocalStorage(storage.LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads"})
+	require.NoError(t, err)
+	return NewStorageCache(store, "acme")
+}
+
+func TestStorageCache(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestStorageCache(t)
+
+	_, err := cache.Get(ctx, "example.com")
+	assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+
+	require.NoError(t, cache.Put(ctx, "example.com+rsa", []byte("certificate")))
+	data, err := cache.Get(ctx, "example.com+rsa")
+	require.NoError(t, err)
+	assert.Equal(t, "certificate", string(data))
+
+	require.NoError(t, cache.Delete(ctx, "example.com+rsa"))
+	_, err = cache.Get(ctx, "example.com+rsa")
+	assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Config{Cache: newTestStorageCache(t)})
+	assert.Error(t, err, "domains are required")
+
+	_, err = New(Config{Domains: []string{"example.com"}})
+	assert.Error(t, err, "a cache is required")
+}
+
+func TestManager_RecordsCertificates(t *testing.T) {
+	ctx := context.Background()
+	recorder := newFakeRecorder()
+	cache := newTestStorageCache(t)
+	manager, err := New(Config{Domains: []string{"Example.com"}, Cache: cache, Recorder: recorder})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, manager.Domains())
+
+	// 证书写入缓存时记录签发，账户密钥等其他键不记录
+	notAfter := time.Now().Add(60 * 24 * time.Hour).Truncate(time.Second)
+	certificate := selfSignedPEM(t, "example.com", notAfter)
+	require.NoError(t, manager.manager.Cache.Put(ctx, "example.com", certificate))
+	require.NoError(t, manager.manager.Cache.Put(ctx, "acme_account+key", []byte("account key")))
+	assert.Equal(t, map[string]time.Time{"example.com": notAfter.UTC()}, recorder.issued)
+
+	// 握手时从缓存加载证书并记录到期时间
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:       "example.com",
+		SignatureSchemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		CipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	assert.True(t, recorder.certificates["example.com"].Equal(notAfter))
+
+	// 未配置的域名被拒绝，不记录指标
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"})
+	assert.Error(t, err)
+	assert.Empty(t, recorder.errors)
+}
+
+func TestManager_TLSConfig(t *testing.T) {
+	manager, err := New(Config{Domains: []string{"example.com"}, Cache: newTestStorageCache(t)})
+	require.NoError(t, err)
+
+	config := manager.TLSConfig()
+	assert.Contains(t, config.NextProtos, "acme-tls/1", "tls-alpn-01 challenges need the acme-tls/1 protocol")
+	assert.NotNil(t, config.GetCertificate)
+}
+
+func TestCertificateDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		ok     bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com+rsa", "example.com", true},
+		{"example.com+token", "", false},
+		{"abc+http-01", "", false},
+		{"acme_account+key", "", false},
+	}
+	for _, tt := range tests {
+		domain, ok := certificateDomain(tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.domain, domain, tt.name)
+	}
+}
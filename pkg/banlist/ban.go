@@ -0,0 +1,74 @@
+// Package banlist 自动封禁名单
+//
+// BanList 定期读取限流违规热点，把违规次数和违规比例超过阈值的 IP 或用户封禁一段时间，
+// 到期后自动解除。封禁记录保存在 Store 中（进程内存储或 Redis），Redis 存储供多实例共享。
+// 管理员提前解除封禁后有一段宽限期，宽限期内不会因解除之前的违规再次自动封禁。
+package banlist
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// 封禁对象类型
+const (
+	TypeIP   = "ip"
+	TypeUser = "user"
+)
+
+// ReasonRateLimitViolations 因限流违规自动封禁
+const ReasonRateLimitViolations = "rate_limit_violations"
+
+var (
+	// ErrNotFound 封禁记录不存在或已到期
+	ErrNotFound = errors.New("banlist: ban not found")
+
+	// ErrInvalidType 封禁对象类型不是 ip 或 user
+	ErrInvalidType = errors.New("banlist: invalid ban type")
+)
+
+// Ban 封禁记录
+type Ban struct {
+	Type          string    `json:"type" example:"ip"`                      // 封禁对象类型：ip 或 user
+	Identifier    string    `json:"identifier" example:"203.0.113.7"`       // IP 地址或用户ID
+	Reason        string    `json:"reason" example:"rate_limit_violations"` // 封禁原因
+	Violations    int       `json:"violations" example:"120"`               // 封禁时统计窗口内的违规次数
+	ViolationRate float64   `json:"violation_rate" example:"85.5"`          // 封禁时统计窗口内的违规比例（百分比）
+	CreatedAt     time.Time `json:"created_at"`                             // 封禁时间
+	ExpiresAt     time.Time `json:"expires_at"`                             // 到期时间，到期后自动解除
+}
+
+// Offender 统计窗口内的违规者，来自限流违规热点
+type Offender struct {
+	Type          string
+	Identifier    string
+	Violations    int
+	ViolationRate float64 // 百分比
+}
+
+// ValidType 判断封禁对象类型是否合法
+func ValidType(banType string) bool {
+	return banType == TypeIP || banType == TypeUser
+}
+
+// clone 返回封禁记录的副本
+func (b *Ban) clone() *Ban {
+	copied := *b
+	return &copied
+}
+
+// member 返回封禁对象在存储中的标识
+func member(banType, identifier string) string {
+	return banType + ":" + identifier
+}
+
+// sortBans 按到期时间排序，先到期的在前
+func sortBans(bans []*Ban) {
+	sort.Slice(bans, func(i, j int) bool {
+		if !bans[i].ExpiresAt.Equal(bans[j].ExpiresAt) {
+			return bans[i].ExpiresAt.Before(bans[j].ExpiresAt)
+		}
+		return member(bans[i].Type, bans[i].Identifier) < member(bans[j].Type, bans[j].Identifier)
+	})
+}
@@ -0,0 +1,190 @@
+package banlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 默认参数
+const (
+	DefaultThreshold   = 50
+	DefaultDuration    = time.Hour
+	DefaultInterval    = 30 * time.Second
+	DefaultGracePeriod = 10 * time.Minute
+)
+
+// Config 自动封禁参数，除 MinViolationRate 外为零的字段使用默认值
+type Config struct {
+	Threshold        int           // 统计窗口内的违规次数达到该值时封禁
+	MinViolationRate float64       // 同时要求违规比例（百分比）不低于该值，避免封禁流量大但偶尔超限的调用方；为 0 时不要求
+	Duration         time.Duration // 封禁时长
+	Interval         time.Duration // 检查违规热点的间隔
+	GracePeriod      time.Duration // 管理员解除封禁后不自动封禁的时间，通常等于热点的统计窗口
+
+	// OnBan 自动封禁时调用，用于记录日志
+	OnBan func(ban *Ban)
+	// OnError 后台检查失败时调用；为 nil 时忽略错误
+	OnError func(err error)
+}
+
+// BanList 自动封禁名单，定期把违规热点中超过阈值的对象加入封禁
+type BanList struct {
+	store     Store
+	source    func() []Offender
+	config    Config
+	now       func() time.Time
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New 创建封禁名单，source 返回当前统计窗口内的违规者
+func New(store Store, source func() []Offender, config Config) *BanList {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.MinViolationRate < 0 {
+		config.MinViolationRate = 0
+	}
+	if config.Duration <= 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = DefaultGracePeriod
+	}
+
+	return &BanList{
+		store:  store,
+		source: source,
+		config: config,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Check 返回对象生效中的封禁记录，未封禁时返回 nil
+func (b *BanList) Check(ctx context.Context, banType, identifier string) (*Ban, error) {
+	ban, err := b.store.Get(ctx, banType, identifier)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return ban, err
+}
+
+// List 返回所有生效中的封禁记录，按到期时间排序
+func (b *BanList) List(ctx context.Context) ([]*Ban, error) {
+	return b.store.List(ctx)
+}
+
+// Unban 解除封禁，宽限期内不会再次自动封禁；未封禁时返回 ErrNotFound
+func (b *BanList) Unban(ctx context.Context, banType, identifier string) error {
+	if !ValidType(banType) {
+		return ErrInvalidType
+	}
+	return b.store.Lift(ctx, banType, identifier, b.config.GracePeriod)
+}
+
+// Evaluate 封禁超过阈值的违规者，已封禁或在宽限期内的对象保持不变，返回新增的封禁记录
+func (b *BanList) Evaluate(ctx context.Context) ([]*Ban, error) {
+	var added []*Ban
+	var errs []error
+	for _, offender := range b.source() {
+		if !ValidType(offender.Type) || offender.Identifier == "" ||
+			offender.Violations < b.config.Threshold || offender.ViolationRate < b.config.MinViolationRate {
+			continue
+		}
+
+		ban, err := b.ban(ctx, offender)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ban != nil {
+			added = append(added, ban)
+		}
+	}
+	return added, errors.Join(errs...)
+}
+
+// ban 封禁一个违规者，不需要封禁时返回 nil
+func (b *BanList) ban(ctx context.Context, offender Offender) (*Ban, error) {
+	if existing, err := b.Check(ctx, offender.Type, offender.Identifier); err != nil || existing != nil {
+		return nil, err
+	}
+	if lifted, err := b.store.Lifted(ctx, offender.Type, offender.Identifier); err != nil || lifted {
+		return nil, err
+	}
+
+	now := b.now().UTC()
+	ban := &Ban{
+		Type:          offender.Type,
+		Identifier:    offender.Identifier,
+		Reason:        ReasonRateLimitViolations,
+		Violations:    offender.Violations,
+		ViolationRate: offender.ViolationRate,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(b.config.Duration),
+	}
+	if err := b.store.Add(ctx, ban); err != nil {
+		return nil, fmt.Errorf("failed to ban %s: %w", member(ban.Type, ban.Identifier), err)
+	}
+	if b.config.OnBan != nil {
+		b.config.OnBan(ban)
+	}
+	return ban, nil
+}
+
+// Start 启动后台检查，只有第一次调用生效
+func (b *BanList) Start() {
+	b.startOnce.Do(func() {
+		go b.run()
+	})
+}
+
+// Stop 停止后台检查；从未启动时直接返回
+func (b *BanList) Stop(ctx context.Context) error {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	// 从未启动时没有 goroutine 关闭 done
+	b.startOnce.Do(func() {
+		close(b.done)
+	})
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 按间隔检查违规热点
+func (b *BanList) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.Interval)
+			_, err := b.Evaluate(ctx)
+			cancel()
+			if err != nil && b.config.OnError != nil {
+				b.config.OnError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package banlist
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offenders 返回固定违规者的数据源
+func offenders(list ...Offender) func() []Offender {
+	return func() []Offender {
+		return list
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Add(ctx, &Ban{Type: TypeIP, Identifier: "203.0.113.7", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Add(ctx, &Ban{Type: TypeUser, Identifier: "u1", ExpiresAt: now.Add(time.Minute)}))
+
+	ban, err := store.Get(ctx, TypeIP, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ban.Identifier)
+	_, err = store.Get(ctx, TypeUser, "203.0.113.7")
+	assert.ErrorIs(t, err, ErrNotFound, "IP 和用户分别封禁")
+
+	bans, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, bans, 2)
+	assert.Equal(t, "u1", bans[0].Identifier, "先到期的在前")
+
+	// 到期的记录不再生效
+	now = now.Add(2 * time.Minute)
+	_, err = store.Get(ctx, TypeUser, "u1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	bans, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bans, 1)
+
+	// 解除封禁后进入宽限期
+	assert.ErrorIs(t, store.Lift(ctx, TypeUser, "u1", time.Minute), ErrNotFound)
+	require.NoError(t, store.Lift(ctx, TypeIP, "203.0.113.7", 10*time.Minute))
+	_, err = store.Get(ctx, TypeIP, "203.0.113.7")
+	assert.ErrorIs(t, err, ErrNotFound)
+	lifted, err := store.Lifted(ctx, TypeIP, "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, lifted)
+
+	now = now.Add(11 * time.Minute)
+	lifted, err = store.Lifted(ctx, TypeIP, "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, lifted)
+}
+
+func TestBanList_Evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("只封禁违规次数和比例都达到阈值的对象", func(t *testing.T) {
+		var banned []*Ban
+		list := New(NewMemoryStore(), offenders(
+			Offender{Type: TypeIP, Identifier: "203.0.113.7", Violations: 80, ViolationRate: 90},
+			Offender{Type: TypeIP, Identifier: "203.0.113.8", Violations: 20, ViolationRate: 90},
+			Offender{Type: TypeUser, Identifier: "u1", Violations: 500, ViolationRate: 10},
+			Offender{Type: TypeUser, Identifier: "u2", Violations: 60, ViolationRate: 60},
+		), Config{Threshold: 50, MinViolationRate: 50, Duration: time.Hour, OnBan: func(ban *Ban) {
+			banned = append(banned, ban)
+		}})
+
+		added, err := list.Evaluate(ctx)
+		require.NoError(t, err)
+		require.Len(t, added, 2)
+		assert.Equal(t, added, banned)
+		assert.Equal(t, "203.0.113.7", added[0].Identifier)
+		assert.Equal(t, ReasonRateLimitViolations, added[0].Reason)
+		assert.Equal(t, time.Hour, added[0].ExpiresAt.Sub(added[0].CreatedAt))
+		assert.Equal(t, "u2", added[1].Identifier)
+
+		ban, err := list.Check(ctx, TypeIP, "203.0.113.7")
+		require.NoError(t, err)
+		require.NotNil(t, ban)
+		ban, err = list.Check(ctx, TypeIP, "203.0.113.8")
+		require.NoError(t, err)
+		assert.Nil(t, ban)
+	})
+
+	t.Run("已封禁的对象不延长封禁", func(t *testing.T) {
+		list := New(NewMemoryStore(), offenders(
+			Offender{Type: TypeIP, Identifier: "203.0.113.7", Violations: 80, ViolationRate: 90},
+		), Config{})
+
+		added, err := list.Evaluate(ctx)
+		require.NoError(t, err)
+		require.Len(t, added, 1)
+
+		added, err = list.Evaluate(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, added)
+	})
+
+	t.Run("解除封禁后宽限期内不再自动封禁", func(t *testing.T) {
+		list := New(NewMemoryStore(), offenders(
+			Offender{Type: TypeIP, Identifier: "203.0.113.7", Violations: 80, ViolationRate: 90},
+		), Config{GracePeriod: time.Hour})
+
+		_, err := list.Evaluate(ctx)
+		require.NoError(t, err)
+		require.NoError(t, list.Unban(ctx, TypeIP, "203.0.113.7"))
+		assert.ErrorIs(t, list.Unban(ctx, TypeIP, "203.0.113.7"), ErrNotFound)
+		assert.ErrorIs(t, list.Unban(ctx, "email", "203.0.113.7"), ErrInvalidType)
+
+		added, err := list.Evaluate(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, added)
+		ban, err := list.Check(ctx, TypeIP, "203.0.113.7")
+		require.NoError(t, err)
+		assert.Nil(t, ban)
+	})
+}
+
+func TestBanList_StartStop(t *testing.T) {
+	var mu sync.Mutex
+	var banned []*Ban
+	list := New(NewMemoryStore(), offenders(
+		Offender{Type: TypeIP, Identifier: "203.0.113.7", Violations: 80, ViolationRate: 90},
+	), Config{Interval: 10 * time.Millisecond, OnBan: func(ban *Ban) {
+		mu.Lock()
+		defer mu.Unlock()
+		banned = append(banned, ban)
+	}})
+
+	list.Start()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(banned) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, list.Stop(context.Background()))
+	require.NoError(t, list.Stop(context.Background()), "重复停止")
+
+	// 从未启动时停止不会阻塞
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, New(NewMemoryStore(), offenders(), Config{}).Stop(ctx))
+}
+
+func TestRedisStore(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test:banlist:%d", time.Now().UnixNano())
+	defer func() {
+		keys, _ := client.Keys(context.Background(), prefix+":*").Result()
+		if len(keys) > 0 {
+			client.Del(context.Background(), keys...)
+		}
+	}()
+
+	store := NewRedisStore(client, prefix)
+	now := time.Now().UTC()
+	require.NoError(t, store.Add(ctx, &Ban{Type: TypeIP, Identifier: "2001:db8::1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Add(ctx, &Ban{Type: TypeUser, Identifier: "u1", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}))
+
+	ban, err := store.Get(ctx, TypeIP, "2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ban.Identifier)
+
+	bans, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, bans, 2)
+	assert.Equal(t, "u1", bans[0].Identifier)
+
+	require.NoError(t, store.Lift(ctx, TypeUser, "u1", time.Minute))
+	assert.ErrorIs(t, store.Lift(ctx, TypeUser, "u1", time.Minute), ErrNotFound)
+	_, err = store.Get(ctx, TypeUser, "u1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	lifted, err := store.Lifted(ctx, TypeUser, "u1")
+	require.NoError(t, err)
+	assert.True(t, lifted)
+
+	bans, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bans, 1)
+}
@@ -0,0 +1,161 @@
+package banlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 将封禁记录保存在 Redis 中，供多实例共享
+//
+// 键布局：
+//
+//	<prefix>:bans                          有序集合，成员为 <type>:<identifier>，分数为到期时间（Unix 毫秒），用于列出封禁
+//	<prefix>:ban:<type>:<identifier>       JSON 格式的封禁记录，到期时自动删除
+//	<prefix>:lifted:<type>:<identifier>    解除封禁后的宽限期标记，到期时自动删除
+type RedisStore struct {
+	client   *redis.Client
+	prefix   string
+	indexKey string
+}
+
+// NewRedisStore 创建 Redis 存储，prefix 为键前缀，如 banlist
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client:   client,
+		prefix:   prefix,
+		indexKey: prefix + ":bans",
+	}
+}
+
+// Add 保存封禁记录
+func (s *RedisStore) Add(ctx context.Context, ban *Ban) error {
+	ttl := time.Until(ban.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.banKey(ban.Type, ban.Identifier), data, ttl)
+		pipe.ZAdd(ctx, s.indexKey, redis.Z{
+			Score:  float64(ban.ExpiresAt.UnixMilli()),
+			Member: member(ban.Type, ban.Identifier),
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add ban %s: %w", member(ban.Type, ban.Identifier), err)
+	}
+	return nil
+}
+
+// Get 返回生效中的封禁记录
+func (s *RedisStore) Get(ctx context.Context, banType, identifier string) (*Ban, error) {
+	value, err := s.client.Get(ctx, s.banKey(banType, identifier)).Result()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban %s: %w", member(banType, identifier), err)
+	}
+	return decodeBan(member(banType, identifier), value)
+}
+
+// List 返回所有生效中的封禁记录，顺带从索引中删除已到期的成员
+func (s *RedisStore) List(ctx context.Context) ([]*Ban, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := s.client.ZRemRangeByScore(ctx, s.indexKey, "-inf", now).Err(); err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+	members, err := s.client.ZRange(ctx, s.indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+	if len(members) == 0 {
+		return []*Ban{}, nil
+	}
+
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = s.prefix + ":ban:" + m
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+
+	bans := make([]*Ban, 0, len(values))
+	for i, value := range values {
+		// 记录已到期但索引尚未清理
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		ban, err := decodeBan(members[i], data)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+	sortBans(bans)
+	return bans, nil
+}
+
+// Lift 解除封禁并记录宽限期
+func (s *RedisStore) Lift(ctx context.Context, banType, identifier string, grace time.Duration) error {
+	deleted, err := s.client.Del(ctx, s.banKey(banType, identifier)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to lift ban %s: %w", member(banType, identifier), err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.indexKey, member(banType, identifier))
+		if grace > 0 {
+			pipe.Set(ctx, s.liftedKey(banType, identifier), 1, grace)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lift ban %s: %w", member(banType, identifier), err)
+	}
+	return nil
+}
+
+// Lifted 判断对象是否在宽限期内
+func (s *RedisStore) Lifted(ctx context.Context, banType, identifier string) (bool, error) {
+	exists, err := s.client.Exists(ctx, s.liftedKey(banType, identifier)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check lifted ban %s: %w", member(banType, identifier), err)
+	}
+	return exists > 0, nil
+}
+
+// banKey 返回封禁记录的键
+func (s *RedisStore) banKey(banType, identifier string) string {
+	return s.prefix + ":ban:" + member(banType, identifier)
+}
+
+// liftedKey 返回宽限期标记的键
+func (s *RedisStore) liftedKey(banType, identifier string) string {
+	return s.prefix + ":lifted:" + member(banType, identifier)
+}
+
+// decodeBan 解析 JSON 格式的封禁记录
+func decodeBan(name, value string) (*Ban, error) {
+	var ban Ban
+	if err := json.Unmarshal([]byte(value), &ban); err != nil {
+		return nil, fmt.Errorf("failed to decode ban %s: %w", name, err)
+	}
+	return &ban, nil
+}
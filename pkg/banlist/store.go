@@ -0,0 +1,124 @@
+package banlist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store 封禁记录的存储
+type Store interface {
+	// Add 保存封禁记录，同一对象已有记录时覆盖；记录在 ExpiresAt 之后失效
+	Add(ctx context.Context, ban *Ban) error
+
+	// Get 返回生效中的封禁记录，不存在或已到期时返回 ErrNotFound
+	Get(ctx context.Context, banType, identifier string) (*Ban, error)
+
+	// List 返回所有生效中的封禁记录，按到期时间排序
+	List(ctx context.Context) ([]*Ban, error)
+
+	// Lift 解除封禁，并在 grace 内记录该对象刚被解除封禁；不存在时返回 ErrNotFound
+	Lift(ctx context.Context, banType, identifier string, grace time.Duration) error
+
+	// Lifted 判断对象是否在解除封禁后的宽限期内
+	Lifted(ctx context.Context, banType, identifier string) (bool, error)
+}
+
+// MemoryStore 进程内存储，封禁只对当前实例有效，重启后清空
+type MemoryStore struct {
+	mu     sync.Mutex
+	bans   map[string]*Ban
+	lifted map[string]time.Time // 对象 -> 宽限期结束时间
+	now    func() time.Time
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		bans:   make(map[string]*Ban),
+		lifted: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Add 保存封禁记录
+func (s *MemoryStore) Add(ctx context.Context, ban *Ban) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	s.bans[member(ban.Type, ban.Identifier)] = ban.clone()
+	return nil
+}
+
+// Get 返回生效中的封禁记录
+func (s *MemoryStore) Get(ctx context.Context, banType, identifier string) (*Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := member(banType, identifier)
+	ban, ok := s.bans[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !ban.ExpiresAt.After(s.now()) {
+		delete(s.bans, key)
+		return nil, ErrNotFound
+	}
+	return ban.clone(), nil
+}
+
+// List 返回所有生效中的封禁记录
+func (s *MemoryStore) List(ctx context.Context) ([]*Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	bans := make([]*Ban, 0, len(s.bans))
+	for _, ban := range s.bans {
+		bans = append(bans, ban.clone())
+	}
+	sortBans(bans)
+	return bans, nil
+}
+
+// Lift 解除封禁并记录宽限期
+func (s *MemoryStore) Lift(ctx context.Context, banType, identifier string, grace time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	key := member(banType, identifier)
+	if _, ok := s.bans[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.bans, key)
+	if grace > 0 {
+		s.lifted[key] = s.now().Add(grace)
+	}
+	return nil
+}
+
+// Lifted 判断对象是否在宽限期内
+func (s *MemoryStore) Lifted(ctx context.Context, banType, identifier string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.lifted[member(banType, identifier)]
+	return ok && until.After(s.now()), nil
+}
+
+// pruneLocked 删除到期的封禁记录和宽限期，调用方须持有锁
+func (s *MemoryStore) pruneLocked() {
+	now := s.now()
+	for key, ban := range s.bans {
+		if !ban.ExpiresAt.After(now) {
+			delete(s.bans, key)
+		}
+	}
+	for key, until := range s.lifted {
+		if !until.After(now) {
+			delete(s.lifted, key)
+		}
+	}
+}
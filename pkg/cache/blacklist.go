@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go-server/pkg/auth"
+	"go-server/pkg/clock"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -16,6 +19,11 @@ type BlacklistService struct {
 	cache      Cache
 	jwtManager *auth.JWTManager
 	keyPrefix  string
+	indexTag   string
+	userTag    string
+	batchSize  int
+	filter     *blacklistFilter
+	clock      clock.Clock
 }
 
 // BlacklistConfig 保存黑名单服务的配置
@@ -24,8 +32,14 @@ type BlacklistConfig struct {
 	KeyPrefix string
 	// CleanupInterval 是运行过期令牌清理的频率
 	CleanupInterval time.Duration
-	// BatchSize 是每次清理批次中要处理的令牌数量
+	// BatchSize 是清理和遍历黑名单索引时每批处理的令牌数量
 	BatchSize int
+	// Filter 启用本地布隆过滤器前置检查，大部分未吊销的令牌无需访问缓存；为 nil 时不启用
+	// 过滤器依赖按模式列出键，缓存驱动不支持 CapabilityKeys 时自动停用。
+	// 共享同一缓存的所有实例须同时启用，否则未启用的实例吊销令牌时不会通知其他实例
+	Filter *BlacklistFilterConfig
+	// Clock 判断令牌是否过期和计算黑名单 TTL 使用的时钟，为 nil 时使用系统时钟
+	Clock clock.Clock
 }
 
 // DefaultBlacklistConfig 返回黑名单服务的默认配置
@@ -43,11 +57,54 @@ func NewBlacklistService(cache Cache, jwtManager *auth.JWTManager, config *Black
 		config = DefaultBlacklistConfig()
 	}
 
-	return &BlacklistService{
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBlacklistConfig().BatchSize
+	}
+
+	service := &BlacklistService{
 		cache:      cache,
 		jwtManager: jwtManager,
 		keyPrefix:  config.KeyPrefix,
+		indexTag:   strings.TrimRight(config.KeyPrefix, ":"),
+		userTag:    strings.TrimRight(config.KeyPrefix, ":") + "_users",
+		batchSize:  batchSize,
+		clock:      clock.OrReal(config.Clock),
+	}
+	if config.Filter != nil && service.canListKeys() {
+		service.filter = newBlacklistFilter(cache, config.KeyPrefix, *config.Filter, service.listFilterKeys)
+	}
+	return service
+}
+
+// FilterEnabled 判断是否启用了本地布隆过滤器
+func (b *BlacklistService) FilterEnabled() bool {
+	return b.filter != nil
+}
+
+// FilterRefreshInterval 返回过滤器的同步间隔，未启用过滤器时为 0
+func (b *BlacklistService) FilterRefreshInterval() time.Duration {
+	if b.filter == nil {
+		return 0
+	}
+	return b.filter.config.RefreshInterval
+}
+
+// RefreshFilter 从缓存同步本地布隆过滤器，黑名单版本号未变化时不重建
+// 同步失败时过滤器停用，所有检查回退到缓存查询，直到下一次同步成功
+func (b *BlacklistService) RefreshFilter(ctx context.Context) error {
+	if b.filter == nil {
+		return nil
+	}
+	return b.filter.refresh(ctx)
+}
+
+// FilterStats 返回本地布隆过滤器的统计信息，未启用过滤器时返回 false
+func (b *BlacklistService) FilterStats() (BlacklistFilterStats, bool) {
+	if b.filter == nil {
+		return BlacklistFilterStats{}, false
 	}
+	return b.filter.stats(), true
 }
 
 // AddToBlacklist 将 JWT 令牌添加到黑名单
@@ -60,7 +117,7 @@ func (b *BlacklistService) AddToBlacklist(ctx context.Context, tokenString strin
 	}
 
 	// 计算直到令牌过期的 TTL
-	ttl := time.Until(claims.ExpiresAt.Time)
+	ttl := b.clock.Until(claims.ExpiresAt.Time)
 	if ttl <= 0 {
 		// 令牌已经过期，无需加入黑名单
 		return nil
@@ -69,8 +126,16 @@ func (b *BlacklistService) AddToBlacklist(ctx context.Context, tokenString strin
 	// 为令牌生成唯一键
 	tokenKey := b.generateTokenKey(tokenString)
 
-	// 添加到缓存，TTL 等于令牌的剩余生命周期
-	return b.cache.Set(ctx, tokenKey, "blacklisted", ttl)
+	// 添加到缓存并加入黑名单索引，TTL 等于令牌的剩余生命周期
+	if err := b.cache.SetWithTags(ctx, tokenKey, "blacklisted", ttl, b.indexTag); err != nil {
+		return err
+	}
+
+	if b.filter != nil {
+		b.filter.add(tokenKey)
+		return b.filter.publish(ctx)
+	}
+	return nil
 }
 
 // IsBlacklisted 检查 JWT 令牌是否在黑名单中
@@ -83,67 +148,183 @@ func (b *BlacklistService) IsBlacklisted(ctx context.Context, tokenString string
 	}
 
 	// 检查令牌是否过期
-	if time.Now().After(claims.ExpiresAt.Time) {
+	if b.clock.Now().After(claims.ExpiresAt.Time) {
 		// 令牌已自然过期
 		return false, nil
 	}
 
 	// 检查令牌是否存在于黑名单中
 	tokenKey := b.generateTokenKey(tokenString)
+	var userKey string
+	if claims.UserID != "" && claims.IssuedAt != nil {
+		userKey = b.userRevocationKey(claims.UserID)
+	}
+
+	// 本地过滤器判定不在黑名单时无需访问缓存
+	result := filterBypass
+	if b.filter != nil {
+		result = b.filter.check(tokenKey, userKey)
+		if result == filterAbsent {
+			return false, nil
+		}
+	}
+
 	exists, err := b.cache.Exists(ctx, tokenKey)
 	if err != nil {
 		return false, fmt.Errorf("failed to check blacklist: %w", err)
 	}
 
+	// 检查用户的令牌是否在签发后被整体吊销（如修改密码、删除账户）
+	if !exists && userKey != "" {
+		revokedAt, err := b.userRevokedAt(ctx, userKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to check user token revocation: %w", err)
+		}
+		exists = revokedAt > 0 && claims.IssuedAtMilli() <= revokedAt
+	}
+
+	if result == filterMaybe && !exists {
+		b.filter.recordFalsePositive()
+	}
+
 	return exists, nil
 }
 
+// RevokeUserTokens 吊销用户在此之前签发的全部令牌，用于修改密码、删除账户等场景
+// 吊销时间与令牌的 iat_ms 声明都精确到毫秒，吊销后立即重新登录签发的令牌不受影响
+func (b *BlacklistService) RevokeUserTokens(ctx context.Context, userID string) error {
+	userKey := b.userRevocationKey(userID)
+	revokedAt := strconv.FormatInt(b.clock.Now().UnixMilli(), 10)
+
+	// 保留到吊销前签发的令牌全部过期为止
+	ttl := 24 * time.Hour
+	if b.jwtManager != nil && b.jwtManager.ExpiresIn() > 0 {
+		ttl = b.jwtManager.ExpiresIn()
+	}
+	if b.jwtManager != nil && b.jwtManager.RefreshExpiresIn() > ttl {
+		// 刷新令牌的有效期更长，吊销记录需要保留到刷新令牌全部过期
+		ttl = b.jwtManager.RefreshExpiresIn()
+	}
+
+	if err := b.cache.SetWithTags(ctx, userKey, revokedAt, ttl, b.userTag); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	if b.filter != nil {
+		b.filter.add(userKey)
+		return b.filter.publish(ctx)
+	}
+	return nil
+}
+
+// userRevocationKey 返回记录用户令牌吊销时间的键
+func (b *BlacklistService) userRevocationKey(userID string) string {
+	return b.keyPrefix + "user:" + userID
+}
+
+// legacyRevocationLimit 小于该值的吊销时间是升级前以秒记录的
+const legacyRevocationLimit = 1e12
+
+// userRevokedAt 返回用户令牌的吊销时间（Unix 毫秒），未吊销时为 0
+func (b *BlacklistService) userRevokedAt(ctx context.Context, userKey string) (int64, error) {
+	data, found := b.cache.GetBytes(ctx, userKey)
+	if !found {
+		return 0, nil
+	}
+	revokedAt, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid revocation time %q: %w", data, err)
+	}
+	if revokedAt < legacyRevocationLimit {
+		// 按所在秒的结束计算，与升级前一样吊销同一秒内签发的令牌
+		revokedAt = revokedAt*1000 + 999
+	}
+	return revokedAt, nil
+}
+
 // RemoveFromBlacklist 从黑名单中移除 JWT 令牌
 // 这对于希望重新使用令牌的情况很有用
 func (b *BlacklistService) RemoveFromBlacklist(ctx context.Context, tokenString string) error {
 	tokenKey := b.generateTokenKey(tokenString)
-	return b.cache.Delete(ctx, tokenKey)
+	if err := b.cache.Delete(ctx, tokenKey); err != nil {
+		return err
+	}
+	if index, ok := b.cache.(TagIndex); ok {
+		if err := index.UntagKeys(ctx, b.indexTag, tokenKey); err != nil {
+			return err
+		}
+	}
+
+	// 布隆过滤器不支持删除，递增版本号让各实例重建过滤器，避免已移除的令牌持续误判
+	if b.filter != nil {
+		return b.filter.publish(ctx)
+	}
+	return nil
 }
 
-// CleanupExpiredTokens 从黑名单中移除过期令牌
-// 这是一个维护操作，用于保持黑名单的清洁
+// CleanupExpiredTokens 从黑名单索引中移除已过期的令牌和用户吊销记录
+// 键由缓存按 TTL 自动删除，索引中残留的成员分批检查后移除，使 GetBlacklistSize 保持准确
 func (b *BlacklistService) CleanupExpiredTokens(ctx context.Context) error {
-	// 获取所有黑名单键
-	keys, err := b.cache.Keys(ctx, "*")
-	if err != nil {
-		return fmt.Errorf("failed to get blacklist keys: %w", err)
+	// 不支持索引的驱动（如 memcached）依赖过期时间自动清理
+	index, ok := b.cache.(TagIndex)
+	if !ok {
+		return nil
 	}
 
-	for _, key := range keys {
-		// 检查键是否仍然存在（可能已过期）
-		exists, err := b.cache.Exists(ctx, key)
-		if err != nil {
-			continue // 出错时跳过此键
+	for _, tag := range []string{b.indexTag, b.userTag} {
+		if err := b.pruneIndex(ctx, index, tag); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if !exists {
-			// 键已过期，Redis 自动移除了它
-			continue
+// pruneIndex 分批遍历索引，移除键已不存在的成员
+func (b *BlacklistService) pruneIndex(ctx context.Context, index TagIndex, tag string) error {
+	var cursor uint64
+	for {
+		keys, next, err := index.ScanTag(ctx, tag, cursor, int64(b.batchSize))
+		if err != nil {
+			return fmt.Errorf("failed to scan blacklist index: %w", err)
 		}
 
-		// 可选地检查存储的令牌是否实际过期
-		// 这是额外的安全检查，以防 TTL 未正确设置
-		value, found := b.cache.Get(ctx, key)
-		if !found {
-			continue
+		var expired []string
+		for _, key := range keys {
+			exists, err := b.cache.Exists(ctx, key)
+			if err != nil {
+				continue // 出错时跳过此键，下次清理时重试
+			}
+			if !exists {
+				expired = append(expired, key)
+			}
+		}
+		if err := index.UntagKeys(ctx, tag, expired...); err != nil {
+			return fmt.Errorf("failed to remove expired entries from blacklist index: %w", err)
 		}
 
-		// 如果我们存储了关于令牌的额外元数据，我们可以在这里检查
-		// 目前，我们依赖 Redis TTL 进行自动清理
-		_ = value // 抑制未使用变量警告
+		if next == 0 {
+			return nil
+		}
+		cursor = next
 	}
-
-	return nil
 }
 
 // GetBlacklistSize 返回当前黑名单中的令牌数量
+// 优先读取黑名单索引的大小（可能包含上次清理后才过期的令牌），不会扫描键空间；
+// 缓存驱动不支持索引时退回到按前缀列出键
 func (b *BlacklistService) GetBlacklistSize(ctx context.Context) (int, error) {
-	keys, err := b.cache.Keys(ctx, "*")
+	if index, ok := b.cache.(TagIndex); ok {
+		size, err := index.TagSize(ctx, b.indexTag)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count blacklist index: %w", err)
+		}
+		return int(size), nil
+	}
+
+	if !Supports(b.cache, CapabilityKeys) {
+		return 0, fmt.Errorf("failed to count blacklist: %w", ErrNotSupported)
+	}
+	keys, err := b.cache.Keys(ctx, b.keyPrefix+"*")
 	if err != nil {
 		return 0, fmt.Errorf("failed to get blacklist keys: %w", err)
 	}
@@ -152,9 +333,19 @@ func (b *BlacklistService) GetBlacklistSize(ctx context.Context) (int, error) {
 }
 
 // ClearBlacklist 从黑名单中移除所有令牌
+// 按黑名单索引分批删除被吊销的令牌和用户吊销记录，不影响缓存中的其他键
 // 谨慎使用 - 这将允许所有先前被列入黑名单的令牌再次使用
 func (b *BlacklistService) ClearBlacklist(ctx context.Context) error {
-	return b.cache.Clear(ctx)
+	for _, tag := range []string{b.indexTag, b.userTag} {
+		if err := b.cache.InvalidateTag(ctx, tag); err != nil {
+			return fmt.Errorf("failed to clear blacklist: %w", err)
+		}
+	}
+
+	if b.filter != nil {
+		return b.filter.publish(ctx)
+	}
+	return nil
 }
 
 // ValidateTokenWithBlacklist 是一个便捷方法，结合了 JWT 验证和黑名单检查
@@ -174,14 +365,14 @@ func (b *BlacklistService) ValidateTokenWithBlacklist(ctx context.Context, token
 }
 
 // parseToken 解析 JWT 令牌并返回其声明
-func (b *BlacklistService) parseToken(tokenString string) (*jwt.RegisteredClaims, error) {
+func (b *BlacklistService) parseToken(tokenString string) (*auth.Claims, error) {
 	// 不验证解析以获取过期时间
-	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &auth.Claims{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*auth.Claims)
 	if !ok {
 		return nil, fmt.Errorf("invalid token claims")
 	}
@@ -203,10 +394,7 @@ func (b *BlacklistService) AddMultipleToBlacklist(ctx context.Context, tokens []
 		return nil
 	}
 
-	// 准备批量设置的项目
-	items := make(map[string]interface{})
-	ttls := make(map[string]time.Duration)
-
+	var added []string
 	for _, token := range tokens {
 		claims, err := b.parseToken(token)
 		if err != nil {
@@ -214,66 +402,122 @@ func (b *BlacklistService) AddMultipleToBlacklist(ctx context.Context, tokens []
 			continue
 		}
 
-		ttl := time.Until(claims.ExpiresAt.Time)
+		ttl := b.clock.Until(claims.ExpiresAt.Time)
 		if ttl <= 0 {
 			// 跳过过期令牌
 			continue
 		}
 
+		// 每个令牌使用各自的剩余生命周期，并加入黑名单索引
 		tokenKey := b.generateTokenKey(token)
-		items[tokenKey] = "blacklisted"
-		ttls[tokenKey] = ttl
-	}
-
-	// 由于缓存接口在 SetMultiple 中不支持每个键的 TTL，
-	// 我们需要单独设置它们或找到最小 TTL
-	// 为简单起见，我们将对所有令牌使用最小 TTL
-	var minTTL time.Duration
-	if len(ttls) > 0 {
-		// 找到第一个键以获取其 TTL
-		for key := range items {
-			minTTL = ttls[key]
-			break
-		}
-		for _, ttl := range ttls {
-			if ttl < minTTL {
-				minTTL = ttl
-			}
+		if err := b.cache.SetWithTags(ctx, tokenKey, "blacklisted", ttl, b.indexTag); err != nil {
+			return err
 		}
+		added = append(added, tokenKey)
 	}
 
-	return b.cache.SetMultiple(ctx, items, minTTL)
+	if b.filter != nil && len(added) > 0 {
+		for _, key := range added {
+			b.filter.add(key)
+		}
+		return b.filter.publish(ctx)
+	}
+	return nil
 }
 
-// GetBlacklistedTokensInfo 返回关于被列入黑名单令牌的信息
+// GetBlacklistedTokensInfo 返回关于被列入黑名单令牌的信息，limit 不大于 0 时返回全部
 func (b *BlacklistService) GetBlacklistedTokensInfo(ctx context.Context, limit int) ([]BlacklistedTokenInfo, error) {
-	keys, err := b.cache.Keys(ctx, "*")
+	keys, err := b.listKeys(ctx, b.indexTag, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get blacklist keys: %w", err)
-	}
-
-	if limit > 0 && len(keys) > limit {
-		keys = keys[:limit]
+		return nil, err
 	}
 
 	var infos []BlacklistedTokenInfo
 	for _, key := range keys {
-		// 获取键的 TTL
-		cache := b.cache.(*RedisCache) // 类型断言以使用 Redis 特定方法
-		ttl, err := cache.GetTTL(ctx, key)
-		if err != nil {
+		// 跳过索引中已过期的令牌
+		_, ttl, found := b.cache.GetWithTTL(ctx, key)
+		if !found {
 			continue
 		}
 
 		infos = append(infos, BlacklistedTokenInfo{
 			Key:       key,
-			ExpiresAt: time.Now().Add(ttl),
+			ExpiresAt: b.clock.Now().Add(ttl),
 		})
 	}
 
 	return infos, nil
 }
 
+// canListKeys 判断能否列出黑名单中的键
+func (b *BlacklistService) canListKeys() bool {
+	if _, ok := b.cache.(TagIndex); ok {
+		return true
+	}
+	return Supports(b.cache, CapabilityKeys)
+}
+
+// listFilterKeys 列出过滤器需要的全部键：被吊销的令牌和用户
+func (b *BlacklistService) listFilterKeys(ctx context.Context) ([]string, error) {
+	keys, err := b.listKeys(ctx, b.indexTag, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := b.cache.(TagIndex); !ok {
+		// 按前缀列出的键已包含用户吊销记录
+		return keys, nil
+	}
+
+	users, err := b.listKeys(ctx, b.userTag, 0)
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, users...), nil
+}
+
+// listKeys 列出索引 tag 中的键，limit 不大于 0 时返回全部
+// 优先分批遍历索引，缓存驱动不支持索引时退回到按前缀列出键
+func (b *BlacklistService) listKeys(ctx context.Context, tag string, limit int) ([]string, error) {
+	index, ok := b.cache.(TagIndex)
+	if !ok {
+		if !Supports(b.cache, CapabilityKeys) {
+			return nil, fmt.Errorf("failed to list blacklist keys: %w", ErrNotSupported)
+		}
+		keys, err := b.cache.Keys(ctx, b.keyPrefix+"*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blacklist keys: %w", err)
+		}
+		if limit > 0 && len(keys) > limit {
+			keys = keys[:limit]
+		}
+		return keys, nil
+	}
+
+	var keys []string
+	seen := make(map[string]struct{})
+	var cursor uint64
+	for {
+		batch, next, err := index.ScanTag(ctx, tag, cursor, int64(b.batchSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blacklist index: %w", err)
+		}
+		for _, key := range batch {
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+			if limit > 0 && len(keys) >= limit {
+				return keys, nil
+			}
+		}
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
 // BlacklistedTokenInfo 保存关于被列入黑名单令牌的信息
 type BlacklistedTokenInfo struct {
 	Key       string
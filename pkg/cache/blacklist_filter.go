@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BlacklistFilterConfig 黑名单本地布隆过滤器配置
+type BlacklistFilterConfig struct {
+	// ExpectedTokens 预期的黑名单令牌数，用于计算过滤器大小；实际令牌更多时按实际数量重建
+	ExpectedTokens int
+	// FalsePositiveRate 期望的误判率，误判的令牌会回退到缓存查询
+	FalsePositiveRate float64
+	// RefreshInterval 从缓存同步其他实例新增黑名单令牌的间隔，也是其他实例吊销令牌生效的最大延迟
+	RefreshInterval time.Duration
+}
+
+// DefaultBlacklistFilterConfig 返回黑名单过滤器的默认配置
+func DefaultBlacklistFilterConfig() *BlacklistFilterConfig {
+	return &BlacklistFilterConfig{
+		ExpectedTokens:    100000,
+		FalsePositiveRate: 0.001,
+		RefreshInterval:   5 * time.Second,
+	}
+}
+
+// BlacklistFilterStats 黑名单过滤器的统计信息
+type BlacklistFilterStats struct {
+	Ready             bool       `json:"ready"`                  // 过滤器是否已从缓存加载，未就绪时所有检查都查询缓存
+	Tokens            int        `json:"tokens"`                 // 过滤器中的令牌数
+	Bits              uint64     `json:"bits"`                   // 过滤器位数
+	HashFunctions     uint64     `json:"hash_functions"`         // 哈希函数个数
+	Version           int64      `json:"version"`                // 最近同步的黑名单版本号
+	LastRefresh       *time.Time `json:"last_refresh,omitempty"` // 最近一次重建时间
+	Rebuilds          uint64     `json:"rebuilds"`               // 重建次数
+	RefreshErrors     uint64     `json:"refresh_errors"`         // 同步失败次数
+	Checks            uint64     `json:"checks"`                 // 经过过滤器的检查次数
+	Skipped           uint64     `json:"skipped"`                // 过滤器判定不在黑名单、跳过缓存查询的次数
+	Lookups           uint64     `json:"lookups"`                // 查询缓存的次数（过滤器判定可能存在或尚未就绪）
+	FalsePositives    uint64     `json:"false_positives"`        // 过滤器判定可能存在但缓存中不存在的次数
+	FalsePositiveRate float64    `json:"false_positive_rate"`    // 观测到的误判率：误判次数 / 不在黑名单的检查次数
+}
+
+// filterResult 过滤器的判定结果
+type filterResult int
+
+const (
+	filterBypass filterResult = iota // 过滤器未就绪，需要查询缓存
+	filterAbsent                     // 一定不在黑名单
+	filterMaybe                      // 可能在黑名单，需要查询缓存确认
+)
+
+// blacklistFilter 黑名单的本地前置过滤器
+//
+// 本实例加入的令牌立即写入过滤器；其他实例加入令牌时递增缓存中的版本号，
+// 本实例定期比对版本号，变化时列出全部黑名单键重建过滤器。
+// 先读取版本号再列出键，保证重建期间其他实例新增的令牌最晚在下一次同步时可见
+type blacklistFilter struct {
+	cache      Cache
+	versionKey string
+	config     BlacklistFilterConfig
+	listKeys   func(ctx context.Context) ([]string, error)
+
+	mu          sync.Mutex
+	bloom       *BloomFilter
+	ready       bool
+	version     int64
+	lastRefresh time.Time
+	rebuilding  bool
+	pending     []string // 重建期间本实例加入的键，重建完成后补入新过滤器
+
+	checks         atomic.Uint64
+	skipped        atomic.Uint64
+	lookups        atomic.Uint64
+	falsePositives atomic.Uint64
+	rebuilds       atomic.Uint64
+	refreshErrors  atomic.Uint64
+}
+
+// newBlacklistFilter 创建黑名单过滤器，首次 refresh 成功前所有检查都回退到缓存
+// listKeys 列出全部黑名单键，用于重建过滤器
+func newBlacklistFilter(cache Cache, keyPrefix string, config BlacklistFilterConfig, listKeys func(ctx context.Context) ([]string, error)) *blacklistFilter {
+	defaults := DefaultBlacklistFilterConfig()
+	if config.ExpectedTokens <= 0 {
+		config.ExpectedTokens = defaults.ExpectedTokens
+	}
+	if config.FalsePositiveRate <= 0 || config.FalsePositiveRate >= 1 {
+		config.FalsePositiveRate = defaults.FalsePositiveRate
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaults.RefreshInterval
+	}
+
+	return &blacklistFilter{
+		cache:      cache,
+		versionKey: strings.TrimRight(keyPrefix, ":") + "_version",
+		config:     config,
+		listKeys:   listKeys,
+	}
+}
+
+// check 查询过滤器，全部键都一定不存在时返回 filterAbsent，空键忽略
+func (f *blacklistFilter) check(keys ...string) filterResult {
+	f.checks.Add(1)
+
+	f.mu.Lock()
+	bloom, ready := f.bloom, f.ready
+	f.mu.Unlock()
+
+	if !ready {
+		f.lookups.Add(1)
+		return filterBypass
+	}
+	for _, key := range keys {
+		if key != "" && bloom.Test(key) {
+			f.lookups.Add(1)
+			return filterMaybe
+		}
+	}
+	f.skipped.Add(1)
+	return filterAbsent
+}
+
+// recordFalsePositive 记录一次误判
+func (f *blacklistFilter) recordFalsePositive() {
+	f.falsePositives.Add(1)
+}
+
+// add 将本实例加入的键写入过滤器
+func (f *blacklistFilter) add(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.bloom != nil {
+		f.bloom.Add(key)
+	}
+	if f.rebuilding {
+		f.pending = append(f.pending, key)
+	}
+}
+
+// publish 递增缓存中的版本号，通知其他实例重建过滤器
+func (f *blacklistFilter) publish(ctx context.Context) error {
+	if _, err := f.cache.Increment(ctx, f.versionKey, 1); err != nil {
+		return fmt.Errorf("failed to publish blacklist version: %w", err)
+	}
+	return nil
+}
+
+// refresh 版本号变化或过滤器未就绪时从缓存重建过滤器
+func (f *blacklistFilter) refresh(ctx context.Context) error {
+	version, err := f.currentVersion(ctx)
+	if err != nil {
+		return f.fail(err)
+	}
+
+	f.mu.Lock()
+	if (f.ready && version == f.version) || f.rebuilding {
+		f.mu.Unlock()
+		return nil
+	}
+	f.rebuilding = true
+	f.pending = nil
+	f.mu.Unlock()
+
+	keys, err := f.listKeys(ctx)
+	if err != nil {
+		f.mu.Lock()
+		f.rebuilding = false
+		f.pending = nil
+		f.mu.Unlock()
+		return f.fail(err)
+	}
+
+	bloom := NewBloomFilter(max(len(keys), f.config.ExpectedTokens), f.config.FalsePositiveRate)
+	for _, key := range keys {
+		bloom.Add(key)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range f.pending {
+		bloom.Add(key)
+	}
+	f.bloom = bloom
+	f.version = version
+	f.ready = true
+	f.lastRefresh = time.Now()
+	f.rebuilding = false
+	f.pending = nil
+	f.rebuilds.Add(1)
+	return nil
+}
+
+// fail 同步失败时停用过滤器，避免长期使用过期数据放行其他实例已吊销的令牌
+func (f *blacklistFilter) fail(err error) error {
+	f.refreshErrors.Add(1)
+	f.mu.Lock()
+	f.ready = false
+	f.mu.Unlock()
+	return err
+}
+
+// currentVersion 读取缓存中的版本号，不存在时为 0
+func (f *blacklistFilter) currentVersion(ctx context.Context) (int64, error) {
+	exists, err := f.cache.Exists(ctx, f.versionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read blacklist version: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	data, found := f.cache.GetBytes(ctx, f.versionKey)
+	if !found {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid blacklist version %q: %w", data, err)
+	}
+	return version, nil
+}
+
+// stats 返回统计信息
+func (f *blacklistFilter) stats() BlacklistFilterStats {
+	stats := BlacklistFilterStats{
+		Rebuilds:       f.rebuilds.Load(),
+		RefreshErrors:  f.refreshErrors.Load(),
+		Checks:         f.checks.Load(),
+		Skipped:        f.skipped.Load(),
+		Lookups:        f.lookups.Load(),
+		FalsePositives: f.falsePositives.Load(),
+	}
+	if negatives := stats.Skipped + stats.FalsePositives; negatives > 0 {
+		stats.FalsePositiveRate = float64(stats.FalsePositives) / float64(negatives)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats.Ready = f.ready
+	stats.Version = f.version
+	if f.bloom != nil {
+		stats.Tokens = f.bloom.Len()
+		stats.Bits = f.bloom.Bits()
+		stats.HashFunctions = f.bloom.HashFunctions()
+	}
+	if !f.lastRefresh.IsZero() {
+		lastRefresh := f.lastRefresh
+		stats.LastRefresh = &lastRefresh
+	}
+	return stats
+}
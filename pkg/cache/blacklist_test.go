@@ -2,13 +2,18 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"go-server/pkg/auth"
+	"go-server/pkg/clock"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -17,8 +22,11 @@ import (
 
 // MockCache implements the Cache interface for testing
 type MockCache struct {
-	data map[string]mockCacheItem
-	mu   sync.RWMutex
+	data  map[string]mockCacheItem
+	tags  map[string]map[string]uint64 // tag -> member -> insertion sequence
+	seq   uint64
+	mu    sync.RWMutex
+	clock clock.Clock
 }
 
 type mockCacheItem struct {
@@ -27,8 +35,14 @@ type mockCacheItem struct {
 }
 
 func NewMockCache() *MockCache {
+	return NewMockCacheWithClock(clock.Real)
+}
+
+// NewMockCacheWithClock 创建使用指定时钟判断过期的模拟缓存
+func NewMockCacheWithClock(clk clock.Clock) *MockCache {
 	return &MockCache{
-		data: make(map[string]mockCacheItem),
+		data:  make(map[string]mockCacheItem),
+		clock: clk,
 	}
 }
 
@@ -42,13 +56,34 @@ func (m *MockCache) Get(ctx context.Context, key string) (interface{}, bool) {
 	}
 
 	// Check if expired
-	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+	if !item.expiresAt.IsZero() && m.clock.Now().After(item.expiresAt) {
 		return nil, false
 	}
 
 	return item.value, true
 }
 
+func (m *MockCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	value, exists := m.Get(ctx, key)
+	if !exists {
+		return nil, false
+	}
+	return mockCacheBytes(value), true
+}
+
+// mockCacheBytes 按 RedisCache.Set 的规则将值转换为存储的字节
+func mockCacheBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	default:
+		data, _ := json.Marshal(v)
+		return data
+	}
+}
+
 func (m *MockCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -59,13 +94,13 @@ func (m *MockCache) GetWithTTL(ctx context.Context, key string) (interface{}, ti
 	}
 
 	// Check if expired
-	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+	if !item.expiresAt.IsZero() && m.clock.Now().After(item.expiresAt) {
 		return nil, 0, false
 	}
 
 	var ttl time.Duration
 	if !item.expiresAt.IsZero() {
-		ttl = time.Until(item.expiresAt)
+		ttl = m.clock.Until(item.expiresAt)
 		if ttl < 0 {
 			ttl = 0
 		}
@@ -80,7 +115,7 @@ func (m *MockCache) Set(ctx context.Context, key string, value interface{}, ttl
 
 	var expiresAt time.Time
 	if ttl > 0 {
-		expiresAt = time.Now().Add(ttl)
+		expiresAt = m.clock.Now().Add(ttl)
 	}
 
 	m.data[key] = mockCacheItem{
@@ -112,6 +147,75 @@ func (m *MockCache) DeleteMultiple(ctx context.Context, keys []string) error {
 	return nil
 }
 
+func (m *MockCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := m.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tags == nil {
+		m.tags = make(map[string]map[string]uint64)
+	}
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]uint64)
+		}
+		if _, ok := m.tags[tag][key]; !ok {
+			m.seq++
+			m.tags[tag][key] = m.seq
+		}
+	}
+	return nil
+}
+
+func (m *MockCache) InvalidateTag(ctx context.Context, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.tags[tag] {
+		delete(m.data, key)
+	}
+	delete(m.tags, tag)
+	return nil
+}
+
+func (m *MockCache) TagSize(ctx context.Context, tag string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.tags[tag])), nil
+}
+
+func (m *MockCache) ScanTag(ctx context.Context, tag string, cursor uint64, count int64) ([]string, uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// The cursor is the insertion sequence of the last returned member, so like SSCAN
+	// members present for the whole scan are returned even if others are removed meanwhile
+	keys := make([]string, 0, len(m.tags[tag]))
+	for key, seq := range m.tags[tag] {
+		if seq > cursor {
+			keys = append(keys, key)
+		}
+	}
+	members := m.tags[tag]
+	sort.Slice(keys, func(i, j int) bool { return members[keys[i]] < members[keys[j]] })
+
+	if int64(len(keys)) <= count {
+		return keys, 0, nil
+	}
+	keys = keys[:count]
+	return keys, members[keys[len(keys)-1]], nil
+}
+
+func (m *MockCache) UntagKeys(ctx context.Context, tag string, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.tags[tag], key)
+	}
+	return nil
+}
+
 func (m *MockCache) Exists(ctx context.Context, key string) (bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -123,7 +227,7 @@ func (m *MockCache) Exists(ctx context.Context, key string) (bool, error) {
 
 	// Check if expired
 	item := m.data[key]
-	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+	if !item.expiresAt.IsZero() && m.clock.Now().After(item.expiresAt) {
 		return false, nil
 	}
 
@@ -143,8 +247,8 @@ func (m *MockCache) Keys(ctx context.Context, pattern string) ([]string, error)
 
 	var keys []string
 	for key := range m.data {
-		// Simple pattern matching - only support "*" for now
-		if pattern == "*" {
+		// Simple pattern matching - only support "*" and "prefix*"
+		if pattern == "*" || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(key, strings.TrimSuffix(pattern, "*"))) {
 			keys = append(keys, key)
 		}
 	}
@@ -203,7 +307,7 @@ func (m *MockCache) GetStats(ctx context.Context) (map[string]interface{}, error
 	// Count non-expired items
 	activeItems := 0
 	for _, item := range m.data {
-		if item.expiresAt.IsZero() || time.Now().Before(item.expiresAt) {
+		if item.expiresAt.IsZero() || m.clock.Now().Before(item.expiresAt) {
 			activeItems++
 		}
 	}
@@ -568,6 +672,13 @@ func (f *FailingMockCache) Set(ctx context.Context, key string, value interface{
 	return f.MockCache.Set(ctx, key, value, ttl)
 }
 
+func (f *FailingMockCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if f.shouldFailSet {
+		return errors.New("cache set operation failed")
+	}
+	return f.MockCache.SetWithTags(ctx, key, value, ttl, tags...)
+}
+
 func (f *FailingMockCache) Exists(ctx context.Context, key string) (bool, error) {
 	if f.shouldFailExists {
 		return false, errors.New("cache exists operation failed")
@@ -1104,9 +1215,12 @@ func TestBlacklistService_RealWorldScenarios(t *testing.T) {
 	})
 
 	t.Run("Token Expiration Cleanup Scenario", func(t *testing.T) {
-		mockCache := NewMockCache()
+		clk := clock.NewFake(time.Now())
+		mockCache := NewMockCacheWithClock(clk)
 		jwtManager := auth.NewJWTManager("test-secret", 24)
-		service := NewBlacklistService(mockCache, jwtManager, nil)
+		config := DefaultBlacklistConfig()
+		config.Clock = clk
+		service := NewBlacklistService(mockCache, jwtManager, config)
 
 		ctx := context.Background()
 
@@ -1130,8 +1244,8 @@ func TestBlacklistService_RealWorldScenarios(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, blacklisted)
 
-		// Wait for short-lived token to expire
-		time.Sleep(150 * time.Millisecond)
+		// Advance past the short-lived token's expiry
+		clk.Advance(150 * time.Millisecond)
 
 		// Short-lived token should no longer be blacklisted (expired)
 		blacklisted, err = service.IsBlacklisted(ctx, shortLivedToken)
@@ -1148,3 +1262,223 @@ func TestBlacklistService_RealWorldScenarios(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestBlacklistService_Filter(t *testing.T) {
+	ctx := context.Background()
+	mockCache := NewMockCache()
+	jwtManager := auth.NewJWTManager("test-secret", 24)
+	config := DefaultBlacklistConfig()
+	config.Filter = &BlacklistFilterConfig{ExpectedTokens: 1000, FalsePositiveRate: 0.001, RefreshInterval: time.Second}
+
+	service := NewBlacklistService(mockCache, jwtManager, config)
+	peer := NewBlacklistService(mockCache, jwtManager, config)
+	require.True(t, service.FilterEnabled())
+	assert.Equal(t, time.Second, service.FilterRefreshInterval())
+
+	revoked := createTestToken(jwtManager, "user1", "alice", "alice@example.com", time.Hour)
+	valid := createTestToken(jwtManager, "user2", "bob", "bob@example.com", time.Hour)
+
+	// Until the first refresh every check falls back to the cache
+	require.NoError(t, service.AddToBlacklist(ctx, revoked))
+	blacklisted, err := service.IsBlacklisted(ctx, valid)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+	stats, ok := service.FilterStats()
+	require.True(t, ok)
+	assert.False(t, stats.Ready)
+	assert.Equal(t, uint64(1), stats.Lookups)
+
+	require.NoError(t, service.RefreshFilter(ctx))
+	require.NoError(t, peer.RefreshFilter(ctx))
+
+	blacklisted, err = service.IsBlacklisted(ctx, revoked)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+	blacklisted, err = service.IsBlacklisted(ctx, valid)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	stats, _ = service.FilterStats()
+	assert.True(t, stats.Ready)
+	assert.Equal(t, 1, stats.Tokens)
+	assert.Equal(t, uint64(1), stats.Skipped)
+
+	// Tokens revoked by another instance become visible after the next refresh
+	other := createTestToken(jwtManager, "user3", "carol", "carol@example.com", time.Hour)
+	require.NoError(t, peer.AddToBlacklist(ctx, other))
+	require.NoError(t, service.RefreshFilter(ctx))
+	blacklisted, err = service.IsBlacklisted(ctx, other)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+
+	// Removed tokens stay in the filter until it is rebuilt and count as false positives
+	require.NoError(t, service.RemoveFromBlacklist(ctx, revoked))
+	blacklisted, err = service.IsBlacklisted(ctx, revoked)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+	stats, _ = service.FilterStats()
+	assert.Equal(t, uint64(1), stats.FalsePositives)
+	assert.Greater(t, stats.FalsePositiveRate, 0.0)
+
+	require.NoError(t, service.RefreshFilter(ctx))
+	stats, _ = service.FilterStats()
+	assert.Equal(t, 1, stats.Tokens)
+	assert.Equal(t, uint64(3), stats.Rebuilds)
+}
+
+func TestBlacklistService_FilterDisabled(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret", 24)
+	service := NewBlacklistService(NewMockCache(), jwtManager, nil)
+
+	assert.False(t, service.FilterEnabled())
+	assert.NoError(t, service.RefreshFilter(context.Background()))
+	_, ok := service.FilterStats()
+	assert.False(t, ok)
+}
+
+func TestBloomFilter(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add(fmt.Sprintf("member-%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, filter.Test(fmt.Sprintf("member-%d", i)))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.Test(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 300)
+	assert.Equal(t, 1000, filter.Len())
+	assert.Greater(t, filter.HashFunctions(), uint64(1))
+}
+
+func TestBlacklistService_Index(t *testing.T) {
+	ctx := context.Background()
+	mockCache := NewMockCache()
+	jwtManager := auth.NewJWTManager("test-secret", 24)
+	config := DefaultBlacklistConfig()
+	config.BatchSize = 2
+	service := NewBlacklistService(mockCache, jwtManager, config)
+
+	// Unrelated keys are neither counted nor cleared
+	require.NoError(t, mockCache.Set(ctx, "user:1", "alice", time.Hour))
+
+	tokens := make([]string, 5)
+	for i := range tokens {
+		tokens[i] = createTestToken(jwtManager, fmt.Sprintf("user%d", i), "user", "user@example.com", time.Hour)
+	}
+	require.NoError(t, service.AddToBlacklist(ctx, tokens[0]))
+	require.NoError(t, service.AddMultipleToBlacklist(ctx, tokens[1:]))
+
+	size, err := service.GetBlacklistSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, size)
+
+	infos, err := service.GetBlacklistedTokensInfo(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, infos, 3)
+
+	// Expired tokens stay in the index until cleanup removes them
+	require.NoError(t, mockCache.Delete(ctx, service.generateTokenKey(tokens[1])))
+	require.NoError(t, mockCache.Delete(ctx, service.generateTokenKey(tokens[2])))
+	size, err = service.GetBlacklistSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, size)
+
+	require.NoError(t, service.CleanupExpiredTokens(ctx))
+	size, err = service.GetBlacklistSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, size)
+
+	require.NoError(t, service.RemoveFromBlacklist(ctx, tokens[3]))
+	size, err = service.GetBlacklistSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, size)
+
+	require.NoError(t, service.ClearBlacklist(ctx))
+	size, err = service.GetBlacklistSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+	blacklisted, err := service.IsBlacklisted(ctx, tokens[4])
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	_, found := mockCache.Get(ctx, "user:1")
+	assert.True(t, found)
+}
+
+func TestBlacklistService_RevokeUserTokens(t *testing.T) {
+	ctx := context.Background()
+	mockCache := NewMockCache()
+	jwtManager := auth.NewJWTManager("test-secret", 24)
+	config := DefaultBlacklistConfig()
+	config.Filter = DefaultBlacklistFilterConfig()
+	service := NewBlacklistService(mockCache, jwtManager, config)
+	require.NoError(t, service.RefreshFilter(ctx))
+
+	issued, err := jwtManager.GenerateToken("user1", "alice", "alice@example.com")
+	require.NoError(t, err)
+	other, err := jwtManager.GenerateToken("user2", "bob", "bob@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeUserTokens(ctx, "user1"))
+
+	blacklisted, err := service.IsBlacklisted(ctx, issued)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+	blacklisted, err = service.IsBlacklisted(ctx, other)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	// A token issued right after the revocation, such as when the user logs in again
+	// after changing the password, is accepted even within the same second
+	time.Sleep(2 * time.Millisecond)
+	relogin, err := jwtManager.GenerateToken("user1", "alice", "alice@example.com")
+	require.NoError(t, err)
+	blacklisted, err = service.IsBlacklisted(ctx, relogin)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	// Tokens without iat_ms are compared by the start of their issue second
+	claims := jwt.MapClaims{
+		"user_id": "user1",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iat":     time.Now().Add(2 * time.Second).Unix(),
+	}
+	later, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	blacklisted, err = service.IsBlacklisted(ctx, later)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	// The revocation survives a filter rebuild and is cleared with the blacklist
+	peer := NewBlacklistService(mockCache, jwtManager, config)
+	require.NoError(t, peer.RefreshFilter(ctx))
+	blacklisted, err = peer.IsBlacklisted(ctx, issued)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+
+	require.NoError(t, service.ClearBlacklist(ctx))
+	blacklisted, err = service.IsBlacklisted(ctx, issued)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	// Revocations recorded in seconds before the upgrade still revoke the whole second
+	second := time.Now().Truncate(time.Second)
+	legacy := jwt.MapClaims{
+		"user_id": "user3",
+		"exp":     second.Add(time.Hour).Unix(),
+		"iat":     second.Unix(),
+		"iat_ms":  second.Add(999 * time.Millisecond).UnixMilli(),
+	}
+	legacyToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, legacy).SignedString([]byte("test-secret"))
+	require.NoError(t, mockCache.SetWithTags(ctx, service.userRevocationKey("user3"),
+		strconv.FormatInt(second.Unix(), 10), time.Hour, service.userTag))
+	require.NoError(t, service.RefreshFilter(ctx))
+	blacklisted, err = service.IsBlacklisted(ctx, legacyToken)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+}
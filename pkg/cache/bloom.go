@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// BloomFilter 线程安全的布隆过滤器
+//
+// Test 返回 false 时元素一定不存在，返回 true 时元素可能存在（存在误判），不支持删除元素
+type BloomFilter struct {
+	mu     sync.RWMutex
+	bits   []uint64
+	m      uint64 // 位数
+	k      uint64 // 哈希函数个数
+	length int    // 已添加的元素数
+}
+
+// NewBloomFilter 按预期元素数和期望误判率创建布隆过滤器
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/n*math.Ln2))
+
+	words := (uint64(m) + 63) / 64
+	return &BloomFilter{
+		bits: make([]uint64, words),
+		m:    words * 64,
+		k:    uint64(k),
+	}
+}
+
+// Add 添加元素
+func (f *BloomFilter) Add(item string) {
+	h1, h2 := bloomHash(item)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+	f.length++
+}
+
+// Test 判断元素是否可能存在
+func (f *BloomFilter) Test(item string) bool {
+	h1, h2 := bloomHash(item)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len 返回已添加的元素数（重复添加会重复计数）
+func (f *BloomFilter) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.length
+}
+
+// Bits 返回过滤器的位数
+func (f *BloomFilter) Bits() uint64 {
+	return f.m
+}
+
+// HashFunctions 返回哈希函数个数
+func (f *BloomFilter) HashFunctions() uint64 {
+	return f.k
+}
+
+// bloomHash 用 128 位 FNV-1a 生成两个基础哈希，按双重哈希法派生 k 个位置
+func bloomHash(item string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(item))
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotSupported 缓存驱动不支持该操作，调用方可用 errors.Is 判断并降级处理
+var ErrNotSupported = errors.New("cache: operation not supported by this driver")
+
+// Capability 缓存驱动支持的可选能力，可按位组合
+type Capability uint32
+
+const (
+	// CapabilityKeys Keys 支持按模式列出键
+	CapabilityKeys Capability = 1 << iota
+
+	// CapabilityTTL GetWithTTL 返回键的剩余生存时间；不支持时剩余时间始终为 0
+	CapabilityTTL
+
+	// CapabilitySignedCounters Increment/Decrement 支持负数结果；不支持时计数最小为 0
+	CapabilitySignedCounters
+
+	// CapabilityScopedClear Clear 只删除本实例键前缀下的键；不支持时会清空整个缓存服务器
+	CapabilityScopedClear
+
+	// AllCapabilities 全部能力，未声明能力的驱动视为全部支持
+	AllCapabilities = CapabilityKeys | CapabilityTTL | CapabilitySignedCounters | CapabilityScopedClear
+)
+
+// capabilityNames 能力名称，用于日志和统计输出
+var capabilityNames = []struct {
+	capability Capability
+	name       string
+}{
+	{CapabilityKeys, "keys"},
+	{CapabilityTTL, "ttl"},
+	{CapabilitySignedCounters, "signed_counters"},
+	{CapabilityScopedClear, "scoped_clear"},
+}
+
+// Has 判断是否包含给定的全部能力
+func (c Capability) Has(capability Capability) bool {
+	return c&capability == capability
+}
+
+// Names 返回包含的能力名称
+func (c Capability) Names() []string {
+	names := make([]string, 0, len(capabilityNames))
+	for _, entry := range capabilityNames {
+		if c.Has(entry.capability) {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// String 返回以逗号分隔的能力名称
+func (c Capability) String() string {
+	return strings.Join(c.Names(), ",")
+}
+
+// CapabilityProvider 声明自身能力的缓存驱动
+type CapabilityProvider interface {
+	Capabilities() Capability
+}
+
+// CapabilitiesOf 返回缓存驱动的能力，未实现 CapabilityProvider 的驱动视为支持全部能力
+func CapabilitiesOf(c Cache) Capability {
+	if provider, ok := c.(CapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+	return AllCapabilities
+}
+
+// Supports 判断缓存驱动是否支持给定能力
+func Supports(c Cache, capability Capability) bool {
+	return CapabilitiesOf(c).Has(capability)
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	ugorji "github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 缓存值的序列化方式
+//
+// 通过 Codec 写入的值是编码后的字节，需使用 GetBytes 读取并用同一 Codec 解码，
+// Get 的 JSON 解析会将数字变为 float64、结构体变为 map，无法还原 Go 类型
+type Codec interface {
+	// Name 返回编码名称，用于配置和日志
+	Name() string
+
+	// Marshal 将值编码为字节
+	Marshal(value interface{}) ([]byte, error)
+
+	// Unmarshal 将字节解码到 target 指向的值
+	Unmarshal(data []byte, target interface{}) error
+}
+
+// 内置编码名称
+const (
+	CodecJSON     = "json"
+	CodecGob      = "gob"
+	CodecMsgpack  = "msgpack"
+	CodecProtobuf = "protobuf"
+)
+
+// JSONCodec 使用 encoding/json 编码，可读性好，可被其他语言的服务读取
+type JSONCodec struct{}
+
+// Name 返回编码名称
+func (JSONCodec) Name() string { return CodecJSON }
+
+// Marshal 将值编码为 JSON
+func (JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal 将 JSON 解码到 target
+func (JSONCodec) Unmarshal(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+// GobCodec 使用 encoding/gob 编码，体积更小且保留 Go 类型，只能被 Go 服务读取
+// 注意 gob 会编码所有导出字段，包括带 json:"-" 标签的字段
+type GobCodec struct{}
+
+// Name 返回编码名称
+func (GobCodec) Name() string { return CodecGob }
+
+// Marshal 将值编码为 gob
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 将 gob 解码到 target
+func (GobCodec) Unmarshal(data []byte, target interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
+
+// msgpackHandle MessagePack 编码选项，首次使用后不可修改，可并发使用
+var msgpackHandle = func() *ugorji.MsgpackHandle {
+	h := &ugorji.MsgpackHandle{}
+	h.WriteExt = true // time.Time 使用 MessagePack 时间戳扩展类型
+	h.RawToString = true
+	return h
+}()
+
+// MsgpackCodec 使用 MessagePack 编码，体积小于 JSON 且保留整数和时间类型，可被其他语言的服务读取
+// 字段名和忽略规则与 JSON 相同（读取 json 标签）
+type MsgpackCodec struct{}
+
+// Name 返回编码名称
+func (MsgpackCodec) Name() string { return CodecMsgpack }
+
+// Marshal 将值编码为 MessagePack
+func (MsgpackCodec) Marshal(value interface{}) ([]byte, error) {
+	var data []byte
+	if err := ugorji.NewEncoderBytes(&data, msgpackHandle).Encode(value); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Unmarshal 将 MessagePack 解码到 target
+func (MsgpackCodec) Unmarshal(data []byte, target interface{}) error {
+	return ugorji.NewDecoderBytes(data, msgpackHandle).Decode(target)
+}
+
+// ProtobufCodec 使用 protobuf 二进制编码，只支持实现 proto.Message 的值
+type ProtobufCodec struct{}
+
+// Name 返回编码名称
+func (ProtobufCodec) Name() string { return CodecProtobuf }
+
+// Marshal 将 proto.Message 编码为二进制
+func (ProtobufCodec) Marshal(value interface{}) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message: %w", value, ErrNotSupported)
+	}
+	return proto.Marshal(message)
+}
+
+// Unmarshal 将二进制解码到 proto.Message
+func (ProtobufCodec) Unmarshal(data []byte, target interface{}) error {
+	message, ok := target.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T is not a proto.Message: %w", target, ErrNotSupported)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		CodecJSON:     JSONCodec{},
+		CodecGob:      GobCodec{},
+		CodecMsgpack:  MsgpackCodec{},
+		CodecProtobuf: ProtobufCodec{},
+	}
+)
+
+// RegisterCodec 注册编码，可用于接入 CBOR 等第三方编码，同名编码会被替换
+func RegisterCodec(codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// LookupCodec 按名称查找已注册的编码
+func LookupCodec(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown codec %q", name)
+	}
+	return codec, nil
+}
+
+// Codecs 返回已注册的编码名称
+func Codecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
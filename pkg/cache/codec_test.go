@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecTestValue struct {
+	ID        string
+	Count     int64
+	Tags      []string
+	UpdatedAt time.Time
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	value := codecTestValue{
+		ID:        "user-1",
+		Count:     1 << 60, // beyond float64 precision
+		Tags:      []string{"a", "b"},
+		UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	for _, codec := range []Codec{JSONCodec{}, GobCodec{}, MsgpackCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			data, err := codec.Marshal(value)
+			require.NoError(t, err)
+
+			var decoded codecTestValue
+			require.NoError(t, codec.Unmarshal(data, &decoded))
+			assert.Equal(t, value.ID, decoded.ID)
+			assert.Equal(t, value.Count, decoded.Count)
+			assert.Equal(t, value.Tags, decoded.Tags)
+			assert.True(t, value.UpdatedAt.Equal(decoded.UpdatedAt))
+		})
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	codec := ProtobufCodec{}
+
+	data, err := codec.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	decoded := &wrapperspb.StringValue{}
+	require.NoError(t, codec.Unmarshal(data, decoded))
+	assert.Equal(t, "hello", decoded.GetValue())
+
+	_, err = codec.Marshal(codecTestValue{})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.ErrorIs(t, codec.Unmarshal(data, &codecTestValue{}), ErrNotSupported)
+}
+
+type customCodec struct{ JSONCodec }
+
+func (customCodec) Name() string { return "custom" }
+
+func TestLookupCodec(t *testing.T) {
+	codec, err := LookupCodec(CodecGob)
+	require.NoError(t, err)
+	assert.Equal(t, CodecGob, codec.Name())
+
+	_, err = LookupCodec("cbor")
+	assert.Error(t, err)
+
+	RegisterCodec(customCodec{})
+	codec, err = LookupCodec("custom")
+	require.NoError(t, err)
+	assert.Equal(t, "custom", codec.Name())
+	assert.Contains(t, Codecs(), "custom")
+}
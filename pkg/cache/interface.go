@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -11,6 +12,10 @@ type Cache interface {
 	// 返回值和一个布尔值，指示是否找到键
 	Get(ctx context.Context, key string) (interface{}, bool)
 
+	// GetBytes 返回键存储的原始字节，不做 JSON 解析
+	// 配合 Codec 使用，由调用方按写入时的编码方式解码
+	GetBytes(ctx context.Context, key string) ([]byte, bool)
+
 	// GetWithTTL 从缓存中检索值及其剩余TTL
 	// 返回值、剩余生存时间以及一个布尔值，指示是否找到键
 	GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool)
@@ -29,6 +34,14 @@ type Cache interface {
 	// DeleteMultiple 从缓存中删除多个键
 	DeleteMultiple(ctx context.Context, keys []string) error
 
+	// SetWithTags 存储值并将键关联到给定标签，用于按标签批量失效
+	// 标签的生存时间不短于其关联键中最长的TTL
+	SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+
+	// InvalidateTag 删除与标签关联的所有键以及标签本身
+	// 无需扫描键空间，适合替代基于 Keys 模式匹配的批量失效
+	InvalidateTag(ctx context.Context, tag string) error
+
 	// Exists 检查键是否存在于缓存中
 	Exists(ctx context.Context, key string) (bool, error)
 
@@ -65,4 +78,52 @@ type Cache interface {
 	// GetStats 返回缓存统计信息和健康指标
 	// 返回有关缓存性能和使用的详细信息
 	GetStats(ctx context.Context) (map[string]interface{}, error)
-}
\ No newline at end of file
+}
+
+// TagIndex 可选接口：标签关联的键集合可直接计数和分批遍历，无需扫描键空间
+// 键过期后仍保留在标签集合中，直到调用 UntagKeys 移除或标签集合本身过期
+type TagIndex interface {
+	// TagSize 返回标签关联的键数量
+	TagSize(ctx context.Context, tag string) (int64, error)
+
+	// ScanTag 从 cursor 开始分批返回标签关联的键，count 为每批的建议数量
+	// 返回的游标为 0 表示遍历结束，遍历期间集合被修改时键可能重复返回
+	ScanTag(ctx context.Context, tag string, cursor uint64, count int64) ([]string, uint64, error)
+
+	// UntagKeys 将键从标签集合中移除，不删除键本身
+	UntagKeys(ctx context.Context, tag string, keys ...string) error
+}
+
+// KeyScanner 可选接口：按游标分批遍历匹配模式的键，不会像 Keys 一样一次加载整个键空间
+type KeyScanner interface {
+	// ScanKeys 从 cursor 开始返回一批匹配 pattern 的键（不含应用键前缀），count 为每批的建议数量
+	// 返回的游标为 0 表示遍历结束，遍历期间键被修改时键可能重复返回
+	ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error)
+}
+
+// Invalidator 可选接口：在一次批量操作中删除键并失效标签，减少与缓存服务器的往返次数
+type Invalidator interface {
+	// Invalidate 删除键并失效各标签，效果与 DeleteMultiple 后逐个调用 InvalidateTag 相同
+	Invalidate(ctx context.Context, keys []string, tags ...string) error
+}
+
+// Invalidate 删除键并失效各标签，驱动实现 Invalidator 时合并为一次批量操作，
+// 否则依次调用 DeleteMultiple 和 InvalidateTag；某一步失败时仍执行其余步骤，返回合并后的错误
+func Invalidate(ctx context.Context, c Cache, keys []string, tags ...string) error {
+	if invalidator, ok := c.(Invalidator); ok {
+		return invalidator.Invalidate(ctx, keys, tags...)
+	}
+
+	var errs []error
+	if len(keys) > 0 {
+		if err := c.DeleteMultiple(ctx, keys); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, tag := range tags {
+		if err := c.InvalidateTag(ctx, tag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
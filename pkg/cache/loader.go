@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// LoadFunc 缓存未命中时从数据源加载数据
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// LoaderOptions 缓存加载器配置
+type LoaderOptions struct {
+	// TTLJitter 过期时间的随机抖动比例，如 0.1 表示在 ±10% 范围内随机，避免同一批写入的键同时过期
+	TTLJitter float64
+
+	// EarlyRecomputeBeta 提前重新计算的激进程度（XFetch 算法的 beta），0 表示不提前重新计算
+	// 键临近过期时按概率在后台刷新，加载耗时越长、beta 越大，越早开始刷新
+	EarlyRecomputeBeta float64
+}
+
+// DefaultLoaderOptions 返回默认的加载器配置
+func DefaultLoaderOptions() LoaderOptions {
+	return LoaderOptions{
+		TTLJitter:          0.1,
+		EarlyRecomputeBeta: 1.0,
+	}
+}
+
+// Loader 带缓存击穿保护的读穿缓存加载器
+//
+// 同一键的并发未命中只会调用一次加载函数，其余请求共享结果；
+// 开启提前重新计算时，热点键在过期前由单个请求在后台刷新，其他请求继续读取旧值
+type Loader struct {
+	cache Cache
+	opts  LoaderOptions
+	group Group
+
+	// 每个键最近一次加载的耗时，用于估算提前重新计算的时机
+	deltas sync.Map
+}
+
+// NewLoader 创建缓存加载器
+func NewLoader(cache Cache, opts LoaderOptions) *Loader {
+	if opts.TTLJitter < 0 {
+		opts.TTLJitter = 0
+	}
+	if opts.TTLJitter > 1 {
+		opts.TTLJitter = 1
+	}
+	if opts.EarlyRecomputeBeta < 0 {
+		opts.EarlyRecomputeBeta = 0
+	}
+	return &Loader{cache: cache, opts: opts}
+}
+
+// GetOrLoad 从缓存读取键，未命中时调用 loader 加载并以抖动后的 ttl 写入缓存
+// 加载失败时不写入缓存并返回错误；写入缓存失败不影响返回结果
+func (l *Loader) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	if l.opts.EarlyRecomputeBeta > 0 && ttl > 0 {
+		if value, remaining, found := l.cache.GetWithTTL(ctx, key); found {
+			if l.shouldRecompute(key, remaining) {
+				l.refreshAsync(ctx, key, ttl, loader)
+			}
+			return value, nil
+		}
+	} else if value, found := l.cache.Get(ctx, key); found {
+		return value, nil
+	}
+
+	value, err, _ := l.group.Do(key, func() (interface{}, error) {
+		return l.load(ctx, key, ttl, loader)
+	})
+	return value, err
+}
+
+// load 调用加载函数并写入缓存，记录加载耗时
+func (l *Loader) load(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	start := time.Now()
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.deltas.Store(key, time.Since(start))
+
+	_ = l.cache.Set(ctx, key, value, JitterTTL(ttl, l.opts.TTLJitter))
+	return value, nil
+}
+
+// refreshAsync 在后台刷新键，同一键同时只有一个刷新在进行
+func (l *Loader) refreshAsync(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) {
+	ctx = context.WithoutCancel(ctx)
+	go l.group.Do(key, func() (interface{}, error) {
+		return l.load(ctx, key, ttl, loader)
+	})
+}
+
+// shouldRecompute 按 XFetch 算法判断是否提前重新计算：remaining <= -delta * beta * ln(rand)
+// 剩余时间为 0 表示键没有过期时间，不需要刷新
+func (l *Loader) shouldRecompute(key string, remaining time.Duration) bool {
+	if remaining <= 0 {
+		return false
+	}
+	value, ok := l.deltas.Load(key)
+	if !ok {
+		return false
+	}
+	delta := value.(time.Duration)
+
+	window := -float64(delta) * l.opts.EarlyRecomputeBeta * math.Log(1-rand.Float64())
+	return float64(remaining) <= window
+}
+
+// JitterTTL 在 ttl 的 ±fraction 范围内随机调整过期时间，ttl 为 0（不过期）时原样返回
+func JitterTTL(ttl time.Duration, fraction float64) time.Duration {
+	if ttl <= 0 || fraction <= 0 {
+		return ttl
+	}
+	jittered := time.Duration(float64(ttl) * (1 + fraction*(2*rand.Float64()-1)))
+	if jittered <= 0 {
+		return ttl
+	}
+	return jittered
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Do_CoalescesConcurrentCalls(t *testing.T) {
+	var group Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 50
+	var wg sync.WaitGroup
+	results := make([]interface{}, callers)
+	sharedCount := atomic.Int32{}
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err, shared := group.Do("key", func() (interface{}, error) {
+				calls.Add(1)
+				<-release
+				return "value", nil
+			})
+			require.NoError(t, err)
+			results[i] = value
+			if shared {
+				sharedCount.Add(1)
+			}
+		}(i)
+	}
+
+	// 等待所有调用方进入等待后再放行
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(callers), sharedCount.Load())
+	for _, value := range results {
+		assert.Equal(t, "value", value)
+	}
+
+	// 调用完成后同一键再次执行
+	value, _, shared := group.Do("key", func() (interface{}, error) { return "again", nil })
+	assert.Equal(t, "again", value)
+	assert.False(t, shared)
+}
+
+func TestGroup_Do_Panic(t *testing.T) {
+	var group Group
+	_, err, _ := group.Do("key", func() (interface{}, error) {
+		panic("boom")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestLoader_GetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMockCache()
+	loader := NewLoader(cache, LoaderOptions{})
+
+	var calls atomic.Int32
+	load := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := loader.GetOrLoad(ctx, "hot", time.Minute, load)
+			assert.NoError(t, err)
+			assert.Equal(t, "loaded", value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "concurrent misses should trigger a single load")
+
+	// 命中缓存时不再加载
+	value, err := loader.GetOrLoad(ctx, "hot", time.Minute, load)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestLoader_GetOrLoad_ErrorNotCached(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMockCache()
+	loader := NewLoader(cache, DefaultLoaderOptions())
+
+	loadErr := errors.New("database unavailable")
+	_, err := loader.GetOrLoad(ctx, "key", time.Minute, func(ctx context.Context) (interface{}, error) {
+		return nil, loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+
+	_, found := cache.Get(ctx, "key")
+	assert.False(t, found)
+}
+
+func TestLoader_GetOrLoad_EarlyRecompute(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMockCache()
+	// beta 足够大时，命中临近过期的键几乎必定触发提前刷新
+	loader := NewLoader(cache, LoaderOptions{EarlyRecomputeBeta: 1e6})
+
+	var calls atomic.Int32
+	load := func(ctx context.Context) (interface{}, error) {
+		n := calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return n, nil
+	}
+
+	value, err := loader.GetOrLoad(ctx, "key", 200*time.Millisecond, load)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), value)
+
+	// 命中时立即返回旧值，后台刷新
+	value, err = loader.GetOrLoad(ctx, "key", 200*time.Millisecond, load)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), value)
+
+	assert.Eventually(t, func() bool {
+		value, found := cache.Get(ctx, "key")
+		return found && value == int32(2)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestJitterTTL(t *testing.T) {
+	assert.Equal(t, time.Duration(0), JitterTTL(0, 0.1))
+	assert.Equal(t, time.Minute, JitterTTL(time.Minute, 0))
+
+	for i := 0; i < 100; i++ {
+		ttl := JitterTTL(time.Minute, 0.1)
+		assert.GreaterOrEqual(t, ttl, 54*time.Second)
+		assert.LessOrEqual(t, ttl, 66*time.Second)
+	}
+}
@@ -0,0 +1,762 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memcachedMaxKeyLength memcached 键的最大长度（含前缀）
+const memcachedMaxKeyLength = 250
+
+// memcachedMaxRelativeExpiration 超过 30 天的过期时间会被 memcached 视为 Unix 时间戳
+const memcachedMaxRelativeExpiration = 30 * 24 * time.Hour
+
+// memcachedCASRetries 按标签失效时 CAS 冲突的最大重试次数
+const memcachedCASRetries = 10
+
+// MemcachedConfig 保存 memcached 连接的配置
+type MemcachedConfig struct {
+	Servers      []string      // 服务器地址列表（host:port），键按哈希分布到各服务器
+	Prefix       string        // 键前缀
+	Timeout      time.Duration // 连接和读写超时
+	MaxIdleConns int           // 每个服务器的最大空闲连接数
+}
+
+// DefaultMemcachedConfig 返回默认的 memcached 配置
+func DefaultMemcachedConfig() *MemcachedConfig {
+	return &MemcachedConfig{
+		Servers:      []string{"localhost:11211"},
+		Prefix:       "cache:",
+		Timeout:      3 * time.Second,
+		MaxIdleConns: 10,
+	}
+}
+
+// MemcachedCache 使用 memcached 文本协议实现 Cache 接口
+//
+// memcached 不支持按模式列出键、查询剩余 TTL 和负数计数，对应能力不在 Capabilities 中：
+// Keys 返回 ErrNotSupported，GetWithTTL 的剩余时间始终为 0，Decrement 的结果最小为 0，
+// Clear 会清空整个服务器。标签通过 append 维护的键列表实现
+type MemcachedCache struct {
+	servers []*memcachedServer
+	prefix  string
+}
+
+// memcachedServer 单个 memcached 服务器及其空闲连接池
+type memcachedServer struct {
+	addr    string
+	timeout time.Duration
+	maxIdle int
+
+	mu     sync.Mutex
+	idle   []*memcachedConn
+	closed bool
+}
+
+// memcachedConn 一条到 memcached 服务器的连接
+type memcachedConn struct {
+	net.Conn
+	rw *bufio.ReadWriter
+}
+
+// memcachedResponseError 服务器返回的错误响应，连接仍可继续使用
+type memcachedResponseError struct {
+	line string
+}
+
+func (e *memcachedResponseError) Error() string {
+	return "memcached: " + e.line
+}
+
+// NewMemcachedCache 创建新的 memcached 缓存实例并检查各服务器连接
+func NewMemcachedCache(config *MemcachedConfig) (Cache, error) {
+	if config == nil {
+		config = DefaultMemcachedConfig()
+	}
+	if len(config.Servers) == 0 {
+		return nil, errors.New("memcached: at least one server is required")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMemcachedConfig().Timeout
+	}
+
+	m := &MemcachedCache{prefix: config.Prefix}
+	for _, addr := range config.Servers {
+		m.servers = append(m.servers, &memcachedServer{
+			addr:    addr,
+			timeout: timeout,
+			maxIdle: config.MaxIdleConns,
+		})
+	}
+
+	// 测试连接
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.Health(ctx); err != nil {
+		m.Close()
+		return nil, fmt.Errorf("failed to connect to memcached: %w", err)
+	}
+
+	return m, nil
+}
+
+// Capabilities 返回 memcached 驱动支持的能力
+func (m *MemcachedCache) Capabilities() Capability {
+	return 0
+}
+
+// getKey 返回带前缀的键，键不符合 memcached 要求时返回错误
+func (m *MemcachedCache) getKey(key string) (string, error) {
+	fullKey := m.prefix + key
+	if len(fullKey) > memcachedMaxKeyLength {
+		return "", fmt.Errorf("memcached: key %q exceeds %d bytes", key, memcachedMaxKeyLength)
+	}
+	for i := 0; i < len(fullKey); i++ {
+		if fullKey[i] <= ' ' || fullKey[i] == 0x7f {
+			return "", fmt.Errorf("memcached: key %q contains whitespace or control characters", key)
+		}
+	}
+	return fullKey, nil
+}
+
+// serverFor 按键的哈希选择服务器
+func (m *MemcachedCache) serverFor(fullKey string) *memcachedServer {
+	if len(m.servers) == 1 {
+		return m.servers[0]
+	}
+	return m.servers[crc32.ChecksumIEEE([]byte(fullKey))%uint32(len(m.servers))]
+}
+
+// Get 从缓存中检索值
+func (m *MemcachedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	items, err := m.serverFor(fullKey).get(ctx, []string{fullKey}, false)
+	if err != nil {
+		return nil, false
+	}
+	item, found := items[fullKey]
+	if !found {
+		return nil, false
+	}
+	return decodeValue(item.data), true
+}
+
+// GetBytes 返回键存储的原始字节
+func (m *MemcachedCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	items, err := m.serverFor(fullKey).get(ctx, []string{fullKey}, false)
+	if err != nil {
+		return nil, false
+	}
+	item, found := items[fullKey]
+	if !found {
+		return nil, false
+	}
+	return item.data, true
+}
+
+// GetWithTTL 从缓存中检索值，memcached 无法查询剩余 TTL，剩余时间始终为 0
+func (m *MemcachedCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	value, found := m.Get(ctx, key)
+	return value, 0, found
+}
+
+// Set 在缓存中存储值，可选 TTL
+func (m *MemcachedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	_, err := m.store(ctx, "set", key, value, ttl)
+	return err
+}
+
+// SetMultiple 在缓存中存储多个键值对
+func (m *MemcachedCache) SetMultiple(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	for key, value := range items {
+		if err := m.Set(ctx, key, value, ttl); err != nil {
+			return fmt.Errorf("failed to set key %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// store 执行存储命令，返回值是否已写入
+func (m *MemcachedCache) store(ctx context.Context, verb, key string, value interface{}, ttl time.Duration) (bool, error) {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return false, err
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	return m.serverFor(fullKey).store(ctx, verb, fullKey, data, memcachedExpiration(ttl), 0)
+}
+
+// Delete 从缓存中删除键
+func (m *MemcachedCache) Delete(ctx context.Context, key string) error {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return err
+	}
+	return m.serverFor(fullKey).delete(ctx, fullKey)
+}
+
+// DeleteMultiple 从缓存中删除多个键
+func (m *MemcachedCache) DeleteMultiple(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := m.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete key %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SetWithTags 存储值并将键追加到各标签的键列表
+// 标签键列表不过期，失效标签时清空，避免标签先于其关联的键过期
+func (m *MemcachedCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := m.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		tagKey, err := m.getKey(tagKeyPrefix + tag)
+		if err != nil {
+			return err
+		}
+		if err := m.serverFor(tagKey).appendMember(ctx, tagKey, key); err != nil {
+			return fmt.Errorf("failed to tag key %s with %s: %w", key, tag, err)
+		}
+	}
+	return nil
+}
+
+// InvalidateTag 使用 CAS 原子地取出并清空标签的键列表，然后删除对应的键
+func (m *MemcachedCache) InvalidateTag(ctx context.Context, tag string) error {
+	tagKey, err := m.getKey(tagKeyPrefix + tag)
+	if err != nil {
+		return err
+	}
+	server := m.serverFor(tagKey)
+
+	for attempt := 0; attempt < memcachedCASRetries; attempt++ {
+		items, err := server.get(ctx, []string{tagKey}, true)
+		if err != nil {
+			return fmt.Errorf("failed to read tag %s: %w", tag, err)
+		}
+		item, found := items[tagKey]
+		if !found {
+			return nil
+		}
+
+		stored, err := server.store(ctx, "cas", tagKey, nil, 0, item.cas)
+		if err != nil {
+			return fmt.Errorf("failed to reset tag %s: %w", tag, err)
+		}
+		if !stored {
+			// 期间有新键加入标签，重新读取
+			continue
+		}
+
+		return m.DeleteMultiple(ctx, strings.Fields(string(item.data)))
+	}
+	return fmt.Errorf("failed to invalidate tag %s: too many concurrent updates", tag)
+}
+
+// Exists 检查键是否存在于缓存中
+func (m *MemcachedCache) Exists(ctx context.Context, key string) (bool, error) {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return false, err
+	}
+	items, err := m.serverFor(fullKey).get(ctx, []string{fullKey}, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if key exists: %w", err)
+	}
+	_, found := items[fullKey]
+	return found, nil
+}
+
+// Clear 清空所有服务器上的全部数据
+// memcached 无法按前缀删除，这会同时删除其他应用写入同一服务器的数据
+func (m *MemcachedCache) Clear(ctx context.Context) error {
+	for _, server := range m.servers {
+		if err := server.simpleCommand(ctx, "flush_all", "OK"); err != nil {
+			return fmt.Errorf("failed to flush %s: %w", server.addr, err)
+		}
+	}
+	return nil
+}
+
+// Keys memcached 不支持按模式列出键，始终返回 ErrNotSupported
+func (m *MemcachedCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return nil, fmt.Errorf("memcached keys %s: %w", pattern, ErrNotSupported)
+}
+
+// GetMultiple 从缓存中检索多个值，按服务器分组批量读取
+func (m *MemcachedCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	results := make(map[string]interface{})
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	originalKeys := make(map[string]string, len(keys))
+	byServer := make(map[*memcachedServer][]string)
+	for _, key := range keys {
+		fullKey, err := m.getKey(key)
+		if err != nil {
+			continue
+		}
+		originalKeys[fullKey] = key
+		server := m.serverFor(fullKey)
+		byServer[server] = append(byServer[server], fullKey)
+	}
+
+	for server, fullKeys := range byServer {
+		items, err := server.get(ctx, fullKeys, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get multiple values: %w", err)
+		}
+		for fullKey, item := range items {
+			results[originalKeys[fullKey]] = decodeValue(item.data)
+		}
+	}
+
+	return results, nil
+}
+
+// SetIfNotExists 仅在键不存在时设置值
+func (m *MemcachedCache) SetIfNotExists(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	stored, err := m.store(ctx, "add", key, value, ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to set if not exists: %w", err)
+	}
+	return stored, nil
+}
+
+// Increment 按给定数量递增键的数值，键不存在时从 0 开始且不过期
+// memcached 计数为无符号数，递减到 0 以下时结果为 0
+func (m *MemcachedCache) Increment(ctx context.Context, key string, amount int64) (int64, error) {
+	fullKey, err := m.getKey(key)
+	if err != nil {
+		return 0, err
+	}
+	server := m.serverFor(fullKey)
+
+	verb, delta := "incr", uint64(amount)
+	if amount < 0 {
+		verb, delta = "decr", uint64(-amount)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		value, found, err := server.incrDecr(ctx, verb, fullKey, delta)
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s key %s: %w", verb, key, err)
+		}
+		if found {
+			return int64(value), nil
+		}
+
+		// 键不存在时以初始值创建，并发创建失败时重试递增
+		initial := amount
+		if initial < 0 {
+			initial = 0
+		}
+		stored, err := server.store(ctx, "add", fullKey, []byte(strconv.FormatInt(initial, 10)), 0, 0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s key %s: %w", verb, key, err)
+		}
+		if stored {
+			return initial, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to %s key %s: concurrent update", verb, key)
+}
+
+// Decrement 按给定数量递减键的数值，结果最小为 0
+func (m *MemcachedCache) Decrement(ctx context.Context, key string, amount int64) (int64, error) {
+	return m.Increment(ctx, key, -amount)
+}
+
+// Close 关闭所有空闲连接，之后借出的连接在归还时关闭
+func (m *MemcachedCache) Close() error {
+	for _, server := range m.servers {
+		server.close()
+	}
+	return nil
+}
+
+// Health 检查所有服务器的连接
+func (m *MemcachedCache) Health(ctx context.Context) error {
+	for _, server := range m.servers {
+		if _, err := server.version(ctx); err != nil {
+			return fmt.Errorf("memcached server %s is unhealthy: %w", server.addr, err)
+		}
+	}
+	return nil
+}
+
+// GetStats 返回各服务器的统计信息
+func (m *MemcachedCache) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	servers := make(map[string]interface{}, len(m.servers))
+	for _, server := range m.servers {
+		start := time.Now()
+		serverStats, err := server.stats(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get memcached stats from %s: %w", server.addr, err)
+		}
+		serverStats["latency_ms"] = time.Since(start).Milliseconds()
+		servers[server.addr] = serverStats
+	}
+
+	return map[string]interface{}{
+		"driver":       "memcached",
+		"capabilities": m.Capabilities().String(),
+		"servers":      servers,
+	}, nil
+}
+
+// memcachedItem get/gets 返回的数据项
+type memcachedItem struct {
+	data []byte
+	cas  uint64
+}
+
+// get 批量读取键，withCAS 为 true 时使用 gets 返回 CAS 值
+func (s *memcachedServer) get(ctx context.Context, keys []string, withCAS bool) (map[string]memcachedItem, error) {
+	verb := "get"
+	if withCAS {
+		verb = "gets"
+	}
+
+	items := make(map[string]memcachedItem, len(keys))
+	err := s.do(ctx, func(conn *memcachedConn) error {
+		if _, err := fmt.Fprintf(conn.rw, "%s %s\r\n", verb, strings.Join(keys, " ")); err != nil {
+			return err
+		}
+		if err := conn.rw.Flush(); err != nil {
+			return err
+		}
+
+		for {
+			line, err := conn.readLine()
+			if err != nil {
+				return err
+			}
+			if line == "END" {
+				return nil
+			}
+
+			// VALUE <key> <flags> <bytes> [<cas unique>]
+			fields := strings.Fields(line)
+			if len(fields) < 4 || fields[0] != "VALUE" || (withCAS && len(fields) < 5) {
+				return fmt.Errorf("memcached: unexpected response %q", line)
+			}
+			size, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return fmt.Errorf("memcached: invalid value size in %q", line)
+			}
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(conn.rw, data); err != nil {
+				return err
+			}
+
+			item := memcachedItem{data: data[:size]}
+			if withCAS {
+				if item.cas, err = strconv.ParseUint(fields[4], 10, 64); err != nil {
+					return fmt.Errorf("memcached: invalid cas value in %q", line)
+				}
+			}
+			items[fields[1]] = item
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// store 执行 set/add/append/cas 存储命令，返回值是否已写入
+func (s *memcachedServer) store(ctx context.Context, verb, key string, data []byte, exptime int64, cas uint64) (bool, error) {
+	var stored bool
+	err := s.do(ctx, func(conn *memcachedConn) error {
+		if verb == "cas" {
+			fmt.Fprintf(conn.rw, "cas %s 0 %d %d %d\r\n", key, exptime, len(data), cas)
+		} else {
+			fmt.Fprintf(conn.rw, "%s %s 0 %d %d\r\n", verb, key, exptime, len(data))
+		}
+		conn.rw.Write(data)
+		conn.rw.WriteString("\r\n")
+		if err := conn.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := conn.readLine()
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "STORED":
+			stored = true
+		case "NOT_STORED", "EXISTS", "NOT_FOUND":
+			stored = false
+		default:
+			return fmt.Errorf("memcached: unexpected response %q", line)
+		}
+		return nil
+	})
+	return stored, err
+}
+
+// appendMember 将成员追加到以空格分隔的列表键，键不存在时创建
+func (s *memcachedServer) appendMember(ctx context.Context, key, member string) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		appended, err := s.store(ctx, "append", key, []byte(" "+member), 0, 0)
+		if err != nil || appended {
+			return err
+		}
+		added, err := s.store(ctx, "add", key, []byte(member), 0, 0)
+		if err != nil || added {
+			return err
+		}
+	}
+	return errors.New("memcached: concurrent update")
+}
+
+// delete 删除键，键不存在不视为错误
+func (s *memcachedServer) delete(ctx context.Context, key string) error {
+	return s.do(ctx, func(conn *memcachedConn) error {
+		line, err := conn.command("delete " + key)
+		if err != nil {
+			return err
+		}
+		if line != "DELETED" && line != "NOT_FOUND" {
+			return fmt.Errorf("memcached: unexpected response %q", line)
+		}
+		return nil
+	})
+}
+
+// incrDecr 执行 incr/decr，found 为 false 表示键不存在
+func (s *memcachedServer) incrDecr(ctx context.Context, verb, key string, delta uint64) (uint64, bool, error) {
+	var value uint64
+	var found bool
+	err := s.do(ctx, func(conn *memcachedConn) error {
+		line, err := conn.command(fmt.Sprintf("%s %s %d", verb, key, delta))
+		if err != nil {
+			return err
+		}
+		if line == "NOT_FOUND" {
+			return nil
+		}
+		value, err = strconv.ParseUint(line, 10, 64)
+		if err != nil {
+			return fmt.Errorf("memcached: unexpected response %q", line)
+		}
+		found = true
+		return nil
+	})
+	return value, found, err
+}
+
+// simpleCommand 执行只返回单行状态的命令
+func (s *memcachedServer) simpleCommand(ctx context.Context, command, expected string) error {
+	return s.do(ctx, func(conn *memcachedConn) error {
+		line, err := conn.command(command)
+		if err != nil {
+			return err
+		}
+		if line != expected {
+			return fmt.Errorf("memcached: unexpected response %q", line)
+		}
+		return nil
+	})
+}
+
+// version 返回服务器版本
+func (s *memcachedServer) version(ctx context.Context) (string, error) {
+	var version string
+	err := s.do(ctx, func(conn *memcachedConn) error {
+		line, err := conn.command("version")
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, "VERSION ") {
+			return fmt.Errorf("memcached: unexpected response %q", line)
+		}
+		version = strings.TrimPrefix(line, "VERSION ")
+		return nil
+	})
+	return version, err
+}
+
+// stats 返回服务器的 stats 输出，数值字段解析为整数
+func (s *memcachedServer) stats(ctx context.Context) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	err := s.do(ctx, func(conn *memcachedConn) error {
+		line, err := conn.command("stats")
+		for ; err == nil && line != "END"; line, err = conn.readLine() {
+			// STAT <name> <value>
+			parts := strings.SplitN(line, " ", 3)
+			if len(parts) != 3 || parts[0] != "STAT" {
+				return fmt.Errorf("memcached: unexpected response %q", line)
+			}
+			if intVal, parseErr := strconv.ParseInt(parts[2], 10, 64); parseErr == nil {
+				result[parts[1]] = intVal
+			} else {
+				result[parts[1]] = parts[2]
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// do 借出连接执行 fn，服务器错误响应后连接仍可复用，其他错误时关闭连接
+func (s *memcachedServer) do(ctx context.Context, fn func(conn *memcachedConn) error) error {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	err = fn(conn)
+	var respErr *memcachedResponseError
+	if err == nil || errors.As(err, &respErr) {
+		s.release(conn)
+	} else {
+		conn.Close()
+	}
+	return err
+}
+
+// acquire 从空闲池取出连接，没有空闲连接时新建
+func (s *memcachedServer) acquire(ctx context.Context) (*memcachedConn, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		conn := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return conn, nil
+	}
+	s.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &memcachedConn{
+		Conn: netConn,
+		rw:   bufio.NewReadWriter(bufio.NewReader(netConn), bufio.NewWriter(netConn)),
+	}, nil
+}
+
+// release 归还连接，空闲池已满或已关闭时关闭连接
+func (s *memcachedServer) release(conn *memcachedConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.idle) >= s.maxIdle {
+		conn.Close()
+		return
+	}
+	s.idle = append(s.idle, conn)
+}
+
+// close 关闭所有空闲连接
+func (s *memcachedServer) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, conn := range s.idle {
+		conn.Close()
+	}
+	s.idle = nil
+}
+
+// command 发送单行命令并读取一行响应
+func (c *memcachedConn) command(command string) (string, error) {
+	if _, err := c.rw.WriteString(command + "\r\n"); err != nil {
+		return "", err
+	}
+	if err := c.rw.Flush(); err != nil {
+		return "", err
+	}
+	return c.readLine()
+}
+
+// readLine 读取一行响应，ERROR/CLIENT_ERROR/SERVER_ERROR 转换为 memcachedResponseError
+func (c *memcachedConn) readLine() (string, error) {
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR ") || strings.HasPrefix(line, "SERVER_ERROR ") {
+		return "", &memcachedResponseError{line: line}
+	}
+	return line, nil
+}
+
+// memcachedExpiration 将 TTL 转换为 memcached 的过期时间，不足 1 秒按 1 秒计
+// 超过 30 天时使用 Unix 时间戳
+func memcachedExpiration(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > memcachedMaxRelativeExpiration {
+		return time.Now().Add(ttl).Unix()
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
+// encodeValue 将值序列化为存储格式：字符串和字节切片原样存储，其他类型序列化为 JSON
+func encodeValue(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+		return data, nil
+	}
+}
+
+// decodeValue 将存储的数据解析为 JSON，不是 JSON 时作为字符串返回
+func decodeValue(data []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(data)
+	}
+	return value
+}
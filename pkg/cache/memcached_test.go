@@ -0,0 +1,385 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMemcached is an in-process server speaking the subset of the memcached text protocol used by MemcachedCache
+type fakeMemcached struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	items   map[string]fakeMemcachedItem
+	nextCAS uint64
+	exptime map[string]int64
+}
+
+type fakeMemcachedItem struct {
+	data []byte
+	cas  uint64
+}
+
+func newFakeMemcached(t *testing.T) *fakeMemcached {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeMemcached{
+		listener: listener,
+		items:    make(map[string]fakeMemcachedItem),
+		exptime:  make(map[string]int64),
+	}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeMemcached) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeMemcached) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		f.mu.Lock()
+		switch fields[0] {
+		case "get", "gets":
+			for _, key := range fields[1:] {
+				if item, ok := f.items[key]; ok {
+					if fields[0] == "gets" {
+						fmt.Fprintf(w, "VALUE %s 0 %d %d\r\n", key, len(item.data), item.cas)
+					} else {
+						fmt.Fprintf(w, "VALUE %s 0 %d\r\n", key, len(item.data))
+					}
+					w.Write(item.data)
+					w.WriteString("\r\n")
+				}
+			}
+			w.WriteString("END\r\n")
+		case "set", "add", "append", "cas":
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			f.mu.Unlock()
+			_, err := io.ReadFull(r, data)
+			f.mu.Lock()
+			if err != nil {
+				f.mu.Unlock()
+				return
+			}
+			w.WriteString(f.store(fields, data[:size]) + "\r\n")
+		case "delete":
+			if _, ok := f.items[fields[1]]; ok {
+				delete(f.items, fields[1])
+				w.WriteString("DELETED\r\n")
+			} else {
+				w.WriteString("NOT_FOUND\r\n")
+			}
+		case "incr", "decr":
+			item, ok := f.items[fields[1]]
+			if !ok {
+				w.WriteString("NOT_FOUND\r\n")
+				break
+			}
+			current, _ := strconv.ParseUint(string(item.data), 10, 64)
+			delta, _ := strconv.ParseUint(fields[2], 10, 64)
+			if fields[0] == "incr" {
+				current += delta
+			} else if delta > current {
+				current = 0
+			} else {
+				current -= delta
+			}
+			f.nextCAS++
+			f.items[fields[1]] = fakeMemcachedItem{data: []byte(strconv.FormatUint(current, 10)), cas: f.nextCAS}
+			fmt.Fprintf(w, "%d\r\n", current)
+		case "flush_all":
+			f.items = make(map[string]fakeMemcachedItem)
+			w.WriteString("OK\r\n")
+		case "version":
+			w.WriteString("VERSION 1.6.0-fake\r\n")
+		case "stats":
+			fmt.Fprintf(w, "STAT curr_items %d\r\nSTAT version 1.6.0-fake\r\nEND\r\n", len(f.items))
+		default:
+			w.WriteString("ERROR\r\n")
+		}
+		f.mu.Unlock()
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// store handles set/add/append/cas; must be called with f.mu held
+func (f *fakeMemcached) store(fields []string, data []byte) string {
+	key := fields[1]
+	existing, exists := f.items[key]
+
+	switch fields[0] {
+	case "add":
+		if exists {
+			return "NOT_STORED"
+		}
+	case "append":
+		if !exists {
+			return "NOT_STORED"
+		}
+		data = append(append([]byte{}, existing.data...), data...)
+	case "cas":
+		if !exists {
+			return "NOT_FOUND"
+		}
+		cas, _ := strconv.ParseUint(fields[5], 10, 64)
+		if cas != existing.cas {
+			return "EXISTS"
+		}
+	}
+
+	if fields[0] != "append" {
+		f.exptime[key], _ = strconv.ParseInt(fields[3], 10, 64)
+	}
+	f.nextCAS++
+	f.items[key] = fakeMemcachedItem{data: data, cas: f.nextCAS}
+	return "STORED"
+}
+
+func (f *fakeMemcached) raw(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[key]
+	return string(item.data), ok
+}
+
+func newTestMemcachedCache(t *testing.T, servers ...*fakeMemcached) *MemcachedCache {
+	config := DefaultMemcachedConfig()
+	config.Servers = nil
+	for _, server := range servers {
+		config.Servers = append(config.Servers, server.listener.Addr().String())
+	}
+	config.Prefix = "test:"
+
+	c, err := NewMemcachedCache(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c.(*MemcachedCache)
+}
+
+func TestMemcachedCache_BasicOperations(t *testing.T) {
+	server := newFakeMemcached(t)
+	c := newTestMemcachedCache(t, server)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "greeting", "hello", time.Minute))
+	value, found := c.Get(ctx, "greeting")
+	assert.True(t, found)
+	assert.Equal(t, "hello", value)
+
+	// Values are stored with the prefix and decoded from JSON like RedisCache
+	require.NoError(t, c.Set(ctx, "user", map[string]interface{}{"name": "Ann"}, 0))
+	raw, ok := server.raw("test:user")
+	require.True(t, ok)
+	assert.JSONEq(t, `{"name":"Ann"}`, raw)
+	value, found = c.Get(ctx, "user")
+	assert.True(t, found)
+	assert.Equal(t, map[string]interface{}{"name": "Ann"}, value)
+	data, found := c.GetBytes(ctx, "user")
+	assert.True(t, found)
+	assert.JSONEq(t, `{"name":"Ann"}`, string(data))
+
+	// GetWithTTL can't report the remaining TTL
+	_, ttl, found := c.GetWithTTL(ctx, "greeting")
+	assert.True(t, found)
+	assert.Zero(t, ttl)
+
+	exists, err := c.Exists(ctx, "greeting")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, c.Delete(ctx, "greeting"))
+	_, found = c.Get(ctx, "greeting")
+	assert.False(t, found)
+	assert.NoError(t, c.Delete(ctx, "greeting"), "deleting a missing key is not an error")
+
+	stored, err := c.SetIfNotExists(ctx, "lock", "1", time.Second)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	stored, err = c.SetIfNotExists(ctx, "lock", "2", time.Second)
+	require.NoError(t, err)
+	assert.False(t, stored)
+
+	require.NoError(t, c.Clear(ctx))
+	_, found = c.Get(ctx, "user")
+	assert.False(t, found)
+}
+
+func TestMemcachedCache_MultipleServers(t *testing.T) {
+	servers := []*fakeMemcached{newFakeMemcached(t), newFakeMemcached(t)}
+	c := newTestMemcachedCache(t, servers...)
+	ctx := context.Background()
+
+	items := make(map[string]interface{})
+	keys := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key:%d", i)
+		items[key] = fmt.Sprintf("value-%d", i)
+		keys = append(keys, key)
+	}
+	require.NoError(t, c.SetMultiple(ctx, items, time.Minute))
+
+	values, err := c.GetMultiple(ctx, append(keys, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, items, values)
+
+	// Keys are spread across both servers
+	for _, server := range servers {
+		server.mu.Lock()
+		assert.NotEmpty(t, server.items)
+		server.mu.Unlock()
+	}
+
+	require.NoError(t, c.DeleteMultiple(ctx, keys))
+	values, err = c.GetMultiple(ctx, keys)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestMemcachedCache_Counters(t *testing.T) {
+	c := newTestMemcachedCache(t, newFakeMemcached(t))
+	ctx := context.Background()
+
+	value, err := c.Increment(ctx, "counter", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, err = c.Increment(ctx, "counter", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), value)
+
+	value, err = c.Decrement(ctx, "counter", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), value)
+
+	// Without signed counters the value stops at zero
+	value, err = c.Decrement(ctx, "counter", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), value)
+	assert.False(t, Supports(c, CapabilitySignedCounters))
+}
+
+func TestMemcachedCache_Tags(t *testing.T) {
+	server := newFakeMemcached(t)
+	c := newTestMemcachedCache(t, server)
+	ctx := context.Background()
+
+	require.NoError(t, c.SetWithTags(ctx, "list:1", "page1", time.Minute, "lists"))
+	require.NoError(t, c.SetWithTags(ctx, "list:2", "page2", time.Minute, "lists"))
+	require.NoError(t, c.Set(ctx, "untagged", "value", time.Minute))
+
+	members, ok := server.raw("test:tag:lists")
+	require.True(t, ok)
+	assert.Equal(t, "list:1 list:2", members)
+
+	require.NoError(t, c.InvalidateTag(ctx, "lists"))
+	_, found := c.Get(ctx, "list:1")
+	assert.False(t, found)
+	_, found = c.Get(ctx, "list:2")
+	assert.False(t, found)
+	_, found = c.Get(ctx, "untagged")
+	assert.True(t, found)
+
+	// The tag list is emptied and reused
+	require.NoError(t, c.SetWithTags(ctx, "list:3", "page3", time.Minute, "lists"))
+	members, _ = server.raw("test:tag:lists")
+	assert.Equal(t, " list:3", members)
+
+	assert.NoError(t, c.InvalidateTag(ctx, "missing"))
+}
+
+func TestMemcachedCache_Capabilities(t *testing.T) {
+	c := newTestMemcachedCache(t, newFakeMemcached(t))
+	ctx := context.Background()
+
+	assert.False(t, Supports(c, CapabilityKeys))
+	assert.False(t, Supports(c, CapabilityTTL))
+	assert.False(t, Supports(c, CapabilityScopedClear))
+
+	_, err := c.Keys(ctx, "*")
+	assert.True(t, errors.Is(err, ErrNotSupported))
+
+	// Drivers that don't declare capabilities are assumed to support everything
+	assert.Equal(t, AllCapabilities, CapabilitiesOf(NewMockCache()))
+	assert.Equal(t, "keys,ttl,signed_counters,scoped_clear", AllCapabilities.String())
+	assert.Equal(t, []string{"keys", "scoped_clear"}, (CapabilityKeys | CapabilityScopedClear).Names())
+	assert.Empty(t, CapabilitiesOf(c).Names())
+
+	stats, err := c.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "memcached", stats["driver"])
+	assert.Len(t, stats["servers"], 1)
+}
+
+func TestMemcachedCache_InvalidKeys(t *testing.T) {
+	c := newTestMemcachedCache(t, newFakeMemcached(t))
+	ctx := context.Background()
+
+	assert.Error(t, c.Set(ctx, "has space", "value", 0))
+	assert.Error(t, c.Set(ctx, strings.Repeat("k", memcachedMaxKeyLength), "value", 0))
+	_, found := c.Get(ctx, "has space")
+	assert.False(t, found)
+}
+
+func TestMemcachedCache_ConnectionFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	config := DefaultMemcachedConfig()
+	config.Servers = []string{addr}
+	config.Timeout = 200 * time.Millisecond
+	_, err = NewMemcachedCache(config)
+	assert.Error(t, err)
+
+	_, err = NewMemcachedCache(&MemcachedConfig{})
+	assert.Error(t, err)
+}
+
+func TestMemcachedExpiration(t *testing.T) {
+	assert.Equal(t, int64(0), memcachedExpiration(0))
+	assert.Equal(t, int64(1), memcachedExpiration(10*time.Millisecond))
+	assert.Equal(t, int64(60), memcachedExpiration(time.Minute))
+
+	// Beyond 30 days memcached expects an absolute Unix timestamp
+	expiration := memcachedExpiration(31 * 24 * time.Hour)
+	assert.InDelta(t, time.Now().Add(31*24*time.Hour).Unix(), expiration, 2)
+}
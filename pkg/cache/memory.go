@@ -0,0 +1,392 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-server/pkg/clock"
+)
+
+// errMemoryCacheClosed 缓存已关闭
+var errMemoryCacheClosed = errors.New("memory cache: closed")
+
+// MemoryCache 进程内的 Cache 实现，用于测试和本地开发
+// 值与 Redis 驱动一样按 JSON 编码保存，过期的键在读取时删除；Keys 的模式语法与 Redis 的 glob 相近（使用 path.Match）
+type MemoryCache struct {
+	mu     sync.Mutex
+	items  map[string]memoryItem
+	tags   map[string]map[string]struct{}
+	clock  clock.Clock
+	closed bool
+}
+
+// memoryItem 一个缓存项，expiresAt 为零值表示不过期
+type memoryItem struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache 创建进程内缓存
+func NewMemoryCache() *MemoryCache {
+	return NewMemoryCacheWithClock(clock.Real)
+}
+
+// NewMemoryCacheWithClock 创建使用指定时钟判断过期的进程内缓存，测试中配合 clock.Fake 推进时间
+func NewMemoryCacheWithClock(clk clock.Clock) *MemoryCache {
+	return &MemoryCache{
+		items: make(map[string]memoryItem),
+		tags:  make(map[string]map[string]struct{}),
+		clock: clock.OrReal(clk),
+	}
+}
+
+// Capabilities 进程内缓存支持全部能力
+func (m *MemoryCache) Capabilities() Capability {
+	return AllCapabilities
+}
+
+// lookup 返回未过期的缓存项，调用方须持有锁
+func (m *MemoryCache) lookup(key string) (memoryItem, bool) {
+	item, ok := m.items[key]
+	if !ok {
+		return memoryItem{}, false
+	}
+	if !item.expiresAt.IsZero() && !m.clock.Now().Before(item.expiresAt) {
+		delete(m.items, key)
+		return memoryItem{}, false
+	}
+	return item, true
+}
+
+// store 保存缓存项，调用方须持有锁
+func (m *MemoryCache) store(key string, value interface{}, ttl time.Duration) error {
+	if m.closed {
+		return errMemoryCacheClosed
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	item := memoryItem{data: data}
+	if ttl > 0 {
+		item.expiresAt = m.clock.Now().Add(ttl)
+	}
+	m.items[key] = item
+	return nil
+}
+
+// Get 从缓存中检索值
+func (m *MemoryCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	data, found := m.GetBytes(ctx, key)
+	if !found {
+		return nil, false
+	}
+	return decodeValue(data), true
+}
+
+// GetBytes 返回键存储的原始字节
+func (m *MemoryCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, found := m.lookup(key)
+	if !found {
+		return nil, false
+	}
+	return append([]byte(nil), item.data...), true
+}
+
+// GetWithTTL 从缓存中检索值及其剩余 TTL，不过期的键剩余时间为 0
+func (m *MemoryCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, found := m.lookup(key)
+	if !found {
+		return nil, 0, false
+	}
+	var ttl time.Duration
+	if !item.expiresAt.IsZero() {
+		ttl = m.clock.Until(item.expiresAt)
+	}
+	return decodeValue(item.data), ttl, true
+}
+
+// Set 在缓存中存储值，可选 TTL
+func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store(key, value, ttl)
+}
+
+// SetMultiple 在缓存中存储多个键值对
+func (m *MemoryCache) SetMultiple(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range items {
+		if err := m.store(key, value, ttl); err != nil {
+			return fmt.Errorf("failed to set key %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Delete 从缓存中删除键
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	return m.DeleteMultiple(ctx, []string{key})
+}
+
+// DeleteMultiple 从缓存中删除多个键
+func (m *MemoryCache) DeleteMultiple(ctx context.Context, keys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// SetWithTags 存储值并将键关联到给定标签
+func (m *MemoryCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store(key, value, ttl); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+// InvalidateTag 删除与标签关联的所有键以及标签本身
+func (m *MemoryCache) InvalidateTag(ctx context.Context, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.tags[tag] {
+		delete(m.items, key)
+	}
+	delete(m.tags, tag)
+	return nil
+}
+
+// Invalidate 在同一把锁内删除键并失效各标签
+func (m *MemoryCache) Invalidate(ctx context.Context, keys []string, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			delete(m.items, key)
+		}
+		delete(m.tags, tag)
+	}
+	return nil
+}
+
+// TagSize 返回标签关联的键数量
+func (m *MemoryCache) TagSize(ctx context.Context, tag string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.tags[tag])), nil
+}
+
+// ScanTag 从 cursor 开始分批返回标签关联的键，游标为已返回的键数量
+func (m *MemoryCache) ScanTag(ctx context.Context, tag string, cursor uint64, count int64) ([]string, uint64, error) {
+	m.mu.Lock()
+	keys := make([]string, 0, len(m.tags[tag]))
+	for key := range m.tags[tag] {
+		keys = append(keys, key)
+	}
+	m.mu.Unlock()
+
+	sort.Strings(keys)
+	if count <= 0 {
+		count = 10
+	}
+	start := min(cursor, uint64(len(keys)))
+	end := min(start+uint64(count), uint64(len(keys)))
+	if end == uint64(len(keys)) {
+		return keys[start:end], 0, nil
+	}
+	return keys[start:end], end, nil
+}
+
+// UntagKeys 将键从标签集合中移除，不删除键本身
+func (m *MemoryCache) UntagKeys(ctx context.Context, tag string, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.tags[tag], key)
+	}
+	if len(m.tags[tag]) == 0 {
+		delete(m.tags, tag)
+	}
+	return nil
+}
+
+// Exists 检查键是否存在于缓存中
+func (m *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, found := m.lookup(key)
+	return found, nil
+}
+
+// Clear 删除所有键和标签
+func (m *MemoryCache) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]memoryItem)
+	m.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// Keys 返回匹配模式的所有键，按字典序排列
+func (m *MemoryCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.items))
+	for key := range m.items {
+		if _, found := m.lookup(key); !found {
+			continue
+		}
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+		if matched {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// ScanKeys 从 cursor 开始分批返回匹配模式的键，游标为已返回的键数量
+func (m *MemoryCache) ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	keys, err := m.Keys(ctx, pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if count <= 0 {
+		count = 10
+	}
+	start := min(cursor, uint64(len(keys)))
+	end := min(start+uint64(count), uint64(len(keys)))
+	if end == uint64(len(keys)) {
+		return keys[start:end], 0, nil
+	}
+	return keys[start:end], end, nil
+}
+
+// GetMultiple 从缓存中检索多个值
+func (m *MemoryCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if item, found := m.lookup(key); found {
+			result[key] = decodeValue(item.data)
+		}
+	}
+	return result, nil
+}
+
+// SetIfNotExists 仅在键不存在时设置值
+func (m *MemoryCache) SetIfNotExists(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found := m.lookup(key); found {
+		return false, nil
+	}
+	if err := m.store(key, value, ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Increment 按给定数量递增键的数值，键不存在时从 0 开始并且不过期
+func (m *MemoryCache) Increment(ctx context.Context, key string, amount int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return 0, errMemoryCacheClosed
+	}
+	item, found := m.lookup(key)
+	var current int64
+	if found {
+		n, err := strconv.ParseInt(string(item.data), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value of key %s is not an integer", key)
+		}
+		current = n
+	}
+	current += amount
+	item.data = []byte(strconv.FormatInt(current, 10))
+	m.items[key] = item
+	return current, nil
+}
+
+// Decrement 按给定数量递减键的数值
+func (m *MemoryCache) Decrement(ctx context.Context, key string, amount int64) (int64, error) {
+	return m.Increment(ctx, key, -amount)
+}
+
+// Close 关闭缓存，之后的写入返回错误
+func (m *MemoryCache) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// Health 缓存关闭后返回错误
+func (m *MemoryCache) Health(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return errMemoryCacheClosed
+	}
+	return nil
+}
+
+// GetStats 返回键和标签数量，缓存关闭后返回错误
+func (m *MemoryCache) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, errMemoryCacheClosed
+	}
+	return map[string]interface{}{
+		"driver":       "memory",
+		"capabilities": m.Capabilities().String(),
+		"keys":         len(m.items),
+		"tags":         len(m.tags),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-server/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	c := NewMemoryCacheWithClock(clk)
+
+	t.Run("读写与过期", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "user:1", map[string]interface{}{"id": "1"}, time.Minute))
+		require.NoError(t, c.Set(ctx, "name", "plain text", 0))
+
+		value, ttl, found := c.GetWithTTL(ctx, "user:1")
+		require.True(t, found)
+		assert.Equal(t, map[string]interface{}{"id": "1"}, value)
+		assert.Equal(t, time.Minute, ttl)
+
+		name, found := GetAs[string](ctx, c, "name")
+		require.True(t, found)
+		assert.Equal(t, "plain text", name)
+
+		clk.Advance(time.Minute)
+		_, found = c.Get(ctx, "user:1")
+		assert.False(t, found, "过期的键应读取不到")
+		_, found = c.Get(ctx, "name")
+		assert.True(t, found, "TTL 为 0 的键不过期")
+	})
+
+	t.Run("模式匹配与标签", func(t *testing.T) {
+		require.NoError(t, c.Clear(ctx))
+		require.NoError(t, c.SetWithTags(ctx, "user:1", "a", 0, "users"))
+		require.NoError(t, c.SetWithTags(ctx, "user:2", "b", 0, "users"))
+		require.NoError(t, c.Set(ctx, "session:1", "c", 0))
+
+		keys, err := c.Keys(ctx, "user:*")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+		size, err := c.TagSize(ctx, "users")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), size)
+		batch, cursor, err := c.ScanTag(ctx, "users", 0, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:1"}, batch)
+		batch, cursor, err = c.ScanTag(ctx, "users", cursor, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:2"}, batch)
+		assert.Zero(t, cursor)
+
+		batch, cursor, err = c.ScanKeys(ctx, "user:*", 0, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:1"}, batch)
+		assert.NotZero(t, cursor)
+		batch, cursor, err = c.ScanKeys(ctx, "user:*", cursor, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:2"}, batch)
+		assert.Zero(t, cursor)
+
+		require.NoError(t, c.InvalidateTag(ctx, "users"))
+		keys, err = c.Keys(ctx, "*")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"session:1"}, keys)
+	})
+
+	t.Run("批量失效键和标签", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "user:id:1", "alice", time.Minute))
+		require.NoError(t, c.SetWithTags(ctx, "response:1", "cached", time.Minute, "user:1"))
+		require.NoError(t, c.SetWithTags(ctx, "users:list:1", "page", time.Minute, "users:list"))
+		require.NoError(t, c.SetWithTags(ctx, "users:list:2", "page", time.Minute, "users:list", "other"))
+		require.NoError(t, c.Set(ctx, "kept", "value", time.Minute))
+
+		require.NoError(t, Invalidate(ctx, c, []string{"user:id:1", "missing"}, "user:1", "users:list"))
+		for _, key := range []string{"user:id:1", "response:1", "users:list:1", "users:list:2"} {
+			_, found := c.Get(ctx, key)
+			assert.False(t, found, key)
+		}
+		_, found := c.Get(ctx, "kept")
+		assert.True(t, found)
+		size, err := c.TagSize(ctx, "users:list")
+		require.NoError(t, err)
+		assert.Zero(t, size)
+	})
+
+	t.Run("计数器与条件写入", func(t *testing.T) {
+		n, err := c.Increment(ctx, "counter", 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+		n, err = c.Decrement(ctx, "counter", 7)
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), n)
+
+		set, err := c.SetIfNotExists(ctx, "lock", "1", time.Second)
+		require.NoError(t, err)
+		assert.True(t, set)
+		set, err = c.SetIfNotExists(ctx, "lock", "2", time.Second)
+		require.NoError(t, err)
+		assert.False(t, set)
+	})
+
+	t.Run("关闭后不可用", func(t *testing.T) {
+		require.NoError(t, c.Close())
+		assert.Error(t, c.Health(ctx))
+		assert.Error(t, c.Set(ctx, "key", "value", 0))
+	})
+}
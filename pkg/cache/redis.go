@@ -8,14 +8,19 @@ import (
 	"strings"
 	"time"
 
+	"go-server/internal/metrics"
+	"go-server/pkg/clock"
+
 	"github.com/redis/go-redis/v9"
 	"github.com/redis/go-redis/v9/maintnotifications"
 )
 
 // RedisCache 使用 Redis 实现 Cache 接口
 type RedisCache struct {
-	client *redis.Client
-	prefix string
+	client  *redis.Client
+	prefix  string
+	metrics *metrics.CacheMetrics // 读写命中统计，按去掉前缀的键分组
+	clock   clock.Clock           // 计时读写耗时
 }
 
 // RedisConfig 保存 Redis 连接的配置
@@ -31,6 +36,13 @@ type RedisConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	PoolTimeout  time.Duration
+
+	// Metrics 记录命中、未命中和读写耗时，为 nil 时创建新的指标收集器
+	Metrics *metrics.CacheMetrics
+
+	// Clock 用于计时读写耗时，为 nil 时使用系统时钟
+	// 键的过期由 Redis 服务端负责，不受该时钟影响；需要推进时间的测试使用 NewMemoryCacheWithClock
+	Clock clock.Clock
 }
 
 // DefaultRedisConfig 返回 Redis 的默认配置
@@ -79,9 +91,16 @@ func NewRedisCache(config *RedisConfig) (Cache, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	cacheMetrics := config.Metrics
+	if cacheMetrics == nil {
+		cacheMetrics = metrics.NewCacheMetrics()
+	}
+
 	return &RedisCache{
-		client: rdb,
-		prefix: config.Prefix,
+		client:  rdb,
+		prefix:  config.Prefix,
+		metrics: cacheMetrics,
+		clock:   clock.OrReal(config.Clock),
 	}, nil
 }
 
@@ -91,11 +110,23 @@ func NewRedisCacheWithClient(client *redis.Client, prefix string) Cache {
 		prefix = "cache:"
 	}
 	return &RedisCache{
-		client: client,
-		prefix: prefix,
+		client:  client,
+		prefix:  prefix,
+		metrics: metrics.NewCacheMetrics(),
+		clock:   clock.Real,
 	}
 }
 
+// Metrics 返回缓存的指标收集器
+func (r *RedisCache) Metrics() *metrics.CacheMetrics {
+	return r.metrics
+}
+
+// recordGet 记录一次读取，键不存在不算作错误
+func (r *RedisCache) recordGet(key string, start time.Time, err error) {
+	r.metrics.RecordGet(key, r.clock.Since(start), err == nil, err == nil || err == redis.Nil)
+}
+
 // getKey 返回带前缀的完整键名
 func (r *RedisCache) getKey(key string) string {
 	return r.prefix + key
@@ -103,7 +134,9 @@ func (r *RedisCache) getKey(key string) string {
 
 // Get 从缓存中检索值
 func (r *RedisCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	start := r.clock.Now()
 	result, err := r.client.Get(ctx, r.getKey(key)).Result()
+	r.recordGet(key, start, err)
 	if err != nil {
 		if err == redis.Nil {
 			return nil, false
@@ -122,19 +155,33 @@ func (r *RedisCache) Get(ctx context.Context, key string) (interface{}, bool) {
 	return value, true
 }
 
+// GetBytes 返回键存储的原始字节
+func (r *RedisCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	start := r.clock.Now()
+	data, err := r.client.Get(ctx, r.getKey(key)).Bytes()
+	r.recordGet(key, start, err)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
 // GetWithTTL 从缓存中检索值及其剩余 TTL
 func (r *RedisCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
 	// 使用管道同时获取值和 TTL
+	start := r.clock.Now()
 	pipe := r.client.Pipeline()
 	valueCmd := pipe.Get(ctx, r.getKey(key))
 	ttlCmd := pipe.TTL(ctx, r.getKey(key))
 
 	_, err := pipe.Exec(ctx)
 	if err != nil && err != redis.Nil {
+		r.recordGet(key, start, err)
 		return nil, 0, false
 	}
 
 	result, err := valueCmd.Result()
+	r.recordGet(key, start, err)
 	if err != nil {
 		if err == redis.Nil {
 			return nil, 0, false
@@ -175,10 +222,13 @@ func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 		}
 	}
 
-	if ttl > 0 {
-		return r.client.Set(ctx, r.getKey(key), data, ttl).Err()
+	if ttl < 0 {
+		ttl = 0
 	}
-	return r.client.Set(ctx, r.getKey(key), data, 0).Err()
+	start := r.clock.Now()
+	err = r.client.Set(ctx, r.getKey(key), data, ttl).Err()
+	r.metrics.RecordSet(key, r.clock.Since(start), err == nil)
+	return err
 }
 
 // SetMultiple 在缓存中存储多个键值对
@@ -217,7 +267,10 @@ func (r *RedisCache) SetMultiple(ctx context.Context, items map[string]interface
 
 // Delete 从缓存中删除键
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, r.getKey(key)).Err()
+	start := r.clock.Now()
+	err := r.client.Del(ctx, r.getKey(key)).Err()
+	r.metrics.RecordDelete(key, r.clock.Since(start), err == nil)
+	return err
 }
 
 // DeleteMultiple 从缓存中删除多个键
@@ -234,6 +287,167 @@ func (r *RedisCache) DeleteMultiple(ctx context.Context, keys []string) error {
 	return r.client.Del(ctx, redisKeys...).Err()
 }
 
+// tagKeyPrefix 标签集合的键前缀，集合成员为关联的完整 Redis 键
+const tagKeyPrefix = "tag:"
+
+// tagInvalidateBatchSize 失效标签时每批弹出并删除的键数量，避免单条命令阻塞 Redis
+const tagInvalidateBatchSize = 500
+
+// setWithTagsScript 原子地写入值并加入各标签集合
+// 标签集合的过期时间只延长不缩短；写入不过期的键时标签集合也不过期
+// KEYS[1] 为值的键，KEYS[2..] 为标签集合的键；ARGV[1] 为值，ARGV[2] 为毫秒TTL（0 表示不过期）
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local current = redis.call('PTTL', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl <= 0 then
+		redis.call('PERSIST', KEYS[i])
+	elseif current == -2 or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+// tagKey 返回标签集合的完整键
+func (r *RedisCache) tagKey(tag string) string {
+	return r.getKey(tagKeyPrefix + tag)
+}
+
+// SetWithTags 存储值并将键加入各标签的 Redis 集合
+func (r *RedisCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return r.Set(ctx, key, value, ttl)
+	}
+
+	var data []byte
+	var err error
+
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		data, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
+	}
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, r.getKey(key))
+	for _, tag := range tags {
+		keys = append(keys, r.tagKey(tag))
+	}
+
+	start := r.clock.Now()
+	err = setWithTagsScript.Run(ctx, r.client, keys, data, ttl.Milliseconds()).Err()
+	r.metrics.RecordSet(key, r.clock.Since(start), err == nil)
+	if err != nil {
+		return fmt.Errorf("failed to set key %s with tags: %w", key, err)
+	}
+	return nil
+}
+
+// InvalidateTag 分批弹出标签集合的成员并删除对应的键
+// 使用 SPOP 逐批取出成员，失效期间并发加入标签的键不会被遗漏
+func (r *RedisCache) InvalidateTag(ctx context.Context, tag string) error {
+	tagKey := r.tagKey(tag)
+	for {
+		keys, err := r.client.SPopN(ctx, tagKey, tagInvalidateBatchSize).Result()
+		if err != nil {
+			return fmt.Errorf("failed to pop members of tag %s: %w", tag, err)
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to delete keys of tag %s: %w", tag, err)
+		}
+	}
+}
+
+// Invalidate 使用流水线删除键并分批弹出各标签集合的成员，弹出成员的删除与下一批弹出在同一次往返中完成
+// 标签集合不超过一批时只需两次往返；不足一批的标签视为已清空，不再继续弹出
+func (r *RedisCache) Invalidate(ctx context.Context, keys []string, tags ...string) error {
+	pending := make([]string, len(keys))
+	for i, key := range keys {
+		pending[i] = r.getKey(key)
+	}
+
+	for len(pending) > 0 || len(tags) > 0 {
+		pipe := r.client.Pipeline()
+		if len(pending) > 0 {
+			pipe.Del(ctx, pending...)
+		}
+		pops := make([]*redis.StringSliceCmd, len(tags))
+		for i, tag := range tags {
+			pops[i] = pipe.SPopN(ctx, r.tagKey(tag), tagInvalidateBatchSize)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to invalidate keys and tags: %w", err)
+		}
+
+		pending = nil
+		var remaining []string
+		for i, pop := range pops {
+			members := pop.Val()
+			pending = append(pending, members...)
+			if len(members) == tagInvalidateBatchSize {
+				remaining = append(remaining, tags[i])
+			}
+		}
+		tags = remaining
+	}
+	return nil
+}
+
+// TagSize 返回标签集合的成员数量
+func (r *RedisCache) TagSize(ctx context.Context, tag string) (int64, error) {
+	size, err := r.client.SCard(ctx, r.tagKey(tag)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count members of tag %s: %w", tag, err)
+	}
+	return size, nil
+}
+
+// ScanTag 使用 SSCAN 分批遍历标签集合，返回去掉前缀的键
+func (r *RedisCache) ScanTag(ctx context.Context, tag string, cursor uint64, count int64) ([]string, uint64, error) {
+	members, next, err := r.client.SScan(ctx, r.tagKey(tag), cursor, "", count).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan members of tag %s: %w", tag, err)
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, strings.TrimPrefix(member, r.prefix))
+	}
+	return keys, next, nil
+}
+
+// UntagKeys 将键从标签集合中移除
+func (r *RedisCache) UntagKeys(ctx context.Context, tag string, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(keys))
+	for i, key := range keys {
+		members[i] = r.getKey(key)
+	}
+	if err := r.client.SRem(ctx, r.tagKey(tag), members...).Err(); err != nil {
+		return fmt.Errorf("failed to untag keys from %s: %w", tag, err)
+	}
+	return nil
+}
+
 // Exists 检查键是否存在于缓存中
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	result, err := r.client.Exists(ctx, r.getKey(key)).Result()
@@ -275,6 +489,19 @@ func (r *RedisCache) Keys(ctx context.Context, pattern string) ([]string, error)
 	return result, nil
 }
 
+// ScanKeys 使用 SCAN 分批遍历匹配模式的键，单次调用只检查约 count 个键，不会阻塞 Redis
+func (r *RedisCache) ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	keys, next, err := r.client.Scan(ctx, cursor, r.prefix+pattern, count).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan keys matching pattern %s: %w", pattern, err)
+	}
+
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, r.prefix)
+	}
+	return keys, next, nil
+}
+
 // GetMultiple 从缓存中检索多个值
 func (r *RedisCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
 	if len(keys) == 0 {
@@ -443,11 +670,14 @@ func (r *RedisCache) GetStats(ctx context.Context) (map[string]interface{}, erro
 	}
 
 	// 测试延迟
-	start := time.Now()
+	start := r.clock.Now()
 	if err := r.client.Ping(ctx).Err(); err == nil {
-		stats["latency_ms"] = time.Since(start).Milliseconds()
+		stats["latency_ms"] = r.clock.Since(start).Milliseconds()
 	}
 
+	// 本实例的命中率、加载和按键前缀的统计
+	stats["metrics"] = r.metrics.Summary()
+
 	return stats, nil
 }
 
@@ -585,6 +815,11 @@ func (r *RedisCache) GetClient() *redis.Client {
 	return r.client
 }
 
+// Capabilities 返回 Redis 驱动支持的能力，Redis 支持全部能力
+func (r *RedisCache) Capabilities() Capability {
+	return AllCapabilities
+}
+
 // SetTTL 为现有键设置或更新 TTL
 func (r *RedisCache) SetTTL(ctx context.Context, key string, ttl time.Duration) error {
 	return r.client.Expire(ctx, r.getKey(key), ttl).Err()
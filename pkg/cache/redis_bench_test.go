@@ -283,6 +283,62 @@ func BenchmarkCacheDelete(b *testing.B) {
 	}
 }
 
+// BenchmarkCacheInvalidate compares deleting a user's keys and invalidating the user and list tags
+// with sequential DeleteMultiple and InvalidateTag calls against a single pipelined Invalidate
+func BenchmarkCacheInvalidate(b *testing.B) {
+	cache := setupBenchmarkCache(b)
+	defer cleanupBenchmarkCache(b, cache)
+
+	ctx := context.Background()
+	keys := []string{"user:id:1", "user:email:alice@example.com", "user:username:alice", "user:exists:email:alice@example.com", "user:exists:username:alice"}
+	tags := []string{"user:1", "users:list"}
+
+	populate := func(b *testing.B) {
+		for _, key := range keys {
+			if err := cache.Set(ctx, key, "value", time.Hour); err != nil {
+				b.Fatalf("Failed to pre-warm cache: %v", err)
+			}
+		}
+		if err := cache.SetWithTags(ctx, "response:1", "value", time.Hour, tags[0]); err != nil {
+			b.Fatalf("Failed to pre-warm cache: %v", err)
+		}
+		if err := cache.SetWithTags(ctx, "users:list:0:10", "value", time.Hour, tags[1]); err != nil {
+			b.Fatalf("Failed to pre-warm cache: %v", err)
+		}
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			populate(b)
+			b.StartTimer()
+
+			if err := cache.DeleteMultiple(ctx, keys); err != nil {
+				b.Fatalf("Failed to delete keys: %v", err)
+			}
+			for _, tag := range tags {
+				if err := cache.InvalidateTag(ctx, tag); err != nil {
+					b.Fatalf("Failed to invalidate tag: %v", err)
+				}
+			}
+		}
+	})
+
+	b.Run("pipelined", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			populate(b)
+			b.StartTimer()
+
+			if err := Invalidate(ctx, cache, keys, tags...); err != nil {
+				b.Fatalf("Failed to invalidate: %v", err)
+			}
+		}
+	})
+}
+
 // BenchmarkCacheExists benchmarks cache Exists operations
 func BenchmarkCacheExists(b *testing.B) {
 	cache := setupBenchmarkCache(b)
@@ -322,6 +322,100 @@ func TestRedisCache_KeysAndClear(t *testing.T) {
 	assert.Len(t, keys, 0)
 }
 
+// TestRedisCache_Tags tests tag-based invalidation
+func TestRedisCache_Tags(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Host = "localhost"
+	config.Port = 6379
+	config.DB = 1
+	config.Prefix = "test_tags:"
+
+	cache, err := NewRedisCache(config)
+	if err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+		return
+	}
+	defer cache.Close()
+	defer cache.Clear(context.Background())
+
+	ctx := context.Background()
+
+	require.NoError(t, cache.SetWithTags(ctx, "list:1", "page1", time.Minute, "lists"))
+	require.NoError(t, cache.SetWithTags(ctx, "list:2", map[string]int{"total": 2}, 2*time.Minute, "lists", "other"))
+	require.NoError(t, cache.SetWithTags(ctx, "untagged", "value", time.Minute))
+
+	// The tag set lives at least as long as its longest-lived key
+	redisCache := cache.(*RedisCache)
+	ttl, err := redisCache.GetTTL(ctx, tagKeyPrefix+"lists")
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Minute)
+
+	// The tag index can be counted and scanned without KEYS
+	size, err := redisCache.TagSize(ctx, "lists")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), size)
+	keys, cursor, err := redisCache.ScanTag(ctx, "lists", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), cursor)
+	assert.ElementsMatch(t, []string{"list:1", "list:2"}, keys)
+	require.NoError(t, redisCache.UntagKeys(ctx, "other", "list:2"))
+	size, err = redisCache.TagSize(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	require.NoError(t, cache.InvalidateTag(ctx, "lists"))
+
+	_, found := cache.Get(ctx, "list:1")
+	assert.False(t, found)
+	_, found = cache.Get(ctx, "list:2")
+	assert.False(t, found)
+	_, found = cache.Get(ctx, "untagged")
+	assert.True(t, found)
+
+	exists, err := cache.Exists(ctx, tagKeyPrefix+"lists")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Invalidating an unknown tag is a no-op
+	assert.NoError(t, cache.InvalidateTag(ctx, "missing"))
+}
+
+// TestRedisCache_Invalidate tests pipelined invalidation of keys and tags
+func TestRedisCache_Invalidate(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Host = "localhost"
+	config.Port = 6379
+	config.DB = 1
+	config.Prefix = "test_invalidate:"
+
+	cache, err := NewRedisCache(config)
+	if err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+		return
+	}
+	defer cache.Close()
+	defer cache.Clear(context.Background())
+
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "user:id:1", "alice", time.Minute))
+	require.NoError(t, cache.SetWithTags(ctx, "response:1", "cached", time.Minute, "user:1"))
+	// More members than one batch, so the tag is popped over several round trips
+	for i := 0; i < tagInvalidateBatchSize+10; i++ {
+		require.NoError(t, cache.SetWithTags(ctx, fmt.Sprintf("users:list:%d", i), "page", time.Minute, "users:list"))
+	}
+	require.NoError(t, cache.Set(ctx, "kept", "value", time.Minute))
+
+	require.NoError(t, Invalidate(ctx, cache, []string{"user:id:1", "missing"}, "user:1", "users:list", "unknown"))
+
+	keys, err := cache.Keys(ctx, "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept"}, keys)
+
+	// Nothing to invalidate is a no-op
+	assert.NoError(t, cache.(*RedisCache).Invalidate(ctx, nil))
+}
+
 // MockRedisClient is a mock implementation of redis.Client for testing error scenarios
 type MockRedisClient struct {
 	mock.Mock
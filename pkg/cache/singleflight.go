@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// singleflightCall 一次正在进行或已完成的调用
+type singleflightCall struct {
+	wg   sync.WaitGroup
+	val  interface{}
+	err  error
+	dups int
+}
+
+// Group 合并同一键的并发调用，同一时刻只有一个调用真正执行，其余调用等待并共享结果
+// 零值可直接使用
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+}
+
+// Do 执行并返回 fn 的结果，同一键的并发调用只执行一次
+// shared 表示结果是否被多个调用方共享
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*singleflightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err, true
+	}
+
+	call := &singleflightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	g.doCall(call, key, fn)
+	return call.val, call.err, call.dups > 0
+}
+
+// doCall 执行调用，fn 发生 panic 时转换为错误，避免等待中的调用方永久阻塞
+func (g *Group) doCall(call *singleflightCall, key string, fn func() (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("singleflight: panic in load of %q: %v", key, r)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.val, call.err = fn()
+}
+
+// Forget 使后续对该键的调用不再等待正在进行的调用
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
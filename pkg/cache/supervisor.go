@@ -0,0 +1,578 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable 缓存处于降级模式，操作被直接跳过
+var ErrUnavailable = errors.New("cache: unavailable, running in pass-through mode")
+
+// SupervisorState 缓存健康状态
+type SupervisorState int32
+
+const (
+	// StateHealthy 缓存正常，请求访问缓存
+	StateHealthy SupervisorState = iota
+	// StateDegraded 缓存连续失败，请求跳过缓存直接访问数据源，后台探测恢复
+	StateDegraded
+)
+
+// String 返回状态名称
+func (s SupervisorState) String() string {
+	if s == StateDegraded {
+		return "degraded"
+	}
+	return "healthy"
+}
+
+// 降级判定和恢复探测的默认值
+const (
+	DefaultFailureThreshold        = 5
+	DefaultProbeInterval           = 5 * time.Second
+	DefaultProbeTimeout            = time.Second
+	DefaultMaxPendingInvalidations = 10000
+)
+
+// SupervisorConfig 缓存健康监督配置
+type SupervisorConfig struct {
+	FailureThreshold        int           // 连续失败多少次后进入降级模式
+	ProbeInterval           time.Duration // 降级期间探测恢复的间隔
+	ProbeTimeout            time.Duration // 单次探测的超时时间
+	MaxPendingInvalidations int           // 降级期间最多记录多少个待补删的键和标签
+
+	// OnStateChange 状态切换时调用，err 为触发降级的最后一个错误，恢复时为 nil
+	OnStateChange func(from, to SupervisorState, err error)
+}
+
+// SupervisorStats 缓存健康监督统计
+type SupervisorStats struct {
+	State               string    `json:"state" example:"healthy"`           // 当前状态
+	Since               time.Time `json:"since"`                             // 进入当前状态的时间
+	ConsecutiveFailures int64     `json:"consecutive_failures" example:"0"`  // 当前连续失败次数
+	Transitions         uint64    `json:"transitions" example:"2"`           // 状态切换次数
+	SkippedOperations   uint64    `json:"skipped_operations" example:"1280"` // 降级期间跳过的缓存操作数
+	Probes              uint64    `json:"probes" example:"12"`               // 恢复探测次数
+	PendingInvalidation int       `json:"pending_invalidations" example:"0"` // 等待恢复后补删的键和标签数
+	LostInvalidations   uint64    `json:"lost_invalidations" example:"0"`    // 超出上限或补删失败的失效操作数，这些条目只能等待过期
+	LastError           string    `json:"last_error,omitempty"`              // 最近一次失败的原因
+}
+
+// Supervisor 缓存健康监督器
+// 包装 Cache，统计连续的连接类错误（超时、连接被拒绝等，缓存未命中和 Redis 返回的命令错误不计入），
+// 达到阈值后进入降级模式：所有操作直接返回未命中或 ErrUnavailable，不再为每个请求等待缓存超时；
+// 后台按间隔调用 Health 探测，成功后恢复正常，并补删降级期间跳过的失效操作，避免恢复后读到旧数据。
+// 包装 RedisCache 时通过 go-redis 的钩子观察客户端上的所有命令，包括 Get 这类不返回错误的读操作
+type Supervisor struct {
+	cache  Cache
+	config SupervisorConfig
+
+	state    atomic.Int32
+	failures atomic.Int64
+	// observesClient 为 true 时由客户端钩子统计成功和失败，包装方法不再重复统计
+	observesClient bool
+
+	mu          sync.Mutex
+	since       time.Time
+	lastError   error
+	pendingKeys map[string]struct{}
+	pendingTags map[string]struct{}
+
+	transitions atomic.Uint64
+	skipped     atomic.Uint64
+	probes      atomic.Uint64
+	lost        atomic.Uint64
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewSupervisor 创建缓存健康监督器，调用 Start 后开始探测恢复
+func NewSupervisor(c Cache, config SupervisorConfig) *Supervisor {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultFailureThreshold
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = DefaultProbeInterval
+	}
+	if config.ProbeTimeout <= 0 {
+		config.ProbeTimeout = DefaultProbeTimeout
+	}
+	if config.MaxPendingInvalidations <= 0 {
+		config.MaxPendingInvalidations = DefaultMaxPendingInvalidations
+	}
+
+	s := &Supervisor{
+		cache:       c,
+		config:      config,
+		since:       time.Now(),
+		pendingKeys: make(map[string]struct{}),
+		pendingTags: make(map[string]struct{}),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	if redisCache, ok := c.(*RedisCache); ok {
+		redisCache.client.AddHook(supervisorHook{s})
+		s.observesClient = true
+	}
+	return s
+}
+
+// Start 启动后台恢复探测
+func (s *Supervisor) Start() {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Stop 停止后台恢复探测，不关闭被包装的缓存
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.startOnce.Do(func() {
+		close(s.done)
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// State 返回当前状态
+func (s *Supervisor) State() SupervisorState {
+	return SupervisorState(s.state.Load())
+}
+
+// Available 判断缓存是否可用，降级期间返回 false
+func (s *Supervisor) Available() bool {
+	return s.State() == StateHealthy
+}
+
+// Stats 返回监督统计
+func (s *Supervisor) Stats() SupervisorStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SupervisorStats{
+		State:               s.State().String(),
+		Since:               s.since,
+		ConsecutiveFailures: s.failures.Load(),
+		Transitions:         s.transitions.Load(),
+		SkippedOperations:   s.skipped.Load(),
+		Probes:              s.probes.Load(),
+		PendingInvalidation: len(s.pendingKeys) + len(s.pendingTags),
+		LostInvalidations:   s.lost.Load(),
+	}
+	if s.lastError != nil {
+		stats.LastError = s.lastError.Error()
+	}
+	return stats
+}
+
+// RecordResult 记录一次缓存访问的结果，供未被钩子覆盖的调用方（如直接使用客户端的组件）上报
+func (s *Supervisor) RecordResult(err error) {
+	if !isConnectionError(err) {
+		s.failures.Store(0)
+		return
+	}
+
+	s.mu.Lock()
+	s.lastError = err
+	s.mu.Unlock()
+
+	if s.failures.Add(1) >= int64(s.config.FailureThreshold) {
+		s.degrade(err)
+	}
+}
+
+// degrade 进入降级模式
+func (s *Supervisor) degrade(err error) {
+	if s.state.CompareAndSwap(int32(StateHealthy), int32(StateDegraded)) {
+		s.transition(StateHealthy, StateDegraded, err)
+	}
+}
+
+// recover 补删降级期间跳过的失效操作后恢复正常
+// 先补删再恢复，避免请求读到降级期间已失效的条目；切换前后仍在降级的请求记录的失效操作在切换后再补删一次
+func (s *Supervisor) recover(ctx context.Context) {
+	s.flushInvalidations(ctx)
+
+	s.failures.Store(0)
+	if s.state.CompareAndSwap(int32(StateDegraded), int32(StateHealthy)) {
+		s.transition(StateDegraded, StateHealthy, nil)
+	}
+
+	s.flushInvalidations(ctx)
+}
+
+// flushInvalidations 执行记录的失效操作，失败的计入丢失数
+func (s *Supervisor) flushInvalidations(ctx context.Context) {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.pendingKeys))
+	for key := range s.pendingKeys {
+		keys = append(keys, key)
+	}
+	tags := make([]string, 0, len(s.pendingTags))
+	for tag := range s.pendingTags {
+		tags = append(tags, tag)
+	}
+	s.pendingKeys = make(map[string]struct{})
+	s.pendingTags = make(map[string]struct{})
+	s.mu.Unlock()
+
+	if len(keys) > 0 {
+		if err := s.cache.DeleteMultiple(ctx, keys); err != nil {
+			s.lost.Add(uint64(len(keys)))
+		}
+	}
+	for _, tag := range tags {
+		if err := s.cache.InvalidateTag(ctx, tag); err != nil {
+			s.lost.Add(1)
+		}
+	}
+}
+
+// transition 记录状态切换并通知回调
+func (s *Supervisor) transition(from, to SupervisorState, err error) {
+	s.mu.Lock()
+	s.since = time.Now()
+	s.mu.Unlock()
+	s.transitions.Add(1)
+
+	if s.config.OnStateChange != nil {
+		s.config.OnStateChange(from, to, err)
+	}
+}
+
+// run 降级期间按间隔探测缓存，探测成功后恢复
+func (s *Supervisor) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if s.State() != StateDegraded {
+			continue
+		}
+		s.probe()
+	}
+}
+
+// probe 执行一次恢复探测
+func (s *Supervisor) probe() {
+	s.probes.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ProbeTimeout)
+	defer cancel()
+
+	if err := s.cache.Health(ctx); err != nil {
+		s.mu.Lock()
+		s.lastError = err
+		s.mu.Unlock()
+		return
+	}
+	s.recover(ctx)
+}
+
+// skip 判断操作是否应跳过，跳过时计数
+func (s *Supervisor) skip() bool {
+	if s.Available() {
+		return false
+	}
+	s.skipped.Add(1)
+	return true
+}
+
+// observe 记录包装方法返回的错误，被钩子覆盖时忽略
+func (s *Supervisor) observe(err error) error {
+	if !s.observesClient {
+		s.RecordResult(err)
+	}
+	return err
+}
+
+// deferInvalidation 记录降级期间跳过的删除，恢复后补删
+func (s *Supervisor) deferInvalidation(keys []string, tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if len(s.pendingKeys)+len(s.pendingTags) >= s.config.MaxPendingInvalidations {
+			s.lost.Add(1)
+			continue
+		}
+		s.pendingKeys[key] = struct{}{}
+	}
+	for _, tag := range tags {
+		if len(s.pendingKeys)+len(s.pendingTags) >= s.config.MaxPendingInvalidations {
+			s.lost.Add(1)
+			continue
+		}
+		s.pendingTags[tag] = struct{}{}
+	}
+}
+
+// isConnectionError 判断错误是否说明缓存服务不可用
+// 未命中、调用方取消以及 Redis 返回的命令错误（服务仍在响应）不计为失败
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, ErrNotSupported) {
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return false
+	}
+	return true
+}
+
+// supervisorHook 观察 Redis 客户端执行的命令
+type supervisorHook struct {
+	s *Supervisor
+}
+
+func (h supervisorHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.s.RecordResult(err)
+		}
+		return conn, err
+	}
+}
+
+func (h supervisorHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		h.s.RecordResult(err)
+		return err
+	}
+}
+
+func (h supervisorHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		h.s.RecordResult(err)
+		return err
+	}
+}
+
+// Get 从缓存中检索值，降级期间返回未命中
+func (s *Supervisor) Get(ctx context.Context, key string) (interface{}, bool) {
+	if s.skip() {
+		return nil, false
+	}
+	return s.cache.Get(ctx, key)
+}
+
+// GetBytes 返回键存储的原始字节，降级期间返回未命中
+func (s *Supervisor) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	if s.skip() {
+		return nil, false
+	}
+	return s.cache.GetBytes(ctx, key)
+}
+
+// GetWithTTL 检索值及其剩余TTL，降级期间返回未命中
+func (s *Supervisor) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	if s.skip() {
+		return nil, 0, false
+	}
+	return s.cache.GetWithTTL(ctx, key)
+}
+
+// Set 存储值
+func (s *Supervisor) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if s.skip() {
+		return ErrUnavailable
+	}
+	return s.observe(s.cache.Set(ctx, key, value, ttl))
+}
+
+// SetMultiple 存储多个键值对
+func (s *Supervisor) SetMultiple(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if s.skip() {
+		return ErrUnavailable
+	}
+	return s.observe(s.cache.SetMultiple(ctx, items, ttl))
+}
+
+// Delete 删除键，降级期间记录下来在恢复后补删
+func (s *Supervisor) Delete(ctx context.Context, key string) error {
+	return s.DeleteMultiple(ctx, []string{key})
+}
+
+// DeleteMultiple 删除多个键，降级期间记录下来在恢复后补删
+func (s *Supervisor) DeleteMultiple(ctx context.Context, keys []string) error {
+	if s.skip() {
+		s.deferInvalidation(keys, nil)
+		return ErrUnavailable
+	}
+	err := s.observe(s.cache.DeleteMultiple(ctx, keys))
+	if isConnectionError(err) {
+		s.deferInvalidation(keys, nil)
+	}
+	return err
+}
+
+// SetWithTags 存储值并关联标签
+func (s *Supervisor) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if s.skip() {
+		return ErrUnavailable
+	}
+	return s.observe(s.cache.SetWithTags(ctx, key, value, ttl, tags...))
+}
+
+// InvalidateTag 删除标签关联的所有键，降级期间记录下来在恢复后补删
+func (s *Supervisor) InvalidateTag(ctx context.Context, tag string) error {
+	if s.skip() {
+		s.deferInvalidation(nil, []string{tag})
+		return ErrUnavailable
+	}
+	err := s.observe(s.cache.InvalidateTag(ctx, tag))
+	if isConnectionError(err) {
+		s.deferInvalidation(nil, []string{tag})
+	}
+	return err
+}
+
+// Invalidate 删除键并失效标签，底层驱动支持时合并为一次批量操作；降级期间记录下来在恢复后补删
+func (s *Supervisor) Invalidate(ctx context.Context, keys []string, tags ...string) error {
+	if s.skip() {
+		s.deferInvalidation(keys, tags)
+		return ErrUnavailable
+	}
+	err := s.observe(Invalidate(ctx, s.cache, keys, tags...))
+	if isConnectionError(err) {
+		s.deferInvalidation(keys, tags)
+	}
+	return err
+}
+
+// Exists 检查键是否存在
+func (s *Supervisor) Exists(ctx context.Context, key string) (bool, error) {
+	if s.skip() {
+		return false, ErrUnavailable
+	}
+	exists, err := s.cache.Exists(ctx, key)
+	return exists, s.observe(err)
+}
+
+// Clear 删除所有键
+func (s *Supervisor) Clear(ctx context.Context) error {
+	if s.skip() {
+		return ErrUnavailable
+	}
+	return s.observe(s.cache.Clear(ctx))
+}
+
+// Keys 返回匹配模式的所有键
+func (s *Supervisor) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if s.skip() {
+		return nil, ErrUnavailable
+	}
+	keys, err := s.cache.Keys(ctx, pattern)
+	return keys, s.observe(err)
+}
+
+// ScanKeys 分批遍历匹配模式的键，被包装的缓存不支持时返回 ErrNotSupported
+func (s *Supervisor) ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	scanner, ok := s.cache.(KeyScanner)
+	if !ok {
+		return nil, 0, ErrNotSupported
+	}
+	if s.skip() {
+		return nil, 0, ErrUnavailable
+	}
+	keys, next, err := scanner.ScanKeys(ctx, pattern, cursor, count)
+	return keys, next, s.observe(err)
+}
+
+// GetMultiple 检索多个值，降级期间返回空结果
+func (s *Supervisor) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	if s.skip() {
+		return map[string]interface{}{}, nil
+	}
+	values, err := s.cache.GetMultiple(ctx, keys)
+	return values, s.observe(err)
+}
+
+// SetIfNotExists 仅在键不存在时设置值
+func (s *Supervisor) SetIfNotExists(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if s.skip() {
+		return false, ErrUnavailable
+	}
+	set, err := s.cache.SetIfNotExists(ctx, key, value, ttl)
+	return set, s.observe(err)
+}
+
+// Increment 递增键的数值
+func (s *Supervisor) Increment(ctx context.Context, key string, amount int64) (int64, error) {
+	if s.skip() {
+		return 0, ErrUnavailable
+	}
+	value, err := s.cache.Increment(ctx, key, amount)
+	return value, s.observe(err)
+}
+
+// Decrement 递减键的数值
+func (s *Supervisor) Decrement(ctx context.Context, key string, amount int64) (int64, error) {
+	if s.skip() {
+		return 0, ErrUnavailable
+	}
+	value, err := s.cache.Decrement(ctx, key, amount)
+	return value, s.observe(err)
+}
+
+// Close 关闭被包装的缓存
+func (s *Supervisor) Close() error {
+	return s.cache.Close()
+}
+
+// Health 检查缓存健康状况，降级期间直接返回 ErrUnavailable，由后台探测负责检测恢复
+func (s *Supervisor) Health(ctx context.Context) error {
+	if !s.Available() {
+		return ErrUnavailable
+	}
+	return s.cache.Health(ctx)
+}
+
+// GetStats 返回缓存统计，包含监督统计；降级期间只返回监督统计
+func (s *Supervisor) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	stats := map[string]interface{}{}
+	if s.Available() {
+		inner, err := s.cache.GetStats(ctx)
+		if err != nil {
+			return nil, s.observe(err)
+		}
+		for key, value := range inner {
+			stats[key] = value
+		}
+	}
+	stats["supervisor"] = s.Stats()
+	return stats, nil
+}
+
+// Capabilities 返回被包装缓存的能力
+func (s *Supervisor) Capabilities() Capability {
+	return CapabilitiesOf(s.cache)
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnectionRefused = errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+
+// outageCache 可模拟故障的缓存，统计实际到达的调用
+type outageCache struct {
+	*MockCache
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (o *outageCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	o.calls.Add(1)
+	if o.down.Load() {
+		return errConnectionRefused
+	}
+	return o.MockCache.Set(ctx, key, value, ttl)
+}
+
+func (o *outageCache) GetBytes(ctx context.Context, key string) ([]byte, bool) {
+	o.calls.Add(1)
+	if o.down.Load() {
+		return nil, false
+	}
+	return o.MockCache.GetBytes(ctx, key)
+}
+
+func (o *outageCache) DeleteMultiple(ctx context.Context, keys []string) error {
+	o.calls.Add(1)
+	if o.down.Load() {
+		return errConnectionRefused
+	}
+	return o.MockCache.DeleteMultiple(ctx, keys)
+}
+
+func (o *outageCache) InvalidateTag(ctx context.Context, tag string) error {
+	o.calls.Add(1)
+	if o.down.Load() {
+		return errConnectionRefused
+	}
+	return o.MockCache.InvalidateTag(ctx, tag)
+}
+
+func (o *outageCache) Health(ctx context.Context) error {
+	if o.down.Load() {
+		return errConnectionRefused
+	}
+	return nil
+}
+
+func TestSupervisor_DegradesAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	inner := &outageCache{MockCache: NewMockCache()}
+
+	var transitions []SupervisorState
+	transitioned := make(chan SupervisorState, 4)
+	supervisor := NewSupervisor(inner, SupervisorConfig{
+		FailureThreshold: 3,
+		ProbeInterval:    10 * time.Millisecond,
+		OnStateChange: func(from, to SupervisorState, err error) {
+			transitioned <- to
+		},
+	})
+	supervisor.Start()
+	defer supervisor.Stop(context.Background())
+
+	require.NoError(t, supervisor.Set(ctx, "user:1", "stale", time.Minute))
+	require.NoError(t, supervisor.Set(ctx, "user:2", "stale", time.Minute))
+
+	// 连续失败达到阈值后进入降级模式
+	inner.down.Store(true)
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, supervisor.Set(ctx, "user:3", "value", time.Minute), errConnectionRefused)
+	}
+	transitions = append(transitions, <-transitioned)
+	assert.Equal(t, StateDegraded, supervisor.State())
+
+	// 降级期间不再访问缓存
+	calls := inner.calls.Load()
+	_, found := supervisor.GetBytes(ctx, "user:1")
+	assert.False(t, found)
+	assert.ErrorIs(t, supervisor.Set(ctx, "user:1", "value", time.Minute), ErrUnavailable)
+	assert.ErrorIs(t, supervisor.Delete(ctx, "user:1"), ErrUnavailable)
+	assert.ErrorIs(t, supervisor.InvalidateTag(ctx, "users"), ErrUnavailable)
+	assert.ErrorIs(t, supervisor.Health(ctx), ErrUnavailable)
+	assert.Equal(t, calls, inner.calls.Load())
+
+	stats := supervisor.Stats()
+	assert.Equal(t, "degraded", stats.State)
+	assert.Equal(t, uint64(4), stats.SkippedOperations)
+	assert.Equal(t, 2, stats.PendingInvalidation)
+	assert.Contains(t, stats.LastError, "connection refused")
+
+	// 探测成功后恢复，并补删降级期间跳过的失效操作
+	inner.down.Store(false)
+	transitions = append(transitions, <-transitioned)
+	assert.Equal(t, []SupervisorState{StateDegraded, StateHealthy}, transitions)
+	assert.True(t, supervisor.Available())
+
+	_, found = supervisor.GetBytes(ctx, "user:1")
+	assert.False(t, found, "降级期间删除的键应在恢复后补删")
+	_, found = supervisor.GetBytes(ctx, "user:2")
+	assert.True(t, found)
+
+	stats = supervisor.Stats()
+	assert.Equal(t, uint64(2), stats.Transitions)
+	assert.Zero(t, stats.PendingInvalidation)
+	assert.Zero(t, stats.ConsecutiveFailures)
+}
+
+func TestSupervisor_SuccessResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	inner := &outageCache{MockCache: NewMockCache()}
+	supervisor := NewSupervisor(inner, SupervisorConfig{FailureThreshold: 3})
+
+	for i := 0; i < 5; i++ {
+		inner.down.Store(true)
+		_ = supervisor.Set(ctx, "key", "value", time.Minute)
+		_ = supervisor.Set(ctx, "key", "value", time.Minute)
+		inner.down.Store(false)
+		require.NoError(t, supervisor.Set(ctx, "key", "value", time.Minute))
+	}
+	assert.True(t, supervisor.Available())
+}
+
+func TestSupervisor_PendingInvalidationsAreBounded(t *testing.T) {
+	ctx := context.Background()
+	inner := &outageCache{MockCache: NewMockCache()}
+	supervisor := NewSupervisor(inner, SupervisorConfig{FailureThreshold: 1, MaxPendingInvalidations: 2})
+
+	inner.down.Store(true)
+	_ = supervisor.Set(ctx, "key", "value", time.Minute)
+	require.False(t, supervisor.Available())
+
+	assert.ErrorIs(t, supervisor.DeleteMultiple(ctx, []string{"a", "b", "c"}), ErrUnavailable)
+	stats := supervisor.Stats()
+	assert.Equal(t, 2, stats.PendingInvalidation)
+	assert.Equal(t, uint64(1), stats.LostInvalidations)
+}
+
+func TestSupervisor_ObservesRedisClient(t *testing.T) {
+	// 没有服务监听的端口，连接立即被拒绝
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	supervisor := NewSupervisor(NewRedisCacheWithClient(client, "test:"), SupervisorConfig{FailureThreshold: 2})
+
+	// Get 不返回错误，由客户端钩子统计失败
+	ctx := context.Background()
+	supervisor.Get(ctx, "key")
+	supervisor.Get(ctx, "key")
+	assert.Equal(t, StateDegraded, supervisor.State())
+
+	stats, err := supervisor.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "degraded", stats["supervisor"].(SupervisorStats).State)
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.False(t, isConnectionError(nil))
+	assert.False(t, isConnectionError(redis.Nil))
+	assert.False(t, isConnectionError(context.Canceled))
+	assert.False(t, isConnectionError(ErrNotSupported))
+	assert.True(t, isConnectionError(context.DeadlineExceeded))
+	assert.True(t, isConnectionError(errConnectionRefused))
+}
+
+func TestSupervisor_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	inner := &outageCache{MockCache: NewMockCache()}
+	supervisor := NewSupervisor(inner, SupervisorConfig{FailureThreshold: 1, MaxPendingInvalidations: 10})
+
+	// 底层驱动不支持批量失效时依次删除键和失效标签
+	require.NoError(t, supervisor.Invalidate(ctx, []string{"a", "b"}, "users"))
+	assert.Equal(t, int32(2), inner.calls.Load())
+
+	inner.down.Store(true)
+	assert.ErrorIs(t, supervisor.Invalidate(ctx, []string{"a", "b"}, "users", "lists"), errConnectionRefused)
+	require.False(t, supervisor.Available())
+	assert.Equal(t, 4, supervisor.Stats().PendingInvalidation, "连接失败的键和标签在恢复后补删")
+
+	assert.ErrorIs(t, supervisor.Invalidate(ctx, []string{"c"}, "other"), ErrUnavailable)
+	assert.Equal(t, 6, supervisor.Stats().PendingInvalidation)
+}
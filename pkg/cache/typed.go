@@ -0,0 +1,49 @@
+package cache
+
+import "context"
+
+// GetAs 读取键并解码为 T，适用于通过 Set 写入的值
+//
+// Set 将字符串和字节切片原样存储、其他值编码为 JSON，GetAs 按相同规则还原，
+// 因此命中时返回与写入时相同的 Go 类型，而不是 Get 返回的 map[string]interface{} 或 float64。
+// 未命中或解码失败时返回 false
+func GetAs[T any](ctx context.Context, c Cache, key string) (T, bool) {
+	var value T
+
+	data, found := c.GetBytes(ctx, key)
+	if !found {
+		return value, false
+	}
+
+	switch target := any(&value).(type) {
+	case *string:
+		*target = string(data)
+		return value, true
+	case *[]byte:
+		*target = data
+		return value, true
+	}
+
+	if err := (JSONCodec{}).Unmarshal(data, &value); err != nil {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
+// GetAsWith 读取键并使用给定编码解码为 T，适用于通过同一 Codec 编码后写入的值
+// 未命中或解码失败时返回 false
+func GetAsWith[T any](ctx context.Context, c Cache, codec Codec, key string) (T, bool) {
+	var value T
+
+	data, found := c.GetBytes(ctx, key)
+	if !found {
+		return value, false
+	}
+
+	if err := codec.Unmarshal(data, &value); err != nil {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
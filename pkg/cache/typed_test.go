@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestUser struct {
+	ID        string    `json:"id"`
+	Logins    int64     `json:"logins"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestGetAs(t *testing.T) {
+	ctx := context.Background()
+	c := NewMockCache()
+
+	user := typedTestUser{ID: "user-1", Logins: 1<<53 + 1, CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	require.NoError(t, c.Set(ctx, "user", user, time.Minute))
+	require.NoError(t, c.Set(ctx, "count", int64(42), time.Minute))
+	require.NoError(t, c.Set(ctx, "name", "plain text", time.Minute))
+
+	cached, found := GetAs[typedTestUser](ctx, c, "user")
+	require.True(t, found)
+	assert.Equal(t, user.ID, cached.ID)
+	assert.Equal(t, user.Logins, cached.Logins)
+	assert.True(t, user.CreatedAt.Equal(cached.CreatedAt))
+
+	pointer, found := GetAs[*typedTestUser](ctx, c, "user")
+	require.True(t, found)
+	assert.Equal(t, "user-1", pointer.ID)
+
+	count, found := GetAs[int64](ctx, c, "count")
+	require.True(t, found)
+	assert.Equal(t, int64(42), count)
+
+	// Strings are stored verbatim by Set
+	name, found := GetAs[string](ctx, c, "name")
+	require.True(t, found)
+	assert.Equal(t, "plain text", name)
+
+	_, found = GetAs[typedTestUser](ctx, c, "missing")
+	assert.False(t, found)
+
+	// Values that don't decode into T are misses
+	_, found = GetAs[int64](ctx, c, "user")
+	assert.False(t, found)
+}
+
+func TestGetAsWith(t *testing.T) {
+	ctx := context.Background()
+	c := NewMockCache()
+	codec := GobCodec{}
+
+	data, err := codec.Marshal(typedTestUser{ID: "user-1", Logins: 7})
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "user", data, time.Minute))
+
+	user, found := GetAsWith[typedTestUser](ctx, c, codec, "user")
+	require.True(t, found)
+	assert.Equal(t, "user-1", user.ID)
+	assert.Equal(t, int64(7), user.Logins)
+
+	// Decoding with a different codec fails and is treated as a miss
+	_, found = GetAsWith[typedTestUser](ctx, c, JSONCodec{}, "user")
+	assert.False(t, found)
+}
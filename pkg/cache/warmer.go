@@ -0,0 +1,292 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrWarmupInProgress 已有预热任务在执行
+var ErrWarmupInProgress = errors.New("cache: warm-up already in progress")
+
+// ErrUnknownDataset 预热的数据集未注册
+var ErrUnknownDataset = errors.New("cache: unknown warm-up dataset")
+
+// WarmProgress 报告数据集的预热进度，total 未知时为 0
+type WarmProgress func(done, total int)
+
+// WarmFunc 将一个数据集加载到缓存
+type WarmFunc func(ctx context.Context, progress WarmProgress) error
+
+// WarmState 预热任务或数据集的状态
+type WarmState string
+
+const (
+	WarmStatePending   WarmState = "pending"
+	WarmStateRunning   WarmState = "running"
+	WarmStateCompleted WarmState = "completed"
+	WarmStateFailed    WarmState = "failed"
+)
+
+// 预热触发方式
+const (
+	WarmTriggerStartup = "startup"
+	WarmTriggerAPI     = "api"
+)
+
+// WarmDatasetStatus 单个数据集的预热进度
+type WarmDatasetStatus struct {
+	Name       string    `json:"name" example:"users"`       // 数据集名称
+	State      WarmState `json:"state" example:"running"`    // 状态
+	Done       int       `json:"done" example:"40"`          // 已预热的条目数
+	Total      int       `json:"total" example:"100"`        // 条目总数，未知时为 0
+	Error      string    `json:"error,omitempty"`            // 失败原因
+	DurationMS int64     `json:"duration_ms" example:"1250"` // 耗时（毫秒）
+	startedAt  time.Time
+}
+
+// WarmStatus 一次预热任务的进度
+type WarmStatus struct {
+	ID         int                 `json:"id" example:"3"`          // 任务编号，每次预热递增
+	Trigger    string              `json:"trigger" example:"api"`   // 触发方式：startup 或 api
+	State      WarmState           `json:"state" example:"running"` // 状态
+	StartedAt  time.Time           `json:"started_at"`              // 开始时间
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`   // 结束时间
+	Datasets   []WarmDatasetStatus `json:"datasets"`                // 各数据集的进度
+}
+
+// Warmer 缓存预热器
+//
+// 启动时或按需将注册的热点数据集加载到缓存，避免冷启动时大量请求同时访问数据库。
+// 同一时刻只执行一个预热任务，数据集按注册顺序依次预热，单个数据集失败不影响其他数据集
+type Warmer struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	order   []string
+	tasks   map[string]WarmFunc
+	status  *WarmStatus
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewWarmer 创建缓存预热器，timeout 为单次预热任务的超时时间，0 表示不限制
+func NewWarmer(timeout time.Duration) *Warmer {
+	return &Warmer{
+		timeout: timeout,
+		tasks:   make(map[string]WarmFunc),
+	}
+}
+
+// Register 注册数据集，重复注册时替换原有的加载函数
+func (w *Warmer) Register(name string, fn WarmFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.tasks[name]; !exists {
+		w.order = append(w.order, name)
+	}
+	w.tasks[name] = fn
+}
+
+// Datasets 返回按注册顺序排列的数据集名称
+func (w *Warmer) Datasets() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.order...)
+}
+
+// Start 在后台预热给定数据集，names 为空时预热全部数据集，返回任务的初始状态
+// 预热不随 ctx 取消而中止，调用 Close 可中止正在进行的预热
+func (w *Warmer) Start(ctx context.Context, trigger string, names []string) (WarmStatus, error) {
+	runCtx, status, err := w.begin(ctx, trigger, names)
+	if err != nil {
+		return WarmStatus{}, err
+	}
+
+	go w.run(runCtx)
+	return status, nil
+}
+
+// Run 同步预热给定数据集，names 为空时预热全部数据集，返回任务的最终状态
+// 有数据集预热失败时返回错误
+func (w *Warmer) Run(ctx context.Context, trigger string, names []string) (WarmStatus, error) {
+	runCtx, _, err := w.begin(ctx, trigger, names)
+	if err != nil {
+		return WarmStatus{}, err
+	}
+
+	w.run(runCtx)
+
+	status, _ := w.Status()
+	if status.State == WarmStateFailed {
+		var errs []error
+		for _, dataset := range status.Datasets {
+			if dataset.Error != "" {
+				errs = append(errs, fmt.Errorf("%s: %s", dataset.Name, dataset.Error))
+			}
+		}
+		return status, fmt.Errorf("cache warm-up failed: %w", errors.Join(errs...))
+	}
+	return status, nil
+}
+
+// Status 返回当前或最近一次预热任务的状态，尚未预热过时返回 false
+func (w *Warmer) Status() (WarmStatus, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status == nil {
+		return WarmStatus{}, false
+	}
+	return w.snapshot(), true
+}
+
+// Close 中止正在进行的预热并等待其结束
+func (w *Warmer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin 校验数据集并登记新的预热任务
+func (w *Warmer) begin(ctx context.Context, trigger string, names []string) (context.Context, WarmStatus, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return nil, WarmStatus{}, ErrWarmupInProgress
+	}
+	if len(names) == 0 {
+		names = w.order
+	}
+	for _, name := range names {
+		if _, exists := w.tasks[name]; !exists {
+			return nil, WarmStatus{}, fmt.Errorf("%w: %s", ErrUnknownDataset, name)
+		}
+	}
+
+	id := 1
+	if w.status != nil {
+		id = w.status.ID + 1
+	}
+	status := &WarmStatus{
+		ID:        id,
+		Trigger:   trigger,
+		State:     WarmStateRunning,
+		StartedAt: time.Now(),
+	}
+	for _, name := range names {
+		status.Datasets = append(status.Datasets, WarmDatasetStatus{Name: name, State: WarmStatePending})
+	}
+
+	runCtx := context.WithoutCancel(ctx)
+	var cancel context.CancelFunc
+	if w.timeout > 0 {
+		runCtx, cancel = context.WithTimeout(runCtx, w.timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(runCtx)
+	}
+
+	w.status = status
+	w.running = true
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	return runCtx, w.snapshot(), nil
+}
+
+// run 依次预热各数据集并更新进度
+func (w *Warmer) run(ctx context.Context) {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	datasets := len(w.status.Datasets)
+	w.mu.Unlock()
+
+	defer func() {
+		cancel()
+		w.mu.Lock()
+		w.running = false
+		w.cancel = nil
+		close(done)
+		w.mu.Unlock()
+	}()
+
+	failed := false
+	for i := 0; i < datasets; i++ {
+		w.mu.Lock()
+		dataset := &w.status.Datasets[i]
+		fn := w.tasks[dataset.Name]
+		dataset.State = WarmStateRunning
+		dataset.startedAt = time.Now()
+		w.mu.Unlock()
+
+		err := w.warm(ctx, fn, func(done, total int) {
+			w.mu.Lock()
+			dataset.Done, dataset.Total = done, total
+			w.mu.Unlock()
+		})
+
+		w.mu.Lock()
+		dataset.DurationMS = time.Since(dataset.startedAt).Milliseconds()
+		if err != nil {
+			dataset.State = WarmStateFailed
+			dataset.Error = err.Error()
+			failed = true
+		} else {
+			dataset.State = WarmStateCompleted
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	finishedAt := time.Now()
+	w.status.FinishedAt = &finishedAt
+	w.status.State = WarmStateCompleted
+	if failed {
+		w.status.State = WarmStateFailed
+	}
+	w.mu.Unlock()
+}
+
+// warm 执行数据集的加载函数，panic 视为预热失败
+func (w *Warmer) warm(ctx context.Context, fn WarmFunc, progress WarmProgress) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn(ctx, progress)
+}
+
+// snapshot 复制当前状态，调用方需持有锁
+func (w *Warmer) snapshot() WarmStatus {
+	status := *w.status
+	status.Datasets = append([]WarmDatasetStatus(nil), w.status.Datasets...)
+	if status.State == WarmStateRunning {
+		for i := range status.Datasets {
+			if status.Datasets[i].State == WarmStateRunning {
+				status.Datasets[i].DurationMS = time.Since(status.Datasets[i].startedAt).Milliseconds()
+			}
+		}
+	}
+	return status
+}
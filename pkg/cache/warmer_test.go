@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmer_Run(t *testing.T) {
+	cache := NewMockCache()
+	warmer := NewWarmer(time.Second)
+
+	warmer.Register("users", func(ctx context.Context, progress WarmProgress) error {
+		for i := 0; i < 3; i++ {
+			if err := cache.Set(ctx, "user:"+string(rune('a'+i)), i, time.Minute); err != nil {
+				return err
+			}
+			progress(i+1, 3)
+		}
+		return nil
+	})
+	warmer.Register("settings", func(ctx context.Context, progress WarmProgress) error {
+		return cache.Set(ctx, "settings", "blob", time.Minute)
+	})
+	assert.Equal(t, []string{"users", "settings"}, warmer.Datasets())
+
+	_, ok := warmer.Status()
+	assert.False(t, ok)
+
+	status, err := warmer.Run(context.Background(), WarmTriggerStartup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.ID)
+	assert.Equal(t, WarmTriggerStartup, status.Trigger)
+	assert.Equal(t, WarmStateCompleted, status.State)
+	require.NotNil(t, status.FinishedAt)
+	require.Len(t, status.Datasets, 2)
+	assert.Equal(t, "users", status.Datasets[0].Name)
+	assert.Equal(t, WarmStateCompleted, status.Datasets[0].State)
+	assert.Equal(t, 3, status.Datasets[0].Done)
+	assert.Equal(t, 3, status.Datasets[0].Total)
+
+	_, found := cache.Get(context.Background(), "settings")
+	assert.True(t, found)
+
+	// Selective warming
+	status, err = warmer.Run(context.Background(), WarmTriggerAPI, []string{"settings"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.ID)
+	require.Len(t, status.Datasets, 1)
+	assert.Equal(t, "settings", status.Datasets[0].Name)
+
+	_, err = warmer.Run(context.Background(), WarmTriggerAPI, []string{"unknown"})
+	assert.ErrorIs(t, err, ErrUnknownDataset)
+}
+
+func TestWarmer_FailureDoesNotStopOtherDatasets(t *testing.T) {
+	warmer := NewWarmer(0)
+	warmer.Register("broken", func(ctx context.Context, progress WarmProgress) error {
+		return errors.New("database unavailable")
+	})
+	warmer.Register("panics", func(ctx context.Context, progress WarmProgress) error {
+		panic("boom")
+	})
+	ran := false
+	warmer.Register("ok", func(ctx context.Context, progress WarmProgress) error {
+		ran = true
+		return nil
+	})
+
+	status, err := warmer.Run(context.Background(), WarmTriggerAPI, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database unavailable")
+	assert.True(t, ran)
+	assert.Equal(t, WarmStateFailed, status.State)
+	assert.Equal(t, WarmStateFailed, status.Datasets[0].State)
+	assert.Contains(t, status.Datasets[1].Error, "boom")
+	assert.Equal(t, WarmStateCompleted, status.Datasets[2].State)
+}
+
+func TestWarmer_StartReportsProgress(t *testing.T) {
+	warmer := NewWarmer(time.Second)
+	release := make(chan struct{})
+	warmer.Register("users", func(ctx context.Context, progress WarmProgress) error {
+		progress(1, 2)
+		<-release
+		progress(2, 2)
+		return nil
+	})
+
+	status, err := warmer.Start(context.Background(), WarmTriggerAPI, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WarmStateRunning, status.State)
+
+	assert.Eventually(t, func() bool {
+		status, _ := warmer.Status()
+		return status.Datasets[0].Done == 1 && status.Datasets[0].State == WarmStateRunning
+	}, time.Second, 5*time.Millisecond)
+
+	// Only one warm-up runs at a time
+	_, err = warmer.Start(context.Background(), WarmTriggerAPI, nil)
+	assert.ErrorIs(t, err, ErrWarmupInProgress)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		status, _ := warmer.Status()
+		return status.State == WarmStateCompleted && status.Datasets[0].Done == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWarmer_Close(t *testing.T) {
+	warmer := NewWarmer(0)
+	warmer.Register("slow", func(ctx context.Context, progress WarmProgress) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	_, err := warmer.Start(context.Background(), WarmTriggerAPI, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, warmer.Close(ctx))
+
+	status, _ := warmer.Status()
+	assert.Equal(t, WarmStateFailed, status.State)
+	assert.Contains(t, status.Datasets[0].Error, "context canceled")
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource 提供访问令牌
+// 实现了 Invalidate(token string) 的来源在服务端返回 401 后被通知令牌失效，客户端随后重试一次
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken 固定的访问令牌
+type StaticToken string
+
+// Token 返回令牌本身
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// DefaultRefreshLeeway 令牌过期前提前刷新的时间
+const DefaultRefreshLeeway = 30 * time.Second
+
+// RefreshingTokenSource 缓存访问令牌，在令牌过期前或失效后调用 fetch 重新获取
+// 过期时间取自 JWT 的 exp 声明（不校验签名），没有 exp 时一直使用到失效为止
+type RefreshingTokenSource struct {
+	fetch  func(ctx context.Context) (string, error)
+	leeway time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewRefreshingTokenSource 创建令牌来源，leeway <= 0 时使用 DefaultRefreshLeeway
+func NewRefreshingTokenSource(fetch func(ctx context.Context) (string, error), leeway time.Duration) *RefreshingTokenSource {
+	if leeway <= 0 {
+		leeway = DefaultRefreshLeeway
+	}
+	return &RefreshingTokenSource{fetch: fetch, leeway: leeway, now: time.Now}
+}
+
+// LoginTokenSource 使用邮箱和密码登录获取令牌，令牌过期或失效后重新登录
+func LoginTokenSource(c *Client, email, password string) *RefreshingTokenSource {
+	return NewRefreshingTokenSource(func(ctx context.Context) (string, error) {
+		result, err := c.AuthLogin(ctx, LoginRequest{Email: email, Password: password})
+		if err != nil {
+			return "", err
+		}
+		return result.Token, nil
+	}, 0)
+}
+
+// Token 返回缓存的令牌，需要时重新获取；持有锁获取令牌，并发请求只触发一次获取
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || s.now().Before(s.expiry.Add(-s.leeway))) {
+		return s.token, nil
+	}
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry = token, tokenExpiry(token)
+	return token, nil
+}
+
+// Invalidate 丢弃失效的令牌，令牌已被替换时忽略
+func (s *RefreshingTokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token, s.expiry = "", time.Time{}
+	}
+}
+
+// tokenExpiry 读取 JWT 的 exp 声明，不是 JWT 或没有 exp 时返回零值
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.ExpiresAt, 0)
+}
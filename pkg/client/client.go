@@ -0,0 +1,330 @@
+// Package client 接口的 Go 客户端
+//
+// 接口方法、数据类型和错误代码常量由 cmd/genclient 根据 docs/openapi.json 生成（client_gen.go），
+// 方法名为首字母大写的 operationId；本包其余文件手写，负责发送请求、解析响应、令牌刷新和分页遍历。
+//
+//	c := client.New("http://localhost:8080")
+//	c.SetTokenSource(client.LoginTokenSource(c, email, password))
+//	for user, err := range c.UserGetUsersIter(ctx, nil) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client 接口客户端，可以并发使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tokens     TokenSource
+	userAgent  string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用指定的 HTTP 客户端，默认超时 30 秒
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken 使用固定的访问令牌
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource 从 source 获取访问令牌
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = source
+	}
+}
+
+// WithUserAgent 设置 User-Agent 请求头
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New 创建客户端，baseURL 为服务地址，如 http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "go-server-client/" + APIVersion,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetTokenSource 替换访问令牌来源，用于创建客户端之后才能构造的来源（如 LoginTokenSource）
+func (c *Client) SetTokenSource(source TokenSource) {
+	c.tokens = source
+}
+
+// RequestOption 单个请求的选项
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header   http.Header
+	response *http.Header
+}
+
+// WithHeader 为请求添加请求头
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// ResponseHeader 请求成功后将响应头写入 header，用于读取 ETag、X-Request-ID 等
+func ResponseHeader(header *http.Header) RequestOption {
+	return func(o *requestOptions) {
+		o.response = header
+	}
+}
+
+// file multipart 请求中的文件
+type file struct {
+	field    string
+	filename string
+	reader   io.Reader
+}
+
+// request 生成的方法构造的请求
+type request struct {
+	method   string
+	path     string
+	query    url.Values
+	header   http.Header
+	body     any
+	files    []file
+	auth     bool // 需要访问令牌
+	envelope bool // 响应数据在 SuccessResponse 的 data 中
+	text     bool // 响应为纯文本
+}
+
+func (r *request) setQuery(key, value string) {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Set(key, value)
+}
+
+func (r *request) setHeader(key, value string) {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(key, value)
+}
+
+// encode 编码请求体，返回内容和 Content-Type
+// 请求体只编码一次，令牌失效重试时重复使用
+func (r *request) encode() ([]byte, string, error) {
+	if len(r.files) > 0 {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, f := range r.files {
+			filename := f.filename
+			if filename == "" {
+				filename = f.field
+			}
+			part, err := writer.CreateFormFile(f.field, filename)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := io.Copy(part, f.reader); err != nil {
+				return nil, "", fmt.Errorf("client: failed to read %s: %w", f.field, err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+	}
+	if r.body == nil {
+		return nil, "", nil
+	}
+	data, err := json.Marshal(r.body)
+	if err != nil {
+		return nil, "", fmt.Errorf("client: failed to encode request body: %w", err)
+	}
+	return data, "application/json", nil
+}
+
+// do 发送请求并将响应数据解码到 out（为 nil 时丢弃）
+// 需要认证的请求收到 401 且令牌来源支持失效时，使令牌失效并重试一次
+func (c *Client) do(ctx context.Context, r *request, out any, opts []RequestOption) error {
+	var options requestOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	body, contentType, err := r.encode()
+	if err != nil {
+		return err
+	}
+
+	target := c.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, r.method, target, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if body == nil {
+			req.Body, req.ContentLength = http.NoBody, 0
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", "application/json")
+		if r.text {
+			req.Header.Set("Accept", "text/plain")
+		}
+		if c.userAgent != "" {
+			req.Header.Set("User-Agent", c.userAgent)
+		}
+		for key, values := range r.header {
+			req.Header[key] = values
+		}
+		for key, values := range options.header {
+			req.Header[key] = values
+		}
+
+		var token string
+		if r.auth && c.tokens != nil {
+			if token, err = c.tokens.Token(ctx); err != nil {
+				return fmt.Errorf("client: failed to get access token: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && token != "" && attempt == 0 {
+			if invalidator, ok := c.tokens.(interface{ Invalidate(string) }); ok {
+				drain(resp)
+				invalidator.Invalidate(token)
+				continue
+			}
+		}
+		return c.decode(resp, r, out, options.response)
+	}
+}
+
+// decode 检查状态码并解码响应
+func (c *Client) decode(resp *http.Response, r *request, out any, header *http.Header) error {
+	defer drain(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return ErrNotModified
+	case resp.StatusCode >= 400:
+		return parseError(resp)
+	}
+	if header != nil {
+		*header = resp.Header.Clone()
+	}
+
+	if r.text {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if s, ok := out.(*string); ok {
+			*s = string(data)
+		}
+		return nil
+	}
+	if out == nil {
+		return nil
+	}
+	if !r.envelope {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("client: failed to decode response: %w", err)
+		}
+		return nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("client: failed to decode response: %w", err)
+	}
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("client: failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// drain 读完并关闭响应体以便复用连接
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// Page 分页接口的一页数据
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// paginate 从 start（<=0 时为第 1 页）开始逐页获取并逐条返回数据
+// 某页没有数据或已到最后一页时结束，获取失败时返回错误并结束
+func paginate[T any](start int, fetch func(page int) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if start <= 0 {
+			start = 1
+		}
+		for page := start; ; page++ {
+			result, err := fetch(page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range result.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(result.Data) == 0 || page >= result.Pagination.TotalPages {
+				return
+			}
+		}
+	}
+}
+
+// Ptr 返回值的指针，用于设置可选参数
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,2725 @@
+// Code generated by go run ./cmd/genclient; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// APIVersion 生成客户端使用的接口文档版本
+const APIVersion = "1.0"
+
+// 服务端返回的错误代码
+const (
+	ErrCodeValidation          ErrorCode = "VALIDATION_ERROR"
+	ErrCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrCodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden           ErrorCode = "FORBIDDEN"
+	ErrCodeConflict            ErrorCode = "CONFLICT"
+	ErrCodeRateLimitExceeded   ErrorCode = "RATE_LIMIT_EXCEEDED"
+	ErrCodeInternal            ErrorCode = "INTERNAL_ERROR"
+	ErrCodeDatabase            ErrorCode = "DATABASE_ERROR"
+	ErrCodeCache               ErrorCode = "CACHE_ERROR"
+	ErrCodeServiceUnavailable  ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrCodeTimeout             ErrorCode = "TIMEOUT"
+	ErrCodeInvalidToken        ErrorCode = "INVALID_TOKEN"
+	ErrCodeTokenBlacklisted    ErrorCode = "TOKEN_BLACKLISTED"
+	ErrCodeBusinessLogic       ErrorCode = "BUSINESS_LOGIC_ERROR"
+	ErrCodeQuotaExceeded       ErrorCode = "QUOTA_EXCEEDED"
+	ErrCodeMaintenanceMode     ErrorCode = "MAINTENANCE_MODE"
+	ErrCodeThirdPartyService   ErrorCode = "THIRD_PARTY_SERVICE_ERROR"
+	ErrCodeConfiguration       ErrorCode = "CONFIGURATION_ERROR"
+	ErrCodeDependency          ErrorCode = "DEPENDENCY_ERROR"
+	ErrCodeSecurity            ErrorCode = "SECURITY_ERROR"
+	ErrCodeDataIntegrity       ErrorCode = "DATA_INTEGRITY_ERROR"
+	ErrCodePayloadTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrCodePreconditionFailed  ErrorCode = "PRECONDITION_FAILED"
+	ErrCodeEntitlementRequired ErrorCode = "ENTITLEMENT_REQUIRED"
+)
+
+// ErrorCodes 服务端定义的所有错误代码
+var ErrorCodes = []ErrorCode{
+	ErrCodeValidation,
+	ErrCodeNotFound,
+	ErrCodeUnauthorized,
+	ErrCodeForbidden,
+	ErrCodeConflict,
+	ErrCodeRateLimitExceeded,
+	ErrCodeInternal,
+	ErrCodeDatabase,
+	ErrCodeCache,
+	ErrCodeServiceUnavailable,
+	ErrCodeTimeout,
+	ErrCodeInvalidToken,
+	ErrCodeTokenBlacklisted,
+	ErrCodeBusinessLogic,
+	ErrCodeQuotaExceeded,
+	ErrCodeMaintenanceMode,
+	ErrCodeThirdPartyService,
+	ErrCodeConfiguration,
+	ErrCodeDependency,
+	ErrCodeSecurity,
+	ErrCodeDataIntegrity,
+	ErrCodePayloadTooLarge,
+	ErrCodePreconditionFailed,
+	ErrCodeEntitlementRequired,
+}
+
+// AdminOverviewComponent 依赖组件的状态
+type AdminOverviewComponent struct {
+	Driver string         `json:"driver,omitempty"` // 驱动名称
+	Error  string         `json:"error,omitempty"`  // 不健康时的错误信息
+	Stats  map[string]any `json:"stats,omitempty"`  // 组件返回的统计信息
+	Status string         `json:"status,omitempty"` // 组件状态
+}
+
+// AdminOverviewResponse 管理后台系统概览，汇总用户、限流、缓存、数据库和构建信息
+type AdminOverviewResponse struct {
+	Build       Info                   `json:"build,omitempty"`        // 版本和构建信息
+	Cache       AdminOverviewComponent `json:"cache,omitempty"`        // 缓存状态和统计
+	Database    AdminOverviewComponent `json:"database,omitempty"`     // 数据库连接池健康状态
+	GeneratedAt time.Time              `json:"generated_at,omitempty"` // 概览生成时间，缓存期内的请求返回相同的时间
+	RateLimit   RateLimitStats         `json:"rate_limit,omitempty"`   // 请求和限流统计，未启用限流时省略
+	Uptime      AdminOverviewUptime    `json:"uptime,omitempty"`       // 进程运行时长
+	Users       AdminOverviewUsers     `json:"users,omitempty"`        // 用户数量
+}
+
+// AdminOverviewUptime 进程运行时长
+type AdminOverviewUptime struct {
+	Human     string    `json:"human,omitempty"`      // 可读的运行时长
+	Seconds   int64     `json:"seconds,omitempty"`    // 运行秒数
+	StartedAt time.Time `json:"started_at,omitempty"` // 进程启动时间
+}
+
+// AdminOverviewUsers 用户数量统计
+type AdminOverviewUsers struct {
+	Active   int64  `json:"active,omitempty"`   // 已激活用户数
+	Admins   int64  `json:"admins,omitempty"`   // 管理员数
+	Error    string `json:"error,omitempty"`    // 统计失败时的错误信息
+	Inactive int64  `json:"inactive,omitempty"` // 已停用用户数
+	Total    int64  `json:"total,omitempty"`    // 用户总数（含已停用）
+}
+
+// Announcement 管理员发布的系统公告，在 starts_at 和 ends_at 之间对匹配角色和租户的用户可见
+type Announcement struct {
+	Body      string     `json:"body,omitempty"`       // 正文
+	CreatedAt time.Time  `json:"created_at,omitempty"` // 创建时间
+	CreatedBy string     `json:"created_by,omitempty"` // 创建者ID
+	EndsAt    *time.Time `json:"ends_at,omitempty"`    // 过期时间，为空时一直有效
+	ID        string     `json:"id,omitempty"`         // 公告ID
+	Level     string     `json:"level,omitempty"`      // 级别（info、warning、critical）
+	Roles     []string   `json:"roles,omitempty"`      // 可见的角色，为空时对所有用户（包括未登录用户）可见
+	StartsAt  *time.Time `json:"starts_at,omitempty"`  // 生效时间，为空时立即生效
+	TenantID  *string    `json:"tenant_id,omitempty"`  // 可见的租户ID，为空时对所有租户可见
+	Title     string     `json:"title,omitempty"`      // 标题
+	UpdatedAt time.Time  `json:"updated_at,omitempty"` // 更新时间
+}
+
+// AnnouncementListResponse 公告管理列表响应
+type AnnouncementListResponse struct {
+	Announcements []Announcement `json:"announcements,omitempty"` // 公告，按创建时间倒序
+	Pagination    Pagination     `json:"pagination,omitempty"`    // 分页信息
+}
+
+// AnnouncementRequest 创建或修改公告请求，修改时替换公告的完整定义
+type AnnouncementRequest struct {
+	Body     string     `json:"body"`                // 正文
+	EndsAt   *time.Time `json:"ends_at,omitempty"`   // 过期时间，为空时一直有效，须晚于生效时间
+	Level    string     `json:"level,omitempty"`     // 级别，默认 info
+	Roles    []string   `json:"roles,omitempty"`     // 可见的角色，为空时对所有用户可见
+	StartsAt *time.Time `json:"starts_at,omitempty"` // 生效时间，为空时立即生效
+	TenantID *string    `json:"tenant_id,omitempty"` // 可见的租户ID，为空时对所有租户可见
+	Title    string     `json:"title"`               // 标题
+}
+
+// AssignRolesRequest 分配用户角色请求，角色列表为用户的完整角色集合
+type AssignRolesRequest struct {
+	Roles []string `json:"roles"` // 角色列表
+}
+
+// Backup 数据库的逻辑备份（pg_dump 自定义格式），加密后保存在对象存储中
+type Backup struct {
+	Checksum    string     `json:"checksum,omitempty"`     // 备份文件（加密前）的 SHA-256，完成后填写
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // 完成或失败时间
+	CreatedAt   time.Time  `json:"created_at,omitempty"`   // 创建时间
+	CreatedBy   *string    `json:"created_by,omitempty"`   // 创建者ID，定期备份为空
+	Error       string     `json:"error,omitempty"`        // 失败原因
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`   // 备份删除时间，完成后填写
+	ID          string     `json:"id,omitempty"`           // 备份ID
+	Size        int64      `json:"size,omitempty"`         // 备份文件大小（字节，加密前），完成后填写
+	Status      string     `json:"status,omitempty"`       // 状态（pending、running、completed、failed）
+	Trigger     string     `json:"trigger,omitempty"`      // 触发方式（manual、scheduled）
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`   // 更新时间
+}
+
+// BackupListResponse 备份列表响应
+type BackupListResponse struct {
+	Backups    []Backup   `json:"backups,omitempty"`    // 备份，按创建时间倒序
+	Pagination Pagination `json:"pagination,omitempty"` // 分页信息
+}
+
+// Ban 封禁记录
+type Ban struct {
+	CreatedAt     time.Time `json:"created_at,omitempty"`     // 封禁时间
+	ExpiresAt     time.Time `json:"expires_at,omitempty"`     // 到期时间，到期后自动解除
+	Identifier    string    `json:"identifier,omitempty"`     // IP 地址或用户ID
+	Reason        string    `json:"reason,omitempty"`         // 封禁原因
+	Type          string    `json:"type,omitempty"`           // 封禁对象类型：ip 或 user
+	ViolationRate float64   `json:"violation_rate,omitempty"` // 封禁时统计窗口内的违规比例（百分比）
+	Violations    int       `json:"violations,omitempty"`     // 封禁时统计窗口内的违规次数
+}
+
+// CacheKeysResponse 缓存键列表响应
+type CacheKeysResponse struct {
+	Cursor    string   `json:"cursor,omitempty"`    // 获取下一页时传入的游标，遍历结束时为空
+	Keys      []string `json:"keys,omitempty"`      // 本页匹配的键（不含应用键前缀），页内按字典序排列
+	Pattern   string   `json:"pattern,omitempty"`   // 匹配模式
+	Truncated bool     `json:"truncated,omitempty"` // 是否还有下一页
+}
+
+// CacheStatsResponse 缓存统计响应
+type CacheStatsResponse struct {
+	Capabilities []string       `json:"capabilities,omitempty"` // 驱动支持的可选能力
+	Driver       string         `json:"driver,omitempty"`       // 缓存驱动
+	Stats        map[string]any `json:"stats,omitempty"`        // 驱动返回的统计信息
+}
+
+// ChangeEmailRequest 变更邮箱请求，新邮箱验证后才生效
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"` // 新邮箱地址
+	Password string `json:"password"`  // 当前密码
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	NewPassword string `json:"new_password"` // 新密码
+	OldPassword string `json:"old_password"` // 旧密码
+}
+
+// CheckResult 单个检查项的结果
+type CheckResult struct {
+	Critical   bool    `json:"critical,omitempty"`    // 是否为关键依赖
+	DurationMs float64 `json:"duration_ms,omitempty"` // 检查耗时（毫秒）
+	Error      string  `json:"error,omitempty"`       // 错误信息
+	Status     string  `json:"status,omitempty"`      // 检查状态
+}
+
+// ConfirmEmailChangeRequest 确认邮箱变更请求
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"` // 发送到新邮箱的验证令牌
+}
+
+// CountryStats represents the traffic of clients located in one country
+type CountryStats struct {
+	ClientErrors  int64  `json:"client_errors,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Requests      int64  `json:"requests,omitempty"`
+	ResponseBytes int64  `json:"response_bytes,omitempty"`
+	ServerErrors  int64  `json:"server_errors,omitempty"`
+}
+
+// CreateFeatureFlagRequest 创建功能开关请求
+type CreateFeatureFlagRequest struct {
+	Description string   `json:"description,omitempty"` // 说明
+	Enabled     bool     `json:"enabled,omitempty"`     // 总开关
+	Key         string   `json:"key"`                   // 开关键，小写字母、数字、下划线、点和连字符
+	Percentage  int      `json:"percentage,omitempty"`  // 按用户灰度的比例（0-100）
+	Users       []string `json:"users"`                 // 始终开启的用户ID
+}
+
+// DeleteAccountRequest 注销账户请求
+type DeleteAccountRequest struct {
+	Password string `json:"password"` // 当前密码
+}
+
+// DeviceCodeResponse 设备授权请求的响应（RFC 8628 第 3.2 节），设备显示 user_code 和 verification_uri 并轮询令牌接口
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code,omitempty"`               // 设备码，只用于轮询令牌接口，不要展示给用户
+	ExpiresIn               int    `json:"expires_in,omitempty"`                // 设备码和用户码的有效期（秒）
+	Interval                int    `json:"interval,omitempty"`                  // 轮询间隔（秒）
+	UserCode                string `json:"user_code,omitempty"`                 // 用户码，用户在验证页面输入
+	VerificationUri         string `json:"verification_uri,omitempty"`          // 验证页面地址
+	VerificationUriComplete string `json:"verification_uri_complete,omitempty"` // 带有用户码的验证页面地址，可显示为二维码
+}
+
+// DeviceTokenRequest 设备轮询令牌的请求
+type DeviceTokenRequest struct {
+	DeviceCode string `json:"device_code"` // 设备码
+}
+
+// DeviceVerifyRequest 已登录用户在验证页面对设备登录做出的决定
+type DeviceVerifyRequest struct {
+	Approve  bool   `json:"approve,omitempty"` // 是否允许设备登录
+	UserCode string `json:"user_code"`         // 设备上显示的用户码，不区分大小写，可省略连字符
+}
+
+// DrainStatus 排空进度
+type DrainStatus struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // 完成时间
+	InFlight    int64      `json:"in_flight,omitempty"`    // 处理中的请求数，不含排空接口本身
+	StartedAt   *time.Time `json:"started_at,omitempty"`   // 开始时间
+	State       string     `json:"state,omitempty"`        // idle、draining 或 drained
+	Steps       []Step     `json:"steps,omitempty"`        // 各步骤的进度
+}
+
+// EmailChangeResponse 邮箱变更申请响应
+type EmailChangeResponse struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // 验证令牌过期时间
+	NewEmail  string    `json:"new_email,omitempty"`  // 待验证的新邮箱
+}
+
+// EnableMaintenanceRequest 开启维护请求，已在维护中时更新说明和预计结束时间
+type EnableMaintenanceRequest struct {
+	DurationSeconds int    `json:"duration_seconds,omitempty"` // 预计时长（秒），0 表示未知
+	Message         string `json:"message,omitempty"`          // 展示给用户的说明
+}
+
+// ErasureReport 抹除用户个人数据的结果
+type ErasureReport struct {
+	AvatarDeleted        bool      `json:"avatar_deleted,omitempty"`        // 是否删除了头像文件
+	ErasedAt             time.Time `json:"erased_at,omitempty"`             // 抹除时间
+	NotificationsDeleted int64     `json:"notifications_deleted,omitempty"` // 删除的站内信数
+	TokensRevoked        bool      `json:"tokens_revoked,omitempty"`        // 是否吊销了已签发的令牌
+	UserID               string    `json:"user_id,omitempty"`               // 被抹除的用户ID
+}
+
+// ErrorCodeInfo 错误代码的机器可读说明
+type ErrorCodeInfo struct {
+	Code        ErrorCode `json:"code,omitempty"`        // 错误代码
+	Description string    `json:"description,omitempty"` // 错误说明
+	DocsURL     string    `json:"docs_url,omitempty"`    // 文档链接，同时作为 RFC 7807 的问题类型URI
+	HTTPStatus  int       `json:"http_status,omitempty"` // 对应的HTTP状态码
+	Retryable   bool      `json:"retryable,omitempty"`   // 客户端是否可以原样重试
+	Title       string    `json:"title,omitempty"`       // 简短标题
+}
+
+// Event Stripe 事件，data.object 按事件类型解析
+type Event struct {
+	Created int64     `json:"created,omitempty"` // 事件创建时间（Unix 秒）
+	Data    EventData `json:"data,omitempty"`    // 事件数据
+	ID      string    `json:"id,omitempty"`      // 事件ID
+	Type    string    `json:"type,omitempty"`    // 事件类型
+}
+
+// EventData 事件携带的对象
+type EventData struct {
+	Object any `json:"object,omitempty"` // 事件发生后的对象
+}
+
+// Export 后台生成的用户数据导出文件，记录任务进度和存储位置
+type Export struct {
+	CompletedAt   *time.Time   `json:"completed_at,omitempty"`   // 完成或失败时间
+	CreatedAt     time.Time    `json:"created_at,omitempty"`     // 创建时间
+	CreatedBy     string       `json:"created_by,omitempty"`     // 创建者ID
+	DownloadURL   string       `json:"download_url,omitempty"`   // 带签名的下载地址，完成后每次查询重新签发
+	Error         string       `json:"error,omitempty"`          // 失败原因
+	ExpiresAt     *time.Time   `json:"expires_at,omitempty"`     // 文件删除时间，完成后填写
+	Filter        ExportFilter `json:"filter,omitempty"`         // 筛选条件
+	Format        string       `json:"format,omitempty"`         // 文件格式（csv、json、xlsx）
+	ID            string       `json:"id,omitempty"`             // 导出任务ID
+	ProcessedRows int64        `json:"processed_rows,omitempty"` // 已写入的用户数
+	Size          int64        `json:"size,omitempty"`           // 文件大小（字节），完成后填写
+	Status        string       `json:"status,omitempty"`         // 状态（pending、running、completed、failed）
+	TenantID      *string      `json:"tenant_id,omitempty"`      // 所属租户ID，未启用多租户时为空
+	TotalRows     int64        `json:"total_rows,omitempty"`     // 开始时匹配的用户数
+	UpdatedAt     time.Time    `json:"updated_at,omitempty"`     // 更新时间
+}
+
+// ExportFilter 导出用户的筛选条件，与管理员用户列表的筛选条件相同
+type ExportFilter struct {
+	IsActive *bool  `json:"is_active,omitempty"` // 按激活状态筛选
+	IsAdmin  *bool  `json:"is_admin,omitempty"`  // 按管理员角色筛选
+	Query    string `json:"query,omitempty"`     // 按用户名、邮箱或姓名模糊匹配
+}
+
+// ExportRequest 创建用户数据导出的请求，筛选条件与管理员用户列表相同
+type ExportRequest struct {
+	Format   string `json:"format"`              // 文件格式
+	IsActive *bool  `json:"is_active,omitempty"` // 按激活状态筛选
+	IsAdmin  *bool  `json:"is_admin,omitempty"`  // 按管理员角色筛选
+	Query    string `json:"query,omitempty"`     // 按用户名、邮箱或姓名模糊匹配
+}
+
+// Flag 功能开关定义
+type Flag struct {
+	Description string    `json:"description,omitempty"` // 说明
+	Enabled     bool      `json:"enabled,omitempty"`     // 总开关，关闭时对所有用户关闭
+	Key         string    `json:"key,omitempty"`         // 开关键
+	Percentage  int       `json:"percentage,omitempty"`  // 按用户灰度的比例（0-100），100 表示对所有请求开启
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`  // 最后修改时间
+	Users       []string  `json:"users,omitempty"`       // 始终开启的用户ID
+}
+
+// HTTPRouteStats represents the statistics of one method/route/status series
+type HTTPRouteStats struct {
+	AvgDuration   int               `json:"avg_duration,omitempty"` // 纳秒
+	Latency       HistogramSnapshot `json:"latency,omitempty"`
+	Method        string            `json:"method,omitempty"`
+	Panics        int64             `json:"panics,omitempty"` // panics recovered by the recovery middleware
+	RequestBytes  int64             `json:"request_bytes,omitempty"`
+	Requests      int64             `json:"requests,omitempty"`
+	ResponseBytes int64             `json:"response_bytes,omitempty"`
+	Route         string            `json:"route,omitempty"`
+	Status        int               `json:"status,omitempty"`
+}
+
+// HTTPStats represents aggregated HTTP server statistics
+type HTTPStats struct {
+	InFlight      int64            `json:"in_flight,omitempty"`
+	PeakInFlight  int64            `json:"peak_in_flight,omitempty"`
+	Routes        []HTTPRouteStats `json:"routes,omitempty"`
+	TotalPanics   int64            `json:"total_panics,omitempty"`
+	TotalRequests int64            `json:"total_requests,omitempty"`
+}
+
+// HealthReport 一组检查的汇总结果
+type HealthReport struct {
+	Checks      map[string]CheckResult `json:"checks,omitempty"`      // 各检查项结果
+	Maintenance Maintenance            `json:"maintenance,omitempty"` // 维护倒计时，仅在维护期间出现
+	Status      string                 `json:"status,omitempty"`      // 整体状态
+	Timestamp   time.Time              `json:"timestamp,omitempty"`   // 检查时间
+}
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Maintenance Maintenance    `json:"maintenance,omitempty"` // 维护倒计时，仅在维护期间出现
+	Services    map[string]any `json:"services,omitempty"`    // 服务状态，数据库和缓存为包含 status 的对象
+	Status      string         `json:"status,omitempty"`      // 状态
+	System      map[string]any `json:"system,omitempty"`      // 运行时信息
+	Timestamp   time.Time      `json:"timestamp,omitempty"`   // 时间戳
+	Version     string         `json:"version,omitempty"`     // 版本号
+}
+
+// HistogramBucket represents a single cumulative histogram bucket
+type HistogramBucket struct {
+	Count int64  `json:"count,omitempty"`
+	Le    string `json:"le,omitempty"`
+}
+
+// HistogramSnapshot is a point-in-time view of a latency histogram
+type HistogramSnapshot struct {
+	Buckets []HistogramBucket `json:"buckets,omitempty"`
+	Count   int64             `json:"count,omitempty"`
+	Mean    int               `json:"mean,omitempty"` // 纳秒
+	Sum     int               `json:"sum,omitempty"`  // 纳秒
+}
+
+// ImpersonationResponse 模拟登录响应
+type ImpersonationResponse struct {
+	ExpiresAt      time.Time `json:"expires_at,omitempty"`      // 令牌过期时间
+	ImpersonatorID string    `json:"impersonator_id,omitempty"` // 管理员用户ID
+	Token          string    `json:"token,omitempty"`           // 以目标用户身份签发的令牌
+	User           SafeUser  `json:"user,omitempty"`            // 目标用户
+}
+
+// ImportReport 批量导入用户的结果，试运行时只校验不写入
+type ImportReport struct {
+	Created   int              `json:"created,omitempty"`    // 已创建的用户数，试运行时为 0
+	DryRun    bool             `json:"dry_run,omitempty"`    // 是否为试运行
+	Errors    []ImportRowError `json:"errors,omitempty"`     // 各行的错误，按行号排序
+	TotalRows int              `json:"total_rows,omitempty"` // 文件中的数据行数
+	ValidRows int              `json:"valid_rows,omitempty"` // 通过校验且没有重复的行数
+}
+
+// ImportRowError 导入文件中一行的错误，同一行可以有多个字段错误
+type ImportRowError struct {
+	Code    string `json:"code,omitempty"`    // 错误代码
+	Field   string `json:"field,omitempty"`   // 出错的字段，整行错误时为空
+	Message string `json:"message,omitempty"` // 错误描述
+	Row     int    `json:"row,omitempty"`     // 数据行号，从 1 开始，不含 CSV 表头
+}
+
+// Info 版本和构建信息
+type Info struct {
+	BuildTime string `json:"build_time,omitempty"`
+	GitCommit string `json:"git_commit,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+	Version   string `json:"version,omitempty"`
+}
+
+// JWK JSON Web Key（RFC 7517），仅包含公钥参数
+type JWK struct {
+	Alg string `json:"alg,omitempty"` // 签名算法
+	Crv string `json:"crv,omitempty"` // EC 曲线
+	E   string `json:"e,omitempty"`   // RSA 公开指数
+	Kid string `json:"kid,omitempty"` // 密钥ID
+	Kty string `json:"kty,omitempty"` // 密钥类型：RSA 或 EC
+	N   string `json:"n,omitempty"`   // RSA 模数
+	Use string `json:"use,omitempty"` // 用途：sig
+	X   string `json:"x,omitempty"`   // EC 公钥 X 坐标
+	Y   string `json:"y,omitempty"`   // EC 公钥 Y 坐标
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys,omitempty"`
+}
+
+// LevelSettings 当前生效的全局日志级别和按模块覆盖的日志级别
+type LevelSettings struct {
+	Level   string            `json:"level,omitempty"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Email    string `json:"email"`    // 邮箱地址
+	Password string `json:"password"` // 密码
+}
+
+// LoginResponse 登录响应
+type LoginResponse struct {
+	ExpiresIn    int      `json:"expires_in,omitempty"`    // 访问令牌有效期（秒）
+	RefreshToken string   `json:"refresh_token,omitempty"` // 刷新令牌，用于在访问令牌过期后换取新的令牌
+	Token        string   `json:"token,omitempty"`         // JWT令牌
+	User         SafeUser `json:"user,omitempty"`          // 安全用户信息
+}
+
+// LogoutRequest 登出请求，请求体可以省略
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"` // 同时吊销的刷新令牌
+}
+
+// LogoutResponse 登出响应
+type LogoutResponse struct {
+	Email    string `json:"email,omitempty"`    // 邮箱地址
+	UserID   string `json:"user_id,omitempty"`  // 用户ID
+	Username string `json:"username,omitempty"` // 用户名
+}
+
+// MagicLinkRequest 申请登录链接的请求
+type MagicLinkRequest struct {
+	Email string `json:"email"` // 账号邮箱
+}
+
+// MagicLinkResponse 申请登录链接的响应，邮箱不存在时同样返回，不泄露账号是否存在
+type MagicLinkResponse struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // 登录链接的过期时间
+}
+
+// MagicLinkVerifyRequest 使用登录链接中的令牌登录
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token"` // 登录链接中的令牌
+}
+
+// Maintenance 维护倒计时，维护期间附加在检查结果中，不影响整体状态
+type Maintenance struct {
+	EndsAt           *time.Time `json:"ends_at,omitempty"`           // 预计结束时间，为空表示未知
+	Message          string     `json:"message,omitempty"`           // 维护说明
+	RemainingSeconds int64      `json:"remaining_seconds,omitempty"` // 距预计结束的秒数
+	StartedAt        *time.Time `json:"started_at,omitempty"`        // 开始时间
+}
+
+// MarkNotificationsReadResponse 标记全部通知已读响应
+type MarkNotificationsReadResponse struct {
+	Marked int64 `json:"marked,omitempty"` // 本次标记为已读的通知数
+}
+
+// MetricsHistoryPoint 一个时间桶内所有实例的指标汇总
+type MetricsHistoryPoint struct {
+	CacheHitRate       float64   `json:"cache_hit_rate,omitempty"`       // 用户缓存命中率
+	CacheHits          int64     `json:"cache_hits,omitempty"`           // 用户缓存命中数
+	CacheLoads         int64     `json:"cache_loads,omitempty"`          // 未命中后从数据库加载的次数
+	CacheMisses        int64     `json:"cache_misses,omitempty"`         // 用户缓存未命中数
+	HTTPAvgLatencyMs   float64   `json:"http_avg_latency_ms,omitempty"`  // 平均请求耗时（毫秒）
+	HTTPInFlight       int64     `json:"http_in_flight,omitempty"`       // 各实例处理中请求数的最大值之和
+	HTTPRequests       int64     `json:"http_requests,omitempty"`        // HTTP 请求数
+	HTTPServerErrors   int64     `json:"http_server_errors,omitempty"`   // 状态码 5xx 的请求数
+	Instances          int       `json:"instances,omitempty"`            // 有快照的实例数
+	RateLimitRequests  int64     `json:"rate_limit_requests,omitempty"`  // 限流检查的请求数
+	RateLimitThrottled int64     `json:"rate_limit_throttled,omitempty"` // 被限流的请求数
+	Time               time.Time `json:"time,omitempty"`                 // 时间桶开始时间
+}
+
+// MetricsHistoryResponse 指标历史，按时间桶汇总所有实例的快照，供管理后台绘制趋势图
+type MetricsHistoryResponse struct {
+	From       time.Time             `json:"from,omitempty"`       // 查询范围开始时间
+	Points     []MetricsHistoryPoint `json:"points,omitempty"`     // 按时间排序的数据点，没有快照的时间桶省略
+	Resolution int                   `json:"resolution,omitempty"` // 时间桶长度（秒），查询范围超过原始快照保留时长时使用汇总快照
+	To         time.Time             `json:"to,omitempty"`         // 查询范围结束时间
+}
+
+// Notification 站内信，由通知服务的站内渠道写入，用户在通知收件箱中查看
+type Notification struct {
+	Body      string     `json:"body,omitempty"`       // 正文
+	CreatedAt time.Time  `json:"created_at,omitempty"` // 创建时间
+	ID        string     `json:"id,omitempty"`         // 通知ID
+	ReadAt    *time.Time `json:"read_at,omitempty"`    // 已读时间，未读时为 null
+	Subject   string     `json:"subject,omitempty"`    // 标题
+	Type      string     `json:"type,omitempty"`       // 通知类型
+}
+
+// NotificationListResponse 通知收件箱响应
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications,omitempty"` // 通知，按创建时间倒序
+	Pagination    Pagination     `json:"pagination,omitempty"`    // 分页信息
+	UnreadCount   int64          `json:"unread_count,omitempty"`  // 全部未读通知数
+}
+
+// NotificationPreference 用户对一种通知类型在一个渠道上的投递偏好，没有记录时使用通知类型的默认渠道
+type NotificationPreference struct {
+	Channel string `json:"channel,omitempty"` // 渠道（email、sms、push、in_app）
+	Enabled bool   `json:"enabled,omitempty"` // 是否投递
+	Type    string `json:"type,omitempty"`    // 通知类型
+}
+
+// NotificationPreferenceRequest 一种通知类型在一个渠道上的投递偏好
+type NotificationPreferenceRequest struct {
+	Channel string `json:"channel"`           // 渠道
+	Enabled bool   `json:"enabled,omitempty"` // 是否投递
+	Type    string `json:"type"`              // 通知类型
+}
+
+// OAuthClient 在本服务注册的 OpenID Connect 客户端（依赖方）
+type OAuthClient struct {
+	Confidential bool      `json:"confidential,omitempty"`  // 是否为机密客户端
+	CreatedAt    time.Time `json:"created_at,omitempty"`    // 创建时间
+	CreatedBy    string    `json:"created_by,omitempty"`    // 创建者ID
+	ID           string    `json:"id,omitempty"`            // 客户端ID（client_id）
+	Name         string    `json:"name,omitempty"`          // 名称，显示在授权同意页面
+	RedirectUris []string  `json:"redirect_uris,omitempty"` // 允许的回调地址，须完全匹配
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`    // 更新时间
+}
+
+// OAuthClientCreatedResponse 注册客户端的响应，客户端密钥只在此时返回一次
+type OAuthClientCreatedResponse struct {
+	Client       OAuthClient `json:"client,omitempty"`        // 客户端
+	ClientSecret string      `json:"client_secret,omitempty"` // 客户端密钥，公开客户端为空
+}
+
+// OAuthClientRequest 注册 OpenID Connect 客户端的请求
+type OAuthClientRequest struct {
+	Confidential bool     `json:"confidential,omitempty"` // 是否为机密客户端，机密客户端会签发客户端密钥
+	Name         string   `json:"name"`                   // 名称
+	RedirectUris []string `json:"redirect_uris"`          // 允许的回调地址
+}
+
+// OAuthConsentRequest 用户在授权同意页面做出的决定，附带原始授权请求
+type OAuthConsentRequest struct {
+	Approve             bool   `json:"approve,omitempty"`     // 是否同意授权
+	ClientID            string `json:"client_id"`             // 客户端ID
+	CodeChallenge       string `json:"code_challenge"`        // PKCE 挑战值
+	CodeChallengeMethod string `json:"code_challenge_method"` // PKCE 挑战方法，只支持 S256
+	Nonce               string `json:"nonce,omitempty"`       // 写入 ID 令牌的随机数
+	RedirectUri         string `json:"redirect_uri"`          // 回调地址，须已注册
+	ResponseType        string `json:"response_type"`         // 响应类型，只支持 code
+	Scope               string `json:"scope"`                 // 以空格分隔的范围，须包含 openid
+	State               string `json:"state,omitempty"`       // 原样返回给客户端的状态
+}
+
+// OAuthConsentResponse 授权同意页面需要展示的信息
+type OAuthConsentResponse struct {
+	ClientID   string   `json:"client_id,omitempty"`   // 客户端ID
+	ClientName string   `json:"client_name,omitempty"` // 客户端名称
+	Scopes     []string `json:"scopes,omitempty"`      // 客户端请求的范围
+}
+
+// OAuthErrorResponse OAuth 2.0 错误响应（RFC 6749 第 5.2 节）
+type OAuthErrorResponse struct {
+	Error            string `json:"error,omitempty"`             // 错误码
+	ErrorDescription string `json:"error_description,omitempty"` // 错误描述
+}
+
+// OAuthRedirectResponse 授权决定的结果，客户端应将浏览器重定向到 redirect_to
+type OAuthRedirectResponse struct {
+	RedirectTo string `json:"redirect_to,omitempty"` // 带有授权码或错误的回调地址
+}
+
+// OAuthTokenResponse 令牌接口的响应（RFC 6749 第 5.1 节）
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token,omitempty"` // 访问令牌，与登录签发的令牌相同
+	ExpiresIn   int    `json:"expires_in,omitempty"`   // 访问令牌的有效期（秒）
+	IDToken     string `json:"id_token,omitempty"`     // ID 令牌
+	Scope       string `json:"scope,omitempty"`        // 授予的范围
+	TokenType   string `json:"token_type,omitempty"`   // 令牌类型
+}
+
+// OIDCDiscovery OpenID Connect 发现文档
+type OIDCDiscovery struct {
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`                // 授权端点（授权同意页面）
+	ClaimsSupported                   []string `json:"claims_supported,omitempty"`                      // 支持的声明
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`      // 支持的 PKCE 挑战方法
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`                 // 支持的授权类型
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported,omitempty"` // ID 令牌的签名算法
+	Issuer                            string   `json:"issuer,omitempty"`                                // 签发者
+	JwksUri                           string   `json:"jwks_uri,omitempty"`                              // 公钥集合
+	ResponseTypesSupported            []string `json:"response_types_supported,omitempty"`              // 支持的响应类型
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`                      // 支持的范围
+	SubjectTypesSupported             []string `json:"subject_types_supported,omitempty"`               // 支持的主体标识类型
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`                        // 令牌端点
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"` // 令牌端点的客户端认证方式
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`                     // 用户信息端点
+}
+
+// OIDCUserInfo 用户信息端点的响应
+type OIDCUserInfo struct {
+	Email             string `json:"email,omitempty"`              // 邮箱地址
+	FamilyName        string `json:"family_name,omitempty"`        // 姓
+	GivenName         string `json:"given_name,omitempty"`         // 名
+	Name              string `json:"name,omitempty"`               // 全名
+	PreferredUsername string `json:"preferred_username,omitempty"` // 用户名
+	Sub               string `json:"sub,omitempty"`                // 用户ID
+}
+
+// Pagination 分页信息
+type Pagination struct {
+	Limit      int   `json:"limit,omitempty"`       // 每页数量
+	Page       int   `json:"page,omitempty"`        // 当前页码
+	Total      int64 `json:"total,omitempty"`       // 总记录数
+	TotalPages int   `json:"total_pages,omitempty"` // 总页数
+}
+
+// PasswordResetResponse 管理员重置密码响应
+type PasswordResetResponse struct {
+	TemporaryPassword string `json:"temporary_password,omitempty"` // 临时密码，只返回一次
+	UserID            string `json:"user_id,omitempty"`            // 用户ID
+}
+
+// PersonalDataArchive 用户个人数据的完整导出，包含资料、站内信和通知偏好
+type PersonalDataArchive struct {
+	GeneratedAt             time.Time                `json:"generated_at,omitempty"`             // 生成时间
+	NotificationPreferences []NotificationPreference `json:"notification_preferences,omitempty"` // 各通知类型在各渠道上的投递偏好
+	Notifications           []Notification           `json:"notifications,omitempty"`            // 站内信，按创建时间倒序
+	User                    SafeUser                 `json:"user,omitempty"`                     // 用户资料
+	Version                 int                      `json:"version,omitempty"`                  // 格式版本
+}
+
+// Plan 订阅套餐，由配置 billing.plans 定义
+type Plan struct {
+	Entitlements []string    `json:"entitlements,omitempty"` // 套餐包含的权益
+	ID           string      `json:"id,omitempty"`           // 套餐标识
+	Name         string      `json:"name,omitempty"`         // 套餐名称
+	Quotas       []PlanQuota `json:"quotas,omitempty"`       // 套餐的用量配额，覆盖 quota.limits 中的同名配额
+	RateLimit    int         `json:"rate_limit,omitempty"`   // 限流窗口内的请求数，0 表示使用租户或全局配置
+}
+
+// PlanQuota 套餐的每月用量配额
+type PlanQuota struct {
+	Hard int64  `json:"hard,omitempty"` // 硬限制，0 表示不限制
+	Name string `json:"name,omitempty"` // 配额名称
+	Soft int64  `json:"soft,omitempty"` // 软限制，0 表示没有软限制
+}
+
+// PolicyStatus 一张表的保留策略
+type PolicyStatus struct {
+	Retention string `json:"retention,omitempty"`
+	Table     string `json:"table,omitempty"`
+}
+
+// QueryOffender aggregates the flagged requests of one route
+type QueryOffender struct {
+	FlaggedRequests   int64     `json:"flagged_requests,omitempty"`
+	LastCorrelationID string    `json:"last_correlation_id,omitempty"`
+	LastQueries       int       `json:"last_queries,omitempty"`
+	LastSeen          time.Time `json:"last_seen,omitempty"`
+	MaxQueries        int       `json:"max_queries,omitempty"`
+	Method            string    `json:"method,omitempty"`
+	NPlusOneRequests  int64     `json:"n_plus_one_requests,omitempty"`
+	Route             string    `json:"route,omitempty"`
+	Stack             []string  `json:"stack,omitempty"`
+	TopRepetitions    int       `json:"top_repetitions,omitempty"`
+	TopStatement      string    `json:"top_statement,omitempty"`
+}
+
+// RateLimitConfig represents the current rate limiting configuration
+type RateLimitConfig struct {
+	Algorithm         string `json:"algorithm,omitempty"`
+	Burst             int    `json:"burst,omitempty"`
+	Enabled           bool   `json:"enabled,omitempty"`
+	Mode              string `json:"mode,omitempty"`
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty"`
+	WindowSize        int    `json:"window_size,omitempty"` // 纳秒
+}
+
+// RateLimitOverrides 管理员在运行时设置的限制，优先于配置文件；零值字段沿用配置文件中的值
+type RateLimitOverrides struct {
+	Anonymous     int                      `json:"anonymous,omitempty"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int                      `json:"authenticated,omitempty"` // 认证用户每个时间窗口的请求数
+	Mode          string                   `json:"mode,omitempty"`          // enforce、warn 或 shadow
+	Routes        map[string]RateLimitTier `json:"routes,omitempty"`        // 按路由模板（如 /api/v1/users/:id）单独计数的限制
+	UpdatedAt     time.Time                `json:"updated_at,omitempty"`    // 最后修改时间
+	UpdatedBy     string                   `json:"updated_by,omitempty"`    // 最后修改的管理员ID
+	Window        string                   `json:"window,omitempty"`        // 时间窗口，如 30s、1m
+}
+
+// RateLimitRequest represents a single rate limit request/check
+type RateLimitRequest struct {
+	Allowed      bool      `json:"allowed,omitempty"`
+	CurrentCount int64     `json:"current_count,omitempty"`
+	Duration     int       `json:"duration,omitempty"` // 纳秒
+	Endpoint     string    `json:"endpoint,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	Limit        int64     `json:"limit,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	Timestamp    time.Time `json:"timestamp,omitempty"`
+	UserID       string    `json:"user_id,omitempty"`
+	WindowSize   int       `json:"window_size,omitempty"` // 纳秒
+}
+
+// RateLimitRouteLimit 单个路由的速率限制，0 表示该类用户沿用全局限制
+type RateLimitRouteLimit struct {
+	Anonymous     int `json:"anonymous,omitempty"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int `json:"authenticated,omitempty"` // 认证用户每个时间窗口的请求数
+}
+
+// RateLimitState 速率限制当前生效的参数和管理员设置的覆盖
+type RateLimitState struct {
+	Enabled   bool                     `json:"enabled,omitempty"`   // 是否启用
+	Limits    RateLimitTier            `json:"limits,omitempty"`    // 生效的全局限制
+	Mode      string                   `json:"mode,omitempty"`      // 生效的模式
+	Overrides RateLimitOverrides       `json:"overrides,omitempty"` // 管理员设置的覆盖，为空表示完全使用配置文件
+	Persisted bool                     `json:"persisted,omitempty"` // 覆盖是否保存在 Redis 中并同步到所有实例
+	Routes    map[string]RateLimitTier `json:"routes,omitempty"`    // 生效的按路由限制
+	Window    string                   `json:"window,omitempty"`    // 生效的时间窗口
+}
+
+// RateLimitStats represents aggregated rate limit statistics
+type RateLimitStats struct {
+	AllowRate         float64            `json:"allow_rate,omitempty"`
+	AllowedRequests   int64              `json:"allowed_requests,omitempty"`
+	AvgCheckDuration  int                `json:"avg_check_duration,omitempty"` // 纳秒
+	Configuration     RateLimitConfig    `json:"configuration,omitempty"`
+	EffectiveRate     float64            `json:"effective_rate,omitempty"`     // Effectiveness score
+	MaxCheckDuration  int                `json:"max_check_duration,omitempty"` // 纳秒
+	MinCheckDuration  int                `json:"min_check_duration,omitempty"` // 纳秒
+	RecentRequests    []RateLimitRequest `json:"recent_requests,omitempty"`
+	ThrottleRate      float64            `json:"throttle_rate,omitempty"`
+	ThrottledRequests int64              `json:"throttled_requests,omitempty"`
+	TopViolatingIPs   []ViolationTracker `json:"top_violating_ips,omitempty"`
+	TopViolatingUsers []ViolationTracker `json:"top_violating_users,omitempty"`
+	TotalRequests     int64              `json:"total_requests,omitempty"`
+}
+
+// RateLimitTier 匿名和认证用户的请求限制，0 表示沿用上一级的限制
+type RateLimitTier struct {
+	Anonymous     int `json:"anonymous,omitempty"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int `json:"authenticated,omitempty"` // 认证用户每个时间窗口的请求数
+}
+
+// RateLimitTimeSeriesPoint aggregates the rate limit checks of one interval
+type RateLimitTimeSeriesPoint struct {
+	AvgCheckDuration int       `json:"avg_check_duration,omitempty"` // 纳秒
+	MaxCheckDuration int       `json:"max_check_duration,omitempty"` // 纳秒
+	P95CheckDuration int       `json:"p95_check_duration,omitempty"` // 纳秒
+	Requests         int64     `json:"requests,omitempty"`
+	Start            time.Time `json:"start,omitempty"`
+	ThrottleRate     float64   `json:"throttle_rate,omitempty"` // percentage
+	Throttled        int64     `json:"throttled,omitempty"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"` // 登录或上次刷新时返回的刷新令牌
+}
+
+// RefreshTokenResponse 刷新令牌响应，原刷新令牌随即失效
+type RefreshTokenResponse struct {
+	ExpiresIn    int    `json:"expires_in,omitempty"`    // 访问令牌有效期（秒）
+	RefreshToken string `json:"refresh_token,omitempty"` // 新的刷新令牌
+	Token        string `json:"token,omitempty"`         // 新的访问令牌
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Email     string `json:"email"`                // 邮箱地址
+	FirstName string `json:"first_name,omitempty"` // 名
+	LastName  string `json:"last_name,omitempty"`  // 姓
+	Password  string `json:"password"`             // 密码
+	Username  string `json:"username"`             // 用户名
+}
+
+// RequestQueryStats represents the thresholds, totals and worst offending routes
+type RequestQueryStats struct {
+	FlaggedRequests int64           `json:"flagged_requests,omitempty"`
+	MaxQueries      int             `json:"max_queries,omitempty"`
+	Offenders       []QueryOffender `json:"offenders,omitempty"`
+	RepeatThreshold int             `json:"repeat_threshold,omitempty"`
+	Requests        int64           `json:"requests,omitempty"`
+}
+
+// RestoreBackupRequest 恢复备份的请求，confirm 须与路径中的备份ID相同，避免误操作覆盖数据库
+type RestoreBackupRequest struct {
+	Confirm string `json:"confirm"` // 要恢复的备份ID
+}
+
+// RestoreStatus 本实例最近一次恢复的状态
+type RestoreStatus struct {
+	BackupID    string     `json:"backup_id,omitempty"`    // 恢复的备份ID
+	Error       string     `json:"error,omitempty"`        // 失败原因
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`  // 结束时间
+	RequestedBy string     `json:"requested_by,omitempty"` // 发起恢复的管理员ID
+	StartedAt   *time.Time `json:"started_at,omitempty"`   // 开始时间
+	State       string     `json:"state,omitempty"`        // 状态（idle、running、completed、failed）
+}
+
+// Result 一张表的清理结果
+type Result struct {
+	Cutoff    time.Time `json:"cutoff,omitempty"`    // 早于该时间的行被删除
+	Error     string    `json:"error,omitempty"`     // 清理失败的原因，失败前已删除的行数计入 Rows
+	Retention string    `json:"retention,omitempty"` // 保留期限，如 720h0m0s
+	Rows      int64     `json:"rows,omitempty"`      // 删除的行数，试运行时为可删除的行数
+	Table     string    `json:"table,omitempty"`
+}
+
+// RetentionReport 一次清理的报告
+type RetentionReport struct {
+	ActorID    string    `json:"actor_id,omitempty"` // 手动触发清理的管理员ID
+	DryRun     bool      `json:"dry_run,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Results    []Result  `json:"results,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+}
+
+// RetentionStatus 保留策略和最近一次清理的报告
+type RetentionStatus struct {
+	DryRun     bool            `json:"dry_run,omitempty"`     // 定期清理是否为试运行
+	Interval   string          `json:"interval,omitempty"`    // 定期清理的间隔，如 1h0m0s
+	LastReport RetentionReport `json:"last_report,omitempty"` // 最近一次清理（定期或手动）的报告，本实例尚未清理时为空
+	Policies   []PolicyStatus  `json:"policies,omitempty"`
+}
+
+// SafeUser 不包含敏感信息的用户对象
+type SafeUser struct {
+	Avatar    string     `json:"avatar,omitempty"`     // 头像URL
+	CreatedAt time.Time  `json:"created_at,omitempty"` // 创建时间
+	Email     string     `json:"email,omitempty"`      // 邮箱地址
+	FirstName string     `json:"first_name,omitempty"` // 名
+	ID        string     `json:"id,omitempty"`         // 用户ID
+	IsActive  bool       `json:"is_active,omitempty"`  // 是否激活
+	IsAdmin   bool       `json:"is_admin,omitempty"`   // 是否为管理员
+	LastLogin *time.Time `json:"last_login,omitempty"` // 最后登录时间
+	LastName  string     `json:"last_name,omitempty"`  // 姓
+	TenantID  *string    `json:"tenant_id,omitempty"`  // 所属租户ID
+	UpdatedAt time.Time  `json:"updated_at,omitempty"` // 更新时间
+	Username  string     `json:"username,omitempty"`   // 用户名
+}
+
+// State 维护状态
+type State struct {
+	Enabled   bool       `json:"enabled,omitempty"`    // 是否处于维护中
+	EndsAt    *time.Time `json:"ends_at,omitempty"`    // 预计结束时间，为空表示未知
+	Message   string     `json:"message,omitempty"`    // 展示给用户的说明
+	Source    string     `json:"source,omitempty"`     // admin 由管理员开启；config 由配置开启
+	StartedAt *time.Time `json:"started_at,omitempty"` // 开始时间
+	UpdatedBy string     `json:"updated_by,omitempty"` // 最后修改状态的管理员ID
+}
+
+// Step 排空步骤的进度
+type Step struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // 结束时间
+	Error       string     `json:"error,omitempty"`        // 失败原因
+	Name        string     `json:"name,omitempty"`         // stop_accepting、in_flight_requests 或 background_jobs
+	StartedAt   *time.Time `json:"started_at,omitempty"`   // 开始时间
+	Status      string     `json:"status,omitempty"`       // pending、running、done 或 failed
+}
+
+// StripeWebhookResponse Stripe webhook 的处理结果
+type StripeWebhookResponse struct {
+	EventID string `json:"event_id,omitempty"` // 事件ID
+	Outcome string `json:"outcome,omitempty"`  // applied 已同步；ignored 不处理的事件类型或无法关联到用户；stale 比已同步的事件更旧
+}
+
+// Subscription 用户的订阅，由 Stripe webhook 同步，每个用户最多一条
+type Subscription struct {
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end,omitempty"` // 是否在周期结束时取消
+	CreatedAt         time.Time  `json:"created_at,omitempty"`           // 创建时间
+	CurrentPeriodEnd  *time.Time `json:"current_period_end,omitempty"`   // 当前计费周期的结束时间
+	ID                string     `json:"id,omitempty"`                   // 订阅ID
+	PlanID            string     `json:"plan_id,omitempty"`              // 套餐标识
+	Status            string     `json:"status,omitempty"`               // 订阅状态
+	UpdatedAt         time.Time  `json:"updated_at,omitempty"`           // 更新时间
+	UserID            string     `json:"user_id,omitempty"`              // 用户ID
+}
+
+// SubscriptionResponse 当前用户的套餐和订阅
+type SubscriptionResponse struct {
+	Plan         Plan         `json:"plan,omitempty"`         // 当前生效的套餐，没有有效订阅时为默认套餐
+	Subscription Subscription `json:"subscription,omitempty"` // 订阅，从未订阅时为空
+}
+
+// UpdateFeatureFlagRequest 修改功能开关请求，替换开关的完整定义
+type UpdateFeatureFlagRequest struct {
+	Description string   `json:"description,omitempty"` // 说明
+	Enabled     bool     `json:"enabled,omitempty"`     // 总开关
+	Percentage  int      `json:"percentage,omitempty"`  // 按用户灰度的比例（0-100）
+	Users       []string `json:"users"`                 // 始终开启的用户ID
+}
+
+// UpdateLogLevelRequest 修改日志级别请求，module 为空时修改全局级别，level 为空时移除模块的级别覆盖
+type UpdateLogLevelRequest struct {
+	Level  string `json:"level,omitempty"`  // 日志级别
+	Module string `json:"module,omitempty"` // 模块名称
+}
+
+// UpdateNotificationPreferencesRequest 修改通知偏好请求，只修改列出的通知类型和渠道
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences"` // 偏好
+}
+
+// UpdateRateLimitsRequest 修改运行时速率限制请求，替换管理员设置的全部覆盖；字段为 0 或空时沿用配置文件中的值
+type UpdateRateLimitsRequest struct {
+	Anonymous     int                            `json:"anonymous,omitempty"`     // 匿名用户每个时间窗口的请求数
+	Authenticated int                            `json:"authenticated,omitempty"` // 认证用户每个时间窗口的请求数
+	Mode          string                         `json:"mode,omitempty"`          // enforce、warn 或 shadow
+	Routes        map[string]RateLimitRouteLimit `json:"routes,omitempty"`        // 按路由模板（如 /api/v1/users/:id）单独计数的限制
+	Window        string                         `json:"window,omitempty"`        // 时间窗口，如 30s、1m
+}
+
+// UpdateUserRequest 更新用户请求
+type UpdateUserRequest struct {
+	Avatar    string `json:"avatar,omitempty"`     // 头像URL
+	FirstName string `json:"first_name,omitempty"` // 名
+	LastName  string `json:"last_name,omitempty"`  // 姓
+	Username  string `json:"username,omitempty"`   // 用户名
+}
+
+// Usage 主体在当前周期内的用量
+type Usage struct {
+	Hard        int64     `json:"hard,omitempty"`         // 硬限制，0 表示不限制
+	PeriodStart time.Time `json:"period_start,omitempty"` // 本周期开始时间
+	Quota       string    `json:"quota,omitempty"`        // 配额名称
+	Remaining   int64     `json:"remaining,omitempty"`    // 剩余用量，不限制时为 -1
+	ResetAt     time.Time `json:"reset_at,omitempty"`     // 用量重置时间，即下一周期开始时间
+	Soft        int64     `json:"soft,omitempty"`         // 软限制，0 表示没有软限制
+	SoftReached bool      `json:"soft_reached,omitempty"` // 是否已达到软限制
+	Used        int64     `json:"used,omitempty"`         // 本周期已用量
+}
+
+// ViolationTracker tracks rate limit violations for a specific identifier
+type ViolationTracker struct {
+	ASN              int         `json:"asn,omitempty"`     // Autonomous system of an IP, when GeoIP lookup is enabled
+	Country          string      `json:"country,omitempty"` // Client country of an IP, when GeoIP lookup is enabled
+	FirstViolation   time.Time   `json:"first_violation,omitempty"`
+	Identifier       string      `json:"identifier,omitempty"` // IP or user ID
+	LastViolation    time.Time   `json:"last_violation,omitempty"`
+	TotalViolations  int64       `json:"total_violations,omitempty"`
+	ViolationHistory []time.Time `json:"violation_history,omitempty"`
+}
+
+// WarmCacheRequest 缓存预热请求，datasets 为空时预热全部数据集
+type WarmCacheRequest struct {
+	Datasets []string `json:"datasets"` // 数据集名称
+}
+
+// WarmDatasetStatus 单个数据集的预热进度
+type WarmDatasetStatus struct {
+	Done       int    `json:"done,omitempty"`        // 已预热的条目数
+	DurationMs int64  `json:"duration_ms,omitempty"` // 耗时（毫秒）
+	Error      string `json:"error,omitempty"`       // 失败原因
+	Name       string `json:"name,omitempty"`        // 数据集名称
+	State      string `json:"state,omitempty"`       // 状态
+	Total      int    `json:"total,omitempty"`       // 条目总数，未知时为 0
+}
+
+// WarmStatus 一次预热任务的进度
+type WarmStatus struct {
+	Datasets   []WarmDatasetStatus `json:"datasets,omitempty"`    // 各数据集的进度
+	FinishedAt *time.Time          `json:"finished_at,omitempty"` // 结束时间
+	ID         int                 `json:"id,omitempty"`          // 任务编号，每次预热递增
+	StartedAt  time.Time           `json:"started_at,omitempty"`  // 开始时间
+	State      string              `json:"state,omitempty"`       // 状态
+	Trigger    string              `json:"trigger,omitempty"`     // 触发方式：startup 或 api
+}
+
+// AuthJWKS JSON Web Key Set
+// Publish the public keys used to sign access tokens so that other services can verify them. Keys are identified by the kid token header; HMAC keys are never published.
+//
+// GET /.well-known/jwks.json
+func (c *Client) AuthJWKS(ctx context.Context, opts ...RequestOption) (*JWKS, error) {
+	req := &request{method: http.MethodGet, path: "/.well-known/jwks.json"}
+	var out JWKS
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OIDCDiscovery OpenID Connect 发现文档
+// 返回 OpenID Connect 提供方的元数据，依赖方据此找到授权、令牌、用户信息端点和公钥集合。authorization_endpoint 为前端的授权同意页面
+//
+// GET /.well-known/openid-configuration
+func (c *Client) OIDCDiscovery(ctx context.Context, opts ...RequestOption) (*OIDCDiscovery, error) {
+	req := &request{method: http.MethodGet, path: "/.well-known/openid-configuration"}
+	var out OIDCDiscovery
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementAdminListAnnouncementsParams AnnouncementAdminListAnnouncements 的查询参数和请求头，零值的参数不会发送
+type AnnouncementAdminListAnnouncementsParams struct {
+	Page  int // 页码
+	Limit int // 每页项目数量
+}
+
+// apply 将参数写入请求
+func (p *AnnouncementAdminListAnnouncementsParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Page != 0 {
+		r.setQuery("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+}
+
+// AnnouncementAdminListAnnouncements 列出公告
+// 分页列出全部公告，包括尚未生效和已过期的公告，按创建时间倒序（仅管理员）
+//
+// GET /api/v1/admin/announcements
+func (c *Client) AnnouncementAdminListAnnouncements(ctx context.Context, params *AnnouncementAdminListAnnouncementsParams, opts ...RequestOption) (*AnnouncementListResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/announcements", auth: true, envelope: true}
+	params.apply(req)
+	var out AnnouncementListResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementCreateAnnouncement 发布公告
+// 创建公告（仅管理员）。roles 为空时对所有用户可见，tenant_id 为空时对所有租户可见；starts_at 为空时立即生效，ends_at 为空时一直有效。本实例的订阅者立即收到推送
+//
+// POST /api/v1/admin/announcements
+func (c *Client) AnnouncementCreateAnnouncement(ctx context.Context, body AnnouncementRequest, opts ...RequestOption) (*Announcement, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/announcements", auth: true, envelope: true}
+	req.body = body
+	var out Announcement
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementAdminGetAnnouncement 获取公告
+// 获取指定公告（仅管理员）
+//
+// GET /api/v1/admin/announcements/{id}
+func (c *Client) AnnouncementAdminGetAnnouncement(ctx context.Context, id string, opts ...RequestOption) (*Announcement, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/announcements/" + url.PathEscape(id), auth: true, envelope: true}
+	var out Announcement
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementUpdateAnnouncement 修改公告
+// 替换公告的完整定义（仅管理员），本实例的订阅者立即收到推送，其他实例在缓存失效后的下一次检查时推送
+//
+// PUT /api/v1/admin/announcements/{id}
+func (c *Client) AnnouncementUpdateAnnouncement(ctx context.Context, id string, body AnnouncementRequest, opts ...RequestOption) (*Announcement, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/announcements/" + url.PathEscape(id), auth: true, envelope: true}
+	req.body = body
+	var out Announcement
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementDeleteAnnouncement 删除公告
+// 删除公告（仅管理员），要提前结束公告也可以把 ends_at 修改为当前时间
+//
+// DELETE /api/v1/admin/announcements/{id}
+func (c *Client) AnnouncementDeleteAnnouncement(ctx context.Context, id string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/announcements/" + url.PathEscape(id), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// BackupListBackupsParams BackupListBackups 的查询参数和请求头，零值的参数不会发送
+type BackupListBackupsParams struct {
+	Page  int // 页码
+	Limit int // 每页项目数量
+}
+
+// apply 将参数写入请求
+func (p *BackupListBackupsParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Page != 0 {
+		r.setQuery("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+}
+
+// BackupListBackups 列出数据库备份
+// 分页列出手动和自动创建的备份，包括执行中和失败的备份，按创建时间倒序（仅管理员）。过期的备份由定期清理删除
+//
+// GET /api/v1/admin/backups
+func (c *Client) BackupListBackups(ctx context.Context, params *BackupListBackupsParams, opts ...RequestOption) (*BackupListResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/backups", auth: true, envelope: true}
+	params.apply(req)
+	var out BackupListResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BackupCreateBackup 创建数据库备份
+// 在后台执行 pg_dump 并把加密后的备份文件保存到对象存储（仅管理员）。轮询 GET /api/v1/admin/backups/{id} 查看进度；每个实例同一时间只执行一个备份或恢复
+//
+// POST /api/v1/admin/backups
+func (c *Client) BackupCreateBackup(ctx context.Context, opts ...RequestOption) (*Backup, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/backups", auth: true, envelope: true}
+	var out Backup
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BackupGetBackup 查询数据库备份
+// 返回备份的状态、大小和 SHA-256 校验和（仅管理员），校验和针对解密后的备份文件
+//
+// GET /api/v1/admin/backups/{id}
+func (c *Client) BackupGetBackup(ctx context.Context, id string, opts ...RequestOption) (*Backup, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/backups/" + url.PathEscape(id), auth: true, envelope: true}
+	var out Backup
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BackupRestoreBackup 从备份恢复数据库
+// 在后台用已完成的备份替换数据库的内容（仅管理员），confirm 须与路径中的备份ID相同。恢复在单个事务中执行，失败时数据库保持恢复前的状态；备份列表不受恢复影响。恢复期间其他实例仍在处理请求，应先开启维护模式。轮询 GET /api/v1/admin/restore 查看进度
+//
+// POST /api/v1/admin/backups/{id}/restore
+func (c *Client) BackupRestoreBackup(ctx context.Context, id string, body RestoreBackupRequest, opts ...RequestOption) (*RestoreStatus, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/backups/" + url.PathEscape(id) + "/restore", auth: true, envelope: true}
+	req.body = body
+	var out RestoreStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BanListListBans 列出封禁
+// 列出因限流违规被自动封禁的 IP 和用户，按到期时间排序（仅管理员）
+//
+// GET /api/v1/admin/bans
+func (c *Client) BanListListBans(ctx context.Context, opts ...RequestOption) ([]Ban, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/bans", auth: true, envelope: true}
+	var out []Ban
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// BanListUnban 解除封禁
+// 提前解除 IP 或用户的封禁（仅管理员），解除后的一个统计窗口内不会因之前的违规再次自动封禁
+//
+// DELETE /api/v1/admin/bans/{type}/{identifier}
+func (c *Client) BanListUnban(ctx context.Context, typeParam string, identifier string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/bans/" + url.PathEscape(typeParam) + "/" + url.PathEscape(identifier), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// CacheFlushParams CacheFlush 的查询参数和请求头，零值的参数不会发送
+type CacheFlushParams struct {
+	Force *bool // Confirm flushing the whole cache server when the driver cannot scope the flush
+}
+
+// apply 将参数写入请求
+func (p *CacheFlushParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Force != nil {
+		r.setQuery("force", strconv.FormatBool(*p.Force))
+	}
+}
+
+// CacheFlush Flush cache
+// Delete every key under the application key prefix (admin only). Drivers that cannot scope the flush to the prefix (memcached) wipe the whole cache server, so the request must then be confirmed with force=true.
+//
+// POST /api/v1/admin/cache/flush
+func (c *Client) CacheFlush(ctx context.Context, params *CacheFlushParams, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/cache/flush", auth: true, envelope: true}
+	params.apply(req)
+	return c.do(ctx, req, nil, opts)
+}
+
+// CacheListKeysParams CacheListKeys 的查询参数和请求头，零值的参数不会发送
+type CacheListKeysParams struct {
+	Pattern string // Glob pattern
+	Limit   int    // Approximate number of keys per page (1-1000)
+	Cursor  string // Cursor returned by the previous page
+}
+
+// apply 将参数写入请求
+func (p *CacheListKeysParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Pattern != "" {
+		r.setQuery("pattern", p.Pattern)
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		r.setQuery("cursor", p.Cursor)
+	}
+}
+
+// CacheListKeys List cache keys
+// List the cache keys matching a glob pattern, without the application key prefix (admin only). Keys are read incrementally with SCAN, so listing never blocks the cache server; each page holds about limit keys, sorted within the page. Pass the returned cursor to fetch the next page; an empty cursor means the scan is complete. Keys changed during the scan may be returned twice or not at all. Not supported by the memcached driver.
+//
+// GET /api/v1/admin/cache/keys
+func (c *Client) CacheListKeys(ctx context.Context, params *CacheListKeysParams, opts ...RequestOption) (*CacheKeysResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/cache/keys", auth: true, envelope: true}
+	params.apply(req)
+	var out CacheKeysResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CacheDeleteKey Delete cache key
+// Delete a single cache key, given without the application key prefix (admin only). The next read reloads the value from the database.
+//
+// DELETE /api/v1/admin/cache/keys/{key}
+func (c *Client) CacheDeleteKey(ctx context.Context, key string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/cache/keys/" + url.PathEscape(key), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// CacheGetStats Get cache statistics
+// Get the statistics reported by the cache driver, together with the optional capabilities it supports (admin only)
+//
+// GET /api/v1/admin/cache/stats
+func (c *Client) CacheGetStats(ctx context.Context, opts ...RequestOption) (*CacheStatsResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/cache/stats", auth: true, envelope: true}
+	var out CacheStatsResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CacheWarmStatus Get cache warm-up progress
+// Get the progress of the running or most recent cache warm-up, including the startup warm-up (admin only)
+//
+// GET /api/v1/admin/cache/warm
+func (c *Client) CacheWarmStatus(ctx context.Context, opts ...RequestOption) (*WarmStatus, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/cache/warm", auth: true, envelope: true}
+	var out WarmStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CacheWarm Warm cache
+// Start warming the cache in the background (admin only). Without datasets every registered dataset is warmed; otherwise only the named ones. Only one warm-up runs at a time. Poll GET /api/v1/admin/cache/warm for progress.
+//
+// POST /api/v1/admin/cache/warm
+func (c *Client) CacheWarm(ctx context.Context, body WarmCacheRequest, opts ...RequestOption) (*WarmStatus, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/cache/warm", auth: true, envelope: true}
+	req.body = body
+	var out WarmStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DrainGetDrain 获取排空进度
+// 返回当前实例的排空进度和处理中的请求数（仅管理员）。state 为 drained 时进程可以安全退出
+//
+// GET /api/v1/admin/drain
+func (c *Client) DrainGetDrain(ctx context.Context, opts ...RequestOption) (*DrainStatus, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/drain", auth: true, envelope: true}
+	var out DrainStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DrainStartDrain 排空实例
+// 在后台排空处理请求的实例（仅管理员）：先将就绪检查标记为失败并等待 server.shutdown.pre_stop_delay 秒让负载均衡器摘除流量， 再等待处理中的请求完成（最多 server.shutdown.drain_timeout 秒），最后停止后台任务和事件消费者。排空期间端口保持打开，可通过 GET 轮询进度。 排空完成后 server.shutdown.exit_after_drain 为 true 时进程自动退出，否则等待 SIGTERM 并跳过已完成的关闭阶段。排空只执行一次，重复调用返回 200 和当前进度
+//
+// POST /api/v1/admin/drain
+func (c *Client) DrainStartDrain(ctx context.Context, opts ...RequestOption) (*DrainStatus, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/drain", auth: true, envelope: true}
+	var out DrainStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportCreateExport 导出用户数据
+// 创建导出任务并在后台生成 CSV、JSON 或 XLSX 文件（仅管理员），筛选条件与用户列表相同。轮询 GET /api/v1/admin/exports/{id} 查看进度，完成后响应中的 download_url 为限时有效的下载链接。XLSX 最多导出 1048575 行
+//
+// POST /api/v1/admin/exports
+func (c *Client) ExportCreateExport(ctx context.Context, body ExportRequest, opts ...RequestOption) (*Export, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/exports", auth: true, envelope: true}
+	req.body = body
+	var out Export
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportGetExport 查询导出任务
+// 返回导出任务的状态和进度（仅管理员）。任务完成后 download_url 为新签发的下载链接，链接在 exports.url_ttl 后失效，过期后重新查询即可获得新链接；文件在 expires_at 后删除
+//
+// GET /api/v1/admin/exports/{id}
+func (c *Client) ExportGetExport(ctx context.Context, id string, opts ...RequestOption) (*Export, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/exports/" + url.PathEscape(id), auth: true, envelope: true}
+	var out Export
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FeatureFlagListFeatureFlags 列出功能开关
+// 列出所有功能开关的定义，按键排序（仅管理员）
+//
+// GET /api/v1/admin/feature-flags
+func (c *Client) FeatureFlagListFeatureFlags(ctx context.Context, opts ...RequestOption) ([]Flag, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/feature-flags", auth: true, envelope: true}
+	var out []Flag
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// FeatureFlagCreateFeatureFlag 创建功能开关
+// 创建功能开关（仅管理员）。percentage 为按用户ID哈希分桶开启的比例，100 表示对所有请求开启；users 中的用户始终开启；enabled 为 false 时对所有用户关闭
+//
+// POST /api/v1/admin/feature-flags
+func (c *Client) FeatureFlagCreateFeatureFlag(ctx context.Context, body CreateFeatureFlagRequest, opts ...RequestOption) (*Flag, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/feature-flags", auth: true, envelope: true}
+	req.body = body
+	var out Flag
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FeatureFlagGetFeatureFlag 获取功能开关
+// 获取指定功能开关的定义（仅管理员）
+//
+// GET /api/v1/admin/feature-flags/{key}
+func (c *Client) FeatureFlagGetFeatureFlag(ctx context.Context, key string, opts ...RequestOption) (*Flag, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/feature-flags/" + url.PathEscape(key), auth: true, envelope: true}
+	var out Flag
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FeatureFlagUpdateFeatureFlag 修改功能开关
+// 替换功能开关的完整定义（仅管理员），所有实例在收到变更通知或本地缓存过期后生效
+//
+// PUT /api/v1/admin/feature-flags/{key}
+func (c *Client) FeatureFlagUpdateFeatureFlag(ctx context.Context, key string, body UpdateFeatureFlagRequest, opts ...RequestOption) (*Flag, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/feature-flags/" + url.PathEscape(key), auth: true, envelope: true}
+	req.body = body
+	var out Flag
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FeatureFlagDeleteFeatureFlag 删除功能开关
+// 删除功能开关（仅管理员），删除后的开关对所有用户视为关闭
+//
+// DELETE /api/v1/admin/feature-flags/{key}
+func (c *Client) FeatureFlagDeleteFeatureFlag(ctx context.Context, key string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/feature-flags/" + url.PathEscape(key), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// AdminUserStopImpersonation 结束模拟登录
+// 使用模拟登录令牌调用，吊销该令牌并记录审计事件，之后该令牌不能再使用。普通令牌不能调用此接口。
+//
+// POST /api/v1/admin/impersonation/stop
+func (c *Client) AdminUserStopImpersonation(ctx context.Context, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/impersonation/stop", auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// ImportImportUsersParams ImportImportUsers 的查询参数和请求头，零值的参数不会发送
+type ImportImportUsersParams struct {
+	Format string // 文件格式（csv、json），默认按文件扩展名判断
+	DryRun *bool  // 只校验不写入
+}
+
+// apply 将参数写入请求
+func (p *ImportImportUsersParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Format != "" {
+		r.setQuery("format", p.Format)
+	}
+	if p.DryRun != nil {
+		r.setQuery("dry_run", strconv.FormatBool(*p.DryRun))
+	}
+}
+
+// ImportImportUsers 批量导入用户
+// 以 multipart/form-data 上传 CSV 或 JSON 文件批量创建用户（仅管理员）。CSV 须包含 username、email、password 列，可包含 first_name、last_name 列；JSON 为对象数组，字段与注册请求相同。每行按注册请求的规则校验，邮箱（不区分大小写）或用户名与文件中之前的行或现有用户重复的行被跳过；有效的行分批写入，每批一个事务。dry_run=true 时只校验并返回错误报告。导入的用户为已激活的普通用户，不发送欢迎通知
+//
+// POST /api/v1/admin/imports
+func (c *Client) ImportImportUsers(ctx context.Context, fileParamFilename string, fileParam io.Reader, params *ImportImportUsersParams, opts ...RequestOption) (*ImportReport, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/imports", auth: true, envelope: true}
+	req.files = append(req.files, file{field: "file", filename: fileParamFilename, reader: fileParam})
+	params.apply(req)
+	var out ImportReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LoggingGetLevels Get log levels
+// Get the global log level and the per-module level overrides currently in effect (admin only)
+//
+// GET /api/v1/admin/logging/level
+func (c *Client) LoggingGetLevels(ctx context.Context, opts ...RequestOption) (*LevelSettings, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/logging/level", auth: true, envelope: true}
+	var out LevelSettings
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LoggingUpdateLevel Update log level
+// Change the log level at runtime without a restart (admin only). Without a module the global level is changed and is restored from configuration on the next reload; with a module the level overrides the global level for that module, and an empty level removes the override.
+//
+// PUT /api/v1/admin/logging/level
+func (c *Client) LoggingUpdateLevel(ctx context.Context, body UpdateLogLevelRequest, opts ...RequestOption) (*LevelSettings, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/logging/level", auth: true, envelope: true}
+	req.body = body
+	var out LevelSettings
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MaintenanceGetMaintenance 获取维护状态
+// 返回当前生效的维护状态，source 为 config 表示由配置开启（仅管理员）
+//
+// GET /api/v1/admin/maintenance
+func (c *Client) MaintenanceGetMaintenance(ctx context.Context, opts ...RequestOption) (*State, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/maintenance", auth: true, envelope: true}
+	var out State
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MaintenanceEnableMaintenance 开启维护
+// 开启维护模式（仅管理员）。维护期间除放行路径外的请求返回 503 MAINTENANCE_MODE，携带 X-Maintenance-Bypass 旁路令牌的请求不受影响。 已在维护中时更新说明和预计结束时间，开始时间不变；缓存是 Redis 时其他实例在 maintenance.refresh_interval 内生效
+//
+// PUT /api/v1/admin/maintenance
+func (c *Client) MaintenanceEnableMaintenance(ctx context.Context, body EnableMaintenanceRequest, opts ...RequestOption) (*State, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/maintenance", auth: true, envelope: true}
+	req.body = body
+	var out State
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MaintenanceDisableMaintenance 结束维护
+// 结束管理员开启的维护（仅管理员）。由配置开启的维护需要修改 maintenance.active 才能结束，此时返回 409
+//
+// DELETE /api/v1/admin/maintenance
+func (c *Client) MaintenanceDisableMaintenance(ctx context.Context, opts ...RequestOption) (*State, error) {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/maintenance", auth: true, envelope: true}
+	var out State
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetricsCountries 按国家统计流量
+// 按客户端 IP 所在国家返回请求数、4xx 和 5xx 数以及响应字节数（仅管理员），需要启用 GeoIP 查询。无法定位的请求计入 unknown。结果按请求数从多到少排序。同样的请求数以 Prometheus 格式在 /api/v1/metrics/prometheus 的 http_requests_by_country_total 导出。
+//
+// GET /api/v1/admin/metrics/countries
+func (c *Client) MetricsCountries(ctx context.Context, opts ...RequestOption) ([]CountryStats, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/metrics/countries", auth: true, envelope: true}
+	var out []CountryStats
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// MetricsHistoryParams MetricsHistory 的查询参数和请求头，零值的参数不会发送
+type MetricsHistoryParams struct {
+	Hours int // 查询最近多少小时，默认 24
+}
+
+// apply 将参数写入请求
+func (p *MetricsHistoryParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Hours != 0 {
+		r.setQuery("hours", strconv.Itoa(p.Hours))
+	}
+}
+
+// MetricsHistory 指标历史
+// 返回最近 hours 小时内按时间桶汇总的请求数、5xx 数、平均耗时、限流和用户缓存统计，供管理后台绘制趋势图（仅管理员）。数据来自定期写入数据库的指标快照，进程重启后仍然保留。查询范围不超过原始快照保留时长时按采集间隔返回，否则按汇总间隔返回，尚未结束的汇总时间桶不包含在内。
+//
+// GET /api/v1/admin/metrics/history
+func (c *Client) MetricsHistory(ctx context.Context, params *MetricsHistoryParams, opts ...RequestOption) (*MetricsHistoryResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/metrics/history", auth: true, envelope: true}
+	params.apply(req)
+	var out MetricsHistoryResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetricsHTTPMetricsParams MetricsHTTPMetrics 的查询参数和请求头，零值的参数不会发送
+type MetricsHTTPMetricsParams struct {
+	Route  string // 只返回该路由模板的统计，如 /api/v1/users/:id
+	Method string // 只返回该方法的统计，如 GET
+}
+
+// apply 将参数写入请求
+func (p *MetricsHTTPMetricsParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Route != "" {
+		r.setQuery("route", p.Route)
+	}
+	if p.Method != "" {
+		r.setQuery("method", p.Method)
+	}
+}
+
+// MetricsHTTPMetrics HTTP 服务指标
+// 按方法、路由模板和状态码返回请求数、请求和响应字节数以及耗时直方图，并返回当前和峰值处理中的请求数（仅管理员）。未匹配任何路由的请求计入 unmatched 路由。结果按请求数从多到少排序，可按路由模板和方法过滤。同样的指标以 Prometheus 格式在 /api/v1/metrics/prometheus 导出。
+//
+// GET /api/v1/admin/metrics/http
+func (c *Client) MetricsHTTPMetrics(ctx context.Context, params *MetricsHTTPMetricsParams, opts ...RequestOption) (*HTTPStats, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/metrics/http", auth: true, envelope: true}
+	params.apply(req)
+	var out HTTPStats
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetricsQueryOffenders 请求查询最多的路由
+// 返回本实例查询过多或出现 N+1 查询的路由（仅管理员），需要启用 database.request_queries。单个请求的查询数超过 max_queries，或同一语句（忽略参数）执行达到 repeat_threshold 次时，请求计入所属路由，并记录查询数最多的请求的重复语句和调用栈。结果按单个请求的最大查询数从多到少排序，数据在进程内保存，重启后清零。
+//
+// GET /api/v1/admin/metrics/queries
+func (c *Client) MetricsQueryOffenders(ctx context.Context, opts ...RequestOption) (*RequestQueryStats, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/metrics/queries", auth: true, envelope: true}
+	var out RequestQueryStats
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetricsRateLimitTimeSeriesParams MetricsRateLimitTimeSeries 的查询参数和请求头，零值的参数不会发送
+type MetricsRateLimitTimeSeriesParams struct {
+	Window     string // 查询最近多长时间，Go 时长格式，最大 24h
+	Resolution string // 每个数据点的时长，Go 时长格式，不超过 window
+}
+
+// apply 将参数写入请求
+func (p *MetricsRateLimitTimeSeriesParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Window != "" {
+		r.setQuery("window", p.Window)
+	}
+	if p.Resolution != "" {
+		r.setQuery("resolution", p.Resolution)
+	}
+}
+
+// MetricsRateLimitTimeSeries 限流时间序列
+// 返回本实例最近 window 时长内按 resolution 汇总的限流检查数、拒绝数、拒绝率和检查耗时（平均、最大、P95），供管理后台绘制限流趋势（仅管理员）。数据来自进程内按分钟汇总的统计，保留 24 小时，进程重启后清零；resolution 向上取整到分钟，数据点按 resolution 的整数倍对齐，没有请求的时间段返回 0。
+//
+// GET /api/v1/admin/metrics/rate-limit
+func (c *Client) MetricsRateLimitTimeSeries(ctx context.Context, params *MetricsRateLimitTimeSeriesParams, opts ...RequestOption) ([]RateLimitTimeSeriesPoint, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/metrics/rate-limit", auth: true, envelope: true}
+	params.apply(req)
+	var out []RateLimitTimeSeriesPoint
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// OIDCListClients 列出 OpenID Connect 客户端
+// 列出已注册的客户端，按创建时间倒序（仅管理员）
+//
+// GET /api/v1/admin/oauth-clients
+func (c *Client) OIDCListClients(ctx context.Context, opts ...RequestOption) ([]OAuthClient, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/oauth-clients", auth: true, envelope: true}
+	var out []OAuthClient
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// OIDCCreateClient 注册 OpenID Connect 客户端
+// 注册依赖方（仅管理员）。回调地址须为不带片段的 http 或 https 绝对地址，授权请求中的 redirect_uri 须与其中之一完全一致。机密客户端的客户端密钥只在响应中返回一次
+//
+// POST /api/v1/admin/oauth-clients
+func (c *Client) OIDCCreateClient(ctx context.Context, body OAuthClientRequest, opts ...RequestOption) (*OAuthClientCreatedResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/oauth-clients", auth: true, envelope: true}
+	req.body = body
+	var out OAuthClientCreatedResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OIDCDeleteClient 删除 OpenID Connect 客户端
+// 删除客户端（仅管理员），之后该客户端不能再发起授权或兑换令牌，已签发的令牌在过期前仍然有效
+//
+// DELETE /api/v1/admin/oauth-clients/{id}
+func (c *Client) OIDCDeleteClient(ctx context.Context, id string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/admin/oauth-clients/" + url.PathEscape(id), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// AdminOverviewOverviewParams AdminOverviewOverview 的查询参数和请求头，零值的参数不会发送
+type AdminOverviewOverviewParams struct {
+	Refresh *bool // 跳过缓存重新采集
+}
+
+// apply 将参数写入请求
+func (p *AdminOverviewOverviewParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Refresh != nil {
+		r.setQuery("refresh", strconv.FormatBool(*p.Refresh))
+	}
+}
+
+// AdminOverviewOverview 系统概览
+// 汇总用户数量、请求和限流统计、缓存统计、数据库连接池健康状态、运行时长和版本信息，供管理后台仪表盘使用（仅管理员）。结果在进程内缓存 5 秒，refresh=true 时重新采集。某一部分采集失败时在该部分的 error 字段中说明，接口仍返回 200。
+//
+// GET /api/v1/admin/overview
+func (c *Client) AdminOverviewOverview(ctx context.Context, params *AdminOverviewOverviewParams, opts ...RequestOption) (*AdminOverviewResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/overview", auth: true, envelope: true}
+	params.apply(req)
+	var out AdminOverviewResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RateLimitGetRateLimits 获取速率限制
+// 返回当前生效的速率限制和管理员设置的覆盖（仅管理员）
+//
+// GET /api/v1/admin/rate-limits
+func (c *Client) RateLimitGetRateLimits(ctx context.Context, opts ...RequestOption) (*RateLimitState, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/rate-limits", auth: true, envelope: true}
+	var out RateLimitState
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RateLimitUpdateRateLimits 修改速率限制
+// 替换管理员设置的速率限制覆盖（仅管理员），覆盖优先于配置文件，字段为 0 或空时沿用配置文件中的值，提交空对象恢复配置文件中的限制。 设置了路由限制的路由单独计数，不占用全局限制。缓存是 Redis 时覆盖保存在 Redis 中，重启后仍然有效，并通过发布订阅同步到所有实例
+//
+// PUT /api/v1/admin/rate-limits
+func (c *Client) RateLimitUpdateRateLimits(ctx context.Context, body UpdateRateLimitsRequest, opts ...RequestOption) (*RateLimitState, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/rate-limits", auth: true, envelope: true}
+	req.body = body
+	var out RateLimitState
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BackupGetRestoreStatus 查询恢复状态
+// 返回本实例最近一次从备份恢复的状态（仅管理员），本实例没有执行过恢复时 state 为 idle。恢复由接收请求的实例执行，多实例部署时须在同一实例上查询
+//
+// GET /api/v1/admin/restore
+func (c *Client) BackupGetRestoreStatus(ctx context.Context, opts ...RequestOption) (*RestoreStatus, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/restore", auth: true, envelope: true}
+	var out RestoreStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RetentionGetRetention 获取数据保留策略
+// 返回各表的保留策略、定期清理的间隔和本实例最近一次清理的报告（仅管理员）
+//
+// GET /api/v1/admin/retention
+func (c *Client) RetentionGetRetention(ctx context.Context, opts ...RequestOption) (*RetentionStatus, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/retention", auth: true, envelope: true}
+	var out RetentionStatus
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RetentionRunRetentionParams RetentionRunRetention 的查询参数和请求头，零值的参数不会发送
+type RetentionRunRetentionParams struct {
+	DryRun *bool // 是否只统计可删除的行数
+}
+
+// apply 将参数写入请求
+func (p *RetentionRunRetentionParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.DryRun != nil {
+		r.setQuery("dry_run", strconv.FormatBool(*p.DryRun))
+	}
+}
+
+// RetentionRunRetention 执行数据保留策略
+// 立即按所有保留策略清理一次过期数据并返回报告（仅管理员）。默认为试运行，只统计各表可删除的行数； dry_run=false 时分批删除数据，每张表删除的行数记录一条审计事件。客户端断开不会中断清理
+//
+// POST /api/v1/admin/retention/run
+func (c *Client) RetentionRunRetention(ctx context.Context, params *RetentionRunRetentionParams, opts ...RequestOption) (*RetentionReport, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/retention/run", auth: true, envelope: true}
+	params.apply(req)
+	var out RetentionReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserListUsersParams AdminUserListUsers 的查询参数和请求头，零值的参数不会发送
+type AdminUserListUsersParams struct {
+	Q      string // 搜索关键字
+	Active *bool  // 是否激活
+	Role   string // 角色，可选值：user, admin
+	Page   int    // 页码
+	Limit  int    // 每页项目数量
+}
+
+// apply 将参数写入请求
+func (p *AdminUserListUsersParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Q != "" {
+		r.setQuery("q", p.Q)
+	}
+	if p.Active != nil {
+		r.setQuery("active", strconv.FormatBool(*p.Active))
+	}
+	if p.Role != "" {
+		r.setQuery("role", p.Role)
+	}
+	if p.Page != 0 {
+		r.setQuery("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+}
+
+// AdminUserListUsers 按条件列出用户
+// 分页列出用户，包含已停用的用户（仅管理员）。q 按用户名、邮箱和姓名模糊匹配；role=admin 只返回管理员，role=user 只返回普通用户。结果不经过缓存。
+//
+// GET /api/v1/admin/users
+func (c *Client) AdminUserListUsers(ctx context.Context, params *AdminUserListUsersParams, opts ...RequestOption) (*Page[SafeUser], error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/users", auth: true, envelope: true}
+	params.apply(req)
+	var out Page[SafeUser]
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserListUsersIter 从 params.Page（默认第 1 页）开始逐页调用 AdminUserListUsers，逐条返回数据，出错时返回错误并结束
+func (c *Client) AdminUserListUsersIter(ctx context.Context, params *AdminUserListUsersParams, opts ...RequestOption) iter.Seq2[SafeUser, error] {
+	var p AdminUserListUsersParams
+	if params != nil {
+		p = *params
+	}
+	return paginate(p.Page, func(page int) (*Page[SafeUser], error) {
+		p.Page = page
+		return c.AdminUserListUsers(ctx, &p, opts...)
+	})
+}
+
+// AdminUserStreamUsersParams AdminUserStreamUsers 的查询参数和请求头，零值的参数不会发送
+type AdminUserStreamUsersParams struct {
+	Q      string // 搜索关键字
+	Active *bool  // 是否激活
+	Role   string // 角色，可选值：user, admin
+}
+
+// apply 将参数写入请求
+func (p *AdminUserStreamUsersParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Q != "" {
+		r.setQuery("q", p.Q)
+	}
+	if p.Active != nil {
+		r.setQuery("active", strconv.FormatBool(*p.Active))
+	}
+	if p.Role != "" {
+		r.setQuery("role", p.Role)
+	}
+}
+
+// AdminUserStreamUsers 流式列出全部用户
+// 不分页地列出匹配条件的全部用户，包含已停用的用户（仅管理员），按用户ID排序。用户逐行从数据库读取并逐个写入响应，内存占用与用户数量无关，适合导出大量用户；需要异步生成文件时使用数据导出接口。响应开始后读取失败时响应体被截断，不是合法的 JSON。结果不经过缓存。
+//
+// GET /api/v1/admin/users/stream
+func (c *Client) AdminUserStreamUsers(ctx context.Context, params *AdminUserStreamUsersParams, opts ...RequestOption) ([]SafeUser, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/admin/users/stream", auth: true, envelope: true}
+	params.apply(req)
+	var out []SafeUser
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// AdminUserActivateUser 激活用户
+// 重新激活已停用的用户（仅管理员）
+//
+// POST /api/v1/admin/users/{id}/activate
+func (c *Client) AdminUserActivateUser(ctx context.Context, id string, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/activate", auth: true, envelope: true}
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserDeactivateUser 停用用户
+// 停用用户并吊销其已签发的全部令牌，停用的用户无法登录（仅管理员）。管理员不能停用自己。
+//
+// POST /api/v1/admin/users/{id}/deactivate
+func (c *Client) AdminUserDeactivateUser(ctx context.Context, id string, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/deactivate", auth: true, envelope: true}
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PrivacyEraseUser 抹除用户的账户
+// 代用户抹除其个人数据（仅管理员），用于处理通过其他渠道提出的删除请求，已注销的账户也可以抹除。处理与用户自行抹除相同，操作不可撤销。管理员不能通过该接口抹除自己
+//
+// POST /api/v1/admin/users/{id}/erasure
+func (c *Client) PrivacyEraseUser(ctx context.Context, id string, opts ...RequestOption) (*ErasureReport, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/erasure", auth: true, envelope: true}
+	var out ErasureReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserImpersonate 模拟登录用户
+// 以目标用户身份签发短期令牌，令牌的 act 声明记录管理员ID，imp_banner 声明为模拟登录横幅（仅管理员）。不能模拟自己或其他管理员，模拟登录期间不能再次模拟。令牌有效期 15 分钟。
+//
+// POST /api/v1/admin/users/{id}/impersonate
+func (c *Client) AdminUserImpersonate(ctx context.Context, id string, opts ...RequestOption) (*ImpersonationResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/impersonate", auth: true, envelope: true}
+	var out ImpersonationResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserResetPassword 强制重置用户密码
+// 将用户密码重置为随机临时密码并吊销其已签发的全部令牌（仅管理员）。临时密码只在本次响应中返回，需要通过安全渠道转交用户，用户登录后应立即修改密码。
+//
+// POST /api/v1/admin/users/{id}/password-reset
+func (c *Client) AdminUserResetPassword(ctx context.Context, id string, opts ...RequestOption) (*PasswordResetResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/password-reset", auth: true, envelope: true}
+	var out PasswordResetResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUserAssignRoles 分配用户角色
+// 设置用户的完整角色集合（仅管理员）。包含 admin 时授予管理员权限，否则撤销；管理员不能撤销自己的管理员角色。
+//
+// PUT /api/v1/admin/users/{id}/roles
+func (c *Client) AdminUserAssignRoles(ctx context.Context, id string, body AssignRolesRequest, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/admin/users/" + url.PathEscape(id) + "/roles", auth: true, envelope: true}
+	req.body = body
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnouncementListAnnouncements 获取生效中的公告
+// 返回当前对调用者生效的公告，按创建时间倒序。未登录时只返回不限角色的公告；租户取自用户所属租户或请求的租户。公告列表来自共享缓存，管理员修改后立即失效
+//
+// GET /api/v1/announcements
+func (c *Client) AnnouncementListAnnouncements(ctx context.Context, opts ...RequestOption) ([]Announcement, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/announcements", auth: true, envelope: true}
+	var out []Announcement
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// AuthChangePassword Change user password
+// Change the password of the currently authenticated user
+//
+// POST /api/v1/auth/change-password
+func (c *Client) AuthChangePassword(ctx context.Context, body ChangePasswordRequest, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/change-password", auth: true, envelope: true}
+	req.body = body
+	return c.do(ctx, req, nil, opts)
+}
+
+// PasswordlessStartDevice 申请设备码
+// 设备（电视、命令行工具）申请设备码和用户码（RFC 8628）。设备显示 user_code 和 verification_uri（或把 verification_uri_complete 显示为二维码），然后按 interval 轮询令牌接口
+//
+// POST /api/v1/auth/device/code
+func (c *Client) PasswordlessStartDevice(ctx context.Context, opts ...RequestOption) (*DeviceCodeResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/device/code", envelope: true}
+	var out DeviceCodeResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PasswordlessDeviceToken 设备轮询令牌
+// 设备用设备码轮询，用户允许后返回与密码登录相同的访问令牌，设备码只能兑换一次。用户尚未决定时返回 400，error_code 为 authorization_pending；轮询过快为 slow_down，设备应把间隔增加 5 秒；用户拒绝为 access_denied；设备码无效或过期为 expired_token
+//
+// POST /api/v1/auth/device/token
+func (c *Client) PasswordlessDeviceToken(ctx context.Context, body DeviceTokenRequest, opts ...RequestOption) (*LoginResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/device/token", envelope: true}
+	req.body = body
+	var out LoginResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PasswordlessVerifyDevice 允许或拒绝设备登录
+// 已登录用户在验证页面输入设备上显示的用户码，允许后设备以该用户身份登录。用户码不区分大小写，只能使用一次
+//
+// POST /api/v1/auth/device/verify
+func (c *Client) PasswordlessVerifyDevice(ctx context.Context, body DeviceVerifyRequest, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/device/verify", auth: true, envelope: true}
+	req.body = body
+	return c.do(ctx, req, nil, opts)
+}
+
+// AuthLogin Login user
+// Authenticate a user with email and password and return an access token together with a refresh token. Use the refresh token with POST /api/v1/auth/refresh to obtain a new pair before the access token expires. Error messages are localized according to the Accept-Language header (en, zh).
+//
+// POST /api/v1/auth/login
+func (c *Client) AuthLogin(ctx context.Context, body LoginRequest, opts ...RequestOption) (*LoginResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/login", envelope: true}
+	req.body = body
+	var out LoginResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthLogout Logout user
+// Logout the current user by blacklisting their access token. Pass the refresh token in the optional request body to revoke it as well; otherwise it stays valid until it expires. Error messages are localized according to the Accept-Language header (en, zh).
+//
+// POST /api/v1/auth/logout
+func (c *Client) AuthLogout(ctx context.Context, body LogoutRequest, opts ...RequestOption) (*LogoutResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/logout", auth: true, envelope: true}
+	req.body = body
+	var out LogoutResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PasswordlessRequestMagicLink 申请登录链接
+// 向账号邮箱发送登录链接，链接在 passwordless.magic_link_ttl 内有效且只能使用一次。邮箱不存在或账号已停用时同样返回 202，不泄露账号是否存在
+//
+// POST /api/v1/auth/magic-link
+func (c *Client) PasswordlessRequestMagicLink(ctx context.Context, body MagicLinkRequest, opts ...RequestOption) (*MagicLinkResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/magic-link", envelope: true}
+	req.body = body
+	var out MagicLinkResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PasswordlessRedeemMagicLink 使用登录链接登录
+// 用登录链接中的令牌换取与密码登录相同的访问令牌，令牌只能使用一次
+//
+// POST /api/v1/auth/magic-link/verify
+func (c *Client) PasswordlessRedeemMagicLink(ctx context.Context, body MagicLinkVerifyRequest, opts ...RequestOption) (*LoginResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/magic-link/verify", envelope: true}
+	req.body = body
+	var out LoginResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthMe Get current user profile
+// Get the profile of the currently authenticated user. This endpoint serves frequently accessed user profile data from Redis cache with 5-minute TTL. If Redis is unavailable, data is served directly from PostgreSQL database. Cache status is provided in response headers.
+//
+// GET /api/v1/auth/me
+func (c *Client) AuthMe(ctx context.Context, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/auth/me", auth: true, envelope: true}
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthRefresh Refresh access token
+// Exchange a refresh token for a new access token and refresh token. The refresh token is rotated: the submitted token is revoked and cannot be used again. Refresh tokens are rejected after logout, after the user changes their password and once the account is deleted or deactivated. Error messages are localized according to the Accept-Language header (en, zh).
+//
+// POST /api/v1/auth/refresh
+func (c *Client) AuthRefresh(ctx context.Context, body RefreshTokenRequest, opts ...RequestOption) (*RefreshTokenResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/refresh", envelope: true}
+	req.body = body
+	var out RefreshTokenResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthRegister Register new user
+// Register a new user account. The username may contain letters, digits, underscores, dots and hyphens; the password must satisfy the password policy. Error messages are localized according to the Accept-Language header (en, zh).
+//
+// POST /api/v1/auth/register
+func (c *Client) AuthRegister(ctx context.Context, body RegisterRequest, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/auth/register", envelope: true}
+	req.body = body
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BillingListPlans 获取订阅套餐
+// 返回所有订阅套餐及其包含的权益、限流和用量配额，按展示顺序排列
+//
+// GET /api/v1/billing/plans
+func (c *Client) BillingListPlans(ctx context.Context, opts ...RequestOption) ([]Plan, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/billing/plans", envelope: true}
+	var out []Plan
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// BillingStripeWebhookParams BillingStripeWebhook 的查询参数和请求头，零值的参数不会发送
+type BillingStripeWebhookParams struct {
+	StripeSignature string // Stripe 签名，如 t=1492774577,v1=5257a869...
+}
+
+// apply 将参数写入请求
+func (p *BillingStripeWebhookParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	r.setHeader("Stripe-Signature", p.StripeSignature)
+}
+
+// BillingStripeWebhook 接收 Stripe webhook
+// 校验 Stripe-Signature 签名后同步订阅的创建、更新和删除，其他事件直接确认。乱序到达的旧事件不会覆盖较新的状态。 订阅的价格没有对应的套餐时返回 400，Stripe 会稍后重试，可在配置中补充 stripe_price_ids 后等待重试
+//
+// POST /api/v1/billing/webhooks/stripe
+func (c *Client) BillingStripeWebhook(ctx context.Context, body Event, params *BillingStripeWebhookParams, opts ...RequestOption) (*StripeWebhookResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/billing/webhooks/stripe", envelope: true}
+	req.body = body
+	params.apply(req)
+	var out StripeWebhookResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HealthHealth Enhanced health check endpoint
+// Comprehensive health check including database connection pool metrics, Redis cache statistics, and system information. This endpoint provides detailed monitoring data including connection pool utilization, query performance, cache hit rates, memory usage, and latency metrics.
+//
+// GET /api/v1/health
+func (c *Client) HealthHealth(ctx context.Context, opts ...RequestOption) (*HealthResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/health", envelope: true}
+	var out HealthResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HealthHealthz2 Liveness probe
+// Kubernetes liveness probe. Runs only the liveness checks registered in the health registry (process-level state, never external dependencies), so a failing database does not cause the pod to be restarted. Returns 200 when alive and 503 otherwise.
+//
+// GET /api/v1/live
+func (c *Client) HealthHealthz2(ctx context.Context, opts ...RequestOption) (*HealthReport, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/live"}
+	var out HealthReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetaListErrorCodes List error codes
+// Get the machine-readable catalog of every error code the API can return, with its HTTP status, whether the request can be retried as-is, and a documentation link. The documentation link is also the RFC 7807 problem type URI.
+//
+// GET /api/v1/meta/errors
+func (c *Client) MetaListErrorCodes(ctx context.Context, opts ...RequestOption) ([]ErrorCodeInfo, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/meta/errors", envelope: true}
+	var out []ErrorCodeInfo
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// HealthMetrics Database metrics endpoint
+// Returns database connection pool statistics (open, idle, in-use connections and wait count), query performance statistics and per-operation query latency histograms collected by the query monitor plugin. The cache section reports hits, misses, loads, load latency and the per-key-prefix breakdown of each instrumented cache, keyed by cache name. The jwt_blacklist section reports the number of revoked tokens (read from the blacklist index, without scanning the keyspace) and, when the bloom filter is enabled, its skip and false-positive counters under jwt_blacklist.filter.
+//
+// GET /api/v1/metrics
+func (c *Client) HealthMetrics(ctx context.Context, opts ...RequestOption) error {
+	req := &request{method: http.MethodGet, path: "/api/v1/metrics", envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// HealthPrometheus Prometheus metrics endpoint
+// Returns the registered metrics in the Prometheus text exposition format: HTTP request counts, latency histograms, request/response sizes and in-flight requests by method, route and status, and cache hits, misses, loads, load latency histograms and per-key-prefix counters labelled by cache name.
+//
+// GET /api/v1/metrics/prometheus
+func (c *Client) HealthPrometheus(ctx context.Context, opts ...RequestOption) (string, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/metrics/prometheus", text: true}
+	var out string
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// OIDCAuthorizeParams OIDCAuthorize 的查询参数和请求头，零值的参数不会发送
+type OIDCAuthorizeParams struct {
+	ResponseType        string // 响应类型，可选值：code
+	ClientID            string // 客户端ID
+	RedirectUri         string // 回调地址
+	Scope               string // 以空格分隔的范围
+	State               string // 原样返回给客户端的状态
+	Nonce               string // 写入 ID 令牌的随机数
+	CodeChallenge       string // PKCE 挑战值
+	CodeChallengeMethod string // PKCE 挑战方法，可选值：S256
+}
+
+// apply 将参数写入请求
+func (p *OIDCAuthorizeParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	r.setQuery("response_type", p.ResponseType)
+	r.setQuery("client_id", p.ClientID)
+	r.setQuery("redirect_uri", p.RedirectUri)
+	r.setQuery("scope", p.Scope)
+	if p.State != "" {
+		r.setQuery("state", p.State)
+	}
+	if p.Nonce != "" {
+		r.setQuery("nonce", p.Nonce)
+	}
+	r.setQuery("code_challenge", p.CodeChallenge)
+	r.setQuery("code_challenge_method", p.CodeChallengeMethod)
+}
+
+// OIDCAuthorize 读取授权请求
+// 授权同意页面携带用户的访问令牌调用，校验依赖方的授权请求（参数与授权端点的查询参数相同）并返回需要展示的客户端名称和范围。只支持 code 响应类型和 S256 PKCE，scope 须包含 openid，不支持的范围被忽略
+//
+// GET /api/v1/oauth/authorize
+func (c *Client) OIDCAuthorize(ctx context.Context, params *OIDCAuthorizeParams, opts ...RequestOption) (*OAuthConsentResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/oauth/authorize", auth: true, envelope: true}
+	params.apply(req)
+	var out OAuthConsentResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OIDCApprove 提交授权决定
+// 授权同意页面提交用户的决定和原始授权请求。同意时签发一次性授权码，拒绝时返回 access_denied 错误，客户端应将浏览器重定向到响应中的 redirect_to
+//
+// POST /api/v1/oauth/authorize
+func (c *Client) OIDCApprove(ctx context.Context, body OAuthConsentRequest, opts ...RequestOption) (*OAuthRedirectResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/oauth/authorize", auth: true, envelope: true}
+	req.body = body
+	var out OAuthRedirectResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OIDCUserInfo 用户信息
+// 返回访问令牌所属用户的标准声明（OpenID Connect Core 第 5.3 节）
+//
+// GET /api/v1/oauth/userinfo
+func (c *Client) OIDCUserInfo(ctx context.Context, opts ...RequestOption) (*OIDCUserInfo, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/oauth/userinfo", auth: true}
+	var out OIDCUserInfo
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HealthReadyz2 Readiness probe
+// Kubernetes readiness probe. Runs every readiness check registered in the health registry (database, cache, event bus, storage, ...) concurrently with per-check timeouts and reports per-dependency status. Returns 503 when a critical dependency is down or the server is shutting down; optional dependencies only degrade the status.
+//
+// GET /api/v1/ready
+func (c *Client) HealthReadyz2(ctx context.Context, opts ...RequestOption) (*HealthReport, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/ready"}
+	var out HealthReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserGetUsersParams UserGetUsers 的查询参数和请求头，零值的参数不会发送
+type UserGetUsersParams struct {
+	Page  int // 页码
+	Limit int // 每页项目数量
+}
+
+// apply 将参数写入请求
+func (p *UserGetUsersParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Page != 0 {
+		r.setQuery("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+}
+
+// UserGetUsers 获取所有用户
+// 获取所有用户列表（仅管理员）。此端点从Redis缓存提供频繁访问的用户数据，TTL为5分钟。如果Redis不可用，数据直接从PostgreSQL数据库提供。缓存状态在响应头中提供。
+//
+// GET /api/v1/users
+func (c *Client) UserGetUsers(ctx context.Context, params *UserGetUsersParams, opts ...RequestOption) (*Page[SafeUser], error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users", auth: true, envelope: true}
+	params.apply(req)
+	var out Page[SafeUser]
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserGetUsersIter 从 params.Page（默认第 1 页）开始逐页调用 UserGetUsers，逐条返回数据，出错时返回错误并结束
+func (c *Client) UserGetUsersIter(ctx context.Context, params *UserGetUsersParams, opts ...RequestOption) iter.Seq2[SafeUser, error] {
+	var p UserGetUsersParams
+	if params != nil {
+		p = *params
+	}
+	return paginate(p.Page, func(page int) (*Page[SafeUser], error) {
+		p.Page = page
+		return c.UserGetUsers(ctx, &p, opts...)
+	})
+}
+
+// ProfileGetProfileParams ProfileGetProfile 的查询参数和请求头，零值的参数不会发送
+type ProfileGetProfileParams struct {
+	IfNoneMatch string // 上次获取时的 ETag
+}
+
+// apply 将参数写入请求
+func (p *ProfileGetProfileParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.IfNoneMatch != "" {
+		r.setHeader("If-None-Match", p.IfNoneMatch)
+	}
+}
+
+// ProfileGetProfile 获取当前用户资料
+// 返回当前登录用户的资料，读取走缓存仓库。响应带有 ETag，携带 If-None-Match 且未变更时返回 304
+//
+// 资源未修改（304）时返回 ErrNotModified。
+//
+// GET /api/v1/users/me
+func (c *Client) ProfileGetProfile(ctx context.Context, params *ProfileGetProfileParams, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/me", auth: true, envelope: true}
+	params.apply(req)
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProfileUpdateProfileParams ProfileUpdateProfile 的查询参数和请求头，零值的参数不会发送
+type ProfileUpdateProfileParams struct {
+	IfMatch string // 获取资料时的 ETag
+}
+
+// apply 将参数写入请求
+func (p *ProfileUpdateProfileParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.IfMatch != "" {
+		r.setHeader("If-Match", p.IfMatch)
+	}
+}
+
+// ProfileUpdateProfile 更新当前用户资料
+// 更新当前登录用户的用户名、姓名和头像URL，更新后相关缓存立即失效。 携带 If-Match 时仅在资料未被其他请求修改过时更新，否则返回 412
+//
+// PUT /api/v1/users/me
+func (c *Client) ProfileUpdateProfile(ctx context.Context, body UpdateUserRequest, params *ProfileUpdateProfileParams, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/users/me", auth: true, envelope: true}
+	req.body = body
+	params.apply(req)
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProfileDeleteAccount 注销当前用户账户
+// 校验当前密码后软删除当前用户，并吊销该用户已签发的全部令牌
+//
+// DELETE /api/v1/users/me
+func (c *Client) ProfileDeleteAccount(ctx context.Context, body DeleteAccountRequest, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/users/me", auth: true, envelope: true}
+	req.body = body
+	return c.do(ctx, req, nil, opts)
+}
+
+// AvatarUploadAvatar 上传当前用户头像
+// 以 multipart/form-data 流式上传头像，文件直接写入对象存储而不缓存在内存或临时文件中。内容类型根据文件头探测，不信任客户端声明的类型。上传成功后旧头像将被删除。返回的头像地址带有内容哈希（v 参数），内容变化时地址随之变化，可以长期缓存；启用签名链接时头像地址为 GET /api/v1/users/{id}/avatar。
+//
+// POST /api/v1/users/me/avatar
+func (c *Client) AvatarUploadAvatar(ctx context.Context, avatarFilename string, avatar io.Reader, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/avatar", auth: true, envelope: true}
+	req.files = append(req.files, file{field: "avatar", filename: avatarFilename, reader: avatar})
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PrivacyExportMyData 导出当前用户的个人数据
+// 返回当前用户个人数据的完整副本：资料、全部站内信和各通知类型在各渠道上的投递偏好。version 为格式版本，字段发生不兼容的变化时递增
+//
+// POST /api/v1/users/me/data-export
+func (c *Client) PrivacyExportMyData(ctx context.Context, opts ...RequestOption) (*PersonalDataArchive, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/data-export", auth: true, envelope: true}
+	var out PersonalDataArchive
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProfileRequestEmailChange 申请变更当前用户邮箱
+// 校验当前密码后向新邮箱发送验证令牌，新邮箱在调用验证接口确认后才生效，令牌有效期 24 小时
+//
+// POST /api/v1/users/me/email
+func (c *Client) ProfileRequestEmailChange(ctx context.Context, body ChangeEmailRequest, opts ...RequestOption) (*EmailChangeResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/email", auth: true, envelope: true}
+	req.body = body
+	var out EmailChangeResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProfileConfirmEmailChange 确认变更当前用户邮箱
+// 使用发送到新邮箱的验证令牌确认邮箱变更，令牌只能使用一次
+//
+// POST /api/v1/users/me/email/verify
+func (c *Client) ProfileConfirmEmailChange(ctx context.Context, body ConfirmEmailChangeRequest, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/email/verify", auth: true, envelope: true}
+	req.body = body
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PrivacyEraseMyAccount 抹除当前用户的账户
+// 校验当前密码后抹除当前用户的个人数据：吊销已签发的全部令牌，删除站内信、通知偏好和头像文件，将用户名、邮箱、姓名等替换为占位值并注销账户。操作不可撤销，原邮箱和用户名可以重新注册
+//
+// POST /api/v1/users/me/erasure
+func (c *Client) PrivacyEraseMyAccount(ctx context.Context, body DeleteAccountRequest, opts ...RequestOption) (*ErasureReport, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/erasure", auth: true, envelope: true}
+	req.body = body
+	var out ErasureReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NotificationGetNotificationPreferences 获取通知偏好
+// 返回当前用户对每种通知类型在每个已启用渠道上是否投递，未设置的使用通知类型的默认渠道
+//
+// GET /api/v1/users/me/notification-preferences
+func (c *Client) NotificationGetNotificationPreferences(ctx context.Context, opts ...RequestOption) ([]NotificationPreference, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/me/notification-preferences", auth: true, envelope: true}
+	var out []NotificationPreference
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// NotificationUpdateNotificationPreferences 修改通知偏好
+// 修改当前用户对指定通知类型和渠道的偏好，未列出的保持不变，返回修改后的全部偏好
+//
+// PUT /api/v1/users/me/notification-preferences
+func (c *Client) NotificationUpdateNotificationPreferences(ctx context.Context, body UpdateNotificationPreferencesRequest, opts ...RequestOption) ([]NotificationPreference, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/users/me/notification-preferences", auth: true, envelope: true}
+	req.body = body
+	var out []NotificationPreference
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// NotificationListNotificationsParams NotificationListNotifications 的查询参数和请求头，零值的参数不会发送
+type NotificationListNotificationsParams struct {
+	Unread *bool // 只返回未读通知
+	Page   int   // 页码
+	Limit  int   // 每页项目数量
+}
+
+// apply 将参数写入请求
+func (p *NotificationListNotificationsParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.Unread != nil {
+		r.setQuery("unread", strconv.FormatBool(*p.Unread))
+	}
+	if p.Page != 0 {
+		r.setQuery("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit != 0 {
+		r.setQuery("limit", strconv.Itoa(p.Limit))
+	}
+}
+
+// NotificationListNotifications 获取通知收件箱
+// 分页返回当前用户的站内信，按创建时间倒序。unread=true 时只返回未读通知；unread_count 为全部未读数，读取走缓存
+//
+// GET /api/v1/users/me/notifications
+func (c *Client) NotificationListNotifications(ctx context.Context, params *NotificationListNotificationsParams, opts ...RequestOption) (*NotificationListResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/me/notifications", auth: true, envelope: true}
+	params.apply(req)
+	var out NotificationListResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NotificationMarkAllNotificationsRead 标记全部通知已读
+// 把当前用户的全部未读通知标记为已读
+//
+// POST /api/v1/users/me/notifications/read
+func (c *Client) NotificationMarkAllNotificationsRead(ctx context.Context, opts ...RequestOption) (*MarkNotificationsReadResponse, error) {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/notifications/read", auth: true, envelope: true}
+	var out MarkNotificationsReadResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NotificationMarkNotificationRead 标记通知已读
+// 把当前用户的一条通知标记为已读，已读的通知保留原来的已读时间
+//
+// POST /api/v1/users/me/notifications/{id}/read
+func (c *Client) NotificationMarkNotificationRead(ctx context.Context, id string, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/notifications/" + url.PathEscape(id) + "/read", auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// ProfileChangePassword 修改当前用户密码
+// 校验当前密码后修改密码，并吊销该用户此前签发的全部令牌，客户端需要重新登录
+//
+// POST /api/v1/users/me/password
+func (c *Client) ProfileChangePassword(ctx context.Context, body ChangePasswordRequest, opts ...RequestOption) error {
+	req := &request{method: http.MethodPost, path: "/api/v1/users/me/password", auth: true, envelope: true}
+	req.body = body
+	return c.do(ctx, req, nil, opts)
+}
+
+// QuotaGetMyQuota 获取我的用量配额
+// 返回当前用户本月（UTC）各项配额的用量、软限制和硬限制，用量在每月 1 日零点重置。查询本身不消耗 api_calls 配额
+//
+// GET /api/v1/users/me/quota
+func (c *Client) QuotaGetMyQuota(ctx context.Context, opts ...RequestOption) ([]Usage, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/me/quota", auth: true, envelope: true}
+	var out []Usage
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// BillingGetMySubscription 获取我的订阅
+// 返回当前用户生效的套餐和订阅状态，没有有效订阅时套餐为默认套餐。查询本身不消耗 api_calls 配额
+//
+// GET /api/v1/users/me/subscription
+func (c *Client) BillingGetMySubscription(ctx context.Context, opts ...RequestOption) (*SubscriptionResponse, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/me/subscription", auth: true, envelope: true}
+	var out SubscriptionResponse
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserGetUser Get user by ID
+// Get a specific user by ID. This endpoint serves frequently accessed user profile data from Redis cache with 5-minute TTL. If Redis is unavailable, data is served directly from PostgreSQL database. Cache status is provided in response headers.
+//
+// GET /api/v1/users/{id}
+func (c *Client) UserGetUser(ctx context.Context, id string, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodGet, path: "/api/v1/users/" + url.PathEscape(id), auth: true, envelope: true}
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserUpdateUserParams UserUpdateUser 的查询参数和请求头，零值的参数不会发送
+type UserUpdateUserParams struct {
+	IfMatch string // ETag returned when the user was fetched
+}
+
+// apply 将参数写入请求
+func (p *UserUpdateUserParams) apply(r *request) {
+	if p == nil {
+		return
+	}
+	if p.IfMatch != "" {
+		r.setHeader("If-Match", p.IfMatch)
+	}
+}
+
+// UserUpdateUser Update user
+// Update a user's information. When If-Match is sent the update only applies if the user has not changed since it was fetched.
+//
+// PUT /api/v1/users/{id}
+func (c *Client) UserUpdateUser(ctx context.Context, id string, body UpdateUserRequest, params *UserUpdateUserParams, opts ...RequestOption) (*SafeUser, error) {
+	req := &request{method: http.MethodPut, path: "/api/v1/users/" + url.PathEscape(id), auth: true, envelope: true}
+	req.body = body
+	params.apply(req)
+	var out SafeUser
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserDeleteUser Delete user
+// Delete a user (admin only or own account)
+//
+// DELETE /api/v1/users/{id}
+func (c *Client) UserDeleteUser(ctx context.Context, id string, opts ...RequestOption) error {
+	req := &request{method: http.MethodDelete, path: "/api/v1/users/" + url.PathEscape(id), auth: true, envelope: true}
+	return c.do(ctx, req, nil, opts)
+}
+
+// HealthHealthz Liveness probe
+// Kubernetes liveness probe. Runs only the liveness checks registered in the health registry (process-level state, never external dependencies), so a failing database does not cause the pod to be restarted. Returns 200 when alive and 503 otherwise.
+//
+// GET /healthz
+func (c *Client) HealthHealthz(ctx context.Context, opts ...RequestOption) (*HealthReport, error) {
+	req := &request{method: http.MethodGet, path: "/healthz"}
+	var out HealthReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MetaOpenAPISpec OpenAPI document
+// Get the OpenAPI 3.1 document of this API. It is generated from the handler annotations by `make openapi` and embedded at build time; the Swagger UI at /swagger/index.html renders it.
+//
+// GET /openapi.json
+func (c *Client) MetaOpenAPISpec(ctx context.Context, opts ...RequestOption) (map[string]any, error) {
+	req := &request{method: http.MethodGet, path: "/openapi.json"}
+	var out map[string]any
+	err := c.do(ctx, req, &out, opts)
+	return out, err
+}
+
+// HealthReadyz Readiness probe
+// Kubernetes readiness probe. Runs every readiness check registered in the health registry (database, cache, event bus, storage, ...) concurrently with per-check timeouts and reports per-dependency status. Returns 503 when a critical dependency is down or the server is shutting down; optional dependencies only degrade the status.
+//
+// GET /readyz
+func (c *Client) HealthReadyz(ctx context.Context, opts ...RequestOption) (*HealthReport, error) {
+	req := &request{method: http.MethodGet, path: "/readyz"}
+	var out HealthReport
+	if err := c.do(ctx, req, &out, opts); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJSON 以服务端的信封格式返回数据
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"success": status < 400, "message": "ok", "data": data})
+}
+
+func TestClient_Envelope(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("ETag", `W/"v1"`)
+		writeJSON(w, http.StatusOK, map[string]any{"id": "u1", "username": "alice", "last_login": nil})
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/", WithToken("t1"))
+	var header http.Header
+	user, err := c.UserGetUser(context.Background(), "a/b", ResponseHeader(&header), WithHeader("X-Test", "1"))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", user.ID)
+	assert.Equal(t, "alice", user.Username)
+	assert.Nil(t, user.LastLogin)
+	assert.Equal(t, `W/"v1"`, header.Get("ETag"))
+
+	assert.Equal(t, "/api/v1/users/a%2Fb", got.URL.EscapedPath(), "path parameters are escaped")
+	assert.Equal(t, "Bearer t1", got.Header.Get("Authorization"))
+	assert.Equal(t, "1", got.Header.Get("X-Test"))
+	assert.Equal(t, "go-server-client/"+APIVersion, got.Header.Get("User-Agent"))
+}
+
+func TestClient_RequestBodyAndParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, `W/"v1"`, r.Header.Get("If-Match"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var body UpdateUserRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		writeJSON(w, http.StatusOK, map[string]any{"id": "u1", "first_name": body.FirstName})
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	user, err := c.ProfileUpdateProfile(context.Background(), UpdateUserRequest{FirstName: "Bob"}, &ProfileUpdateProfileParams{IfMatch: `W/"v1"`})
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", user.FirstName)
+
+	t.Run("零值的查询参数不发送", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "active=false&q=bob", r.URL.RawQuery)
+			writeJSON(w, http.StatusOK, map[string]any{"data": []any{}, "pagination": map[string]any{}})
+		}))
+		defer server.Close()
+
+		_, err := New(server.URL).AdminUserListUsers(context.Background(), &AdminUserListUsersParams{Q: "bob", Active: Ptr(false)})
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_Multipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("avatar")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, "me.png", header.Filename)
+		assert.Equal(t, "png-bytes", string(data))
+		writeJSON(w, http.StatusOK, map[string]any{"id": "u1", "avatar": "https://cdn/avatar.png"})
+	}))
+	defer server.Close()
+
+	user, err := New(server.URL).AvatarUploadAvatar(context.Background(), "me.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn/avatar.png", user.Avatar)
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("信封格式", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-ID", "req-1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"message":"Request failed","correlation_id":"corr-1","error":{"code":"NOT_FOUND","message":"user not found","user_message":"用户不存在","details":{"resource":"user"}}}`)
+		}))
+		defer server.Close()
+
+		_, err := New(server.URL).UserGetUser(context.Background(), "u1")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrCodeNotFound)
+		assert.NotErrorIs(t, err, ErrCodeValidation)
+
+		apiErr, ok := AsAPIError(err)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "user not found", apiErr.Message)
+		assert.Equal(t, "用户不存在", apiErr.UserMessage)
+		assert.Equal(t, "user", apiErr.Details["resource"])
+		assert.Equal(t, "corr-1", apiErr.CorrelationID)
+		assert.Equal(t, "req-1", apiErr.RequestID)
+		assert.False(t, apiErr.Temporary())
+	})
+
+	t.Run("RFC 7807 格式", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"type":"/problems/rate-limit-exceeded","title":"Too Many Requests","status":429,"detail":"请稍后再试","code":"RATE_LIMIT_EXCEEDED","correlation_id":"corr-2"}`)
+		}))
+		defer server.Close()
+
+		_, err := New(server.URL).AuthLogin(context.Background(), LoginRequest{Email: "a@example.com", Password: "x"})
+		apiErr, ok := AsAPIError(err)
+		require.True(t, ok)
+		assert.Equal(t, ErrCodeRateLimitExceeded, apiErr.Code)
+		assert.Equal(t, "请稍后再试", apiErr.Message)
+		assert.Equal(t, "corr-2", apiErr.CorrelationID)
+		assert.True(t, apiErr.Temporary())
+	})
+
+	t.Run("无法解析时按状态码推断", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := New(server.URL).UserDeleteUser(context.Background(), "u1")
+		assert.ErrorIs(t, err, ErrCodeInternal)
+	})
+
+	t.Run("304 返回 ErrNotModified", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, `W/"v1"`, r.Header.Get("If-None-Match"))
+			w.WriteHeader(http.StatusNotModified)
+		}))
+		defer server.Close()
+
+		_, err := New(server.URL).ProfileGetProfile(context.Background(), &ProfileGetProfileParams{IfNoneMatch: `W/"v1"`})
+		assert.ErrorIs(t, err, ErrNotModified)
+	})
+}
+
+func TestClient_Pagination(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"), "other parameters are kept")
+		users := []map[string]any{{"id": fmt.Sprintf("u%d", page*2-1)}, {"id": fmt.Sprintf("u%d", page*2)}}
+		if page == 3 {
+			users = users[:1]
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data":       users,
+			"pagination": map[string]any{"page": page, "limit": 2, "total": 5, "total_pages": 3},
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	var ids []string
+	for user, err := range c.UserGetUsersIter(context.Background(), &UserGetUsersParams{Limit: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, user.ID)
+	}
+	assert.Equal(t, []string{"u1", "u2", "u3", "u4", "u5"}, ids)
+	assert.Equal(t, int32(3), requests.Load())
+
+	t.Run("提前结束时不再请求", func(t *testing.T) {
+		requests.Store(0)
+		for user := range c.UserGetUsersIter(context.Background(), &UserGetUsersParams{Page: 2, Limit: 2}) {
+			assert.Equal(t, "u3", user.ID)
+			break
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+// testJWT 生成只包含 exp 声明的未签名 JWT
+func testJWT(name string, expiresAt time.Time) string {
+	payload, _ := json.Marshal(map[string]any{"sub": name, "exp": expiresAt.Unix()})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	t.Run("服务端返回 401 后重新登录并重试", func(t *testing.T) {
+		var logins atomic.Int32
+		valid := ""
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/auth/login":
+				n := logins.Add(1)
+				valid = testJWT(fmt.Sprintf("login-%d", n), time.Now().Add(time.Hour))
+				writeJSON(w, http.StatusOK, map[string]any{"token": valid})
+			case "/api/v1/auth/me":
+				if r.Header.Get("Authorization") != "Bearer "+valid {
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprint(w, `{"error":{"code":"INVALID_TOKEN","message":"token expired"}}`)
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"id": "u1"})
+			}
+		}))
+		defer server.Close()
+
+		c := New(server.URL)
+		c.SetTokenSource(LoginTokenSource(c, "a@example.com", "secret"))
+		ctx := context.Background()
+
+		_, err := c.AuthMe(ctx)
+		require.NoError(t, err)
+		_, err = c.AuthMe(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), logins.Load(), "cached token is reused")
+
+		// 服务端吊销令牌
+		valid = "revoked"
+		_, err = c.AuthMe(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), logins.Load())
+	})
+
+	t.Run("只重试一次", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		source := NewRefreshingTokenSource(func(context.Context) (string, error) {
+			return "opaque", nil
+		}, 0)
+		_, err := New(server.URL, WithTokenSource(source)).AuthMe(context.Background())
+		assert.ErrorIs(t, err, ErrCodeUnauthorized)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("过期前刷新", func(t *testing.T) {
+		now := time.Now()
+		fetches := 0
+		source := NewRefreshingTokenSource(func(context.Context) (string, error) {
+			fetches++
+			return testJWT("t", now.Add(time.Minute)), nil
+		}, 10*time.Second)
+		source.now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, err := source.Token(ctx)
+		require.NoError(t, err)
+		_, err = source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fetches)
+
+		now = now.Add(55 * time.Second)
+		_, err = source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("获取失败", func(t *testing.T) {
+		source := NewRefreshingTokenSource(func(context.Context) (string, error) {
+			return "", errors.New("boom")
+		}, 0)
+		_, err := New("http://127.0.0.1:0", WithTokenSource(source)).AuthMe(context.Background())
+		assert.ErrorContains(t, err, "boom")
+	})
+}
+
+func TestClient_Text(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/plain", r.Header.Get("Accept"))
+		fmt.Fprint(w, "http_requests_total 1\n")
+	}))
+	defer server.Close()
+
+	text, err := New(server.URL).HealthPrometheus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "http_requests_total 1\n", text)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrNotModified 条件请求（If-None-Match）的资源未修改
+var ErrNotModified = errors.New("client: not modified")
+
+// ErrorCode 服务端的错误代码，与服务端 AppError 的代码一致
+// ErrorCode 实现了 error，可以用 errors.Is(err, client.ErrCodeNotFound) 判断 APIError 的代码
+type ErrorCode string
+
+// Error 返回错误代码
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// APIError 服务端返回的错误响应，同时支持信封格式和 RFC 7807 格式
+type APIError struct {
+	StatusCode    int
+	Code          ErrorCode
+	Message       string
+	UserMessage   string
+	Details       map[string]any
+	CorrelationID string
+	RequestID     string
+}
+
+// Error 返回错误代码、状态码和错误消息
+func (e *APIError) Error() string {
+	return fmt.Sprintf("client: %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// Is 支持 errors.Is(err, client.ErrCodeXxx)
+func (e *APIError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
+
+// Temporary 判断错误是否可以稍后重试（限流、服务不可用、超时）
+func (e *APIError) Temporary() bool {
+	switch e.Code {
+	case ErrCodeRateLimitExceeded, ErrCodeServiceUnavailable, ErrCodeTimeout, ErrCodeMaintenanceMode:
+		return true
+	}
+	return false
+}
+
+// AsAPIError 从错误链中取出 APIError
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// errorBody 信封格式 {"error": {...}} 和 RFC 7807 格式共有的字段
+type errorBody struct {
+	Code          ErrorCode      `json:"code"`
+	Message       string         `json:"message"`
+	UserMessage   string         `json:"user_message"`
+	Details       map[string]any `json:"details"`
+	Detail        string         `json:"detail"` // RFC 7807
+	CorrelationID string         `json:"correlation_id"`
+	Error         *errorBody     `json:"error"`
+}
+
+// parseError 解析错误响应，无法解析时按状态码推断错误代码
+func parseError(resp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode:    resp.StatusCode,
+		Code:          codeForStatus(resp.StatusCode),
+		Message:       http.StatusText(resp.StatusCode),
+		CorrelationID: resp.Header.Get("X-Correlation-ID"),
+		RequestID:     resp.Header.Get("X-Request-ID"),
+	}
+
+	var body errorBody
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) != nil {
+		return apiErr
+	}
+	if body.CorrelationID != "" {
+		apiErr.CorrelationID = body.CorrelationID
+	}
+	detail := body.Detail
+	if body.Error != nil {
+		message := body.Message
+		body = *body.Error
+		if body.Message == "" {
+			body.Message = message
+		}
+	}
+	if body.Code != "" {
+		apiErr.Code = body.Code
+	}
+	switch {
+	case body.Message != "":
+		apiErr.Message = body.Message
+	case detail != "":
+		apiErr.Message = detail
+	}
+	apiErr.UserMessage = body.UserMessage
+	if apiErr.UserMessage == "" {
+		apiErr.UserMessage = detail
+	}
+	apiErr.Details = body.Details
+	return apiErr
+}
+
+// codeForStatus 无法解析错误响应时按状态码推断的错误代码
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeValidation
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusPreconditionFailed:
+		return ErrCodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return ErrCodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return ErrCodeRateLimitExceeded
+	case http.StatusServiceUnavailable:
+		return ErrCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrCodeTimeout
+	}
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrorCode(fmt.Sprintf("HTTP_%d", status))
+}
@@ -0,0 +1,78 @@
+// Package clock 抽象当前时间，测试中用 Fake 推进时间而不必等待
+//
+//	clk := clock.NewFake(time.Now())
+//	cache := cache.NewMemoryCacheWithClock(clk)
+//	cache.Set(ctx, "key", "value", time.Minute)
+//	clk.Advance(time.Minute) // key 已过期
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 提供当前时间
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+}
+
+// Real 系统时钟
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration { return time.Until(t) }
+
+// OrReal 在 c 为 nil 时返回系统时钟，用于可选的时钟配置项
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// Fake 手动推进的时钟，可并发使用
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake 创建停在 now 的时钟
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now 返回时钟当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since 返回从 t 到时钟当前时间经过的时长
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Until 返回从时钟当前时间到 t 的时长
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// Advance 将时钟向前推进 d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set 将时钟设置为 t，可以回拨
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+}
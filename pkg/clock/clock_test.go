@@ -0,0 +1,30 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clk := NewFake(start)
+
+	assert.Equal(t, start, clk.Now())
+
+	clk.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), clk.Now())
+	assert.Equal(t, 90*time.Second, clk.Since(start))
+	assert.Equal(t, -90*time.Second, clk.Until(start))
+
+	clk.Set(start)
+	assert.Equal(t, time.Duration(0), clk.Since(start))
+}
+
+func TestOrReal(t *testing.T) {
+	assert.Equal(t, Real, OrReal(nil))
+
+	clk := NewFake(time.Now())
+	assert.Same(t, clk, OrReal(clk))
+}
@@ -0,0 +1,214 @@
+// Package drain 部署前排空实例
+//
+// 排空依次执行三个步骤：标记未就绪并等待负载均衡器摘除流量、等待处理中的请求完成、停止后台任务。
+// 排空期间监听端口保持打开，部署工具可以轮询进度；排空完成后进程可以随时退出而不丢失请求和任务。
+package drain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 排空状态
+const (
+	StateIdle     = "idle"     // 未开始
+	StateDraining = "draining" // 排空中
+	StateDrained  = "drained"  // 已完成，可以退出进程
+)
+
+// 步骤状态
+const (
+	StepPending = "pending"
+	StepRunning = "running"
+	StepDone    = "done"
+	StepFailed  = "failed" // 出错或超时，排空继续执行后续步骤
+)
+
+// 步骤名称
+const (
+	StepStopAccepting = "stop_accepting"
+	StepRequests      = "in_flight_requests"
+	StepBackground    = "background_jobs"
+)
+
+// 默认值
+const (
+	DefaultTimeout      = 15 * time.Second
+	DefaultPollInterval = 100 * time.Millisecond
+)
+
+// Tracker 统计处理中的请求
+type Tracker struct {
+	inFlight atomic.Int64
+}
+
+// NewTracker 创建请求统计
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Begin 记录一个开始处理的请求，返回的函数在请求结束时调用
+func (t *Tracker) Begin() func() {
+	t.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { t.inFlight.Add(-1) })
+	}
+}
+
+// InFlight 返回处理中的请求数
+func (t *Tracker) InFlight() int64 {
+	return t.inFlight.Load()
+}
+
+// Step 排空步骤的进度
+type Step struct {
+	Name        string     `json:"name" example:"in_flight_requests"` // stop_accepting、in_flight_requests 或 background_jobs
+	Status      string     `json:"status" example:"running"`          // pending、running、done 或 failed
+	StartedAt   *time.Time `json:"started_at,omitempty"`              // 开始时间
+	CompletedAt *time.Time `json:"completed_at,omitempty"`            // 结束时间
+	Error       string     `json:"error,omitempty"`                   // 失败原因
+}
+
+// Status 排空进度
+type Status struct {
+	State       string     `json:"state" example:"draining"` // idle、draining 或 drained
+	StartedAt   *time.Time `json:"started_at,omitempty"`     // 开始时间
+	CompletedAt *time.Time `json:"completed_at,omitempty"`   // 完成时间
+	InFlight    int64      `json:"in_flight" example:"3"`    // 处理中的请求数，不含排空接口本身
+	Steps       []Step     `json:"steps"`                    // 各步骤的进度
+}
+
+// Config 排空配置
+type Config struct {
+	StopAccepting func(ctx context.Context) error // 标记未就绪并等待负载均衡器摘除流量
+	StopWorkers   func(ctx context.Context) error // 停止后台任务、调度器和事件消费者
+	Timeout       time.Duration                   // 等待处理中请求的超时时间，默认 DefaultTimeout
+	PollInterval  time.Duration                   // 检查处理中请求数的间隔，默认 DefaultPollInterval
+	OnDrained     func()                          // 排空完成后调用，如通知进程退出
+}
+
+// Drainer 执行排空并记录进度，每个实例只排空一次
+type Drainer struct {
+	tracker *Tracker
+	config  Config
+
+	mu     sync.Mutex
+	status Status
+}
+
+// New 创建排空器
+func New(tracker *Tracker, cfg Config) *Drainer {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	return &Drainer{
+		tracker: tracker,
+		config:  cfg,
+		status: Status{
+			State: StateIdle,
+			Steps: []Step{
+				{Name: StepStopAccepting, Status: StepPending},
+				{Name: StepRequests, Status: StepPending},
+				{Name: StepBackground, Status: StepPending},
+			},
+		},
+	}
+}
+
+// Start 在后台开始排空，返回当前进度；已经开始过时不重复执行，started 为 false
+func (d *Drainer) Start() (status Status, started bool) {
+	d.mu.Lock()
+	if d.status.State != StateIdle {
+		d.mu.Unlock()
+		return d.Status(), false
+	}
+	now := time.Now().UTC()
+	d.status.State = StateDraining
+	d.status.StartedAt = &now
+	d.mu.Unlock()
+
+	go d.run()
+	return d.Status(), true
+}
+
+// Status 返回排空进度
+func (d *Drainer) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := d.status
+	status.Steps = append([]Step(nil), d.status.Steps...)
+	status.InFlight = d.tracker.InFlight()
+	return status
+}
+
+// run 依次执行各步骤，某个步骤失败时记录原因并继续
+func (d *Drainer) run() {
+	ctx := context.Background()
+	d.step(0, func() error { return call(ctx, d.config.StopAccepting) })
+	d.step(1, func() error { return d.waitRequests(ctx) })
+	d.step(2, func() error { return call(ctx, d.config.StopWorkers) })
+
+	d.mu.Lock()
+	now := time.Now().UTC()
+	d.status.State = StateDrained
+	d.status.CompletedAt = &now
+	d.mu.Unlock()
+
+	if d.config.OnDrained != nil {
+		d.config.OnDrained()
+	}
+}
+
+// step 执行一个步骤并记录进度
+func (d *Drainer) step(index int, fn func() error) {
+	d.mu.Lock()
+	startedAt := time.Now().UTC()
+	d.status.Steps[index].Status = StepRunning
+	d.status.Steps[index].StartedAt = &startedAt
+	d.mu.Unlock()
+
+	err := fn()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	completedAt := time.Now().UTC()
+	d.status.Steps[index].CompletedAt = &completedAt
+	d.status.Steps[index].Status = StepDone
+	if err != nil {
+		d.status.Steps[index].Status = StepFailed
+		d.status.Steps[index].Error = err.Error()
+	}
+}
+
+// waitRequests 等待处理中的请求完成，超时后返回仍在处理的请求数
+func (d *Drainer) waitRequests(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for d.tracker.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s with %d requests in flight", d.config.Timeout, d.tracker.InFlight())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// call 调用可选的步骤函数
+func call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx)
+}
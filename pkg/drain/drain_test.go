@@ -0,0 +1,97 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitDrained 等待排空完成
+func waitDrained(t *testing.T, d *Drainer) Status {
+	t.Helper()
+	require.Eventually(t, func() bool { return d.Status().State == StateDrained }, 2*time.Second, 5*time.Millisecond)
+	return d.Status()
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	first := tracker.Begin()
+	second := tracker.Begin()
+	assert.Equal(t, int64(2), tracker.InFlight())
+
+	first()
+	first()
+	assert.Equal(t, int64(1), tracker.InFlight(), "重复调用结束函数只计一次")
+	second()
+	assert.Zero(t, tracker.InFlight())
+}
+
+func TestDrainer_WaitsForRequests(t *testing.T) {
+	tracker := NewTracker()
+	done := tracker.Begin()
+
+	var order []string
+	drained := make(chan struct{})
+	d := New(tracker, Config{
+		StopAccepting: func(ctx context.Context) error {
+			order = append(order, StepStopAccepting)
+			return nil
+		},
+		StopWorkers: func(ctx context.Context) error {
+			assert.Zero(t, tracker.InFlight(), "请求完成后才停止后台任务")
+			order = append(order, StepBackground)
+			return nil
+		},
+		PollInterval: time.Millisecond,
+		OnDrained:    func() { close(drained) },
+	})
+	assert.Equal(t, StateIdle, d.Status().State)
+
+	status, started := d.Start()
+	require.True(t, started)
+	assert.Equal(t, StateDraining, status.State)
+
+	require.Eventually(t, func() bool { return d.Status().Steps[1].Status == StepRunning }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(1), d.Status().InFlight)
+
+	_, started = d.Start()
+	assert.False(t, started, "排空只执行一次")
+
+	done()
+	<-drained
+	status = waitDrained(t, d)
+	assert.Equal(t, []string{StepStopAccepting, StepBackground}, order)
+	for _, step := range status.Steps {
+		assert.Equal(t, StepDone, step.Status, step.Name)
+	}
+	assert.NotNil(t, status.CompletedAt)
+}
+
+func TestDrainer_ContinuesAfterFailures(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Begin()
+
+	stopped := false
+	d := New(tracker, Config{
+		StopAccepting: func(ctx context.Context) error { return errors.New("readiness failed") },
+		StopWorkers: func(ctx context.Context) error {
+			stopped = true
+			return nil
+		},
+		Timeout:      20 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+	d.Start()
+
+	status := waitDrained(t, d)
+	assert.True(t, stopped)
+	assert.Equal(t, StepFailed, status.Steps[0].Status)
+	assert.Equal(t, "readiness failed", status.Steps[0].Error)
+	assert.Equal(t, StepFailed, status.Steps[1].Status)
+	assert.Contains(t, status.Steps[1].Error, "1 requests in flight")
+	assert.Equal(t, StepDone, status.Steps[2].Status)
+}
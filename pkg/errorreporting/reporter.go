@@ -0,0 +1,181 @@
+package errorreporting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-server/pkg/errors"
+)
+
+// 上报级别
+const (
+	LevelError = "error" // 5xx 错误
+	LevelFatal = "fatal" // 恢复的 panic
+)
+
+// ScrubbedValue 脱敏后替换原值的占位符
+const ScrubbedValue = "[Filtered]"
+
+// Config 错误上报配置
+type Config struct {
+	DSN         string        // Sentry 兼容的 DSN，如 https://<key>@sentry.example.com/<project_id>
+	Environment string        // 运行环境
+	Release     string        // 发布版本
+	ServerName  string        // 服务器名称
+	SampleRate  float64       // 上报采样率，取值 (0, 1]
+	ScrubFields []string      // 需要脱敏的字段名（请求头、查询参数和附加信息），不区分大小写
+	Timeout     time.Duration // 单次上报请求超时时间
+	QueueSize   int           // 待上报事件的缓冲数量，队列满时丢弃新事件
+}
+
+// Report 描述一次需要上报的错误
+type Report struct {
+	Level         string                 // 上报级别
+	Type          string                 // 错误类型，如错误代码或 panic 值的类型
+	Message       string                 // 错误消息
+	StackTrace    string                 // runtime/debug.Stack 格式的堆栈跟踪
+	CorrelationID string                 // 关联ID
+	UserID        string                 // 用户ID
+	Request       *http.Request          // 触发错误的请求
+	Tags          map[string]string      // 可检索的标签
+	Extra         map[string]interface{} // 附加信息
+}
+
+// Reporter 错误上报接口
+type Reporter interface {
+	// Capture 异步上报错误，不阻塞调用方
+	Capture(report *Report)
+
+	// Flush 等待已入队的事件上报完成或 ctx 结束
+	Flush(ctx context.Context) error
+}
+
+// New 根据配置创建错误上报器，DSN 为空时返回空操作上报器
+func New(cfg Config) (Reporter, error) {
+	if cfg.DSN == "" {
+		return NewNoopReporter(), nil
+	}
+	return NewSentryReporter(cfg)
+}
+
+// noopReporter 空操作上报器，错误上报未启用时使用
+type noopReporter struct{}
+
+// NewNoopReporter 创建空操作上报器
+func NewNoopReporter() Reporter {
+	return noopReporter{}
+}
+
+func (noopReporter) Capture(report *Report)          {}
+func (noopReporter) Flush(ctx context.Context) error { return nil }
+
+// FromAppError 根据应用错误创建上报内容，包含错误代码、关联ID、用户ID和堆栈跟踪
+func FromAppError(appErr *errors.AppError) *Report {
+	report := &Report{
+		Level:         LevelError,
+		Type:          string(appErr.Code),
+		Message:       appErr.Message,
+		StackTrace:    appErr.StackTrace,
+		CorrelationID: appErr.CorrelationID,
+		Tags: map[string]string{
+			"error_code": string(appErr.Code),
+		},
+		Extra: make(map[string]interface{}),
+	}
+
+	if appErr.Cause != nil {
+		report.Message = fmt.Sprintf("%s: %v", appErr.Message, appErr.Cause)
+	}
+	if appErr.Category != "" {
+		report.Tags["category"] = appErr.Category
+	}
+	if appErr.Severity != "" {
+		report.Tags["severity"] = appErr.Severity
+	}
+	for key, value := range appErr.Details {
+		report.Extra[key] = value
+	}
+	if appErr.Context != nil {
+		report.UserID = appErr.Context.UserID
+		if appErr.Context.Operation != "" {
+			report.Tags["operation"] = appErr.Context.Operation
+		}
+	}
+
+	return report
+}
+
+// FromPanic 根据恢复的 panic 值和堆栈创建上报内容
+func FromPanic(recovered interface{}, stack []byte) *Report {
+	message := fmt.Sprint(recovered)
+	if err, ok := recovered.(error); ok {
+		message = err.Error()
+	}
+
+	return &Report{
+		Level:      LevelFatal,
+		Type:       fmt.Sprintf("panic(%T)", recovered),
+		Message:    message,
+		StackTrace: string(stack),
+		Tags:       map[string]string{"panic": "true"},
+		Extra:      make(map[string]interface{}),
+	}
+}
+
+// scrubber 按字段名脱敏上报内容
+type scrubber struct {
+	fields map[string]bool
+}
+
+// newScrubber 创建脱敏器，字段名不区分大小写
+func newScrubber(fields []string) *scrubber {
+	s := &scrubber{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			s.fields[field] = true
+		}
+	}
+	return s
+}
+
+// matches 判断字段是否需要脱敏
+func (s *scrubber) matches(name string) bool {
+	return s.fields[strings.ToLower(name)]
+}
+
+// scrubValue 递归脱敏附加信息中的 map 值
+func (s *scrubber) scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		scrubbed := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if s.matches(key) {
+				scrubbed[key] = ScrubbedValue
+				continue
+			}
+			scrubbed[key] = s.scrubValue(child)
+		}
+		return scrubbed
+	case []interface{}:
+		scrubbed := make([]interface{}, len(v))
+		for i, child := range v {
+			scrubbed[i] = s.scrubValue(child)
+		}
+		return scrubbed
+	default:
+		return value
+	}
+}
+
+// scrubMap 脱敏字符串键值对，如请求头和查询参数
+func (s *scrubber) scrubMap(values map[string]string) map[string]string {
+	for key := range values {
+		if s.matches(key) {
+			values[key] = ScrubbedValue
+		}
+	}
+	return values
+}
@@ -0,0 +1,356 @@
+package errorreporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sentryClientName 上报时使用的客户端标识
+const sentryClientName = "go-server-errorreporting/1.0"
+
+// dsn 解析后的 Sentry DSN
+type dsn struct {
+	storeURL  string
+	publicKey string
+	secretKey string
+}
+
+// parseDSN 解析 {scheme}://{public_key}[:{secret_key}]@{host}[/{path}]/{project_id} 形式的 DSN
+func parseDSN(raw string) (*dsn, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid DSN scheme: %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("DSN is missing the public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx < 0 || path[idx+1:] == "" {
+		return nil, fmt.Errorf("DSN is missing the project ID")
+	}
+	prefix, projectID := path[:idx], path[idx+1:]
+
+	secretKey, _ := u.User.Password()
+	return &dsn{
+		storeURL:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, projectID),
+		publicKey: u.User.Username(),
+		secretKey: secretKey,
+	}, nil
+}
+
+// authHeader 返回 X-Sentry-Auth 请求头
+func (d *dsn) authHeader() string {
+	header := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClientName, d.publicKey)
+	if d.secretKey != "" {
+		header += ", sentry_secret=" + d.secretKey
+	}
+	return header
+}
+
+// sentryEvent Sentry store API 的事件格式
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	User        *sentryUser            `json:"user,omitempty"`
+	Request     *sentryRequest         `json:"request,omitempty"`
+	Exception   *sentryExceptions      `json:"exception,omitempty"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+type sentryRequest struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	QueryString string            `json:"query_string,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// queueItem 上报队列中的事件，flushed 非空时表示刷新标记
+type queueItem struct {
+	event   *sentryEvent
+	flushed chan struct{}
+}
+
+// SentryReporter 将错误上报到 Sentry 兼容服务（Sentry、GlitchTip 等）的 store API
+//
+// 事件在调用方协程中构建并脱敏，由后台协程发送：队列满时丢弃新事件，发送失败不重试，
+// 避免错误上报服务不可用时影响请求处理
+type SentryReporter struct {
+	dsn      *dsn
+	cfg      Config
+	client   *http.Client
+	scrubber *scrubber
+	queue    chan queueItem
+}
+
+// NewSentryReporter 根据配置创建 Sentry 兼容的错误上报器，并启动后台发送协程
+func NewSentryReporter(cfg Config) (*SentryReporter, error) {
+	parsed, err := parseDSN(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be in (0, 1], got %v", cfg.SampleRate)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	r := &SentryReporter{
+		dsn:      parsed,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		scrubber: newScrubber(cfg.ScrubFields),
+		queue:    make(chan queueItem, cfg.QueueSize),
+	}
+	go r.run()
+
+	return r, nil
+}
+
+// Capture 实现 Reporter 接口，按采样率决定是否上报
+func (r *SentryReporter) Capture(report *Report) {
+	if report == nil || (r.cfg.SampleRate < 1 && rand.Float64() >= r.cfg.SampleRate) {
+		return
+	}
+
+	select {
+	case r.queue <- queueItem{event: r.buildEvent(report)}:
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: error reporting queue is full, dropped event %q\n", report.Type)
+	}
+}
+
+// Flush 实现 Reporter 接口
+func (r *SentryReporter) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case r.queue <- queueItem{flushed: flushed}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 后台发送循环
+func (r *SentryReporter) run() {
+	for item := range r.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if err := r.send(item.event); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to report error event %s: %v\n", item.event.EventID, err)
+		}
+	}
+}
+
+// send 发送一个事件
+func (r *SentryReporter) send(event *sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.dsn.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.dsn.authHeader())
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// buildEvent 将上报内容转换为脱敏后的 Sentry 事件
+func (r *SentryReporter) buildEvent(report *Report) *sentryEvent {
+	event := &sentryEvent{
+		EventID:     strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       report.Level,
+		Platform:    "go",
+		Message:     report.Message,
+		Environment: r.cfg.Environment,
+		Release:     r.cfg.Release,
+		ServerName:  r.cfg.ServerName,
+		Tags:        make(map[string]string, len(report.Tags)+1),
+		Exception: &sentryExceptions{Values: []sentryException{{
+			Type:       report.Type,
+			Value:      report.Message,
+			Stacktrace: parseStack(report.StackTrace),
+		}}},
+	}
+	if event.Level == "" {
+		event.Level = LevelError
+	}
+
+	for key, value := range report.Tags {
+		event.Tags[key] = value
+	}
+	if report.CorrelationID != "" {
+		event.Tags["correlation_id"] = report.CorrelationID
+	}
+	if len(report.Extra) > 0 {
+		event.Extra = r.scrubber.scrubValue(report.Extra).(map[string]interface{})
+	}
+	if report.UserID != "" {
+		event.User = &sentryUser{ID: report.UserID}
+	}
+	if report.Request != nil {
+		event.Request = r.buildRequest(report.Request)
+	}
+
+	return event
+}
+
+// buildRequest 提取脱敏后的请求信息，不包含请求体
+func (r *SentryReporter) buildRequest(req *http.Request) *sentryRequest {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for key := range req.Header {
+		headers[key] = req.Header.Get(key)
+	}
+
+	query := req.URL.Query()
+	for key := range query {
+		if r.scrubber.matches(key) {
+			query.Set(key, ScrubbedValue)
+		}
+	}
+
+	return &sentryRequest{
+		URL:         fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path),
+		Method:      req.Method,
+		QueryString: query.Encode(),
+		Headers:     r.scrubber.scrubMap(headers),
+	}
+}
+
+// parseStack 将 runtime/debug.Stack 格式的堆栈解析为调用帧，按 Sentry 要求由外到内排列
+func parseStack(stack string) *sentryStacktrace {
+	if stack == "" {
+		return nil
+	}
+
+	var frames []sentryFrame
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		function, location := lines[i], lines[i+1]
+		if strings.HasPrefix(function, "\t") || !strings.HasPrefix(location, "\t") {
+			continue
+		}
+		i++
+
+		// 去掉参数和创建者信息，如 main.handler(0x1, ...) 和 created by main.run in goroutine 1
+		function = strings.TrimPrefix(function, "created by ")
+		if idx := strings.Index(function, " in goroutine "); idx >= 0 {
+			function = function[:idx]
+		}
+		if strings.HasSuffix(function, ")") {
+			if idx := strings.LastIndex(function, "("); idx > 0 {
+				function = function[:idx]
+			}
+		}
+
+		location = strings.TrimSpace(location)
+		if idx := strings.LastIndex(location, " +0x"); idx >= 0 {
+			location = location[:idx]
+		}
+		path, line := location, 0
+		if idx := strings.LastIndex(location, ":"); idx >= 0 {
+			path = location[:idx]
+			line, _ = strconv.Atoi(location[idx+1:])
+		}
+
+		// 跳过堆栈采集本身的调用帧
+		if function == "runtime/debug.Stack" {
+			continue
+		}
+
+		frames = append(frames, sentryFrame{
+			Function: function,
+			AbsPath:  path,
+			Lineno:   line,
+			InApp:    !strings.HasPrefix(function, "runtime.") && !strings.Contains(path, "/pkg/mod/"),
+		})
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &sentryStacktrace{Frames: frames}
+}
@@ -0,0 +1,70 @@
+package errors
+
+import "strings"
+
+// ErrorCodeInfo 错误代码的机器可读说明
+type ErrorCodeInfo struct {
+	Code        ErrorCode `json:"code" example:"VALIDATION_ERROR"`                         // 错误代码
+	Title       string    `json:"title" example:"Validation error"`                        // 简短标题
+	Description string    `json:"description" example:"The request data is invalid."`      // 错误说明
+	HTTPStatus  int       `json:"http_status" example:"400"`                               // 对应的HTTP状态码
+	Retryable   bool      `json:"retryable" example:"false"`                               // 客户端是否可以原样重试
+	DocsURL     string    `json:"docs_url,omitempty" example:"/problems/validation-error"` // 文档链接，同时作为 RFC 7807 的问题类型URI
+}
+
+// errorCatalog 所有预定义错误代码的说明，新增错误代码时需要同步添加
+var errorCatalog = []ErrorCodeInfo{
+	{Code: ErrCodeValidation, Title: "Validation error", Description: "The request data is malformed or fails validation; see details for the offending fields."},
+	{Code: ErrCodeNotFound, Title: "Not found", Description: "The requested resource does not exist."},
+	{Code: ErrCodeUnauthorized, Title: "Unauthorized", Description: "Authentication is missing or failed."},
+	{Code: ErrCodeForbidden, Title: "Forbidden", Description: "The caller is authenticated but lacks permission for this resource."},
+	{Code: ErrCodeConflict, Title: "Conflict", Description: "The request conflicts with the current state of the resource, such as a duplicate email or username."},
+	{Code: ErrCodeRateLimitExceeded, Title: "Rate limit exceeded", Description: "Too many requests; retry after the interval given in the Retry-After header.", Retryable: true},
+	{Code: ErrCodeInternal, Title: "Internal error", Description: "An unexpected server error occurred; report the correlation ID when contacting support."},
+	{Code: ErrCodeDatabase, Title: "Database error", Description: "A database operation failed.", Retryable: true},
+	{Code: ErrCodeCache, Title: "Cache error", Description: "A cache operation failed.", Retryable: true},
+	{Code: ErrCodeServiceUnavailable, Title: "Service unavailable", Description: "A required service is temporarily unavailable.", Retryable: true},
+	{Code: ErrCodeTimeout, Title: "Timeout", Description: "The operation did not complete in time.", Retryable: true},
+	{Code: ErrCodeInvalidToken, Title: "Invalid token", Description: "The access or refresh token is malformed, expired or has an invalid signature."},
+	{Code: ErrCodeTokenBlacklisted, Title: "Token revoked", Description: "The token has been revoked, for example by logout; sign in again."},
+	{Code: ErrCodeBusinessLogic, Title: "Business rule violation", Description: "The request is well-formed but violates a business rule."},
+	{Code: ErrCodeQuotaExceeded, Title: "Quota exceeded", Description: "The resource quota is used up; retry after the reset time given in details.", Retryable: true},
+	{Code: ErrCodeMaintenance, Title: "Maintenance", Description: "The service is under maintenance; retry after the estimated downtime.", Retryable: true},
+	{Code: ErrCodeThirdPartyService, Title: "Third-party service error", Description: "An external service returned an error.", Retryable: true},
+	{Code: ErrCodeConfiguration, Title: "Configuration error", Description: "The server is misconfigured."},
+	{Code: ErrCodeDependency, Title: "Dependency error", Description: "A dependent service or component is unhealthy.", Retryable: true},
+	{Code: ErrCodeSecurity, Title: "Security error", Description: "The request was rejected by a security check."},
+	{Code: ErrCodeDataIntegrity, Title: "Data integrity error", Description: "The operation would violate a data integrity constraint."},
+	{Code: ErrCodePayloadTooLarge, Title: "Payload too large", Description: "The request body exceeds the allowed size; see details for the limit."},
+	{Code: ErrCodePreconditionFailed, Title: "Precondition failed", Description: "The If-Match header does not match the current ETag of the resource; fetch it again and retry."},
+	{Code: ErrCodeEntitlementRequired, Title: "Entitlement required", Description: "The current subscription plan does not include this feature; see details for the missing entitlement and upgrade the plan."},
+}
+
+// Slug 返回错误代码的小写连字符形式，如 VALIDATION_ERROR 对应 validation-error
+func (c ErrorCode) Slug() string {
+	return strings.ReplaceAll(strings.ToLower(string(c)), "_", "-")
+}
+
+// Catalog 返回所有预定义错误代码的说明，docsBaseURL 非空时拼接错误代码的 Slug 作为文档链接
+func Catalog(docsBaseURL string) []ErrorCodeInfo {
+	catalog := make([]ErrorCodeInfo, len(errorCatalog))
+	for i, info := range errorCatalog {
+		info.HTTPStatus = getStatusCode(info.Code)
+		if docsBaseURL != "" {
+			info.DocsURL = docsBaseURL + info.Code.Slug()
+		}
+		catalog[i] = info
+	}
+	return catalog
+}
+
+// LookupErrorCode 查找错误代码的说明
+func LookupErrorCode(code ErrorCode) (ErrorCodeInfo, bool) {
+	for _, info := range errorCatalog {
+		if info.Code == code {
+			info.HTTPStatus = getStatusCode(code)
+			return info, true
+		}
+	}
+	return ErrorCodeInfo{}, false
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogCoversAllErrorCodes(t *testing.T) {
+	catalog := Catalog("")
+
+	seen := make(map[ErrorCode]bool, len(catalog))
+	for _, info := range catalog {
+		assert.False(t, seen[info.Code], "duplicate catalog entry for %s", info.Code)
+		seen[info.Code] = true
+
+		assert.NotEmpty(t, info.Title, info.Code)
+		assert.NotEmpty(t, info.Description, info.Code)
+		assert.Equal(t, getStatusCode(info.Code), info.HTTPStatus, info.Code)
+		assert.Empty(t, info.DocsURL)
+	}
+
+	// 每个有状态码映射的错误代码都必须出现在目录中
+	for code := range statusCodeMapping {
+		assert.True(t, seen[code], "error code %s is missing from the catalog", code)
+	}
+	assert.Len(t, catalog, len(statusCodeMapping))
+}
+
+func TestCatalogDocsURL(t *testing.T) {
+	catalog := Catalog("https://docs.example.com/errors/")
+	require.NotEmpty(t, catalog)
+	assert.Equal(t, "https://docs.example.com/errors/validation-error", catalog[0].DocsURL)
+}
+
+func TestLookupErrorCode(t *testing.T) {
+	info, ok := LookupErrorCode(ErrCodeRateLimitExceeded)
+	require.True(t, ok)
+	assert.Equal(t, 429, info.HTTPStatus)
+	assert.True(t, info.Retryable)
+
+	_, ok = LookupErrorCode("UNKNOWN_CODE")
+	assert.False(t, ok)
+}
+
+func TestErrorCodeSlug(t *testing.T) {
+	assert.Equal(t, "third-party-service-error", ErrCodeThirdPartyService.Slug())
+	assert.Equal(t, "timeout", ErrCodeTimeout.Slug())
+}
@@ -2,10 +2,13 @@ package errors
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"go-server/pkg/i18n"
+
 	"github.com/google/uuid"
 )
 
@@ -76,6 +79,15 @@ const (
 
 	// ErrCodeDataIntegrity 数据完整性错误 - 数据完整性校验失败
 	ErrCodeDataIntegrity ErrorCode = "DATA_INTEGRITY_ERROR"
+
+	// ErrCodePayloadTooLarge 请求体过大 - 请求体超过允许的大小
+	ErrCodePayloadTooLarge ErrorCode = "PAYLOAD_TOO_LARGE"
+
+	// ErrCodePreconditionFailed 前置条件失败 - If-Match 等条件请求头与资源当前版本不匹配
+	ErrCodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+
+	// ErrCodeEntitlementRequired 需要升级套餐 - 当前订阅套餐不包含请求的功能
+	ErrCodeEntitlementRequired ErrorCode = "ENTITLEMENT_REQUIRED"
 )
 
 // ErrorDetails 错误详细信息结构
@@ -225,27 +237,30 @@ func (e *AppError) IsServerError() bool {
 
 // HTTP状态码映射表
 var statusCodeMapping = map[ErrorCode]int{
-	ErrCodeValidation:         http.StatusBadRequest,
-	ErrCodeNotFound:           http.StatusNotFound,
-	ErrCodeUnauthorized:       http.StatusUnauthorized,
-	ErrCodeForbidden:          http.StatusForbidden,
-	ErrCodeConflict:           http.StatusConflict,
-	ErrCodeRateLimitExceeded:  http.StatusTooManyRequests,
-	ErrCodeQuotaExceeded:      http.StatusTooManyRequests,
-	ErrCodeInternal:           http.StatusInternalServerError,
-	ErrCodeDatabase:           http.StatusInternalServerError,
-	ErrCodeCache:              http.StatusInternalServerError,
-	ErrCodeServiceUnavailable: http.StatusServiceUnavailable,
-	ErrCodeTimeout:            http.StatusRequestTimeout,
-	ErrCodeInvalidToken:       http.StatusUnauthorized,
-	ErrCodeTokenBlacklisted:   http.StatusUnauthorized,
-	ErrCodeBusinessLogic:      http.StatusBadRequest,
-	ErrCodeMaintenance:        http.StatusServiceUnavailable,
-	ErrCodeThirdPartyService:  http.StatusBadGateway,
-	ErrCodeConfiguration:      http.StatusInternalServerError,
-	ErrCodeDependency:         http.StatusServiceUnavailable,
-	ErrCodeSecurity:           http.StatusForbidden,
-	ErrCodeDataIntegrity:      http.StatusConflict,
+	ErrCodeValidation:          http.StatusBadRequest,
+	ErrCodeNotFound:            http.StatusNotFound,
+	ErrCodeUnauthorized:        http.StatusUnauthorized,
+	ErrCodeForbidden:           http.StatusForbidden,
+	ErrCodeConflict:            http.StatusConflict,
+	ErrCodeRateLimitExceeded:   http.StatusTooManyRequests,
+	ErrCodeQuotaExceeded:       http.StatusTooManyRequests,
+	ErrCodeInternal:            http.StatusInternalServerError,
+	ErrCodeDatabase:            http.StatusInternalServerError,
+	ErrCodeCache:               http.StatusInternalServerError,
+	ErrCodeServiceUnavailable:  http.StatusServiceUnavailable,
+	ErrCodeTimeout:             http.StatusRequestTimeout,
+	ErrCodeInvalidToken:        http.StatusUnauthorized,
+	ErrCodeTokenBlacklisted:    http.StatusUnauthorized,
+	ErrCodeBusinessLogic:       http.StatusBadRequest,
+	ErrCodeMaintenance:         http.StatusServiceUnavailable,
+	ErrCodeThirdPartyService:   http.StatusBadGateway,
+	ErrCodeConfiguration:       http.StatusInternalServerError,
+	ErrCodeDependency:          http.StatusServiceUnavailable,
+	ErrCodeSecurity:            http.StatusForbidden,
+	ErrCodeDataIntegrity:       http.StatusConflict,
+	ErrCodePayloadTooLarge:     http.StatusRequestEntityTooLarge,
+	ErrCodePreconditionFailed:  http.StatusPreconditionFailed,
+	ErrCodeEntitlementRequired: http.StatusForbidden,
 }
 
 // getStatusCode 根据错误代码获取对应的HTTP状态码
@@ -342,6 +357,34 @@ func NewDatabaseError(message string, cause error) *AppError {
 	return err
 }
 
+// NewTransientDatabaseError 创建暂时性数据库错误，返回 503，客户端可以在 retryAfter 后原样重试
+func NewTransientDatabaseError(message string, cause error, retryAfter time.Duration) *AppError {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	err := NewDatabaseError(message, cause).
+		WithDetail("retry_after", seconds).
+		WithRetryable(true)
+	err.StatusCode = http.StatusServiceUnavailable
+	return err
+}
+
+// TransientError 由可以稍后重试的暂时性错误实现，例如重试用尽的数据库序列化失败
+type TransientError interface {
+	error
+	RetryAfter() time.Duration // 建议客户端等待的时间
+}
+
+// RetryAfterHint 返回错误链中暂时性错误建议的等待时间，错误不是暂时性错误时返回 false
+func RetryAfterHint(err error) (time.Duration, bool) {
+	var transient TransientError
+	if stderrors.As(err, &transient) {
+		return transient.RetryAfter(), true
+	}
+	return 0, false
+}
+
 // NewCacheError 创建缓存错误
 func NewCacheError(message string, cause error) *AppError {
 	err := NewAppError(ErrCodeCache, message)
@@ -466,6 +509,32 @@ func NewDataIntegrityError(entityType string, entityID string, constraint string
 		WithRetryable(false)
 }
 
+// NewPayloadTooLargeError 创建请求体过大错误
+func NewPayloadTooLargeError(limitBytes int64) *AppError {
+	message := fmt.Sprintf("Request body exceeds the limit of %d bytes", limitBytes)
+	return NewAppError(ErrCodePayloadTooLarge, message).
+		WithDetail("limit_bytes", limitBytes).
+		WithRetryable(false)
+}
+
+// NewPreconditionFailedError 创建前置条件失败错误，currentETag 为资源当前的实体标签
+func NewPreconditionFailedError(currentETag string) *AppError {
+	return NewAppError(ErrCodePreconditionFailed, "The resource has been modified since it was last fetched").
+		WithDetail("current_etag", currentETag).
+		WithUserMessage("The resource was changed by someone else. Reload it and try again.").
+		WithRetryable(false)
+}
+
+// NewEntitlementRequiredError 创建需要升级套餐错误，entitlement 为缺少的权益，plan 为当前套餐
+func NewEntitlementRequiredError(entitlement, plan string) *AppError {
+	message := fmt.Sprintf("The current plan does not include '%s'", entitlement)
+	return NewAppError(ErrCodeEntitlementRequired, message).
+		WithDetail("entitlement", entitlement).
+		WithDetail("plan", plan).
+		WithUserMessage("Your subscription plan does not include this feature. Upgrade your plan to use it.").
+		WithRetryable(false)
+}
+
 // WrapError 包装现有错误为应用程序错误
 func WrapError(err error, code ErrorCode, message string) *AppError {
 	if err == nil {
@@ -552,17 +621,21 @@ func (e *AppError) AddInternationalizedMessages(messages map[string]string) *App
 	return e
 }
 
+// WithMessageKey 使用内置消息目录中 key 对应的各语言消息作为国际化错误消息，{0}、{1} 依次替换为 args
+func (e *AppError) WithMessageKey(key string, args ...string) *AppError {
+	return e.AddInternationalizedMessages(i18n.Default().Messages(key, args...))
+}
+
 // GetLocalizedMessage 获取本地化错误消息
+// 按回退链选择消息，如 zh-CN 依次尝试 zh-cn、zh，最后使用英文
 func (e *AppError) GetLocalizedMessage(languageCode string) string {
 	if e.Details != nil {
 		if i18nMessages, ok := e.Details["i18n_messages"]; ok {
 			if messages, ok := i18nMessages.(map[string]string); ok {
-				if msg, exists := messages[languageCode]; exists {
-					return msg
-				}
-				// 如果没有找到指定语言，尝试返回英文
-				if msg, exists := messages["en"]; exists {
-					return msg
+				for _, locale := range i18n.Fallbacks(languageCode, i18n.DefaultLocale) {
+					if msg, exists := messages[locale]; exists {
+						return msg
+					}
 				}
 			}
 		}
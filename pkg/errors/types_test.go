@@ -723,6 +723,24 @@ func TestInternationalizedMessages(t *testing.T) {
 	assert.Equal(t, "Please check your input", err3.GetLocalizedMessage("fr")) // Falls back to English
 }
 
+func TestLocalizedMessageFallbackChain(t *testing.T) {
+	err := NewValidationError("Validation failed").AddInternationalizedMessages(map[string]string{
+		"en": "Please check your input",
+		"zh": "请检查您的输入",
+	})
+
+	// Regional variants fall back to the primary language before English
+	assert.Equal(t, "请检查您的输入", err.GetLocalizedMessage("zh-CN"))
+	assert.Equal(t, "请检查您的输入", err.GetLocalizedMessage("zh_Hant_TW"))
+	assert.Equal(t, "Please check your input", err.GetLocalizedMessage("en-GB"))
+}
+
+func TestWithMessageKey(t *testing.T) {
+	err := NewUnauthorizedError("Invalid credentials").WithMessageKey("auth.invalid_credentials")
+	assert.Equal(t, "邮箱或密码错误", err.GetLocalizedMessage("zh"))
+	assert.Equal(t, "Invalid credentials", err.GetLocalizedMessage("fr"))
+}
+
 func TestCreateBusinessValidationError(t *testing.T) {
 	fieldErrors := []ErrorDetails{
 		{
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 事件驱动类型
+const (
+	DriverNATS   = "nats"   // NATS 驱动
+	DriverKafka  = "kafka"  // Kafka 驱动
+	DriverMemory = "memory" // 进程内驱动（开发和测试使用）
+	DriverNoop   = "noop"   // 空操作驱动（事件总线禁用时使用）
+)
+
+// ErrClosed 在事件总线关闭后继续发布或订阅时返回
+var ErrClosed = errors.New("events: bus is closed")
+
+// Event 表示一条与传输无关的领域事件
+type Event struct {
+	ID        string            `json:"id"`                 // 事件唯一ID
+	Type      string            `json:"type"`               // 事件类型，如 user.created
+	Source    string            `json:"source"`             // 事件来源服务
+	Timestamp time.Time         `json:"timestamp"`          // 事件发生时间
+	Data      json.RawMessage   `json:"data"`               // 事件负载（JSON）
+	Metadata  map[string]string `json:"metadata,omitempty"` // 附加元数据，如关联ID
+}
+
+// NewEvent 创建新事件，payload 会被序列化为 JSON
+func NewEvent(eventType string, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	return &Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+		Metadata:  make(map[string]string),
+	}, nil
+}
+
+// Decode 将事件负载反序列化到目标对象
+func (e *Event) Decode(target interface{}) error {
+	return json.Unmarshal(e.Data, target)
+}
+
+// encodeEvent 序列化事件用于传输
+func encodeEvent(event *Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// decodeEvent 反序列化传输中的事件
+func decodeEvent(data []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return &event, nil
+}
+
+// Handler 处理订阅到的事件，返回错误时由驱动决定是否重试
+type Handler func(ctx context.Context, event *Event) error
+
+// Publisher 定义事件发布接口，领域服务只依赖该接口
+type Publisher interface {
+	// Publish 将事件发布到指定主题
+	Publish(ctx context.Context, topic string, event *Event) error
+}
+
+// Subscription 表示一个活动的订阅
+type Subscription interface {
+	// Topic 返回订阅的主题
+	Topic() string
+
+	// Unsubscribe 取消订阅
+	Unsubscribe() error
+}
+
+// Subscriber 定义事件订阅接口
+type Subscriber interface {
+	// Subscribe 订阅指定主题，事件到达时调用 handler
+	Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error)
+}
+
+// Bus 组合发布、订阅及生命周期管理
+type Bus interface {
+	Publisher
+	Subscriber
+
+	// Driver 返回驱动名称
+	Driver() string
+
+	// Health 检查与消息中间件的连接状态
+	Health(ctx context.Context) error
+
+	// Drain 停止接收新消息，等待处理中的消息完成并刷新待发送消息后关闭连接
+	Drain(ctx context.Context) error
+
+	// Close 立即关闭连接
+	Close() error
+}
+
+// Config 事件总线配置
+type Config struct {
+	Driver string      // 驱动类型：nats、kafka、memory、noop
+	Source string      // 事件来源标识，发布时自动填充
+	NATS   NATSConfig  // NATS 配置
+	Kafka  KafkaConfig // Kafka 配置
+}
+
+// New 根据配置创建事件总线
+func New(cfg Config) (Bus, error) {
+	var (
+		bus Bus
+		err error
+	)
+
+	switch cfg.Driver {
+	case DriverNATS:
+		bus, err = NewNATSBus(cfg.NATS)
+	case DriverKafka:
+		bus, err = NewKafkaBus(cfg.Kafka)
+	case DriverMemory:
+		bus = NewMemoryBus()
+	case DriverNoop, "":
+		bus = NewNoopBus()
+	default:
+		return nil, fmt.Errorf("unsupported events driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Source != "" {
+		bus = &sourceBus{Bus: bus, source: cfg.Source}
+	}
+
+	return bus, nil
+}
+
+// sourceBus 在发布时自动填充事件来源
+type sourceBus struct {
+	Bus
+	source string
+}
+
+// Publish 填充来源后发布事件
+func (b *sourceBus) Publish(ctx context.Context, topic string, event *Event) error {
+	if event != nil && event.Source == "" {
+		event.Source = b.source
+	}
+	return b.Bus.Publish(ctx, topic, event)
+}
+
+// PublishEvent 创建并发布事件的便捷函数，topic 与事件类型相同
+func PublishEvent(ctx context.Context, publisher Publisher, eventType string, payload interface{}) error {
+	if publisher == nil {
+		return nil
+	}
+
+	event, err := NewEvent(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	if correlationID, ok := ctx.Value("correlation_id").(string); ok && correlationID != "" {
+		event.Metadata["correlation_id"] = correlationID
+	}
+
+	return publisher.Publish(ctx, eventType, event)
+}
@@ -0,0 +1,216 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("user.created", testPayload{UserID: "u-1", Email: "a@example.com"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "user.created", event.Type)
+	assert.False(t, event.Timestamp.IsZero())
+
+	var decoded testPayload
+	require.NoError(t, event.Decode(&decoded))
+	assert.Equal(t, "u-1", decoded.UserID)
+	assert.Equal(t, "a@example.com", decoded.Email)
+}
+
+func TestEncodeDecodeEvent(t *testing.T) {
+	event, err := NewEvent("user.created", testPayload{UserID: "u-2"})
+	require.NoError(t, err)
+	event.Metadata["correlation_id"] = "corr-1"
+
+	data, err := encodeEvent(event)
+	require.NoError(t, err)
+
+	decoded, err := decodeEvent(data)
+	require.NoError(t, err)
+	assert.Equal(t, event.ID, decoded.ID)
+	assert.Equal(t, "corr-1", decoded.Metadata["correlation_id"])
+
+	_, err = decodeEvent([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNew_Drivers(t *testing.T) {
+	bus, err := New(Config{Driver: DriverMemory})
+	require.NoError(t, err)
+	assert.Equal(t, DriverMemory, bus.Driver())
+
+	bus, err = New(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, DriverNoop, bus.Driver())
+
+	_, err = New(Config{Driver: "carrier-pigeon"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Driver: DriverKafka})
+	assert.Error(t, err, "kafka driver requires brokers")
+}
+
+func TestMemoryBus_PublishSubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		received []*Event
+		wg       sync.WaitGroup
+	)
+	wg.Add(1)
+
+	_, err := bus.Subscribe(ctx, "user.created", func(ctx context.Context, event *Event) error {
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+		wg.Done()
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, PublishEvent(ctx, bus, "user.created", testPayload{UserID: "u-3"}))
+	require.NoError(t, PublishEvent(ctx, bus, "user.deleted", testPayload{UserID: "u-3"}))
+
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "user.created", received[0].Type)
+}
+
+func TestMemoryBus_Unsubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	called := make(chan struct{}, 1)
+	sub, err := bus.Subscribe(ctx, "topic", func(ctx context.Context, event *Event) error {
+		called <- struct{}{}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "topic", sub.Topic())
+	require.NoError(t, sub.Unsubscribe())
+
+	require.NoError(t, PublishEvent(ctx, bus, "topic", nil))
+	require.NoError(t, bus.Drain(ctx))
+
+	select {
+	case <-called:
+		t.Fatal("handler should not be called after unsubscribe")
+	default:
+	}
+}
+
+func TestMemoryBus_DrainWaitsForHandlers(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	var finished bool
+	var mu sync.Mutex
+	_, err := bus.Subscribe(ctx, "slow", func(ctx context.Context, event *Event) error {
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		finished = true
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, PublishEvent(ctx, bus, "slow", nil))
+	require.NoError(t, bus.Drain(ctx))
+
+	mu.Lock()
+	assert.True(t, finished, "drain should wait for in-flight handlers")
+	mu.Unlock()
+
+	assert.ErrorIs(t, PublishEvent(ctx, bus, "slow", nil), ErrClosed)
+	assert.ErrorIs(t, bus.Health(ctx), ErrClosed)
+}
+
+func TestMemoryBus_DrainTimeout(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	_, err := bus.Subscribe(ctx, "blocked", func(ctx context.Context, event *Event) error {
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, PublishEvent(ctx, bus, "blocked", nil))
+
+	drainCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, bus.Drain(drainCtx), context.DeadlineExceeded)
+
+	close(release)
+}
+
+func TestSourceBus_FillsSourceAndCorrelationID(t *testing.T) {
+	bus, err := New(Config{Driver: DriverMemory, Source: "go-server"})
+	require.NoError(t, err)
+
+	received := make(chan *Event, 1)
+	_, err = bus.Subscribe(context.Background(), "user.created", func(ctx context.Context, event *Event) error {
+		received <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), "correlation_id", "corr-42")
+	require.NoError(t, PublishEvent(ctx, bus, "user.created", testPayload{UserID: "u-4"}))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "go-server", event.Source)
+		assert.Equal(t, "corr-42", event.Metadata["correlation_id"])
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestPublishEvent_NilPublisher(t *testing.T) {
+	assert.NoError(t, PublishEvent(context.Background(), nil, "user.created", nil))
+}
+
+func TestNoopBus(t *testing.T) {
+	bus := NewNoopBus()
+	ctx := context.Background()
+
+	sub, err := bus.Subscribe(ctx, "topic", func(ctx context.Context, event *Event) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, "topic", sub.Topic())
+	assert.NoError(t, bus.Publish(ctx, "topic", &Event{}))
+	assert.NoError(t, bus.Health(ctx))
+	assert.NoError(t, bus.Drain(ctx))
+}
+
+func TestKafkaFetchBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{5, 1600 * time.Millisecond},
+		{10, 30 * time.Second},
+		{100, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, kafkaFetchBackoff(tt.failures), "failures=%d", tt.failures)
+	}
+}
@@ -0,0 +1,293 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 拉取消息连续失败时的等待时间，每次失败翻倍，避免 Broker 不可用时空转
+const (
+	kafkaFetchInitialBackoff = 100 * time.Millisecond
+	kafkaFetchMaxBackoff     = 30 * time.Second
+)
+
+// KafkaConfig Kafka 连接配置
+type KafkaConfig struct {
+	Brokers      []string      // Broker 地址列表
+	ClientID     string        // 客户端ID
+	GroupID      string        // 消费者组ID
+	TopicPrefix  string        // 主题前缀，如 "app."
+	BatchTimeout time.Duration // 批量发送等待时间
+	DialTimeout  time.Duration // 连接超时时间
+}
+
+// KafkaBus 基于 Kafka 的事件总线
+type KafkaBus struct {
+	config  KafkaConfig
+	writer  *kafka.Writer
+	dialer  *kafka.Dialer
+	mu      sync.Mutex
+	readers map[*kafkaSubscription]struct{}
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// kafkaSubscription Kafka 订阅
+type kafkaSubscription struct {
+	bus    *KafkaBus
+	topic  string
+	reader *kafka.Reader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKafkaBus 创建 Kafka 事件总线
+func NewKafkaBus(cfg KafkaConfig) (*KafkaBus, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka brokers are required")
+	}
+	if cfg.GroupID == "" {
+		cfg.GroupID = cfg.ClientID
+	}
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = 10 * time.Millisecond
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+
+	dialer := &kafka.Dialer{
+		ClientID: cfg.ClientID,
+		Timeout:  cfg.DialTimeout,
+	}
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           cfg.BatchTimeout,
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		Transport: &kafka.Transport{
+			ClientID:    cfg.ClientID,
+			DialTimeout: cfg.DialTimeout,
+		},
+	}
+
+	return &KafkaBus{
+		config:  cfg,
+		writer:  writer,
+		dialer:  dialer,
+		readers: make(map[*kafkaSubscription]struct{}),
+	}, nil
+}
+
+// Driver 返回驱动名称
+func (b *KafkaBus) Driver() string {
+	return DriverKafka
+}
+
+// topicName 返回带前缀的主题名
+func (b *KafkaBus) topicName(topic string) string {
+	return b.config.TopicPrefix + topic
+}
+
+// Publish 将事件写入 Kafka，事件ID作为消息键
+func (b *KafkaBus) Publish(ctx context.Context, topic string, event *Event) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	data, err := encodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Topic: b.topicName(topic),
+		Key:   []byte(event.ID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	}
+
+	if err := b.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish event to Kafka: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 以消费者组方式订阅 Kafka 主题，处理成功后提交偏移量
+func (b *KafkaBus) Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: b.config.Brokers,
+		GroupID: b.config.GroupID,
+		Topic:   b.topicName(topic),
+		Dialer:  b.dialer,
+	})
+
+	consumeCtx, cancel := context.WithCancel(context.Background())
+	sub := &kafkaSubscription{
+		bus:    b,
+		topic:  topic,
+		reader: reader,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	b.readers[sub] = struct{}{}
+
+	b.wg.Add(1)
+	go sub.consume(consumeCtx, handler)
+
+	return sub, nil
+}
+
+// consume 循环拉取消息并调用处理器
+func (s *kafkaSubscription) consume(ctx context.Context, handler Handler) {
+	defer s.bus.wg.Done()
+	defer close(s.done)
+
+	failures := 0
+	for {
+		msg, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			wait := kafkaFetchBackoff(failures)
+			log.Printf("events: failed to fetch Kafka message from %s, retrying in %s: %v", s.topic, wait, err)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		failures = 0
+
+		event, err := decodeEvent(msg.Value)
+		if err != nil {
+			log.Printf("events: dropping malformed Kafka message on %s: %v", s.topic, err)
+		} else if err := handler(ctx, event); err != nil {
+			// 处理失败不提交偏移量，但消费者会继续拉取后续消息，不会立即重试；
+			// 只有在重新平衡或重启后从已提交的偏移量重新消费时才会再次投递，
+			// 之后的消息提交成功后偏移量越过该消息，该消息不再投递
+			log.Printf("events: Kafka handler for topic %s failed: %v", s.topic, err)
+			continue
+		}
+
+		if err := s.reader.CommitMessages(context.Background(), msg); err != nil {
+			log.Printf("events: failed to commit Kafka offset on %s: %v", s.topic, err)
+		}
+	}
+}
+
+// kafkaFetchBackoff 返回连续第 n 次（从 1 开始）拉取失败后的等待时间
+func kafkaFetchBackoff(n int) time.Duration {
+	backoff := kafkaFetchInitialBackoff
+	for i := 1; i < n && backoff < kafkaFetchMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, kafkaFetchMaxBackoff)
+}
+
+// Health 检查能否连接到任一 Broker
+func (b *KafkaBus) Health(ctx context.Context) error {
+	var lastErr error
+	for _, broker := range b.config.Brokers {
+		conn, err := b.dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no Kafka broker reachable: %w", lastErr)
+}
+
+// Drain 停止所有消费者，等待处理中的消息完成，并刷新待发送消息
+func (b *KafkaBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := make([]*kafkaSubscription, 0, len(b.readers))
+	for sub := range b.readers {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	var drainErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		drainErr = ctx.Err()
+	}
+
+	for _, sub := range subs {
+		sub.reader.Close()
+	}
+
+	// Writer.Close 会等待缓冲中的消息发送完成
+	if err := b.writer.Close(); err != nil && drainErr == nil {
+		drainErr = fmt.Errorf("failed to flush Kafka writer: %w", err)
+	}
+
+	return drainErr
+}
+
+// Close 立即关闭所有消费者和生产者
+func (b *KafkaBus) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Drain(ctx)
+	return nil
+}
+
+// Topic 返回订阅的主题
+func (s *kafkaSubscription) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe 停止消费并关闭读取器
+func (s *kafkaSubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	delete(s.bus.readers, s)
+	s.bus.mu.Unlock()
+
+	s.cancel()
+	<-s.done
+	return s.reader.Close()
+}
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// MemoryBus 进程内事件总线，事件异步分发给同一进程内的订阅者
+type MemoryBus struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]*memorySubscription
+	wg            sync.WaitGroup
+	closed        bool
+}
+
+// memorySubscription 进程内订阅
+type memorySubscription struct {
+	bus     *MemoryBus
+	topic   string
+	handler Handler
+}
+
+// NewMemoryBus 创建进程内事件总线
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subscriptions: make(map[string][]*memorySubscription),
+	}
+}
+
+// Driver 返回驱动名称
+func (b *MemoryBus) Driver() string {
+	return DriverMemory
+}
+
+// Publish 将事件异步分发给所有订阅者
+func (b *MemoryBus) Publish(ctx context.Context, topic string, event *Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	for _, sub := range b.subscriptions[topic] {
+		b.wg.Add(1)
+		go func(s *memorySubscription) {
+			defer b.wg.Done()
+			if err := s.handler(context.Background(), event); err != nil {
+				log.Printf("events: memory handler for topic %s failed: %v", s.topic, err)
+			}
+		}(sub)
+	}
+
+	return nil
+}
+
+// Subscribe 订阅指定主题
+func (b *MemoryBus) Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &memorySubscription{bus: b, topic: topic, handler: handler}
+	b.subscriptions[topic] = append(b.subscriptions[topic], sub)
+	return sub, nil
+}
+
+// Health 进程内总线始终健康，关闭后返回错误
+func (b *MemoryBus) Health(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Drain 停止接收新事件并等待处理中的事件完成
+func (b *MemoryBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 立即关闭事件总线
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.subscriptions = make(map[string][]*memorySubscription)
+	return nil
+}
+
+// Topic 返回订阅的主题
+func (s *memorySubscription) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe 取消订阅
+func (s *memorySubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	subs := s.bus.subscriptions[s.topic]
+	for i, sub := range subs {
+		if sub == s {
+			s.bus.subscriptions[s.topic] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// NoopBus 空操作事件总线，丢弃所有事件
+type NoopBus struct{}
+
+// NewNoopBus 创建空操作事件总线
+func NewNoopBus() *NoopBus {
+	return &NoopBus{}
+}
+
+// Driver 返回驱动名称
+func (b *NoopBus) Driver() string { return DriverNoop }
+
+// Publish 丢弃事件
+func (b *NoopBus) Publish(ctx context.Context, topic string, event *Event) error { return nil }
+
+// Subscribe 返回不会收到任何事件的订阅
+func (b *NoopBus) Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error) {
+	return noopSubscription(topic), nil
+}
+
+// Health 始终健康
+func (b *NoopBus) Health(ctx context.Context) error { return nil }
+
+// Drain 无需处理
+func (b *NoopBus) Drain(ctx context.Context) error { return nil }
+
+// Close 无需处理
+func (b *NoopBus) Close() error { return nil }
+
+// noopSubscription 空操作订阅
+type noopSubscription string
+
+func (s noopSubscription) Topic() string      { return string(s) }
+func (s noopSubscription) Unsubscribe() error { return nil }
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig NATS 连接配置
+type NATSConfig struct {
+	URL            string        // 服务器地址，多个地址以逗号分隔
+	Name           string        // 客户端连接名称
+	QueueGroup     string        // 订阅使用的队列组，为空时每个实例都会收到事件
+	ConnectTimeout time.Duration // 连接超时时间
+	MaxReconnects  int           // 最大重连次数，-1 表示无限重连
+	ReconnectWait  time.Duration // 重连等待时间
+	DrainTimeout   time.Duration // Drain 超时时间
+}
+
+// NATSBus 基于 NATS 的事件总线
+type NATSBus struct {
+	conn   *nats.Conn
+	config NATSConfig
+	closed chan struct{}
+	once   sync.Once
+}
+
+// natsSubscription NATS 订阅
+type natsSubscription struct {
+	sub *nats.Subscription
+}
+
+// NewNATSBus 连接 NATS 并创建事件总线
+func NewNATSBus(cfg NATSConfig) (*NATSBus, error) {
+	if cfg.URL == "" {
+		cfg.URL = nats.DefaultURL
+	}
+	if cfg.ConnectTimeout <= 0 {
+		cfg.ConnectTimeout = 5 * time.Second
+	}
+	if cfg.ReconnectWait <= 0 {
+		cfg.ReconnectWait = 2 * time.Second
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 30 * time.Second
+	}
+
+	bus := &NATSBus{
+		config: cfg,
+		closed: make(chan struct{}),
+	}
+
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name(cfg.Name),
+		nats.Timeout(cfg.ConnectTimeout),
+		nats.MaxReconnects(cfg.MaxReconnects),
+		nats.ReconnectWait(cfg.ReconnectWait),
+		nats.DrainTimeout(cfg.DrainTimeout),
+		nats.ClosedHandler(func(_ *nats.Conn) {
+			bus.once.Do(func() { close(bus.closed) })
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("events: NATS disconnected: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	bus.conn = conn
+	return bus, nil
+}
+
+// Driver 返回驱动名称
+func (b *NATSBus) Driver() string {
+	return DriverNATS
+}
+
+// Publish 将事件发布到 NATS 主题
+func (b *NATSBus) Publish(ctx context.Context, topic string, event *Event) error {
+	if b.conn.IsClosed() || b.conn.IsDraining() {
+		return ErrClosed
+	}
+
+	data, err := encodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	if err := b.conn.Publish(topic, data); err != nil {
+		return fmt.Errorf("failed to publish event to NATS: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅 NATS 主题，配置了队列组时使用队列订阅实现负载均衡
+func (b *NATSBus) Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error) {
+	callback := func(msg *nats.Msg) {
+		event, err := decodeEvent(msg.Data)
+		if err != nil {
+			log.Printf("events: dropping malformed NATS message on %s: %v", msg.Subject, err)
+			return
+		}
+		if err := handler(context.Background(), event); err != nil {
+			log.Printf("events: NATS handler for topic %s failed: %v", msg.Subject, err)
+		}
+	}
+
+	var (
+		sub *nats.Subscription
+		err error
+	)
+	if b.config.QueueGroup != "" {
+		sub, err = b.conn.QueueSubscribe(topic, b.config.QueueGroup, callback)
+	} else {
+		sub, err = b.conn.Subscribe(topic, callback)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to NATS topic %s: %w", topic, err)
+	}
+
+	return &natsSubscription{sub: sub}, nil
+}
+
+// Health 检查 NATS 连接状态
+func (b *NATSBus) Health(ctx context.Context) error {
+	if !b.conn.IsConnected() {
+		return fmt.Errorf("NATS connection status: %v", b.conn.Status())
+	}
+	return nil
+}
+
+// Drain 排空订阅和待发送消息后关闭连接
+func (b *NATSBus) Drain(ctx context.Context) error {
+	if b.conn.IsClosed() {
+		return nil
+	}
+
+	if err := b.conn.Drain(); err != nil {
+		return fmt.Errorf("failed to drain NATS connection: %w", err)
+	}
+
+	select {
+	case <-b.closed:
+		return nil
+	case <-ctx.Done():
+		b.conn.Close()
+		return ctx.Err()
+	}
+}
+
+// Close 立即关闭 NATS 连接
+func (b *NATSBus) Close() error {
+	b.conn.Close()
+	return nil
+}
+
+// Topic 返回订阅的主题
+func (s *natsSubscription) Topic() string {
+	return s.sub.Subject
+}
+
+// Unsubscribe 取消订阅
+func (s *natsSubscription) Unsubscribe() error {
+	return s.sub.Unsubscribe()
+}
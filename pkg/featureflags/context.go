@@ -0,0 +1,74 @@
+package featureflags
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// Set 一次请求中所有开关的评估结果
+// 由中间件创建，第一次读取时才评估，使认证中间件在其后写入的用户ID也能参与评估
+type Set struct {
+	once     sync.Once
+	evaluate func() map[string]bool
+	values   map[string]bool
+}
+
+// NewSet 使用已评估的结果创建开关集合
+func NewSet(values map[string]bool) *Set {
+	set := &Set{values: values}
+	set.once.Do(func() {})
+	return set
+}
+
+// NewLazySet 创建第一次读取时才评估的开关集合
+func NewLazySet(evaluate func() map[string]bool) *Set {
+	return &Set{evaluate: evaluate}
+}
+
+// Enabled 判断开关是否开启，未知开关视为关闭
+func (s *Set) Enabled(key string) bool {
+	if s == nil {
+		return false
+	}
+	return s.resolve()[key]
+}
+
+// All 返回所有开关的评估结果
+func (s *Set) All() map[string]bool {
+	if s == nil {
+		return map[string]bool{}
+	}
+	values := s.resolve()
+	copied := make(map[string]bool, len(values))
+	for key, enabled := range values {
+		copied[key] = enabled
+	}
+	return copied
+}
+
+// resolve 返回评估结果，只评估一次
+func (s *Set) resolve() map[string]bool {
+	s.once.Do(func() {
+		if s.evaluate != nil {
+			s.values = s.evaluate()
+		}
+	})
+	return s.values
+}
+
+// WithFlags 将开关集合写入上下文
+func WithFlags(ctx context.Context, set *Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, set)
+}
+
+// Flags 返回上下文中的开关集合，未经过中间件时返回空集合，所有开关视为关闭
+func Flags(ctx context.Context) *Set {
+	if ctx != nil {
+		if set, ok := ctx.Value(contextKey{}).(*Set); ok && set != nil {
+			return set
+		}
+	}
+	return NewSet(nil)
+}
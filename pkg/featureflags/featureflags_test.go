@@ -0,0 +1,181 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlag_Evaluate(t *testing.T) {
+	t.Run("关闭的开关对所有用户关闭", func(t *testing.T) {
+		flag := &Flag{Key: "beta", Enabled: false, Percentage: 100, Users: []string{"u1"}}
+		assert.False(t, flag.Evaluate("u1"))
+		assert.False(t, flag.Evaluate(""))
+	})
+
+	t.Run("全量开关对匿名请求也开启", func(t *testing.T) {
+		flag := &Flag{Key: "beta", Enabled: true, Percentage: 100}
+		assert.True(t, flag.Evaluate(""))
+		assert.True(t, flag.Evaluate("u1"))
+	})
+
+	t.Run("定向用户始终开启", func(t *testing.T) {
+		flag := &Flag{Key: "beta", Enabled: true, Users: []string{"u1"}}
+		assert.True(t, flag.Evaluate("u1"))
+		assert.False(t, flag.Evaluate("u2"))
+		assert.False(t, flag.Evaluate(""))
+	})
+
+	t.Run("按比例分桶且结果稳定", func(t *testing.T) {
+		flag := &Flag{Key: "beta", Enabled: true, Percentage: 30}
+		enabled := 0
+		for i := 0; i < 1000; i++ {
+			userID := fmt.Sprintf("user-%d", i)
+			result := flag.Evaluate(userID)
+			assert.Equal(t, result, flag.Evaluate(userID))
+			if result {
+				enabled++
+			}
+		}
+		assert.InDelta(t, 300, enabled, 60)
+	})
+}
+
+func TestFlag_Validate(t *testing.T) {
+	assert.NoError(t, (&Flag{Key: "new_dashboard.v2", Percentage: 50}).Validate())
+	assert.ErrorIs(t, (&Flag{Key: "New Dashboard"}).Validate(), ErrInvalidFlag)
+	assert.ErrorIs(t, (&Flag{Key: "beta", Percentage: 101}).Validate(), ErrInvalidFlag)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(&Flag{Key: "b"}, &Flag{Key: "a"})
+
+	flags, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, "a", flags[0].Key)
+
+	assert.ErrorIs(t, store.Create(ctx, &Flag{Key: "a"}), ErrExists)
+	assert.ErrorIs(t, store.Update(ctx, &Flag{Key: "missing"}), ErrNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, "missing"), ErrNotFound)
+
+	require.NoError(t, store.Update(ctx, &Flag{Key: "a", Enabled: true, Users: []string{"u1"}}))
+	flag, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+
+	// 返回的是副本，修改不影响存储
+	flag.Users[0] = "changed"
+	flag, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, flag.Users)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// countingStore 统计 List 调用次数的存储
+type countingStore struct {
+	*MemoryStore
+	lists atomic.Int32
+	err   error
+}
+
+func (s *countingStore) List(ctx context.Context) ([]*Flag, error) {
+	s.lists.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemoryStore.List(ctx)
+}
+
+func TestManager_CachesAndInvalidates(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{MemoryStore: NewMemoryStore(&Flag{Key: "beta", Enabled: true, Percentage: 100})}
+	manager := NewManager(store, time.Minute)
+
+	assert.True(t, manager.IsEnabled(ctx, "beta", ""))
+	assert.True(t, manager.IsEnabled(ctx, "beta", "u1"))
+	assert.False(t, manager.IsEnabled(ctx, "missing", "u1"))
+	assert.Equal(t, int32(1), store.lists.Load())
+
+	// 写操作使缓存失效
+	require.NoError(t, manager.Update(ctx, &Flag{Key: "beta", Enabled: false}))
+	assert.False(t, manager.IsEnabled(ctx, "beta", "u1"))
+	assert.Equal(t, int32(2), store.lists.Load())
+
+	// 读取失败时沿用旧的定义
+	require.NoError(t, manager.Create(ctx, &Flag{Key: "gamma", Enabled: true, Percentage: 100}))
+	store.err = errors.New("unavailable")
+	assert.Equal(t, map[string]bool{"beta": false}, manager.Evaluate(ctx, "u1"))
+
+	assert.ErrorIs(t, manager.Create(ctx, &Flag{Key: "Bad Key"}), ErrInvalidFlag)
+}
+
+func TestFlags_Context(t *testing.T) {
+	t.Run("未经过中间件时所有开关关闭", func(t *testing.T) {
+		assert.False(t, Flags(context.Background()).Enabled("beta"))
+		assert.Empty(t, Flags(context.Background()).All())
+	})
+
+	t.Run("延迟评估只执行一次", func(t *testing.T) {
+		var calls atomic.Int32
+		set := NewLazySet(func() map[string]bool {
+			calls.Add(1)
+			return map[string]bool{"beta": true}
+		})
+		ctx := WithFlags(context.Background(), set)
+		assert.Equal(t, int32(0), calls.Load())
+
+		assert.True(t, Flags(ctx).Enabled("beta"))
+		assert.False(t, Flags(ctx).Enabled("gamma"))
+		assert.Equal(t, map[string]bool{"beta": true}, Flags(ctx).All())
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestRedisStore_PublishesChanges(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test:featureflags:%d", time.Now().UnixNano())
+	defer client.Del(context.Background(), prefix+":flags")
+
+	store := NewRedisStore(client, prefix)
+	require.NoError(t, store.Seed(ctx, &Flag{Key: "beta", Enabled: true}))
+	require.NoError(t, store.Seed(ctx, &Flag{Key: "beta", Enabled: false}))
+
+	flag, err := store.Get(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled, "Seed 不覆盖已存在的开关")
+
+	manager := NewManager(store, time.Hour)
+	manager.Start(ctx)
+	defer manager.Close(context.Background())
+	assert.True(t, manager.IsEnabled(ctx, "beta", "u1") == flag.Evaluate("u1"))
+
+	// 其他实例的修改通过发布订阅使缓存失效
+	other := NewRedisStore(client, prefix)
+	require.Eventually(t, func() bool {
+		_ = other.Update(ctx, &Flag{Key: "beta", Enabled: true, Percentage: 100})
+		return manager.IsEnabled(ctx, "beta", "")
+	}, 3*time.Second, 50*time.Millisecond)
+
+	assert.ErrorIs(t, store.Create(ctx, &Flag{Key: "beta"}), ErrExists)
+	require.NoError(t, store.Delete(ctx, "beta"))
+	assert.ErrorIs(t, store.Delete(ctx, "beta"), ErrNotFound)
+}
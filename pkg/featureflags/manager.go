@@ -0,0 +1,175 @@
+package featureflags
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Manager 开关管理器，在本地缓存开关定义，写操作和其他实例的变更通知都会使缓存失效
+type Manager struct {
+	store    Store
+	cacheTTL time.Duration
+
+	mu       sync.RWMutex
+	snapshot map[string]*Flag
+	loadedAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager 创建开关管理器，cacheTTL 为本地缓存的有效期，<=0 时每次评估都读取存储
+func NewManager(store Store, cacheTTL time.Duration) *Manager {
+	return &Manager{
+		store:    store,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Start 存储支持 Watcher 时在后台监听变更通知
+func (m *Manager) Start(ctx context.Context) {
+	watcher, ok := m.store.(Watcher)
+	if !ok || m.cancel != nil {
+		return
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		for {
+			err := watcher.Watch(ctx, func(string) {
+				m.Invalidate()
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("featureflags: watcher stopped, retrying: %v", err)
+			}
+			// 断线期间的变更可能丢失，重新订阅前使缓存失效
+			m.Invalidate()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+}
+
+// Close 停止监听变更通知
+func (m *Manager) Close(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Invalidate 使本地缓存过期，下次评估时重新读取存储
+func (m *Manager) Invalidate() {
+	m.mu.Lock()
+	m.loadedAt = time.Time{}
+	m.mu.Unlock()
+}
+
+// IsEnabled 判断开关对用户是否开启，开关不存在或读取失败时视为关闭
+func (m *Manager) IsEnabled(ctx context.Context, key, userID string) bool {
+	flag, ok := m.load(ctx)[key]
+	return ok && flag.Evaluate(userID)
+}
+
+// Evaluate 计算所有开关对用户的结果
+func (m *Manager) Evaluate(ctx context.Context, userID string) map[string]bool {
+	flags := m.load(ctx)
+	result := make(map[string]bool, len(flags))
+	for key, flag := range flags {
+		result[key] = flag.Evaluate(userID)
+	}
+	return result
+}
+
+// List 返回所有开关
+func (m *Manager) List(ctx context.Context) ([]*Flag, error) {
+	return m.store.List(ctx)
+}
+
+// Get 返回指定开关
+func (m *Manager) Get(ctx context.Context, key string) (*Flag, error) {
+	return m.store.Get(ctx, key)
+}
+
+// Create 校验并创建开关
+func (m *Manager) Create(ctx context.Context, flag *Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+	flag.UpdatedAt = time.Now().UTC()
+	if err := m.store.Create(ctx, flag); err != nil {
+		return err
+	}
+	m.Invalidate()
+	return nil
+}
+
+// Update 校验并替换开关定义
+func (m *Manager) Update(ctx context.Context, flag *Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+	flag.UpdatedAt = time.Now().UTC()
+	if err := m.store.Update(ctx, flag); err != nil {
+		return err
+	}
+	m.Invalidate()
+	return nil
+}
+
+// Delete 删除开关
+func (m *Manager) Delete(ctx context.Context, key string) error {
+	if err := m.store.Delete(ctx, key); err != nil {
+		return err
+	}
+	m.Invalidate()
+	return nil
+}
+
+// load 返回缓存的开关定义，缓存失效时从存储读取；读取失败时沿用旧的定义
+func (m *Manager) load(ctx context.Context) map[string]*Flag {
+	m.mu.RLock()
+	snapshot, loadedAt := m.snapshot, m.loadedAt
+	m.mu.RUnlock()
+	if snapshot != nil && time.Since(loadedAt) < m.cacheTTL {
+		return snapshot
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.snapshot != nil && time.Since(m.loadedAt) < m.cacheTTL {
+		return m.snapshot
+	}
+
+	flags, err := m.store.List(ctx)
+	if err != nil {
+		log.Printf("featureflags: failed to load flags: %v", err)
+		if m.snapshot == nil {
+			return map[string]*Flag{}
+		}
+		return m.snapshot
+	}
+
+	snapshot = make(map[string]*Flag, len(flags))
+	for _, flag := range flags {
+		snapshot[flag.Key] = flag
+	}
+	m.snapshot, m.loadedAt = snapshot, time.Now()
+	return snapshot
+}
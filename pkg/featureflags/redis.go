@@ -0,0 +1,167 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 将开关保存在 Redis 哈希中，修改后通过发布订阅通知所有实例
+//
+// 键布局：
+//
+//	<prefix>:flags    哈希，字段为开关键，值为 JSON 格式的开关定义
+//	<prefix>:changes  发布订阅频道，消息为被修改的开关键
+type RedisStore struct {
+	client  *redis.Client
+	hashKey string
+	channel string
+}
+
+// NewRedisStore 创建 Redis 存储，prefix 为键前缀，如 featureflags
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client:  client,
+		hashKey: prefix + ":flags",
+		channel: prefix + ":changes",
+	}
+}
+
+// Seed 写入配置中的开关，已存在的开关保持不变，使管理员的修改在重启后仍然有效
+func (s *RedisStore) Seed(ctx context.Context, flags ...*Flag) error {
+	for _, flag := range flags {
+		data, err := json.Marshal(flag)
+		if err != nil {
+			return err
+		}
+		created, err := s.client.HSetNX(ctx, s.hashKey, flag.Key, data).Result()
+		if err != nil {
+			return fmt.Errorf("failed to seed feature flag %s: %w", flag.Key, err)
+		}
+		if created {
+			s.notify(ctx, flag.Key)
+		}
+	}
+	return nil
+}
+
+// List 返回所有开关
+func (s *RedisStore) List(ctx context.Context) ([]*Flag, error) {
+	values, err := s.client.HGetAll(ctx, s.hashKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+
+	flags := make([]*Flag, 0, len(values))
+	for key, value := range values {
+		flag, err := decodeFlag(key, value)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	sortFlags(flags)
+	return flags, nil
+}
+
+// Get 返回指定开关
+func (s *RedisStore) Get(ctx context.Context, key string) (*Flag, error) {
+	value, err := s.client.HGet(ctx, s.hashKey, key).Result()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature flag %s: %w", key, err)
+	}
+	return decodeFlag(key, value)
+}
+
+// Create 创建开关
+func (s *RedisStore) Create(ctx context.Context, flag *Flag) error {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	created, err := s.client.HSetNX(ctx, s.hashKey, flag.Key, data).Result()
+	if err != nil {
+		return fmt.Errorf("failed to create feature flag %s: %w", flag.Key, err)
+	}
+	if !created {
+		return ErrExists
+	}
+	s.notify(ctx, flag.Key)
+	return nil
+}
+
+// Update 替换开关定义
+func (s *RedisStore) Update(ctx context.Context, flag *Flag) error {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	exists, err := s.client.HExists(ctx, s.hashKey, flag.Key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update feature flag %s: %w", flag.Key, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	if err := s.client.HSet(ctx, s.hashKey, flag.Key, data).Err(); err != nil {
+		return fmt.Errorf("failed to update feature flag %s: %w", flag.Key, err)
+	}
+	s.notify(ctx, flag.Key)
+	return nil
+}
+
+// Delete 删除开关
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	deleted, err := s.client.HDel(ctx, s.hashKey, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag %s: %w", key, err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	s.notify(ctx, key)
+	return nil
+}
+
+// Watch 订阅变更频道，收到消息时调用 onChange
+func (s *RedisStore) Watch(ctx context.Context, onChange func(key string)) error {
+	pubsub := s.client.Subscribe(ctx, s.channel)
+	defer pubsub.Close()
+
+	// 等待订阅确认，确保返回前已开始接收消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", s.channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			onChange(message.Payload)
+		}
+	}
+}
+
+// notify 发布变更通知，发布失败时其他实例会在本地缓存过期后读取到新定义
+func (s *RedisStore) notify(ctx context.Context, key string) {
+	_ = s.client.Publish(ctx, s.channel, key).Err()
+}
+
+// decodeFlag 解析哈希中保存的开关
+func decodeFlag(key, value string) (*Flag, error) {
+	var flag Flag
+	if err := json.Unmarshal([]byte(value), &flag); err != nil {
+		return nil, fmt.Errorf("failed to decode feature flag %s: %w", key, err)
+	}
+	return &flag, nil
+}
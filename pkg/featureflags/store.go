@@ -0,0 +1,112 @@
+package featureflags
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Store 开关定义的存储
+type Store interface {
+	// List 返回所有开关，按键排序
+	List(ctx context.Context) ([]*Flag, error)
+
+	// Get 返回指定开关，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (*Flag, error)
+
+	// Create 创建开关，已存在时返回 ErrExists
+	Create(ctx context.Context, flag *Flag) error
+
+	// Update 替换开关定义，不存在时返回 ErrNotFound
+	Update(ctx context.Context, flag *Flag) error
+
+	// Delete 删除开关，不存在时返回 ErrNotFound
+	Delete(ctx context.Context, key string) error
+}
+
+// Watcher 可选接口：其他实例修改开关时收到通知
+type Watcher interface {
+	// Watch 阻塞监听开关变更，每次变更调用 onChange，ctx 取消时返回
+	Watch(ctx context.Context, onChange func(key string)) error
+}
+
+// MemoryStore 进程内存储，开关来自配置文件，运行时的修改只对当前实例有效，重启后恢复为配置
+type MemoryStore struct {
+	mu    sync.RWMutex
+	flags map[string]*Flag
+}
+
+// NewMemoryStore 使用配置中的开关创建进程内存储
+func NewMemoryStore(flags ...*Flag) *MemoryStore {
+	store := &MemoryStore{flags: make(map[string]*Flag, len(flags))}
+	for _, flag := range flags {
+		store.flags[flag.Key] = flag.clone()
+	}
+	return store
+}
+
+// List 返回所有开关
+func (s *MemoryStore) List(ctx context.Context) ([]*Flag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flags := make([]*Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag.clone())
+	}
+	sortFlags(flags)
+	return flags, nil
+}
+
+// Get 返回指定开关
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Flag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flag, ok := s.flags[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return flag.clone(), nil
+}
+
+// Create 创建开关
+func (s *MemoryStore) Create(ctx context.Context, flag *Flag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.flags[flag.Key]; ok {
+		return ErrExists
+	}
+	s.flags[flag.Key] = flag.clone()
+	return nil
+}
+
+// Update 替换开关定义
+func (s *MemoryStore) Update(ctx context.Context, flag *Flag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.flags[flag.Key]; !ok {
+		return ErrNotFound
+	}
+	s.flags[flag.Key] = flag.clone()
+	return nil
+}
+
+// Delete 删除开关
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.flags[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.flags, key)
+	return nil
+}
+
+// sortFlags 按键排序
+func sortFlags(flags []*Flag) {
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+}
@@ -0,0 +1,261 @@
+// Package fieldcrypt 模型字段的静态加密
+//
+// 字段值使用 AES-256-GCM 加密，密文带有密钥版本号，轮换时新增版本即可，旧版本的密钥保留用于解密已有数据。
+// 加密使用随机 nonce，相同的明文每次得到不同的密文，因此不能按密文查询；需要按值查询的字段另存盲索引
+// （规范化后的值的 HMAC-SHA256），按索引做等值查询。
+//
+// 模型字段用 gorm:"serializer:encrypted" 声明加密，由 Install 安装的密钥环加解密。
+// 未安装密钥环时字段按明文读写；未加密的旧数据在启用加密后仍可读取，重新保存时加密。
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// KeySize AES-256 密钥长度（字节）
+const KeySize = 32
+
+// MinIndexKeySize 盲索引密钥的最小长度（字节）
+const MinIndexKeySize = 32
+
+// prefix 密文前缀，完整格式为 enc:v<版本>:<base64(nonce+密文)>
+const prefix = "enc:v"
+
+// 加解密相关错误
+var (
+	ErrUnknownKey       = errors.New("fieldcrypt: unknown key version")
+	ErrMalformed        = errors.New("fieldcrypt: malformed ciphertext")
+	ErrNotConfigured    = errors.New("fieldcrypt: encrypted value found but no keyring is installed")
+	ErrInvalidKeySpec   = errors.New("fieldcrypt: invalid key specification")
+	ErrIndexKeyTooShort = fmt.Errorf("fieldcrypt: blind index key must be at least %d bytes", MinIndexKeySize)
+)
+
+// Keyring 加密密钥环：按版本保存的 AES 密钥、加密新数据使用的版本和盲索引密钥
+type Keyring struct {
+	aeads    map[uint32]cipher.AEAD
+	active   uint32
+	indexKey []byte
+}
+
+// NewKeyring 创建密钥环，active 为 0 时使用最大的版本加密新数据
+// 盲索引密钥不随 AES 密钥轮换，修改后须重新计算全部索引
+func NewKeyring(keys map[uint32][]byte, active uint32, indexKey []byte) (*Keyring, error) {
+	if len(indexKey) < MinIndexKeySize {
+		return nil, ErrIndexKeyTooShort
+	}
+	return newKeyring(keys, active, append([]byte(nil), indexKey...))
+}
+
+func newKeyring(keys map[uint32][]byte, active uint32, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeySpec)
+	}
+
+	k := &Keyring{aeads: make(map[uint32]cipher.AEAD, len(keys)), active: active, indexKey: indexKey}
+	for version, key := range keys {
+		if version == 0 {
+			return nil, fmt.Errorf("%w: key versions start at 1", ErrInvalidKeySpec)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: key %d is %d bytes, want %d", ErrInvalidKeySpec, version, len(key), KeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[version] = aead
+		if active == 0 && version > k.active {
+			k.active = version
+		}
+	}
+	if _, ok := k.aeads[k.active]; !ok {
+		return nil, fmt.Errorf("%w: active key %d is not configured", ErrInvalidKeySpec, active)
+	}
+	return k, nil
+}
+
+// WithKeys 返回换用另一组 AES 密钥、保留盲索引密钥的密钥环，用于外部密钥后端中的密钥轮换
+func (k *Keyring) WithKeys(keys map[uint32][]byte, active uint32) (*Keyring, error) {
+	return newKeyring(keys, active, k.indexKey)
+}
+
+// ParseKeys 解析 "版本:base64密钥" 形式、逗号分隔的密钥列表，如 "1:q83v...,2:7Yk0..."
+func ParseKeys(spec string) (map[uint32][]byte, error) {
+	keys := make(map[uint32][]byte)
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rawVersion, rawKey, found := strings.Cut(entry, ":")
+		if !found {
+			// 错误信息中不包含条目内容，以免输出密钥
+			return nil, fmt.Errorf("%w: entry %d is not in version:key form", ErrInvalidKeySpec, i+1)
+		}
+		version, err := strconv.ParseUint(strings.TrimSpace(rawVersion), 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w: invalid key version %q", ErrInvalidKeySpec, rawVersion)
+		}
+		if _, exists := keys[uint32(version)]; exists {
+			return nil, fmt.Errorf("%w: duplicate key version %d", ErrInvalidKeySpec, version)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rawKey))
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %d is not valid base64", ErrInvalidKeySpec, version)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: key %d is %d bytes, want %d", ErrInvalidKeySpec, version, len(key), KeySize)
+		}
+		keys[uint32(version)] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeySpec)
+	}
+	return keys, nil
+}
+
+// ActiveVersion 返回加密新数据使用的密钥版本
+func (k *Keyring) ActiveVersion() uint32 {
+	return k.active
+}
+
+// Versions 返回密钥环中的全部密钥版本，按升序排列
+func (k *Keyring) Versions() []uint32 {
+	versions := make([]uint32, 0, len(k.aeads))
+	for version := range k.aeads {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// Encrypt 使用当前版本的密钥加密字段值，aad 将密文绑定到所在的列（见 Column），空值不加密
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead := k.aeads[k.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return prefix + strconv.FormatUint(uint64(k.active), 10) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密字段值，未加密的值原样返回
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	version, payload, ok := parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	aead, found := k.aeads[version]
+	if !found {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKey, version)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// IsCurrent 判断字段值是否已用当前版本的密钥加密，空值视为已加密
+func (k *Keyring) IsCurrent(value string) bool {
+	if value == "" {
+		return true
+	}
+	version, ok := KeyVersion(value)
+	return ok && version == k.active
+}
+
+// BlindIndex 返回字段值的盲索引：去除首尾空白并转为小写后的 HMAC-SHA256，十六进制编码
+// 因此按盲索引查询不区分大小写
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyVersion 返回密文使用的密钥版本，值未加密时返回 false
+func KeyVersion(value string) (uint32, bool) {
+	version, _, ok := parse(value)
+	return version, ok
+}
+
+// IsEncrypted 判断字段值是否为密文
+func IsEncrypted(value string) bool {
+	_, _, ok := parse(value)
+	return ok
+}
+
+// Column 返回加密列的附加认证数据，密文复制到其他列后无法解密
+func Column(table, column string) string {
+	return table + "." + column
+}
+
+// parse 拆分密文中的密钥版本和载荷
+func parse(value string) (uint32, string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return 0, "", false
+	}
+	rawVersion, payload, found := strings.Cut(value[len(prefix):], ":")
+	if !found {
+		return 0, "", false
+	}
+	version, err := strconv.ParseUint(rawVersion, 10, 32)
+	if err != nil || version == 0 {
+		return 0, "", false
+	}
+	return uint32(version), payload, true
+}
+
+// current 当前安装的密钥环，为 nil 时字段按明文读写
+var current atomic.Pointer[Keyring]
+
+// Install 安装模型字段加解密使用的密钥环，keyring 为 nil 时停用加密
+// 密钥轮换时可以在运行中替换，正在进行的读写使用替换前的密钥环
+func Install(keyring *Keyring) {
+	current.Store(keyring)
+}
+
+// Current 返回当前安装的密钥环，未启用加密时返回 nil
+func Current() *Keyring {
+	return current.Load()
+}
+
+// Enabled 判断是否已安装密钥环
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// BlindIndex 使用当前安装的密钥环计算盲索引，未启用加密时返回 false
+func BlindIndex(value string) (string, bool) {
+	keyring := current.Load()
+	if keyring == nil {
+		return "", false
+	}
+	return keyring.BlindIndex(value), true
+}
@@ -0,0 +1,195 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+// indexKey 测试用的盲索引密钥
+var indexKey = []byte("blind-index-key-for-tests-32-bytes")
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func newTestKeyring(t *testing.T, keys map[uint32][]byte, active uint32) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(keys, active, indexKey)
+	require.NoError(t, err)
+	return keyring
+}
+
+// install 安装密钥环并在测试结束时停用加密
+func install(t *testing.T, keyring *Keyring) {
+	t.Helper()
+	Install(keyring)
+	t.Cleanup(func() { Install(nil) })
+}
+
+func TestKeyring(t *testing.T) {
+	v1 := newTestKeyring(t, map[uint32][]byte{1: testKey(1)}, 0)
+
+	ciphertext, err := v1.Encrypt("alice@example.com", "users.email")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:v1:"))
+	assert.NotContains(t, ciphertext, "alice")
+	again, err := v1.Encrypt("alice@example.com", "users.email")
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "随机 nonce")
+
+	plaintext, err := v1.Decrypt(ciphertext, "users.email")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", plaintext)
+
+	_, err = v1.Decrypt(ciphertext, "users.first_name")
+	assert.Error(t, err, "密文绑定到所在的列")
+	_, err = v1.Decrypt("enc:v1:"+base64.StdEncoding.EncodeToString([]byte("short")), "users.email")
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	plaintext, err = v1.Decrypt("legacy@example.com", "users.email")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy@example.com", plaintext, "未加密的值原样返回")
+
+	empty, err := v1.Encrypt("", "users.first_name")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	t.Run("rotation", func(t *testing.T) {
+		v2, err := v1.WithKeys(map[uint32][]byte{1: testKey(1), 2: testKey(2)}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), v2.ActiveVersion())
+		assert.Equal(t, []uint32{1, 2}, v2.Versions())
+		assert.False(t, v2.IsCurrent(ciphertext))
+		assert.False(t, v2.IsCurrent("legacy@example.com"))
+		assert.True(t, v2.IsCurrent(""))
+
+		plaintext, err := v2.Decrypt(ciphertext, "users.email")
+		require.NoError(t, err, "旧版本的密钥仍可解密")
+		assert.Equal(t, "alice@example.com", plaintext)
+
+		rotated, err := v2.Encrypt(plaintext, "users.email")
+		require.NoError(t, err)
+		assert.True(t, v2.IsCurrent(rotated))
+		_, err = v1.Decrypt(rotated, "users.email")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		assert.Equal(t, v1.BlindIndex("alice@example.com"), v2.BlindIndex("alice@example.com"), "盲索引密钥不随轮换变化")
+
+		pinned, err := v1.WithKeys(map[uint32][]byte{1: testKey(1), 2: testKey(2)}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), pinned.ActiveVersion())
+	})
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring(map[uint32][]byte{1: testKey(1)}, 0, []byte("short"))
+	assert.ErrorIs(t, err, ErrIndexKeyTooShort)
+	_, err = NewKeyring(nil, 0, indexKey)
+	assert.ErrorIs(t, err, ErrInvalidKeySpec)
+	_, err = NewKeyring(map[uint32][]byte{1: testKey(1)}, 2, indexKey)
+	assert.ErrorIs(t, err, ErrInvalidKeySpec, "当前版本不在密钥列表中")
+	_, err = NewKeyring(map[uint32][]byte{1: []byte("too-short")}, 0, indexKey)
+	assert.ErrorIs(t, err, ErrInvalidKeySpec)
+}
+
+func TestParseKeys(t *testing.T) {
+	one := base64.StdEncoding.EncodeToString(testKey(1))
+	two := base64.StdEncoding.EncodeToString(testKey(2))
+
+	keys, err := ParseKeys(" 1:" + one + " , 2:" + two + ",")
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32][]byte{1: testKey(1), 2: testKey(2)}, keys)
+
+	for _, spec := range []string{"", one, "0:" + one, "x:" + one, "1:not-base64!", "1:" + base64.StdEncoding.EncodeToString([]byte("short")), "1:" + one + ",1:" + two} {
+		_, err := ParseKeys(spec)
+		assert.ErrorIs(t, err, ErrInvalidKeySpec, spec)
+		if err != nil {
+			assert.NotContains(t, err.Error(), one, "错误信息中不包含密钥")
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring := newTestKeyring(t, map[uint32][]byte{1: testKey(1)}, 0)
+	index := keyring.BlindIndex("Alice@Example.com ")
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, keyring.BlindIndex("alice@example.com"), "不区分大小写")
+	assert.NotEqual(t, index, keyring.BlindIndex("bob@example.com"))
+
+	other, err := NewKeyring(map[uint32][]byte{1: testKey(1)}, 0, []byte("another-blind-index-key-of-32-bytes"))
+	require.NoError(t, err)
+	assert.NotEqual(t, index, other.BlindIndex("alice@example.com"))
+
+	_, ok := BlindIndex("alice@example.com")
+	assert.False(t, ok, "未启用加密")
+	install(t, keyring)
+	installed, ok := BlindIndex("alice@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, index, installed)
+}
+
+// account 使用加密字段的测试模型
+type account struct {
+	ID    uint
+	Email string `gorm:"serializer:encrypted"`
+}
+
+func TestSerializer(t *testing.T) {
+	s, err := schema.Parse(&account{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+	field := s.LookUpField("email")
+	require.NotNil(t, field)
+	ctx := context.Background()
+
+	scan := func(dbValue interface{}) (string, error) {
+		var a account
+		err := Serializer{}.Scan(ctx, field, reflect.ValueOf(&a).Elem(), dbValue)
+		return a.Email, err
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		value, err := Serializer{}.Value(ctx, field, reflect.Value{}, "alice@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", value)
+
+		_, err = scan("enc:v1:AAAA")
+		assert.ErrorIs(t, err, ErrNotConfigured)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		keyring := newTestKeyring(t, map[uint32][]byte{1: testKey(1)}, 0)
+		install(t, keyring)
+
+		value, err := Serializer{}.Value(ctx, field, reflect.Value{}, "alice@example.com")
+		require.NoError(t, err)
+		ciphertext := value.(string)
+		assert.True(t, IsEncrypted(ciphertext))
+		plaintext, err := keyring.Decrypt(ciphertext, Column("accounts", "email"))
+		require.NoError(t, err, "附加认证数据为表和列")
+		assert.Equal(t, "alice@example.com", plaintext)
+
+		email, err := scan([]byte(ciphertext))
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", email)
+
+		email, err = scan("legacy@example.com")
+		require.NoError(t, err, "启用加密前写入的明文")
+		assert.Equal(t, "legacy@example.com", email)
+
+		email, err = scan(nil)
+		require.NoError(t, err)
+		assert.Empty(t, email)
+
+		_, err = Serializer{}.Value(ctx, field, reflect.Value{}, 42)
+		assert.Error(t, err)
+	})
+}
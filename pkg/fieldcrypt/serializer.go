@@ -0,0 +1,61 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName 加密字段在 GORM 标签中使用的序列化器名称
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer GORM 序列化器，使用当前安装的密钥环加解密 string 字段，附加认证数据为所在的表和列
+// 写入时未启用加密则保存明文；读取时明文原样返回，密文须能用密钥环中的某个版本解密
+type Serializer struct{}
+
+// Scan 解密数据库中的值并写入字段
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("fieldcrypt: unsupported database value %T for %s", dbValue, field.Name)
+	}
+
+	if IsEncrypted(value) {
+		keyring := current.Load()
+		if keyring == nil {
+			return fmt.Errorf("%w (%s)", ErrNotConfigured, field.Name)
+		}
+		plaintext, err := keyring.Decrypt(value, Column(field.Schema.Table, field.DBName))
+		if err != nil {
+			return fmt.Errorf("%w (%s)", err, field.Name)
+		}
+		value = plaintext
+	}
+	return field.Set(ctx, dst, value)
+}
+
+// Value 加密字段值
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("fieldcrypt: unsupported field type %T for %s, only string is supported", fieldValue, field.Name)
+	}
+
+	keyring := current.Load()
+	if keyring == nil {
+		return value, nil
+	}
+	return keyring.Encrypt(value, Column(field.Schema.Table, field.DBName))
+}
@@ -0,0 +1,244 @@
+// Package geoip 基于 MaxMind 数据库（GeoLite2/GeoIP2）的 IP 地理位置查询
+//
+// Reader 打开国家（或城市）数据库和可选的 ASN 数据库，查询 IP 所属的国家代码和自治系统。
+// 数据库文件更新后（如 geoipupdate 定期下载），后台刷新在不中断查询的情况下换用新文件。
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// ErrNoDatabase 没有配置国家数据库
+var ErrNoDatabase = errors.New("geoip: country database path is required")
+
+// Location IP 地址的地理位置
+type Location struct {
+	Country      string `json:"country,omitempty" example:"US"`    // ISO 3166-1 两位国家代码
+	ASN          uint   `json:"asn,omitempty" example:"15169"`     // 自治系统编号，未配置 ASN 数据库时为 0
+	Organization string `json:"as_org,omitempty" example:"GOOGLE"` // 自治系统所属组织
+}
+
+// Locator 查询 IP 地址的地理位置
+type Locator interface {
+	// Locate 返回 IP 地址的地理位置，地址无效或数据库中没有记录时返回 false
+	Locate(ip string) (Location, bool)
+}
+
+// Config 数据库路径和刷新参数
+type Config struct {
+	CountryDatabase string        // GeoLite2-Country 或 GeoLite2-City 数据库路径
+	ASNDatabase     string        // GeoLite2-ASN 数据库路径，为空时不查询自治系统
+	RefreshInterval time.Duration // 检查数据库文件是否更新的间隔，<=0 时不检查
+
+	// OnReload 换用更新后的数据库文件时调用，用于记录日志
+	OnReload func(path string)
+	// OnError 后台刷新失败时调用，失败时继续使用原来的数据库；为 nil 时忽略错误
+	OnError func(err error)
+}
+
+// database 一个已打开的数据库文件
+type database struct {
+	path    string
+	reader  *geoip2.Reader
+	modTime time.Time
+	size    int64
+}
+
+// Reader 查询国家和自治系统，数据库文件更新后自动换用新文件
+type Reader struct {
+	config Config
+
+	// 查询持有读锁；换用新文件时持有写锁，旧文件在没有查询使用后关闭
+	mu      sync.RWMutex
+	country *database
+	asn     *database
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// Open 打开配置的数据库文件
+func Open(config Config) (*Reader, error) {
+	if config.CountryDatabase == "" {
+		return nil, ErrNoDatabase
+	}
+
+	country, err := openDatabase(config.CountryDatabase)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{
+		config:  config,
+		country: country,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if config.ASNDatabase != "" {
+		if r.asn, err = openDatabase(config.ASNDatabase); err != nil {
+			country.reader.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// openDatabase 打开数据库文件并记录文件的修改时间和大小
+func openDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: failed to open %s: %w", path, err)
+	}
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: failed to open %s: %w", path, err)
+	}
+	return &database{path: path, reader: reader, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// changed 判断数据库文件是否已被替换
+func (d *database) changed() (bool, error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return false, fmt.Errorf("geoip: failed to stat %s: %w", d.path, err)
+	}
+	return !info.ModTime().Equal(d.modTime) || info.Size() != d.size, nil
+}
+
+// Locate 返回 IP 地址的国家和自治系统
+func (r *Reader) Locate(ip string) (Location, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.country == nil {
+		return Location{}, false
+	}
+
+	var location Location
+	if country, err := r.country.reader.Country(parsed); err == nil {
+		location.Country = country.Country.IsoCode
+		if location.Country == "" {
+			// 卫星、匿名代理等地址只有注册国家
+			location.Country = country.RegisteredCountry.IsoCode
+		}
+	}
+	if r.asn != nil {
+		if asn, err := r.asn.reader.ASN(parsed); err == nil {
+			location.ASN = asn.AutonomousSystemNumber
+			location.Organization = asn.AutonomousSystemOrganization
+		}
+	}
+	return location, location.Country != "" || location.ASN != 0
+}
+
+// Refresh 重新打开修改过的数据库文件；打开失败时继续使用原来的数据库
+func (r *Reader) Refresh() error {
+	r.mu.RLock()
+	current := []*database{r.country, r.asn}
+	r.mu.RUnlock()
+
+	var errs []error
+	for i, db := range current {
+		if db == nil {
+			continue
+		}
+		changed, err := db.changed()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		reloaded, err := openDatabase(db.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.mu.Lock()
+		slot := &r.country
+		if i == 1 {
+			slot = &r.asn
+		}
+		if *slot != db {
+			// 期间已被关闭或由另一次刷新替换
+			r.mu.Unlock()
+			reloaded.reader.Close()
+			continue
+		}
+		*slot = reloaded
+		r.mu.Unlock()
+		// 写锁释放前所有查询都已结束，之后的查询使用新文件
+		db.reader.Close()
+
+		if r.config.OnReload != nil {
+			r.config.OnReload(db.path)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start 按 RefreshInterval 在后台检查数据库文件，只有第一次调用生效
+func (r *Reader) Start() {
+	if r.config.RefreshInterval <= 0 {
+		return
+	}
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// run 定期刷新数据库文件
+func (r *Reader) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Refresh(); err != nil && r.config.OnError != nil {
+				r.config.OnError(err)
+			}
+		}
+	}
+}
+
+// Close 停止后台刷新并关闭数据库文件，之后的查询返回 false
+func (r *Reader) Close() error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	// 从未启动时没有 goroutine 关闭 done
+	r.startOnce.Do(func() {
+		close(r.done)
+	})
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for _, db := range []*database{r.country, r.asn} {
+		if db != nil {
+			errs = append(errs, db.reader.Close())
+		}
+	}
+	r.country, r.asn = nil, nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,295 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trieNode 测试数据库的搜索树节点，data >= 0 表示网段对应的数据
+type trieNode struct {
+	children [2]*trieNode
+	data     int
+}
+
+// writeTestDatabase 写入只包含 IPv4 网段的 MaxMind 数据库（24 位记录）
+// networks 的键为 CIDR，值为该网段的数据
+func writeTestDatabase(t *testing.T, path, databaseType string, networks map[string]map[string]interface{}) {
+	t.Helper()
+
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+
+	// 数据区
+	var data bytes.Buffer
+	offsets := make([]int, len(cidrs))
+	root := &trieNode{data: -1}
+	for i, cidr := range cidrs {
+		offsets[i] = data.Len()
+		encodeValue(&data, networks[cidr])
+
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		ones, _ := network.Mask.Size()
+		ip := network.IP.To4()
+		node := root
+		for bit := 0; bit < ones; bit++ {
+			b := ip[bit/8] >> (7 - bit%8) & 1
+			if node.children[b] == nil {
+				node.children[b] = &trieNode{data: -1}
+			}
+			node = node.children[b]
+		}
+		node.data = i
+	}
+
+	// 按广度优先给内部节点编号
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	queue := []*trieNode{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		index[node] = len(nodes)
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil && child.data < 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	var file bytes.Buffer
+	for _, node := range nodes {
+		for _, child := range node.children {
+			record := nodeCount // 没有数据
+			switch {
+			case child == nil:
+			case child.data >= 0:
+				record = nodeCount + 16 + offsets[child.data]
+			default:
+				record = index[child]
+			}
+			file.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	encodeValue(&file, map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               databaseType,
+		"languages":                   []interface{}{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(time.Now().Unix()),
+		"description":                 map[string]interface{}{"en": "test"},
+	})
+
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o644))
+}
+
+// encodeValue 按 MaxMind 数据库格式编码字符串、无符号整数、数组和映射
+func encodeValue(buf *bytes.Buffer, value interface{}) {
+	control := func(typ, size int) {
+		// 长度 29 以上时在控制字节后多用一个字节，测试中的值都小于 285
+		length := size
+		if size >= 29 {
+			length = 29
+		}
+		if typ <= 7 {
+			buf.WriteByte(byte(typ<<5 | length))
+		} else {
+			// 扩展类型
+			buf.WriteByte(byte(length))
+			buf.WriteByte(byte(typ - 7))
+		}
+		if size >= 29 {
+			buf.WriteByte(byte(size - 29))
+		}
+	}
+	unsigned := func(typ int, v uint64, width int) {
+		raw := make([]byte, 8)
+		binary.BigEndian.PutUint64(raw, v)
+		raw = bytes.TrimLeft(raw[8-width:], "\x00")
+		control(typ, len(raw))
+		buf.Write(raw)
+	}
+
+	switch v := value.(type) {
+	case string:
+		control(2, len(v))
+		buf.WriteString(v)
+	case uint16:
+		unsigned(5, uint64(v), 2)
+	case uint32:
+		unsigned(6, uint64(v), 4)
+	case uint64:
+		unsigned(9, v, 8)
+	case []interface{}:
+		control(11, len(v))
+		for _, item := range v {
+			encodeValue(buf, item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		control(7, len(v))
+		for _, key := range keys {
+			encodeValue(buf, key)
+			encodeValue(buf, v[key])
+		}
+	default:
+		panic("unsupported value")
+	}
+}
+
+func country(code string) map[string]interface{} {
+	return map[string]interface{}{"country": map[string]interface{}{"iso_code": code}}
+}
+
+func TestReader(t *testing.T) {
+	dir := t.TempDir()
+	countryPath := filepath.Join(dir, "GeoLite2-Country.mmdb")
+	asnPath := filepath.Join(dir, "GeoLite2-ASN.mmdb")
+	writeTestDatabase(t, countryPath, "GeoLite2-Country", map[string]map[string]interface{}{
+		"8.0.0.0/8":   country("US"),
+		"81.2.0.0/16": country("GB"),
+		"203.0.113.0/24": {
+			"registered_country": map[string]interface{}{"iso_code": "AU"},
+		},
+	})
+	writeTestDatabase(t, asnPath, "GeoLite2-ASN", map[string]map[string]interface{}{
+		"8.8.8.0/24": {
+			"autonomous_system_number":       uint32(15169),
+			"autonomous_system_organization": "GOOGLE",
+		},
+	})
+
+	_, err := Open(Config{})
+	assert.ErrorIs(t, err, ErrNoDatabase)
+	_, err = Open(Config{CountryDatabase: filepath.Join(dir, "missing.mmdb")})
+	assert.Error(t, err)
+
+	reader, err := Open(Config{CountryDatabase: countryPath, ASNDatabase: asnPath})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	t.Run("查询国家和自治系统", func(t *testing.T) {
+		location, ok := reader.Locate("8.8.8.8")
+		require.True(t, ok)
+		assert.Equal(t, Location{Country: "US", ASN: 15169, Organization: "GOOGLE"}, location)
+
+		location, ok = reader.Locate("81.2.69.142")
+		require.True(t, ok)
+		assert.Equal(t, Location{Country: "GB"}, location)
+	})
+
+	t.Run("没有国家时使用注册国家", func(t *testing.T) {
+		location, ok := reader.Locate("203.0.113.7")
+		require.True(t, ok)
+		assert.Equal(t, "AU", location.Country)
+	})
+
+	t.Run("没有记录或地址无效时返回 false", func(t *testing.T) {
+		for _, ip := range []string{"10.0.0.1", "not-an-ip", "", "2001:db8::1"} {
+			_, ok := reader.Locate(ip)
+			assert.False(t, ok, ip)
+		}
+	})
+}
+
+func TestReader_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "GeoLite2-Country.mmdb")
+	writeTestDatabase(t, path, "GeoLite2-Country", map[string]map[string]interface{}{
+		"8.0.0.0/8": country("US"),
+	})
+
+	var reloaded []string
+	reader, err := Open(Config{CountryDatabase: path, OnReload: func(path string) {
+		reloaded = append(reloaded, path)
+	}})
+	require.NoError(t, err)
+
+	// 文件没有变化时不重新打开
+	require.NoError(t, reader.Refresh())
+	assert.Empty(t, reloaded)
+
+	// 与 geoipupdate 一样写入新文件后替换
+	next := filepath.Join(dir, "next.mmdb")
+	writeTestDatabase(t, next, "GeoLite2-Country", map[string]map[string]interface{}{
+		"8.0.0.0/8": country("CA"),
+		"9.0.0.0/8": country("MX"),
+	})
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(next, later, later))
+	require.NoError(t, os.Rename(next, path))
+
+	require.NoError(t, reader.Refresh())
+	assert.Equal(t, []string{path}, reloaded)
+	location, ok := reader.Locate("8.8.8.8")
+	require.True(t, ok)
+	assert.Equal(t, "CA", location.Country)
+
+	// 新文件无效时继续使用原来的数据库
+	require.NoError(t, os.WriteFile(next, []byte("corrupt"), 0o644))
+	require.NoError(t, os.Rename(next, path))
+	assert.Error(t, reader.Refresh())
+	location, ok = reader.Locate("9.1.1.1")
+	require.True(t, ok)
+	assert.Equal(t, "MX", location.Country)
+
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close(), "重复关闭")
+	_, ok = reader.Locate("8.8.8.8")
+	assert.False(t, ok)
+}
+
+func TestReader_StartClose(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "GeoLite2-Country.mmdb")
+	writeTestDatabase(t, path, "GeoLite2-Country", map[string]map[string]interface{}{
+		"8.0.0.0/8": country("US"),
+	})
+
+	reloaded := make(chan string, 1)
+	reader, err := Open(Config{CountryDatabase: path, RefreshInterval: 10 * time.Millisecond, OnReload: func(path string) {
+		reloaded <- path
+	}})
+	require.NoError(t, err)
+	reader.Start()
+
+	next := filepath.Join(dir, "next.mmdb")
+	writeTestDatabase(t, next, "GeoLite2-Country", map[string]map[string]interface{}{
+		"8.0.0.0/8": country("DE"),
+		"9.0.0.0/8": country("DE"),
+	})
+	require.NoError(t, os.Rename(next, path))
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("数据库文件更新后没有重新打开")
+	}
+	location, _ := reader.Locate("8.8.8.8")
+	assert.Equal(t, "DE", location.Country)
+	require.NoError(t, reader.Close())
+}
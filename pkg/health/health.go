@@ -0,0 +1,309 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status 健康状态
+type Status string
+
+// 健康状态取值，与现有 /api/v1/health 响应保持一致
+const (
+	StatusHealthy   Status = "healthy"   // 正常
+	StatusDegraded  Status = "degraded"  // 非关键依赖异常，服务仍可用
+	StatusUnhealthy Status = "unhealthy" // 关键依赖异常，服务不可用
+)
+
+// 默认超时时间
+const (
+	DefaultCheckTimeout = 2 * time.Second // 单个检查的默认超时时间
+)
+
+// Checker 健康检查器，返回 nil 表示健康
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 将普通函数适配为 Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check 实现 Checker 接口
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Option 检查项注册选项
+type Option func(*check)
+
+// WithTimeout 设置检查超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *check) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// Optional 标记为非关键依赖，失败时整体状态为 degraded 而不是 unhealthy
+func Optional() Option {
+	return func(c *check) {
+		c.critical = false
+	}
+}
+
+// check 已注册的检查项
+type check struct {
+	name     string
+	checker  Checker
+	timeout  time.Duration
+	critical bool
+}
+
+// CheckResult 单个检查项的结果
+type CheckResult struct {
+	Status     Status  `json:"status"`          // 检查状态
+	Critical   bool    `json:"critical"`        // 是否为关键依赖
+	DurationMs float64 `json:"duration_ms"`     // 检查耗时（毫秒）
+	Error      string  `json:"error,omitempty"` // 错误信息
+}
+
+// Maintenance 维护倒计时，维护期间附加在检查结果中，不影响整体状态
+type Maintenance struct {
+	Message          string     `json:"message,omitempty"`           // 维护说明
+	StartedAt        *time.Time `json:"started_at,omitempty"`        // 开始时间
+	EndsAt           *time.Time `json:"ends_at,omitempty"`           // 预计结束时间，为空表示未知
+	RemainingSeconds int64      `json:"remaining_seconds,omitempty"` // 距预计结束的秒数
+}
+
+// MaintenanceFunc 返回当前的维护倒计时，不在维护中时返回 nil
+type MaintenanceFunc func(ctx context.Context) *Maintenance
+
+// Report 一组检查的汇总结果
+type Report struct {
+	Status      Status                 `json:"status"`                // 整体状态
+	Checks      map[string]CheckResult `json:"checks"`                // 各检查项结果
+	Maintenance *Maintenance           `json:"maintenance,omitempty"` // 维护倒计时，仅在维护期间出现
+	Timestamp   time.Time              `json:"timestamp"`             // 检查时间
+}
+
+// Healthy 整体状态是否可对外提供服务（degraded 视为可用）
+func (r *Report) Healthy() bool {
+	return r.Status != StatusUnhealthy
+}
+
+// Registry 健康检查注册中心
+// 存活检查（liveness）只应包含进程自身状态，就绪检查（readiness）包含外部依赖
+type Registry struct {
+	mu             sync.RWMutex
+	liveness       map[string]*check
+	readiness      map[string]*check
+	defaultTimeout time.Duration
+	shuttingDown   bool
+	maintenance    MaintenanceFunc
+}
+
+// NewRegistry 创建健康检查注册中心，defaultTimeout 为 0 时使用 DefaultCheckTimeout
+func NewRegistry(defaultTimeout time.Duration) *Registry {
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultCheckTimeout
+	}
+	return &Registry{
+		liveness:       make(map[string]*check),
+		readiness:      make(map[string]*check),
+		defaultTimeout: defaultTimeout,
+	}
+}
+
+// RegisterLiveness 注册存活检查项，同名检查项将被替换
+func (r *Registry) RegisterLiveness(name string, checker Checker, opts ...Option) {
+	r.register(r.liveness, name, checker, opts)
+}
+
+// RegisterReadiness 注册就绪检查项，同名检查项将被替换
+func (r *Registry) RegisterReadiness(name string, checker Checker, opts ...Option) {
+	r.register(r.readiness, name, checker, opts)
+}
+
+// register 注册检查项
+func (r *Registry) register(target map[string]*check, name string, checker Checker, opts []Option) {
+	c := &check{
+		name:     name,
+		checker:  checker,
+		timeout:  r.defaultTimeout,
+		critical: true,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	target[name] = c
+}
+
+// Unregister 移除检查项
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.liveness, name)
+	delete(r.readiness, name)
+}
+
+// Names 返回已注册的就绪检查项名称（按字母排序）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.readiness))
+	for name := range r.readiness {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetShuttingDown 标记服务正在关闭，此后就绪检查始终返回 unhealthy，
+// 使负载均衡器在连接排空期间停止转发新请求
+func (r *Registry) SetShuttingDown(shuttingDown bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shuttingDown = shuttingDown
+}
+
+// ShuttingDown 返回服务是否正在关闭
+func (r *Registry) ShuttingDown() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.shuttingDown
+}
+
+// SetMaintenance 设置维护倒计时的来源，检查结果在维护期间附带倒计时
+// 维护期间实例仍然就绪，由维护模式中间件返回 503，负载均衡器不应摘除实例
+func (r *Registry) SetMaintenance(fn MaintenanceFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maintenance = fn
+}
+
+// Maintenance 返回当前的维护倒计时，未设置来源或不在维护中时返回 nil
+func (r *Registry) Maintenance(ctx context.Context) *Maintenance {
+	r.mu.RLock()
+	fn := r.maintenance
+	r.mu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx)
+}
+
+// CheckLiveness 执行所有存活检查
+func (r *Registry) CheckLiveness(ctx context.Context) *Report {
+	report := r.run(ctx, r.snapshot(r.liveness))
+	report.Maintenance = r.Maintenance(ctx)
+	return report
+}
+
+// CheckReadiness 执行所有就绪检查，服务关闭期间直接返回 unhealthy
+func (r *Registry) CheckReadiness(ctx context.Context) *Report {
+	report := r.run(ctx, r.snapshot(r.readiness))
+	report.Maintenance = r.Maintenance(ctx)
+	if r.ShuttingDown() {
+		report.Status = StatusUnhealthy
+		report.Checks["shutdown"] = CheckResult{
+			Status:   StatusUnhealthy,
+			Critical: true,
+			Error:    "server is shutting down",
+		}
+	}
+	return report
+}
+
+// snapshot 复制检查项列表，避免执行检查时持有锁
+func (r *Registry) snapshot(source map[string]*check) []*check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checks := make([]*check, 0, len(source))
+	for _, c := range source {
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// run 并发执行检查项并汇总结果
+func (r *Registry) run(ctx context.Context, checks []*check) *Report {
+	report := &Report{
+		Status:    StatusHealthy,
+		Checks:    make(map[string]CheckResult, len(checks)),
+		Timestamp: time.Now().UTC(),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+			result := c.run(ctx)
+
+			mu.Lock()
+			report.Checks[c.name] = result
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// run 在超时时间内执行单个检查，检查器忽略上下文时也不会阻塞调用方
+func (c *check) run(ctx context.Context) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("health check panicked: %v", rec)
+			}
+		}()
+		done <- c.checker.Check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = fmt.Errorf("health check timed out after %s", c.timeout)
+	}
+
+	result := CheckResult{
+		Status:     StatusHealthy,
+		Critical:   c.critical,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func healthy() Checker {
+	return CheckerFunc(func(ctx context.Context) error { return nil })
+}
+
+func failing(msg string) Checker {
+	return CheckerFunc(func(ctx context.Context) error { return errors.New(msg) })
+}
+
+func TestRegistry_EmptyIsHealthy(t *testing.T) {
+	registry := NewRegistry(0)
+
+	report := registry.CheckReadiness(context.Background())
+	assert.Equal(t, StatusHealthy, report.Status)
+	assert.Empty(t, report.Checks)
+	assert.True(t, report.Healthy())
+}
+
+func TestRegistry_CriticalFailureIsUnhealthy(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.RegisterReadiness("database", failing("connection refused"))
+	registry.RegisterReadiness("cache", healthy(), Optional())
+
+	report := registry.CheckReadiness(context.Background())
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+	assert.Equal(t, StatusHealthy, report.Checks["cache"].Status)
+	assert.False(t, report.Checks["cache"].Critical)
+}
+
+func TestRegistry_OptionalFailureIsDegraded(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.RegisterReadiness("database", healthy())
+	registry.RegisterReadiness("cache", failing("redis down"), Optional())
+
+	report := registry.CheckReadiness(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Healthy())
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	registry := NewRegistry(0)
+	// 忽略上下文的检查器也不能阻塞探针
+	registry.RegisterReadiness("slow", CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}), WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	report := registry.CheckReadiness(context.Background())
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Contains(t, report.Checks["slow"].Error, "timed out")
+}
+
+func TestRegistry_PanicIsReportedAsFailure(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.RegisterLiveness("broken", CheckerFunc(func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	report := registry.CheckLiveness(context.Background())
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Contains(t, report.Checks["broken"].Error, "boom")
+}
+
+func TestRegistry_LivenessAndReadinessAreSeparate(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.RegisterLiveness("goroutines", healthy())
+	registry.RegisterReadiness("database", failing("down"))
+
+	assert.Equal(t, StatusHealthy, registry.CheckLiveness(context.Background()).Status)
+	assert.Equal(t, StatusUnhealthy, registry.CheckReadiness(context.Background()).Status)
+	assert.Equal(t, []string{"database"}, registry.Names())
+
+	registry.Unregister("database")
+	assert.Equal(t, StatusHealthy, registry.CheckReadiness(context.Background()).Status)
+}
+
+func TestRegistry_ShuttingDown(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.RegisterReadiness("database", healthy())
+	registry.SetShuttingDown(true)
+
+	report := registry.CheckReadiness(context.Background())
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Contains(t, report.Checks, "shutdown")
+
+	// 存活检查不受影响，避免在排空期间被重启
+	assert.Equal(t, StatusHealthy, registry.CheckLiveness(context.Background()).Status)
+}
+
+func TestRegistry_Maintenance(t *testing.T) {
+	r := NewRegistry(0)
+	assert.Nil(t, r.CheckReadiness(context.Background()).Maintenance)
+
+	endsAt := time.Now().Add(time.Hour)
+	r.SetMaintenance(func(ctx context.Context) *Maintenance {
+		return &Maintenance{Message: "升级", EndsAt: &endsAt, RemainingSeconds: 3600}
+	})
+
+	report := r.CheckReadiness(context.Background())
+	assert.Equal(t, StatusHealthy, report.Status, "维护期间实例仍然就绪")
+	assert.Equal(t, int64(3600), report.Maintenance.RemainingSeconds)
+	assert.Equal(t, "升级", r.CheckLiveness(context.Background()).Maintenance.Message)
+}
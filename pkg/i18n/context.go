@@ -0,0 +1,25 @@
+package i18n
+
+import "context"
+
+type contextKey struct{}
+
+// WithLocale 将请求协商出的语言写入上下文
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// Locale 返回上下文中的语言，未经过语言协商中间件时返回 false
+func Locale(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	locale, ok := ctx.Value(contextKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// T 使用内置消息目录和上下文中的语言翻译消息，上下文中没有语言时使用默认语言
+func T(ctx context.Context, key string, args ...string) string {
+	locale, _ := Locale(ctx)
+	return Default().Translate(locale, key, args...)
+}
@@ -0,0 +1,200 @@
+// Package i18n 接口消息的本地化
+//
+// 消息目录为 locales 目录下以语言命名的 JSON 文件（如 zh.json），内容为消息键到消息模板的映射，
+// 模板中的 {0}、{1} 依次替换为参数。目录嵌入到二进制中，Default 返回加载了这些目录的 Bundle。
+//
+// 查找消息时按回退链依次尝试：zh-Hant-TW → zh-hant → zh → 默认语言，
+// 某个语言缺少的消息使用更通用的语言或默认语言的消息，都没有时返回消息键本身。
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale 请求未指定或指定了不支持的语言时使用的语言
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var catalogFS embed.FS
+
+var (
+	defaultOnce   sync.Once
+	defaultBundle *Bundle
+)
+
+// Default 返回加载了内置消息目录的 Bundle，默认语言为 DefaultLocale
+// 内置目录在编译时嵌入，无法解析说明目录文件有误，直接 panic
+func Default() *Bundle {
+	defaultOnce.Do(func() {
+		bundle := NewBundle(DefaultLocale)
+		if err := bundle.LoadFS(catalogFS, "locales/*.json"); err != nil {
+			panic(fmt.Sprintf("i18n: load embedded catalogs: %v", err))
+		}
+		defaultBundle = bundle
+	})
+	return defaultBundle
+}
+
+// Bundle 各语言的消息目录
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	catalogs      map[string]map[string]string
+}
+
+// NewBundle 创建空的 Bundle，defaultLocale 为回退链的最后一级
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: Normalize(defaultLocale),
+		catalogs:      make(map[string]map[string]string),
+	}
+}
+
+// LoadFS 加载 fsys 中匹配 pattern 的 JSON 目录，文件名（不含扩展名）为语言
+// 同一语言的多个文件合并，后加载的消息覆盖先加载的同名消息
+func (b *Bundle) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("parse catalog %s: %w", file, err)
+		}
+		b.AddMessages(strings.TrimSuffix(path.Base(file), path.Ext(file)), messages)
+	}
+	return nil
+}
+
+// AddMessages 向语言的目录添加消息，覆盖同名消息
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	locale = Normalize(locale)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog, ok := b.catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		b.catalogs[locale] = catalog
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// DefaultLocale 返回默认语言
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// Locales 返回有消息目录的语言，按字母顺序排列
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.catalogs))
+	for locale := range b.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supported 判断语言本身或其回退链中的某个语言（不含默认语言）是否有消息目录，返回该语言
+func (b *Bundle) Supported(locale string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, candidate := range Fallbacks(locale, "") {
+		if _, ok := b.catalogs[candidate]; ok {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// Lookup 按回退链查找消息模板，找不到时返回 false
+func (b *Bundle) Lookup(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, candidate := range Fallbacks(locale, b.defaultLocale) {
+		if message, ok := b.catalogs[candidate][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Translate 返回消息在指定语言下的文本，{0}、{1} 依次替换为 args，找不到消息时返回消息键
+func (b *Bundle) Translate(locale, key string, args ...string) string {
+	message, ok := b.Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return Format(message, args...)
+}
+
+// Messages 返回消息在所有语言下的文本，键为语言，用于 AppError.AddInternationalizedMessages
+// 某个语言缺少该消息时不包含该语言，由 GetLocalizedMessage 按回退链选择
+func (b *Bundle) Messages(key string, args ...string) map[string]string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	messages := make(map[string]string, len(b.catalogs))
+	for locale, catalog := range b.catalogs {
+		if message, ok := catalog[key]; ok {
+			messages[locale] = Format(message, args...)
+		}
+	}
+	return messages
+}
+
+// Format 将模板中的 {0}、{1} 依次替换为 args
+func Format(message string, args ...string) string {
+	if len(args) == 0 {
+		return message
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for i, arg := range args {
+		pairs = append(pairs, "{"+strconv.Itoa(i)+"}", arg)
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// Normalize 规范化语言标签：小写，下划线替换为连字符，如 zh_Hans_CN → zh-hans-cn
+func Normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Fallbacks 返回语言的回退链，依次去掉最后一个子标签，最后是 defaultLocale（为空时不包含）
+// 如 Fallbacks("zh-Hant-TW", "en") 返回 [zh-hant-tw zh-hant zh en]
+func Fallbacks(locale, defaultLocale string) []string {
+	var chain []string
+	for tag := Normalize(locale); tag != ""; {
+		chain = append(chain, tag)
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	if defaultLocale = Normalize(defaultLocale); defaultLocale != "" {
+		for _, tag := range chain {
+			if tag == defaultLocale {
+				return chain
+			}
+		}
+		chain = append(chain, defaultLocale)
+	}
+	return chain
+}
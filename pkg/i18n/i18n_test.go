@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCatalogsHaveSameKeys(t *testing.T) {
+	bundle := Default()
+	require.Equal(t, []string{"en", "zh"}, bundle.Locales())
+
+	en := bundle.catalogs["en"]
+	zh := bundle.catalogs["zh"]
+	for key := range en {
+		assert.Contains(t, zh, key, "zh catalog is missing %s", key)
+	}
+	for key := range zh {
+		assert.Contains(t, en, key, "en catalog is missing %s", key)
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	assert.Equal(t, []string{"zh-hant-tw", "zh-hant", "zh", "en"}, Fallbacks("zh_Hant_TW", "en"))
+	assert.Equal(t, []string{"en-us", "en"}, Fallbacks("en-US", "en"))
+	assert.Equal(t, []string{"fr"}, Fallbacks("fr", ""))
+	assert.Equal(t, []string{"en"}, Fallbacks("", "en"))
+}
+
+func TestTranslateFallbackChain(t *testing.T) {
+	bundle := NewBundle("en")
+	bundle.AddMessages("en", map[string]string{"greeting": "Hello, {0}", "farewell": "Goodbye"})
+	bundle.AddMessages("zh", map[string]string{"greeting": "你好，{0}"})
+	bundle.AddMessages("zh-TW", map[string]string{"farewell": "再見"})
+
+	assert.Equal(t, "你好，Ann", bundle.Translate("zh-CN", "greeting", "Ann"))
+	assert.Equal(t, "你好，Ann", bundle.Translate("zh-tw", "greeting", "Ann"))
+	assert.Equal(t, "再見", bundle.Translate("zh-TW", "farewell"))
+	assert.Equal(t, "Goodbye", bundle.Translate("zh", "farewell"))
+	assert.Equal(t, "Hello, Ann", bundle.Translate("fr", "greeting", "Ann"))
+	assert.Equal(t, "missing.key", bundle.Translate("en", "missing.key"))
+}
+
+func TestMessages(t *testing.T) {
+	messages := Default().Messages("validation.tag.password_strength", "password")
+	assert.Equal(t, "password must contain at least one letter and one number", messages["en"])
+	assert.Equal(t, "password必须至少包含一个字母和一个数字", messages["zh"])
+	assert.Empty(t, Default().Messages("missing.key"))
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"catalogs/de.json": {Data: []byte(`{"greeting": "Hallo"}`)},
+		"catalogs/bad.txt": {Data: []byte(`ignored`)},
+	}
+	bundle := NewBundle("en")
+	require.NoError(t, bundle.LoadFS(fsys, "catalogs/*.json"))
+	assert.Equal(t, []string{"de"}, bundle.Locales())
+	assert.Equal(t, "Hallo", bundle.Translate("de-AT", "greeting"))
+
+	fsys["catalogs/fr.json"] = &fstest.MapFile{Data: []byte(`{"greeting": 1}`)}
+	assert.Error(t, bundle.LoadFS(fsys, "catalogs/*.json"))
+}
+
+func TestMatch(t *testing.T) {
+	bundle := Default()
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"zh-CN", "zh"},
+		{"zh-Hant-TW,en;q=0.5", "zh"},
+		{"fr-FR,zh;q=0.8", "zh"},
+		{"en;q=0.4,zh;q=0.9", "zh"},
+		{"zh;q=0,en", "en"},
+		{"fr", "en"},
+		{"*", "en"},
+		{"zh;q=abc,en", "en"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, bundle.Match(tt.header), tt.header)
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	_, ok := Locale(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, "Invalid credentials", T(context.Background(), "auth.invalid_credentials"))
+
+	ctx := WithLocale(context.Background(), "zh")
+	locale, ok := Locale(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "zh", locale)
+	assert.Equal(t, "邮箱或密码错误", T(ctx, "auth.invalid_credentials"))
+}
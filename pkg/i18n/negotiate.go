@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageRange Accept-Language 中的一项
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// Match 根据 Accept-Language 请求头选择支持的语言，按权重从高到低依次尝试，
+// 权重相同时保持请求头中的顺序；语言本身不受支持时尝试其更通用的语言（zh-CN → zh），
+// 都不受支持或请求头为空时返回默认语言
+func (b *Bundle) Match(acceptLanguage string) string {
+	for _, r := range parseAcceptLanguage(acceptLanguage) {
+		if r.tag == "*" {
+			return b.defaultLocale
+		}
+		if locale, ok := b.Supported(r.tag); ok {
+			return locale
+		}
+	}
+	return b.defaultLocale
+}
+
+// parseAcceptLanguage 解析 Accept-Language，忽略权重为 0 和无法解析的项，按权重从高到低排序
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				quality = 0
+			} else {
+				quality = q
+			}
+		}
+		if quality == 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	return ranges
+}
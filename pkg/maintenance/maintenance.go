@@ -0,0 +1,168 @@
+// Package maintenance 维护模式
+//
+// 维护期间除放行路径外的请求返回 503 MAINTENANCE_MODE，持有旁路令牌的运维人员仍可访问。
+// 管理员通过接口开启的维护保存在 Store 中（进程内存储或 Redis），Redis 存储供多实例共享；
+// 配置中开启的维护只对读取该配置的实例生效，与管理员开启的维护同时存在时以配置为准。
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-server/pkg/health"
+)
+
+// 维护的来源
+const (
+	SourceAdmin  = "admin"
+	SourceConfig = "config"
+)
+
+// DefaultRefreshInterval 本地缓存维护状态的默认时间，其他实例的开关最多延迟这么久生效
+const DefaultRefreshInterval = 5 * time.Second
+
+// State 维护状态
+type State struct {
+	Enabled   bool       `json:"enabled" example:"true"`                                              // 是否处于维护中
+	Message   string     `json:"message,omitempty" example:"数据库升级中，预计 30 分钟"`                         // 展示给用户的说明
+	Source    string     `json:"source,omitempty" example:"admin"`                                    // admin 由管理员开启；config 由配置开启
+	StartedAt *time.Time `json:"started_at,omitempty"`                                                // 开始时间
+	EndsAt    *time.Time `json:"ends_at,omitempty"`                                                   // 预计结束时间，为空表示未知
+	UpdatedBy string     `json:"updated_by,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // 最后修改状态的管理员ID
+}
+
+// Remaining 返回距预计结束的时间，未知或已过预计结束时间时返回 0
+func (s *State) Remaining(now time.Time) time.Duration {
+	if s.EndsAt == nil {
+		return 0
+	}
+	return max(s.EndsAt.Sub(now), 0)
+}
+
+// Mode 维护模式开关，在本地缓存管理员开启的维护状态，写操作会立即刷新本实例的缓存
+type Mode struct {
+	store           Store
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu       sync.RWMutex
+	forced   *State // 配置开启的维护
+	stored   State
+	loadedAt time.Time
+}
+
+// New 创建维护模式开关，refreshInterval <=0 时使用 DefaultRefreshInterval
+func New(store Store, refreshInterval time.Duration) *Mode {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	return &Mode{
+		store:           store,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// SetForced 设置配置开启的维护，nil 表示配置没有开启维护
+func (m *Mode) SetForced(state *State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state != nil {
+		forced := *state
+		forced.Enabled = true
+		forced.Source = SourceConfig
+		state = &forced
+	}
+	m.forced = state
+}
+
+// Forced 返回配置开启的维护，配置没有开启维护时返回 nil
+func (m *Mode) Forced() *State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.forced == nil {
+		return nil
+	}
+	forced := *m.forced
+	return &forced
+}
+
+// Current 返回当前生效的维护状态
+// 读取存储失败时沿用上一次读到的状态并返回错误，调用方可以据此放行请求或记录日志
+func (m *Mode) Current(ctx context.Context) (State, error) {
+	m.mu.RLock()
+	forced, stored, fresh := m.forced, m.stored, m.now().Sub(m.loadedAt) < m.refreshInterval
+	m.mu.RUnlock()
+
+	if forced != nil {
+		return *forced, nil
+	}
+	if fresh {
+		return stored, nil
+	}
+
+	state, err := m.store.Load(ctx)
+	if err != nil {
+		return stored, err
+	}
+	m.mu.Lock()
+	m.stored, m.loadedAt = state, m.now()
+	m.mu.Unlock()
+	return state, nil
+}
+
+// Enable 开启维护，duration >0 时记录预计结束时间；已在维护中时保留开始时间
+func (m *Mode) Enable(ctx context.Context, message string, duration time.Duration, by string) (State, error) {
+	now := m.now().UTC()
+	state := State{
+		Enabled:   true,
+		Message:   message,
+		Source:    SourceAdmin,
+		StartedAt: &now,
+		UpdatedBy: by,
+	}
+	if current, err := m.store.Load(ctx); err == nil && current.Enabled && current.StartedAt != nil {
+		state.StartedAt = current.StartedAt
+	}
+	if duration > 0 {
+		endsAt := now.Add(duration)
+		state.EndsAt = &endsAt
+	}
+	return m.save(ctx, state)
+}
+
+// Disable 结束管理员开启的维护，配置开启的维护需要修改配置才能结束
+func (m *Mode) Disable(ctx context.Context, by string) (State, error) {
+	return m.save(ctx, State{UpdatedBy: by})
+}
+
+// save 保存状态并刷新本地缓存，返回当前生效的状态
+func (m *Mode) save(ctx context.Context, state State) (State, error) {
+	if err := m.store.Save(ctx, state); err != nil {
+		return State{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stored, m.loadedAt = state, m.now()
+	if m.forced != nil {
+		return *m.forced, nil
+	}
+	return state, nil
+}
+
+// Countdown 返回健康检查中的维护倒计时，不在维护中时返回 nil，可作为 health.MaintenanceFunc 使用
+func (m *Mode) Countdown(ctx context.Context) *health.Maintenance {
+	state, _ := m.Current(ctx)
+	if !state.Enabled {
+		return nil
+	}
+	return &health.Maintenance{
+		Message:          state.Message,
+		StartedAt:        state.StartedAt,
+		EndsAt:           state.EndsAt,
+		RemainingSeconds: int64(state.Remaining(m.now()).Seconds()),
+	}
+}
@@ -0,0 +1,131 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore 读取总是失败的存储
+type failingStore struct{ MemoryStore }
+
+func (s *failingStore) Load(ctx context.Context) (State, error) {
+	return State{}, errors.New("store unavailable")
+}
+
+func TestModeEnableDisable(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := New(NewMemoryStore(), time.Minute)
+	m.now = func() time.Time { return now }
+
+	state, err := m.Current(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+
+	state, err = m.Enable(ctx, "数据库升级", 30*time.Minute, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, SourceAdmin, state.Source)
+	assert.Equal(t, now, *state.StartedAt)
+	assert.Equal(t, 30*time.Minute, state.Remaining(now))
+
+	// 延长维护时保留开始时间
+	now = now.Add(10 * time.Minute)
+	state, err = m.Enable(ctx, "数据库升级", time.Hour, "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-10*time.Minute), *state.StartedAt)
+	assert.Equal(t, time.Hour, state.Remaining(now))
+	assert.Zero(t, state.Remaining(now.Add(2*time.Hour)), "超过预计结束时间后剩余时间为 0")
+	assert.Equal(t, int64(3600), m.Countdown(ctx).RemainingSeconds)
+
+	state, err = m.Disable(ctx, "admin-1")
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, "admin-1", state.UpdatedBy)
+	assert.Nil(t, m.Countdown(ctx))
+}
+
+func TestModeRefreshesFromStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	m := New(store, time.Minute)
+	m.now = func() time.Time { return now }
+
+	_, err := m.Current(ctx)
+	require.NoError(t, err)
+
+	// 其他实例开启的维护在本地缓存过期后生效
+	require.NoError(t, store.Save(ctx, State{Enabled: true, Source: SourceAdmin}))
+	state, err := m.Current(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+
+	now = now.Add(time.Minute)
+	state, err = m.Current(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+}
+
+func TestModeForced(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore(), time.Minute)
+
+	m.SetForced(&State{Message: "迁移中"})
+	state, err := m.Current(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, SourceConfig, state.Source)
+
+	// 配置开启的维护不能通过接口结束
+	state, err = m.Disable(ctx, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+
+	m.SetForced(nil)
+	state, err = m.Current(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+}
+
+func TestModeKeepsLastStateOnStoreError(t *testing.T) {
+	m := New(&failingStore{}, time.Nanosecond)
+	m.stored = State{Enabled: true}
+
+	state, err := m.Current(context.Background())
+	assert.Error(t, err)
+	assert.True(t, state.Enabled)
+}
+
+func TestRedisStore(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test:maintenance:%d", time.Now().UnixNano())
+	defer client.Del(context.Background(), prefix+":state")
+	store := NewRedisStore(client, prefix)
+
+	state, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+
+	endsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, store.Save(ctx, State{Enabled: true, Message: "升级", Source: SourceAdmin, EndsAt: &endsAt}))
+	state, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "升级", state.Message)
+	assert.True(t, endsAt.Equal(*state.EndsAt))
+}
@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store 管理员开启的维护状态的存储
+type Store interface {
+	// Load 返回保存的状态，从未保存时返回零值
+	Load(ctx context.Context) (State, error)
+	// Save 保存状态
+	Save(ctx context.Context, state State) error
+}
+
+// MemoryStore 进程内存储，维护状态只对当前实例有效，重启后清空
+type MemoryStore struct {
+	mu    sync.Mutex
+	state State
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load 返回保存的状态
+func (s *MemoryStore) Load(ctx context.Context) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+// Save 保存状态
+func (s *MemoryStore) Save(ctx context.Context, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
+
+// RedisStore 将维护状态以 JSON 保存在 Redis 的一个键中，供多实例共享
+type RedisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisStore 创建 Redis 存储，prefix 为键前缀，状态保存在 <prefix>:state
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		key:    prefix + ":state",
+	}
+}
+
+// Load 返回保存的状态
+func (s *RedisStore) Load(ctx context.Context) (State, error) {
+	data, err := s.client.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("failed to load maintenance state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("failed to decode maintenance state: %w", err)
+	}
+	return state, nil
+}
+
+// Save 保存状态，不设置过期时间，维护需要显式结束
+func (s *RedisStore) Save(ctx context.Context, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save maintenance state: %w", err)
+	}
+	return nil
+}
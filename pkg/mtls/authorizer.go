@@ -0,0 +1,63 @@
+package mtls
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ServiceAccount 内部服务账户，证书 SAN 与 Identities 中任一项相同的调用方使用该账户
+type ServiceAccount struct {
+	Name        string   `json:"name"`
+	Identities  []string `json:"-"`
+	Permissions []string `json:"permissions"` // 如 users:read；users:* 包含 users 的全部操作，* 包含全部权限
+}
+
+// Can 判断账户是否拥有权限
+func (a *ServiceAccount) Can(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range a.Permissions {
+		if granted == "*" || granted == permission || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer 把客户端证书身份映射到服务账户
+type Authorizer struct {
+	accounts   []*ServiceAccount
+	identities map[string]*ServiceAccount
+}
+
+// NewAuthorizer 创建服务账户映射，同一身份属于多个账户时返回错误
+func NewAuthorizer(accounts []ServiceAccount) (*Authorizer, error) {
+	a := &Authorizer{identities: make(map[string]*ServiceAccount)}
+	for _, account := range accounts {
+		account.Identities = slices.Clone(account.Identities)
+		account.Permissions = slices.Clone(account.Permissions)
+		for _, identity := range account.Identities {
+			if existing, ok := a.identities[identity]; ok {
+				return nil, fmt.Errorf("identity %q is mapped to both %s and %s", identity, existing.Name, account.Name)
+			}
+			a.identities[identity] = &account
+		}
+		a.accounts = append(a.accounts, &account)
+	}
+	return a, nil
+}
+
+// Authorize 返回身份对应的服务账户，按 URI、DNS、邮箱的顺序匹配第一个已映射的 SAN
+func (a *Authorizer) Authorize(identity Identity) (*ServiceAccount, bool) {
+	for _, name := range identity.Names() {
+		if account, ok := a.identities[name]; ok {
+			return account, true
+		}
+	}
+	return nil, false
+}
+
+// Accounts 返回全部服务账户
+func (a *Authorizer) Accounts() []*ServiceAccount {
+	return slices.Clone(a.accounts)
+}
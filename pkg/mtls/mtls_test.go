@@ -0,0 +1,203 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA 测试用的证书颁发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue 签发证书，template 中需设置 SAN 和用途
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writePEM 把证书和私钥写入 PEM 文件，返回证书和私钥路径
+func writePEM(t *testing.T, dir, name string, cert tls.Certificate) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestIdentityFromCertificate(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://cluster.local/ns/billing/sa/worker")
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "billing"},
+		URIs:           []*url.URL{spiffe},
+		DNSNames:       []string{"billing.internal"},
+		EmailAddresses: []string{"billing@example.com"},
+	}
+
+	identity := IdentityFromCertificate(cert)
+	assert.Equal(t, "billing", identity.CommonName)
+	assert.Equal(t, []string{
+		"spiffe://cluster.local/ns/billing/sa/worker",
+		"billing.internal",
+		"billing@example.com",
+	}, identity.Names())
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer, err := NewAuthorizer([]ServiceAccount{
+		{Name: "billing", Identities: []string{"spiffe://cluster.local/ns/billing/sa/worker"}, Permissions: []string{"users:read"}},
+		{Name: "ops", Identities: []string{"ops.internal"}, Permissions: []string{"*"}},
+	})
+	require.NoError(t, err)
+
+	account, ok := authorizer.Authorize(Identity{DNSNames: []string{"unknown.internal"}, URIs: []string{"spiffe://cluster.local/ns/billing/sa/worker"}})
+	require.True(t, ok)
+	assert.Equal(t, "billing", account.Name)
+	assert.True(t, account.Can("users:read"))
+	assert.False(t, account.Can("users:write"))
+
+	account, ok = authorizer.Authorize(Identity{DNSNames: []string{"ops.internal"}})
+	require.True(t, ok)
+	assert.True(t, account.Can("users:write"))
+
+	// CN 不参与授权
+	_, ok = authorizer.Authorize(Identity{CommonName: "ops.internal"})
+	assert.False(t, ok)
+
+	_, err = NewAuthorizer([]ServiceAccount{
+		{Name: "a", Identities: []string{"shared.internal"}},
+		{Name: "b", Identities: []string{"shared.internal"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestServiceAccountCan(t *testing.T) {
+	account := &ServiceAccount{Permissions: []string{"users:*", "metrics:read"}}
+	assert.True(t, account.Can("users:read"))
+	assert.True(t, account.Can("users:delete"))
+	assert.True(t, account.Can("metrics:read"))
+	assert.False(t, account.Can("metrics:write"))
+	assert.False(t, account.Can("usersx:read"))
+}
+
+func TestHandler_ExtractsVerifiedIdentity(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+
+	serverCert := ca.issue(t, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	certFile, keyFile := writePEM(t, dir, "server", serverCert)
+
+	tlsConfig, err := NewTLSConfig(ServerConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := IdentityFromContext(r.Context())
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(identity)
+	})))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing"},
+		DNSNames:    []string{"billing.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var identity Identity
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&identity))
+	assert.Equal(t, "billing", identity.CommonName)
+	assert.Equal(t, []string{"billing.internal"}, identity.DNSNames)
+
+	// 没有客户端证书时握手失败
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if resp, err := anonymous.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatalf("expected handshake to fail without a client certificate, got status %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_PlainRequestHasNoIdentity(t *testing.T) {
+	var found bool
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, found = IdentityFromContext(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.False(t, found)
+}
+
+func TestNewTLSConfig_InvalidFiles(t *testing.T) {
+	_, err := NewTLSConfig(ServerConfig{CertFile: "missing.crt", KeyFile: "missing.key", ClientCAFile: "missing.crt"})
+	assert.Error(t, err)
+}
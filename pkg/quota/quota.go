@@ -0,0 +1,212 @@
+// Package quota 长周期用量配额，如每个用户每月 10000 次 API 调用
+//
+// 与限流（每分钟的请求数）不同，配额按自然月（UTC）累计，每月 1 日零点重置。每种配额有软限制和硬限制：
+// 达到软限制时只发出提醒，超过硬限制时拒绝。计数保存在 Store 中（进程内存储或 Redis），Redis 存储供多实例共享。
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// APICalls 按请求计数的配额名称，由配额中间件消耗
+const APICalls = "api_calls"
+
+// ErrUnknownQuota 配额没有定义
+var ErrUnknownQuota = errors.New("quota: unknown quota")
+
+// Limit 一种配额的限制
+type Limit struct {
+	Name string // 配额名称，如 api_calls
+	Soft int64  // 软限制，用量达到该值时提醒；0 表示没有软限制
+	Hard int64  // 硬限制，用量超过该值时拒绝；0 表示不限制
+}
+
+// Usage 主体在当前周期内的用量
+type Usage struct {
+	Quota       string    `json:"quota" example:"api_calls"`   // 配额名称
+	Used        int64     `json:"used" example:"8421"`         // 本周期已用量
+	Soft        int64     `json:"soft" example:"8000"`         // 软限制，0 表示没有软限制
+	Hard        int64     `json:"hard" example:"10000"`        // 硬限制，0 表示不限制
+	Remaining   int64     `json:"remaining" example:"1579"`    // 剩余用量，不限制时为 -1
+	SoftReached bool      `json:"soft_reached" example:"true"` // 是否已达到软限制
+	PeriodStart time.Time `json:"period_start"`                // 本周期开始时间
+	ResetAt     time.Time `json:"reset_at"`                    // 用量重置时间，即下一周期开始时间
+}
+
+// ExceededError 用量超过硬限制
+type ExceededError struct {
+	Usage Usage
+}
+
+// Error 返回配额名称和硬限制
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota: %s exceeded (limit %d, resets at %s)", e.Usage.Quota, e.Usage.Hard, e.Usage.ResetAt.Format(time.RFC3339))
+}
+
+// Config 配额管理器参数
+type Config struct {
+	// OnSoftLimit 主体的用量在本次消耗中达到软限制时调用，每个周期最多调用一次，用于记录日志或通知用户
+	OnSoftLimit func(subject string, usage Usage)
+	// Limits 返回主体自己的限制，覆盖同名的默认限制，如按订阅套餐设置配额；返回 nil 时使用默认限制
+	Limits func(ctx context.Context, subject string) []Limit
+}
+
+// Manager 配额管理器
+type Manager struct {
+	store  Store
+	limits map[string]Limit
+	config Config
+	now    func() time.Time
+}
+
+// New 创建配额管理器，同名的限制以后出现的为准
+func New(store Store, limits []Limit, config Config) *Manager {
+	m := &Manager{
+		store:  store,
+		limits: make(map[string]Limit, len(limits)),
+		config: config,
+		now:    time.Now,
+	}
+	for _, limit := range limits {
+		m.limits[limit.Name] = limit
+	}
+	return m
+}
+
+// Limits 返回默认的配额，按名称排序
+func (m *Manager) Limits() []Limit {
+	return sortedLimits(m.limits)
+}
+
+// Has 判断默认的配额中是否定义了该配额
+func (m *Manager) Has(name string) bool {
+	_, ok := m.limits[name]
+	return ok
+}
+
+// limitsFor 返回主体适用的配额，主体自己的限制覆盖同名的默认限制
+func (m *Manager) limitsFor(ctx context.Context, subject string) map[string]Limit {
+	if m.config.Limits == nil {
+		return m.limits
+	}
+	overrides := m.config.Limits(ctx, subject)
+	if len(overrides) == 0 {
+		return m.limits
+	}
+
+	limits := make(map[string]Limit, len(m.limits)+len(overrides))
+	for name, limit := range m.limits {
+		limits[name] = limit
+	}
+	for _, limit := range overrides {
+		limits[limit.Name] = limit
+	}
+	return limits
+}
+
+// Consume 为主体消耗 n 个单位的配额，返回消耗后的用量
+// 超过硬限制时不计入本次消耗，返回 *ExceededError
+func (m *Manager) Consume(ctx context.Context, name, subject string, n int64) (Usage, error) {
+	limit, ok := m.limitsFor(ctx, subject)[name]
+	if !ok {
+		return Usage{}, ErrUnknownQuota
+	}
+
+	start, reset := period(m.now())
+	key := counterKey(name, subject, start)
+	// 计数保留到下一周期结束后，重置时间附近的请求不会因时钟偏差读到已删除的计数
+	used, err := m.store.Increment(ctx, key, n, reset.AddDate(0, 0, 1))
+	if err != nil {
+		return Usage{}, err
+	}
+	usage := newUsage(limit, used, start, reset)
+
+	if limit.Hard > 0 && used > limit.Hard {
+		if used, err = m.store.Increment(ctx, key, -n, reset.AddDate(0, 0, 1)); err != nil {
+			return Usage{}, err
+		}
+		usage = newUsage(limit, used, start, reset)
+		return usage, &ExceededError{Usage: usage}
+	}
+	if limit.Soft > 0 && used >= limit.Soft && used-n < limit.Soft && m.config.OnSoftLimit != nil {
+		m.config.OnSoftLimit(subject, usage)
+	}
+	return usage, nil
+}
+
+// Usage 返回主体在当前周期内某种配额的用量
+func (m *Manager) Usage(ctx context.Context, name, subject string) (Usage, error) {
+	limit, ok := m.limitsFor(ctx, subject)[name]
+	if !ok {
+		return Usage{}, ErrUnknownQuota
+	}
+	return m.usage(ctx, limit, subject)
+}
+
+// usage 读取主体在当前周期内的用量
+func (m *Manager) usage(ctx context.Context, limit Limit, subject string) (Usage, error) {
+	start, reset := period(m.now())
+	used, err := m.store.Get(ctx, counterKey(limit.Name, subject, start))
+	if err != nil {
+		return Usage{}, err
+	}
+	return newUsage(limit, used, start, reset), nil
+}
+
+// UsageAll 返回主体在当前周期内所有配额的用量，按配额名称排序
+func (m *Manager) UsageAll(ctx context.Context, subject string) ([]Usage, error) {
+	limits := sortedLimits(m.limitsFor(ctx, subject))
+	usages := make([]Usage, 0, len(limits))
+	for _, limit := range limits {
+		usage, err := m.usage(ctx, limit, subject)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// sortedLimits 返回按名称排序的限制
+func sortedLimits(limits map[string]Limit) []Limit {
+	sorted := make([]Limit, 0, len(limits))
+	for _, limit := range limits {
+		sorted = append(sorted, limit)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// newUsage 根据限制和已用量计算用量
+func newUsage(limit Limit, used int64, start, reset time.Time) Usage {
+	usage := Usage{
+		Quota:       limit.Name,
+		Used:        used,
+		Soft:        limit.Soft,
+		Hard:        limit.Hard,
+		Remaining:   -1,
+		SoftReached: limit.Soft > 0 && used >= limit.Soft,
+		PeriodStart: start,
+		ResetAt:     reset,
+	}
+	if limit.Hard > 0 {
+		usage.Remaining = max(limit.Hard-used, 0)
+	}
+	return usage
+}
+
+// period 返回 now 所在自然月（UTC）的开始时间和下一个月的开始时间
+func period(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// counterKey 返回主体在某周期内的计数键，如 api_calls:user:42:2024-05
+func counterKey(name, subject string, start time.Time) string {
+	return name + ":" + subject + ":" + start.Format("2006-01")
+}
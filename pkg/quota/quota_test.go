@@ -0,0 +1,196 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager 创建使用进程内存储的配额管理器，存储和管理器使用同一个时钟
+func newTestManager(now *time.Time, limits []Limit, config Config) *Manager {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	m := New(store, limits, config)
+	m.now = store.now
+	return m
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	value, err := store.Increment(ctx, "k", 3, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+	value, err = store.Increment(ctx, "k", -1, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+
+	value, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+	value, err = store.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Zero(t, value)
+
+	// 到期的计数不再生效，再次增加时从 0 开始
+	now = now.Add(time.Hour)
+	value, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, value)
+	value, err = store.Increment(ctx, "k", 1, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+}
+
+func TestManagerConsume(t *testing.T) {
+	ctx := context.Background()
+	var warned []Usage
+	now := time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)
+	m := newTestManager(&now, []Limit{{Name: APICalls, Soft: 3, Hard: 4}}, Config{
+		OnSoftLimit: func(subject string, usage Usage) {
+			assert.Equal(t, "user:1", subject)
+			warned = append(warned, usage)
+		},
+	})
+
+	for i := 1; i <= 4; i++ {
+		usage, err := m.Consume(ctx, APICalls, "user:1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i), usage.Used)
+		assert.Equal(t, int64(4-i), usage.Remaining)
+		assert.Equal(t, i >= 3, usage.SoftReached)
+	}
+	require.Len(t, warned, 1, "软限制每个周期只提醒一次")
+	assert.Equal(t, int64(3), warned[0].Used)
+
+	// 超过硬限制时拒绝，且不计入用量
+	usage, err := m.Consume(ctx, APICalls, "user:1", 1)
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, int64(4), usage.Used)
+	assert.Zero(t, usage.Remaining)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), exceeded.Usage.ResetAt)
+
+	// 其他主体不受影响
+	usage, err = m.Consume(ctx, APICalls, "user:2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Used)
+
+	_, err = m.Consume(ctx, "storage", "user:1", 1)
+	assert.ErrorIs(t, err, ErrUnknownQuota)
+}
+
+func TestManagerRollover(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 31, 23, 59, 0, 0, time.UTC)
+	m := newTestManager(&now, []Limit{{Name: APICalls, Hard: 1}}, Config{})
+
+	_, err := m.Consume(ctx, APICalls, "user:1", 1)
+	require.NoError(t, err)
+	_, err = m.Consume(ctx, APICalls, "user:1", 1)
+	require.Error(t, err)
+
+	// 下个月的用量重新计算
+	now = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	usage, err := m.Usage(ctx, APICalls, "user:1")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Used)
+	assert.Equal(t, now, usage.PeriodStart)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), usage.ResetAt)
+	_, err = m.Consume(ctx, APICalls, "user:1", 1)
+	assert.NoError(t, err)
+}
+
+func TestManagerUsageAll(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore(), []Limit{{Name: "storage_mb"}, {Name: APICalls, Soft: 8, Hard: 10}}, Config{})
+
+	_, err := m.Consume(ctx, "storage_mb", "user:1", 250)
+	require.NoError(t, err)
+
+	usages, err := m.UsageAll(ctx, "user:1")
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	assert.Equal(t, APICalls, usages[0].Quota)
+	assert.Equal(t, int64(10), usages[0].Remaining)
+	assert.Equal(t, "storage_mb", usages[1].Quota)
+	assert.Equal(t, int64(250), usages[1].Used)
+	assert.Equal(t, int64(-1), usages[1].Remaining, "没有硬限制时剩余用量为 -1")
+}
+
+func TestManagerSubjectLimits(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore(), []Limit{{Name: APICalls, Hard: 1}}, Config{
+		Limits: func(ctx context.Context, subject string) []Limit {
+			if subject == "user:pro" {
+				return []Limit{{Name: APICalls, Hard: 3}, {Name: "exports", Hard: 5}}
+			}
+			return nil
+		},
+	})
+
+	_, err := m.Consume(ctx, APICalls, "user:free", 1)
+	require.NoError(t, err)
+	_, err = m.Consume(ctx, APICalls, "user:free", 1)
+	var exceeded *ExceededError
+	assert.ErrorAs(t, err, &exceeded)
+	_, err = m.Consume(ctx, "exports", "user:free", 1)
+	assert.ErrorIs(t, err, ErrUnknownQuota)
+
+	for range 3 {
+		_, err = m.Consume(ctx, APICalls, "user:pro", 1)
+		require.NoError(t, err)
+	}
+	usages, err := m.UsageAll(ctx, "user:pro")
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	assert.Equal(t, int64(3), usages[0].Hard)
+	assert.Equal(t, int64(0), usages[0].Remaining)
+	assert.Equal(t, "exports", usages[1].Quota)
+}
+
+func TestRedisStore(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available for testing: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test:quota:%d", time.Now().UnixNano())
+	defer func() {
+		keys, _ := client.Keys(context.Background(), prefix+":*").Result()
+		if len(keys) > 0 {
+			client.Del(context.Background(), keys...)
+		}
+	}()
+
+	store := NewRedisStore(client, prefix)
+	expireAt := time.Now().Add(time.Hour)
+	value, err := store.Increment(ctx, "k", 5, expireAt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+	value, err = store.Increment(ctx, "k", -2, expireAt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+
+	value, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+	ttl, err := client.TTL(ctx, prefix+":k").Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, 59*time.Minute)
+
+	value, err = store.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Zero(t, value)
+}
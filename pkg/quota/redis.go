@@ -0,0 +1,58 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 将配额计数保存在 Redis 中，供多实例共享
+//
+// 键布局：
+//
+//	<prefix>:<quota>:<subject>:<yyyy-mm>    计数，到期时自动删除
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 存储，prefix 为键前缀，如 quota
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Increment 增加计数，INCRBY 和 EXPIREAT 在同一事务中执行
+func (s *RedisStore) Increment(ctx context.Context, key string, n int64, expireAt time.Time) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, s.key(key), n)
+		pipe.ExpireAt(ctx, s.key(key), expireAt)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment quota %s: %w", key, err)
+	}
+	return incr.Val(), nil
+}
+
+// Get 返回计数
+func (s *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	value, err := s.client.Get(ctx, s.key(key)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get quota %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// key 返回带前缀的键
+func (s *RedisStore) key(key string) string {
+	return s.prefix + ":" + key
+}
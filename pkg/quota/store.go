@@ -0,0 +1,75 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store 配额计数的存储
+type Store interface {
+	// Increment 把计数增加 n（可以为负数）并返回增加后的值，计数不存在时从 0 开始；计数在 expireAt 之后删除
+	Increment(ctx context.Context, key string, n int64, expireAt time.Time) (int64, error)
+
+	// Get 返回计数，不存在或已删除时返回 0
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// counter 进程内存储的计数
+type counter struct {
+	value    int64
+	expireAt time.Time
+}
+
+// MemoryStore 进程内存储，计数只对当前实例有效，重启后清空
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+	now      func() time.Time
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: make(map[string]*counter),
+		now:      time.Now,
+	}
+}
+
+// Increment 增加计数
+func (s *MemoryStore) Increment(ctx context.Context, key string, n int64, expireAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.value += n
+	c.expireAt = expireAt
+	return c.value, nil
+}
+
+// Get 返回计数
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !s.now().Before(c.expireAt) {
+		return 0, nil
+	}
+	return c.value, nil
+}
+
+// pruneLocked 删除已到期的计数
+func (s *MemoryStore) pruneLocked() {
+	now := s.now()
+	for key, c := range s.counters {
+		if !now.Before(c.expireAt) {
+			delete(s.counters, key)
+		}
+	}
+}
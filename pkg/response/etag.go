@@ -0,0 +1,92 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DataContextKey 用于在Gin上下文中记录成功响应的数据，供 ETag 等中间件读取
+	DataContextKey = "response_data"
+
+	// ETagHeader 实体标签响应头
+	ETagHeader = "ETag"
+
+	// IfNoneMatchHeader 条件读取请求头，匹配时返回 304
+	IfNoneMatchHeader = "If-None-Match"
+
+	// IfMatchHeader 条件写入请求头，不匹配时返回 412
+	IfMatchHeader = "If-Match"
+)
+
+// DataFromContext 返回当前请求通过 Success 发送的响应数据
+func DataFromContext(c *gin.Context) (interface{}, bool) {
+	return c.Get(DataContextKey)
+}
+
+// ComputeETag 根据响应数据计算弱实体标签
+// 统一响应结构中的时间戳和关联ID每次都不同，因此只对数据部分计算哈希，数据序列化失败时返回空字符串
+func ComputeETag(data interface{}) string {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	return "W/" + StrongETag(body)
+}
+
+// StrongETag 根据响应体字节计算强实体标签
+func StrongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// SetETag 设置 ETag 响应头，tag 为空时不设置
+func SetETag(c *gin.Context, tag string) {
+	if tag != "" {
+		c.Header(ETagHeader, tag)
+	}
+}
+
+// ETagMatches 判断条件请求头中的实体标签列表是否包含 tag，使用弱比较，* 匹配任意标签
+func ETagMatches(header, tag string) bool {
+	if header == "" || tag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	opaque := strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIfNoneMatch 设置 ETag 响应头，If-None-Match 匹配时发送 304 并返回 true，处理器应直接返回
+func CheckIfNoneMatch(c *gin.Context, tag string) bool {
+	SetETag(c, tag)
+	if !ETagMatches(c.GetHeader(IfNoneMatchHeader), tag) {
+		return false
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// CheckIfMatch 校验写请求的 If-Match 请求头，未携带该请求头或匹配时返回 true，
+// 否则发送 412 并返回 false。资源的 ETag 均由数据计算得出，因此这里同样使用弱比较
+func CheckIfMatch(c *gin.Context, currentTag string) bool {
+	header := c.GetHeader(IfMatchHeader)
+	if header == "" || ETagMatches(header, currentTag) {
+		return true
+	}
+	PreconditionFailedError(c, currentTag)
+	return false
+}
@@ -0,0 +1,146 @@
+package response
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-server/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 错误响应格式
+const (
+	ErrorFormatEnvelope = "envelope" // 统一响应结构（默认）
+	ErrorFormatProblem  = "problem"  // RFC 7807 application/problem+json
+)
+
+// ProblemContentType RFC 7807 错误响应的媒体类型
+const ProblemContentType = "application/problem+json"
+
+// DefaultProblemTypeBaseURL 默认的问题类型URI前缀
+const DefaultProblemTypeBaseURL = "/problems/"
+
+// ProblemDetails RFC 7807 错误响应结构，code、correlation_id 等为扩展成员
+type ProblemDetails struct {
+	Type          string                 `json:"type"`                     // 问题类型URI，每个错误代码对应一个
+	Title         string                 `json:"title"`                    // 问题类型的简短描述
+	Status        int                    `json:"status"`                   // HTTP状态码
+	Detail        string                 `json:"detail,omitempty"`         // 本次错误的具体说明
+	Instance      string                 `json:"instance,omitempty"`       // 发生错误的请求路径
+	Code          errors.ErrorCode       `json:"code"`                     // 错误代码
+	Details       map[string]interface{} `json:"details,omitempty"`        // 详细错误信息
+	InternalError string                 `json:"internal_error,omitempty"` // 内部错误详情（仅开发环境）
+	CorrelationID string                 `json:"correlation_id,omitempty"` // 关联ID，用于请求追踪
+	Timestamp     time.Time              `json:"timestamp"`                // 响应时间戳
+}
+
+// errorFormatSettings 错误响应格式设置
+type errorFormatSettings struct {
+	format      string
+	typeBaseURL string
+}
+
+var currentErrorFormat atomic.Pointer[errorFormatSettings]
+
+// ConfigureErrorFormat 设置默认的错误响应格式和问题类型URI前缀
+// format 为 envelope 时，请求头 Accept 包含 application/problem+json 的客户端仍会收到 RFC 7807 格式的响应
+func ConfigureErrorFormat(format string, typeBaseURL string) {
+	if format != ErrorFormatProblem {
+		format = ErrorFormatEnvelope
+	}
+	if typeBaseURL == "" {
+		typeBaseURL = DefaultProblemTypeBaseURL
+	}
+	currentErrorFormat.Store(&errorFormatSettings{format: format, typeBaseURL: typeBaseURL})
+}
+
+// errorFormat 返回当前的错误响应格式设置
+func errorFormat() *errorFormatSettings {
+	if settings := currentErrorFormat.Load(); settings != nil {
+		return settings
+	}
+	return &errorFormatSettings{format: ErrorFormatEnvelope, typeBaseURL: DefaultProblemTypeBaseURL}
+}
+
+// ProblemTypeURI 返回错误代码对应的问题类型URI，如 VALIDATION_ERROR 对应 /problems/validation-error
+func ProblemTypeURI(code errors.ErrorCode) string {
+	return errorFormat().typeBaseURL + code.Slug()
+}
+
+// ProblemTypeBaseURL 返回当前的问题类型URI前缀
+func ProblemTypeBaseURL() string {
+	return errorFormat().typeBaseURL
+}
+
+// WantsProblem 判断当前请求是否应使用 RFC 7807 格式返回错误
+func WantsProblem(c *gin.Context) bool {
+	if errorFormat().format == ErrorFormatProblem {
+		return true
+	}
+	if c.Request == nil {
+		return false
+	}
+
+	for _, accept := range c.Request.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == ProblemContentType && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewProblemDetails 根据AppError构建 RFC 7807 错误响应
+func NewProblemDetails(c *gin.Context, appError *errors.AppError) *ProblemDetails {
+	detail := appError.Message
+	if appError.UserMessage != "" {
+		detail = appError.UserMessage
+	}
+
+	problem := &ProblemDetails{
+		Type:          ProblemTypeURI(appError.Code),
+		Title:         http.StatusText(appError.StatusCode),
+		Status:        appError.StatusCode,
+		Detail:        detail,
+		Code:          appError.Code,
+		Details:       appError.Details,
+		InternalError: getInternalErrorMessage(appError),
+		CorrelationID: appError.CorrelationID,
+		Timestamp:     time.Now().UTC(),
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+	return problem
+}
+
+// Problem 以 application/problem+json 格式发送错误响应
+func Problem(c *gin.Context, problem *ProblemDetails) {
+	c.Render(problem.Status, problemRender{problem: problem})
+}
+
+// problemRender 使用 RFC 7807 媒体类型输出JSON
+type problemRender struct {
+	problem *ProblemDetails
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	body, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ProblemContentType)
+}
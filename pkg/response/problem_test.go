@@ -0,0 +1,114 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "go-server/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProblemTestContext(accept string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/42", nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	return c, w
+}
+
+func TestProblemTypeURI(t *testing.T) {
+	t.Cleanup(func() { ConfigureErrorFormat(ErrorFormatEnvelope, "") })
+
+	assert.Equal(t, "/problems/validation-error", ProblemTypeURI(apperrors.ErrCodeValidation))
+
+	ConfigureErrorFormat(ErrorFormatEnvelope, "https://docs.example.com/errors/")
+	assert.Equal(t, "https://docs.example.com/errors/rate-limit-exceeded", ProblemTypeURI(apperrors.ErrCodeRateLimitExceeded))
+}
+
+func TestWantsProblem(t *testing.T) {
+	t.Cleanup(func() { ConfigureErrorFormat(ErrorFormatEnvelope, "") })
+
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json;q=0.9", true},
+		{"application/problem+json;q=0", false},
+	}
+	for _, tt := range tests {
+		c, _ := newProblemTestContext(tt.accept)
+		assert.Equal(t, tt.expected, WantsProblem(c), tt.accept)
+	}
+
+	// 配置为 problem 时无需协商
+	ConfigureErrorFormat(ErrorFormatProblem, "")
+	c, _ := newProblemTestContext("application/json")
+	assert.True(t, WantsProblem(c))
+}
+
+func TestErrorWithAppErrorProblemNegotiation(t *testing.T) {
+	c, w := newProblemTestContext("application/problem+json")
+
+	NotFoundError(c, "User", "42")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/problems/not-found", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "/api/v1/users/42", problem.Instance)
+	assert.Equal(t, apperrors.ErrCodeNotFound, problem.Code)
+	assert.NotEmpty(t, problem.Detail)
+	assert.NotEmpty(t, problem.CorrelationID)
+	assert.Equal(t, "User", problem.Details["resource_type"])
+
+	// 错误上报仍可读取原始错误
+	appErr, ok := AppErrorFromContext(c)
+	require.True(t, ok)
+	assert.Equal(t, apperrors.ErrCodeNotFound, appErr.Code)
+}
+
+func TestErrorWithAppErrorProblemConfigured(t *testing.T) {
+	ConfigureErrorFormat(ErrorFormatProblem, "")
+	t.Cleanup(func() { ConfigureErrorFormat(ErrorFormatEnvelope, "") })
+
+	c, w := newProblemTestContext("")
+	ValidationError(c, "输入验证失败", apperrors.ErrorDetails{Field: "email", Message: "邮箱格式不正确"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "/problems/validation-error", body["type"])
+	assert.NotContains(t, body, "success")
+}
+
+func TestErrorWithAppErrorEnvelopeByDefault(t *testing.T) {
+	c, w := newProblemTestContext("application/json")
+
+	NotFoundError(c, "User", "42")
+
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var response Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Success)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, apperrors.ErrCodeNotFound, response.Error.Code)
+}
@@ -2,6 +2,7 @@ package response
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"go-server/pkg/errors"
@@ -9,6 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AppErrorContextKey 用于在Gin上下文中记录已发送的错误响应，供错误上报等中间件读取
+const AppErrorContextKey = "app_error"
+
 // Response 统一响应结构
 type Response struct {
 	Success       bool           `json:"success"`                  // 请求是否成功
@@ -38,6 +42,7 @@ func Success(c *gin.Context, statusCode int, message string, data interface{}) {
 		CorrelationID: correlationID,
 		Timestamp:     time.Now().UTC(),
 	}
+	c.Set(DataContextKey, data)
 	c.JSON(statusCode, response)
 }
 
@@ -93,6 +98,13 @@ func ErrorWithAppError(c *gin.Context, appError *errors.AppError) {
 	if appError.CorrelationID == "" {
 		appError.CorrelationID = correlationID
 	}
+	c.Set(AppErrorContextKey, appError)
+	setRetryAfter(c, appError)
+
+	if WantsProblem(c) {
+		Problem(c, NewProblemDetails(c, appError))
+		return
+	}
 
 	response := Response{
 		Success: false,
@@ -111,6 +123,16 @@ func ErrorWithAppError(c *gin.Context, appError *errors.AppError) {
 	c.JSON(appError.StatusCode, response)
 }
 
+// setRetryAfter 为可重试且带有 retry_after（秒）详情的错误设置 Retry-After 头，已设置时不覆盖
+func setRetryAfter(c *gin.Context, appError *errors.AppError) {
+	if !appError.Retryable || c.Writer.Header().Get("Retry-After") != "" {
+		return
+	}
+	if seconds, ok := appError.Details["retry_after"].(int); ok && seconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+}
+
 // ValidationError 发送验证错误响应
 func ValidationError(c *gin.Context, message string, fieldDetails ...errors.ErrorDetails) {
 	appError := errors.NewValidationError(message, fieldDetails...)
@@ -147,15 +169,22 @@ func RateLimitError(c *gin.Context, limit int, windowSeconds int) {
 	ErrorWithAppError(c, appError)
 }
 
-// InternalServerErrorWithCause 发送带原因的内部服务器错误响应
+// InternalServerErrorWithCause 发送带原因的内部服务器错误响应，原因是暂时性错误时按 DatabaseError 返回 503
 func InternalServerErrorWithCause(c *gin.Context, message string, cause error) {
+	if _, ok := errors.RetryAfterHint(cause); ok {
+		DatabaseError(c, message, cause)
+		return
+	}
 	appError := errors.NewInternalError(message, cause)
 	ErrorWithAppError(c, appError)
 }
 
-// DatabaseError 发送数据库错误响应
+// DatabaseError 发送数据库错误响应，原因是重试用尽的暂时性错误时返回 503 和 Retry-After 头
 func DatabaseError(c *gin.Context, message string, cause error) {
 	appError := errors.NewDatabaseError(message, cause)
+	if retryAfter, ok := errors.RetryAfterHint(cause); ok {
+		appError = errors.NewTransientDatabaseError(message, cause, retryAfter)
+	}
 	ErrorWithAppError(c, appError)
 }
 
@@ -189,8 +218,30 @@ func TokenBlacklistedError(c *gin.Context) {
 	ErrorWithAppError(c, appError)
 }
 
+// PayloadTooLargeError 发送请求体过大错误响应
+func PayloadTooLargeError(c *gin.Context, limitBytes int64) {
+	appError := errors.NewPayloadTooLargeError(limitBytes)
+	ErrorWithAppError(c, appError)
+}
+
+// PreconditionFailedError 发送前置条件失败错误响应（412状态码）
+func PreconditionFailedError(c *gin.Context, currentETag string) {
+	appError := errors.NewPreconditionFailedError(currentETag)
+	ErrorWithAppError(c, appError)
+}
+
 // ========== 辅助函数 ==========
 
+// AppErrorFromContext 返回当前请求已发送的错误响应对应的AppError
+func AppErrorFromContext(c *gin.Context) (*errors.AppError, bool) {
+	if value, exists := c.Get(AppErrorContextKey); exists {
+		if appError, ok := value.(*errors.AppError); ok {
+			return appError, true
+		}
+	}
+	return nil, false
+}
+
 // getCorrelationID 从请求上下文中获取或生成关联ID
 func getCorrelationID(c *gin.Context) string {
 	// 首先尝试从请求头中获取
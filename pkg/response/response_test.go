@@ -3,6 +3,7 @@ package response
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -657,3 +658,45 @@ func TestErrorWithComplexDataStructures(t *testing.T) {
 	assert.Contains(t, response.Error.Details, "request_context")
 	assert.Contains(t, response.Error.Details, "nested_object")
 }
+
+// transientCause 模拟重试用尽的数据库暂时性错误
+type transientCause struct{}
+
+func (transientCause) Error() string             { return "serialization failure" }
+func (transientCause) RetryAfter() time.Duration { return 1500 * time.Millisecond }
+
+func TestDatabaseErrorTransientCause(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	DatabaseError(c, "获取用户失败", fmt.Errorf("failed to get user: %w", transientCause{}))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	var response Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, apperrors.ErrCodeDatabase, response.Error.Code)
+	assert.Equal(t, float64(2), response.Error.Details["retry_after"])
+
+	appError, ok := AppErrorFromContext(c)
+	assert.True(t, ok)
+	assert.True(t, appError.Retryable)
+}
+
+func TestDatabaseErrorPermanentCause(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	DatabaseError(c, "获取用户失败", errors.New("syntax error"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
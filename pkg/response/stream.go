@@ -0,0 +1,149 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamFlushSize 流式响应每写入这么多字节刷新一次，大于压缩阈值，刷新时压缩中间件已经开始压缩
+	streamFlushSize = 32 << 10
+
+	// streamWriteTimeout 服务器的写超时针对整个响应，流式响应每次刷新前把写截止时间延长这么久
+	streamWriteTimeout = 30 * time.Second
+)
+
+// JSONArrayStream 流式发送 data 为数组的成功响应，响应体与 Success 发送的统一响应结构相同
+// 数组元素逐个编码后直接写入响应并定期刷新，内存占用与元素数量无关，适用于导出大量数据的列表。
+// 响应在发送第一个元素或 Close 时开始，此前出错仍可发送普通的错误响应；开始后出错时调用 Abort，
+// 响应体缺少结尾，不是合法的 JSON，客户端据此判断数据不完整
+type JSONArrayStream struct {
+	c          *gin.Context
+	statusCode int
+	message    string
+	encoder    *json.Encoder
+	controller *http.ResponseController
+	pending    int // 上次刷新后写入的字节数
+	count      int
+	started    bool
+	done       bool
+}
+
+// NewJSONArrayStream 创建流式数组响应，调用 Send 发送元素，最后调用 Close 结束响应
+func NewJSONArrayStream(c *gin.Context, statusCode int, message string) *JSONArrayStream {
+	s := &JSONArrayStream{
+		c:          c,
+		statusCode: statusCode,
+		message:    message,
+		controller: http.NewResponseController(c.Writer),
+	}
+	s.encoder = json.NewEncoder(streamWriter{s})
+	return s
+}
+
+// Started 响应是否已经开始发送
+func (s *JSONArrayStream) Started() bool {
+	return s.started
+}
+
+// Count 已发送的元素数
+func (s *JSONArrayStream) Count() int {
+	return s.count
+}
+
+// Send 编码并发送一个数组元素，写入失败（如客户端断开）时返回错误
+func (s *JSONArrayStream) Send(item interface{}) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	if s.count > 0 {
+		if _, err := s.write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	if err := s.encoder.Encode(item); err != nil {
+		return err
+	}
+	s.count++
+
+	if s.pending >= streamFlushSize {
+		s.flush()
+	}
+	return nil
+}
+
+// Close 写入数组结尾、关联ID和时间戳，结束响应
+func (s *JSONArrayStream) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	if s.done {
+		return nil
+	}
+	s.done = true
+
+	timestamp, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	tail := []byte(`]`)
+	if correlationID := getCorrelationID(s.c); correlationID != "" {
+		id, _ := json.Marshal(correlationID)
+		tail = append(append(tail, `,"correlation_id":`...), id...)
+	}
+	tail = append(append(append(tail, `,"timestamp":`...), timestamp...), '}')
+	_, err = s.write(tail)
+	return err
+}
+
+// Abort 在响应开始后出错时结束响应，不写入数组结尾，并把错误记录到 Gin 上下文供日志等中间件读取
+func (s *JSONArrayStream) Abort(err error) {
+	s.done = true
+	_ = s.c.Error(err)
+	s.c.Abort()
+}
+
+// start 写入状态码、响应头和统一响应结构的开头
+func (s *JSONArrayStream) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	message, err := json.Marshal(s.message)
+	if err != nil {
+		return err
+	}
+	s.c.Header("Content-Type", "application/json; charset=utf-8")
+	s.c.Status(s.statusCode)
+
+	head := append([]byte(`{"success":true,"message":`), message...)
+	_, err = s.write(append(head, `,"data":[`...))
+	return err
+}
+
+// write 写入响应并记录未刷新的字节数
+func (s *JSONArrayStream) write(data []byte) (int, error) {
+	n, err := s.c.Writer.Write(data)
+	s.pending += n
+	return n, err
+}
+
+// flush 延长写截止时间并刷新响应，压缩中间件随之输出已压缩的数据
+func (s *JSONArrayStream) flush() {
+	_ = s.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	s.c.Writer.Flush()
+	s.pending = 0
+}
+
+// streamWriter 供 json.Encoder 写入流式响应
+type streamWriter struct {
+	stream *JSONArrayStream
+}
+
+func (w streamWriter) Write(data []byte) (int, error) {
+	return w.stream.write(data)
+}
@@ -0,0 +1,91 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("X-Correlation-ID", "stream-test")
+	return c, w
+}
+
+func TestJSONArrayStream(t *testing.T) {
+	c, w := newStreamTestContext()
+
+	stream := NewJSONArrayStream(c, http.StatusOK, "成功获取用户")
+	assert.False(t, stream.Started())
+	for i := 0; i < 3; i++ {
+		require.NoError(t, stream.Send(map[string]int{"id": i}))
+	}
+	require.NoError(t, stream.Close())
+	assert.True(t, stream.Started())
+	assert.Equal(t, 3, stream.Count())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var body struct {
+		Response
+		Data []map[string]int `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Success)
+	assert.Equal(t, "成功获取用户", body.Message)
+	assert.Equal(t, "stream-test", body.CorrelationID)
+	assert.NotZero(t, body.Timestamp)
+	assert.Equal(t, []map[string]int{{"id": 0}, {"id": 1}, {"id": 2}}, body.Data)
+}
+
+func TestJSONArrayStream_Empty(t *testing.T) {
+	c, w := newStreamTestContext()
+
+	require.NoError(t, NewJSONArrayStream(c, http.StatusOK, "ok").Close())
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []interface{}{}, body["data"])
+}
+
+func TestJSONArrayStream_FlushesLargeResponses(t *testing.T) {
+	c, w := newStreamTestContext()
+
+	stream := NewJSONArrayStream(c, http.StatusOK, "ok")
+	item := map[string]string{"name": "streamed item"}
+	for !w.Flushed {
+		require.NoError(t, stream.Send(item))
+		require.LessOrEqual(t, w.Body.Len(), 2*streamFlushSize, "response should be flushed once streamFlushSize bytes are written")
+	}
+	assert.Zero(t, stream.pending)
+	require.NoError(t, stream.Close())
+
+	var body struct {
+		Data []map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Data, stream.Count())
+}
+
+func TestJSONArrayStream_Abort(t *testing.T) {
+	c, w := newStreamTestContext()
+
+	stream := NewJSONArrayStream(c, http.StatusOK, "ok")
+	require.NoError(t, stream.Send(map[string]int{"id": 1}))
+	stream.Abort(errors.New("connection reset"))
+	require.NoError(t, stream.Close(), "Close after Abort is a no-op")
+
+	assert.True(t, c.IsAborted())
+	assert.Len(t, c.Errors, 1)
+	assert.False(t, json.Valid(w.Body.Bytes()), "an aborted stream must not look complete")
+}
@@ -0,0 +1,123 @@
+package saml
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// canonicalize 按 Exclusive XML Canonicalization 1.0（不含注释）输出以 e 为顶点的子树
+// exclude 为跳过的子元素（封装签名变换中的 Signature 元素），inclusive 为 InclusiveNamespaces PrefixList 中的前缀，
+// 默认命名空间在其中以空字符串表示
+func canonicalize(e *element, exclude *element, inclusive []string) []byte {
+	c := &canonicalizer{exclude: exclude, inclusive: inclusive}
+	c.element(e, map[string]string{})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	exclude   *element
+	inclusive []string
+}
+
+// element 输出元素，rendered 为输出的祖先元素已声明的命名空间
+func (c *canonicalizer) element(e *element, rendered map[string]string) {
+	// 只输出本元素及其属性实际使用的命名空间和包含列表中的命名空间，且祖先已输出相同声明时省略
+	utilized := map[string]bool{e.prefix: true}
+	for _, attr := range e.attrs {
+		if attr.Name.Space != "" && attr.Name.Space != "xml" {
+			utilized[attr.Name.Space] = true
+		}
+	}
+	for _, prefix := range c.inclusive {
+		if _, ok := e.lookupNamespace(prefix); ok {
+			utilized[prefix] = true
+		}
+	}
+
+	var decls []string
+	scope := rendered
+	for prefix := range utilized {
+		if prefix == "xml" {
+			continue
+		}
+		uri, _ := e.lookupNamespace(prefix)
+		previous, declared := rendered[prefix]
+		if declared && previous == uri || !declared && prefix == "" && uri == "" {
+			continue
+		}
+		if len(decls) == 0 {
+			scope = make(map[string]string, len(rendered)+len(utilized))
+			for k, v := range rendered {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = uri
+		decls = append(decls, prefix)
+	}
+	sort.Strings(decls)
+
+	attrs := make([]canonicalAttr, 0, len(e.attrs))
+	for _, attr := range e.attrs {
+		namespace := ""
+		if attr.Name.Space != "" {
+			namespace, _ = e.lookupNamespace(attr.Name.Space)
+		}
+		attrs = append(attrs, canonicalAttr{
+			namespace: namespace,
+			local:     attr.Name.Local,
+			name:      qualifiedName(attr.Name.Space, attr.Name.Local),
+			value:     attr.Value,
+		})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].namespace != attrs[j].namespace {
+			return attrs[i].namespace < attrs[j].namespace
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	c.buf.WriteByte('<')
+	c.buf.WriteString(name)
+	for _, prefix := range decls {
+		if prefix == "" {
+			c.buf.WriteString(` xmlns="`)
+		} else {
+			c.buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		c.buf.WriteString(attrEscaper.Replace(scope[prefix]))
+		c.buf.WriteByte('"')
+	}
+	for _, attr := range attrs {
+		c.buf.WriteString(" " + attr.name + `="`)
+		c.buf.WriteString(attrEscaper.Replace(attr.value))
+		c.buf.WriteByte('"')
+	}
+	c.buf.WriteByte('>')
+
+	for _, child := range e.children {
+		switch {
+		case child.elem == nil:
+			c.buf.WriteString(textEscaper.Replace(child.text))
+		case child.elem != c.exclude:
+			c.element(child.elem, scope)
+		}
+	}
+
+	c.buf.WriteString("</" + name + ">")
+}
+
+// canonicalAttr 按命名空间 URI 和本地名排序的属性
+type canonicalAttr struct {
+	namespace string
+	local     string
+	name      string
+	value     string
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
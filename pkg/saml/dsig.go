@@ -0,0 +1,187 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	// 注册签名和摘要算法使用的哈希
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// XML 签名相关的命名空间和算法
+const (
+	namespaceDSig   = "http://www.w3.org/2000/09/xmldsig#"
+	namespaceExcC14 = "http://www.w3.org/2001/10/xml-exc-c14n#"
+
+	algorithmExcC14N      = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algorithmEnveloped    = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algorithmRSASHA256    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algorithmRSASHA512    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algorithmDigestSHA256 = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algorithmDigestSHA512 = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// 支持的签名和摘要算法，SHA-1 不再安全，不支持
+var (
+	signatureHashes = map[string]crypto.Hash{
+		algorithmRSASHA256: crypto.SHA256,
+		algorithmRSASHA512: crypto.SHA512,
+	}
+	digestHashes = map[string]crypto.Hash{
+		algorithmDigestSHA256: crypto.SHA256,
+		algorithmDigestSHA512: crypto.SHA512,
+	}
+)
+
+// ErrSignatureMissing 元素没有签名
+var ErrSignatureMissing = errors.New("saml: signature missing")
+
+// signature 返回元素的封装签名（直接子元素 ds:Signature），没有签名时返回 nil
+func signature(e *element) (*element, error) {
+	return e.child(namespaceDSig, "Signature")
+}
+
+// verifySignature 验证元素的封装签名（enveloped signature）
+// 签名必须是元素的直接子元素，且唯一的 Reference 指向元素自身的 ID，因此签名只能证明这个元素未被篡改；
+// 调用方随后只从同一个元素读取数据，防止签名包装攻击。验证使用配置的证书，忽略消息中的 KeyInfo
+func verifySignature(e *element, cert *x509.Certificate) error {
+	sig, err := signature(e)
+	if err != nil {
+		return err
+	}
+	if sig == nil {
+		return ErrSignatureMissing
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("saml: unsupported certificate key type %T", cert.PublicKey)
+	}
+
+	signedInfo, err := requiredChild(sig, namespaceDSig, "SignedInfo")
+	if err != nil {
+		return err
+	}
+	c14nMethod, err := requiredChild(signedInfo, namespaceDSig, "CanonicalizationMethod")
+	if err != nil {
+		return err
+	}
+	if algorithm := c14nMethod.attr("Algorithm"); algorithm != algorithmExcC14N {
+		return fmt.Errorf("saml: unsupported canonicalization method %q", algorithm)
+	}
+	signatureMethod, err := requiredChild(signedInfo, namespaceDSig, "SignatureMethod")
+	if err != nil {
+		return err
+	}
+	signatureHash, ok := signatureHashes[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("saml: unsupported signature method %q", signatureMethod.attr("Algorithm"))
+	}
+
+	references := signedInfo.childElements(namespaceDSig, "Reference")
+	if len(references) != 1 {
+		return fmt.Errorf("saml: expected one signature reference, found %d", len(references))
+	}
+	reference := references[0]
+	if id := e.attr("ID"); id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("saml: signature does not reference the signed element")
+	}
+
+	// 变换只允许封装签名和排他规范化，规范化总是在最后执行
+	var (
+		enveloped bool
+		inclusive []string
+	)
+	if transforms, err := reference.child(namespaceDSig, "Transforms"); err != nil {
+		return err
+	} else if transforms != nil {
+		for _, transform := range transforms.childElements(namespaceDSig, "Transform") {
+			switch algorithm := transform.attr("Algorithm"); algorithm {
+			case algorithmEnveloped:
+				enveloped = true
+			case algorithmExcC14N:
+				inclusive = inclusivePrefixes(transform)
+			default:
+				return fmt.Errorf("saml: unsupported transform %q", algorithm)
+			}
+		}
+	}
+	if !enveloped {
+		return errors.New("saml: signature is not an enveloped signature")
+	}
+
+	digestMethod, err := requiredChild(reference, namespaceDSig, "DigestMethod")
+	if err != nil {
+		return err
+	}
+	digestHash, ok := digestHashes[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("saml: unsupported digest method %q", digestMethod.attr("Algorithm"))
+	}
+	digestValue, err := requiredChild(reference, namespaceDSig, "DigestValue")
+	if err != nil {
+		return err
+	}
+	expected, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("saml: invalid digest value: %w", err)
+	}
+	digest := digestHash.New()
+	digest.Write(canonicalize(e, sig, inclusive))
+	if subtle.ConstantTimeCompare(digest.Sum(nil), expected) != 1 {
+		return errors.New("saml: digest mismatch")
+	}
+
+	signatureValue, err := requiredChild(sig, namespaceDSig, "SignatureValue")
+	if err != nil {
+		return err
+	}
+	signed, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("saml: invalid signature value: %w", err)
+	}
+	hashed := signatureHash.New()
+	hashed.Write(canonicalize(signedInfo, nil, inclusivePrefixes(c14nMethod)))
+	if err := rsa.VerifyPKCS1v15(publicKey, signatureHash, hashed.Sum(nil), signed); err != nil {
+		return fmt.Errorf("saml: invalid signature: %w", err)
+	}
+	return nil
+}
+
+// inclusivePrefixes 读取排他规范化的 InclusiveNamespaces PrefixList，#default 表示默认命名空间
+func inclusivePrefixes(method *element) []string {
+	inclusive, _ := method.child(namespaceExcC14, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+	prefixes := strings.Fields(inclusive.attr("PrefixList"))
+	for i, prefix := range prefixes {
+		if prefix == "#default" {
+			prefixes[i] = ""
+		}
+	}
+	return prefixes
+}
+
+// requiredChild 返回唯一的直接子元素，不存在时返回错误
+func requiredChild(e *element, namespace, local string) (*element, error) {
+	child, err := e.child(namespace, local)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, fmt.Errorf("saml: missing %s in %s", local, e.local)
+	}
+	return child, nil
+}
+
+// decodeBase64 解码可能带有换行的 base64 文本
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
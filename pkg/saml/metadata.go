@@ -0,0 +1,52 @@
+package saml
+
+import (
+	"encoding/xml"
+)
+
+// entityDescriptor SP 元数据，字段标签直接写出前缀，生成的文档与常见 IdP 导入的格式一致
+type entityDescriptor struct {
+	XMLName    xml.Name        `xml:"md:EntityDescriptor"`
+	Namespace  string          `xml:"xmlns:md,attr"`
+	EntityID   string          `xml:"entityID,attr"`
+	Descriptor spSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormats              []string                 `xml:"md:NameIDFormat"`
+	AssertionConsumerService   assertionConsumerService `xml:"md:AssertionConsumerService"`
+}
+
+type assertionConsumerService struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// Metadata 生成 SP 元数据，IdP 管理员导入后即可得到实体ID和 ACS 地址
+// AuthnRequest 不签名；要求 IdP 签名断言
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	metadata := entityDescriptor{
+		Namespace: NamespaceMetadata,
+		EntityID:  sp.EntityID,
+		Descriptor: spSSODescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: NamespaceProtocol,
+			NameIDFormats:              []string{NameIDFormatEmail, NameIDFormatUnspecified},
+			AssertionConsumerService: assertionConsumerService{
+				Binding:   BindingHTTPPost,
+				Location:  sp.ACSURL,
+				IsDefault: true,
+			},
+		},
+	}
+	body, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
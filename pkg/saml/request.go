@@ -0,0 +1,92 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-server/pkg/clock"
+)
+
+// authnRequest SAML AuthnRequest
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"samlp:AuthnRequest"`
+	ProtocolNamespace           string       `xml:"xmlns:samlp,attr"`
+	AssertionNamespace          string       `xml:"xmlns:saml,attr"`
+	ID                          string       `xml:"ID,attr"`
+	Version                     string       `xml:"Version,attr"`
+	IssueInstant                string       `xml:"IssueInstant,attr"`
+	Destination                 string       `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string       `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string       `xml:"ProtocolBinding,attr"`
+	Issuer                      string       `xml:"saml:Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"samlp:NameIDPolicy"`
+}
+
+type nameIDPolicy struct {
+	Format      string `xml:"Format,attr"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+// AuthnRequest 发往 IdP 的认证请求
+type AuthnRequest struct {
+	// ID 请求ID，IdP 在 Response 的 InResponseTo 中原样返回，调用方应保存以拒绝未经请求的响应
+	ID string
+	// URL 浏览器应重定向到的 IdP 地址（HTTP-Redirect 绑定）
+	URL string
+}
+
+// NewAuthnRequest 创建认证请求，relayState 会由 IdP 随 Response 原样提交回 ACS
+func (sp *ServiceProvider) NewAuthnRequest(relayState string) (*AuthnRequest, error) {
+	random := make([]byte, 20)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	// xs:ID 不能以数字开头
+	id := "_" + hex.EncodeToString(random)
+
+	body, err := xml.Marshal(authnRequest{
+		ProtocolNamespace:           NamespaceProtocol,
+		AssertionNamespace:          NamespaceAssertion,
+		ID:                          id,
+		Version:                     "2.0",
+		IssueInstant:                clock.OrReal(sp.Clock).Now().UTC().Format(time.RFC3339),
+		Destination:                 sp.IdP.SSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             BindingHTTPPost,
+		Issuer:                      sp.EntityID,
+		NameIDPolicy:                nameIDPolicy{Format: NameIDFormatUnspecified, AllowCreate: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// HTTP-Redirect 绑定：DEFLATE 压缩后 base64 编码放入查询参数
+	var compressed bytes.Buffer
+	writer, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString(compressed.Bytes())}}
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	separator := "?"
+	if strings.Contains(sp.IdP.SSOURL, "?") {
+		separator = "&"
+	}
+	return &AuthnRequest{ID: id, URL: sp.IdP.SSOURL + separator + query.Encode()}, nil
+}
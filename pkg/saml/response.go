@@ -0,0 +1,315 @@
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-server/pkg/clock"
+)
+
+// MaxResponseSize 接受的 SAMLResponse 解码后的最大字节数
+const MaxResponseSize = 256 << 10
+
+// ErrInvalidResponse 响应无效：格式错误、签名无效、签发者或受众不符或已过期，具体原因在包装的错误中
+var ErrInvalidResponse = errors.New("saml: invalid response")
+
+// StatusError IdP 返回了非成功的状态，如用户取消登录或没有访问该应用的权限
+type StatusError struct {
+	Code    string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("saml: identity provider returned %s: %s", e.Code, e.Message)
+	}
+	return "saml: identity provider returned " + e.Code
+}
+
+// Assertion 从已验证的断言中读取的身份信息
+type Assertion struct {
+	// ID 断言ID
+	ID string
+	// InResponseTo 对应的 AuthnRequest ID，调用方必须确认它是自己发出且尚未使用的请求
+	InResponseTo string
+	// NameID 用户标识及其格式
+	NameID       string
+	NameIDFormat string
+	// SessionIndex IdP 会话标识
+	SessionIndex string
+	// Attributes 属性，键为 Attribute 的 Name
+	Attributes map[string][]string
+	// NotOnOrAfter 断言的过期时间
+	NotOnOrAfter time.Time
+}
+
+// Attribute 返回属性的第一个值
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseResponse 解码并验证 HTTP-POST 绑定提交的 SAMLResponse（base64），返回其中唯一的断言
+// Response 和 Assertion 至少有一个须由 IdP 签名，存在的签名都必须有效；拒绝未经请求（没有 InResponseTo）的响应
+func (sp *ServiceProvider) ParseResponse(encoded string) (*Assertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, invalid("decode response: %v", err)
+	}
+	if len(data) > MaxResponseSize {
+		return nil, invalid("response exceeds %d bytes", MaxResponseSize)
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	if !response.is(NamespaceProtocol, "Response") {
+		return nil, invalid("root element is %s, not Response", response.local)
+	}
+	if response.attr("Version") != "2.0" {
+		return nil, invalid("unsupported SAML version %q", response.attr("Version"))
+	}
+	if destination := response.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, invalid("destination %q does not match %q", destination, sp.ACSURL)
+	}
+	if err := sp.checkIssuer(response, false); err != nil {
+		return nil, err
+	}
+
+	responseSigned, err := sp.verifyIfSigned(response)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(response); err != nil {
+		return nil, err
+	}
+
+	if encrypted := response.childElements(NamespaceAssertion, "EncryptedAssertion"); len(encrypted) > 0 {
+		return nil, invalid("encrypted assertions are not supported")
+	}
+	assertions := response.childElements(NamespaceAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, invalid("expected one assertion, found %d", len(assertions))
+	}
+	assertion := assertions[0]
+	assertionSigned, err := sp.verifyIfSigned(assertion)
+	if err != nil {
+		return nil, err
+	}
+	if !responseSigned && !assertionSigned {
+		return nil, invalid("neither the response nor the assertion is signed")
+	}
+
+	inResponseTo := response.attr("InResponseTo")
+	if inResponseTo == "" {
+		return nil, invalid("unsolicited responses are not accepted")
+	}
+	return sp.readAssertion(assertion, inResponseTo)
+}
+
+// verifyIfSigned 元素带有签名时验证签名，返回元素是否已签名
+func (sp *ServiceProvider) verifyIfSigned(e *element) (bool, error) {
+	sig, err := signature(e)
+	if err != nil {
+		return false, invalid("%v", err)
+	}
+	if sig == nil {
+		return false, nil
+	}
+	if err := verifySignature(e, sp.IdP.Certificate); err != nil {
+		return false, invalid("%s signature: %v", e.local, err)
+	}
+	return true, nil
+}
+
+// checkIssuer 检查签发者是否为配置的 IdP，required 为 false 时允许省略
+func (sp *ServiceProvider) checkIssuer(e *element, required bool) error {
+	issuer, err := e.child(NamespaceAssertion, "Issuer")
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if issuer == nil {
+		if required {
+			return invalid("%s has no issuer", e.local)
+		}
+		return nil
+	}
+	if issuer.text() != sp.IdP.EntityID {
+		return invalid("issuer %q does not match %q", issuer.text(), sp.IdP.EntityID)
+	}
+	return nil
+}
+
+// checkStatus 检查顶层状态码是否为成功
+func checkStatus(response *element) error {
+	status, err := requiredChild(response, NamespaceProtocol, "Status")
+	if err != nil {
+		return invalid("%v", err)
+	}
+	code, err := requiredChild(status, NamespaceProtocol, "StatusCode")
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if value := code.attr("Value"); value != StatusSuccess {
+		statusErr := &StatusError{Code: value}
+		if message, _ := status.child(NamespaceProtocol, "StatusMessage"); message != nil {
+			statusErr.Message = message.text()
+		}
+		// 次级状态码说明具体原因，如 AuthnFailed、RequestDenied
+		if detail, _ := code.child(NamespaceProtocol, "StatusCode"); detail != nil {
+			statusErr.Code = detail.attr("Value")
+		}
+		return statusErr
+	}
+	return nil
+}
+
+// readAssertion 校验断言的签发者、主体确认、有效期和受众，并读取身份信息
+func (sp *ServiceProvider) readAssertion(assertion *element, inResponseTo string) (*Assertion, error) {
+	if assertion.attr("Version") != "2.0" {
+		return nil, invalid("unsupported assertion version %q", assertion.attr("Version"))
+	}
+	if err := sp.checkIssuer(assertion, true); err != nil {
+		return nil, err
+	}
+	now := clock.OrReal(sp.Clock).Now()
+	skew := sp.clockSkew()
+
+	subject, err := requiredChild(assertion, NamespaceAssertion, "Subject")
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	nameID, err := requiredChild(subject, NamespaceAssertion, "NameID")
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	if nameID.text() == "" {
+		return nil, invalid("empty NameID")
+	}
+	if err := sp.checkSubjectConfirmation(subject, inResponseTo, now, skew); err != nil {
+		return nil, err
+	}
+
+	conditions, err := requiredChild(assertion, NamespaceAssertion, "Conditions")
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	notOnOrAfter, err := checkValidity(conditions, now, skew)
+	if err != nil {
+		return nil, err
+	}
+	if err := sp.checkAudience(conditions); err != nil {
+		return nil, err
+	}
+
+	result := &Assertion{
+		ID:           assertion.attr("ID"),
+		InResponseTo: inResponseTo,
+		NameID:       nameID.text(),
+		NameIDFormat: nameID.attr("Format"),
+		Attributes:   make(map[string][]string),
+		NotOnOrAfter: notOnOrAfter,
+	}
+	if statement, _ := assertion.child(NamespaceAssertion, "AuthnStatement"); statement != nil {
+		result.SessionIndex = statement.attr("SessionIndex")
+	}
+	for _, statement := range assertion.childElements(NamespaceAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(NamespaceAssertion, "Attribute") {
+			name := attribute.attr("Name")
+			for _, value := range attribute.childElements(NamespaceAssertion, "AttributeValue") {
+				result.Attributes[name] = append(result.Attributes[name], value.text())
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkSubjectConfirmation 要求存在一个有效的 bearer 主体确认：接收方为 ACS、未过期且对应同一个请求
+func (sp *ServiceProvider) checkSubjectConfirmation(subject *element, inResponseTo string, now time.Time, skew time.Duration) error {
+	var reasons []error
+	for _, confirmation := range subject.childElements(NamespaceAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != methodBearer {
+			continue
+		}
+		data, err := requiredChild(confirmation, NamespaceAssertion, "SubjectConfirmationData")
+		if err != nil {
+			reasons = append(reasons, err)
+			continue
+		}
+		notOnOrAfter, err := parseTime(data.attr("NotOnOrAfter"))
+		switch {
+		case err != nil:
+			reasons = append(reasons, fmt.Errorf("subject confirmation NotOnOrAfter: %w", err))
+		case !now.Before(notOnOrAfter.Add(skew)):
+			reasons = append(reasons, errors.New("subject confirmation expired"))
+		case data.attr("Recipient") != sp.ACSURL:
+			reasons = append(reasons, fmt.Errorf("subject confirmation recipient %q does not match", data.attr("Recipient")))
+		case data.attr("InResponseTo") != inResponseTo:
+			reasons = append(reasons, errors.New("subject confirmation belongs to another request"))
+		default:
+			return nil
+		}
+	}
+	if len(reasons) == 0 {
+		return invalid("no bearer subject confirmation")
+	}
+	return invalid("%v", errors.Join(reasons...))
+}
+
+// checkValidity 检查 Conditions 的有效期，返回过期时间
+func checkValidity(conditions *element, now time.Time, skew time.Duration) (time.Time, error) {
+	if value := conditions.attr("NotBefore"); value != "" {
+		notBefore, err := parseTime(value)
+		if err != nil {
+			return time.Time{}, invalid("conditions NotBefore: %v", err)
+		}
+		if now.Add(skew).Before(notBefore) {
+			return time.Time{}, invalid("assertion is not yet valid")
+		}
+	}
+	notOnOrAfter, err := parseTime(conditions.attr("NotOnOrAfter"))
+	if err != nil {
+		return time.Time{}, invalid("conditions NotOnOrAfter: %v", err)
+	}
+	if !now.Before(notOnOrAfter.Add(skew)) {
+		return time.Time{}, invalid("assertion expired")
+	}
+	return notOnOrAfter, nil
+}
+
+// checkAudience 要求至少有一个受众限制，且每个受众限制都包含本 SP
+func (sp *ServiceProvider) checkAudience(conditions *element) error {
+	restrictions := conditions.childElements(NamespaceAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return invalid("assertion has no audience restriction")
+	}
+	for _, restriction := range restrictions {
+		allowed := false
+		for _, audience := range restriction.childElements(NamespaceAssertion, "Audience") {
+			if audience.text() == sp.EntityID {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return invalid("assertion is not intended for %q", sp.EntityID)
+		}
+	}
+	return nil
+}
+
+// parseTime 解析 xs:dateTime（UTC）
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("missing timestamp")
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// invalid 返回包装 ErrInvalidResponse 的错误
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, fmt.Sprintf(format, args...))
+}
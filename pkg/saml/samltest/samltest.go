@@ -0,0 +1,164 @@
+// Package samltest 测试用的 SAML 身份提供方，签发已签名的 Response
+//
+// 生成的断言直接写成排他规范化后的形式（只在断言元素上声明命名空间、属性有序、不使用自闭合标签），
+// 因此无需规范化实现即可计算摘要。
+package samltest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	namespaceProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	namespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	namespaceDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+)
+
+// IdentityProvider 测试身份提供方
+type IdentityProvider struct {
+	EntityID    string
+	Key         *rsa.PrivateKey
+	Certificate *x509.Certificate
+}
+
+// NewIdentityProvider 创建带有自签名证书的身份提供方
+func NewIdentityProvider(entityID string) (*IdentityProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "samltest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &IdentityProvider{EntityID: entityID, Key: key, Certificate: cert}, nil
+}
+
+// CertificatePEM 返回 PEM 格式的签名证书
+func (idp *IdentityProvider) CertificatePEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: idp.Certificate.Raw}))
+}
+
+// Response 要签发的响应
+type Response struct {
+	// InResponseTo 对应的 AuthnRequest ID
+	InResponseTo string
+	// Audience SP 实体ID
+	Audience string
+	// ACSURL 断言消费服务地址，作为 Destination 和 Recipient
+	ACSURL string
+	NameID string
+	// Attributes 属性，按名称排序输出
+	Attributes map[string][]string
+	// IssueInstant 签发时间，断言在其后 5 分钟内有效
+	IssueInstant time.Time
+}
+
+// Sign 返回断言已签名、base64 编码的 SAMLResponse
+func (idp *IdentityProvider) Sign(r Response) (string, error) {
+	issued := r.IssueInstant.UTC()
+	assertionID := "_assertion" + randomHex()
+	assertion := idp.assertion(assertionID, r, issued)
+
+	digest := sha256.Sum256([]byte(assertion))
+	signedInfo := `<ds:SignedInfo xmlns:ds="` + namespaceDSig + `">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + assertionID + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue></ds:Reference></ds:SignedInfo>`
+	hashed := sha256.Sum256([]byte(signedInfo))
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.Key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	signature := `<ds:Signature xmlns:ds="` + namespaceDSig + `">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(value) + `</ds:SignatureValue></ds:Signature>`
+
+	// 封装签名紧跟在断言的 Issuer 之后
+	issuerEnd := strings.Index(assertion, "</saml:Issuer>") + len("</saml:Issuer>")
+	signed := assertion[:issuerEnd] + signature + assertion[issuerEnd:]
+
+	response := `<samlp:Response xmlns:samlp="` + namespaceProtocol + `" xmlns:saml="` + namespaceAssertion + `"` +
+		` Destination="` + attrEscaper.Replace(r.ACSURL) + `" ID="_response` + randomHex() + `" InResponseTo="` + attrEscaper.Replace(r.InResponseTo) + `"` +
+		` IssueInstant="` + timestamp(issued) + `" Version="2.0">` +
+		`<saml:Issuer>` + textEscaper.Replace(idp.EntityID) + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="` + statusSuccess + `"></samlp:StatusCode></samlp:Status>` +
+		signed + `</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(response)), nil
+}
+
+// assertion 以排他规范化后的形式生成断言
+func (idp *IdentityProvider) assertion(id string, r Response, issued time.Time) string {
+	expires := timestamp(issued.Add(5 * time.Minute))
+	var b strings.Builder
+	b.WriteString(`<saml:Assertion xmlns:saml="` + namespaceAssertion + `" ID="` + id + `" IssueInstant="` + timestamp(issued) + `" Version="2.0">`)
+	b.WriteString(`<saml:Issuer>` + textEscaper.Replace(idp.EntityID) + `</saml:Issuer>`)
+	b.WriteString(`<saml:Subject><saml:NameID>` + textEscaper.Replace(r.NameID) + `</saml:NameID>`)
+	b.WriteString(`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">`)
+	b.WriteString(`<saml:SubjectConfirmationData InResponseTo="` + attrEscaper.Replace(r.InResponseTo) + `" NotOnOrAfter="` + expires + `" Recipient="` + attrEscaper.Replace(r.ACSURL) + `"></saml:SubjectConfirmationData>`)
+	b.WriteString(`</saml:SubjectConfirmation></saml:Subject>`)
+	b.WriteString(`<saml:Conditions NotBefore="` + timestamp(issued.Add(-time.Minute)) + `" NotOnOrAfter="` + expires + `">`)
+	b.WriteString(`<saml:AudienceRestriction><saml:Audience>` + textEscaper.Replace(r.Audience) + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>`)
+	b.WriteString(`<saml:AuthnStatement AuthnInstant="` + timestamp(issued) + `" SessionIndex="_session` + randomHex() + `"></saml:AuthnStatement>`)
+
+	names := make([]string, 0, len(r.Attributes))
+	for name := range r.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		b.WriteString(`<saml:AttributeStatement>`)
+		for _, name := range names {
+			b.WriteString(`<saml:Attribute Name="` + attrEscaper.Replace(name) + `">`)
+			for _, value := range r.Attributes[name] {
+				b.WriteString(`<saml:AttributeValue>` + textEscaper.Replace(value) + `</saml:AttributeValue>`)
+			}
+			b.WriteString(`</saml:Attribute>`)
+		}
+		b.WriteString(`</saml:AttributeStatement>`)
+	}
+	b.WriteString(`</saml:Assertion>`)
+	return b.String()
+}
+
+// 规范化形式中文本和属性值的转义
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func randomHex() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
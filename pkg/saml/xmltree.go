@@ -0,0 +1,190 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlNamespace xml 前缀固定绑定的命名空间
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// element XML 元素，保留原始前缀和命名空间声明，规范化时按原文输出
+// encoding/xml 的 Unmarshal 会丢弃前缀，无法用来计算签名摘要，因此验证签名和读取断言都基于这棵树，
+// 保证读取的正是验证过签名的节点
+type element struct {
+	parent   *element
+	prefix   string
+	local    string
+	attrs    []xml.Attr // 普通属性，Name.Space 为前缀
+	nsDecls  []xml.Attr // 命名空间声明，Name.Local 为前缀（默认命名空间为空），Value 为 URI
+	children []node
+}
+
+// node 元素的子节点：元素或文本
+type node struct {
+	elem *element
+	text string
+}
+
+// parseXML 解析 XML 文档，拒绝 DTD（防止实体扩展攻击）、未声明的前缀和不匹配的结束标签
+// 注释和处理指令被丢弃，签名内容中出现它们时摘要不一致，验证失败
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var root, current *element
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &element{parent: current, prefix: t.Name.Space, local: t.Name.Local}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					el.nsDecls = append(el.nsDecls, xml.Attr{Name: xml.Name{Local: attr.Name.Local}, Value: attr.Value})
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					el.nsDecls = append(el.nsDecls, xml.Attr{Value: attr.Value})
+				default:
+					el.attrs = append(el.attrs, attr)
+				}
+			}
+			if err := el.checkPrefixes(); err != nil {
+				return nil, err
+			}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("saml: multiple root elements")
+				}
+				root = el
+			} else {
+				current.children = append(current.children, node{elem: el})
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || t.Name.Space != current.prefix || t.Name.Local != current.local {
+				return nil, fmt.Errorf("saml: unexpected end element %s", qualifiedName(t.Name.Space, t.Name.Local))
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, node{text: string(t)})
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("saml: text outside the root element")
+			}
+		case xml.Directive:
+			return nil, errors.New("saml: DTDs are not allowed")
+		}
+	}
+	if root == nil || current != nil {
+		return nil, errors.New("saml: incomplete XML document")
+	}
+	return root, nil
+}
+
+// checkPrefixes 检查元素和属性使用的前缀都已声明
+func (e *element) checkPrefixes() error {
+	if _, ok := e.lookupNamespace(e.prefix); !ok {
+		return fmt.Errorf("saml: undeclared namespace prefix %q", e.prefix)
+	}
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" {
+			continue
+		}
+		if _, ok := e.lookupNamespace(attr.Name.Space); !ok {
+			return fmt.Errorf("saml: undeclared namespace prefix %q", attr.Name.Space)
+		}
+	}
+	return nil
+}
+
+// lookupNamespace 返回前缀在本元素处绑定的命名空间，空前缀为默认命名空间（未声明时为空字符串）
+func (e *element) lookupNamespace(prefix string) (string, bool) {
+	for el := e; el != nil; el = el.parent {
+		for _, decl := range el.nsDecls {
+			if decl.Name.Local == prefix {
+				return decl.Value, true
+			}
+		}
+	}
+	switch prefix {
+	case "":
+		return "", true
+	case "xml":
+		return xmlNamespace, true
+	}
+	return "", false
+}
+
+// namespace 元素所属的命名空间
+func (e *element) namespace() string {
+	uri, _ := e.lookupNamespace(e.prefix)
+	return uri
+}
+
+// is 判断元素的命名空间和本地名
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// childElements 返回指定命名空间和本地名的直接子元素
+func (e *element) childElements(namespace, local string) []*element {
+	var children []*element
+	for _, child := range e.children {
+		if child.elem != nil && child.elem.is(namespace, local) {
+			children = append(children, child.elem)
+		}
+	}
+	return children
+}
+
+// child 返回唯一的直接子元素，不存在时返回 nil，存在多个时返回错误
+func (e *element) child(namespace, local string) (*element, error) {
+	children := e.childElements(namespace, local)
+	switch len(children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return children[0], nil
+	}
+	return nil, fmt.Errorf("saml: multiple %s elements in %s", local, e.local)
+}
+
+// attr 返回无前缀属性的值
+func (e *element) attr(name string) string {
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text 返回直接文本子节点拼接后去掉首尾空白的内容
+func (e *element) text() string {
+	var b strings.Builder
+	for _, child := range e.children {
+		if child.elem == nil {
+			b.WriteString(child.text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// qualifiedName 拼接前缀和本地名
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncryptedKeySize 加密存储使用的 AES-256 密钥长度（字节）
+const EncryptedKeySize = 32
+
+// encryptedChunkSize 每个加密分块的明文长度，读写时最多缓冲一个分块
+const encryptedChunkSize = 64 * 1024
+
+// encryptedMagic 加密对象的文件头标识，完整文件头为 标识 + 密钥版本（4 字节）+ nonce 前缀（7 字节）
+var encryptedMagic = []byte("GTENC1")
+
+const encryptedHeaderSize = 6 + 4 + 7
+
+// 加密存储错误
+var (
+	ErrEncryptedKey       = errors.New("storage: invalid encryption key")
+	ErrEncryptedUnknown   = errors.New("storage: object encrypted with an unknown key version")
+	ErrEncryptedMalformed = errors.New("storage: encrypted object is corrupted or truncated")
+)
+
+// EncryptedStorage 在写入前加密、读取时解密对象内容的存储包装
+//
+// 对象按 64KB 分块以 AES-256-GCM 加密，每块的 nonce 由对象的随机前缀、块序号和最后一块标志组成，
+// 调换、删除或截断分块都会在读取时报错。文件头记录密钥版本，轮换时新增版本并切换 active，旧版本保留用于读取已有对象。
+// 读取在到达对象末尾时才能确认内容完整，调用方在 Read 返回 io.EOF 之前不应信任已读取的数据。
+type EncryptedStorage struct {
+	Storage
+	aeads  map[uint32]cipher.AEAD
+	active uint32
+}
+
+// NewEncryptedStorage 创建加密存储，keys 为按版本保存的 32 字节密钥，active 为 0 时使用最大的版本加密新对象
+func NewEncryptedStorage(inner Storage, keys map[uint32][]byte, active uint32) (*EncryptedStorage, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrEncryptedKey)
+	}
+
+	s := &EncryptedStorage{Storage: inner, aeads: make(map[uint32]cipher.AEAD, len(keys)), active: active}
+	for version, key := range keys {
+		if version == 0 {
+			return nil, fmt.Errorf("%w: key versions start at 1", ErrEncryptedKey)
+		}
+		if len(key) != EncryptedKeySize {
+			return nil, fmt.Errorf("%w: key %d is %d bytes, want %d", ErrEncryptedKey, version, len(key), EncryptedKeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.aeads[version] = aead
+		if active == 0 && version > s.active {
+			s.active = version
+		}
+	}
+	if _, ok := s.aeads[s.active]; !ok {
+		return nil, fmt.Errorf("%w: active key %d is not configured", ErrEncryptedKey, active)
+	}
+	return s, nil
+}
+
+// Put 加密 reader 的内容后写入，返回的 Size 为密文长度
+func (s *EncryptedStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*Object, error) {
+	header := make([]byte, encryptedHeaderSize)
+	copy(header, encryptedMagic)
+	binary.BigEndian.PutUint32(header[len(encryptedMagic):], s.active)
+	if _, err := io.ReadFull(rand.Reader, header[len(encryptedMagic)+4:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	encrypted := &encryptingReader{
+		source: reader,
+		aead:   s.aeads[s.active],
+		prefix: header[len(encryptedMagic)+4:],
+		buf:    header,
+		plain:  make([]byte, encryptedChunkSize),
+	}
+	return s.Storage.Put(ctx, key, encrypted, -1, contentType)
+}
+
+// Get 读取并解密对象，返回的元数据中 Size 为密文长度
+func (s *EncryptedStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	reader, object, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := make([]byte, encryptedHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header[:len(encryptedMagic)], encryptedMagic) {
+		reader.Close()
+		return nil, nil, ErrEncryptedMalformed
+	}
+	version := binary.BigEndian.Uint32(header[len(encryptedMagic):])
+	aead, ok := s.aeads[version]
+	if !ok {
+		reader.Close()
+		return nil, nil, fmt.Errorf("%w: %d", ErrEncryptedUnknown, version)
+	}
+
+	return &decryptingReader{
+		source: reader,
+		aead:   aead,
+		prefix: header[len(encryptedMagic)+4:],
+		sealed: make([]byte, encryptedChunkSize+aead.Overhead()),
+	}, object, nil
+}
+
+// URL 加密对象不能直接公开访问，返回空字符串
+func (s *EncryptedStorage) URL(key string) string {
+	return ""
+}
+
+// chunkNonce 返回分块的 nonce：前缀（7 字节）+ 块序号（4 字节）+ 最后一块标志（1 字节）
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[7:], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptingReader 读取明文并输出文件头和加密分块
+// 满块为中间块，最后一块的明文短于满块（可能为空），读取端据此识别最后一块
+type encryptingReader struct {
+	source  io.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte // 待输出的密文
+	plain   []byte
+	done    bool
+}
+
+// Read 实现 io.Reader 接口
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(r.source, r.plain)
+		last := false
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			last = true
+		case err != nil:
+			return 0, err
+		}
+		if r.counter == ^uint32(0) {
+			return 0, errors.New("storage: object too large to encrypt")
+		}
+		r.buf = r.aead.Seal(nil, chunkNonce(r.prefix, r.counter, last), r.plain[:n], nil)
+		r.counter++
+		r.done = last
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// decryptingReader 读取加密分块并输出明文
+type decryptingReader struct {
+	source  io.ReadCloser
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	sealed  []byte
+	buf     []byte // 待输出的明文
+	done    bool
+}
+
+// Read 实现 io.Reader 接口
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(r.source, r.sealed)
+		last := false
+		switch {
+		case err == io.ErrUnexpectedEOF:
+			last = true
+		case err == io.EOF:
+			// 缺少最后一块，对象被截断
+			return 0, ErrEncryptedMalformed
+		case err != nil:
+			return 0, err
+		}
+		plain, openErr := r.aead.Open(r.sealed[:0], chunkNonce(r.prefix, r.counter, last), r.sealed[:n], nil)
+		if openErr != nil {
+			return 0, ErrEncryptedMalformed
+		}
+		r.buf = plain
+		r.counter++
+		r.done = last
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close 关闭底层的对象读取
+func (r *decryptingReader) Close() error {
+	return r.source.Close()
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptionKey 返回测试用的随机密钥
+func encryptionKey(t *testing.T) []byte {
+	key := make([]byte, EncryptedKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestEncryptedStorage_RoundTrip(t *testing.T) {
+	local, err := NewLocalStorage(LocalConfig{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	store, err := NewEncryptedStorage(local, map[uint32][]byte{1: encryptionKey(t)}, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 空对象、不足一块、恰好整块和跨多块
+	for _, size := range []int{0, 10, encryptedChunkSize, 3*encryptedChunkSize + 17} {
+		content := make([]byte, size)
+		_, err := rand.Read(content)
+		require.NoError(t, err)
+
+		_, err = store.Put(ctx, "backups/a.dump", bytes.NewReader(content), int64(size), "application/octet-stream")
+		require.NoError(t, err)
+
+		raw, err := os.ReadFile(filepath.Join(local.BaseDir(), "backups", "a.dump"))
+		require.NoError(t, err)
+		if size > 0 {
+			assert.NotContains(t, string(raw), string(content), "写入的内容已加密")
+		}
+
+		reader, _, err := store.Get(ctx, "backups/a.dump")
+		require.NoError(t, err)
+		got, err := io.ReadAll(reader)
+		require.NoError(t, reader.Close())
+		require.NoError(t, err)
+		assert.Equal(t, content, got, "size %d", size)
+	}
+	assert.Empty(t, store.URL("backups/a.dump"))
+}
+
+func TestEncryptedStorage_DetectsTampering(t *testing.T) {
+	local, err := NewLocalStorage(LocalConfig{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	store, err := NewEncryptedStorage(local, map[uint32][]byte{1: encryptionKey(t)}, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("x"), 2*encryptedChunkSize+5)
+	_, err = store.Put(ctx, "backups/a.dump", bytes.NewReader(content), -1, "")
+	require.NoError(t, err)
+	path := filepath.Join(local.BaseDir(), "backups", "a.dump")
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	read := func(t *testing.T, data []byte) error {
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		reader, _, err := store.Get(ctx, "backups/a.dump")
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		_, err = io.ReadAll(reader)
+		return err
+	}
+
+	t.Run("修改密文", func(t *testing.T) {
+		tampered := append([]byte(nil), raw...)
+		tampered[encryptedHeaderSize+100] ^= 1
+		assert.ErrorIs(t, read(t, tampered), ErrEncryptedMalformed)
+	})
+
+	t.Run("截断到整块", func(t *testing.T) {
+		chunk := encryptedChunkSize + 16
+		assert.ErrorIs(t, read(t, raw[:encryptedHeaderSize+2*chunk]), ErrEncryptedMalformed)
+	})
+
+	t.Run("截断最后一块", func(t *testing.T) {
+		assert.ErrorIs(t, read(t, raw[:len(raw)-1]), ErrEncryptedMalformed)
+	})
+
+	t.Run("不是加密对象", func(t *testing.T) {
+		assert.ErrorIs(t, read(t, []byte("plain text")), ErrEncryptedMalformed)
+	})
+}
+
+func TestEncryptedStorage_KeyRotation(t *testing.T) {
+	local, err := NewLocalStorage(LocalConfig{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	ctx := context.Background()
+	oldKey, newKey := encryptionKey(t), encryptionKey(t)
+
+	old, err := NewEncryptedStorage(local, map[uint32][]byte{1: oldKey}, 0)
+	require.NoError(t, err)
+	_, err = old.Put(ctx, "backups/old.dump", bytes.NewReader([]byte("old")), -1, "")
+	require.NoError(t, err)
+
+	rotated, err := NewEncryptedStorage(local, map[uint32][]byte{1: oldKey, 2: newKey}, 0)
+	require.NoError(t, err)
+	reader, _, err := rotated.Get(ctx, "backups/old.dump")
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "old", string(got), "旧版本的密钥仍可读取")
+
+	_, err = rotated.Put(ctx, "backups/new.dump", bytes.NewReader([]byte("new")), -1, "")
+	require.NoError(t, err)
+	_, _, err = old.Get(ctx, "backups/new.dump")
+	assert.ErrorIs(t, err, ErrEncryptedUnknown, "新对象使用最大的版本加密")
+
+	_, err = NewEncryptedStorage(local, nil, 0)
+	assert.ErrorIs(t, err, ErrEncryptedKey)
+	_, err = NewEncryptedStorage(local, map[uint32][]byte{1: []byte("short")}, 0)
+	assert.ErrorIs(t, err, ErrEncryptedKey)
+	_, err = NewEncryptedStorage(local, map[uint32][]byte{1: oldKey}, 2)
+	assert.ErrorIs(t, err, ErrEncryptedKey)
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalConfig 本地文件系统存储配置
+type LocalConfig struct {
+	BaseDir string // 文件存储根目录
+	BaseURL string // 公开访问地址前缀，如 /uploads
+}
+
+// LocalStorage 基于本地文件系统的存储实现
+type LocalStorage struct {
+	baseDir string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地文件系统存储，根目录不存在时自动创建
+func NewLocalStorage(cfg LocalConfig) (*LocalStorage, error) {
+	if cfg.BaseDir == "" {
+		cfg.BaseDir = "./uploads"
+	}
+
+	absDir, err := filepath.Abs(cfg.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage directory: %w", err)
+	}
+
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalStorage{
+		baseDir: absDir,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+	}, nil
+}
+
+// Driver 返回驱动名称
+func (s *LocalStorage) Driver() string {
+	return DriverLocal
+}
+
+// BaseDir 返回存储根目录
+func (s *LocalStorage) BaseDir() string {
+	return s.baseDir
+}
+
+// path 将对象键转换为文件路径
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(key)), nil
+}
+
+// Put 先写入临时文件再原子重命名，避免读到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), &contextReader{ctx: ctx, reader: reader})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+
+	if size >= 0 && written != size {
+		return nil, fmt.Errorf("object size mismatch: expected %d bytes, wrote %d", size, written)
+	}
+
+	if err := os.Rename(tmpName, filePath); err != nil {
+		return nil, fmt.Errorf("failed to move object into place: %w", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         written,
+		ContentType:  contentType,
+		ETag:         hex.EncodeToString(hash.Sum(nil)),
+		LastModified: info.ModTime(),
+		URL:          s.URL(key),
+	}, nil
+}
+
+// Get 打开对象文件
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return file, &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentType,
+		LastModified: info.ModTime(),
+		URL:          s.URL(key),
+	}, nil
+}
+
+// Delete 删除对象文件
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// Exists 检查对象文件是否存在
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// URL 返回对象的访问地址
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Health 检查根目录是否可写
+func (s *LocalStorage) Health(ctx context.Context) error {
+	tmp, err := os.CreateTemp(s.baseDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("storage directory is not writable: %w", err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// Handler 返回用于提供本地文件访问的 HTTP 处理器
+func (s *LocalStorage) Handler() http.Handler {
+	return http.FileServer(http.Dir(s.baseDir))
+}
+
+// contextReader 在上下文取消时中止读取
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read 实现 io.Reader 接口
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashingReader(t *testing.T) {
+	reader := NewHashingReader(strings.NewReader("avatar"))
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "avatar", string(data))
+
+	sum := sha256.Sum256([]byte("avatar"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), reader.Sum())
+}
+
+func TestVersionedURL(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	assert.Equal(t, "/uploads/avatars/a.png?v=abababababababab", VersionedURL("/uploads/avatars/a.png", hash))
+	assert.Equal(t, "/uploads/a.png?x=1&v=abababababababab", VersionedURL("/uploads/a.png?x=1", hash))
+	assert.Equal(t, "/uploads/a.png", VersionedURL("/uploads/a.png", ""))
+}
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner([]byte("media-signing-key"), time.Hour)
+	now := time.Unix(1700000000, 0)
+	signer.now = func() time.Time { return now }
+
+	signed, err := signer.Sign("https://cdn.example.com/uploads/avatars/u1/a.png?v=abcdef")
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", u.Query().Get("v"), "其他查询参数保留")
+	assert.Equal(t, "1700003600", u.Query().Get("expires"))
+	require.NoError(t, signer.Verify(u.EscapedPath(), u.Query()))
+
+	// 路径被篡改
+	assert.ErrorIs(t, signer.Verify("/uploads/avatars/u2/a.png", u.Query()), ErrInvalidSignature)
+
+	// 过期时间被篡改
+	tampered := u.Query()
+	tampered.Set("expires", "1800000000")
+	assert.ErrorIs(t, signer.Verify(u.EscapedPath(), tampered), ErrInvalidSignature)
+
+	// 其他密钥签发
+	other := NewURLSigner([]byte("other-key"), time.Hour)
+	other.now = signer.now
+	otherSigned, err := other.Sign("/uploads/avatars/u1/a.png")
+	require.NoError(t, err)
+	ou, _ := url.Parse(otherSigned)
+	assert.ErrorIs(t, signer.Verify(ou.EscapedPath(), ou.Query()), ErrInvalidSignature)
+
+	// 过期
+	now = now.Add(2 * time.Hour)
+	assert.ErrorIs(t, signer.Verify(u.EscapedPath(), u.Query()), ErrURLExpired)
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config S3 兼容对象存储配置
+type S3Config struct {
+	Endpoint        string // 服务地址，如 s3.amazonaws.com 或 minio:9000
+	Region          string // 区域
+	Bucket          string // 存储桶名称
+	AccessKeyID     string // 访问密钥ID
+	SecretAccessKey string // 访问密钥
+	UseSSL          bool   // 是否使用 HTTPS
+	PublicURL       string // 公开访问地址前缀（如 CDN 地址），为空时使用服务地址
+	PartSize        uint64 // 分片上传的分片大小（字节），0 表示使用默认值
+}
+
+// S3Storage 基于 S3 兼容对象存储的实现
+type S3Storage struct {
+	client *minio.Client
+	config S3Config
+}
+
+// NewS3Storage 创建 S3 兼容对象存储
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("s3 endpoint is required")
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return &S3Storage{
+		client: client,
+		config: cfg,
+	}, nil
+}
+
+// Driver 返回驱动名称
+func (s *S3Storage) Driver() string {
+	return DriverS3
+}
+
+// Put 以流式方式上传对象，size 为 -1 时自动使用分片上传
+func (s *S3Storage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	info, err := s.client.PutObject(ctx, s.config.Bucket, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    s.config.PartSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload object: %w", err)
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  contentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		URL:          s.URL(key),
+	}, nil
+}
+
+// Get 下载对象
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s.translateError(err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, s.translateError(err)
+	}
+
+	return object, &Object{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		URL:          s.URL(key),
+	}, nil
+}
+
+// Delete 删除对象
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(ctx, s.config.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		if errors.Is(s.translateError(err), ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// Exists 检查对象是否存在
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ValidateKey(key); err != nil {
+		return false, err
+	}
+
+	if _, err := s.client.StatObject(ctx, s.config.Bucket, key, minio.StatObjectOptions{}); err != nil {
+		if errors.Is(s.translateError(err), ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// URL 返回对象的公开访问地址
+func (s *S3Storage) URL(key string) string {
+	if s.config.PublicURL != "" {
+		return strings.TrimSuffix(s.config.PublicURL, "/") + "/" + key
+	}
+
+	scheme := "http"
+	if s.config.UseSSL {
+		scheme = "https"
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   s.config.Endpoint,
+		Path:   "/" + s.config.Bucket + "/" + key,
+	}
+	return u.String()
+}
+
+// Health 检查存储桶是否可访问
+func (s *S3Storage) Health(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.config.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to reach s3 endpoint: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("s3 bucket %s does not exist", s.config.Bucket)
+	}
+	return nil
+}
+
+// translateError 将 S3 的不存在错误转换为 ErrNotFound
+func (s *S3Storage) translateError(err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == 404 {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 存储驱动类型
+const (
+	DriverLocal = "local" // 本地文件系统
+	DriverS3    = "s3"    // S3 兼容对象存储（AWS S3、MinIO 等）
+)
+
+// 存储错误
+var (
+	ErrNotFound           = errors.New("storage: object not found")
+	ErrInvalidKey         = errors.New("storage: invalid object key")
+	ErrTooLarge           = errors.New("storage: object exceeds maximum size")
+	ErrUnsupportedContent = errors.New("storage: unsupported content type")
+)
+
+// Object 存储对象的元数据
+type Object struct {
+	Key          string    `json:"key"`           // 对象键
+	Size         int64     `json:"size"`          // 对象大小（字节）
+	ContentType  string    `json:"content_type"`  // 内容类型
+	ETag         string    `json:"etag"`          // 实体标签
+	LastModified time.Time `json:"last_modified"` // 最后修改时间
+	URL          string    `json:"url"`           // 访问地址
+}
+
+// Storage 定义对象存储接口
+type Storage interface {
+	// Put 以流式方式写入对象，size 未知时传 -1
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*Object, error)
+
+	// Get 读取对象，调用方负责关闭返回的 ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+
+	// Exists 检查对象是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// URL 返回对象的公开访问地址
+	URL(key string) string
+
+	// Health 检查存储后端是否可用
+	Health(ctx context.Context) error
+
+	// Driver 返回驱动名称
+	Driver() string
+}
+
+// Config 对象存储配置
+type Config struct {
+	Driver string      // 驱动类型：local、s3
+	Local  LocalConfig // 本地文件系统配置
+	S3     S3Config    // S3 配置
+}
+
+// New 根据配置创建存储实例
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.Local)
+	case DriverS3:
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
+
+// GenerateKey 生成带前缀的唯一对象键，如 avatars/<owner>/<uuid>.png
+func GenerateKey(prefix, owner, extension string) string {
+	name := uuid.New().String()
+	if extension != "" {
+		name += "." + strings.TrimPrefix(extension, ".")
+	}
+	return path.Join(prefix, owner, name)
+}
+
+// ValidateKey 检查对象键是否安全（禁止绝对路径和目录穿越）
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// sniffLength 内容类型探测读取的字节数
+const sniffLength = 512
+
+// DetectContentType 读取数据头部探测内容类型，返回的 Reader 包含完整数据
+func DetectContentType(reader io.Reader) (string, io.Reader, error) {
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, fmt.Errorf("failed to read content header: %w", err)
+	}
+	header = header[:n]
+
+	contentType := http.DetectContentType(header)
+	if idx := strings.Index(contentType, ";"); idx >= 0 {
+		contentType = strings.TrimSpace(contentType[:idx])
+	}
+
+	return contentType, io.MultiReader(bytes.NewReader(header), reader), nil
+}
+
+// ValidateContentType 检查内容类型是否在允许列表中，列表为空时允许所有类型
+func ValidateContentType(contentType string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, contentType) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedContent, contentType)
+}
+
+// ExtensionForContentType 返回常见内容类型对应的文件扩展名
+func ExtensionForContentType(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return "jpg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	case "application/pdf":
+		return "pdf"
+	default:
+		return ""
+	}
+}
+
+// LimitReader 限制读取的最大字节数，超出时返回 ErrTooLarge
+func LimitReader(reader io.Reader, maxBytes int64) io.Reader {
+	return &limitedReader{reader: reader, remaining: maxBytes}
+}
+
+// limitedReader 超出限制时返回错误而不是静默截断
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+// Read 实现 io.Reader 接口
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// 多读一个字节用于判断是否超限
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader 最小的 PNG 文件头，用于内容类型探测
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'}
+
+func TestNew_Drivers(t *testing.T) {
+	store, err := New(Config{Local: LocalConfig{BaseDir: t.TempDir()}})
+	require.NoError(t, err)
+	assert.Equal(t, DriverLocal, store.Driver())
+
+	_, err = New(Config{Driver: DriverS3})
+	assert.Error(t, err, "s3 driver requires endpoint and bucket")
+
+	store, err = New(Config{Driver: DriverS3, S3: S3Config{Endpoint: "localhost:9000", Bucket: "uploads"}})
+	require.NoError(t, err)
+	assert.Equal(t, DriverS3, store.Driver())
+	assert.Equal(t, "http://localhost:9000/uploads/avatars/a.png", store.URL("avatars/a.png"))
+
+	_, err = New(Config{Driver: "floppy"})
+	assert.Error(t, err)
+}
+
+func TestLocalStorage_RoundTrip(t *testing.T) {
+	store, err := NewLocalStorage(LocalConfig{BaseDir: t.TempDir(), BaseURL: "/uploads/"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	content := []byte("hello storage")
+	obj, err := store.Put(ctx, "avatars/u-1/a.txt", bytes.NewReader(content), -1, "text/plain")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), obj.Size)
+	assert.Equal(t, "/uploads/avatars/u-1/a.txt", obj.URL)
+	assert.NotEmpty(t, obj.ETag)
+
+	exists, err := store.Exists(ctx, "avatars/u-1/a.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	reader, meta, err := store.Get(ctx, "avatars/u-1/a.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, int64(len(content)), meta.Size)
+
+	require.NoError(t, store.Delete(ctx, "avatars/u-1/a.txt"))
+	require.NoError(t, store.Delete(ctx, "avatars/u-1/a.txt"), "deleting a missing object is not an error")
+
+	_, _, err = store.Get(ctx, "avatars/u-1/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, store.Health(ctx))
+}
+
+func TestLocalStorage_PutFailureLeavesNoObject(t *testing.T) {
+	store, err := NewLocalStorage(LocalConfig{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	reader := LimitReader(strings.NewReader("0123456789"), 4)
+	_, err = store.Put(ctx, "big/file.bin", reader, -1, "application/octet-stream")
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	exists, err := store.Exists(ctx, "big/file.bin")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.Put(ctx, "../escape.txt", strings.NewReader("x"), 1, "text/plain")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"a.png", "avatars/u-1/a.png"}
+	for _, key := range valid {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+
+	invalid := []string{"", "/abs.png", "a/../b.png", "a//b.png", "./a.png", `a\b.png`}
+	for _, key := range invalid {
+		assert.ErrorIs(t, ValidateKey(key), ErrInvalidKey, key)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key := GenerateKey("avatars", "u-1", ".png")
+	assert.True(t, strings.HasPrefix(key, "avatars/u-1/"))
+	assert.True(t, strings.HasSuffix(key, ".png"))
+	assert.NoError(t, ValidateKey(key))
+	assert.NotEqual(t, key, GenerateKey("avatars", "u-1", "png"))
+}
+
+func TestDetectContentType(t *testing.T) {
+	payload := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 1024)...)
+
+	contentType, reader, err := DetectContentType(bytes.NewReader(payload))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, payload, data, "returned reader must replay the sniffed header")
+
+	contentType, _, err = DetectContentType(strings.NewReader("plain text"))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+}
+
+func TestValidateContentType(t *testing.T) {
+	allowed := []string{"image/png", "image/jpeg"}
+	assert.NoError(t, ValidateContentType("image/png", allowed))
+	assert.NoError(t, ValidateContentType("text/plain", nil))
+
+	err := ValidateContentType("text/html", allowed)
+	assert.True(t, errors.Is(err, ErrUnsupportedContent))
+}
+
+func TestLimitReader(t *testing.T) {
+	data, err := io.ReadAll(LimitReader(strings.NewReader("1234"), 4))
+	require.NoError(t, err)
+	assert.Equal(t, "1234", string(data))
+
+	_, err = io.ReadAll(LimitReader(strings.NewReader("12345"), 4))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
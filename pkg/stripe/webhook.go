@@ -0,0 +1,204 @@
+// Package stripe 校验和解析 Stripe webhook 事件，只依赖标准库
+//
+// Stripe 在 Stripe-Signature 头中携带时间戳和签名：t=1492774577,v1=5257a869...，
+// 签名为 webhook 密钥对 "{t}.{请求体}" 的 HMAC-SHA256。密钥轮换期间一个请求可能带有多个 v1 签名，任意一个匹配即可。
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeaderName Stripe 携带签名的请求头
+const SignatureHeaderName = "Stripe-Signature"
+
+// DefaultTolerance 签名时间戳与当前时间允许的最大偏差，与 Stripe 官方库相同
+const DefaultTolerance = 5 * time.Minute
+
+// 订阅相关的事件类型
+const (
+	EventSubscriptionCreated = "customer.subscription.created"
+	EventSubscriptionUpdated = "customer.subscription.updated"
+	EventSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+var (
+	// ErrInvalidHeader Stripe-Signature 头缺失或格式错误
+	ErrInvalidHeader = errors.New("stripe: invalid signature header")
+	// ErrNoValidSignature 没有与请求体匹配的签名
+	ErrNoValidSignature = errors.New("stripe: no valid signature")
+	// ErrTimestampOutOfRange 签名时间戳超出允许的偏差，可能是重放的请求
+	ErrTimestampOutOfRange = errors.New("stripe: timestamp outside the tolerance zone")
+)
+
+// Event Stripe 事件，data.object 按事件类型解析
+type Event struct {
+	ID      string    `json:"id" example:"evt_1NG8Du2eZvKYlo2CUI79vXWy"`    // 事件ID
+	Type    string    `json:"type" example:"customer.subscription.updated"` // 事件类型
+	Created int64     `json:"created" example:"1686089970"`                 // 事件创建时间（Unix 秒）
+	Data    EventData `json:"data"`                                         // 事件数据
+}
+
+// EventData 事件携带的对象
+type EventData struct {
+	Object json.RawMessage `json:"object" swaggertype:"object"` // 事件发生后的对象
+}
+
+// CreatedAt 返回事件的创建时间
+func (e *Event) CreatedAt() time.Time {
+	return time.Unix(e.Created, 0).UTC()
+}
+
+// Subscription 订阅对象中用到的字段
+type Subscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []SubscriptionItem `json:"data"`
+	} `json:"items"`
+}
+
+// SubscriptionItem 订阅项，每项对应一个价格
+type SubscriptionItem struct {
+	Price struct {
+		ID string `json:"id"`
+	} `json:"price"`
+	CurrentPeriodEnd int64 `json:"current_period_end"`
+}
+
+// PriceIDs 返回订阅各项的价格ID
+func (s *Subscription) PriceIDs() []string {
+	ids := make([]string, 0, len(s.Items.Data))
+	for _, item := range s.Items.Data {
+		if item.Price.ID != "" {
+			ids = append(ids, item.Price.ID)
+		}
+	}
+	return ids
+}
+
+// PeriodEnd 返回当前计费周期的结束时间，较新的 API 版本把它放在订阅项上；没有时返回零值
+func (s *Subscription) PeriodEnd() time.Time {
+	end := s.CurrentPeriodEnd
+	for _, item := range s.Items.Data {
+		end = max(end, item.CurrentPeriodEnd)
+	}
+	if end == 0 {
+		return time.Time{}
+	}
+	return time.Unix(end, 0).UTC()
+}
+
+// Subscription 把事件对象解析为订阅
+func (e *Event) Subscription() (*Subscription, error) {
+	var subscription Subscription
+	if err := json.Unmarshal(e.Data.Object, &subscription); err != nil {
+		return nil, fmt.Errorf("stripe: failed to decode subscription: %w", err)
+	}
+	if subscription.ID == "" {
+		return nil, errors.New("stripe: subscription id is missing")
+	}
+	return &subscription, nil
+}
+
+// ConstructEvent 校验签名后解析事件，tolerance 为 0 时不检查时间戳
+func ConstructEvent(payload []byte, header, secret string, tolerance time.Duration, now time.Time) (*Event, error) {
+	if err := VerifySignature(payload, header, secret, tolerance, now); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("stripe: failed to decode event: %w", err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, errors.New("stripe: event id or type is missing")
+	}
+	return &event, nil
+}
+
+// VerifySignature 校验 Stripe-Signature 头
+func VerifySignature(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	timestamp, signatures, err := parseHeader(header)
+	if err != nil {
+		return err
+	}
+
+	expected := computeSignature(payload, timestamp, secret)
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal(expected, signature) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrNoValidSignature
+	}
+
+	if tolerance > 0 {
+		if skew := now.Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+			return ErrTimestampOutOfRange
+		}
+	}
+	return nil
+}
+
+// SignatureHeader 返回请求体的 Stripe-Signature 头，用于测试和本地重放事件
+func SignatureHeader(payload []byte, secret string, timestamp time.Time) string {
+	t := timestamp.Unix()
+	return "t=" + strconv.FormatInt(t, 10) + ",v1=" + hex.EncodeToString(computeSignature(payload, t, secret))
+}
+
+// parseHeader 解析时间戳和 v1 签名，忽略其他方案的签名
+func parseHeader(header string) (int64, [][]byte, error) {
+	var timestamp int64
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, nil, ErrInvalidHeader
+		}
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, nil, ErrInvalidHeader
+			}
+			timestamp = t
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+	if timestamp == 0 {
+		return 0, nil, ErrInvalidHeader
+	}
+	if len(signatures) == 0 {
+		return 0, nil, ErrNoValidSignature
+	}
+	return timestamp, signatures, nil
+}
+
+// computeSignature 计算 "{t}.{payload}" 的 HMAC-SHA256
+func computeSignature(payload []byte, timestamp int64, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
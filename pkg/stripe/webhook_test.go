@@ -0,0 +1,79 @@
+package stripe
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "whsec_test"
+
+const subscriptionEvent = `{
+	"id": "evt_1",
+	"type": "customer.subscription.updated",
+	"created": 1717200000,
+	"data": {"object": {
+		"id": "sub_1",
+		"customer": "cus_1",
+		"status": "active",
+		"cancel_at_period_end": true,
+		"metadata": {"user_id": "u1"},
+		"items": {"data": [{"price": {"id": "price_pro"}, "current_period_end": 1719792000}]}
+	}}
+}`
+
+func TestConstructEvent(t *testing.T) {
+	now := time.Unix(1717200000, 0)
+	payload := []byte(subscriptionEvent)
+
+	event, err := ConstructEvent(payload, SignatureHeader(payload, testSecret, now), testSecret, DefaultTolerance, now)
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, EventSubscriptionUpdated, event.Type)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), event.CreatedAt())
+
+	subscription, err := event.Subscription()
+	require.NoError(t, err)
+	assert.Equal(t, "sub_1", subscription.ID)
+	assert.Equal(t, "cus_1", subscription.Customer)
+	assert.True(t, subscription.CancelAtPeriodEnd)
+	assert.Equal(t, "u1", subscription.Metadata["user_id"])
+	assert.Equal(t, []string{"price_pro"}, subscription.PriceIDs())
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), subscription.PeriodEnd(), "周期结束时间取自订阅项")
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1717200000, 0)
+	payload := []byte(subscriptionEvent)
+	header := SignatureHeader(payload, testSecret, now)
+
+	t.Run("密钥轮换期间任意一个签名匹配即可", func(t *testing.T) {
+		rotated := SignatureHeader(payload, "whsec_old", now) + "," + strings.Split(header, ",")[1] + ",v0=ignored"
+		assert.NoError(t, VerifySignature(payload, rotated, testSecret, DefaultTolerance, now))
+	})
+
+	t.Run("请求体被修改", func(t *testing.T) {
+		tampered := []byte(strings.Replace(subscriptionEvent, "price_pro", "price_enterprise", 1))
+		assert.ErrorIs(t, VerifySignature(tampered, header, testSecret, DefaultTolerance, now), ErrNoValidSignature)
+	})
+
+	t.Run("密钥错误", func(t *testing.T) {
+		assert.ErrorIs(t, VerifySignature(payload, header, "whsec_other", DefaultTolerance, now), ErrNoValidSignature)
+	})
+
+	t.Run("时间戳超出偏差", func(t *testing.T) {
+		late := now.Add(DefaultTolerance + time.Second)
+		assert.ErrorIs(t, VerifySignature(payload, header, testSecret, DefaultTolerance, late), ErrTimestampOutOfRange)
+		assert.NoError(t, VerifySignature(payload, header, testSecret, 0, late), "tolerance 为 0 时不检查时间戳")
+	})
+
+	t.Run("签名头格式错误", func(t *testing.T) {
+		for _, header := range []string{"", "garbage", "t=abc,v1=00", "v1=00"} {
+			assert.ErrorIs(t, VerifySignature(payload, header, testSecret, DefaultTolerance, now), ErrInvalidHeader, header)
+		}
+		assert.ErrorIs(t, VerifySignature(payload, "t=1717200000", testSecret, DefaultTolerance, now), ErrNoValidSignature)
+	})
+}
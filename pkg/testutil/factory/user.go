@@ -0,0 +1,215 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-server/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// firstNames 和 lastNames 让批量生成的用户姓名有一定分布，便于压测搜索接口
+var (
+	firstNames = []string{"Alice", "Bob", "Carol", "David", "Erin", "Frank", "Grace", "Heidi", "Ivan", "Judy"}
+	lastNames  = []string{"Smith", "Johnson", "Brown", "Lee", "Wang", "Garcia", "Miller", "Davis", "Chen", "Wilson"}
+)
+
+// UserCreator 能保存用户的存储，repositories.UserRepository 和内存仓库都满足
+type UserCreator interface {
+	Create(ctx context.Context, user *models.User) error
+}
+
+// UserBuilder 用户构造器，方法返回自身以便链式调用；Build 不修改构造器，可重复使用
+type UserBuilder struct {
+	id           string
+	prefix       string
+	username     string
+	email        string
+	firstName    string
+	lastName     string
+	passwordHash string
+	tenantID     *string
+	admin        bool
+	inactive     bool
+	createdAt    time.Time
+	lastLogin    *time.Time
+	each         []func(i int, user *models.User)
+}
+
+// User 创建用户构造器，默认生成激活的普通用户，密码为 DefaultPassword
+func User() *UserBuilder {
+	return &UserBuilder{prefix: "user"}
+}
+
+// Admin 生成管理员
+func (b *UserBuilder) Admin() *UserBuilder {
+	b.admin = true
+	return b
+}
+
+// Inactive 生成已停用的用户
+func (b *UserBuilder) Inactive() *UserBuilder {
+	b.inactive = true
+	return b
+}
+
+// WithID 指定用户ID，只适合生成单个用户
+func (b *UserBuilder) WithID(id string) *UserBuilder {
+	b.id = id
+	return b
+}
+
+// WithPrefix 指定生成用户名和邮箱时使用的前缀，如 "bench" 生成 bench_1 和 bench-1@example.com
+func (b *UserBuilder) WithPrefix(prefix string) *UserBuilder {
+	b.prefix = prefix
+	return b
+}
+
+// WithUsername 指定用户名，只适合生成单个用户
+func (b *UserBuilder) WithUsername(username string) *UserBuilder {
+	b.username = username
+	return b
+}
+
+// WithEmail 指定邮箱，只适合生成单个用户
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.email = email
+	return b
+}
+
+// WithName 指定姓名
+func (b *UserBuilder) WithName(firstName, lastName string) *UserBuilder {
+	b.firstName = firstName
+	b.lastName = lastName
+	return b
+}
+
+// WithPassword 指定明文密码，立即计算哈希
+func (b *UserBuilder) WithPassword(password string) *UserBuilder {
+	b.passwordHash = hashPassword(password)
+	return b
+}
+
+// WithPasswordHash 直接指定密码哈希
+func (b *UserBuilder) WithPasswordHash(hash string) *UserBuilder {
+	b.passwordHash = hash
+	return b
+}
+
+// WithTenant 指定所属租户
+func (b *UserBuilder) WithTenant(tenantID string) *UserBuilder {
+	b.tenantID = &tenantID
+	return b
+}
+
+// CreatedAt 指定创建时间
+func (b *UserBuilder) CreatedAt(t time.Time) *UserBuilder {
+	b.createdAt = t
+	return b
+}
+
+// LastLoginAt 指定最后登录时间
+func (b *UserBuilder) LastLoginAt(t time.Time) *UserBuilder {
+	b.lastLogin = &t
+	return b
+}
+
+// Each 在生成每个用户后调用 fn，i 为批量生成中的下标（Build 时为 0），用于让批量数据有所差异
+func (b *UserBuilder) Each(fn func(i int, user *models.User)) *UserBuilder {
+	b.each = append(b.each, fn)
+	return b
+}
+
+// Build 生成一个用户，不写入存储
+func (b *UserBuilder) Build() *models.User {
+	return b.build(0)
+}
+
+// BuildMany 生成 n 个用户，不写入存储
+func (b *UserBuilder) BuildMany(n int) []*models.User {
+	users := make([]*models.User, n)
+	for i := range users {
+		users[i] = b.build(i)
+	}
+	return users
+}
+
+// build 生成第 i 个用户
+func (b *UserBuilder) build(i int) *models.User {
+	seq := Next()
+	now := time.Now()
+
+	user := &models.User{
+		ID:        b.id,
+		Username:  b.username,
+		Email:     b.email,
+		FirstName: b.firstName,
+		LastName:  b.lastName,
+		Password:  b.passwordHash,
+		TenantID:  b.tenantID,
+		IsActive:  !b.inactive,
+		IsAdmin:   b.admin,
+		CreatedAt: b.createdAt,
+		UpdatedAt: now,
+	}
+	if user.ID == "" {
+		user.ID = uuid.NewString()
+	}
+	if user.Username == "" {
+		user.Username = fmt.Sprintf("%s_%d", b.prefix, seq)
+	}
+	if user.Email == "" {
+		user.Email = fmt.Sprintf("%s-%d@example.com", b.prefix, seq)
+	}
+	if user.FirstName == "" && user.LastName == "" {
+		user.FirstName = firstNames[seq%int64(len(firstNames))]
+		user.LastName = lastNames[(seq/int64(len(firstNames)))%int64(len(lastNames))]
+	}
+	if user.Password == "" {
+		user.Password = defaultPasswordHash()
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if b.lastLogin != nil {
+		lastLogin := *b.lastLogin
+		user.LastLogin = &lastLogin
+	}
+	for _, fn := range b.each {
+		fn(i, user)
+	}
+	return user
+}
+
+// Create 生成一个用户并写入数据库
+func (b *UserBuilder) Create(db *gorm.DB) (*models.User, error) {
+	user := b.Build()
+	if err := db.Create(user).Error; err != nil {
+		return nil, fmt.Errorf("factory: create user: %w", err)
+	}
+	return user, nil
+}
+
+// CreateMany 生成 n 个用户并分批写入数据库
+func (b *UserBuilder) CreateMany(db *gorm.DB, n int) ([]*models.User, error) {
+	users := b.BuildMany(n)
+	if len(users) == 0 {
+		return users, nil
+	}
+	if err := db.CreateInBatches(users, batchSize).Error; err != nil {
+		return nil, fmt.Errorf("factory: create %d users: %w", n, err)
+	}
+	return users, nil
+}
+
+// Save 生成一个用户并通过仓库保存，适合不直接访问数据库的测试
+func (b *UserBuilder) Save(ctx context.Context, repo UserCreator) (*models.User, error) {
+	user := b.Build()
+	if err := repo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("factory: save user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,82 @@
+package factory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-server/internal/models"
+	"go-server/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserBuilder(t *testing.T) {
+	t.Run("默认值", func(t *testing.T) {
+		user := User().Build()
+
+		assert.NotEmpty(t, user.ID)
+		assert.NotEmpty(t, user.Username)
+		assert.Contains(t, user.Email, "@example.com")
+		assert.NotEmpty(t, user.FirstName)
+		assert.True(t, user.IsActive)
+		assert.False(t, user.IsAdmin)
+		assert.False(t, user.CreatedAt.IsZero())
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(DefaultPassword)))
+	})
+
+	t.Run("链式指定字段", func(t *testing.T) {
+		login := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		user := User().Admin().Inactive().
+			WithID("user-1").WithEmail("root@example.com").WithUsername("root").
+			WithName("Root", "Admin").WithPassword("S3cret!").WithTenant("tenant-1").
+			LastLoginAt(login).Build()
+
+		assert.Equal(t, "user-1", user.ID)
+		assert.Equal(t, "root@example.com", user.Email)
+		assert.Equal(t, "root", user.Username)
+		assert.Equal(t, "Root", user.FirstName)
+		assert.True(t, user.IsAdmin)
+		assert.False(t, user.IsActive)
+		require.NotNil(t, user.TenantID)
+		assert.Equal(t, "tenant-1", *user.TenantID)
+		assert.Equal(t, login, *user.LastLogin)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("S3cret!")))
+	})
+
+	t.Run("批量生成的用户互不冲突", func(t *testing.T) {
+		users := User().WithPrefix("bench").Each(func(i int, user *models.User) {
+			user.IsAdmin = i%10 == 0
+		}).BuildMany(50)
+
+		require.Len(t, users, 50)
+		emails := make(map[string]bool)
+		admins := 0
+		for _, user := range users {
+			assert.Contains(t, user.Username, "bench_")
+			assert.False(t, emails[user.Email], "duplicate email %s", user.Email)
+			emails[user.Email] = true
+			if user.IsAdmin {
+				admins++
+			}
+		}
+		assert.Equal(t, 5, admins)
+	})
+
+	t.Run("通过仓库保存", func(t *testing.T) {
+		repo := repositories.NewMemoryUserRepository()
+		ctx := context.Background()
+
+		saved, err := User().WithEmail("saved@example.com").Save(ctx, repo)
+		require.NoError(t, err)
+
+		found, err := repo.GetByEmail(ctx, "saved@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, saved.ID, found.ID)
+
+		_, err = User().WithEmail("saved@example.com").Save(ctx, repo)
+		assert.ErrorIs(t, err, repositories.ErrDuplicateUser)
+	})
+}
@@ -0,0 +1,146 @@
+// Package tracecontext 解析和生成 W3C Trace Context（https://www.w3.org/TR/trace-context/）请求头
+//
+// 服务收到带 traceparent 的请求时作为调用方 span 的子 span 继续同一条链路，没有或无效时开始新的链路；
+// 调用下游服务时通过 Inject 传递当前 span，使日志和已有的链路追踪系统按 trace-id 关联。
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 请求头名称
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// FlagSampled traceparent 中表示调用方已记录该链路的标志位
+const FlagSampled byte = 0x01
+
+// maxTraceStateLength tracestate 的最大长度，超过时不再传递
+const maxTraceStateLength = 512
+
+// ErrInvalidTraceParent traceparent 格式不正确
+var ErrInvalidTraceParent = errors.New("tracecontext: invalid traceparent")
+
+// SpanContext 一次调用（span）在链路中的位置
+type SpanContext struct {
+	TraceID    string // 32 位小写十六进制链路ID，同一链路的所有服务相同
+	SpanID     string // 16 位小写十六进制 span ID，每一跳不同
+	Flags      byte   // 链路标志，目前只定义了 FlagSampled
+	TraceState string // 各追踪系统的附加信息，原样传递
+}
+
+// Parse 解析 traceparent 和 tracestate 请求头，traceparent 无效时忽略 tracestate
+func Parse(traceParent, traceState string) (SpanContext, error) {
+	traceParent = strings.TrimSpace(traceParent)
+	// 版本 00 固定为 55 个字符；更高版本可以在后面追加以 - 分隔的字段
+	if len(traceParent) < 55 || (len(traceParent) > 55 && traceParent[55] != '-') {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	version, traceID, spanID, flags := traceParent[0:2], traceParent[3:35], traceParent[36:52], traceParent[53:55]
+	if traceParent[2] != '-' || traceParent[35] != '-' || traceParent[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	if !isHex(version) || version == "ff" || (version == "00" && len(traceParent) != 55) {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	if !isHex(traceID) || isZero(traceID) || !isHex(spanID) || isZero(spanID) || !isHex(flags) {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	decoded, _ := hex.DecodeString(flags)
+	sc := SpanContext{TraceID: traceID, SpanID: spanID, Flags: decoded[0]}
+	if traceState = strings.TrimSpace(traceState); len(traceState) <= maxTraceStateLength {
+		sc.TraceState = traceState
+	}
+	return sc, nil
+}
+
+// New 开始一条新的链路，标记为已记录
+func New() SpanContext {
+	return SpanContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: FlagSampled}
+}
+
+// Child 返回同一链路中的下一跳，保留标志和 tracestate
+func (sc SpanContext) Child() SpanContext {
+	sc.SpanID = randomHex(8)
+	return sc
+}
+
+// IsValid 判断 span 是否有链路ID和 span ID
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+// Sampled 判断链路是否已被记录
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// TraceParent 返回版本 00 格式的 traceparent 请求头
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// contextKey 请求上下文中保存 span 的键
+type contextKey struct{}
+
+// WithSpanContext 返回保存了当前 span 的上下文
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext 返回上下文中的当前 span，不存在时返回 false
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Inject 把上下文中的当前 span 写入发往下游服务的请求头，下游服务将其作为父 span
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	header.Set(TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(TraceStateHeader, sc.TraceState)
+	} else {
+		header.Del(TraceStateHeader)
+	}
+}
+
+// isHex 判断是否为小写十六进制字符串
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero 判断十六进制字符串是否全为 0，全 0 的 ID 无效
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// randomHex 生成 n 字节的随机 ID，全 0 时重新生成
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			panic(fmt.Sprintf("tracecontext: failed to generate id: %v", err))
+		}
+		if id := hex.EncodeToString(b); !isZero(id) {
+			return id
+		}
+	}
+}
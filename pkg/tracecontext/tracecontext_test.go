@@ -0,0 +1,78 @@
+package tracecontext
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParse(t *testing.T) {
+	sc, err := Parse(validTraceParent, "congo=t61rcWkgMzE")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID)
+	assert.True(t, sc.Sampled())
+	assert.Equal(t, "congo=t61rcWkgMzE", sc.TraceState)
+	assert.Equal(t, validTraceParent, sc.TraceParent())
+
+	t.Run("更高版本忽略追加的字段", func(t *testing.T) {
+		sc, err := Parse("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", "")
+		require.NoError(t, err)
+		assert.False(t, sc.Sampled())
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sc.TraceParent())
+	})
+
+	t.Run("无效的 traceparent", func(t *testing.T) {
+		for _, header := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+		} {
+			_, err := Parse(header, "congo=t61rcWkgMzE")
+			assert.ErrorIs(t, err, ErrInvalidTraceParent, header)
+		}
+	})
+}
+
+func TestNewAndChild(t *testing.T) {
+	root := New()
+	assert.Len(t, root.TraceID, 32)
+	assert.Len(t, root.SpanID, 16)
+	assert.True(t, root.Sampled())
+
+	parsed, err := Parse(root.TraceParent(), "")
+	require.NoError(t, err)
+	assert.Equal(t, root, parsed)
+
+	child := root.Child()
+	assert.Equal(t, root.TraceID, child.TraceID)
+	assert.NotEqual(t, root.SpanID, child.SpanID)
+	assert.NotEqual(t, root.TraceID, New().TraceID)
+}
+
+func TestInject(t *testing.T) {
+	header := http.Header{}
+	Inject(context.Background(), header)
+	assert.Empty(t, header.Get(TraceParentHeader), "上下文中没有 span 时不写入")
+
+	sc, err := Parse(validTraceParent, "congo=t61rcWkgMzE")
+	require.NoError(t, err)
+	ctx := WithSpanContext(context.Background(), sc)
+	got, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, sc, got)
+
+	Inject(ctx, header)
+	assert.Equal(t, validTraceParent, header.Get(TraceParentHeader))
+	assert.Equal(t, "congo=t61rcWkgMzE", header.Get(TraceStateHeader))
+}
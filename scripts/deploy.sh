@@ -67,7 +67,7 @@ validate_environment() {
         "APP_JWT_SECRET_KEY"
         "APP_DATABASE_HOST"
         "APP_DATABASE_PASSWORD"
-        "APP_DATABASE_NAME"
+        "APP_DATABASE_DB_NAME"
         "APP_REDIS_HOST"
     )
 
@@ -205,13 +205,12 @@ build_application() {
         exit 1
     fi
 
-    # Generate Swagger documentation
-    print_status "Generating Swagger documentation..."
-    if command_exists swag; then
-        swag init -g cmd/api/main.go -o docs
-        print_success "Swagger documentation generated"
+    # Generate OpenAPI documentation
+    print_status "Generating OpenAPI documentation..."
+    if go run ./cmd/openapi; then
+        print_success "OpenAPI documentation generated"
     else
-        print_warning "swag not found, skipping documentation generation"
+        print_warning "Failed to generate OpenAPI documentation"
     fi
 }
 
@@ -466,7 +465,7 @@ while [[ $# -gt 0 ]]; do
             echo "  APP_JWT_SECRET_KEY    JWT secret key"
             echo "  APP_DATABASE_HOST     Database host"
             echo "  APP_DATABASE_PASSWORD Database password"
-            echo "  APP_DATABASE_NAME     Database name"
+            echo "  APP_DATABASE_DB_NAME  Database name"
             echo "  APP_REDIS_HOST        Redis host"
             echo ""
             exit 0
@@ -45,8 +45,8 @@ if not exist .env (
         echo APP_DATABASE_PORT=5432
         echo APP_DATABASE_USER=postgres
         echo APP_DATABASE_PASSWORD=password
-        echo APP_DATABASE_NAME=golang_template_dev
-        echo APP_DATABASE_SSLMODE=disable
+        echo APP_DATABASE_DB_NAME=golang_template_dev
+        echo APP_DATABASE_SSL_MODE=disable
     ) > .env
     echo [SUCCESS] .env file created
 ) else (
@@ -104,23 +104,13 @@ if %errorlevel% neq 0 (
 )
 echo [SUCCESS] go.mod tidied
 
-REM Install development tools
-where swag >nul 2>nul
-if %errorlevel% neq 0 (
-    echo [INFO] Installing swag...
-    go install github.com/swaggo/swag/cmd/swag@latest
-    echo [SUCCESS] swag installed
-) else (
-    echo [SUCCESS] swag already installed
-)
-
-REM Generate Swagger documentation
-echo [INFO] Generating Swagger documentation...
-swag init -g cmd/api/main.go -o docs >nul 2>nul
+REM Generate OpenAPI documentation
+echo [INFO] Generating OpenAPI documentation...
+go run ./cmd/openapi >nul 2>nul
 if %errorlevel% equ 0 (
-    echo [SUCCESS] Swagger documentation generated
+    echo [SUCCESS] OpenAPI documentation generated
 ) else (
-    echo [WARNING] Failed to generate Swagger docs ^(non-critical^)
+    echo [WARNING] Failed to generate OpenAPI docs ^(non-critical^)
 )
 
 REM Display startup information
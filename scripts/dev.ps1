@@ -98,8 +98,8 @@ APP_DATABASE_HOST=localhost
 APP_DATABASE_PORT=5432
 APP_DATABASE_USER=postgres
 APP_DATABASE_PASSWORD=password
-APP_DATABASE_NAME=golang_template_dev
-APP_DATABASE_SSLMODE=disable
+APP_DATABASE_DB_NAME=golang_template_dev
+APP_DATABASE_SSL_MODE=disable
 "@ | Out-File -FilePath ".env" -Encoding UTF8
     Write-Status ".env file created" "SUCCESS"
 } else {
@@ -199,16 +199,6 @@ if (Test-Path "go.mod") {
 # Install development tools
 Write-Header "Installing Development Tools"
 
-# Install swag
-$swagAvailable = Get-Command swag -ErrorAction SilentlyContinue
-if (-not $swagAvailable) {
-    Write-Status "Installing swag..." "INFO"
-    & go install github.com/swaggo/swag/cmd/swag@latest
-    Write-Status "swag installed" "SUCCESS"
-} else {
-    Write-Status "swag already installed" "SUCCESS"
-}
-
 # Install golangci-lint
 $golangciAvailable = Get-Command golangci-lint -ErrorAction SilentlyContinue
 if (-not $golangciAvailable) {
@@ -219,15 +209,15 @@ if (-not $golangciAvailable) {
     Write-Status "golangci-lint already installed" "SUCCESS"
 }
 
-# Generate Swagger documentation
-Write-Header "Generating Swagger Documentation"
+# Generate OpenAPI documentation
+Write-Header "Generating OpenAPI Documentation"
 
-Write-Status "Generating Swagger docs..." "INFO"
-& swag init -g cmd/api/main.go -o docs 2>$null
+Write-Status "Generating OpenAPI docs..." "INFO"
+& go run ./cmd/openapi 2>$null
 if ($LASTEXITCODE -eq 0) {
-    Write-Status "Swagger documentation generated" "SUCCESS"
+    Write-Status "OpenAPI documentation generated" "SUCCESS"
 } else {
-    Write-Status "Failed to generate Swagger docs (non-critical)" "WARNING"
+    Write-Status "Failed to generate OpenAPI docs (non-critical)" "WARNING"
 }
 
 # Start the development server
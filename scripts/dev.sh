@@ -184,15 +184,6 @@ install_dependencies() {
 install_dev_tools() {
     print_header "Installing Development Tools"
 
-    # Install swag for Swagger documentation
-    if ! command_exists swag; then
-        print_status "Installing swag..."
-        go install github.com/swaggo/swag/cmd/swag@latest
-        print_success "swag installed"
-    else
-        print_success "swag already installed"
-    fi
-
     # Install golangci-lint
     if ! command_exists golangci-lint; then
         print_status "Installing golangci-lint..."
@@ -203,15 +194,15 @@ install_dev_tools() {
     fi
 }
 
-# Generate Swagger documentation
+# Generate OpenAPI documentation
 generate_docs() {
-    print_header "Generating Swagger Documentation"
+    print_header "Generating OpenAPI Documentation"
 
-    print_status "Generating Swagger docs..."
-    if swag init -g cmd/api/main.go -o docs; then
-        print_success "Swagger documentation generated"
+    print_status "Generating OpenAPI docs..."
+    if go run ./cmd/openapi; then
+        print_success "OpenAPI documentation generated"
     else
-        print_warning "Failed to generate Swagger docs (non-critical)"
+        print_warning "Failed to generate OpenAPI docs (non-critical)"
     fi
 }
 
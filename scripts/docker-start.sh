@@ -73,13 +73,10 @@ echo -e "${GREEN}[SUCCESS] Dependencies installed${NC}"
 
 # Install tools
 echo -e "${BLUE}[INFO] Installing development tools...${NC}"
-if ! command -v swag &> /dev/null; then
-    go install github.com/swaggo/swag/cmd/swag@latest
-fi
 
 # Generate docs
-echo -e "${BLUE}[INFO] Generating Swagger docs...${NC}"
-swag init -g cmd/api/main.go -o docs 2>/dev/null || echo -e "${YELLOW}[WARNING] Swagger generation failed${NC}"
+echo -e "${BLUE}[INFO] Generating OpenAPI docs...${NC}"
+go run ./cmd/openapi 2>/dev/null || echo -e "${YELLOW}[WARNING] OpenAPI generation failed${NC}"
 
 # Set environment (only the mode, other settings come from YAML)
 export APP_ENV=development
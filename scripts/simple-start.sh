@@ -40,14 +40,10 @@ echo -e "${GREEN}[SUCCESS] Dependencies installed${NC}"
 # Install development tools
 echo -e "${BLUE}[INFO] Installing development tools...${NC}"
 
-if ! command -v swag &> /dev/null; then
-    go install github.com/swaggo/swag/cmd/swag@latest
-    echo -e "${GREEN}[SUCCESS] swag installed${NC}"
-fi
 
 # Generate documentation
-echo -e "${BLUE}[INFO] Generating Swagger docs...${NC}"
-swag init -g cmd/api/main.go -o docs 2>/dev/null || echo -e "${YELLOW}[WARNING] Swagger generation failed (non-critical)${NC}"
+echo -e "${BLUE}[INFO] Generating OpenAPI docs...${NC}"
+go run ./cmd/openapi 2>/dev/null || echo -e "${YELLOW}[WARNING] OpenAPI generation failed (non-critical)${NC}"
 
 # Start server
 echo ""